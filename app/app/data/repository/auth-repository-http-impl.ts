@@ -212,7 +212,14 @@ export class HttpAuthRepository implements IAuthRepository {
       const response = await getApiV1Auth2FaTotp({ client: this.client, headers, throwOnError: true });
       const payload = response.data?.data;
       if (!payload?.secret || !payload.token) logAndThrow("Invalid TOTP setup response.");
-      return { qrCode: payload.qr_code, secret: payload.secret, token: payload.token, url: payload.url };
+      // The QR code is rendered lazily by the backend, fetch it with the setup token.
+      const qrResponse = await this.client.get<{ 200: { data: { qr_code: string } } }, unknown, true>({
+        url         : "/api/v1/auth/2fa/totp/{token}/qr",
+        path        : { token: payload.token },
+        headers,
+        throwOnError: true,
+      });
+      return { qrCode: qrResponse.data?.data?.qr_code ?? "", secret: payload.secret, token: payload.token, url: payload.url };
     });
   }
 
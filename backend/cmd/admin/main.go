@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/di"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const usage = `usage: admin user <create|create-admin|reset-password|disable-2fa|list> [flags]
+       admin jwt rotate
+       admin tools <list|export|import|delete> [flags]
+
+The commands work offline on the configured database and take the same environment
+as the server. Run the database migration first.
+
+user manages the accounts, run admin user -h for its flags.
+
+jwt rotate replaces the JWT secret generated in CONFIG_FILE_PATH.
+
+tools manages the tools of one user, run admin tools -h for its flags.`
+
+func main() {
+	if err := runAndClose(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runAndClose closes the storage before the process exits, also while a panic unwinds
+func runAndClose(args []string) (err error) {
+	defer func() {
+		if closeErr := di.Close(); closeErr != nil && err == nil {
+			err = errors.Wrap(closeErr, "failed to close storage")
+		}
+	}()
+	return run(args)
+}
+
+func run(args []string) error {
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "user":
+		return runUser(args[1:])
+	case "jwt":
+		return runJWT(args[1:])
+	case "tools":
+		return runTools(args[1:])
+	default:
+		return errors.New(usage)
+	}
+}
+
+// runJWT rotates the JWT secret, the instances sign and verify access tokens with the secret read at start
+func runJWT(args []string) error {
+	if len(args) != 1 || args[0] != "rotate" {
+		return errors.New(usage)
+	}
+
+	var writableConfig config.WritableConfig
+	if err := initDependencies(func(w config.WritableConfig) {
+		writableConfig = w
+	}); err != nil {
+		return err
+	}
+
+	if err := writableConfig.RotateJWTSecret(); err != nil {
+		return errors.Wrap(err, "failed to rotate jwt secret")
+	}
+
+	fmt.Println("jwt secret rotated, restart the instances to use it.")
+	fmt.Println("access tokens signed with the old secret are refused, clients get new ones with their refresh tokens.")
+	return nil
+}
+
+// initDependencies initializes the DI container and the logger and calls fn with the dependencies it takes
+func initDependencies(fn any) error {
+	di.InitDI()
+
+	var cfg config.Config
+	if err := di.Container.Invoke(func(c config.Config) {
+		cfg = c
+	}); err != nil {
+		return errors.Errorf("failed to initialize admin dependencies: %v", err)
+	}
+	logger.InitLogger(cfg)
+
+	if err := di.Container.Invoke(fn); err != nil {
+		return errors.Errorf("failed to initialize admin dependencies: %v", err)
+	}
+	return nil
+}
+
+func initRequestContext() context.Context {
+	ctx := utils.NewValueContext(context.Background())
+	ctx.Set("x-request-id", uuid.New().String())
+	ctx.Set("request-start-time", time.Now())
+	return ctx
+}
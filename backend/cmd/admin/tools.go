@@ -0,0 +1,406 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"ya-tool-craft/internal/application/controller/auth"
+	"ya-tool-craft/internal/application/controller/tools"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/di"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const toolsUsage = `usage: admin tools list   (-user <name> | -server <url>)
+       admin tools export (-user <name> | -server <url>) [-o <file>]
+       admin tools import (-user <name> | -server <url>) -i <file>
+       admin tools delete (-user <name> | -server <url>) -uid <tool uid>
+
+With -user the commands work offline on the configured database. Stop the instances
+first when KEY_VALUE_DB_TYPE=nutsdb, a running instance keeps the nutsdb store locked.
+
+With -server they call the api of a running instance as the owner of the refresh
+token in TOOLBAKE_REFRESH_TOKEN.
+
+export writes the tools as json, to stdout without -o. import creates the tools of
+such a file and skips the ones whose id the user already has.`
+
+const (
+	// toolsRefreshTokenEnv holds the refresh token for -server, a flag would end up in the shell history
+	toolsRefreshTokenEnv = "TOOLBAKE_REFRESH_TOKEN"
+	// toolsExportVersion is the format of the export files, import refuses the other versions
+	toolsExportVersion = 1
+	toolsRemoteTimeout = 30 * time.Second
+)
+
+type toolsExport struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Tools      []tools.ToolDto `json:"tools"`
+}
+
+// toolsBackend is where the tool commands act: the database of the host, or a running instance over its api.
+type toolsBackend interface {
+	// List returns every tool of the user, archived ones included
+	List(ctx context.Context) ([]tools.ToolDto, error)
+	Create(ctx context.Context, tool tools.CreateToolRequestDto) error
+	Delete(ctx context.Context, toolUID string) error
+}
+
+func runTools(args []string) error {
+	if len(args) < 1 || !lo.Contains([]string{"list", "export", "import", "delete"}, args[0]) {
+		return errors.New(toolsUsage)
+	}
+	command := args[0]
+
+	flags := flag.NewFlagSet("tools "+command, flag.ContinueOnError)
+	username := flags.String("user", "", "username whose tools are managed offline")
+	server := flags.String("server", "", "url of a running instance, the tools of the refresh token owner are managed")
+	output := flags.String("o", "", "file the export is written to, stdout when empty")
+	input := flags.String("i", "", "export file to import")
+	toolUID := flags.String("uid", "", "uid of the tool to delete")
+	if err := flags.Parse(args[1:]); err != nil {
+		return errors.New(toolsUsage)
+	}
+	if (*username == "") == (*server == "") ||
+		(command == "import" && *input == "") ||
+		(command == "delete" && *toolUID == "") {
+		return errors.New(toolsUsage)
+	}
+
+	ctx := initRequestContext()
+	var backend toolsBackend
+	var err error
+	if *server != "" {
+		backend, err = newRemoteToolsBackend(ctx, *server, os.Getenv(toolsRefreshTokenEnv), &http.Client{Timeout: toolsRemoteTimeout})
+	} else {
+		backend, err = newOfflineToolsBackend(ctx, *username)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "list":
+		return listTools(ctx, backend, os.Stdout)
+	case "export":
+		return exportTools(ctx, backend, *output)
+	case "import":
+		return importTools(ctx, backend, *input, os.Stdout)
+	default:
+		return deleteTool(ctx, backend, *toolUID)
+	}
+}
+
+func listTools(ctx context.Context, backend toolsBackend, out io.Writer) error {
+	list, err := backend.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "UID\tID\tNAME\tCATEGORY\tSTATE")
+	for _, tool := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", tool.UID, tool.ToolID, tool.Name, tool.Category, toolState(tool))
+	}
+	return w.Flush()
+}
+
+func toolState(tool tools.ToolDto) string {
+	switch {
+	case tool.IsArchived:
+		return "archived"
+	case !tool.IsActivate:
+		return "inactive"
+	default:
+		return "active"
+	}
+}
+
+func exportTools(ctx context.Context, backend toolsBackend, output string) error {
+	list, err := backend.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(toolsExport{Version: toolsExportVersion, ExportedAt: time.Now().UTC(), Tools: list}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal tools")
+	}
+	content = append(content, '\n')
+	if output == "" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+	if err := os.WriteFile(output, content, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write %s", output)
+	}
+	fmt.Fprintf(os.Stderr, "%d tools exported to %s\n", len(list), output)
+	return nil
+}
+
+func importTools(ctx context.Context, backend toolsBackend, input string, out io.Writer) error {
+	content, err := os.ReadFile(input)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", input)
+	}
+	var export toolsExport
+	if err := json.Unmarshal(content, &export); err != nil {
+		return errors.Wrapf(err, "%s is not a tools export", input)
+	}
+	if export.Version != toolsExportVersion {
+		return errors.Errorf("%s has export version %d, only version %d is supported", input, export.Version, toolsExportVersion)
+	}
+
+	existing, err := backend.List(ctx)
+	if err != nil {
+		return err
+	}
+	existingIDs := lo.SliceToMap(existing, func(tool tools.ToolDto) (string, bool) { return tool.ToolID, true })
+
+	failed := 0
+	for _, tool := range export.Tools {
+		if existingIDs[tool.ToolID] {
+			fmt.Fprintf(out, "skipped %s: the id is taken\n", tool.ToolID)
+			continue
+		}
+		if err := backend.Create(ctx, createToolRequest(tool)); err != nil {
+			failed++
+			fmt.Fprintf(out, "failed %s: %v\n", tool.ToolID, err)
+			continue
+		}
+		existingIDs[tool.ToolID] = true
+		fmt.Fprintf(out, "imported %s\n", tool.ToolID)
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d tools failed to import", failed, len(export.Tools))
+	}
+	return nil
+}
+
+// createToolRequest turns an exported tool into a new tool, it gets a new uid and is not archived.
+func createToolRequest(tool tools.ToolDto) tools.CreateToolRequestDto {
+	return tools.CreateToolRequestDto{
+		ID:                tool.ToolID,
+		Name:              tool.Name,
+		Namespace:         tool.Namespace,
+		IsActivate:        tool.IsActivate,
+		RealtimeExecution: lo.ToPtr(tool.RealtimeExecution),
+		UiWidgets:         lo.ToPtr(tool.UiWidgets),
+		Source:            tool.Source,
+		Description:       lo.ToPtr(tool.Description),
+		ExtraInfo:         tool.ExtraInfo,
+		Category:          lo.ToPtr(tool.Category),
+	}
+}
+
+func deleteTool(ctx context.Context, backend toolsBackend, toolUID string) error {
+	list, err := backend.List(ctx)
+	if err != nil {
+		return err
+	}
+	if !lo.ContainsBy(list, func(tool tools.ToolDto) bool { return tool.UID == toolUID }) {
+		return errors.Errorf("the user has no tool with uid %s", toolUID)
+	}
+	if err := backend.Delete(ctx, toolUID); err != nil {
+		return err
+	}
+	fmt.Printf("tool deleted: uid: %s\n", toolUID)
+	return nil
+}
+
+// offlineToolsBackend changes the tools in the database like the api does, without the quota and secret checks
+// of the api. The cached tool lists are dropped, so running instances sharing the key-value store see the changes.
+type offlineToolsBackend struct {
+	userID          entity.UserIDEntity
+	toolRepository  repository.IToolRepository
+	cache           repository.ICache
+	meteringService *service.MeteringService
+}
+
+func newOfflineToolsBackend(ctx context.Context, username string) (*offlineToolsBackend, error) {
+	di.InitDI()
+
+	var cfg config.Config
+	var userRepository repository.IUserRepository
+	backend := &offlineToolsBackend{}
+	if err := di.Container.Invoke(func(
+		c config.Config,
+		u repository.IUserRepository,
+		t repository.IToolRepository,
+		cache repository.ICache,
+		m *service.MeteringService,
+	) {
+		cfg = c
+		userRepository = u
+		backend.toolRepository = t
+		backend.cache = cache
+		backend.meteringService = m
+	}); err != nil {
+		return nil, errors.Errorf("failed to initialize admin dependencies: %v", err)
+	}
+	logger.InitLogger(cfg)
+
+	user, exists, err := userRepository.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get user %s", username)
+	}
+	if !exists {
+		return nil, errors.Errorf("user %s does not exist", username)
+	}
+	backend.userID = user.ID
+	return backend, nil
+}
+
+func (b *offlineToolsBackend) List(ctx context.Context) ([]tools.ToolDto, error) {
+	list, err := b.toolRepository.AllTools(b.userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tools")
+	}
+	return lo.Map(list.Tools, func(tool entity.ToolEntity, _ int) tools.ToolDto {
+		item := tools.ToolDto{}
+		item.FromEntity(tool)
+		return item
+	}), nil
+}
+
+func (b *offlineToolsBackend) Create(ctx context.Context, req tools.CreateToolRequestDto) error {
+	// an exported tool brings every field, there is nothing left for the namespace defaults to fill
+	tool := req.ToEntity(entity.NamespaceEntity{})
+	if err := entity.ValidateToolExtraInfo(tool.ExtraInfo); err != nil {
+		return err
+	}
+	if err := b.toolRepository.CreateTool(b.userID, tool, b.eventSource()); err != nil {
+		return errors.Wrap(err, "failed to create tool")
+	}
+	return b.toolsChanged(ctx)
+}
+
+func (b *offlineToolsBackend) Delete(ctx context.Context, toolUID string) error {
+	if err := b.toolRepository.DeleteTool(b.userID, toolUID, b.eventSource()); err != nil {
+		return errors.Wrap(err, "failed to delete tool")
+	}
+	return b.toolsChanged(ctx)
+}
+
+// eventSource is the source of the tool events of the offline backend, it has no device nor request
+func (b *offlineToolsBackend) eventSource() entity.ToolEventSourceEntity {
+	return entity.ToolEventSourceEntity{ActorID: b.userID}
+}
+
+func (b *offlineToolsBackend) toolsChanged(ctx context.Context) error {
+	if err := b.meteringService.RecordToolStorage(ctx, b.userID); err != nil {
+		logger.Errorf(ctx, "Failed to record tool storage for user %s: %v", b.userID, err)
+	}
+	if err := tools.DeleteToolsCache(ctx, b.cache, b.userID); err != nil {
+		return errors.Wrap(err, "failed to delete cached tool lists")
+	}
+	return nil
+}
+
+// remoteToolsBackend calls the api of a running instance with an access token issued for the refresh token,
+// the instance applies its quota and secret checks as for any client.
+type remoteToolsBackend struct {
+	server      string
+	client      *http.Client
+	accessToken string
+}
+
+type remoteResponse struct {
+	Status    string          `json:"status"`
+	Message   string          `json:"message"`
+	ErrorCode string          `json:"error_code"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func newRemoteToolsBackend(ctx context.Context, server string, refreshToken string, client *http.Client) (*remoteToolsBackend, error) {
+	if refreshToken == "" {
+		return nil, errors.Errorf("%s must hold a refresh token of the user whose tools are managed", toolsRefreshTokenEnv)
+	}
+
+	backend := &remoteToolsBackend{server: strings.TrimSuffix(server, "/"), client: client}
+	var token auth.IssueAccessTokenResponseDto
+	if err := backend.call(ctx, http.MethodPost, "/api/v1/auth/access-token", auth.IssueAccessTokenRequestDto{RefreshToken: refreshToken}, &token); err != nil {
+		return nil, errors.Wrap(err, "failed to get an access token")
+	}
+	backend.accessToken = token.AccessToken
+	return backend, nil
+}
+
+func (b *remoteToolsBackend) List(ctx context.Context) ([]tools.ToolDto, error) {
+	var resp tools.AllToolsResponseDto
+	if err := b.call(ctx, http.MethodGet, "/api/v1/tools?archived=include", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tools, nil
+}
+
+func (b *remoteToolsBackend) Create(ctx context.Context, tool tools.CreateToolRequestDto) error {
+	var resp tools.CreateToolResponseDto
+	if err := b.call(ctx, http.MethodPost, "/api/v1/tools/create", tool, &resp); err != nil {
+		return err
+	}
+	for _, warning := range resp.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s looks like a credential (%s) on line %d\n", warning.Masked, warning.Rule, warning.Line)
+	}
+	return nil
+}
+
+func (b *remoteToolsBackend) Delete(ctx context.Context, toolUID string) error {
+	return b.call(ctx, http.MethodDelete, "/api/v1/tools/"+toolUID, nil, nil)
+}
+
+// call sends body as json and decodes the data of the response into out, an error response becomes an error.
+func (b *remoteToolsBackend) call(ctx context.Context, method string, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal request of %s %s", method, path)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.server+path, reader)
+	if err != nil {
+		return errors.Wrapf(err, "invalid request %s %s", method, path)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+b.accessToken)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to call %s %s", method, path)
+	}
+	defer resp.Body.Close()
+
+	var decoded remoteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return errors.Wrapf(err, "unexpected response of %s %s: http %d", method, path, resp.StatusCode)
+	}
+	if decoded.Status != "ok" {
+		return errors.Errorf("%s %s failed: %s %s", method, path, decoded.ErrorCode, decoded.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(decoded.Data, out); err != nil {
+		return errors.Wrapf(err, "unexpected data of %s %s", method, path)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"ya-tool-craft/internal/application/controller/tools"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryToolsBackend keeps the tools in memory, creating a tool with the id "broken" fails
+type memoryToolsBackend struct {
+	tools []tools.ToolDto
+}
+
+func (b *memoryToolsBackend) List(context.Context) ([]tools.ToolDto, error) {
+	return b.tools, nil
+}
+
+func (b *memoryToolsBackend) Create(_ context.Context, tool tools.CreateToolRequestDto) error {
+	if tool.ID == "broken" {
+		return errors.New("invalid tool")
+	}
+	b.tools = append(b.tools, tools.ToolDto{UID: "uid-" + tool.ID, ToolID: tool.ID, Name: tool.Name, Source: tool.Source, Category: *tool.Category})
+	return nil
+}
+
+func (b *memoryToolsBackend) Delete(_ context.Context, toolUID string) error {
+	for i, tool := range b.tools {
+		if tool.UID == toolUID {
+			b.tools = append(b.tools[:i], b.tools[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func writeToolsExport(t *testing.T, export toolsExport) string {
+	t.Helper()
+	content, err := json.Marshal(export)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "tools.json")
+	require.NoError(t, os.WriteFile(path, content, 0600))
+	return path
+}
+
+func TestImportTools(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("skips taken ids and reports failed tools", func(t *testing.T) {
+		backend := &memoryToolsBackend{tools: []tools.ToolDto{{UID: "uid-a", ToolID: "a"}}}
+		path := writeToolsExport(t, toolsExport{Version: toolsExportVersion, Tools: []tools.ToolDto{
+			{ToolID: "a", Name: "A again"},
+			{ToolID: "b", Name: "B", Source: "// b", Category: "misc"},
+			{ToolID: "broken"},
+		}})
+		var out bytes.Buffer
+
+		err := importTools(ctx, backend, path, &out)
+
+		require.ErrorContains(t, err, "1 of 3 tools failed to import")
+		assert.Equal(t, "skipped a: the id is taken\nimported b\nfailed broken: invalid tool\n", out.String())
+		require.Len(t, backend.tools, 2)
+		assert.Equal(t, tools.ToolDto{UID: "uid-b", ToolID: "b", Name: "B", Source: "// b", Category: "misc"}, backend.tools[1])
+	})
+
+	t.Run("refuses another export version", func(t *testing.T) {
+		path := writeToolsExport(t, toolsExport{Version: 2})
+
+		err := importTools(ctx, &memoryToolsBackend{}, path, &bytes.Buffer{})
+
+		require.ErrorContains(t, err, "only version 1 is supported")
+	})
+}
+
+func TestDeleteTool_Unknown(t *testing.T) {
+	err := deleteTool(context.Background(), &memoryToolsBackend{tools: []tools.ToolDto{{UID: "uid-a"}}}, "uid-b")
+
+	require.ErrorContains(t, err, "no tool with uid uid-b")
+}
+
+func TestRemoteToolsBackend(t *testing.T) {
+	ctx := context.Background()
+	var created tools.CreateToolRequestDto
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/auth/access-token" {
+			var req map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			if req["refresh_token"] != "refresh" {
+				_, _ = w.Write([]byte(`{"status":"error","error_code":"InvalidRefreshToken","message":"invalid refresh token"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"status":"ok","data":{"access_token":"access"}}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"status":"error","error_code":"InvalidAccessToken","message":"invalid access token"}`))
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/tools" && r.URL.Query().Get("archived") == "include":
+			_, _ = w.Write([]byte(`{"status":"ok","data":{"tools":[{"uid":"uid-a","tool_id":"a","is_archived":true}]}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/tools/create":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			_, _ = w.Write([]byte(`{"status":"ok","data":{"warnings":[]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := newRemoteToolsBackend(ctx, server.URL, "stolen", server.Client())
+	require.ErrorContains(t, err, "InvalidRefreshToken invalid refresh token")
+
+	backend, err := newRemoteToolsBackend(ctx, server.URL+"/", "refresh", server.Client())
+	require.NoError(t, err)
+
+	list, err := backend.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []tools.ToolDto{{UID: "uid-a", ToolID: "a", IsArchived: true}}, list)
+
+	require.NoError(t, backend.Create(ctx, createToolRequest(tools.ToolDto{ToolID: "b", Name: "B", Category: "misc"})))
+	assert.Equal(t, "b", created.ID)
+	assert.Equal(t, "misc", *created.Category)
+
+	err = backend.Delete(ctx, "uid-a")
+	require.ErrorContains(t, err, "unexpected response of DELETE /api/v1/tools/uid-a: http 404")
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const userUsage = `usage: admin user create         -username <name> [-password <password>] [-admin]
+       admin user create-admin   -username <name> [-password <password>]
+       admin user reset-password -username <name> [-password <password>] [-require-change]
+       admin user disable-2fa    -username <name> [-type <totp|webauthn>]
+       admin user list           [-q <query>] [-limit <n>] [-offset <n>]
+
+create creates a user, with the admin role with -admin, the registration setting does
+not apply. create-admin is create -admin.
+
+reset-password sets a new password and signs the user out of every device. Without
+-password a password is generated and printed, with -require-change the user has to
+change it after signing in.
+
+disable-2fa removes the 2FA method of a locked out user, every method without -type.
+The user signs in with the password alone until 2FA is enabled again.`
+
+func runUser(args []string) error {
+	commands := []string{"create", "create-admin", "reset-password", "disable-2fa", "list"}
+	if len(args) < 1 || !lo.Contains(commands, args[0]) {
+		return errors.New(userUsage)
+	}
+	command := args[0]
+
+	flags := flag.NewFlagSet("user "+command, flag.ContinueOnError)
+	username := flags.String("username", "", "username of the user")
+	password := flags.String("password", "", "password of the user, generated when empty")
+	admin := flags.Bool("admin", false, "create the user with the admin role")
+	requireChange := flags.Bool("require-change", false, "make the user change the reset password after signing in")
+	twoFAType := flags.String("type", "", "2FA method to remove, totp or webauthn, every method when empty")
+	query := flags.String("q", "", "list the users whose name or mail contains the query")
+	limit := flags.Int("limit", 50, "users listed at most, up to 200")
+	offset := flags.Int("offset", 0, "users skipped before the listed ones")
+	if err := flags.Parse(args[1:]); err != nil {
+		return errors.New(userUsage)
+	}
+	if (command != "list" && *username == "") ||
+		(*twoFAType != "" && !lo.Contains([]entity.TwoFAType{entity.TwoFATypeTOTP, entity.TwoFATypeWebAuthn}, entity.TwoFAType(*twoFAType))) {
+		return errors.New(userUsage)
+	}
+
+	var userService *service.UserService
+	var twoFAService *service.TwoFAService
+	if err := initDependencies(func(u *service.UserService, t *service.TwoFAService) {
+		userService = u
+		twoFAService = t
+	}); err != nil {
+		return err
+	}
+
+	ctx := initRequestContext()
+	switch command {
+	case "create", "create-admin":
+		return createUser(ctx, userService, *username, *password, *admin || command == "create-admin")
+	case "reset-password":
+		return resetPassword(ctx, userService, *username, *password, *requireChange)
+	case "disable-2fa":
+		return disable2FA(ctx, userService, twoFAService, *username, entity.TwoFAType(*twoFAType))
+	default:
+		return listUsers(ctx, userService, entity.UserFilter{Query: *query, Limit: *limit, Offset: *offset}, os.Stdout)
+	}
+}
+
+func createUser(ctx context.Context, userService *service.UserService, username string, password string, admin bool) error {
+	create, kind := userService.CreateUserByAdmin, "user"
+	if admin {
+		create, kind = userService.CreateAdmin, "admin"
+	}
+
+	user, password, err := create(ctx, username, password)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", kind)
+	}
+
+	fmt.Printf("%s created: username: %s userid: %s password: %s\n", kind, user.Name, user.ID, password)
+	return nil
+}
+
+func resetPassword(ctx context.Context, userService *service.UserService, username string, password string, requireChange bool) error {
+	user, err := userService.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	password, err = userService.ResetPassword(ctx, user.ID, password)
+	if err != nil {
+		return errors.Wrap(err, "failed to reset password")
+	}
+	if requireChange {
+		if err := userService.RequirePasswordChange(ctx, user.ID); err != nil {
+			return errors.Wrap(err, "failed to require password change")
+		}
+	}
+
+	fmt.Printf("password reset: username: %s userid: %s password: %s\n", user.Name, user.ID, password)
+	return nil
+}
+
+func disable2FA(ctx context.Context, userService *service.UserService, twoFAService *service.TwoFAService, username string, twoFAType entity.TwoFAType) error {
+	user, err := userService.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	types := []entity.TwoFAType{twoFAType}
+	if twoFAType == "" {
+		infos, err := twoFAService.Get2FAInfo(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if len(infos) == 0 {
+			return errors.Errorf("user %s has no 2FA method", username)
+		}
+		types = lo.Map(infos, func(info service.TwoFAInfo, _ int) entity.TwoFAType { return info.Type })
+	}
+
+	for _, t := range types {
+		if err := twoFAService.Remove2FAByAdmin(ctx, user.ID, t); err != nil {
+			return errors.Wrapf(err, "failed to remove %s of user %s", t, username)
+		}
+		fmt.Printf("2FA removed: username: %s userid: %s type: %s\n", user.Name, user.ID, t)
+	}
+	return nil
+}
+
+func listUsers(ctx context.Context, userService *service.UserService, filter entity.UserFilter, out io.Writer) error {
+	users, total, err := userService.ListUsers(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return writeUsers(out, users, total, filter.Offset)
+}
+
+func writeUsers(out io.Writer, users []entity.UserSummaryEntity, total int, offset int) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tMAIL\tROLES\tTOOLS\tCREATED\tLAST LOGIN\tSTATE")
+	for _, summary := range users {
+		user := summary.User
+		roles := lo.Map(user.Roles, func(role entity.UserRoleEntity, _ int) string { return role.RoleName })
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			user.ID, user.Name, lo.FromPtrOr(user.Mail, "-"), strings.Join(roles, ","), summary.ToolCount,
+			summary.CreatedAt.UTC().Format(time.DateTime), formatOptionalTime(user.LastLoginAt), userState(user))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "%d-%d of %d users\n", min(offset+1, total), min(offset+len(users), total), total)
+	return err
+}
+
+func userState(user entity.UserEntity) string {
+	switch {
+	case user.Disabled:
+		return "disabled"
+	case user.DeletionScheduledAt != nil:
+		return "deletion scheduled"
+	case user.PasswordChangeRequired:
+		return "password change required"
+	default:
+		return "active"
+	}
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.DateTime)
+}
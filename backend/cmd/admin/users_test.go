@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUser_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"rename"},
+		{"create"},
+		{"reset-password", "-password", "secret123"},
+		{"disable-2fa", "-username", "alice", "-type", "recovery_code"},
+		{"list", "-unknown"},
+	} {
+		err := runUser(args)
+		require.EqualError(t, err, userUsage, "%v", args)
+	}
+}
+
+func TestWriteUsers(t *testing.T) {
+	mail := "alice@example.com"
+	lastLogin := time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC)
+	users := []entity.UserSummaryEntity{
+		{
+			User: entity.UserEntity{
+				ID: "user-1", Name: "alice", Mail: &mail, LastLoginAt: &lastLogin,
+				Roles: []entity.UserRoleEntity{entity.UserRoleUser, entity.UserRoleAdmin},
+			},
+			ToolCount: 3,
+			CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			User:      entity.UserEntity{ID: "user-2", Name: "bob", Disabled: true, Roles: []entity.UserRoleEntity{entity.UserRoleUser}},
+			CreatedAt: time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC),
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, writeUsers(&out, users, 12, 10))
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 4)
+	assert.Regexp(t, `^user-1\s+alice\s+alice@example.com\s+user,admin\s+3\s+2026-03-01 12:00:00\s+2026-03-02 08:30:00\s+active$`, string(lines[1]))
+	assert.Regexp(t, `^user-2\s+bob\s+-\s+user\s+0\s+2026-03-01 13:00:00\s+-\s+disabled$`, string(lines[2]))
+	assert.Equal(t, "11-12 of 12 users", string(lines[3]))
+}
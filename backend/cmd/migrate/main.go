@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"time"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/core/logger"
 	"ya-tool-craft/internal/di"
 	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
 	"ya-tool-craft/internal/utils"
 
 	"github.com/google/uuid"
@@ -15,7 +17,18 @@ import (
 )
 
 func main() {
+	// close the database also when the migration panics, so a half applied migration is flushed
+	defer func() {
+		if err := di.Close(); err != nil {
+			fmt.Println("failed to close storage:", err)
+		}
+	}()
+
+	encryptSources := flag.Bool("encrypt-sources", false, "encrypt the stored tool sources and global scripts after the migration, needs ENCRYPT_SOURCES_AT_REST=true")
+	flag.Parse()
+
 	migrator := NewMigratorCommand()
+	migrator.encryptSources = *encryptSources
 	if err := migrator.Run(); err != nil {
 		panic(err)
 	}
@@ -23,8 +36,11 @@ func main() {
 
 // MigratorCommand encapsulates all dependencies required to run database migrations.
 type MigratorCommand struct {
-	config    config.Config
-	migration repository.IMigration
+	config           config.Config
+	migration        repository.IMigration
+	sourceEncryption *service.SourceEncryptionService
+	// encryptSources encrypts the stored sources once the migration added the columns for it
+	encryptSources bool
 }
 
 func NewMigratorCommand() *MigratorCommand {
@@ -36,9 +52,10 @@ func NewMigratorCommand() *MigratorCommand {
 func (m *MigratorCommand) init() {
 	di.InitDI()
 
-	if err := di.Container.Invoke(func(cfg config.Config, migration repository.IMigration) {
+	if err := di.Container.Invoke(func(cfg config.Config, migration repository.IMigration, sourceEncryption *service.SourceEncryptionService) {
 		m.config = cfg
 		m.migration = migration
+		m.sourceEncryption = sourceEncryption
 	}); err != nil {
 		panic(errors.Errorf("failed to initialize migrate command dependencies: %v", err))
 	}
@@ -56,6 +73,14 @@ func (m *MigratorCommand) Run() error {
 
 	logger.Info(ctx, "migration completed successfully")
 	fmt.Println("migration completed successfully")
+
+	if m.encryptSources {
+		result, err := m.sourceEncryption.EncryptStoredSources(ctx)
+		if err != nil {
+			return errors.Wrap(err, "source encryption failed")
+		}
+		fmt.Printf("encrypted %d tool sources and %d global scripts\n", result.ToolSources, result.GlobalScripts)
+	}
 	return nil
 }
 
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+	"ya-tool-craft/internal/application/controller/tools"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/di"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	// close the database also when seeding panics, so the data written so far is flushed
+	defer func() {
+		if err := di.Close(); err != nil {
+			fmt.Println("failed to close storage:", err)
+		}
+	}()
+
+	username := flag.String("username", "demo", "username of the demo user, created when missing")
+	password := flag.String("password", "", "password of a created demo user, generated when empty")
+	flag.Parse()
+
+	seeder := NewSeedCommand()
+	if err := seeder.Run(*username, *password); err != nil {
+		panic(err)
+	}
+}
+
+// SeedCommand migrates the database and fills it with a demo user, sample tools and a global script, for development
+// and demos. Running it again keeps what it seeded before and what the user changed since.
+type SeedCommand struct {
+	config           config.Config
+	migration        repository.IMigration
+	userService      *service.UserService
+	toolRepository   repository.IToolRepository
+	globalScriptRepo repository.IGlobalScriptRepository
+	cache            repository.ICache
+	meteringService  *service.MeteringService
+}
+
+func NewSeedCommand() *SeedCommand {
+	s := &SeedCommand{}
+	s.init()
+	return s
+}
+
+func (s *SeedCommand) init() {
+	di.InitDI()
+
+	if err := di.Container.Invoke(func(
+		cfg config.Config,
+		migration repository.IMigration,
+		userService *service.UserService,
+		toolRepository repository.IToolRepository,
+		globalScriptRepo repository.IGlobalScriptRepository,
+		cache repository.ICache,
+		meteringService *service.MeteringService,
+	) {
+		s.config = cfg
+		s.migration = migration
+		s.userService = userService
+		s.toolRepository = toolRepository
+		s.globalScriptRepo = globalScriptRepo
+		s.cache = cache
+		s.meteringService = meteringService
+	}); err != nil {
+		panic(errors.Errorf("failed to initialize seed command dependencies: %v", err))
+	}
+
+	logger.InitLogger(s.config)
+}
+
+func (s *SeedCommand) Run(username string, password string) error {
+	ctx := initRequestContext()
+
+	if err := s.migration.RunMigrate(ctx); err != nil {
+		return errors.Wrap(err, "migration failed")
+	}
+
+	user, err := s.seedUser(ctx, username, password)
+	if err != nil {
+		return err
+	}
+	if err := s.seedTools(ctx, user.ID); err != nil {
+		return err
+	}
+	return s.seedGlobalScript(user.ID)
+}
+
+// seedUser creates the demo user, an existing user is used as it is
+func (s *SeedCommand) seedUser(ctx context.Context, username string, password string) (entity.UserEntity, error) {
+	user, err := s.userService.GetUserByUsername(ctx, username)
+	if err == nil {
+		fmt.Printf("using existing user: username: %s userid: %s\n", user.Name, user.ID)
+		return user, nil
+	}
+
+	user, password, err = s.userService.CreateUserByAdmin(ctx, username, password)
+	if err != nil {
+		return entity.UserEntity{}, errors.Wrap(err, "failed to create demo user")
+	}
+	fmt.Printf("user created: username: %s userid: %s password: %s\n", user.Name, user.ID, password)
+	return user, nil
+}
+
+// seedTools creates the sample tools the user does not have yet
+func (s *SeedCommand) seedTools(ctx context.Context, userID entity.UserIDEntity) error {
+	created := 0
+	now := time.Now().UTC()
+	for _, sample := range sampleTools {
+		tool, err := sample.toEntity(now)
+		if err != nil {
+			return err
+		}
+		err = s.toolRepository.CreateTool(userID, tool, entity.ToolEventSourceEntity{ActorID: userID})
+		if errors.Is(err, repository.ErrToolIDTaken) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to create sample tool %s", sample.ID)
+		}
+		created++
+	}
+
+	if err := s.meteringService.RecordToolStorage(ctx, userID); err != nil {
+		logger.Errorf(ctx, "Failed to record tool storage for user %s: %v", userID, err)
+	}
+	if err := tools.DeleteToolsCache(ctx, s.cache, userID); err != nil {
+		return errors.Wrap(err, "failed to delete cached tool lists")
+	}
+
+	fmt.Printf("sample tools: %d created, %d already present\n", created, len(sampleTools)-created)
+	return nil
+}
+
+// seedGlobalScript sets the sample global script unless the user has a global script
+func (s *SeedCommand) seedGlobalScript(userID entity.UserIDEntity) error {
+	existing, err := s.globalScriptRepo.GetGlobalScript(userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get global script")
+	}
+	if existing != nil && existing.Script != "" {
+		fmt.Println("global script: kept the existing one")
+		return nil
+	}
+
+	script, err := sampleGlobalScript()
+	if err != nil {
+		return err
+	}
+	if err := s.globalScriptRepo.UpdateGlobalScript(userID, script); err != nil {
+		return errors.Wrap(err, "failed to set global script")
+	}
+	fmt.Println("global script: set")
+	return nil
+}
+
+func initRequestContext() context.Context {
+	ctx := utils.NewValueContext(context.Background())
+	ctx.Set("x-request-id", uuid.New().String())
+	ctx.Set("request-start-time", time.Now())
+	return ctx
+}
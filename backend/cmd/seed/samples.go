@@ -0,0 +1,102 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/pkg/errors"
+)
+
+// sampleNamespace groups the seeded tools, so they are told apart from the tools of the user
+const sampleNamespace = "🧪 Demo"
+
+//go:embed samples
+var samples embed.FS
+
+// sampleTool describes a seeded tool, its handler.js and uiWidgets.json are in samples/<dir>
+type sampleTool struct {
+	ID                string
+	Name              string
+	Category          string
+	Description       string
+	RealtimeExecution bool
+	dir               string
+}
+
+var sampleTools = []sampleTool{
+	{
+		ID:                "demo-text-statistics",
+		Name:              "Text Statistics",
+		Category:          "📐 Processor",
+		Description:       "Count the characters, words, lines and UTF-8 bytes of a text.",
+		RealtimeExecution: true,
+		dir:               "text-statistics",
+	},
+	{
+		ID:                "demo-slug-generator",
+		Name:              "Slug Generator",
+		Category:          "🔄 Converter",
+		Description:       "Turn a title into a url slug with the slugify function of the global script.",
+		RealtimeExecution: true,
+		dir:               "slug-generator",
+	},
+	{
+		ID:                "demo-base64-text-codec",
+		Name:              "Base64 Text Encoder/Decoder",
+		Category:          "🧬 Encoder/Decoder",
+		Description:       "Encode a UTF-8 text as base64 or decode it back.",
+		RealtimeExecution: true,
+		dir:               "base64-text-codec",
+	},
+	{
+		ID:          "demo-password-generator",
+		Name:        "Password Generator",
+		Category:    "🎲 Generator",
+		Description: "Generate a random password of the given length.",
+		dir:         "password-generator",
+	},
+	{
+		ID:                "demo-json-formatter",
+		Name:              "JSON Formatter",
+		Category:          "↔️ Format Converter",
+		Description:       "Prettify or minify a JSON document.",
+		RealtimeExecution: true,
+		dir:               "json-formatter",
+	},
+}
+
+// toEntity reads the sources of the tool, the ui widgets are checked to be a json list of rows
+func (t sampleTool) toEntity(now time.Time) (entity.ToolEntity, error) {
+	source, err := samples.ReadFile(path.Join("samples", t.dir, "handler.js"))
+	if err != nil {
+		return entity.ToolEntity{}, errors.Wrapf(err, "failed to read source of sample tool %s", t.ID)
+	}
+	uiWidgets, err := samples.ReadFile(path.Join("samples", t.dir, "uiWidgets.json"))
+	if err != nil {
+		return entity.ToolEntity{}, errors.Wrapf(err, "failed to read ui widgets of sample tool %s", t.ID)
+	}
+	var rows [][]map[string]any
+	if err := json.Unmarshal(uiWidgets, &rows); err != nil {
+		return entity.ToolEntity{}, errors.Wrapf(err, "invalid ui widgets of sample tool %s", t.ID)
+	}
+
+	return entity.NewToolEntityWithoutUID(
+		t.ID, t.Name, sampleNamespace, t.Category,
+		true, t.RealtimeExecution,
+		string(uiWidgets), string(source),
+		t.Description,
+		map[string]string{},
+		now, now,
+	), nil
+}
+
+func sampleGlobalScript() (string, error) {
+	script, err := samples.ReadFile("samples/global_script.js")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read sample global script")
+	}
+	return string(script), nil
+}
@@ -0,0 +1,16 @@
+/**
+ * @param {InputUIWidgets} inputWidgets When tool is executed, this object contains all the input widget values.
+ * @param {ChangedUIWidget} changedWidgetIds When tool is executed, this string value tells you which input widget triggered the execution.
+ * @returns {Promise<HandlerReturnWidgets>}
+ */
+async function handler(inputWidgets, changedWidgetIds) {
+  const text = inputWidgets["input-text"] || "";
+
+  if (inputWidgets["decode"]) {
+    const bytes = Uint8Array.from(atob(text.trim()), (c) => c.charCodeAt(0));
+    return { "output-result": new TextDecoder().decode(bytes) };
+  }
+
+  const bytes = new TextEncoder().encode(text);
+  return { "output-result": btoa(String.fromCharCode(...bytes)) };
+}
@@ -0,0 +1,19 @@
+/**
+ * The global script is concatenated before the handler of every tool, the functions
+ * defined here can be called from any handler.
+ */
+
+// sleep waits for ms milliseconds: 'await sleep(1000);'
+async function sleep(ms) {
+  return new Promise((resolve) => setTimeout(resolve, ms));
+}
+
+// slugify turns a text into a lowercase, dash separated slug usable in urls
+function slugify(text) {
+  return text
+    .normalize("NFKD")
+    .replace(/[\u0300-\u036f]/g, "")
+    .toLowerCase()
+    .replace(/[^a-z0-9]+/g, "-")
+    .replace(/^-+|-+$/g, "");
+}
@@ -0,0 +1,16 @@
+/**
+ * @param {InputUIWidgets} inputWidgets When tool is executed, this object contains all the input widget values.
+ * @param {ChangedUIWidget} changedWidgetIds When tool is executed, this string value tells you which input widget triggered the execution.
+ * @returns {Promise<HandlerReturnWidgets>}
+ */
+async function handler(inputWidgets, changedWidgetIds) {
+  const input = inputWidgets["input-json"] || "";
+  if (!input.trim()) {
+    return { "output-json": "" };
+  }
+
+  const indent = inputWidgets["minify"] ? 0 : 2;
+  return {
+    "output-json": JSON.stringify(JSON.parse(input), null, indent),
+  };
+}
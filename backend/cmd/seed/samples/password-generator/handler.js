@@ -0,0 +1,20 @@
+/**
+ * @param {InputUIWidgets} inputWidgets When tool is executed, this object contains all the input widget values.
+ * @param {ChangedUIWidget} changedWidgetIds When tool is executed, this string value tells you which input widget triggered the execution.
+ * @returns {Promise<HandlerReturnWidgets>}
+ */
+async function handler(inputWidgets, changedWidgetIds) {
+  const length = Number(inputWidgets["length"]) || 16;
+  let alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789";
+  if (inputWidgets["symbols"]) {
+    alphabet += "!@#$%^&*()-_=+[]{}";
+  }
+
+  const random = new Uint32Array(length);
+  crypto.getRandomValues(random);
+  const password = Array.from(random, (n) => alphabet[n % alphabet.length]).join("");
+
+  return {
+    "out-password": password,
+  };
+}
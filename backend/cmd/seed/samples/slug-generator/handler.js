@@ -0,0 +1,15 @@
+/**
+ * slugify is defined in the global script.
+ *
+ * @param {InputUIWidgets} inputWidgets When tool is executed, this object contains all the input widget values.
+ * @param {ChangedUIWidget} changedWidgetIds When tool is executed, this string value tells you which input widget triggered the execution.
+ * @returns {Promise<HandlerReturnWidgets>}
+ */
+async function handler(inputWidgets, changedWidgetIds) {
+  const title = inputWidgets["input-title"] || "";
+  const separator = inputWidgets["separator"] || "-";
+
+  return {
+    "out-slug": slugify(title).replace(/-/g, separator),
+  };
+}
@@ -0,0 +1,17 @@
+/**
+ * @param {InputUIWidgets} inputWidgets When tool is executed, this object contains all the input widget values.
+ * @param {ChangedUIWidget} changedWidgetIds When tool is executed, this string value tells you which input widget triggered the execution.
+ * @returns {Promise<HandlerReturnWidgets>}
+ */
+async function handler(inputWidgets, changedWidgetIds) {
+  const text = inputWidgets["input-text"] || "";
+  const words = text.trim() ? text.trim().split(/\s+/) : [];
+  const lines = text ? text.split(/\r?\n/) : [];
+
+  return {
+    "out-characters": String([...text].length),
+    "out-words"     : String(words.length),
+    "out-lines"     : String(lines.length),
+    "out-bytes"     : String(new TextEncoder().encode(text).length),
+  };
+}
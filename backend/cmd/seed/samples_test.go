@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleTools(t *testing.T) {
+	ids := map[string]bool{}
+	for _, sample := range sampleTools {
+		tool, err := sample.toEntity(time.Now())
+		require.NoError(t, err, sample.ID)
+
+		assert.False(t, ids[tool.ID], "duplicate sample tool id %s", tool.ID)
+		ids[tool.ID] = true
+		assert.Equal(t, sampleNamespace, tool.Namespace)
+		assert.NotEmpty(t, tool.Category, sample.ID)
+		assert.Contains(t, tool.Source, "async function handler(", sample.ID)
+		assert.True(t, strings.HasPrefix(tool.UniqueID, "tool-"), sample.ID)
+	}
+
+	script, err := sampleGlobalScript()
+	require.NoError(t, err)
+	// the slug generator calls slugify from the global script
+	assert.Contains(t, script, "function slugify(")
+}
@@ -0,0 +1,142 @@
+package admin
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminAccountRecoveryController(
+	accountRecoveryService *service.AccountRecoveryService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return AdminAccountRecoveryController{
+		accountRecoveryService:     accountRecoveryService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+// AdminAccountRecoveryController reviews the recovery requests of locked-out users.
+// Every action needs the users:reset_credentials permission and is recorded by the audit log.
+type AdminAccountRecoveryController struct {
+	common.JsonResponse
+
+	accountRecoveryService     *service.AccountRecoveryService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c AdminAccountRecoveryController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/admin/recovery-requests", Handler: c.List, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/admin/recovery-requests/:request_id/approve", Handler: c.Approve},
+		{Method: http.MethodPost, Path: "/api/v1/admin/recovery-requests/:request_id/reject", Handler: c.Reject},
+	}
+}
+
+// @Summary		List account recovery requests
+// @Description	List the account recovery requests newest first, optionally only those with a status. Requires the users:reset_credentials permission.
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			status			query		string	false	"Request status"	Enums(pending, approved, rejected, cancelled, completed)
+// @Success		200				{object}	swagger.BaseSuccessResponse[AdminAccountRecoveryRequestsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/recovery-requests [get]
+func (c *AdminAccountRecoveryController) List(ctx *gin.Context) {
+	logger.Infof(ctx, "List account recovery requests requested")
+
+	if _, err := c.accessTokenHeaderValidator.ValidatePermissionAccessTokenHeader(ctx, entity.UserPermissionResetCredentials); err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	status := entity.AccountRecoveryStatus(ctx.Query("status"))
+	switch status {
+	case "", entity.AccountRecoveryStatusPending, entity.AccountRecoveryStatusApproved, entity.AccountRecoveryStatusRejected,
+		entity.AccountRecoveryStatusCancelled, entity.AccountRecoveryStatusCompleted:
+	default:
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "unknown account recovery status %s", status))
+		return
+	}
+
+	requests, err := c.accountRecoveryService.ListRequests(ctx, status)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list account recovery requests: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp AdminAccountRecoveryRequestsResponseDto
+	resp.FromEntity(requests)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Approve an account recovery request
+// @Description	Let the requester complete the recovery once its delay is over, the account is notified by email and can still cancel it.
+// @Description	An admin can not approve the recovery of the own account. Requires the users:reset_credentials permission.
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			request_id		path		string	true	"Account recovery request ID"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/recovery-requests/{request_id}/approve [post]
+func (c *AdminAccountRecoveryController) Approve(ctx *gin.Context) {
+	logger.Infof(ctx, "Approve account recovery request requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidatePermissionAccessTokenHeader(ctx, entity.UserPermissionResetCredentials)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	requestID := ctx.Param("request_id")
+	if err := c.accountRecoveryService.ApproveRequest(ctx, admin.ID, requestID); err != nil {
+		logger.Errorf(ctx, "Failed to approve account recovery request %s: %v", requestID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "Account recovery request approved successfully", gin.H{})
+}
+
+// @Summary		Reject an account recovery request
+// @Description	Close the recovery request for good, the account is notified by email. Requires the users:reset_credentials permission.
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			request_id		path		string	true	"Account recovery request ID"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/recovery-requests/{request_id}/reject [post]
+func (c *AdminAccountRecoveryController) Reject(ctx *gin.Context) {
+	logger.Infof(ctx, "Reject account recovery request requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidatePermissionAccessTokenHeader(ctx, entity.UserPermissionResetCredentials)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	requestID := ctx.Param("request_id")
+	if err := c.accountRecoveryService.RejectRequest(ctx, admin.ID, requestID); err != nil {
+		logger.Errorf(ctx, "Failed to reject account recovery request %s: %v", requestID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "Account recovery request rejected successfully", gin.H{})
+}
@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+type AdminAccountRecoveryRequestDto struct {
+	ID          string    `json:"id" example:"4f9c2f0e-3a5b-4f7e-9a55-2b0c5e1d7c11"`
+	UserID      string    `json:"user_id" example:"user-1"`
+	Status      string    `json:"status" example:"pending" enums:"pending,approved,rejected,cancelled,completed"`
+	CreatedAt   time.Time `json:"created_at" format:"date-time"`
+	AvailableAt time.Time `json:"available_at" format:"date-time"`
+	// DecidedBy is the admin who approved or rejected the request, or the user who cancelled it
+	DecidedBy *string   `json:"decided_by" example:"admin-1"`
+	UpdatedAt time.Time `json:"updated_at" format:"date-time"`
+}
+
+func (dto *AdminAccountRecoveryRequestDto) FromEntity(request entity.AccountRecoveryRequestEntity) {
+	dto.ID = request.ID
+	dto.UserID = string(request.UserID)
+	dto.Status = string(request.Status)
+	dto.CreatedAt = request.CreatedAt
+	dto.AvailableAt = request.AvailableAt
+	if request.DecidedBy != nil {
+		decidedBy := string(*request.DecidedBy)
+		dto.DecidedBy = &decidedBy
+	}
+	dto.UpdatedAt = request.UpdatedAt
+}
+
+type AdminAccountRecoveryRequestsResponseDto struct {
+	Requests []AdminAccountRecoveryRequestDto `json:"requests"`
+}
+
+func (dto *AdminAccountRecoveryRequestsResponseDto) FromEntity(requests []entity.AccountRecoveryRequestEntity) {
+	dto.Requests = make([]AdminAccountRecoveryRequestDto, 0, len(requests))
+	for _, request := range requests {
+		var item AdminAccountRecoveryRequestDto
+		item.FromEntity(request)
+		dto.Requests = append(dto.Requests, item)
+	}
+}
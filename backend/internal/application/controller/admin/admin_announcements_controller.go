@@ -0,0 +1,194 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminAnnouncementsController(
+	announcementService *service.AnnouncementService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return AdminAnnouncementsController{
+		announcementService:        announcementService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type AdminAnnouncementsController struct {
+	common.JsonResponse
+
+	announcementService        *service.AnnouncementService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c AdminAnnouncementsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/admin/announcements", Handler: c.AllAnnouncements, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/admin/announcements", Handler: c.CreateAnnouncement},
+		{Method: http.MethodPut, Path: "/api/v1/admin/announcements/:id", Handler: c.UpdateAnnouncement},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/announcements/:id", Handler: c.DeleteAnnouncement},
+	}
+}
+
+// @Summary		List announcements
+// @Description	List every announcement including scheduled and expired ones
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AllAnnouncementsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/announcements [get]
+func (c *AdminAnnouncementsController) AllAnnouncements(ctx *gin.Context) {
+	logger.Infof(ctx, "List announcements requested")
+
+	if _, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx); err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	announcements, err := c.announcementService.AllAnnouncements(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get announcements: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected get announcements error"))
+		return
+	}
+
+	var resp AllAnnouncementsResponseDto
+	resp.FromEntity(announcements, time.Now())
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Create announcement
+// @Description	Create an announcement banner, it is shown between starts_at and ends_at, omitted bounds are open
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token of an admin"
+// @Param			request			body		SaveAnnouncementRequestDto	true	"Announcement"
+// @Success		200				{object}	swagger.BaseSuccessResponse[CreateAnnouncementResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/announcements [post]
+func (c *AdminAnnouncementsController) CreateAnnouncement(ctx *gin.Context) {
+	logger.Infof(ctx, "Create announcement requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req SaveAnnouncementRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	announcement, err := c.announcementService.CreateAnnouncement(
+		ctx,
+		admin.ID,
+		req.Message,
+		entity.AnnouncementSeverity(req.Severity),
+		req.StartsAt,
+		req.EndsAt,
+		*req.Dismissible,
+	)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to create announcement: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Announcement %s created by %s", announcement.ID, admin.ID)
+	c.Success(ctx, "Announcement created successfully", CreateAnnouncementResponseDto{ID: announcement.ID})
+}
+
+// @Summary		Update announcement
+// @Description	Replace the content and schedule of an announcement
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token of an admin"
+// @Param			id				path		string						true	"Announcement id"
+// @Param			request			body		SaveAnnouncementRequestDto	true	"Announcement"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UpdateAnnouncementResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/announcements/{id} [put]
+func (c *AdminAnnouncementsController) UpdateAnnouncement(ctx *gin.Context) {
+	logger.Infof(ctx, "Update announcement requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req SaveAnnouncementRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	id := ctx.Param("id")
+	if err := c.announcementService.UpdateAnnouncement(
+		ctx,
+		id,
+		req.Message,
+		entity.AnnouncementSeverity(req.Severity),
+		req.StartsAt,
+		req.EndsAt,
+		*req.Dismissible,
+	); err != nil {
+		logger.Errorf(ctx, "Failed to update announcement %s: %v", id, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Announcement %s updated by %s", id, admin.ID)
+	c.Success(ctx, "Announcement updated successfully", UpdateAnnouncementResponseDto{})
+}
+
+// @Summary		Delete announcement
+// @Description	Delete an announcement
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Param			id				path		string	true	"Announcement id"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UpdateAnnouncementResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/announcements/{id} [delete]
+func (c *AdminAnnouncementsController) DeleteAnnouncement(ctx *gin.Context) {
+	logger.Infof(ctx, "Delete announcement requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	id := ctx.Param("id")
+	if err := c.announcementService.DeleteAnnouncement(ctx, id); err != nil {
+		logger.Errorf(ctx, "Failed to delete announcement %s: %v", id, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Announcement %s deleted by %s", id, admin.ID)
+	c.Success(ctx, "Announcement deleted successfully", UpdateAnnouncementResponseDto{})
+}
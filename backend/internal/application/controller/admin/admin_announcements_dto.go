@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type AdminAnnouncementDto struct {
+	ID          string     `json:"id" example:"ann-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	Message     string     `json:"message" example:"Scheduled maintenance tonight at 22:00 UTC"`
+	Severity    string     `json:"severity" enums:"info,warning,critical" example:"warning"`
+	StartsAt    *time.Time `json:"starts_at" example:"2024-01-01T00:00:00Z"`
+	EndsAt      *time.Time `json:"ends_at" example:"2024-01-02T00:00:00Z"`
+	Dismissible bool       `json:"dismissible" example:"true"`
+	Active      bool       `json:"active" example:"true"`
+	CreatedBy   string     `json:"created_by" example:"user-xxxx"`
+	CreatedAt   time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   time.Time  `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+func (dto *AdminAnnouncementDto) FromEntity(announcement entity.AnnouncementEntity, now time.Time) {
+	dto.ID = announcement.ID
+	dto.Message = announcement.Message
+	dto.Severity = string(announcement.Severity)
+	dto.StartsAt = announcement.StartsAt
+	dto.EndsAt = announcement.EndsAt
+	dto.Dismissible = announcement.Dismissible
+	dto.Active = announcement.IsActiveAt(now)
+	dto.CreatedBy = string(announcement.CreatedBy)
+	dto.CreatedAt = announcement.CreatedAt
+	dto.UpdatedAt = announcement.UpdatedAt
+}
+
+type AllAnnouncementsResponseDto struct {
+	Announcements []AdminAnnouncementDto `json:"announcements"`
+}
+
+func (dto *AllAnnouncementsResponseDto) FromEntity(announcements []entity.AnnouncementEntity, now time.Time) {
+	dto.Announcements = lo.Map(announcements, func(announcement entity.AnnouncementEntity, _ int) AdminAnnouncementDto {
+		item := AdminAnnouncementDto{}
+		item.FromEntity(announcement, now)
+		return item
+	})
+}
+
+type SaveAnnouncementRequestDto struct {
+	Message     string     `json:"message" binding:"required,max=2000" example:"Scheduled maintenance tonight at 22:00 UTC"`
+	Severity    string     `json:"severity" binding:"required,oneof=info warning critical" example:"warning"`
+	StartsAt    *time.Time `json:"starts_at" example:"2024-01-01T00:00:00Z"`
+	EndsAt      *time.Time `json:"ends_at" example:"2024-01-02T00:00:00Z"`
+	Dismissible *bool      `json:"dismissible" binding:"required" example:"true"`
+}
+
+type CreateAnnouncementResponseDto struct {
+	ID string `json:"id" example:"ann-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+}
+
+type UpdateAnnouncementResponseDto struct{}
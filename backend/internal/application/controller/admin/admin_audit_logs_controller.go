@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminAuditLogsController(
+	auditLogService *service.AuditLogService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return AdminAuditLogsController{
+		auditLogService:            auditLogService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+// AdminAuditLogsController exposes the audit records, they are written by the audit middleware of every admin route.
+type AdminAuditLogsController struct {
+	common.JsonResponse
+
+	auditLogService            *service.AuditLogService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c AdminAuditLogsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/admin/audit-logs", Handler: c.AuditLogs, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		List audit logs
+// @Description	List the recorded admin actions, newest first. Every state changing admin request is recorded with actor, route, a redacted payload summary and outcome
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Param			actor_id		query		string	false	"Only records of this user"
+// @Param			outcome			query		string	false	"Only records with this outcome"	Enums(success, failure)
+// @Param			limit			query		int		false	"Page size"							default(50)	maximum(200)
+// @Param			offset			query		int		false	"Records to skip"					default(0)
+// @Success		200				{object}	swagger.BaseSuccessResponse[AuditLogsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/audit-logs [get]
+func (c *AdminAuditLogsController) AuditLogs(ctx *gin.Context) {
+	logger.Infof(ctx, "List audit logs requested")
+
+	if _, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx); err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req AuditLogsRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	auditLogs, total, err := c.auditLogService.List(ctx, req.ToEntity())
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list audit logs: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected list audit logs error"))
+		return
+	}
+
+	var resp AuditLogsResponseDto
+	resp.FromEntity(auditLogs, total)
+	c.Success(ctx, "", resp)
+}
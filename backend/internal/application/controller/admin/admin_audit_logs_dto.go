@@ -0,0 +1,66 @@
+package admin
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type AuditLogsRequestDto struct {
+	ActorID string `form:"actor_id" binding:"omitempty,max=255" example:"user-xxxx"`
+	Outcome string `form:"outcome" binding:"omitempty,oneof=success failure alert" example:"failure"`
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=200" example:"50"`
+	Offset  int    `form:"offset" binding:"omitempty,min=0" example:"0"`
+}
+
+func (dto AuditLogsRequestDto) ToEntity() entity.AuditLogFilter {
+	return entity.AuditLogFilter{
+		ActorID: entity.UserIDEntity(dto.ActorID),
+		Outcome: entity.AuditOutcome(dto.Outcome),
+		Limit:   dto.Limit,
+		Offset:  dto.Offset,
+	}
+}
+
+type AuditLogDto struct {
+	ID        string    `json:"id" example:"audit-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	ActorID   string    `json:"actor_id" example:"user-xxxx"`
+	Method    string    `json:"method" example:"PUT"`
+	Route     string    `json:"route" example:"/api/v1/admin/settings/:key"`
+	Path      string    `json:"path" example:"/api/v1/admin/settings/registration.enabled"`
+	Payload   string    `json:"payload" example:"{\"value\":\"false\"}"`
+	Status    int       `json:"status" example:"200"`
+	Outcome   string    `json:"outcome" enums:"success,failure,alert" example:"success"`
+	RequestID string    `json:"request_id" example:"xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	ClientIP  string    `json:"client_ip" example:"127.0.0.1"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+func (dto *AuditLogDto) FromEntity(auditLog entity.AuditLogEntity) {
+	dto.ID = auditLog.ID
+	dto.ActorID = string(auditLog.ActorID)
+	dto.Method = auditLog.Method
+	dto.Route = auditLog.Route
+	dto.Path = auditLog.Path
+	dto.Payload = auditLog.Payload
+	dto.Status = auditLog.Status
+	dto.Outcome = string(auditLog.Outcome)
+	dto.RequestID = auditLog.RequestID
+	dto.ClientIP = auditLog.ClientIP
+	dto.CreatedAt = auditLog.CreatedAt
+}
+
+type AuditLogsResponseDto struct {
+	AuditLogs []AuditLogDto `json:"audit_logs"`
+	Total     int           `json:"total" example:"1"`
+}
+
+func (dto *AuditLogsResponseDto) FromEntity(auditLogs []entity.AuditLogEntity, total int) {
+	dto.AuditLogs = lo.Map(auditLogs, func(auditLog entity.AuditLogEntity, _ int) AuditLogDto {
+		item := AuditLogDto{}
+		item.FromEntity(auditLog)
+		return item
+	})
+	dto.Total = total
+}
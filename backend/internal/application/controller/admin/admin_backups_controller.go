@@ -0,0 +1,165 @@
+package admin
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminBackupsController(
+	backupService *service.BackupService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return AdminBackupsController{
+		backupService:              backupService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type AdminBackupsController struct {
+	common.JsonResponse
+
+	backupService              *service.BackupService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c AdminBackupsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/admin/backups", Handler: c.AllBackups, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/admin/backups", Handler: c.CreateBackup},
+		{Method: http.MethodPost, Path: "/api/v1/admin/backups/:name/restore", Handler: c.RestoreBackup},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/backups/pending-restore", Handler: c.CancelRestore},
+	}
+}
+
+// @Summary		List backups
+// @Description	List the database backups in BACKUP_DIR newest first, and the backup staged for restore
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AllBackupsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/backups [get]
+func (c *AdminBackupsController) AllBackups(ctx *gin.Context) {
+	logger.Infof(ctx, "List backups requested")
+
+	if _, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx); err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	backups, pendingRestore, err := c.backupService.ListBackups(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list backups: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp AllBackupsResponseDto
+	resp.FromEntity(backups, pendingRestore)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Create backup
+// @Description	Back up the sqlite database and the nutsdb store now, the backups beyond BACKUP_RETENTION are dropped
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Success		200				{object}	swagger.BaseSuccessResponse[CreateBackupResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/backups [post]
+func (c *AdminBackupsController) CreateBackup(ctx *gin.Context) {
+	logger.Infof(ctx, "Create backup requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	backup, err := c.backupService.CreateBackup(ctx)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to create backup: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Backup %s created by %s", backup.Name, admin.ID)
+	var resp CreateBackupResponseDto
+	resp.Backup.FromEntity(backup)
+	c.Success(ctx, "Backup created", resp)
+}
+
+// @Summary		Restore backup
+// @Description	Stage the backup to be restored on the next start of the server, the changes made until then are lost.
+// @Description	Staging another backup replaces the staged one.
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Param			name			path		string	true	"Backup name"
+// @Success		200				{object}	swagger.BaseSuccessResponse[RestoreBackupResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/backups/{name}/restore [post]
+func (c *AdminBackupsController) RestoreBackup(ctx *gin.Context) {
+	logger.Infof(ctx, "Restore backup requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	name := ctx.Param("name")
+	if err := c.backupService.StageRestore(ctx, name); err != nil {
+		logger.Warnf(ctx, "Failed to stage restore of backup %s: %v", name, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Restore of backup %s staged by %s", name, admin.ID)
+	c.Success(ctx, "Backup is restored on the next start", RestoreBackupResponseDto{})
+}
+
+// @Summary		Cancel restore
+// @Description	Drop the staged restore, the server starts with the current stores
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Success		200				{object}	swagger.BaseSuccessResponse[CancelRestoreResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/backups/pending-restore [delete]
+func (c *AdminBackupsController) CancelRestore(ctx *gin.Context) {
+	logger.Infof(ctx, "Cancel restore requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	cancelled, err := c.backupService.CancelRestore(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to cancel restore: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+	if !cancelled {
+		c.Success(ctx, "No restore was staged", CancelRestoreResponseDto{})
+		return
+	}
+
+	logger.Infof(ctx, "Staged restore cancelled by %s", admin.ID)
+	c.Success(ctx, "Restore cancelled", CancelRestoreResponseDto{})
+}
@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type BackupDto struct {
+	Name      string    `json:"name" example:"toolbake-backup-20260102T030000Z.tar.gz"`
+	Size      int64     `json:"size" example:"1048576"`
+	CreatedAt time.Time `json:"created_at" format:"date-time"`
+	// Components are the stores in the backup, "rds" for the sqlite database and "key_value" for the nutsdb store
+	Components []string `json:"components" example:"rds,key_value"`
+}
+
+func (dto *BackupDto) FromEntity(backup entity.BackupEntity) {
+	dto.Name = backup.Name
+	dto.Size = backup.Size
+	dto.CreatedAt = backup.CreatedAt
+	dto.Components = backup.Components
+	if dto.Components == nil {
+		dto.Components = []string{}
+	}
+}
+
+type AllBackupsResponseDto struct {
+	Backups []BackupDto `json:"backups"`
+	// PendingRestore is the backup restored on the next start, null when none is staged
+	PendingRestore *string `json:"pending_restore" example:"toolbake-backup-20260102T030000Z.tar.gz"`
+}
+
+func (dto *AllBackupsResponseDto) FromEntity(backups []entity.BackupEntity, pendingRestore *string) {
+	dto.Backups = lo.Map(backups, func(backup entity.BackupEntity, _ int) BackupDto {
+		var backupDto BackupDto
+		backupDto.FromEntity(backup)
+		return backupDto
+	})
+	dto.PendingRestore = pendingRestore
+}
+
+type CreateBackupResponseDto struct {
+	Backup BackupDto `json:"backup"`
+}
+
+type RestoreBackupResponseDto struct{}
+
+type CancelRestoreResponseDto struct{}
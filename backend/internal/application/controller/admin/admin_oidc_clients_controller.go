@@ -0,0 +1,141 @@
+package admin
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminOidcClientsController(
+	oidcService *service.OidcService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return AdminOidcClientsController{
+		oidcService:                oidcService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type AdminOidcClientsController struct {
+	common.JsonResponse
+
+	oidcService                *service.OidcService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c AdminOidcClientsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/admin/oidc-clients", Handler: c.AllClients, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/admin/oidc-clients", Handler: c.CreateClient},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/oidc-clients/:client_id", Handler: c.DeleteClient},
+	}
+}
+
+// @Summary		List OIDC clients
+// @Description	List the apps registered to sign their users in with ToolBake, newest first
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AllOidcClientsResponseDto]
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/oidc-clients [get]
+func (c *AdminOidcClientsController) AllClients(ctx *gin.Context) {
+	logger.Infof(ctx, "List OIDC clients requested")
+
+	if _, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx); err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	clients, err := c.oidcService.ListClients(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list OIDC clients: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp AllOidcClientsResponseDto
+	resp.FromEntity(clients)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Register OIDC client
+// @Description	Register an app to sign its users in with ToolBake. The client secret is returned only once.
+// @Description	The app receives ToolBake access tokens of the users, register trusted apps only.
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token of an admin"
+// @Param			request			body		CreateOidcClientRequestDto	true	"Client name and redirect URIs"
+// @Success		200				{object}	swagger.BaseSuccessResponse[CreateOidcClientResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/oidc-clients [post]
+func (c *AdminOidcClientsController) CreateClient(ctx *gin.Context) {
+	logger.Infof(ctx, "Register OIDC client requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req CreateOidcClientRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Failed to bind request: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	oidcClient, secret, err := c.oidcService.RegisterClient(ctx, req.Name, req.RedirectURIs, admin.ID)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to register OIDC client: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp CreateOidcClientResponseDto
+	resp.Client.FromEntity(oidcClient)
+	resp.ClientSecret = secret
+	ctx.Header("Cache-Control", "no-store")
+	c.Success(ctx, "OIDC client registered", resp)
+}
+
+// @Summary		Delete OIDC client
+// @Description	Delete the client, it can not sign users in anymore. The tokens it was issued stay valid until they expire.
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Param			client_id		path		string	true	"Client ID"
+// @Success		200				{object}	swagger.BaseSuccessResponse[DeleteOidcClientResponseDto]
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/oidc-clients/{client_id} [delete]
+func (c *AdminOidcClientsController) DeleteClient(ctx *gin.Context) {
+	logger.Infof(ctx, "Delete OIDC client requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	clientID := ctx.Param("client_id")
+	if err := c.oidcService.DeleteClient(ctx, clientID); err != nil {
+		logger.Warnf(ctx, "Failed to delete OIDC client %s: %v", clientID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "OIDC client %s deleted by %s", clientID, admin.ID)
+	c.Success(ctx, "OIDC client deleted", DeleteOidcClientResponseDto{})
+}
@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type OidcClientDto struct {
+	ClientID     string    `json:"client_id" example:"3c1d1f8e-7a5b-4c1d-9f0e-2b7a6c5d4e3f"`
+	Name         string    `json:"name" example:"Wiki"`
+	RedirectURIs []string  `json:"redirect_uris" example:"https://wiki.example.com/oauth/callback"`
+	CreatedBy    string    `json:"created_by" example:"u-admin"`
+	CreatedAt    time.Time `json:"created_at" format:"date-time"`
+}
+
+func (dto *OidcClientDto) FromEntity(oidcClient entity.OidcClientEntity) {
+	dto.ClientID = oidcClient.ID
+	dto.Name = oidcClient.Name
+	dto.RedirectURIs = oidcClient.RedirectURIs
+	dto.CreatedBy = string(oidcClient.CreatedBy)
+	dto.CreatedAt = oidcClient.CreatedAt
+}
+
+type AllOidcClientsResponseDto struct {
+	Clients []OidcClientDto `json:"clients"`
+}
+
+func (dto *AllOidcClientsResponseDto) FromEntity(clients []entity.OidcClientEntity) {
+	dto.Clients = lo.Map(clients, func(oidcClient entity.OidcClientEntity, _ int) OidcClientDto {
+		var clientDto OidcClientDto
+		clientDto.FromEntity(oidcClient)
+		return clientDto
+	})
+}
+
+type CreateOidcClientRequestDto struct {
+	Name string `json:"name" binding:"required,max=255" example:"Wiki"`
+	// RedirectURIs are compared exactly with the redirect_uri of the authorization requests
+	RedirectURIs []string `json:"redirect_uris" binding:"required,min=1,max=16,dive,required,max=2048" example:"https://wiki.example.com/oauth/callback"`
+}
+
+type CreateOidcClientResponseDto struct {
+	Client OidcClientDto `json:"client"`
+	// ClientSecret is returned only once, only its hash is stored
+	ClientSecret string `json:"client_secret" example:"b2JqZWN0LXNlY3JldC1leGFtcGxl"`
+}
+
+type DeleteOidcClientResponseDto struct{}
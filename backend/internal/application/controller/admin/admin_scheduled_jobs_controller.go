@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/core/scheduler"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminScheduledJobsController(
+	scheduler *scheduler.Scheduler,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return AdminScheduledJobsController{
+		scheduler:                  scheduler,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type AdminScheduledJobsController struct {
+	common.JsonResponse
+
+	scheduler                  *scheduler.Scheduler
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c AdminScheduledJobsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/admin/jobs", Handler: c.AllJobs, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/admin/jobs/:name/run", Handler: c.RunJob},
+	}
+}
+
+// @Summary		List scheduled jobs
+// @Description	List the housekeeping jobs with their schedule, next run and the last run in this server instance
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AllScheduledJobsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/jobs [get]
+func (c *AdminScheduledJobsController) AllJobs(ctx *gin.Context) {
+	logger.Infof(ctx, "List scheduled jobs requested")
+
+	if _, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx); err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp AllScheduledJobsResponseDto
+	resp.FromEntity(c.scheduler.Jobs())
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Run scheduled job
+// @Description	Start a run of a housekeeping job now, in the background and next to its schedule
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Param			name			path		string	true	"Job name"
+// @Success		200				{object}	swagger.BaseSuccessResponse[RunScheduledJobResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/jobs/{name}/run [post]
+func (c *AdminScheduledJobsController) RunJob(ctx *gin.Context) {
+	logger.Infof(ctx, "Run scheduled job requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	name := ctx.Param("name")
+	if err := c.scheduler.Trigger(ctx, name); err != nil {
+		logger.Warnf(ctx, "Failed to run scheduled job %s: %v", name, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Scheduled job %s started by %s", name, admin.ID)
+	c.Success(ctx, "Scheduled job started", RunScheduledJobResponseDto{})
+}
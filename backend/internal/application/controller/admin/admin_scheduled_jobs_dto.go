@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type ScheduledJobRunDto struct {
+	// Trigger is "schedule" or "manual"
+	Trigger    string    `json:"trigger" example:"schedule"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms" example:"120"`
+	// Status is "success" or "failed"
+	Status string `json:"status" example:"success"`
+	Error  string `json:"error" example:""`
+}
+
+type ScheduledJobDto struct {
+	Name        string `json:"name" example:"refresh_token_cleanup"`
+	Description string `json:"description" example:"Drops expired refresh tokens from the token index of every user"`
+	// Schedule is the cron expression in use, empty when the job is disabled
+	Schedule      string              `json:"schedule" example:"0 4 * * *"`
+	ScheduleError string              `json:"schedule_error" example:""`
+	Running       bool                `json:"running" example:"false"`
+	NextRunAt     *time.Time          `json:"next_run_at"`
+	LastRun       *ScheduledJobRunDto `json:"last_run"`
+}
+
+type AllScheduledJobsResponseDto struct {
+	Jobs []ScheduledJobDto `json:"jobs"`
+}
+
+func (dto *AllScheduledJobsResponseDto) FromEntity(jobs []entity.ScheduledJobEntity) {
+	dto.Jobs = lo.Map(jobs, func(job entity.ScheduledJobEntity, _ int) ScheduledJobDto {
+		jobDto := ScheduledJobDto{
+			Name:          job.Name,
+			Description:   job.Description,
+			Schedule:      job.Schedule,
+			ScheduleError: job.ScheduleError,
+			Running:       job.Running,
+			NextRunAt:     job.NextRunAt,
+		}
+		if job.LastRun != nil {
+			jobDto.LastRun = &ScheduledJobRunDto{
+				Trigger:    string(job.LastRun.Trigger),
+				StartedAt:  job.LastRun.StartedAt,
+				DurationMs: job.LastRun.Duration.Milliseconds(),
+				Status:     string(job.LastRun.Status),
+				Error:      job.LastRun.Error,
+			}
+		}
+		return jobDto
+	})
+}
+
+type RunScheduledJobResponseDto struct{}
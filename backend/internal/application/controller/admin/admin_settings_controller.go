@@ -0,0 +1,138 @@
+package admin
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminSettingsController(
+	settingsService *service.SystemSettingsService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return AdminSettingsController{
+		settingsService:            settingsService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type AdminSettingsController struct {
+	common.JsonResponse
+
+	settingsService            *service.SystemSettingsService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c AdminSettingsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/admin/settings", Handler: c.AllSettings, DemoModeAllowed: true},
+		{Method: http.MethodPut, Path: "/api/v1/admin/settings/:key", Handler: c.UpdateSetting},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/settings/:key", Handler: c.ResetSetting},
+	}
+}
+
+// @Summary		List system settings
+// @Description	List the runtime system settings with their effective value, env config default and last change
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AllSystemSettingsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/settings [get]
+func (c *AdminSettingsController) AllSettings(ctx *gin.Context) {
+	logger.Infof(ctx, "List system settings requested")
+
+	if _, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx); err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	settings, err := c.settingsService.AllSettingDetails(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get system settings: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected get system settings error"))
+		return
+	}
+
+	var resp AllSystemSettingsResponseDto
+	resp.FromEntity(settings)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Update system setting
+// @Description	Override a runtime system setting, the change applies without restart
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string							true	"Bearer access token of an admin"
+// @Param			key				path		string							true	"Setting key"
+// @Param			request			body		UpdateSystemSettingRequestDto	true	"New value"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UpdateSystemSettingResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/settings/{key} [put]
+func (c *AdminSettingsController) UpdateSetting(ctx *gin.Context) {
+	logger.Infof(ctx, "Update system setting requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req UpdateSystemSettingRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	key := ctx.Param("key")
+	if err := c.settingsService.UpdateSetting(ctx, admin.ID, key, *req.Value); err != nil {
+		logger.Errorf(ctx, "Failed to update system setting %s: %v", key, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "System setting %s updated by %s", key, admin.ID)
+	c.Success(ctx, "System setting updated successfully", UpdateSystemSettingResponseDto{})
+}
+
+// @Summary		Reset system setting
+// @Description	Remove the override of a runtime system setting so the env config default applies again
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Param			key				path		string	true	"Setting key"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UpdateSystemSettingResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/settings/{key} [delete]
+func (c *AdminSettingsController) ResetSetting(ctx *gin.Context) {
+	logger.Infof(ctx, "Reset system setting requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	key := ctx.Param("key")
+	if err := c.settingsService.ResetSetting(ctx, key); err != nil {
+		logger.Errorf(ctx, "Failed to reset system setting %s: %v", key, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "System setting %s reset by %s", key, admin.ID)
+	c.Success(ctx, "System setting reset successfully", UpdateSystemSettingResponseDto{})
+}
@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type SystemSettingDto struct {
+	Key          string     `json:"key" example:"registration.enabled"`
+	Type         string     `json:"type" example:"bool"`
+	Description  string     `json:"description" example:"Allow new users to sign up, by password or by SSO"`
+	Value        string     `json:"value" example:"true"`
+	DefaultValue string     `json:"default_value" example:"true"`
+	Overridden   bool       `json:"overridden" example:"false"`
+	UpdatedBy    string     `json:"updated_by" example:"user-xxxx"`
+	UpdatedAt    *time.Time `json:"updated_at"`
+}
+
+type AllSystemSettingsResponseDto struct {
+	Settings []SystemSettingDto `json:"settings"`
+}
+
+func (dto *AllSystemSettingsResponseDto) FromEntity(settings []entity.SystemSettingDetailEntity) {
+	dto.Settings = lo.Map(settings, func(setting entity.SystemSettingDetailEntity, _ int) SystemSettingDto {
+		return SystemSettingDto{
+			Key:          setting.Key,
+			Type:         string(setting.Type),
+			Description:  setting.Description,
+			Value:        setting.Value,
+			DefaultValue: setting.DefaultValue,
+			Overridden:   setting.Overridden,
+			UpdatedBy:    string(setting.UpdatedBy),
+			UpdatedAt:    setting.UpdatedAt,
+		}
+	})
+}
+
+type UpdateSystemSettingRequestDto struct {
+	// Value is the new value as a string, booleans are "true"/"false" and integers are decimal
+	Value *string `json:"value" binding:"required" example:"false"`
+}
+
+type UpdateSystemSettingResponseDto struct{}
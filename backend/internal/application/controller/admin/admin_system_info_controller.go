@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminSystemInfoController(
+	systemInfoService *service.SystemInfoService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return AdminSystemInfoController{
+		systemInfoService:          systemInfoService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type AdminSystemInfoController struct {
+	common.JsonResponse
+
+	systemInfoService          *service.SystemInfoService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c AdminSystemInfoController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/admin/system-info", Handler: c.SystemInfo, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Get system info
+// @Description	Get facts about the instance serving the request, such as the applied database schema version and the one this build expects
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Success		200				{object}	swagger.BaseSuccessResponse[SystemInfoResponseDto]
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/system-info [get]
+func (c *AdminSystemInfoController) SystemInfo(ctx *gin.Context) {
+	logger.Infof(ctx, "Get system info requested")
+
+	if _, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx); err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	schemaVersion, err := c.systemInfoService.SchemaVersion(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get schema version: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected get system info error"))
+		return
+	}
+
+	var resp SystemInfoResponseDto
+	resp.FromEntity(schemaVersion)
+	c.Success(ctx, "", resp)
+}
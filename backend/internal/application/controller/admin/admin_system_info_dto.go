@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+type SchemaVersionDto struct {
+	AppliedVersion int        `json:"applied_version" example:"5"`
+	AppliedAt      *time.Time `json:"applied_at" example:"2024-01-01T00:00:00Z"`
+	LatestVersion  int        `json:"latest_version" example:"5"`
+	UpToDate       bool       `json:"up_to_date" example:"true"`
+}
+
+type SystemInfoResponseDto struct {
+	Schema SchemaVersionDto `json:"schema"`
+}
+
+func (dto *SystemInfoResponseDto) FromEntity(schemaVersion entity.SchemaVersionEntity) {
+	dto.Schema = SchemaVersionDto{
+		AppliedVersion: schemaVersion.AppliedVersion,
+		LatestVersion:  schemaVersion.LatestVersion,
+		UpToDate:       schemaVersion.UpToDate(),
+	}
+	if !schemaVersion.AppliedAt.IsZero() {
+		appliedAt := schemaVersion.AppliedAt
+		dto.Schema.AppliedAt = &appliedAt
+	}
+}
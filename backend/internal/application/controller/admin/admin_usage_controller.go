@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminUsageController(
+	meteringService *service.MeteringService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return AdminUsageController{
+		meteringService:            meteringService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+// AdminUsageController exposes the metered usage of every user, e.g. for an external billing export.
+type AdminUsageController struct {
+	common.JsonResponse
+
+	meteringService            *service.MeteringService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c AdminUsageController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/admin/usage", Handler: c.Usage, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		List usage
+// @Description	List the metered usage of every user with usage in a month, it stays empty unless metering is enabled
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token of an admin"
+// @Param			period			query		string	false	"Month like 2026-01, the current month when empty"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AdminUsageResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/usage [get]
+func (c *AdminUsageController) Usage(ctx *gin.Context) {
+	logger.Infof(ctx, "List usage requested")
+
+	if _, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx); err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req AdminUsageRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	period, usages, err := c.meteringService.PeriodUsage(ctx, req.Period)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list usage: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp AdminUsageResponseDto
+	resp.FromEntity(period, usages)
+	c.Success(ctx, "", resp)
+}
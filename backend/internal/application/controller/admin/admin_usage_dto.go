@@ -0,0 +1,33 @@
+package admin
+
+import "ya-tool-craft/internal/domain/entity"
+
+type AdminUsageRequestDto struct {
+	Period string `form:"period" binding:"omitempty,len=7" example:"2026-01"`
+}
+
+type AdminUserUsageDto struct {
+	UserID string `json:"user_id" example:"user-xxxx"`
+	// Usage maps a metric to the amount used in the period, metrics without usage are missing
+	Usage map[string]int64 `json:"usage" example:"api_calls:120,storage_bytes:4096"`
+}
+
+type AdminUsageResponseDto struct {
+	Period string              `json:"period" example:"2026-01"`
+	Users  []AdminUserUsageDto `json:"users"`
+}
+
+func (dto *AdminUsageResponseDto) FromEntity(period string, usages []entity.UsageEntity) {
+	dto.Period = period
+	dto.Users = []AdminUserUsageDto{}
+	index := map[entity.UserIDEntity]int{}
+	for _, usage := range usages {
+		i, ok := index[usage.UserID]
+		if !ok {
+			i = len(dto.Users)
+			index[usage.UserID] = i
+			dto.Users = append(dto.Users, AdminUserUsageDto{UserID: string(usage.UserID), Usage: map[string]int64{}})
+		}
+		dto.Users[i].Usage[string(usage.Metric)] = usage.Amount
+	}
+}
@@ -0,0 +1,186 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminUserSecurityController(
+	userService *service.UserService,
+	twoFAService *service.TwoFAService,
+	authPasskeyService *service.AuthPasskeyService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return AdminUserSecurityController{
+		userService:                userService,
+		twoFAService:               twoFAService,
+		authPasskeyService:         authPasskeyService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+// AdminUserSecurityController handles a compromised account on behalf of its user.
+// Every action needs its own permission, admins hold them all, and is recorded by the audit log.
+type AdminUserSecurityController struct {
+	common.JsonResponse
+
+	userService                *service.UserService
+	twoFAService               *service.TwoFAService
+	authPasskeyService         *service.AuthPasskeyService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c AdminUserSecurityController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/admin/users/:user_id/sessions/revoke", Handler: c.RevokeSessions},
+		{Method: http.MethodPost, Path: "/api/v1/admin/users/:user_id/password/require-change", Handler: c.RequirePasswordChange},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/users/:user_id/2fa/:type", Handler: c.Remove2FA},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/users/:user_id/passkeys/:passkey_id", Handler: c.RemovePasskey},
+	}
+}
+
+// @Summary		Revoke user sessions
+// @Description	Sign the user out of every device by revoking all of the user's access and refresh tokens. Requires the users:revoke_sessions permission.
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			user_id			path		string	true	"User ID"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/users/{user_id}/sessions/revoke [post]
+func (c *AdminUserSecurityController) RevokeSessions(ctx *gin.Context) {
+	logger.Infof(ctx, "Revoke user sessions requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidatePermissionAccessTokenHeader(ctx, entity.UserPermissionRevokeSessions)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	userID := entity.UserIDEntity(ctx.Param("user_id"))
+	if err := c.userService.RevokeSessions(ctx, userID); err != nil {
+		logger.Errorf(ctx, "Failed to revoke user sessions: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Sessions of user %s revoked by %s", userID, admin.ID)
+	c.Success(ctx, "User sessions revoked successfully", gin.H{})
+}
+
+// @Summary		Require a password change
+// @Description	Make the user change the password before doing anything else. Every session of the user is refused with PasswordChangeRequired until the password is changed. Requires the users:require_password_change permission.
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			user_id			path		string	true	"User ID"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/users/{user_id}/password/require-change [post]
+func (c *AdminUserSecurityController) RequirePasswordChange(ctx *gin.Context) {
+	logger.Infof(ctx, "Require user password change requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidatePermissionAccessTokenHeader(ctx, entity.UserPermissionRequirePasswordChange)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	userID := entity.UserIDEntity(ctx.Param("user_id"))
+	if err := c.userService.RequirePasswordChange(ctx, userID); err != nil {
+		logger.Errorf(ctx, "Failed to require user password change: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Password change of user %s required by %s", userID, admin.ID)
+	c.Success(ctx, "User password change required successfully", gin.H{})
+}
+
+// @Summary		Remove a user's 2FA method
+// @Description	Remove a lost or compromised 2FA method of the user without a code. The recovery code is cleared once no method is left. Requires the users:reset_credentials permission.
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			user_id			path		string	true	"User ID"
+// @Param			type			path		string	true	"2FA type"	Enums(totp, webauthn)
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/users/{user_id}/2fa/{type} [delete]
+func (c *AdminUserSecurityController) Remove2FA(ctx *gin.Context) {
+	logger.Infof(ctx, "Remove user 2FA requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidatePermissionAccessTokenHeader(ctx, entity.UserPermissionResetCredentials)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	twoFAType := entity.TwoFAType(ctx.Param("type"))
+	if twoFAType != entity.TwoFATypeTOTP && twoFAType != entity.TwoFATypeWebAuthn {
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "unknown 2FA type %s", twoFAType))
+		return
+	}
+
+	userID := entity.UserIDEntity(ctx.Param("user_id"))
+	if err := c.twoFAService.Remove2FAByAdmin(ctx, userID, twoFAType); err != nil {
+		logger.Errorf(ctx, "Failed to remove user 2FA: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "2FA %s of user %s removed by %s", twoFAType, userID, admin.ID)
+	c.Success(ctx, "User 2FA removed successfully", gin.H{})
+}
+
+// @Summary		Remove a user's passkey
+// @Description	Remove a lost or compromised passkey of the user. Requires the users:reset_credentials permission.
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			user_id			path		string	true	"User ID"
+// @Param			passkey_id		path		int64	true	"Passkey ID"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/users/{user_id}/passkeys/{passkey_id} [delete]
+func (c *AdminUserSecurityController) RemovePasskey(ctx *gin.Context) {
+	logger.Infof(ctx, "Remove user passkey requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidatePermissionAccessTokenHeader(ctx, entity.UserPermissionResetCredentials)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	passkeyID, err := strconv.ParseInt(ctx.Param("passkey_id"), 10, 64)
+	if err != nil || passkeyID <= 0 {
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "passkey_id must be a positive integer"))
+		return
+	}
+
+	userID := entity.UserIDEntity(ctx.Param("user_id"))
+	if err := c.authPasskeyService.RemovePasskeyByAdmin(ctx, userID, passkeyID); err != nil {
+		logger.Errorf(ctx, "Failed to remove user passkey: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Passkey %d of user %s removed by %s", passkeyID, userID, admin.ID)
+	c.Success(ctx, "User passkey removed successfully", gin.H{})
+}
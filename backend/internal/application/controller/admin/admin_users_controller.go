@@ -0,0 +1,175 @@
+package admin
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminUsersController(
+	userService *service.UserService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return AdminUsersController{
+		userService:                userService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+// AdminUsersController lists the users and manages their accounts and roles.
+// Listing and disabling need their own permissions, admins hold them all, changing roles needs the admin role.
+type AdminUsersController struct {
+	common.JsonResponse
+
+	userService                *service.UserService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c AdminUsersController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/admin/users", Handler: c.Users, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/admin/users/:user_id/disable", Handler: c.DisableUser},
+		{Method: http.MethodPost, Path: "/api/v1/admin/users/:user_id/enable", Handler: c.EnableUser},
+		{Method: http.MethodPut, Path: "/api/v1/admin/users/:user_id/roles", Handler: c.SetRoles},
+	}
+}
+
+// @Summary		List users
+// @Description	List the users, oldest first, with the number of tools each owns. q matches a part of the username or the email. Requires the users:list permission.
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			q				query		string	false	"Text the username or the email contains"
+// @Param			limit			query		int		false	"Page size"		default(50)	maximum(200)
+// @Param			offset			query		int		false	"Users to skip"	default(0)
+// @Success		200				{object}	swagger.BaseSuccessResponse[AdminUsersResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/users [get]
+func (c *AdminUsersController) Users(ctx *gin.Context) {
+	logger.Infof(ctx, "List users requested")
+
+	if _, err := c.accessTokenHeaderValidator.ValidatePermissionAccessTokenHeader(ctx, entity.UserPermissionListUsers); err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req AdminUsersRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	users, total, err := c.userService.ListUsers(ctx, req.ToEntity())
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list users: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected list users error"))
+		return
+	}
+
+	var resp AdminUsersResponseDto
+	resp.FromEntity(users, total)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Disable a user
+// @Description	Sign the user out of every device and refuse every login until the user is enabled again. The tools of the user are kept. Admins can not disable their own account. Requires the users:disable permission.
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			user_id			path		string	true	"User ID"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/users/{user_id}/disable [post]
+func (c *AdminUsersController) DisableUser(ctx *gin.Context) {
+	c.setUserDisabled(ctx, true)
+}
+
+// @Summary		Enable a user
+// @Description	Let a disabled user log in again. Requires the users:disable permission.
+// @Tags			Admin
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			user_id			path		string	true	"User ID"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/users/{user_id}/enable [post]
+func (c *AdminUsersController) EnableUser(ctx *gin.Context) {
+	c.setUserDisabled(ctx, false)
+}
+
+func (c *AdminUsersController) setUserDisabled(ctx *gin.Context, disabled bool) {
+	logger.Infof(ctx, "Set user disabled to %t requested", disabled)
+
+	admin, err := c.accessTokenHeaderValidator.ValidatePermissionAccessTokenHeader(ctx, entity.UserPermissionDisableUsers)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	userID := entity.UserIDEntity(ctx.Param("user_id"))
+	if err := c.userService.SetUserDisabled(ctx, admin.ID, userID, disabled); err != nil {
+		logger.Errorf(ctx, "Failed to set user disabled: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	if disabled {
+		logger.Infof(ctx, "User %s disabled by %s", userID, admin.ID)
+		c.Success(ctx, "User disabled successfully", gin.H{})
+		return
+	}
+	logger.Infof(ctx, "User %s enabled by %s", userID, admin.ID)
+	c.Success(ctx, "User enabled successfully", gin.H{})
+}
+
+// @Summary		Set user roles
+// @Description	Replace the roles of the user. A role is admin, user or the name of a permission such as users:list, which grants only that permission. Admins can not remove their own admin role.
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token of an admin"
+// @Param			user_id			path		string						true	"User ID"
+// @Param			request			body		AdminSetUserRolesRequestDto	true	"New roles"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AdminSetUserRolesResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/users/{user_id}/roles [put]
+func (c *AdminUsersController) SetRoles(ctx *gin.Context) {
+	logger.Infof(ctx, "Set user roles requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req AdminSetUserRolesRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	userID := entity.UserIDEntity(ctx.Param("user_id"))
+	roles, err := c.userService.SetUserRoles(ctx, admin.ID, userID, req.Roles)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to set user roles: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Roles of user %s set by %s", userID, admin.ID)
+	c.Success(ctx, "User roles updated successfully", AdminSetUserRolesResponseDto{Roles: roleNames(roles)})
+}
@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type AdminUsersRequestDto struct {
+	Query  string `form:"q" binding:"omitempty,max=255" example:"alice"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200" example:"50"`
+	Offset int    `form:"offset" binding:"omitempty,min=0" example:"0"`
+}
+
+func (dto AdminUsersRequestDto) ToEntity() entity.UserFilter {
+	return entity.UserFilter{
+		Query:  dto.Query,
+		Limit:  dto.Limit,
+		Offset: dto.Offset,
+	}
+}
+
+type AdminUserDto struct {
+	ID                     string     `json:"id" example:"u-xxxx"`
+	Username               string     `json:"username" example:"alice"`
+	Email                  *string    `json:"email" example:"alice@example.com"`
+	Roles                  []string   `json:"roles" example:"user"`
+	Disabled               bool       `json:"disabled" example:"false"`
+	PasswordChangeRequired bool       `json:"password_change_required" example:"false"`
+	LastLoginAt            *time.Time `json:"last_login_at" example:"2024-01-01T00:00:00Z"`
+	LoginCount             int        `json:"login_count" example:"3"`
+	ToolCount              int        `json:"tool_count" example:"12"`
+	CreatedAt              time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+func (dto *AdminUserDto) FromEntity(summary entity.UserSummaryEntity) {
+	dto.ID = string(summary.User.ID)
+	dto.Username = summary.User.Name
+	dto.Email = summary.User.Mail
+	dto.Roles = roleNames(summary.User.Roles)
+	dto.Disabled = summary.User.Disabled
+	dto.PasswordChangeRequired = summary.User.PasswordChangeRequired
+	dto.LastLoginAt = summary.User.LastLoginAt
+	dto.LoginCount = summary.User.LoginCount
+	dto.ToolCount = summary.ToolCount
+	dto.CreatedAt = summary.CreatedAt
+}
+
+type AdminUsersResponseDto struct {
+	Users []AdminUserDto `json:"users"`
+	Total int            `json:"total" example:"1"`
+}
+
+func (dto *AdminUsersResponseDto) FromEntity(users []entity.UserSummaryEntity, total int) {
+	dto.Users = lo.Map(users, func(user entity.UserSummaryEntity, _ int) AdminUserDto {
+		item := AdminUserDto{}
+		item.FromEntity(user)
+		return item
+	})
+	dto.Total = total
+}
+
+type AdminSetUserRolesRequestDto struct {
+	// Roles are admin, user or permission names such as users:list
+	Roles []string `json:"roles" binding:"required,min=1,max=20,dive,min=1,max=64" example:"user,users:list"`
+}
+
+type AdminSetUserRolesResponseDto struct {
+	Roles []string `json:"roles" example:"user,users:list"`
+}
+
+func roleNames(roles []entity.UserRoleEntity) []string {
+	return lo.Map(roles, func(role entity.UserRoleEntity, _ int) string { return role.RoleName })
+}
@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAdminUsersMergeController(
+	userService *service.UserService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return AdminUsersMergeController{
+		userService:                userService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type AdminUsersMergeController struct {
+	common.JsonResponse
+
+	userService                *service.UserService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c AdminUsersMergeController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/admin/users/merge", Handler: c.MergeUsers},
+	}
+}
+
+// @Summary		Merge duplicate users
+// @Description	Move tools, global script, passkeys and SSO bindings of the duplicate user into the survivor, then delete the duplicate
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token of an admin"
+// @Param			request			body		AdminMergeUsersRequestDto	true	"Users to merge"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AdminMergeUsersResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/admin/users/merge [post]
+func (c *AdminUsersMergeController) MergeUsers(ctx *gin.Context) {
+	logger.Infof(ctx, "Merge users requested")
+
+	admin, err := c.accessTokenHeaderValidator.ValidateAdminAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req AdminMergeUsersRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	result, err := c.userService.MergeUsers(ctx, entity.UserIDEntity(req.SurvivorUserID), entity.UserIDEntity(req.DuplicateUserID))
+	if err != nil {
+		logger.Errorf(ctx, "Failed to merge users: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "User %s merged into %s by %s", req.DuplicateUserID, req.SurvivorUserID, admin.ID)
+
+	var resp AdminMergeUsersResponseDto
+	resp.FromEntity(result)
+	c.Success(ctx, "Users merged successfully", resp)
+}
@@ -0,0 +1,40 @@
+package announcement
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type AnnouncementDto struct {
+	ID          string     `json:"id" example:"ann-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	Message     string     `json:"message" example:"Scheduled maintenance tonight at 22:00 UTC"`
+	Severity    string     `json:"severity" enums:"info,warning,critical" example:"warning"`
+	StartsAt    *time.Time `json:"starts_at" example:"2024-01-01T00:00:00Z"`
+	EndsAt      *time.Time `json:"ends_at" example:"2024-01-02T00:00:00Z"`
+	Dismissible bool       `json:"dismissible" example:"true"`
+	UpdatedAt   time.Time  `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+func (dto *AnnouncementDto) FromEntity(announcement entity.AnnouncementEntity) {
+	dto.ID = announcement.ID
+	dto.Message = announcement.Message
+	dto.Severity = string(announcement.Severity)
+	dto.StartsAt = announcement.StartsAt
+	dto.EndsAt = announcement.EndsAt
+	dto.Dismissible = announcement.Dismissible
+	dto.UpdatedAt = announcement.UpdatedAt
+}
+
+type ActiveAnnouncementsResponseDto struct {
+	Announcements []AnnouncementDto `json:"announcements"`
+}
+
+func (dto *ActiveAnnouncementsResponseDto) FromEntity(announcements []entity.AnnouncementEntity) {
+	dto.Announcements = lo.Map(announcements, func(announcement entity.AnnouncementEntity, _ int) AnnouncementDto {
+		item := AnnouncementDto{}
+		item.FromEntity(announcement)
+		return item
+	})
+}
@@ -0,0 +1,52 @@
+package announcement
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAnnouncementsController(announcementService *service.AnnouncementService) router.Controller {
+	return AnnouncementsController{announcementService: announcementService}
+}
+
+type AnnouncementsController struct {
+	common.JsonResponse
+
+	announcementService *service.AnnouncementService
+}
+
+func (c AnnouncementsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/announcements", Handler: c.ActiveAnnouncements, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		List active announcements
+// @Description	Public endpoint polled by the frontend, returns the announcement banners to show right now, newest first
+// @Tags			Announcement
+// @Produce		json
+// @Success		200	{object}	swagger.BaseSuccessResponse[ActiveAnnouncementsResponseDto]
+// @Failure		400	{object}	swagger.BaseFailResponse
+// @Router			/api/v1/announcements [get]
+func (c *AnnouncementsController) ActiveAnnouncements(ctx *gin.Context) {
+	logger.Infof(ctx, "List active announcements requested")
+
+	announcements, err := c.announcementService.ActiveAnnouncements(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get active announcements: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected get announcements error"))
+		return
+	}
+
+	var resp ActiveAnnouncementsResponseDto
+	resp.FromEntity(announcements)
+	c.Success(ctx, "", resp)
+}
@@ -39,6 +39,7 @@ func (c TwoFARecoveryController) RouterInfo() []router.RouterInfo {
 // @Success		200		{object}	swagger.BaseSuccessResponse[any]
 // @Failure		400		{object}	swagger.BaseFailResponse
 // @Failure		401		{object}	swagger.BaseFailResponse
+// @Failure		429		{object}	swagger.BaseFailResponse
 // @Router			/api/v1/auth/2fa/recovery [post]
 func (c *TwoFARecoveryController) Handler(ctx *gin.Context) {
 	logger.Infof(ctx, "2FA recovery requested")
@@ -40,7 +40,7 @@ func (c TwoFADeleteController) RouterInfo() []router.RouterInfo {
 }
 
 // @Summary		Delete 2FA
-// @Description	Delete a specific 2FA method for the current user (requires verification code)
+// @Description	Delete a specific 2FA method for the current user (requires a TOTP code or the recovery code)
 // @Tags			Auth
 // @Accept			json
 // @Produce		json
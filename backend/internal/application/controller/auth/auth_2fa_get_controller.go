@@ -28,7 +28,7 @@ type TwoFAGetController struct {
 
 func (c TwoFAGetController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodGet, Path: "/api/v1/auth/2fa", Handler: c.Handler},
+		{Method: http.MethodGet, Path: "/api/v1/auth/2fa", Handler: c.Handler, DemoModeAllowed: true},
 	}
 }
 
@@ -56,6 +56,13 @@ func (c *TwoFAGetController) Handler(ctx *gin.Context) {
 		return
 	}
 
+	methods, err := c.twoFAService.TwoFAMethods(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get 2FA methods: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
 	dtoList := make([]TwoFAInfoDto, 0, len(twoFAInfoList))
 	for _, info := range twoFAInfoList {
 		dtoList = append(dtoList, TwoFAInfoDto{
@@ -66,6 +73,7 @@ func (c *TwoFAGetController) Handler(ctx *gin.Context) {
 	}
 
 	c.Success(ctx, "", TwoFAGetResponseDto{
-		TwoFAList: dtoList,
+		TwoFAList:       dtoList,
+		PreferredMethod: string(methods.Preferred),
 	})
 }
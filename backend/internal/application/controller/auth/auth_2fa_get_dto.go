@@ -9,5 +9,6 @@ type TwoFAInfoDto struct {
 }
 
 type TwoFAGetResponseDto struct {
-	TwoFAList []TwoFAInfoDto `json:"two_fa_list"`
+	TwoFAList       []TwoFAInfoDto `json:"two_fa_list"`
+	PreferredMethod string         `json:"preferred_method"` // 2FA method offered first at login, empty when 2FA is not enabled
 }
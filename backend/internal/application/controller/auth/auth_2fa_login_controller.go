@@ -6,15 +6,18 @@ import (
 	"ya-tool-craft/internal/core/logger"
 	"ya-tool-craft/internal/core/router"
 	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/middleware"
 
 	_ "ya-tool-craft/internal/swagger"
 
 	"github.com/gin-gonic/gin"
 )
 
-func NewTwoFALoginController(twoFAService *service.TwoFAService) router.Controller {
+func NewTwoFALoginController(twoFAService *service.TwoFAService, syncService *service.SyncService) router.Controller {
 	return TwoFALoginController{
 		twoFAService: twoFAService,
+		syncService:  syncService,
 	}
 }
 
@@ -22,16 +25,17 @@ type TwoFALoginController struct {
 	common.JsonResponse
 
 	twoFAService *service.TwoFAService
+	syncService  *service.SyncService
 }
 
 func (c TwoFALoginController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodPost, Path: "/api/v1/auth/2fa/login", Handler: c.Handler},
+		{Method: http.MethodPost, Path: "/api/v1/auth/2fa/login", Handler: c.Handler, DemoModeAllowed: true},
 	}
 }
 
 // @Summary		2FA Login
-// @Description	Complete login with 2FA verification
+// @Description	Complete login with 2FA verification, using any 2FA method enabled by the user (TOTP code or passkey assertion)
 // @Tags			Auth
 // @Accept			json
 // @Produce		json
@@ -39,6 +43,7 @@ func (c TwoFALoginController) RouterInfo() []router.RouterInfo {
 // @Success		200		{object}	swagger.BaseSuccessResponse[TwoFALoginResponseDto]
 // @Failure		400		{object}	swagger.BaseFailResponse
 // @Failure		401		{object}	swagger.BaseFailResponse
+// @Failure		429		{object}	swagger.BaseFailResponse
 // @Router			/api/v1/auth/2fa/login [post]
 func (c *TwoFALoginController) Handler(ctx *gin.Context) {
 	logger.Infof(ctx, "2FA login requested")
@@ -50,7 +55,13 @@ func (c *TwoFALoginController) Handler(ctx *gin.Context) {
 		return
 	}
 
-	result, err := c.twoFAService.Verify2FATokenAndLogin(ctx, req.Token, req.Code)
+	verification, err := req.ToEntity()
+	if err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	result, err := c.twoFAService.Verify2FAAndLogin(ctx, req.Token, verification)
 	if err != nil {
 		logger.Errorf(ctx, "Failed to verify 2FA and login: %v", err)
 		c.Error(ctx, err)
@@ -59,8 +70,39 @@ func (c *TwoFALoginController) Handler(ctx *gin.Context) {
 
 	logger.Infof(ctx, "2FA login successful: user_id=%s", result.User.ID)
 
+	// the exported auth event names the user who logged in
+	middleware.SetAuditActor(ctx, result.User.ID)
+
+	device, err := c.syncService.RegisterDevice(ctx, result.User.ID, ctx.Request.UserAgent())
+	if err != nil {
+		logger.Errorf(ctx, "Failed to register device: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected register device error"))
+		return
+	}
+
 	c.Success(ctx, "", TwoFALoginResponseDto{
 		AccessToken:  result.AccessToken.Token,
 		RefreshToken: result.RefreshToken.Token,
+		DeviceID:     device.ID,
 	})
 }
+
+// newTwoFARequiredError tells the client to continue the login with one of the user's 2FA methods
+func newTwoFARequiredError(ctx *gin.Context, twoFAService *service.TwoFAService, twoFAToken string) error {
+	methods, err := twoFAService.Pending2FAMethods(ctx, twoFAToken)
+	if err != nil {
+		return err
+	}
+	var methodsDto TwoFAMethodsDto
+	methodsDto.FromEntity(methods)
+
+	return error_code.NewErrorWithErrorCodeFAppendExtraData(
+		error_code.TwoFaTotpIsRequiredForLogin,
+		gin.H{
+			"two_fa_token":            twoFAToken,
+			"two_fa_methods":          methodsDto.Methods,
+			"preferred_two_fa_method": methodsDto.Preferred,
+		},
+		"",
+	)
+}
@@ -1,11 +1,48 @@
 package auth
 
+import "ya-tool-craft/internal/domain/entity"
+
 type TwoFALoginRequestDto struct {
-	Token string `json:"token" binding:"required"` // token from login API when 2FA is required
-	Code  string `json:"code" binding:"required"`  // 6-digit TOTP code from authenticator app
+	Token            string                  `json:"token" binding:"required"`        // token from login API when 2FA is required
+	Method           string                  `json:"method,omitempty" example:"totp"` // 2FA method used to verify, totp, webauthn or recovery_code (a single-use recovery code), defaults to totp
+	Code             string                  `json:"code,omitempty"`                  // 6-digit TOTP code from authenticator app for the totp method, or a single-use recovery code for the recovery_code method
+	WebAuthnResponse *PasskeyLoginRequestDto `json:"webauthn_response,omitempty"`     // passkey assertion for the webauthn challenge, for the webauthn method
+}
+
+func (d *TwoFALoginRequestDto) ToEntity() (entity.TwoFAVerificationEntity, error) {
+	verification := entity.TwoFAVerificationEntity{
+		Method: entity.TwoFAType(d.Method),
+		Code:   d.Code,
+	}
+	if verification.Method == "" {
+		verification.Method = entity.TwoFATypeTOTP
+	}
+	if d.WebAuthnResponse != nil {
+		webAuthnResponse, err := d.WebAuthnResponse.ToEntity()
+		if err != nil {
+			return entity.TwoFAVerificationEntity{}, err
+		}
+		verification.WebAuthnResponse = &webAuthnResponse
+	}
+	return verification, nil
 }
 
 type TwoFALoginResponseDto struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
+	DeviceID     string `json:"device_id"` // identifies this login when syncing tools
+}
+
+// TwoFAMethodsDto lists the 2FA methods that can complete a login
+type TwoFAMethodsDto struct {
+	Methods   []string `json:"methods"`
+	Preferred string   `json:"preferred"` // method to offer first, empty when 2FA is not enabled
+}
+
+func (d *TwoFAMethodsDto) FromEntity(methods entity.TwoFAMethodsEntity) {
+	d.Methods = make([]string, 0, len(methods.Methods))
+	for _, method := range methods.Methods {
+		d.Methods = append(d.Methods, string(method))
+	}
+	d.Preferred = string(methods.Preferred)
 }
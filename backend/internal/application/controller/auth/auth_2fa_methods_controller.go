@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewTwoFAMethodsController(twoFAService *service.TwoFAService) router.Controller {
+	return TwoFAMethodsController{
+		twoFAService: twoFAService,
+	}
+}
+
+type TwoFAMethodsController struct {
+	common.JsonResponse
+
+	twoFAService *service.TwoFAService
+}
+
+type TwoFAMethodsRequestDto struct {
+	Token string `json:"token" binding:"required"` // token from login API when 2FA is required
+}
+
+func (c TwoFAMethodsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/auth/2fa/methods", Handler: c.Handler, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		List 2FA methods for login
+// @Description	List the 2FA methods that can complete a pending login, and the one the user prefers
+// @Tags			Auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		TwoFAMethodsRequestDto	true	"2FA token"
+// @Success		200		{object}	swagger.BaseSuccessResponse[TwoFAMethodsDto]
+// @Failure		400		{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/2fa/methods [post]
+func (c *TwoFAMethodsController) Handler(ctx *gin.Context) {
+	logger.Infof(ctx, "List 2FA methods requested")
+
+	var req TwoFAMethodsRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	methods, err := c.twoFAService.Pending2FAMethods(ctx, req.Token)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list 2FA methods: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp TwoFAMethodsDto
+	resp.FromEntity(methods)
+	c.Success(ctx, "", resp)
+}
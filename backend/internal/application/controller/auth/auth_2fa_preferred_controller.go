@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewTwoFAPreferredController(twoFAService *service.TwoFAService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return TwoFAPreferredController{
+		twoFAService:               twoFAService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type TwoFAPreferredController struct {
+	common.JsonResponse
+
+	twoFAService               *service.TwoFAService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+type TwoFAPreferredRequestDto struct {
+	Method string `json:"method" binding:"required" example:"totp"` // an enabled 2FA method, totp or webauthn
+}
+
+func (c TwoFAPreferredController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPut, Path: "/api/v1/auth/2fa/preferred", Handler: c.Handler},
+	}
+}
+
+// @Summary		Set preferred 2FA method
+// @Description	Set the 2FA method offered first at login, it has to be enabled
+// @Tags			Auth
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token"
+// @Param			request			body		TwoFAPreferredRequestDto	true	"Preferred 2FA method"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/2fa/preferred [put]
+func (c *TwoFAPreferredController) Handler(ctx *gin.Context) {
+	logger.Infof(ctx, "Set preferred 2FA method requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req TwoFAPreferredRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	if err := c.twoFAService.SetPreferred2FAMethod(ctx, user.ID, entity.TwoFAType(req.Method)); err != nil {
+		logger.Errorf(ctx, "Failed to set preferred 2FA method: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "Preferred 2FA method updated", nil)
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+type TwoFARecoveryCodesRegenerateRequestDto struct {
+	Code string `json:"code" binding:"required"` // TOTP code, the recovery code or an unused single-use recovery code
+}
+
+type TwoFARecoveryCodesRegenerateResponseDto struct {
+	RecoveryCodes []string `json:"recovery_codes"` // shown only once, each code completes one 2FA login
+}
+
+type TwoFARecoveryCodesStatusDto struct {
+	Remaining   int        `json:"remaining"`
+	Total       int        `json:"total"`
+	GeneratedAt *time.Time `json:"generated_at"` // null when no single-use recovery codes were generated
+}
+
+func (d *TwoFARecoveryCodesStatusDto) FromEntity(status entity.RecoveryCodesStatusEntity) {
+	d.Remaining = status.Remaining
+	d.Total = status.Total
+	d.GeneratedAt = status.GeneratedAt
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewTwoFARecoveryCodesGetController(twoFAService *service.TwoFAService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return TwoFARecoveryCodesGetController{
+		twoFAService:               twoFAService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type TwoFARecoveryCodesGetController struct {
+	common.JsonResponse
+
+	twoFAService               *service.TwoFAService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c TwoFARecoveryCodesGetController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/auth/2fa/recovery-codes", Handler: c.Handler, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Get recovery codes status
+// @Description	Get how many single-use recovery codes of the current user are left, the codes themselves are never returned again
+// @Tags			Auth
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[TwoFARecoveryCodesStatusDto]
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/2fa/recovery-codes [get]
+func (c *TwoFARecoveryCodesGetController) Handler(ctx *gin.Context) {
+	logger.Infof(ctx, "Get recovery codes status requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	status, err := c.twoFAService.RecoveryCodesStatus(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get recovery codes status: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp TwoFARecoveryCodesStatusDto
+	resp.FromEntity(status)
+	c.Success(ctx, "", resp)
+}
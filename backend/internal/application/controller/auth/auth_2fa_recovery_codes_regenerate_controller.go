@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewTwoFARecoveryCodesRegenerateController(twoFAService *service.TwoFAService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return TwoFARecoveryCodesRegenerateController{
+		twoFAService:               twoFAService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type TwoFARecoveryCodesRegenerateController struct {
+	common.JsonResponse
+
+	twoFAService               *service.TwoFAService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c TwoFARecoveryCodesRegenerateController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/auth/2fa/recovery-codes", Handler: c.Handler},
+	}
+}
+
+// @Summary		Regenerate recovery codes
+// @Description	Replace the single-use recovery codes of the current user with a new set, the previous codes stop working (requires 2FA and a TOTP code or a recovery code)
+// @Tags			Auth
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string									true	"Bearer access token"
+// @Param			request			body		TwoFARecoveryCodesRegenerateRequestDto	true	"Verification code"
+// @Success		200				{object}	swagger.BaseSuccessResponse[TwoFARecoveryCodesRegenerateResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/2fa/recovery-codes [post]
+func (c *TwoFARecoveryCodesRegenerateController) Handler(ctx *gin.Context) {
+	logger.Infof(ctx, "Regenerate recovery codes requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req TwoFARecoveryCodesRegenerateRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	codes, err := c.twoFAService.RegenerateRecoveryCodes(ctx, user.ID, req.Code)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to regenerate recovery codes: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "", TwoFARecoveryCodesRegenerateResponseDto{RecoveryCodes: codes})
+}
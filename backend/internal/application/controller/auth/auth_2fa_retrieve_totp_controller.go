@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"fmt"
 	"net/http"
 	"ya-tool-craft/internal/application/controller/common"
 	"ya-tool-craft/internal/core/logger"
@@ -33,7 +34,7 @@ func (c TwoFARetrieveTOTPController) RouterInfo() []router.RouterInfo {
 }
 
 // @Summary		Retrieve TOTP setup info
-// @Description	Generate TOTP secret and QR code for 2FA setup
+// @Description	Generate TOTP secret for 2FA setup, the QR code is fetched separately from qr_code_url
 // @Tags			Auth
 // @Produce		json
 // @Param			Authorization	header		string	true	"Bearer access token"
@@ -58,10 +59,10 @@ func (c *TwoFARetrieveTOTPController) Handler(ctx *gin.Context) {
 	}
 
 	respDto := TwoFARetrieveTOTPResponseDto{
-		Token:  totpInfo.Token,
-		Secret: totpInfo.Secret,
-		URL:    totpInfo.URL,
-		QRCode: totpInfo.QRCode,
+		Token:     totpInfo.Token,
+		Secret:    totpInfo.Secret,
+		URL:       totpInfo.URL,
+		QRCodeURL: fmt.Sprintf("/api/v1/auth/2fa/totp/%s/qr", totpInfo.Token),
 	}
 	c.Success(ctx, "", respDto)
 }
@@ -1,8 +1,12 @@
 package auth
 
 type TwoFARetrieveTOTPResponseDto struct {
-	Token  string `json:"token"` // token for verification
-	Secret string `json:"secret"`
-	URL    string `json:"url"`
+	Token     string `json:"token"` // token for verification
+	Secret    string `json:"secret"`
+	URL       string `json:"url"`
+	QRCodeURL string `json:"qr_code_url"` // endpoint to fetch the QR code lazily
+}
+
+type TwoFARetrieveTOTPQRCodeResponseDto struct {
 	QRCode string `json:"qr_code"` // base64 encoded PNG image
 }
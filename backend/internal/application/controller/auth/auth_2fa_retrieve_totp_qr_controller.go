@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewTwoFARetrieveTOTPQRCodeController(twoFAService *service.TwoFAService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return TwoFARetrieveTOTPQRCodeController{
+		twoFAService:               twoFAService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type TwoFARetrieveTOTPQRCodeController struct {
+	common.JsonResponse
+
+	twoFAService               *service.TwoFAService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c TwoFARetrieveTOTPQRCodeController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/auth/2fa/totp/:token/qr", Handler: c.Handler, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Retrieve TOTP QR code
+// @Description	Render the QR code of a pending TOTP setup. The image is generated lazily and cached until the setup session expires
+// @Tags			Auth
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			token			path		string	true	"TOTP setup token returned by GET /api/v1/auth/2fa/totp"
+// @Success		200				{object}	swagger.BaseSuccessResponse[TwoFARetrieveTOTPQRCodeResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/2fa/totp/{token}/qr [get]
+func (c *TwoFARetrieveTOTPQRCodeController) Handler(ctx *gin.Context) {
+	logger.Infof(ctx, "Retrieve TOTP QR code requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	token := ctx.Param("token")
+	if token == "" {
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "token is required"))
+		return
+	}
+
+	qrCode, err := c.twoFAService.GetTOTPQRCode(ctx, user.ID, token)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to retrieve TOTP QR code: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "", TwoFARetrieveTOTPQRCodeResponseDto{QRCode: qrCode})
+}
@@ -33,7 +33,7 @@ func (c TwoFATOTPAddController) RouterInfo() []router.RouterInfo {
 }
 
 // @Summary		Add TOTP 2FA
-// @Description	Verify TOTP code and enable 2FA for the user. Returns a recovery code that can be used to disable 2FA.
+// @Description	Verify TOTP code and enable 2FA for the user. Returns a recovery code that can be used to disable 2FA. With REVOKE_SESSIONS_ON_2FA_ENABLE every other device is signed out, the current one stays signed in.
 // @Tags			Auth
 // @Accept			json
 // @Produce		json
@@ -60,7 +60,7 @@ func (c *TwoFATOTPAddController) Handler(ctx *gin.Context) {
 		return
 	}
 
-	recoveryCode, err := c.twoFAService.VerifyAndEnableTOTP(ctx, user.ID, req.Token, req.Code)
+	recoveryCode, err := c.twoFAService.VerifyAndEnableTOTP(ctx, user.ID, req.Token, req.Code, common.CurrentRefreshTokenHash(ctx))
 	if err != nil {
 		logger.Errorf(ctx, "Failed to enable TOTP 2FA: %v", err)
 		c.Error(ctx, err)
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewTwoFAWebAuthnAddController(twoFAService *service.TwoFAService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return TwoFAWebAuthnAddController{
+		twoFAService:               twoFAService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type TwoFAWebAuthnAddController struct {
+	common.JsonResponse
+
+	twoFAService               *service.TwoFAService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+type TwoFAWebAuthnAddResponseDto struct {
+	RecoveryCode *string `json:"recovery_code"` // only set when the user had no recovery code yet
+}
+
+func (c TwoFAWebAuthnAddController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/auth/2fa/webauthn", Handler: c.Handler},
+	}
+}
+
+// @Summary		Enable WebAuthn 2FA
+// @Description	Require a passkey assertion as second factor of every login, the current user needs a registered passkey. With REVOKE_SESSIONS_ON_2FA_ENABLE every other device is signed out, the current one stays signed in
+// @Tags			Auth
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[TwoFAWebAuthnAddResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/2fa/webauthn [post]
+func (c *TwoFAWebAuthnAddController) Handler(ctx *gin.Context) {
+	logger.Infof(ctx, "Enable WebAuthn 2FA requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	recoveryCode, err := c.twoFAService.EnableWebAuthn2FA(ctx, user.ID, common.CurrentRefreshTokenHash(ctx))
+	if err != nil {
+		logger.Errorf(ctx, "Failed to enable WebAuthn 2FA: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "WebAuthn 2FA enabled for user: %s", user.ID)
+	c.Success(ctx, "WebAuthn 2FA enabled successfully", TwoFAWebAuthnAddResponseDto{
+		RecoveryCode: recoveryCode,
+	})
+}
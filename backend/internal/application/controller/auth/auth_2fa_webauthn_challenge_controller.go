@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewTwoFAWebAuthnChallengeController(twoFAService *service.TwoFAService) router.Controller {
+	return TwoFAWebAuthnChallengeController{
+		twoFAService: twoFAService,
+	}
+}
+
+type TwoFAWebAuthnChallengeController struct {
+	common.JsonResponse
+
+	twoFAService *service.TwoFAService
+}
+
+type TwoFAWebAuthnChallengeRequestDto struct {
+	Token string `json:"token" binding:"required"` // token from login API when 2FA is required
+}
+
+func (c TwoFAWebAuthnChallengeController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/auth/2fa/webauthn/challenge", Handler: c.Handler, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Begin WebAuthn 2FA
+// @Description	Generate a passkey challenge completing a pending login. Pass the navigator.credentials.get() result as webauthn_response to the 2FA login API
+// @Tags			Auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		TwoFAWebAuthnChallengeRequestDto	true	"2FA token"
+// @Success		200		{object}	swagger.BaseSuccessResponse[PasskeyLoginChallengeResponseDto]
+// @Failure		400		{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/2fa/webauthn/challenge [post]
+func (c *TwoFAWebAuthnChallengeController) Handler(ctx *gin.Context) {
+	logger.Infof(ctx, "Begin WebAuthn 2FA requested")
+
+	var req TwoFAWebAuthnChallengeRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	options, err := c.twoFAService.WebAuthn2FAChallenge(ctx, req.Token)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to begin WebAuthn 2FA: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "WebAuthn 2FA challenge generated", options)
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewAccountRecoveryController(accountRecoveryService *service.AccountRecoveryService) router.Controller {
+	return AccountRecoveryController{
+		accountRecoveryService: accountRecoveryService,
+	}
+}
+
+// AccountRecoveryController lets a user who lost both the password and every 2FA method recover the account,
+// the routes need no access token.
+type AccountRecoveryController struct {
+	common.JsonResponse
+
+	accountRecoveryService *service.AccountRecoveryService
+}
+
+func (c AccountRecoveryController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/auth/recovery/start", Handler: c.Start},
+		{Method: http.MethodPost, Path: "/api/v1/auth/recovery/verify", Handler: c.Verify},
+		{Method: http.MethodPost, Path: "/api/v1/auth/recovery/complete", Handler: c.Complete},
+	}
+}
+
+// @Summary		Start an account recovery
+// @Description	Email a recovery code to the account using the email. The response is the same whether an account uses the email or not.
+// @Description	Use this when both the password and every 2FA method are lost, a lost 2FA method alone is removed with the 2FA recovery code.
+// @Tags			Auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		AccountRecoveryStartRequestDto	true	"Account email"
+// @Success		200		{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400		{object}	swagger.BaseFailResponse
+// @Failure		503		{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/recovery/start [post]
+func (c *AccountRecoveryController) Start(ctx *gin.Context) {
+	logger.Infof(ctx, "Account recovery start requested")
+
+	var req AccountRecoveryStartRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Failed to bind request: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	if err := c.accountRecoveryService.StartRecovery(ctx, req.Email); err != nil {
+		logger.Errorf(ctx, "Failed to start account recovery: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "If an account uses this email, a recovery code was sent to it", gin.H{})
+}
+
+// @Summary		Verify an account recovery code
+// @Description	Check the emailed code and create the recovery request. An admin has to approve it, then it can be completed once ACCOUNT_RECOVERY_DELAY is over.
+// @Description	The account is notified by email and can cancel the request meanwhile. Keep the recovery token, it is returned only once.
+// @Tags			Auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		AccountRecoveryVerifyRequestDto	true	"Account email and recovery code"
+// @Success		200		{object}	swagger.BaseSuccessResponse[AccountRecoveryVerifyResponseDto]
+// @Failure		400		{object}	swagger.BaseFailResponse
+// @Failure		409		{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/recovery/verify [post]
+func (c *AccountRecoveryController) Verify(ctx *gin.Context) {
+	logger.Infof(ctx, "Account recovery verify requested")
+
+	var req AccountRecoveryVerifyRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Failed to bind request: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	request, token, err := c.accountRecoveryService.VerifyRecovery(ctx, req.Email, req.Code)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to verify account recovery: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp AccountRecoveryVerifyResponseDto
+	resp.FromEntity(request, token)
+	ctx.Header("Cache-Control", "no-store")
+	c.Success(ctx, "Account recovery requested, it has to be approved by an administrator", resp)
+}
+
+// @Summary		Complete an account recovery
+// @Description	Set a new password, remove every 2FA method and the 2FA recovery code, and sign the account out of every device.
+// @Description	The request must be approved by an admin and its delay over.
+// @Tags			Auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		AccountRecoveryCompleteRequestDto	true	"Recovery request, token and new password"
+// @Success		200		{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400		{object}	swagger.BaseFailResponse
+// @Failure		403		{object}	swagger.BaseFailResponse
+// @Failure		404		{object}	swagger.BaseFailResponse
+// @Failure		409		{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/recovery/complete [post]
+func (c *AccountRecoveryController) Complete(ctx *gin.Context) {
+	logger.Infof(ctx, "Account recovery complete requested")
+
+	var req AccountRecoveryCompleteRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Failed to bind request: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	if err := c.accountRecoveryService.CompleteRecovery(ctx, req.RequestID, req.RecoveryToken, req.NewPassword); err != nil {
+		logger.Errorf(ctx, "Failed to complete account recovery %s: %v", req.RequestID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Account recovery %s completed", req.RequestID)
+	c.Success(ctx, "Account recovered, sign in with the new password", gin.H{})
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+type AccountRecoveryStartRequestDto struct {
+	Email string `json:"email" binding:"required,email,max=255" example:"user@example.com"`
+}
+
+type AccountRecoveryVerifyRequestDto struct {
+	Email string `json:"email" binding:"required,email,max=255" example:"user@example.com"`
+	Code  string `json:"code" binding:"required,max=16" example:"123456"`
+}
+
+type AccountRecoveryVerifyResponseDto struct {
+	RequestID string `json:"request_id" example:"4f9c2f0e-3a5b-4f7e-9a55-2b0c5e1d7c11"`
+	// RecoveryToken completes the recovery once it is approved, it is returned only once
+	RecoveryToken string    `json:"recovery_token" example:"Zk1tQ2dYV3lOb2h4..."`
+	Status        string    `json:"status" example:"pending"`
+	AvailableAt   time.Time `json:"available_at" format:"date-time"`
+}
+
+func (dto *AccountRecoveryVerifyResponseDto) FromEntity(request entity.AccountRecoveryRequestEntity, token string) {
+	dto.RequestID = request.ID
+	dto.RecoveryToken = token
+	dto.Status = string(request.Status)
+	dto.AvailableAt = request.AvailableAt
+}
+
+type AccountRecoveryCompleteRequestDto struct {
+	RequestID     string `json:"request_id" binding:"required,max=64" example:"4f9c2f0e-3a5b-4f7e-9a55-2b0c5e1d7c11"`
+	RecoveryToken string `json:"recovery_token" binding:"required,max=128" example:"Zk1tQ2dYV3lOb2h4..."`
+	NewPassword   string `json:"new_password" binding:"required,min=8,max=32" example:"new_password"`
+}
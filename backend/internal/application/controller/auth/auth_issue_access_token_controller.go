@@ -8,6 +8,7 @@ import (
 	"ya-tool-craft/internal/core/router"
 	"ya-tool-craft/internal/domain/service"
 	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/middleware"
 
 	_ "ya-tool-craft/internal/swagger"
 
@@ -30,7 +31,7 @@ type AuthIssueAccessTokenController struct {
 
 func (c AuthIssueAccessTokenController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodPost, Path: "/api/v1/auth/access-token", Handler: c.Handler},
+		{Method: http.MethodPost, Path: "/api/v1/auth/access-token", Handler: c.Handler, DemoModeAllowed: true},
 	}
 }
 
@@ -52,7 +53,7 @@ func (c *AuthIssueAccessTokenController) Handler(ctx *gin.Context) {
 		return
 	}
 
-	accessToken, valid, err := c.authService.IssueNewAccessToken(ctx, req.RefreshToken)
+	accessToken, rotatedRefreshToken, valid, err := c.authService.IssueNewAccessToken(ctx, req.RefreshToken)
 	if err != nil {
 		logger.Errorf(ctx, "failed to issue access token: %v", err)
 		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected issue access token error"))
@@ -65,7 +66,10 @@ func (c *AuthIssueAccessTokenController) Handler(ctx *gin.Context) {
 		return
 	}
 
+	// the exported auth event names the user whose session was refreshed
+	middleware.SetAuditActor(ctx, accessToken.UserID)
+
 	resp := IssueAccessTokenResponseDto{}
-	resp.FromEntity(accessToken)
+	resp.FromEntity(accessToken, rotatedRefreshToken)
 	c.Success(ctx, "", resp)
 }
@@ -12,9 +12,16 @@ type IssueAccessTokenRequestDto struct {
 type IssueAccessTokenResponseDto struct {
 	AccessToken          string `json:"access_token" example:"access_token_a"`
 	AccessTokenExpiresIn string `json:"expires_in" example:"2024-12-31T23:59:59Z" format:"date-time"`
+	// the new refresh token when refresh token rotation is enabled, the supplied one is not valid anymore
+	RefreshToken          string `json:"refresh_token,omitempty" example:"refresh_token_b"`
+	RefreshTokenExpiresIn string `json:"refresh_token_expires_in,omitempty" example:"2025-01-30T23:59:59Z" format:"date-time"`
 }
 
-func (d *IssueAccessTokenResponseDto) FromEntity(accessToken entity.AccessToken) {
+func (d *IssueAccessTokenResponseDto) FromEntity(accessToken entity.AccessToken, rotatedRefreshToken *entity.RefreshToken) {
 	d.AccessToken = accessToken.Token
 	d.AccessTokenExpiresIn = accessToken.ExpireAt.Format(time.RFC3339)
+	if rotatedRefreshToken != nil {
+		d.RefreshToken = rotatedRefreshToken.Token
+		d.RefreshTokenExpiresIn = rotatedRefreshToken.ExpireAt.Format(time.RFC3339)
+	}
 }
@@ -8,34 +8,41 @@ import (
 	"ya-tool-craft/internal/core/router"
 	"ya-tool-craft/internal/domain/service"
 	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/middleware"
 
 	_ "ya-tool-craft/internal/swagger"
 
 	"github.com/gin-gonic/gin"
 )
 
-func NewAuthLoginController(config config.Config, authService *service.AuthService) router.Controller {
+func NewAuthLoginController(config config.Config, authService *service.AuthService, settingsService *service.SystemSettingsService, syncService *service.SyncService, twoFAService *service.TwoFAService) router.Controller {
 	return AuthLoginController{
-		config:      config,
-		authService: authService,
+		config:          config,
+		authService:     authService,
+		settingsService: settingsService,
+		syncService:     syncService,
+		twoFAService:    twoFAService,
 	}
 }
 
 type AuthLoginController struct {
 	common.JsonResponse
 
-	config      config.Config
-	authService *service.AuthService
+	config          config.Config
+	authService     *service.AuthService
+	settingsService *service.SystemSettingsService
+	syncService     *service.SyncService
+	twoFAService    *service.TwoFAService
 }
 
 func (c AuthLoginController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodPost, Path: "/api/v1/auth/login", Handler: c.Login},
+		{Method: http.MethodPost, Path: "/api/v1/auth/login", Handler: c.Login, DemoModeAllowed: true},
 	}
 }
 
 // @Summary		login
-// @Description	login and get refresh token, access token
+// @Description	login and get refresh token, access token. The username can also be the verified email of the account
 // @Tags			Auth
 // @Accept			json
 // @Produce		json
@@ -46,9 +53,15 @@ func (c AuthLoginController) RouterInfo() []router.RouterInfo {
 func (c *AuthLoginController) Login(ctx *gin.Context) {
 	logger.Infof(ctx, "Login")
 
-	if !c.config.ENABLE_PASSWORD_LOGIN {
-		logger.Warnf(ctx, "Authentication is disabled in the configuration")
-		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.PasswordLoginIsNotEnabled, "password login is not enabled, please set env: ENABLE_PASSWORD_LOGIN"))
+	settings, err := c.settingsService.Settings(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get system settings: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected get system settings error"))
+		return
+	}
+	if !settings.EnablePasswordLogin {
+		logger.Warnf(ctx, "Password login is disabled in the system settings")
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.PasswordLoginIsNotEnabled, "password login is not enabled"))
 		return
 	}
 	var req LoginRequestDto
@@ -60,7 +73,7 @@ func (c *AuthLoginController) Login(ctx *gin.Context) {
 	res, twoFAToken, credentialValid, err := c.authService.Login(ctx, req.UserName, req.Password)
 	if err != nil {
 		logger.Errorf(ctx, "Failed to login: %v", err)
-		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected login error"))
+		c.Error(ctx, err)
 		return
 	}
 
@@ -72,18 +85,21 @@ func (c *AuthLoginController) Login(ctx *gin.Context) {
 
 	// Check if 2FA is required
 	if twoFAToken != nil {
-		err := error_code.NewErrorWithErrorCodeFAppendExtraData(
-			error_code.TwoFaTotpIsRequiredForLogin,
-			gin.H{
-				"two_fa_token": *twoFAToken,
-			},
-			"",
-		)
-		c.Error(ctx, err)
+		c.Error(ctx, newTwoFARequiredError(ctx, c.twoFAService, *twoFAToken))
+		return
+	}
+
+	// the exported auth event names the user who logged in
+	middleware.SetAuditActor(ctx, res.User.ID)
+
+	device, err := c.syncService.RegisterDevice(ctx, res.User.ID, ctx.Request.UserAgent())
+	if err != nil {
+		logger.Errorf(ctx, "Failed to register device: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected register device error"))
 		return
 	}
 
 	respDto := LoginResponseDto{}
-	respDto.FromEntity(res.AccessToken, res.RefreshToken)
+	respDto.FromEntity(res.AccessToken, res.RefreshToken, device)
 	c.Success(ctx, "", respDto)
 }
@@ -32,7 +32,7 @@ type AuthLogoutController struct {
 
 func (c AuthLogoutController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodPost, Path: "/api/v1/auth/logout", Handler: c.Handler},
+		{Method: http.MethodPost, Path: "/api/v1/auth/logout", Handler: c.Handler, DemoModeAllowed: true},
 	}
 }
 
@@ -48,7 +48,8 @@ func (c AuthLogoutController) RouterInfo() []router.RouterInfo {
 func (c *AuthLogoutController) Handler(ctx *gin.Context) {
 	logger.Infof(ctx, "Logout requested")
 
-	_, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	// a user who must change the password can still sign out
+	_, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeaderAllowingPasswordChange(ctx)
 	if err != nil {
 		c.Error(ctx, err)
 		return
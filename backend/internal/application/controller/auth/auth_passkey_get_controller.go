@@ -28,7 +28,7 @@ type PasskeyGetController struct {
 
 func (c PasskeyGetController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodGet, Path: "/api/v1/auth/passkeys", Handler: c.Handler},
+		{Method: http.MethodGet, Path: "/api/v1/auth/passkeys", Handler: c.Handler, DemoModeAllowed: true},
 	}
 }
 
@@ -8,13 +8,29 @@ import (
 )
 
 type PasskeyDto struct {
-	ID             int64      `json:"id"`
-	CredentialID   string     `json:"credential_id"`
-	DeviceName     *string    `json:"device_name"`
-	BackupEligible *bool      `json:"backup_eligible"`
-	BackupState    *bool      `json:"backup_state"`
-	CreatedAt      time.Time  `json:"created_at"`
-	LastUsedAt     *time.Time `json:"last_used_at"`
+	ID                 int64      `json:"id"`
+	CredentialID       string     `json:"credential_id"`
+	DeviceName         *string    `json:"device_name"`
+	AAGUID             *string    `json:"aaguid"`              // authenticator model identifier, null when the authenticator did not report one
+	AuthenticatorModel *string    `json:"authenticator_model"` // authenticator model known for the aaguid, such as "YubiKey 5 Series", null when unknown
+	BackupEligible     *bool      `json:"backup_eligible"`
+	BackupState        *bool      `json:"backup_state"`
+	CreatedAt          time.Time  `json:"created_at"`
+	LastUsedAt         *time.Time `json:"last_used_at"`
+}
+
+func (d *PasskeyDto) FromEntity(passkey entity.PasskeyEntity) {
+	*d = PasskeyDto{
+		ID:                 passkey.ID,
+		CredentialID:       base64.RawURLEncoding.EncodeToString(passkey.CredentialID),
+		DeviceName:         passkey.DeviceName,
+		AAGUID:             passkey.AAGUIDString(),
+		AuthenticatorModel: passkey.AuthenticatorModel(),
+		BackupEligible:     passkey.BackupEligible,
+		BackupState:        passkey.BackupState,
+		CreatedAt:          passkey.CreatedAt,
+		LastUsedAt:         passkey.LastUsedAt,
+	}
 }
 
 type PasskeyGetResponseDto struct {
@@ -24,14 +40,6 @@ type PasskeyGetResponseDto struct {
 func (d *PasskeyGetResponseDto) FromEntity(passkeys []entity.PasskeyEntity) {
 	d.Passkeys = make([]PasskeyDto, len(passkeys))
 	for i, passkey := range passkeys {
-		d.Passkeys[i] = PasskeyDto{
-			ID:             passkey.ID,
-			CredentialID:   base64.RawURLEncoding.EncodeToString(passkey.CredentialID),
-			DeviceName:     passkey.DeviceName,
-			BackupEligible: passkey.BackupEligible,
-			BackupState:    passkey.BackupState,
-			CreatedAt:      passkey.CreatedAt,
-			LastUsedAt:     passkey.LastUsedAt,
-		}
+		d.Passkeys[i].FromEntity(passkey)
 	}
 }
@@ -26,26 +26,33 @@ type PasskeyLoginChallengeController struct {
 
 func (c PasskeyLoginChallengeController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodPost, Path: "/api/v1/auth/passkey/login/challenge", Handler: c.Handler},
+		{Method: http.MethodPost, Path: "/api/v1/auth/passkey/login/challenge", Handler: c.Handler, DemoModeAllowed: true},
 	}
 }
 
 // @Summary		Begin passkey login
-// @Description	Generate challenge for passkey login. Returns WebAuthn CredentialRequestOptions for navigator.credentials.get()
+// @Description	Generate challenge for passkey login. Returns WebAuthn CredentialRequestOptions for navigator.credentials.get(). Challenges are rate limited per client ip
 // @Tags			Auth
 // @Produce		json
 // @Success		200	{object}	swagger.BaseSuccessResponse[PasskeyLoginChallengeResponseDto]
+// @Header			200,429	{integer}	X-RateLimit-Limit		"Challenges a client ip gets per PASSKEY_LOGIN_CHALLENGE_IP_WINDOW"
+// @Header			200,429	{integer}	X-RateLimit-Remaining	"Challenges left in the window"
+// @Header			200,429	{integer}	X-RateLimit-Reset		"Unix time when a challenge is available again"
+// @Header			429		{integer}	Retry-After				"Seconds until a challenge is available again"
 // @Failure		400	{object}	swagger.BaseFailResponse
+// @Failure		429	{object}	swagger.BaseFailResponse
+// @Failure		503	{object}	swagger.BaseFailResponse
 // @Router			/api/v1/auth/passkey/login/challenge [post]
 func (c *PasskeyLoginChallengeController) Handler(ctx *gin.Context) {
 	logger.Infof(ctx, "Begin passkey login requested")
 
-	options, err := c.authPasskeyService.LoginChallenge(ctx)
+	options, rateLimit, err := c.authPasskeyService.LoginChallenge(ctx)
 	if err != nil {
 		logger.Errorf(ctx, "Failed to begin passkey login: %v", err)
 		c.Error(ctx, err)
 		return
 	}
+	common.SetRateLimitHeaders(ctx, rateLimit)
 
 	c.Success(ctx, "Passkey login challenge generated", options)
 }
@@ -7,15 +7,17 @@ import (
 	"ya-tool-craft/internal/core/router"
 	"ya-tool-craft/internal/domain/service"
 	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/middleware"
 
 	_ "ya-tool-craft/internal/swagger"
 
 	"github.com/gin-gonic/gin"
 )
 
-func NewPasskeyLoginVerifyController(authPasskeyService *service.AuthPasskeyService) router.Controller {
+func NewPasskeyLoginVerifyController(authPasskeyService *service.AuthPasskeyService, syncService *service.SyncService) router.Controller {
 	return PasskeyLoginVerifyController{
 		authPasskeyService: authPasskeyService,
+		syncService:        syncService,
 	}
 }
 
@@ -23,11 +25,12 @@ type PasskeyLoginVerifyController struct {
 	common.JsonResponse
 
 	authPasskeyService *service.AuthPasskeyService
+	syncService        *service.SyncService
 }
 
 func (c PasskeyLoginVerifyController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodPost, Path: "/api/v1/auth/passkey/login/verify", Handler: c.Handler},
+		{Method: http.MethodPost, Path: "/api/v1/auth/passkey/login/verify", Handler: c.Handler, DemoModeAllowed: true},
 	}
 }
 
@@ -63,8 +66,18 @@ func (c *PasskeyLoginVerifyController) Handler(ctx *gin.Context) {
 		return
 	}
 
+	// the exported auth event names the user who logged in
+	middleware.SetAuditActor(ctx, accessToken.UserID)
+
+	device, err := c.syncService.RegisterDevice(ctx, accessToken.UserID, ctx.Request.UserAgent())
+	if err != nil {
+		logger.Errorf(ctx, "Failed to register device: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected register device error"))
+		return
+	}
+
 	var resp LoginResponseDto
-	resp.FromEntity(accessToken, refreshToken)
+	resp.FromEntity(accessToken, refreshToken, device)
 
 	c.Success(ctx, "Passkey login successful", resp)
 }
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewPasskeyUpdateController(authPasskeyService *service.AuthPasskeyService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return PasskeyUpdateController{
+		authPasskeyService:         authPasskeyService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type PasskeyUpdateController struct {
+	common.JsonResponse
+
+	authPasskeyService         *service.AuthPasskeyService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c PasskeyUpdateController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPut, Path: "/api/v1/auth/passkeys/:passkey_id", Handler: c.Handler},
+	}
+}
+
+// @Summary		Update passkey
+// @Description	Rename a passkey of the current user, the name is at most 64 characters and an empty name clears it. Returns the passkey with the authenticator model known for its AAGUID
+// @Tags			Auth
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string					true	"Bearer access token"
+// @Param			passkey_id		path		int64					true	"Passkey ID"
+// @Param			request			body		PasskeyUpdateRequestDto	true	"New passkey name"
+// @Success		200				{object}	swagger.BaseSuccessResponse[PasskeyDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/passkeys/{passkey_id} [put]
+func (c *PasskeyUpdateController) Handler(ctx *gin.Context) {
+	logger.Infof(ctx, "Update passkey requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	passkeyID, err := strconv.ParseInt(ctx.Param("passkey_id"), 10, 64)
+	if err != nil || passkeyID <= 0 {
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "passkey_id must be a positive integer"))
+		return
+	}
+
+	var req PasskeyUpdateRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	passkey, err := c.authPasskeyService.UpdatePasskey(ctx, user.ID, passkeyID, req.DeviceName)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to update passkey: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var respDto PasskeyDto
+	respDto.FromEntity(passkey)
+	c.Success(ctx, "Passkey updated successfully", respDto)
+}
@@ -0,0 +1,5 @@
+package auth
+
+type PasskeyUpdateRequestDto struct {
+	DeviceName string `json:"device_name" example:"MacBook Pro"` // new name of the passkey, an empty name clears it
+}
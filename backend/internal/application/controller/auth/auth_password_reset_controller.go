@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewPasswordResetController(passwordResetService *service.PasswordResetService, settingsService *service.SystemSettingsService) router.Controller {
+	return PasswordResetController{
+		passwordResetService: passwordResetService,
+		settingsService:      settingsService,
+	}
+}
+
+// PasswordResetController lets a user who forgot the password set a new one with a token emailed to the account,
+// the routes need no access token and are only open while password login is enabled.
+type PasswordResetController struct {
+	common.JsonResponse
+
+	passwordResetService *service.PasswordResetService
+	settingsService      *service.SystemSettingsService
+}
+
+func (c PasswordResetController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/auth/password-reset/request", Handler: c.Request},
+		{Method: http.MethodPost, Path: "/api/v1/auth/password-reset/complete", Handler: c.Complete},
+	}
+}
+
+// @Summary		Request a password reset
+// @Description	Email a single-use reset token to the account using the email, it expires after PASSWORD_RESET_TOKEN_TTL.
+// @Description	The response is the same whether an account uses the email or not. Requesting again drops the token sent before.
+// @Tags			Auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		PasswordResetRequestDto	true	"Account email"
+// @Success		200		{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400		{object}	swagger.BaseFailResponse
+// @Failure		403		{object}	swagger.BaseFailResponse
+// @Failure		503		{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/password-reset/request [post]
+func (c *PasswordResetController) Request(ctx *gin.Context) {
+	logger.Infof(ctx, "Password reset requested")
+
+	if !c.passwordLoginEnabled(ctx) {
+		return
+	}
+	var req PasswordResetRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Failed to bind request: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	if err := c.passwordResetService.StartReset(ctx, req.Email); err != nil {
+		logger.Errorf(ctx, "Failed to start password reset: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "If an account uses this email, a reset token was sent to it", gin.H{})
+}
+
+// @Summary		Complete a password reset
+// @Description	Set a new password with the emailed reset token and sign the account out of every device. The 2FA methods are kept.
+// @Tags			Auth
+// @Accept			json
+// @Produce		json
+// @Param			request	body		PasswordResetCompleteRequestDto	true	"Reset token and new password"
+// @Success		200		{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400		{object}	swagger.BaseFailResponse
+// @Failure		403		{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/password-reset/complete [post]
+func (c *PasswordResetController) Complete(ctx *gin.Context) {
+	logger.Infof(ctx, "Password reset complete requested")
+
+	if !c.passwordLoginEnabled(ctx) {
+		return
+	}
+	var req PasswordResetCompleteRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Failed to bind request: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	if err := c.passwordResetService.CompleteReset(ctx, req.Token, req.NewPassword); err != nil {
+		logger.Errorf(ctx, "Failed to complete password reset: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "Password reset, sign in with the new password", gin.H{})
+}
+
+// passwordLoginEnabled answers the request with an error when password login is disabled, a reset password could not be used
+func (c *PasswordResetController) passwordLoginEnabled(ctx *gin.Context) bool {
+	settings, err := c.settingsService.Settings(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get system settings: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected get system settings error"))
+		return false
+	}
+	if !settings.EnablePasswordLogin {
+		logger.Warnf(ctx, "Password login is disabled in the system settings")
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.PasswordLoginIsNotEnabled, "password login is not enabled"))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,10 @@
+package auth
+
+type PasswordResetRequestDto struct {
+	Email string `json:"email" binding:"required,email,max=255" example:"user@example.com"`
+}
+
+type PasswordResetCompleteRequestDto struct {
+	Token       string `json:"token" binding:"required,max=128" example:"Zk1tQ2dYV3lOb2h4..."`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=32" example:"new_password"`
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewSessionDeleteController(authService *service.AuthService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return SessionDeleteController{
+		authService:                authService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type SessionDeleteController struct {
+	common.JsonResponse
+
+	authService                *service.AuthService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c SessionDeleteController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodDelete, Path: "/api/v1/auth/sessions/:session_id", Handler: c.Handler},
+	}
+}
+
+// @Summary		Revoke session
+// @Description	Sign a session of the current user out, its refresh token and access tokens stop working. Revoking the current session signs the request out like a logout
+// @Tags			Auth
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			session_id		path		string	true	"Session ID"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/sessions/{session_id} [delete]
+func (c *SessionDeleteController) Handler(ctx *gin.Context) {
+	logger.Infof(ctx, "Revoke session requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	sessionID := ctx.Param("session_id")
+	if sessionID == "" {
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "session_id is required"))
+		return
+	}
+
+	if err := c.authService.RevokeSession(ctx, user.ID, sessionID); err != nil {
+		logger.Errorf(ctx, "Failed to revoke session: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "Session revoked successfully", gin.H{})
+}
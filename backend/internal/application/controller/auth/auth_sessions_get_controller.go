@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewSessionsGetController(authService *service.AuthService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return SessionsGetController{
+		authService:                authService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type SessionsGetController struct {
+	common.JsonResponse
+
+	authService                *service.AuthService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c SessionsGetController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/auth/sessions", Handler: c.Handler, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Get active sessions
+// @Description	List the signed in sessions of the current user, one per refresh token, the newest first
+// @Tags			Auth
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[SessionsGetResponseDto]
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/auth/sessions [get]
+func (c *SessionsGetController) Handler(ctx *gin.Context) {
+	logger.Infof(ctx, "Get sessions requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	sessions, err := c.authService.ActiveSessions(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get sessions: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	respDto := SessionsGetResponseDto{}
+	respDto.FromEntity(sessions, common.CurrentRefreshTokenHash(ctx))
+	c.Success(ctx, "", respDto)
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+type SessionDto struct {
+	ID       string    `json:"id"` // revokes the session with DELETE /api/v1/auth/sessions/{session_id}
+	IssueAt  time.Time `json:"issue_at"`
+	ExpireAt time.Time `json:"expire_at"`
+	Current  bool      `json:"current"` // the session of the request
+}
+
+type SessionsGetResponseDto struct {
+	Sessions []SessionDto `json:"sessions"`
+}
+
+func (d *SessionsGetResponseDto) FromEntity(sessions []entity.RefreshToken, currentSessionID string) {
+	d.Sessions = make([]SessionDto, len(sessions))
+	for i, session := range sessions {
+		d.Sessions[i] = SessionDto{
+			ID:       session.TokenHash,
+			IssueAt:  session.IssueAt,
+			ExpireAt: session.ExpireAt,
+			Current:  currentSessionID != "" && session.TokenHash == currentSessionID,
+		}
+	}
+}
@@ -37,21 +37,22 @@ func (c SSOBindingAddController) RouterInfo() []router.RouterInfo {
 }
 
 // @Summary		Add SSO binding
-// @Description	Bind a SSO account (github/google) to the current user
+// @Description	Bind a SSO account (github/google) to the current user, a user with 2FA confirms it with a 2FA code. The user is notified by email
 // @Tags			Auth
 // @Accept			json
 // @Produce		json
 // @Param			Authorization	header		string				true	"Bearer access token"
 // @Param			provider		path		string				true	"SSO provider (github/google)"
-// @Param			request			body		SSOLoginRequestDto	true	"OAuth code"
+// @Param			request			body		SSOBindingAddRequestDto	true	"OAuth code and 2FA code"
 // @Success		200				{object}	swagger.BaseSuccessResponse[any]
 // @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
 // @Router			/api/v1/auth/sso/{provider} [put]
 func (c *SSOBindingAddController) Handler(ctx *gin.Context) {
 	provider := ctx.Param("provider")
 	logger.Infof(ctx, "Add %s SSO binding requested", provider)
 
-	var req SSOLoginRequestDto
+	var req SSOBindingAddRequestDto
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
 		return
@@ -63,7 +64,7 @@ func (c *SSOBindingAddController) Handler(ctx *gin.Context) {
 		return
 	}
 
-	err = c.authService.AddSSOBindingForUser(ctx, user.ID, provider, req.OauthCode)
+	err = c.authService.AddSSOBindingForUser(ctx, user.ID, provider, req.OauthCode, req.TwoFACode)
 	if err != nil {
 		logger.Errorf(ctx, "Failed to add %s SSO binding: %v", provider, err)
 		c.Error(ctx, err)
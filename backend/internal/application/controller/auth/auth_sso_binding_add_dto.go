@@ -0,0 +1,6 @@
+package auth
+
+type SSOBindingAddRequestDto struct {
+	OauthCode string `json:"oauth_code" binding:"required,min=1" example:"xxxxxxxxx"`
+	TwoFACode string `json:"two_fa_code,omitempty" binding:"omitempty,max=64" example:"123456"` // TOTP code, the recovery code or an unused single-use recovery code, required when the user has 2FA enabled
+}
@@ -32,7 +32,7 @@ type SSOBindingGetController struct {
 
 func (c SSOBindingGetController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodGet, Path: "/api/v1/auth/sso/bindings", Handler: c.Handler},
+		{Method: http.MethodGet, Path: "/api/v1/auth/sso/bindings", Handler: c.Handler, DemoModeAllowed: true},
 	}
 }
 
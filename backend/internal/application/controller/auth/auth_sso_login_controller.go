@@ -8,29 +8,34 @@ import (
 	"ya-tool-craft/internal/core/router"
 	"ya-tool-craft/internal/domain/service"
 	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/middleware"
 
 	_ "ya-tool-craft/internal/swagger"
 
 	"github.com/gin-gonic/gin"
 )
 
-func NewSSOLoginController(config config.Config, authService *service.AuthService) router.Controller {
+func NewSSOLoginController(config config.Config, authService *service.AuthService, syncService *service.SyncService, twoFAService *service.TwoFAService) router.Controller {
 	return SSOLoginController{
-		config:      config,
-		authService: authService,
+		config:       config,
+		authService:  authService,
+		syncService:  syncService,
+		twoFAService: twoFAService,
 	}
 }
 
 type SSOLoginController struct {
 	common.JsonResponse
 
-	config      config.Config
-	authService *service.AuthService
+	config       config.Config
+	authService  *service.AuthService
+	syncService  *service.SyncService
+	twoFAService *service.TwoFAService
 }
 
 func (c SSOLoginController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodPost, Path: "/api/v1/auth/sso/:provider", Handler: c.SSOLogin},
+		{Method: http.MethodPost, Path: "/api/v1/auth/sso/:provider", Handler: c.SSOLogin, DemoModeAllowed: true},
 	}
 }
 
@@ -63,18 +68,21 @@ func (c *SSOLoginController) SSOLogin(ctx *gin.Context) {
 
 	// Check if 2FA is required
 	if twoFAToken != nil {
-		err := error_code.NewErrorWithErrorCodeFAppendExtraData(
-			error_code.TwoFaTotpIsRequiredForLogin,
-			gin.H{
-				"two_fa_token": *twoFAToken,
-			},
-			"",
-		)
-		c.Error(ctx, err)
+		c.Error(ctx, newTwoFARequiredError(ctx, c.twoFAService, *twoFAToken))
+		return
+	}
+
+	// the exported auth event names the user who logged in
+	middleware.SetAuditActor(ctx, res.User.ID)
+
+	device, err := c.syncService.RegisterDevice(ctx, res.User.ID, ctx.Request.UserAgent())
+	if err != nil {
+		logger.Errorf(ctx, "Failed to register device: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected register device error"))
 		return
 	}
 
 	respDto := LoginResponseDto{}
-	respDto.FromEntity(res.AccessToken, res.RefreshToken)
+	respDto.FromEntity(res.AccessToken, res.RefreshToken, device)
 	c.Success(ctx, "", respDto)
 }
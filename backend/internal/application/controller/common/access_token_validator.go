@@ -1,11 +1,13 @@
 package common
 
 import (
+	"errors"
 	"ya-tool-craft/internal/core/logger"
 	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/domain/service"
 	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,16 +15,19 @@ import (
 func NewAccessTokenHeaderValidator(
 	authService *service.AuthService,
 	userRepository repository.IUserRepository,
+	meteringService *service.MeteringService,
 ) AccessTokenHeaderValidator {
 	return AccessTokenHeaderValidator{
-		authService:    authService,
-		userRepository: userRepository,
+		authService:     authService,
+		userRepository:  userRepository,
+		meteringService: meteringService,
 	}
 }
 
 type AccessTokenHeaderValidator struct {
-	authService    *service.AuthService
-	userRepository repository.IUserRepository
+	authService     *service.AuthService
+	userRepository  repository.IUserRepository
+	meteringService *service.MeteringService
 }
 
 func (v *AccessTokenHeaderValidator) ValidateOptionalAccessTokenHeader(ctx *gin.Context) (user entity.UserEntity, accessTokenExists bool, err error) {
@@ -45,7 +50,23 @@ func (v *AccessTokenHeaderValidator) ValidateOptionalAccessTokenHeader(ctx *gin.
 
 }
 
+// ValidateAccessTokenHeader validates the access token and returns its user.
+// A user who must change the password is refused with PasswordChangeRequired, a disabled user with UserDisabled.
 func (v *AccessTokenHeaderValidator) ValidateAccessTokenHeader(ctx *gin.Context) (entity.UserEntity, error) {
+	user, err := v.ValidateAccessTokenHeaderAllowingPasswordChange(ctx)
+	if err != nil {
+		return entity.UserEntity{}, err
+	}
+	if user.PasswordChangeRequired {
+		logger.Errorf(ctx, "user %s must change the password first", user.ID)
+		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.PasswordChangeRequired, "Password must be changed first")
+	}
+	return user, nil
+}
+
+// ValidateAccessTokenHeaderAllowingPasswordChange validates the access token like ValidateAccessTokenHeader but lets
+// users who must change the password through, for the few endpoints they still need such as changing it.
+func (v *AccessTokenHeaderValidator) ValidateAccessTokenHeaderAllowingPasswordChange(ctx *gin.Context) (entity.UserEntity, error) {
 	accessTokenStr, err := GetAccessTokenHeader(ctx)
 	if err != nil {
 		logger.Errorf(ctx, "access token error from header: %v", err)
@@ -61,6 +82,8 @@ func (v *AccessTokenHeaderValidator) ValidateAccessTokenHeader(ctx *gin.Context)
 		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidAccessToken, "Access token is invalid")
 	}
 
+	ctx.Set(currentAccessTokenContextKey, accessToken)
+
 	userID := accessToken.UserID
 
 	user, exists, err := v.userRepository.GetByID(ctx, userID)
@@ -72,6 +95,51 @@ func (v *AccessTokenHeaderValidator) ValidateAccessTokenHeader(ctx *gin.Context)
 		logger.Errorf(ctx, "user not found: %s", userID)
 		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "User not found")
 	}
+	if user.Disabled {
+		logger.Errorf(ctx, "user is disabled: %s", userID)
+		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserDisabled, "User is disabled")
+	}
+
+	middleware.SetAuditActor(ctx, user.ID)
+
+	// every authenticated request is an api call of the user
+	if err := v.meteringService.RecordAPICall(ctx, user.ID); err != nil {
+		var codeErr error_code.ErrorWithErrorCode
+		if errors.As(err, &codeErr) {
+			return entity.UserEntity{}, err
+		}
+		logger.Errorf(ctx, "fail to record api call: %v", err)
+		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected metering error")
+	}
+	return user, nil
+}
+
+// ValidateAdminAccessTokenHeader validates the access token like ValidateAccessTokenHeader and requires the admin role.
+func (v *AccessTokenHeaderValidator) ValidateAdminAccessTokenHeader(ctx *gin.Context) (entity.UserEntity, error) {
+	user, err := v.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		return entity.UserEntity{}, err
+	}
+
+	if !user.HasRole(entity.UserRoleAdmin) {
+		logger.Errorf(ctx, "user %s is not an admin", user.ID)
+		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.Forbidden, "Admin role is required")
+	}
+
+	return user, nil
+}
+
+// ValidatePermissionAccessTokenHeader validates the access token like ValidateAccessTokenHeader and requires the permission.
+func (v *AccessTokenHeaderValidator) ValidatePermissionAccessTokenHeader(ctx *gin.Context, permission entity.UserPermissionEntity) (entity.UserEntity, error) {
+	user, err := v.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		return entity.UserEntity{}, err
+	}
+
+	if !user.HasPermission(permission) {
+		logger.Errorf(ctx, "user %s lacks permission %s", user.ID, permission)
+		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.Forbidden, "Permission %s is required", permission)
+	}
 
 	return user, nil
 }
@@ -1,13 +1,33 @@
 package common
 
 import (
+	"strconv"
 	"strings"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/error_code"
 	"ya-tool-craft/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// currentAccessTokenContextKey holds the access token the request was authenticated with
+const currentAccessTokenContextKey = "current_access_token"
+
+// CurrentRefreshTokenHash returns the hash of the refresh token the validated access token of the request was issued
+// for, it identifies the session of the request. It is empty until the access token header was validated.
+func CurrentRefreshTokenHash(ctx *gin.Context) string {
+	value, exists := ctx.Get(currentAccessTokenContextKey)
+	if !exists {
+		return ""
+	}
+	accessToken, ok := value.(entity.AccessToken)
+	if !ok {
+		return ""
+	}
+	return accessToken.RelativeRefreshToken
+}
+
 func GetAccessTokenHeader(ctx *gin.Context) (string, error) {
 	authHeader := ctx.GetHeader("Authorization")
 
@@ -35,3 +55,20 @@ func GetOptionalAccessTokenHeader(ctx *gin.Context) (token *string, err error) {
 	v := strings.TrimPrefix(authHeader, "Bearer ")
 	return &v, nil
 }
+
+// SetRateLimitHeaders reports the rate limit of the request in the X-RateLimit-* headers, see entity.RateLimitEntity.
+// Nothing is set when the limit is not known.
+func SetRateLimitHeaders(ctx *gin.Context, rateLimit entity.RateLimitEntity) {
+	if rateLimit.Limit <= 0 {
+		return
+	}
+	ctx.Header("X-RateLimit-Limit", strconv.Itoa(rateLimit.Limit))
+	ctx.Header("X-RateLimit-Remaining", strconv.Itoa(rateLimit.Remaining))
+	ctx.Header("X-RateLimit-Reset", strconv.FormatInt(rateLimit.ResetAt.Unix(), 10))
+}
+
+// setRateLimitedHeaders adds Retry-After to the X-RateLimit-* headers of a request the rate limit refused
+func setRateLimitedHeaders(ctx *gin.Context, rateLimit entity.RateLimitEntity) {
+	SetRateLimitHeaders(ctx, rateLimit)
+	ctx.Header("Retry-After", strconv.Itoa(rateLimit.RetryAfter(time.Now())))
+}
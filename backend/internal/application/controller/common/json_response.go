@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/core/logger"
 	"ya-tool-craft/internal/core/requestid"
+	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/error_code"
 
 	"net/http"
@@ -45,6 +47,60 @@ func (j *JsonResponse) SuccessWithCachedJsonString(ctx *gin.Context, message, ob
 	ctx.Data(http.StatusOK, "application/json", finalBody)
 }
 
+// SuccessWithStreamedData writes a success response whose data is written by writeData, for data too large to build
+// in memory at once. Nothing is sent before writeData first writes, an error until then is answered with Error.
+// Once the data started the status is sent already, so a later error cuts the body off and the client fails to parse it.
+func (j *JsonResponse) SuccessWithStreamedData(ctx *gin.Context, message string, writeData func(w io.Writer) error) {
+	tail, err := json.Marshal(gin.H{
+		"message":    message,
+		"request_id": requestid.GetRequestID(ctx),
+		"status":     "ok",
+	})
+	if err != nil {
+		j.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "fail generate streamed json response"))
+		return
+	}
+
+	w := &streamedDataWriter{ctx: ctx}
+	if err := writeData(w); err != nil {
+		if !w.started {
+			j.Error(ctx, err)
+			return
+		}
+		logger.Errorf(ctx, "streamed response cut off: %+v", err)
+		ctx.Abort()
+		return
+	}
+	if !w.started {
+		if _, err := w.Write([]byte("null")); err != nil {
+			logger.Errorf(ctx, "streamed response cut off: %v", err)
+			return
+		}
+	}
+	// the keys follow data in the order Success writes them, sorted
+	if _, err := w.Write(append([]byte(","), tail[1:]...)); err != nil {
+		logger.Errorf(ctx, "streamed response cut off: %v", err)
+	}
+}
+
+// streamedDataWriter sends the status and the head of a success response before the first data is written
+type streamedDataWriter struct {
+	ctx     *gin.Context
+	started bool
+}
+
+func (w *streamedDataWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.ctx.Header("Content-Type", "application/json; charset=utf-8")
+		w.ctx.Status(http.StatusOK)
+		if _, err := io.WriteString(w.ctx.Writer, `{"data":`); err != nil {
+			return 0, err
+		}
+	}
+	return w.ctx.Writer.Write(p)
+}
+
 func (j *JsonResponse) Error(ctx *gin.Context, err error) {
 	// Keep full multiline stack in local/test for readability; emit single-line logs with literal "\n" in other envs.
 	env := config.GetEnvName()
@@ -72,6 +128,10 @@ func (j *JsonResponse) Error(ctx *gin.Context, err error) {
 		if e.ExtraData != nil {
 			extraData = e.ExtraData
 		}
+		// a refused request tells the client when to come back, see entity.RateLimitEntity
+		if rateLimit, ok := e.ExtraData.(entity.RateLimitEntity); ok {
+			setRateLimitedHeaders(ctx, rateLimit)
+		}
 
 		logger.Errorf(ctx, "error response: error_code=%s, message=%s, extra_data=%+v", code, message, extraData)
 
@@ -16,6 +16,7 @@ import (
 	"ya-tool-craft/internal/core/router"
 	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
 
 	appembed "ya-tool-craft/internal/embed"
 
@@ -23,15 +24,22 @@ import (
 	"github.com/pkg/errors"
 )
 
-func NewFrontendAssetsHostController(config config.Config, migration repository.IMigration) router.Controller {
+func NewFrontendAssetsHostController(config config.Config, migration repository.IMigration, settingsService *service.SystemSettingsService) router.Controller {
 	assetFS, err := resolveAssetFS(config.FrontendAssetPath)
 	if err != nil {
 		panic("failed to resolve frontend assets: " + err.Error())
 	}
 	return &FrontendAssetsHostController{
-		Config:              config,
-		assetFS:             assetFS,
-		runtimeConfigScript: buildRuntimeConfigScript(config),
+		Config:  config,
+		assetFS: assetFS,
+		systemSettings: func(ctx context.Context) entity.SystemSettingsEntity {
+			settings, err := settingsService.Settings(ctx)
+			if err != nil {
+				logger.Errorf(ctx, "failed to get system settings for runtime config, fall back to env config: %v", err)
+				return settingsService.DefaultSettings()
+			}
+			return settings
+		},
 	}
 }
 
@@ -57,10 +65,11 @@ func resolveAssetFS(path string) (fs.FS, error) {
 type FrontendAssetsHostController struct {
 	common.JsonResponse
 
-	Config              config.Config
-	assetFS             fs.FS
-	runtimeConfigScript string
-	htmlCache           sync.Map // cacheKey -> []byte
+	Config    config.Config
+	assetFS   fs.FS
+	htmlCache sync.Map // cacheKey -> []byte, html with theme injected, runtime config is injected per request
+	// systemSettings returns the runtime settings, they can change without restart so they are not cached with the html
+	systemSettings func(ctx context.Context) entity.SystemSettingsEntity
 }
 
 // RouterInfo returns empty slice as this controller uses NoRoute
@@ -186,7 +195,7 @@ func (c *FrontendAssetsHostController) serveHTMLWithRuntimeConfig(ctx *gin.Conte
 	cacheKey := relativePath + "|" + themeClass + "|" + themeColor
 	if cached, ok := c.htmlCache.Load(cacheKey); ok {
 		logger.Infof(ctx, "html cache hit: %s", cacheKey)
-		ctx.Data(http.StatusOK, "text/html; charset=utf-8", cached.([]byte))
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", c.injectRuntimeConfig(ctx, cached.([]byte)))
 		return
 	}
 
@@ -212,24 +221,34 @@ func (c *FrontendAssetsHostController) serveHTMLWithRuntimeConfig(ctx *gin.Conte
 		`" data-theme="` + themeClass + `" data-theme-color="` + themeColor + `">`
 	html = strings.Replace(html, `<html lang="en" class="light theme-color-indigo" data-theme="light" data-theme-color="indigo">`, themeTag, 1)
 
-	// Inject runtime config (pre-computed at startup)
-	anchor := `<script id="__SSR_CONFIG__" data-runtime-config-anchor="true"></script>`
-	html = strings.Replace(html, anchor, c.runtimeConfigScript, 1)
-
 	result := []byte(html)
 	logger.Infof(ctx, "html cache store: %s", cacheKey)
 	c.htmlCache.Store(cacheKey, result)
-	ctx.Data(http.StatusOK, "text/html; charset=utf-8", result)
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", c.injectRuntimeConfig(ctx, result))
+}
+
+// injectRuntimeConfig replaces the runtime config anchor of a cached html with the current runtime config.
+func (c *FrontendAssetsHostController) injectRuntimeConfig(ctx *gin.Context, html []byte) []byte {
+	anchor := []byte(`<script id="__SSR_CONFIG__" data-runtime-config-anchor="true"></script>`)
+	script := buildRuntimeConfigScript(c.Config, c.systemSettings(ctx))
+	return bytes.Replace(html, anchor, []byte(script), 1)
 }
 
-func buildRuntimeConfigScript(cfg config.Config) string {
+func buildRuntimeConfigScript(cfg config.Config, settings entity.SystemSettingsEntity) string {
 	var runtimeConfig entity.FrontendRuntimeConfigEntity
-	runtimeConfig.SSO.Github.ClientID = cfg.SSO_GITHUB_CLIENT_ID
-	runtimeConfig.SSO.Github.RedirectURI = cfg.SSO_GITHUB_REDIRECT_URL
-	runtimeConfig.SSO.Google.ClientID = cfg.SSO_GOOGLE_CLIENT_ID
-	runtimeConfig.SSO.Google.RedirectURI = cfg.SSO_GOOGLE_REDIRECT_URL
-	runtimeConfig.EnablePasswordLogin = cfg.ENABLE_PASSWORD_LOGIN
-	runtimeConfig.EnableRegister = cfg.ENABLE_USER_REGISTRATION
+	// a disabled provider is sent without client id so the frontend hides its login button
+	if settings.EnableGithubSSO {
+		runtimeConfig.SSO.Github.ClientID = cfg.SSO_GITHUB_CLIENT_ID
+		runtimeConfig.SSO.Github.RedirectURI = cfg.SSO_GITHUB_REDIRECT_URL
+	}
+	if settings.EnableGoogleSSO {
+		runtimeConfig.SSO.Google.ClientID = cfg.SSO_GOOGLE_CLIENT_ID
+		runtimeConfig.SSO.Google.RedirectURI = cfg.SSO_GOOGLE_REDIRECT_URL
+	}
+	runtimeConfig.EnablePasswordLogin = settings.EnablePasswordLogin
+	runtimeConfig.EnableRegister = settings.EnableUserRegistration
+	runtimeConfig.MaintenanceMessage = settings.MaintenanceMessage
+	runtimeConfig.DemoMode = cfg.DemoMode
 
 	var dto ssrConfigDTO
 	dto.FromEntity(runtimeConfig)
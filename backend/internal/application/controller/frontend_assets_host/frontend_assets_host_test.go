@@ -1,6 +1,7 @@
 package frontend_assets_host
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"testing"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
 
 	"github.com/gin-gonic/gin"
 )
@@ -79,15 +81,15 @@ func TestServeStatic_RejectsNonGET(t *testing.T) {
 }
 
 func TestBuildRuntimeConfigScript_EnableRegister(t *testing.T) {
-	scriptEnabled := buildRuntimeConfigScript(config.Config{
-		ENABLE_USER_REGISTRATION: true,
+	scriptEnabled := buildRuntimeConfigScript(config.Config{}, entity.SystemSettingsEntity{
+		EnableUserRegistration: true,
 	})
 	if !strings.Contains(scriptEnabled, `"enable_register":true`) {
 		t.Fatalf("expected enable_register=true in runtime config script, got %q", scriptEnabled)
 	}
 
-	scriptDisabled := buildRuntimeConfigScript(config.Config{
-		ENABLE_USER_REGISTRATION: false,
+	scriptDisabled := buildRuntimeConfigScript(config.Config{}, entity.SystemSettingsEntity{
+		EnableUserRegistration: false,
 	})
 	if !strings.Contains(scriptDisabled, `"enable_register":false`) {
 		t.Fatalf("expected enable_register=false in runtime config script, got %q", scriptDisabled)
@@ -113,6 +115,30 @@ func newTestController(t *testing.T) testController {
 		baseDir: baseDir,
 		ctrl: &FrontendAssetsHostController{
 			assetFS: os.DirFS(baseDir),
+			systemSettings: func(ctx context.Context) entity.SystemSettingsEntity {
+				return entity.SystemSettingsEntity{}
+			},
 		},
 	}
 }
+
+func TestBuildRuntimeConfigScript_DisabledSSOHidesClientID(t *testing.T) {
+	cfg := config.Config{
+		SSO_GITHUB_CLIENT_ID: "github-client",
+		SSO_GOOGLE_CLIENT_ID: "google-client",
+	}
+
+	script := buildRuntimeConfigScript(cfg, entity.SystemSettingsEntity{
+		EnableGithubSSO:    true,
+		MaintenanceMessage: "down at 10pm",
+	})
+	if !strings.Contains(script, `"client_id":"github-client"`) {
+		t.Fatalf("expected github client id in runtime config script, got %q", script)
+	}
+	if strings.Contains(script, "google-client") {
+		t.Fatalf("expected disabled google sso to be hidden, got %q", script)
+	}
+	if !strings.Contains(script, `"maintenance_message":"down at 10pm"`) {
+		t.Fatalf("expected maintenance message in runtime config script, got %q", script)
+	}
+}
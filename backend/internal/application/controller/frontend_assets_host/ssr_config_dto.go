@@ -13,8 +13,10 @@ type ssrConfigDTO struct {
 			RedirectURI string `json:"redirect_uri"`
 		} `json:"google"`
 	} `json:"sso"`
-	EnablePasswordLogin bool `json:"password_login"`
-	EnableRegister      bool `json:"enable_register"`
+	EnablePasswordLogin bool   `json:"password_login"`
+	EnableRegister      bool   `json:"enable_register"`
+	MaintenanceMessage  string `json:"maintenance_message"`
+	DemoMode            bool   `json:"demo_mode"`
 }
 
 func (d *ssrConfigDTO) FromEntity(cfg entity.FrontendRuntimeConfigEntity) {
@@ -24,4 +26,6 @@ func (d *ssrConfigDTO) FromEntity(cfg entity.FrontendRuntimeConfigEntity) {
 	d.SSO.Google.RedirectURI = cfg.SSO.Google.RedirectURI
 	d.EnablePasswordLogin = cfg.EnablePasswordLogin
 	d.EnableRegister = cfg.EnableRegister
+	d.MaintenanceMessage = cfg.MaintenanceMessage
+	d.DemoMode = cfg.DemoMode
 }
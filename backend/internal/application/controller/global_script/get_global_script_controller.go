@@ -36,7 +36,7 @@ type GetGlobalScriptController struct {
 
 func (c GetGlobalScriptController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodGet, Path: "/api/v1/global-script", Handler: c.GetGlobalScript},
+		{Method: http.MethodGet, Path: "/api/v1/global-script", Handler: c.GetGlobalScript, DemoModeAllowed: true},
 	}
 }
 
@@ -0,0 +1,206 @@
+package graphql_api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gin-gonic/gin"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+const (
+	// graphqlComplexityLimit refuses operations that select more fields than this, counting list fields once
+	graphqlComplexityLimit = 500
+	// graphqlKeepAliveInterval is how often an idle subscription stream gets a ping, so proxies keep it open
+	graphqlKeepAliveInterval = 30 * time.Second
+)
+
+func NewGraphQLController(
+	config config.Config,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	cache repository.ICache,
+	toolRepository repository.IToolRepository,
+	toolService *service.ToolService,
+	namespaceService *service.NamespaceService,
+	meteringService *service.MeteringService,
+	toolEventService *service.ToolEventService,
+	userSettingsService *service.UserSettingsService,
+) router.Controller {
+	resolver := &Resolver{
+		cache:               cache,
+		toolService:         toolService,
+		toolRepository:      toolRepository,
+		namespaceService:    namespaceService,
+		meteringService:     meteringService,
+		toolEventService:    toolEventService,
+		userSettingsService: userSettingsService,
+	}
+	return GraphQLController{
+		config:                     config,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		server:                     newGraphQLServer(resolver),
+	}
+}
+
+// GraphQLController serves schema.graphqls. Queries are read with GET and POST, mutations only with POST, and
+// subscriptions stream as server-sent events to a POST with Accept: text/event-stream.
+type GraphQLController struct {
+	common.JsonResponse
+
+	config                     config.Config
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	server                     *handler.Server
+}
+
+func newGraphQLServer(resolver *Resolver) *handler.Server {
+	server := handler.New(NewExecutableSchema(Config{Resolvers: resolver}))
+	// the sse transport must come before POST, it is a POST too
+	server.AddTransport(transport.SSE{KeepAlivePingInterval: graphqlKeepAliveInterval})
+	server.AddTransport(transport.GET{})
+	server.AddTransport(transport.POST{})
+	server.Use(extension.Introspection{})
+	server.Use(extension.FixedComplexityLimit(graphqlComplexityLimit))
+	server.SetErrorPresenter(presentError)
+	server.SetRecoverFunc(recoverPanic)
+	return server
+}
+
+func (c GraphQLController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/graphql", Handler: c.Query, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/graphql", Handler: c.Execute},
+	}
+}
+
+// @Summary		Run a graphql query
+// @Description	Runs a query of the graphql schema over the tools and the profile of the user, mutations need POST.
+// @Description	Answered with GraphQLDisabled unless GRAPHQL_ENABLED is true.
+// @Tags			GraphQL
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			query			query		string	true	"The graphql query"
+// @Param			operationName	query		string	false	"The operation of the query to run"
+// @Param			variables		query		string	false	"The variables as a json object"
+// @Success		200				{object}	GraphQLResponseDto
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/graphql [get]
+func (c *GraphQLController) Query(ctx *gin.Context) {
+	c.serve(ctx)
+}
+
+// @Summary		Run a graphql operation
+// @Description	Runs a query or mutation of the graphql schema over the tools and the profile of the user.
+// @Description	A subscription is streamed as server-sent events when the request accepts text/event-stream.
+// @Description	Answered with GraphQLDisabled unless GRAPHQL_ENABLED is true.
+// @Tags			GraphQL
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string				true	"Bearer access token"
+// @Param			request			body		GraphQLRequestDto	true	"The graphql operation"
+// @Success		200				{object}	GraphQLResponseDto
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/graphql [post]
+func (c *GraphQLController) Execute(ctx *gin.Context) {
+	c.serve(ctx)
+}
+
+// serve checks the access token before the operation is parsed, so an anonymous request learns nothing of the schema
+func (c *GraphQLController) serve(ctx *gin.Context) {
+	logger.Infof(ctx, "GraphQL requested")
+
+	if !c.config.GraphQLEnabled {
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.GraphQLDisabled, "GRAPHQL_ENABLED is false"))
+		return
+	}
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+	c.serveAs(ctx, user)
+}
+
+// serveAs runs the operation of the request for the user, the resolvers read both with requestFromContext
+func (c *GraphQLController) serveAs(ctx *gin.Context, user entity.UserEntity) {
+	ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), requestContextKey{}, requestContext{
+		ginCtx: ctx,
+		user:   user,
+	}))
+	c.server.ServeHTTP(ctx.Writer, ctx.Request)
+}
+
+type requestContextKey struct{}
+
+type requestContext struct {
+	ginCtx *gin.Context
+	user   entity.UserEntity
+}
+
+// requestFromContext returns the gin context of the request, for logging and the services, and the user of the
+// access token. A resolver that keeps running after the request (a subscription) must use a copy of the gin context.
+func requestFromContext(ctx context.Context) (*gin.Context, entity.UserEntity) {
+	request := ctx.Value(requestContextKey{}).(requestContext)
+	return request.ginCtx, request.user
+}
+
+// presentError answers an error with an error code like the REST api does, with the code and the extra data in the
+// extensions. Any other error of a resolver is unexpected and answered without its details, errors of parsing and
+// validating the operation are answered as they are.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	presented := graphql.DefaultErrorPresenter(ctx, err)
+
+	var codeErr error_code.ErrorWithErrorCode
+	if errors.As(err, &codeErr) {
+		presented.Message = codeErr.ErrorCode.Message
+		presented.Extensions = map[string]any{
+			"error_code": codeErr.ErrorCode.Code,
+			"extra_data": codeErr.ExtraData,
+		}
+		logGraphQLError(ctx, fmt.Sprintf("%+v", err))
+		return presented
+	}
+
+	var parseErr *gqlerror.Error
+	if errors.As(err, &parseErr) && parseErr.Unwrap() == nil {
+		return presented
+	}
+
+	logGraphQLError(ctx, fmt.Sprintf("%+v", err))
+	presented.Message = error_code.InternalServerError.Message
+	presented.Extensions = map[string]any{"error_code": error_code.InternalServerError.Code}
+	return presented
+}
+
+func recoverPanic(ctx context.Context, recovered any) error {
+	logGraphQLError(ctx, fmt.Sprintf("panic: %v", recovered))
+	return error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "graphql resolver panicked")
+}
+
+// logGraphQLError logs with the request id when the error belongs to a request the controller served
+func logGraphQLError(ctx context.Context, message string) {
+	if request, ok := ctx.Value(requestContextKey{}).(requestContext); ok {
+		logger.Errorf(request.ginCtx, "GraphQL error: %s", message)
+		return
+	}
+	logger.Errorf(ctx, "GraphQL error: %s", message)
+}
@@ -0,0 +1,339 @@
+package graphql_api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const testUserID = entity.UserIDEntity("user-1")
+
+type graphQLTestDeps struct {
+	toolRepo      *mockgen.MockIToolRepository
+	namespaceRepo *mockgen.MockINamespaceRepository
+	toolEventRepo *mockgen.MockIToolEventRepository
+	cache         *fixtures.FakeCache
+}
+
+// newTestGraphQLServer serves the graphql api of the test user, the access token check of the controller is skipped
+func newTestGraphQLServer(t *testing.T) (*httptest.Server, graphQLTestDeps) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger.InitLogger(config.Config{LogLevel: "error", LogFormat: "text"})
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	clock := fixtures.NewFakeClock(time.Now())
+	deps := graphQLTestDeps{
+		toolRepo:      mockgen.NewMockIToolRepository(ctrl),
+		namespaceRepo: mockgen.NewMockINamespaceRepository(ctrl),
+		toolEventRepo: mockgen.NewMockIToolEventRepository(ctrl),
+		cache:         fixtures.NewFakeCache(clock),
+	}
+	cfg := config.Config{GraphQLEnabled: true, ToolSecretScanMode: service.ToolSecretScanModeWarn}
+	settingRepo := mockgen.NewMockISystemSettingRepository(ctrl)
+	settingRepo.EXPECT().AllSettings(gomock.Any()).Return(nil, nil).AnyTimes()
+	settingsService := service.NewSystemSettingsService(settingRepo, deps.cache, cfg, clock)
+	meteringService := service.NewMeteringService(nil, deps.toolRepo, nil, clock, cfg)
+	controller := NewGraphQLController(
+		cfg,
+		common.AccessTokenHeaderValidator{},
+		deps.cache,
+		deps.toolRepo,
+		service.NewToolService(deps.toolRepo, nil, settingsService, nil, meteringService, nil, clock, cfg),
+		service.NewNamespaceService(deps.namespaceRepo),
+		meteringService,
+		service.NewToolEventService(deps.toolEventRepo),
+		nil,
+	).(GraphQLController)
+
+	engine := gin.New()
+	engine.Any("/graphql", func(ctx *gin.Context) {
+		controller.serveAs(ctx, entity.UserEntity{ID: testUserID, Name: "alice", LoginCount: 3})
+	})
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+	return server, deps
+}
+
+type graphQLTestResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, server *httptest.Server, query string, variables map[string]any) graphQLTestResponse {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/graphql", "application/json", strings.NewReader(string(body)))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var result graphQLTestResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return result
+}
+
+func TestGraphQLController_Disabled(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+	logger.InitLogger(config.Config{LogLevel: "error", LogFormat: "text"})
+
+	controller := &GraphQLController{config: config.Config{GraphQLEnabled: false}}
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ me { id } }"}`))
+
+	controller.Execute(ctx)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Contains(t, w.Body.String(), `"error_code":"GraphQLDisabled"`)
+}
+
+func TestGraphQL_QueryReturnsTheSelectedFields(t *testing.T) {
+	t.Parallel()
+	server, deps := newTestGraphQLServer(t)
+
+	namespace := "utils"
+	deps.toolRepo.EXPECT().ListTools(testUserID, entity.ToolListOptions{
+		Namespace: &namespace,
+		Archived:  entity.ToolArchiveFilterInclude,
+		SortBy:    entity.ToolSortByName,
+		Limit:     10,
+	}).Return([]entity.ToolEntity{{UniqueID: "tool-uid-1", Name: "Base64", Source: "secret source"}}, 12, nil)
+
+	resp := postGraphQL(t, server, `{
+		me { id name loginCount }
+		tools(filter: {namespace: "utils", archived: INCLUDE}, sortBy: NAME, limit: 10) { total tools { uid name } }
+	}`, nil)
+
+	require.Empty(t, resp.Errors)
+	require.Equal(t, map[string]any{"id": "user-1", "name": "alice", "loginCount": float64(3)}, resp.Data["me"])
+	require.Equal(t, map[string]any{
+		"total": float64(12),
+		"tools": []any{map[string]any{"uid": "tool-uid-1", "name": "Base64"}},
+	}, resp.Data["tools"])
+}
+
+func TestGraphQL_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("an error code is answered in the extensions", func(t *testing.T) {
+		t.Parallel()
+		server, deps := newTestGraphQLServer(t)
+		deps.toolRepo.EXPECT().AllTools(testUserID).Return(entity.ToolsEntity{}, nil)
+
+		resp := postGraphQL(t, server, `{ tool(uid: "tool-missing") { name } }`, nil)
+
+		require.Len(t, resp.Errors, 1)
+		require.Equal(t, error_code.ToolNotFound.Message, resp.Errors[0].Message)
+		require.Equal(t, error_code.ToolNotFound.Code, resp.Errors[0].Extensions["error_code"])
+	})
+
+	t.Run("an unexpected error hides its details", func(t *testing.T) {
+		t.Parallel()
+		server, deps := newTestGraphQLServer(t)
+		deps.toolRepo.EXPECT().AllTools(testUserID).Return(entity.ToolsEntity{}, errors.New("dial tcp 10.0.0.5:5432"))
+
+		resp := postGraphQL(t, server, `{ tool(uid: "tool-1") { name } }`, nil)
+
+		require.Len(t, resp.Errors, 1)
+		require.Equal(t, error_code.InternalServerError.Code, resp.Errors[0].Extensions["error_code"])
+		require.NotContains(t, resp.Errors[0].Message, "10.0.0.5")
+	})
+
+	t.Run("an invalid operation is answered as it is", func(t *testing.T) {
+		t.Parallel()
+		server, _ := newTestGraphQLServer(t)
+
+		resp := postGraphQL(t, server, `{ tools { unknownField } }`, nil)
+
+		require.Len(t, resp.Errors, 1)
+		require.Contains(t, resp.Errors[0].Message, "unknownField")
+	})
+
+	t.Run("a page over the max limit is refused", func(t *testing.T) {
+		t.Parallel()
+		server, _ := newTestGraphQLServer(t)
+
+		resp := postGraphQL(t, server, `{ tools(limit: 1000) { total } }`, nil)
+
+		require.Len(t, resp.Errors, 1)
+		require.Equal(t, error_code.InvalidRequestParameters.Code, resp.Errors[0].Extensions["error_code"])
+	})
+}
+
+func TestGraphQL_MutationsNeedPost(t *testing.T) {
+	t.Parallel()
+	server, _ := newTestGraphQLServer(t)
+
+	query := url.Values{"query": {`mutation { deleteTool(uid: "tool-1") }`}}
+	resp, err := http.Get(server.URL + "/graphql?" + query.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var result graphQLTestResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Len(t, result.Errors, 1)
+	require.Nil(t, result.Data)
+}
+
+func TestGraphQL_CreateTool(t *testing.T) {
+	t.Parallel()
+
+	const mutation = `mutation($input: CreateToolInput!) {
+		createTool(input: $input) { tool { uid id name category uiWidgets extraInfo { key value } } warnings { rule } }
+	}`
+
+	t.Run("fields left out come from the namespace", func(t *testing.T) {
+		t.Parallel()
+		server, deps := newTestGraphQLServer(t)
+		deps.cache.Set(t.Context(), "tools:user-1", "cached")
+
+		deps.namespaceRepo.EXPECT().GetNamespace(gomock.Any(), testUserID, "utils").
+			Return(entity.NamespaceEntity{Name: "utils", DefaultCategory: "text", DefaultUiWidgets: `[{"id":"input"}]`}, true, nil)
+		deps.toolRepo.EXPECT().CreateTool(testUserID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ entity.UserIDEntity, tool entity.ToolEntity, source entity.ToolEventSourceEntity) error {
+				require.Equal(t, "Base64", tool.Name)
+				require.Equal(t, testUserID, source.ActorID)
+				return nil
+			})
+
+		resp := postGraphQL(t, server, mutation, map[string]any{"input": map[string]any{
+			"id":         "base64",
+			"name":       "Base64",
+			"namespace":  "utils",
+			"isActivate": true,
+			"source":     "function handler() {}",
+			"extraInfo":  []map[string]any{{"key": "b", "value": "2"}, {"key": "a", "value": "1"}},
+		}})
+
+		require.Empty(t, resp.Errors)
+		tool := resp.Data["createTool"].(map[string]any)["tool"].(map[string]any)
+		require.NotEmpty(t, tool["uid"])
+		require.Equal(t, "base64", tool["id"])
+		require.Equal(t, "text", tool["category"])
+		require.Equal(t, `[{"id":"input"}]`, tool["uiWidgets"])
+		require.Equal(t, []any{
+			map[string]any{"key": "a", "value": "1"},
+			map[string]any{"key": "b", "value": "2"},
+		}, tool["extraInfo"])
+		require.Equal(t, []any{}, resp.Data["createTool"].(map[string]any)["warnings"])
+
+		_, found, err := deps.cache.Get(t.Context(), "tools:user-1")
+		require.NoError(t, err)
+		require.False(t, found, "the cached tool list must be dropped")
+	})
+
+	t.Run("the input is checked like the REST api", func(t *testing.T) {
+		t.Parallel()
+		server, _ := newTestGraphQLServer(t)
+
+		resp := postGraphQL(t, server, mutation, map[string]any{"input": map[string]any{
+			"name":       strings.Repeat("x", 256),
+			"namespace":  "utils",
+			"isActivate": true,
+			"source":     "function handler() {}",
+		}})
+
+		require.Len(t, resp.Errors, 1)
+		require.Equal(t, error_code.InvalidRequestParameters.Code, resp.Errors[0].Extensions["error_code"])
+	})
+}
+
+func TestGraphQL_UpdateTool_KeepsTheFieldsLeftOut(t *testing.T) {
+	t.Parallel()
+	server, deps := newTestGraphQLServer(t)
+
+	current := entity.ToolEntity{
+		UniqueID:  "tool-uid-1",
+		ID:        "base64",
+		Name:      "Base64",
+		Namespace: "utils",
+		Category:  "text",
+		Source:    "function handler() {}",
+		ExtraInfo: map[string]string{"a": "1"},
+	}
+	updated := current
+	updated.Name = "Base64 Encode"
+	gomock.InOrder(
+		deps.toolRepo.EXPECT().AllTools(testUserID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{current}}, nil),
+		deps.toolRepo.EXPECT().UpdateTool(testUserID, updated, gomock.Any()).Return(nil),
+		deps.toolRepo.EXPECT().AllTools(testUserID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{updated}}, nil),
+	)
+
+	resp := postGraphQL(t, server, `mutation {
+		updateTool(uid: "tool-uid-1", input: {name: "Base64 Encode"}) { tool { name category source } }
+	}`, nil)
+
+	require.Empty(t, resp.Errors)
+	require.Equal(t, map[string]any{
+		"name":     "Base64 Encode",
+		"category": "text",
+		"source":   "function handler() {}",
+	}, resp.Data["updateTool"].(map[string]any)["tool"])
+}
+
+func TestGraphQL_SubscriptionStreamsNewToolEvents(t *testing.T) {
+	t.Parallel()
+	server, deps := newTestGraphQLServer(t)
+
+	// the events of before the subscription are skipped
+	deps.toolEventRepo.EXPECT().ListEvents(gomock.Any(), testUserID, "tool-uid-1", int64(0), 1).
+		Return([]entity.ToolEventEntity{{ID: 5}}, nil)
+	deps.toolEventRepo.EXPECT().ListEvents(gomock.Any(), testUserID, "tool-uid-1", int64(0), gomock.Any()).
+		Return([]entity.ToolEventEntity{
+			{ID: 6, ToolUID: "tool-uid-1", Type: entity.ToolEventTypeUpdated, ChangedFields: []string{"name"}},
+			{ID: 5, ToolUID: "tool-uid-1", Type: entity.ToolEventTypeCreated},
+		}, nil).
+		AnyTimes()
+
+	body := `{"query":"subscription { toolEvents(toolUid: \"tool-uid-1\") { id type changedFields } }"}`
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL+"/graphql", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Contains(t, resp.Header.Get("Content-Type"), "text/event-stream")
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var result graphQLTestResponse
+		require.NoError(t, json.Unmarshal([]byte(data), &result))
+		require.Empty(t, result.Errors)
+		require.Equal(t, map[string]any{
+			"id":            float64(6),
+			"type":          "UPDATED",
+			"changedFields": []any{"name"},
+		}, result.Data["toolEvents"])
+		return
+	}
+	t.Fatalf("the stream ended without an event: %v", scanner.Err())
+}
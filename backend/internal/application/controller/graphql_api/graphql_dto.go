@@ -0,0 +1,257 @@
+package graphql_api
+
+import (
+	"cmp"
+	"slices"
+	"time"
+	"ya-tool-craft/internal/application/controller/tools"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+const (
+	// toolsDefaultLimit and toolsMaxLimit bound a page of tools like GET /api/v1/tools/list
+	toolsDefaultLimit = 50
+	toolsMaxLimit     = 200
+)
+
+// GraphQLRequestDto documents the body of POST /graphql, the operation is read by the graphql server
+type GraphQLRequestDto struct {
+	Query         string         `json:"query" example:"{ me { name } tools(limit: 10) { total tools { uid name } } }"`
+	OperationName string         `json:"operationName,omitempty" example:""`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLResponseDto documents the answer of /graphql, an operation that fails in part has data and errors
+type GraphQLResponseDto struct {
+	Data   map[string]any   `json:"data"`
+	Errors []map[string]any `json:"errors,omitempty"`
+}
+
+type UserDto struct {
+	ID                  string     `json:"id"`
+	Name                string     `json:"name"`
+	Mail                *string    `json:"mail"`
+	LastLoginAt         *time.Time `json:"lastLoginAt"`
+	LoginCount          int        `json:"loginCount"`
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt"`
+}
+
+func (dto *UserDto) FromEntity(user entity.UserEntity) {
+	dto.ID = string(user.ID)
+	dto.Name = user.Name
+	dto.Mail = user.Mail
+	dto.LastLoginAt = user.LastLoginAt
+	dto.LoginCount = user.LoginCount
+	dto.DeletionScheduledAt = user.DeletionScheduledAt
+}
+
+type UserSettingDto struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func userSettingsFromEntity(settings []entity.UserSettingDetailEntity) []UserSettingDto {
+	return lo.Map(settings, func(setting entity.UserSettingDetailEntity, _ int) UserSettingDto {
+		return UserSettingDto{Key: setting.Key, Value: setting.Value}
+	})
+}
+
+type ToolDto struct {
+	UID               string              `json:"uid"`
+	ID                string              `json:"id"`
+	Name              string              `json:"name"`
+	Namespace         string              `json:"namespace"`
+	Category          string              `json:"category"`
+	Description       string              `json:"description"`
+	IsActivate        bool                `json:"isActivate"`
+	IsArchived        bool                `json:"isArchived"`
+	RealtimeExecution bool                `json:"realtimeExecution"`
+	UiWidgets         string              `json:"uiWidgets"`
+	Source            string              `json:"source"`
+	ExtraInfo         []ExtraInfoEntryDto `json:"extraInfo"`
+	CreatedAt         time.Time           `json:"createdAt"`
+	UpdatedAt         time.Time           `json:"updatedAt"`
+}
+
+func (dto *ToolDto) FromEntity(tool entity.ToolEntity) {
+	dto.UID = tool.UniqueID
+	dto.ID = tool.ID
+	dto.Name = tool.Name
+	dto.Namespace = tool.Namespace
+	dto.Category = tool.Category
+	dto.Description = tool.Description
+	dto.IsActivate = tool.IsActivate
+	dto.IsArchived = tool.IsArchived
+	dto.RealtimeExecution = tool.RealtimeExecution
+	dto.UiWidgets = tool.UiWidgets
+	dto.Source = tool.Source
+	dto.ExtraInfo = extraInfoFromEntity(tool.ExtraInfo)
+	dto.CreatedAt = tool.CreatedAt
+	dto.UpdatedAt = tool.UpdatedAt
+}
+
+func toolsFromEntity(tools []entity.ToolEntity) []ToolDto {
+	return lo.Map(tools, func(tool entity.ToolEntity, _ int) ToolDto {
+		dto := ToolDto{}
+		dto.FromEntity(tool)
+		return dto
+	})
+}
+
+// ExtraInfoEntryDto is a key of the extra info of a tool, graphql has no map type
+type ExtraInfoEntryDto struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// extraInfoFromEntity lists the extra info by key, so the order is the same on every read
+func extraInfoFromEntity(extraInfo map[string]string) []ExtraInfoEntryDto {
+	entries := lo.MapToSlice(extraInfo, func(key string, value string) ExtraInfoEntryDto {
+		return ExtraInfoEntryDto{Key: key, Value: value}
+	})
+	slices.SortFunc(entries, func(a, b ExtraInfoEntryDto) int { return cmp.Compare(a.Key, b.Key) })
+	return entries
+}
+
+// extraInfoToEntity returns nil for nil entries, a later entry wins over an earlier one with the same key
+func extraInfoToEntity(entries []ExtraInfoEntryDto) map[string]string {
+	if entries == nil {
+		return nil
+	}
+	return lo.SliceToMap(entries, func(entry ExtraInfoEntryDto) (string, string) {
+		return entry.Key, entry.Value
+	})
+}
+
+type ToolPageDto struct {
+	Tools []ToolDto `json:"tools"`
+	Total int       `json:"total"`
+}
+
+type ToolFilterDto struct {
+	Archived   entity.ToolArchiveFilter `json:"archived"`
+	Category   *string                  `json:"category"`
+	Namespace  *string                  `json:"namespace"`
+	IsActivate *bool                    `json:"isActivate"`
+}
+
+// ToEntity builds the options of a tool listing, a nil filter matches every tool that is not archived.
+func (dto *ToolFilterDto) ToEntity(sortBy entity.ToolSortBy, limit int, offset int) entity.ToolListOptions {
+	options := entity.ToolListOptions{
+		Archived: entity.ToolArchiveFilterExclude,
+		SortBy:   sortBy,
+		Limit:    limit,
+		Offset:   offset,
+	}
+	if dto != nil {
+		options.Archived = lo.Ternary(dto.Archived == "", entity.ToolArchiveFilterExclude, dto.Archived)
+		options.Category = dto.Category
+		options.Namespace = dto.Namespace
+		options.IsActivate = dto.IsActivate
+	}
+	return options
+}
+
+type ToolEventDto struct {
+	ID            int64                `json:"id"`
+	ToolUID       string               `json:"toolUid"`
+	Type          entity.ToolEventType `json:"type"`
+	ActorID       string               `json:"actorId"`
+	DeviceID      string               `json:"deviceId"`
+	ChangedFields []string             `json:"changedFields"`
+	CreatedAt     time.Time            `json:"createdAt"`
+}
+
+func (dto *ToolEventDto) FromEntity(event entity.ToolEventEntity) {
+	dto.ID = event.ID
+	dto.ToolUID = event.ToolUID
+	dto.Type = event.Type
+	dto.ActorID = string(event.ActorID)
+	dto.DeviceID = event.DeviceID
+	dto.ChangedFields = lo.Ternary(event.ChangedFields == nil, []string{}, event.ChangedFields)
+	dto.CreatedAt = event.CreatedAt
+}
+
+func toolEventsFromEntity(events []entity.ToolEventEntity) []ToolEventDto {
+	return lo.Map(events, func(event entity.ToolEventEntity, _ int) ToolEventDto {
+		dto := ToolEventDto{}
+		dto.FromEntity(event)
+		return dto
+	})
+}
+
+type ToolSaveResultDto struct {
+	Tool     ToolDto                      `json:"tool"`
+	Warnings []tools.ToolSecretWarningDto `json:"warnings"`
+}
+
+func (dto *ToolSaveResultDto) FromEntity(tool entity.ToolEntity, findings []entity.ToolSecretFindingEntity) {
+	dto.Tool.FromEntity(tool)
+	dto.Warnings = lo.Map(findings, func(finding entity.ToolSecretFindingEntity, _ int) tools.ToolSecretWarningDto {
+		return tools.ToolSecretWarningDto{Rule: finding.Rule, Line: finding.Line, Masked: finding.Masked}
+	})
+}
+
+// CreateToolInputDto is checked with the rules of tools.CreateToolRequestDto
+type CreateToolInputDto struct {
+	ID                *string             `json:"id" binding:"omitempty,max=128"`
+	Name              string              `json:"name" binding:"required,min=1,max=255"`
+	Namespace         string              `json:"namespace" binding:"required,min=1,max=255"`
+	IsActivate        bool                `json:"isActivate"`
+	RealtimeExecution *bool               `json:"realtimeExecution"`
+	UiWidgets         *string             `json:"uiWidgets"`
+	Source            string              `json:"source" binding:"required"`
+	Description       *string             `json:"description"`
+	ExtraInfo         []ExtraInfoEntryDto `json:"extraInfo"`
+	Category          *string             `json:"category" binding:"omitempty,max=255"`
+}
+
+// ToEntity builds the tool like POST /api/v1/tools/create, the fields left out are taken from the defaults of its
+// namespace.
+func (dto CreateToolInputDto) ToEntity(defaults entity.NamespaceEntity) entity.ToolEntity {
+	return tools.CreateToolRequestDto{
+		ID:                lo.FromPtr(dto.ID),
+		Name:              dto.Name,
+		Namespace:         dto.Namespace,
+		IsActivate:        dto.IsActivate,
+		RealtimeExecution: dto.RealtimeExecution,
+		UiWidgets:         dto.UiWidgets,
+		Source:            dto.Source,
+		Description:       dto.Description,
+		ExtraInfo:         extraInfoToEntity(dto.ExtraInfo),
+		Category:          dto.Category,
+	}.ToEntity(defaults)
+}
+
+// UpdateToolInputDto changes only the fields that are set
+type UpdateToolInputDto struct {
+	ID                *string             `json:"id" binding:"omitempty,min=1,max=128"`
+	Name              *string             `json:"name" binding:"omitempty,min=1,max=255"`
+	Namespace         *string             `json:"namespace" binding:"omitempty,min=1,max=255"`
+	IsActivate        *bool               `json:"isActivate"`
+	RealtimeExecution *bool               `json:"realtimeExecution"`
+	UiWidgets         *string             `json:"uiWidgets"`
+	Source            *string             `json:"source"`
+	Description       *string             `json:"description"`
+	ExtraInfo         []ExtraInfoEntryDto `json:"extraInfo"`
+	Category          *string             `json:"category" binding:"omitempty,max=255"`
+}
+
+// ApplyTo returns the tool with the fields of the input that are set, the updated at is set by the repository.
+func (dto UpdateToolInputDto) ApplyTo(tool entity.ToolEntity) entity.ToolEntity {
+	tool.ID = lo.FromPtrOr(dto.ID, tool.ID)
+	tool.Name = lo.FromPtrOr(dto.Name, tool.Name)
+	tool.Namespace = lo.FromPtrOr(dto.Namespace, tool.Namespace)
+	tool.IsActivate = lo.FromPtrOr(dto.IsActivate, tool.IsActivate)
+	tool.RealtimeExecution = lo.FromPtrOr(dto.RealtimeExecution, tool.RealtimeExecution)
+	tool.UiWidgets = lo.FromPtrOr(dto.UiWidgets, tool.UiWidgets)
+	tool.Source = lo.FromPtrOr(dto.Source, tool.Source)
+	tool.Description = lo.FromPtrOr(dto.Description, tool.Description)
+	tool.Category = lo.FromPtrOr(dto.Category, tool.Category)
+	if dto.ExtraInfo != nil {
+		tool.ExtraInfo = extraInfoToEntity(dto.ExtraInfo)
+	}
+	return tool
+}
@@ -0,0 +1,25 @@
+package graphql_api
+
+//go:generate go run github.com/99designs/gqlgen generate --config gqlgen.yml
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+)
+
+// toolEventsPollInterval is how often a tool events subscription reads the event log for new events. Reading the log
+// instead of a notifier in memory sees the changes made through every instance of a deployment.
+const toolEventsPollInterval = 2 * time.Second
+
+// Resolver answers the operations of schema.graphqls with the services the REST controllers use. The user of an
+// operation is the one of the access token the controller validated, see requestFromContext.
+type Resolver struct {
+	cache               repository.ICache
+	toolService         *service.ToolService
+	toolRepository      repository.IToolRepository
+	namespaceService    *service.NamespaceService
+	meteringService     *service.MeteringService
+	toolEventService    *service.ToolEventService
+	userSettingsService *service.UserSettingsService
+}
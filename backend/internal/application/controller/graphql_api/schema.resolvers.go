@@ -0,0 +1,233 @@
+package graphql_api
+
+// This file will be automatically regenerated based on the schema, any resolver
+// implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.87
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/application/controller/tools"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/samber/lo"
+)
+
+// CreateTool is the resolver for the createTool field.
+func (r *mutationResolver) CreateTool(ctx context.Context, input CreateToolInputDto) (*ToolSaveResultDto, error) {
+	c, user := requestFromContext(ctx)
+	logger.Infof(c, "GraphQL create tool requested")
+
+	if err := binding.Validator.ValidateStruct(input); err != nil {
+		return nil, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error())
+	}
+
+	defaults, err := r.namespaceService.ToolDefaults(c, user.ID, input.Namespace)
+	if err != nil {
+		logger.Errorf(c, "Failed to get the defaults of namespace %s for user %s: %v", input.Namespace, user.ID, err)
+		return nil, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected fetch namespace error")
+	}
+	tool := input.ToEntity(defaults)
+
+	created, findings, err := r.toolService.SaveNewTool(c, user.ID, tool)
+	if err != nil {
+		return nil, err
+	}
+	if err := tools.DeleteToolsCache(c, r.cache, user.ID); err != nil {
+		logger.Errorf(c, "Failed to delete cache for user %s: %v", user.ID, err)
+		return nil, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error")
+	}
+
+	logger.Infof(c, "Tool created successfully for user %s with tool uid %s", user.ID, created.UniqueID)
+	result := &ToolSaveResultDto{}
+	result.FromEntity(created, findings)
+	return result, nil
+}
+
+// UpdateTool is the resolver for the updateTool field.
+func (r *mutationResolver) UpdateTool(ctx context.Context, uid string, input UpdateToolInputDto) (*ToolSaveResultDto, error) {
+	c, user := requestFromContext(ctx)
+	logger.Infof(c, "GraphQL update tool requested")
+
+	if err := binding.Validator.ValidateStruct(input); err != nil {
+		return nil, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error())
+	}
+
+	current, err := r.toolService.GetTool(c, user.ID, uid)
+	if err != nil {
+		return nil, err
+	}
+	tool := input.ApplyTo(current)
+
+	findings, err := r.toolService.SaveToolUpdate(c, user.ID, tool)
+	if err != nil {
+		return nil, err
+	}
+	if err := tools.DeleteToolsCache(c, r.cache, user.ID); err != nil {
+		logger.Errorf(c, "Failed to delete cache for user %s: %v", user.ID, err)
+		return nil, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error")
+	}
+
+	// read the tool back for the updated at the repository set
+	updated, err := r.toolService.GetTool(c, user.ID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof(c, "Tool updated successfully for user %s with tool uid %s", user.ID, uid)
+	result := &ToolSaveResultDto{}
+	result.FromEntity(updated, findings)
+	return result, nil
+}
+
+// DeleteTool is the resolver for the deleteTool field.
+func (r *mutationResolver) DeleteTool(ctx context.Context, uid string) (bool, error) {
+	c, user := requestFromContext(ctx)
+	logger.Infof(c, "GraphQL delete tool requested")
+
+	if err := r.toolRepository.DeleteTool(user.ID, uid, service.ToolEventSourceFromContext(c, user.ID)); err != nil {
+		logger.Errorf(c, "Failed to delete tool %s for user %s: %v", uid, user.ID, err)
+		return false, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete tool error")
+	}
+
+	// the tool is gone either way, a storage usage that is not updated is measured again on the next save
+	if err := r.meteringService.RecordToolStorage(c, user.ID); err != nil {
+		logger.Errorf(c, "Failed to record tool storage for user %s: %v", user.ID, err)
+	}
+	if err := tools.DeleteToolsCache(c, r.cache, user.ID); err != nil {
+		logger.Errorf(c, "Failed to delete cache for user %s: %v", user.ID, err)
+		return false, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error")
+	}
+
+	logger.Infof(c, "Tool deleted successfully for user %s with tool uid %s", user.ID, uid)
+	return true, nil
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*UserDto, error) {
+	_, user := requestFromContext(ctx)
+	dto := &UserDto{}
+	dto.FromEntity(user)
+	return dto, nil
+}
+
+// Tools is the resolver for the tools field.
+func (r *queryResolver) Tools(ctx context.Context, filter *ToolFilterDto, sortBy *entity.ToolSortBy, limit *int, offset *int) (*ToolPageDto, error) {
+	c, user := requestFromContext(ctx)
+
+	pageLimit := lo.FromPtrOr(limit, toolsDefaultLimit)
+	pageOffset := lo.FromPtrOr(offset, 0)
+	if pageLimit < 1 || pageLimit > toolsMaxLimit || pageOffset < 0 {
+		return nil, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters,
+			"limit must be between 1 and %d and offset must not be negative", toolsMaxLimit)
+	}
+
+	page, total, err := r.toolRepository.ListTools(user.ID, filter.ToEntity(lo.FromPtr(sortBy), pageLimit, pageOffset))
+	if err != nil {
+		logger.Errorf(c, "Failed to list tools for user %s: %v", user.ID, err)
+		return nil, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected fetch tools error")
+	}
+	return &ToolPageDto{Tools: toolsFromEntity(page), Total: total}, nil
+}
+
+// Tool is the resolver for the tool field.
+func (r *queryResolver) Tool(ctx context.Context, uid string) (*ToolDto, error) {
+	c, user := requestFromContext(ctx)
+
+	tool, err := r.toolService.GetTool(c, user.ID, uid)
+	if err != nil {
+		return nil, err
+	}
+	dto := &ToolDto{}
+	dto.FromEntity(tool)
+	return dto, nil
+}
+
+// ToolEvents is the resolver for the toolEvents field.
+func (r *queryResolver) ToolEvents(ctx context.Context, toolUID *string, before *int, limit *int) ([]ToolEventDto, error) {
+	c, user := requestFromContext(ctx)
+
+	events, err := r.toolEventService.ListEvents(c, user.ID, lo.FromPtr(toolUID), int64(lo.FromPtr(before)), lo.FromPtr(limit))
+	if err != nil {
+		logger.Errorf(c, "Failed to list tool events for user %s: %v", user.ID, err)
+		return nil, err
+	}
+	return toolEventsFromEntity(events), nil
+}
+
+// ToolEvents is the resolver for the toolEvents field.
+func (r *subscriptionResolver) ToolEvents(ctx context.Context, toolUID *string) (<-chan *ToolEventDto, error) {
+	c, user := requestFromContext(ctx)
+	logger.Infof(c, "GraphQL tool events subscription of user %s started", user.ID)
+
+	after, err := r.toolEventService.LatestEventID(c, user.ID, lo.FromPtr(toolUID))
+	if err != nil {
+		return nil, err
+	}
+
+	// the gin context is reused once the request is done, the polling goes on with a copy
+	pollCtx := c.Copy()
+	events := make(chan *ToolEventDto)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(toolEventsPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Infof(pollCtx, "GraphQL tool events subscription of user %s ended", user.ID)
+				return
+			case <-ticker.C:
+			}
+
+			newEvents, err := r.toolEventService.EventsAfter(pollCtx, user.ID, lo.FromPtr(toolUID), after)
+			if err != nil {
+				logger.Errorf(pollCtx, "Failed to poll tool events for user %s: %v", user.ID, err)
+				continue
+			}
+			for _, event := range toolEventsFromEntity(newEvents) {
+				select {
+				case events <- &event:
+					after = event.ID
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// Settings is the resolver for the settings field.
+func (r *userResolver) Settings(ctx context.Context, obj *UserDto) ([]UserSettingDto, error) {
+	c, _ := requestFromContext(ctx)
+
+	settings, err := r.userSettingsService.Settings(c, entity.UserIDEntity(obj.ID))
+	if err != nil {
+		logger.Errorf(c, "Failed to get user settings: %v", err)
+		return nil, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected get user settings error")
+	}
+	return userSettingsFromEntity(settings), nil
+}
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -0,0 +1,45 @@
+package healthcheck
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewCapabilitiesController(capabilitiesService *service.DeploymentCapabilitiesService) router.Controller {
+	return CapabilitiesController{capabilitiesService: capabilitiesService}
+}
+
+// CapabilitiesController tells the frontend which optional features this deployment has, so it can hide the others.
+type CapabilitiesController struct {
+	common.JsonResponse
+
+	capabilitiesService *service.DeploymentCapabilitiesService
+}
+
+func (c CapabilitiesController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/capabilities", Handler: c.Capabilities, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Deployment capabilities
+// @Description	Which optional features are enabled on this deployment, from the env config and the current system settings.
+// @Description	A disabled feature should be hidden by the client, its routes reject the requests.
+// @Tags			Maintenance
+// @Produce		json
+// @Success		200	{object}	swagger.BaseSuccessResponse[CapabilitiesResponseDto]
+// @Router			/api/capabilities [get]
+func (c *CapabilitiesController) Capabilities(ctx *gin.Context) {
+	logger.Infof(ctx, "Capabilities requested")
+
+	var resp CapabilitiesResponseDto
+	resp.FromEntity(c.capabilitiesService.Capabilities(ctx))
+	c.Success(ctx, "", resp)
+}
@@ -0,0 +1,35 @@
+package healthcheck
+
+import "ya-tool-craft/internal/domain/entity"
+
+type CapabilitiesResponseDto struct {
+	UserRegistration bool     `json:"user_registration" example:"true"`
+	PasswordLogin    bool     `json:"password_login" example:"true"`
+	SSOProviders     []string `json:"sso_providers" example:"github,google"`
+	Passkeys         bool     `json:"passkeys" example:"true"`
+	// PasswordReset and AccountRecovery need a mailer
+	PasswordReset   bool `json:"password_reset" example:"false"`
+	AccountRecovery bool `json:"account_recovery" example:"false"`
+	OIDCProvider    bool `json:"oidc_provider" example:"false"`
+	// DemoMode rejects every request changing stored data
+	DemoMode bool `json:"demo_mode" example:"false"`
+	Gallery  bool `json:"gallery" example:"true"`
+	// ToolExecution runs tools on the server through POST /api/v1/tools/{tool_uid}/execute
+	ToolExecution bool `json:"tool_execution" example:"false"`
+	// GraphQL serves the tools and the profile of the user at /graphql
+	GraphQL bool `json:"graphql" example:"false"`
+}
+
+func (dto *CapabilitiesResponseDto) FromEntity(capabilities entity.DeploymentCapabilitiesEntity) {
+	dto.UserRegistration = capabilities.UserRegistration
+	dto.PasswordLogin = capabilities.PasswordLogin
+	dto.SSOProviders = capabilities.SSOProviders
+	dto.Passkeys = capabilities.Passkeys
+	dto.PasswordReset = capabilities.PasswordReset
+	dto.AccountRecovery = capabilities.AccountRecovery
+	dto.OIDCProvider = capabilities.OIDCProvider
+	dto.DemoMode = capabilities.DemoMode
+	dto.Gallery = capabilities.Gallery
+	dto.ToolExecution = capabilities.ToolExecution
+	dto.GraphQL = capabilities.GraphQL
+}
@@ -0,0 +1,48 @@
+package healthcheck
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/middleware"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewClientCompatibilityController(compatibilityService *service.ClientCompatibilityService) router.Controller {
+	return ClientCompatibilityController{compatibilityService: compatibilityService}
+}
+
+// ClientCompatibilityController lets a client check at startup whether it is still supported and which api
+// capabilities it can use.
+type ClientCompatibilityController struct {
+	common.JsonResponse
+
+	compatibilityService *service.ClientCompatibilityService
+}
+
+func (c ClientCompatibilityController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/compatibility", Handler: c.Compatibility, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Client compatibility
+// @Description	The minimum supported client version and the api capabilities of the server. A client older than the minimum
+// @Description	still gets its responses, every one of them with a Deprecation: true header and a Warning asking to upgrade.
+// @Tags			Maintenance
+// @Produce		json
+// @Param			X-Client-Version	header		string	false	"Version of the client"
+// @Success		200					{object}	swagger.BaseSuccessResponse[ClientCompatibilityResponseDto]
+// @Router			/api/v1/compatibility [get]
+func (c *ClientCompatibilityController) Compatibility(ctx *gin.Context) {
+	logger.Infof(ctx, "Client compatibility requested")
+
+	var resp ClientCompatibilityResponseDto
+	resp.FromEntity(c.compatibilityService.Compatibility(ctx.GetHeader(middleware.ClientVersionHeader)))
+	c.Success(ctx, "", resp)
+}
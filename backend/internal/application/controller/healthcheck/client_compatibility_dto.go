@@ -0,0 +1,25 @@
+package healthcheck
+
+import (
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type ClientCompatibilityResponseDto struct {
+	ServerVersion string `json:"server_version" example:"v1.5.0"`
+	// MinClientVersion is empty when every client version is supported
+	MinClientVersion string `json:"min_client_version" example:"1.4.0"`
+	// ClientVersion echoes the X-Client-Version header of the request
+	ClientVersion    string   `json:"client_version" example:"1.3.2"`
+	ClientDeprecated bool     `json:"client_deprecated" example:"true"`
+	Capabilities     []string `json:"capabilities" example:"sync_v1,tool_schema"`
+}
+
+func (dto *ClientCompatibilityResponseDto) FromEntity(compatibility entity.ClientCompatibilityEntity) {
+	dto.ServerVersion = compatibility.ServerVersion
+	dto.MinClientVersion = compatibility.MinClientVersion
+	dto.ClientVersion = compatibility.ClientVersion
+	dto.ClientDeprecated = compatibility.ClientDeprecated
+	dto.Capabilities = lo.Map(compatibility.Capabilities, func(capability entity.APICapability, _ int) string { return string(capability) })
+}
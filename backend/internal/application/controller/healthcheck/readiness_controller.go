@@ -0,0 +1,63 @@
+package healthcheck
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewReadinessController(readinessService *service.ReadinessService) router.Controller {
+	return ReadinessController{readinessService: readinessService}
+}
+
+// ReadinessController serves the liveness and readiness probes, unlike the health check they verify the dependencies.
+type ReadinessController struct {
+	common.JsonResponse
+
+	readinessService *service.ReadinessService
+}
+
+func (c ReadinessController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/healthz", Handler: c.Healthz, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/readyz", Handler: c.Readyz, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Liveness probe
+// @Description	Runs the checks of the readiness probe and reports them, but responds 200 as long as the server handles requests, a restart does not fix a failed dependency
+// @Tags			Maintenance
+// @Produce		json
+// @Success		200	{object}	swagger.BaseSuccessResponse[ReadinessResponseDto]
+// @Router			/healthz [get]
+func (c *ReadinessController) Healthz(ctx *gin.Context) {
+	var resp ReadinessResponseDto
+	resp.FromEntity(c.readinessService.Check(ctx))
+	c.Success(ctx, "service is alive", resp)
+}
+
+// @Summary		Readiness probe
+// @Description	Actively checks the database, the key value store and optionally the SSO providers with short timeouts. Responds 503 with the failed checks in extra_data when any of them fails
+// @Tags			Maintenance
+// @Produce		json
+// @Success		200	{object}	swagger.BaseSuccessResponse[ReadinessResponseDto]
+// @Failure		503	{object}	swagger.BaseFailResponse
+// @Router			/readyz [get]
+func (c *ReadinessController) Readyz(ctx *gin.Context) {
+	logger.Infof(ctx, "Readiness check requested")
+
+	var resp ReadinessResponseDto
+	resp.FromEntity(c.readinessService.Check(ctx))
+	if !resp.Ready {
+		c.Error(ctx, error_code.NewErrorWithErrorCodeFAppendExtraData(error_code.ServiceNotReady, resp, "readiness check failed"))
+		return
+	}
+	c.Success(ctx, "service is ready", resp)
+}
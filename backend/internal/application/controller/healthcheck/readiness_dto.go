@@ -0,0 +1,36 @@
+package healthcheck
+
+import (
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type ReadinessCheckDto struct {
+	Name      string `json:"name" example:"database"`
+	Backend   string `json:"backend" example:"sqlite"`
+	Healthy   bool   `json:"healthy" example:"true"`
+	LatencyMs int64  `json:"latency_ms" example:"3"`
+	Error     string `json:"error,omitempty" example:"timed out after 2s"`
+	// Optional checks are reported but do not fail the readiness probe
+	Optional bool `json:"optional" example:"false"`
+}
+
+type ReadinessResponseDto struct {
+	Ready  bool                `json:"ready" example:"true"`
+	Checks []ReadinessCheckDto `json:"checks"`
+}
+
+func (dto *ReadinessResponseDto) FromEntity(report entity.ReadinessReportEntity) {
+	dto.Ready = report.Ready
+	dto.Checks = lo.Map(report.Checks, func(check entity.ReadinessCheckEntity, _ int) ReadinessCheckDto {
+		return ReadinessCheckDto{
+			Name:      check.Name,
+			Backend:   check.Backend,
+			Healthy:   check.Healthy,
+			LatencyMs: check.Latency.Milliseconds(),
+			Error:     check.Error,
+			Optional:  check.Optional,
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package healthcheck
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewStatusController(statusService *service.StatusService) router.Controller {
+	return StatusController{statusService: statusService}
+}
+
+// StatusController serves the public status for "backend degraded" banners and uptime monitors.
+type StatusController struct {
+	common.JsonResponse
+
+	statusService *service.StatusService
+}
+
+func (c StatusController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/status", Handler: c.Status, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Public status
+// @Description	Version, build commit, uptime and whether each component is healthy, without any other detail.
+// @Description	Always responds 200, a failed component turns the status to degraded. The components are checked at most every 10 seconds.
+// @Tags			Maintenance
+// @Produce		json
+// @Success		200	{object}	swagger.BaseSuccessResponse[StatusResponseDto]
+// @Router			/api/status [get]
+func (c *StatusController) Status(ctx *gin.Context) {
+	logger.Infof(ctx, "Status requested")
+
+	var resp StatusResponseDto
+	resp.FromEntity(c.statusService.Status(ctx))
+	c.Success(ctx, "", resp)
+}
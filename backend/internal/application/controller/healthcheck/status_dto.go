@@ -0,0 +1,23 @@
+package healthcheck
+
+import "ya-tool-craft/internal/domain/entity"
+
+type StatusResponseDto struct {
+	// Status is ok when every component is healthy, degraded otherwise
+	Status        string          `json:"status" example:"ok" enums:"ok,degraded"`
+	Version       string          `json:"version" example:"v1.2.0"`
+	Commit        string          `json:"commit" example:"a1b2c3d"`
+	UptimeSeconds int64           `json:"uptime_seconds" example:"86400"`
+	Components    map[string]bool `json:"components" example:"database:true,key_value_store:true"`
+}
+
+func (dto *StatusResponseDto) FromEntity(status entity.StatusEntity) {
+	dto.Status = "ok"
+	if !status.Healthy {
+		dto.Status = "degraded"
+	}
+	dto.Version = status.Version
+	dto.Commit = status.Commit
+	dto.UptimeSeconds = int64(status.Uptime.Seconds())
+	dto.Components = status.Components
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"net/http"
+	coremetrics "ya-tool-craft/internal/core/metrics"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func NewMetricsController(systemInfoService *service.SystemInfoService) router.Controller {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		newSchemaVersionCollector(systemInfoService),
+		coremetrics.SlowQueriesTotal,
+		coremetrics.ScheduledJobRunsTotal,
+		coremetrics.DataRetentionPurgedRowsTotal,
+		coremetrics.TokenIssuanceAnomaliesTotal,
+		coremetrics.SIEMEventsTotal,
+		coremetrics.PasskeyLoginChallengesTotal,
+		coremetrics.PasskeyLoginChallengesOutstanding,
+		coremetrics.ToolExecutionsTotal,
+		coremetrics.CacheFallbackOperationsTotal,
+		coremetrics.CacheDegraded,
+	)
+
+	return MetricsController{
+		// a failing collector must not hide the metrics of the others
+		handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError}),
+	}
+}
+
+// MetricsController serves the metrics in the Prometheus text format.
+type MetricsController struct {
+	handler http.Handler
+}
+
+func (c MetricsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/metrics", Handler: c.Metrics, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Prometheus metrics
+// @Description	Metrics in the Prometheus text format, including toolbake_schema_version, toolbake_schema_latest_version and toolbake_slow_queries_total
+// @Tags			Maintenance
+// @Produce		plain
+// @Success		200	{string}	string	"metrics"
+// @Router			/metrics [get]
+func (c *MetricsController) Metrics(ctx *gin.Context) {
+	c.handler.ServeHTTP(ctx.Writer, ctx.Request)
+}
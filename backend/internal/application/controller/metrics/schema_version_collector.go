@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/domain/service"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// schemaCollectTimeout keeps a slow database from stalling the whole scrape
+const schemaCollectTimeout = 2 * time.Second
+
+var (
+	schemaVersionDesc = prometheus.NewDesc(
+		"toolbake_schema_version",
+		"Newest database schema migration applied to the database.",
+		nil, nil,
+	)
+	schemaLatestVersionDesc = prometheus.NewDesc(
+		"toolbake_schema_latest_version",
+		"Newest database schema migration known to this build.",
+		nil, nil,
+	)
+)
+
+// schemaVersionCollector reads the schema version on every scrape, another instance may migrate the database at any time.
+type schemaVersionCollector struct {
+	systemInfoService *service.SystemInfoService
+}
+
+func newSchemaVersionCollector(systemInfoService *service.SystemInfoService) schemaVersionCollector {
+	return schemaVersionCollector{systemInfoService: systemInfoService}
+}
+
+func (c schemaVersionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- schemaVersionDesc
+	ch <- schemaLatestVersionDesc
+}
+
+func (c schemaVersionCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), schemaCollectTimeout)
+	defer cancel()
+
+	version, err := c.systemInfoService.SchemaVersion(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(schemaVersionDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(schemaVersionDesc, prometheus.GaugeValue, float64(version.AppliedVersion))
+	ch <- prometheus.MustNewConstMetric(schemaLatestVersionDesc, prometheus.GaugeValue, float64(version.LatestVersion))
+}
@@ -0,0 +1,110 @@
+package oidc
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewOidcConsentController(
+	oidcService *service.OidcService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return OidcConsentController{
+		oidcService:                oidcService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+// OidcConsentController backs the consent page, the signed in user approves or denies a pending authorization request.
+type OidcConsentController struct {
+	common.JsonResponse
+
+	oidcService                *service.OidcService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c OidcConsentController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/oidc/authorize/:request_id", Handler: c.GetRequest, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/oidc/authorize/:request_id", Handler: c.Decide, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Get an OIDC authorization request
+// @Description	The client and the scopes of a pending authorization request, for the consent page
+// @Tags			OIDC
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			request_id		path		string	true	"Authorization request ID"
+// @Success		200				{object}	swagger.BaseSuccessResponse[OidcAuthorizationRequestResponseDto]
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/oidc/authorize/{request_id} [get]
+func (c *OidcConsentController) GetRequest(ctx *gin.Context) {
+	logger.Infof(ctx, "OIDC authorization request details requested")
+
+	if _, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx); err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	request, oidcClient, err := c.oidcService.GetAuthorizationRequest(ctx, ctx.Param("request_id"))
+	if err != nil {
+		logger.Warnf(ctx, "Failed to get OIDC authorization request: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp OidcAuthorizationRequestResponseDto
+	resp.FromEntity(request, oidcClient)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Approve or deny an OIDC authorization request
+// @Description	Decide a pending authorization request for the signed in user, then send the browser to redirect_to.
+// @Description	On approval it carries the authorization code, otherwise the access_denied error. A request is decided once.
+// @Tags			OIDC
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string								true	"Bearer access token"
+// @Param			request_id		path		string								true	"Authorization request ID"
+// @Param			request			body		OidcAuthorizationDecisionRequestDto	true	"Decision"
+// @Success		200				{object}	swagger.BaseSuccessResponse[OidcAuthorizationDecisionResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/oidc/authorize/{request_id} [post]
+func (c *OidcConsentController) Decide(ctx *gin.Context) {
+	logger.Infof(ctx, "OIDC authorization decision requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req OidcAuthorizationDecisionRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Failed to bind request: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	redirect, err := c.oidcService.DecideAuthorization(ctx, user.ID, ctx.Param("request_id"), *req.Approve)
+	if err != nil {
+		logger.Warnf(ctx, "Failed to decide OIDC authorization request: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	c.Success(ctx, "", OidcAuthorizationDecisionResponseDto{RedirectTo: redirect})
+}
@@ -0,0 +1,88 @@
+package oidc
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+)
+
+// OidcTokenRequestDto is the form of the token endpoint, the client may authenticate with HTTP basic auth instead of
+// client_id and client_secret
+type OidcTokenRequestDto struct {
+	GrantType    string `form:"grant_type" binding:"required" example:"authorization_code"`
+	ClientID     string `form:"client_id" example:"3c1d1f8e-7a5b-4c1d-9f0e-2b7a6c5d4e3f"`
+	ClientSecret string `form:"client_secret" example:"b2Jq..."`
+	Code         string `form:"code" example:"c3Zh..."`
+	RedirectURI  string `form:"redirect_uri" example:"https://wiki.example.com/oauth/callback"`
+	CodeVerifier string `form:"code_verifier" example:"dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"`
+	RefreshToken string `form:"refresh_token" example:"cmVm..."`
+}
+
+func (dto OidcTokenRequestDto) ToParams(clientID string, clientSecret string) service.OidcTokenParams {
+	return service.OidcTokenParams{
+		GrantType:    dto.GrantType,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Code:         dto.Code,
+		RedirectURI:  dto.RedirectURI,
+		CodeVerifier: dto.CodeVerifier,
+		RefreshToken: dto.RefreshToken,
+	}
+}
+
+type OidcTokenResponseDto struct {
+	AccessToken string `json:"access_token" example:"eyJhbGciOi..."`
+	TokenType   string `json:"token_type" example:"Bearer"`
+	ExpiresIn   int64  `json:"expires_in" example:"900"`
+	// RefreshToken is returned by the authorization code grant, and by the refresh grant when REFRESH_TOKEN_ROTATION replaced the presented token
+	RefreshToken string `json:"refresh_token,omitempty" example:"cmVm..."`
+	// IDToken is only returned by the authorization code grant
+	IDToken string `json:"id_token,omitempty" example:"eyJhbGciOiJSUzI1NiIs..."`
+}
+
+func (dto *OidcTokenResponseDto) FromEntity(token entity.OidcTokenEntity) {
+	dto.AccessToken = token.AccessToken.Token
+	dto.TokenType = "Bearer"
+	dto.ExpiresIn = token.ExpiresIn
+	dto.RefreshToken = token.RefreshToken
+	dto.IDToken = token.IDToken
+}
+
+// OidcErrorResponseDto is the error of the token and userinfo endpoints, in the OAuth 2.0 format
+type OidcErrorResponseDto struct {
+	Error            string `json:"error" example:"invalid_grant"`
+	ErrorDescription string `json:"error_description" example:"Invalid or expired OpenID Connect grant"`
+}
+
+type OidcJwksResponseDto struct {
+	Keys []service.OidcJwk `json:"keys"`
+}
+
+type OidcAuthorizationRequestResponseDto struct {
+	RequestID  string   `json:"request_id" example:"4f9c2f0e-3a5b-4f7e-9a55-2b0c5e1d7c11"`
+	ClientID   string   `json:"client_id" example:"3c1d1f8e-7a5b-4c1d-9f0e-2b7a6c5d4e3f"`
+	ClientName string   `json:"client_name" example:"Wiki"`
+	Scopes     []string `json:"scopes" example:"openid,profile,email"`
+	// RedirectURI is where the browser is sent after the decision
+	RedirectURI string    `json:"redirect_uri" example:"https://wiki.example.com/oauth/callback"`
+	ExpiresAt   time.Time `json:"expires_at" format:"date-time"`
+}
+
+func (dto *OidcAuthorizationRequestResponseDto) FromEntity(request entity.OidcAuthorizationRequestEntity, oidcClient entity.OidcClientEntity) {
+	dto.RequestID = request.ID
+	dto.ClientID = oidcClient.ID
+	dto.ClientName = oidcClient.Name
+	dto.Scopes = request.Scopes
+	dto.RedirectURI = request.RedirectURI
+	dto.ExpiresAt = time.Unix(request.ExpiresAt, 0).UTC()
+}
+
+type OidcAuthorizationDecisionRequestDto struct {
+	// Approve signs the user in to the client, false denies the request
+	Approve *bool `json:"approve" binding:"required" example:"true"`
+}
+
+type OidcAuthorizationDecisionResponseDto struct {
+	// RedirectTo is where the browser has to be sent, back to the client
+	RedirectTo string `json:"redirect_to" example:"https://wiki.example.com/oauth/callback?code=c3Zh...&state=af0ifjsldkj"`
+}
@@ -0,0 +1,205 @@
+package oidc
+
+import (
+	"errors"
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oauthErrors maps the error codes to the errors of the OAuth 2.0 spec, the clients only understand those
+var oauthErrors = map[string]string{
+	error_code.InvalidOidcClient.Code:        "invalid_client",
+	error_code.InvalidOidcRequest.Code:       "invalid_request",
+	error_code.InvalidOidcGrant.Code:         "invalid_grant",
+	error_code.UnsupportedOidcGrantType.Code: "unsupported_grant_type",
+	error_code.InvalidOidcAccessToken.Code:   "invalid_token",
+	error_code.OidcProviderDisabled.Code:     "invalid_request",
+}
+
+func NewOidcProviderController(oidcService *service.OidcService) router.Controller {
+	return OidcProviderController{
+		oidcService: oidcService,
+	}
+}
+
+// OidcProviderController serves the OpenID Connect endpoints the client apps call. They answer in the formats of the
+// OAuth 2.0 and OpenID Connect specs instead of the usual response envelope, except the authorization endpoint
+// which the browser is sent to and which reports the errors it can not redirect as usual.
+type OidcProviderController struct {
+	common.JsonResponse
+
+	oidcService *service.OidcService
+}
+
+func (c OidcProviderController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/.well-known/openid-configuration", Handler: c.Discovery, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/api/v1/oidc/authorize", Handler: c.Authorize, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/oidc/token", Handler: c.Token, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/api/v1/oidc/userinfo", Handler: c.UserInfo, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/oidc/userinfo", Handler: c.UserInfo, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/api/v1/oidc/jwks", Handler: c.Jwks, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		OpenID Connect discovery
+// @Description	The OpenID provider metadata, served when OIDC_PROVIDER_ENABLED is true
+// @Tags			OIDC
+// @Produce		json
+// @Success		200	{object}	entity.OidcDiscoveryEntity
+// @Failure		404	{object}	swagger.BaseFailResponse
+// @Router			/.well-known/openid-configuration [get]
+func (c *OidcProviderController) Discovery(ctx *gin.Context) {
+	discovery, err := c.oidcService.Discovery()
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, discovery)
+}
+
+// @Summary		OpenID Connect authorization endpoint
+// @Description	Start the authorization code flow. The browser is sent to the consent page of ToolBake, or back to the client with an error.
+// @Description	Only the code response type is supported, the openid scope and PKCE with S256 are required.
+// @Description	An unknown client or an unregistered redirect_uri is answered with an error instead of a redirect.
+// @Tags			OIDC
+// @Produce		json
+// @Param			client_id				query	string	true	"Client ID"
+// @Param			redirect_uri			query	string	true	"A redirect URI registered for the client"
+// @Param			response_type			query	string	true	"Must be code"
+// @Param			scope					query	string	true	"Space separated scopes, openid is required, profile and email are supported"
+// @Param			state					query	string	false	"Opaque value returned to the client"
+// @Param			nonce					query	string	false	"Copied into the id token"
+// @Param			code_challenge			query	string	true	"PKCE code challenge"
+// @Param			code_challenge_method	query	string	true	"Must be S256"
+// @Success		302
+// @Failure		400	{object}	swagger.BaseFailResponse
+// @Failure		401	{object}	swagger.BaseFailResponse
+// @Failure		404	{object}	swagger.BaseFailResponse
+// @Router			/api/v1/oidc/authorize [get]
+func (c *OidcProviderController) Authorize(ctx *gin.Context) {
+	logger.Infof(ctx, "OIDC authorization requested by client %s", ctx.Query("client_id"))
+
+	redirect, err := c.oidcService.Authorize(ctx, service.OidcAuthorizeParams{
+		ClientID:            ctx.Query("client_id"),
+		RedirectURI:         ctx.Query("redirect_uri"),
+		ResponseType:        ctx.Query("response_type"),
+		Scope:               ctx.Query("scope"),
+		State:               ctx.Query("state"),
+		Nonce:               ctx.Query("nonce"),
+		CodeChallenge:       ctx.Query("code_challenge"),
+		CodeChallengeMethod: ctx.Query("code_challenge_method"),
+	})
+	if err != nil {
+		logger.Warnf(ctx, "Rejected OIDC authorization request: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+	ctx.Redirect(http.StatusFound, redirect)
+}
+
+// @Summary		OpenID Connect token endpoint
+// @Description	Redeem an authorization code, or refresh an access token with the refresh_token grant.
+// @Description	The client authenticates with HTTP basic auth or with client_id and client_secret in the form.
+// @Tags			OIDC
+// @Accept			x-www-form-urlencoded
+// @Produce		json
+// @Param			request	formData	OidcTokenRequestDto	true	"Token request"
+// @Success		200		{object}	OidcTokenResponseDto
+// @Failure		400		{object}	OidcErrorResponseDto
+// @Failure		401		{object}	OidcErrorResponseDto
+// @Router			/api/v1/oidc/token [post]
+func (c *OidcProviderController) Token(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	var req OidcTokenRequestDto
+	if err := ctx.ShouldBind(&req); err != nil {
+		logger.Errorf(ctx, "Failed to bind request: %v", err)
+		c.oauthError(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidOidcRequest, err.Error()))
+		return
+	}
+	clientID, clientSecret, basicAuth := ctx.Request.BasicAuth()
+	if !basicAuth {
+		clientID, clientSecret = req.ClientID, req.ClientSecret
+	}
+	logger.Infof(ctx, "OIDC %s grant requested by client %s", req.GrantType, clientID)
+
+	token, err := c.oidcService.Token(ctx, req.ToParams(clientID, clientSecret))
+	if err != nil {
+		logger.Warnf(ctx, "Rejected OIDC token request: %v", err)
+		if basicAuth {
+			ctx.Header("WWW-Authenticate", `Basic realm="toolbake"`)
+		}
+		c.oauthError(ctx, err)
+		return
+	}
+
+	var resp OidcTokenResponseDto
+	resp.FromEntity(token)
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary		OpenID Connect userinfo endpoint
+// @Description	The claims of the user the access token belongs to
+// @Tags			OIDC
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	service.OidcUserInfo
+// @Failure		401				{object}	OidcErrorResponseDto
+// @Router			/api/v1/oidc/userinfo [get]
+// @Router			/api/v1/oidc/userinfo [post]
+func (c *OidcProviderController) UserInfo(ctx *gin.Context) {
+	token, err := common.GetAccessTokenHeader(ctx)
+	if err != nil {
+		err = error_code.NewErrorWithErrorCode(error_code.InvalidOidcAccessToken, err.Error())
+	} else {
+		var info service.OidcUserInfo
+		info, err = c.oidcService.UserInfo(ctx, token)
+		if err == nil {
+			ctx.JSON(http.StatusOK, info)
+			return
+		}
+	}
+	logger.Warnf(ctx, "Rejected OIDC userinfo request: %v", err)
+	ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+	c.oauthError(ctx, err)
+}
+
+// @Summary		OpenID Connect JSON Web Key Set
+// @Description	The public keys the id tokens are signed with
+// @Tags			OIDC
+// @Produce		json
+// @Success		200	{object}	OidcJwksResponseDto
+// @Failure		404	{object}	swagger.BaseFailResponse
+// @Router			/api/v1/oidc/jwks [get]
+func (c *OidcProviderController) Jwks(ctx *gin.Context) {
+	keys, err := c.oidcService.Jwks(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, OidcJwksResponseDto{Keys: keys})
+}
+
+// oauthError writes the error in the OAuth 2.0 format, the unexpected errors become server_error.
+func (c *OidcProviderController) oauthError(ctx *gin.Context, err error) {
+	var codeErr error_code.ErrorWithErrorCode
+	if !errors.As(err, &codeErr) {
+		logger.Errorf(ctx, "%+v", err)
+		ctx.JSON(http.StatusInternalServerError, OidcErrorResponseDto{Error: "server_error", ErrorDescription: "internal server error"})
+		return
+	}
+	oauthError, known := oauthErrors[codeErr.ErrorCode.Code]
+	if !known {
+		oauthError = "invalid_request"
+	}
+	ctx.JSON(codeErr.ErrorCode.HTTPStatusCode, OidcErrorResponseDto{Error: oauthError, ErrorDescription: codeErr.ErrorCode.Message})
+}
@@ -0,0 +1,195 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	appembed "ya-tool-craft/internal/embed"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	bearerSecurityName = "BearerAuth"
+
+	passwordLoginPath = "/api/v1/auth/login"
+	registrationPath  = "/api/v1/user/create"
+	ssoProviderPath   = "/api/v1/auth/sso/{provider}"
+)
+
+func NewOpenAPIController(settingsService *service.SystemSettingsService) router.Controller {
+	return &OpenAPIController{
+		spec: appembed.OpenAPISpec,
+		systemSettings: func(ctx context.Context) entity.SystemSettingsEntity {
+			settings, err := settingsService.Settings(ctx)
+			if err != nil {
+				logger.Errorf(ctx, "failed to get system settings for openapi spec, fall back to env config: %v", err)
+				return settingsService.DefaultSettings()
+			}
+			return settings
+		},
+	}
+}
+
+type OpenAPIController struct {
+	common.JsonResponse
+
+	// spec is the static swagger document generated at build time
+	spec []byte
+	// systemSettings returns the runtime settings that decide which endpoints are available
+	systemSettings func(ctx context.Context) entity.SystemSettingsEntity
+}
+
+func (c *OpenAPIController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/openapi.json", Handler: c.Spec, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		OpenAPI spec
+// @Description	Swagger 2.0 document of this instance: host, scheme and base path follow the request (X-Forwarded-* aware), endpoints of disabled login methods and registration are omitted
+// @Tags			Maintenance
+// @Produce		json
+// @Success		200	{object}	object
+// @Failure		400	{object}	swagger.BaseFailResponse
+// @Router			/openapi.json [get]
+func (c *OpenAPIController) Spec(ctx *gin.Context) {
+	logger.Infof(ctx, "OpenAPI spec requested")
+
+	// decode per request, the document is mutated below and this endpoint is rarely hit
+	var spec map[string]any
+	if err := json.Unmarshal(c.spec, &spec); err != nil {
+		logger.Errorf(ctx, "Failed to decode embedded openapi spec: %v", errors.WithStack(err))
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "OpenAPI spec is unavailable"))
+		return
+	}
+
+	applyServer(spec, ctx.Request)
+	applySecurity(spec)
+	applySettings(spec, c.systemSettings(ctx))
+
+	ctx.JSON(http.StatusOK, spec)
+}
+
+// applyServer points the document at the address the client used to reach this instance.
+func applyServer(spec map[string]any, req *http.Request) {
+	scheme, host, basePath := requestServer(req)
+	spec["host"] = host
+	spec["schemes"] = []string{scheme}
+	spec["basePath"] = basePath
+}
+
+// requestServer returns the scheme, host and base path the client used to reach this instance, behind proxies too.
+func requestServer(req *http.Request) (string, string, string) {
+	host := req.Host
+	if forwardedHost := firstHeaderValue(req, "X-Forwarded-Host"); forwardedHost != "" {
+		host = forwardedHost
+	}
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	if forwardedProto := firstHeaderValue(req, "X-Forwarded-Proto"); forwardedProto != "" {
+		scheme = forwardedProto
+	}
+	basePath := "/"
+	if prefix := firstHeaderValue(req, "X-Forwarded-Prefix"); prefix != "" {
+		basePath = "/" + strings.Trim(prefix, "/")
+	}
+	return scheme, host, basePath
+}
+
+// firstHeaderValue returns the first entry of a possibly comma separated header set by a proxy chain.
+func firstHeaderValue(req *http.Request, name string) string {
+	value, _, _ := strings.Cut(req.Header.Get(name), ",")
+	return strings.TrimSpace(value)
+}
+
+// applySecurity declares the bearer access token scheme and attaches it to every operation taking an Authorization header.
+func applySecurity(spec map[string]any) {
+	spec["securityDefinitions"] = map[string]any{
+		bearerSecurityName: map[string]any{
+			"type":        "apiKey",
+			"name":        "Authorization",
+			"in":          "header",
+			"description": "Access token in the form \"Bearer <token>\"",
+		},
+	}
+
+	forEachOperation(spec, func(operation map[string]any) {
+		params, _ := operation["parameters"].([]any)
+		for _, param := range params {
+			p, _ := param.(map[string]any)
+			if p["in"] == "header" && p["name"] == "Authorization" {
+				operation["security"] = []any{map[string]any{bearerSecurityName: []any{}}}
+				return
+			}
+		}
+	})
+}
+
+// applySettings drops the endpoints that the current system settings disable.
+func applySettings(spec map[string]any, settings entity.SystemSettingsEntity) {
+	paths, _ := spec["paths"].(map[string]any)
+	if paths == nil {
+		return
+	}
+
+	if !settings.EnablePasswordLogin {
+		delete(paths, passwordLoginPath)
+	}
+	if !settings.EnableUserRegistration {
+		delete(paths, registrationPath)
+	}
+
+	var providers []any
+	if settings.EnableGithubSSO {
+		providers = append(providers, "github")
+	}
+	if settings.EnableGoogleSSO {
+		providers = append(providers, "google")
+	}
+	if len(providers) == 0 {
+		delete(paths, ssoProviderPath)
+		return
+	}
+	if ssoPath, ok := paths[ssoProviderPath].(map[string]any); ok {
+		forEachPathOperation(ssoPath, func(operation map[string]any) {
+			params, _ := operation["parameters"].([]any)
+			for _, param := range params {
+				p, _ := param.(map[string]any)
+				if p["in"] == "path" && p["name"] == "provider" {
+					p["enum"] = providers
+				}
+			}
+		})
+	}
+}
+
+func forEachOperation(spec map[string]any, fn func(operation map[string]any)) {
+	paths, _ := spec["paths"].(map[string]any)
+	for _, path := range paths {
+		if pathItem, ok := path.(map[string]any); ok {
+			forEachPathOperation(pathItem, fn)
+		}
+	}
+}
+
+func forEachPathOperation(pathItem map[string]any, fn func(operation map[string]any)) {
+	for _, operation := range pathItem {
+		if op, ok := operation.(map[string]any); ok {
+			fn(op)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+
+	appembed "ya-tool-craft/internal/embed"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func requestSpec(t *testing.T, settings entity.SystemSettingsEntity, headers map[string]string) map[string]any {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger.InitLogger(config.Config{
+		LogLevel:  "error",
+		LogFormat: "text",
+	})
+
+	controller := &OpenAPIController{
+		spec: appembed.OpenAPISpec,
+		systemSettings: func(ctx context.Context) entity.SystemSettingsEntity {
+			return settings
+		},
+	}
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "http://backend.internal:8080/openapi.json", nil)
+	for name, value := range headers {
+		ctx.Request.Header.Set(name, value)
+	}
+
+	controller.Spec(ctx)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var spec map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	return spec
+}
+
+func TestOpenAPIController_Spec_Server(t *testing.T) {
+	spec := requestSpec(t, entity.SystemSettingsEntity{}, nil)
+	require.Equal(t, "backend.internal:8080", spec["host"])
+	require.Equal(t, []any{"http"}, spec["schemes"])
+	require.Equal(t, "/", spec["basePath"])
+
+	spec = requestSpec(t, entity.SystemSettingsEntity{}, map[string]string{
+		"X-Forwarded-Host":   "tools.example.com, proxy.internal",
+		"X-Forwarded-Proto":  "https",
+		"X-Forwarded-Prefix": "/toolbake/",
+	})
+	require.Equal(t, "tools.example.com", spec["host"])
+	require.Equal(t, []any{"https"}, spec["schemes"])
+	require.Equal(t, "/toolbake", spec["basePath"])
+}
+
+func TestOpenAPIController_Spec_Security(t *testing.T) {
+	spec := requestSpec(t, entity.SystemSettingsEntity{}, nil)
+	require.Contains(t, spec["securityDefinitions"], bearerSecurityName)
+
+	paths := spec["paths"].(map[string]any)
+	userInfo := paths["/api/v1/user"].(map[string]any)["get"].(map[string]any)
+	require.Equal(t, []any{map[string]any{bearerSecurityName: []any{}}}, userInfo["security"])
+
+	healthcheck := paths["/api/v1/healthcheck"].(map[string]any)["get"].(map[string]any)
+	require.NotContains(t, healthcheck, "security")
+}
+
+func TestOpenAPIController_Spec_FeatureFlags(t *testing.T) {
+	spec := requestSpec(t, entity.SystemSettingsEntity{}, nil)
+	paths := spec["paths"].(map[string]any)
+	require.NotContains(t, paths, passwordLoginPath)
+	require.NotContains(t, paths, registrationPath)
+	require.NotContains(t, paths, ssoProviderPath)
+
+	spec = requestSpec(t, entity.SystemSettingsEntity{
+		EnablePasswordLogin:    true,
+		EnableUserRegistration: true,
+		EnableGoogleSSO:        true,
+	}, nil)
+	paths = spec["paths"].(map[string]any)
+	require.Contains(t, paths, passwordLoginPath)
+	require.Contains(t, paths, registrationPath)
+
+	ssoLogin := paths[ssoProviderPath].(map[string]any)["post"].(map[string]any)
+	providerParam := ssoLogin["parameters"].([]any)[0].(map[string]any)
+	require.Equal(t, "provider", providerParam["name"])
+	require.Equal(t, []any{"google"}, providerParam["enum"])
+}
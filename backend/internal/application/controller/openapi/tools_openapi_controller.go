@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewToolsOpenAPIController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	toolService *service.ToolService,
+) router.Controller {
+	return &ToolsOpenAPIController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		toolService:                toolService,
+	}
+}
+
+// ToolsOpenAPIController describes the active tools of the caller as an OpenAPI document, so other services can call them
+type ToolsOpenAPIController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	toolService                *service.ToolService
+}
+
+func (c *ToolsOpenAPIController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/tools/openapi.json", Handler: c.Spec, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		OpenAPI spec of the tools of the user
+// @Description	OpenAPI 3.1 document with one invoke operation per active tool of the caller, under /api/tools/{namespace}/{name}/invoke.
+// @Description	The request body takes the inputs of the tool input schema and the response holds its output schema, see /api/v1/tools/{tool_uid}/schema.
+// @Description	Tools sharing a namespace and name, tools with a slash in either, and tools whose schema can not be resolved, are left out.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	object
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/tools/openapi.json [get]
+func (c *ToolsOpenAPIController) Spec(ctx *gin.Context) {
+	logger.Infof(ctx, "Tools OpenAPI spec requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	tools, err := c.toolService.InvocableTools(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list invocable tools of user %s: %v", user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	scheme, host, basePath := requestServer(ctx.Request)
+	ctx.JSON(http.StatusOK, buildToolsSpec(tools, scheme+"://"+host+strings.TrimSuffix(basePath, "/")))
+}
+
+// toolInvokePath is the invoke endpoint of the tool with the namespace and name.
+func toolInvokePath(namespace, name string) string {
+	return "/api/tools/" + url.PathEscape(namespace) + "/" + url.PathEscape(name) + "/invoke"
+}
+
+// buildToolsSpec builds the OpenAPI 3.1 document of the tools, 3.1 takes the JSON Schemas of the tools as they are.
+func buildToolsSpec(tools []entity.InvocableToolEntity, serverURL string) map[string]any {
+	paths := map[string]any{}
+	for _, invocable := range tools {
+		tool := invocable.Tool
+		// the router matches the unescaped path, a slash would split the namespace or name
+		if strings.Contains(tool.Namespace, "/") || strings.Contains(tool.Name, "/") {
+			continue
+		}
+		paths[toolInvokePath(tool.Namespace, tool.Name)] = map[string]any{
+			"post": map[string]any{
+				"operationId": tool.UniqueID,
+				"summary":     tool.Name,
+				"description": tool.Description,
+				"tags":        []any{tool.Namespace},
+				"requestBody": map[string]any{
+					"required": true,
+					"content": map[string]any{
+						"application/json": map[string]any{
+							"schema": map[string]any{
+								"type":     "object",
+								"required": []any{"inputs"},
+								"properties": map[string]any{
+									"inputs":            invocable.Schema.Input,
+									"changed_widget_id": map[string]any{"type": "string", "description": "Widget passed to the handler as the one that triggered the run"},
+								},
+							},
+						},
+					},
+				},
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": "The handler finished",
+						"content": map[string]any{
+							"application/json": map[string]any{
+								"schema": toolInvokeSuccessSchema(invocable.Schema.Output),
+							},
+						},
+					},
+					"default": map[string]any{
+						"description": "The tool was not run or its handler failed, extra_data.logs holds the console output of a failed run",
+						"content": map[string]any{
+							"application/json": map[string]any{
+								"schema": map[string]any{"$ref": "#/components/schemas/Error"},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "ToolBake tools",
+			"version":     "1.0.0",
+			"description": "The active tools of the user, run on the server. Runs are rate limited per user, see the X-RateLimit-* headers.",
+		},
+		"servers":  []any{map[string]any{"url": serverURL}},
+		"security": []any{map[string]any{bearerSecurityName: []any{}}},
+		"paths":    paths,
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				bearerSecurityName: map[string]any{"type": "http", "scheme": "bearer"},
+			},
+			"schemas": map[string]any{
+				"Log": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"level":   map[string]any{"type": "string"},
+						"message": map[string]any{"type": "string"},
+					},
+				},
+				"Error": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"status":     map[string]any{"type": "string", "const": "error"},
+						"error_code": map[string]any{"type": "string"},
+						"message":    map[string]any{"type": "string"},
+						"request_id": map[string]any{"type": "string"},
+						"extra_data": map[string]any{},
+					},
+				},
+			},
+		},
+	}
+}
+
+// toolInvokeSuccessSchema is the response envelope of a finished run, data.outputs is null when the handler returned nothing
+func toolInvokeSuccessSchema(output map[string]any) map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"status":     map[string]any{"type": "string", "const": "ok"},
+			"message":    map[string]any{"type": "string"},
+			"request_id": map[string]any{"type": "string"},
+			"data": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"outputs":     map[string]any{"anyOf": []any{output, map[string]any{"type": "null"}}},
+					"updates":     map[string]any{"type": "array", "items": map[string]any{"type": "object"}},
+					"logs":        map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/Log"}},
+					"duration_ms": map[string]any{"type": "integer"},
+				},
+			},
+		},
+	}
+}
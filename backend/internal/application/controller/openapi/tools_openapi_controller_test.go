@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	"testing"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildToolsSpec(t *testing.T) {
+	input := map[string]any{"type": "object", "properties": map[string]any{"text": map[string]any{"type": "string"}}}
+	output := map[string]any{"type": "object", "properties": map[string]any{"echo": map[string]any{"type": "string"}}}
+	tools := []entity.InvocableToolEntity{
+		{
+			Tool:   entity.ToolEntity{UniqueID: "tool-echo", Namespace: "my utils", Name: "Echo Text", Description: "Echoes the text"},
+			Schema: entity.ToolSchemaEntity{Input: input, Output: output},
+		},
+		{
+			Tool:   entity.ToolEntity{UniqueID: "tool-slash", Namespace: "my utils", Name: "Encode/Decode"},
+			Schema: entity.ToolSchemaEntity{Input: input, Output: output},
+		},
+	}
+
+	spec := buildToolsSpec(tools, "https://toolbake.example.com/base")
+	require.Equal(t, "3.1.0", spec["openapi"])
+	require.Equal(t, []any{map[string]any{"url": "https://toolbake.example.com/base"}}, spec["servers"])
+
+	// the namespace and name are escaped, a name with a slash can not be routed and is left out
+	paths := spec["paths"].(map[string]any)
+	require.Len(t, paths, 1)
+	operation := paths["/api/tools/my%20utils/Echo%20Text/invoke"].(map[string]any)["post"].(map[string]any)
+	require.Equal(t, "tool-echo", operation["operationId"])
+	require.Equal(t, "Echoes the text", operation["description"])
+	require.Equal(t, []any{"my utils"}, operation["tags"])
+
+	body := operation["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+	require.Equal(t, input, body["properties"].(map[string]any)["inputs"])
+
+	response := operation["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+	data := response["properties"].(map[string]any)["data"].(map[string]any)
+	outputs := data["properties"].(map[string]any)["outputs"].(map[string]any)
+	require.Equal(t, []any{output, map[string]any{"type": "null"}}, outputs["anyOf"])
+}
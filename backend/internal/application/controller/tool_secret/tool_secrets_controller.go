@@ -0,0 +1,156 @@
+package tool_secret
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewToolSecretsController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	toolSecretService *service.ToolSecretService,
+) router.Controller {
+	return ToolSecretsController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		toolSecretService:          toolSecretService,
+	}
+}
+
+// ToolSecretsController manages the secret variables injected into tool runs.
+// The account routes and the tool routes share the handlers, the account routes have no tool_uid.
+type ToolSecretsController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	toolSecretService          *service.ToolSecretService
+}
+
+func (c ToolSecretsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/secrets", Handler: c.List, DemoModeAllowed: true},
+		{Method: http.MethodPut, Path: "/api/v1/secrets/:name", Handler: c.Put},
+		{Method: http.MethodDelete, Path: "/api/v1/secrets/:name", Handler: c.Delete},
+		{Method: http.MethodGet, Path: "/api/v1/tools/:tool_uid/secrets", Handler: c.List, DemoModeAllowed: true},
+		{Method: http.MethodPut, Path: "/api/v1/tools/:tool_uid/secrets/:name", Handler: c.Put},
+		{Method: http.MethodDelete, Path: "/api/v1/tools/:tool_uid/secrets/:name", Handler: c.Delete},
+	}
+}
+
+// @Summary		List secrets
+// @Description	List the account level secrets, or the secrets of a tool. Values are never returned, only masked.
+// @Tags			Secrets
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ListToolSecretsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/secrets [get]
+// @Router			/api/v1/tools/{tool_uid}/secrets [get]
+func (c *ToolSecretsController) List(ctx *gin.Context) {
+	logger.Infof(ctx, "List secrets requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	secrets, err := c.toolSecretService.ListSecrets(ctx, user.ID, toolUID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list secrets of tool %q for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp ListToolSecretsResponseDto
+	resp.FromEntity(secrets)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Set or rotate a secret
+// @Description	Create a secret, or rotate it when the name is already used: the value is replaced and the version increased.
+// @Description	Names are environment variable names (upper case letters, digits and underscores). A tool secret overrides the account secret with the same name.
+// @Tags			Secrets
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string					true	"Bearer access token"
+// @Param			tool_uid		path		string					true	"Tool unique identifier (UID)"
+// @Param			name			path		string					true	"Secret name"
+// @Param			request			body		PutToolSecretRequestDto	true	"Secret value"
+// @Success		200				{object}	swagger.BaseSuccessResponse[PutToolSecretResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/secrets/{name} [put]
+// @Router			/api/v1/tools/{tool_uid}/secrets/{name} [put]
+func (c *ToolSecretsController) Put(ctx *gin.Context) {
+	logger.Infof(ctx, "Put secret requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req PutToolSecretRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		// the binding error only names the field, never the value
+		logger.Errorf(ctx, "Invalid secret payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	toolUID, name := ctx.Param("tool_uid"), ctx.Param("name")
+	secret, err := c.toolSecretService.PutSecret(ctx, user.ID, toolUID, name, req.Value)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to put secret %s of tool %q for user %s: %v", name, toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Secret %s of tool %q set to version %d for user %s", name, toolUID, secret.Version, user.ID)
+	var resp PutToolSecretResponseDto
+	resp.Secret.FromEntity(secret)
+	c.Success(ctx, "Secret saved successfully", resp)
+}
+
+// @Summary		Delete a secret
+// @Description	Delete an account level secret, or a secret of a tool.
+// @Tags			Secrets
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Param			name			path		string	true	"Secret name"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/secrets/{name} [delete]
+// @Router			/api/v1/tools/{tool_uid}/secrets/{name} [delete]
+func (c *ToolSecretsController) Delete(ctx *gin.Context) {
+	logger.Infof(ctx, "Delete secret requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID, name := ctx.Param("tool_uid"), ctx.Param("name")
+	if err := c.toolSecretService.DeleteSecret(ctx, user.ID, toolUID, name); err != nil {
+		logger.Errorf(ctx, "Failed to delete secret %s of tool %q for user %s: %v", name, toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Secret %s of tool %q deleted for user %s", name, toolUID, user.ID)
+	c.Success(ctx, "Secret deleted successfully", gin.H{})
+}
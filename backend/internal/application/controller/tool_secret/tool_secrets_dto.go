@@ -0,0 +1,47 @@
+package tool_secret
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+type ToolSecretDto struct {
+	// ToolUID is empty for an account level secret
+	ToolUID string `json:"tool_uid" example:""`
+	Name    string `json:"name" example:"OPENAI_API_KEY"`
+	// MaskedValue never holds the value, only the last 4 characters of a value of 16 or more characters
+	MaskedValue string    `json:"masked_value" example:"********cdef"`
+	Version     int       `json:"version" example:"2"`
+	CreatedAt   time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt   time.Time `json:"updated_at" format:"date-time"`
+}
+
+func (dto *ToolSecretDto) FromEntity(info entity.ToolSecretInfoEntity) {
+	dto.ToolUID = info.ToolUID
+	dto.Name = info.Name
+	dto.MaskedValue = info.MaskedValue
+	dto.Version = info.Version
+	dto.CreatedAt = info.CreatedAt
+	dto.UpdatedAt = info.UpdatedAt
+}
+
+type ListToolSecretsResponseDto struct {
+	Secrets []ToolSecretDto `json:"secrets"`
+}
+
+func (dto *ListToolSecretsResponseDto) FromEntity(infos []entity.ToolSecretInfoEntity) {
+	dto.Secrets = make([]ToolSecretDto, 0, len(infos))
+	for _, info := range infos {
+		var secret ToolSecretDto
+		secret.FromEntity(info)
+		dto.Secrets = append(dto.Secrets, secret)
+	}
+}
+
+type PutToolSecretRequestDto struct {
+	Value string `json:"value" binding:"required"`
+}
+
+type PutToolSecretResponseDto struct {
+	Secret ToolSecretDto `json:"secret"`
+}
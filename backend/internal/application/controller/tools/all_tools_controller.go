@@ -2,7 +2,6 @@ package tools
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"ya-tool-craft/internal/application/controller/common"
 	"ya-tool-craft/internal/config"
@@ -41,16 +40,18 @@ type AllToolsController struct {
 
 func (c AllToolsController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodGet, Path: "/api/v1/tools", Handler: c.AllTools},
+		{Method: http.MethodGet, Path: "/api/v1/tools", Handler: c.AllTools, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/api/v1/tools/list", Handler: c.ListTools, DemoModeAllowed: true},
 	}
 }
 
 // @Summary		List tools
-// @Description	Retrieve all tools of the authenticated user
+// @Description	Retrieve the tools of the authenticated user, archived tools are excluded unless requested with the archived parameter
 // @Tags			Tools
 // @Accept			json
 // @Produce		json
 // @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			archived		query		string	false	"Archived tools handling"	Enums(exclude, include, only)	default(exclude)
 // @Success		200				{object}	swagger.BaseSuccessResponse[AllToolsResponseDto]
 // @Failure		400				{object}	swagger.BaseFailResponse
 // @Router			/api/v1/tools [get]
@@ -63,8 +64,15 @@ func (c *AllToolsController) AllTools(ctx *gin.Context) {
 		return
 	}
 
+	var req AllToolsRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+	archiveFilter := req.ArchiveFilter()
+
 	// Try to get from cache first
-	cacheKey := fmt.Sprintf("tools:%s", user.ID)
+	cacheKey := toolsCacheKey(user.ID, archiveFilter)
 	cachedValue, found, err := c.cache.Get(ctx, cacheKey)
 	if err != nil {
 		logger.Errorf(ctx, "Failed to get cache for user %s: %v", user.ID, err)
@@ -88,7 +96,7 @@ func (c *AllToolsController) AllTools(ctx *gin.Context) {
 		}
 
 		var resp AllToolsResponseDto
-		resp.FromEntity(toolsEntity)
+		resp.FromEntity(toolsEntity.FilterArchived(archiveFilter))
 
 		// Store result in cache as JSON string
 		respJSON, err := json.Marshal(resp)
@@ -108,3 +116,46 @@ func (c *AllToolsController) AllTools(ctx *gin.Context) {
 
 	c.SuccessWithCachedJsonString(ctx, "", respJsonObjectStr)
 }
+
+// @Summary		List a page of tools
+// @Description	Retrieve a page of the tools of the authenticated user with the total count of matching tools, for clients that should not load every tool and its source at once.
+// @Description	Archived tools are excluded unless requested with the archived parameter. Without sort_by the oldest tools come first, updated_at lists the most recently updated first.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			archived		query		string	false	"Archived tools handling"	Enums(exclude, include, only)	default(exclude)
+// @Param			category		query		string	false	"Only tools of the category, empty for tools without one"
+// @Param			namespace		query		string	false	"Only tools of the namespace"
+// @Param			is_activate		query		bool	false	"Only active or only inactive tools"
+// @Param			sort_by			query		string	false	"Order of the tools"	Enums(created_at, updated_at, name)	default(created_at)
+// @Param			limit			query		int		false	"Page size, 50 by default"
+// @Param			offset			query		int		false	"Tools to skip"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ListToolsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/list [get]
+func (c *AllToolsController) ListTools(ctx *gin.Context) {
+	logger.Infof(ctx, "List tools page requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req ListToolsRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	tools, total, err := c.toolRepository.ListTools(user.ID, req.ToEntity())
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list tools for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected fetch tools error"))
+		return
+	}
+
+	var resp ListToolsResponseDto
+	resp.FromEntity(tools, total)
+	c.Success(ctx, "", resp)
+}
@@ -7,6 +7,17 @@ import (
 	"github.com/samber/lo"
 )
 
+type AllToolsRequestDto struct {
+	Archived string `form:"archived" binding:"omitempty,oneof=exclude include only" example:"exclude"`
+}
+
+func (dto AllToolsRequestDto) ArchiveFilter() entity.ToolArchiveFilter {
+	if dto.Archived == "" {
+		return entity.ToolArchiveFilterExclude
+	}
+	return entity.ToolArchiveFilter(dto.Archived)
+}
+
 type AllToolsResponseDto struct {
 	Tools              []ToolDto `json:"tools"`
 	ToolsLastUpdatedAt time.Time `json:"tools_last_update_at" format:"date-time"`
@@ -21,3 +32,44 @@ func (dto *AllToolsResponseDto) FromEntity(list entity.ToolsEntity) {
 
 	dto.ToolsLastUpdatedAt = list.LastUpdatedAt
 }
+
+// listToolsDefaultLimit is the page size of a tool listing without a limit
+const listToolsDefaultLimit = 50
+
+type ListToolsRequestDto struct {
+	Archived string `form:"archived" binding:"omitempty,oneof=exclude include only" example:"exclude"`
+	// Category and Namespace match exactly, an empty category matches the tools without one
+	Category   *string `form:"category" binding:"omitempty,max=255" example:"analytics"`
+	Namespace  *string `form:"namespace" binding:"omitempty,max=255" example:"utils"`
+	IsActivate *bool   `form:"is_activate" example:"true"`
+	SortBy     string  `form:"sort_by" binding:"omitempty,oneof=created_at updated_at name" example:"updated_at"`
+	Limit      int     `form:"limit" binding:"omitempty,min=1,max=200" example:"50"`
+	Offset     int     `form:"offset" binding:"omitempty,min=0" example:"0"`
+}
+
+func (dto ListToolsRequestDto) ToEntity() entity.ToolListOptions {
+	return entity.ToolListOptions{
+		Category:   dto.Category,
+		Namespace:  dto.Namespace,
+		IsActivate: dto.IsActivate,
+		Archived:   AllToolsRequestDto{Archived: dto.Archived}.ArchiveFilter(),
+		SortBy:     entity.ToolSortBy(dto.SortBy),
+		Limit:      lo.Ternary(dto.Limit == 0, listToolsDefaultLimit, dto.Limit),
+		Offset:     dto.Offset,
+	}
+}
+
+type ListToolsResponseDto struct {
+	Tools []ToolDto `json:"tools"`
+	// Total is the number of tools matching the filters, across all pages
+	Total int `json:"total" example:"120"`
+}
+
+func (dto *ListToolsResponseDto) FromEntity(tools []entity.ToolEntity, total int) {
+	dto.Tools = lo.Map(tools, func(tool entity.ToolEntity, _ int) ToolDto {
+		item := ToolDto{}
+		item.FromEntity(tool)
+		return item
+	})
+	dto.Total = total
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"errors"
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewArchiveToolController(
+	config config.Config,
+	toolRepository repository.IToolRepository,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	cache repository.ICache,
+) router.Controller {
+	return ArchiveToolController{
+		config:                     config,
+		toolRepository:             toolRepository,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		cache:                      cache,
+	}
+}
+
+type ArchiveToolController struct {
+	common.JsonResponse
+
+	config                     config.Config
+	toolRepository             repository.IToolRepository
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	cache                      repository.ICache
+}
+
+func (c ArchiveToolController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPut, Path: "/api/v1/tools/:tool_uid/archive", Handler: c.Archive},
+	}
+}
+
+// @Summary		Archive or restore tool
+// @Description	Archive a retired tool or restore an archived one. Archived tools keep their data but are hidden from the default tool list and sync.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string					true	"Bearer access token"
+// @Param			tool_uid		path		string					true	"Tool unique identifier (UID)"
+// @Param			request			body		ArchiveToolRequestDto	true	"Archived state"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ArchiveToolResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/archive [put]
+func (c *ArchiveToolController) Archive(ctx *gin.Context) {
+	logger.Infof(ctx, "Archive Tool requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	if toolUID == "" {
+		logger.Errorf(ctx, "Invalid tool archive: tool_uid is required")
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "tool_uid is required"))
+		return
+	}
+
+	var req ArchiveToolRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid tool archive payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	if err := c.toolRepository.SetToolArchived(user.ID, toolUID, *req.Archived, service.ToolEventSourceFromContext(ctx, user.ID)); err != nil {
+		if errors.Is(err, repository.ErrToolNotFound) {
+			c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.ToolNotFound, err.Error()))
+			return
+		}
+		logger.Errorf(ctx, "Failed to archive tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected archive tool error"))
+		return
+	}
+
+	if err := DeleteToolsCache(ctx, c.cache, user.ID); err != nil {
+		logger.Errorf(ctx, "Failed to delete cache for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error"))
+		return
+	}
+
+	logger.Infof(ctx, "Tool archived state set to %t for user %s with tool uid %s", *req.Archived, user.ID, toolUID)
+	c.Success(ctx, "Tool archived state updated successfully", ArchiveToolResponseDto{})
+}
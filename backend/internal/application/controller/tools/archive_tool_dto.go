@@ -0,0 +1,7 @@
+package tools
+
+type ArchiveToolRequestDto struct {
+	Archived *bool `json:"archived" binding:"required" example:"true"`
+}
+
+type ArchiveToolResponseDto struct{}
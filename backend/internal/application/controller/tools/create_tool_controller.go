@@ -7,6 +7,7 @@ import (
 	"ya-tool-craft/internal/core/logger"
 	"ya-tool-craft/internal/core/router"
 	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
 	"ya-tool-craft/internal/error_code"
 
 	_ "ya-tool-craft/internal/swagger"
@@ -16,15 +17,17 @@ import (
 
 func NewCreateToolController(
 	config config.Config,
-	toolRepository repository.IToolRepository,
 	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
 	cache repository.ICache,
+	toolService *service.ToolService,
+	namespaceService *service.NamespaceService,
 ) router.Controller {
 	return CreateToolController{
 		config:                     config,
-		toolRepository:             toolRepository,
 		accessTokenHeaderValidator: accessTokenHeaderValidator,
 		cache:                      cache,
+		toolService:                toolService,
+		namespaceService:           namespaceService,
 	}
 }
 
@@ -32,9 +35,10 @@ type CreateToolController struct {
 	common.JsonResponse
 
 	config                     config.Config
-	toolRepository             repository.IToolRepository
 	accessTokenHeaderValidator common.AccessTokenHeaderValidator
 	cache                      repository.ICache
+	toolService                *service.ToolService
+	namespaceService           *service.NamespaceService
 }
 
 func (c CreateToolController) RouterInfo() []router.RouterInfo {
@@ -44,7 +48,8 @@ func (c CreateToolController) RouterInfo() []router.RouterInfo {
 }
 
 // @Summary		Create tool
-// @Description	Create a custom tool under the authenticated user
+// @Description	Create a custom tool under the authenticated user. Credentials found in the source are reported as warnings, or reject the tool when the server blocks them.
+// @Description	A category, realtime_execution or ui_widgets left out is taken from the defaults of the namespace.
 // @Tags			Tools
 // @Accept			json
 // @Produce		json
@@ -52,6 +57,8 @@ func (c CreateToolController) RouterInfo() []router.RouterInfo {
 // @Param			request			body		CreateToolRequestDto	true	"Tool definition"
 // @Success		200				{object}	swagger.BaseSuccessResponse[CreateToolResponseDto]
 // @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
 // @Router			/api/v1/tools/create [post]
 func (c *CreateToolController) Create(ctx *gin.Context) {
 	logger.Infof(ctx, "Create Tool requested")
@@ -69,20 +76,26 @@ func (c *CreateToolController) Create(ctx *gin.Context) {
 		return
 	}
 
-	tool := req.ToEntity()
+	defaults, err := c.namespaceService.ToolDefaults(ctx, user.ID, req.Namespace)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get the defaults of namespace %s for user %s: %v", req.Namespace, user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected fetch namespace error"))
+		return
+	}
+	tool := req.ToEntity(defaults)
 
-	if err := c.toolRepository.CreateTool(user.ID, tool); err != nil {
-		logger.Errorf(ctx, "Failed to create tool for user %s: %v", user.ID, err)
-		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected create tool error"))
+	_, findings, err := c.toolService.SaveNewTool(ctx, user.ID, tool)
+	if err != nil {
+		c.Error(ctx, err)
 		return
 	}
 
-	if err := c.cache.Delete(ctx, toolsCacheKey(user.ID)); err != nil {
+	if err := DeleteToolsCache(ctx, c.cache, user.ID); err != nil {
 		logger.Errorf(ctx, "Failed to delete cache for user %s: %v", user.ID, err)
 		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error"))
 		return
 	}
 
 	logger.Infof(ctx, "Tool created successfully for user %s with tool id %s", user.ID, tool.ID)
-	c.Success(ctx, "Tool created successfully", CreateToolResponseDto{})
+	c.Success(ctx, "Tool created successfully", CreateToolResponseDto{Warnings: toolSecretWarningsFromEntity(findings)})
 }
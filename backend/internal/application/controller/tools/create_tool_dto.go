@@ -7,25 +7,29 @@ import (
 	"ya-tool-craft/internal/domain/entity"
 
 	"github.com/google/uuid"
+	"github.com/samber/lo"
 )
 
 type CreateToolRequestDto struct {
-	ID                string            `json:"id" binding:"required,min=1,max=128" example:"tool-123"`
-	Name              string            `json:"name" binding:"required,min=1,max=255" example:"Sample Tool"`
-	Namespace         string            `json:"namespace" binding:"required,min=1,max=255" example:"default"`
-	IsActivate        bool              `json:"is_activate" example:"true"`
-	RealtimeExecution bool              `json:"realtime_execution" example:"false"`
-	UiWidgets         string            `json:"ui_widgets" binding:"required" example:"[]"`
+	// ID is generated by the server when left out
+	ID         string `json:"id" binding:"omitempty,max=128" example:"tool-123"`
+	Name       string `json:"name" binding:"required,min=1,max=255" example:"Sample Tool"`
+	Namespace  string `json:"namespace" binding:"required,min=1,max=255" example:"default"`
+	IsActivate bool   `json:"is_activate" example:"true"`
+	// RealtimeExecution, UiWidgets and Category default to the settings of the namespace when left out
+	RealtimeExecution *bool             `json:"realtime_execution" binding:"omitempty" example:"false"`
+	UiWidgets         *string           `json:"ui_widgets" binding:"omitempty" example:"[]"`
 	Source            string            `json:"source" binding:"required" example:"// source code"`
 	Description       *string           `json:"description" binding:"omitempty" example:"Describe the tool briefly"`
 	ExtraInfo         map[string]string `json:"extra_info" binding:"required" example:"{\"key\":\"value\"}"`
 	Category          *string           `json:"category" binding:"omitempty,max=255" example:"analytics"`
 }
 
-func (dto CreateToolRequestDto) ToEntity() entity.ToolEntity {
+// ToEntity builds the tool, the fields left out of the request are taken from the defaults of its namespace.
+func (dto CreateToolRequestDto) ToEntity(defaults entity.NamespaceEntity) entity.ToolEntity {
 	toolID := strings.TrimSpace(dto.ID)
 	if toolID == "" {
-		toolID = fmt.Sprintf("generated-tool-%s", uuid.New().String())
+		toolID = fmt.Sprintf("generated-tool-%s", uuid.Must(uuid.NewV7()).String())
 	}
 
 	extraInfo := dto.ExtraInfo
@@ -33,15 +37,20 @@ func (dto CreateToolRequestDto) ToEntity() entity.ToolEntity {
 		extraInfo = map[string]string{}
 	}
 
+	uiWidgets := defaults.DefaultUiWidgets
+	if uiWidgets == "" {
+		uiWidgets = "[]"
+	}
+
 	now := time.Now().UTC()
 	return entity.NewToolEntityWithoutUID(
 		toolID,
 		dto.Name,
 		dto.Namespace,
-		stringValue(dto.Category),
+		lo.FromPtrOr(dto.Category, defaults.DefaultCategory),
 		dto.IsActivate,
-		dto.RealtimeExecution,
-		dto.UiWidgets,
+		lo.FromPtrOr(dto.RealtimeExecution, defaults.DefaultRealtimeExecution),
+		lo.FromPtrOr(dto.UiWidgets, uiWidgets),
 		dto.Source,
 		stringValue(dto.Description),
 		extraInfo,
@@ -50,4 +59,7 @@ func (dto CreateToolRequestDto) ToEntity() entity.ToolEntity {
 	)
 }
 
-type CreateToolResponseDto struct{}
+type CreateToolResponseDto struct {
+	// Warnings lists credentials found in the source, the tool is saved anyway unless the server blocks them
+	Warnings []ToolSecretWarningDto `json:"warnings"`
+}
@@ -7,6 +7,7 @@ import (
 	"ya-tool-craft/internal/core/logger"
 	"ya-tool-craft/internal/core/router"
 	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
 	"ya-tool-craft/internal/error_code"
 
 	_ "ya-tool-craft/internal/swagger"
@@ -19,12 +20,14 @@ func NewDeleteToolController(
 	toolRepository repository.IToolRepository,
 	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
 	cache repository.ICache,
+	meteringService *service.MeteringService,
 ) router.Controller {
 	return DeleteToolController{
 		config:                     config,
 		toolRepository:             toolRepository,
 		accessTokenHeaderValidator: accessTokenHeaderValidator,
 		cache:                      cache,
+		meteringService:            meteringService,
 	}
 }
 
@@ -35,6 +38,7 @@ type DeleteToolController struct {
 	toolRepository             repository.IToolRepository
 	accessTokenHeaderValidator common.AccessTokenHeaderValidator
 	cache                      repository.ICache
+	meteringService            *service.MeteringService
 }
 
 func (c DeleteToolController) RouterInfo() []router.RouterInfo {
@@ -69,13 +73,18 @@ func (c *DeleteToolController) Delete(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.toolRepository.DeleteTool(user.ID, toolUID); err != nil {
+	if err := c.toolRepository.DeleteTool(user.ID, toolUID, service.ToolEventSourceFromContext(ctx, user.ID)); err != nil {
 		logger.Errorf(ctx, "Failed to delete tool %s for user %s: %v", toolUID, user.ID, err)
 		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete tool error"))
 		return
 	}
 
-	if err := c.cache.Delete(ctx, toolsCacheKey(user.ID)); err != nil {
+	// the tool is gone either way, a storage usage that is not updated is measured again on the next save
+	if err := c.meteringService.RecordToolStorage(ctx, user.ID); err != nil {
+		logger.Errorf(ctx, "Failed to record tool storage for user %s: %v", user.ID, err)
+	}
+
+	if err := DeleteToolsCache(ctx, c.cache, user.ID); err != nil {
 		logger.Errorf(ctx, "Failed to delete cache for user %s: %v", user.ID, err)
 		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error"))
 		return
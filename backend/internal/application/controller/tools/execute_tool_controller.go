@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewExecuteToolController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	toolExecutionService *service.ToolExecutionService,
+) router.Controller {
+	return ExecuteToolController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		toolExecutionService:       toolExecutionService,
+	}
+}
+
+type ExecuteToolController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	toolExecutionService       *service.ToolExecutionService
+}
+
+func (c ExecuteToolController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/tools/:tool_uid/execute", Handler: c.Execute},
+	}
+}
+
+// @Summary		Run a tool on the server
+// @Description	Call the handler of the tool in a sandboxed javascript runtime on the server, for scripts and automations without a browser.
+// @Description	Only available when TOOL_EXECUTION_ENABLED is set, see the tool_execution capability. The handler gets the secrets of the tool as the secrets global,
+// @Description	but no requirePackage, timers or network. A failed run reports the console output written before in extra_data.logs.
+// @Description	Runs are limited per user, the X-RateLimit-* headers report the limit of the caller. resource_class picks the time and
+// @Description	memory limits of the run, a medium run is metered as 4 tool executions and a large run as 16.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string					true	"Bearer access token"
+// @Param			tool_uid		path		string					true	"Tool unique identifier (UID)"
+// @Param			request			body		ExecuteToolRequestDto	true	"Handler arguments"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ExecuteToolResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		413				{object}	swagger.BaseFailResponse
+// @Failure		422				{object}	swagger.BaseFailResponse
+// @Failure		429				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/execute [post]
+func (c *ExecuteToolController) Execute(ctx *gin.Context) {
+	logger.Infof(ctx, "Tool execution requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req ExecuteToolRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid tool execution payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	result, rateLimit, err := c.toolExecutionService.ExecuteTool(ctx, user.ID, toolUID, req.ToEntity())
+	common.SetRateLimitHeaders(ctx, rateLimit)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to run tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Tool %s ran for user %s in %s", toolUID, user.ID, result.Duration)
+	var resp ExecuteToolResponseDto
+	resp.FromEntity(result)
+	c.Success(ctx, "", resp)
+}
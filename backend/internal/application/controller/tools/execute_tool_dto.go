@@ -0,0 +1,47 @@
+package tools
+
+import "ya-tool-craft/internal/domain/entity"
+
+type ExecuteToolRequestDto struct {
+	// Inputs are the values of the input widgets by widget id, the handler gets them as its first argument
+	Inputs map[string]any `json:"inputs" binding:"required" swaggertype:"object"`
+	// ChangedWidgetID is passed to the handler as the widget that triggered the run
+	ChangedWidgetID string `json:"changed_widget_id" binding:"omitempty,max=128" example:"input-text"`
+	// ResourceClass selects the time and memory limits of the run, a run without one is small
+	ResourceClass string `json:"resource_class" binding:"omitempty,oneof=small medium large" enums:"small,medium,large" example:"small"`
+}
+
+func (dto ExecuteToolRequestDto) ToEntity() entity.ToolExecutionRequestEntity {
+	return entity.ToolExecutionRequestEntity{
+		Inputs:          dto.Inputs,
+		ChangedWidgetID: dto.ChangedWidgetID,
+		ResourceClass:   entity.ToolResourceClass(dto.ResourceClass),
+	}
+}
+
+type ToolExecutionLogDto struct {
+	Level   string `json:"level" example:"log"`
+	Message string `json:"message" example:"processing 3 items"`
+}
+
+type ExecuteToolResponseDto struct {
+	// Outputs is the object the handler returned, null when it returned undefined or null
+	Outputs map[string]any `json:"outputs" swaggertype:"object"`
+	// Updates are the objects the handler passed to its callback, in order
+	Updates    []map[string]any      `json:"updates"`
+	Logs       []ToolExecutionLogDto `json:"logs"`
+	DurationMs int64                 `json:"duration_ms" example:"12"`
+}
+
+func (dto *ExecuteToolResponseDto) FromEntity(result entity.ToolExecutionResultEntity) {
+	dto.Outputs = result.Outputs
+	dto.Updates = result.Updates
+	if dto.Updates == nil {
+		dto.Updates = []map[string]any{}
+	}
+	dto.Logs = make([]ToolExecutionLogDto, len(result.Logs))
+	for i, log := range result.Logs {
+		dto.Logs[i] = ToolExecutionLogDto{Level: log.Level, Message: log.Message}
+	}
+	dto.DurationMs = result.Duration.Milliseconds()
+}
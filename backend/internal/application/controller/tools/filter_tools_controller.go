@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewFilterToolsController(
+	toolRepository repository.IToolRepository,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return FilterToolsController{
+		toolRepository:             toolRepository,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type FilterToolsController struct {
+	common.JsonResponse
+
+	toolRepository             repository.IToolRepository
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c FilterToolsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/filter", Handler: c.FilterTools, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Filter tools by extra info
+// @Description	List the tools of the authenticated user whose extra info matches every extra_info.<key>=<value> query parameter, e.g. ?extra_info.language=python
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization			header		string	true	"Bearer access token"
+// @Param			extra_info.{key}		query		string	true	"Extra info value to match, at least one extra_info.<key> parameter is required"
+// @Success		200						{object}	swagger.BaseSuccessResponse[FilterToolsResponseDto]
+// @Failure		400						{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/filter [get]
+func (c *FilterToolsController) FilterTools(ctx *gin.Context) {
+	logger.Infof(ctx, "Filter tools requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	filters := extraInfoFiltersFromQuery(ctx.Request.URL.Query())
+	if len(filters) == 0 {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, "at least one extra_info.<key> query parameter is required"))
+		return
+	}
+
+	tools, err := c.toolRepository.FilterToolsByExtraInfo(user.ID, filters)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to filter tools for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected filter tools error"))
+		return
+	}
+
+	var resp FilterToolsResponseDto
+	resp.FromEntity(tools)
+	c.Success(ctx, "", resp)
+}
@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"strings"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+// filterToolsExtraInfoQueryPrefix marks the query parameters used as extra info filters,
+// e.g. ?extra_info.language=python
+const filterToolsExtraInfoQueryPrefix = "extra_info."
+
+type FilterToolsResponseDto struct {
+	Tools []ToolDto `json:"tools"`
+}
+
+func (dto *FilterToolsResponseDto) FromEntity(tools []entity.ToolEntity) {
+	dto.Tools = lo.Map(tools, func(tool entity.ToolEntity, _ int) ToolDto {
+		item := ToolDto{}
+		item.FromEntity(tool)
+		return item
+	})
+}
+
+// extraInfoFiltersFromQuery collects the extra_info.<key>=<value> query parameters into a filter map.
+// When a key is given more than once the last value wins.
+func extraInfoFiltersFromQuery(query map[string][]string) map[string]string {
+	filters := map[string]string{}
+	for name, values := range query {
+		key, ok := strings.CutPrefix(name, filterToolsExtraInfoQueryPrefix)
+		if !ok || key == "" || len(values) == 0 {
+			continue
+		}
+		filters[key] = values[len(values)-1]
+	}
+	return filters
+}
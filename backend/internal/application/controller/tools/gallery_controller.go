@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewGalleryController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	cache repository.ICache,
+	galleryService *service.GalleryService,
+) router.Controller {
+	return GalleryController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		cache:                      cache,
+		galleryService:             galleryService,
+	}
+}
+
+// GalleryController serves the public gallery of published tools, browsing it needs no login
+type GalleryController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	cache                      repository.ICache
+	galleryService             *service.GalleryService
+}
+
+func (c GalleryController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/gallery", Handler: c.List, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/api/v1/gallery/:tool_uid", Handler: c.Get, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/gallery/:tool_uid/install", Handler: c.Install},
+		{Method: http.MethodPost, Path: "/api/v1/gallery/:tool_uid/fork", Handler: c.Fork},
+	}
+}
+
+// @Summary		Browse the gallery
+// @Description	Lists the published tools, newest first or with the most installs and forks first. q matches the tool name,
+// @Description	category the category and tag one of the comma separated tags in the tags extra info, all case-insensitively except the category.
+// @Tags			Gallery
+// @Produce		json
+// @Param			q			query		string	false	"Text the tool name contains"
+// @Param			category	query		string	false	"Category of the tools"
+// @Param			tag			query		string	false	"Tag of the tools"
+// @Param			sort		query		string	false	"newest (default) or popular"
+// @Param			limit		query		int		false	"Page size, 20 by default"
+// @Param			offset		query		int		false	"Tools to skip"
+// @Success		200			{object}	swagger.BaseSuccessResponse[GalleryResponseDto]
+// @Failure		400			{object}	swagger.BaseFailResponse
+// @Router			/api/v1/gallery [get]
+func (c *GalleryController) List(ctx *gin.Context) {
+	logger.Infof(ctx, "Gallery requested")
+
+	var req GalleryRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		logger.Errorf(ctx, "Invalid gallery query: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	galleryTools, total, err := c.galleryService.ListGallery(ctx, req.ToEntity())
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list gallery tools: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected fetch gallery error"))
+		return
+	}
+
+	var resp GalleryResponseDto
+	resp.FromEntity(galleryTools, total)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Get a gallery tool
+// @Description	Returns a published tool with its source and ui widgets, so it can be tried before it is installed
+// @Tags			Gallery
+// @Produce		json
+// @Param			tool_uid	path		string	true	"Tool unique identifier (UID)"
+// @Success		200			{object}	swagger.BaseSuccessResponse[GetGalleryToolResponseDto]
+// @Failure		404			{object}	swagger.BaseFailResponse
+// @Router			/api/v1/gallery/{tool_uid} [get]
+func (c *GalleryController) Get(ctx *gin.Context) {
+	logger.Infof(ctx, "Get Gallery Tool requested")
+
+	toolUID := ctx.Param("tool_uid")
+	galleryTool, err := c.galleryService.GetGalleryTool(ctx, toolUID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get gallery tool %s: %v", toolUID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp GetGalleryToolResponseDto
+	resp.GalleryTool.FromEntity(galleryTool)
+	resp.Tool.FromEntity(galleryTool.Tool)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Install a gallery tool
+// @Description	Copies a published tool into the tools of the authenticated user to use it. The copy gets a new uid, and a number
+// @Description	on its tool id when the user already has the id. Later changes of the owner do not reach it.
+// @Tags			Gallery
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Success		200				{object}	swagger.BaseSuccessResponse[CopyGalleryToolResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/gallery/{tool_uid}/install [post]
+func (c *GalleryController) Install(ctx *gin.Context) {
+	c.copyTool(ctx, entity.GalleryCopyInstall)
+}
+
+// @Summary		Fork a gallery tool
+// @Description	Copies a published tool into the tools of the authenticated user to change it. The copy gets a new uid, a -fork
+// @Description	suffix on its tool id when the user already has the id, and remembers the gallery tool in its forked_from once published.
+// @Tags			Gallery
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Success		200				{object}	swagger.BaseSuccessResponse[CopyGalleryToolResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/gallery/{tool_uid}/fork [post]
+func (c *GalleryController) Fork(ctx *gin.Context) {
+	c.copyTool(ctx, entity.GalleryCopyFork)
+}
+
+func (c *GalleryController) copyTool(ctx *gin.Context, kind entity.GalleryCopyKind) {
+	logger.Infof(ctx, "Gallery Tool %s requested", kind)
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	tool, findings, err := c.galleryService.CopyTool(ctx, user.ID, toolUID, kind)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to %s gallery tool %s for user %s: %v", kind, toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	if err := DeleteToolsCache(ctx, c.cache, user.ID); err != nil {
+		logger.Errorf(ctx, "Failed to delete cache for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error"))
+		return
+	}
+
+	logger.Infof(ctx, "Gallery tool %s copied (%s) for user %s with tool id %s", toolUID, kind, user.ID, tool.ID)
+	var resp CopyGalleryToolResponseDto
+	resp.Tool.FromEntity(tool)
+	resp.Warnings = toolSecretWarningsFromEntity(findings)
+	c.Success(ctx, "Gallery tool copied successfully", resp)
+}
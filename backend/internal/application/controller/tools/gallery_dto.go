@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type GalleryRequestDto struct {
+	Query    string `form:"q" binding:"omitempty,max=255" example:"json"`
+	Category string `form:"category" binding:"omitempty,max=255" example:"formatter"`
+	Tag      string `form:"tag" binding:"omitempty,max=32" example:"json"`
+	Sort     string `form:"sort" binding:"omitempty,oneof=newest popular" enums:"newest,popular" example:"popular"`
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=100" example:"20"`
+	Offset   int    `form:"offset" binding:"omitempty,min=0" example:"0"`
+}
+
+func (dto GalleryRequestDto) ToEntity() entity.GalleryFilter {
+	return entity.GalleryFilter{
+		Query:    dto.Query,
+		Category: dto.Category,
+		Tag:      dto.Tag,
+		Sort:     entity.GallerySort(dto.Sort),
+		Limit:    dto.Limit,
+		Offset:   dto.Offset,
+	}
+}
+
+// GalleryToolDto describes a published tool without its source, uid is the id of the tool in the gallery.
+type GalleryToolDto struct {
+	UID         string   `json:"uid" example:"tool-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	ToolID      string   `json:"tool_id" example:"json-format"`
+	Name        string   `json:"name" example:"JSON Formatter"`
+	Namespace   string   `json:"namespace" example:"default"`
+	Category    string   `json:"category" example:"formatter"`
+	Description string   `json:"description" example:"Pretty prints JSON"`
+	Tags        []string `json:"tags" example:"json,format"`
+	// ForkedFrom is the uid of the gallery tool this one was forked from, empty when it was not forked
+	ForkedFrom   string    `json:"forked_from" example:""`
+	OwnerName    string    `json:"owner_name" example:"alice"`
+	InstallCount int64     `json:"install_count" example:"12"`
+	ForkCount    int64     `json:"fork_count" example:"3"`
+	PublishedAt  time.Time `json:"published_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt    time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+func (dto *GalleryToolDto) FromEntity(galleryTool entity.GalleryToolEntity) {
+	tool := galleryTool.Tool
+	dto.UID = tool.UniqueID
+	dto.ToolID = tool.ID
+	dto.Name = tool.Name
+	dto.Namespace = tool.Namespace
+	dto.Category = tool.Category
+	dto.Description = tool.Description
+	dto.Tags = entity.ParseToolTags(tool.ExtraInfo[entity.ToolExtraInfoKeyTags])
+	dto.ForkedFrom = tool.ExtraInfo[entity.ToolExtraInfoKeyForkedFrom]
+	dto.OwnerName = galleryTool.OwnerName
+	dto.InstallCount = galleryTool.InstallCount
+	dto.ForkCount = galleryTool.ForkCount
+	dto.PublishedAt = galleryTool.PublishedAt
+	dto.UpdatedAt = tool.UpdatedAt
+}
+
+type GalleryResponseDto struct {
+	Tools []GalleryToolDto `json:"tools"`
+	Total int              `json:"total" example:"1"`
+}
+
+func (dto *GalleryResponseDto) FromEntity(galleryTools []entity.GalleryToolEntity, total int) {
+	dto.Tools = lo.Map(galleryTools, func(galleryTool entity.GalleryToolEntity, _ int) GalleryToolDto {
+		var galleryToolDto GalleryToolDto
+		galleryToolDto.FromEntity(galleryTool)
+		return galleryToolDto
+	})
+	dto.Total = total
+}
+
+type GetGalleryToolResponseDto struct {
+	GalleryTool GalleryToolDto `json:"gallery_tool"`
+	// Tool is the published tool with its source and ui widgets
+	Tool ToolDto `json:"tool"`
+}
+
+type CopyGalleryToolResponseDto struct {
+	Tool     ToolDto                `json:"tool"`
+	Warnings []ToolSecretWarningDto `json:"warnings"`
+}
@@ -0,0 +1,258 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+)
+
+func NewGithubImportController(
+	githubImportService *service.GithubImportService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	cache repository.ICache,
+) router.Controller {
+	return GithubImportController{
+		githubImportService:        githubImportService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		cache:                      cache,
+	}
+}
+
+type GithubImportController struct {
+	common.JsonResponse
+
+	githubImportService        *service.GithubImportService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	cache                      repository.ICache
+}
+
+func (c GithubImportController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/import/github/repos", Handler: c.ListRepos, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/api/v1/tools/import/github/repos/:owner/:repo/files", Handler: c.ListRepoFiles, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/tools/import/github/repos/:owner/:repo", Handler: c.ImportRepoFiles},
+		{Method: http.MethodGet, Path: "/api/v1/tools/import/github/gists", Handler: c.ListGists, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/tools/import/github/gists/:gist_id", Handler: c.ImportGistFiles},
+	}
+}
+
+// @Summary		List GitHub repositories
+// @Description	List the repositories the GitHub account bound to the authenticated user can read, most recently updated first
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			page			query		int		false	"Page, starts at 1"
+// @Success		200				{object}	swagger.BaseSuccessResponse[GithubReposResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		429				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/import/github/repos [get]
+func (c *GithubImportController) ListRepos(ctx *gin.Context) {
+	logger.Infof(ctx, "List GitHub repositories requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req GithubImportPageRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	page, err := c.githubImportService.ListRepos(ctx, user.ID, req.Page)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list GitHub repositories for user %s: %v", user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp GithubReposResponseDto
+	resp.FromEntity(page)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		List importable files of a GitHub repository
+// @Description	List the .js files of the default branch of a repository, each can be imported as a tool
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			owner			path		string	true	"Repository owner"
+// @Param			repo			path		string	true	"Repository name"
+// @Success		200				{object}	swagger.BaseSuccessResponse[GithubRepoFilesResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		429				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/import/github/repos/{owner}/{repo}/files [get]
+func (c *GithubImportController) ListRepoFiles(ctx *gin.Context) {
+	logger.Infof(ctx, "List GitHub repository files requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	files, err := c.githubImportService.ListRepoFiles(ctx, user.ID, ctx.Param("owner"), ctx.Param("repo"))
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list GitHub repository files for user %s: %v", user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp GithubRepoFilesResponseDto
+	resp.FromEntity(files)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Import tools from a GitHub repository
+// @Description	Import the selected .js files of the default branch as tools. The directory of a file becomes the namespace, a handler.js is named after its directory,
+// @Description	and a uiWidgets.json next to the file becomes the widgets. Every file passes the import scan, files whose tool id exists, whose source is blocked by the
+// @Description	secret scan or that exceed the tool quota are skipped and reported with a reason.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string							true	"Bearer access token"
+// @Param			owner			path		string							true	"Repository owner"
+// @Param			repo			path		string							true	"Repository name"
+// @Param			request			body		GithubImportRepoFilesRequestDto	true	"Files to import"
+// @Success		200				{object}	swagger.BaseSuccessResponse[GithubImportResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		413				{object}	swagger.BaseFailResponse
+// @Failure		429				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/import/github/repos/{owner}/{repo} [post]
+func (c *GithubImportController) ImportRepoFiles(ctx *gin.Context) {
+	logger.Infof(ctx, "Import tools from GitHub repository requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req GithubImportRepoFilesRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	results, err := c.githubImportService.ImportRepoFiles(ctx, user.ID, ctx.Param("owner"), ctx.Param("repo"), req.Paths)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to import tools from GitHub repository for user %s: %v", user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+	c.importFinished(ctx, user.ID, results)
+}
+
+// @Summary		List GitHub gists
+// @Description	List the gists of the GitHub account bound to the authenticated user
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			page			query		int		false	"Page, starts at 1"
+// @Success		200				{object}	swagger.BaseSuccessResponse[GithubGistsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		429				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/import/github/gists [get]
+func (c *GithubImportController) ListGists(ctx *gin.Context) {
+	logger.Infof(ctx, "List GitHub gists requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req GithubImportPageRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	page, err := c.githubImportService.ListGists(ctx, user.ID, req.Page)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list GitHub gists for user %s: %v", user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp GithubGistsResponseDto
+	resp.FromEntity(page)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Import tools from a GitHub gist
+// @Description	Import the selected .js files of a gist as tools in the gists namespace, a uiWidgets.json in the gist becomes the widgets.
+// @Description	Skipped files are reported with a reason like the repository import.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string							true	"Bearer access token"
+// @Param			gist_id			path		string							true	"Gist id"
+// @Param			request			body		GithubImportGistFilesRequestDto	true	"Files to import"
+// @Success		200				{object}	swagger.BaseSuccessResponse[GithubImportResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		413				{object}	swagger.BaseFailResponse
+// @Failure		429				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/import/github/gists/{gist_id} [post]
+func (c *GithubImportController) ImportGistFiles(ctx *gin.Context) {
+	logger.Infof(ctx, "Import tools from GitHub gist requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req GithubImportGistFilesRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	results, err := c.githubImportService.ImportGistFiles(ctx, user.ID, ctx.Param("gist_id"), req.Files)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to import tools from GitHub gist for user %s: %v", user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+	c.importFinished(ctx, user.ID, results)
+}
+
+// importFinished drops the cached tool lists when anything was imported and answers with the results
+func (c *GithubImportController) importFinished(ctx *gin.Context, userID entity.UserIDEntity, results []entity.GithubImportResultEntity) {
+	imported := lo.CountBy(results, func(result entity.GithubImportResultEntity) bool {
+		return result.Imported
+	})
+	if imported > 0 {
+		if err := DeleteToolsCache(ctx, c.cache, userID); err != nil {
+			logger.Errorf(ctx, "Failed to delete cache for user %s: %v", userID, err)
+			c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error"))
+			return
+		}
+	}
+
+	logger.Infof(ctx, "Imported %d of %d GitHub files as tools for user %s", imported, len(results), userID)
+	var resp GithubImportResponseDto
+	resp.FromEntity(results)
+	c.Success(ctx, "Tools imported", resp)
+}
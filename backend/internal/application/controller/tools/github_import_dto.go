@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type GithubImportPageRequestDto struct {
+	Page int `form:"page" binding:"omitempty,min=1" example:"1"`
+}
+
+type GithubRepoDto struct {
+	Owner         string    `json:"owner" example:"octocat"`
+	Name          string    `json:"name" example:"toolbake-tools"`
+	FullName      string    `json:"full_name" example:"octocat/toolbake-tools"`
+	Description   string    `json:"description" example:"My ToolBake tools"`
+	Private       bool      `json:"private" example:"false"`
+	DefaultBranch string    `json:"default_branch" example:"main"`
+	HTMLURL       string    `json:"html_url" example:"https://github.com/octocat/toolbake-tools"`
+	UpdatedAt     time.Time `json:"updated_at" example:"2026-01-02T03:04:05Z"`
+}
+
+type GithubReposResponseDto struct {
+	Repos []GithubRepoDto `json:"repos"`
+	// NextPage is the page to request next, 0 on the last page
+	NextPage int `json:"next_page" example:"2"`
+}
+
+func (dto *GithubReposResponseDto) FromEntity(page entity.GithubPageEntity[entity.GithubRepoEntity]) {
+	dto.NextPage = page.NextPage
+	dto.Repos = lo.Map(page.Items, func(repo entity.GithubRepoEntity, _ int) GithubRepoDto {
+		return GithubRepoDto{
+			Owner:         repo.Owner,
+			Name:          repo.Name,
+			FullName:      repo.FullName,
+			Description:   repo.Description,
+			Private:       repo.Private,
+			DefaultBranch: repo.DefaultBranch,
+			HTMLURL:       repo.HTMLURL,
+			UpdatedAt:     repo.UpdatedAt,
+		}
+	})
+}
+
+type GithubGistDto struct {
+	ID          string    `json:"id" example:"aa5a315d61ae9438b18d"`
+	Description string    `json:"description" example:"JSON escaper tool"`
+	Public      bool      `json:"public" example:"true"`
+	Files       []string  `json:"files" example:"handler.js,uiWidgets.json"`
+	HTMLURL     string    `json:"html_url" example:"https://gist.github.com/aa5a315d61ae9438b18d"`
+	UpdatedAt   time.Time `json:"updated_at" example:"2026-01-02T03:04:05Z"`
+}
+
+type GithubGistsResponseDto struct {
+	Gists []GithubGistDto `json:"gists"`
+	// NextPage is the page to request next, 0 on the last page
+	NextPage int `json:"next_page" example:"0"`
+}
+
+func (dto *GithubGistsResponseDto) FromEntity(page entity.GithubPageEntity[entity.GithubGistEntity]) {
+	dto.NextPage = page.NextPage
+	dto.Gists = lo.Map(page.Items, func(gist entity.GithubGistEntity, _ int) GithubGistDto {
+		return GithubGistDto{
+			ID:          gist.ID,
+			Description: gist.Description,
+			Public:      gist.Public,
+			Files:       gist.Files,
+			HTMLURL:     gist.HTMLURL,
+			UpdatedAt:   gist.UpdatedAt,
+		}
+	})
+}
+
+type GithubRepoFileDto struct {
+	Path string `json:"path" example:"tools/json-escaper/handler.js"`
+	Size int    `json:"size" example:"1024"`
+}
+
+type GithubRepoFilesResponseDto struct {
+	Files []GithubRepoFileDto `json:"files"`
+}
+
+func (dto *GithubRepoFilesResponseDto) FromEntity(files []entity.GithubFileEntity) {
+	dto.Files = lo.Map(files, func(file entity.GithubFileEntity, _ int) GithubRepoFileDto {
+		return GithubRepoFileDto{Path: file.Path, Size: file.Size}
+	})
+}
+
+type GithubImportRepoFilesRequestDto struct {
+	Paths []string `json:"paths" binding:"required,min=1,dive,min=1,max=1024" example:"tools/json-escaper/handler.js"`
+}
+
+type GithubImportGistFilesRequestDto struct {
+	Files []string `json:"files" binding:"required,min=1,dive,min=1,max=255" example:"handler.js"`
+}
+
+type GithubImportResultDto struct {
+	Path     string `json:"path" example:"tools/json-escaper/handler.js"`
+	ToolID   string `json:"tool_id" example:"github-octocat-toolbake-tools-tools-json-escaper-handler-js"`
+	Imported bool   `json:"imported" example:"true"`
+	// Reason tells why the file was skipped, empty when it was imported
+	Reason   string                 `json:"reason" example:""`
+	Warnings []ToolSecretWarningDto `json:"warnings"`
+}
+
+type GithubImportResponseDto struct {
+	Results []GithubImportResultDto `json:"results"`
+}
+
+func (dto *GithubImportResponseDto) FromEntity(results []entity.GithubImportResultEntity) {
+	dto.Results = lo.Map(results, func(result entity.GithubImportResultEntity, _ int) GithubImportResultDto {
+		return GithubImportResultDto{
+			Path:     result.Path,
+			ToolID:   result.ToolID,
+			Imported: result.Imported,
+			Reason:   result.Reason,
+			Warnings: toolSecretWarningsFromEntity(result.Warnings),
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewInvokeToolController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	toolExecutionService *service.ToolExecutionService,
+) router.Controller {
+	return InvokeToolController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		toolExecutionService:       toolExecutionService,
+	}
+}
+
+// InvokeToolController runs a tool by namespace and name, as described by the tools openapi spec of the user
+type InvokeToolController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	toolExecutionService       *service.ToolExecutionService
+}
+
+func (c InvokeToolController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/tools/:namespace/:name/invoke", Handler: c.Invoke},
+	}
+}
+
+// @Summary		Invoke a tool by namespace and name
+// @Description	Run the active tool with the namespace and name on the server, like /api/v1/tools/{tool_uid}/execute, for services calling tools
+// @Description	described by /api/tools/openapi.json. The inputs are checked against the input schema of the tool first,
+// @Description	a mismatch fails with ToolInputInvalid and lists the problems in extra_data.problems.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string					true	"Bearer access token"
+// @Param			namespace		path		string					true	"Tool namespace"
+// @Param			name			path		string					true	"Tool name"
+// @Param			request			body		ExecuteToolRequestDto	true	"Handler arguments"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ExecuteToolResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Failure		413				{object}	swagger.BaseFailResponse
+// @Failure		422				{object}	swagger.BaseFailResponse
+// @Failure		429				{object}	swagger.BaseFailResponse
+// @Router			/api/tools/{namespace}/{name}/invoke [post]
+func (c *InvokeToolController) Invoke(ctx *gin.Context) {
+	logger.Infof(ctx, "Tool invocation requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req ExecuteToolRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid tool invocation payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	namespace := ctx.Param("namespace")
+	name := ctx.Param("name")
+	result, rateLimit, err := c.toolExecutionService.InvokeTool(ctx, user.ID, namespace, name, req.ToEntity())
+	common.SetRateLimitHeaders(ctx, rateLimit)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to invoke tool %q of namespace %q for user %s: %v", name, namespace, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Tool %q of namespace %q invoked by user %s in %s", name, namespace, user.ID, result.Duration)
+	var resp ExecuteToolResponseDto
+	resp.FromEntity(result)
+	c.Success(ctx, "", resp)
+}
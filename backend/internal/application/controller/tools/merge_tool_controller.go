@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewMergeToolController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	cache repository.ICache,
+	toolService *service.ToolService,
+) router.Controller {
+	return MergeToolController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		cache:                      cache,
+		toolService:                toolService,
+	}
+}
+
+type MergeToolController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	cache                      repository.ICache
+	toolService                *service.ToolService
+}
+
+func (c MergeToolController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/tools/:tool_uid/merge", Handler: c.Merge},
+	}
+}
+
+// @Summary		Merge an offline edit of a tool
+// @Description	Three-way merge of the client's edited version of a tool with the current server version, using the version the edit started from as base.
+// @Description	Every field changed by either side is listed with its status, a field changed by one side only takes that side's value and the source is merged line by line.
+// @Description	Source ranges changed differently by both sides are returned in source_conflicts. With apply=true the merged tool is saved when nothing conflicts.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string				true	"Bearer access token"
+// @Param			tool_uid		path		string				true	"Tool identifier"
+// @Param			request			body		MergeToolRequestDto	true	"Base and edited versions of the tool"
+// @Success		200				{object}	swagger.BaseSuccessResponse[MergeToolResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/merge [post]
+func (c *MergeToolController) Merge(ctx *gin.Context) {
+	logger.Infof(ctx, "Merge Tool requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	if toolUID == "" {
+		logger.Errorf(ctx, "Invalid tool merge: tool_uid is required")
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "tool_uid is required"))
+		return
+	}
+
+	var req MergeToolRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid tool merge payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	result, err := c.toolService.MergeTool(ctx, user.ID, toolUID, req.Base.ToEntity(toolUID), req.Edited.ToEntity(toolUID))
+	if err != nil {
+		logger.Errorf(ctx, "Failed to merge tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var warnings []ToolSecretWarningDto
+	applied := false
+	if req.Apply && result.Merged != nil {
+		warnings, err = c.applyMerged(ctx, user.ID, *result.Merged)
+		if err != nil {
+			c.Error(ctx, err)
+			return
+		}
+		applied = true
+	}
+
+	logger.Infof(ctx, "Tool %s merged for user %s, %d fields changed, conflicts: %t, applied: %t", toolUID, user.ID, len(result.Fields), result.HasConflicts(), applied)
+	var resp MergeToolResponseDto
+	resp.FromEntity(result, applied, warnings)
+	c.Success(ctx, "", resp)
+}
+
+// applyMerged saves the merged tool with the same checks as a tool update.
+func (c *MergeToolController) applyMerged(ctx *gin.Context, userID entity.UserIDEntity, merged entity.ToolEntity) ([]ToolSecretWarningDto, error) {
+	findings, err := c.toolService.SaveToolUpdate(ctx, userID, merged)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := DeleteToolsCache(ctx, c.cache, userID); err != nil {
+		logger.Errorf(ctx, "Failed to delete cache for user %s: %v", userID, err)
+		return nil, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error")
+	}
+	return toolSecretWarningsFromEntity(findings), nil
+}
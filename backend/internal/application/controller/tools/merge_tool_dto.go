@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type MergeToolRequestDto struct {
+	// Base is the version of the tool the client started editing from
+	Base UpdateToolRequestDto `json:"base"`
+	// Edited is the client's version of the tool
+	Edited UpdateToolRequestDto `json:"edited"`
+	// Apply saves the merged tool when nothing conflicts
+	Apply bool `json:"apply" example:"true"`
+}
+
+type ToolMergeFieldDto struct {
+	Field  string  `json:"field" example:"name"`
+	Base   *string `json:"base" example:"Sample Tool"`
+	Local  *string `json:"local" example:"Renamed Tool"`
+	Server *string `json:"server" example:"Sample Tool"`
+	Status string  `json:"status" enums:"local,server,both,merged,conflict" example:"local"`
+}
+
+type ToolSourceConflictDto struct {
+	BaseLine    int      `json:"base_line" example:"12"`
+	LocalLine   int      `json:"local_line" example:"12"`
+	ServerLine  int      `json:"server_line" example:"14"`
+	BaseLines   []string `json:"base_lines"`
+	LocalLines  []string `json:"local_lines"`
+	ServerLines []string `json:"server_lines"`
+}
+
+type MergeToolResponseDto struct {
+	// Fields lists the fields changed by either side since the base version
+	Fields          []ToolMergeFieldDto     `json:"fields"`
+	SourceConflicts []ToolSourceConflictDto `json:"source_conflicts"`
+	HasConflicts    bool                    `json:"has_conflicts" example:"false"`
+	Server          ToolDto                 `json:"server"`
+	// Merged is the merged tool, null when a field conflicts
+	Merged  *ToolDto `json:"merged"`
+	Applied bool     `json:"applied" example:"true"`
+	// Warnings lists credentials found in the merged source when it was applied
+	Warnings []ToolSecretWarningDto `json:"warnings"`
+}
+
+func (dto *MergeToolResponseDto) FromEntity(result entity.ToolMergeEntity, applied bool, warnings []ToolSecretWarningDto) {
+	dto.Fields = lo.Map(result.Fields, func(field entity.ToolMergeFieldEntity, _ int) ToolMergeFieldDto {
+		return ToolMergeFieldDto{
+			Field:  field.Field,
+			Base:   field.Base,
+			Local:  field.Local,
+			Server: field.Server,
+			Status: string(field.Status),
+		}
+	})
+	dto.SourceConflicts = lo.Map(result.SourceConflicts, func(conflict entity.ToolSourceConflictEntity, _ int) ToolSourceConflictDto {
+		return ToolSourceConflictDto{
+			BaseLine:    conflict.BaseLine,
+			LocalLine:   conflict.LocalLine,
+			ServerLine:  conflict.ServerLine,
+			BaseLines:   conflict.BaseLines,
+			LocalLines:  conflict.LocalLines,
+			ServerLines: conflict.ServerLines,
+		}
+	})
+	dto.HasConflicts = result.HasConflicts()
+	dto.Server.FromEntity(result.Server)
+	if result.Merged != nil {
+		dto.Merged = &ToolDto{}
+		dto.Merged.FromEntity(*result.Merged)
+	}
+	dto.Applied = applied
+	dto.Warnings = lo.Ternary(warnings == nil, []ToolSecretWarningDto{}, warnings)
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewPublishToolController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	galleryService *service.GalleryService,
+) router.Controller {
+	return PublishToolController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		galleryService:             galleryService,
+	}
+}
+
+type PublishToolController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	galleryService             *service.GalleryService
+}
+
+func (c PublishToolController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPut, Path: "/api/v1/tools/:tool_uid/publish", Handler: c.Publish},
+	}
+}
+
+// @Summary		Publish or unpublish tool
+// @Description	Publish a tool to the public gallery, where anyone can find it and other users can install or fork it, or take it out of the gallery.
+// @Description	The gallery shows the current state of the tool. Archived tools can not be published and are hidden from the gallery.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string					true	"Bearer access token"
+// @Param			tool_uid		path		string					true	"Tool unique identifier (UID)"
+// @Param			request			body		PublishToolRequestDto	true	"Published state"
+// @Success		200				{object}	swagger.BaseSuccessResponse[PublishToolResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/publish [put]
+func (c *PublishToolController) Publish(ctx *gin.Context) {
+	logger.Infof(ctx, "Publish Tool requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req PublishToolRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid tool publish payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	if err := c.galleryService.SetPublished(ctx, user.ID, toolUID, *req.Published); err != nil {
+		logger.Errorf(ctx, "Failed to set published of tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Tool %s published set to %t for user %s", toolUID, *req.Published, user.ID)
+	c.Success(ctx, "Tool published state updated successfully", PublishToolResponseDto{})
+}
@@ -0,0 +1,7 @@
+package tools
+
+type PublishToolRequestDto struct {
+	Published *bool `json:"published" binding:"required" example:"true"`
+}
+
+type PublishToolResponseDto struct{}
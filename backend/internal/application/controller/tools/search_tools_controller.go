@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewSearchToolsController(
+	toolService *service.ToolService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return SearchToolsController{
+		toolService:                toolService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type SearchToolsController struct {
+	common.JsonResponse
+
+	toolService                *service.ToolService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c SearchToolsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/search", Handler: c.SearchTools, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/api/v1/tools/search/fulltext", Handler: c.FullTextSearchTools, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Search tools
+// @Description	Case-insensitive search in the id, name, namespace, category and description of the tools of the authenticated user.
+// @Description	With include_source=true the tool source is searched too and the matched lines are returned with their line number and highlight ranges.
+// @Description	Highlight ranges are character offsets into the snippet, long lines are cut to a window around the first match.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			q				query		string	true	"Search text"
+// @Param			include_source	query		bool	false	"Also search the tool source"
+// @Success		200				{object}	swagger.BaseSuccessResponse[SearchToolsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/search [get]
+func (c *SearchToolsController) SearchTools(ctx *gin.Context) {
+	logger.Infof(ctx, "Search tools requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req SearchToolsRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	results, err := c.toolService.SearchTools(ctx, user.ID, req.Query, req.IncludeSource)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to search tools for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected search tools error"))
+		return
+	}
+
+	var resp SearchToolsResponseDto
+	resp.FromEntity(results)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Full text search tools
+// @Description	Search the name, description and source of the tools of the authenticated user with the full text index of the database.
+// @Description	The query is split into words of letters and digits, a tool matches when it has a word starting with each of them.
+// @Description	The most relevant tools come first, matches in the name weigh more than in the description, and those more than in the source.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			q				query		string	true	"Search words"
+// @Param			limit			query		int		false	"Max tools returned, 20 by default"
+// @Success		200				{object}	swagger.BaseSuccessResponse[FilterToolsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/search/fulltext [get]
+func (c *SearchToolsController) FullTextSearchTools(ctx *gin.Context) {
+	logger.Infof(ctx, "Full text search tools requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	req := FullTextSearchToolsRequestDto{Limit: fullTextSearchDefaultLimit}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	tools, err := c.toolService.FullTextSearchTools(ctx, user.ID, req.Query, req.Limit)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to full text search tools for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected search tools error"))
+		return
+	}
+
+	var resp FilterToolsResponseDto
+	resp.FromEntity(tools)
+	c.Success(ctx, "", resp)
+}
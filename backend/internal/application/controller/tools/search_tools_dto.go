@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type SearchToolsRequestDto struct {
+	Query         string `form:"q" binding:"required,max=255" example:"fetch("`
+	IncludeSource bool   `form:"include_source" example:"true"`
+}
+
+// fullTextSearchDefaultLimit is the number of tools a full text search returns without a limit
+const fullTextSearchDefaultLimit = 20
+
+type FullTextSearchToolsRequestDto struct {
+	Query string `form:"q" binding:"required,max=255" example:"json format"`
+	Limit int    `form:"limit" binding:"min=1,max=100" example:"20"`
+}
+
+type TextRangeDto struct {
+	Start int `json:"start" example:"4"`
+	End   int `json:"end" example:"10"`
+}
+
+type ToolSourceMatchDto struct {
+	Line       int            `json:"line" example:"12"`
+	Snippet    string         `json:"snippet" example:"  return fetch(url)"`
+	Highlights []TextRangeDto `json:"highlights"`
+}
+
+type ToolSearchResultDto struct {
+	Tool          ToolDto              `json:"tool"`
+	MatchedFields []string             `json:"matched_fields" example:"name,source"`
+	SourceMatches []ToolSourceMatchDto `json:"source_matches"`
+}
+
+type SearchToolsResponseDto struct {
+	Results []ToolSearchResultDto `json:"results"`
+}
+
+func (dto *SearchToolsResponseDto) FromEntity(results []entity.ToolSearchResultEntity) {
+	dto.Results = lo.Map(results, func(result entity.ToolSearchResultEntity, _ int) ToolSearchResultDto {
+		item := ToolSearchResultDto{
+			MatchedFields: result.MatchedFields,
+			SourceMatches: lo.Map(result.SourceMatches, func(match entity.ToolSourceMatchEntity, _ int) ToolSourceMatchDto {
+				return ToolSourceMatchDto{
+					Line:    match.Line,
+					Snippet: match.Snippet,
+					Highlights: lo.Map(match.Highlights, func(h entity.TextRangeEntity, _ int) TextRangeDto {
+						return TextRangeDto{Start: h.Start, End: h.End}
+					}),
+				}
+			}),
+		}
+		item.Tool.FromEntity(result.Tool)
+		return item
+	})
+}
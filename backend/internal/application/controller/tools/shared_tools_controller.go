@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewSharedToolsController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	cache repository.ICache,
+	toolShareService *service.ToolShareService,
+) router.Controller {
+	return SharedToolsController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		cache:                      cache,
+		toolShareService:           toolShareService,
+	}
+}
+
+// SharedToolsController serves the tools other users shared with the user or by link
+type SharedToolsController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	cache                      repository.ICache
+	toolShareService           *service.ToolShareService
+}
+
+func (c SharedToolsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/shared", Handler: c.List, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/api/v1/tools/shared/:share_id", Handler: c.Get, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/tools/shared/:share_id/fork", Handler: c.Fork},
+	}
+}
+
+// @Summary		List the tools shared with me
+// @Description	Lists the tools other users shared with the authenticated user, newest share first. Tools shared by link are not listed.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ListSharedToolsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/shared [get]
+func (c *SharedToolsController) List(ctx *gin.Context) {
+	logger.Infof(ctx, "List Shared Tools requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	sharedTools, err := c.toolShareService.SharedWithUser(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list tools shared with user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected fetch shared tools error"))
+		return
+	}
+
+	var resp ListSharedToolsResponseDto
+	resp.FromEntity(sharedTools)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Get a shared tool
+// @Description	Returns the current state of a shared tool. A public link can be opened without logging in, a share with a user only by that user.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	false	"Bearer access token"
+// @Param			share_id		path		string	true	"Share id"
+// @Success		200				{object}	swagger.BaseSuccessResponse[GetSharedToolResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/shared/{share_id} [get]
+func (c *SharedToolsController) Get(ctx *gin.Context) {
+	logger.Infof(ctx, "Get Shared Tool requested")
+
+	user, _, err := c.accessTokenHeaderValidator.ValidateOptionalAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	shareID := ctx.Param("share_id")
+	sharedTool, err := c.toolShareService.GetSharedTool(ctx, user.ID, shareID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get shared tool %s: %v", shareID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp GetSharedToolResponseDto
+	resp.SharedTool.FromEntity(sharedTool)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Fork a shared tool
+// @Description	Copies a tool shared with fork permission into the tools of the authenticated user. The copy gets a new uid and
+// @Description	a -fork suffix on its tool id when the user already has the id, later changes of the owner do not reach it.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			share_id		path		string	true	"Share id"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ForkSharedToolResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/shared/{share_id}/fork [post]
+func (c *SharedToolsController) Fork(ctx *gin.Context) {
+	logger.Infof(ctx, "Fork Shared Tool requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	shareID := ctx.Param("share_id")
+	tool, findings, err := c.toolShareService.ForkTool(ctx, user.ID, shareID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to fork shared tool %s for user %s: %v", shareID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	if err := DeleteToolsCache(ctx, c.cache, user.ID); err != nil {
+		logger.Errorf(ctx, "Failed to delete cache for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error"))
+		return
+	}
+
+	logger.Infof(ctx, "Shared tool %s forked for user %s with tool id %s", shareID, user.ID, tool.ID)
+	var resp ForkSharedToolResponseDto
+	resp.Tool.FromEntity(tool)
+	resp.Warnings = toolSecretWarningsFromEntity(findings)
+	c.Success(ctx, "Tool forked successfully", resp)
+}
@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"io"
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewSyncToolsController(
+	syncService *service.SyncService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return SyncToolsController{
+		syncService:                syncService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type SyncToolsController struct {
+	common.JsonResponse
+
+	syncService                *service.SyncService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c SyncToolsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/sync", Handler: c.Sync, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/tools/sync/ack", Handler: c.Ack, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/api/v1/tools/sync/since", Handler: c.Since, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Sync tools of a device
+// @Description	Return the tools changed and the tool uids deleted since the cursor the device last acknowledged.
+// @Description	The device id is issued by the login APIs. A new device receives every tool.
+// @Description	The response is streamed a tool at a time, a body cut off by a server error fails to parse and the sync is simply retried.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			device_id		query		string	true	"Device id from login"
+// @Success		200				{object}	swagger.BaseSuccessResponse[SyncToolsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/sync [get]
+func (c *SyncToolsController) Sync(ctx *gin.Context) {
+	logger.Infof(ctx, "Sync tools requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req SyncToolsRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	c.SuccessWithStreamedData(ctx, "", func(w io.Writer) error {
+		resp := newSyncToolsResponseWriter(w)
+		deleted, err := c.syncService.StreamToolChanges(ctx, user.ID, req.DeviceID, resp.Begin, resp.WriteTool)
+		if err != nil {
+			logger.Errorf(ctx, "Failed to get tool changes of device %s: %v", req.DeviceID, err)
+			return err
+		}
+		return resp.End(deleted)
+	})
+}
+
+// @Summary		Acknowledge synced tools
+// @Description	Store the cursor of a sync response after the device applied it, the next sync only returns later changes
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string					true	"Bearer access token"
+// @Param			request			body		AckToolsSyncRequestDto	true	"Device and cursor"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AckToolsSyncResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/sync/ack [post]
+func (c *SyncToolsController) Ack(ctx *gin.Context) {
+	logger.Infof(ctx, "Acknowledge tools sync requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req AckToolsSyncRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	if err := c.syncService.AcknowledgeToolChanges(ctx, user.ID, req.DeviceID, *req.Cursor); err != nil {
+		logger.Errorf(ctx, "Failed to acknowledge tool changes of device %s: %v", req.DeviceID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "", AckToolsSyncResponseDto{})
+}
+
+// @Summary		Tools changed since a time
+// @Description	Return the tools created or updated and the tool uids deleted at or after a time, without a device.
+// @Description	Pass the until of the response as since of the next request, the changes made exactly at that time are returned again.
+// @Description	Without since every tool is returned. The device sync of /api/v1/tools/sync is exact, this one goes by the server clock.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			since			query		string	false	"RFC 3339 time, the until of the previous response"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ToolsUpdatedSinceResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/sync/since [get]
+func (c *SyncToolsController) Since(ctx *gin.Context) {
+	logger.Infof(ctx, "Tools updated since requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req ToolsUpdatedSinceRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	changes, err := c.syncService.ToolsUpdatedSince(ctx, user.ID, req.Since)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get tools updated since %s: %v", req.Since, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp ToolsUpdatedSinceResponseDto
+	resp.FromEntity(changes)
+	c.Success(ctx, "", resp)
+}
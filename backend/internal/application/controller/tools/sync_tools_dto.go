@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type SyncToolsRequestDto struct {
+	DeviceID string `form:"device_id" binding:"required" example:"d-xxxx"`
+}
+
+type SyncToolsResponseDto struct {
+	// Cursor is acknowledged through /api/v1/tools/sync/ack once the changes are applied
+	Cursor          int64     `json:"cursor" example:"42"`
+	Tools           []ToolDto `json:"tools"`
+	DeletedToolUIDs []string  `json:"deleted_tool_uids" example:"tool_uid_a"`
+}
+
+// syncToolsResponseWriter writes a SyncToolsResponseDto a tool at a time, so the changed tools of a sync are never
+// all held in memory.
+type syncToolsResponseWriter struct {
+	w     io.Writer
+	tools int
+}
+
+func newSyncToolsResponseWriter(w io.Writer) *syncToolsResponseWriter {
+	return &syncToolsResponseWriter{w: w}
+}
+
+func (s *syncToolsResponseWriter) Begin(cursor int64) error {
+	_, err := fmt.Fprintf(s.w, `{"cursor":%d,"tools":[`, cursor)
+	return err
+}
+
+func (s *syncToolsResponseWriter) WriteTool(tool entity.ToolEntity) error {
+	var item ToolDto
+	item.FromEntity(tool)
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if s.tools > 0 {
+		encoded = append([]byte(","), encoded...)
+	}
+	s.tools++
+	_, err = s.w.Write(encoded)
+	return err
+}
+
+func (s *syncToolsResponseWriter) End(deletedToolUIDs []string) error {
+	encoded, err := json.Marshal(deletedToolUIDs)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, `],"deleted_tool_uids":%s}`, encoded)
+	return err
+}
+
+type AckToolsSyncRequestDto struct {
+	DeviceID string `json:"device_id" binding:"required" example:"d-xxxx"`
+	Cursor   *int64 `json:"cursor" binding:"required" example:"42"`
+}
+
+type AckToolsSyncResponseDto struct {
+}
+
+type ToolsUpdatedSinceRequestDto struct {
+	// Since is the until of the previous response, every tool is returned without it
+	Since time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00" example:"2026-01-02T15:04:05.123456Z"`
+}
+
+type ToolsUpdatedSinceResponseDto struct {
+	// Until is passed as since of the next request
+	Until           string   `json:"until" example:"2026-01-02T15:04:05.123456Z"`
+	CreatedToolUIDs []string `json:"created_tool_uids" example:"tool_uid_a"`
+	UpdatedToolUIDs []string `json:"updated_tool_uids" example:"tool_uid_b"`
+	DeletedToolUIDs []string `json:"deleted_tool_uids" example:"tool_uid_c"`
+	// Tools are the created and updated tools
+	Tools []ToolDto `json:"tools"`
+}
+
+func (d *ToolsUpdatedSinceResponseDto) FromEntity(changes entity.ToolsUpdatedSinceEntity) {
+	toolUID := func(tool entity.ToolEntity, _ int) string { return tool.UniqueID }
+	d.Until = changes.Until.Format(time.RFC3339Nano)
+	d.CreatedToolUIDs = lo.Map(changes.CreatedTools, toolUID)
+	d.UpdatedToolUIDs = lo.Map(changes.UpdatedTools, toolUID)
+	d.DeletedToolUIDs = changes.DeletedToolUIDs
+	d.Tools = make([]ToolDto, 0, len(changes.CreatedTools)+len(changes.UpdatedTools))
+	for _, tool := range slices.Concat(changes.CreatedTools, changes.UpdatedTools) {
+		var item ToolDto
+		item.FromEntity(tool)
+		d.Tools = append(d.Tools, item)
+	}
+}
@@ -0,0 +1,230 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+func NewToolBundleController(
+	config config.Config,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	cache repository.ICache,
+	toolService *service.ToolService,
+) router.Controller {
+	return ToolBundleController{
+		config:                     config,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		cache:                      cache,
+		toolService:                toolService,
+	}
+}
+
+// ToolBundleController exports all tools of a user as one bundle and imports a bundle, to move the tools to another instance
+type ToolBundleController struct {
+	common.JsonResponse
+
+	config                     config.Config
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	cache                      repository.ICache
+	toolService                *service.ToolService
+}
+
+func (c ToolBundleController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/export", Handler: c.Export, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/tools/import", Handler: c.Import},
+		{Method: http.MethodPost, Path: "/api/v1/tools/import/zip", Handler: c.ImportZip},
+	}
+}
+
+// @Summary		Export all tools
+// @Description	Exports all tools of the authenticated user, archived ones included, and the global script as one bundle.
+// @Description	format=zip downloads a zip holding the bundle as toolbake-tools.json instead of the json response.
+// @Tags			Tools
+// @Produce		json
+// @Produce		application/zip
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			format			query		string	false	"json (default) or zip"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ToolBundleDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/export [get]
+func (c *ToolBundleController) Export(ctx *gin.Context) {
+	logger.Infof(ctx, "Export Tools requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req ExportToolsRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		logger.Errorf(ctx, "Invalid export tools query: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	bundle, err := c.toolService.ExportTools(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to export tools for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected export tools error"))
+		return
+	}
+
+	var resp ToolBundleDto
+	resp.FromEntity(bundle)
+	if req.Format != "zip" {
+		c.Success(ctx, "", resp)
+		return
+	}
+
+	content, err := toolBundleZip(resp)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to zip tools bundle for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected export tools error"))
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="toolbake-tools-%s.zip"`, bundle.ExportedAt.Format("20060102T150405Z")))
+	ctx.Data(http.StatusOK, "application/zip", content)
+}
+
+// @Summary		Import a tool bundle
+// @Description	Imports the tools and the global script of a bundle exported by GET /api/v1/tools/export. A tool with the namespace and name
+// @Description	of an existing tool is skipped (default), overwrites the existing tool or is imported under a numbered name, as conflict tells.
+// @Description	Every tool passes the secret scan, quota and storage checks of a created tool, a tool that fails them is skipped and reported.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string					true	"Bearer access token"
+// @Param			request			body		ImportToolsRequestDto	true	"Bundle to import"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ImportToolsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		413				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/import [post]
+func (c *ToolBundleController) Import(ctx *gin.Context) {
+	logger.Infof(ctx, "Import Tools requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, c.maxBundleSize())
+	var req ImportToolsRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid import tools payload: %v", err)
+		c.Error(ctx, c.invalidBundleError(err))
+		return
+	}
+
+	c.importBundle(ctx, user.ID, req.Bundle, entity.ToolImportConflict(req.Conflict))
+}
+
+// @Summary		Import a zip tool bundle
+// @Description	Imports a zip bundle downloaded from GET /api/v1/tools/export?format=zip, the request body is the zip file.
+// @Description	Works like POST /api/v1/tools/import.
+// @Tags			Tools
+// @Accept			application/zip
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			conflict		query		string	false	"skip (default), overwrite or rename"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ImportToolsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		413				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/import/zip [post]
+func (c *ToolBundleController) ImportZip(ctx *gin.Context) {
+	logger.Infof(ctx, "Import Tools Zip requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req ImportToolsZipRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		logger.Errorf(ctx, "Invalid import tools query: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	content, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, c.maxBundleSize()))
+	if err != nil {
+		logger.Errorf(ctx, "Failed to read zip tool bundle: %v", err)
+		c.Error(ctx, c.invalidBundleError(err))
+		return
+	}
+	data, found, err := readToolBundleZip(content, c.maxBundleSize())
+	if err != nil || !found {
+		logger.Errorf(ctx, "Invalid zip tool bundle, found %s: %t, err: %v", toolBundleZipEntry, found, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InvalidToolBundle, "the zip must hold %s of at most %d bytes", toolBundleZipEntry, c.maxBundleSize()))
+		return
+	}
+
+	var bundle ToolBundleDto
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		logger.Errorf(ctx, "Invalid tool bundle json: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidToolBundle, err.Error()))
+		return
+	}
+	if err := binding.Validator.ValidateStruct(bundle); err != nil {
+		logger.Errorf(ctx, "Invalid tool bundle: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidToolBundle, err.Error()))
+		return
+	}
+
+	c.importBundle(ctx, user.ID, bundle, entity.ToolImportConflict(req.Conflict))
+}
+
+func (c *ToolBundleController) importBundle(ctx *gin.Context, userID entity.UserIDEntity, bundle ToolBundleDto, conflict entity.ToolImportConflict) {
+	result, err := c.toolService.ImportTools(ctx, userID, bundle.ToEntity(), conflict)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to import tool bundle for user %s: %v", userID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	// the global script is not part of the cached tool lists
+	if result.HasSavedTools() {
+		if err := DeleteToolsCache(ctx, c.cache, userID); err != nil {
+			logger.Errorf(ctx, "Failed to delete cache for user %s: %v", userID, err)
+			c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error"))
+			return
+		}
+	}
+
+	var resp ImportToolsResponseDto
+	resp.FromEntity(result)
+	c.Success(ctx, "Tools imported", resp)
+}
+
+// maxBundleSize caps an uploaded bundle at what the import limits allow, every source and ui widgets at the max file size
+func (c *ToolBundleController) maxBundleSize() int64 {
+	return int64(c.config.ImportMaxFileSize) * int64(c.config.ImportMaxFiles) * 2
+}
+
+// invalidBundleError tells a too large upload from a malformed one
+func (c *ToolBundleController) invalidBundleError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return error_code.NewErrorWithErrorCodef(error_code.ImportFileTooLarge, "a tool bundle can have at most %d bytes", maxBytesErr.Limit)
+	}
+	return error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error())
+}
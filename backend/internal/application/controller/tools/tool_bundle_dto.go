@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+// toolBundleZipEntry is the file in a zip bundle that holds the bundle json
+const toolBundleZipEntry = "toolbake-tools.json"
+
+type ExportToolsRequestDto struct {
+	Format string `form:"format" binding:"omitempty,oneof=json zip" enums:"json,zip" example:"json"`
+}
+
+// ToolBundleDto is the portable bundle of all tools, the global script and the settings of a user. A zip bundle
+// holds it as toolbake-tools.json.
+type ToolBundleDto struct {
+	FormatVersion int       `json:"format_version" binding:"required" example:"1"`
+	ExportedAt    time.Time `json:"exported_at" example:"2024-01-01T00:00:00Z"`
+	// GlobalScript is null when the user has no global script
+	GlobalScript *string             `json:"global_script" extensions:"x-nullable" example:"// global script"`
+	Tools        []ToolBundleToolDto `json:"tools" binding:"required,dive"`
+	// Settings are the settings the user has set by key, missing in bundles exported before settings were stored
+	Settings map[string]string `json:"settings,omitempty" example:"theme:dark"`
+}
+
+type ToolBundleToolDto struct {
+	ToolID            string            `json:"tool_id" binding:"required,max=128" example:"tool-123"`
+	Name              string            `json:"name" binding:"required,max=255" example:"Sample Tool"`
+	Namespace         string            `json:"namespace" binding:"required,max=255" example:"default"`
+	Category          string            `json:"category" binding:"max=255" example:"analytics"`
+	IsActivate        bool              `json:"is_activate" example:"true"`
+	IsArchived        bool              `json:"is_archived" example:"false"`
+	RealtimeExecution bool              `json:"realtime_execution" example:"false"`
+	UiWidgets         string            `json:"ui_widgets" example:"[]"`
+	Source            string            `json:"source" example:"// source code"`
+	Description       string            `json:"description" example:"Simple tool"`
+	ExtraInfo         map[string]string `json:"extra_info" example:"{\"key\":\"value\"}"`
+}
+
+func (dto *ToolBundleDto) FromEntity(bundle entity.ToolBundleEntity) {
+	dto.FormatVersion = bundle.FormatVersion
+	dto.ExportedAt = bundle.ExportedAt
+	dto.GlobalScript = bundle.GlobalScript
+	dto.Settings = bundle.Settings
+	dto.Tools = lo.Map(bundle.Tools, func(tool entity.ToolEntity, _ int) ToolBundleToolDto {
+		return ToolBundleToolDto{
+			ToolID:            tool.ID,
+			Name:              tool.Name,
+			Namespace:         tool.Namespace,
+			Category:          tool.Category,
+			IsActivate:        tool.IsActivate,
+			IsArchived:        tool.IsArchived,
+			RealtimeExecution: tool.RealtimeExecution,
+			UiWidgets:         tool.UiWidgets,
+			Source:            tool.Source,
+			Description:       tool.Description,
+			ExtraInfo:         copyExtraInfoMap(tool.ExtraInfo),
+		}
+	})
+}
+
+func (dto ToolBundleDto) ToEntity() entity.ToolBundleEntity {
+	return entity.ToolBundleEntity{
+		FormatVersion: dto.FormatVersion,
+		ExportedAt:    dto.ExportedAt,
+		GlobalScript:  dto.GlobalScript,
+		Settings:      dto.Settings,
+		Tools: lo.Map(dto.Tools, func(tool ToolBundleToolDto, _ int) entity.ToolEntity {
+			toolEntity := entity.NewToolEntityWithUID(
+				"",
+				tool.ToolID,
+				tool.Name,
+				tool.Namespace,
+				tool.Category,
+				tool.IsActivate,
+				tool.RealtimeExecution,
+				tool.UiWidgets,
+				tool.Source,
+				tool.Description,
+				tool.ExtraInfo,
+				dto.ExportedAt,
+				dto.ExportedAt,
+			)
+			toolEntity.IsArchived = tool.IsArchived
+			return toolEntity
+		}),
+	}
+}
+
+// toolBundleZip packs the bundle json into a zip
+func toolBundleZip(bundle ToolBundleDto) ([]byte, error) {
+	content, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	file, err := writer.CreateHeader(&zip.FileHeader{Name: toolBundleZipEntry, Method: zip.Deflate, Modified: bundle.ExportedAt})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(content); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readToolBundleZip reads the bundle json of a zip, maxSize caps the uncompressed json.
+// The returned bool is false when the zip has no bundle json or it is larger than maxSize.
+func readToolBundleZip(content []byte, maxSize int64) ([]byte, bool, error) {
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, false, err
+	}
+	file, ok := lo.Find(reader.File, func(file *zip.File) bool { return file.Name == toolBundleZipEntry })
+	if !ok {
+		return nil, false, nil
+	}
+	opened, err := file.Open()
+	if err != nil {
+		return nil, false, err
+	}
+	defer opened.Close()
+
+	data, err := io.ReadAll(io.LimitReader(opened, maxSize+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, false, nil
+	}
+	return data, true, nil
+}
+
+type ImportToolsRequestDto struct {
+	Bundle   ToolBundleDto `json:"bundle" binding:"required"`
+	Conflict string        `json:"conflict" binding:"omitempty,oneof=skip overwrite rename" enums:"skip,overwrite,rename" example:"skip"`
+}
+
+type ImportToolsZipRequestDto struct {
+	Conflict string `form:"conflict" binding:"omitempty,oneof=skip overwrite rename" enums:"skip,overwrite,rename" example:"skip"`
+}
+
+type ToolImportResultDto struct {
+	Namespace string `json:"namespace" example:"default"`
+	Name      string `json:"name" example:"Sample Tool"`
+	ToolID    string `json:"tool_id" example:"tool-123"`
+	// UID is the uid of the saved tool, empty when it was skipped
+	UID    string `json:"uid" example:"tool-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	Action string `json:"action" enums:"created,overwritten,skipped" example:"created"`
+	// Reason tells why the tool was skipped, empty otherwise
+	Reason   string                 `json:"reason" example:""`
+	Warnings []ToolSecretWarningDto `json:"warnings"`
+}
+
+type ImportToolsResponseDto struct {
+	Tools                []ToolImportResultDto `json:"tools"`
+	GlobalScriptImported bool                  `json:"global_script_imported" example:"true"`
+	// SettingsImported counts the settings of the bundle that were stored, the user's own settings are only replaced
+	// when overwriting
+	SettingsImported int `json:"settings_imported" example:"2"`
+}
+
+func (dto *ImportToolsResponseDto) FromEntity(result entity.ToolBundleImportEntity) {
+	dto.GlobalScriptImported = result.GlobalScriptImported
+	dto.SettingsImported = result.SettingsImported
+	dto.Tools = lo.Map(result.Tools, func(tool entity.ToolImportResultEntity, _ int) ToolImportResultDto {
+		return ToolImportResultDto{
+			Namespace: tool.Namespace,
+			Name:      tool.Name,
+			ToolID:    tool.ToolID,
+			UID:       tool.ToolUniqueID,
+			Action:    string(tool.Action),
+			Reason:    tool.Reason,
+			Warnings:  toolSecretWarningsFromEntity(tool.Warnings),
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewToolCategoriesController(
+	toolRepository repository.IToolRepository,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
+	return ToolCategoriesController{
+		toolRepository:             toolRepository,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type ToolCategoriesController struct {
+	common.JsonResponse
+
+	toolRepository             repository.IToolRepository
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c ToolCategoriesController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/categories", Handler: c.AllCategories, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		List tool categories
+// @Description	List the categories used by the tools of the authenticated user with their tool counts
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AllToolCategoriesResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/categories [get]
+func (c *ToolCategoriesController) AllCategories(ctx *gin.Context) {
+	logger.Infof(ctx, "List tool categories requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	categories, err := c.toolRepository.AllCategories(user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get tool categories for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected fetch tool categories error"))
+		return
+	}
+
+	var resp AllToolCategoriesResponseDto
+	resp.FromEntity(categories)
+	c.Success(ctx, "", resp)
+}
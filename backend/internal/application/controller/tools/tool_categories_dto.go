@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type ToolCategoryDto struct {
+	Name      string `json:"name" example:"analytics"`
+	ToolCount int    `json:"tool_count" example:"3"`
+}
+
+type AllToolCategoriesResponseDto struct {
+	Categories []ToolCategoryDto `json:"categories"`
+}
+
+func (dto *AllToolCategoriesResponseDto) FromEntity(categories []entity.ToolCategoryEntity) {
+	dto.Categories = lo.Map(categories, func(category entity.ToolCategoryEntity, _ int) ToolCategoryDto {
+		return ToolCategoryDto{Name: category.Name, ToolCount: category.ToolCount}
+	})
+}
+
+type RenameToolCategoryRequestDto struct {
+	From string `json:"from" binding:"max=255" example:"analytics"`
+	To   string `json:"to" binding:"max=255,nefield=From" example:"data"`
+}
+
+type MergeToolCategoriesRequestDto struct {
+	Source string `json:"source" binding:"max=255" example:"charts"`
+	Target string `json:"target" binding:"max=255,nefield=Source" example:"analytics"`
+}
+
+type UpdateToolCategoryResponseDto struct {
+	UpdatedToolCount int64 `json:"updated_tool_count" example:"3"`
+}
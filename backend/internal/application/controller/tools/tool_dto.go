@@ -3,22 +3,27 @@ package tools
 import (
 	"time"
 	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
 )
 
 type ToolDto struct {
-	UID               string            `json:"uid" example:"tool-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
-	ToolID            string            `json:"tool_id" example:"tool-123"`
-	Name              string            `json:"name" example:"Sample Tool"`
-	Namespace         string            `json:"namespace" example:"default"`
-	Category          string            `json:"category" example:"analytics"`
-	IsActivate        bool              `json:"is_activate" example:"true"`
-	RealtimeExecution bool              `json:"realtime_execution" example:"false"`
-	UiWidgets         string            `json:"ui_widgets" example:"[]"`
-	Source            string            `json:"source" example:"// source code"`
-	Description       string            `json:"description" example:"Simple tool"`
-	ExtraInfo         map[string]string `json:"extra_info" example:"{\"key\":\"value\"}"`
-	CreatedAt         time.Time         `json:"created_at" example:"2024-01-01T00:00:00Z"`
-	UpdatedAt         time.Time         `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+	UID               string `json:"uid" example:"tool-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	ToolID            string `json:"tool_id" example:"tool-123"`
+	Name              string `json:"name" example:"Sample Tool"`
+	Namespace         string `json:"namespace" example:"default"`
+	Category          string `json:"category" example:"analytics"`
+	IsActivate        bool   `json:"is_activate" example:"true"`
+	IsArchived        bool   `json:"is_archived" example:"false"`
+	RealtimeExecution bool   `json:"realtime_execution" example:"false"`
+	UiWidgets         string `json:"ui_widgets" example:"[]"`
+	Source            string `json:"source" example:"// source code"`
+	// SourceHash is the hex sha256 of the source, tools with the same source have the same hash
+	SourceHash  string            `json:"source_hash" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Description string            `json:"description" example:"Simple tool"`
+	ExtraInfo   map[string]string `json:"extra_info" example:"{\"key\":\"value\"}"`
+	CreatedAt   time.Time         `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   time.Time         `json:"updated_at" example:"2024-01-01T00:00:00Z"`
 }
 
 func (t *ToolDto) FromEntity(tool entity.ToolEntity) {
@@ -28,9 +33,11 @@ func (t *ToolDto) FromEntity(tool entity.ToolEntity) {
 	t.Namespace = tool.Namespace
 	t.Category = tool.Category
 	t.IsActivate = tool.IsActivate
+	t.IsArchived = tool.IsArchived
 	t.RealtimeExecution = tool.RealtimeExecution
 	t.UiWidgets = tool.UiWidgets
 	t.Source = tool.Source
+	t.SourceHash = tool.SourceHash
 	t.Description = tool.Description
 	t.ExtraInfo = copyExtraInfoMap(tool.ExtraInfo)
 	t.CreatedAt = tool.CreatedAt
@@ -48,3 +55,16 @@ func copyExtraInfoMap(info map[string]string) map[string]string {
 	}
 	return result
 }
+
+// ToolSecretWarningDto reports a credential found in the saved tool source, the secret itself is masked.
+type ToolSecretWarningDto struct {
+	Rule   string `json:"rule" enums:"aws_access_key_id,aws_secret_access_key,github_token,github_fine_grained_token,private_key" example:"github_token"`
+	Line   int    `json:"line" example:"12"`
+	Masked string `json:"masked" example:"ghp_************************************"`
+}
+
+func toolSecretWarningsFromEntity(findings []entity.ToolSecretFindingEntity) []ToolSecretWarningDto {
+	return lo.Map(findings, func(finding entity.ToolSecretFindingEntity, _ int) ToolSecretWarningDto {
+		return ToolSecretWarningDto{Rule: finding.Rule, Line: finding.Line, Masked: finding.Masked}
+	})
+}
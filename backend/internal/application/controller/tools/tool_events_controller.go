@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewToolEventsController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	toolEventService *service.ToolEventService,
+) router.Controller {
+	return ToolEventsController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		toolEventService:           toolEventService,
+	}
+}
+
+type ToolEventsController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	toolEventService           *service.ToolEventService
+}
+
+func (c ToolEventsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/events", Handler: c.List, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		List the tool events of the user
+// @Description	Lists the recorded creations, updates, deletions, archivals and restorations of the tools of the user newest first.
+// @Description	Every event names who made the change, from which device (the X-Device-ID header of the request) and which fields changed.
+// @Description	The next page is read by passing the id of the last event of a page as before.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		query		string	false	"Only the events of this tool"
+// @Param			before			query		int		false	"Only the events with an id lower than this one"
+// @Param			limit			query		int		false	"Page size"	default(50)	maximum(200)
+// @Success		200				{object}	swagger.BaseSuccessResponse[ListToolEventsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/events [get]
+func (c *ToolEventsController) List(ctx *gin.Context) {
+	logger.Infof(ctx, "List Tool Events requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req ListToolEventsRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		logger.Errorf(ctx, "Invalid tool events query: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	events, err := c.toolEventService.ListEvents(ctx, user.ID, req.ToolUID, req.Before, req.Limit)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list tool events for user %s: %v", user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp ListToolEventsResponseDto
+	resp.FromEntity(events)
+	c.Success(ctx, "", resp)
+}
@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type ListToolEventsRequestDto struct {
+	ToolUID string `form:"tool_uid" binding:"omitempty,max=255" example:"tool-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	Before  int64  `form:"before" binding:"omitempty,min=0" example:"0"`
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=200" example:"50"`
+}
+
+type ToolEventDto struct {
+	ID            int64     `json:"id" example:"42"`
+	ToolUID       string    `json:"tool_uid" example:"tool-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	Type          string    `json:"type" enums:"created,updated,deleted,archived,restored" example:"updated"`
+	ActorID       string    `json:"actor_id" example:"user-xxxx"`
+	DeviceID      string    `json:"device_id" example:"laptop-firefox"`
+	RequestID     string    `json:"request_id" example:"xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	ChangedFields []string  `json:"changed_fields" example:"name,source"`
+	CreatedAt     time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+type ListToolEventsResponseDto struct {
+	Events []ToolEventDto `json:"events"`
+}
+
+func (dto *ListToolEventsResponseDto) FromEntity(events []entity.ToolEventEntity) {
+	dto.Events = lo.Map(events, func(event entity.ToolEventEntity, _ int) ToolEventDto {
+		return ToolEventDto{
+			ID:            event.ID,
+			ToolUID:       event.ToolUID,
+			Type:          string(event.Type),
+			ActorID:       string(event.ActorID),
+			DeviceID:      event.DeviceID,
+			RequestID:     event.RequestID,
+			ChangedFields: event.ChangedFields,
+			CreatedAt:     event.CreatedAt,
+		}
+	})
+}
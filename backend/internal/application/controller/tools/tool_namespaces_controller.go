@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewToolNamespacesController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	namespaceService *service.NamespaceService,
+) router.Controller {
+	return ToolNamespacesController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		namespaceService:           namespaceService,
+	}
+}
+
+type ToolNamespacesController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	namespaceService           *service.NamespaceService
+}
+
+// namespaces may contain slashes, so the name travels in the body instead of the path
+func (c ToolNamespacesController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/namespaces", Handler: c.AllNamespaces, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/tools/namespaces/create", Handler: c.Create},
+		{Method: http.MethodPost, Path: "/api/v1/tools/namespaces/update", Handler: c.Update},
+		{Method: http.MethodPost, Path: "/api/v1/tools/namespaces/delete", Handler: c.Delete},
+	}
+}
+
+// @Summary		List tool namespaces
+// @Description	List the namespaces of the authenticated user that have settings, with their defaults for new tools and their tool counts
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AllToolNamespacesResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/namespaces [get]
+func (c *ToolNamespacesController) AllNamespaces(ctx *gin.Context) {
+	logger.Infof(ctx, "List tool namespaces requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	namespaces, err := c.namespaceService.ListNamespaces(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get tool namespaces for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected fetch tool namespaces error"))
+		return
+	}
+
+	var resp AllToolNamespacesResponseDto
+	resp.FromEntity(namespaces)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Create tool namespace
+// @Description	Save the settings of a namespace. New tools created in it without a category, realtime_execution or ui_widgets take the defaults
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token"
+// @Param			request			body		SaveToolNamespaceRequestDto	true	"Namespace settings"
+// @Success		200				{object}	swagger.BaseSuccessResponse[SaveToolNamespaceResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/namespaces/create [post]
+func (c *ToolNamespacesController) Create(ctx *gin.Context) {
+	logger.Infof(ctx, "Create tool namespace requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req SaveToolNamespaceRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid create tool namespace payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	namespace, err := c.namespaceService.CreateNamespace(ctx, user.ID, req.ToEntity())
+	if err != nil {
+		logger.Errorf(ctx, "Failed to create tool namespace %s for user %s: %v", req.Name, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Tool namespace %s created for user %s", namespace.Name, user.ID)
+	var resp SaveToolNamespaceResponseDto
+	resp.Namespace.FromEntity(namespace)
+	c.Success(ctx, "Tool namespace created successfully", resp)
+}
+
+// @Summary		Update tool namespace
+// @Description	Replace the description and the defaults of a namespace, the tools already in it are not changed
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token"
+// @Param			request			body		SaveToolNamespaceRequestDto	true	"Namespace settings"
+// @Success		200				{object}	swagger.BaseSuccessResponse[SaveToolNamespaceResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/namespaces/update [post]
+func (c *ToolNamespacesController) Update(ctx *gin.Context) {
+	logger.Infof(ctx, "Update tool namespace requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req SaveToolNamespaceRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid update tool namespace payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	namespace, err := c.namespaceService.UpdateNamespace(ctx, user.ID, req.ToEntity())
+	if err != nil {
+		logger.Errorf(ctx, "Failed to update tool namespace %s for user %s: %v", req.Name, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Tool namespace %s updated for user %s", namespace.Name, user.ID)
+	var resp SaveToolNamespaceResponseDto
+	resp.Namespace.FromEntity(namespace)
+	c.Success(ctx, "Tool namespace updated successfully", resp)
+}
+
+// @Summary		Delete tool namespace
+// @Description	Delete the settings of a namespace, the tools in it are kept and new tools get no defaults
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string							true	"Bearer access token"
+// @Param			request			body		DeleteToolNamespaceRequestDto	true	"Namespace to delete"
+// @Success		200				{object}	swagger.BaseSuccessResponse[DeleteToolNamespaceResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/namespaces/delete [post]
+func (c *ToolNamespacesController) Delete(ctx *gin.Context) {
+	logger.Infof(ctx, "Delete tool namespace requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req DeleteToolNamespaceRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid delete tool namespace payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	if err := c.namespaceService.DeleteNamespace(ctx, user.ID, req.Name); err != nil {
+		logger.Errorf(ctx, "Failed to delete tool namespace %s for user %s: %v", req.Name, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Tool namespace %s deleted for user %s", req.Name, user.ID)
+	c.Success(ctx, "Tool namespace deleted successfully", DeleteToolNamespaceResponseDto{})
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type ToolNamespaceDto struct {
+	Name                     string    `json:"name" example:"tools/json"`
+	Description              string    `json:"description" example:"JSON helpers"`
+	DefaultCategory          string    `json:"default_category" example:"analytics"`
+	DefaultRealtimeExecution bool      `json:"default_realtime_execution" example:"false"`
+	DefaultUiWidgets         string    `json:"default_ui_widgets" example:"[]"`
+	ToolCount                int       `json:"tool_count" example:"3"`
+	CreatedAt                time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt                time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+func (dto *ToolNamespaceDto) FromEntity(namespace entity.NamespaceEntity) {
+	dto.Name = namespace.Name
+	dto.Description = namespace.Description
+	dto.DefaultCategory = namespace.DefaultCategory
+	dto.DefaultRealtimeExecution = namespace.DefaultRealtimeExecution
+	dto.DefaultUiWidgets = namespace.DefaultUiWidgets
+	dto.ToolCount = namespace.ToolCount
+	dto.CreatedAt = namespace.CreatedAt
+	dto.UpdatedAt = namespace.UpdatedAt
+}
+
+type AllToolNamespacesResponseDto struct {
+	Namespaces []ToolNamespaceDto `json:"namespaces"`
+}
+
+func (dto *AllToolNamespacesResponseDto) FromEntity(namespaces []entity.NamespaceEntity) {
+	dto.Namespaces = lo.Map(namespaces, func(namespace entity.NamespaceEntity, _ int) ToolNamespaceDto {
+		item := ToolNamespaceDto{}
+		item.FromEntity(namespace)
+		return item
+	})
+}
+
+// SaveToolNamespaceRequestDto creates a namespace or replaces its settings, an empty default_ui_widgets gives new
+// tools an empty widget list.
+type SaveToolNamespaceRequestDto struct {
+	Name                     string `json:"name" binding:"required,min=1,max=255" example:"tools/json"`
+	Description              string `json:"description" binding:"max=1024" example:"JSON helpers"`
+	DefaultCategory          string `json:"default_category" binding:"max=255" example:"analytics"`
+	DefaultRealtimeExecution bool   `json:"default_realtime_execution" example:"false"`
+	DefaultUiWidgets         string `json:"default_ui_widgets" example:"[]"`
+}
+
+func (dto SaveToolNamespaceRequestDto) ToEntity() entity.NamespaceEntity {
+	return entity.NamespaceEntity{
+		Name:                     dto.Name,
+		Description:              dto.Description,
+		DefaultCategory:          dto.DefaultCategory,
+		DefaultRealtimeExecution: dto.DefaultRealtimeExecution,
+		DefaultUiWidgets:         dto.DefaultUiWidgets,
+	}
+}
+
+type SaveToolNamespaceResponseDto struct {
+	Namespace ToolNamespaceDto `json:"namespace"`
+}
+
+type DeleteToolNamespaceRequestDto struct {
+	Name string `json:"name" binding:"required,min=1,max=255" example:"tools/json"`
+}
+
+type DeleteToolNamespaceResponseDto struct{}
@@ -0,0 +1,267 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewToolSchedulesController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	toolScheduleService *service.ToolScheduleService,
+) router.Controller {
+	return ToolSchedulesController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		toolScheduleService:        toolScheduleService,
+	}
+}
+
+// ToolSchedulesController lets the owner of a tool run it on the server on a cron expression
+type ToolSchedulesController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	toolScheduleService        *service.ToolScheduleService
+}
+
+func (c ToolSchedulesController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/:tool_uid/schedules", Handler: c.List, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/tools/:tool_uid/schedules", Handler: c.Create},
+		{Method: http.MethodGet, Path: "/api/v1/tools/:tool_uid/schedules/:schedule_id", Handler: c.Get, DemoModeAllowed: true},
+		{Method: http.MethodPut, Path: "/api/v1/tools/:tool_uid/schedules/:schedule_id", Handler: c.Update},
+		{Method: http.MethodDelete, Path: "/api/v1/tools/:tool_uid/schedules/:schedule_id", Handler: c.Delete},
+		{Method: http.MethodGet, Path: "/api/v1/tools/:tool_uid/schedules/:schedule_id/runs", Handler: c.ListRuns, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		List the schedules of a tool
+// @Description	Lists the schedules running the tool on the server, oldest first
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ListToolSchedulesResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/schedules [get]
+func (c *ToolSchedulesController) List(ctx *gin.Context) {
+	logger.Infof(ctx, "List Tool Schedules requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	schedules, err := c.toolScheduleService.ListSchedules(ctx, user.ID, toolUID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list schedules of tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp ListToolSchedulesResponseDto
+	resp.FromEntity(schedules)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Schedule a tool
+// @Description	Runs the tool on the server with the stored inputs whenever the cron expression matches, evaluated in UTC.
+// @Description	Needs the tool_execution capability. Scheduled runs count against the tool execution limits of the user and get
+// @Description	the limits of the resource class of the schedule, their outcome is kept in the run history of the schedule.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token"
+// @Param			tool_uid		path		string						true	"Tool unique identifier (UID)"
+// @Param			request			body		SaveToolScheduleRequestDto	true	"Schedule"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ToolScheduleResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		413				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/schedules [post]
+func (c *ToolSchedulesController) Create(ctx *gin.Context) {
+	logger.Infof(ctx, "Create Tool Schedule requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req SaveToolScheduleRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid tool schedule payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	schedule, err := c.toolScheduleService.CreateSchedule(ctx, user.ID, toolUID, req.Name, req.Cron, req.Inputs, entity.ToolResourceClass(req.ResourceClass), *req.Enabled)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to schedule tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Tool %s scheduled by user %s with schedule %s", toolUID, user.ID, schedule.ID)
+	var resp ToolScheduleResponseDto
+	resp.Schedule.FromEntity(schedule)
+	c.Success(ctx, "Tool schedule created successfully", resp)
+}
+
+// @Summary		Get a schedule of a tool
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Param			schedule_id		path		string	true	"Schedule id"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ToolScheduleResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/schedules/{schedule_id} [get]
+func (c *ToolSchedulesController) Get(ctx *gin.Context) {
+	logger.Infof(ctx, "Get Tool Schedule requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	scheduleID := ctx.Param("schedule_id")
+	schedule, err := c.toolScheduleService.GetSchedule(ctx, user.ID, toolUID, scheduleID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get schedule %s of tool %s for user %s: %v", scheduleID, toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp ToolScheduleResponseDto
+	resp.Schedule.FromEntity(schedule)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Update a schedule of a tool
+// @Description	Replaces the name, cron expression, inputs, resource class and enabled state of the schedule. The next run is computed from now,
+// @Description	runs missed while the schedule was disabled are not made up.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token"
+// @Param			tool_uid		path		string						true	"Tool unique identifier (UID)"
+// @Param			schedule_id		path		string						true	"Schedule id"
+// @Param			request			body		SaveToolScheduleRequestDto	true	"Schedule"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ToolScheduleResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		403				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		413				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/schedules/{schedule_id} [put]
+func (c *ToolSchedulesController) Update(ctx *gin.Context) {
+	logger.Infof(ctx, "Update Tool Schedule requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req SaveToolScheduleRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid tool schedule payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	scheduleID := ctx.Param("schedule_id")
+	schedule, err := c.toolScheduleService.UpdateSchedule(ctx, user.ID, toolUID, scheduleID, req.Name, req.Cron, req.Inputs, entity.ToolResourceClass(req.ResourceClass), *req.Enabled)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to update schedule %s of tool %s for user %s: %v", scheduleID, toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Schedule %s of tool %s updated by user %s", scheduleID, toolUID, user.ID)
+	var resp ToolScheduleResponseDto
+	resp.Schedule.FromEntity(schedule)
+	c.Success(ctx, "Tool schedule updated successfully", resp)
+}
+
+// @Summary		Delete a schedule of a tool
+// @Description	Deletes the schedule with the history of its runs
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Param			schedule_id		path		string	true	"Schedule id"
+// @Success		200				{object}	swagger.BaseSuccessResponse[DeleteToolScheduleResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/schedules/{schedule_id} [delete]
+func (c *ToolSchedulesController) Delete(ctx *gin.Context) {
+	logger.Infof(ctx, "Delete Tool Schedule requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	scheduleID := ctx.Param("schedule_id")
+	if err := c.toolScheduleService.DeleteSchedule(ctx, user.ID, toolUID, scheduleID); err != nil {
+		logger.Errorf(ctx, "Failed to delete schedule %s of tool %s for user %s: %v", scheduleID, toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Schedule %s of tool %s deleted by user %s", scheduleID, toolUID, user.ID)
+	c.Success(ctx, "Tool schedule deleted successfully", DeleteToolScheduleResponseDto{})
+}
+
+// @Summary		List the runs of a schedule
+// @Description	Lists the recorded runs of the schedule, newest first. Only the newest TOOL_RUN_HISTORY_LIMIT runs are kept.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Param			schedule_id		path		string	true	"Schedule id"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ListToolRunsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/schedules/{schedule_id}/runs [get]
+func (c *ToolSchedulesController) ListRuns(ctx *gin.Context) {
+	logger.Infof(ctx, "List Tool Runs requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	scheduleID := ctx.Param("schedule_id")
+	runs, err := c.toolScheduleService.ListRuns(ctx, user.ID, toolUID, scheduleID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list runs of schedule %s of tool %s for user %s: %v", scheduleID, toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp ListToolRunsResponseDto
+	resp.FromEntity(runs)
+	c.Success(ctx, "", resp)
+}
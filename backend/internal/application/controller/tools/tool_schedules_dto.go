@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+// ToolScheduleDto runs the tool on the server whenever cron matches, next_run_at is null while it is disabled.
+type ToolScheduleDto struct {
+	ID      string `json:"id" example:"schedule-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	ToolUID string `json:"tool_uid" example:"tool-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	Name    string `json:"name" example:"Nightly report"`
+	// Cron is evaluated in UTC
+	Cron          string         `json:"cron" example:"0 3 * * *"`
+	Inputs        map[string]any `json:"inputs" swaggertype:"object"`
+	ResourceClass string         `json:"resource_class" enums:"small,medium,large" example:"small"`
+	Enabled       bool           `json:"enabled" example:"true"`
+	NextRunAt     *time.Time     `json:"next_run_at" example:"2024-01-02T03:00:00Z"`
+	LastRunAt     *time.Time     `json:"last_run_at" example:"2024-01-01T03:00:00Z"`
+	CreatedAt     time.Time      `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt     time.Time      `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+func (dto *ToolScheduleDto) FromEntity(schedule entity.ToolScheduleEntity) {
+	dto.ID = schedule.ID
+	dto.ToolUID = schedule.ToolUID
+	dto.Name = schedule.Name
+	dto.Cron = schedule.Cron
+	dto.Inputs = schedule.Inputs
+	dto.ResourceClass = string(schedule.ResourceClass)
+	dto.Enabled = schedule.Enabled
+	dto.NextRunAt = schedule.NextRunAt
+	dto.LastRunAt = schedule.LastRunAt
+	dto.CreatedAt = schedule.CreatedAt
+	dto.UpdatedAt = schedule.UpdatedAt
+}
+
+type ListToolSchedulesResponseDto struct {
+	Schedules []ToolScheduleDto `json:"schedules"`
+}
+
+func (dto *ListToolSchedulesResponseDto) FromEntity(schedules []entity.ToolScheduleEntity) {
+	dto.Schedules = lo.Map(schedules, func(schedule entity.ToolScheduleEntity, _ int) ToolScheduleDto {
+		var scheduleDto ToolScheduleDto
+		scheduleDto.FromEntity(schedule)
+		return scheduleDto
+	})
+}
+
+// SaveToolScheduleRequestDto creates a schedule, or replaces every field of one on update.
+type SaveToolScheduleRequestDto struct {
+	Name string `json:"name" binding:"required,max=128" example:"Nightly report"`
+	// Cron is a five field cron expression, a descriptor such as @daily or @every 1h, evaluated in UTC
+	Cron string `json:"cron" binding:"required,max=128" example:"0 3 * * *"`
+	// Inputs are the values of the input widgets by widget id the handler is called with
+	Inputs map[string]any `json:"inputs" swaggertype:"object"`
+	// ResourceClass selects the time and memory limits of the scheduled runs, small when it is left out
+	ResourceClass string `json:"resource_class" binding:"omitempty,oneof=small medium large" enums:"small,medium,large" example:"small"`
+	Enabled       *bool  `json:"enabled" binding:"required" example:"true"`
+}
+
+type ToolScheduleResponseDto struct {
+	Schedule ToolScheduleDto `json:"schedule"`
+}
+
+type DeleteToolScheduleResponseDto struct{}
+
+// ToolRunDto is a recorded scheduled run, error_code and error_message are empty when it succeeded.
+type ToolRunDto struct {
+	ID         string `json:"id" example:"run-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	ScheduleID string `json:"schedule_id" example:"schedule-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	Status     string `json:"status" enums:"success,failed" example:"success"`
+	// Outputs is the object the handler returned, null when it failed or returned nothing
+	Outputs      map[string]any        `json:"outputs" swaggertype:"object"`
+	Logs         []ToolExecutionLogDto `json:"logs"`
+	ErrorCode    string                `json:"error_code" example:"ToolExecutionTimedOut"`
+	ErrorMessage string                `json:"error_message" example:"the handler did not finish in 5s"`
+	StartedAt    time.Time             `json:"started_at" example:"2024-01-01T03:00:00Z"`
+	DurationMs   int64                 `json:"duration_ms" example:"12"`
+}
+
+func (dto *ToolRunDto) FromEntity(run entity.ToolRunEntity) {
+	dto.ID = run.ID
+	dto.ScheduleID = run.ScheduleID
+	dto.Status = string(run.Status)
+	dto.Outputs = run.Outputs
+	dto.Logs = lo.Map(run.Logs, func(log entity.ToolExecutionLogEntity, _ int) ToolExecutionLogDto {
+		return ToolExecutionLogDto{Level: log.Level, Message: log.Message}
+	})
+	dto.ErrorCode = run.ErrorCode
+	dto.ErrorMessage = run.ErrorMessage
+	dto.StartedAt = run.StartedAt
+	dto.DurationMs = run.Duration.Milliseconds()
+}
+
+type ListToolRunsResponseDto struct {
+	Runs []ToolRunDto `json:"runs"`
+}
+
+func (dto *ListToolRunsResponseDto) FromEntity(runs []entity.ToolRunEntity) {
+	dto.Runs = lo.Map(runs, func(run entity.ToolRunEntity, _ int) ToolRunDto {
+		var runDto ToolRunDto
+		runDto.FromEntity(run)
+		return runDto
+	})
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewToolSchemaController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	toolService *service.ToolService,
+) router.Controller {
+	return ToolSchemaController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		toolService:                toolService,
+	}
+}
+
+type ToolSchemaController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	toolService                *service.ToolService
+}
+
+func (c ToolSchemaController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/:tool_uid/schema", Handler: c.Schema, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/tools/:tool_uid/schema/validate", Handler: c.Validate, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Get tool input and output schemas
+// @Description	JSON Schemas of the arguments a tool handler takes and of the object it returns, for headless invocation and LLM tool calling.
+// @Description	A schema declared in the inputSchema or outputSchema extra info is returned as is, otherwise it is derived from the ui widgets:
+// @Description	one property per widget id, input widgets for the input and output widgets for the output. File widgets are left out.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ToolSchemaResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		422				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/schema [get]
+func (c *ToolSchemaController) Schema(ctx *gin.Context) {
+	logger.Infof(ctx, "Tool schema requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	schema, err := c.toolService.ToolSchema(ctx, user.ID, toolUID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to resolve schema of tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp ToolSchemaResponseDto
+	resp.FromEntity(schema)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Validate a tool run against its schemas
+// @Description	Check the arguments of a headless run, and optionally the object the handler returned, against the tool schemas.
+// @Description	Problems are reported as "<json path>: <message>", the request succeeds whether the values are valid or not.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token"
+// @Param			tool_uid		path		string						true	"Tool unique identifier (UID)"
+// @Param			request			body		ValidateToolRunRequestDto	true	"Handler arguments and result"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ValidateToolRunResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		422				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/schema/validate [post]
+func (c *ToolSchemaController) Validate(ctx *gin.Context) {
+	logger.Infof(ctx, "Tool run validation requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req ValidateToolRunRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid tool run validation payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	result, err := c.toolService.ValidateToolRun(ctx, user.ID, toolUID, req.Input, req.Output)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to validate run of tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Run of tool %s validated for user %s, valid: %t", toolUID, user.ID, result.Valid())
+	var resp ValidateToolRunResponseDto
+	resp.FromEntity(result)
+	c.Success(ctx, "", resp)
+}
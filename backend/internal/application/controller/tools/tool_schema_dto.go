@@ -0,0 +1,36 @@
+package tools
+
+import "ya-tool-craft/internal/domain/entity"
+
+type ToolSchemaResponseDto struct {
+	InputSchema  map[string]any `json:"input_schema" swaggertype:"object"`
+	OutputSchema map[string]any `json:"output_schema" swaggertype:"object"`
+	// InputDerived is true when the input schema was derived from the ui widgets instead of declared in the extra info
+	InputDerived  bool `json:"input_derived" example:"true"`
+	OutputDerived bool `json:"output_derived" example:"false"`
+}
+
+func (dto *ToolSchemaResponseDto) FromEntity(schema entity.ToolSchemaEntity) {
+	dto.InputSchema = schema.Input
+	dto.OutputSchema = schema.Output
+	dto.InputDerived = schema.InputDerived
+	dto.OutputDerived = schema.OutputDerived
+}
+
+type ValidateToolRunRequestDto struct {
+	Input map[string]any `json:"input" binding:"required" swaggertype:"object"`
+	// Output is the object the handler returned, it is only checked when given
+	Output map[string]any `json:"output" binding:"omitempty" swaggertype:"object"`
+}
+
+type ValidateToolRunResponseDto struct {
+	Valid          bool     `json:"valid" example:"false"`
+	InputProblems  []string `json:"input_problems" example:"$.count: must be a number"`
+	OutputProblems []string `json:"output_problems"`
+}
+
+func (dto *ValidateToolRunResponseDto) FromEntity(result entity.ToolSchemaValidationEntity) {
+	dto.Valid = result.Valid()
+	dto.InputProblems = result.InputProblems
+	dto.OutputProblems = result.OutputProblems
+}
@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewToolSharesController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	toolShareService *service.ToolShareService,
+) router.Controller {
+	return ToolSharesController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		toolShareService:           toolShareService,
+	}
+}
+
+// ToolSharesController lets the owner of a tool manage who it is shared with
+type ToolSharesController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	toolShareService           *service.ToolShareService
+}
+
+func (c ToolSharesController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/:tool_uid/shares", Handler: c.List, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/tools/:tool_uid/shares", Handler: c.Create},
+		{Method: http.MethodDelete, Path: "/api/v1/tools/:tool_uid/shares/:share_id", Handler: c.Revoke},
+	}
+}
+
+// @Summary		List the shares of a tool
+// @Description	Lists the users the tool is shared with and its public link, oldest first
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ListToolSharesResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/shares [get]
+func (c *ToolSharesController) List(ctx *gin.Context) {
+	logger.Infof(ctx, "List Tool Shares requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	shares, err := c.toolShareService.ListShares(ctx, user.ID, toolUID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list shares of tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp ListToolSharesResponseDto
+	resp.FromEntity(shares)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Share a tool
+// @Description	Grants the user named username read or fork access to the tool, or makes it public to anyone with the link when public is true.
+// @Description	Sharing again with the same user, or by link again, changes the permission and keeps the share id.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token"
+// @Param			tool_uid		path		string						true	"Tool unique identifier (UID)"
+// @Param			request			body		CreateToolShareRequestDto	true	"Share target and permission"
+// @Success		200				{object}	swagger.BaseSuccessResponse[CreateToolShareResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/shares [post]
+func (c *ToolSharesController) Create(ctx *gin.Context) {
+	logger.Infof(ctx, "Create Tool Share requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req CreateToolShareRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid tool share payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+	if req.Public == (req.Username != "") {
+		logger.Errorf(ctx, "Invalid tool share payload: exactly one of username and public must be set")
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "exactly one of username and public must be set"))
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	permission := entity.ToolSharePermission(req.Permission)
+	var share entity.ToolShareEntity
+	if req.Public {
+		share, err = c.toolShareService.ShareByLink(ctx, user.ID, toolUID, permission)
+	} else {
+		share, err = c.toolShareService.ShareWithUser(ctx, user.ID, toolUID, req.Username, permission)
+	}
+	if err != nil {
+		logger.Errorf(ctx, "Failed to share tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Tool %s shared by user %s with share %s", toolUID, user.ID, share.ID)
+	var resp CreateToolShareResponseDto
+	resp.Share.FromEntity(share)
+	c.Success(ctx, "Tool shared successfully", resp)
+}
+
+// @Summary		Revoke a share of a tool
+// @Description	Removes the access of the grantee, revoking the public link makes the link stop working
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Param			share_id		path		string	true	"Share id"
+// @Success		200				{object}	swagger.BaseSuccessResponse[RevokeToolShareResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/shares/{share_id} [delete]
+func (c *ToolSharesController) Revoke(ctx *gin.Context) {
+	logger.Infof(ctx, "Revoke Tool Share requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	shareID := ctx.Param("share_id")
+	if err := c.toolShareService.RevokeShare(ctx, user.ID, toolUID, shareID); err != nil {
+		logger.Errorf(ctx, "Failed to revoke share %s of tool %s for user %s: %v", shareID, toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	logger.Infof(ctx, "Share %s of tool %s revoked by user %s", shareID, toolUID, user.ID)
+	c.Success(ctx, "Tool share revoked successfully", RevokeToolShareResponseDto{})
+}
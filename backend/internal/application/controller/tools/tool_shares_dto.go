@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+// ToolShareDto is a grant of a tool, grantee_id is empty for the public link of the tool and id is the link token.
+type ToolShareDto struct {
+	ID         string    `json:"id" example:"share-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	ToolUID    string    `json:"tool_uid" example:"tool-xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"`
+	GranteeID  string    `json:"grantee_id" example:"user-123"`
+	Public     bool      `json:"public" example:"false"`
+	Permission string    `json:"permission" enums:"read,fork" example:"read"`
+	CreatedAt  time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt  time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+func (dto *ToolShareDto) FromEntity(share entity.ToolShareEntity) {
+	dto.ID = share.ID
+	dto.ToolUID = share.ToolUniqueID
+	dto.GranteeID = string(share.GranteeID)
+	dto.Public = share.IsPublic()
+	dto.Permission = string(share.Permission)
+	dto.CreatedAt = share.CreatedAt
+	dto.UpdatedAt = share.UpdatedAt
+}
+
+type ListToolSharesResponseDto struct {
+	Shares []ToolShareDto `json:"shares"`
+}
+
+func (dto *ListToolSharesResponseDto) FromEntity(shares []entity.ToolShareEntity) {
+	dto.Shares = lo.Map(shares, func(share entity.ToolShareEntity, _ int) ToolShareDto {
+		var shareDto ToolShareDto
+		shareDto.FromEntity(share)
+		return shareDto
+	})
+}
+
+// CreateToolShareRequestDto shares the tool with the user named username, or by link when public is true.
+type CreateToolShareRequestDto struct {
+	Username   string `json:"username" binding:"omitempty,max=255" example:"alice"`
+	Public     bool   `json:"public" example:"false"`
+	Permission string `json:"permission" binding:"required,oneof=read fork" enums:"read,fork" example:"read"`
+}
+
+type CreateToolShareResponseDto struct {
+	Share ToolShareDto `json:"share"`
+}
+
+type RevokeToolShareResponseDto struct{}
+
+type SharedToolDto struct {
+	Share     ToolShareDto `json:"share"`
+	OwnerName string       `json:"owner_name" example:"bob"`
+	Tool      ToolDto      `json:"tool"`
+}
+
+func (dto *SharedToolDto) FromEntity(sharedTool entity.SharedToolEntity) {
+	dto.Share.FromEntity(sharedTool.Share)
+	dto.OwnerName = sharedTool.OwnerName
+	dto.Tool.FromEntity(sharedTool.Tool)
+}
+
+type ListSharedToolsResponseDto struct {
+	Tools []SharedToolDto `json:"tools"`
+}
+
+func (dto *ListSharedToolsResponseDto) FromEntity(sharedTools []entity.SharedToolEntity) {
+	dto.Tools = lo.Map(sharedTools, func(sharedTool entity.SharedToolEntity, _ int) SharedToolDto {
+		var sharedToolDto SharedToolDto
+		sharedToolDto.FromEntity(sharedTool)
+		return sharedToolDto
+	})
+}
+
+type GetSharedToolResponseDto struct {
+	SharedTool SharedToolDto `json:"shared_tool"`
+}
+
+type ForkSharedToolResponseDto struct {
+	Tool     ToolDto                `json:"tool"`
+	Warnings []ToolSecretWarningDto `json:"warnings"`
+}
@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewToolTagsController(
+	toolRepository repository.IToolRepository,
+	toolService *service.ToolService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	cache repository.ICache,
+) router.Controller {
+	return ToolTagsController{
+		toolRepository:             toolRepository,
+		toolService:                toolService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		cache:                      cache,
+	}
+}
+
+// ToolTagsController lists the tags of the tools of a user and tags and untags single tools. The tags are kept in the
+// tags extra info of the tools, a tag change shows up there like any other tool update.
+type ToolTagsController struct {
+	common.JsonResponse
+
+	toolRepository             repository.IToolRepository
+	toolService                *service.ToolService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	cache                      repository.ICache
+}
+
+func (c ToolTagsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/tags", Handler: c.AllTags, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/api/v1/tools/tags/:tag", Handler: c.ToolsByTag, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/tools/:tool_uid/tags", Handler: c.AddTag},
+		{Method: http.MethodDelete, Path: "/api/v1/tools/:tool_uid/tags/:tag", Handler: c.RemoveTag},
+	}
+}
+
+// @Summary		List tool tags
+// @Description	List the tags used by the tools of the authenticated user with their tool counts, ordered by tag
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AllToolTagsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/tags [get]
+func (c *ToolTagsController) AllTags(ctx *gin.Context) {
+	logger.Infof(ctx, "List tool tags requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	tags, err := c.toolRepository.AllTags(user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get tool tags for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected fetch tool tags error"))
+		return
+	}
+
+	var resp AllToolTagsResponseDto
+	resp.FromEntity(tags)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		List the tools with a tag
+// @Description	List the tools of the authenticated user with the tag, oldest first. The tag is matched case-insensitively.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tag				path		string	true	"Tag"
+// @Success		200				{object}	swagger.BaseSuccessResponse[FilterToolsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/tags/{tag} [get]
+func (c *ToolTagsController) ToolsByTag(ctx *gin.Context) {
+	logger.Infof(ctx, "List tools by tag requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	tools, err := c.toolService.ToolsByTag(ctx, user.ID, ctx.Param("tag"))
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get tools by tag for user %s: %v", user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp FilterToolsResponseDto
+	resp.FromEntity(tools)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Tag a tool
+// @Description	Add a tag to the tags extra info of the tool, tags are stored lower cased. Adding a tag the tool has changes nothing.
+// @Description	A tool has at most 20 tags of at most 32 characters, tags can not contain commas.
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string					true	"Bearer access token"
+// @Param			tool_uid		path		string					true	"Tool unique identifier (UID)"
+// @Param			request			body		AddToolTagRequestDto	true	"Tag"
+// @Success		200				{object}	swagger.BaseSuccessResponse[AddToolTagResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/tags [post]
+func (c *ToolTagsController) AddTag(ctx *gin.Context) {
+	logger.Infof(ctx, "Add tool tag requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req AddToolTagRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid add tool tag payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	tag, err := c.toolService.AddToolTag(ctx, user.ID, toolUID, req.Tag, service.ToolEventSourceFromContext(ctx, user.ID))
+	if err != nil {
+		logger.Errorf(ctx, "Failed to tag tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+	if !c.deleteToolsCache(ctx, user.ID) {
+		return
+	}
+
+	logger.Infof(ctx, "Tool %s of user %s tagged %s", toolUID, user.ID, tag)
+	c.Success(ctx, "Tool tag added successfully", AddToolTagResponseDto{Tag: tag})
+}
+
+// @Summary		Untag a tool
+// @Description	Remove a tag from the tags extra info of the tool, removing a tag the tool does not have changes nothing
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Param			tag				path		string	true	"Tag"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/tags/{tag} [delete]
+func (c *ToolTagsController) RemoveTag(ctx *gin.Context) {
+	logger.Infof(ctx, "Remove tool tag requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	if err := c.toolService.RemoveToolTag(ctx, user.ID, toolUID, ctx.Param("tag"), service.ToolEventSourceFromContext(ctx, user.ID)); err != nil {
+		logger.Errorf(ctx, "Failed to untag tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+	if !c.deleteToolsCache(ctx, user.ID) {
+		return
+	}
+
+	c.Success(ctx, "Tool tag removed successfully", nil)
+}
+
+// deleteToolsCache drops the cached tool lists of the user, the extra info of a tool changed. It answers the request
+// itself when that fails.
+func (c *ToolTagsController) deleteToolsCache(ctx *gin.Context, userID entity.UserIDEntity) bool {
+	if err := DeleteToolsCache(ctx, c.cache, userID); err != nil {
+		logger.Errorf(ctx, "Failed to delete cache for user %s: %v", userID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error"))
+		return false
+	}
+	return true
+}
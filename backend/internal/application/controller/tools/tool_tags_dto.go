@@ -0,0 +1,31 @@
+package tools
+
+import (
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type ToolTagDto struct {
+	Name      string `json:"name" example:"json"`
+	ToolCount int    `json:"tool_count" example:"3"`
+}
+
+type AllToolTagsResponseDto struct {
+	Tags []ToolTagDto `json:"tags"`
+}
+
+func (dto *AllToolTagsResponseDto) FromEntity(tags []entity.ToolTagEntity) {
+	dto.Tags = lo.Map(tags, func(tag entity.ToolTagEntity, _ int) ToolTagDto {
+		return ToolTagDto{Name: tag.Name, ToolCount: tag.ToolCount}
+	})
+}
+
+type AddToolTagRequestDto struct {
+	Tag string `json:"tag" binding:"required,max=255" example:"JSON"`
+}
+
+type AddToolTagResponseDto struct {
+	// Tag is the tag as it is stored, lower cased
+	Tag string `json:"tag" example:"json"`
+}
@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"net/http"
+	"strconv"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewToolVersionsController(
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	cache repository.ICache,
+	toolVersionService *service.ToolVersionService,
+) router.Controller {
+	return ToolVersionsController{
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		cache:                      cache,
+		toolVersionService:         toolVersionService,
+	}
+}
+
+type ToolVersionsController struct {
+	common.JsonResponse
+
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	cache                      repository.ICache
+	toolVersionService         *service.ToolVersionService
+}
+
+func (c ToolVersionsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/tools/:tool_uid/versions", Handler: c.List, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/api/v1/tools/:tool_uid/versions/diff", Handler: c.Diff, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/tools/:tool_uid/versions/:version/restore", Handler: c.Restore},
+	}
+}
+
+// @Summary		List the versions of a tool
+// @Description	Lists the saved versions of a tool newest first. A version is recorded whenever the source or the ui widgets of the tool change,
+// @Description	only the latest TOOL_VERSION_LIMIT versions are kept.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Success		200				{object}	swagger.BaseSuccessResponse[ListToolVersionsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/versions [get]
+func (c *ToolVersionsController) List(ctx *gin.Context) {
+	logger.Infof(ctx, "List Tool Versions requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	versions, err := c.toolVersionService.ListVersions(ctx, user.ID, toolUID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list versions of tool %s for user %s: %v", toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp ListToolVersionsResponseDto
+	resp.FromEntity(versions)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Diff two versions of a tool
+// @Description	Returns the lines of the source and of the ui widgets removed and added from the version from to the version to.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Param			from			query		int		true	"Older version"
+// @Param			to				query		int		true	"Newer version"
+// @Success		200				{object}	swagger.BaseSuccessResponse[DiffToolVersionsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/versions/diff [get]
+func (c *ToolVersionsController) Diff(ctx *gin.Context) {
+	logger.Infof(ctx, "Diff Tool Versions requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req DiffToolVersionsRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		logger.Errorf(ctx, "Invalid tool version diff query: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	diff, err := c.toolVersionService.DiffVersions(ctx, user.ID, toolUID, req.From, req.To)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to diff versions %d and %d of tool %s for user %s: %v", req.From, req.To, toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp DiffToolVersionsResponseDto
+	resp.FromEntity(diff)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Restore a version of a tool
+// @Description	Sets the source and the ui widgets of the tool back to the ones of the version, the other fields are kept.
+// @Description	The restored state is saved as a new version, the versions after the restored one stay in the history.
+// @Tags			Tools
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			tool_uid		path		string	true	"Tool unique identifier (UID)"
+// @Param			version			path		int		true	"Version to restore"
+// @Success		200				{object}	swagger.BaseSuccessResponse[RestoreToolVersionResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/{tool_uid}/versions/{version}/restore [post]
+func (c *ToolVersionsController) Restore(ctx *gin.Context) {
+	logger.Infof(ctx, "Restore Tool Version requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	toolUID := ctx.Param("tool_uid")
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil || version < 1 {
+		logger.Errorf(ctx, "Invalid tool version restore: version %q is not a positive number", ctx.Param("version"))
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "version must be a positive number"))
+		return
+	}
+
+	tool, err := c.toolVersionService.RestoreVersion(ctx, user.ID, toolUID, version)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to restore version %d of tool %s for user %s: %v", version, toolUID, user.ID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	if err := DeleteToolsCache(ctx, c.cache, user.ID); err != nil {
+		logger.Errorf(ctx, "Failed to delete cache for user %s: %v", user.ID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error"))
+		return
+	}
+
+	logger.Infof(ctx, "Version %d of tool %s restored for user %s", version, toolUID, user.ID)
+	var resp RestoreToolVersionResponseDto
+	resp.Tool.FromEntity(tool)
+	c.Success(ctx, "Tool version restored successfully", resp)
+}
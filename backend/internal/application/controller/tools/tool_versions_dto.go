@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type ToolVersionDto struct {
+	Version    int       `json:"version" example:"3"`
+	SourceHash string    `json:"source_hash" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	CreatedAt  time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+type ListToolVersionsResponseDto struct {
+	Versions []ToolVersionDto `json:"versions"`
+}
+
+func (dto *ListToolVersionsResponseDto) FromEntity(versions []entity.ToolVersionEntity) {
+	dto.Versions = lo.Map(versions, func(version entity.ToolVersionEntity, _ int) ToolVersionDto {
+		return ToolVersionDto{Version: version.Version, SourceHash: version.SourceHash, CreatedAt: version.CreatedAt}
+	})
+}
+
+type DiffToolVersionsRequestDto struct {
+	From int `form:"from" binding:"required,min=1" example:"1"`
+	To   int `form:"to" binding:"required,min=1" example:"3"`
+}
+
+// ToolVersionDiffLineDto is a removed or added line, from_line and to_line are its 1-based position in the older
+// and the newer version.
+type ToolVersionDiffLineDto struct {
+	Kind     string `json:"kind" enums:"removed,added" example:"added"`
+	FromLine int    `json:"from_line" example:"4"`
+	ToLine   int    `json:"to_line" example:"5"`
+	Text     string `json:"text" example:"return input.trim()"`
+}
+
+type DiffToolVersionsResponseDto struct {
+	FromVersion int                      `json:"from_version" example:"1"`
+	ToVersion   int                      `json:"to_version" example:"3"`
+	Source      []ToolVersionDiffLineDto `json:"source"`
+	UiWidgets   []ToolVersionDiffLineDto `json:"ui_widgets"`
+}
+
+func (dto *DiffToolVersionsResponseDto) FromEntity(diff entity.ToolVersionDiffEntity) {
+	dto.FromVersion = diff.FromVersion
+	dto.ToVersion = diff.ToVersion
+	dto.Source = toolVersionDiffLinesFromEntity(diff.Source)
+	dto.UiWidgets = toolVersionDiffLinesFromEntity(diff.UiWidgets)
+}
+
+func toolVersionDiffLinesFromEntity(lines []entity.ToolVersionDiffLineEntity) []ToolVersionDiffLineDto {
+	return lo.Map(lines, func(line entity.ToolVersionDiffLineEntity, _ int) ToolVersionDiffLineDto {
+		return ToolVersionDiffLineDto{Kind: string(line.Kind), FromLine: line.FromLine, ToLine: line.ToLine, Text: line.Text}
+	})
+}
+
+type RestoreToolVersionResponseDto struct {
+	Tool ToolDto `json:"tool"`
+}
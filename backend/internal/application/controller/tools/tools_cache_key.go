@@ -1,10 +1,33 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
 )
 
-func toolsCacheKey(userID entity.UserIDEntity) string {
-	return fmt.Sprintf("tools:%s", userID)
+var toolsCacheArchiveFilters = []entity.ToolArchiveFilter{
+	entity.ToolArchiveFilterExclude,
+	entity.ToolArchiveFilterInclude,
+	entity.ToolArchiveFilterOnly,
+}
+
+// toolsCacheKey is the cache key of the tool list of a user, the default list keeps the original key.
+func toolsCacheKey(userID entity.UserIDEntity, filter entity.ToolArchiveFilter) string {
+	if filter == entity.ToolArchiveFilterExclude {
+		return fmt.Sprintf("tools:%s", userID)
+	}
+	return fmt.Sprintf("tools:%s:%s", userID, filter)
+}
+
+// DeleteToolsCache drops every cached tool list of a user, call it after any tool change. cmd/admin calls it
+// after changing the tools in the database directly.
+func DeleteToolsCache(ctx context.Context, cache repository.ICache, userID entity.UserIDEntity) error {
+	for _, filter := range toolsCacheArchiveFilters {
+		if err := cache.Delete(ctx, toolsCacheKey(userID, filter)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"errors"
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewUpdateToolCategoryController(
+	toolRepository repository.IToolRepository,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	cache repository.ICache,
+) router.Controller {
+	return UpdateToolCategoryController{
+		toolRepository:             toolRepository,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		cache:                      cache,
+	}
+}
+
+type UpdateToolCategoryController struct {
+	common.JsonResponse
+
+	toolRepository             repository.IToolRepository
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	cache                      repository.ICache
+}
+
+func (c UpdateToolCategoryController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/tools/categories/rename", Handler: c.Rename},
+		{Method: http.MethodPost, Path: "/api/v1/tools/categories/merge", Handler: c.Merge},
+	}
+}
+
+// @Summary		Rename tool category
+// @Description	Move every tool of a category to a new category name. Fails if the new name is already used, merge the categories instead
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string							true	"Bearer access token"
+// @Param			request			body		RenameToolCategoryRequestDto	true	"Category rename request"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UpdateToolCategoryResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/categories/rename [post]
+func (c *UpdateToolCategoryController) Rename(ctx *gin.Context) {
+	logger.Infof(ctx, "Rename tool category requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req RenameToolCategoryRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid rename tool category payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	updated, err := c.toolRepository.RenameCategory(user.ID, req.From, req.To, service.ToolEventSourceFromContext(ctx, user.ID))
+	c.respond(ctx, user.ID, updated, err)
+}
+
+// @Summary		Merge tool categories
+// @Description	Move every tool of the source category into the existing target category
+// @Tags			Tools
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string							true	"Bearer access token"
+// @Param			request			body		MergeToolCategoriesRequestDto	true	"Category merge request"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UpdateToolCategoryResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/tools/categories/merge [post]
+func (c *UpdateToolCategoryController) Merge(ctx *gin.Context) {
+	logger.Infof(ctx, "Merge tool categories requested")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req MergeToolCategoriesRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		logger.Errorf(ctx, "Invalid merge tool categories payload: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	updated, err := c.toolRepository.MergeCategories(user.ID, req.Source, req.Target, service.ToolEventSourceFromContext(ctx, user.ID))
+	c.respond(ctx, user.ID, updated, err)
+}
+
+func (c *UpdateToolCategoryController) respond(ctx *gin.Context, userID entity.UserIDEntity, updated int64, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrToolCategoryNotFound):
+			c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.ToolCategoryNotFound, err.Error()))
+		case errors.Is(err, repository.ErrToolCategoryAlreadyExists):
+			c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.ToolCategoryAlreadyExists, err.Error()))
+		default:
+			logger.Errorf(ctx, "Failed to update tool category for user %s: %v", userID, err)
+			c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected update tool category error"))
+		}
+		return
+	}
+
+	if err := DeleteToolsCache(ctx, c.cache, userID); err != nil {
+		logger.Errorf(ctx, "Failed to delete cache for user %s: %v", userID, err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error"))
+		return
+	}
+
+	logger.Infof(ctx, "Tool category updated for user %s, %d tools moved", userID, updated)
+	c.Success(ctx, "Tool category updated successfully", UpdateToolCategoryResponseDto{UpdatedToolCount: updated})
+}
@@ -7,6 +7,7 @@ import (
 	"ya-tool-craft/internal/core/logger"
 	"ya-tool-craft/internal/core/router"
 	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
 	"ya-tool-craft/internal/error_code"
 
 	_ "ya-tool-craft/internal/swagger"
@@ -16,15 +17,15 @@ import (
 
 func NewUpdateToolController(
 	config config.Config,
-	toolRepository repository.IToolRepository,
 	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
 	cache repository.ICache,
+	toolService *service.ToolService,
 ) router.Controller {
 	return UpdateToolController{
 		config:                     config,
-		toolRepository:             toolRepository,
 		accessTokenHeaderValidator: accessTokenHeaderValidator,
 		cache:                      cache,
+		toolService:                toolService,
 	}
 }
 
@@ -32,9 +33,9 @@ type UpdateToolController struct {
 	common.JsonResponse
 
 	config                     config.Config
-	toolRepository             repository.IToolRepository
 	accessTokenHeaderValidator common.AccessTokenHeaderValidator
 	cache                      repository.ICache
+	toolService                *service.ToolService
 }
 
 func (c UpdateToolController) RouterInfo() []router.RouterInfo {
@@ -44,7 +45,7 @@ func (c UpdateToolController) RouterInfo() []router.RouterInfo {
 }
 
 // @Summary		Update tool
-// @Description	Update an existing tool belonging to the authenticated user
+// @Description	Update an existing tool belonging to the authenticated user. Credentials found in the source are reported as warnings, or reject the update when the server blocks them
 // @Tags			Tools
 // @Accept			json
 // @Produce		json
@@ -53,6 +54,7 @@ func (c UpdateToolController) RouterInfo() []router.RouterInfo {
 // @Param			request			body		UpdateToolRequestDto	true	"Fields to update"
 // @Success		200				{object}	swagger.BaseSuccessResponse[UpdateToolResponseDto]
 // @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
 // @Router			/api/v1/tools/{tool_uid} [put]
 func (c *UpdateToolController) Update(ctx *gin.Context) {
 	logger.Infof(ctx, "Update Tool requested")
@@ -79,18 +81,18 @@ func (c *UpdateToolController) Update(ctx *gin.Context) {
 
 	tool := req.ToEntity(toolUID)
 
-	if err := c.toolRepository.UpdateTool(user.ID, tool); err != nil {
-		logger.Errorf(ctx, "Failed to update tool %s for user %s: %v", toolUID, user.ID, err)
-		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected update tool error"))
+	findings, err := c.toolService.SaveToolUpdate(ctx, user.ID, tool)
+	if err != nil {
+		c.Error(ctx, err)
 		return
 	}
 
-	if err := c.cache.Delete(ctx, toolsCacheKey(user.ID)); err != nil {
+	if err := DeleteToolsCache(ctx, c.cache, user.ID); err != nil {
 		logger.Errorf(ctx, "Failed to delete cache for user %s: %v", user.ID, err)
 		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected delete cache error"))
 		return
 	}
 
 	logger.Infof(ctx, "Tool updated successfully for user %s with tool uid %s", user.ID, toolUID)
-	c.Success(ctx, "Tool updated successfully", UpdateToolResponseDto{})
+	c.Success(ctx, "Tool updated successfully", UpdateToolResponseDto{Warnings: toolSecretWarningsFromEntity(findings)})
 }
@@ -41,4 +41,7 @@ func (dto UpdateToolRequestDto) ToEntity(toolUID string) entity.ToolEntity {
 	)
 }
 
-type UpdateToolResponseDto struct{}
+type UpdateToolResponseDto struct {
+	// Warnings lists credentials found in the source, the tool is saved anyway unless the server blocks them
+	Warnings []ToolSecretWarningDto `json:"warnings"`
+}
@@ -29,7 +29,7 @@ type CheckUsernameController struct {
 
 func (c CheckUsernameController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodPost, Path: "/api/v1/user/check", Handler: c.Check},
+		{Method: http.MethodPost, Path: "/api/v1/user/check", Handler: c.Check, DemoModeAllowed: true},
 	}
 }
 
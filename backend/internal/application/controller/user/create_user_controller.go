@@ -14,18 +14,20 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewCreateUserController(config config.Config, userService *service.UserService) router.Controller {
+func NewCreateUserController(config config.Config, userService *service.UserService, settingsService *service.SystemSettingsService) router.Controller {
 	return CreateuserController{
-		config:      config,
-		userService: userService,
+		config:          config,
+		userService:     userService,
+		settingsService: settingsService,
 	}
 }
 
 type CreateuserController struct {
 	common.JsonResponse
 
-	config      config.Config
-	userService *service.UserService
+	config          config.Config
+	userService     *service.UserService
+	settingsService *service.SystemSettingsService
 }
 
 func (c CreateuserController) RouterInfo() []router.RouterInfo {
@@ -45,9 +47,15 @@ func (c CreateuserController) RouterInfo() []router.RouterInfo {
 // @Router			/api/v1/user/create [post]
 func (c *CreateuserController) Create(ctx *gin.Context) {
 	logger.Infof(ctx, "Create User")
-	if !c.config.ENABLE_USER_REGISTRATION {
-		logger.Warnf(ctx, "User registration is disabled in the configuration")
-		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.UserRegistrationIsNotEnabled, "user registration is not enabled, please set env: ENABLE_USER_REGISTRATION"))
+	settings, err := c.settingsService.Settings(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get system settings: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected get system settings error"))
+		return
+	}
+	if !settings.EnableUserRegistration {
+		logger.Warnf(ctx, "User registration is disabled in the system settings")
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.UserRegistrationIsNotEnabled, "user registration is not enabled"))
 		return
 	}
 
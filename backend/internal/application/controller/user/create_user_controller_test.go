@@ -5,10 +5,15 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/service"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -20,10 +25,20 @@ func TestCreateUserController_Create_RegistrationDisabled(t *testing.T) {
 		LogFormat: "text",
 	})
 
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	settingRepo := mockgen.NewMockISystemSettingRepository(ctrl)
+	cacheRepo := mockgen.NewMockICache(ctrl)
+	cacheRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", false, nil)
+	cacheRepo.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	settingRepo.EXPECT().AllSettings(gomock.Any()).Return(nil, nil)
+
+	cfg := config.Config{
+		ENABLE_USER_REGISTRATION: false,
+	}
 	controller := &CreateuserController{
-		config: config.Config{
-			ENABLE_USER_REGISTRATION: false,
-		},
+		config:          cfg,
+		settingsService: service.NewSystemSettingsService(settingRepo, cacheRepo, cfg, fixtures.NewFakeClock(time.Now())),
 	}
 
 	w := httptest.NewRecorder()
@@ -13,10 +13,10 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewDeleteUserController(config config.Config, userService *service.UserService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+func NewDeleteUserController(config config.Config, accountDeletionService *service.AccountDeletionService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
 	return DeleteUserController{
 		config:                     config,
-		userService:                userService,
+		accountDeletionService:     accountDeletionService,
 		accessTokenHeaderValidator: accessTokenHeaderValidator,
 	}
 }
@@ -25,18 +25,19 @@ type DeleteUserController struct {
 	common.JsonResponse
 
 	config                     config.Config
-	userService                *service.UserService
+	accountDeletionService     *service.AccountDeletionService
 	accessTokenHeaderValidator common.AccessTokenHeaderValidator
 }
 
 func (c DeleteUserController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
 		{Method: http.MethodDelete, Path: "/api/v1/user/delete", Handler: c.Delete},
+		{Method: http.MethodPost, Path: "/api/v1/user/delete/cancel", Handler: c.CancelDelete},
 	}
 }
 
 // @Summary		Delete current user
-// @Description	Delete the current authenticated user and all related data
+// @Description	Schedule the deletion of the current authenticated user and all related data after ACCOUNT_DELETION_GRACE_PERIOD, the user can cancel it until then. Without a grace period the user is deleted right away.
 // @Tags			User
 // @Accept			json
 // @Produce		json
@@ -55,11 +56,40 @@ func (c *DeleteUserController) Delete(ctx *gin.Context) {
 
 	logger.Infof(ctx, "Deleting user: %s", user.ID)
 
-	if err := c.userService.DeleteUser(ctx, user.ID); err != nil {
+	deletionScheduledAt, err := c.accountDeletionService.ScheduleDeletion(ctx, user.ID)
+	if err != nil {
 		logger.Errorf(ctx, "Failed to delete user: %v", err)
 		c.Error(ctx, err)
 		return
 	}
 
-	c.Success(ctx, "User deleted successfully", DeleteUserResponseDto{})
+	if deletionScheduledAt == nil {
+		c.Success(ctx, "User deleted successfully", DeleteUserResponseDto{})
+		return
+	}
+	c.Success(ctx, "User deletion scheduled", DeleteUserResponseDto{DeletionScheduledAt: deletionScheduledAt})
+}
+
+// @Summary		Cancel deletion of current user
+// @Description	Cancel the scheduled deletion of the current authenticated user, the account and its data are kept
+// @Tags			User
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[CancelUserDeletionResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/delete/cancel [post]
+func (c *DeleteUserController) CancelDelete(ctx *gin.Context) {
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	if err := c.accountDeletionService.CancelDeletion(ctx, user.ID); err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "User deletion cancelled", CancelUserDeletionResponseDto{})
 }
@@ -1,4 +1,11 @@
 package user
 
+import "time"
+
 type DeleteUserResponseDto struct {
+	// DeletionScheduledAt is when the account is deleted, null when it was deleted right away
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at" example:"2025-01-08T00:00:00Z" extensions:"x-nullable"`
+}
+
+type CancelUserDeletionResponseDto struct {
 }
@@ -0,0 +1,92 @@
+package user
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewMergeUserController(config config.Config, userService *service.UserService, authService *service.AuthService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return MergeUserController{
+		config:                     config,
+		userService:                userService,
+		authService:                authService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type MergeUserController struct {
+	common.JsonResponse
+
+	config                     config.Config
+	userService                *service.UserService
+	authService                *service.AuthService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c MergeUserController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/user/merge", Handler: c.Merge},
+	}
+}
+
+// @Summary		Merge a duplicate account into current user
+// @Description	Move tools, global script, passkeys and SSO bindings of the duplicate account into the current user, then delete the duplicate.
+// @Description	The duplicate is proven by an access token of it, so the user has to log in to both accounts.
+// @Tags			User
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string				true	"Bearer access token of the surviving account"
+// @Param			request			body		MergeUserRequestDto	true	"Access token of the duplicate account"
+// @Success		200				{object}	swagger.BaseSuccessResponse[MergeUserResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/merge [post]
+func (c *MergeUserController) Merge(ctx *gin.Context) {
+	logger.Infof(ctx, "Merge User")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req MergeUserRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	duplicateToken, valid, err := c.authService.ValidateAccessToken(ctx, req.DuplicateAccessToken)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to validate duplicate access token: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+	if !valid {
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InvalidAccessToken, "duplicate access token is invalid"))
+		return
+	}
+
+	logger.Infof(ctx, "Merging user %s into %s", duplicateToken.UserID, user.ID)
+
+	result, err := c.userService.MergeUsers(ctx, user.ID, duplicateToken.UserID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to merge user: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp MergeUserResponseDto
+	resp.FromEntity(result)
+	c.Success(ctx, "User merged successfully", resp)
+}
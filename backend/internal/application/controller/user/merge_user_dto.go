@@ -0,0 +1,33 @@
+package user
+
+import "ya-tool-craft/internal/domain/entity"
+
+type MergeUserRequestDto struct {
+	DuplicateAccessToken string `json:"duplicate_access_token" binding:"required" example:"at-xxxx"`
+}
+
+type MergeUserResponseDto struct {
+	SurvivorUserID    string            `json:"survivor_user_id" example:"u-1"`
+	DuplicateUserID   string            `json:"duplicate_user_id" example:"u-2"`
+	MovedTools        int               `json:"moved_tools" example:"3"`
+	RenamedTools      map[string]string `json:"renamed_tools" example:"json-formatter:json-formatter-merged"`
+	MovedPasskeys     int               `json:"moved_passkeys" example:"1"`
+	MovedSSOBindings  int               `json:"moved_sso_bindings" example:"1"`
+	MovedGlobalScript bool              `json:"moved_global_script" example:"false"`
+	MovedPassword     bool              `json:"moved_password" example:"true"`
+	MovedEmail        bool              `json:"moved_email" example:"false"`
+	DeletedSecrets    int               `json:"deleted_secrets" example:"0"`
+}
+
+func (d *MergeUserResponseDto) FromEntity(result entity.UserMergeResultEntity) {
+	d.SurvivorUserID = string(result.SurvivorID)
+	d.DuplicateUserID = string(result.DuplicateID)
+	d.MovedTools = result.MovedTools
+	d.RenamedTools = result.RenamedTools
+	d.MovedPasskeys = result.MovedPasskeys
+	d.MovedSSOBindings = result.MovedSSOBindings
+	d.MovedGlobalScript = result.MovedGlobalScript
+	d.MovedPassword = result.MovedPassword
+	d.MovedEmail = result.MovedEmail
+	d.DeletedSecrets = result.DeletedSecrets
+}
@@ -0,0 +1,69 @@
+package user
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewUpdatePasswordController(userService *service.UserService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return UpdatePasswordController{
+		userService:                userService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type UpdatePasswordController struct {
+	common.JsonResponse
+
+	userService                *service.UserService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c UpdatePasswordController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPut, Path: "/api/v1/user/password", Handler: c.Update},
+	}
+}
+
+// @Summary		Change password
+// @Description	Change the current user's password, the current password is required when the user has one. Users an admin required to change the password can still call this endpoint. With REVOKE_SESSIONS_ON_PASSWORD_CHANGE every other device is signed out, the current one stays signed in.
+// @Tags			User
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token"
+// @Param			request			body		UpdatePasswordRequestDto	true	"Current and new password"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UpdatePasswordResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		401				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/password [put]
+func (c *UpdatePasswordController) Update(ctx *gin.Context) {
+	logger.Infof(ctx, "Update password")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeaderAllowingPasswordChange(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req UpdatePasswordRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	if err := c.userService.ChangePassword(ctx, user.ID, req.CurrentPassword, req.NewPassword, common.CurrentRefreshTokenHash(ctx)); err != nil {
+		logger.Errorf(ctx, "Failed to update password: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "Password updated successfully", UpdatePasswordResponseDto{})
+}
@@ -0,0 +1,10 @@
+package user
+
+type UpdatePasswordRequestDto struct {
+	// CurrentPassword is required when the user has a password
+	CurrentPassword string `json:"current_password" binding:"max=32" example:"password"`
+	NewPassword     string `json:"new_password" binding:"required,min=8,max=32" example:"new_password"`
+}
+
+type UpdatePasswordResponseDto struct {
+}
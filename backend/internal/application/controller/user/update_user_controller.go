@@ -37,7 +37,7 @@ func (c UpdateUserController) RouterInfo() []router.RouterInfo {
 }
 
 // @Summary		Update user
-// @Description	Update current user's information (username, mail). Only provided fields will be updated.
+// @Description	Update current user's information (username). Only provided fields will be updated. The email is set with POST /api/v1/user/email/verification.
 // @Tags			User
 // @Accept			json
 // @Produce		json
@@ -0,0 +1,94 @@
+package user
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewUserAccountRecoveryController(accountRecoveryService *service.AccountRecoveryService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return UserAccountRecoveryController{
+		accountRecoveryService:     accountRecoveryService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+// UserAccountRecoveryController lets a signed in user spot and cancel the recovery requests of the account.
+type UserAccountRecoveryController struct {
+	common.JsonResponse
+
+	accountRecoveryService     *service.AccountRecoveryService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c UserAccountRecoveryController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/user/recovery-requests", Handler: c.List, DemoModeAllowed: true},
+		{Method: http.MethodPost, Path: "/api/v1/user/recovery-requests/:request_id/cancel", Handler: c.Cancel},
+	}
+}
+
+// @Summary		List account recovery requests
+// @Description	List the recovery requests of the current user newest first
+// @Tags			User
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UserAccountRecoveryRequestsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/recovery-requests [get]
+func (c *UserAccountRecoveryController) List(ctx *gin.Context) {
+	logger.Infof(ctx, "List account recovery requests")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	requests, err := c.accountRecoveryService.ListUserRequests(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list account recovery requests: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp UserAccountRecoveryRequestsResponseDto
+	resp.FromEntity(requests)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Cancel an account recovery request
+// @Description	Cancel a pending or approved recovery request of the current user, the way out when someone else asked for it
+// @Tags			User
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			request_id		path		string	true	"Account recovery request ID"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/recovery-requests/{request_id}/cancel [post]
+func (c *UserAccountRecoveryController) Cancel(ctx *gin.Context) {
+	logger.Infof(ctx, "Cancel account recovery request")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	requestID := ctx.Param("request_id")
+	if err := c.accountRecoveryService.CancelRequest(ctx, user.ID, requestID); err != nil {
+		logger.Errorf(ctx, "Failed to cancel account recovery request %s: %v", requestID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "Account recovery request cancelled successfully", gin.H{})
+}
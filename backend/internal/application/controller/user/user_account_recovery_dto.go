@@ -0,0 +1,29 @@
+package user
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+type UserAccountRecoveryRequestDto struct {
+	ID          string    `json:"id" example:"4f9c2f0e-3a5b-4f7e-9a55-2b0c5e1d7c11"`
+	Status      string    `json:"status" example:"pending" enums:"pending,approved,rejected,cancelled,completed"`
+	CreatedAt   time.Time `json:"created_at" format:"date-time"`
+	AvailableAt time.Time `json:"available_at" format:"date-time"`
+}
+
+type UserAccountRecoveryRequestsResponseDto struct {
+	Requests []UserAccountRecoveryRequestDto `json:"requests"`
+}
+
+func (dto *UserAccountRecoveryRequestsResponseDto) FromEntity(requests []entity.AccountRecoveryRequestEntity) {
+	dto.Requests = make([]UserAccountRecoveryRequestDto, 0, len(requests))
+	for _, request := range requests {
+		dto.Requests = append(dto.Requests, UserAccountRecoveryRequestDto{
+			ID:          request.ID,
+			Status:      string(request.Status),
+			CreatedAt:   request.CreatedAt,
+			AvailableAt: request.AvailableAt,
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewUserDataExportController(
+	userDataExportService *service.UserDataExportService,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+	cfg config.Config,
+) router.Controller {
+	return UserDataExportController{
+		userDataExportService:      userDataExportService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+		config:                     cfg,
+	}
+}
+
+// UserDataExportController lets users request an archive of all their data, poll its progress and download it
+type UserDataExportController struct {
+	common.JsonResponse
+
+	userDataExportService      *service.UserDataExportService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+	config                     config.Config
+}
+
+func (c UserDataExportController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/user/data-exports", Handler: c.Request},
+		{Method: http.MethodGet, Path: "/api/v1/user/data-exports/:export_id", Handler: c.Get, DemoModeAllowed: true},
+		{Method: http.MethodGet, Path: "/api/v1/user/data-exports/:export_id/download", Handler: c.Download, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Request a data export
+// @Description	Starts building an archive of everything kept about the current user in the background: the profile, the SSO bindings,
+// @Description	the tools, the global script, the passkeys and the audit records. Poll the export until its status is done, then download it.
+// @Tags			User
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UserDataExportDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/data-exports [post]
+func (c *UserDataExportController) Request(ctx *gin.Context) {
+	logger.Infof(ctx, "Request data export")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	export, err := c.userDataExportService.RequestExport(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to request data export: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp UserDataExportDto
+	resp.FromEntity(export, c.retention())
+	c.Success(ctx, "Data export requested", resp)
+}
+
+// @Summary		Get a data export
+// @Description	Returns the status and progress of a data export of the current user
+// @Tags			User
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			export_id		path		string	true	"Data export id"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UserDataExportDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/data-exports/{export_id} [get]
+func (c *UserDataExportController) Get(ctx *gin.Context) {
+	logger.Infof(ctx, "Get data export")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	export, err := c.userDataExportService.GetExport(ctx, user.ID, ctx.Param("export_id"))
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp UserDataExportDto
+	resp.FromEntity(export, c.retention())
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Download a data export
+// @Description	Downloads the archive of a done data export of the current user as a JSON file
+// @Tags			User
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			export_id		path		string	true	"Data export id"
+// @Success		200				{object}	UserDataArchiveDto
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/data-exports/{export_id}/download [get]
+func (c *UserDataExportController) Download(ctx *gin.Context) {
+	logger.Infof(ctx, "Download data export")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	archive, err := c.userDataExportService.DownloadExport(ctx, user.ID, ctx.Param("export_id"))
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp UserDataArchiveDto
+	resp.FromEntity(archive)
+	content, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		logger.Errorf(ctx, "Failed to encode data export archive: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected data export error"))
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="toolbake-data-%s.json"`, archive.ExportedAt.Format("20060102T150405Z")))
+	ctx.Data(http.StatusOK, "application/json", content)
+}
+
+func (c *UserDataExportController) retention() time.Duration {
+	return time.Duration(c.config.UserDataExportRetentionHours) * time.Hour
+}
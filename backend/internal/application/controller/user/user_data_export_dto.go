@@ -0,0 +1,173 @@
+package user
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type UserDataExportDto struct {
+	ID     string `json:"id" example:"export-xxxx"`
+	Status string `json:"status" enums:"pending,running,done,failed" example:"running"`
+	// Progress counts the finished steps of the Total steps building the archive
+	Progress int `json:"progress" example:"3"`
+	Total    int `json:"total" example:"6"`
+	// Error tells why a failed export failed, empty otherwise
+	Error     string    `json:"error" example:""`
+	CreatedAt time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt time.Time `json:"updated_at" format:"date-time"`
+	// FinishedAt is null until the export is done or failed
+	FinishedAt *time.Time `json:"finished_at" format:"date-time" extensions:"x-nullable"`
+	// ExpiresAt is when a finished export is deleted, null until it finished
+	ExpiresAt *time.Time `json:"expires_at" format:"date-time" extensions:"x-nullable"`
+}
+
+func (dto *UserDataExportDto) FromEntity(export entity.UserDataExportEntity, retention time.Duration) {
+	dto.ID = export.ID
+	dto.Status = string(export.Status)
+	dto.Progress = export.Progress
+	dto.Total = export.Total
+	dto.Error = export.Error
+	dto.CreatedAt = export.CreatedAt
+	dto.UpdatedAt = export.UpdatedAt
+	dto.FinishedAt = export.FinishedAt
+	if export.FinishedAt != nil {
+		expiresAt := export.FinishedAt.Add(retention)
+		dto.ExpiresAt = &expiresAt
+	}
+}
+
+// UserDataArchiveDto is the downloaded archive of everything kept about a user. It leaves out the password hash,
+// the tool secrets and the public keys of the passkeys.
+type UserDataArchiveDto struct {
+	ExportedAt   time.Time               `json:"exported_at" format:"date-time"`
+	Profile      UserDataProfileDto      `json:"profile"`
+	SSOBindings  []UserDataSSOBindingDto `json:"sso_bindings"`
+	Tools        []UserDataToolDto       `json:"tools"`
+	GlobalScript *string                 `json:"global_script" extensions:"x-nullable" example:"// global script"`
+	Passkeys     []UserDataPasskeyDto    `json:"passkeys"`
+	AuditLogs    []UserDataAuditLogDto   `json:"audit_logs"`
+}
+
+type UserDataProfileDto struct {
+	ID          string     `json:"id" example:"u-xxxx"`
+	Name        string     `json:"name" example:"alice"`
+	Mail        *string    `json:"mail" extensions:"x-nullable" example:"alice@example.com"`
+	Roles       []string   `json:"roles" example:"user"`
+	HasPassword bool       `json:"has_password" example:"true"`
+	LastLoginAt *time.Time `json:"last_login_at" format:"date-time" extensions:"x-nullable"`
+	LoginCount  int        `json:"login_count" example:"12"`
+}
+
+type UserDataSSOBindingDto struct {
+	Provider         string    `json:"provider" example:"github"`
+	ProviderUserID   string    `json:"provider_user_id" example:"12345"`
+	ProviderUsername *string   `json:"provider_username" extensions:"x-nullable" example:"alice"`
+	ProviderEmail    *string   `json:"provider_email" extensions:"x-nullable" example:"alice@example.com"`
+	CreatedAt        time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt        time.Time `json:"updated_at" format:"date-time"`
+}
+
+type UserDataToolDto struct {
+	UniqueID          string            `json:"uid" example:"tool-xxxx"`
+	ToolID            string            `json:"tool_id" example:"tool-123"`
+	Name              string            `json:"name" example:"Sample Tool"`
+	Namespace         string            `json:"namespace" example:"default"`
+	Category          string            `json:"category" example:"analytics"`
+	IsActivate        bool              `json:"is_activate" example:"true"`
+	IsArchived        bool              `json:"is_archived" example:"false"`
+	RealtimeExecution bool              `json:"realtime_execution" example:"false"`
+	UiWidgets         string            `json:"ui_widgets" example:"[]"`
+	Source            string            `json:"source" example:"// source code"`
+	Description       string            `json:"description" example:"Simple tool"`
+	ExtraInfo         map[string]string `json:"extra_info" example:"{\"key\":\"value\"}"`
+	CreatedAt         time.Time         `json:"created_at" format:"date-time"`
+	UpdatedAt         time.Time         `json:"updated_at" format:"date-time"`
+}
+
+type UserDataPasskeyDto struct {
+	ID             int64      `json:"id" example:"1"`
+	DeviceName     *string    `json:"device_name" extensions:"x-nullable" example:"MacBook"`
+	AAGUID         string     `json:"aaguid" example:"adce000235bcc60a648b0b25f1f05503"`
+	Transports     *string    `json:"transports" extensions:"x-nullable" example:"internal,hybrid"`
+	BackupEligible *bool      `json:"backup_eligible" extensions:"x-nullable" example:"true"`
+	BackupState    *bool      `json:"backup_state" extensions:"x-nullable" example:"true"`
+	CreatedAt      time.Time  `json:"created_at" format:"date-time"`
+	LastUsedAt     *time.Time `json:"last_used_at" format:"date-time" extensions:"x-nullable"`
+}
+
+type UserDataAuditLogDto struct {
+	Method    string    `json:"method" example:"POST"`
+	Route     string    `json:"route" example:"/api/v1/admin/settings/:key"`
+	Path      string    `json:"path" example:"/api/v1/admin/settings/theme"`
+	Status    int       `json:"status" example:"200"`
+	Outcome   string    `json:"outcome" example:"success"`
+	ClientIP  string    `json:"client_ip" example:"192.0.2.1"`
+	CreatedAt time.Time `json:"created_at" format:"date-time"`
+}
+
+func (dto *UserDataArchiveDto) FromEntity(archive entity.UserDataArchiveEntity) {
+	dto.ExportedAt = archive.ExportedAt
+	dto.Profile = UserDataProfileDto{
+		ID:          string(archive.Profile.ID),
+		Name:        archive.Profile.Name,
+		Mail:        archive.Profile.Mail,
+		Roles:       archive.Profile.Roles,
+		HasPassword: archive.Profile.HasPassword,
+		LastLoginAt: archive.Profile.LastLoginAt,
+		LoginCount:  archive.Profile.LoginCount,
+	}
+	dto.SSOBindings = lo.Map(archive.SSOBindings, func(binding entity.UserSSOEntity, _ int) UserDataSSOBindingDto {
+		return UserDataSSOBindingDto{
+			Provider:         binding.Provider,
+			ProviderUserID:   binding.ProviderUserID,
+			ProviderUsername: binding.ProviderUsername,
+			ProviderEmail:    binding.ProviderEmail,
+			CreatedAt:        binding.CreatedAt,
+			UpdatedAt:        binding.UpdatedAt,
+		}
+	})
+	dto.Tools = lo.Map(archive.Tools, func(tool entity.ToolEntity, _ int) UserDataToolDto {
+		return UserDataToolDto{
+			UniqueID:          tool.UniqueID,
+			ToolID:            tool.ID,
+			Name:              tool.Name,
+			Namespace:         tool.Namespace,
+			Category:          tool.Category,
+			IsActivate:        tool.IsActivate,
+			IsArchived:        tool.IsArchived,
+			RealtimeExecution: tool.RealtimeExecution,
+			UiWidgets:         tool.UiWidgets,
+			Source:            tool.Source,
+			Description:       tool.Description,
+			ExtraInfo:         tool.ExtraInfo,
+			CreatedAt:         tool.CreatedAt,
+			UpdatedAt:         tool.UpdatedAt,
+		}
+	})
+	dto.GlobalScript = archive.GlobalScript
+	dto.Passkeys = lo.Map(archive.Passkeys, func(passkey entity.UserDataPasskeyEntity, _ int) UserDataPasskeyDto {
+		return UserDataPasskeyDto{
+			ID:             passkey.ID,
+			DeviceName:     passkey.DeviceName,
+			AAGUID:         passkey.AAGUID,
+			Transports:     passkey.Transports,
+			BackupEligible: passkey.BackupEligible,
+			BackupState:    passkey.BackupState,
+			CreatedAt:      passkey.CreatedAt,
+			LastUsedAt:     passkey.LastUsedAt,
+		}
+	})
+	dto.AuditLogs = lo.Map(archive.AuditLogs, func(auditLog entity.AuditLogEntity, _ int) UserDataAuditLogDto {
+		return UserDataAuditLogDto{
+			Method:    auditLog.Method,
+			Route:     auditLog.Route,
+			Path:      auditLog.Path,
+			Status:    auditLog.Status,
+			Outcome:   string(auditLog.Outcome),
+			ClientIP:  auditLog.ClientIP,
+			CreatedAt: auditLog.CreatedAt,
+		}
+	})
+}
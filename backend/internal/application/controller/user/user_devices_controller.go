@@ -0,0 +1,92 @@
+package user
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewUserDevicesController(syncService *service.SyncService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return UserDevicesController{
+		syncService:                syncService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type UserDevicesController struct {
+	common.JsonResponse
+
+	syncService                *service.SyncService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c UserDevicesController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/user/devices", Handler: c.AllDevices, DemoModeAllowed: true},
+		{Method: http.MethodDelete, Path: "/api/v1/user/devices/:device_id", Handler: c.Delete},
+	}
+}
+
+// @Summary		List devices
+// @Description	List the devices of the current user, every login registers one
+// @Tags			User
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UserDevicesResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/devices [get]
+func (c *UserDevicesController) AllDevices(ctx *gin.Context) {
+	logger.Infof(ctx, "List devices")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	devices, err := c.syncService.Devices(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get devices: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp UserDevicesResponseDto
+	resp.FromEntity(devices)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Delete device
+// @Description	Forget a device of the current user, it has to log in again to sync tools
+// @Tags			User
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			device_id		path		string	true	"Device id"
+// @Success		200				{object}	swagger.BaseSuccessResponse[DeleteUserDeviceResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		404				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/devices/{device_id} [delete]
+func (c *UserDevicesController) Delete(ctx *gin.Context) {
+	logger.Infof(ctx, "Delete device")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	deviceID := ctx.Param("device_id")
+	if err := c.syncService.DeleteDevice(ctx, user.ID, deviceID); err != nil {
+		logger.Errorf(ctx, "Failed to delete device %s: %v", deviceID, err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "Device deleted successfully", DeleteUserDeviceResponseDto{})
+}
@@ -0,0 +1,35 @@
+package user
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type UserDeviceDto struct {
+	ID         string    `json:"id" example:"d-xxxx"`
+	Name       string    `json:"name" example:"Mozilla/5.0"`
+	SyncCursor int64     `json:"sync_cursor" example:"42"`
+	CreatedAt  time.Time `json:"created_at" format:"date-time"`
+	LastSeenAt time.Time `json:"last_seen_at" format:"date-time"`
+}
+
+type UserDevicesResponseDto struct {
+	Devices []UserDeviceDto `json:"devices"`
+}
+
+func (dto *UserDevicesResponseDto) FromEntity(devices []entity.UserDeviceEntity) {
+	dto.Devices = lo.Map(devices, func(device entity.UserDeviceEntity, _ int) UserDeviceDto {
+		return UserDeviceDto{
+			ID:         device.ID,
+			Name:       device.Name,
+			SyncCursor: device.SyncCursor,
+			CreatedAt:  device.CreatedAt,
+			LastSeenAt: device.LastSeenAt,
+		}
+	})
+}
+
+type DeleteUserDeviceResponseDto struct {
+}
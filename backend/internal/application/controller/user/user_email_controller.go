@@ -0,0 +1,138 @@
+package user
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewUserEmailController(userEmailService *service.UserEmailService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return UserEmailController{
+		userEmailService:           userEmailService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+// UserEmailController lets a signed in user set the email of the account by verifying a code sent to it, or remove it.
+type UserEmailController struct {
+	common.JsonResponse
+
+	userEmailService           *service.UserEmailService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c UserEmailController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodPost, Path: "/api/v1/user/email/verification", Handler: c.StartVerification},
+		{Method: http.MethodPost, Path: "/api/v1/user/email/verify", Handler: c.Verify},
+		{Method: http.MethodDelete, Path: "/api/v1/user/email", Handler: c.Remove},
+	}
+}
+
+// @Summary		Send an email verification code
+// @Description	Email a verification code to the new email of the current user, it expires after 15 minutes. The email of the
+// @Description	account does not change until the code is verified. A new code can be requested once a minute, it drops the code sent before.
+// @Tags			User
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string							true	"Bearer access token"
+// @Param			request			body		UserEmailVerificationRequestDto	true	"New email"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Failure		429				{object}	swagger.BaseFailResponse
+// @Failure		503				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/email/verification [post]
+func (c *UserEmailController) StartVerification(ctx *gin.Context) {
+	logger.Infof(ctx, "Start email verification")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req UserEmailVerificationRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	if err := c.userEmailService.StartVerification(ctx, user.ID, req.Email); err != nil {
+		logger.Errorf(ctx, "Failed to start email verification: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "A verification code was sent to the email", gin.H{})
+}
+
+// @Summary		Verify the email
+// @Description	Check the emailed code and make the email the email of the current user. It can then be used to log in, reset
+// @Description	the password and recover the account. The previous email is told about the change.
+// @Tags			User
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string						true	"Bearer access token"
+// @Param			request			body		UserEmailVerifyRequestDto	true	"Verification code"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UserEmailResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Failure		409				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/email/verify [post]
+func (c *UserEmailController) Verify(ctx *gin.Context) {
+	logger.Infof(ctx, "Verify email")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req UserEmailVerifyRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	email, err := c.userEmailService.Verify(ctx, user.ID, req.Code)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to verify email: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "Email verified successfully", UserEmailResponseDto{Email: email})
+}
+
+// @Summary		Remove the email
+// @Description	Remove the email of the current user, the account can no longer log in with it, reset its password or be recovered.
+// @Tags			User
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[any]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/email [delete]
+func (c *UserEmailController) Remove(ctx *gin.Context) {
+	logger.Infof(ctx, "Remove email")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	if err := c.userEmailService.Remove(ctx, user.ID); err != nil {
+		logger.Errorf(ctx, "Failed to remove email: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	c.Success(ctx, "Email removed successfully", gin.H{})
+}
@@ -0,0 +1,13 @@
+package user
+
+type UserEmailVerificationRequestDto struct {
+	Email string `json:"email" binding:"required,email,max=255" example:"user@example.com"`
+}
+
+type UserEmailVerifyRequestDto struct {
+	Code string `json:"code" binding:"required,max=16" example:"123456"`
+}
+
+type UserEmailResponseDto struct {
+	Email string `json:"email" example:"user@example.com"`
+}
@@ -8,16 +8,24 @@ import (
 	"ya-tool-craft/internal/core/router"
 	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
 
 	_ "ya-tool-craft/internal/swagger"
 
 	"github.com/gin-gonic/gin"
 )
 
-func NewUserInfoController(config config.Config, authService *service.AuthService, userRepository repository.IUserRepository, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+func NewUserInfoController(
+	config config.Config,
+	authService *service.AuthService,
+	userSettingsService *service.UserSettingsService,
+	userRepository repository.IUserRepository,
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator,
+) router.Controller {
 	return UserInfoController{
 		config:                     config,
 		authService:                authService,
+		userSettingsService:        userSettingsService,
 		userRepository:             userRepository,
 		accessTokenHeaderValidator: accessTokenHeaderValidator,
 	}
@@ -29,17 +37,18 @@ type UserInfoController struct {
 	config                     config.Config
 	accessTokenHeaderValidator common.AccessTokenHeaderValidator
 	authService                *service.AuthService
+	userSettingsService        *service.UserSettingsService
 	userRepository             repository.IUserRepository
 }
 
 func (c UserInfoController) RouterInfo() []router.RouterInfo {
 	return []router.RouterInfo{
-		{Method: http.MethodGet, Path: "/api/v1/user", Handler: c.Handler},
+		{Method: http.MethodGet, Path: "/api/v1/user", Handler: c.Handler, DemoModeAllowed: true},
 	}
 }
 
 // @Summary		Get current user info
-// @Description	Fetch user information based on the supplied access token
+// @Description	Fetch user information based on the supplied access token, with the effective settings of the user
 // @Tags			User
 // @Accept			json
 // @Produce		json
@@ -56,9 +65,16 @@ func (c *UserInfoController) Handler(ctx *gin.Context) {
 		return
 	}
 
+	settings, err := c.userSettingsService.Settings(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get user settings: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected get user settings error"))
+		return
+	}
+
 	logger.Infof(ctx, "User retrieved successfully: %s", user.ID)
 	respDto := UserInfoResponseDto{}
-	respDto.FromEntity(user)
+	respDto.FromEntity(user, settings)
 
 	c.Success(ctx, "User retrieved successfully", respDto)
 }
@@ -1,15 +1,33 @@
 package user
 
-import "ya-tool-craft/internal/domain/entity"
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
 
 type UserInfoResponseDto struct {
 	ID   string  `json:"id" example:"user_id_a"`
 	Name string  `json:"name" example:"username"`
 	Mail *string `json:"mail,omitempty" example:"user@example.com"`
+	// LastLoginAt is omitted until the first login
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" example:"2025-01-01T00:00:00Z"`
+	LoginCount  int        `json:"login_count" example:"12"`
+	// DeletionScheduledAt is when the account is deleted, omitted unless the user asked to delete it
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" example:"2025-01-08T00:00:00Z"`
+	// Settings are the effective preferences of the user by key, see GET /api/v1/user/settings
+	Settings map[string]string `json:"settings" example:"theme:dark,editor.font_size:14"`
 }
 
-func (c *UserInfoResponseDto) FromEntity(user entity.UserEntity) {
+func (c *UserInfoResponseDto) FromEntity(user entity.UserEntity, settings []entity.UserSettingDetailEntity) {
 	c.ID = string(user.ID)
 	c.Name = user.Name
 	c.Mail = user.Mail
+	c.LastLoginAt = user.LastLoginAt
+	c.LoginCount = user.LoginCount
+	c.DeletionScheduledAt = user.DeletionScheduledAt
+	c.Settings = lo.SliceToMap(settings, func(setting entity.UserSettingDetailEntity) (string, string) {
+		return setting.Key, setting.Value
+	})
 }
@@ -0,0 +1,101 @@
+package user
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewUserSettingsController(userSettingsService *service.UserSettingsService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return UserSettingsController{
+		userSettingsService:        userSettingsService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type UserSettingsController struct {
+	common.JsonResponse
+
+	userSettingsService        *service.UserSettingsService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c UserSettingsController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/user/settings", Handler: c.Settings, DemoModeAllowed: true},
+		{Method: http.MethodPut, Path: "/api/v1/user/settings", Handler: c.UpdateSettings},
+	}
+}
+
+// @Summary		Get settings
+// @Description	Get the preferences of the current user, like the theme and the editor options, with their defaults
+// @Tags			User
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UserSettingsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/settings [get]
+func (c *UserSettingsController) Settings(ctx *gin.Context) {
+	logger.Infof(ctx, "Get user settings")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	settings, err := c.userSettingsService.Settings(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get user settings: %v", err)
+		c.Error(ctx, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected get user settings error"))
+		return
+	}
+
+	var resp UserSettingsResponseDto
+	resp.FromEntity(settings)
+	c.Success(ctx, "", resp)
+}
+
+// @Summary		Update settings
+// @Description	Set several preferences of the current user at once. An unknown key or invalid value rejects the whole update
+// @Tags			User
+// @Accept			json
+// @Produce		json
+// @Param			Authorization	header		string							true	"Bearer access token"
+// @Param			request			body		UpdateUserSettingsRequestDto	true	"New values"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UserSettingsResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/settings [put]
+func (c *UserSettingsController) UpdateSettings(ctx *gin.Context) {
+	logger.Infof(ctx, "Update user settings")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req UpdateUserSettingsRequestDto
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	settings, err := c.userSettingsService.UpdateSettings(ctx, user.ID, req.Settings)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to update user settings: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp UserSettingsResponseDto
+	resp.FromEntity(settings)
+	c.Success(ctx, "User settings updated successfully", resp)
+}
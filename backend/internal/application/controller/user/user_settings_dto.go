@@ -0,0 +1,39 @@
+package user
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/samber/lo"
+)
+
+type UserSettingDto struct {
+	Key          string `json:"key" example:"theme"`
+	Type         string `json:"type" enums:"bool,int,string" example:"string"`
+	Value        string `json:"value" example:"dark"`
+	DefaultValue string `json:"default_value" example:"light"`
+	// UpdatedAt is null when the user did not set the setting
+	UpdatedAt *time.Time `json:"updated_at" extensions:"x-nullable"`
+}
+
+type UserSettingsResponseDto struct {
+	Settings []UserSettingDto `json:"settings"`
+}
+
+func (dto *UserSettingsResponseDto) FromEntity(settings []entity.UserSettingDetailEntity) {
+	dto.Settings = lo.Map(settings, func(setting entity.UserSettingDetailEntity, _ int) UserSettingDto {
+		return UserSettingDto{
+			Key:          setting.Key,
+			Type:         string(setting.Type),
+			Value:        setting.Value,
+			DefaultValue: setting.DefaultValue,
+			UpdatedAt:    setting.UpdatedAt,
+		}
+	})
+}
+
+type UpdateUserSettingsRequestDto struct {
+	// Settings maps setting keys to their new value as a string, booleans are "true"/"false" and integers are
+	// decimal. A null value resets the setting to its default, settings not listed are left as they are.
+	Settings map[string]*string `json:"settings" binding:"required" example:"theme:dark,editor.font_size:16"`
+}
@@ -0,0 +1,70 @@
+package user
+
+import (
+	"net/http"
+	"ya-tool-craft/internal/application/controller/common"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/error_code"
+
+	_ "ya-tool-craft/internal/swagger"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NewUserUsageController(meteringService *service.MeteringService, accessTokenHeaderValidator common.AccessTokenHeaderValidator) router.Controller {
+	return UserUsageController{
+		meteringService:            meteringService,
+		accessTokenHeaderValidator: accessTokenHeaderValidator,
+	}
+}
+
+type UserUsageController struct {
+	common.JsonResponse
+
+	meteringService            *service.MeteringService
+	accessTokenHeaderValidator common.AccessTokenHeaderValidator
+}
+
+func (c UserUsageController) RouterInfo() []router.RouterInfo {
+	return []router.RouterInfo{
+		{Method: http.MethodGet, Path: "/api/v1/user/usage", Handler: c.Usage, DemoModeAllowed: true},
+	}
+}
+
+// @Summary		Get usage
+// @Description	Get the metered usage of the current user in a month, it stays empty unless metering is enabled
+// @Tags			User
+// @Produce		json
+// @Param			Authorization	header		string	true	"Bearer access token"
+// @Param			period			query		string	false	"Month like 2026-01, the current month when empty"
+// @Success		200				{object}	swagger.BaseSuccessResponse[UserUsageResponseDto]
+// @Failure		400				{object}	swagger.BaseFailResponse
+// @Router			/api/v1/user/usage [get]
+func (c *UserUsageController) Usage(ctx *gin.Context) {
+	logger.Infof(ctx, "Get usage")
+
+	user, err := c.accessTokenHeaderValidator.ValidateAccessTokenHeader(ctx)
+	if err != nil {
+		c.Error(ctx, err)
+		return
+	}
+
+	var req UserUsageRequestDto
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.Error(ctx, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error()))
+		return
+	}
+
+	period, usages, err := c.meteringService.UserUsage(ctx, user.ID, req.Period)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get usage: %v", err)
+		c.Error(ctx, err)
+		return
+	}
+
+	var resp UserUsageResponseDto
+	resp.FromEntity(period, usages)
+	c.Success(ctx, "", resp)
+}
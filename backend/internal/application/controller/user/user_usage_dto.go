@@ -0,0 +1,21 @@
+package user
+
+import "ya-tool-craft/internal/domain/entity"
+
+type UserUsageRequestDto struct {
+	Period string `form:"period" binding:"omitempty,len=7" example:"2026-01"`
+}
+
+type UserUsageResponseDto struct {
+	Period string `json:"period" example:"2026-01"`
+	// Usage maps a metric to the amount used in the period, metrics without usage are missing
+	Usage map[string]int64 `json:"usage" example:"api_calls:120,storage_bytes:4096"`
+}
+
+func (dto *UserUsageResponseDto) FromEntity(period string, usages []entity.UsageEntity) {
+	dto.Period = period
+	dto.Usage = map[string]int64{}
+	for _, usage := range usages {
+		dto.Usage[string(usage.Metric)] = usage.Amount
+	}
+}
@@ -1,10 +1,17 @@
 package application
 
 import (
+	"ya-tool-craft/internal/application/controller/admin"
+	"ya-tool-craft/internal/application/controller/announcement"
 	"ya-tool-craft/internal/application/controller/auth"
 	"ya-tool-craft/internal/application/controller/frontend_assets_host"
 	"ya-tool-craft/internal/application/controller/global_script"
+	"ya-tool-craft/internal/application/controller/graphql_api"
 	"ya-tool-craft/internal/application/controller/healthcheck"
+	"ya-tool-craft/internal/application/controller/metrics"
+	"ya-tool-craft/internal/application/controller/oidc"
+	"ya-tool-craft/internal/application/controller/openapi"
+	"ya-tool-craft/internal/application/controller/tool_secret"
 	"ya-tool-craft/internal/application/controller/tools"
 	"ya-tool-craft/internal/application/controller/user"
 )
@@ -12,6 +19,11 @@ import (
 func ControllerFactories() []any {
 	return []any{
 		healthcheck.NewHealthCheckController,
+		healthcheck.NewReadinessController,
+		healthcheck.NewStatusController,
+		healthcheck.NewClientCompatibilityController,
+		healthcheck.NewCapabilitiesController,
+		metrics.NewMetricsController,
 		auth.NewAuthLoginController,
 		auth.NewAuthIssueAccessTokenController,
 		auth.NewAuthLogoutController,
@@ -24,24 +36,84 @@ func ControllerFactories() []any {
 		auth.NewPasskeyLoginChallengeController,
 		auth.NewPasskeyLoginVerifyController,
 		auth.NewPasskeyGetController,
+		auth.NewPasskeyUpdateController,
 		auth.NewPasskeyDeleteController,
+		auth.NewSessionsGetController,
+		auth.NewSessionDeleteController,
 		auth.NewTwoFAGetController,
 		auth.NewTwoFADeleteController,
 		auth.NewTwoFARetrieveTOTPController,
+		auth.NewTwoFARetrieveTOTPQRCodeController,
 		auth.NewTwoFATOTPAddController,
 		auth.NewTwoFALoginController,
+		auth.NewTwoFAMethodsController,
+		auth.NewTwoFAWebAuthnChallengeController,
+		auth.NewTwoFAWebAuthnAddController,
+		auth.NewTwoFAPreferredController,
 		auth.NewTwoFARecoveryController,
+		auth.NewTwoFARecoveryCodesGetController,
+		auth.NewTwoFARecoveryCodesRegenerateController,
+		auth.NewAccountRecoveryController,
+		auth.NewPasswordResetController,
 		user.NewCreateUserController,
 		user.NewUserInfoController,
 		user.NewUpdateUserController,
+		user.NewUpdatePasswordController,
+		user.NewUserEmailController,
 		user.NewDeleteUserController,
 		user.NewCheckUsernameController,
+		user.NewMergeUserController,
+		user.NewUserDevicesController,
+		user.NewUserDataExportController,
+		user.NewUserUsageController,
+		user.NewUserSettingsController,
+		user.NewUserAccountRecoveryController,
 		global_script.NewGetGlobalScriptController,
 		global_script.NewUpdateGlobalScriptController,
 		tools.NewAllToolsController,
 		tools.NewCreateToolController,
 		tools.NewUpdateToolController,
+		tools.NewMergeToolController,
 		tools.NewDeleteToolController,
+		tools.NewArchiveToolController,
+		tools.NewToolVersionsController,
+		tools.NewToolEventsController,
+		tools.NewToolSchemaController,
+		tools.NewExecuteToolController,
+		tools.NewToolSchedulesController,
+		tools.NewInvokeToolController,
+		tools.NewToolCategoriesController,
+		tools.NewToolTagsController,
+		tools.NewUpdateToolCategoryController,
+		tools.NewToolNamespacesController,
+		tools.NewToolSharesController,
+		tools.NewSharedToolsController,
+		tools.NewPublishToolController,
+		tools.NewGalleryController,
+		tools.NewToolBundleController,
+		tools.NewFilterToolsController,
+		tools.NewSearchToolsController,
+		tools.NewSyncToolsController,
+		tools.NewGithubImportController,
+		tool_secret.NewToolSecretsController,
+		graphql_api.NewGraphQLController,
+		admin.NewAdminSettingsController,
+		admin.NewAdminAnnouncementsController,
+		admin.NewAdminAuditLogsController,
+		admin.NewAdminSystemInfoController,
+		admin.NewAdminUsersMergeController,
+		admin.NewAdminUserSecurityController,
+		admin.NewAdminUsersController,
+		admin.NewAdminAccountRecoveryController,
+		admin.NewAdminScheduledJobsController,
+		admin.NewAdminBackupsController,
+		admin.NewAdminOidcClientsController,
+		admin.NewAdminUsageController,
+		announcement.NewAnnouncementsController,
+		oidc.NewOidcProviderController,
+		oidc.NewOidcConsentController,
+		openapi.NewOpenAPIController,
+		openapi.NewToolsOpenAPIController,
 		frontend_assets_host.NewFrontendAssetsHostController,
 	}
 }
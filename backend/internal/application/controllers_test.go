@@ -0,0 +1,80 @@
+package application
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"testing"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/router"
+
+	"github.com/stretchr/testify/require"
+)
+
+// demoModeWriteRoutes are the routes other than GET a demo visitor still needs, they start or end a session or
+// store nothing. The SSO login signs in existing accounts only, the auth service refuses to create one in demo mode.
+var demoModeWriteRoutes = []string{
+	"POST /api/v1/auth/login",
+	"POST /api/v1/auth/access-token",
+	"POST /api/v1/auth/logout",
+	"POST /api/v1/auth/sso/:provider",
+	"POST /api/v1/auth/2fa/login",
+	"POST /api/v1/auth/2fa/methods",
+	"POST /api/v1/auth/2fa/webauthn/challenge",
+	"POST /api/v1/auth/passkey/login/challenge",
+	"POST /api/v1/auth/passkey/login/verify",
+	"POST /api/v1/user/check",
+	"POST /api/v1/tools/sync/ack",
+	"POST /api/v1/tools/:tool_uid/schema/validate",
+	"POST /api/v1/oidc/authorize/:request_id",
+	"POST /api/v1/oidc/token",
+	"POST /api/v1/oidc/userinfo",
+}
+
+// demoModeBlockedReadRoutes are the GET routes that store something, so demo mode rejects them
+var demoModeBlockedReadRoutes = []string{
+	// generates the pending TOTP secret of the user
+	"GET /api/v1/auth/2fa/totp",
+}
+
+// registeredRoutes returns the routes of every controller. The controllers are built with zero dependencies, their
+// constructors only keep them.
+func registeredRoutes(t *testing.T) map[string]router.RouterInfo {
+	t.Helper()
+	routes := map[string]router.RouterInfo{}
+	for _, factory := range ControllerFactories() {
+		constructor := reflect.ValueOf(factory)
+		args := make([]reflect.Value, constructor.Type().NumIn())
+		for i := range args {
+			args[i] = reflect.Zero(constructor.Type().In(i))
+		}
+		controller, ok := constructor.Call(args)[0].Interface().(router.Controller)
+		require.True(t, ok, "%s does not build a controller", constructor.Type())
+		for _, route := range controller.RouterInfo() {
+			routes[route.Method+" "+route.Path] = route
+		}
+	}
+	return routes
+}
+
+func TestControllerRoutes_DemoMode(t *testing.T) {
+	logger.InitLogger(config.Config{})
+
+	routes := registeredRoutes(t)
+	require.NotEmpty(t, routes)
+	for _, key := range append(append([]string{}, demoModeWriteRoutes...), demoModeBlockedReadRoutes...) {
+		require.Contains(t, routes, key, "listed route is not registered")
+	}
+
+	for key, route := range routes {
+		switch {
+		case route.Method == http.MethodGet:
+			// a reading route left closed breaks the demo, one that stores something has to be listed
+			require.Equal(t, !slices.Contains(demoModeBlockedReadRoutes, key), route.DemoModeAllowed, "%s", key)
+		default:
+			// a new write route is closed in demo mode unless it is listed here
+			require.Equal(t, slices.Contains(demoModeWriteRoutes, key), route.DemoModeAllowed, "%s", key)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
 	"strings"
 	"ya-tool-craft/internal/utils"
@@ -10,6 +11,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 )
 
 type Config struct {
@@ -17,21 +19,49 @@ type Config struct {
 
 	Host string `env:"HOST" envDefault:"0.0.0.0:8080"`
 
-	DBType     string `env:"DB_TYPE" envDefault:"sqlite" validate:"oneof=sqlite mysql"` // supports: sqlite, mysql
-	DuckDBPath string `env:"DUCKDB_PATH" envDefault:"data/duckdb.db"`                   // also support "memory" for in-memory db
-	SqlitePath string `env:"SQLLITE_PATH" envDefault:"data/sqlite.db"`                  // also support "memory" for in-memory db
+	// stateless keeps every piece of state in external services, so instances can be started and dropped freely
+	DeploymentProfile string `env:"DEPLOYMENT_PROFILE" envDefault:"default" validate:"oneof=default stateless"` // supports: default, stateless
+	// serverless serves AWS Lambda invocations instead of listening on HOST, a Lambda instance has no persistent
+	// disk so it requires the stateless profile
+	Serverless bool `env:"SERVERLESS" envDefault:"false"`
+
+	DBType     string `env:"DB_TYPE" envDefault:"sqlite" validate:"oneof=sqlite mysql postgres"` // supports: sqlite, mysql, postgres
+	DuckDBPath string `env:"DUCKDB_PATH" envDefault:"data/duckdb.db"`                            // also support "memory" for in-memory db
+	SqlitePath string `env:"SQLLITE_PATH" envDefault:"data/sqlite.db"`                           // also support "memory" for in-memory db
+
+	// seconds an instance waits for another one to finish running migrations
+	MigrationLockTimeout int `env:"MIGRATION_LOCK_TIMEOUT" envDefault:"60" validate:"min=1"`
+
+	// milliseconds after which a database statement is logged as slow, 0 disables slow query logging
+	SlowQueryThreshold int `env:"SLOW_QUERY_THRESHOLD" envDefault:"500" validate:"min=0"`
 
 	KeyValueDBType string `env:"KEY_VALUE_DB_TYPE" envDefault:"nutsdb" validate:"oneof=nutsdb redis rds"` // supports: badger, nutsdb, redis, rds
 	BadgerPath     string `env:"BADGER_PATH" envDefault:"data/badger"`                                    // also support "memory" for in-memory db
 	NutsDBPath     string `env:"NUTSDB_PATH" envDefault:"data/nutsdb"`
 
+	// ttl of hot cache keys is randomly moved by up to this percent, so keys set together do not expire together
+	CacheTTLJitterPercent int `env:"CACHE_TTL_JITTER_PERCENT" envDefault:"10" validate:"min=0,max=50"`
+
+	// keep the cache working when its backend fails: keys are read and written in a memory store of at most
+	// CACHE_FALLBACK_MAX_KEYS keys until the backend answers again. The memory store is not shared between instances
+	CacheFallbackEnabled bool `env:"CACHE_FALLBACK_ENABLED" envDefault:"false"`
+	CacheFallbackMaxKeys int  `env:"CACHE_FALLBACK_MAX_KEYS" envDefault:"10000" validate:"min=1"`
+
 	RefreshTokenTTL uint64 `env:"REFRESH_TOKEN_TTL" envDefault:"15778463"`
 	AccessTokenTTL  uint64 `env:"ACCESS_TOKEN_TTL" envDefault:"300"`
 
 	ConfigFilePath string `env:"CONFIG_FILE_PATH" envDefault:"data/config.json"` // support memory
+	// signing secret of access tokens, takes the place of the one generated into CONFIG_FILE_PATH so all instances share it
+	JWTSecret string `env:"JWT_SECRET" envDefault:""`
 
 	LogFormat string `env:"LOG_FORMAT" envDefault:"text" validate:"oneof=text json"`            // supports: text, json
 	LogLevel  string `env:"LOG_LEVEL" envDefault:"info" validate:"oneof=debug info warn error"` // supports: debug, info, warn, error
+	// routes whose redacted request and response bodies are logged when LOG_LEVEL is debug, "[METHOD ]PATH" separated by commas,
+	// PATH is the route pattern (e.g. /api/v1/tools/:tool_uid) and may end with * to match every route under it
+	DebugBodyLogRoutes []string `env:"DEBUG_BODY_LOG_ROUTES" envSeparator:"," envDefault:""`
+	// add the redacted json body of every state changing request to its request log line, passwords, TOTP codes and
+	// tokens are replaced by [REDACTED]
+	RequestLogPayloads bool `env:"REQUEST_LOG_PAYLOADS" envDefault:"false"`
 	// Cache     string `env:"CACHE" envDefault:"disabled" validate:"oneof=disabled memory redis mysql"` // supports: disabled, memory, redis, mysql
 
 	SSO_GITHUB_CLIENT_ID     string `env:"SSO_GITHUB_CLIENT_ID" envDefault:""`
@@ -42,14 +72,180 @@ type Config struct {
 	SSO_GOOGLE_CLIENT_SECRET string `env:"SSO_GOOGLE_CLIENT_SECRET" envDefault:""`
 	SSO_GOOGLE_REDIRECT_URL  string `env:"SSO_GOOGLE_REDIRECT_URL" envDefault:""`
 
-	ENABLE_PASSWORD_LOGIN     bool `env:"ENABLE_PASSWORD_LOGIN" envDefault:"false"`
+	ENABLE_PASSWORD_LOGIN    bool `env:"ENABLE_PASSWORD_LOGIN" envDefault:"false"`
 	ENABLE_USER_REGISTRATION bool `env:"ENABLE_USER_REGISTRATION" envDefault:"true"`
 
+	// clients sending an X-Client-Version older than this still get their responses, with a Deprecation header, empty disables it
+	MinClientVersion string `env:"MIN_CLIENT_VERSION" envDefault:""`
+
+	// read-only public playground: every request that would change stored data is rejected, logging in still works
+	DemoMode bool `env:"DEMO_MODE" envDefault:"false"`
+
+	// first run admin: when no admin exists at startup one is created with this username,
+	// an empty BOOTSTRAP_ADMIN_PASSWORD generates a password printed once to the log
+	BootstrapAdminUsername string `env:"BOOTSTRAP_ADMIN_USERNAME" envDefault:""`
+	BootstrapAdminPassword string `env:"BOOTSTRAP_ADMIN_PASSWORD" envDefault:""`
+
+	// how credentials found in tool source on save are handled: off, warn (saved with warnings) or block (rejected)
+	ToolSecretScanMode string `env:"TOOL_SECRET_SCAN_MODE" envDefault:"warn" validate:"oneof=off warn block"`
+
+	// server side tool execution runs tool handlers headlessly in an embedded javascript runtime, off by default.
+	// A run is stopped after TOOL_EXECUTION_TIMEOUT seconds and its inputs and outputs are each limited to
+	// TOOL_EXECUTION_MAX_PAYLOAD bytes. Every run happens in a child process that may allocate TOOL_EXECUTION_MAX_MEMORY
+	// bytes. A user runs at most TOOL_EXECUTION_MAX_CONCURRENT tools at a time and
+	// TOOL_EXECUTION_RATE_LIMIT runs per minute, 0 disables the rate limit
+	ToolExecutionEnabled       bool `env:"TOOL_EXECUTION_ENABLED" envDefault:"false"`
+	ToolExecutionTimeout       int  `env:"TOOL_EXECUTION_TIMEOUT" envDefault:"5" validate:"min=1,max=300"`
+	ToolExecutionMaxPayload    int  `env:"TOOL_EXECUTION_MAX_PAYLOAD" envDefault:"1048576" validate:"min=1024"`
+	ToolExecutionMaxMemory     int  `env:"TOOL_EXECUTION_MAX_MEMORY" envDefault:"67108864" validate:"min=1048576"`
+	ToolExecutionMaxConcurrent int  `env:"TOOL_EXECUTION_MAX_CONCURRENT" envDefault:"2" validate:"min=1"`
+	ToolExecutionRateLimit     int  `env:"TOOL_EXECUTION_RATE_LIMIT" envDefault:"60" validate:"min=0"`
+
+	// a run or a schedule picks the small, medium or large resource class, small gets the timeout and memory above.
+	// A medium run counts as 4 tool executions against the metered quota and a large run as 16
+	ToolExecutionMediumTimeout   int `env:"TOOL_EXECUTION_MEDIUM_TIMEOUT" envDefault:"30" validate:"min=1,max=300"`
+	ToolExecutionMediumMaxMemory int `env:"TOOL_EXECUTION_MEDIUM_MAX_MEMORY" envDefault:"268435456" validate:"min=1048576"`
+	ToolExecutionLargeTimeout    int `env:"TOOL_EXECUTION_LARGE_TIMEOUT" envDefault:"120" validate:"min=1,max=300"`
+	ToolExecutionLargeMaxMemory  int `env:"TOOL_EXECUTION_LARGE_MAX_MEMORY" envDefault:"1073741824" validate:"min=1048576"`
+
+	// scheduled runs of tools, a user keeps at most TOOL_SCHEDULE_MAX_PER_USER schedules and the
+	// TOOL_RUN_HISTORY_LIMIT newest runs of each schedule, scheduled runs count against the limits above
+	ToolScheduleMaxPerUser int `env:"TOOL_SCHEDULE_MAX_PER_USER" envDefault:"20" validate:"min=1"`
+	ToolRunHistoryLimit    int `env:"TOOL_RUN_HISTORY_LIMIT" envDefault:"50" validate:"min=1"`
+
+	// saved versions of the source and ui widgets kept per tool, the oldest are dropped past the limit
+	ToolVersionLimit int `env:"TOOL_VERSION_LIMIT" envDefault:"50" validate:"min=1"`
+
+	// refuse a tool whose name another tool of the user already has in the same namespace
+	UniqueToolNames bool `env:"UNIQUE_TOOL_NAMES" envDefault:"false"`
+
+	// archives of the data export of a user are kept for USER_DATA_EXPORT_RETENTION_HOURS after they are built
+	UserDataExportRetentionHours int `env:"USER_DATA_EXPORT_RETENTION_HOURS" envDefault:"24" validate:"min=1"`
+
+	// seal the tool sources and global scripts saved from now on with the encrypt key of their owner and
+	// SOURCE_ENCRYPTION_SECRET, migrate -encrypt-sources encrypts the stored ones. The full text search leaves the
+	// sources out while it is on
+	EncryptSourcesAtRest bool `env:"ENCRYPT_SOURCES_AT_REST" envDefault:"false"`
+
+	// server secret the data keys of the sealed sources are sealed with, next to the encrypt key of their owner, so a
+	// copy of the database alone does not open them. Required by ENCRYPT_SOURCES_AT_REST, kept out of the database,
+	// losing it loses the sealed sources
+	SourceEncryptionSecret string `env:"SOURCE_ENCRYPTION_SECRET" envDefault:"" validate:"omitempty,min=32"`
+
+	// scanner every imported file passes before it is stored: none, clamav or http
+	ImportScanner              string `env:"IMPORT_SCANNER" envDefault:"none" validate:"oneof=none clamav http"`
+	ImportScannerClamAVAddress string `env:"IMPORT_SCANNER_CLAMAV_ADDRESS" envDefault:"tcp://127.0.0.1:3310"` // tcp://host:port or unix:///path/to/clamd.sock
+	ImportScannerHTTPURL       string `env:"IMPORT_SCANNER_HTTP_URL" envDefault:""`
+	ImportMaxFileSize          int    `env:"IMPORT_MAX_FILE_SIZE" envDefault:"1048576" validate:"min=1"` // bytes
+	ImportMaxFiles             int    `env:"IMPORT_MAX_FILES" envDefault:"100" validate:"min=1"`
+
+	// metering records the api calls and tool storage of every user per month, USAGE_ENFORCER decides whether a user may use more:
+	// none allows everything, http asks an external billing service
+	MeteringEnabled      bool   `env:"METERING_ENABLED" envDefault:"false"`
+	UsageEnforcer        string `env:"USAGE_ENFORCER" envDefault:"none" validate:"oneof=none http"`
+	UsageEnforcerHTTPURL string `env:"USAGE_ENFORCER_HTTP_URL" envDefault:""`
+
+	// mailer of the account emails such as recovery codes: none disables the flows needing one, log writes the mails to the log
+	// (development only, codes included), smtp sends them through SMTP_HOST. Port 465 uses implicit tls, the others STARTTLS when offered
+	Mailer       string `env:"MAILER" envDefault:"none" validate:"oneof=none log smtp"`
+	MailFrom     string `env:"MAIL_FROM" envDefault:""`
+	SMTPHost     string `env:"SMTP_HOST" envDefault:""`
+	SMTPPort     int    `env:"SMTP_PORT" envDefault:"587" validate:"min=1,max=65535"`
+	SMTPUsername string `env:"SMTP_USERNAME" envDefault:""`
+	SMTPPassword string `env:"SMTP_PASSWORD" envDefault:""`
+
+	// account recovery for users who lost both the password and every 2FA method, needs a mailer: seconds between the
+	// request and the moment it can be completed once an admin approved it, the account is notified and can cancel meanwhile
+	AccountRecoveryDelay int `env:"ACCOUNT_RECOVERY_DELAY" envDefault:"259200" validate:"min=0"`
+
+	// seconds between the request of a user to delete the account and its purge, the user can sign in and cancel
+	// meanwhile. 0 deletes the account right away
+	AccountDeletionGracePeriod int `env:"ACCOUNT_DELETION_GRACE_PERIOD" envDefault:"604800" validate:"min=0"`
+
+	// self-service password reset, needs a mailer: seconds the emailed reset token stays valid, only the latest token of a user works
+	PasswordResetTokenTTL int `env:"PASSWORD_RESET_TOKEN_TTL" envDefault:"1800" validate:"min=60"`
+
+	// sign the user out of every other device once the password is changed or a 2FA method is enabled, the device making
+	// the change stays signed in. The account is notified by email when a mailer is set up
+	RevokeSessionsOnPasswordChange bool `env:"REVOKE_SESSIONS_ON_PASSWORD_CHANGE" envDefault:"false"`
+	RevokeSessionsOn2FAEnable      bool `env:"REVOKE_SESSIONS_ON_2FA_ENABLE" envDefault:"false"`
+
+	// replace the refresh token every time it is used, the client must store the refresh token of the response. A
+	// replaced token presented again is treated as stolen and signs out every session rotated from the same login
+	RefreshTokenRotation bool `env:"REFRESH_TOKEN_ROTATION" envDefault:"false"`
+
+	// number of single-use recovery codes in a set, a user regenerates the set once the codes run low
+	RecoveryCodeCount int `env:"RECOVERY_CODE_COUNT" envDefault:"10" validate:"min=1,max=50"`
+
+	// wrong 2FA codes a user can send within 5 minutes before the 2FA verification of the user is locked
+	TwoFAMaxVerifyAttempts int `env:"TWO_FA_MAX_VERIFY_ATTEMPTS" envDefault:"5" validate:"min=1"`
+
+	// openid connect provider mode, other apps sign their users in with ToolBake. OIDC_ISSUER is the public url ToolBake
+	// is reached at, the discovery document is served at OIDC_ISSUER/.well-known/openid-configuration
+	OIDCProviderEnabled bool   `env:"OIDC_PROVIDER_ENABLED" envDefault:"false"`
+	OIDCIssuer          string `env:"OIDC_ISSUER" envDefault:""`
+
+	// graphql endpoint at /graphql over the tools and the profile of the user, for clients that pick their own fields
+	GraphQLEnabled bool `env:"GRAPHQL_ENABLED" envDefault:"false"`
+
+	// readiness probe: seconds each dependency check may take, and whether the sso providers are probed too
+	ReadinessCheckTimeout int  `env:"READINESS_CHECK_TIMEOUT" envDefault:"2" validate:"min=1"`
+	ReadinessCheckSSO     bool `env:"READINESS_CHECK_SSO" envDefault:"false"`
+
+	// startup self-check, the clock is compared with SELF_CHECK_NTP_SERVER, none skips the comparison
+	SelfCheckNTPServer    string `env:"SELF_CHECK_NTP_SERVER" envDefault:"pool.ntp.org"`
+	SelfCheckMaxClockSkew int    `env:"SELF_CHECK_MAX_CLOCK_SKEW" envDefault:"30" validate:"min=1"` // seconds
+
+	// housekeeping jobs, SCHEDULER_ENABLED=false leaves them to another instance. Schedules are cron expressions,
+	// empty disables the job, and admins can override them at runtime in the system settings
+	SchedulerEnabled                 bool   `env:"SCHEDULER_ENABLED" envDefault:"true"`
+	ScheduleRefreshTokenCleanup      string `env:"SCHEDULE_REFRESH_TOKEN_CLEANUP" envDefault:"0 4 * * *"`
+	ScheduleKeyValueCompaction       string `env:"SCHEDULE_KEY_VALUE_COMPACTION" envDefault:"30 4 * * *"`
+	ScheduleDatabaseBackup           string `env:"SCHEDULE_DATABASE_BACKUP" envDefault:""`
+	ScheduleDataRetention            string `env:"SCHEDULE_DATA_RETENTION" envDefault:"0 5 * * *"`
+	ScheduleExpiredCachePurge        string `env:"SCHEDULE_EXPIRED_CACHE_PURGE" envDefault:"@every 15m"`
+	SchedulePasskeyChallengeEviction string `env:"SCHEDULE_PASSKEY_CHALLENGE_EVICTION" envDefault:"@every 5m"`
+
+	// data retention, the data_retention job deletes the records older than the window of their category in days,
+	// 0 keeps them forever. Export the audit log to a SIEM first when it has to be archived for longer
+	AuditLogRetentionDays int `env:"AUDIT_LOG_RETENTION_DAYS" envDefault:"0" validate:"min=0"`
+
+	// database backups are archives of the sqlite database and the nutsdb store written to BACKUP_DIR, the newest
+	// BACKUP_RETENTION are kept. mysql and redis live outside ToolBake and are backed up with their own tooling
+	BackupDir       string `env:"BACKUP_DIR" envDefault:"data/backups"`
+	BackupRetention int    `env:"BACKUP_RETENTION" envDefault:"7" validate:"min=1"`
+
+	// token issuance anomaly detection: more refresh tokens than a threshold within TOKEN_ANOMALY_WINDOW seconds
+	// for one user, from distinct ips for one user, or for all users together are flagged, 0 disables a check
+	TokenAnomalyWindow          int `env:"TOKEN_ANOMALY_WINDOW" envDefault:"600" validate:"min=1"`
+	TokenAnomalyUserThreshold   int `env:"TOKEN_ANOMALY_USER_THRESHOLD" envDefault:"20" validate:"min=0"`
+	TokenAnomalyUserIPThreshold int `env:"TOKEN_ANOMALY_USER_IP_THRESHOLD" envDefault:"5" validate:"min=0"`
+	TokenAnomalyGlobalThreshold int `env:"TOKEN_ANOMALY_GLOBAL_THRESHOLD" envDefault:"500" validate:"min=0"`
+
+	// siem export streams the audit records and the requests to the auth routes to a SIEM: none disables it,
+	// http posts batches to SIEM_HTTP_URL, file appends them to SIEM_FILE_PATH. Formats: jsonl or cef
+	SIEMExport        string `env:"SIEM_EXPORT" envDefault:"none" validate:"oneof=none http file"`
+	SIEMFormat        string `env:"SIEM_FORMAT" envDefault:"jsonl" validate:"oneof=jsonl cef"`
+	SIEMHTTPURL       string `env:"SIEM_HTTP_URL" envDefault:""`
+	SIEMFilePath      string `env:"SIEM_FILE_PATH" envDefault:""`
+	SIEMBufferSize    int    `env:"SIEM_BUFFER_SIZE" envDefault:"10000" validate:"min=1"` // events waiting for export, newer ones are dropped when it is full
+	SIEMBatchSize     int    `env:"SIEM_BATCH_SIZE" envDefault:"100" validate:"min=1"`
+	SIEMFlushInterval int    `env:"SIEM_FLUSH_INTERVAL" envDefault:"5" validate:"min=1"` // seconds
+	SIEMMaxRetries    int    `env:"SIEM_MAX_RETRIES" envDefault:"5" validate:"min=0"`
+
 	// WebAuthn Configuration
-	WebAuthnRPName       string `env:"WEBAUTHN_RP_NAME" envDefault:"ToolBake-localhost"`
-	WebAuthnRPID         string `env:"WEBAUTHN_RP_ID" envDefault:"localhost"`
-	WebAuthnRPOrigin     string `env:"WEBAUTHN_RP_ORIGIN" envDefault:"http://localhost:8080"`
-	WebAuthnChallengeTTL int    `env:"WEBAUTHN_CHALLENGE_TTL" envDefault:"300"` // seconds
+	WebAuthnRPName string `env:"WEBAUTHN_RP_NAME" envDefault:"ToolBake-localhost"`
+	WebAuthnRPID   string `env:"WEBAUTHN_RP_ID" envDefault:"localhost"`
+	// origins the passkey ceremonies may come from, comma separated: the website, staging domains
+	// and app origins such as android:apk-key-hash:<hash>
+	WebAuthnRPOrigins    []string `env:"WEBAUTHN_RP_ORIGIN" envSeparator:"," envDefault:"http://localhost:8080"`
+	WebAuthnChallengeTTL int      `env:"WEBAUTHN_CHALLENGE_TTL" envDefault:"300"` // seconds
+
+	// anonymous passkey login challenges: at most PASSKEY_LOGIN_CHALLENGE_IP_LIMIT per client ip within
+	// PASSKEY_LOGIN_CHALLENGE_IP_WINDOW seconds, and at most PASSKEY_LOGIN_CHALLENGE_MAX_OUTSTANDING unfinished ones per instance, 0 disables a limit
+	PasskeyLoginChallengeIPLimit        int `env:"PASSKEY_LOGIN_CHALLENGE_IP_LIMIT" envDefault:"30" validate:"min=0"`
+	PasskeyLoginChallengeIPWindow       int `env:"PASSKEY_LOGIN_CHALLENGE_IP_WINDOW" envDefault:"60" validate:"min=1"`
+	PasskeyLoginChallengeMaxOutstanding int `env:"PASSKEY_LOGIN_CHALLENGE_MAX_OUTSTANDING" envDefault:"10000" validate:"min=0"`
 
 	MysqlHost string `env:"MYSQL_HOST"`
 	MysqlPort string `env:"MYSQL_PORT"`
@@ -57,9 +253,18 @@ type Config struct {
 	MysqlPass string `env:"MYSQL_PASS"`
 	MysqlDB   string `env:"MYSQL_DB"`
 
-	// RedisHost     string `env:"REDIS_HOST" envDefault:""`
-	// RedisPort     int    `env:"REDIS_PORT" envDefault:"6379"`
-	// RedisPassword string `env:"REDIS_PASSWORD" envDefault:""`
+	PostgresHost    string `env:"POSTGRES_HOST"`
+	PostgresPort    string `env:"POSTGRES_PORT"`
+	PostgresUser    string `env:"POSTGRES_USER"`
+	PostgresPass    string `env:"POSTGRES_PASS"`
+	PostgresDB      string `env:"POSTGRES_DB"`
+	PostgresSSLMode string `env:"POSTGRES_SSLMODE" envDefault:"prefer" validate:"oneof=disable allow prefer require verify-ca verify-full"` // supports: disable, allow, prefer, require, verify-ca, verify-full
+
+	RedisHost     string `env:"REDIS_HOST" envDefault:""`
+	RedisPort     int    `env:"REDIS_PORT" envDefault:"6379"`
+	RedisPassword string `env:"REDIS_PASSWORD" envDefault:""`
+	RedisDB       int    `env:"REDIS_DB" envDefault:"0"`
+	RedisTLS      bool   `env:"REDIS_TLS" envDefault:"false"`
 }
 
 func NewConfig() (Config, error) {
@@ -111,9 +316,14 @@ func (c *Config) debugPrintConfig() {
 		"SSO_GITHUB_CLIENT_SECRET": true,
 		"SSO_GOOGLE_CLIENT_SECRET": true,
 		"MysqlPass":                true,
+		"PostgresPass":             true,
 		"RedisPassword":            true,
+		"JWTSecret":                true,
+		"SourceEncryptionSecret":   true,
+		"BootstrapAdminPassword":   true,
 		"S3SecretKey":              true,
 		"S3AccessKey":              true,
+		"SMTPPassword":             true,
 	}
 
 	var sb strings.Builder
@@ -136,6 +346,121 @@ func (c Config) Validate() error {
 	if err := validate.Struct(c); err != nil {
 		return errors.Errorf("config validation failed, check your config or environment variables: %+v", err)
 	}
+	if err := c.validateWebAuthn(); err != nil {
+		return err
+	}
+	if c.MinClientVersion != "" {
+		if _, err := utils.CompareVersions(c.MinClientVersion, c.MinClientVersion); err != nil {
+			return errors.Errorf("MIN_CLIENT_VERSION must be a version such as 1.4.0: %v", err)
+		}
+	}
+	if c.OIDCProviderEnabled {
+		if err := c.validateOIDCIssuer(); err != nil {
+			return err
+		}
+	}
+	if c.EncryptSourcesAtRest && c.SourceEncryptionSecret == "" {
+		return errors.Errorf("ENCRYPT_SOURCES_AT_REST=true requires SOURCE_ENCRYPTION_SECRET, the database holds the encrypt keys of the users next to the sources")
+	}
+	if c.Serverless && c.DeploymentProfile != "stateless" {
+		return errors.Errorf("SERVERLESS=true requires DEPLOYMENT_PROFILE=stateless, a Lambda instance keeps nothing on disk")
+	}
+	if c.DeploymentProfile == "stateless" {
+		return c.validateStatelessProfile()
+	}
+	return nil
+}
+
+// validateOIDCIssuer checks that OIDC_ISSUER is an absolute url, relying parties compare it byte for byte with the iss claim
+func (c Config) validateOIDCIssuer() error {
+	parsed, err := url.Parse(c.OIDCIssuer)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return errors.Errorf("OIDC_ISSUER=%q must be the public url of ToolBake such as https://toolbake.com", c.OIDCIssuer)
+	}
+	if strings.HasSuffix(c.OIDCIssuer, "/") || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return errors.Errorf("OIDC_ISSUER=%q must not end with a slash or have a query or fragment", c.OIDCIssuer)
+	}
+	return nil
+}
+
+// validateWebAuthn checks that WEBAUTHN_RP_ID is a bare domain and that every web origin is on it or one of its subdomains,
+// browsers refuse passkeys otherwise and webauthn only reports it when a ceremony fails.
+func (c Config) validateWebAuthn() error {
+	var problems []string
+	rpID := c.WebAuthnRPID
+	if rpID == "" || strings.ContainsAny(rpID, ":/") || rpID != strings.ToLower(rpID) {
+		problems = append(problems, fmt.Sprintf("WEBAUTHN_RP_ID=%q must be a lower case domain without scheme, port or path, e.g. toolbake.com", rpID))
+	}
+
+	origins := c.WebAuthnAllowedOrigins()
+	if len(origins) == 0 {
+		problems = append(problems, "WEBAUTHN_RP_ORIGIN needs at least one origin")
+	}
+	for _, origin := range origins {
+		if problem := webAuthnOriginProblem(origin, rpID); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("config validation failed, invalid webauthn config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// WebAuthnAllowedOrigins returns the configured origins without blanks, "a, b" is read as two origins.
+func (c Config) WebAuthnAllowedOrigins() []string {
+	return lo.FilterMap(c.WebAuthnRPOrigins, func(origin string, _ int) (string, bool) {
+		origin = strings.TrimSpace(origin)
+		return origin, origin != ""
+	})
+}
+
+// webAuthnOriginProblem describes what is wrong with an origin, http and https origins must be on the rp id,
+// other schemes are app origins and only need a scheme
+func webAuthnOriginProblem(origin string, rpID string) string {
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Scheme == "" {
+		return fmt.Sprintf("WEBAUTHN_RP_ORIGIN %q is not an origin such as https://toolbake.com", origin)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return ""
+	}
+	if parsed.Host == "" || (parsed.Path != "" && parsed.Path != "/") || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Sprintf("WEBAUTHN_RP_ORIGIN %q must be scheme and host only, such as https://toolbake.com", origin)
+	}
+	if parsed.Path == "/" {
+		return fmt.Sprintf("WEBAUTHN_RP_ORIGIN %q must not end with a slash, browsers send the origin without it", origin)
+	}
+	host := parsed.Hostname()
+	if host != rpID && !strings.HasSuffix(host, "."+rpID) {
+		return fmt.Sprintf("WEBAUTHN_RP_ORIGIN %q is not on WEBAUTHN_RP_ID %q, the host must be the rp id or a subdomain of it", origin, rpID)
+	}
+	return ""
+}
+
+// statelessJWTSecretMinLength matches the length of the generated secret, 32 random bytes hex encoded
+const statelessJWTSecretMinLength = 64
+
+// validateStatelessProfile rejects every setting that would keep state on the local disk of an instance.
+func (c Config) validateStatelessProfile() error {
+	var problems []string
+	if c.DBType == "sqlite" {
+		problems = append(problems, "DB_TYPE=sqlite stores the database on local disk, use an external database")
+	}
+	if c.KeyValueDBType != "redis" {
+		problems = append(problems, fmt.Sprintf("KEY_VALUE_DB_TYPE=%s is not external, use redis", c.KeyValueDBType))
+	}
+	if c.KeyValueDBType == "redis" && c.RedisHost == "" {
+		problems = append(problems, "REDIS_HOST is required")
+	}
+	if len(c.JWTSecret) < statelessJWTSecretMinLength {
+		problems = append(problems, fmt.Sprintf("JWT_SECRET must be set to at least %d characters shared by all instances", statelessJWTSecretMinLength))
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("config validation failed, DEPLOYMENT_PROFILE=stateless requires: %s", strings.Join(problems, "; "))
+	}
 	return nil
 }
 
@@ -65,6 +65,122 @@ func TestNewConfig(t *testing.T) {
 	})
 }
 
+func TestValidateStatelessProfile(t *testing.T) {
+	stateless := Config{
+		DBType:                        "mysql",
+		KeyValueDBType:                "redis",
+		LogFormat:                     "text",
+		LogLevel:                      "info",
+		ToolSecretScanMode:            "warn",
+		ImportScanner:                 "none",
+		UsageEnforcer:                 "none",
+		SIEMExport:                    "none",
+		Mailer:                        "none",
+		SMTPPort:                      587,
+		BackupRetention:               1,
+		SIEMFormat:                    "jsonl",
+		SIEMBufferSize:                1,
+		SIEMBatchSize:                 1,
+		SIEMFlushInterval:             1,
+		PasskeyLoginChallengeIPWindow: 1,
+		WebAuthnRPID:                  "localhost",
+		WebAuthnRPOrigins:             []string{"http://localhost:8080"},
+		ImportMaxFileSize:             1,
+		ImportMaxFiles:                1,
+		MigrationLockTimeout:          1,
+		PostgresSSLMode:               "prefer",
+		ReadinessCheckTimeout:         1,
+		SelfCheckMaxClockSkew:         1,
+		RecoveryCodeCount:             1,
+		TwoFAMaxVerifyAttempts:        1,
+		PasswordResetTokenTTL:         60,
+		ToolVersionLimit:              1,
+		ToolExecutionTimeout:          1,
+		ToolExecutionMaxPayload:       1024,
+		ToolExecutionMaxMemory:        1048576,
+		ToolExecutionMaxConcurrent:    1,
+		ToolExecutionMediumTimeout:    1,
+		ToolExecutionMediumMaxMemory:  1048576,
+		ToolExecutionLargeTimeout:     1,
+		ToolExecutionLargeMaxMemory:   1048576,
+		ToolScheduleMaxPerUser:        1,
+		ToolRunHistoryLimit:           1,
+		UserDataExportRetentionHours:  1,
+		CacheFallbackMaxKeys:          1,
+		TokenAnomalyWindow:            1,
+		DeploymentProfile:             "stateless",
+		RedisHost:                     "redis.internal",
+		JWTSecret:                     strings.Repeat("a", 64),
+	}
+
+	t.Run("should accept external database, redis and a shared secret", func(t *testing.T) {
+		assert.NoError(t, stateless.Validate())
+
+		c := stateless
+		c.DBType = "postgres"
+		assert.NoError(t, c.Validate())
+	})
+
+	t.Run("should list every setting that keeps state locally", func(t *testing.T) {
+		c := stateless
+		c.DBType = "sqlite"
+		c.KeyValueDBType = "nutsdb"
+		c.JWTSecret = ""
+
+		err := c.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "DB_TYPE=sqlite")
+		assert.Contains(t, err.Error(), "KEY_VALUE_DB_TYPE=nutsdb")
+		assert.Contains(t, err.Error(), "JWT_SECRET")
+	})
+
+	t.Run("should require the redis host", func(t *testing.T) {
+		c := stateless
+		c.RedisHost = ""
+
+		err := c.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "REDIS_HOST")
+	})
+
+	t.Run("should run serverless only with the stateless profile", func(t *testing.T) {
+		c := stateless
+		c.Serverless = true
+		assert.NoError(t, c.Validate())
+
+		c.DeploymentProfile = "default"
+		err := c.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "SERVERLESS=true requires DEPLOYMENT_PROFILE=stateless")
+	})
+
+	t.Run("should require the source encryption secret to encrypt sources", func(t *testing.T) {
+		c := stateless
+		c.EncryptSourcesAtRest = true
+
+		err := c.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ENCRYPT_SOURCES_AT_REST=true requires SOURCE_ENCRYPTION_SECRET")
+
+		c.SourceEncryptionSecret = "a source encryption secret of the tests"
+		assert.NoError(t, c.Validate())
+	})
+
+	t.Run("should not check the default profile", func(t *testing.T) {
+		c := stateless
+		c.DeploymentProfile = "default"
+		c.DBType = "sqlite"
+		c.KeyValueDBType = "nutsdb"
+		c.JWTSecret = ""
+
+		assert.NoError(t, c.Validate())
+	})
+}
+
 func TestLoadConfigFromEnvFile(t *testing.T) {
 	t.Setenv("APP_ENV", "test")
 	envContent := "LOG_LEVEL=warn\n"
@@ -88,7 +204,7 @@ func TestDumpEnvDefaultsMarkdownTable(t *testing.T) {
 
 	assert.Contains(t, table, "| ENV_NAME | defaultValue | supported value |")
 	assert.Contains(t, table, "| FRONTEND_ASSET_PATH | ./frontend |  |")
-	assert.Contains(t, table, "| DB_TYPE | sqlite | `sqlite`, `mysql` |")
+	assert.Contains(t, table, "| DB_TYPE | sqlite | `sqlite`, `mysql`, `postgres` |")
 	assert.Contains(t, table, "| MYSQL_HOST |  |  |")
 
 	lineCount := len(strings.Split(strings.TrimSpace(table), "\n"))
@@ -103,3 +219,58 @@ func TestDumpEnvDefaultsMarkdownTable(t *testing.T) {
 	// 2 table header lines + one line per env-tagged field.
 	assert.Equal(t, tagCount+2, lineCount)
 }
+
+func TestValidateOIDCIssuer(t *testing.T) {
+	tests := []struct {
+		name    string
+		issuer  string
+		wantErr string
+	}{
+		{name: "https", issuer: "https://toolbake.com"},
+		{name: "behind a path", issuer: "http://localhost:8080/toolbake"},
+		{name: "empty", issuer: "", wantErr: "must be the public url"},
+		{name: "without scheme", issuer: "toolbake.com", wantErr: "must be the public url"},
+		{name: "trailing slash", issuer: "https://toolbake.com/", wantErr: "must not end with a slash"},
+		{name: "query", issuer: "https://toolbake.com?tenant=a", wantErr: "must not end with a slash or have a query"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Config{OIDCProviderEnabled: true, OIDCIssuer: tt.issuer}.validateOIDCIssuer()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateWebAuthn(t *testing.T) {
+	tests := []struct {
+		name    string
+		rpID    string
+		origins []string
+		wantErr string
+	}{
+		{name: "default", rpID: "localhost", origins: []string{"http://localhost:8080"}},
+		{name: "subdomains and app origins", rpID: "toolbake.com", origins: []string{"https://toolbake.com", " https://staging.toolbake.com", "android:apk-key-hash:abc"}},
+		{name: "rp id with scheme", rpID: "https://toolbake.com", origins: []string{"https://toolbake.com"}, wantErr: "WEBAUTHN_RP_ID"},
+		{name: "no origin", rpID: "toolbake.com", origins: []string{""}, wantErr: "needs at least one origin"},
+		{name: "origin on another domain", rpID: "toolbake.com", origins: []string{"https://toolbake.com", "https://eviltoolbake.com"}, wantErr: `"https://eviltoolbake.com" is not on WEBAUTHN_RP_ID`},
+		{name: "origin with trailing slash", rpID: "toolbake.com", origins: []string{"https://toolbake.com/"}, wantErr: "must not end with a slash"},
+		{name: "origin with path", rpID: "toolbake.com", origins: []string{"https://toolbake.com/app"}, wantErr: "scheme and host only"},
+		{name: "origin without scheme", rpID: "toolbake.com", origins: []string{"toolbake.com"}, wantErr: "is not an origin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Config{WebAuthnRPID: tt.rpID, WebAuthnRPOrigins: tt.origins}.validateWebAuthn()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
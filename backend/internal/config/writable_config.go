@@ -37,7 +37,26 @@ func (w *WritableConfig) SetValue(field *string, value string) error {
 	return w.persist()
 }
 
+// RotateJWTSecret replaces the generated JWT secret in CONFIG_FILE_PATH, the access tokens signed with the old secret
+// stop working once the instances are restarted. A secret from JWT_SECRET is rotated by changing the environment.
+func (w *WritableConfig) RotateJWTSecret() error {
+	if w.config.JWTSecret != "" {
+		return errors.New("the jwt secret is set by JWT_SECRET, change it there and restart the instances")
+	}
+	if w.config.ConfigFilePath == "memory" {
+		return errors.New("the jwt secret is not persisted with CONFIG_FILE_PATH=memory, restart the instance to generate a new one")
+	}
+
+	return w.SetValue(&w.Value.JWTSecret, generateJWTSecret())
+}
+
 func (w *WritableConfig) init() error {
+	// a secret from the environment is shared by every instance, nothing is generated or written to disk
+	if w.config.JWTSecret != "" {
+		w.Value.JWTSecret = w.config.JWTSecret
+		return nil
+	}
+
 	if err := w.load(); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			w.Value.JWTSecret = generateJWTSecret()
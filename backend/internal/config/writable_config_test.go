@@ -87,3 +87,46 @@ func TestWritableConfigInMemoryMode(t *testing.T) {
 	require.True(t, errors.Is(err, os.ErrNotExist))
 	require.Equal(t, newSecret, wc.Value.JWTSecret)
 }
+
+func TestWritableConfigUsesSecretFromEnvironment(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	cfg := Config{ConfigFilePath: filepath.Join(tempDir, "config.json"), JWTSecret: "secret-shared-by-all-instances"}
+
+	wc := NewWritableConfig(cfg)
+
+	require.Equal(t, cfg.JWTSecret, wc.Value.JWTSecret)
+	require.NoFileExists(t, cfg.ConfigFilePath)
+}
+
+func TestWritableConfigRotateJWTSecret(t *testing.T) {
+	t.Parallel()
+
+	t.Run("persists a new secret", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := Config{ConfigFilePath: filepath.Join(t.TempDir(), "config.json")}
+		wc := NewWritableConfig(cfg)
+		oldSecret := wc.Value.JWTSecret
+
+		require.NoError(t, wc.RotateJWTSecret())
+		require.NotEqual(t, oldSecret, wc.Value.JWTSecret)
+		require.Equal(t, wc.Value.JWTSecret, NewWritableConfig(cfg).Value.JWTSecret)
+	})
+
+	t.Run("refuses a secret it does not own", func(t *testing.T) {
+		t.Parallel()
+
+		for _, cfg := range []Config{
+			{ConfigFilePath: filepath.Join(t.TempDir(), "config.json"), JWTSecret: "secret-shared-by-all-instances"},
+			{ConfigFilePath: "memory"},
+		} {
+			wc := NewWritableConfig(cfg)
+			oldSecret := wc.Value.JWTSecret
+
+			require.Error(t, wc.RotateJWTSecret())
+			require.Equal(t, oldSecret, wc.Value.JWTSecret)
+		}
+	})
+}
@@ -0,0 +1,247 @@
+// Package contract replays recorded HTTP scenarios against a fully wired engine and diffs the
+// responses with golden files, so a change in the shape of any API response shows up in review.
+//
+// Scenarios live in testdata/scenarios/<name>.json, their expected responses in
+// testdata/golden/<name>.golden.json. After an intended API change, regenerate the golden files with
+//
+//	go test ./internal/contract -update
+//
+// and review the diff like any other code change.
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"ya-tool-craft/internal/core/engine"
+	"ya-tool-craft/internal/unittest"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current responses")
+
+var (
+	handler     http.Handler
+	handlerOnce sync.Once
+	// runCount tells apart repeated runs in one process (go test -count=N), they share the database
+	runCount int
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	dataDir, err := os.MkdirTemp("", "contract-test-*")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("APP_ENV", "test")
+	os.Setenv("NUTSDB_PATH", filepath.Join(dataDir, "nutsdb"))
+	os.Setenv("ENABLE_PASSWORD_LOGIN", "true")
+	os.Setenv("ENABLE_USER_REGISTRATION", "true")
+
+	code := m.Run()
+	os.RemoveAll(dataDir)
+	os.Exit(code)
+}
+
+// getHandler boots the fully wired engine once, the di container is process wide and can not be built twice.
+func getHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	handlerOnce.Do(func() {
+		// the env file is resolved from the project folder, explicit env vars win over it
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(unittest.UnitTestGetProjectPath()))
+		defer os.Chdir(wd)
+
+		e, err := engine.NewEngine()
+		require.NoError(t, err)
+		require.NoError(t, e.RunDBMigration())
+		handler = e.Handler()
+	})
+	require.NotNil(t, handler, "engine failed to boot")
+	return handler
+}
+
+// scenario is a sequence of requests sharing captured values such as tokens and ids.
+type scenario struct {
+	Steps []step `json:"steps"`
+}
+
+type step struct {
+	Name    string            `json:"name"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	// Capture stores values of the response for later steps, name -> dotted path such as "data.tools.0.uid".
+	// Later steps reference them as {{name}} in path, headers and body.
+	Capture map[string]string `json:"capture,omitempty"`
+}
+
+type recordedResponse struct {
+	Name   string `json:"name"`
+	Status int    `json:"status"`
+	Body   any    `json:"body"`
+}
+
+func TestContract(t *testing.T) {
+	scenarios, err := filepath.Glob(filepath.Join("testdata", "scenarios", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, scenarios)
+
+	handler := getHandler(t)
+	runCount++
+	run := fmt.Sprintf("r%d", runCount)
+
+	// scenarios share the database, they run one after another and must use their own users,
+	// the {{run}} placeholder keeps usernames unique across repeated runs
+	for _, scenarioPath := range scenarios {
+		name := strings.TrimSuffix(filepath.Base(scenarioPath), ".json")
+		t.Run(name, func(t *testing.T) {
+			raw, err := os.ReadFile(scenarioPath)
+			require.NoError(t, err)
+			var sc scenario
+			require.NoError(t, json.Unmarshal(raw, &sc))
+
+			got := runScenario(t, handler, run, sc)
+
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			encoder.SetIndent("", "  ")
+			require.NoError(t, encoder.Encode(got))
+			encoded := buf.Bytes()
+
+			goldenPath := filepath.Join("testdata", "golden", name+".golden.json")
+			if *update {
+				require.NoError(t, os.MkdirAll(filepath.Dir(goldenPath), 0o755))
+				require.NoError(t, os.WriteFile(goldenPath, encoded, 0o644))
+				return
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			require.NoError(t, err, "golden file missing, run `go test ./internal/contract -update`")
+			require.JSONEq(t, string(want), string(encoded))
+		})
+	}
+}
+
+func runScenario(t *testing.T, handler http.Handler, run string, sc scenario) []recordedResponse {
+	t.Helper()
+
+	captured := map[string]string{"run": run}
+	var responses []recordedResponse
+	for _, st := range sc.Steps {
+		var body io.Reader
+		if len(st.Body) > 0 {
+			body = strings.NewReader(substitute(string(st.Body), captured))
+		}
+		req := httptest.NewRequest(st.Method, substitute(st.Path, captured), body)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		for name, value := range st.Headers {
+			req.Header.Set(name, substitute(value, captured))
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		var decoded any
+		if w.Body.Len() > 0 {
+			decoder := json.NewDecoder(bytes.NewReader(w.Body.Bytes()))
+			decoder.UseNumber()
+			require.NoError(t, decoder.Decode(&decoded), "step %q returned non json body: %s", st.Name, w.Body.String())
+		}
+
+		for name, path := range st.Capture {
+			value, ok := lookup(decoded, path)
+			require.True(t, ok, "step %q: nothing to capture at %q in %s", st.Name, path, w.Body.String())
+			captured[name] = value
+		}
+
+		responses = append(responses, recordedResponse{Name: st.Name, Status: w.Code, Body: normalize(decoded, run)})
+	}
+	return responses
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{(\w+)\}\}`)
+
+func substitute(s string, captured map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if value, ok := captured[match[2:len(match)-2]]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// lookup resolves a dotted path, numeric segments index into arrays.
+func lookup(v any, path string) (string, bool) {
+	for _, segment := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[segment]
+		case []any:
+			var index int
+			if _, err := fmt.Sscanf(segment, "%d", &index); err != nil || index < 0 || index >= len(node) {
+				return "", false
+			}
+			v = node[index]
+		default:
+			return "", false
+		}
+	}
+	switch value := v.(type) {
+	case string:
+		return value, true
+	case json.Number:
+		return value.String(), true
+	}
+	return "", false
+}
+
+var volatilePatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`), "<uuid>"},
+	{regexp.MustCompile(`^[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`), "<jwt>"},
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`), "<time>"},
+}
+
+// normalize replaces the values that change on every run (ids, tokens, timestamps) with placeholders,
+// so the golden files only capture the shape and the stable values of a response.
+func normalize(v any, run string) any {
+	switch node := v.(type) {
+	case map[string]any:
+		for key, value := range node {
+			node[key] = normalize(value, run)
+		}
+		return node
+	case []any:
+		for i, value := range node {
+			node[i] = normalize(value, run)
+		}
+		return node
+	case string:
+		for _, volatile := range volatilePatterns {
+			node = volatile.pattern.ReplaceAllString(node, volatile.replacement)
+		}
+		return strings.ReplaceAll(node, run, "{{run}}")
+	}
+	return v
+}
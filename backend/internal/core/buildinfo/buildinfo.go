@@ -0,0 +1,36 @@
+package buildinfo
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// Version, Commit and BuildTime are set at link time by scripts/build-all.sh
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+var startedAt = time.Now()
+
+// StartedAt is when the process started, the base of the uptime.
+func StartedAt() time.Time {
+	return startedAt
+}
+
+// Revision returns the commit the binary was built from. Without the link time Commit it falls back to the
+// revision go embeds when building from a git checkout, "unknown" when there is neither.
+func Revision() string {
+	if Commit != "" {
+		return Commit
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && setting.Value != "" {
+				return setting.Value
+			}
+		}
+	}
+	return "unknown"
+}
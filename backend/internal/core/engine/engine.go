@@ -3,12 +3,19 @@ package engine
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
+	"ya-tool-craft/internal/application/controller/common"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/core/logger"
 	"ya-tool-craft/internal/core/router"
+	"ya-tool-craft/internal/core/scheduler"
 	"ya-tool-craft/internal/di"
 	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/domain/service"
 	"ya-tool-craft/internal/middleware"
 	"ya-tool-craft/internal/utils"
 
@@ -18,14 +25,26 @@ import (
 	"go.uber.org/dig"
 )
 
+// adminRoutePrefix marks the routes whose state changing requests are written to the audit log
+const adminRoutePrefix = "/api/v1/admin/"
+
+// authRoutePrefix marks the routes whose state changing requests are exported to the SIEM as auth events
+const authRoutePrefix = "/api/v1/auth/"
+
+// shutdownTimeout bounds how long in-flight requests may take once a shutdown signal arrived
+const shutdownTimeout = 10 * time.Second
+
 type Engine struct {
 	ginEngine *gin.Engine
 
 	config    config.Config
 	migration repository.IMigration
+	scheduler *scheduler.Scheduler
+	siem      *service.SIEMExportService
 }
 
-func NewEngine() *Engine {
+// NewEngine builds the engine, outside of serverless mode it refuses to when a self-check fails.
+func NewEngine() (*Engine, error) {
 	envName := config.GetEnvName()
 	if envName != "test" && envName != "local" {
 		gin.SetMode(gin.ReleaseMode)
@@ -34,44 +53,75 @@ func NewEngine() *Engine {
 	e := &Engine{
 		ginEngine: gin.Default(),
 	}
-	e.init()
+	if err := e.init(); err != nil {
+		return nil, err
+	}
 
-	return e
+	return e, nil
 }
 
-func (e *Engine) init() {
+func (e *Engine) init() error {
 	di.InitDI()
 
 	var c config.Config
-	var migration repository.IMigration
-	if err := di.Container.Invoke(func(cnf config.Config, m repository.IMigration) {
+	if err := di.Container.Invoke(func(cnf config.Config) {
 		c = cnf
-		migration = m
 	}); err != nil {
 		panic(errors.Errorf("failed to get config from di container: %v", err))
 	}
 	e.config = c
-	e.migration = migration
 	logger.InitLogger(c)
+	// before the stores are opened, a store on an unwritable directory fails with a less helpful error.
+	// Lambda keeps nothing on disk and keeps the clock in sync, the checks are skipped there
+	if !c.Serverless {
+		if err := e.selfCheck(); err != nil {
+			return err
+		}
+	}
+	// the stores are opened by the invoke below, a staged restore has to be in place before
+	e.applyPendingRestore()
+
+	var migration repository.IMigration
+	var compatibilityService *service.ClientCompatibilityService
+	if err := di.Container.Invoke(func(m repository.IMigration, cs *service.ClientCompatibilityService) {
+		migration = m
+		compatibilityService = cs
+	}); err != nil {
+		panic(errors.Errorf("failed to get migration from di container: %v", err))
+	}
+	e.migration = migration
 
 	// register middleware
 	e.ginEngine.Use(middleware.RequestIDMiddlewareFactory())
-	e.ginEngine.Use(middleware.RequestInfoMiddlewareFactory(c))
+	e.ginEngine.Use(middleware.ClientIPMiddlewareFactory())
+	e.ginEngine.Use(middleware.DeviceIDMiddlewareFactory())
+	e.ginEngine.Use(middleware.RequestLogMiddlewareFactory(c))
+	e.ginEngine.Use(middleware.ClientVersionMiddlewareFactory(compatibilityService))
 	if gin.Mode() == gin.DebugMode {
 		e.ginEngine.Use(middleware.DebugCORSMiddleware())
 	}
 
 	e.registerController()
-
+	e.registerScheduledJobs()
+	e.resolveSIEMExport()
+	return nil
 }
 
 func (e *Engine) registerController() {
 	// get controllers from di container
 	type ControllerFactoryParams struct {
 		dig.In
-		Controllers []router.Controller `group:"controllers"`
+		Controllers     []router.Controller `group:"controllers"`
+		AuditLogService *service.AuditLogService
 	}
 	err := di.Container.Invoke(func(c ControllerFactoryParams) {
+		// every admin route is audited, handlers do not have to remember it
+		auditLogMiddleware := middleware.AuditLogMiddlewareFactory(c.AuditLogService)
+		// logins, logouts and the other auth requests are streamed to the SIEM when an export is configured
+		authEventMiddleware := middleware.AuthEventMiddlewareFactory(c.AuditLogService)
+		// a demo instance rejects every route not marked as allowed in demo mode
+		demoModeMiddleware := middleware.DemoModeMiddleware((&common.JsonResponse{}).Error)
+
 		// fmt.Println(c.Controllers)
 		// get router infos and register to gin engine
 		for _, controller := range c.Controllers {
@@ -83,7 +133,21 @@ func (e *Engine) registerController() {
 
 			routerInfos := controller.RouterInfo()
 			for _, routerInfo := range routerInfos {
-				handlers := append(routerInfo.Middlewares, routerInfo.Handler)
+				var handlers []gin.HandlerFunc
+				if strings.HasPrefix(routerInfo.Path, adminRoutePrefix) {
+					handlers = append(handlers, auditLogMiddleware)
+				}
+				if c.AuditLogService.AuthEventsExported() && strings.HasPrefix(routerInfo.Path, authRoutePrefix) {
+					handlers = append(handlers, authEventMiddleware)
+				}
+				if e.config.DemoMode && !routerInfo.DemoModeAllowed {
+					handlers = append(handlers, demoModeMiddleware)
+				}
+				if e.debugBodyLogEnabled() && middleware.MatchDebugBodyLogRoute(e.config.DebugBodyLogRoutes, routerInfo.Method, routerInfo.Path) {
+					handlers = append(handlers, middleware.DebugBodyLogMiddleware())
+				}
+				handlers = append(handlers, routerInfo.Middlewares...)
+				handlers = append(handlers, routerInfo.Handler)
 				e.ginEngine.Handle(routerInfo.Method, routerInfo.Path, handlers...)
 			}
 		}
@@ -94,12 +158,101 @@ func (e *Engine) registerController() {
 
 }
 
+// applyPendingRestore puts a backup staged for restore in place of the local stores.
+func (e *Engine) applyPendingRestore() {
+	err := di.Container.Invoke(func(backupRepo repository.IBackupRepository) error {
+		name, applied, err := backupRepo.ApplyPendingRestore(context.Background())
+		if err != nil {
+			return err
+		}
+		if applied {
+			logger.Warnf(context.Background(), "Restored backup %s, the replaced stores are kept with the .before-restore suffix", name)
+		}
+		return nil
+	})
+	if err != nil {
+		panic(errors.Errorf("failed to restore the staged backup: %v", err))
+	}
+}
+
+// registerScheduledJobs hands the housekeeping, backup, tool schedule, data export and account deletion jobs to the
+// scheduler, it only runs them once Run starts it.
+func (e *Engine) registerScheduledJobs() {
+	err := di.Container.Invoke(func(
+		s *scheduler.Scheduler,
+		housekeepingService *service.HousekeepingService,
+		backupService *service.BackupService,
+		toolScheduleService *service.ToolScheduleService,
+		userDataExportService *service.UserDataExportService,
+		accountDeletionService *service.AccountDeletionService,
+	) {
+		s.Register(housekeepingService.Jobs()...)
+		s.Register(backupService.Jobs()...)
+		s.Register(toolScheduleService.Jobs()...)
+		s.Register(userDataExportService.Jobs()...)
+		s.Register(accountDeletionService.Jobs()...)
+		e.scheduler = s
+	})
+	if err != nil {
+		panic(errors.Errorf("failed to get scheduler from di container: %v", err))
+	}
+}
+
+// resolveSIEMExport keeps the siem export, Run starts its background loop.
+func (e *Engine) resolveSIEMExport() {
+	err := di.Container.Invoke(func(s *service.SIEMExportService) {
+		e.siem = s
+	})
+	if err != nil {
+		panic(errors.Errorf("failed to get siem export from di container: %v", err))
+	}
+}
+
+// debugBodyLogEnabled reports whether bodies of the DEBUG_BODY_LOG_ROUTES are logged, they are only logged at debug level.
+func (e *Engine) debugBodyLogEnabled() bool {
+	return e.config.LogLevel == "debug" && len(e.config.DebugBodyLogRoutes) > 0
+}
+
+// Handler exposes the router so the engine can be driven in-process, e.g. by the contract tests.
+func (e *Engine) Handler() http.Handler {
+	return e.ginEngine
+}
+
+// Run serves HTTP until SIGINT or SIGTERM, then lets the in-flight requests and jobs finish before returning,
+// so the storage can be closed after nothing uses it anymore.
 func (e *Engine) Run() error {
-	host := e.config.Host
-	if utils.StringRemoveAllSpace(host) == "" {
-		host = "0.0.0.0:8080"
+	host := listenHost(e.config)
+	// stopped last, the events of the final requests and jobs are still written
+	e.siem.Start()
+	defer e.siem.Stop()
+	if e.config.SchedulerEnabled {
+		e.scheduler.Start()
+		defer e.scheduler.Stop()
+	}
+
+	signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: host, Handler: e.ginEngine}
+	serveErr := make(chan error, 1)
+	go func() {
+		fmt.Printf("[ENGINE] Listening and serving HTTP on %s\n", host)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return errors.Wrap(err, "http server stopped")
+	case <-signalCtx.Done():
+	}
+
+	fmt.Println("[ENGINE] Shutting down, waiting for in-flight requests")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return errors.Wrap(err, "failed to shut down http server")
 	}
-	return e.ginEngine.Run(host)
+	return nil
 }
 
 func (e *Engine) RunDBMigration() error {
@@ -114,5 +267,20 @@ func (e *Engine) RunDBMigration() error {
 
 	logger.Info(ctx, "migration completed successfully")
 	fmt.Println("migration completed successfully")
-	return nil
+
+	// the search index must not keep a plaintext copy of the sources once they are encrypted
+	return di.Container.Invoke(func(sourceEncryption *service.SourceEncryptionService) error {
+		return sourceEncryption.DropSearchSources(ctx)
+	})
+}
+
+// BootstrapAdmin creates the admin configured by BOOTSTRAP_ADMIN_USERNAME when none exists, it runs after the migration.
+func (e *Engine) BootstrapAdmin() error {
+	ctx := utils.NewValueContext(context.Background())
+	ctx.Set("x-request-id", uuid.New().String())
+	ctx.Set("request-start-time", time.Now())
+
+	return di.Container.Invoke(func(userService *service.UserService) error {
+		return userService.BootstrapAdmin(ctx)
+	})
 }
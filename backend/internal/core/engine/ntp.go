@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ntpPacketSize = 48
+	// ntpEpochOffset is the seconds from the NTP epoch, 1900-01-01, to the unix epoch
+	ntpEpochOffset = 2208988800
+	// ntpModeServer is the mode of a reply to a client request
+	ntpModeServer = 4
+)
+
+// queryClockOffset asks an NTP server for the time with one SNTP request (RFC 4330) and returns how far the local
+// clock is off, positive when it is ahead of the server. server is host or host:port, the port defaults to 123.
+func queryClockOffset(ctx context.Context, server string) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to dial ntp server %s", server)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return 0, errors.Wrap(err, "failed to set ntp deadline")
+		}
+	}
+
+	request := make([]byte, ntpPacketSize)
+	// leap indicator 0, version 4, mode 3 (client)
+	request[0] = 0x23
+	sentAt := time.Now()
+	if _, err := conn.Write(request); err != nil {
+		return 0, errors.Wrapf(err, "failed to send ntp request to %s", server)
+	}
+
+	response := make([]byte, ntpPacketSize)
+	n, err := conn.Read(response)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read ntp response of %s", server)
+	}
+	receivedAt := time.Now()
+	if n < ntpPacketSize || response[0]&0x07 != ntpModeServer {
+		return 0, errors.Errorf("invalid ntp response of %s", server)
+	}
+	// stratum 0 is a kiss-o'-death, the server refuses to tell the time
+	if response[1] == 0 {
+		return 0, errors.Errorf("ntp server %s refused the request: %s", server, string(response[12:16]))
+	}
+
+	serverReceivedAt := ntpTimestamp(response[32:40])
+	serverSentAt := ntpTimestamp(response[40:48])
+	// the server is ahead by ((T2 - T1) + (T3 - T4)) / 2, the local clock is ahead by the negation
+	serverAhead := (serverReceivedAt.Sub(sentAt) + serverSentAt.Sub(receivedAt)) / 2
+	return -serverAhead, nil
+}
+
+// ntpTimestamp decodes a 64 bit NTP timestamp, seconds below 2^31 are read as era 1 which starts in 2036.
+func ntpTimestamp(b []byte) time.Time {
+	seconds := int64(binary.BigEndian.Uint32(b[0:4]))
+	fraction := uint64(binary.BigEndian.Uint32(b[4:8]))
+	if seconds < 1<<31 {
+		seconds += 1 << 32
+	}
+	return time.Unix(seconds-ntpEpochOffset, int64((fraction*uint64(time.Second))>>32))
+}
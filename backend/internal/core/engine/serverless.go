@@ -0,0 +1,236 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/pkg/errors"
+)
+
+// RunServerless serves the requests of API Gateway (REST and HTTP APIs) and of Lambda function URLs until the Lambda
+// runtime stops the process. The engine, and with it the database connections, is built on the first invocation and
+// reused by the invocations the warm instance serves afterwards. The first invocation also runs the migrations, the
+// migration lock keeps concurrent cold starts from running them twice. Scheduled jobs do not run in this mode, the
+// process is frozen between invocations.
+func RunServerless() {
+	var (
+		once   sync.Once
+		engine *Engine
+	)
+	build := func() {
+		defer func() {
+			if r := recover(); r != nil {
+				// a half built container can not be built again, let Lambda start a fresh instance
+				fmt.Println("[ENGINE] Failed to start serverless engine:", r)
+				os.Exit(1)
+			}
+		}()
+		var err error
+		if engine, err = NewEngine(); err != nil {
+			panic(err)
+		}
+		if err := engine.RunDBMigration(); err != nil {
+			panic(err)
+		}
+		if err := engine.BootstrapAdmin(); err != nil {
+			fmt.Println("[ENGINE] Failed to bootstrap admin:", err)
+		}
+		engine.LogStartupSummary()
+		fmt.Println("[ENGINE] Serverless engine ready")
+	}
+
+	lambda.Start(func(ctx context.Context, payload json.RawMessage) (any, error) {
+		once.Do(build)
+		// the process may be frozen as soon as the response is returned, the events must not wait for the next invocation
+		defer engine.siem.Flush()
+		return serveLambdaEvent(ctx, engine.ginEngine, payload)
+	})
+}
+
+// lambdaEventVersion tells the payload formats apart, HTTP APIs and function URLs send version 2.0 while REST APIs
+// send no version but an httpMethod.
+type lambdaEventVersion struct {
+	Version    string `json:"version"`
+	HTTPMethod string `json:"httpMethod"`
+}
+
+// serveLambdaEvent runs an API Gateway or function URL event through the handler and returns the response in the
+// format of the event.
+func serveLambdaEvent(ctx context.Context, handler http.Handler, payload json.RawMessage) (any, error) {
+	var version lambdaEventVersion
+	if err := json.Unmarshal(payload, &version); err != nil {
+		return nil, errors.Wrap(err, "failed to decode lambda event")
+	}
+	switch {
+	case version.Version == "2.0":
+		var event events.APIGatewayV2HTTPRequest
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, errors.Wrap(err, "failed to decode http api event")
+		}
+		req, err := httpRequestFromV2Event(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+		return serveLambdaRequest(handler, req).toV2Response(), nil
+	case version.HTTPMethod != "":
+		var event events.APIGatewayProxyRequest
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, errors.Wrap(err, "failed to decode rest api event")
+		}
+		req, err := httpRequestFromV1Event(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+		return serveLambdaRequest(handler, req).toV1Response(), nil
+	default:
+		return nil, errors.Errorf("unsupported lambda event, only API Gateway and function URL events are served")
+	}
+}
+
+func httpRequestFromV2Event(ctx context.Context, event events.APIGatewayV2HTTPRequest) (*http.Request, error) {
+	path := event.RawPath
+	if path == "" {
+		path = "/"
+	}
+	header := http.Header{}
+	for key, value := range event.Headers {
+		// http apis join repeated headers with a comma, which is how they are read anyway
+		header.Set(key, value)
+	}
+	if len(event.Cookies) > 0 {
+		header.Set("Cookie", strings.Join(event.Cookies, "; "))
+	}
+	return newLambdaHTTPRequest(ctx, event.RequestContext.HTTP.Method, path, event.RawQueryString, header,
+		event.Body, event.IsBase64Encoded, event.RequestContext.HTTP.SourceIP)
+}
+
+func httpRequestFromV1Event(ctx context.Context, event events.APIGatewayProxyRequest) (*http.Request, error) {
+	query := url.Values{}
+	for key, value := range event.QueryStringParameters {
+		query.Set(key, value)
+	}
+	for key, values := range event.MultiValueQueryStringParameters {
+		query[key] = values
+	}
+	header := http.Header{}
+	for key, value := range event.Headers {
+		header.Set(key, value)
+	}
+	for key, values := range event.MultiValueHeaders {
+		header[http.CanonicalHeaderKey(key)] = values
+	}
+	return newLambdaHTTPRequest(ctx, event.HTTPMethod, event.Path, query.Encode(), header,
+		event.Body, event.IsBase64Encoded, event.RequestContext.Identity.SourceIP)
+}
+
+func newLambdaHTTPRequest(ctx context.Context, method string, path string, rawQuery string, header http.Header, body string, base64Encoded bool, sourceIP string) (*http.Request, error) {
+	rawBody := []byte(body)
+	if base64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode base64 request body")
+		}
+		rawBody = decoded
+	}
+
+	target := path
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(rawBody))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to build request %s %s", method, path)
+	}
+	req.Header = header
+	req.Host = header.Get("Host")
+	req.RemoteAddr = net.JoinHostPort(sourceIP, "0")
+	return req, nil
+}
+
+// lambdaResponseWriter keeps the response in memory, Lambda returns it in one piece.
+type lambdaResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func serveLambdaRequest(handler http.Handler, req *http.Request) *lambdaResponseWriter {
+	w := &lambdaResponseWriter{header: http.Header{}}
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func (w *lambdaResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *lambdaResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.body.Write(b)
+}
+
+func (w *lambdaResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Flush is a no-op, the response is sent once the handler returned.
+func (w *lambdaResponseWriter) Flush() {}
+
+func (w *lambdaResponseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// encodedBody returns the body as is when it is text, binary bodies such as the frontend assets are base64 encoded.
+func (w *lambdaResponseWriter) encodedBody() (string, bool) {
+	if utf8.Valid(w.body.Bytes()) {
+		return w.body.String(), false
+	}
+	return base64.StdEncoding.EncodeToString(w.body.Bytes()), true
+}
+
+func (w *lambdaResponseWriter) toV2Response() events.APIGatewayV2HTTPResponse {
+	body, base64Encoded := w.encodedBody()
+	headers := map[string]string{}
+	for key, values := range w.header {
+		if key == "Set-Cookie" {
+			continue
+		}
+		headers[key] = strings.Join(values, ",")
+	}
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode:      w.statusCode(),
+		Headers:         headers,
+		Cookies:         w.header.Values("Set-Cookie"),
+		Body:            body,
+		IsBase64Encoded: base64Encoded,
+	}
+}
+
+func (w *lambdaResponseWriter) toV1Response() events.APIGatewayProxyResponse {
+	body, base64Encoded := w.encodedBody()
+	return events.APIGatewayProxyResponse{
+		StatusCode:        w.statusCode(),
+		MultiValueHeaders: w.header,
+		Body:              body,
+		IsBase64Encoded:   base64Encoded,
+	}
+}
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoHandler answers with what it received, so the tests can check how the events were turned into requests
+var echoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	http.SetCookie(w, &http.Cookie{Name: "a", Value: "1"})
+	http.SetCookie(w, &http.Cookie{Name: "b", Value: "2"})
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"method":      r.Method,
+		"path":        r.URL.Path,
+		"query":       r.URL.Query(),
+		"host":        r.Host,
+		"remote_addr": r.RemoteAddr,
+		"cookie":      r.Header.Get("Cookie"),
+		"accept":      r.Header.Values("Accept"),
+		"body":        string(body),
+	})
+})
+
+func decodeEcho(t *testing.T, body string) map[string]any {
+	var echo map[string]any
+	require.NoError(t, json.Unmarshal([]byte(body), &echo))
+	return echo
+}
+
+func TestServeLambdaEvent_HTTPAPI(t *testing.T) {
+	payload := `{
+		"version": "2.0",
+		"rawPath": "/api/v1/tools",
+		"rawQueryString": "q=a%20b&tag=x&tag=y",
+		"cookies": ["session=abc", "theme=dark"],
+		"headers": {"host": "toolbake.example.com", "content-type": "application/json"},
+		"requestContext": {"http": {"method": "POST", "sourceIp": "203.0.113.7"}},
+		"body": "` + base64.StdEncoding.EncodeToString([]byte(`{"name":"tool"}`)) + `",
+		"isBase64Encoded": true
+	}`
+
+	resp, err := serveLambdaEvent(context.Background(), echoHandler, json.RawMessage(payload))
+	require.NoError(t, err)
+	v2, ok := resp.(events.APIGatewayV2HTTPResponse)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusCreated, v2.StatusCode)
+	assert.Equal(t, "application/json", v2.Headers["Content-Type"])
+	assert.Equal(t, []string{"a=1", "b=2"}, v2.Cookies)
+	assert.NotContains(t, v2.Headers, "Set-Cookie")
+	assert.False(t, v2.IsBase64Encoded)
+
+	echo := decodeEcho(t, v2.Body)
+	assert.Equal(t, "POST", echo["method"])
+	assert.Equal(t, "/api/v1/tools", echo["path"])
+	assert.Equal(t, map[string]any{"q": []any{"a b"}, "tag": []any{"x", "y"}}, echo["query"])
+	assert.Equal(t, "toolbake.example.com", echo["host"])
+	assert.Equal(t, "203.0.113.7:0", echo["remote_addr"])
+	assert.Equal(t, "session=abc; theme=dark", echo["cookie"])
+	assert.Equal(t, `{"name":"tool"}`, echo["body"])
+}
+
+func TestServeLambdaEvent_RESTAPI(t *testing.T) {
+	payload := `{
+		"httpMethod": "GET",
+		"path": "/api/v1/healthcheck",
+		"queryStringParameters": {"tag": "y"},
+		"multiValueQueryStringParameters": {"tag": ["x", "y"]},
+		"headers": {"Host": "toolbake.example.com"},
+		"multiValueHeaders": {"accept": ["text/html", "application/json"]},
+		"requestContext": {"identity": {"sourceIp": "2001:db8::1"}},
+		"body": ""
+	}`
+
+	resp, err := serveLambdaEvent(context.Background(), echoHandler, json.RawMessage(payload))
+	require.NoError(t, err)
+	v1, ok := resp.(events.APIGatewayProxyResponse)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusCreated, v1.StatusCode)
+	assert.Equal(t, []string{"a=1", "b=2"}, v1.MultiValueHeaders["Set-Cookie"])
+
+	echo := decodeEcho(t, v1.Body)
+	assert.Equal(t, "GET", echo["method"])
+	assert.Equal(t, map[string]any{"tag": []any{"x", "y"}}, echo["query"])
+	assert.Equal(t, []any{"text/html", "application/json"}, echo["accept"])
+	assert.Equal(t, "[2001:db8::1]:0", echo["remote_addr"])
+}
+
+func TestServeLambdaEvent_BinaryResponse(t *testing.T) {
+	binary := []byte{0x89, 'P', 'N', 'G', 0xff, 0x00}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(binary)
+	})
+	payload := `{"version": "2.0", "rawPath": "/logo.png", "requestContext": {"http": {"method": "GET", "sourceIp": "203.0.113.7"}}}`
+
+	resp, err := serveLambdaEvent(context.Background(), handler, json.RawMessage(payload))
+	require.NoError(t, err)
+	v2 := resp.(events.APIGatewayV2HTTPResponse)
+	assert.Equal(t, http.StatusOK, v2.StatusCode)
+	assert.True(t, v2.IsBase64Encoded)
+	decoded, err := base64.StdEncoding.DecodeString(v2.Body)
+	require.NoError(t, err)
+	assert.Equal(t, binary, decoded)
+}
+
+func TestServeLambdaEvent_Unsupported(t *testing.T) {
+	_, err := serveLambdaEvent(context.Background(), echoHandler, json.RawMessage(`{"Records": []}`))
+	assert.ErrorContains(t, err, "unsupported lambda event")
+	_, err = serveLambdaEvent(context.Background(), echoHandler, json.RawMessage(`not json`))
+	assert.Error(t, err)
+}
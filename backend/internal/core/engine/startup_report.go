@@ -0,0 +1,372 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/buildinfo"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/di"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// defaultHost is where the server listens when HOST is empty
+const defaultHost = "0.0.0.0:8080"
+
+// clockCheckTimeout bounds the ntp query, an unreachable server must not hold up the start for long
+const clockCheckTimeout = 3 * time.Second
+
+type selfCheckStatus string
+
+const (
+	selfCheckOK   selfCheckStatus = "ok"
+	selfCheckWarn selfCheckStatus = "warn"
+	// selfCheckFail stops the start, the instance would not work correctly
+	selfCheckFail selfCheckStatus = "fail"
+)
+
+// selfCheckResult is the outcome of one self-check, Remediation tells the operator what to change when it is not ok.
+type selfCheckResult struct {
+	Name        string
+	Status      selfCheckStatus
+	Detail      string
+	Remediation string
+}
+
+// LogStartupSummary logs what the instance runs with: enabled features, storage backends, auth methods and listening
+// addresses. It reads the effective system settings, so it runs after the migration.
+func (e *Engine) LogStartupSummary() {
+	ctx := newStartupContext()
+
+	var settings entity.SystemSettingsEntity
+	err := di.Container.Invoke(func(settingsService *service.SystemSettingsService) {
+		var err error
+		if settings, err = settingsService.Settings(ctx); err != nil {
+			logger.Warnf(ctx, "failed to read the system settings for the startup summary, showing the env config: %v", err)
+			settings = settingsService.DefaultSettings()
+		}
+	})
+	if err != nil {
+		panic(errors.Errorf("failed to get system settings from di container: %v", err))
+	}
+
+	logger.InfoFields(ctx, startupSummary(e.config, settings, net.InterfaceAddrs), "startup summary")
+}
+
+// selfCheck checks the environment of the host before the stores are opened: the data directories must be writable
+// and the clock must not be off. Every result is logged, the returned error lists the failed checks with what to change.
+func (e *Engine) selfCheck() error {
+	ctx := newStartupContext()
+
+	results := runSelfChecks(ctx, e.config, queryClockOffset)
+	var failures []string
+	for _, result := range results {
+		fields := map[string]any{"check": result.Name, "status": result.Status}
+		switch result.Status {
+		case selfCheckOK:
+			logger.InfoFields(ctx, fields, "self-check passed: ", result.Detail)
+		case selfCheckWarn:
+			fields["remediation"] = result.Remediation
+			logger.WarnFields(ctx, fields, "self-check warning: ", result.Detail)
+		case selfCheckFail:
+			fields["remediation"] = result.Remediation
+			logger.ErrorFields(ctx, fields, "self-check failed: ", result.Detail)
+			failures = append(failures, fmt.Sprintf("%s: %s, %s", result.Name, result.Detail, result.Remediation))
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.Errorf("%d self-check(s) failed:\n  - %s", len(failures), strings.Join(failures, "\n  - "))
+	}
+	return nil
+}
+
+func newStartupContext() utils.ContextWithValue {
+	ctx := utils.NewValueContext(context.Background())
+	ctx.Set("x-request-id", uuid.New().String())
+	ctx.Set("request-start-time", time.Now())
+	return ctx
+}
+
+// listenHost returns the address the HTTP server listens on.
+func listenHost(cfg config.Config) string {
+	if utils.StringRemoveAllSpace(cfg.Host) == "" {
+		return defaultHost
+	}
+	return cfg.Host
+}
+
+// startupSummary builds the fields of the startup summary. The auth methods and some features are switched by the
+// system settings, settings holds their effective values. No secret is included.
+func startupSummary(cfg config.Config, settings entity.SystemSettingsEntity, interfaceAddrs func() ([]net.Addr, error)) map[string]any {
+	summary := map[string]any{
+		"version":            buildinfo.Version,
+		"revision":           buildinfo.Revision(),
+		"env":                config.GetEnvName(),
+		"deployment_profile": cfg.DeploymentProfile,
+		"serverless":         cfg.Serverless,
+		"database":           databaseSummary(cfg),
+		"key_value_store":    keyValueStoreSummary(cfg),
+		"auth_methods":       authMethods(settings),
+		"features":           enabledFeatures(cfg, settings),
+	}
+	if !cfg.Serverless {
+		summary["listen"] = listenHost(cfg)
+		summary["listen_addresses"] = listenAddresses(listenHost(cfg), interfaceAddrs)
+	}
+	return summary
+}
+
+func databaseSummary(cfg config.Config) string {
+	switch cfg.DBType {
+	case "sqlite":
+		return "sqlite " + cfg.SqlitePath
+	case "mysql":
+		return fmt.Sprintf("mysql %s/%s", net.JoinHostPort(cfg.MysqlHost, lo.CoalesceOrEmpty(cfg.MysqlPort, "3306")), cfg.MysqlDB)
+	case "postgres":
+		return fmt.Sprintf("postgres %s/%s sslmode=%s", net.JoinHostPort(cfg.PostgresHost, lo.CoalesceOrEmpty(cfg.PostgresPort, "5432")), cfg.PostgresDB, cfg.PostgresSSLMode)
+	default:
+		return cfg.DBType
+	}
+}
+
+func keyValueStoreSummary(cfg config.Config) string {
+	switch cfg.KeyValueDBType {
+	case "nutsdb":
+		return "nutsdb " + cfg.NutsDBPath
+	case "redis":
+		summary := fmt.Sprintf("redis %s/%d", net.JoinHostPort(cfg.RedisHost, fmt.Sprint(cfg.RedisPort)), cfg.RedisDB)
+		if cfg.RedisTLS {
+			summary += " tls"
+		}
+		return summary
+	case "rds":
+		return "rds, stored in the " + cfg.DBType + " database"
+	default:
+		return cfg.KeyValueDBType
+	}
+}
+
+// authMethods lists the ways users can sign in, passkeys are always offered.
+func authMethods(settings entity.SystemSettingsEntity) []string {
+	methods := []string{"passkey"}
+	if settings.EnablePasswordLogin {
+		methods = append(methods, "password")
+	}
+	if settings.EnableGithubSSO {
+		methods = append(methods, "github")
+	}
+	if settings.EnableGoogleSSO {
+		methods = append(methods, "google")
+	}
+	return methods
+}
+
+// enabledFeatures lists the optional features that are on, with the backend after a colon where there is a choice.
+func enabledFeatures(cfg config.Config, settings entity.SystemSettingsEntity) []string {
+	features := []string{}
+	add := func(enabled bool, feature string) {
+		if enabled {
+			features = append(features, feature)
+		}
+	}
+	add(settings.EnableUserRegistration, "user_registration")
+	add(cfg.DemoMode, "demo_mode")
+	add(settings.MaintenanceMessage != "", "maintenance_message")
+	add(cfg.SchedulerEnabled && !cfg.Serverless, "scheduler")
+	add(settings.DatabaseBackupSchedule != "", "database_backup")
+	add(cfg.OIDCProviderEnabled, "oidc_provider")
+	add(cfg.MeteringEnabled, "metering")
+	add(cfg.UsageEnforcer != "none", "usage_enforcer:"+cfg.UsageEnforcer)
+	add(cfg.Mailer != "none", "mailer:"+cfg.Mailer)
+	add(cfg.SIEMExport != "none", "siem_export:"+cfg.SIEMExport)
+	add(cfg.ImportScanner != "none", "import_scanner:"+cfg.ImportScanner)
+	add(cfg.ToolSecretScanMode != "off", "tool_secret_scan:"+cfg.ToolSecretScanMode)
+	add(cfg.RevokeSessionsOnPasswordChange, "revoke_sessions_on_password_change")
+	add(cfg.RevokeSessionsOn2FAEnable, "revoke_sessions_on_2fa_enable")
+	add(cfg.RefreshTokenRotation, "refresh_token_rotation")
+	add(cfg.CacheFallbackEnabled, "cache_fallback")
+	return features
+}
+
+// listenAddresses resolves a wildcard listen address to the addresses of the network interfaces, they are what
+// clients connect to. Other addresses are returned as they are.
+func listenAddresses(host string, interfaceAddrs func() ([]net.Addr, error)) []string {
+	ip, port, err := net.SplitHostPort(host)
+	if err != nil || (ip != "" && !net.ParseIP(ip).IsUnspecified()) {
+		return []string{host}
+	}
+
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		return []string{host}
+	}
+	addresses := []string{}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		// a 0.0.0.0 listener only accepts ipv4
+		if ip == "0.0.0.0" && ipNet.IP.To4() == nil {
+			continue
+		}
+		addresses = append(addresses, net.JoinHostPort(ipNet.IP.String(), port))
+	}
+	if len(addresses) == 0 {
+		return []string{host}
+	}
+	return addresses
+}
+
+func runSelfChecks(ctx context.Context, cfg config.Config, clockOffset func(ctx context.Context, server string) (time.Duration, error)) []selfCheckResult {
+	var results []selfCheckResult
+	for _, dir := range dataDirs(cfg) {
+		results = append(results, checkDataDir(dir))
+	}
+	if cfg.SelfCheckNTPServer != "none" {
+		results = append(results, checkClockSkew(ctx, cfg, clockOffset))
+	}
+	results = append(results, checkWebAuthn(cfg)...)
+	return results
+}
+
+// dataDir is a local directory a store writes to, setting is the env variable that points there
+type dataDir struct {
+	setting string
+	path    string
+}
+
+// dataDirs lists the local directories the configured stores write to.
+func dataDirs(cfg config.Config) []dataDir {
+	var dirs []dataDir
+	add := func(setting string, path string) {
+		if path == "" || lo.ContainsBy(dirs, func(d dataDir) bool { return d.path == path }) {
+			return
+		}
+		dirs = append(dirs, dataDir{setting: setting, path: path})
+	}
+	if cfg.DBType == "sqlite" && cfg.SqlitePath != "memory" {
+		add("SQLLITE_PATH", filepath.Dir(cfg.SqlitePath))
+	}
+	if cfg.KeyValueDBType == "nutsdb" {
+		add("NUTSDB_PATH", cfg.NutsDBPath)
+	}
+	if cfg.ConfigFilePath != "memory" {
+		add("CONFIG_FILE_PATH", filepath.Dir(cfg.ConfigFilePath))
+	}
+	if cfg.ScheduleDatabaseBackup != "" {
+		add("BACKUP_DIR", cfg.BackupDir)
+	}
+	if cfg.SIEMExport == "file" && cfg.SIEMFilePath != "" {
+		add("SIEM_FILE_PATH", filepath.Dir(cfg.SIEMFilePath))
+	}
+	return dirs
+}
+
+// checkDataDir creates the directory when it is missing and writes a probe file into it.
+func checkDataDir(dir dataDir) selfCheckResult {
+	setting, path := dir.setting, dir.path
+	result := selfCheckResult{Name: "data_dir", Status: selfCheckOK, Detail: fmt.Sprintf("%s is writable", path)}
+
+	err := os.MkdirAll(path, 0755)
+	if err == nil {
+		var probe *os.File
+		if probe, err = os.CreateTemp(path, ".toolbake-self-check-*"); err == nil {
+			_ = probe.Close()
+			err = os.Remove(probe.Name())
+		}
+	}
+	if err != nil {
+		result.Status = selfCheckFail
+		result.Detail = fmt.Sprintf("%s of %s is not writable: %v", path, setting, err)
+		result.Remediation = fmt.Sprintf("give the user running ToolBake write access to %s, or point %s to a writable location", path, setting)
+	}
+	return result
+}
+
+// checkClockSkew compares the clock with SELF_CHECK_NTP_SERVER. Access tokens, TOTP codes and WebAuthn challenges
+// expire by the clock, a clock that is off signs users out or rejects their codes.
+func checkClockSkew(ctx context.Context, cfg config.Config, clockOffset func(ctx context.Context, server string) (time.Duration, error)) selfCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, clockCheckTimeout)
+	defer cancel()
+
+	result := selfCheckResult{Name: "clock_skew"}
+	offset, err := clockOffset(ctx, cfg.SelfCheckNTPServer)
+	if err != nil {
+		result.Status = selfCheckWarn
+		result.Detail = fmt.Sprintf("could not compare the clock with %s: %v", cfg.SelfCheckNTPServer, err)
+		result.Remediation = "allow outbound UDP port 123 to the server, set SELF_CHECK_NTP_SERVER to a reachable ntp server, or set it to none to skip the check"
+		return result
+	}
+
+	direction := "ahead of"
+	if offset < 0 {
+		direction = "behind"
+	}
+	result.Detail = fmt.Sprintf("the clock is %s %s %s", offset.Abs().Round(time.Millisecond), direction, cfg.SelfCheckNTPServer)
+	maxSkew := time.Duration(cfg.SelfCheckMaxClockSkew) * time.Second
+	if offset.Abs() > maxSkew {
+		result.Status = selfCheckFail
+		result.Detail += fmt.Sprintf(", more than SELF_CHECK_MAX_CLOCK_SKEW=%d seconds", cfg.SelfCheckMaxClockSkew)
+		result.Remediation = "synchronize the clock of the host, e.g. with chrony or systemd-timesyncd, tokens and TOTP codes are checked against it"
+		return result
+	}
+	result.Status = selfCheckOK
+	return result
+}
+
+// checkWebAuthn warns about WebAuthn settings that pass the config validation but keep passkeys from working:
+// browsers only offer WebAuthn on https or on localhost.
+func checkWebAuthn(cfg config.Config) []selfCheckResult {
+	var results []selfCheckResult
+	envName := config.GetEnvName()
+	if cfg.WebAuthnRPID == "localhost" && (envName == "production" || envName == "staging") {
+		results = append(results, selfCheckResult{
+			Name:        "webauthn",
+			Status:      selfCheckWarn,
+			Detail:      fmt.Sprintf("WEBAUTHN_RP_ID is localhost in %s, passkeys only work when ToolBake is opened on localhost", envName),
+			Remediation: "set WEBAUTHN_RP_ID to the domain ToolBake is served on and WEBAUTHN_RP_ORIGIN to its https origin",
+		})
+	}
+	for _, origin := range cfg.WebAuthnAllowedOrigins() {
+		parsed, err := url.Parse(origin)
+		if err != nil || parsed.Scheme != "http" || isLoopbackHost(parsed.Hostname()) {
+			continue
+		}
+		results = append(results, selfCheckResult{
+			Name:        "webauthn",
+			Status:      selfCheckWarn,
+			Detail:      fmt.Sprintf("WEBAUTHN_RP_ORIGIN %q is plain http, browsers refuse passkeys on it", origin),
+			Remediation: "serve ToolBake over https and list the https origin in WEBAUTHN_RP_ORIGIN",
+		})
+	}
+	if len(results) == 0 {
+		results = append(results, selfCheckResult{
+			Name:   "webauthn",
+			Status: selfCheckOK,
+			Detail: fmt.Sprintf("WEBAUTHN_RP_ID %s matches the origins %s", cfg.WebAuthnRPID, strings.Join(cfg.WebAuthnAllowedOrigins(), ", ")),
+		})
+	}
+	return results
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,202 @@
+package engine
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startNTPServer answers every request with the local time shifted by skew, stratum 0 sends a kiss-o'-death
+func startNTPServer(t *testing.T, skew time.Duration, stratum byte) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, ntpPacketSize)
+		for {
+			_, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			response := make([]byte, ntpPacketSize)
+			response[0] = 0x24 // version 4, mode 4 (server)
+			response[1] = stratum
+			copy(response[12:16], "RATE")
+			now := time.Now().Add(skew)
+			putNTPTimestamp(response[32:40], now)
+			putNTPTimestamp(response[40:48], now)
+			_, _ = conn.WriteTo(response, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func putNTPTimestamp(b []byte, t time.Time) {
+	binary.BigEndian.PutUint32(b[0:4], uint32(t.Unix()+ntpEpochOffset))
+	binary.BigEndian.PutUint32(b[4:8], uint32((uint64(t.Nanosecond())<<32)/uint64(time.Second)))
+}
+
+func TestQueryClockOffset(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	t.Run("local clock behind the server", func(t *testing.T) {
+		offset, err := queryClockOffset(ctx, startNTPServer(t, time.Hour, 2))
+
+		require.NoError(t, err)
+		assert.InDelta(t, float64(-time.Hour), float64(offset), float64(time.Second))
+	})
+
+	t.Run("kiss-o'-death", func(t *testing.T) {
+		_, err := queryClockOffset(ctx, startNTPServer(t, 0, 0))
+
+		require.ErrorContains(t, err, "refused the request: RATE")
+	})
+}
+
+func TestNTPTimestamp_Era1(t *testing.T) {
+	b := make([]byte, 8)
+	want := time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC)
+	putNTPTimestamp(b, want)
+
+	assert.True(t, want.Equal(ntpTimestamp(b)))
+}
+
+func TestCheckDataDir(t *testing.T) {
+	t.Run("creates a missing directory", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "data")
+
+		result := checkDataDir(dataDir{setting: "NUTSDB_PATH", path: path})
+
+		assert.Equal(t, selfCheckOK, result.Status)
+		assert.DirExists(t, path)
+		entries, err := os.ReadDir(path)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "the probe file is removed")
+	})
+
+	t.Run("fails below a file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0600))
+
+		result := checkDataDir(dataDir{setting: "SQLLITE_PATH", path: filepath.Join(file, "data")})
+
+		assert.Equal(t, selfCheckFail, result.Status)
+		assert.Contains(t, result.Remediation, "SQLLITE_PATH")
+	})
+}
+
+func TestDataDirs(t *testing.T) {
+	cfg := config.Config{
+		DBType:         "sqlite",
+		SqlitePath:     "data/sqlite.db",
+		KeyValueDBType: "nutsdb",
+		NutsDBPath:     "data/nutsdb",
+		ConfigFilePath: "data/config.json",
+		BackupDir:      "data/backups",
+	}
+
+	assert.Equal(t, []dataDir{{setting: "SQLLITE_PATH", path: "data"}, {setting: "NUTSDB_PATH", path: "data/nutsdb"}}, dataDirs(cfg))
+
+	cfg.DBType = "mysql"
+	cfg.KeyValueDBType = "redis"
+	cfg.ConfigFilePath = "memory"
+	cfg.ScheduleDatabaseBackup = "0 3 * * *"
+	assert.Equal(t, []dataDir{{setting: "BACKUP_DIR", path: "data/backups"}}, dataDirs(cfg))
+}
+
+func TestCheckClockSkew(t *testing.T) {
+	cfg := config.Config{SelfCheckNTPServer: "ntp.test", SelfCheckMaxClockSkew: 30}
+	offsetOf := func(offset time.Duration, err error) func(context.Context, string) (time.Duration, error) {
+		return func(context.Context, string) (time.Duration, error) { return offset, err }
+	}
+
+	result := checkClockSkew(context.Background(), cfg, offsetOf(-2*time.Second, nil))
+	assert.Equal(t, selfCheckOK, result.Status)
+	assert.Equal(t, "the clock is 2s behind ntp.test", result.Detail)
+
+	result = checkClockSkew(context.Background(), cfg, offsetOf(45*time.Second, nil))
+	assert.Equal(t, selfCheckFail, result.Status)
+	assert.Equal(t, "the clock is 45s ahead of ntp.test, more than SELF_CHECK_MAX_CLOCK_SKEW=30 seconds", result.Detail)
+	assert.NotEmpty(t, result.Remediation)
+
+	result = checkClockSkew(context.Background(), cfg, offsetOf(0, errors.New("i/o timeout")))
+	assert.Equal(t, selfCheckWarn, result.Status, "an unreachable server does not stop the start")
+	assert.Contains(t, result.Remediation, "SELF_CHECK_NTP_SERVER")
+}
+
+func TestCheckWebAuthn(t *testing.T) {
+	cfg := config.Config{WebAuthnRPID: "toolbake.test", WebAuthnRPOrigins: []string{"https://toolbake.test", "http://app.toolbake.test"}}
+
+	results := checkWebAuthn(cfg)
+
+	require.Len(t, results, 1)
+	assert.Equal(t, selfCheckWarn, results[0].Status)
+	assert.Contains(t, results[0].Detail, `"http://app.toolbake.test" is plain http`)
+
+	cfg = config.Config{WebAuthnRPID: "localhost", WebAuthnRPOrigins: []string{"http://localhost:8080"}}
+	results = checkWebAuthn(cfg)
+	require.Len(t, results, 1)
+	assert.Equal(t, selfCheckOK, results[0].Status)
+}
+
+func TestListenAddresses(t *testing.T) {
+	interfaceAddrs := func() ([]net.Addr, error) {
+		return []net.Addr{
+			&net.IPNet{IP: net.ParseIP("127.0.0.1")},
+			&net.IPNet{IP: net.ParseIP("10.0.0.5")},
+			&net.IPNet{IP: net.ParseIP("fe80::1")},
+			&net.IPNet{IP: net.ParseIP("2001:db8::5")},
+		}, nil
+	}
+
+	assert.Equal(t, []string{"127.0.0.1:8080", "10.0.0.5:8080"}, listenAddresses("0.0.0.0:8080", interfaceAddrs))
+	assert.Equal(t, []string{"127.0.0.1:8080", "10.0.0.5:8080", "[2001:db8::5]:8080"}, listenAddresses(":8080", interfaceAddrs))
+	assert.Equal(t, []string{"10.0.0.5:9000"}, listenAddresses("10.0.0.5:9000", interfaceAddrs))
+}
+
+func TestStartupSummary(t *testing.T) {
+	cfg := config.Config{
+		DBType:             "postgres",
+		PostgresHost:       "db.internal",
+		PostgresDB:         "toolbake",
+		PostgresPass:       "secret",
+		PostgresSSLMode:    "require",
+		KeyValueDBType:     "redis",
+		RedisHost:          "redis.internal",
+		RedisPort:          6379,
+		RedisPassword:      "secret",
+		Host:               "10.0.0.5:9000",
+		Mailer:             "smtp",
+		UsageEnforcer:      "none",
+		SIEMExport:         "none",
+		ImportScanner:      "none",
+		ToolSecretScanMode: "off",
+		SchedulerEnabled:   true,
+	}
+	settings := entity.SystemSettingsEntity{EnablePasswordLogin: true, EnableGoogleSSO: true}
+
+	summary := startupSummary(cfg, settings, net.InterfaceAddrs)
+
+	assert.Equal(t, "postgres db.internal:5432/toolbake sslmode=require", summary["database"])
+	assert.Equal(t, "redis redis.internal:6379/0", summary["key_value_store"])
+	assert.Equal(t, []string{"passkey", "password", "google"}, summary["auth_methods"])
+	assert.Equal(t, []string{"scheduler", "mailer:smtp"}, summary["features"])
+	assert.Equal(t, []string{"10.0.0.5:9000"}, summary["listen_addresses"])
+	for key, value := range summary {
+		assert.NotContains(t, fmt.Sprint(value), "secret", key)
+	}
+}
@@ -96,3 +96,18 @@ func Debug(ctx context.Context, args ...any) {
 func Debugf(ctx context.Context, format string, args ...any) {
 	withExtraInfo(ctx).Debugf(format, args...)
 }
+
+// InfoFields  logrus.WithFields(fields).Info, the fields are queryable in the json log format
+func InfoFields(ctx context.Context, fields map[string]any, args ...any) {
+	withExtraInfo(ctx).WithFields(fields).Info(args...)
+}
+
+// WarnFields  logrus.WithFields(fields).Warn
+func WarnFields(ctx context.Context, fields map[string]any, args ...any) {
+	withExtraInfo(ctx).WithFields(fields).Warn(args...)
+}
+
+// ErrorFields  logrus.WithFields(fields).Error
+func ErrorFields(ctx context.Context, fields map[string]any, args ...any) {
+	withExtraInfo(ctx).WithFields(fields).Error(args...)
+}
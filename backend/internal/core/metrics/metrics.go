@@ -0,0 +1,93 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// SlowQueriesTotal counts the database statements that took longer than SLOW_QUERY_THRESHOLD, by operation (exec or query).
+var SlowQueriesTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "toolbake_slow_queries_total",
+		Help: "Database statements slower than the slow query threshold.",
+	},
+	[]string{"operation"},
+)
+
+// ScheduledJobRunsTotal counts the finished runs of the scheduled housekeeping jobs, by job and status (success or failed).
+var ScheduledJobRunsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "toolbake_scheduled_job_runs_total",
+		Help: "Finished runs of the scheduled housekeeping jobs.",
+	},
+	[]string{"job", "status"},
+)
+
+// DataRetentionPurgedRowsTotal counts the records the data retention job deleted, by category (audit_logs).
+var DataRetentionPurgedRowsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "toolbake_data_retention_purged_rows_total",
+		Help: "Records deleted by the data retention job once older than their retention window.",
+	},
+	[]string{"category"},
+)
+
+// TokenIssuanceAnomaliesTotal counts the flagged refresh token issuance anomalies, by kind (user_spike, user_many_ips or global_spike).
+var TokenIssuanceAnomaliesTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "toolbake_token_issuance_anomalies_total",
+		Help: "Flagged anomalies of the refresh token issuance rate.",
+	},
+	[]string{"kind"},
+)
+
+// SIEMEventsTotal counts the security events handed to the siem export, by status (exported, buffer_full or delivery_failed).
+var SIEMEventsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "toolbake_siem_events_total",
+		Help: "Security events handed to the SIEM export.",
+	},
+	[]string{"status"},
+)
+
+// PasskeyLoginChallengesTotal counts the anonymous passkey login challenges, by result (created, completed, rate_limited or limit_reached).
+var PasskeyLoginChallengesTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "toolbake_passkey_login_challenges_total",
+		Help: "Passkey login challenges created, completed and refused.",
+	},
+	[]string{"result"},
+)
+
+// PasskeyLoginChallengesOutstanding is the number of passkey login challenges created but neither completed nor expired.
+var PasskeyLoginChallengesOutstanding = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "toolbake_passkey_login_challenges_outstanding",
+		Help: "Passkey login challenges waiting to be completed on this instance.",
+	},
+)
+
+// ToolExecutionsTotal counts the tool runs on the server, by result (success, failed, timed_out, too_large,
+// out_of_memory, rate_limited or busy).
+var ToolExecutionsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "toolbake_tool_executions_total",
+		Help: "Tool runs on the server by result.",
+	},
+	[]string{"result"},
+)
+
+// CacheFallbackOperationsTotal counts the cache operations served by the memory fallback because the cache backend failed,
+// by operation (set, get, delete or has).
+var CacheFallbackOperationsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "toolbake_cache_fallback_operations_total",
+		Help: "Cache operations served by the memory fallback while the cache backend failed.",
+	},
+	[]string{"operation"},
+)
+
+// CacheDegraded is 1 while the cache backend fails and the memory fallback serves the cache, 0 otherwise.
+var CacheDegraded = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "toolbake_cache_degraded",
+		Help: "Whether the cache is served by the memory fallback on this instance.",
+	},
+)
@@ -0,0 +1,15 @@
+package requestid
+
+import (
+	"context"
+	"ya-tool-craft/internal/error_code"
+)
+
+// GetClientIP returns the ip of the client that sent the request, empty outside of a request
+func GetClientIP(ctx context.Context) string {
+	if ctx == nil {
+		panic(error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "get client ip from context failed, context is nil"))
+	}
+	clientIP, _ := ctx.Value("client-ip").(string)
+	return clientIP
+}
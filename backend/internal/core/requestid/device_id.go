@@ -0,0 +1,15 @@
+package requestid
+
+import (
+	"context"
+	"ya-tool-craft/internal/error_code"
+)
+
+// GetDeviceID returns the device the request named in the X-Device-ID header, empty when it named none
+func GetDeviceID(ctx context.Context) string {
+	if ctx == nil {
+		panic(error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "get device id from context failed, context is nil"))
+	}
+	deviceID, _ := ctx.Value("device-id").(string)
+	return deviceID
+}
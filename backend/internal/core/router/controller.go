@@ -9,6 +9,9 @@ type RouterInfo struct {
 	Path        string
 	Handler     gin.HandlerFunc
 	Middlewares []gin.HandlerFunc
+	// DemoModeAllowed keeps the route open when DEMO_MODE is set, every other route is rejected. Only routes that
+	// store nothing, or start or end a session, set it.
+	DemoModeAllowed bool
 }
 
 type Controller interface {
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	t.Parallel()
+
+	// a Wednesday
+	from := time.Date(2026, 1, 14, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		spec string
+		want time.Time
+	}{
+		{name: "every minute", spec: "* * * * *", want: time.Date(2026, 1, 14, 10, 18, 0, 0, time.UTC)},
+		{name: "daily later today", spec: "30 12 * * *", want: time.Date(2026, 1, 14, 12, 30, 0, 0, time.UTC)},
+		{name: "daily tomorrow", spec: "0 4 * * *", want: time.Date(2026, 1, 15, 4, 0, 0, 0, time.UTC)},
+		{name: "step", spec: "*/15 * * * *", want: time.Date(2026, 1, 14, 10, 30, 0, 0, time.UTC)},
+		{name: "range with step", spec: "0 1-23/6 * * *", want: time.Date(2026, 1, 14, 13, 0, 0, 0, time.UTC)},
+		{name: "list", spec: "0 9,21 * * *", want: time.Date(2026, 1, 14, 21, 0, 0, 0, time.UTC)},
+		{name: "day of week", spec: "0 0 * * 1", want: time.Date(2026, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{name: "sunday as 7", spec: "0 0 * * 7", want: time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)},
+		{name: "day of month or day of week", spec: "0 0 20 * 5", want: time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "month rolls the year", spec: "0 0 1 1 *", want: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "descriptor", spec: "@daily", want: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{name: "every", spec: "@every 6h", want: time.Date(2026, 1, 14, 16, 17, 30, 0, time.UTC)},
+		{name: "never matches", spec: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			schedule, err := ParseCron(tt.spec)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, schedule.Next(from))
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every 30s",
+		"@every soon",
+		"@sometimes",
+	}
+
+	for _, spec := range tests {
+		t.Run(spec, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseCron(spec)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/metrics"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// schedulerMaxSleep bounds how long the loop sleeps, so a schedule changed at runtime is picked up within it
+const schedulerMaxSleep = time.Minute
+
+// Job is a piece of housekeeping run by the scheduler.
+type Job struct {
+	Name        string
+	Description string
+	// Spec returns the cron expression of the job, an empty one disables it.
+	// It is read on every loop so it can come from a setting changed at runtime.
+	Spec func(ctx context.Context) (string, error)
+	Run  func(ctx context.Context) error
+}
+
+type jobState struct {
+	job Job
+
+	spec     string
+	specErr  error
+	schedule CronSchedule
+	next     time.Time
+	running  bool
+	lastRun  *entity.ScheduledJobRunEntity
+}
+
+func NewScheduler(clock client.IClock) *Scheduler {
+	return &Scheduler{clock: clock}
+}
+
+// Scheduler runs the registered jobs on their cron schedule, one run of a job at a time.
+// The state is kept in memory, every instance of the server runs its own jobs.
+type Scheduler struct {
+	clock client.IClock
+
+	mu      sync.Mutex
+	jobs    []*jobState
+	stop    chan struct{}
+	stopped chan struct{}
+	runs    sync.WaitGroup
+}
+
+// Register adds jobs, it must be called before Start.
+func (s *Scheduler) Register(jobs ...Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range jobs {
+		s.jobs = append(s.jobs, &jobState{job: job})
+	}
+}
+
+// Start runs the scheduling loop in the background until Stop.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.stop = make(chan struct{})
+	s.stopped = make(chan struct{})
+	s.mu.Unlock()
+
+	go func() {
+		defer close(s.stopped)
+		for {
+			wait := s.tick(s.newRunContext())
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(wait):
+			}
+		}
+	}()
+}
+
+// Stop ends the scheduling loop and waits for the running jobs to finish.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	stop, stopped := s.stop, s.stopped
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-stopped
+	s.runs.Wait()
+}
+
+// tick refreshes the schedules, starts the jobs that are due and returns how long to sleep until the next one.
+func (s *Scheduler) tick(ctx context.Context) time.Duration {
+	s.mu.Lock()
+	jobs := append([]*jobState(nil), s.jobs...)
+	s.mu.Unlock()
+
+	// the specs may hit the database, they are read without holding the lock
+	specs := make([]string, len(jobs))
+	specErrs := make([]error, len(jobs))
+	for i, state := range jobs {
+		specs[i], specErrs[i] = state.job.Spec(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	wait := schedulerMaxSleep
+	for i, state := range jobs {
+		s.refreshSchedule(ctx, state, specs[i], specErrs[i], now)
+		if state.schedule == nil || state.next.IsZero() {
+			continue
+		}
+		if !now.Before(state.next) {
+			if !state.running {
+				s.startRun(state, entity.ScheduledJobTriggerSchedule)
+			}
+			state.next = state.schedule.Next(now)
+			if state.next.IsZero() {
+				continue
+			}
+		}
+		wait = min(wait, state.next.Sub(now))
+	}
+	return max(wait, time.Second)
+}
+
+// refreshSchedule parses the spec again when it changed, the next run is computed from now.
+func (s *Scheduler) refreshSchedule(ctx context.Context, state *jobState, spec string, specErr error, now time.Time) {
+	if specErr != nil {
+		// keep the last known schedule when the source of the spec is unavailable for a moment
+		logger.Warnf(ctx, "Failed to get the schedule of job %s: %v", state.job.Name, specErr)
+		return
+	}
+	if spec == state.spec && (state.schedule != nil || state.specErr != nil || spec == "") {
+		return
+	}
+
+	state.spec, state.schedule, state.specErr, state.next = spec, nil, nil, time.Time{}
+	if spec == "" {
+		logger.Infof(ctx, "Scheduled job %s is disabled", state.job.Name)
+		return
+	}
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		state.specErr = err
+		logger.Errorf(ctx, "Invalid schedule %q of job %s: %v", spec, state.job.Name, err)
+		return
+	}
+	state.schedule = schedule
+	state.next = schedule.Next(now)
+	logger.Infof(ctx, "Scheduled job %s with %q, next run at %s", state.job.Name, spec, state.next)
+}
+
+// startRun runs the job in the background, the caller holds the lock.
+func (s *Scheduler) startRun(state *jobState, trigger entity.ScheduledJobTrigger) {
+	state.running = true
+	s.runs.Add(1)
+
+	go func() {
+		defer s.runs.Done()
+
+		runCtx := utils.NewValueContext(context.Background())
+		runCtx.Set("x-request-id", uuid.New().String())
+		runCtx.Set("request-start-time", s.clock.Now())
+
+		run := entity.ScheduledJobRunEntity{Trigger: trigger, StartedAt: s.clock.Now()}
+		logger.Infof(runCtx, "Scheduled job %s started (%s)", state.job.Name, trigger)
+		err := runJob(runCtx, state.job)
+		run.Duration = s.clock.Now().Sub(run.StartedAt)
+		run.Status = entity.ScheduledJobRunStatusSuccess
+		if err != nil {
+			run.Status = entity.ScheduledJobRunStatusFailed
+			run.Error = err.Error()
+			logger.Errorf(runCtx, "Scheduled job %s failed after %s: %v", state.job.Name, run.Duration, err)
+		} else {
+			logger.Infof(runCtx, "Scheduled job %s finished in %s", state.job.Name, run.Duration)
+		}
+		metrics.ScheduledJobRunsTotal.WithLabelValues(state.job.Name, string(run.Status)).Inc()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		state.running = false
+		state.lastRun = &run
+	}()
+}
+
+// newRunContext gives the loop and every run their own request id, so their logs can be told apart
+func (s *Scheduler) newRunContext() context.Context {
+	ctx := utils.NewValueContext(context.Background())
+	ctx.Set("x-request-id", uuid.New().String())
+	ctx.Set("request-start-time", s.clock.Now())
+	return ctx
+}
+
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// Trigger starts a run of the job now, next to its schedule.
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, state := range s.jobs {
+		if state.job.Name != name {
+			continue
+		}
+		if state.running {
+			return error_code.NewErrorWithErrorCodef(error_code.ScheduledJobAlreadyRunning, "job %s is already running", name)
+		}
+		s.startRun(state, entity.ScheduledJobTriggerManual)
+		return nil
+	}
+	return error_code.NewErrorWithErrorCodef(error_code.ScheduledJobNotFound, "unknown job: %s", name)
+}
+
+// Jobs lists the registered jobs with their schedule and last run in this process.
+func (s *Scheduler) Jobs() []entity.ScheduledJobEntity {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]entity.ScheduledJobEntity, 0, len(s.jobs))
+	for _, state := range s.jobs {
+		job := entity.ScheduledJobEntity{
+			Name:        state.job.Name,
+			Description: state.job.Description,
+			Schedule:    state.spec,
+			Running:     state.running,
+		}
+		if state.specErr != nil {
+			job.ScheduleError = state.specErr.Error()
+		}
+		if !state.next.IsZero() {
+			next := state.next
+			job.NextRunAt = &next
+		}
+		if state.lastRun != nil {
+			lastRun := *state.lastRun
+			job.LastRun = &lastRun
+		}
+		result = append(result, job)
+	}
+	return result
+}
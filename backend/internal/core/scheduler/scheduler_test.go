@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func staticSpec(spec string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) { return spec, nil }
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	clock := fixtures.NewFakeClock(time.Date(2026, 1, 14, 3, 59, 30, 0, time.UTC))
+	s := NewScheduler(clock)
+
+	var runs atomic.Int32
+	s.Register(
+		Job{Name: "cleanup", Spec: staticSpec("0 4 * * *"), Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		}},
+		Job{Name: "disabled", Spec: staticSpec(""), Run: func(ctx context.Context) error { return nil }},
+		Job{Name: "broken", Spec: staticSpec("not a cron"), Run: func(ctx context.Context) error { return nil }},
+	)
+
+	// the first tick only schedules, the wait is bounded by the next run
+	require.Equal(t, 30*time.Second, s.tick(context.Background()))
+	require.Zero(t, runs.Load())
+
+	clock.Advance(30 * time.Second)
+	s.tick(context.Background())
+	s.runs.Wait()
+	require.EqualValues(t, 1, runs.Load())
+
+	jobs := s.Jobs()
+	require.Len(t, jobs, 3)
+
+	require.Equal(t, "0 4 * * *", jobs[0].Schedule)
+	require.Equal(t, time.Date(2026, 1, 15, 4, 0, 0, 0, time.UTC), *jobs[0].NextRunAt)
+	require.NotNil(t, jobs[0].LastRun)
+	require.Equal(t, entity.ScheduledJobTriggerSchedule, jobs[0].LastRun.Trigger)
+	require.Equal(t, entity.ScheduledJobRunStatusSuccess, jobs[0].LastRun.Status)
+
+	require.Empty(t, jobs[1].Schedule)
+	require.Nil(t, jobs[1].NextRunAt)
+
+	require.NotEmpty(t, jobs[2].ScheduleError)
+	require.Nil(t, jobs[2].NextRunAt)
+}
+
+func TestScheduler_TickKeepsScheduleWhenSpecFails(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	clock := fixtures.NewFakeClock(time.Date(2026, 1, 14, 3, 0, 0, 0, time.UTC))
+	s := NewScheduler(clock)
+
+	var failing atomic.Bool
+	s.Register(Job{
+		Name: "cleanup",
+		Spec: func(ctx context.Context) (string, error) {
+			if failing.Load() {
+				return "", errors.New("database is down")
+			}
+			return "0 4 * * *", nil
+		},
+		Run: func(ctx context.Context) error { return nil },
+	})
+
+	s.tick(context.Background())
+	failing.Store(true)
+	s.tick(context.Background())
+
+	jobs := s.Jobs()
+	require.Equal(t, "0 4 * * *", jobs[0].Schedule)
+	require.NotNil(t, jobs[0].NextRunAt)
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	clock := fixtures.NewFakeClock(time.Date(2026, 1, 14, 3, 0, 0, 0, time.UTC))
+	s := NewScheduler(clock)
+
+	release := make(chan struct{})
+	s.Register(
+		Job{Name: "slow", Spec: staticSpec(""), Run: func(ctx context.Context) error {
+			<-release
+			return nil
+		}},
+		Job{Name: "failing", Spec: staticSpec(""), Run: func(ctx context.Context) error {
+			return errors.New("boom")
+		}},
+		Job{Name: "panicking", Spec: staticSpec(""), Run: func(ctx context.Context) error {
+			panic("boom")
+		}},
+	)
+
+	require.NoError(t, s.Trigger(context.Background(), "slow"))
+
+	var ecErr error_code.ErrorWithErrorCode
+	err := s.Trigger(context.Background(), "slow")
+	require.ErrorAs(t, err, &ecErr)
+	require.Equal(t, error_code.ScheduledJobAlreadyRunning.Code, ecErr.ErrorCode.Code)
+
+	err = s.Trigger(context.Background(), "missing")
+	require.ErrorAs(t, err, &ecErr)
+	require.Equal(t, error_code.ScheduledJobNotFound.Code, ecErr.ErrorCode.Code)
+
+	require.True(t, s.Jobs()[0].Running)
+	close(release)
+
+	require.NoError(t, s.Trigger(context.Background(), "failing"))
+	require.NoError(t, s.Trigger(context.Background(), "panicking"))
+	s.runs.Wait()
+
+	jobs := s.Jobs()
+	require.False(t, jobs[0].Running)
+	require.Equal(t, entity.ScheduledJobTriggerManual, jobs[0].LastRun.Trigger)
+	require.Equal(t, entity.ScheduledJobRunStatusSuccess, jobs[0].LastRun.Status)
+	require.Equal(t, entity.ScheduledJobRunStatusFailed, jobs[1].LastRun.Status)
+	require.Equal(t, "boom", jobs[1].LastRun.Error)
+	require.Equal(t, entity.ScheduledJobRunStatusFailed, jobs[2].LastRun.Status)
+	require.Contains(t, jobs[2].LastRun.Error, "panicked")
+}
@@ -1,9 +1,11 @@
 package di
 
 import (
+	"io"
 	"ya-tool-craft/internal/application"
 	"ya-tool-craft/internal/application/controller/common"
 	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/scheduler"
 	domain_client "ya-tool-craft/internal/domain/client"
 	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/domain/service"
@@ -43,22 +45,54 @@ func InitDI() {
 	// 	repositoryBackendType = "rds"
 	case "sqlite":
 		bind(infra_client.NewSqliteClient, new(repository.IRdsClient))
+		bind(repository_impl.NewRdsMaintenanceSqliteImpl, new(repository.IRdsMaintenance))
 		repositoryBackendType = "rds"
 	case "mysql":
 		bind(infra_client.NewMysqlClient, new(repository.IRdsClient))
+		bind(repository_impl.NewRdsMaintenanceNoopImpl, new(repository.IRdsMaintenance))
+		repositoryBackendType = "rds"
+	case "postgres":
+		bind(infra_client.NewPostgresClient, new(repository.IRdsClient))
+		bind(repository_impl.NewRdsMaintenanceNoopImpl, new(repository.IRdsMaintenance))
 		repositoryBackendType = "rds"
 	default:
 		panic(errors.Errorf("invalid DBType in config: %s", c.DBType))
 	}
+	// the rds clients are closed on shutdown, sqlite would otherwise lose its unflushed WAL
+	decorate(func(client repository.IRdsClient) repository.IRdsClient {
+		if closer, ok := client.(io.Closer); ok {
+			onClose(c.DBType, closer)
+		}
+		return client
+	})
+	// backups stay on local disk whatever the stores are
+	bind(repository_impl.NewBackupRepositoryLocalImpl, new(repository.IBackupRepository))
 	// provide migration, repository backend
 	switch repositoryBackendType {
 	case "rds":
 		bind(migration.NewRdsMigrationImpl, new(repository.IMigration))
 		bind(repository_impl.NewUserRepositoryRdsImpl, new(repository.IUserRepository))
 		bind(repository_impl.NewToolRepositoryRdsImpl, new(repository.IToolRepository))
+		bind(repository_impl.NewToolVersionRepositoryRdsImpl, new(repository.IToolVersionRepository))
+		bind(repository_impl.NewToolEventRepositoryRdsImpl, new(repository.IToolEventRepository))
+		bind(repository_impl.NewNamespaceRepositoryRdsImpl, new(repository.INamespaceRepository))
+		bind(repository_impl.NewToolShareRepositoryRdsImpl, new(repository.IToolShareRepository))
+		bind(repository_impl.NewGalleryRepositoryRdsImpl, new(repository.IGalleryRepository))
 		bind(repository_impl.NewGlobalScriptRepositoryRdsImpl, new(repository.IGlobalScriptRepository))
 		bind(repository_impl.NewPasskeyRepositoryRdsImpl, new(repository.IPasskeyRepository))
 		bind(repository_impl.NewAuth2FARepositoryRdsImpl, new(repository.IAuth2FARepository))
+		bind(repository_impl.NewSystemSettingRepositoryRdsImpl, new(repository.ISystemSettingRepository))
+		bind(repository_impl.NewUserSettingRepositoryRdsImpl, new(repository.IUserSettingRepository))
+		bind(repository_impl.NewAnnouncementRepositoryRdsImpl, new(repository.IAnnouncementRepository))
+		bind(repository_impl.NewAuditLogRepositoryRdsImpl, new(repository.IAuditLogRepository))
+		bind(repository_impl.NewUserDeviceRepositoryRdsImpl, new(repository.IUserDeviceRepository))
+		bind(repository_impl.NewUsageRepositoryRdsImpl, new(repository.IUsageRepository))
+		bind(repository_impl.NewToolSecretRepositoryRdsImpl, new(repository.IToolSecretRepository))
+		bind(repository_impl.NewToolScheduleRepositoryRdsImpl, new(repository.IToolScheduleRepository))
+		bind(repository_impl.NewUserDataExportRepositoryRdsImpl, new(repository.IUserDataExportRepository))
+		bind(repository_impl.NewAccountRecoveryRepositoryRdsImpl, new(repository.IAccountRecoveryRepository))
+		bind(repository_impl.NewOidcClientRepositoryRdsImpl, new(repository.IOidcClientRepository))
+		bind(repository_impl.NewOidcSigningKeyRepositoryRdsImpl, new(repository.IOidcSigningKeyRepository))
 	default:
 		panic(errors.Errorf("unsupported repository backend type: %s", repositoryBackendType))
 	}
@@ -73,21 +107,88 @@ func InitDI() {
 		// 	bind(repository_impl.NewAuthRefreshTokenRepositoryBadgerImpl, new(repository.IAuthRefreshTokenRepository))
 		case "nutsdb":
 			provide(infra_client.NewNutsDBClient)
+			decorate(func(client *infra_client.NutsDBClient) *infra_client.NutsDBClient {
+				onClose("nutsdb", client)
+				return client
+			})
 			bind(repository_impl.NewCacheNutsDBImpl, new(repository.ICache))
 			bind(repository_impl.NewAuthRefreshTokenRepositoryNutsDBImpl, new(repository.IAuthRefreshTokenRepository))
+			bind(repository_impl.NewKeyValueStoreMaintenanceNutsDBImpl, new(repository.IKeyValueStoreMaintenance))
 		case "redis":
-			// todo:
+			provide(infra_client.NewRedisClient)
+			decorate(func(client *infra_client.RedisClient) *infra_client.RedisClient {
+				onClose("redis", client)
+				return client
+			})
+			bind(repository_impl.NewCacheRedisImpl, new(repository.ICache))
+			bind(repository_impl.NewAuthRefreshTokenRepositoryRedisImpl, new(repository.IAuthRefreshTokenRepository))
+			bind(repository_impl.NewKeyValueStoreMaintenanceNoopImpl, new(repository.IKeyValueStoreMaintenance))
 		case "rds":
 			// todo:
 		default:
 			panic(errors.Errorf("invalid KeyValueDBType in config: %s", c.KeyValueDBType))
 		}
+		if c.CacheFallbackEnabled {
+			// the cache keeps working in memory while its backend fails
+			decorate(func(cache repository.ICache, clock domain_client.IClock) repository.ICache {
+				return repository_impl.NewCacheFallbackImpl(c, cache, clock)
+			})
+			// the decorated cache is the fallback, whose memory store keeps the expired keys until purged
+			provide(func(cache repository.ICache) repository.ICacheMaintenance {
+				return cache.(repository.ICacheMaintenance)
+			})
+		} else {
+			bind(repository_impl.NewCacheMaintenanceNoopImpl, new(repository.ICacheMaintenance))
+		}
+		bind(repository_impl.NewCacheJitterImpl, new(repository.IHotCache))
 	}
 
 	// bind SSO clients to auth service interfaces
 	bind(infra_client.NewGithubClient, new(domain_client.IGithubAuthClient))
+	bind(infra_client.NewGithubClient, new(domain_client.IGithubRepoClient))
 	bind(infra_client.NewGoogleClient, new(domain_client.IGoogleAuthClient))
 
+	bind(infra_client.NewSystemClock, new(domain_client.IClock))
+	bind(infra_client.NewGojaToolRunner, new(domain_client.IToolRunner))
+
+	// bind the scanner imported content passes by config
+	switch c.ImportScanner {
+	case "clamav":
+		bind(infra_client.NewClamAVContentScanner, new(domain_client.IContentScanner))
+	case "http":
+		bind(infra_client.NewHTTPContentScanner, new(domain_client.IContentScanner))
+	default:
+		bind(infra_client.NewNoopContentScanner, new(domain_client.IContentScanner))
+	}
+
+	// bind the enforcer metered usage is checked with by config
+	switch c.UsageEnforcer {
+	case "http":
+		bind(infra_client.NewHTTPUsageEnforcer, new(domain_client.IUsageEnforcer))
+	default:
+		bind(infra_client.NewNoopUsageEnforcer, new(domain_client.IUsageEnforcer))
+	}
+
+	// bind the sink security events are exported to by config
+	switch c.SIEMExport {
+	case "http":
+		bind(infra_client.NewHTTPSIEMSink, new(domain_client.ISIEMSink))
+	case "file":
+		bind(infra_client.NewFileSIEMSink, new(domain_client.ISIEMSink))
+	default:
+		bind(infra_client.NewNoopSIEMSink, new(domain_client.ISIEMSink))
+	}
+
+	// bind the mailer account emails are sent with by config
+	switch c.Mailer {
+	case "smtp":
+		bind(infra_client.NewSMTPMailer, new(domain_client.IMailer))
+	case "log":
+		bind(infra_client.NewLogMailer, new(domain_client.IMailer))
+	default:
+		bind(infra_client.NewNoopMailer, new(domain_client.IMailer))
+	}
+
 	infBinds := [][]any{
 		{repository_impl.NewAuthAccessTokenRepositoryJWTImpl, new(repository.IAuthAccessTokenRepository)},
 	}
@@ -100,12 +201,57 @@ func InitDI() {
 		service.NewAuthPasskeyService,
 		service.NewUserService,
 		service.NewTwoFaService,
+		service.NewAccountRecoveryService,
+		service.NewAccountDeletionService,
+		service.NewPasswordResetService,
+		service.NewUserEmailService,
+		service.NewToolService,
+		service.NewToolVersionService,
+		service.NewToolEventService,
+		service.NewNamespaceService,
+		service.NewToolShareService,
+		service.NewGalleryService,
+		service.NewToolSecretService,
+		service.NewSystemSettingsService,
+		service.NewUserSettingsService,
+		service.NewAnnouncementService,
+		service.NewAuditLogService,
+		service.NewSIEMExportService,
+		service.NewImportScanService,
+		service.NewGithubImportService,
+		service.NewMeteringService,
+		service.NewReadinessService,
+		service.NewStatusService,
+		service.NewClientCompatibilityService,
+		service.NewDeploymentCapabilitiesService,
+		service.NewSystemInfoService,
+		service.NewSyncService,
+		service.NewSourceEncryptionService,
+		service.NewHousekeepingService,
+		service.NewBackupService,
+		service.NewOidcService,
+		service.NewTokenIssuanceMonitor,
+		service.NewSessionRevocationService,
+		service.NewPasskeyChallengeLimiter,
+		service.NewToolExecutionLimiter,
+		service.NewToolExecutionService,
+		service.NewToolScheduleService,
+		service.NewUserDataExportService,
 	}
 	for _, factory := range factories {
 		provide(factory)
 	}
 
+	// the token and challenge lifetimes come from the runtime settings
+	provide(func(settings *service.SystemSettingsService) repository.ISessionTTLProvider { return settings })
+
+	// every issued refresh token passes the anomaly detection
+	decorate(func(repo repository.IAuthRefreshTokenRepository, monitor *service.TokenIssuanceMonitor) repository.IAuthRefreshTokenRepository {
+		return monitor.Observe(repo)
+	})
+
 	provide(common.NewAccessTokenHeaderValidator)
+	provide(scheduler.NewScheduler)
 }
 
 func bind(factory any, interfaceType any) {
@@ -119,3 +265,10 @@ func provide(factory any) {
 		panic(errors.Errorf("failed to provide factory %T into di container: %v", factory, err))
 	}
 }
+
+// decorate runs the decorator on a dependency once it is built, before it is handed to anything depending on it
+func decorate(decorator any) {
+	if err := Container.Decorate(decorator); err != nil {
+		panic(errors.Errorf("failed to decorate %T in di container: %v", decorator, err))
+	}
+}
@@ -0,0 +1,43 @@
+package di
+
+import (
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type namedCloser struct {
+	name   string
+	closer io.Closer
+}
+
+var (
+	closersMu sync.Mutex
+	closers   []namedCloser
+)
+
+// onClose registers a dependency to be closed by Close, it is called as the dependency is built.
+func onClose(name string, closer io.Closer) {
+	closersMu.Lock()
+	defer closersMu.Unlock()
+	closers = append(closers, namedCloser{name: name, closer: closer})
+}
+
+// Close closes the built dependencies holding storage, the last built first, so their data is flushed to disk.
+// Dependencies never built are not closed, each one is closed at most once.
+// A failing one does not stop the others from closing, the first error is returned.
+func Close() error {
+	closersMu.Lock()
+	toClose := closers
+	closers = nil
+	closersMu.Unlock()
+
+	var firstErr error
+	for i := len(toClose) - 1; i >= 0; i-- {
+		if err := toClose[i].closer.Close(); err != nil && firstErr == nil {
+			firstErr = errors.Wrapf(err, "failed to close %s", toClose[i].name)
+		}
+	}
+	return firstErr
+}
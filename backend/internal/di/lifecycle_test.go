@@ -0,0 +1,35 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingCloser struct {
+	name   string
+	err    error
+	closed *[]string
+}
+
+func (c recordingCloser) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return c.err
+}
+
+func TestClose(t *testing.T) {
+	var closed []string
+	onClose("sqlite", recordingCloser{name: "sqlite", closed: &closed})
+	onClose("nutsdb", recordingCloser{name: "nutsdb", err: errors.New("disk full"), closed: &closed})
+	onClose("redis", recordingCloser{name: "redis", closed: &closed})
+
+	err := Close()
+	require.ErrorContains(t, err, "failed to close nutsdb: disk full")
+	// the last built is closed first and a failure does not stop the others
+	require.Equal(t, []string{"redis", "nutsdb", "sqlite"}, closed)
+
+	// closing again does not close anything twice
+	require.NoError(t, Close())
+	require.Len(t, closed, 3)
+}
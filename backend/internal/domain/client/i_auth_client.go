@@ -1,15 +1,22 @@
 package client
 
-import "ya-tool-craft/internal/domain/entity"
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
 
 // IGithubAuthClient defines GitHub OAuth capabilities used by the domain service.
 type IGithubAuthClient interface {
 	OauthTokenToAccessToken(oauthToken string) (string, error)
 	GetUserInfo(accessToken string) (entity.GithubUserInfoEntity, error)
+	// Ping checks that the GitHub API can be reached, used by the readiness probe
+	Ping(ctx context.Context) error
 }
 
 // IGoogleAuthClient defines Google OAuth capabilities used by the domain service.
 type IGoogleAuthClient interface {
 	OauthCodeToAccessToken(oauthCode string) (string, error)
 	GetUserInfo(accessToken string) (entity.GoogleUserInfoEntity, error)
+	// Ping checks that the Google OAuth API can be reached, used by the readiness probe
+	Ping(ctx context.Context) error
 }
@@ -0,0 +1,9 @@
+package client
+
+import "time"
+
+// IClock is the source of the current time for domain services and repositories,
+// so expiry and timestamp logic can be tested without waiting on the wall clock.
+type IClock interface {
+	Now() time.Time
+}
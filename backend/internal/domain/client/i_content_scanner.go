@@ -0,0 +1,12 @@
+package client
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+// IContentScanner inspects imported content (malware, policy) before it is stored.
+// An error means the content could not be scanned, not that it is infected.
+type IContentScanner interface {
+	Scan(ctx context.Context, file entity.ImportFileEntity) (entity.ContentScanResultEntity, error)
+}
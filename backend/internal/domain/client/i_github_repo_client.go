@@ -0,0 +1,21 @@
+package client
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+// IGithubRepoClient reads repositories and gists with the api token of a bound GitHub account.
+// Errors carry the GithubAccountNotAuthorized, GithubRepositoryNotFound, GithubRateLimited or GithubUnavailable error code.
+type IGithubRepoClient interface {
+	// ListRepos returns one page of the repositories the account can read, page starts at 1
+	ListRepos(ctx context.Context, accessToken string, page int) (entity.GithubPageEntity[entity.GithubRepoEntity], error)
+	// ListGists returns one page of the gists of the account, page starts at 1
+	ListGists(ctx context.Context, accessToken string, page int) (entity.GithubPageEntity[entity.GithubGistEntity], error)
+	// ListRepoFiles lists every file of the default branch, GitHub cuts the listing of very large repositories
+	ListRepoFiles(ctx context.Context, accessToken string, owner string, repo string) ([]entity.GithubFileEntity, error)
+	// GetRepoFile returns the content of a file of the default branch
+	GetRepoFile(ctx context.Context, accessToken string, owner string, repo string, path string) ([]byte, error)
+	// GetGistFiles returns the content of every file of a gist by file name
+	GetGistFiles(ctx context.Context, accessToken string, gistID string) (map[string][]byte, error)
+}
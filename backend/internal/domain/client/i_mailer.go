@@ -0,0 +1,12 @@
+package client
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+// IMailer sends the emails of the account flows, such as account recovery codes and notices.
+// An error means the email may not have been delivered.
+type IMailer interface {
+	Send(ctx context.Context, mail entity.MailEntity) error
+}
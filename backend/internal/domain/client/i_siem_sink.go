@@ -0,0 +1,9 @@
+package client
+
+import "context"
+
+// ISIEMSink delivers a batch of formatted security events to a SIEM, one event per line.
+// An error means the batch was not delivered and may be written again.
+type ISIEMSink interface {
+	Write(ctx context.Context, batch []byte) error
+}
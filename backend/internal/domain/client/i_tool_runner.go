@@ -0,0 +1,15 @@
+package client
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+// IToolRunner runs the handler of a tool source outside the browser.
+// A handler that throws, rejects or returns something else than an object fails the run with ToolExecutionFailed,
+// one that outlives the timeout or ctx with ToolExecutionTimedOut, outputs past the payload limit with
+// ToolExecutionTooLarge, and one that allocates past the memory limit with ToolExecutionOutOfMemory. The secrets are
+// exposed to the handler as the secrets global.
+type IToolRunner interface {
+	Run(ctx context.Context, source string, secrets map[string]string, request entity.ToolExecutionRequestEntity, limits entity.ToolExecutionLimitsEntity) (entity.ToolExecutionResultEntity, error)
+}
@@ -0,0 +1,12 @@
+package client
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+// IUsageEnforcer decides whether a user may use more of a metered resource, hosted deployments plug their billing in here.
+// A refusal is an error with the UsageLimitExceeded error code, any other error means the enforcer could not be asked.
+type IUsageEnforcer interface {
+	Allow(ctx context.Context, request entity.UsageRequestEntity) error
+}
@@ -9,6 +9,10 @@ type AccessToken struct {
 	ExpireAt time.Time
 
 	RelativeRefreshToken string
+	// ClientID and Scopes are copied from the refresh token, a token of an oidc client is only accepted by the
+	// oidc userinfo endpoint
+	ClientID string
+	Scopes   []string
 }
 
 func NewAccessToken(userID UserIDEntity, token string, issueAt, expireAt time.Time, relativeRefreshToken string) AccessToken {
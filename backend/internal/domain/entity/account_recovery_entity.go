@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+type AccountRecoveryStatus string
+
+const (
+	// AccountRecoveryStatusPending waits for an admin to approve or reject the request
+	AccountRecoveryStatusPending AccountRecoveryStatus = "pending"
+	// AccountRecoveryStatusApproved can be completed by the requester once the delay is over
+	AccountRecoveryStatusApproved  AccountRecoveryStatus = "approved"
+	AccountRecoveryStatusRejected  AccountRecoveryStatus = "rejected"
+	AccountRecoveryStatusCancelled AccountRecoveryStatus = "cancelled"
+	AccountRecoveryStatusCompleted AccountRecoveryStatus = "completed"
+)
+
+// IsOpen reports whether the request can still be completed
+func (s AccountRecoveryStatus) IsOpen() bool {
+	return s == AccountRecoveryStatusPending || s == AccountRecoveryStatusApproved
+}
+
+// AccountRecoveryRequestEntity is a request to recover an account whose password and 2FA methods are all lost.
+// It is created once the requester proved the ownership of the account email, approved by an admin, and completed
+// by the requester with the recovery token after AvailableAt, unless the account cancelled it meanwhile.
+type AccountRecoveryRequestEntity struct {
+	ID     string
+	UserID UserIDEntity
+	Status AccountRecoveryStatus
+	// TokenHash is the sha256 of the recovery token handed to the requester, the token itself is never stored
+	TokenHash   string
+	CreatedAt   time.Time
+	AvailableAt time.Time
+	// DecidedBy is the admin who approved or rejected the request, or the user who cancelled it
+	DecidedBy *UserIDEntity
+	UpdatedAt time.Time
+}
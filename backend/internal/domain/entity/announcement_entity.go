@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AnnouncementSeverity string
+
+const (
+	AnnouncementSeverityInfo     AnnouncementSeverity = "info"
+	AnnouncementSeverityWarning  AnnouncementSeverity = "warning"
+	AnnouncementSeverityCritical AnnouncementSeverity = "critical"
+)
+
+// AnnouncementEntity is a banner shown to every visitor between StartsAt and EndsAt, nil bounds are open.
+type AnnouncementEntity struct {
+	ID          string
+	Message     string
+	Severity    AnnouncementSeverity
+	StartsAt    *time.Time
+	EndsAt      *time.Time
+	Dismissible bool
+	CreatedBy   UserIDEntity
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func NewAnnouncementEntityWithoutID(
+	message string,
+	severity AnnouncementSeverity,
+	startsAt, endsAt *time.Time,
+	dismissible bool,
+	createdBy UserIDEntity,
+) AnnouncementEntity {
+	return AnnouncementEntity{
+		ID:          fmt.Sprintf("ann-%s", uuid.New().String()),
+		Message:     message,
+		Severity:    severity,
+		StartsAt:    startsAt,
+		EndsAt:      endsAt,
+		Dismissible: dismissible,
+		CreatedBy:   createdBy,
+	}
+}
+
+// IsActiveAt reports if the announcement should be shown at the given time.
+func (a AnnouncementEntity) IsActiveAt(now time.Time) bool {
+	if a.StartsAt != nil && now.Before(*a.StartsAt) {
+		return false
+	}
+	if a.EndsAt != nil && !now.Before(*a.EndsAt) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+	// AuditOutcomeAlert marks events the server raised itself, such as detected anomalies
+	AuditOutcomeAlert AuditOutcome = "alert"
+)
+
+// AuditMethodEvent is the method of audit records not caused by a request to an admin route, their route names the event
+const AuditMethodEvent = "EVENT"
+
+// AuditLogEntity records one admin action: who did what on which route and how it ended.
+type AuditLogEntity struct {
+	ID string
+	// ActorID is empty when the request was rejected before the caller was authenticated
+	ActorID UserIDEntity
+	Method  string
+	// Route is the registered route pattern such as /api/v1/admin/settings/:key, Path the requested path
+	Route string
+	Path  string
+	// Payload is a redacted and truncated summary of the request body
+	Payload   string
+	Status    int
+	Outcome   AuditOutcome
+	RequestID string
+	ClientIP  string
+	CreatedAt time.Time
+}
+
+func NewAuditLogEntityWithoutID(
+	actorID UserIDEntity,
+	method, route, path, payload string,
+	status int,
+	requestID, clientIP string,
+) AuditLogEntity {
+	outcome := AuditOutcomeSuccess
+	if status >= 400 {
+		outcome = AuditOutcomeFailure
+	}
+	return AuditLogEntity{
+		ID:        fmt.Sprintf("audit-%s", uuid.New().String()),
+		ActorID:   actorID,
+		Method:    method,
+		Route:     route,
+		Path:      path,
+		Payload:   payload,
+		Status:    status,
+		Outcome:   outcome,
+		RequestID: requestID,
+		ClientIP:  clientIP,
+	}
+}
+
+// NewAuditEventEntityWithoutID records an event the server raised, details is a json summary of what was seen.
+func NewAuditEventEntityWithoutID(actorID UserIDEntity, event string, details string, requestID, clientIP string) AuditLogEntity {
+	return AuditLogEntity{
+		ID:        fmt.Sprintf("audit-%s", uuid.New().String()),
+		ActorID:   actorID,
+		Method:    AuditMethodEvent,
+		Route:     event,
+		Payload:   details,
+		Outcome:   AuditOutcomeAlert,
+		RequestID: requestID,
+		ClientIP:  clientIP,
+	}
+}
+
+// AuditLogFilter narrows an audit log listing, zero values match everything.
+type AuditLogFilter struct {
+	ActorID UserIDEntity
+	Outcome AuditOutcome
+	Limit   int
+	Offset  int
+}
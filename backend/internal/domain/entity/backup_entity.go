@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// components of a backup, each is the copy of one store ToolBake keeps on local disk
+const (
+	BackupComponentRds      = "rds"
+	BackupComponentKeyValue = "key_value"
+)
+
+// BackupEntity is a backup archive of the local stores.
+type BackupEntity struct {
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+	// Components are the stores the backup holds, such as rds for the sqlite database
+	Components []string
+}
@@ -0,0 +1,56 @@
+package entity
+
+// APICapability names a part of the api a client may rely on, clients check for it instead of comparing server versions.
+type APICapability string
+
+const (
+	// APICapabilitySyncV1 is the cursor based tool sync of /api/v1/tools/sync
+	APICapabilitySyncV1       APICapability = "sync_v1"
+	APICapabilityToolArchive  APICapability = "tool_archive"
+	APICapabilityToolSchema   APICapability = "tool_schema"
+	APICapabilityToolSearch   APICapability = "tool_search"
+	APICapabilityToolSecrets  APICapability = "tool_secrets"
+	APICapabilityGithubImport APICapability = "github_import"
+	// APICapabilityToolNamespaces is the namespace settings api and the namespace defaults of new tools
+	APICapabilityToolNamespaces APICapability = "tool_namespaces"
+	// APICapabilityToolSharing is the tool share api of /api/v1/tools/:tool_uid/shares and /api/v1/tools/shared
+	APICapabilityToolSharing APICapability = "tool_sharing"
+	// APICapabilityToolGallery is the public gallery of /api/v1/gallery and the tags extra info it is searched by
+	APICapabilityToolGallery APICapability = "tool_gallery"
+	// APICapabilityToolBundles is the export and import of all tools of /api/v1/tools/export and /api/v1/tools/import
+	APICapabilityToolBundles  APICapability = "tool_bundles"
+	APICapabilityPasskeyLogin APICapability = "passkey_login"
+	// APICapabilityRateLimitHeaders is the X-RateLimit-* and Retry-After backoff contract of RateLimitEntity
+	APICapabilityRateLimitHeaders APICapability = "rate_limit_headers"
+	// APICapabilityToolExecution is the server side run of /api/v1/tools/:tool_uid/execute, the deployment may still
+	// have it off, see DeploymentCapabilitiesEntity.ToolExecution
+	APICapabilityToolExecution APICapability = "tool_execution"
+	// APICapabilityToolSchedules is the cron scheduled runs of /api/v1/tools/:tool_uid/schedules and their run history
+	APICapabilityToolSchedules APICapability = "tool_schedules"
+	// APICapabilityToolInvocation is the per user spec of /api/tools/openapi.json and the invoke endpoint of
+	// /api/tools/:namespace/:name/invoke, running a tool needs the tool_execution capability of the deployment
+	APICapabilityToolInvocation APICapability = "tool_invocation"
+	// APICapabilityToolTags is the tag api of /api/v1/tools/tags and /api/v1/tools/:tool_uid/tags
+	APICapabilityToolTags APICapability = "tool_tags"
+	// APICapabilityToolFullTextSearch is the ranked search of /api/v1/tools/search/fulltext
+	APICapabilityToolFullTextSearch APICapability = "tool_fulltext_search"
+	// APICapabilityToolListPages is the paged and filtered tool list of /api/v1/tools/list
+	APICapabilityToolListPages APICapability = "tool_list_pages"
+	// APICapabilityToolSyncSince is the device-less sync by time of /api/v1/tools/sync/since
+	APICapabilityToolSyncSince APICapability = "tool_sync_since"
+	// APICapabilityUserDataExport is the data export of a user of /api/v1/user/data-exports
+	APICapabilityUserDataExport APICapability = "user_data_export"
+	// APICapabilityAccountDeletionGracePeriod is the scheduled account deletion of /api/v1/user/delete, which can be cancelled
+	APICapabilityAccountDeletionGracePeriod APICapability = "account_deletion_grace_period"
+)
+
+// ClientCompatibilityEntity tells a client whether its version is still supported and what the server offers.
+type ClientCompatibilityEntity struct {
+	ServerVersion string
+	// MinClientVersion is empty when every client version is supported
+	MinClientVersion string
+	// ClientVersion is the version the client reported, empty when it sent none
+	ClientVersion    string
+	ClientDeprecated bool
+	Capabilities     []APICapability
+}
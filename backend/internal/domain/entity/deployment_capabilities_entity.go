@@ -0,0 +1,23 @@
+package entity
+
+// DeploymentCapabilitiesEntity tells which optional features are available on this deployment, so a client can hide
+// the ones that are not instead of calling their routes. Unlike APICapability it changes with the configuration and
+// the system settings, not with the server version.
+type DeploymentCapabilitiesEntity struct {
+	UserRegistration bool
+	PasswordLogin    bool
+	// SSOProviders are the enabled sso providers, such as github
+	SSOProviders []string
+	Passkeys     bool
+	// PasswordReset and AccountRecovery send emails, they are only available with a mailer
+	PasswordReset   bool
+	AccountRecovery bool
+	OIDCProvider    bool
+	DemoMode        bool
+	// Gallery is the public gallery of published tools
+	Gallery bool
+	// ToolExecution runs tools on the server through the api
+	ToolExecution bool
+	// GraphQL is the graphql endpoint at /graphql
+	GraphQL bool
+}
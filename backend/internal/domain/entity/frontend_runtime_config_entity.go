@@ -13,4 +13,6 @@ type FrontendRuntimeConfigEntity struct {
 	}
 	EnablePasswordLogin bool
 	EnableRegister      bool
+	MaintenanceMessage  string
+	DemoMode            bool
 }
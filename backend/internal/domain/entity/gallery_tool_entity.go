@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+// GalleryToolEntity is a tool its owner published to the public gallery. InstallCount and ForkCount count the
+// copies other users made of it from the gallery.
+type GalleryToolEntity struct {
+	Tool         ToolEntity
+	OwnerID      UserIDEntity
+	OwnerName    string
+	InstallCount int64
+	ForkCount    int64
+	PublishedAt  time.Time
+}
+
+type GallerySort string
+
+const (
+	GallerySortNewest  GallerySort = "newest"
+	GallerySortPopular GallerySort = "popular"
+)
+
+// GalleryFilter narrows a gallery listing, zero values match everything.
+type GalleryFilter struct {
+	// Query matches the tool name case-insensitively
+	Query    string
+	Category string
+	// Tag matches one of the tags of the tool extra info case-insensitively
+	Tag    string
+	Sort   GallerySort
+	Limit  int
+	Offset int
+}
+
+// GalleryCopyKind is how a user copies a gallery tool into their own tools.
+type GalleryCopyKind string
+
+const (
+	// GalleryCopyInstall copies the tool to use it as it is
+	GalleryCopyInstall GalleryCopyKind = "install"
+	// GalleryCopyFork copies the tool to change it, the copy remembers the gallery tool it came from
+	GalleryCopyFork GalleryCopyKind = "fork"
+)
@@ -0,0 +1,48 @@
+package entity
+
+import "time"
+
+// GithubRepoEntity is a repository the bound GitHub account can read.
+type GithubRepoEntity struct {
+	Owner         string
+	Name          string
+	FullName      string
+	Description   string
+	Private       bool
+	DefaultBranch string
+	HTMLURL       string
+	UpdatedAt     time.Time
+}
+
+// GithubGistEntity is a gist of the bound GitHub account, Files holds the file names.
+type GithubGistEntity struct {
+	ID          string
+	Description string
+	Public      bool
+	Files       []string
+	HTMLURL     string
+	UpdatedAt   time.Time
+}
+
+// GithubFileEntity is a file of a repository or gist, Path is relative to the repository root.
+type GithubFileEntity struct {
+	Path string
+	Size int
+}
+
+// GithubPageEntity is one page of a paginated GitHub listing, NextPage is 0 on the last page.
+type GithubPageEntity[T any] struct {
+	Items    []T
+	NextPage int
+}
+
+// GithubImportResultEntity is the outcome of importing one selected GitHub file as a tool.
+type GithubImportResultEntity struct {
+	Path     string
+	ToolID   string
+	Imported bool
+	// Reason tells why the file was skipped, empty when it was imported
+	Reason string
+	// Warnings lists credentials found in the source, see ToolSecretScanResultEntity
+	Warnings []ToolSecretFindingEntity
+}
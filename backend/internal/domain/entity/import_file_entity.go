@@ -0,0 +1,14 @@
+package entity
+
+// ImportFileEntity is one file of a tool import (from a URL, a bundle or the marketplace), before it is stored.
+type ImportFileEntity struct {
+	Name    string
+	Content []byte
+}
+
+// ContentScanResultEntity is the verdict of a content scanner on one file.
+type ContentScanResultEntity struct {
+	Clean bool
+	// Signature names what the scanner found, empty when the file is clean
+	Signature string
+}
@@ -0,0 +1,8 @@
+package entity
+
+// MailEntity is a plain text email to one recipient.
+type MailEntity struct {
+	To      string
+	Subject string
+	Body    string
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// NamespaceEntity holds the settings of a tool namespace. The defaults fill the fields a new tool of the namespace
+// is created without, a namespace without a row is used by tools all the same and has no defaults.
+type NamespaceEntity struct {
+	Name        string
+	Description string
+	// DefaultCategory is the category of a new tool that names none, empty for no category
+	DefaultCategory          string
+	DefaultRealtimeExecution bool
+	// DefaultUiWidgets is the ui widgets skeleton of a new tool that brings none, empty for an empty list
+	DefaultUiWidgets string
+	// ToolCount is the number of tools in the namespace, only filled when listing
+	ToolCount int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
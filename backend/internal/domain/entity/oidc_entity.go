@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	OidcScopeOpenID  = "openid"
+	OidcScopeProfile = "profile"
+	OidcScopeEmail   = "email"
+)
+
+// OidcClientEntity is an app registered by an admin to sign its users in with ToolBake.
+type OidcClientEntity struct {
+	ID   string
+	Name string
+	// SecretHash is the sha256 of the client secret, the secret is only shown when the client is registered
+	SecretHash string
+	// RedirectURIs are compared exactly with the redirect_uri of an authorization request
+	RedirectURIs []string
+	CreatedBy    UserIDEntity
+	CreatedAt    time.Time
+}
+
+func (c OidcClientEntity) AllowsRedirectURI(redirectURI string) bool {
+	return slices.Contains(c.RedirectURIs, redirectURI)
+}
+
+// OidcSigningKeyEntity is an RSA key the id tokens are signed with, the newest one signs and all of them are published.
+type OidcSigningKeyEntity struct {
+	KID           string
+	PrivateKeyPEM string
+	CreatedAt     time.Time
+}
+
+// OidcAuthorizationRequestEntity is an authorization request of a client waiting for the user to approve it.
+type OidcAuthorizationRequestEntity struct {
+	ID                  string   `json:"id"`
+	ClientID            string   `json:"client_id"`
+	RedirectURI         string   `json:"redirect_uri"`
+	Scopes              []string `json:"scopes"`
+	State               string   `json:"state"`
+	Nonce               string   `json:"nonce"`
+	CodeChallenge       string   `json:"code_challenge"`
+	CodeChallengeMethod string   `json:"code_challenge_method"`
+	ExpiresAt           int64    `json:"expires_at"`
+}
+
+// OidcTokenEntity is the token response of the token endpoint, IDToken is only set for the authorization code grant.
+type OidcTokenEntity struct {
+	AccessToken AccessToken
+	// ExpiresIn is the seconds the access token is valid for
+	ExpiresIn    int64
+	RefreshToken string
+	IDToken      string
+}
+
+// OidcDiscoveryEntity is the openid provider metadata published at /.well-known/openid-configuration.
+type OidcDiscoveryEntity struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JwksURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
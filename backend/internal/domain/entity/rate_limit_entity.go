@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimitEntity is how much of a rate limit a client has left. Every response of an endpoint with a rate limit
+// reports it in the X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset (unix seconds) headers. A refused
+// request is answered with 429, the usual error body with the rate limit as extra_data, and a Retry-After header.
+//
+// Backoff contract for clients, for example a loop syncing in the background:
+//   - after a 429, wait Retry-After seconds and then a random extra of up to the same time before retrying, so the
+//     clients refused together do not all come back in the same second
+//   - when X-RateLimit-Remaining is 0, hold the next request until X-RateLimit-Reset
+//   - TooManyAttempts is not lifted by waiting, the user has to sign in again
+type RateLimitEntity struct {
+	Limit     int       `json:"limit"` // 0 when the limit is not known, only Retry-After is sent then
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"` // when Remaining goes up again, for a refused request when a retry can succeed
+}
+
+// RetryAfter is the whole seconds from now until the rate limit resets, at least 1
+func (r RateLimitEntity) RetryAfter(now time.Time) int {
+	return max(1, int(math.Ceil(r.ResetAt.Sub(now).Seconds())))
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// ReadinessCheckEntity is the result of probing one dependency of the service.
+type ReadinessCheckEntity struct {
+	// Name identifies the dependency, such as database or key_value_store
+	Name string
+	// Backend is the configured implementation, such as sqlite or nutsdb
+	Backend string
+	Healthy bool
+	Latency time.Duration
+	// Error explains why the check failed, empty when healthy
+	Error string
+	// Optional is set on a dependency the service can serve without, such as the key value store while the cache
+	// fallback answers from memory. It is reported but does not make the service unready.
+	Optional bool
+}
+
+// ReadinessReportEntity is ready only when every check that is not optional is healthy.
+type ReadinessReportEntity struct {
+	Ready  bool
+	Checks []ReadinessCheckEntity
+}
@@ -12,6 +12,13 @@ type RefreshToken struct {
 	ExpireAt time.Time
 
 	TokenHash string
+	// FamilyID is the hash of the first token of a login, the tokens rotated from it share the family
+	FamilyID string
+	// ClientID is the oidc client the token was issued to, empty for the tokens of ToolBake itself.
+	// A client token is only accepted by the oidc token endpoint, for that client.
+	ClientID string
+	// Scopes are the oidc scopes the user granted the client
+	Scopes []string
 }
 
 func NewRefreshToken(userID UserIDEntity, token string, issueAt, expireAt time.Time) RefreshToken {
@@ -21,5 +28,17 @@ func NewRefreshToken(userID UserIDEntity, token string, issueAt, expireAt time.T
 		IssueAt:   issueAt,
 		ExpireAt:  expireAt,
 		TokenHash: utils.Sha256String(token),
+		FamilyID:  utils.Sha256String(token),
+	}
+}
+
+// NewRefreshTokenFromHash rebuilds a stored refresh token, only the hash is persisted so Token stays empty.
+func NewRefreshTokenFromHash(userID UserIDEntity, tokenHash string, issueAt, expireAt time.Time) RefreshToken {
+	return RefreshToken{
+		UserID:    userID,
+		IssueAt:   issueAt,
+		ExpireAt:  expireAt,
+		TokenHash: tokenHash,
+		FamilyID:  tokenHash,
 	}
 }
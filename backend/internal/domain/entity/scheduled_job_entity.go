@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+type ScheduledJobTrigger string
+
+const (
+	ScheduledJobTriggerSchedule ScheduledJobTrigger = "schedule"
+	ScheduledJobTriggerManual   ScheduledJobTrigger = "manual"
+)
+
+type ScheduledJobRunStatus string
+
+const (
+	ScheduledJobRunStatusSuccess ScheduledJobRunStatus = "success"
+	ScheduledJobRunStatusFailed  ScheduledJobRunStatus = "failed"
+)
+
+// ScheduledJobRunEntity is a finished run of a scheduled job.
+type ScheduledJobRunEntity struct {
+	Trigger   ScheduledJobTrigger
+	StartedAt time.Time
+	Duration  time.Duration
+	Status    ScheduledJobRunStatus
+	// Error explains why the run failed, empty on success
+	Error string
+}
+
+// ScheduledJobEntity is a housekeeping job known to the scheduler and its state in this process.
+type ScheduledJobEntity struct {
+	Name        string
+	Description string
+	// Schedule is the effective cron expression, empty when the job is disabled
+	Schedule string
+	// ScheduleError tells why Schedule could not be used, the job does not run on schedule then
+	ScheduleError string
+	Running       bool
+	// NextRunAt is nil when the job is disabled or its schedule is invalid
+	NextRunAt *time.Time
+	// LastRun is nil until the job ran once since the server started
+	LastRun *ScheduledJobRunEntity
+}
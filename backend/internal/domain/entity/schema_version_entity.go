@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// SchemaVersionEntity compares the schema applied to the database with the newest schema this build ships.
+type SchemaVersionEntity struct {
+	// AppliedVersion is the newest versioned migration recorded in the database, 0 when none is applied
+	AppliedVersion int
+	// AppliedAt is when AppliedVersion was applied, zero when none is applied
+	AppliedAt time.Time
+	// LatestVersion is the newest versioned migration known to this build
+	LatestVersion int
+}
+
+// UpToDate reports whether the database runs exactly the schema this build expects.
+func (s SchemaVersionEntity) UpToDate() bool {
+	return s.AppliedVersion == s.LatestVersion
+}
@@ -0,0 +1,17 @@
+package entity
+
+// SIEMEventCategory tells the SIEM where a security event comes from.
+type SIEMEventCategory string
+
+const (
+	// SIEMEventCategoryAudit is an audit record: an admin action or an event the server raised
+	SIEMEventCategoryAudit SIEMEventCategory = "audit"
+	// SIEMEventCategoryAuth is a request to an auth route such as a login, it is exported but not kept in the audit log
+	SIEMEventCategoryAuth SIEMEventCategory = "auth"
+)
+
+// SIEMEventEntity is a security event waiting for the siem export.
+type SIEMEventEntity struct {
+	Category SIEMEventCategory
+	Event    AuditLogEntity
+}
@@ -0,0 +1,8 @@
+package entity
+
+// SourceEncryptionResultEntity counts what encrypting the stored sources sealed.
+type SourceEncryptionResultEntity struct {
+	// ToolSources counts the tools and tool versions whose source was sealed
+	ToolSources   int
+	GlobalScripts int
+}
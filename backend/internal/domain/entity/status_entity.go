@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// StatusEntity is the public status of the instance. It only tells whether each component is healthy,
+// the backends, latencies and errors stay in the readiness report.
+type StatusEntity struct {
+	Healthy bool
+	Version string
+	Commit  string
+	Uptime  time.Duration
+	// Components maps a component name, such as database, to whether it is healthy
+	Components map[string]bool
+}
@@ -0,0 +1,73 @@
+package entity
+
+import "time"
+
+const (
+	SystemSettingKeyUserRegistrationEnabled = "registration.enabled"
+	SystemSettingKeyPasswordLoginEnabled    = "password_login.enabled"
+	SystemSettingKeyGithubSSOEnabled        = "sso.github.enabled"
+	SystemSettingKeyGoogleSSOEnabled        = "sso.google.enabled"
+	SystemSettingKeyMaintenanceMessage      = "maintenance.message"
+	SystemSettingKeyMaxToolsPerUser         = "quota.max_tools_per_user"
+
+	SystemSettingKeyAccessTokenTTL       = "session.access_token_ttl"
+	SystemSettingKeyRefreshTokenTTL      = "session.refresh_token_ttl"
+	SystemSettingKeyWebAuthnChallengeTTL = "session.webauthn_challenge_ttl"
+
+	SystemSettingKeyRefreshTokenCleanupSchedule      = "schedule.refresh_token_cleanup"
+	SystemSettingKeyKeyValueCompactionSchedule       = "schedule.key_value_compaction"
+	SystemSettingKeyDatabaseBackupSchedule           = "schedule.database_backup"
+	SystemSettingKeyDataRetentionSchedule            = "schedule.data_retention"
+	SystemSettingKeyExpiredCachePurgeSchedule        = "schedule.expired_cache_purge"
+	SystemSettingKeyPasskeyChallengeEvictionSchedule = "schedule.passkey_challenge_eviction"
+)
+
+type SystemSettingType string
+
+const (
+	SystemSettingTypeBool   SystemSettingType = "bool"
+	SystemSettingTypeInt    SystemSettingType = "int"
+	SystemSettingTypeString SystemSettingType = "string"
+)
+
+// SystemSettingEntity is a setting value stored in the database, it overrides the env config default of the key.
+type SystemSettingEntity struct {
+	Key       string
+	Value     string
+	UpdatedBy UserIDEntity
+	UpdatedAt time.Time
+}
+
+// SystemSettingDetailEntity describes a setting for the admin, with its effective value and where it comes from.
+type SystemSettingDetailEntity struct {
+	Key          string
+	Type         SystemSettingType
+	Description  string
+	Value        string
+	DefaultValue string
+	Overridden   bool
+	UpdatedBy    UserIDEntity
+	UpdatedAt    *time.Time
+}
+
+// SystemSettingsEntity is the typed, effective view of the runtime settings.
+type SystemSettingsEntity struct {
+	EnableUserRegistration bool
+	EnablePasswordLogin    bool
+	EnableGithubSSO        bool
+	EnableGoogleSSO        bool
+	MaintenanceMessage     string
+	// MaxToolsPerUser limits the tools a user can create, 0 means unlimited.
+	MaxToolsPerUser int
+	// lifetimes in seconds of the tokens and passkey challenges issued from now on
+	AccessTokenTTL       uint64
+	RefreshTokenTTL      uint64
+	WebAuthnChallengeTTL int
+	// cron expressions of the housekeeping jobs, empty disables the job
+	RefreshTokenCleanupSchedule      string
+	KeyValueCompactionSchedule       string
+	DatabaseBackupSchedule           string
+	DataRetentionSchedule            string
+	ExpiredCachePurgeSchedule        string
+	PasskeyChallengeEvictionSchedule string
+}
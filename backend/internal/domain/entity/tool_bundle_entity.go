@@ -0,0 +1,73 @@
+package entity
+
+import "time"
+
+// ToolBundleFormatVersion is the version of the bundle layout, a bundle of a newer version is rejected on import.
+const ToolBundleFormatVersion = 1
+
+// ToolBundleEntity is a portable copy of all tools, the global script and the preferences of a user, to be imported on
+// another instance. The unique ids of the tools are not kept, the importing instance assigns its own.
+type ToolBundleEntity struct {
+	FormatVersion int
+	ExportedAt    time.Time
+	// GlobalScript is nil when the user has none
+	GlobalScript *string
+	Tools        []ToolEntity
+	// Settings are the preferences the user has set, by setting key, empty in bundles exported before they existed
+	Settings map[string]string
+}
+
+// ToolImportConflict tells what to do with a tool of the bundle when the user already has a tool with the same namespace
+// and name.
+type ToolImportConflict string
+
+const (
+	// ToolImportConflictSkip keeps the existing tool and skips the one of the bundle
+	ToolImportConflictSkip ToolImportConflict = "skip"
+	// ToolImportConflictOverwrite replaces the existing tool with the one of the bundle, keeping its uid and history
+	ToolImportConflictOverwrite ToolImportConflict = "overwrite"
+	// ToolImportConflictRename imports the tool of the bundle next to the existing one under a numbered name
+	ToolImportConflictRename ToolImportConflict = "rename"
+)
+
+// ToolImportAction is what an import did with one tool of a bundle.
+type ToolImportAction string
+
+const (
+	ToolImportActionCreated     ToolImportAction = "created"
+	ToolImportActionOverwritten ToolImportAction = "overwritten"
+	ToolImportActionSkipped     ToolImportAction = "skipped"
+)
+
+// ToolImportResultEntity is the outcome of importing one tool of a bundle, Name and ID are the ones it was saved with.
+type ToolImportResultEntity struct {
+	Namespace string
+	Name      string
+	ToolID    string
+	// ToolUniqueID is the uid of the saved tool, empty when it was skipped
+	ToolUniqueID string
+	Action       ToolImportAction
+	// Reason tells why the tool was skipped, empty otherwise
+	Reason string
+	// Warnings lists credentials found in the source, see ToolSecretScanResultEntity
+	Warnings []ToolSecretFindingEntity
+}
+
+// ToolBundleImportEntity is the outcome of importing a bundle.
+type ToolBundleImportEntity struct {
+	Tools []ToolImportResultEntity
+	// GlobalScriptImported is false when the bundle has no global script or the user's own one was kept
+	GlobalScriptImported bool
+	// SettingsImported counts the preferences of the bundle that were stored, see UserSettingsService.ImportSettings
+	SettingsImported int
+}
+
+// HasSavedTools tells whether any tool of the bundle was created or overwritten.
+func (e ToolBundleImportEntity) HasSavedTools() bool {
+	for _, tool := range e.Tools {
+		if tool.Action != ToolImportActionSkipped {
+			return true
+		}
+	}
+	return false
+}
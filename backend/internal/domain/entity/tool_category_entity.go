@@ -0,0 +1,11 @@
+package entity
+
+// ToolCategoryEntity is a category used by the tools of a user together with the number of tools in it.
+type ToolCategoryEntity struct {
+	Name      string
+	ToolCount int
+}
+
+func NewToolCategoryEntity(name string, toolCount int) ToolCategoryEntity {
+	return ToolCategoryEntity{Name: name, ToolCount: toolCount}
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// ToolChangesEntity is what changed in the tools of a user after a sync cursor.
+// Cursor is the sequence number of the newest change included, acknowledging it marks these changes as synced.
+type ToolChangesEntity struct {
+	Cursor          int64
+	Tools           []ToolEntity
+	DeletedToolUIDs []string
+}
+
+// ToolsUpdatedSinceEntity is what changed in the tools of a user at or after a time.
+// Until is the time of the newest change included, or the requested time when nothing changed. Passing it as the time
+// of the next request returns the changes made at that time again, applying a change twice leaves a tool the same.
+type ToolsUpdatedSinceEntity struct {
+	Until           time.Time
+	CreatedTools    []ToolEntity
+	UpdatedTools    []ToolEntity
+	DeletedToolUIDs []string
+}
@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -8,19 +10,29 @@ import (
 )
 
 type ToolEntity struct {
-	UniqueID          string
-	ID                string
-	Name              string
-	Namespace         string
-	IsActivate        bool
+	UniqueID   string
+	ID         string
+	Name       string
+	Namespace  string
+	IsActivate bool
+	// IsArchived marks a retired tool, it is kept with its history but hidden from the default tool list and sync.
+	IsArchived        bool
 	RealtimeExecution bool
 	UiWidgets         string
 	Source            string
-	Description       string
-	ExtraInfo         map[string]string
-	Category          string
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
+	// SourceHash is the hex sha256 of Source, tools with the same hash share one stored source
+	SourceHash  string
+	Description string
+	ExtraInfo   map[string]string
+	Category    string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewToolUniqueID returns a new tool unique id. It holds a UUIDv7, so the ids sort by the time the tools were created.
+// Tools created by older versions keep their UUIDv4 ids, which do not sort by time.
+func NewToolUniqueID() string {
+	return fmt.Sprintf("tool-%s", uuid.Must(uuid.NewV7()).String())
 }
 
 func NewToolEntityWithoutUID(
@@ -31,9 +43,8 @@ func NewToolEntityWithoutUID(
 	extraInfo map[string]string,
 	createdAt, updatedAt time.Time,
 ) ToolEntity {
-	uniqueID := fmt.Sprintf("tool-%s", uuid.New().String())
 	return ToolEntity{
-		UniqueID:          uniqueID,
+		UniqueID:          NewToolUniqueID(),
 		ID:                id,
 		Name:              name,
 		Namespace:         namespace,
@@ -41,6 +52,7 @@ func NewToolEntityWithoutUID(
 		RealtimeExecution: realtimeExecution,
 		UiWidgets:         uiWidgets,
 		Source:            source,
+		SourceHash:        ToolSourceHash(source),
 		Description:       description,
 		ExtraInfo:         copyExtraInfo(extraInfo),
 		Category:          category,
@@ -67,6 +79,7 @@ func NewToolEntityWithUID(
 		RealtimeExecution: realtimeExecution,
 		UiWidgets:         uiWidgets,
 		Source:            source,
+		SourceHash:        ToolSourceHash(source),
 		Description:       description,
 		ExtraInfo:         copyExtraInfo(extraInfo),
 		Category:          category,
@@ -75,11 +88,65 @@ func NewToolEntityWithUID(
 	}
 }
 
+// ToolSourceHash returns the content address of a tool source, the hex sha256 of it.
+func ToolSourceHash(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
 type ToolsEntity struct {
 	Tools         []ToolEntity
 	LastUpdatedAt time.Time
 }
 
+// ToolArchiveFilter selects tools by their archived state when listing them.
+type ToolArchiveFilter string
+
+const (
+	ToolArchiveFilterExclude ToolArchiveFilter = "exclude"
+	ToolArchiveFilterInclude ToolArchiveFilter = "include"
+	ToolArchiveFilterOnly    ToolArchiveFilter = "only"
+)
+
+// FilterArchived keeps the tools matching the archive filter, an unknown filter behaves like ToolArchiveFilterExclude.
+func (t ToolsEntity) FilterArchived(filter ToolArchiveFilter) ToolsEntity {
+	if filter == ToolArchiveFilterInclude {
+		return t
+	}
+
+	tools := make([]ToolEntity, 0, len(t.Tools))
+	for _, tool := range t.Tools {
+		if tool.IsArchived == (filter == ToolArchiveFilterOnly) {
+			tools = append(tools, tool)
+		}
+	}
+	return ToolsEntity{Tools: tools, LastUpdatedAt: t.LastUpdatedAt}
+}
+
+// ToolSortBy orders a tool listing.
+type ToolSortBy string
+
+const (
+	// ToolSortByCreatedAt lists the oldest tools first
+	ToolSortByCreatedAt ToolSortBy = "created_at"
+	// ToolSortByUpdatedAt lists the most recently updated tools first
+	ToolSortByUpdatedAt ToolSortBy = "updated_at"
+	// ToolSortByName lists the tools by name
+	ToolSortByName ToolSortBy = "name"
+)
+
+// ToolListOptions narrows and pages a tool listing, nil filters match every tool. Archived behaves like
+// FilterArchived, an empty SortBy sorts by ToolSortByCreatedAt and a Limit of 0 returns every matching tool.
+type ToolListOptions struct {
+	Category   *string
+	Namespace  *string
+	IsActivate *bool
+	Archived   ToolArchiveFilter
+	SortBy     ToolSortBy
+	Limit      int
+	Offset     int
+}
+
 func copyExtraInfo(info map[string]string) map[string]string {
 	if info == nil {
 		return map[string]string{}
@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+type ToolEventType string
+
+const (
+	ToolEventTypeCreated  ToolEventType = "created"
+	ToolEventTypeUpdated  ToolEventType = "updated"
+	ToolEventTypeDeleted  ToolEventType = "deleted"
+	ToolEventTypeArchived ToolEventType = "archived"
+	ToolEventTypeRestored ToolEventType = "restored"
+)
+
+// fields of a tool named in ToolEventEntity.ChangedFields
+const (
+	ToolEventFieldID                = "id"
+	ToolEventFieldName              = "name"
+	ToolEventFieldNamespace         = "namespace"
+	ToolEventFieldCategory          = "category"
+	ToolEventFieldIsActivate        = "is_activate"
+	ToolEventFieldRealtimeExecution = "realtime_execution"
+	ToolEventFieldUiWidgets         = "ui_widgets"
+	ToolEventFieldSource            = "source"
+	ToolEventFieldDescription       = "description"
+	ToolEventFieldExtraInfo         = "extra_info"
+	ToolEventFieldIsArchived        = "is_archived"
+)
+
+// ToolEventSourceEntity tells who made a tool change, it is stored with the events of the change.
+// DeviceID is the device of the login the request named, empty when the request did not name one.
+type ToolEventSourceEntity struct {
+	ActorID   UserIDEntity
+	DeviceID  string
+	RequestID string
+}
+
+// ToolEventEntity records a create, update, archive or delete of a tool. Events are only appended, they stay after
+// the tool is deleted. ID is a sequence number, the events of a user are in ID order.
+type ToolEventEntity struct {
+	ID        int64
+	UserID    UserIDEntity
+	ToolUID   string
+	Type      ToolEventType
+	ActorID   UserIDEntity
+	DeviceID  string
+	RequestID string
+	// ChangedFields names the fields an update or archive changed, empty for creates and deletes
+	ChangedFields []string
+	CreatedAt     time.Time
+}
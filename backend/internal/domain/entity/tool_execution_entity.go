@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// ToolResourceClass selects the limits a run of a tool handler gets, so a heavy data processing tool does not need
+// the limits of a tiny formatter. A run of a larger class counts as more tool executions against the quota.
+type ToolResourceClass string
+
+const (
+	ToolResourceClassSmall  ToolResourceClass = "small"
+	ToolResourceClassMedium ToolResourceClass = "medium"
+	ToolResourceClassLarge  ToolResourceClass = "large"
+)
+
+// ParseToolResourceClass parses the resource class of a run, a run without one is small.
+func ParseToolResourceClass(value string) (ToolResourceClass, error) {
+	switch class := ToolResourceClass(value); class {
+	case "":
+		return ToolResourceClassSmall, nil
+	case ToolResourceClassSmall, ToolResourceClassMedium, ToolResourceClassLarge:
+		return class, nil
+	default:
+		return "", fmt.Errorf("unknown resource class %q, use small, medium or large", value)
+	}
+}
+
+// ExecutionWeight is how many tool executions a run of the class is metered as.
+func (c ToolResourceClass) ExecutionWeight() int64 {
+	switch c {
+	case ToolResourceClassMedium:
+		return 4
+	case ToolResourceClassLarge:
+		return 16
+	default:
+		return 1
+	}
+}
+
+// ToolExecutionRequestEntity holds the arguments a tool handler is called with in a run on the server.
+type ToolExecutionRequestEntity struct {
+	// Inputs are the values of the input widgets by widget id
+	Inputs map[string]any
+	// ChangedWidgetID is the input widget that triggered the run, empty when none did
+	ChangedWidgetID string
+	// ResourceClass selects the limits of the run, empty runs it as small
+	ResourceClass ToolResourceClass
+}
+
+// ToolExecutionLimitsEntity bounds a single run of a tool handler.
+type ToolExecutionLimitsEntity struct {
+	Timeout time.Duration
+	// MaxPayloadBytes bounds the JSON size of the outputs and of the updates sent through the callback together
+	MaxPayloadBytes int
+	// MaxMemoryBytes bounds how much memory the handler may allocate, 0 leaves it unbounded
+	MaxMemoryBytes int
+}
+
+// ToolExecutionLogEntity is a console call made by the handler.
+type ToolExecutionLogEntity struct {
+	// Level is the console method, log, info, warn, error or debug
+	Level   string
+	Message string
+}
+
+// ToolExecutionResultEntity is the outcome of a run of a tool handler.
+type ToolExecutionResultEntity struct {
+	// Outputs is the object the handler returned, nil when it returned undefined or null
+	Outputs map[string]any
+	// Updates are the objects the handler passed to the callback, in order
+	Updates  []map[string]any
+	Logs     []ToolExecutionLogEntity
+	Duration time.Duration
+}
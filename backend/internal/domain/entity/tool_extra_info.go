@@ -0,0 +1,146 @@
+package entity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ToolExtraInfoKeyExecInterval is the interval in milliseconds a realtime tool is re-executed at.
+	ToolExtraInfoKeyExecInterval = "execInterval"
+	// ToolExtraInfoKeyInputSchema is a JSON Schema of the handler arguments, it replaces the one derived from the ui widgets.
+	ToolExtraInfoKeyInputSchema = "inputSchema"
+	// ToolExtraInfoKeyOutputSchema is a JSON Schema of the object the handler returns.
+	ToolExtraInfoKeyOutputSchema = "outputSchema"
+	// ToolExtraInfoKeyTags are comma separated tags of the tool, the gallery can be searched by them.
+	ToolExtraInfoKeyTags = "tags"
+	// ToolExtraInfoKeyGithubSource is where a tool imported from GitHub came from, set by the server only.
+	ToolExtraInfoKeyGithubSource = "toolbake.githubSource"
+	// ToolExtraInfoKeyForkedFrom is the uid of the gallery tool a tool was forked from, set by the server only.
+	ToolExtraInfoKeyForkedFrom = "toolbake.forkedFrom"
+
+	// toolExtraInfoReservedPrefix marks keys that are managed by the server and cannot be set by clients.
+	toolExtraInfoReservedPrefix = "toolbake."
+
+	toolExtraInfoMaxKeys        = 64
+	toolExtraInfoMaxKeyLength   = 128
+	toolExtraInfoMaxValueLength = 4096
+
+	toolMaxTags      = 20
+	toolMaxTagLength = 32
+)
+
+// toolExtraInfoValidators validates the values of well known extra info keys.
+var toolExtraInfoValidators = map[string]func(value string) error{
+	ToolExtraInfoKeyExecInterval: func(value string) error {
+		interval, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("must be a positive integer in milliseconds")
+		}
+		return nil
+	},
+	ToolExtraInfoKeyInputSchema:  validateToolSchemaExtraInfo,
+	ToolExtraInfoKeyOutputSchema: validateToolSchemaExtraInfo,
+	ToolExtraInfoKeyTags: func(value string) error {
+		for _, tag := range strings.Split(value, ",") {
+			if strings.TrimSpace(tag) == "" {
+				return fmt.Errorf("must be comma separated tags without empty ones")
+			}
+			if len(strings.TrimSpace(tag)) > toolMaxTagLength {
+				return fmt.Errorf("tag %q exceeds %d characters", strings.TrimSpace(tag), toolMaxTagLength)
+			}
+		}
+		if len(ParseToolTags(value)) > toolMaxTags {
+			return fmt.Errorf("can contain at most %d tags", toolMaxTags)
+		}
+		return nil
+	},
+}
+
+// ParseToolTags returns the tags of a tags extra info value lower cased, without blanks and duplicates.
+func ParseToolTags(value string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// NormalizeToolTag returns the tag as it is stored, lower cased without surrounding blanks. A tag can not be blank,
+// longer than the tag limit or contain a comma.
+func NormalizeToolTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", fmt.Errorf("tag must not be empty")
+	}
+	if strings.Contains(tag, ",") {
+		return "", fmt.Errorf("tag %q must not contain a comma", tag)
+	}
+	if len(tag) > toolMaxTagLength {
+		return "", fmt.Errorf("tag %q exceeds %d characters", tag, toolMaxTagLength)
+	}
+	return tag, nil
+}
+
+// ToolTagsValue joins tags into a tags extra info value.
+func ToolTagsValue(tags []string) string {
+	return strings.Join(tags, ",")
+}
+
+// ValidateToolTags checks a tags extra info value the way ValidateToolExtraInfo does.
+func ValidateToolTags(value string) error {
+	return toolExtraInfoValidators[ToolExtraInfoKeyTags](value)
+}
+
+func validateToolSchemaExtraInfo(value string) error {
+	_, err := ParseToolSchema(value)
+	return err
+}
+
+// ValidateToolExtraInfo checks the extra info a client wants to store on a tool.
+// Unknown keys are accepted as free-form strings, reserved and well known keys are checked.
+func ValidateToolExtraInfo(info map[string]string) error {
+	if len(info) > toolExtraInfoMaxKeys {
+		return fmt.Errorf("extra_info can contain at most %d keys", toolExtraInfoMaxKeys)
+	}
+
+	for key, value := range info {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("extra_info key must not be empty")
+		}
+		if len(key) > toolExtraInfoMaxKeyLength {
+			return fmt.Errorf("extra_info key %q exceeds %d characters", key, toolExtraInfoMaxKeyLength)
+		}
+		if strings.HasPrefix(key, toolExtraInfoReservedPrefix) {
+			return fmt.Errorf("extra_info key %q uses the reserved prefix %q", key, toolExtraInfoReservedPrefix)
+		}
+		if len(value) > toolExtraInfoMaxValueLength {
+			return fmt.Errorf("extra_info value of %q exceeds %d characters", key, toolExtraInfoMaxValueLength)
+		}
+		if validator, ok := toolExtraInfoValidators[key]; ok {
+			if err := validator(value); err != nil {
+				return fmt.Errorf("extra_info %q %s", key, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// WithoutReservedToolExtraInfo returns a copy of the extra info without the keys managed by the server,
+// for extra info that comes from outside such as an imported bundle.
+func WithoutReservedToolExtraInfo(info map[string]string) map[string]string {
+	result := make(map[string]string, len(info))
+	for key, value := range info {
+		if !strings.HasPrefix(key, toolExtraInfoReservedPrefix) {
+			result[key] = value
+		}
+	}
+	return result
+}
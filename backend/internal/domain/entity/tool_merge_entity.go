@@ -0,0 +1,71 @@
+package entity
+
+const (
+	ToolMergeFieldID                = "id"
+	ToolMergeFieldName              = "name"
+	ToolMergeFieldNamespace         = "namespace"
+	ToolMergeFieldCategory          = "category"
+	ToolMergeFieldDescription       = "description"
+	ToolMergeFieldIsActivate        = "is_activate"
+	ToolMergeFieldRealtimeExecution = "realtime_execution"
+	ToolMergeFieldUiWidgets         = "ui_widgets"
+	ToolMergeFieldSource            = "source"
+	// ToolMergeFieldExtraInfoPrefix prefixes the key of an extra info entry, e.g. "extra_info.color".
+	ToolMergeFieldExtraInfoPrefix = "extra_info."
+)
+
+// ToolMergeStatus tells which side changed a field compared to the base version.
+type ToolMergeStatus string
+
+const (
+	// ToolMergeStatusLocal means only the edited version changed the field, its value is taken.
+	ToolMergeStatusLocal ToolMergeStatus = "local"
+	// ToolMergeStatusServer means only the server version changed the field, its value is kept.
+	ToolMergeStatusServer ToolMergeStatus = "server"
+	// ToolMergeStatusBoth means both sides made the same change.
+	ToolMergeStatusBoth ToolMergeStatus = "both"
+	// ToolMergeStatusMerged means both sides changed the source on different lines and the changes were combined.
+	ToolMergeStatusMerged ToolMergeStatus = "merged"
+	// ToolMergeStatusConflict means both sides changed the field differently, it cannot be merged automatically.
+	ToolMergeStatusConflict ToolMergeStatus = "conflict"
+)
+
+// ToolMergeFieldEntity is a field changed by at least one side since the base version.
+// A nil value is an extra info key missing in that version.
+type ToolMergeFieldEntity struct {
+	Field  string
+	Base   *string
+	Local  *string
+	Server *string
+	Status ToolMergeStatus
+}
+
+// ToolSourceConflictEntity is a range of source lines both sides changed differently.
+// The line numbers start at 1 and point at where the range starts in each version.
+type ToolSourceConflictEntity struct {
+	BaseLine    int
+	LocalLine   int
+	ServerLine  int
+	BaseLines   []string
+	LocalLines  []string
+	ServerLines []string
+}
+
+// ToolMergeEntity is the three-way diff of an edited tool against the server version, from their common base.
+// Merged is only set when there is no conflict.
+type ToolMergeEntity struct {
+	Server          ToolEntity
+	Fields          []ToolMergeFieldEntity
+	SourceConflicts []ToolSourceConflictEntity
+	Merged          *ToolEntity
+}
+
+// HasConflicts reports whether any field needs to be resolved by hand.
+func (m ToolMergeEntity) HasConflicts() bool {
+	for _, field := range m.Fields {
+		if field.Status == ToolMergeStatusConflict {
+			return true
+		}
+	}
+	return false
+}
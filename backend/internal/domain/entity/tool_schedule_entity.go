@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ToolScheduleEntity runs a tool of the user on the server whenever its cron expression matches, with stored inputs.
+type ToolScheduleEntity struct {
+	ID      string
+	UserID  UserIDEntity
+	ToolUID string
+	Name    string
+	// Cron is a five field cron expression or a descriptor such as @daily, evaluated in UTC
+	Cron string
+	// Inputs are the values of the input widgets the handler is called with
+	Inputs map[string]any
+	// ResourceClass selects the limits of the scheduled runs
+	ResourceClass ToolResourceClass
+	Enabled       bool
+	// NextRunAt is when the schedule is due next, nil while it is disabled
+	NextRunAt *time.Time
+	LastRunAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func NewToolScheduleEntityWithoutID(userID UserIDEntity, toolUID, name, cron string, inputs map[string]any, resourceClass ToolResourceClass, enabled bool) ToolScheduleEntity {
+	return ToolScheduleEntity{
+		ID:            fmt.Sprintf("schedule-%s", uuid.Must(uuid.NewV7()).String()),
+		UserID:        userID,
+		ToolUID:       toolUID,
+		Name:          name,
+		Cron:          cron,
+		Inputs:        inputs,
+		ResourceClass: resourceClass,
+		Enabled:       enabled,
+	}
+}
+
+type ToolRunStatus string
+
+const (
+	ToolRunStatusSuccess ToolRunStatus = "success"
+	ToolRunStatusFailed  ToolRunStatus = "failed"
+)
+
+// ToolRunEntity is the recorded outcome of a scheduled run of a tool.
+type ToolRunEntity struct {
+	ID         string
+	UserID     UserIDEntity
+	ToolUID    string
+	ScheduleID string
+	Status     ToolRunStatus
+	// Outputs is the object the handler returned, nil when it failed or returned nothing
+	Outputs map[string]any
+	Logs    []ToolExecutionLogEntity
+	// ErrorCode and ErrorMessage tell why a failed run failed, such as ToolExecutionTimedOut
+	ErrorCode    string
+	ErrorMessage string
+	StartedAt    time.Time
+	Duration     time.Duration
+}
+
+func NewToolRunEntityWithoutID(schedule ToolScheduleEntity, startedAt time.Time) ToolRunEntity {
+	return ToolRunEntity{
+		ID:         fmt.Sprintf("run-%s", uuid.Must(uuid.NewV7()).String()),
+		UserID:     schedule.UserID,
+		ToolUID:    schedule.ToolUID,
+		ScheduleID: schedule.ID,
+		StartedAt:  startedAt,
+	}
+}
@@ -0,0 +1,130 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ToolSchemaEntity describes the arguments a tool handler takes and the object it returns as JSON Schemas.
+// A schema declared in the extra info is used as is, otherwise it is derived from the ui widgets.
+type ToolSchemaEntity struct {
+	Input         map[string]any
+	Output        map[string]any
+	InputDerived  bool
+	OutputDerived bool
+}
+
+// ToolSchemaValidationEntity is the result of checking a tool input and output against the tool schemas.
+// Each problem is "<json path>: <message>", e.g. "$.count: must be a number".
+type ToolSchemaValidationEntity struct {
+	InputProblems  []string
+	OutputProblems []string
+}
+
+func (v ToolSchemaValidationEntity) Valid() bool {
+	return len(v.InputProblems) == 0 && len(v.OutputProblems) == 0
+}
+
+// toolSchemaTypes are the JSON Schema types a tool schema can use.
+var toolSchemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true,
+}
+
+// ParseToolSchema decodes a tool schema declared in the extra info.
+// Only the keywords the server validates are checked, other keywords are kept as annotations.
+func ParseToolSchema(value string) (map[string]any, error) {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(value), &schema); err != nil {
+		return nil, fmt.Errorf("must be a JSON Schema object")
+	}
+	if err := checkToolSchema(schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func checkToolSchema(schema map[string]any) error {
+	if rawType, ok := schema["type"]; ok {
+		types, ok := rawType.([]any)
+		if !ok {
+			types = []any{rawType}
+		}
+		for _, t := range types {
+			name, _ := t.(string)
+			if !toolSchemaTypes[name] {
+				return fmt.Errorf("has an unknown type %v", t)
+			}
+		}
+	}
+
+	if rawProperties, ok := schema["properties"]; ok {
+		properties, ok := rawProperties.(map[string]any)
+		if !ok {
+			return fmt.Errorf("properties must be an object")
+		}
+		for name, rawProperty := range properties {
+			property, ok := rawProperty.(map[string]any)
+			if !ok {
+				return fmt.Errorf("property %q must be a schema object", name)
+			}
+			if err := checkToolSchema(property); err != nil {
+				return fmt.Errorf("property %q %s", name, err.Error())
+			}
+		}
+	}
+
+	if rawItems, ok := schema["items"]; ok {
+		items, ok := rawItems.(map[string]any)
+		if !ok {
+			return fmt.Errorf("items must be a schema object")
+		}
+		if err := checkToolSchema(items); err != nil {
+			return fmt.Errorf("items %s", err.Error())
+		}
+	}
+
+	if rawRequired, ok := schema["required"]; ok {
+		required, ok := rawRequired.([]any)
+		if !ok {
+			return fmt.Errorf("required must be an array of property names")
+		}
+		for _, name := range required {
+			if _, ok := name.(string); !ok {
+				return fmt.Errorf("required must be an array of property names")
+			}
+		}
+	}
+
+	if rawEnum, ok := schema["enum"]; ok {
+		if _, ok := rawEnum.([]any); !ok {
+			return fmt.Errorf("enum must be an array")
+		}
+	}
+
+	if rawAdditional, ok := schema["additionalProperties"]; ok {
+		switch additional := rawAdditional.(type) {
+		case bool:
+		case map[string]any:
+			if err := checkToolSchema(additional); err != nil {
+				return fmt.Errorf("additionalProperties %s", err.Error())
+			}
+		default:
+			return fmt.Errorf("additionalProperties must be a boolean or a schema object")
+		}
+	}
+
+	for _, keyword := range []string{"minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems"} {
+		if rawBound, ok := schema[keyword]; ok {
+			if _, ok := rawBound.(float64); !ok {
+				return fmt.Errorf("%s must be a number", keyword)
+			}
+		}
+	}
+	return nil
+}
+
+// InvocableToolEntity is an active tool with its schemas, invoked by namespace and name through the tool invocation api.
+type InvocableToolEntity struct {
+	Tool   ToolEntity
+	Schema ToolSchemaEntity
+}
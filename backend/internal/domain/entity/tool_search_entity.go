@@ -0,0 +1,31 @@
+package entity
+
+const (
+	ToolSearchFieldID          = "id"
+	ToolSearchFieldName        = "name"
+	ToolSearchFieldNamespace   = "namespace"
+	ToolSearchFieldCategory    = "category"
+	ToolSearchFieldDescription = "description"
+	ToolSearchFieldSource      = "source"
+)
+
+// TextRangeEntity is a half-open [Start, End) range of characters (runes) in a text.
+type TextRangeEntity struct {
+	Start int
+	End   int
+}
+
+// ToolSourceMatchEntity is a line of a tool source that matched a search query.
+// Highlights are relative to Snippet, which is the matched line or a window of it for long lines.
+type ToolSourceMatchEntity struct {
+	Line       int
+	Snippet    string
+	Highlights []TextRangeEntity
+}
+
+// ToolSearchResultEntity is a tool that matched a search query together with where it matched.
+type ToolSearchResultEntity struct {
+	Tool          ToolEntity
+	MatchedFields []string
+	SourceMatches []ToolSourceMatchEntity
+}
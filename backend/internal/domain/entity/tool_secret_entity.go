@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// ToolSecretEntity is a secret variable injected into the runs of a tool, ToolUID is empty for an account level
+// secret shared by every tool of the user. EncryptedValue is sealed with the user's encrypt key, the plaintext
+// only leaves the service to be injected into a run.
+type ToolSecretEntity struct {
+	UserID         UserIDEntity
+	ToolUID        string
+	Name           string
+	EncryptedValue string
+	// Version starts at 1 and is increased every time the value is rotated
+	Version   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ToolSecretInfoEntity describes a secret without its value, MaskedValue only keeps enough of it to tell two values apart.
+type ToolSecretInfoEntity struct {
+	ToolUID     string
+	Name        string
+	MaskedValue string
+	Version     int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,18 @@
+package entity
+
+// ToolSecretFindingEntity is a credential like string found in tool source.
+type ToolSecretFindingEntity struct {
+	// Rule names what was found, such as aws_access_key_id or private_key
+	Rule string
+	// Line is 1-based
+	Line int
+	// Masked shows only the first characters of the match, the secret itself is never echoed back
+	Masked string
+}
+
+// ToolSecretScanResultEntity is the outcome of scanning tool source before it is saved.
+type ToolSecretScanResultEntity struct {
+	Findings []ToolSecretFindingEntity
+	// Blocked is true when the configured scan mode rejects source with findings
+	Blocked bool
+}
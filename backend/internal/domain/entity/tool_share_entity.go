@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+type ToolSharePermission string
+
+const (
+	// ToolSharePermissionRead lets the grantee see the tool
+	ToolSharePermissionRead ToolSharePermission = "read"
+	// ToolSharePermissionFork also lets the grantee copy the tool into their own tools
+	ToolSharePermissionFork ToolSharePermission = "fork"
+)
+
+// ToolShareEntity grants access to a tool of OwnerID. GranteeID is empty for the public link of the tool, anyone
+// who knows the ID of a public share can open it.
+type ToolShareEntity struct {
+	ID           string
+	OwnerID      UserIDEntity
+	ToolUniqueID string
+	GranteeID    UserIDEntity
+	Permission   ToolSharePermission
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (s ToolShareEntity) IsPublic() bool {
+	return s.GranteeID == ""
+}
+
+func (s ToolShareEntity) CanFork() bool {
+	return s.Permission == ToolSharePermissionFork
+}
+
+// SharedToolEntity is a tool shared with a user, with the share that grants it.
+type SharedToolEntity struct {
+	Share     ToolShareEntity
+	OwnerName string
+	Tool      ToolEntity
+}
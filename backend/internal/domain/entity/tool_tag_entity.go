@@ -0,0 +1,11 @@
+package entity
+
+// ToolTagEntity is a tag used by the tools of a user together with the number of tools that have it.
+type ToolTagEntity struct {
+	Name      string
+	ToolCount int
+}
+
+func NewToolTagEntity(name string, toolCount int) ToolTagEntity {
+	return ToolTagEntity{Name: name, ToolCount: toolCount}
+}
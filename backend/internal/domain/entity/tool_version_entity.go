@@ -0,0 +1,41 @@
+package entity
+
+import "time"
+
+// ToolVersionEntity is a saved state of the source and ui widgets of a tool. Versions are numbered from 1 per tool,
+// a new one is recorded when a tool is created and whenever an update changes its source or ui widgets.
+type ToolVersionEntity struct {
+	ToolUniqueID string
+	Version      int
+	UiWidgets    string
+	Source       string
+	// SourceHash is the hex sha256 of Source, see ToolSourceHash
+	SourceHash string
+	CreatedAt  time.Time
+}
+
+// ToolVersionDiffEntity is the change from one version of a tool to another, the lines of the source and of the
+// ui widgets that were removed or added. A part that did not change has no lines.
+type ToolVersionDiffEntity struct {
+	ToolUniqueID string
+	FromVersion  int
+	ToVersion    int
+	Source       []ToolVersionDiffLineEntity
+	UiWidgets    []ToolVersionDiffLineEntity
+}
+
+type ToolVersionDiffLineKind string
+
+const (
+	ToolVersionDiffLineRemoved ToolVersionDiffLineKind = "removed"
+	ToolVersionDiffLineAdded   ToolVersionDiffLineKind = "added"
+)
+
+// ToolVersionDiffLineEntity is a removed or added line. FromLine is its 1-based number in the older version and
+// ToLine in the newer one, the other is the line number the change sits at.
+type ToolVersionDiffLineEntity struct {
+	Kind     ToolVersionDiffLineKind
+	FromLine int
+	ToLine   int
+	Text     string
+}
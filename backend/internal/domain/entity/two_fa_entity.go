@@ -6,6 +6,10 @@ type TwoFAType string
 
 const (
 	TwoFATypeTOTP TwoFAType = "totp"
+	// TwoFATypeWebAuthn asks for an assertion of one of the user's passkeys, it has no secret of its own.
+	TwoFATypeWebAuthn TwoFAType = "webauthn"
+	// TwoFATypeRecoveryCode completes a login with one of the single-use recovery codes, it is not a method of its own
+	TwoFATypeRecoveryCode TwoFAType = "recovery_code"
 )
 
 type TwoFAEntity struct {
@@ -33,3 +37,24 @@ func NewTwoFAEntity(
 		UpdatedAt: now,
 	}
 }
+
+// TwoFAMethodsEntity lists the verified 2FA methods a login can be completed with.
+// Preferred is the method the user picked as default, or the first method when none is picked.
+type TwoFAMethodsEntity struct {
+	Methods   []TwoFAType
+	Preferred TwoFAType
+}
+
+// TwoFAVerificationEntity is the proof of one 2FA method: Code for TOTP and recovery codes, WebAuthnResponse for WebAuthn.
+type TwoFAVerificationEntity struct {
+	Method           TwoFAType
+	Code             string
+	WebAuthnResponse *PasskeyLoginRequestEntity
+}
+
+// RecoveryCodesStatusEntity counts the single-use recovery codes of the current set, both are 0 when there is no set.
+type RecoveryCodesStatusEntity struct {
+	Remaining   int
+	Total       int
+	GeneratedAt *time.Time
+}
@@ -0,0 +1,42 @@
+package entity
+
+import "time"
+
+// UsageMetric names a resource whose use is metered per user.
+type UsageMetric string
+
+const (
+	// UsageMetricAPICalls counts the authenticated api requests of a user.
+	UsageMetricAPICalls UsageMetric = "api_calls"
+	// UsageMetricStorageBytes is the size of the tools a user stores, it is set rather than counted.
+	UsageMetricStorageBytes UsageMetric = "storage_bytes"
+	// UsageMetricToolExecutions counts the tool runs on the server of a user.
+	UsageMetricToolExecutions UsageMetric = "tool_executions"
+)
+
+// UsagePeriodLayout formats the month usage is recorded in, e.g. "2026-01".
+const UsagePeriodLayout = "2006-01"
+
+// UsagePeriod returns the period a moment belongs to, periods are calendar months in UTC.
+func UsagePeriod(at time.Time) string {
+	return at.UTC().Format(UsagePeriodLayout)
+}
+
+// UsageEntity is the use of a metric by a user within a period.
+type UsageEntity struct {
+	UserID    UserIDEntity
+	Metric    UsageMetric
+	Period    string
+	Amount    int64
+	UpdatedAt time.Time
+}
+
+// UsageRequestEntity asks whether a user may use an amount of a metric on top of the usage of the current period.
+// For storage bytes Usage is the size already stored and Amount the size the write adds, a negative Amount frees space.
+type UsageRequestEntity struct {
+	UserID UserIDEntity
+	Metric UsageMetric
+	Period string
+	Amount int64
+	Usage  int64
+}
@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserDataExportStatus string
+
+const (
+	UserDataExportStatusPending UserDataExportStatus = "pending"
+	UserDataExportStatusRunning UserDataExportStatus = "running"
+	UserDataExportStatusDone    UserDataExportStatus = "done"
+	UserDataExportStatusFailed  UserDataExportStatus = "failed"
+)
+
+// UserDataExportEntity is a request of a user for an archive of all their data, the archive is built in the
+// background and kept until it expires.
+type UserDataExportEntity struct {
+	ID     string
+	UserID UserIDEntity
+	Status UserDataExportStatus
+	// Progress counts the finished steps of the Total steps building the archive
+	Progress int
+	Total    int
+	// Error tells why a failed export failed
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// FinishedAt is nil until the export is done or failed
+	FinishedAt *time.Time
+}
+
+func NewUserDataExportEntityWithoutID(userID UserIDEntity, total int) UserDataExportEntity {
+	return UserDataExportEntity{
+		ID:     fmt.Sprintf("export-%s", uuid.Must(uuid.NewV7()).String()),
+		UserID: userID,
+		Status: UserDataExportStatusPending,
+		Total:  total,
+	}
+}
+
+// Active tells whether the export is still waiting or being built.
+func (export UserDataExportEntity) Active() bool {
+	return export.Status == UserDataExportStatusPending || export.Status == UserDataExportStatusRunning
+}
+
+// UserDataArchiveEntity is everything kept about a user, as a data export hands it out. It leaves out the password
+// hash, the encrypt key, the tool secrets and the public keys of the passkeys.
+type UserDataArchiveEntity struct {
+	ExportedAt   time.Time
+	Profile      UserDataProfileEntity
+	SSOBindings  []UserSSOEntity
+	Tools        []ToolEntity
+	GlobalScript *string
+	Passkeys     []UserDataPasskeyEntity
+	// AuditLogs are the audit records of the requests the user made, newest first
+	AuditLogs []AuditLogEntity
+}
+
+type UserDataProfileEntity struct {
+	ID          UserIDEntity
+	Name        string
+	Mail        *string
+	Roles       []string
+	HasPassword bool
+	LastLoginAt *time.Time
+	LoginCount  int
+}
+
+// UserDataPasskeyEntity is the metadata of a passkey of the user.
+type UserDataPasskeyEntity struct {
+	ID             int64
+	DeviceName     *string
+	AAGUID         string
+	Transports     *string
+	BackupEligible *bool
+	BackupState    *bool
+	CreatedAt      time.Time
+	LastUsedAt     *time.Time
+}
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// UserDeviceEntity is a client of a user, issued at login. SyncCursor is the tool change sequence number
+// the device has acknowledged, changes after it are what the device has not seen yet.
+type UserDeviceEntity struct {
+	ID         string
+	UserID     UserIDEntity
+	Name       string
+	SyncCursor int64
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+}
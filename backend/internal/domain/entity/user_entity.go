@@ -1,5 +1,7 @@
 package entity
 
+import "time"
+
 type UserEntity struct {
 	ID   UserIDEntity
 	Name string
@@ -11,6 +13,19 @@ type UserEntity struct {
 	EncrypKey string
 
 	SSOBindings []UserSSOEntity
+
+	// LastLoginAt is nil until the first successful login
+	LastLoginAt *time.Time
+	LoginCount  int
+
+	// PasswordChangeRequired is set by an admin, the user can do nothing but change the password until it is cleared
+	PasswordChangeRequired bool
+
+	// Disabled is set by an admin, a disabled user can not log in and every session of the user is refused
+	Disabled bool
+
+	// DeletionScheduledAt is when the account is purged, nil unless the user asked to delete it
+	DeletionScheduledAt *time.Time
 }
 
 // check use if has specific role
@@ -23,6 +38,11 @@ func (user UserEntity) HasRole(role UserRoleEntity) bool {
 	return false
 }
 
+// HasPermission reports whether the user may perform the admin action, admins hold every permission
+func (user UserEntity) HasPermission(permission UserPermissionEntity) bool {
+	return user.HasRole(UserRoleAdmin) || user.HasRole(UserRoleEntity{RoleName: string(permission)})
+}
+
 func NewUserEntity(
 	id UserIDEntity,
 	name string,
@@ -40,3 +60,18 @@ func NewUserEntity(
 		EncrypKey:    encrypKey,
 	}
 }
+
+// UserFilter narrows a user listing, zero values match everything.
+type UserFilter struct {
+	// Query matches a part of the username or the email
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// UserSummaryEntity is a user as admins list it, with the number of tools the user owns.
+type UserSummaryEntity struct {
+	User      UserEntity
+	ToolCount int
+	CreatedAt time.Time
+}
@@ -0,0 +1,19 @@
+package entity
+
+// UserMergeResultEntity describes what was moved from the duplicate account to the surviving one.
+type UserMergeResultEntity struct {
+	SurvivorID  UserIDEntity
+	DuplicateID UserIDEntity
+
+	MovedTools int
+	// RenamedTools maps the id of a moved tool to its new id, for ids the surviving account already used
+	RenamedTools      map[string]string
+	MovedPasskeys     int
+	MovedSSOBindings  int
+	MovedGlobalScript bool
+	// MovedPassword and MovedEmail are set when the surviving account had none and took the duplicate's
+	MovedPassword bool
+	MovedEmail    bool
+	// DeletedSecrets counts the duplicate's tool secrets, they are sealed with the duplicate's encrypt key and have to be set again
+	DeletedSecrets int
+}
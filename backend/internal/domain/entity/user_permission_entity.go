@@ -0,0 +1,27 @@
+package entity
+
+// UserPermissionEntity is an admin action on other users that can be granted on its own.
+// A user holds a permission through a role of the same name, or through the admin role which holds them all.
+type UserPermissionEntity string
+
+const (
+	// UserPermissionRevokeSessions signs a user out of every device
+	UserPermissionRevokeSessions UserPermissionEntity = "users:revoke_sessions"
+	// UserPermissionRequirePasswordChange makes a user change the password before doing anything else
+	UserPermissionRequirePasswordChange UserPermissionEntity = "users:require_password_change"
+	// UserPermissionResetCredentials removes a 2FA method or a passkey of a user
+	UserPermissionResetCredentials UserPermissionEntity = "users:reset_credentials"
+	// UserPermissionListUsers lists the users with their tool counts
+	UserPermissionListUsers UserPermissionEntity = "users:list"
+	// UserPermissionDisableUsers disables and enables the account of a user
+	UserPermissionDisableUsers UserPermissionEntity = "users:disable"
+)
+
+// UserPermissions lists every permission, each one can be given to a user as a role of the same name
+var UserPermissions = []UserPermissionEntity{
+	UserPermissionRevokeSessions,
+	UserPermissionRequirePasswordChange,
+	UserPermissionResetCredentials,
+	UserPermissionListUsers,
+	UserPermissionDisableUsers,
+}
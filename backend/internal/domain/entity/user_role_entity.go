@@ -8,3 +8,16 @@ var (
 	UserRoleAdmin = UserRoleEntity{RoleName: "admin"}
 	UserRoleUser  = UserRoleEntity{RoleName: "user"}
 )
+
+// Known reports whether the role is the admin or the user role or names a permission
+func (role UserRoleEntity) Known() bool {
+	if role == UserRoleAdmin || role == UserRoleUser {
+		return true
+	}
+	for _, permission := range UserPermissions {
+		if role.RoleName == string(permission) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+const (
+	UserSettingKeyTheme          = "theme"
+	UserSettingKeyEditorFontSize = "editor.font_size"
+	UserSettingKeyEditorTabSize  = "editor.tab_size"
+	UserSettingKeyEditorWordWrap = "editor.word_wrap"
+	UserSettingKeySyncEnabled    = "sync.enabled"
+)
+
+// UserSettingEntity is a preference of a user stored on the server, so it follows the user to every device.
+type UserSettingEntity struct {
+	Key       string
+	Value     string
+	UpdatedAt time.Time
+}
+
+// UserSettingDetailEntity is a known preference with its effective value, the default when the user did not set it.
+type UserSettingDetailEntity struct {
+	Key          string
+	Type         SystemSettingType
+	Value        string
+	DefaultValue string
+	// UpdatedAt is nil when the user did not set the preference
+	UpdatedAt *time.Time
+}
@@ -0,0 +1,21 @@
+package repository
+
+import "errors"
+
+// Sentinel errors returned by repository implementations, callers match them with errors.Is
+// and translate them into error codes.
+var (
+	ErrToolNotFound              = errors.New("tool not found")
+	ErrToolNameTaken             = errors.New("tool name already used in the namespace")
+	ErrToolIDTaken               = errors.New("tool id already used by another tool of the user")
+	ErrToolUIDTaken              = errors.New("tool unique id already used by another tool")
+	ErrAnnouncementNotFound      = errors.New("announcement not found")
+	ErrToolCategoryNotFound      = errors.New("tool category not found")
+	ErrToolCategoryAlreadyExists = errors.New("tool category already exists")
+	ErrPasskeyCredentialExists   = errors.New("passkey credential already exists")
+	ErrPasskeyNotFound           = errors.New("passkey not found")
+	ErrUserSSOBindingNotFound    = errors.New("user sso binding not found")
+	ErrNamespaceNotFound         = errors.New("namespace not found")
+	ErrNamespaceAlreadyExists    = errors.New("namespace already exists")
+	ErrToolScheduleNotFound      = errors.New("tool schedule not found")
+)
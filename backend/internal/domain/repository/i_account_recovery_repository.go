@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_account_recovery_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IAccountRecoveryRepository
+type IAccountRecoveryRepository interface {
+	Create(ctx context.Context, request entity.AccountRecoveryRequestEntity) error
+	GetByID(ctx context.Context, id string) (entity.AccountRecoveryRequestEntity, bool, error)
+	// List returns the requests with the status newest first, an empty status lists every request
+	List(ctx context.Context, status entity.AccountRecoveryStatus) ([]entity.AccountRecoveryRequestEntity, error)
+	// ListByUserID returns the requests of a user newest first
+	ListByUserID(ctx context.Context, userID entity.UserIDEntity) ([]entity.AccountRecoveryRequestEntity, error)
+	// UpdateStatus moves the request to the status only if it is still in one of the from statuses,
+	// false means the request is missing or was changed meanwhile
+	UpdateStatus(ctx context.Context, id string, from []entity.AccountRecoveryStatus, to entity.AccountRecoveryStatus, decidedBy *entity.UserIDEntity) (bool, error)
+}
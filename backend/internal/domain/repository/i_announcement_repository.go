@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_announcement_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IAnnouncementRepository
+type IAnnouncementRepository interface {
+	// All returns every announcement, newest first
+	All(ctx context.Context) ([]entity.AnnouncementEntity, error)
+
+	// Create stores a new announcement
+	Create(ctx context.Context, announcement entity.AnnouncementEntity) error
+
+	// Update replaces the content of an announcement, returns ErrAnnouncementNotFound if it does not exist
+	Update(ctx context.Context, announcement entity.AnnouncementEntity) error
+
+	// Delete removes an announcement, returns ErrAnnouncementNotFound if it does not exist
+	Delete(ctx context.Context, id string) error
+}
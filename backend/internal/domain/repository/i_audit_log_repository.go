@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_audit_log_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IAuditLogRepository
+type IAuditLogRepository interface {
+	// Create stores an audit record, records are never updated, only deleted once older than AUDIT_LOG_RETENTION_DAYS
+	Create(ctx context.Context, auditLog entity.AuditLogEntity) error
+
+	// List returns the records matching the filter, newest first, and the total count ignoring limit and offset
+	List(ctx context.Context, filter entity.AuditLogFilter) ([]entity.AuditLogEntity, int, error)
+
+	// DeleteCreatedBefore deletes the records created before the time and returns how many were deleted
+	DeleteCreatedBefore(ctx context.Context, before time.Time) (int, error)
+}
@@ -25,6 +25,21 @@ type IAuth2FARepository interface {
 	// GetRecoveryCode retrieves recovery code for a user
 	GetRecoveryCode(ctx context.Context, userID entity.UserIDEntity) (*string, error)
 
-	// ClearRecoveryCode removes recovery code for a user
+	// ClearRecoveryCode removes the recovery code and the single-use recovery codes of a user
 	ClearRecoveryCode(ctx context.Context, userID entity.UserIDEntity) error
+
+	// ReplaceRecoveryCodes stores the hashes of a new set of single-use recovery codes, the previous set is dropped
+	ReplaceRecoveryCodes(ctx context.Context, userID entity.UserIDEntity, codeHashes []string) error
+
+	// UseRecoveryCode marks the unused single-use recovery code with the hash as used, false when there is none
+	UseRecoveryCode(ctx context.Context, userID entity.UserIDEntity, codeHash string) (bool, error)
+
+	// GetRecoveryCodesStatus counts the single-use recovery codes of the current set of a user
+	GetRecoveryCodesStatus(ctx context.Context, userID entity.UserIDEntity) (entity.RecoveryCodesStatusEntity, error)
+
+	// SetPreferredMethod stores the 2FA method offered first at login, nil clears it
+	SetPreferredMethod(ctx context.Context, userID entity.UserIDEntity, method *entity.TwoFAType) error
+
+	// GetPreferredMethod retrieves the preferred 2FA method of a user, nil when none is set
+	GetPreferredMethod(ctx context.Context, userID entity.UserIDEntity) (*entity.TwoFAType, error)
 }
@@ -8,7 +8,13 @@ import (
 //go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_auth_access_token_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IAuthAccessTokenRepository
 type IAuthAccessTokenRepository interface {
 	IssueAccessToken(ctx context.Context, userID entity.UserIDEntity, relativeRefreshTokenHash string) (entity.AccessToken, error)
+	// IssueClientAccessToken issues an access token for a refresh token of an oidc client, it carries the client and its scopes
+	IssueClientAccessToken(ctx context.Context, refreshToken entity.RefreshToken) (entity.AccessToken, error)
 	ValidateAccessToken(ctx context.Context, token string) (entity.AccessToken, bool, error)
 	DeleteAccessToken(ctx context.Context, token entity.AccessToken) error
 	DeleteAllTokensByUserID(ctx context.Context, userID entity.UserIDEntity) error
+	// DeleteOtherTokensByUserID revokes the user's access tokens but those issued for the refresh token with the given hash
+	DeleteOtherTokensByUserID(ctx context.Context, userID entity.UserIDEntity, keepRefreshTokenHash string) error
+	// DeleteTokensByRefreshTokenHash revokes the access tokens issued for the refresh token with the given hash
+	DeleteTokensByRefreshTokenHash(ctx context.Context, refreshTokenHash string) error
 }
@@ -8,9 +8,26 @@ import (
 //go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_auth_refresh_token_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IAuthRefreshTokenRepository
 type IAuthRefreshTokenRepository interface {
 	IssueRefreshToken(ctx context.Context, userID entity.UserIDEntity) (entity.RefreshToken, error)
+	// IssueClientRefreshToken issues a refresh token bound to an oidc client and the scopes the user granted it
+	IssueClientRefreshToken(ctx context.Context, userID entity.UserIDEntity, clientID string, scopes []string) (entity.RefreshToken, error)
 	ValidateRefreshToken(ctx context.Context, token string) (entity.RefreshToken, bool, error)
 	ValidateRefreshTokenHash(ctx context.Context, tokenHash string) (entity.RefreshToken, bool, error)
 	DeleteRefreshToken(ctx context.Context, token string) error
 	DeleteRefreshTokenByHash(ctx context.Context, tokenHash string) error
 	DeleteAllTokensByUserID(ctx context.Context, userID entity.UserIDEntity) error
+	// ListActiveSessionsByUserID returns the user's refresh tokens that are not expired, in no particular order.
+	// The raw tokens are not stored, only the hashes are set.
+	ListActiveSessionsByUserID(ctx context.Context, userID entity.UserIDEntity) ([]entity.RefreshToken, error)
+	// DeleteOtherTokensByUserID removes the user's refresh tokens but the one with the given hash
+	DeleteOtherTokensByUserID(ctx context.Context, userID entity.UserIDEntity, keepTokenHash string) error
+	// RotateRefreshToken replaces a valid refresh token with a new token of the same family and the same expiry.
+	// The replaced token is kept as rotated until it expires so its reuse can be detected.
+	// Returns false when the token is not valid anymore, e.g. it was rotated by a concurrent request.
+	RotateRefreshToken(ctx context.Context, refreshToken entity.RefreshToken) (entity.RefreshToken, bool, error)
+	// FindRotatedRefreshToken returns the stored token when it was already replaced by a rotation and did not expire
+	FindRotatedRefreshToken(ctx context.Context, token string) (entity.RefreshToken, bool, error)
+	// DeleteTokenFamily removes every token of the user's family, rotated ones included, and returns their hashes
+	DeleteTokenFamily(ctx context.Context, userID entity.UserIDEntity, familyID string) ([]string, error)
+	// CleanupExpiredTokenHashesForUser drops the expired tokens from the index of the user's tokens
+	CleanupExpiredTokenHashesForUser(ctx context.Context, userID entity.UserIDEntity) error
 }
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+// IBackupRepository keeps the backup archives of the local stores and restores one of them.
+// A restore only happens on the next start, before the stores are opened, the running stores are never replaced.
+//
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_backup_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IBackupRepository
+type IBackupRepository interface {
+	// Create archives what fill writes into an empty directory, one sub directory per component, as the backup name
+	Create(ctx context.Context, name string, fill func(dir string) ([]string, error)) (entity.BackupEntity, error)
+	// List returns the backups newest first
+	List(ctx context.Context) ([]entity.BackupEntity, error)
+	// Delete returns false when there is no such backup
+	Delete(ctx context.Context, name string) (bool, error)
+	// StageRestore marks the backup to be restored on the next start, false when there is no such backup
+	StageRestore(ctx context.Context, name string) (bool, error)
+	// CancelRestore drops the staged restore, false when none was staged
+	CancelRestore(ctx context.Context) (bool, error)
+	// PendingRestore returns the name of the backup staged for restore
+	PendingRestore(ctx context.Context) (string, bool, error)
+	// ApplyPendingRestore puts the staged backup in place of the local stores and returns its name,
+	// it must run before the stores are opened
+	ApplyPendingRestore(ctx context.Context) (string, bool, error)
+}
@@ -11,4 +11,24 @@ type ICache interface {
 	Get(ctx context.Context, key string) (string, bool, error)
 	Delete(ctx context.Context, key string) error
 	Has(ctx context.Context, key string) (bool, error)
+	// Increment adds one to the counter at key and returns the new count, atomically across concurrent callers.
+	// A missing or expired key starts from 0 and expires after ttl seconds, 0 means never. An existing key keeps
+	// its expiry, so the count covers a fixed window.
+	Increment(ctx context.Context, key string, ttl uint64) (int64, error)
+}
+
+// ICacheWithFallback is an ICache that answers from memory while its backend fails. Backend returns the backend, so
+// probes can see its failures.
+type ICacheWithFallback interface {
+	ICache
+	Backend() ICache
+}
+
+// IHotCache is an ICache for keys many users set at about the same time, such as listings.
+// SetWithTTL moves the ttl by a random CACHE_TTL_JITTER_PERCENT so those keys do not expire together.
+// Keys whose ttl is a security bound (tokens, challenges) must use ICache.
+//
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_hot_cache.go -package mock_gen ya-tool-craft/internal/domain/repository IHotCache
+type IHotCache interface {
+	ICache
 }
@@ -0,0 +1,12 @@
+package repository
+
+import "context"
+
+// ICacheMaintenance runs the upkeep of the entries ICache keeps in memory.
+//
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_cache_maintenance.go -package mock_gen ya-tool-craft/internal/domain/repository ICacheMaintenance
+type ICacheMaintenance interface {
+	// PurgeExpired drops the expired entries and returns how many were dropped, backends expiring their keys on
+	// their own drop none
+	PurgeExpired(ctx context.Context) (int, error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_gallery_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IGalleryRepository
+type IGalleryRepository interface {
+	// PublishTool adds the tool to the gallery, publishing a published tool again keeps its counters
+	PublishTool(ctx context.Context, ownerID entity.UserIDEntity, toolUID string) error
+	// UnpublishTool returns false when the tool of the owner was not published
+	UnpublishTool(ctx context.Context, ownerID entity.UserIDEntity, toolUID string) (bool, error)
+	// ListPublished returns the published tools that are not archived matching the query and the category of the
+	// filter, sorted by the filter. The tag, limit and offset of the filter are not applied
+	ListPublished(ctx context.Context, filter entity.GalleryFilter) ([]entity.GalleryToolEntity, error)
+	// GetPublished returns a published tool that is not archived
+	GetPublished(ctx context.Context, toolUID string) (entity.GalleryToolEntity, bool, error)
+	IncrementInstallCount(ctx context.Context, toolUID string) error
+	IncrementForkCount(ctx context.Context, toolUID string) error
+}
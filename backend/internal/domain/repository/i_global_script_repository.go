@@ -6,4 +6,6 @@ import "ya-tool-craft/internal/domain/entity"
 type IGlobalScriptRepository interface {
 	GetGlobalScript(userID entity.UserIDEntity) (*entity.GlobalScriptEntity, error)
 	UpdateGlobalScript(userID entity.UserIDEntity, script string) error
+	// EncryptGlobalScripts seals the global scripts of every user that are still in plaintext and returns their number.
+	EncryptGlobalScripts() (int, error)
 }
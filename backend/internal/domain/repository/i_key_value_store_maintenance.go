@@ -0,0 +1,14 @@
+package repository
+
+import "context"
+
+// IKeyValueStoreMaintenance runs the upkeep the key-value store behind ICache and the refresh tokens needs.
+//
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_key_value_store_maintenance.go -package mock_gen ya-tool-craft/internal/domain/repository IKeyValueStoreMaintenance
+type IKeyValueStoreMaintenance interface {
+	// Compact reclaims the disk space of deleted and expired entries, stores that do it themselves do nothing
+	Compact(ctx context.Context) error
+	// Backup copies a consistent snapshot of the store into dir, false when the store lives outside ToolBake
+	// and is backed up with its own tooling
+	Backup(ctx context.Context, dir string) (bool, error)
+}
@@ -1,8 +1,13 @@
 package repository
 
-import "context"
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
 
 //go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_migration.go -package mock_gen ya-tool-craft/internal/domain/repository IMigration
 type IMigration interface {
 	RunMigrate(ctx context.Context) error
+	// SchemaVersion returns the applied schema version next to the one this build expects.
+	SchemaVersion(ctx context.Context) (entity.SchemaVersionEntity, error)
 }
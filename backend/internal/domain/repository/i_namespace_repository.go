@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_namespace_repository.go -package mock_gen ya-tool-craft/internal/domain/repository INamespaceRepository
+type INamespaceRepository interface {
+	// ListNamespaces returns the namespaces of the user ordered by name, with the number of tools in each
+	ListNamespaces(ctx context.Context, userID entity.UserIDEntity) ([]entity.NamespaceEntity, error)
+	GetNamespace(ctx context.Context, userID entity.UserIDEntity, name string) (entity.NamespaceEntity, bool, error)
+	// CreateNamespace returns ErrNamespaceAlreadyExists when the user has a namespace with the name
+	CreateNamespace(ctx context.Context, userID entity.UserIDEntity, namespace entity.NamespaceEntity) (entity.NamespaceEntity, error)
+	// UpdateNamespace replaces the description and the defaults, returns ErrNamespaceNotFound when there is no such namespace
+	UpdateNamespace(ctx context.Context, userID entity.UserIDEntity, namespace entity.NamespaceEntity) (entity.NamespaceEntity, error)
+	// DeleteNamespace drops the settings of the namespace, its tools are kept. Returns false when there is no such namespace
+	DeleteNamespace(ctx context.Context, userID entity.UserIDEntity, name string) (bool, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_oidc_client_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IOidcClientRepository
+type IOidcClientRepository interface {
+	Create(ctx context.Context, client entity.OidcClientEntity) error
+	GetByID(ctx context.Context, id string) (entity.OidcClientEntity, bool, error)
+	// List returns the clients newest first
+	List(ctx context.Context) ([]entity.OidcClientEntity, error)
+	// Delete returns false when there is no such client
+	Delete(ctx context.Context, id string) (bool, error)
+}
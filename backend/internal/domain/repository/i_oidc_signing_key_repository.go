@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_oidc_signing_key_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IOidcSigningKeyRepository
+type IOidcSigningKeyRepository interface {
+	Create(ctx context.Context, key entity.OidcSigningKeyEntity) error
+	// List returns the keys newest first
+	List(ctx context.Context) ([]entity.OidcSigningKeyEntity, error)
+}
@@ -7,7 +7,7 @@ import (
 
 //go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_passkey_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IPasskeyRepository
 type IPasskeyRepository interface {
-	// Create creates a new passkey for a user
+	// Create creates a new passkey for a user, ErrPasskeyCredentialExists when the credential ID is taken
 	Create(ctx context.Context, passkey entity.PasskeyEntity) error
 
 	// GetByCredentialID retrieves a passkey by credential ID (used during login)
@@ -22,6 +22,9 @@ type IPasskeyRepository interface {
 	// UpdateLastUsedAt updates the last used timestamp
 	UpdateLastUsedAt(ctx context.Context, id int64) error
 
+	// UpdateName renames a passkey of the user, a nil name clears it, ErrPasskeyNotFound when the user has no such passkey
+	UpdateName(ctx context.Context, id int64, userID entity.UserIDEntity, deviceName *string) error
+
 	// Delete deletes a passkey by ID
 	Delete(ctx context.Context, id int64, userID entity.UserIDEntity) error
 
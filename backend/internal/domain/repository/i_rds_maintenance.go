@@ -0,0 +1,12 @@
+package repository
+
+import "context"
+
+// IRdsMaintenance runs the upkeep the database behind the rds repositories needs.
+//
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_rds_maintenance.go -package mock_gen ya-tool-craft/internal/domain/repository IRdsMaintenance
+type IRdsMaintenance interface {
+	// Backup copies a consistent snapshot of the database into dir, false when the database lives outside ToolBake
+	// and is backed up with its own tooling
+	Backup(ctx context.Context, dir string) (bool, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+)
+
+// ISessionTTLProvider returns the lifetimes in seconds of the tokens and challenges issued now. Admins change them at
+// runtime in the system settings, the env config is the default.
+type ISessionTTLProvider interface {
+	AccessTokenTTL(ctx context.Context) uint64
+	// MaxAccessTokenTTL is the longest an access token can live with any allowed setting, revocations are kept that long
+	MaxAccessTokenTTL() uint64
+	RefreshTokenTTL(ctx context.Context) uint64
+	WebAuthnChallengeTTL(ctx context.Context) int
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_system_setting_repository.go -package mock_gen ya-tool-craft/internal/domain/repository ISystemSettingRepository
+type ISystemSettingRepository interface {
+	// AllSettings returns every setting overridden in the database
+	AllSettings(ctx context.Context) ([]entity.SystemSettingEntity, error)
+
+	// SaveSetting creates or replaces the value of a setting
+	SaveSetting(ctx context.Context, setting entity.SystemSettingEntity) error
+
+	// DeleteSetting removes the override of a setting so its env config default applies again
+	DeleteSetting(ctx context.Context, key string) error
+}
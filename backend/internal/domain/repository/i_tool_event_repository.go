@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+// IToolEventRepository reads the event log of the tools. The events are appended by IToolRepository in the
+// transaction of the change they record, they are never updated.
+//
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_tool_event_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IToolEventRepository
+type IToolEventRepository interface {
+	// ListEvents returns at most limit events of the user with an id below before, newest first. An empty toolUID
+	// returns the events of every tool, a before of 0 starts at the newest event.
+	ListEvents(ctx context.Context, userID entity.UserIDEntity, toolUID string, before int64, limit int) ([]entity.ToolEventEntity, error)
+}
@@ -7,10 +7,66 @@ import (
 
 //go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_tool_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IToolRepository
 type IToolRepository interface {
-	CreateTool(userID entity.UserIDEntity, tool entity.ToolEntity) error
-	UpdateTool(userID entity.UserIDEntity, tool entity.ToolEntity) error
-	DeleteTool(userID entity.UserIDEntity, toolUID string) error
+	// The methods changing tools append a tool event per changed tool in their transaction, eventSource tells who
+	// made the change.
+
+	// CreateTool and UpdateTool return ErrToolNameTaken with UNIQUE_TOOL_NAMES when another tool of the user has the
+	// namespace and name. An update that keeps the namespace and name of the tool is not checked.
+	// CreateTool returns ErrToolIDTaken when the user already has a tool with the id, and ErrToolUIDTaken when any
+	// tool has the unique id.
+	CreateTool(userID entity.UserIDEntity, tool entity.ToolEntity, eventSource entity.ToolEventSourceEntity) error
+	UpdateTool(userID entity.UserIDEntity, tool entity.ToolEntity, eventSource entity.ToolEventSourceEntity) error
+	DeleteTool(userID entity.UserIDEntity, toolUID string, eventSource entity.ToolEventSourceEntity) error
+	// SetToolArchived archives or restores a tool, UpdateTool never changes the archived state.
+	// Returns ErrToolNotFound when the user has no tool with the uid.
+	SetToolArchived(userID entity.UserIDEntity, toolUID string, archived bool, eventSource entity.ToolEventSourceEntity) error
 
 	AllTools(userID entity.UserIDEntity) (entity.ToolsEntity, error)
+	// GetTool returns the tool of the user with the uid, false when the user has no such tool.
+	GetTool(userID entity.UserIDEntity, toolUID string) (entity.ToolEntity, bool, error)
+	// ListTools returns a page of the tools of the user matching the options, and the total count of matching tools.
+	ListTools(userID entity.UserIDEntity, options entity.ToolListOptions) ([]entity.ToolEntity, int, error)
+	// FilterToolsByExtraInfo returns the tools whose extra info contains every given key/value pair.
+	FilterToolsByExtraInfo(userID entity.UserIDEntity, filters map[string]string) ([]entity.ToolEntity, error)
 	ToolsLastUpdatedAt(userID entity.UserIDEntity) (*time.Time, error)
+	// ToolChangesSince returns the tools created or updated and the uids of the tools deleted after the cursor,
+	// a cursor of 0 returns every tool.
+	ToolChangesSince(userID entity.UserIDEntity, cursor int64) (entity.ToolChangesEntity, error)
+	// ToolChangesPage returns at most limit of the changes after the cursor up to and including upTo, in change order.
+	// The cursor of the page is its last change, read the next page from it until a page has no changes.
+	ToolChangesPage(userID entity.UserIDEntity, cursor int64, upTo int64, limit int) (entity.ToolChangesEntity, error)
+	// LatestToolChangeCursor returns the sequence number of the newest tool change of the user, 0 when there is none.
+	LatestToolChangeCursor(userID entity.UserIDEntity) (int64, error)
+	// ToolsUpdatedSince returns the tools created and updated and the uids of the tools deleted at or after since, in
+	// change order. A tool created at or after since counts as created even when it was updated later.
+	ToolsUpdatedSince(userID entity.UserIDEntity, since time.Time) (entity.ToolsUpdatedSinceEntity, error)
+
+	AllCategories(userID entity.UserIDEntity) ([]entity.ToolCategoryEntity, error)
+	// RenameCategory moves all tools of a category to a new, not yet used category name.
+	// Returns ErrToolCategoryNotFound or ErrToolCategoryAlreadyExists.
+	RenameCategory(userID entity.UserIDEntity, from string, to string, eventSource entity.ToolEventSourceEntity) (int64, error)
+	// MergeCategories moves all tools of the source category into an existing target category.
+	// Returns ErrToolCategoryNotFound when either category has no tools.
+	MergeCategories(userID entity.UserIDEntity, source string, target string, eventSource entity.ToolEventSourceEntity) (int64, error)
+
+	// The tags of a tool are kept in its tags extra info and indexed in their own table, tags are lower case.
+	// AddTag and RemoveTag change the tags extra info of the tool, adding a tag it has or removing one it does not
+	// have changes nothing. Both return ErrToolNotFound when the user has no tool with the uid.
+	AddTag(userID entity.UserIDEntity, toolUID string, tag string, eventSource entity.ToolEventSourceEntity) error
+	RemoveTag(userID entity.UserIDEntity, toolUID string, tag string, eventSource entity.ToolEventSourceEntity) error
+	// SearchByTag returns the tools of the user with the tag, oldest first.
+	SearchByTag(userID entity.UserIDEntity, tag string) ([]entity.ToolEntity, error)
+	// AllTags returns the tags used by the tools of the user with their tool counts, ordered by tag.
+	AllTags(userID entity.UserIDEntity) ([]entity.ToolTagEntity, error)
+	// SearchFullText returns at most limit tools of the user whose name, description or source contain a word
+	// starting with each of the terms, the most relevant first. Names weigh more than descriptions, and descriptions
+	// more than sources.
+	SearchFullText(userID entity.UserIDEntity, terms []string, limit int) ([]entity.ToolEntity, error)
+
+	// EncryptStoredSources moves the tools and tool versions of every user with an encrypt key onto sources sealed
+	// for their owner alone, and drops the sources from the full text search. It returns the number of tools and
+	// tool versions moved, the sources of a user without an encrypt key stay in plaintext.
+	EncryptStoredSources() (int, error)
+	// DropSearchSources empties the sources the full text search kept from before the sources were encrypted.
+	DropSearchSources() error
 }
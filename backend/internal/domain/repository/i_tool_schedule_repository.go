@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_tool_schedule_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IToolScheduleRepository
+type IToolScheduleRepository interface {
+	// ListSchedules returns the schedules of the user oldest first, a non empty toolUID only lists the schedules of that tool
+	ListSchedules(ctx context.Context, userID entity.UserIDEntity, toolUID string) ([]entity.ToolScheduleEntity, error)
+	GetSchedule(ctx context.Context, userID entity.UserIDEntity, id string) (entity.ToolScheduleEntity, bool, error)
+	// CountSchedules returns how many schedules the user has
+	CountSchedules(ctx context.Context, userID entity.UserIDEntity) (int, error)
+	CreateSchedule(ctx context.Context, schedule entity.ToolScheduleEntity) error
+	// UpdateSchedule replaces the name, cron, inputs, enabled flag and next run of a schedule,
+	// returns ErrToolScheduleNotFound if the user has no such schedule
+	UpdateSchedule(ctx context.Context, schedule entity.ToolScheduleEntity) error
+	// DeleteSchedule removes a schedule with its runs, returns ErrToolScheduleNotFound if the user has no such schedule
+	DeleteSchedule(ctx context.Context, userID entity.UserIDEntity, id string) error
+
+	// DueSchedules returns up to limit enabled schedules whose next run is at or before now, the longest overdue first
+	DueSchedules(ctx context.Context, now time.Time, limit int) ([]entity.ToolScheduleEntity, error)
+	// ClaimSchedule moves a due schedule to its next run and records now as its last run. It returns false when the
+	// schedule is no longer due at now, another server instance claimed it or it was changed in the meantime.
+	ClaimSchedule(ctx context.Context, id string, now time.Time, next time.Time) (bool, error)
+
+	// AddRun records a run and drops the oldest runs of its schedule beyond keep
+	AddRun(ctx context.Context, run entity.ToolRunEntity, keep int) error
+	// ListRuns returns the runs of a schedule of the user, newest first
+	ListRuns(ctx context.Context, userID entity.UserIDEntity, scheduleID string, limit int) ([]entity.ToolRunEntity, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_tool_secret_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IToolSecretRepository
+type IToolSecretRepository interface {
+	// ListSecrets returns the secrets of a tool ordered by name, an empty toolUID lists the account level secrets
+	ListSecrets(ctx context.Context, userID entity.UserIDEntity, toolUID string) ([]entity.ToolSecretEntity, error)
+	// PutSecret creates a secret or rotates the value of an existing one, the stored secret is returned with its new version
+	PutSecret(ctx context.Context, userID entity.UserIDEntity, toolUID string, name string, encryptedValue string) (entity.ToolSecretEntity, error)
+	// DeleteSecret returns false when there is no such secret
+	DeleteSecret(ctx context.Context, userID entity.UserIDEntity, toolUID string, name string) (bool, error)
+}
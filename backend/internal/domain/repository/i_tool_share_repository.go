@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_tool_share_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IToolShareRepository
+type IToolShareRepository interface {
+	// ListSharesOfTool returns the shares of a tool of the owner, oldest first
+	ListSharesOfTool(ctx context.Context, ownerID entity.UserIDEntity, toolUID string) ([]entity.ToolShareEntity, error)
+	// ListSharesWithUser returns the shares granted to the user, newest first, public links are not included
+	ListSharesWithUser(ctx context.Context, granteeID entity.UserIDEntity) ([]entity.ToolShareEntity, error)
+	GetShare(ctx context.Context, shareID string) (entity.ToolShareEntity, bool, error)
+	// PutShare creates the share of the tool for the grantee or changes the permission of the existing one,
+	// the stored share is returned and keeps its id
+	PutShare(ctx context.Context, share entity.ToolShareEntity) (entity.ToolShareEntity, error)
+	// DeleteShare returns false when the owner has no such share
+	DeleteShare(ctx context.Context, ownerID entity.UserIDEntity, shareID string) (bool, error)
+}
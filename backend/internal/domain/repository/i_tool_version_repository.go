@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+// IToolVersionRepository reads the version history of the tools. The versions are written by IToolRepository
+// in the transaction of the create or update they record, and deleted with their tool.
+//
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_tool_version_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IToolVersionRepository
+type IToolVersionRepository interface {
+	// ListVersions returns the versions of a tool newest first, without their source and ui widgets
+	ListVersions(ctx context.Context, userID entity.UserIDEntity, toolUID string) ([]entity.ToolVersionEntity, error)
+	// GetVersion returns a version with its source and ui widgets, false when the tool has no such version
+	GetVersion(ctx context.Context, userID entity.UserIDEntity, toolUID string, version int) (entity.ToolVersionEntity, bool, error)
+}
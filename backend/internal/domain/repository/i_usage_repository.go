@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_usage_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IUsageRepository
+type IUsageRepository interface {
+	// AddUsage adds an amount to the usage of a metric in a period and returns the new usage
+	AddUsage(ctx context.Context, userID entity.UserIDEntity, metric entity.UsageMetric, period string, amount int64) (int64, error)
+	// SetUsage replaces the usage of a metric in a period, for metrics measured rather than counted
+	SetUsage(ctx context.Context, userID entity.UserIDEntity, metric entity.UsageMetric, period string, amount int64) error
+	// UserUsage returns the usage of every metric a user used in a period
+	UserUsage(ctx context.Context, userID entity.UserIDEntity, period string) ([]entity.UsageEntity, error)
+	// PeriodUsage returns the usage of every user in a period, ordered by user
+	PeriodUsage(ctx context.Context, period string) ([]entity.UsageEntity, error)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_user_data_export_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IUserDataExportRepository
+type IUserDataExportRepository interface {
+	CreateExport(ctx context.Context, export entity.UserDataExportEntity) error
+	// GetExport returns an export of the user without its archive
+	GetExport(ctx context.Context, userID entity.UserIDEntity, id string) (entity.UserDataExportEntity, bool, error)
+	// ActiveExport returns the pending or running export of the user, false when there is none
+	ActiveExport(ctx context.Context, userID entity.UserIDEntity) (entity.UserDataExportEntity, bool, error)
+
+	// PendingExports returns up to limit exports waiting to be built, oldest first. Running exports not updated since
+	// staleBefore count as waiting, the instance building them stopped.
+	PendingExports(ctx context.Context, staleBefore time.Time, limit int) ([]entity.UserDataExportEntity, error)
+	// ClaimExport marks a waiting export running at now. It returns false when the export is no longer waiting,
+	// another server instance claimed it in the meantime.
+	ClaimExport(ctx context.Context, id string, staleBefore time.Time, now time.Time) (bool, error)
+	// UpdateProgress records the finished steps of a running export
+	UpdateProgress(ctx context.Context, id string, progress int, now time.Time) error
+	// FinishExport stores the archive of an export and marks it done, FailExport marks it failed with the reason
+	FinishExport(ctx context.Context, id string, archive entity.UserDataArchiveEntity, now time.Time) error
+	FailExport(ctx context.Context, id string, reason string, now time.Time) error
+	// GetArchive returns the archive of a done export of the user, false when there is no such export or it is not done
+	GetArchive(ctx context.Context, userID entity.UserIDEntity, id string) (entity.UserDataArchiveEntity, bool, error)
+	// DeleteFinishedBefore deletes the exports done or failed before the time and returns how many were deleted
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_user_device_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IUserDeviceRepository
+type IUserDeviceRepository interface {
+	// Create registers a new device of the user with a sync cursor of 0
+	Create(ctx context.Context, userID entity.UserIDEntity, name string) (entity.UserDeviceEntity, error)
+	// Get returns a device of the user, false when the user has no device with the id
+	Get(ctx context.Context, userID entity.UserIDEntity, deviceID string) (entity.UserDeviceEntity, bool, error)
+	// AllDevices returns the devices of the user, last seen first
+	AllDevices(ctx context.Context, userID entity.UserIDEntity) ([]entity.UserDeviceEntity, error)
+	// Touch sets the last seen time of a device to now
+	Touch(ctx context.Context, userID entity.UserIDEntity, deviceID string) error
+	// UpdateSyncCursor stores the cursor a device acknowledged and sets its last seen time to now
+	UpdateSyncCursor(ctx context.Context, userID entity.UserIDEntity, deviceID string, cursor int64) error
+	// Delete removes a device of the user, deleting a missing device is not an error
+	Delete(ctx context.Context, userID entity.UserIDEntity, deviceID string) error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 	"ya-tool-craft/internal/domain/entity"
 )
 
@@ -13,6 +14,12 @@ type IUserRepository interface {
 	// GetByID retrieves a user by ID
 	GetByID(ctx context.Context, id entity.UserIDEntity) (entity.UserEntity, bool, error)
 
+	// AllUserIDs lists the ids of every user, used by housekeeping jobs walking all users
+	AllUserIDs(ctx context.Context) ([]entity.UserIDEntity, error)
+
+	// HasUserWithRole reports whether at least one user has the role
+	HasUserWithRole(ctx context.Context, role entity.UserRoleEntity) (bool, error)
+
 	// GetByEmail retrieves a user by email
 	GetByEmail(ctx context.Context, email string) (entity.UserEntity, bool, error)
 
@@ -25,9 +32,30 @@ type IUserRepository interface {
 	// Delete deletes a user
 	Delete(ctx context.Context, id entity.UserIDEntity) error
 
-	// UpdatePassword updates user's password
+	// UpdatePassword updates user's password and clears a required password change
 	UpdatePassword(ctx context.Context, id entity.UserIDEntity, newPassword string) error
 
+	// SetPasswordChangeRequired sets or clears the flag that makes the user change the password
+	SetPasswordChangeRequired(ctx context.Context, id entity.UserIDEntity, required bool) error
+
+	// SetDisabled disables or enables the account of the user
+	SetDisabled(ctx context.Context, id entity.UserIDEntity, disabled bool) error
+
+	// SetDeletionScheduledAt schedules the purge of the account of the user, nil cancels it
+	SetDeletionScheduledAt(ctx context.Context, id entity.UserIDEntity, at *time.Time) error
+
+	// UsersDueForDeletion returns the users whose account deletion is scheduled at or before the time
+	UsersDueForDeletion(ctx context.Context, before time.Time) ([]entity.UserIDEntity, error)
+
+	// SetRoles replaces the roles of the user
+	SetRoles(ctx context.Context, id entity.UserIDEntity, roles []entity.UserRoleEntity) error
+
+	// ListUsers returns a page of users in creation order with their tool counts, and the total count of matching users
+	ListUsers(ctx context.Context, filter entity.UserFilter) ([]entity.UserSummaryEntity, int, error)
+
+	// RecordLogin sets last login time to now and increases login count, called after every successful login
+	RecordLogin(ctx context.Context, id entity.UserIDEntity) error
+
 	// ValidateCredentialsByUsername validates username and password combination
 	// Returns user entity and true if credentials are valid, otherwise returns false
 	ValidateCredentialsByUsername(ctx context.Context, username string, password string) (entity.UserEntity, bool, error)
@@ -48,9 +76,24 @@ type IUserRepository interface {
 	// AddUserSSOBinding adds a new user sso binding
 	AddUserSSOBinding(ctx context.Context, userID entity.UserIDEntity, provider string, providerUserID string, providerUsername *string, providerEmail *string) error
 
+	// SetUserSSOAccessToken keeps the provider api token on the user sso binding, it is dropped with the binding.
+	// Returns ErrUserSSOBindingNotFound when the user has no binding of the provider
+	SetUserSSOAccessToken(ctx context.Context, userID entity.UserIDEntity, provider string, accessToken string) error
+
+	// GetUserSSOAccessToken returns the provider api token of the user sso binding, false when there is no binding or no token
+	GetUserSSOAccessToken(ctx context.Context, userID entity.UserIDEntity, provider string) (string, bool, error)
+
 	// DeleteUserSSOBinding deletes a user sso binding by provider
 	DeleteUserSSOBinding(ctx context.Context, userID entity.UserIDEntity, provider string) error
 
 	// DeleteUserWithAllData deletes a user and all related data (sso bindings, tools, global scripts, etc.)
 	DeleteUserWithAllData(ctx context.Context, id entity.UserIDEntity) error
+	// DeleteUserIfDeletionDue deletes the user like DeleteUserWithAllData when the account deletion is scheduled at or
+	// before the time, checked in the same transaction. Returns false when the user was kept.
+	DeleteUserIfDeletionDue(ctx context.Context, id entity.UserIDEntity, before time.Time) (bool, error)
+
+	// MergeUsers moves tools, global script, passkeys and sso bindings of the duplicate to the survivor and
+	// deletes the duplicate, all in one transaction. Tool ids the survivor already uses are renamed, the
+	// survivor's global script and 2fa win over the duplicate's.
+	MergeUsers(ctx context.Context, survivorID entity.UserIDEntity, duplicateID entity.UserIDEntity) (entity.UserMergeResultEntity, error)
 }
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+//go:generate mockgen -destination=../../infra/repository_impl/mock_gen/mock_i_user_setting_repository.go -package mock_gen ya-tool-craft/internal/domain/repository IUserSettingRepository
+type IUserSettingRepository interface {
+	// AllSettings returns every preference the user has set
+	AllSettings(ctx context.Context, userID entity.UserIDEntity) ([]entity.UserSettingEntity, error)
+
+	// SaveSettings creates or replaces the given preferences of the user in a single transaction
+	SaveSettings(ctx context.Context, userID entity.UserIDEntity, settings []entity.UserSettingEntity) error
+
+	// DeleteSettings removes the given preferences of the user so their defaults apply again
+	DeleteSettings(ctx context.Context, userID entity.UserIDEntity, keys []string) error
+}
@@ -3,20 +3,27 @@ package service
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"image/png"
+	"math/big"
+	"slices"
 	"strings"
 	"time"
 	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
 	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/utils"
 
 	"github.com/brianvoe/gofakeit/v7"
+	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"github.com/pquerna/otp"
 	"github.com/pquerna/otp/totp"
 )
 
@@ -26,15 +33,19 @@ func NewTwoFaService(
 	accessTokenRepo repository.IAuthAccessTokenRepository,
 	refreshTokenRepo repository.IAuthRefreshTokenRepository,
 	cacheRepo repository.ICache,
+	passkeyService *AuthPasskeyService,
+	sessionRevocationService *SessionRevocationService,
 	config config.Config,
 ) (*TwoFAService, error) {
 	return &TwoFAService{
-		twoFARepo:        twoFARepo,
-		userRepo:         userRepo,
-		accessTokenRepo:  accessTokenRepo,
-		refreshTokenRepo: refreshTokenRepo,
-		cacheRepo:        cacheRepo,
-		config:           config,
+		twoFARepo:                twoFARepo,
+		userRepo:                 userRepo,
+		accessTokenRepo:          accessTokenRepo,
+		refreshTokenRepo:         refreshTokenRepo,
+		cacheRepo:                cacheRepo,
+		passkeyService:           passkeyService,
+		sessionRevocationService: sessionRevocationService,
+		config:                   config,
 	}, nil
 }
 
@@ -45,7 +56,11 @@ type TwoFAService struct {
 	accessTokenRepo  repository.IAuthAccessTokenRepository
 	refreshTokenRepo repository.IAuthRefreshTokenRepository
 	cacheRepo        repository.ICache
-	config           config.Config
+	// passkeyService verifies the passkey assertions of the WebAuthn method
+	passkeyService *AuthPasskeyService
+	// sessionRevocationService signs the other sessions out once a 2FA method is enabled
+	sessionRevocationService *SessionRevocationService
+	config                   config.Config
 }
 
 const (
@@ -53,14 +68,26 @@ const (
 	totpCacheTTL             = 300 // 5 minutes
 	totpVerifyCacheKeyPrefix = "totp_verify:"
 	totpVerifyCacheTTL       = 300 // 5 minutes
+	totpQRCodeCacheKeyPrefix = "totp_qr:"
+	totpQRCodeSize           = 200
 	recoveryCodeWordCount    = 50
+	// a single-use recovery code is recoveryCodeGroups groups of recoveryCodeGroupLength characters, such as
+	// k7pm-x3qa-9tnd. The alphabet leaves out characters that are easily confused when typed from paper
+	recoveryCodeAlphabet    = "abcdefghjkmnpqrstuvwxyz23456789"
+	recoveryCodeGroups      = 3
+	recoveryCodeGroupLength = 4
+
+	// the 2FA verifications of a user are counted under totp_verify_attempts:<user id>, for every 2FA token of the
+	// user, so signing in with the password again does not start a new count
+	totpVerifyAttemptsCacheKeyPrefix = "totp_verify_attempts:"
+	// totpVerifyAttemptsWindow is how long the count lasts from the first attempt, a locked user waits it out
+	totpVerifyAttemptsWindow = totpVerifyCacheTTL
 )
 
 type TOTPSetupInfo struct {
-	Token  string // random token for verification
+	Token  string // random token for verification, also used to fetch the QR code lazily
 	Secret string
 	URL    string
-	QRCode string // base64 encoded PNG image
 }
 
 // totpCacheData is the structure stored in cache
@@ -68,6 +95,7 @@ type totpCacheData struct {
 	Token  string `json:"token"`
 	Secret string `json:"secret"`
 	UserID string `json:"user_id"`
+	URL    string `json:"url"`
 }
 
 // totpVerifyCacheData is the structure stored in cache for 2FA verification during sensitive operations
@@ -101,24 +129,13 @@ func (s *TwoFAService) GenerateNewTOTPForUser(ctx context.Context, userID entity
 	// Generate random token
 	token := fmt.Sprintf("2fa-totp-%s", uuid.New().String())
 
-	// Generate QR code image
-	img, err := key.Image(200, 200)
-	if err != nil {
-		return nil, errors.Wrap(err, "fail to generate qr code image")
-	}
-
-	// Encode image to base64
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, img); err != nil {
-		return nil, errors.Wrap(err, "fail to encode qr code to png")
-	}
-	qrCode := base64.StdEncoding.EncodeToString(buf.Bytes())
-
-	// Cache the data as JSON with TTL for later verification
+	// Cache the data as JSON with TTL for later verification.
+	// The QR code is not rendered here to keep the setup API fast, see GetTOTPQRCode.
 	cacheData := totpCacheData{
 		Token:  token,
 		Secret: secret,
 		UserID: string(userID),
+		URL:    key.URL(),
 	}
 	cacheJSON, err := json.Marshal(cacheData)
 	if err != nil {
@@ -135,10 +152,52 @@ func (s *TwoFAService) GenerateNewTOTPForUser(ctx context.Context, userID entity
 		Token:  token,
 		Secret: secret,
 		URL:    key.URL(),
-		QRCode: qrCode,
 	}, nil
 }
 
+// GetTOTPQRCode returns the base64 encoded PNG QR code of a pending TOTP setup.
+// The image is rendered on first request and cached for the rest of the setup session.
+func (s *TwoFAService) GetTOTPQRCode(ctx context.Context, userID entity.UserIDEntity, token string) (string, error) {
+	cacheData, exists, err := s.GetPendingTOTPByToken(ctx, token)
+	if err != nil {
+		return "", errors.Wrap(err, "fail to get pending totp data")
+	}
+	if !exists {
+		return "", error_code.NewErrorWithErrorCodef(error_code.TwoFaTokenInvalid, "TOTP setup session expired or invalid token, please regenerate a new TOTP")
+	}
+	if cacheData.UserID != string(userID) {
+		return "", error_code.NewErrorWithErrorCodef(error_code.TwoFaTokenInvalid, "token does not belong to the current user")
+	}
+
+	qrCacheKey := fmt.Sprintf("%s%s", totpQRCodeCacheKeyPrefix, token)
+	qrCode, exists, err := s.cacheRepo.Get(ctx, qrCacheKey)
+	if err != nil {
+		return "", errors.Wrap(err, "fail to get totp qr code cache")
+	}
+	if exists {
+		return qrCode, nil
+	}
+
+	key, err := otp.NewKeyFromURL(cacheData.URL)
+	if err != nil {
+		return "", errors.Wrap(err, "fail to parse totp key url")
+	}
+	img, err := key.Image(totpQRCodeSize, totpQRCodeSize)
+	if err != nil {
+		return "", errors.Wrap(err, "fail to generate qr code image")
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", errors.Wrap(err, "fail to encode qr code to png")
+	}
+	qrCode = base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	if err := s.cacheRepo.SetWithTTL(ctx, qrCacheKey, qrCode, totpCacheTTL); err != nil {
+		return "", errors.Wrap(err, "fail to cache totp qr code")
+	}
+	return qrCode, nil
+}
+
 // GetPendingTOTPByToken retrieves the pending TOTP data from cache by token
 func (s *TwoFAService) GetPendingTOTPByToken(ctx context.Context, token string) (*totpCacheData, bool, error) {
 	cacheKey := fmt.Sprintf("%s%s", totpCacheKeyPrefix, token)
@@ -158,10 +217,13 @@ func (s *TwoFAService) GetPendingTOTPByToken(ctx context.Context, token string)
 	return &cacheData, true, nil
 }
 
-// ClearPendingTOTP removes the pending TOTP data from cache by token
+// ClearPendingTOTP removes the pending TOTP data and its rendered QR code from cache by token
 func (s *TwoFAService) ClearPendingTOTP(ctx context.Context, token string) error {
 	cacheKey := fmt.Sprintf("%s%s", totpCacheKeyPrefix, token)
-	return s.cacheRepo.Delete(ctx, cacheKey)
+	if err := s.cacheRepo.Delete(ctx, cacheKey); err != nil {
+		return err
+	}
+	return s.cacheRepo.Delete(ctx, fmt.Sprintf("%s%s", totpQRCodeCacheKeyPrefix, token))
 }
 
 // TwoFAInfo represents the 2FA status for a user
@@ -202,7 +264,9 @@ func (s *TwoFAService) generateRecoveryCode() string {
 // VerifyAndEnableTOTP verifies the TOTP code and enables 2FA for the user
 // It requires the token from GenerateNewTOTPForUser and the TOTP code from the authenticator app
 // Returns the recovery code that can be used to disable 2FA
-func (s *TwoFAService) VerifyAndEnableTOTP(ctx context.Context, userID entity.UserIDEntity, token string, code string) (recoveryCode string, err error) {
+// With REVOKE_SESSIONS_ON_2FA_ENABLE every other session is signed out, the one holding the refresh token with
+// currentRefreshTokenHash stays signed in
+func (s *TwoFAService) VerifyAndEnableTOTP(ctx context.Context, userID entity.UserIDEntity, token string, code string, currentRefreshTokenHash string) (recoveryCode string, err error) {
 	// Check if user already has TOTP enabled
 	_, exists, err := s.twoFARepo.GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP)
 	if err != nil {
@@ -232,6 +296,12 @@ func (s *TwoFAService) VerifyAndEnableTOTP(ctx context.Context, userID entity.Us
 		return "", error_code.NewErrorWithErrorCodef(error_code.InvalidTotpCode, "please try again")
 	}
 
+	// Sign the other sessions out before storing, a failed revocation leaves 2FA disabled
+	revoked, err := s.sessionRevocationService.RevokeOtherSessions(ctx, userID, currentRefreshTokenHash, SessionRevocationReason2FAEnabled)
+	if err != nil {
+		return "", err
+	}
+
 	// Create 2FA record in database
 	twoFAEntity := entity.NewTwoFAEntity(userID, entity.TwoFATypeTOTP, cacheData.Secret)
 	twoFAEntity.Verified = true
@@ -251,18 +321,122 @@ func (s *TwoFAService) VerifyAndEnableTOTP(ctx context.Context, userID entity.Us
 		// The cache will expire anyway
 	}
 
+	if revoked {
+		s.sessionRevocationService.NotifyRevokedSessions(ctx, userID, SessionRevocationReason2FAEnabled)
+	}
 	return recoveryCode, nil
 }
 
-// Get2FAToken checks if user has 2FA enabled and returns a token for verification
+// TwoFAMethods lists the verified 2FA methods of a user and the preferred one.
+// The WebAuthn method only counts while the user still has a passkey.
+func (s *TwoFAService) TwoFAMethods(ctx context.Context, userID entity.UserIDEntity) (entity.TwoFAMethodsEntity, error) {
+	twoFAs, err := s.twoFARepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return entity.TwoFAMethodsEntity{}, errors.Wrap(err, "fail to get 2fa records")
+	}
+
+	methods := []entity.TwoFAType{}
+	for _, twoFA := range twoFAs {
+		if !twoFA.Verified {
+			continue
+		}
+		if twoFA.Type == entity.TwoFATypeWebAuthn {
+			passkeys, err := s.passkeyService.GetPasskeys(ctx, userID)
+			if err != nil {
+				return entity.TwoFAMethodsEntity{}, errors.Wrap(err, "fail to get passkeys")
+			}
+			if len(passkeys) == 0 {
+				continue
+			}
+		}
+		methods = append(methods, twoFA.Type)
+	}
+	if len(methods) == 0 {
+		return entity.TwoFAMethodsEntity{Methods: methods}, nil
+	}
+
+	preferred, err := s.twoFARepo.GetPreferredMethod(ctx, userID)
+	if err != nil {
+		return entity.TwoFAMethodsEntity{}, errors.Wrap(err, "fail to get preferred 2fa method")
+	}
+	result := entity.TwoFAMethodsEntity{Methods: methods, Preferred: methods[0]}
+	if preferred != nil && slices.Contains(methods, *preferred) {
+		result.Preferred = *preferred
+	}
+	return result, nil
+}
+
+// SetPreferred2FAMethod picks the 2FA method offered first at login, it has to be enabled.
+func (s *TwoFAService) SetPreferred2FAMethod(ctx context.Context, userID entity.UserIDEntity, method entity.TwoFAType) error {
+	methods, err := s.TwoFAMethods(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(methods.Methods, method) {
+		return error_code.NewErrorWithErrorCodef(error_code.TwoFaMethodNotEnabled, "2FA method %s is not enabled", method)
+	}
+	if err := s.twoFARepo.SetPreferredMethod(ctx, userID, &method); err != nil {
+		return errors.Wrap(err, "fail to set preferred 2fa method")
+	}
+	return nil
+}
+
+// EnableWebAuthn2FA asks for a passkey assertion as second factor of every login, the user needs a registered passkey.
+// Returns the new recovery code when the user had none yet, nil otherwise. Other sessions are signed out like on
+// VerifyAndEnableTOTP.
+func (s *TwoFAService) EnableWebAuthn2FA(ctx context.Context, userID entity.UserIDEntity, currentRefreshTokenHash string) (*string, error) {
+	_, exists, err := s.twoFARepo.GetByUserIDAndType(ctx, userID, entity.TwoFATypeWebAuthn)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to check existing webauthn 2fa")
+	}
+	if exists {
+		return nil, error_code.NewErrorWithErrorCodef(error_code.TwoFaAlreadyEnabled, "WebAuthn 2FA is already enabled")
+	}
+
+	passkeys, err := s.passkeyService.GetPasskeys(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get passkeys")
+	}
+	if len(passkeys) == 0 {
+		return nil, error_code.NewErrorWithErrorCodef(error_code.TwoFaMethodNotEnabled, "register a passkey before using it as second factor")
+	}
+
+	revoked, err := s.sessionRevocationService.RevokeOtherSessions(ctx, userID, currentRefreshTokenHash, SessionRevocationReason2FAEnabled)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFAEntity := entity.NewTwoFAEntity(userID, entity.TwoFATypeWebAuthn, "")
+	twoFAEntity.Verified = true
+	if err := s.twoFARepo.Create(ctx, twoFAEntity); err != nil {
+		return nil, errors.Wrap(err, "fail to save webauthn 2fa")
+	}
+	if revoked {
+		s.sessionRevocationService.NotifyRevokedSessions(ctx, userID, SessionRevocationReason2FAEnabled)
+	}
+
+	existingRecoveryCode, err := s.twoFARepo.GetRecoveryCode(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get recovery code")
+	}
+	if existingRecoveryCode != nil {
+		return nil, nil
+	}
+	recoveryCode := s.generateRecoveryCode()
+	if err := s.twoFARepo.SetRecoveryCode(ctx, userID, recoveryCode); err != nil {
+		return nil, errors.Wrap(err, "fail to save recovery code")
+	}
+	return &recoveryCode, nil
+}
+
+// Get2FAToken checks if user has any 2FA method enabled and returns a token for verification
 // Returns nil if 2FA is not enabled for the user
 func (s *TwoFAService) Get2FAToken(ctx context.Context, userID entity.UserIDEntity) (*string, error) {
-	// Check if user has TOTP enabled
-	twoFA, exists, err := s.twoFARepo.GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP)
+	methods, err := s.TwoFAMethods(ctx, userID)
 	if err != nil {
 		return nil, errors.Wrap(err, "fail to check 2fa status")
 	}
-	if !exists || !twoFA.Verified {
+	if len(methods.Methods) == 0 {
 		return nil, nil
 	}
 
@@ -288,42 +462,158 @@ func (s *TwoFAService) Get2FAToken(ctx context.Context, userID entity.UserIDEnti
 	return &token, nil
 }
 
-// Verify2FAToken verifies the TOTP code for sensitive operations
-// Returns userID if verification passed
-func (s *TwoFAService) Verify2FAToken(ctx context.Context, token string, code string) (entity.UserIDEntity, error) {
+// pendingLoginUserID returns the user a 2FA token was issued for and the cache key of the token.
+func (s *TwoFAService) pendingLoginUserID(ctx context.Context, token string) (entity.UserIDEntity, string, error) {
 	cacheKey := fmt.Sprintf("%s%s", totpVerifyCacheKeyPrefix, token)
 	cacheJSON, exists, err := s.cacheRepo.Get(ctx, cacheKey)
 	if err != nil {
-		return "", errors.Wrap(err, "fail to get totp verify cache data")
+		return "", "", errors.Wrap(err, "fail to get totp verify cache data")
 	}
 	if !exists {
-		return "", error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "2FA verification session expired or invalid token")
+		return "", "", error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "2FA verification session expired or invalid token")
 	}
 
 	var cacheData totpVerifyCacheData
 	if err := json.Unmarshal([]byte(cacheJSON), &cacheData); err != nil {
-		return "", errors.Wrap(err, "fail to unmarshal totp verify cache data")
+		return "", "", errors.Wrap(err, "fail to unmarshal totp verify cache data")
 	}
 
-	userID := entity.UserIDEntity(cacheData.UserID)
+	return entity.UserIDEntity(cacheData.UserID), cacheKey, nil
+}
 
-	// Get secret from database
-	twoFA, exists, err := s.twoFARepo.GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP)
+// reserveVerifyAttempt counts a 2FA verification of the user before its code is checked, and fails once the user
+// used up TWO_FA_MAX_VERIFY_ATTEMPTS. The count is incremented atomically, so concurrent guesses can not get past the
+// limit together. A successful verification clears the count.
+func (s *TwoFAService) reserveVerifyAttempt(ctx context.Context, userID entity.UserIDEntity) (int, error) {
+	attempts, err := s.cacheRepo.Increment(ctx, verifyAttemptsCacheKey(userID), totpVerifyAttemptsWindow)
 	if err != nil {
-		return "", errors.Wrap(err, "fail to get 2fa record")
+		return 0, errors.Wrap(err, "fail to count 2fa verify attempt")
 	}
-	if !exists {
-		return "", error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "2FA is not enabled")
+	if attempts > int64(s.config.TwoFAMaxVerifyAttempts) {
+		return 0, s.tooManyVerifyAttempts()
+	}
+	return int(attempts), nil
+}
+
+// failVerifyAttempt returns cause for a wrong code, or TooManyAttempts when the attempt was the last one the user had
+func (s *TwoFAService) failVerifyAttempt(ctx context.Context, userID entity.UserIDEntity, attempts int, cause error) error {
+	if attempts >= s.config.TwoFAMaxVerifyAttempts {
+		logger.Warnf(ctx, "2FA verification of user %s locked after %d failed attempts", userID, attempts)
+		return s.tooManyVerifyAttempts()
 	}
+	return cause
+}
 
-	// Verify the TOTP code
-	valid := totp.Validate(code, twoFA.Secret)
-	if !valid {
-		return "", error_code.NewErrorWithErrorCodef(error_code.InvalidTotpCode, "please try again")
+// tooManyVerifyAttempts refuses the 2FA verifications of a locked user, the lock ends with the attempts window
+func (s *TwoFAService) tooManyVerifyAttempts() error {
+	return error_code.NewErrorWithErrorCodeFAppendExtraData(
+		error_code.TooManyAttempts,
+		entity.RateLimitEntity{
+			Limit:   s.config.TwoFAMaxVerifyAttempts,
+			ResetAt: time.Now().Add(totpVerifyAttemptsWindow * time.Second),
+		},
+		"too many failed 2FA attempts, please try again later",
+	)
+}
+
+func verifyAttemptsCacheKey(userID entity.UserIDEntity) string {
+	return totpVerifyAttemptsCacheKeyPrefix + string(userID)
+}
+
+// Pending2FAMethods lists the 2FA methods that can complete the login of a 2FA token
+func (s *TwoFAService) Pending2FAMethods(ctx context.Context, token string) (entity.TwoFAMethodsEntity, error) {
+	userID, _, err := s.pendingLoginUserID(ctx, token)
+	if err != nil {
+		return entity.TwoFAMethodsEntity{}, err
+	}
+	return s.TwoFAMethods(ctx, userID)
+}
+
+// WebAuthn2FAChallenge starts the passkey assertion completing the login of a 2FA token
+func (s *TwoFAService) WebAuthn2FAChallenge(ctx context.Context, token string) (*protocol.CredentialAssertion, error) {
+	userID, _, err := s.pendingLoginUserID(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireVerifiedMethod(ctx, userID, entity.TwoFATypeWebAuthn); err != nil {
+		return nil, err
+	}
+	return s.passkeyService.SecondFactorChallenge(ctx, userID, token)
+}
+
+func (s *TwoFAService) requireVerifiedMethod(ctx context.Context, userID entity.UserIDEntity, method entity.TwoFAType) error {
+	twoFA, exists, err := s.twoFARepo.GetByUserIDAndType(ctx, userID, method)
+	if err != nil {
+		return errors.Wrap(err, "fail to get 2fa record")
+	}
+	if !exists || !twoFA.Verified {
+		return error_code.NewErrorWithErrorCodef(error_code.TwoFaMethodNotEnabled, "2FA method %s is not enabled", method)
+	}
+	return nil
+}
+
+// Verify2FAToken verifies the TOTP code for sensitive operations
+// Returns userID if verification passed
+func (s *TwoFAService) Verify2FAToken(ctx context.Context, token string, code string) (entity.UserIDEntity, error) {
+	return s.Verify2FA(ctx, token, entity.TwoFAVerificationEntity{Method: entity.TwoFATypeTOTP, Code: code})
+}
+
+// Verify2FA verifies the proof of the 2FA method the user chose for a 2FA token
+// Returns userID if verification passed
+func (s *TwoFAService) Verify2FA(ctx context.Context, token string, verification entity.TwoFAVerificationEntity) (entity.UserIDEntity, error) {
+	userID, cacheKey, err := s.pendingLoginUserID(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	attempts, err := s.reserveVerifyAttempt(ctx, userID)
+	if err != nil {
+		return "", err
 	}
 
-	// Clear the token after successful verification
+	switch verification.Method {
+	case entity.TwoFATypeTOTP:
+		// Get secret from database
+		twoFA, exists, err := s.twoFARepo.GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP)
+		if err != nil {
+			return "", errors.Wrap(err, "fail to get 2fa record")
+		}
+		if !exists {
+			return "", error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "2FA is not enabled")
+		}
+
+		// Verify the TOTP code
+		valid := totp.Validate(verification.Code, twoFA.Secret)
+		if !valid {
+			return "", s.failVerifyAttempt(ctx, userID, attempts,
+				error_code.NewErrorWithErrorCodef(error_code.InvalidTotpCode, "please try again"))
+		}
+	case entity.TwoFATypeWebAuthn:
+		if err := s.requireVerifiedMethod(ctx, userID, entity.TwoFATypeWebAuthn); err != nil {
+			return "", err
+		}
+		if verification.WebAuthnResponse == nil {
+			return "", error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "webauthn response is required")
+		}
+		if err := s.passkeyService.VerifySecondFactor(ctx, userID, token, *verification.WebAuthnResponse); err != nil {
+			return "", err
+		}
+	case entity.TwoFATypeRecoveryCode:
+		// only the single-use codes complete a login, the recovery code removes the 2FA methods instead
+		used, err := s.useSingleUseRecoveryCode(ctx, userID, verification.Code)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			return "", s.failVerifyAttempt(ctx, userID, attempts,
+				error_code.NewErrorWithErrorCodef(error_code.InvalidRecoveryCode, "invalid or already used recovery code"))
+		}
+	default:
+		return "", error_code.NewErrorWithErrorCodef(error_code.TwoFaMethodNotEnabled, "unknown 2FA method %s", verification.Method)
+	}
+
+	// Clear the token and the failed attempts of the user after successful verification
 	_ = s.cacheRepo.Delete(ctx, cacheKey)
+	_ = s.cacheRepo.Delete(ctx, verifyAttemptsCacheKey(userID))
 
 	return userID, nil
 }
@@ -337,7 +627,12 @@ type TwoFALoginResult struct {
 
 // Verify2FATokenAndLogin verifies the TOTP code and issues tokens for login
 func (s *TwoFAService) Verify2FATokenAndLogin(ctx context.Context, token string, code string) (TwoFALoginResult, error) {
-	userID, err := s.Verify2FAToken(ctx, token, code)
+	return s.Verify2FAAndLogin(ctx, token, entity.TwoFAVerificationEntity{Method: entity.TwoFATypeTOTP, Code: code})
+}
+
+// Verify2FAAndLogin verifies the proof of the chosen 2FA method and issues tokens for login
+func (s *TwoFAService) Verify2FAAndLogin(ctx context.Context, token string, verification entity.TwoFAVerificationEntity) (TwoFALoginResult, error) {
+	userID, err := s.Verify2FA(ctx, token, verification)
 	if err != nil {
 		return TwoFALoginResult{}, err
 	}
@@ -350,6 +645,13 @@ func (s *TwoFAService) Verify2FATokenAndLogin(ctx context.Context, token string,
 	if !exists {
 		return TwoFALoginResult{}, error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user not found")
 	}
+	if err := checkUserEnabled(user); err != nil {
+		return TwoFALoginResult{}, err
+	}
+
+	if err := s.userRepo.RecordLogin(ctx, userID); err != nil {
+		return TwoFALoginResult{}, errors.Wrap(err, "fail to record login")
+	}
 
 	// Issue tokens
 	refreshToken, err := s.refreshTokenRepo.IssueRefreshToken(ctx, userID)
@@ -381,19 +683,22 @@ func (s *TwoFAService) Delete2FA(ctx context.Context, userID entity.UserIDEntity
 	}
 
 	// Verify the code - try TOTP first, then recovery code
+	// a method without a code of its own is removed with a code of the user's TOTP
 	codeValid := false
 	if twoFAType == entity.TwoFATypeTOTP {
 		codeValid = totp.Validate(code, twoFA.Secret)
+	} else {
+		totpFA, totpExists, err := s.twoFARepo.GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP)
+		if err != nil {
+			return errors.Wrap(err, "fail to check existing totp")
+		}
+		codeValid = totpExists && totpFA.Verified && totp.Validate(code, totpFA.Secret)
 	}
 
 	// If TOTP code is not valid, try recovery code
 	if !codeValid {
-		recoveryCode, err := s.twoFARepo.GetRecoveryCode(ctx, userID)
-		if err != nil {
-			return errors.Wrap(err, "fail to get recovery code")
-		}
-		if recoveryCode != nil && *recoveryCode == code {
-			codeValid = true
+		if codeValid, err = s.matchRecoveryCode(ctx, userID, code); err != nil {
+			return err
 		}
 	}
 
@@ -406,7 +711,12 @@ func (s *TwoFAService) Delete2FA(ctx context.Context, userID entity.UserIDEntity
 		return errors.Wrap(err, "fail to delete 2fa")
 	}
 
-	// Clear the recovery code
+	// Clear the recovery code once no method is left, it still protects the remaining ones
+	remaining, err := s.twoFARepo.GetByUserID(ctx, userID)
+	if err != nil || len(remaining) > 0 {
+		// Don't fail - the 2FA is already deleted, keeping the recovery code is the safe side
+		return nil
+	}
 	if err := s.twoFARepo.ClearRecoveryCode(ctx, userID); err != nil {
 		// Log but don't fail - the 2FA is already deleted
 	}
@@ -414,38 +724,74 @@ func (s *TwoFAService) Delete2FA(ctx context.Context, userID entity.UserIDEntity
 	return nil
 }
 
-// Remove2FAByRecoveryCode verifies the 2FA token and recovery code, then removes 2FA
-// This is used when a user has lost their authenticator but has the recovery code
-func (s *TwoFAService) Remove2FAByRecoveryCode(ctx context.Context, twoFAToken string, recoveryCode string) error {
-	// Verify the 2FA token to get the userID
-	cacheKey := fmt.Sprintf("%s%s", totpVerifyCacheKeyPrefix, twoFAToken)
-	cacheJSON, exists, err := s.cacheRepo.Get(ctx, cacheKey)
+// Remove2FAByAdmin removes a 2FA method of the user without a code, for a method that is lost or compromised.
+// The recovery code is cleared once no method is left.
+func (s *TwoFAService) Remove2FAByAdmin(ctx context.Context, userID entity.UserIDEntity, twoFAType entity.TwoFAType) error {
+	_, exists, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return errors.Wrap(err, "fail to get totp verify cache data")
+		return errors.Wrap(err, "fail to get user by id")
 	}
 	if !exists {
-		return error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "2FA verification session expired or invalid token")
+		return error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user not found")
 	}
 
-	var cacheData totpVerifyCacheData
-	if err := json.Unmarshal([]byte(cacheJSON), &cacheData); err != nil {
-		return errors.Wrap(err, "fail to unmarshal totp verify cache data")
+	_, exists, err = s.twoFARepo.GetByUserIDAndType(ctx, userID, twoFAType)
+	if err != nil {
+		return errors.Wrap(err, "fail to check existing 2fa")
+	}
+	if !exists {
+		return error_code.NewErrorWithErrorCodef(error_code.TwoFaMethodNotEnabled, "2FA of type %s is not enabled", twoFAType)
 	}
 
-	userID := entity.UserIDEntity(cacheData.UserID)
+	if err := s.twoFARepo.Delete(ctx, userID, twoFAType); err != nil {
+		return errors.Wrap(err, "fail to delete 2fa")
+	}
+
+	remaining, err := s.twoFARepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "fail to get remaining 2fa")
+	}
+	if len(remaining) == 0 {
+		if err := s.twoFARepo.ClearRecoveryCode(ctx, userID); err != nil {
+			return errors.Wrap(err, "fail to clear recovery code")
+		}
+	}
+
+	return nil
+}
+
+// Remove2FAByRecoveryCode verifies the 2FA token and recovery code, then removes 2FA
+// This is used when a user has lost their authenticator but has the recovery code
+func (s *TwoFAService) Remove2FAByRecoveryCode(ctx context.Context, twoFAToken string, recoveryCode string) error {
+	// Verify the 2FA token to get the userID
+	userID, cacheKey, err := s.pendingLoginUserID(ctx, twoFAToken)
+	if err != nil {
+		return err
+	}
+	attempts, err := s.reserveVerifyAttempt(ctx, userID)
+	if err != nil {
+		return err
+	}
 
 	// Verify the recovery code
-	storedRecoveryCode, err := s.twoFARepo.GetRecoveryCode(ctx, userID)
+	valid, err := s.matchRecoveryCode(ctx, userID, recoveryCode)
 	if err != nil {
-		return errors.Wrap(err, "fail to get recovery code")
+		return err
 	}
-	if storedRecoveryCode == nil || *storedRecoveryCode != recoveryCode {
-		return error_code.NewErrorWithErrorCodef(error_code.InvalidRecoveryCode, "invalid recovery code")
+	if !valid {
+		return s.failVerifyAttempt(ctx, userID, attempts,
+			error_code.NewErrorWithErrorCodef(error_code.InvalidRecoveryCode, "invalid recovery code"))
 	}
 
-	// Delete the 2FA record
-	if err := s.twoFARepo.Delete(ctx, userID, entity.TwoFATypeTOTP); err != nil {
-		return errors.Wrap(err, "fail to delete 2fa")
+	// Delete every 2FA method, the recovery code is the way out when all of them are lost
+	twoFAs, err := s.twoFARepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "fail to get 2fa records")
+	}
+	for _, twoFA := range twoFAs {
+		if err := s.twoFARepo.Delete(ctx, userID, twoFA.Type); err != nil {
+			return errors.Wrap(err, "fail to delete 2fa")
+		}
 	}
 
 	// Clear the recovery code
@@ -453,8 +799,151 @@ func (s *TwoFAService) Remove2FAByRecoveryCode(ctx context.Context, twoFAToken s
 		// Log but don't fail - the 2FA is already deleted
 	}
 
-	// Clear the 2FA token from cache
+	// Clear the 2FA token and the failed attempts of the user from cache
 	_ = s.cacheRepo.Delete(ctx, cacheKey)
+	_ = s.cacheRepo.Delete(ctx, verifyAttemptsCacheKey(userID))
 
 	return nil
 }
+
+// RegenerateRecoveryCodes replaces the single-use recovery codes of the user with a new set of RECOVERY_CODE_COUNT
+// codes and returns them, only their hashes are stored. code proves the user still controls the 2FA, it is a code of
+// the user's TOTP, the recovery code or an unused single-use recovery code.
+func (s *TwoFAService) RegenerateRecoveryCodes(ctx context.Context, userID entity.UserIDEntity, code string) ([]string, error) {
+	enabled, codeValid, err := s.verifyAccountCode(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, error_code.NewErrorWithErrorCodef(error_code.TwoFaMethodNotEnabled, "enable a 2FA method before generating recovery codes")
+	}
+	if !codeValid {
+		return nil, error_code.NewErrorWithErrorCodef(error_code.InvalidTotpCode, "invalid code, please try again")
+	}
+
+	codes := make([]string, s.config.RecoveryCodeCount)
+	hashes := make([]string, s.config.RecoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = generateSingleUseRecoveryCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = singleUseRecoveryCodeHash(codes[i])
+	}
+	if err := s.twoFARepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, errors.Wrap(err, "fail to save recovery codes")
+	}
+	return codes, nil
+}
+
+// RequireStepUpCode confirms a sensitive change of the signed in user, such as binding another login method, with
+// the 2FA of the user: a TOTP code, the recovery code or an unused single-use recovery code. Users without 2FA pass
+// without a code.
+func (s *TwoFAService) RequireStepUpCode(ctx context.Context, userID entity.UserIDEntity, code string) error {
+	enabled, codeValid, err := s.verifyAccountCode(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+	if code == "" {
+		return error_code.NewErrorWithErrorCodef(error_code.TwoFaCodeRequired, "user %s has 2FA enabled, a 2FA code is required", userID)
+	}
+	if !codeValid {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidTotpCode, "invalid code, please try again")
+	}
+	return nil
+}
+
+// verifyAccountCode checks a code the signed in user gives against the TOTP and then the recovery codes, a matching
+// single-use code is used up. enabled is false when the user has no verified 2FA method.
+func (s *TwoFAService) verifyAccountCode(ctx context.Context, userID entity.UserIDEntity, code string) (enabled bool, codeValid bool, err error) {
+	twoFAs, err := s.twoFARepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, false, errors.Wrap(err, "fail to get 2fa records")
+	}
+	for _, twoFA := range twoFAs {
+		if !twoFA.Verified {
+			continue
+		}
+		enabled = true
+		if twoFA.Type == entity.TwoFATypeTOTP && totp.Validate(code, twoFA.Secret) {
+			codeValid = true
+		}
+	}
+	if !enabled || codeValid || code == "" {
+		return enabled, codeValid, nil
+	}
+	if codeValid, err = s.matchRecoveryCode(ctx, userID, code); err != nil {
+		return false, false, err
+	}
+	return enabled, codeValid, nil
+}
+
+// RecoveryCodesStatus tells how many single-use recovery codes of the current set are left.
+func (s *TwoFAService) RecoveryCodesStatus(ctx context.Context, userID entity.UserIDEntity) (entity.RecoveryCodesStatusEntity, error) {
+	status, err := s.twoFARepo.GetRecoveryCodesStatus(ctx, userID)
+	if err != nil {
+		return entity.RecoveryCodesStatusEntity{}, errors.Wrap(err, "fail to get recovery codes status")
+	}
+	return status, nil
+}
+
+// matchRecoveryCode checks the code against the recovery code and then the single-use recovery codes,
+// a matching single-use code is used up.
+func (s *TwoFAService) matchRecoveryCode(ctx context.Context, userID entity.UserIDEntity, code string) (bool, error) {
+	recoveryCode, err := s.twoFARepo.GetRecoveryCode(ctx, userID)
+	if err != nil {
+		return false, errors.Wrap(err, "fail to get recovery code")
+	}
+	if recoveryCode != nil && *recoveryCode == code {
+		return true, nil
+	}
+	return s.useSingleUseRecoveryCode(ctx, userID, code)
+}
+
+// useSingleUseRecoveryCode marks the single-use recovery code as used, false when it is not an unused code of the user
+func (s *TwoFAService) useSingleUseRecoveryCode(ctx context.Context, userID entity.UserIDEntity, code string) (bool, error) {
+	hash := singleUseRecoveryCodeHash(code)
+	if hash == "" {
+		return false, nil
+	}
+	used, err := s.twoFARepo.UseRecoveryCode(ctx, userID, hash)
+	if err != nil {
+		return false, errors.Wrap(err, "fail to use recovery code")
+	}
+	return used, nil
+}
+
+// generateSingleUseRecoveryCode returns a random code of recoveryCodeAlphabet, grouped with dashes
+func generateSingleUseRecoveryCode() (string, error) {
+	groups := make([]string, recoveryCodeGroups)
+	alphabetSize := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := range groups {
+		group := make([]byte, recoveryCodeGroupLength)
+		for j := range group {
+			n, err := rand.Int(rand.Reader, alphabetSize)
+			if err != nil {
+				return "", errors.Wrap(err, "fail to generate recovery code")
+			}
+			group[j] = recoveryCodeAlphabet[n.Int64()]
+		}
+		groups[i] = string(group)
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+// singleUseRecoveryCodeHash hashes a single-use recovery code as it is stored. Case, spaces and dashes do not matter,
+// the code is typed from paper. Empty when nothing is left of the code.
+func singleUseRecoveryCodeHash(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+	if normalized == "" {
+		return ""
+	}
+	return utils.Sha256String(normalized)
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -17,6 +19,7 @@ import (
 	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/error_code"
 	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
 )
 
 // newTestTwoFAService creates a TwoFAService with all mocked dependencies.
@@ -34,8 +37,9 @@ func newTestTwoFAService(ctrl *gomock.Controller) (
 	refreshRepo := mockgen.NewMockIAuthRefreshTokenRepository(ctrl)
 	cacheRepo := mockgen.NewMockICache(ctrl)
 
-	svc, _ := NewTwoFaService(twoFARepo, userRepo, accessRepo, refreshRepo, cacheRepo, config.Config{
-		WebAuthnRPName: "TestApp",
+	svc, _ := NewTwoFaService(twoFARepo, userRepo, accessRepo, refreshRepo, cacheRepo, nil, newTestSessionRevocationService(config.Config{}), config.Config{
+		WebAuthnRPName:         "TestApp",
+		TwoFAMaxVerifyAttempts: 5,
 	})
 
 	return svc, twoFARepo, userRepo, accessRepo, refreshRepo, cacheRepo
@@ -158,7 +162,6 @@ func TestTwoFAService_GenerateNewTOTPForUser(t *testing.T) {
 			require.True(t, strings.HasPrefix(result.Token, "2fa-totp-"))
 			require.NotEmpty(t, result.Secret)
 			require.NotEmpty(t, result.URL)
-			require.NotEmpty(t, result.QRCode)
 			require.Contains(t, result.URL, "otpauth://totp/")
 		})
 	}
@@ -249,6 +252,105 @@ func TestTwoFAService_GetPendingTOTPByToken(t *testing.T) {
 	}
 }
 
+func TestTwoFAService_GetTOTPQRCode(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const token = "2fa-totp-test-token"
+	userID := entity.UserIDEntity("u-qr-user")
+	pendingData, _ := json.Marshal(totpCacheData{
+		Token:  token,
+		Secret: "JBSWY3DPEHPK3PXP",
+		UserID: string(userID),
+		URL:    "otpauth://totp/TestApp:testuser?issuer=TestApp&secret=JBSWY3DPEHPK3PXP",
+	})
+
+	tests := []struct {
+		name       string
+		setupMocks func(ctx context.Context, cacheRepo *mockgen.MockICache)
+		wantErrSub string
+		wantCode   *error_code.ErrorCode
+		wantQRCode string
+	}{
+		{
+			name: "expired token returns error code",
+			setupMocks: func(ctx context.Context, cacheRepo *mockgen.MockICache) {
+				cacheRepo.EXPECT().Get(ctx, "totp_pending:"+token).Return("", false, nil)
+			},
+			wantErrSub: "TOTP setup session expired",
+			wantCode:   &error_code.TwoFaTokenInvalid,
+		},
+		{
+			name: "token of another user is rejected",
+			setupMocks: func(ctx context.Context, cacheRepo *mockgen.MockICache) {
+				other, _ := json.Marshal(totpCacheData{Token: token, Secret: "S", UserID: "u-other"})
+				cacheRepo.EXPECT().Get(ctx, "totp_pending:"+token).Return(string(other), true, nil)
+			},
+			wantErrSub: "token does not belong to the current user",
+			wantCode:   &error_code.TwoFaTokenInvalid,
+		},
+		{
+			name: "cached qr code is returned without rendering",
+			setupMocks: func(ctx context.Context, cacheRepo *mockgen.MockICache) {
+				cacheRepo.EXPECT().Get(ctx, "totp_pending:"+token).Return(string(pendingData), true, nil)
+				cacheRepo.EXPECT().Get(ctx, "totp_qr:"+token).Return("cached-png", true, nil)
+			},
+			wantQRCode: "cached-png",
+		},
+		{
+			name: "qr code is rendered and cached on first request",
+			setupMocks: func(ctx context.Context, cacheRepo *mockgen.MockICache) {
+				cacheRepo.EXPECT().Get(ctx, "totp_pending:"+token).Return(string(pendingData), true, nil)
+				cacheRepo.EXPECT().Get(ctx, "totp_qr:"+token).Return("", false, nil)
+				cacheRepo.EXPECT().SetWithTTL(ctx, "totp_qr:"+token, gomock.Any(), uint64(totpCacheTTL)).Return(nil)
+			},
+		},
+		{
+			name: "cache set error is wrapped",
+			setupMocks: func(ctx context.Context, cacheRepo *mockgen.MockICache) {
+				cacheRepo.EXPECT().Get(ctx, "totp_pending:"+token).Return(string(pendingData), true, nil)
+				cacheRepo.EXPECT().Get(ctx, "totp_qr:"+token).Return("", false, nil)
+				cacheRepo.EXPECT().SetWithTTL(ctx, "totp_qr:"+token, gomock.Any(), uint64(totpCacheTTL)).Return(errors.New("cache down"))
+			},
+			wantErrSub: "fail to cache totp qr code",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			svc, _, _, _, _, cacheRepo := newTestTwoFAService(ctrl)
+			tt.setupMocks(ctx, cacheRepo)
+
+			qrCode, err := svc.GetTOTPQRCode(ctx, userID, token)
+
+			if tt.wantErrSub != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+				if tt.wantCode != nil {
+					var ecErr error_code.ErrorWithErrorCode
+					require.True(t, errors.As(err, &ecErr))
+					require.Equal(t, tt.wantCode.Code, ecErr.ErrorCode.Code)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			if tt.wantQRCode != "" {
+				require.Equal(t, tt.wantQRCode, qrCode)
+			} else {
+				require.NotEmpty(t, qrCode)
+			}
+		})
+	}
+}
+
 func TestTwoFAService_ClearPendingTOTP(t *testing.T) {
 	t.Parallel()
 
@@ -267,6 +369,9 @@ func TestTwoFAService_ClearPendingTOTP(t *testing.T) {
 				cacheRepo.EXPECT().
 					Delete(ctx, "totp_pending:"+token).
 					Return(nil)
+				cacheRepo.EXPECT().
+					Delete(ctx, "totp_qr:"+token).
+					Return(nil)
 			},
 		},
 		{
@@ -570,6 +675,9 @@ func TestTwoFAService_VerifyAndEnableTOTP(t *testing.T) {
 				cacheRepo.EXPECT().
 					Delete(ctx, "totp_pending:"+token).
 					Return(nil)
+				cacheRepo.EXPECT().
+					Delete(ctx, "totp_qr:"+token).
+					Return(nil)
 			},
 			useReal: true,
 		},
@@ -623,7 +731,7 @@ func TestTwoFAService_VerifyAndEnableTOTP(t *testing.T) {
 				tt.setupMocks(ctx, twoFARepo, cacheRepo, secret, code)
 			}
 
-			recoveryCode, err := svc.VerifyAndEnableTOTP(ctx, userID, token, code)
+			recoveryCode, err := svc.VerifyAndEnableTOTP(ctx, userID, token, code, "current-refresh-token-hash")
 
 			if tt.wantErrSub != "" {
 				require.Error(t, err)
@@ -645,6 +753,58 @@ func TestTwoFAService_VerifyAndEnableTOTP(t *testing.T) {
 	}
 }
 
+func TestTwoFAService_VerifyAndEnableTOTP_RevokesOtherSessions(t *testing.T) {
+	logger.InitLogger(config.Config{})
+
+	const (
+		userID = entity.UserIDEntity("user-1")
+		token  = "2fa-totp-test-token"
+	)
+
+	for _, revocationFails := range []bool{false, true} {
+		ctx := context.Background()
+		ctrl := gomock.NewController(t)
+		twoFARepo := mockgen.NewMockIAuth2FARepository(ctrl)
+		userRepo := mockgen.NewMockIUserRepository(ctrl)
+		accessRepo := mockgen.NewMockIAuthAccessTokenRepository(ctrl)
+		refreshRepo := mockgen.NewMockIAuthRefreshTokenRepository(ctrl)
+		cacheRepo := mockgen.NewMockICache(ctrl)
+		auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+		cfg := config.Config{RevokeSessionsOn2FAEnable: true}
+		sessionRevocationService := NewSessionRevocationService(userRepo, accessRepo, refreshRepo,
+			NewAuditLogService(auditLogRepo, NewSIEMExportService(nil, nil, config.Config{}), fixtures.NewFakeClock(time.Now())), &recordingMailer{}, cfg)
+		svc, _ := NewTwoFaService(twoFARepo, userRepo, accessRepo, refreshRepo, cacheRepo, nil, sessionRevocationService, cfg)
+
+		secret, code := generateTestTOTPSecret(t)
+		jsonData, _ := json.Marshal(totpCacheData{Token: token, Secret: secret, UserID: string(userID)})
+		twoFARepo.EXPECT().GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).Return(entity.TwoFAEntity{}, false, nil)
+		cacheRepo.EXPECT().Get(ctx, "totp_pending:"+token).Return(string(jsonData), true, nil)
+
+		if revocationFails {
+			// 2FA stays disabled when the other sessions could not be signed out
+			refreshRepo.EXPECT().DeleteOtherTokensByUserID(ctx, userID, "current-refresh-token-hash").Return(errors.New("redis offline"))
+
+			_, err := svc.VerifyAndEnableTOTP(ctx, userID, token, code, "current-refresh-token-hash")
+			require.ErrorContains(t, err, "fail to delete other refresh tokens")
+			continue
+		}
+
+		gomock.InOrder(
+			refreshRepo.EXPECT().DeleteOtherTokensByUserID(ctx, userID, "current-refresh-token-hash").Return(nil),
+			accessRepo.EXPECT().DeleteOtherTokensByUserID(ctx, userID, "current-refresh-token-hash").Return(nil),
+			twoFARepo.EXPECT().Create(ctx, gomock.Any()).Return(nil),
+		)
+		twoFARepo.EXPECT().SetRecoveryCode(ctx, userID, gomock.Any()).Return(nil)
+		cacheRepo.EXPECT().Delete(ctx, gomock.Any()).Return(nil).Times(2)
+		auditLogRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+		userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID, Name: "alice"}, true, nil)
+
+		recoveryCode, err := svc.VerifyAndEnableTOTP(ctx, userID, token, code, "current-refresh-token-hash")
+		require.NoError(t, err)
+		require.NotEmpty(t, recoveryCode)
+	}
+}
+
 func TestTwoFAService_Get2FAToken(t *testing.T) {
 	t.Parallel()
 
@@ -659,20 +819,20 @@ func TestTwoFAService_Get2FAToken(t *testing.T) {
 		wantErrSub string
 	}{
 		{
-			name: "repo error checking TOTP is wrapped",
+			name: "repo error listing 2FA methods is wrapped",
 			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache) {
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{}, false, errors.New("db error"))
+					GetByUserID(ctx, userID).
+					Return(nil, errors.New("db error"))
 			},
 			wantErrSub: "fail to check 2fa status",
 		},
 		{
-			name: "no TOTP enabled returns nil",
+			name: "no 2FA method enabled returns nil",
 			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache) {
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{}, false, nil)
+					GetByUserID(ctx, userID).
+					Return([]entity.TwoFAEntity{}, nil)
 			},
 			wantToken: false,
 		},
@@ -680,8 +840,8 @@ func TestTwoFAService_Get2FAToken(t *testing.T) {
 			name: "TOTP not verified returns nil",
 			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache) {
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{Verified: false}, true, nil)
+					GetByUserID(ctx, userID).
+					Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Verified: false}}, nil)
 			},
 			wantToken: false,
 		},
@@ -689,8 +849,11 @@ func TestTwoFAService_Get2FAToken(t *testing.T) {
 			name: "cache set error is wrapped",
 			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache) {
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{Verified: true}, true, nil)
+					GetByUserID(ctx, userID).
+					Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Verified: true}}, nil)
+				twoFARepo.EXPECT().
+					GetPreferredMethod(ctx, userID).
+					Return(nil, nil)
 				cacheRepo.EXPECT().
 					SetWithTTL(ctx, gomock.Any(), gomock.Any(), uint64(totpVerifyCacheTTL)).
 					Return(errors.New("cache error"))
@@ -701,8 +864,11 @@ func TestTwoFAService_Get2FAToken(t *testing.T) {
 			name: "successful token generation",
 			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache) {
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{Verified: true}, true, nil)
+					GetByUserID(ctx, userID).
+					Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Verified: true}}, nil)
+				twoFARepo.EXPECT().
+					GetPreferredMethod(ctx, userID).
+					Return(nil, nil)
 				cacheRepo.EXPECT().
 					SetWithTTL(ctx, gomock.Any(), gomock.Any(), uint64(totpVerifyCacheTTL)).
 					Return(nil)
@@ -801,6 +967,9 @@ func TestTwoFAService_Verify2FAToken(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+token).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				twoFARepo.EXPECT().
 					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
 					Return(entity.TwoFAEntity{}, false, nil)
@@ -817,6 +986,9 @@ func TestTwoFAService_Verify2FAToken(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+token).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				twoFARepo.EXPECT().
 					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
 					Return(entity.TwoFAEntity{}, false, errors.New("db down"))
@@ -832,6 +1004,9 @@ func TestTwoFAService_Verify2FAToken(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+token).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				twoFARepo.EXPECT().
 					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
 					Return(entity.TwoFAEntity{Secret: secret, Verified: true}, true, nil)
@@ -849,12 +1024,18 @@ func TestTwoFAService_Verify2FAToken(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+token).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				twoFARepo.EXPECT().
 					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
 					Return(entity.TwoFAEntity{Secret: secret, Verified: true}, true, nil)
 				cacheRepo.EXPECT().
 					Delete(ctx, "totp_verify:"+token).
 					Return(nil)
+				cacheRepo.EXPECT().
+					Delete(ctx, verifyAttemptsCacheKey(userID)).
+					Return(nil)
 			},
 			useReal:    true,
 			wantUserID: userID,
@@ -941,12 +1122,18 @@ func TestTwoFAService_Verify2FATokenAndLogin(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+token).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				twoFARepo.EXPECT().
 					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
 					Return(entity.TwoFAEntity{Secret: secret, Verified: true}, true, nil)
 				cacheRepo.EXPECT().
 					Delete(ctx, "totp_verify:"+token).
 					Return(nil)
+				cacheRepo.EXPECT().
+					Delete(ctx, verifyAttemptsCacheKey(userID)).
+					Return(nil)
 				userRepo.EXPECT().
 					GetByID(ctx, userID).
 					Return(entity.UserEntity{}, false, nil)
@@ -963,12 +1150,18 @@ func TestTwoFAService_Verify2FATokenAndLogin(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+token).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				twoFARepo.EXPECT().
 					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
 					Return(entity.TwoFAEntity{Secret: secret, Verified: true}, true, nil)
 				cacheRepo.EXPECT().
 					Delete(ctx, "totp_verify:"+token).
 					Return(nil)
+				cacheRepo.EXPECT().
+					Delete(ctx, verifyAttemptsCacheKey(userID)).
+					Return(nil)
 				userRepo.EXPECT().
 					GetByID(ctx, userID).
 					Return(entity.UserEntity{}, false, errors.New("db down"))
@@ -984,15 +1177,24 @@ func TestTwoFAService_Verify2FATokenAndLogin(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+token).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				twoFARepo.EXPECT().
 					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
 					Return(entity.TwoFAEntity{Secret: secret, Verified: true}, true, nil)
 				cacheRepo.EXPECT().
 					Delete(ctx, "totp_verify:"+token).
 					Return(nil)
+				cacheRepo.EXPECT().
+					Delete(ctx, verifyAttemptsCacheKey(userID)).
+					Return(nil)
 				userRepo.EXPECT().
 					GetByID(ctx, userID).
 					Return(entity.UserEntity{ID: userID, Name: "alice"}, true, nil)
+				userRepo.EXPECT().
+					RecordLogin(ctx, userID).
+					Return(nil)
 				refreshRepo.EXPECT().
 					IssueRefreshToken(ctx, userID).
 					Return(entity.RefreshToken{}, errors.New("token error"))
@@ -1008,17 +1210,26 @@ func TestTwoFAService_Verify2FATokenAndLogin(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+token).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				twoFARepo.EXPECT().
 					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
 					Return(entity.TwoFAEntity{Secret: secret, Verified: true}, true, nil)
 				cacheRepo.EXPECT().
 					Delete(ctx, "totp_verify:"+token).
 					Return(nil)
+				cacheRepo.EXPECT().
+					Delete(ctx, verifyAttemptsCacheKey(userID)).
+					Return(nil)
 				user := entity.UserEntity{ID: userID, Name: "alice"}
 				refresh := entity.NewRefreshToken(userID, "rt", time.Unix(1, 0), time.Unix(2, 0))
 				userRepo.EXPECT().
 					GetByID(ctx, userID).
 					Return(user, true, nil)
+				userRepo.EXPECT().
+					RecordLogin(ctx, userID).
+					Return(nil)
 				refreshRepo.EXPECT().
 					IssueRefreshToken(ctx, userID).
 					Return(refresh, nil)
@@ -1037,18 +1248,27 @@ func TestTwoFAService_Verify2FATokenAndLogin(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+token).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				twoFARepo.EXPECT().
 					GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
 					Return(entity.TwoFAEntity{Secret: secret, Verified: true}, true, nil)
 				cacheRepo.EXPECT().
 					Delete(ctx, "totp_verify:"+token).
 					Return(nil)
+				cacheRepo.EXPECT().
+					Delete(ctx, verifyAttemptsCacheKey(userID)).
+					Return(nil)
 				user := entity.UserEntity{ID: userID, Name: "alice"}
 				refresh := entity.NewRefreshToken(userID, "rt", time.Unix(100, 0), time.Unix(200, 0))
 				access := entity.NewAccessToken(userID, "at", time.Unix(100, 0), time.Unix(150, 0), refresh.TokenHash)
 				userRepo.EXPECT().
 					GetByID(ctx, userID).
 					Return(user, true, nil)
+				userRepo.EXPECT().
+					RecordLogin(ctx, userID).
+					Return(nil)
 				refreshRepo.EXPECT().
 					IssueRefreshToken(ctx, userID).
 					Return(refresh, nil)
@@ -1152,6 +1372,9 @@ func TestTwoFAService_Delete2FA(t *testing.T) {
 				twoFARepo.EXPECT().
 					GetRecoveryCode(ctx, userID).
 					Return(nil, nil)
+				twoFARepo.EXPECT().
+					UseRecoveryCode(ctx, userID, singleUseRecoveryCodeHash("000000")).
+					Return(false, nil)
 			},
 			useReal:    true,
 			wantErrSub: "invalid code, please try again",
@@ -1168,6 +1391,9 @@ func TestTwoFAService_Delete2FA(t *testing.T) {
 				twoFARepo.EXPECT().
 					GetRecoveryCode(ctx, userID).
 					Return(&rc, nil)
+				twoFARepo.EXPECT().
+					UseRecoveryCode(ctx, userID, singleUseRecoveryCodeHash("wrong-recovery-code")).
+					Return(false, nil)
 			},
 			useReal:    true,
 			wantErrSub: "invalid code, please try again",
@@ -1196,6 +1422,9 @@ func TestTwoFAService_Delete2FA(t *testing.T) {
 				twoFARepo.EXPECT().
 					Delete(ctx, userID, entity.TwoFATypeTOTP).
 					Return(nil)
+				twoFARepo.EXPECT().
+					GetByUserID(ctx, userID).
+					Return([]entity.TwoFAEntity{}, nil)
 				twoFARepo.EXPECT().
 					ClearRecoveryCode(ctx, userID).
 					Return(nil)
@@ -1216,6 +1445,9 @@ func TestTwoFAService_Delete2FA(t *testing.T) {
 				twoFARepo.EXPECT().
 					Delete(ctx, userID, entity.TwoFATypeTOTP).
 					Return(nil)
+				twoFARepo.EXPECT().
+					GetByUserID(ctx, userID).
+					Return([]entity.TwoFAEntity{}, nil)
 				twoFARepo.EXPECT().
 					ClearRecoveryCode(ctx, userID).
 					Return(nil)
@@ -1244,6 +1476,9 @@ func TestTwoFAService_Delete2FA(t *testing.T) {
 				twoFARepo.EXPECT().
 					Delete(ctx, userID, entity.TwoFATypeTOTP).
 					Return(nil)
+				twoFARepo.EXPECT().
+					GetByUserID(ctx, userID).
+					Return([]entity.TwoFAEntity{}, nil)
 				twoFARepo.EXPECT().
 					ClearRecoveryCode(ctx, userID).
 					Return(errors.New("cache error"))
@@ -1353,6 +1588,9 @@ func TestTwoFAService_Remove2FAByRecoveryCode(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+twoFAToken).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				twoFARepo.EXPECT().
 					GetRecoveryCode(ctx, userID).
 					Return(nil, errors.New("db error"))
@@ -1368,9 +1606,15 @@ func TestTwoFAService_Remove2FAByRecoveryCode(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+twoFAToken).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				twoFARepo.EXPECT().
 					GetRecoveryCode(ctx, userID).
 					Return(nil, nil)
+				twoFARepo.EXPECT().
+					UseRecoveryCode(ctx, userID, singleUseRecoveryCodeHash(recoveryStr)).
+					Return(false, nil)
 			},
 			wantErrSub: "invalid recovery code",
 			wantCode:   &error_code.InvalidRecoveryCode,
@@ -1384,10 +1628,16 @@ func TestTwoFAService_Remove2FAByRecoveryCode(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+twoFAToken).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				rc := recoveryStr
 				twoFARepo.EXPECT().
 					GetRecoveryCode(ctx, userID).
 					Return(&rc, nil)
+				twoFARepo.EXPECT().
+					UseRecoveryCode(ctx, userID, singleUseRecoveryCodeHash("wrong code")).
+					Return(false, nil)
 			},
 			wantErrSub: "invalid recovery code",
 			wantCode:   &error_code.InvalidRecoveryCode,
@@ -1401,10 +1651,16 @@ func TestTwoFAService_Remove2FAByRecoveryCode(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+twoFAToken).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				rc := recoveryStr
 				twoFARepo.EXPECT().
 					GetRecoveryCode(ctx, userID).
 					Return(&rc, nil)
+				twoFARepo.EXPECT().
+					GetByUserID(ctx, userID).
+					Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Verified: true}}, nil)
 				twoFARepo.EXPECT().
 					Delete(ctx, userID, entity.TwoFATypeTOTP).
 					Return(errors.New("delete failed"))
@@ -1420,10 +1676,16 @@ func TestTwoFAService_Remove2FAByRecoveryCode(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+twoFAToken).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				rc := recoveryStr
 				twoFARepo.EXPECT().
 					GetRecoveryCode(ctx, userID).
 					Return(&rc, nil)
+				twoFARepo.EXPECT().
+					GetByUserID(ctx, userID).
+					Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Verified: true}}, nil)
 				twoFARepo.EXPECT().
 					Delete(ctx, userID, entity.TwoFATypeTOTP).
 					Return(nil)
@@ -1433,6 +1695,9 @@ func TestTwoFAService_Remove2FAByRecoveryCode(t *testing.T) {
 				cacheRepo.EXPECT().
 					Delete(ctx, "totp_verify:"+twoFAToken).
 					Return(nil)
+				cacheRepo.EXPECT().
+					Delete(ctx, verifyAttemptsCacheKey(userID)).
+					Return(nil)
 			},
 		},
 		{
@@ -1444,10 +1709,16 @@ func TestTwoFAService_Remove2FAByRecoveryCode(t *testing.T) {
 				cacheRepo.EXPECT().
 					Get(ctx, "totp_verify:"+twoFAToken).
 					Return(string(jsonData), true, nil)
+				cacheRepo.EXPECT().
+					Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+					Return(int64(1), nil)
 				rc := recoveryStr
 				twoFARepo.EXPECT().
 					GetRecoveryCode(ctx, userID).
 					Return(&rc, nil)
+				twoFARepo.EXPECT().
+					GetByUserID(ctx, userID).
+					Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Verified: true}}, nil)
 				twoFARepo.EXPECT().
 					Delete(ctx, userID, entity.TwoFATypeTOTP).
 					Return(nil)
@@ -1457,6 +1728,9 @@ func TestTwoFAService_Remove2FAByRecoveryCode(t *testing.T) {
 				cacheRepo.EXPECT().
 					Delete(ctx, "totp_verify:"+twoFAToken).
 					Return(nil)
+				cacheRepo.EXPECT().
+					Delete(ctx, verifyAttemptsCacheKey(userID)).
+					Return(nil)
 			},
 		},
 	}
@@ -1522,7 +1796,7 @@ func TestTwoFAService_Security_VerifyAndEnableTOTP_TokenUserMismatch(t *testing.
 		Get(ctx, "totp_pending:"+token).
 		Return(string(jsonData), true, nil)
 
-	_, err := svc.VerifyAndEnableTOTP(ctx, attackerID, token, "123456")
+	_, err := svc.VerifyAndEnableTOTP(ctx, attackerID, token, "123456", "current-refresh-token-hash")
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "token does not belong to the current user")
 }
@@ -1532,8 +1806,8 @@ func TestTwoFAService_Security_Verify2FAToken_DoesNotConsumeOnFailure(t *testing
 
 	logger.InitLogger(config.Config{})
 
-	// Verify that token is NOT deleted when TOTP code is invalid.
-	// This is a security concern: allows unlimited brute-force attempts within TTL window.
+	// Verify that token is NOT deleted when TOTP code is invalid, the failure is counted instead.
+	// The token is locked after TWO_FA_MAX_VERIFY_ATTEMPTS failures, see TestTwoFAService_Security_Verify2FA_LocksTokenAfterMaxAttempts.
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
 	t.Cleanup(ctrl.Finish)
@@ -1552,6 +1826,9 @@ func TestTwoFAService_Security_Verify2FAToken_DoesNotConsumeOnFailure(t *testing
 	cacheRepo.EXPECT().
 		Get(ctx, "totp_verify:"+token).
 		Return(string(jsonData), true, nil)
+	cacheRepo.EXPECT().
+		Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).
+		Return(int64(1), nil)
 	twoFARepo.EXPECT().
 		GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
 		Return(entity.TwoFAEntity{Secret: secret, Verified: true}, true, nil)
@@ -1565,6 +1842,159 @@ func TestTwoFAService_Security_Verify2FAToken_DoesNotConsumeOnFailure(t *testing
 	require.Equal(t, error_code.InvalidTotpCode.Code, ecErr.ErrorCode.Code)
 }
 
+func TestTwoFAService_Security_Verify2FA_LocksUserAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	const userID = entity.UserIDEntity("user-1")
+	secret, code := generateTestTOTPSecret(t)
+
+	twoFARepo := mockgen.NewMockIAuth2FARepository(ctrl)
+	twoFARepo.EXPECT().
+		GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
+		Return(entity.TwoFAEntity{Secret: secret, Verified: true}, true, nil).
+		AnyTimes()
+	twoFARepo.EXPECT().
+		GetByUserID(ctx, userID).
+		Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Secret: secret, Verified: true}}, nil).
+		AnyTimes()
+	twoFARepo.EXPECT().
+		GetPreferredMethod(ctx, userID).
+		Return(nil, nil).
+		AnyTimes()
+	twoFARepo.EXPECT().
+		GetRecoveryCode(ctx, userID).
+		Return(nil, nil).
+		AnyTimes()
+	twoFARepo.EXPECT().
+		UseRecoveryCode(ctx, userID, gomock.Any()).
+		Return(false, nil).
+		AnyTimes()
+
+	clock := fixtures.NewFakeClock(time.Now())
+	cache := fixtures.NewFakeCache(clock)
+	svc, err := NewTwoFaService(
+		twoFARepo,
+		mockgen.NewMockIUserRepository(ctrl),
+		mockgen.NewMockIAuthAccessTokenRepository(ctrl),
+		mockgen.NewMockIAuthRefreshTokenRepository(ctrl),
+		cache,
+		nil,
+		nil,
+		config.Config{TwoFAMaxVerifyAttempts: 3},
+	)
+	require.NoError(t, err)
+
+	token, err := svc.Get2FAToken(ctx, userID)
+	require.NoError(t, err)
+
+	// wrong TOTP and recovery codes count against the same user
+	var ecErr error_code.ErrorWithErrorCode
+	_, err = svc.Verify2FAToken(ctx, *token, "000000")
+	require.ErrorAs(t, err, &ecErr)
+	require.Equal(t, error_code.InvalidTotpCode.Code, ecErr.ErrorCode.Code)
+	_, err = svc.Verify2FA(ctx, *token, entity.TwoFAVerificationEntity{Method: entity.TwoFATypeRecoveryCode, Code: "k7pm-x3qa-9tnd"})
+	require.ErrorAs(t, err, &ecErr)
+	require.Equal(t, error_code.InvalidRecoveryCode.Code, ecErr.ErrorCode.Code)
+	_, err = svc.Verify2FAToken(ctx, *token, "000000")
+	require.ErrorAs(t, err, &ecErr)
+	require.Equal(t, error_code.TooManyAttempts.Code, ecErr.ErrorCode.Code)
+
+	// the locked user is refused even with the right code
+	_, err = svc.Verify2FAToken(ctx, *token, code)
+	require.ErrorAs(t, err, &ecErr)
+	require.Equal(t, error_code.TooManyAttempts.Code, ecErr.ErrorCode.Code)
+	err = svc.Remove2FAByRecoveryCode(ctx, *token, "recovery code")
+	require.ErrorAs(t, err, &ecErr)
+	require.Equal(t, error_code.TooManyAttempts.Code, ecErr.ErrorCode.Code)
+
+	// signing in with the password again does not start over
+	token, err = svc.Get2FAToken(ctx, userID)
+	require.NoError(t, err)
+	_, err = svc.Verify2FAToken(ctx, *token, code)
+	require.ErrorAs(t, err, &ecErr)
+	require.Equal(t, error_code.TooManyAttempts.Code, ecErr.ErrorCode.Code)
+
+	// the lock ends with the attempts window
+	clock.Advance(totpVerifyAttemptsWindow * time.Second)
+	token, err = svc.Get2FAToken(ctx, userID)
+	require.NoError(t, err)
+	verifiedUserID, err := svc.Verify2FAToken(ctx, *token, code)
+	require.NoError(t, err)
+	require.Equal(t, userID, verifiedUserID)
+}
+
+func TestTwoFAService_Security_Verify2FA_ConcurrentGuessesStayWithinLimit(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	const userID = entity.UserIDEntity("user-1")
+	const maxAttempts = 3
+	secret, _ := generateTestTOTPSecret(t)
+
+	// every code that is checked reads the TOTP secret
+	var checked atomic.Int32
+	twoFARepo := mockgen.NewMockIAuth2FARepository(ctrl)
+	twoFARepo.EXPECT().
+		GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
+		DoAndReturn(func(context.Context, entity.UserIDEntity, entity.TwoFAType) (entity.TwoFAEntity, bool, error) {
+			checked.Add(1)
+			return entity.TwoFAEntity{Secret: secret, Verified: true}, true, nil
+		}).
+		AnyTimes()
+	twoFARepo.EXPECT().
+		GetByUserID(ctx, userID).
+		Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Secret: secret, Verified: true}}, nil).
+		AnyTimes()
+	twoFARepo.EXPECT().
+		GetPreferredMethod(ctx, userID).
+		Return(nil, nil).
+		AnyTimes()
+
+	svc, err := NewTwoFaService(
+		twoFARepo,
+		mockgen.NewMockIUserRepository(ctrl),
+		mockgen.NewMockIAuthAccessTokenRepository(ctrl),
+		mockgen.NewMockIAuthRefreshTokenRepository(ctrl),
+		fixtures.NewFakeCache(fixtures.NewFakeClock(time.Now())),
+		nil,
+		nil,
+		config.Config{TwoFAMaxVerifyAttempts: maxAttempts},
+	)
+	require.NoError(t, err)
+
+	// the guesses use several tokens of the same user at once
+	tokens := make([]string, 4)
+	for i := range tokens {
+		token, err := svc.Get2FAToken(ctx, userID)
+		require.NoError(t, err)
+		tokens[i] = *token
+	}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.Verify2FAToken(ctx, tokens[i%len(tokens)], "000000")
+			require.Error(t, err)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(maxAttempts), checked.Load())
+}
+
 func TestTwoFAService_Security_Remove2FAByRecoveryCode_NoUserIDInToken(t *testing.T) {
 	t.Parallel()
 
@@ -1584,9 +2014,15 @@ func TestTwoFAService_Security_Remove2FAByRecoveryCode_NoUserIDInToken(t *testin
 	cacheRepo.EXPECT().
 		Get(ctx, "totp_verify:"+twoFAToken).
 		Return(string(jsonData), true, nil)
+	cacheRepo.EXPECT().
+		Increment(ctx, verifyAttemptsCacheKey(""), uint64(totpVerifyAttemptsWindow)).
+		Return(int64(1), nil)
 	twoFARepo.EXPECT().
 		GetRecoveryCode(ctx, entity.UserIDEntity("")).
 		Return(nil, nil)
+	twoFARepo.EXPECT().
+		UseRecoveryCode(ctx, entity.UserIDEntity(""), singleUseRecoveryCodeHash("some-code")).
+		Return(false, nil)
 
 	err := svc.Remove2FAByRecoveryCode(ctx, twoFAToken, "some-code")
 	require.Error(t, err)
@@ -1595,3 +2031,596 @@ func TestTwoFAService_Security_Remove2FAByRecoveryCode_NoUserIDInToken(t *testin
 	require.True(t, errors.As(err, &ecErr))
 	require.Equal(t, error_code.InvalidRecoveryCode.Code, ecErr.ErrorCode.Code)
 }
+
+func TestTwoFAService_Verify2FAToken_WithFakeCache(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	const userID = entity.UserIDEntity("user-1")
+	secret, code := generateTestTOTPSecret(t)
+
+	twoFARepo := mockgen.NewMockIAuth2FARepository(ctrl)
+	twoFARepo.EXPECT().
+		GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).
+		Return(entity.TwoFAEntity{Secret: secret, Verified: true}, true, nil).
+		AnyTimes()
+	twoFARepo.EXPECT().
+		GetByUserID(ctx, userID).
+		Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Secret: secret, Verified: true}}, nil).
+		AnyTimes()
+	twoFARepo.EXPECT().
+		GetPreferredMethod(ctx, userID).
+		Return(nil, nil).
+		AnyTimes()
+
+	clock := fixtures.NewFakeClock(time.Now())
+	cache := fixtures.NewFakeCache(clock)
+	svc, err := NewTwoFaService(
+		twoFARepo,
+		mockgen.NewMockIUserRepository(ctrl),
+		mockgen.NewMockIAuthAccessTokenRepository(ctrl),
+		mockgen.NewMockIAuthRefreshTokenRepository(ctrl),
+		cache,
+		nil,
+		nil,
+		config.Config{TwoFAMaxVerifyAttempts: 5},
+	)
+	require.NoError(t, err)
+
+	// a wrong code keeps the session, the right one consumes it
+	token, err := svc.Get2FAToken(ctx, userID)
+	require.NoError(t, err)
+	_, err = svc.Verify2FAToken(ctx, *token, "000000")
+	require.Error(t, err)
+	verifiedUserID, err := svc.Verify2FAToken(ctx, *token, code)
+	require.NoError(t, err)
+	require.Equal(t, userID, verifiedUserID)
+	require.Empty(t, cache.Keys())
+	_, err = svc.Verify2FAToken(ctx, *token, code)
+	require.Error(t, err)
+
+	// the session expires with its TTL
+	token, err = svc.Get2FAToken(ctx, userID)
+	require.NoError(t, err)
+	clock.Advance(totpVerifyCacheTTL * time.Second)
+	_, err = svc.Verify2FAToken(ctx, *token, code)
+	var ecErr error_code.ErrorWithErrorCode
+	require.ErrorAs(t, err, &ecErr)
+	require.Equal(t, error_code.InvalidRequestParameters.Code, ecErr.ErrorCode.Code)
+}
+
+func TestTwoFAService_TwoFAMethods(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+	webauthn := entity.TwoFATypeWebAuthn
+
+	tests := []struct {
+		name       string
+		records    []entity.TwoFAEntity
+		passkeys   []entity.PasskeyEntity
+		preferred  *entity.TwoFAType
+		want       entity.TwoFAMethodsEntity
+		wantErrSub string
+	}{
+		{
+			name:    "no verified method",
+			records: []entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Verified: false}},
+			want:    entity.TwoFAMethodsEntity{Methods: []entity.TwoFAType{}},
+		},
+		{
+			name: "first method is preferred without a stored preference",
+			records: []entity.TwoFAEntity{
+				{Type: entity.TwoFATypeTOTP, Verified: true},
+				{Type: entity.TwoFATypeWebAuthn, Verified: true},
+			},
+			passkeys: []entity.PasskeyEntity{{ID: 1}},
+			want: entity.TwoFAMethodsEntity{
+				Methods:   []entity.TwoFAType{entity.TwoFATypeTOTP, entity.TwoFATypeWebAuthn},
+				Preferred: entity.TwoFATypeTOTP,
+			},
+		},
+		{
+			name: "stored preference is used",
+			records: []entity.TwoFAEntity{
+				{Type: entity.TwoFATypeTOTP, Verified: true},
+				{Type: entity.TwoFATypeWebAuthn, Verified: true},
+			},
+			passkeys:  []entity.PasskeyEntity{{ID: 1}},
+			preferred: &webauthn,
+			want: entity.TwoFAMethodsEntity{
+				Methods:   []entity.TwoFAType{entity.TwoFATypeTOTP, entity.TwoFATypeWebAuthn},
+				Preferred: entity.TwoFATypeWebAuthn,
+			},
+		},
+		{
+			name: "webauthn without passkeys is skipped",
+			records: []entity.TwoFAEntity{
+				{Type: entity.TwoFATypeTOTP, Verified: true},
+				{Type: entity.TwoFATypeWebAuthn, Verified: true},
+			},
+			passkeys:  []entity.PasskeyEntity{},
+			preferred: &webauthn,
+			want: entity.TwoFAMethodsEntity{
+				Methods:   []entity.TwoFAType{entity.TwoFATypeTOTP},
+				Preferred: entity.TwoFATypeTOTP,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			passkeySvc, _, _, _, passkeyRepo, _ := newTestPasskeyService(ctrl)
+			twoFARepo := mockgen.NewMockIAuth2FARepository(ctrl)
+			svc, err := NewTwoFaService(
+				twoFARepo,
+				mockgen.NewMockIUserRepository(ctrl),
+				mockgen.NewMockIAuthAccessTokenRepository(ctrl),
+				mockgen.NewMockIAuthRefreshTokenRepository(ctrl),
+				mockgen.NewMockICache(ctrl),
+				passkeySvc,
+				newTestSessionRevocationService(config.Config{}),
+				config.Config{},
+			)
+			require.NoError(t, err)
+
+			twoFARepo.EXPECT().GetByUserID(ctx, userID).Return(tt.records, nil)
+			if tt.passkeys != nil {
+				passkeyRepo.EXPECT().GetByUserID(ctx, userID).Return(tt.passkeys, nil)
+			}
+			if len(tt.want.Methods) > 0 {
+				twoFARepo.EXPECT().GetPreferredMethod(ctx, userID).Return(tt.preferred, nil)
+			}
+
+			got, err := svc.TwoFAMethods(ctx, userID)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTwoFAService_SetPreferred2FAMethod(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+
+	t.Run("enabled method is stored", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		svc, twoFARepo, _, _, _, _ := newTestTwoFAService(ctrl)
+		twoFARepo.EXPECT().GetByUserID(ctx, userID).Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Verified: true}}, nil)
+		twoFARepo.EXPECT().GetPreferredMethod(ctx, userID).Return(nil, nil)
+		method := entity.TwoFATypeTOTP
+		twoFARepo.EXPECT().SetPreferredMethod(ctx, userID, &method).Return(nil)
+
+		require.NoError(t, svc.SetPreferred2FAMethod(ctx, userID, entity.TwoFATypeTOTP))
+	})
+
+	t.Run("method not enabled is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		svc, twoFARepo, _, _, _, _ := newTestTwoFAService(ctrl)
+		twoFARepo.EXPECT().GetByUserID(ctx, userID).Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Verified: true}}, nil)
+		twoFARepo.EXPECT().GetPreferredMethod(ctx, userID).Return(nil, nil)
+
+		err := svc.SetPreferred2FAMethod(ctx, userID, entity.TwoFATypeWebAuthn)
+		var ecErr error_code.ErrorWithErrorCode
+		require.ErrorAs(t, err, &ecErr)
+		require.Equal(t, error_code.TwoFaMethodNotEnabled.Code, ecErr.ErrorCode.Code)
+	})
+}
+
+func TestTwoFAService_EnableWebAuthn2FA(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+	existingCode := "existing-code"
+
+	tests := []struct {
+		name         string
+		exists       bool
+		passkeys     []entity.PasskeyEntity
+		recoveryCode *string
+		wantCode     bool
+		wantErrCode  string
+	}{
+		{name: "already enabled", exists: true, wantErrCode: error_code.TwoFaAlreadyEnabled.Code},
+		{name: "no passkey registered", passkeys: []entity.PasskeyEntity{}, wantErrCode: error_code.TwoFaMethodNotEnabled.Code},
+		{name: "first method gets a recovery code", passkeys: []entity.PasskeyEntity{{ID: 1}}, wantCode: true},
+		{name: "existing recovery code is kept", passkeys: []entity.PasskeyEntity{{ID: 1}}, recoveryCode: &existingCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			passkeySvc, _, _, _, passkeyRepo, _ := newTestPasskeyService(ctrl)
+			twoFARepo := mockgen.NewMockIAuth2FARepository(ctrl)
+			svc, err := NewTwoFaService(
+				twoFARepo,
+				mockgen.NewMockIUserRepository(ctrl),
+				mockgen.NewMockIAuthAccessTokenRepository(ctrl),
+				mockgen.NewMockIAuthRefreshTokenRepository(ctrl),
+				mockgen.NewMockICache(ctrl),
+				passkeySvc,
+				newTestSessionRevocationService(config.Config{}),
+				config.Config{},
+			)
+			require.NoError(t, err)
+
+			twoFARepo.EXPECT().GetByUserIDAndType(ctx, userID, entity.TwoFATypeWebAuthn).Return(entity.TwoFAEntity{}, tt.exists, nil)
+			if tt.passkeys != nil {
+				passkeyRepo.EXPECT().GetByUserID(ctx, userID).Return(tt.passkeys, nil)
+			}
+			if tt.wantErrCode == "" {
+				twoFARepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, twoFA entity.TwoFAEntity) error {
+					require.Equal(t, entity.TwoFATypeWebAuthn, twoFA.Type)
+					require.True(t, twoFA.Verified)
+					return nil
+				})
+				twoFARepo.EXPECT().GetRecoveryCode(ctx, userID).Return(tt.recoveryCode, nil)
+				if tt.wantCode {
+					twoFARepo.EXPECT().SetRecoveryCode(ctx, userID, gomock.Any()).Return(nil)
+				}
+			}
+
+			code, err := svc.EnableWebAuthn2FA(ctx, userID, "current-refresh-token-hash")
+			if tt.wantErrCode != "" {
+				var ecErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &ecErr)
+				require.Equal(t, tt.wantErrCode, ecErr.ErrorCode.Code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, code != nil)
+		})
+	}
+}
+
+func TestTwoFAService_Verify2FA_UnknownMethod(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	svc, _, _, _, _, cacheRepo := newTestTwoFAService(ctrl)
+
+	const token = "2fa-totp-verify-token"
+	jsonData, _ := json.Marshal(totpVerifyCacheData{Token: token, UserID: "user-1"})
+	cacheRepo.EXPECT().Get(ctx, "totp_verify:"+token).Return(string(jsonData), true, nil)
+	cacheRepo.EXPECT().Increment(ctx, verifyAttemptsCacheKey("user-1"), uint64(totpVerifyAttemptsWindow)).Return(int64(1), nil)
+
+	_, err := svc.Verify2FA(ctx, token, entity.TwoFAVerificationEntity{Method: "sms", Code: "123456"})
+	var ecErr error_code.ErrorWithErrorCode
+	require.ErrorAs(t, err, &ecErr)
+	require.Equal(t, error_code.TwoFaMethodNotEnabled.Code, ecErr.ErrorCode.Code)
+}
+
+func TestTwoFAService_Remove2FAByAdmin(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+
+	tests := []struct {
+		name       string
+		setupMocks func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository, userRepo *mockgen.MockIUserRepository)
+		wantErrSub string
+		wantCode   *error_code.ErrorCode
+	}{
+		{
+			name: "user not found returns error code",
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{}, false, nil)
+			},
+			wantErrSub: "user not found",
+			wantCode:   &error_code.UserNotFound,
+		},
+		{
+			name: "method not enabled returns error code",
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID}, true, nil)
+				twoFARepo.EXPECT().GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).Return(entity.TwoFAEntity{}, false, nil)
+			},
+			wantErrSub: "2FA of type totp is not enabled",
+			wantCode:   &error_code.TwoFaMethodNotEnabled,
+		},
+		{
+			name: "remaining method keeps the recovery code",
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID}, true, nil)
+				twoFARepo.EXPECT().GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).Return(entity.TwoFAEntity{UserID: userID, Type: entity.TwoFATypeTOTP}, true, nil)
+				twoFARepo.EXPECT().Delete(ctx, userID, entity.TwoFATypeTOTP).Return(nil)
+				twoFARepo.EXPECT().GetByUserID(ctx, userID).Return([]entity.TwoFAEntity{{UserID: userID, Type: entity.TwoFATypeWebAuthn}}, nil)
+			},
+		},
+		{
+			name: "last method clears the recovery code",
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID}, true, nil)
+				twoFARepo.EXPECT().GetByUserIDAndType(ctx, userID, entity.TwoFATypeTOTP).Return(entity.TwoFAEntity{UserID: userID, Type: entity.TwoFATypeTOTP}, true, nil)
+				twoFARepo.EXPECT().Delete(ctx, userID, entity.TwoFATypeTOTP).Return(nil)
+				twoFARepo.EXPECT().GetByUserID(ctx, userID).Return(nil, nil)
+				twoFARepo.EXPECT().ClearRecoveryCode(ctx, userID).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			svc, twoFARepo, userRepo, _, _, _ := newTestTwoFAService(ctrl)
+			tt.setupMocks(ctx, twoFARepo, userRepo)
+
+			err := svc.Remove2FAByAdmin(ctx, userID, entity.TwoFATypeTOTP)
+
+			if tt.wantErrSub == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErrSub)
+			if tt.wantCode != nil {
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, tt.wantCode.Code, codeErr.ErrorCode.Code)
+			}
+		})
+	}
+}
+
+func TestTwoFAService_RegenerateRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+	secret, validCode := generateTestTOTPSecret(t)
+	legacyCode := "legacy recovery code"
+
+	tests := []struct {
+		name        string
+		code        string
+		setupMocks  func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository)
+		wantErrCode string
+	}{
+		{
+			name: "TOTP code regenerates the codes",
+			code: validCode,
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository) {
+				twoFARepo.EXPECT().GetByUserID(ctx, userID).Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Secret: secret, Verified: true}}, nil)
+				twoFARepo.EXPECT().ReplaceRecoveryCodes(ctx, userID, gomock.Len(3)).Return(nil)
+			},
+		},
+		{
+			name: "unused single-use code regenerates the codes",
+			code: "K7PM-X3QA-9TND",
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository) {
+				twoFARepo.EXPECT().GetByUserID(ctx, userID).Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeWebAuthn, Verified: true}}, nil)
+				twoFARepo.EXPECT().GetRecoveryCode(ctx, userID).Return(&legacyCode, nil)
+				twoFARepo.EXPECT().UseRecoveryCode(ctx, userID, singleUseRecoveryCodeHash("k7pmx3qa9tnd")).Return(true, nil)
+				twoFARepo.EXPECT().ReplaceRecoveryCodes(ctx, userID, gomock.Len(3)).Return(nil)
+			},
+		},
+		{
+			name: "wrong code is rejected",
+			code: "000000",
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository) {
+				twoFARepo.EXPECT().GetByUserID(ctx, userID).Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Secret: secret, Verified: true}}, nil)
+				twoFARepo.EXPECT().GetRecoveryCode(ctx, userID).Return(&legacyCode, nil)
+				twoFARepo.EXPECT().UseRecoveryCode(ctx, userID, singleUseRecoveryCodeHash("000000")).Return(false, nil)
+			},
+			wantErrCode: error_code.InvalidTotpCode.Code,
+		},
+		{
+			name: "no verified 2FA method",
+			code: validCode,
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository) {
+				twoFARepo.EXPECT().GetByUserID(ctx, userID).Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Secret: secret}}, nil)
+			},
+			wantErrCode: error_code.TwoFaMethodNotEnabled.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			svc, twoFARepo, _, _, _, _ := newTestTwoFAService(ctrl)
+			svc.config.RecoveryCodeCount = 3
+			tt.setupMocks(ctx, twoFARepo)
+
+			codes, err := svc.RegenerateRecoveryCodes(ctx, userID, tt.code)
+			if tt.wantErrCode != "" {
+				var ecErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &ecErr)
+				require.Equal(t, tt.wantErrCode, ecErr.ErrorCode.Code)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, codes, 3)
+			for _, code := range codes {
+				require.Regexp(t, `^[a-z2-9]{4}-[a-z2-9]{4}-[a-z2-9]{4}$`, code)
+			}
+		})
+	}
+}
+
+func TestTwoFAService_Verify2FA_RecoveryCode(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const token = "2fa-totp-verify-token"
+	const userID = entity.UserIDEntity("user-1")
+
+	tests := []struct {
+		name    string
+		used    bool
+		wantErr bool
+	}{
+		{name: "unused code completes the verification", used: true},
+		{name: "used or unknown code is rejected", used: false, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			svc, twoFARepo, _, _, _, cacheRepo := newTestTwoFAService(ctrl)
+			jsonData, _ := json.Marshal(totpVerifyCacheData{Token: token, UserID: string(userID)})
+			cacheRepo.EXPECT().Get(ctx, "totp_verify:"+token).Return(string(jsonData), true, nil)
+			cacheRepo.EXPECT().Increment(ctx, verifyAttemptsCacheKey(userID), uint64(totpVerifyAttemptsWindow)).Return(int64(1), nil)
+			twoFARepo.EXPECT().UseRecoveryCode(ctx, userID, singleUseRecoveryCodeHash("k7pm-x3qa-9tnd")).Return(tt.used, nil)
+			if !tt.wantErr {
+				cacheRepo.EXPECT().Delete(ctx, "totp_verify:"+token).Return(nil)
+				cacheRepo.EXPECT().Delete(ctx, verifyAttemptsCacheKey(userID)).Return(nil)
+			}
+
+			gotUserID, err := svc.Verify2FA(ctx, token, entity.TwoFAVerificationEntity{Method: entity.TwoFATypeRecoveryCode, Code: "k7pm-x3qa-9tnd"})
+			if tt.wantErr {
+				var ecErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &ecErr)
+				require.Equal(t, error_code.InvalidRecoveryCode.Code, ecErr.ErrorCode.Code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, userID, gotUserID)
+		})
+	}
+}
+
+func TestSingleUseRecoveryCodeHash(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, singleUseRecoveryCodeHash("k7pm-x3qa-9tnd"), singleUseRecoveryCodeHash(" K7PM X3QA 9TND "))
+	require.NotEqual(t, singleUseRecoveryCodeHash("k7pm-x3qa-9tnd"), singleUseRecoveryCodeHash("k7pm-x3qa-9tne"))
+	require.Empty(t, singleUseRecoveryCodeHash(" - "))
+}
+
+func TestTwoFAService_RequireStepUpCode(t *testing.T) {
+	t.Parallel()
+
+	const userID = entity.UserIDEntity("user-1")
+	secret, validCode := generateTestTOTPSecret(t)
+	totpFA := entity.TwoFAEntity{UserID: userID, Type: entity.TwoFATypeTOTP, Secret: secret, Verified: true}
+
+	tests := []struct {
+		name        string
+		code        string
+		setupMocks  func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository)
+		wantErrCode *error_code.ErrorCode
+	}{
+		{
+			name: "user without 2fa passes without a code",
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository) {
+				twoFARepo.EXPECT().GetByUserID(ctx, userID).Return(nil, nil)
+			},
+		},
+		{
+			name: "unverified method does not count",
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository) {
+				twoFARepo.EXPECT().GetByUserID(ctx, userID).Return([]entity.TwoFAEntity{{UserID: userID, Type: entity.TwoFATypeTOTP, Secret: secret}}, nil)
+			},
+		},
+		{
+			name: "user with 2fa needs a code",
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository) {
+				twoFARepo.EXPECT().GetByUserID(ctx, userID).Return([]entity.TwoFAEntity{totpFA}, nil)
+			},
+			wantErrCode: &error_code.TwoFaCodeRequired,
+		},
+		{
+			name: "valid totp code passes",
+			code: validCode,
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository) {
+				twoFARepo.EXPECT().GetByUserID(ctx, userID).Return([]entity.TwoFAEntity{totpFA}, nil)
+			},
+		},
+		{
+			name: "single-use recovery code passes and is used up",
+			code: "k7pm-x3qa-9tnd",
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository) {
+				twoFARepo.EXPECT().GetByUserID(ctx, userID).Return([]entity.TwoFAEntity{totpFA}, nil)
+				twoFARepo.EXPECT().GetRecoveryCode(ctx, userID).Return(nil, nil)
+				twoFARepo.EXPECT().UseRecoveryCode(ctx, userID, singleUseRecoveryCodeHash("k7pm-x3qa-9tnd")).Return(true, nil)
+			},
+		},
+		{
+			name: "wrong code is refused",
+			code: "wrong-code",
+			setupMocks: func(ctx context.Context, twoFARepo *mockgen.MockIAuth2FARepository) {
+				twoFARepo.EXPECT().GetByUserID(ctx, userID).Return([]entity.TwoFAEntity{totpFA}, nil)
+				twoFARepo.EXPECT().GetRecoveryCode(ctx, userID).Return(nil, nil)
+				twoFARepo.EXPECT().UseRecoveryCode(ctx, userID, singleUseRecoveryCodeHash("wrong-code")).Return(false, nil)
+			},
+			wantErrCode: &error_code.InvalidTotpCode,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			svc, twoFARepo, _, _, _, _ := newTestTwoFAService(ctrl)
+			tt.setupMocks(ctx, twoFARepo)
+
+			err := svc.RequireStepUpCode(ctx, userID, tt.code)
+			if tt.wantErrCode == nil {
+				require.NoError(t, err)
+				return
+			}
+			var codeErr error_code.ErrorWithErrorCode
+			require.ErrorAs(t, err, &codeErr)
+			require.Equal(t, *tt.wantErrCode, codeErr.ErrorCode)
+		})
+	}
+}
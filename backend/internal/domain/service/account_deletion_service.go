@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/scheduler"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+)
+
+const AccountDeletionJobPurgeAccounts = "account_deletions"
+
+func NewAccountDeletionService(
+	userRepo repository.IUserRepository,
+	userService *UserService,
+	mailer domain_client.IMailer,
+	clock domain_client.IClock,
+	cfg config.Config,
+) *AccountDeletionService {
+	return &AccountDeletionService{
+		userRepo:    userRepo,
+		userService: userService,
+		mailer:      mailer,
+		clock:       clock,
+		config:      cfg,
+	}
+}
+
+// AccountDeletionService deletes the accounts users asked to delete once ACCOUNT_DELETION_GRACE_PERIOD is over.
+// Until then the account works as before, the user is notified and can sign in and cancel the deletion.
+type AccountDeletionService struct {
+	userRepo    repository.IUserRepository
+	userService *UserService
+	mailer      domain_client.IMailer
+	clock       domain_client.IClock
+	config      config.Config
+}
+
+// Jobs returns the job purging the accounts whose grace period is over.
+func (s *AccountDeletionService) Jobs() []scheduler.Job {
+	return []scheduler.Job{
+		{
+			Name:        AccountDeletionJobPurgeAccounts,
+			Description: "Deletes the accounts whose ACCOUNT_DELETION_GRACE_PERIOD is over, with all their data",
+			Spec: func(ctx context.Context) (string, error) {
+				return "@every 10m", nil
+			},
+			Run: s.PurgeDueAccounts,
+		},
+	}
+}
+
+// ScheduleDeletion schedules the deletion of the account of the user and returns when it is purged. Without a grace
+// period the account is deleted right away and nil is returned. Asking again keeps the time already scheduled.
+func (s *AccountDeletionService) ScheduleDeletion(ctx context.Context, userID entity.UserIDEntity) (*time.Time, error) {
+	if s.config.AccountDeletionGracePeriod == 0 {
+		return nil, s.userService.DeleteUser(ctx, userID)
+	}
+
+	user, exists, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to get user by id")
+	}
+	if !exists {
+		return nil, error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user not found")
+	}
+	if user.DeletionScheduledAt != nil {
+		return user.DeletionScheduledAt, nil
+	}
+
+	deleteAt := s.clock.Now().UTC().Add(time.Duration(s.config.AccountDeletionGracePeriod) * time.Second)
+	if err := s.userRepo.SetDeletionScheduledAt(ctx, userID, &deleteAt); err != nil {
+		return nil, errors.Wrapf(err, "fail to schedule deletion of user %s", userID)
+	}
+
+	logger.Infof(ctx, "Deletion of user %s scheduled at %s", userID, deleteAt.Format(time.RFC3339))
+	s.notify(ctx, user, "Account deletion scheduled", fmt.Sprintf(
+		"The account %s and all its data will be deleted after %s.\n\n"+
+			"If you did not ask for it or changed your mind, sign in and cancel the deletion from your account settings.\n",
+		user.Name, deleteAt.Format(time.RFC1123)))
+	return &deleteAt, nil
+}
+
+// CancelDeletion keeps the account of the user, whose deletion is scheduled.
+func (s *AccountDeletionService) CancelDeletion(ctx context.Context, userID entity.UserIDEntity) error {
+	user, exists, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get user by id")
+	}
+	if !exists {
+		return error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user not found")
+	}
+	if user.DeletionScheduledAt == nil {
+		return error_code.NewErrorWithErrorCodef(error_code.AccountDeletionNotScheduled, "no deletion is scheduled for user %s", userID)
+	}
+
+	if err := s.userRepo.SetDeletionScheduledAt(ctx, userID, nil); err != nil {
+		return errors.Wrapf(err, "fail to cancel deletion of user %s", userID)
+	}
+
+	logger.Infof(ctx, "Deletion of user %s cancelled", userID)
+	s.notify(ctx, user, "Account deletion cancelled", fmt.Sprintf(
+		"The deletion of the account %s was cancelled, the account and its data are kept.\n", user.Name))
+	return nil
+}
+
+// PurgeDueAccounts deletes the accounts whose grace period is over with all their data. A failing account does not
+// stop the others, the failures are reported together.
+func (s *AccountDeletionService) PurgeDueAccounts(ctx context.Context) error {
+	now := s.clock.Now().UTC()
+	userIDs, err := s.userRepo.UsersDueForDeletion(ctx, now)
+	if err != nil {
+		return errors.Wrap(err, "failed to list users due for deletion")
+	}
+
+	failed := 0
+	var lastErr error
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.purge(ctx, userID, now); err != nil {
+			logger.Warnf(ctx, "Failed to delete user %s: %v", userID, err)
+			failed++
+			lastErr = err
+		}
+	}
+	if failed > 0 {
+		return errors.Wrapf(lastErr, "failed to delete %d of %d users due for deletion, last error", failed, len(userIDs))
+	}
+	if len(userIDs) > 0 {
+		logger.Infof(ctx, "Deleted %d users whose deletion grace period is over", len(userIDs))
+	}
+	return nil
+}
+
+// purge deletes the user unless the deletion was cancelled since the users due were listed, the schedule is checked
+// in the transaction deleting the data
+func (s *AccountDeletionService) purge(ctx context.Context, userID entity.UserIDEntity, now time.Time) error {
+	_, err := s.userService.DeleteUserIfDeletionDue(ctx, userID, now)
+	return err
+}
+
+func (s *AccountDeletionService) notify(ctx context.Context, user entity.UserEntity, subject string, body string) {
+	if user.Mail == nil || *user.Mail == "" {
+		return
+	}
+	if err := s.mailer.Send(ctx, entity.MailEntity{To: *user.Mail, Subject: subject, Body: body}); err != nil {
+		logger.Errorf(ctx, "Failed to send account deletion notice %q to user %s: %v", subject, user.ID, err)
+	}
+}
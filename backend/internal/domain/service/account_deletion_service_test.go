@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+type accountDeletionTestEnv struct {
+	svc              *AccountDeletionService
+	userRepo         *mockgen.MockIUserRepository
+	accessTokenRepo  *mockgen.MockIAuthAccessTokenRepository
+	refreshTokenRepo *mockgen.MockIAuthRefreshTokenRepository
+	mailer           *recordingMailer
+	clock            *fixtures.FakeClock
+	user             entity.UserEntity
+}
+
+func newAccountDeletionTestEnv(t *testing.T, gracePeriod int) accountDeletionTestEnv {
+	ctrl := gomock.NewController(t)
+	env := accountDeletionTestEnv{
+		userRepo:         mockgen.NewMockIUserRepository(ctrl),
+		accessTokenRepo:  mockgen.NewMockIAuthAccessTokenRepository(ctrl),
+		refreshTokenRepo: mockgen.NewMockIAuthRefreshTokenRepository(ctrl),
+		mailer:           &recordingMailer{},
+		clock:            fixtures.NewFakeClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)),
+		user:             fixtures.NewTestUser().WithID("user-1").Build(),
+	}
+	mail := "leaving@example.com"
+	env.user.Mail = &mail
+
+	cfg := config.Config{AccountDeletionGracePeriod: gracePeriod}
+	userService := NewUserService(env.userRepo, env.accessTokenRepo, env.refreshTokenRepo, nil, nil, cfg)
+	env.svc = NewAccountDeletionService(env.userRepo, userService, env.mailer, env.clock, cfg)
+	return env
+}
+
+// expectPurge expects the user and all its data to be deleted
+func (env accountDeletionTestEnv) expectPurge(ctx context.Context, user entity.UserEntity) {
+	env.userRepo.EXPECT().GetByID(ctx, user.ID).Return(user, true, nil)
+	env.accessTokenRepo.EXPECT().DeleteAllTokensByUserID(ctx, user.ID).Return(nil)
+	env.refreshTokenRepo.EXPECT().DeleteAllTokensByUserID(ctx, user.ID).Return(nil)
+	env.userRepo.EXPECT().DeleteUserWithAllData(ctx, user.ID).Return(nil)
+}
+
+func TestAccountDeletionService_ScheduleDeletion(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	ctx := context.Background()
+
+	t.Run("schedules the deletion after the grace period and notifies the user", func(t *testing.T) {
+		env := newAccountDeletionTestEnv(t, 3600)
+		wantAt := env.clock.Now().Add(time.Hour)
+		env.userRepo.EXPECT().GetByID(ctx, env.user.ID).Return(env.user, true, nil)
+		env.userRepo.EXPECT().SetDeletionScheduledAt(ctx, env.user.ID, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ entity.UserIDEntity, at *time.Time) error {
+				require.True(t, wantAt.Equal(*at))
+				return nil
+			})
+
+		deletionScheduledAt, err := env.svc.ScheduleDeletion(ctx, env.user.ID)
+		require.NoError(t, err)
+		require.True(t, wantAt.Equal(*deletionScheduledAt))
+		require.Len(t, env.mailer.sent(), 1)
+		require.Equal(t, "Account deletion scheduled", env.mailer.sent()[0].Subject)
+	})
+
+	t.Run("keeps a deletion already scheduled", func(t *testing.T) {
+		env := newAccountDeletionTestEnv(t, 3600)
+		scheduledAt := env.clock.Now().Add(time.Minute)
+		env.user.DeletionScheduledAt = &scheduledAt
+		env.userRepo.EXPECT().GetByID(ctx, env.user.ID).Return(env.user, true, nil)
+
+		deletionScheduledAt, err := env.svc.ScheduleDeletion(ctx, env.user.ID)
+		require.NoError(t, err)
+		require.True(t, scheduledAt.Equal(*deletionScheduledAt))
+		require.Empty(t, env.mailer.sent())
+	})
+
+	t.Run("deletes right away without a grace period", func(t *testing.T) {
+		env := newAccountDeletionTestEnv(t, 0)
+		env.expectPurge(ctx, env.user)
+
+		deletionScheduledAt, err := env.svc.ScheduleDeletion(ctx, env.user.ID)
+		require.NoError(t, err)
+		require.Nil(t, deletionScheduledAt)
+	})
+}
+
+func TestAccountDeletionService_CancelDeletion(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	ctx := context.Background()
+
+	t.Run("nothing to cancel", func(t *testing.T) {
+		env := newAccountDeletionTestEnv(t, 3600)
+		env.userRepo.EXPECT().GetByID(ctx, env.user.ID).Return(env.user, true, nil)
+
+		err := env.svc.CancelDeletion(ctx, env.user.ID)
+		var codeErr error_code.ErrorWithErrorCode
+		require.ErrorAs(t, err, &codeErr)
+		require.Equal(t, error_code.AccountDeletionNotScheduled.Code, codeErr.ErrorCode.Code)
+	})
+
+	t.Run("clears the scheduled deletion", func(t *testing.T) {
+		env := newAccountDeletionTestEnv(t, 3600)
+		scheduledAt := env.clock.Now().Add(time.Minute)
+		env.user.DeletionScheduledAt = &scheduledAt
+		env.userRepo.EXPECT().GetByID(ctx, env.user.ID).Return(env.user, true, nil)
+		env.userRepo.EXPECT().SetDeletionScheduledAt(ctx, env.user.ID, nil).Return(nil)
+
+		require.NoError(t, env.svc.CancelDeletion(ctx, env.user.ID))
+		require.Len(t, env.mailer.sent(), 1)
+		require.Equal(t, "Account deletion cancelled", env.mailer.sent()[0].Subject)
+	})
+}
+
+func TestAccountDeletionService_PurgeDueAccounts(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	ctx := context.Background()
+
+	env := newAccountDeletionTestEnv(t, 3600)
+	now := env.clock.Now().UTC()
+	dueAt := now.Add(-time.Minute)
+	due := fixtures.NewTestUser().WithID("user-due").Build()
+	due.DeletionScheduledAt = &dueAt
+	cancelled := fixtures.NewTestUser().WithID("user-cancelled").Build()
+	failing := fixtures.NewTestUser().WithID("user-failing").Build()
+	failing.DeletionScheduledAt = &dueAt
+
+	env.userRepo.EXPECT().UsersDueForDeletion(ctx, now).Return([]entity.UserIDEntity{due.ID, cancelled.ID, failing.ID}, nil)
+	// the deletion checks the schedule in its transaction, the sessions of a deleted user are revoked
+	env.userRepo.EXPECT().DeleteUserIfDeletionDue(ctx, due.ID, now).Return(true, nil)
+	env.accessTokenRepo.EXPECT().DeleteAllTokensByUserID(ctx, due.ID).Return(nil)
+	env.refreshTokenRepo.EXPECT().DeleteAllTokensByUserID(ctx, due.ID).Return(nil)
+	// cancelled after the users due were listed, the user is kept and so are its sessions
+	env.userRepo.EXPECT().DeleteUserIfDeletionDue(ctx, cancelled.ID, now).Return(false, nil)
+	// a failing user does not stop the others
+	env.userRepo.EXPECT().DeleteUserIfDeletionDue(ctx, failing.ID, now).Return(false, errors.New("database is gone"))
+
+	err := env.svc.PurgeDueAccounts(ctx)
+	require.ErrorContains(t, err, "failed to delete 1 of 3 users due for deletion")
+}
@@ -0,0 +1,423 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	accountRecoveryCodeKeyPrefix     = "account_recovery_code:"
+	accountRecoveryThrottleKeyPrefix = "account_recovery_throttle:"
+	accountRecoveryCodeTTL           = 900 // 15 minutes
+	// accountRecoveryResendInterval is the seconds between two codes sent to the same email
+	accountRecoveryResendInterval = 60
+	// accountRecoveryMaxCodeAttempts wrong codes drop the pending code, a new one has to be requested
+	accountRecoveryMaxCodeAttempts = 5
+	accountRecoveryCodeDigits      = 6
+	accountRecoveryTokenBytes      = 32
+)
+
+func NewAccountRecoveryService(
+	recoveryRepo repository.IAccountRecoveryRepository,
+	userRepo repository.IUserRepository,
+	twoFARepo repository.IAuth2FARepository,
+	cacheRepo repository.ICache,
+	userService *UserService,
+	mailer domain_client.IMailer,
+	clock domain_client.IClock,
+	cfg config.Config,
+) *AccountRecoveryService {
+	return &AccountRecoveryService{
+		recoveryRepo: recoveryRepo,
+		userRepo:     userRepo,
+		twoFARepo:    twoFARepo,
+		cacheRepo:    cacheRepo,
+		userService:  userService,
+		mailer:       mailer,
+		clock:        clock,
+		config:       cfg,
+	}
+}
+
+// AccountRecoveryService recovers the accounts whose password and 2FA methods are all lost. The requester proves
+// the ownership of the account email with a code, an admin approves the request, and once ACCOUNT_RECOVERY_DELAY
+// is over the requester sets a new password and every 2FA method is removed. The account is notified at every step
+// and can cancel the request until it is completed.
+type AccountRecoveryService struct {
+	recoveryRepo repository.IAccountRecoveryRepository
+	userRepo     repository.IUserRepository
+	twoFARepo    repository.IAuth2FARepository
+	cacheRepo    repository.ICache
+	userService  *UserService
+	mailer       domain_client.IMailer
+	clock        domain_client.IClock
+	config       config.Config
+}
+
+// accountRecoveryCode is the pending proof of email ownership, kept in the cache
+type accountRecoveryCode struct {
+	UserID    entity.UserIDEntity `json:"user_id"`
+	CodeHash  string              `json:"code_hash"`
+	Attempts  int                 `json:"attempts"`
+	ExpiresAt int64               `json:"expires_at"`
+}
+
+// StartRecovery emails a code to the account with the email. Nothing tells the caller whether an account uses the email,
+// an unknown email and a throttled one succeed without sending anything.
+func (s *AccountRecoveryService) StartRecovery(ctx context.Context, email string) error {
+	if s.config.Mailer == "none" {
+		return error_code.NewErrorWithErrorCodef(error_code.AccountRecoveryUnavailable, "account recovery needs a mailer, MAILER is none")
+	}
+
+	emailKey := accountRecoveryEmailKey(email)
+	throttled, err := s.cacheRepo.Has(ctx, accountRecoveryThrottleKeyPrefix+emailKey)
+	if err != nil {
+		return errors.Wrap(err, "fail to check account recovery throttle")
+	}
+	if throttled {
+		logger.Infof(ctx, "Account recovery code not sent, one was sent less than %d seconds ago", accountRecoveryResendInterval)
+		return nil
+	}
+	if err := s.cacheRepo.SetWithTTL(ctx, accountRecoveryThrottleKeyPrefix+emailKey, "1", accountRecoveryResendInterval); err != nil {
+		return errors.Wrap(err, "fail to set account recovery throttle")
+	}
+
+	user, exists, err := s.userRepo.GetByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return errors.Wrap(err, "fail to get user by email")
+	}
+	if !exists || user.Mail == nil {
+		logger.Infof(ctx, "Account recovery code not sent, no account uses the email")
+		return nil
+	}
+
+	code, err := generateAccountRecoveryCode()
+	if err != nil {
+		return err
+	}
+	pending, err := json.Marshal(accountRecoveryCode{
+		UserID:    user.ID,
+		CodeHash:  hashAccountRecoverySecret(code),
+		ExpiresAt: s.clock.Now().Add(accountRecoveryCodeTTL * time.Second).Unix(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "fail to marshal account recovery code")
+	}
+	if err := s.cacheRepo.SetWithTTL(ctx, accountRecoveryCodeKeyPrefix+emailKey, string(pending), accountRecoveryCodeTTL); err != nil {
+		return errors.Wrap(err, "fail to store account recovery code")
+	}
+
+	s.notify(ctx, user, "Your account recovery code", fmt.Sprintf(
+		"Someone asked to recover the account %s, which has lost its password and two-factor authentication.\n\n"+
+			"The recovery code is %s, it expires in %d minutes.\n\n"+
+			"If you did not ask for it, ignore this email: nothing changes without the code.\n",
+		user.Name, code, accountRecoveryCodeTTL/60))
+	logger.Infof(ctx, "Account recovery code sent to user %s", user.ID)
+	return nil
+}
+
+// VerifyRecovery checks the emailed code and creates the recovery request. The returned token completes the
+// request, it is only known to the requester.
+func (s *AccountRecoveryService) VerifyRecovery(ctx context.Context, email string, code string) (entity.AccountRecoveryRequestEntity, string, error) {
+	userID, err := s.checkRecoveryCode(ctx, accountRecoveryEmailKey(email), code)
+	if err != nil {
+		return entity.AccountRecoveryRequestEntity{}, "", err
+	}
+	user, exists, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return entity.AccountRecoveryRequestEntity{}, "", errors.Wrap(err, "fail to get user by id")
+	}
+	if !exists {
+		return entity.AccountRecoveryRequestEntity{}, "", error_code.NewErrorWithErrorCodef(error_code.InvalidAccountRecoveryCode, "the account of the code no longer exists")
+	}
+
+	existing, err := s.recoveryRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return entity.AccountRecoveryRequestEntity{}, "", errors.Wrap(err, "fail to list account recovery requests")
+	}
+	if lo.ContainsBy(existing, func(request entity.AccountRecoveryRequestEntity) bool { return request.Status.IsOpen() }) {
+		return entity.AccountRecoveryRequestEntity{}, "", error_code.NewErrorWithErrorCodef(error_code.AccountRecoveryAlreadyRequested,
+			"user %s already has an open account recovery request", userID)
+	}
+
+	token, err := generateAccountRecoveryToken()
+	if err != nil {
+		return entity.AccountRecoveryRequestEntity{}, "", err
+	}
+	now := s.clock.Now()
+	request := entity.AccountRecoveryRequestEntity{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Status:      entity.AccountRecoveryStatusPending,
+		TokenHash:   hashAccountRecoverySecret(token),
+		CreatedAt:   now,
+		AvailableAt: now.Add(time.Duration(s.config.AccountRecoveryDelay) * time.Second),
+		UpdatedAt:   now,
+	}
+	if err := s.recoveryRepo.Create(ctx, request); err != nil {
+		return entity.AccountRecoveryRequestEntity{}, "", errors.Wrap(err, "fail to create account recovery request")
+	}
+
+	s.notify(ctx, user, "Account recovery requested", fmt.Sprintf(
+		"An account recovery was requested for the account %s.\n\n"+
+			"Once an administrator approves it, it can be completed after %s: the password will be replaced and "+
+			"two-factor authentication removed.\n\n"+
+			"If you did not ask for it, sign in and cancel the request from your account settings, or contact an administrator.\n",
+		user.Name, request.AvailableAt.UTC().Format(time.RFC1123)))
+	logger.Infof(ctx, "Account recovery request %s created for user %s, available at %s", request.ID, userID, request.AvailableAt)
+	return request, token, nil
+}
+
+// ListRequests returns the recovery requests with the status for the admins, an empty status lists all of them.
+func (s *AccountRecoveryService) ListRequests(ctx context.Context, status entity.AccountRecoveryStatus) ([]entity.AccountRecoveryRequestEntity, error) {
+	requests, err := s.recoveryRepo.List(ctx, status)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to list account recovery requests")
+	}
+	return requests, nil
+}
+
+// ListUserRequests returns the recovery requests of a user, so a signed in owner can spot and cancel one.
+func (s *AccountRecoveryService) ListUserRequests(ctx context.Context, userID entity.UserIDEntity) ([]entity.AccountRecoveryRequestEntity, error) {
+	requests, err := s.recoveryRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to list account recovery requests")
+	}
+	return requests, nil
+}
+
+// ApproveRequest lets the requester complete the recovery once the delay is over.
+func (s *AccountRecoveryService) ApproveRequest(ctx context.Context, adminID entity.UserIDEntity, requestID string) error {
+	return s.decideRequest(ctx, adminID, requestID, entity.AccountRecoveryStatusApproved)
+}
+
+// RejectRequest closes the recovery request for good.
+func (s *AccountRecoveryService) RejectRequest(ctx context.Context, adminID entity.UserIDEntity, requestID string) error {
+	return s.decideRequest(ctx, adminID, requestID, entity.AccountRecoveryStatusRejected)
+}
+
+func (s *AccountRecoveryService) decideRequest(ctx context.Context, adminID entity.UserIDEntity, requestID string, decision entity.AccountRecoveryStatus) error {
+	request, err := s.existingRequest(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	if request.UserID == adminID {
+		return error_code.NewErrorWithErrorCodef(error_code.Forbidden, "an admin can not decide the recovery of the own account")
+	}
+	if request.Status != entity.AccountRecoveryStatusPending {
+		return error_code.NewErrorWithErrorCodef(error_code.AccountRecoveryClosed, "account recovery request %s is %s", requestID, request.Status)
+	}
+
+	updated, err := s.recoveryRepo.UpdateStatus(ctx, requestID, []entity.AccountRecoveryStatus{entity.AccountRecoveryStatusPending}, decision, &adminID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to %s account recovery request", decision)
+	}
+	if !updated {
+		return error_code.NewErrorWithErrorCodef(error_code.AccountRecoveryClosed, "account recovery request %s changed meanwhile", requestID)
+	}
+
+	if user, exists, err := s.userRepo.GetByID(ctx, request.UserID); err == nil && exists {
+		if decision == entity.AccountRecoveryStatusApproved {
+			s.notify(ctx, user, "Account recovery approved", fmt.Sprintf(
+				"The recovery of the account %s was approved by an administrator. It can be completed after %s.\n\n"+
+					"If you did not ask for it, sign in and cancel the request from your account settings now.\n",
+				user.Name, request.AvailableAt.UTC().Format(time.RFC1123)))
+		} else {
+			s.notify(ctx, user, "Account recovery rejected", fmt.Sprintf(
+				"The recovery of the account %s was rejected by an administrator, nothing changed.\n", user.Name))
+		}
+	}
+	logger.Infof(ctx, "Account recovery request %s of user %s %s by %s", requestID, request.UserID, decision, adminID)
+	return nil
+}
+
+// CancelRequest closes an open recovery request of the user, the way out when someone else asked for it.
+func (s *AccountRecoveryService) CancelRequest(ctx context.Context, userID entity.UserIDEntity, requestID string) error {
+	request, err := s.existingRequest(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	if request.UserID != userID {
+		return error_code.NewErrorWithErrorCodef(error_code.AccountRecoveryNotFound, "account recovery request %s not found", requestID)
+	}
+
+	updated, err := s.recoveryRepo.UpdateStatus(ctx, requestID,
+		[]entity.AccountRecoveryStatus{entity.AccountRecoveryStatusPending, entity.AccountRecoveryStatusApproved},
+		entity.AccountRecoveryStatusCancelled, &userID)
+	if err != nil {
+		return errors.Wrap(err, "fail to cancel account recovery request")
+	}
+	if !updated {
+		return error_code.NewErrorWithErrorCodef(error_code.AccountRecoveryClosed, "account recovery request %s is %s", requestID, request.Status)
+	}
+
+	logger.Infof(ctx, "Account recovery request %s cancelled by user %s", requestID, userID)
+	return nil
+}
+
+// CompleteRecovery sets the new password and removes every 2FA method and the recovery code of the account,
+// then signs it out of every device. The request must be approved and its delay over.
+func (s *AccountRecoveryService) CompleteRecovery(ctx context.Context, requestID string, token string, newPassword string) error {
+	request, err := s.existingRequest(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	// a wrong token looks like a missing request, the request ids are not secrets
+	if subtle.ConstantTimeCompare([]byte(hashAccountRecoverySecret(token)), []byte(request.TokenHash)) != 1 {
+		return error_code.NewErrorWithErrorCodef(error_code.AccountRecoveryNotFound, "account recovery request %s not found", requestID)
+	}
+	switch request.Status {
+	case entity.AccountRecoveryStatusApproved:
+	case entity.AccountRecoveryStatusPending:
+		return error_code.NewErrorWithErrorCodef(error_code.AccountRecoveryNotApproved, "account recovery request %s is not approved yet", requestID)
+	default:
+		return error_code.NewErrorWithErrorCodef(error_code.AccountRecoveryClosed, "account recovery request %s is %s", requestID, request.Status)
+	}
+	if s.clock.Now().Before(request.AvailableAt) {
+		return error_code.NewErrorWithErrorCodeFAppendExtraData(error_code.AccountRecoveryNotYetAvailable,
+			map[string]any{"available_at": request.AvailableAt},
+			"account recovery request %s can be completed after %s", requestID, request.AvailableAt)
+	}
+
+	// closing the request first makes a cancellation racing the completion win or lose as a whole
+	updated, err := s.recoveryRepo.UpdateStatus(ctx, requestID,
+		[]entity.AccountRecoveryStatus{entity.AccountRecoveryStatusApproved}, entity.AccountRecoveryStatusCompleted, nil)
+	if err != nil {
+		return errors.Wrap(err, "fail to complete account recovery request")
+	}
+	if !updated {
+		return error_code.NewErrorWithErrorCodef(error_code.AccountRecoveryClosed, "account recovery request %s changed meanwhile", requestID)
+	}
+
+	twoFAs, err := s.twoFARepo.GetByUserID(ctx, request.UserID)
+	if err != nil {
+		return errors.Wrap(err, "fail to get 2fa records")
+	}
+	for _, twoFA := range twoFAs {
+		if err := s.twoFARepo.Delete(ctx, request.UserID, twoFA.Type); err != nil {
+			return errors.Wrapf(err, "fail to delete 2fa %s", twoFA.Type)
+		}
+	}
+	if err := s.twoFARepo.ClearRecoveryCode(ctx, request.UserID); err != nil {
+		return errors.Wrap(err, "fail to clear recovery code")
+	}
+	if err := s.userRepo.UpdatePassword(ctx, request.UserID, newPassword); err != nil {
+		return errors.Wrap(err, "fail to update password")
+	}
+	if err := s.userService.RevokeSessions(ctx, request.UserID); err != nil {
+		return err
+	}
+
+	if user, exists, err := s.userRepo.GetByID(ctx, request.UserID); err == nil && exists {
+		s.notify(ctx, user, "Account recovered", fmt.Sprintf(
+			"The account %s was recovered: the password was replaced, two-factor authentication removed and every device signed out.\n\n"+
+				"If you did not do it, contact an administrator right away.\n", user.Name))
+	}
+	logger.Infof(ctx, "Account recovery request %s completed, %d 2fa methods of user %s removed", requestID, len(twoFAs), request.UserID)
+	return nil
+}
+
+// checkRecoveryCode returns the user of the pending code, a wrong code counts as an attempt
+func (s *AccountRecoveryService) checkRecoveryCode(ctx context.Context, emailKey string, code string) (entity.UserIDEntity, error) {
+	key := accountRecoveryCodeKeyPrefix + emailKey
+	invalid := error_code.NewErrorWithErrorCodef(error_code.InvalidAccountRecoveryCode, "account recovery code is expired or invalid")
+
+	raw, exists, err := s.cacheRepo.Get(ctx, key)
+	if err != nil {
+		return "", errors.Wrap(err, "fail to get account recovery code")
+	}
+	if !exists {
+		return "", invalid
+	}
+	var pending accountRecoveryCode
+	if err := json.Unmarshal([]byte(raw), &pending); err != nil {
+		return "", errors.Wrap(err, "fail to unmarshal account recovery code")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAccountRecoverySecret(strings.TrimSpace(code))), []byte(pending.CodeHash)) == 1 {
+		_ = s.cacheRepo.Delete(ctx, key)
+		return pending.UserID, nil
+	}
+
+	pending.Attempts++
+	remaining := pending.ExpiresAt - s.clock.Now().Unix()
+	if pending.Attempts >= accountRecoveryMaxCodeAttempts || remaining <= 0 {
+		_ = s.cacheRepo.Delete(ctx, key)
+		return "", invalid
+	}
+	updated, err := json.Marshal(pending)
+	if err != nil {
+		return "", errors.Wrap(err, "fail to marshal account recovery code")
+	}
+	if err := s.cacheRepo.SetWithTTL(ctx, key, string(updated), uint64(remaining)); err != nil {
+		return "", errors.Wrap(err, "fail to update account recovery code")
+	}
+	return "", invalid
+}
+
+func (s *AccountRecoveryService) existingRequest(ctx context.Context, requestID string) (entity.AccountRecoveryRequestEntity, error) {
+	request, exists, err := s.recoveryRepo.GetByID(ctx, requestID)
+	if err != nil {
+		return entity.AccountRecoveryRequestEntity{}, errors.Wrap(err, "fail to get account recovery request")
+	}
+	if !exists {
+		return entity.AccountRecoveryRequestEntity{}, error_code.NewErrorWithErrorCodef(error_code.AccountRecoveryNotFound, "account recovery request %s not found", requestID)
+	}
+	return request, nil
+}
+
+// notify emails the account, a failed notice is logged and does not fail the step it reports
+func (s *AccountRecoveryService) notify(ctx context.Context, user entity.UserEntity, subject string, body string) {
+	if user.Mail == nil || *user.Mail == "" {
+		logger.Warnf(ctx, "Account recovery notice %q not sent, user %s has no email", subject, user.ID)
+		return
+	}
+	if err := s.mailer.Send(ctx, entity.MailEntity{To: *user.Mail, Subject: subject, Body: body}); err != nil {
+		logger.Errorf(ctx, "Failed to send account recovery notice %q to user %s: %v", subject, user.ID, err)
+	}
+}
+
+// accountRecoveryEmailKey keeps the emails out of the cache keys
+func accountRecoveryEmailKey(email string) string {
+	return hashAccountRecoverySecret(strings.ToLower(strings.TrimSpace(email)))
+}
+
+func hashAccountRecoverySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAccountRecoveryCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", errors.Wrap(err, "fail to generate account recovery code")
+	}
+	return fmt.Sprintf("%0*d", accountRecoveryCodeDigits, n.Int64()), nil
+}
+
+func generateAccountRecoveryToken() (string, error) {
+	buf := make([]byte, accountRecoveryTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "fail to generate account recovery token")
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+// recordingMailer keeps the sent mails instead of sending them.
+type recordingMailer struct {
+	mu    sync.Mutex
+	mails []entity.MailEntity
+}
+
+func (m *recordingMailer) Send(ctx context.Context, mail entity.MailEntity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mails = append(m.mails, mail)
+	return nil
+}
+
+func (m *recordingMailer) sent() []entity.MailEntity {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]entity.MailEntity(nil), m.mails...)
+}
+
+var accountRecoveryCodePattern = regexp.MustCompile(`\b\d{6}\b`)
+
+type accountRecoveryTestEnv struct {
+	svc              *AccountRecoveryService
+	recoveryRepo     *mockgen.MockIAccountRecoveryRepository
+	userRepo         *mockgen.MockIUserRepository
+	twoFARepo        *mockgen.MockIAuth2FARepository
+	accessTokenRepo  *mockgen.MockIAuthAccessTokenRepository
+	refreshTokenRepo *mockgen.MockIAuthRefreshTokenRepository
+	mailer           *recordingMailer
+	clock            *fixtures.FakeClock
+	user             entity.UserEntity
+}
+
+func newAccountRecoveryTestEnv(t *testing.T, mailer string) accountRecoveryTestEnv {
+	ctrl := gomock.NewController(t)
+	env := accountRecoveryTestEnv{
+		recoveryRepo:     mockgen.NewMockIAccountRecoveryRepository(ctrl),
+		userRepo:         mockgen.NewMockIUserRepository(ctrl),
+		twoFARepo:        mockgen.NewMockIAuth2FARepository(ctrl),
+		accessTokenRepo:  mockgen.NewMockIAuthAccessTokenRepository(ctrl),
+		refreshTokenRepo: mockgen.NewMockIAuthRefreshTokenRepository(ctrl),
+		mailer:           &recordingMailer{},
+		clock:            fixtures.NewFakeClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)),
+		user:             fixtures.NewTestUser().WithID("user-1").Build(),
+	}
+	mail := "locked-out@example.com"
+	env.user.Mail = &mail
+	env.userRepo.EXPECT().GetByID(gomock.Any(), env.user.ID).Return(env.user, true, nil).AnyTimes()
+	env.userRepo.EXPECT().GetByEmail(gomock.Any(), mail).Return(env.user, true, nil).AnyTimes()
+	env.userRepo.EXPECT().GetByEmail(gomock.Any(), gomock.Any()).Return(entity.UserEntity{}, false, nil).AnyTimes()
+
+	cfg := config.Config{Mailer: mailer, AccountRecoveryDelay: 3600}
+	userService := NewUserService(env.userRepo, env.accessTokenRepo, env.refreshTokenRepo, nil, nil, cfg)
+	env.svc = NewAccountRecoveryService(env.recoveryRepo, env.userRepo, env.twoFARepo,
+		fixtures.NewFakeCache(env.clock), userService, env.mailer, env.clock, cfg)
+	return env
+}
+
+func requireAccountRecoveryErrorCode(t *testing.T, err error, want error_code.ErrorCode) {
+	t.Helper()
+	var codeErr error_code.ErrorWithErrorCode
+	require.ErrorAs(t, err, &codeErr)
+	assert.Equal(t, want.Code, codeErr.ErrorCode.Code)
+}
+
+func TestAccountRecoveryService_StartRecovery(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	t.Run("needs a mailer", func(t *testing.T) {
+		t.Parallel()
+		env := newAccountRecoveryTestEnv(t, "none")
+		requireAccountRecoveryErrorCode(t, env.svc.StartRecovery(context.Background(), *env.user.Mail), error_code.AccountRecoveryUnavailable)
+	})
+
+	t.Run("unknown email sends nothing", func(t *testing.T) {
+		t.Parallel()
+		env := newAccountRecoveryTestEnv(t, "log")
+		require.NoError(t, env.svc.StartRecovery(context.Background(), "nobody@example.com"))
+		assert.Empty(t, env.mailer.sent())
+	})
+
+	t.Run("code is sent once per interval", func(t *testing.T) {
+		t.Parallel()
+		env := newAccountRecoveryTestEnv(t, "log")
+		ctx := context.Background()
+
+		require.NoError(t, env.svc.StartRecovery(ctx, *env.user.Mail))
+		require.Len(t, env.mailer.sent(), 1)
+		assert.Equal(t, *env.user.Mail, env.mailer.sent()[0].To)
+		assert.Regexp(t, accountRecoveryCodePattern, env.mailer.sent()[0].Body)
+
+		require.NoError(t, env.svc.StartRecovery(ctx, *env.user.Mail))
+		assert.Len(t, env.mailer.sent(), 1)
+
+		env.clock.Advance(accountRecoveryResendInterval * time.Second)
+		require.NoError(t, env.svc.StartRecovery(ctx, *env.user.Mail))
+		assert.Len(t, env.mailer.sent(), 2)
+	})
+}
+
+func TestAccountRecoveryService_VerifyRecovery(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	t.Run("creates a delayed request and notifies the account", func(t *testing.T) {
+		t.Parallel()
+		env := newAccountRecoveryTestEnv(t, "log")
+		ctx := context.Background()
+		require.NoError(t, env.svc.StartRecovery(ctx, *env.user.Mail))
+		code := accountRecoveryCodePattern.FindString(env.mailer.sent()[0].Body)
+
+		env.recoveryRepo.EXPECT().ListByUserID(gomock.Any(), env.user.ID).Return(nil, nil)
+		var created entity.AccountRecoveryRequestEntity
+		env.recoveryRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, request entity.AccountRecoveryRequestEntity) error {
+			created = request
+			return nil
+		})
+
+		request, token, err := env.svc.VerifyRecovery(ctx, "  LOCKED-OUT@example.com ", code)
+		require.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Equal(t, created, request)
+		assert.Equal(t, entity.AccountRecoveryStatusPending, request.Status)
+		assert.Equal(t, env.clock.Now().Add(time.Hour), request.AvailableAt)
+		// only the hash of the token is stored
+		assert.Equal(t, hashAccountRecoverySecret(token), request.TokenHash)
+		assert.NotContains(t, request.TokenHash, token)
+		require.Len(t, env.mailer.sent(), 2)
+		assert.Equal(t, "Account recovery requested", env.mailer.sent()[1].Subject)
+
+		// the code can only be used once
+		_, _, err = env.svc.VerifyRecovery(ctx, *env.user.Mail, code)
+		requireAccountRecoveryErrorCode(t, err, error_code.InvalidAccountRecoveryCode)
+	})
+
+	t.Run("wrong codes drop the pending code", func(t *testing.T) {
+		t.Parallel()
+		env := newAccountRecoveryTestEnv(t, "log")
+		ctx := context.Background()
+		require.NoError(t, env.svc.StartRecovery(ctx, *env.user.Mail))
+		code := accountRecoveryCodePattern.FindString(env.mailer.sent()[0].Body)
+		wrong := "000000"
+		if code == wrong {
+			wrong = "111111"
+		}
+
+		for range accountRecoveryMaxCodeAttempts {
+			_, _, err := env.svc.VerifyRecovery(ctx, *env.user.Mail, wrong)
+			requireAccountRecoveryErrorCode(t, err, error_code.InvalidAccountRecoveryCode)
+		}
+		_, _, err := env.svc.VerifyRecovery(ctx, *env.user.Mail, code)
+		requireAccountRecoveryErrorCode(t, err, error_code.InvalidAccountRecoveryCode)
+	})
+
+	t.Run("one open request per account", func(t *testing.T) {
+		t.Parallel()
+		env := newAccountRecoveryTestEnv(t, "log")
+		ctx := context.Background()
+		require.NoError(t, env.svc.StartRecovery(ctx, *env.user.Mail))
+		code := accountRecoveryCodePattern.FindString(env.mailer.sent()[0].Body)
+
+		env.recoveryRepo.EXPECT().ListByUserID(gomock.Any(), env.user.ID).Return([]entity.AccountRecoveryRequestEntity{
+			{ID: "request-1", UserID: env.user.ID, Status: entity.AccountRecoveryStatusApproved},
+		}, nil)
+		_, _, err := env.svc.VerifyRecovery(ctx, *env.user.Mail, code)
+		requireAccountRecoveryErrorCode(t, err, error_code.AccountRecoveryAlreadyRequested)
+	})
+}
+
+func TestAccountRecoveryService_DecideAndCancel(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	admin := entity.UserIDEntity("admin-1")
+	pending := entity.AccountRecoveryRequestEntity{ID: "request-1", UserID: "user-1", Status: entity.AccountRecoveryStatusPending}
+
+	t.Run("approve notifies the account", func(t *testing.T) {
+		t.Parallel()
+		env := newAccountRecoveryTestEnv(t, "log")
+		env.recoveryRepo.EXPECT().GetByID(gomock.Any(), "request-1").Return(pending, true, nil)
+		env.recoveryRepo.EXPECT().UpdateStatus(gomock.Any(), "request-1",
+			[]entity.AccountRecoveryStatus{entity.AccountRecoveryStatusPending}, entity.AccountRecoveryStatusApproved, &admin).Return(true, nil)
+
+		require.NoError(t, env.svc.ApproveRequest(context.Background(), admin, "request-1"))
+		require.Len(t, env.mailer.sent(), 1)
+		assert.Equal(t, "Account recovery approved", env.mailer.sent()[0].Subject)
+	})
+
+	t.Run("an admin can not approve the own recovery", func(t *testing.T) {
+		t.Parallel()
+		env := newAccountRecoveryTestEnv(t, "log")
+		env.recoveryRepo.EXPECT().GetByID(gomock.Any(), "request-1").Return(pending, true, nil)
+		requireAccountRecoveryErrorCode(t, env.svc.ApproveRequest(context.Background(), "user-1", "request-1"), error_code.Forbidden)
+	})
+
+	t.Run("a decided request can not be rejected", func(t *testing.T) {
+		t.Parallel()
+		env := newAccountRecoveryTestEnv(t, "log")
+		approved := pending
+		approved.Status = entity.AccountRecoveryStatusApproved
+		env.recoveryRepo.EXPECT().GetByID(gomock.Any(), "request-1").Return(approved, true, nil)
+		requireAccountRecoveryErrorCode(t, env.svc.RejectRequest(context.Background(), admin, "request-1"), error_code.AccountRecoveryClosed)
+	})
+
+	t.Run("only the owner can cancel", func(t *testing.T) {
+		t.Parallel()
+		env := newAccountRecoveryTestEnv(t, "log")
+		env.recoveryRepo.EXPECT().GetByID(gomock.Any(), "request-1").Return(pending, true, nil).Times(2)
+		requireAccountRecoveryErrorCode(t, env.svc.CancelRequest(context.Background(), "user-2", "request-1"), error_code.AccountRecoveryNotFound)
+
+		owner := entity.UserIDEntity("user-1")
+		env.recoveryRepo.EXPECT().UpdateStatus(gomock.Any(), "request-1",
+			[]entity.AccountRecoveryStatus{entity.AccountRecoveryStatusPending, entity.AccountRecoveryStatusApproved},
+			entity.AccountRecoveryStatusCancelled, &owner).Return(true, nil)
+		require.NoError(t, env.svc.CancelRequest(context.Background(), owner, "request-1"))
+	})
+}
+
+func TestAccountRecoveryService_CompleteRecovery(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	const token = "recovery-token"
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	request := func(status entity.AccountRecoveryStatus, availableAt time.Time) entity.AccountRecoveryRequestEntity {
+		return entity.AccountRecoveryRequestEntity{
+			ID: "request-1", UserID: "user-1", Status: status, TokenHash: hashAccountRecoverySecret(token), AvailableAt: availableAt,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		request     entity.AccountRecoveryRequestEntity
+		token       string
+		wantErrCode *error_code.ErrorCode
+	}{
+		{name: "wrong token", request: request(entity.AccountRecoveryStatusApproved, now), token: "other", wantErrCode: &error_code.AccountRecoveryNotFound},
+		{name: "not approved", request: request(entity.AccountRecoveryStatusPending, now), token: token, wantErrCode: &error_code.AccountRecoveryNotApproved},
+		{name: "cancelled", request: request(entity.AccountRecoveryStatusCancelled, now), token: token, wantErrCode: &error_code.AccountRecoveryClosed},
+		{name: "delay not over", request: request(entity.AccountRecoveryStatusApproved, now.Add(time.Second)), token: token, wantErrCode: &error_code.AccountRecoveryNotYetAvailable},
+		{name: "completed", request: request(entity.AccountRecoveryStatusApproved, now), token: token},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			env := newAccountRecoveryTestEnv(t, "log")
+			env.recoveryRepo.EXPECT().GetByID(gomock.Any(), "request-1").Return(tt.request, true, nil)
+			if tt.wantErrCode == nil {
+				env.recoveryRepo.EXPECT().UpdateStatus(gomock.Any(), "request-1",
+					[]entity.AccountRecoveryStatus{entity.AccountRecoveryStatusApproved}, entity.AccountRecoveryStatusCompleted, nil).Return(true, nil)
+				env.twoFARepo.EXPECT().GetByUserID(gomock.Any(), env.user.ID).Return([]entity.TwoFAEntity{
+					{Type: entity.TwoFATypeTOTP}, {Type: entity.TwoFATypeWebAuthn},
+				}, nil)
+				env.twoFARepo.EXPECT().Delete(gomock.Any(), env.user.ID, entity.TwoFATypeTOTP).Return(nil)
+				env.twoFARepo.EXPECT().Delete(gomock.Any(), env.user.ID, entity.TwoFATypeWebAuthn).Return(nil)
+				env.twoFARepo.EXPECT().ClearRecoveryCode(gomock.Any(), env.user.ID).Return(nil)
+				env.userRepo.EXPECT().UpdatePassword(gomock.Any(), env.user.ID, "new-password").Return(nil)
+				env.accessTokenRepo.EXPECT().DeleteAllTokensByUserID(gomock.Any(), env.user.ID).Return(nil)
+				env.refreshTokenRepo.EXPECT().DeleteAllTokensByUserID(gomock.Any(), env.user.ID).Return(nil)
+			}
+
+			err := env.svc.CompleteRecovery(context.Background(), "request-1", tt.token, "new-password")
+			if tt.wantErrCode != nil {
+				requireAccountRecoveryErrorCode(t, err, *tt.wantErrCode)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, env.mailer.sent(), 1)
+			assert.Equal(t, "Account recovered", env.mailer.sent()[0].Subject)
+		})
+	}
+}
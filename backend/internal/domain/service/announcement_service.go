@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// announcementsCacheKey caches every announcement, the active ones are picked at read time
+// so an announcement starts and ends on time without invalidating the cache.
+const announcementsCacheKey = "announcements"
+
+const announcementMaxMessageLength = 2000
+
+func NewAnnouncementService(announcementRepo repository.IAnnouncementRepository, cache repository.ICache, clock client.IClock) *AnnouncementService {
+	return &AnnouncementService{announcementRepo: announcementRepo, cache: cache, clock: clock}
+}
+
+type AnnouncementService struct {
+	announcementRepo repository.IAnnouncementRepository
+	cache            repository.ICache
+	clock            client.IClock
+}
+
+// ActiveAnnouncements returns the announcements to show right now, newest first.
+func (s *AnnouncementService) ActiveAnnouncements(ctx context.Context) ([]entity.AnnouncementEntity, error) {
+	announcements, err := s.cachedAnnouncements(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	return lo.Filter(announcements, func(announcement entity.AnnouncementEntity, _ int) bool {
+		return announcement.IsActiveAt(now)
+	}), nil
+}
+
+// AllAnnouncements returns every announcement including scheduled and expired ones, for the admin.
+func (s *AnnouncementService) AllAnnouncements(ctx context.Context) ([]entity.AnnouncementEntity, error) {
+	announcements, err := s.announcementRepo.All(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get announcements")
+	}
+	return announcements, nil
+}
+
+func (s *AnnouncementService) CreateAnnouncement(
+	ctx context.Context,
+	actor entity.UserIDEntity,
+	message string,
+	severity entity.AnnouncementSeverity,
+	startsAt, endsAt *time.Time,
+	dismissible bool,
+) (entity.AnnouncementEntity, error) {
+	announcement := entity.NewAnnouncementEntityWithoutID(strings.TrimSpace(message), severity, startsAt, endsAt, dismissible, actor)
+	if err := validateAnnouncement(announcement); err != nil {
+		return entity.AnnouncementEntity{}, err
+	}
+
+	now := s.clock.Now()
+	announcement.CreatedAt = now
+	announcement.UpdatedAt = now
+	if err := s.announcementRepo.Create(ctx, announcement); err != nil {
+		return entity.AnnouncementEntity{}, errors.Wrap(err, "fail to create announcement")
+	}
+
+	return announcement, s.invalidateCache(ctx)
+}
+
+func (s *AnnouncementService) UpdateAnnouncement(
+	ctx context.Context,
+	id string,
+	message string,
+	severity entity.AnnouncementSeverity,
+	startsAt, endsAt *time.Time,
+	dismissible bool,
+) error {
+	announcement := entity.AnnouncementEntity{
+		ID:          id,
+		Message:     strings.TrimSpace(message),
+		Severity:    severity,
+		StartsAt:    startsAt,
+		EndsAt:      endsAt,
+		Dismissible: dismissible,
+		UpdatedAt:   s.clock.Now(),
+	}
+	if err := validateAnnouncement(announcement); err != nil {
+		return err
+	}
+
+	if err := s.announcementRepo.Update(ctx, announcement); err != nil {
+		if errors.Is(err, repository.ErrAnnouncementNotFound) {
+			return error_code.NewErrorWithErrorCodef(error_code.AnnouncementNotFound, "announcement %s not found", id)
+		}
+		return errors.Wrapf(err, "fail to update announcement %s", id)
+	}
+
+	return s.invalidateCache(ctx)
+}
+
+func (s *AnnouncementService) DeleteAnnouncement(ctx context.Context, id string) error {
+	if err := s.announcementRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrAnnouncementNotFound) {
+			return error_code.NewErrorWithErrorCodef(error_code.AnnouncementNotFound, "announcement %s not found", id)
+		}
+		return errors.Wrapf(err, "fail to delete announcement %s", id)
+	}
+
+	return s.invalidateCache(ctx)
+}
+
+func (s *AnnouncementService) cachedAnnouncements(ctx context.Context) ([]entity.AnnouncementEntity, error) {
+	cached, found, err := s.cache.Get(ctx, announcementsCacheKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get announcements from cache")
+	}
+	if found {
+		var announcements []entity.AnnouncementEntity
+		if err := json.Unmarshal([]byte(cached), &announcements); err != nil {
+			return nil, errors.Wrap(err, "fail to decode cached announcements")
+		}
+		return announcements, nil
+	}
+
+	announcements, err := s.announcementRepo.All(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get announcements")
+	}
+
+	encoded, err := json.Marshal(announcements)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to encode announcements for cache")
+	}
+	if err := s.cache.Set(ctx, announcementsCacheKey, string(encoded)); err != nil {
+		return nil, errors.Wrap(err, "fail to cache announcements")
+	}
+	return announcements, nil
+}
+
+func (s *AnnouncementService) invalidateCache(ctx context.Context) error {
+	if err := s.cache.Delete(ctx, announcementsCacheKey); err != nil {
+		return errors.Wrap(err, "fail to delete announcements cache")
+	}
+	return nil
+}
+
+func validateAnnouncement(announcement entity.AnnouncementEntity) error {
+	if announcement.Message == "" {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidAnnouncement, "message must not be empty")
+	}
+	if len([]rune(announcement.Message)) > announcementMaxMessageLength {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidAnnouncement, "message must be at most %d characters", announcementMaxMessageLength)
+	}
+	switch announcement.Severity {
+	case entity.AnnouncementSeverityInfo, entity.AnnouncementSeverityWarning, entity.AnnouncementSeverityCritical:
+	default:
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidAnnouncement, "unknown severity %q", announcement.Severity)
+	}
+	if announcement.StartsAt != nil && announcement.EndsAt != nil && !announcement.EndsAt.After(*announcement.StartsAt) {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidAnnouncement, "ends_at must be after starts_at")
+	}
+	return nil
+}
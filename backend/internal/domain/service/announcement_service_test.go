@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+func TestAnnouncementService_ActiveAnnouncements(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	announcements := []entity.AnnouncementEntity{
+		{ID: "ann-open", Message: "open", Severity: entity.AnnouncementSeverityInfo},
+		{ID: "ann-running", Message: "running", Severity: entity.AnnouncementSeverityWarning, StartsAt: &past, EndsAt: &future},
+		{ID: "ann-scheduled", Message: "scheduled", Severity: entity.AnnouncementSeverityInfo, StartsAt: &future},
+		{ID: "ann-expired", Message: "expired", Severity: entity.AnnouncementSeverityCritical, EndsAt: &past},
+		{ID: "ann-ends-now", Message: "ends now", Severity: entity.AnnouncementSeverityInfo, StartsAt: &past, EndsAt: &now},
+	}
+	encoded, err := json.Marshal(announcements)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		setup func(repo *mockgen.MockIAnnouncementRepository, cache *mockgen.MockICache)
+	}{
+		{
+			name: "cache miss loads from repository and fills the cache",
+			setup: func(repo *mockgen.MockIAnnouncementRepository, cache *mockgen.MockICache) {
+				cache.EXPECT().Get(gomock.Any(), announcementsCacheKey).Return("", false, nil)
+				repo.EXPECT().All(gomock.Any()).Return(announcements, nil)
+				cache.EXPECT().Set(gomock.Any(), announcementsCacheKey, string(encoded)).Return(nil)
+			},
+		},
+		{
+			name: "cache hit skips the repository",
+			setup: func(repo *mockgen.MockIAnnouncementRepository, cache *mockgen.MockICache) {
+				cache.EXPECT().Get(gomock.Any(), announcementsCacheKey).Return(string(encoded), true, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			repo := mockgen.NewMockIAnnouncementRepository(ctrl)
+			cache := mockgen.NewMockICache(ctrl)
+			tt.setup(repo, cache)
+
+			svc := NewAnnouncementService(repo, cache, fixtures.NewFakeClock(now))
+			active, err := svc.ActiveAnnouncements(context.Background())
+			require.NoError(t, err)
+			require.Len(t, active, 2)
+			require.Equal(t, "ann-open", active[0].ID)
+			require.Equal(t, "ann-running", active[1].ID)
+		})
+	}
+}
+
+func TestAnnouncementService_CreateAnnouncement(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		message  string
+		severity entity.AnnouncementSeverity
+		startsAt *time.Time
+		endsAt   *time.Time
+		wantCode *error_code.ErrorCode
+	}{
+		{name: "valid", message: "  maintenance tonight  ", severity: entity.AnnouncementSeverityWarning, startsAt: &now, endsAt: &later},
+		{name: "blank message", message: "   ", severity: entity.AnnouncementSeverityInfo, wantCode: &error_code.InvalidAnnouncement},
+		{name: "too long message", message: strings.Repeat("a", announcementMaxMessageLength+1), severity: entity.AnnouncementSeverityInfo, wantCode: &error_code.InvalidAnnouncement},
+		{name: "unknown severity", message: "hello", severity: "fatal", wantCode: &error_code.InvalidAnnouncement},
+		{name: "ends before starts", message: "hello", severity: entity.AnnouncementSeverityInfo, startsAt: &later, endsAt: &now, wantCode: &error_code.InvalidAnnouncement},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			repo := mockgen.NewMockIAnnouncementRepository(ctrl)
+			cache := mockgen.NewMockICache(ctrl)
+			if tt.wantCode == nil {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, announcement entity.AnnouncementEntity) error {
+					require.Equal(t, "maintenance tonight", announcement.Message)
+					require.Equal(t, entity.UserIDEntity("admin-1"), announcement.CreatedBy)
+					return nil
+				})
+				cache.EXPECT().Delete(gomock.Any(), announcementsCacheKey).Return(nil)
+			}
+
+			svc := NewAnnouncementService(repo, cache, fixtures.NewFakeClock(time.Now()))
+			announcement, err := svc.CreateAnnouncement(context.Background(), "admin-1", tt.message, tt.severity, tt.startsAt, tt.endsAt, true)
+			if tt.wantCode != nil {
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, *tt.wantCode, codeErr.ErrorCode)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(announcement.ID, "ann-"))
+		})
+	}
+}
+
+func TestAnnouncementService_NotFound(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	repo := mockgen.NewMockIAnnouncementRepository(ctrl)
+	cache := mockgen.NewMockICache(ctrl)
+	repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.Wrap(repository.ErrAnnouncementNotFound, "missing"))
+	repo.EXPECT().Delete(gomock.Any(), "ann-missing").Return(errors.Wrap(repository.ErrAnnouncementNotFound, "missing"))
+
+	svc := NewAnnouncementService(repo, cache, fixtures.NewFakeClock(time.Now()))
+
+	var codeErr error_code.ErrorWithErrorCode
+	err := svc.UpdateAnnouncement(context.Background(), "ann-missing", "hello", entity.AnnouncementSeverityInfo, nil, nil, false)
+	require.ErrorAs(t, err, &codeErr)
+	require.Equal(t, error_code.AnnouncementNotFound, codeErr.ErrorCode)
+
+	err = svc.DeleteAnnouncement(context.Background(), "ann-missing")
+	require.ErrorAs(t, err, &codeErr)
+	require.Equal(t, error_code.AnnouncementNotFound, codeErr.ErrorCode)
+}
+
+func TestAnnouncementService_CacheInvalidation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	clock := fixtures.NewFakeClock(now)
+	cache := fixtures.NewFakeCache(clock)
+	repo := mockgen.NewMockIAnnouncementRepository(ctrl)
+	svc := NewAnnouncementService(repo, cache, clock)
+
+	scheduled := entity.AnnouncementEntity{ID: "ann-scheduled", Message: "release", Severity: entity.AnnouncementSeverityInfo, StartsAt: &later}
+	created := entity.AnnouncementEntity{}
+	gomock.InOrder(
+		repo.EXPECT().All(ctx).Return([]entity.AnnouncementEntity{scheduled}, nil),
+		repo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, announcement entity.AnnouncementEntity) error {
+			created = announcement
+			return nil
+		}),
+		repo.EXPECT().All(ctx).DoAndReturn(func(context.Context) ([]entity.AnnouncementEntity, error) {
+			return []entity.AnnouncementEntity{created, scheduled}, nil
+		}),
+	)
+
+	// the second read is served from the cache
+	for range 2 {
+		active, err := svc.ActiveAnnouncements(ctx)
+		require.NoError(t, err)
+		require.Empty(t, active)
+	}
+
+	// the cached list is filtered at read time, so a scheduled announcement starts without a write
+	clock.Set(later)
+	active, err := svc.ActiveAnnouncements(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+
+	// a write drops the cache and the next read sees it
+	_, err = svc.CreateAnnouncement(ctx, "admin-1", "maintenance", entity.AnnouncementSeverityWarning, nil, nil, true)
+	require.NoError(t, err)
+	require.Empty(t, cache.Keys())
+	active, err = svc.ActiveAnnouncements(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	auditLogDefaultLimit = 50
+	auditLogMaxLimit     = 200
+
+	// auditPayloadMaxLength bounds the stored payload summary, bulk requests are not worth keeping in full
+	auditPayloadMaxLength = 2048
+)
+
+func NewAuditLogService(auditLogRepo repository.IAuditLogRepository, siemExportService *SIEMExportService, clock client.IClock) *AuditLogService {
+	return &AuditLogService{auditLogRepo: auditLogRepo, siemExportService: siemExportService, clock: clock}
+}
+
+type AuditLogService struct {
+	auditLogRepo      repository.IAuditLogRepository
+	siemExportService *SIEMExportService
+	clock             client.IClock
+}
+
+// Record stores an audit record and hands it to the siem export, the payload is replaced by its redacted summary.
+func (s *AuditLogService) Record(ctx context.Context, auditLog entity.AuditLogEntity) error {
+	auditLog.Payload = summarizeAuditPayload(auditLog.Payload)
+	auditLog.CreatedAt = s.clock.Now()
+	if err := s.auditLogRepo.Create(ctx, auditLog); err != nil {
+		return errors.Wrap(err, "fail to record audit log")
+	}
+	s.siemExportService.Export(ctx, entity.SIEMEventCategoryAudit, auditLog)
+	return nil
+}
+
+// ExportAuthEvent hands a request to an auth route to the siem export, it is not stored in the audit log.
+func (s *AuditLogService) ExportAuthEvent(ctx context.Context, auditLog entity.AuditLogEntity) {
+	auditLog.Payload = summarizeAuditPayload(auditLog.Payload)
+	auditLog.CreatedAt = s.clock.Now()
+	s.siemExportService.Export(ctx, entity.SIEMEventCategoryAuth, auditLog)
+}
+
+// AuthEventsExported reports whether requests to the auth routes are exported, the auth event middleware is only attached then.
+func (s *AuditLogService) AuthEventsExported() bool {
+	return s.siemExportService.Enabled()
+}
+
+// List returns a page of audit records, newest first, and the total count of matching records.
+func (s *AuditLogService) List(ctx context.Context, filter entity.AuditLogFilter) ([]entity.AuditLogEntity, int, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = auditLogDefaultLimit
+	}
+	filter.Limit = min(filter.Limit, auditLogMaxLimit)
+	filter.Offset = max(filter.Offset, 0)
+
+	auditLogs, total, err := s.auditLogRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "fail to list audit logs")
+	}
+	return auditLogs, total, nil
+}
+
+// summarizeAuditPayload redacts sensitive fields of a json body and truncates it, a non json body is only described by its size.
+func summarizeAuditPayload(payload string) string {
+	return RedactPayload(payload, auditPayloadMaxLength)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+func TestAuditLogService_Record(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		payload     string
+		repoErr     error
+		wantPayload string
+		wantErrSub  string
+	}{
+		{
+			name:        "empty body stays empty",
+			payload:     "",
+			wantPayload: "",
+		},
+		{
+			name:        "sensitive fields are redacted at any depth",
+			payload:     `{"username":"alice","password":"hunter2","nested":[{"client_secret":"s","note":"keep"}],"AccessToken":"t"}`,
+			wantPayload: `{"AccessToken":"[REDACTED]","nested":[{"client_secret":"[REDACTED]","note":"keep"}],"password":"[REDACTED]","username":"alice"}`,
+		},
+		{
+			name:        "non json body is only described",
+			payload:     "plain text",
+			wantPayload: "<10 bytes non-json body>",
+		},
+		{
+			name:       "repository error is wrapped",
+			payload:    `{"value":"true"}`,
+			repoErr:    errors.New("db offline"),
+			wantErrSub: "fail to record audit log",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+			auditLogRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, auditLog entity.AuditLogEntity) error {
+				if tt.repoErr == nil {
+					require.Equal(t, tt.wantPayload, auditLog.Payload)
+					require.Equal(t, now, auditLog.CreatedAt)
+				}
+				return tt.repoErr
+			})
+
+			svc := NewAuditLogService(auditLogRepo, NewSIEMExportService(nil, nil, config.Config{}), fixtures.NewFakeClock(now))
+			auditLog := entity.NewAuditLogEntityWithoutID("admin-1", http.MethodPost, "/api/v1/admin/announcements", "/api/v1/admin/announcements", tt.payload, http.StatusOK, "req-1", "127.0.0.1")
+			err := svc.Record(context.Background(), auditLog)
+			if tt.wantErrSub != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAuditLogService_RecordTruncatesLongPayload(t *testing.T) {
+	t.Parallel()
+
+	summary := summarizeAuditPayload(`{"message":"` + strings.Repeat("あ", auditPayloadMaxLength) + `"}`)
+	require.True(t, strings.HasSuffix(summary, "...(truncated)"))
+	require.LessOrEqual(t, len(summary), auditPayloadMaxLength+len("...(truncated)"))
+	require.True(t, strings.ToValidUTF8(summary, "") == summary, "truncation must not split a rune")
+}
+
+func TestAuditLogService_List(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		filter     entity.AuditLogFilter
+		wantFilter entity.AuditLogFilter
+	}{
+		{
+			name:       "limit defaults when omitted",
+			filter:     entity.AuditLogFilter{ActorID: "admin-1"},
+			wantFilter: entity.AuditLogFilter{ActorID: "admin-1", Limit: auditLogDefaultLimit},
+		},
+		{
+			name:       "limit and offset are clamped",
+			filter:     entity.AuditLogFilter{Limit: 10000, Offset: -5},
+			wantFilter: entity.AuditLogFilter{Limit: auditLogMaxLimit},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+			auditLogRepo.EXPECT().List(gomock.Any(), tt.wantFilter).Return([]entity.AuditLogEntity{{ID: "audit-1"}}, 7, nil)
+
+			svc := NewAuditLogService(auditLogRepo, NewSIEMExportService(nil, nil, config.Config{}), fixtures.NewFakeClock(time.Now()))
+			auditLogs, total, err := svc.List(context.Background(), tt.filter)
+			require.NoError(t, err)
+			require.Len(t, auditLogs, 1)
+			require.Equal(t, 7, total)
+		})
+	}
+}
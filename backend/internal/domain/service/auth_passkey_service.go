@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/core/logger"
 	"ya-tool-craft/internal/domain/entity"
@@ -14,6 +15,7 @@ import (
 	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 )
 
 const passkeyChallengePrefix = "passkey:challenge:"
@@ -24,12 +26,14 @@ func NewAuthPasskeyService(
 	refreshTokenRepo repository.IAuthRefreshTokenRepository,
 	passkeyRepo repository.IPasskeyRepository,
 	cacheRepo repository.ICache,
+	challengeLimiter *PasskeyChallengeLimiter,
+	ttls repository.ISessionTTLProvider,
 	config config.Config,
 ) (*AuthPasskeyService, error) {
 	wconfig := &webauthn.Config{
 		RPDisplayName: config.WebAuthnRPName,
 		RPID:          config.WebAuthnRPID,
-		RPOrigins:     []string{config.WebAuthnRPOrigin},
+		RPOrigins:     config.WebAuthnAllowedOrigins(),
 	}
 
 	w, err := webauthn.New(wconfig)
@@ -43,6 +47,8 @@ func NewAuthPasskeyService(
 		refreshTokenRepo: refreshTokenRepo,
 		passkeyRepo:      passkeyRepo,
 		cacheRepo:        cacheRepo,
+		challengeLimiter: challengeLimiter,
+		ttls:             ttls,
 		webauthn:         w,
 		config:           config,
 	}, nil
@@ -54,6 +60,8 @@ type AuthPasskeyService struct {
 	refreshTokenRepo repository.IAuthRefreshTokenRepository
 	passkeyRepo      repository.IPasskeyRepository
 	cacheRepo        repository.ICache
+	challengeLimiter *PasskeyChallengeLimiter
+	ttls             repository.ISessionTTLProvider
 	webauthn         *webauthn.WebAuthn
 	config           config.Config
 }
@@ -124,7 +132,7 @@ func (s *AuthPasskeyService) RegistrationChallenge(ctx context.Context, userID e
 	// Use userID as part of key to allow only one active registration flow per user.
 	// If user requests a new challenge, the previous one will be overwritten intentionally.
 	cacheKey := fmt.Sprintf("%s%s:register", passkeyChallengePrefix, userID)
-	if err := s.cacheRepo.SetWithTTL(ctx, cacheKey, string(sessionBytes), uint64(s.config.WebAuthnChallengeTTL)); err != nil {
+	if err := s.cacheRepo.SetWithTTL(ctx, cacheKey, string(sessionBytes), uint64(s.ttls.WebAuthnChallengeTTL(ctx))); err != nil {
 		return nil, errors.Wrap(err, "failed to store challenge in cache")
 	}
 
@@ -133,6 +141,11 @@ func (s *AuthPasskeyService) RegistrationChallenge(ctx context.Context, userID e
 
 // FinishRegistration verifies the passkey registration response and stores the new credential.
 func (s *AuthPasskeyService) FinishRegistration(ctx context.Context, userID entity.UserIDEntity, req entity.PasskeyRegisterRequestEntity, deviceName *string) (entity.PasskeyEntity, error) {
+	deviceName, err := normalizePasskeyDeviceName(deviceName)
+	if err != nil {
+		return entity.PasskeyEntity{}, err
+	}
+
 	user, exists, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return entity.PasskeyEntity{}, errors.Wrap(err, "failed to get user")
@@ -197,12 +210,6 @@ func (s *AuthPasskeyService) FinishRegistration(ctx context.Context, userID enti
 		credential.Flags.UserPresent,
 	)
 
-	if _, exists, err := s.passkeyRepo.GetByCredentialID(ctx, credential.ID); err != nil {
-		return entity.PasskeyEntity{}, errors.Wrap(err, "failed to check existing passkey credential")
-	} else if exists {
-		return entity.PasskeyEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "credential already registered")
-	}
-
 	var transports *string
 	if len(credential.Transport) > 0 {
 		items := make([]string, len(credential.Transport))
@@ -225,7 +232,11 @@ func (s *AuthPasskeyService) FinishRegistration(ctx context.Context, userID enti
 		boolPtr(credential.Flags.BackupState),
 	)
 
+	// the unique credential_id index rejects a credential registered before, or concurrently by another request
 	if err := s.passkeyRepo.Create(ctx, passkey); err != nil {
+		if errors.Is(err, repository.ErrPasskeyCredentialExists) {
+			return entity.PasskeyEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "credential already registered")
+		}
 		return entity.PasskeyEntity{}, errors.Wrap(err, "failed to store passkey")
 	}
 
@@ -236,27 +247,34 @@ func (s *AuthPasskeyService) FinishRegistration(ctx context.Context, userID enti
 	return passkey, nil
 }
 
-// LoginChallenge generates challenge for passkey login (discoverable credentials)
-func (s *AuthPasskeyService) LoginChallenge(ctx context.Context) (*protocol.CredentialAssertion, error) {
+// LoginChallenge generates challenge for passkey login (discoverable credentials), with the rate limit of the client ip
+func (s *AuthPasskeyService) LoginChallenge(ctx context.Context) (*protocol.CredentialAssertion, entity.RateLimitEntity, error) {
 	options, session, err := s.webauthn.BeginDiscoverableLogin(
 		webauthn.WithUserVerification(protocol.VerificationPreferred),
 	)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to begin discoverable login")
+		return nil, entity.RateLimitEntity{}, errors.Wrap(err, "failed to begin discoverable login")
 	}
 
 	sessionBytes, err := json.Marshal(session)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to marshal session")
+		return nil, entity.RateLimitEntity{}, errors.Wrap(err, "failed to marshal session")
+	}
+
+	// anyone can ask for a challenge, every one is a session in the cache until it expires
+	rateLimit, err := s.challengeLimiter.Acquire(ctx, session.Challenge)
+	if err != nil {
+		return nil, entity.RateLimitEntity{}, err
 	}
 
 	// Use challenge as cache key since we don't have userID for discoverable login
 	cacheKey := fmt.Sprintf("%s%s:login", passkeyChallengePrefix, session.Challenge)
-	if err := s.cacheRepo.SetWithTTL(ctx, cacheKey, string(sessionBytes), uint64(s.config.WebAuthnChallengeTTL)); err != nil {
-		return nil, errors.Wrap(err, "failed to store challenge in cache")
+	if err := s.cacheRepo.SetWithTTL(ctx, cacheKey, string(sessionBytes), uint64(s.ttls.WebAuthnChallengeTTL(ctx))); err != nil {
+		s.challengeLimiter.Release(session.Challenge, false)
+		return nil, entity.RateLimitEntity{}, errors.Wrap(err, "failed to store challenge in cache")
 	}
 
-	return options, nil
+	return options, rateLimit, nil
 }
 
 // GetPasskeys retrieves all passkeys for a user
@@ -268,6 +286,46 @@ func (s *AuthPasskeyService) GetPasskeys(ctx context.Context, userID entity.User
 	return passkeys, nil
 }
 
+// UpdatePasskey renames a passkey of the user and returns it, an empty name clears the name
+func (s *AuthPasskeyService) UpdatePasskey(ctx context.Context, userID entity.UserIDEntity, passkeyID int64, deviceName string) (entity.PasskeyEntity, error) {
+	name, err := normalizePasskeyDeviceName(&deviceName)
+	if err != nil {
+		return entity.PasskeyEntity{}, err
+	}
+
+	if err := s.passkeyRepo.UpdateName(ctx, passkeyID, userID, name); err != nil {
+		if errors.Is(err, repository.ErrPasskeyNotFound) {
+			return entity.PasskeyEntity{}, error_code.NewErrorWithErrorCodef(error_code.PasskeyNotFound, "passkey %d not found", passkeyID)
+		}
+		return entity.PasskeyEntity{}, errors.Wrap(err, "failed to update passkey name")
+	}
+
+	passkeys, err := s.passkeyRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return entity.PasskeyEntity{}, errors.Wrap(err, "failed to get passkeys")
+	}
+	passkey, ok := lo.Find(passkeys, func(pk entity.PasskeyEntity) bool { return pk.ID == passkeyID })
+	if !ok {
+		return entity.PasskeyEntity{}, error_code.NewErrorWithErrorCodef(error_code.PasskeyNotFound, "passkey %d not found", passkeyID)
+	}
+	return passkey, nil
+}
+
+// normalizePasskeyDeviceName trims the device name given to a passkey, a blank name becomes nil
+func normalizePasskeyDeviceName(deviceName *string) (*string, error) {
+	if deviceName == nil {
+		return nil, nil
+	}
+	trimmed := strings.TrimSpace(*deviceName)
+	if trimmed == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(trimmed) > entity.PasskeyDeviceNameMaxLength {
+		return nil, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "device name must be at most %d characters", entity.PasskeyDeviceNameMaxLength)
+	}
+	return &trimmed, nil
+}
+
 // DeletePasskey deletes a passkey for a user by passkey ID
 func (s *AuthPasskeyService) DeletePasskey(ctx context.Context, userID entity.UserIDEntity, passkeyID int64) error {
 	if err := s.passkeyRepo.Delete(ctx, passkeyID, userID); err != nil {
@@ -276,6 +334,32 @@ func (s *AuthPasskeyService) DeletePasskey(ctx context.Context, userID entity.Us
 	return nil
 }
 
+// RemovePasskeyByAdmin removes a passkey of the user, for a passkey that is lost or compromised
+func (s *AuthPasskeyService) RemovePasskeyByAdmin(ctx context.Context, userID entity.UserIDEntity, passkeyID int64) error {
+	_, exists, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get user by id")
+	}
+	if !exists {
+		return error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user not found")
+	}
+
+	passkeys, err := s.passkeyRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get passkeys")
+	}
+	if !lo.ContainsBy(passkeys, func(pk entity.PasskeyEntity) bool { return pk.ID == passkeyID }) {
+		return error_code.NewErrorWithErrorCodef(error_code.PasskeyNotFound, "passkey %d not found", passkeyID)
+	}
+
+	if err := s.passkeyRepo.Delete(ctx, passkeyID, userID); err != nil {
+		return errors.Wrap(err, "failed to delete passkey")
+	}
+
+	logger.Infof(ctx, "passkey %d removed by admin: userid: %s", passkeyID, userID)
+	return nil
+}
+
 // FinishLogin verifies the passkey login response and returns tokens
 func (s *AuthPasskeyService) FinishLogin(ctx context.Context, req entity.PasskeyLoginRequestEntity) (entity.AccessToken, entity.RefreshToken, error) {
 	parsedResponse, err := req.Parse()
@@ -308,7 +392,7 @@ func (s *AuthPasskeyService) FinishLogin(ctx context.Context, req entity.Passkey
 	}
 
 	// Variables to capture user info from the handler
-	var foundUserID entity.UserIDEntity
+	var foundUser entity.UserEntity
 	var foundPasskey entity.PasskeyEntity
 
 	// User handler for discoverable login - looks up user by userHandle (which is userID)
@@ -331,30 +415,7 @@ func (s *AuthPasskeyService) FinishLogin(ctx context.Context, req entity.Passkey
 
 		var credentials []webauthn.Credential
 		for _, pk := range passkeys {
-			var transports []protocol.AuthenticatorTransport
-			if pk.Transports != nil {
-				for _, t := range strings.Split(*pk.Transports, ",") {
-					transports = append(transports, protocol.AuthenticatorTransport(t))
-				}
-			}
-
-			credential := webauthn.Credential{
-				ID:              pk.CredentialID,
-				PublicKey:       pk.PublicKey,
-				AttestationType: "",
-				Transport:       transports,
-				Authenticator: webauthn.Authenticator{
-					AAGUID:    pk.AAGUID,
-					SignCount: uint32(pk.SignCount),
-				},
-			}
-
-			if pk.BackupEligible != nil {
-				credential.Flags.BackupEligible = *pk.BackupEligible
-			}
-			if pk.BackupState != nil {
-				credential.Flags.BackupState = *pk.BackupState
-			}
+			credential := passkeyCredential(pk)
 
 			// Check if this is the credential being used
 			if string(pk.CredentialID) == string(rawID) {
@@ -370,7 +431,7 @@ func (s *AuthPasskeyService) FinishLogin(ctx context.Context, req entity.Passkey
 			credentials = append(credentials, credential)
 		}
 
-		foundUserID = userID
+		foundUser = user
 
 		return &webauthnUser{
 			id:          []byte(userID),
@@ -390,6 +451,10 @@ func (s *AuthPasskeyService) FinishLogin(ctx context.Context, req entity.Passkey
 	if foundPasskey.ID == 0 {
 		return entity.AccessToken{}, entity.RefreshToken{}, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "passkey credential not found")
 	}
+	if err := checkUserEnabled(foundUser); err != nil {
+		return entity.AccessToken{}, entity.RefreshToken{}, err
+	}
+	foundUserID := foundUser.ID
 
 	// Update sign count
 	if err := s.passkeyRepo.UpdateSignCount(ctx, foundPasskey.ID, int64(credential.Authenticator.SignCount)); err != nil {
@@ -405,6 +470,11 @@ func (s *AuthPasskeyService) FinishLogin(ctx context.Context, req entity.Passkey
 	if err := s.cacheRepo.Delete(ctx, cacheKey); err != nil {
 		return entity.AccessToken{}, entity.RefreshToken{}, errors.Wrap(err, "failed to delete passkey login session")
 	}
+	s.challengeLimiter.Release(challenge, true)
+
+	if err := s.userRepo.RecordLogin(ctx, foundUserID); err != nil {
+		return entity.AccessToken{}, entity.RefreshToken{}, errors.Wrap(err, "failed to record login")
+	}
 
 	// Generate tokens
 	refreshToken, err := s.refreshTokenRepo.IssueRefreshToken(ctx, foundUserID)
@@ -420,6 +490,152 @@ func (s *AuthPasskeyService) FinishLogin(ctx context.Context, req entity.Passkey
 	return accessToken, refreshToken, nil
 }
 
+// SecondFactorChallenge generates the challenge of a passkey assertion used as second factor of a login.
+// Unlike LoginChallenge it only allows the passkeys of the user, the session is kept under sessionKey (the 2FA token).
+func (s *AuthPasskeyService) SecondFactorChallenge(ctx context.Context, userID entity.UserIDEntity, sessionKey string) (*protocol.CredentialAssertion, error) {
+	wuser, _, err := s.secondFactorUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	options, session, err := s.webauthn.BeginLogin(wuser, webauthn.WithUserVerification(protocol.VerificationPreferred))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin passkey second factor login")
+	}
+
+	sessionBytes, err := json.Marshal(session)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal session")
+	}
+
+	cacheKey := fmt.Sprintf("%s%s:2fa", passkeyChallengePrefix, sessionKey)
+	if err := s.cacheRepo.SetWithTTL(ctx, cacheKey, string(sessionBytes), uint64(s.ttls.WebAuthnChallengeTTL(ctx))); err != nil {
+		return nil, errors.Wrap(err, "failed to store challenge in cache")
+	}
+
+	return options, nil
+}
+
+// VerifySecondFactor validates the passkey assertion answering SecondFactorChallenge, it issues no tokens.
+func (s *AuthPasskeyService) VerifySecondFactor(ctx context.Context, userID entity.UserIDEntity, sessionKey string, req entity.PasskeyLoginRequestEntity) error {
+	parsedResponse, err := req.Parse()
+	if err != nil {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "%s", err.Error())
+	}
+
+	cacheKey := fmt.Sprintf("%s%s:2fa", passkeyChallengePrefix, sessionKey)
+	sessionJSON, ok, err := s.cacheRepo.Get(ctx, cacheKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to get passkey second factor session")
+	}
+	if !ok {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "passkey second factor session not found or expired")
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal([]byte(sessionJSON), &session); err != nil {
+		return errors.Wrap(err, "failed to unmarshal passkey second factor session")
+	}
+
+	wuser, passkeys, err := s.secondFactorUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	// passkeys registered before the backup flags were stored take them from the assertion, like the discoverable login does
+	flags := parsedResponse.Response.AuthenticatorData.Flags
+	for i, pk := range passkeys {
+		if string(pk.CredentialID) != string(parsedResponse.RawID) {
+			continue
+		}
+		if pk.BackupEligible == nil {
+			wuser.credentials[i].Flags.BackupEligible = flags.HasBackupEligible()
+		}
+		if pk.BackupState == nil {
+			wuser.credentials[i].Flags.BackupState = flags.HasBackupState()
+		}
+	}
+
+	credential, err := s.webauthn.ValidateLogin(wuser, session, parsedResponse)
+	if err != nil {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "%s", err.Error())
+	}
+
+	for _, pk := range passkeys {
+		if string(pk.CredentialID) != string(credential.ID) {
+			continue
+		}
+		if err := s.passkeyRepo.UpdateSignCount(ctx, pk.ID, int64(credential.Authenticator.SignCount)); err != nil {
+			return errors.Wrap(err, "failed to update sign count")
+		}
+		if err := s.passkeyRepo.UpdateLastUsedAt(ctx, pk.ID); err != nil {
+			return errors.Wrap(err, "failed to update last used at")
+		}
+	}
+
+	if err := s.cacheRepo.Delete(ctx, cacheKey); err != nil {
+		return errors.Wrap(err, "failed to delete passkey second factor session")
+	}
+	return nil
+}
+
+// secondFactorUser loads the user with all its passkeys as webauthn credentials.
+func (s *AuthPasskeyService) secondFactorUser(ctx context.Context, userID entity.UserIDEntity) (*webauthnUser, []entity.PasskeyEntity, error) {
+	user, exists, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get user")
+	}
+	if !exists {
+		return nil, nil, error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user not found")
+	}
+
+	passkeys, err := s.passkeyRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get user passkeys")
+	}
+	if len(passkeys) == 0 {
+		return nil, nil, error_code.NewErrorWithErrorCodef(error_code.TwoFaMethodNotEnabled, "user has no passkey")
+	}
+
+	credentials := make([]webauthn.Credential, len(passkeys))
+	for i, pk := range passkeys {
+		credentials[i] = passkeyCredential(pk)
+	}
+
+	return &webauthnUser{
+		id:          []byte(userID),
+		name:        user.Name,
+		displayName: user.Name,
+		credentials: credentials,
+	}, passkeys, nil
+}
+
+// passkeyCredential converts a stored passkey to a webauthn credential.
+func passkeyCredential(pk entity.PasskeyEntity) webauthn.Credential {
+	var transports []protocol.AuthenticatorTransport
+	if pk.Transports != nil {
+		for _, t := range strings.Split(*pk.Transports, ",") {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+	}
+
+	credential := webauthn.Credential{
+		ID:        pk.CredentialID,
+		PublicKey: pk.PublicKey,
+		Transport: transports,
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    pk.AAGUID,
+			SignCount: uint32(pk.SignCount),
+		},
+	}
+	if pk.BackupEligible != nil {
+		credential.Flags.BackupEligible = *pk.BackupEligible
+	}
+	if pk.BackupState != nil {
+		credential.Flags.BackupState = *pk.BackupState
+	}
+	return credential
+}
+
 func boolPtr(value bool) *bool {
 	return &value
 }
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-webauthn/webauthn/protocol"
 	"github.com/go-webauthn/webauthn/webauthn"
@@ -15,8 +17,10 @@ import (
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/core/logger"
 	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/error_code"
 	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
 )
 
 const (
@@ -27,7 +31,7 @@ const (
 var testConfig = config.Config{
 	WebAuthnRPName:       "TestRP",
 	WebAuthnRPID:         "localhost",
-	WebAuthnRPOrigin:     "http://localhost:8080",
+	WebAuthnRPOrigins:    []string{"http://localhost:8080"},
 	WebAuthnChallengeTTL: 300,
 }
 
@@ -46,7 +50,7 @@ func newTestPasskeyService(ctrl *gomock.Controller) (
 	passkeyRepo := mockgen.NewMockIPasskeyRepository(ctrl)
 	cacheRepo := mockgen.NewMockICache(ctrl)
 
-	svc, err := NewAuthPasskeyService(userRepo, accessRepo, refreshRepo, passkeyRepo, cacheRepo, testConfig)
+	svc, err := NewAuthPasskeyService(userRepo, accessRepo, refreshRepo, passkeyRepo, cacheRepo, NewPasskeyChallengeLimiter(fixtures.NewFakeClock(time.Now()), fixtures.NewConfigSessionTTLs(testConfig), testConfig), fixtures.NewConfigSessionTTLs(testConfig), testConfig)
 	if err != nil {
 		panic(fmt.Sprintf("failed to create test passkey service: %v", err))
 	}
@@ -55,7 +59,7 @@ func newTestPasskeyService(ctrl *gomock.Controller) (
 }
 
 func testUser() entity.UserEntity {
-	return entity.UserEntity{ID: testUserID, Name: testUserName}
+	return fixtures.NewTestUser().WithID(testUserID).WithName(testUserName).Build()
 }
 
 // --- NewAuthPasskeyService ---
@@ -75,6 +79,7 @@ func TestNewAuthPasskeyService(t *testing.T) {
 			mockgen.NewMockIAuthRefreshTokenRepository(ctrl),
 			mockgen.NewMockIPasskeyRepository(ctrl),
 			mockgen.NewMockICache(ctrl),
+			NewPasskeyChallengeLimiter(fixtures.NewFakeClock(time.Now()), fixtures.NewConfigSessionTTLs(testConfig), testConfig), fixtures.NewConfigSessionTTLs(testConfig),
 			testConfig,
 		)
 		require.NoError(t, err)
@@ -89,7 +94,7 @@ func TestNewAuthPasskeyService(t *testing.T) {
 		customConfig := config.Config{
 			WebAuthnRPName:       "CustomRP",
 			WebAuthnRPID:         "example.com",
-			WebAuthnRPOrigin:     "https://example.com",
+			WebAuthnRPOrigins:    []string{"https://example.com"},
 			WebAuthnChallengeTTL: 600,
 		}
 
@@ -99,6 +104,7 @@ func TestNewAuthPasskeyService(t *testing.T) {
 			mockgen.NewMockIAuthRefreshTokenRepository(ctrl),
 			mockgen.NewMockIPasskeyRepository(ctrl),
 			mockgen.NewMockICache(ctrl),
+			NewPasskeyChallengeLimiter(fixtures.NewFakeClock(time.Now()), fixtures.NewConfigSessionTTLs(customConfig), customConfig), fixtures.NewConfigSessionTTLs(customConfig),
 			customConfig,
 		)
 		require.NoError(t, err)
@@ -509,7 +515,7 @@ func TestAuthPasskeyService_LoginChallenge(t *testing.T) {
 			svc, _, _, _, _, cacheRepo := newTestPasskeyService(ctrl)
 			tt.setupMocks(ctx, cacheRepo)
 
-			options, err := svc.LoginChallenge(ctx)
+			options, _, err := svc.LoginChallenge(ctx)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -544,7 +550,7 @@ func TestAuthPasskeyService_LoginChallenge_CacheKeyContainsChallenge(t *testing.
 			return nil
 		})
 
-	_, err := svc.LoginChallenge(ctx)
+	_, _, err := svc.LoginChallenge(ctx)
 	require.NoError(t, err)
 
 	// Key should be in format passkey:challenge:<challenge>:login
@@ -570,7 +576,7 @@ func TestAuthPasskeyService_LoginChallenge_SessionStoredAsValidJSON(t *testing.T
 			return nil
 		})
 
-	_, err := svc.LoginChallenge(ctx)
+	_, _, err := svc.LoginChallenge(ctx)
 	require.NoError(t, err)
 
 	var session webauthn.SessionData
@@ -777,6 +783,95 @@ func TestAuthPasskeyService_DeletePasskey(t *testing.T) {
 	}
 }
 
+func TestAuthPasskeyService_UpdatePasskey(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	renamed := "Work Laptop"
+	tests := []struct {
+		name       string
+		deviceName string
+		setupMocks func(ctx context.Context, passkeyRepo *mockgen.MockIPasskeyRepository)
+		wantName   *string
+		wantCode   *error_code.ErrorCode
+		wantErrSub string
+	}{
+		{
+			name:       "renames with the name trimmed",
+			deviceName: "  Work Laptop ",
+			setupMocks: func(ctx context.Context, passkeyRepo *mockgen.MockIPasskeyRepository) {
+				passkeyRepo.EXPECT().UpdateName(ctx, int64(42), testUserID, &renamed).Return(nil)
+				passkeyRepo.EXPECT().GetByUserID(ctx, testUserID).Return([]entity.PasskeyEntity{
+					{ID: 7, UserID: testUserID},
+					{ID: 42, UserID: testUserID, DeviceName: &renamed},
+				}, nil)
+			},
+			wantName: &renamed,
+		},
+		{
+			name:       "blank name clears it",
+			deviceName: "   ",
+			setupMocks: func(ctx context.Context, passkeyRepo *mockgen.MockIPasskeyRepository) {
+				passkeyRepo.EXPECT().UpdateName(ctx, int64(42), testUserID, (*string)(nil)).Return(nil)
+				passkeyRepo.EXPECT().GetByUserID(ctx, testUserID).Return([]entity.PasskeyEntity{{ID: 42, UserID: testUserID}}, nil)
+			},
+		},
+		{
+			name:       "name too long",
+			deviceName: strings.Repeat("a", entity.PasskeyDeviceNameMaxLength+1),
+			setupMocks: func(ctx context.Context, passkeyRepo *mockgen.MockIPasskeyRepository) {},
+			wantCode:   &error_code.InvalidRequestParameters,
+		},
+		{
+			name:       "passkey of another user",
+			deviceName: renamed,
+			setupMocks: func(ctx context.Context, passkeyRepo *mockgen.MockIPasskeyRepository) {
+				passkeyRepo.EXPECT().UpdateName(ctx, int64(42), testUserID, &renamed).Return(fmt.Errorf("passkey 42: %w", repository.ErrPasskeyNotFound))
+			},
+			wantCode: &error_code.PasskeyNotFound,
+		},
+		{
+			name:       "repo error is wrapped",
+			deviceName: renamed,
+			setupMocks: func(ctx context.Context, passkeyRepo *mockgen.MockIPasskeyRepository) {
+				passkeyRepo.EXPECT().UpdateName(ctx, int64(42), testUserID, &renamed).Return(errors.New("db error"))
+			},
+			wantErrSub: "failed to update passkey name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			svc, _, _, _, passkeyRepo, _ := newTestPasskeyService(ctrl)
+			tt.setupMocks(ctx, passkeyRepo)
+
+			passkey, err := svc.UpdatePasskey(ctx, testUserID, 42, tt.deviceName)
+
+			if tt.wantCode != nil {
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, tt.wantCode.Code, codeErr.ErrorCode.Code)
+				return
+			}
+			if tt.wantErrSub != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, int64(42), passkey.ID)
+			require.Equal(t, tt.wantName, passkey.DeviceName)
+		})
+	}
+}
+
 // --- Security Tests ---
 
 func TestAuthPasskeyService_Security_CrossUserRegistrationIsolation(t *testing.T) {
@@ -896,7 +991,7 @@ func TestAuthPasskeyService_Security_LoginChallengeUniquePerCall(t *testing.T) {
 		}).Times(3)
 
 	for i := 0; i < 3; i++ {
-		_, err := svc.LoginChallenge(ctx)
+		_, _, err := svc.LoginChallenge(ctx)
 		require.NoError(t, err)
 	}
 
@@ -971,7 +1066,7 @@ func TestAuthPasskeyService_Security_RegistrationChallenge_UsesConfiguredTTL(t *
 	customConfig := config.Config{
 		WebAuthnRPName:       "TestRP",
 		WebAuthnRPID:         "localhost",
-		WebAuthnRPOrigin:     "http://localhost:8080",
+		WebAuthnRPOrigins:    []string{"http://localhost:8080"},
 		WebAuthnChallengeTTL: 600,
 	}
 
@@ -981,7 +1076,7 @@ func TestAuthPasskeyService_Security_RegistrationChallenge_UsesConfiguredTTL(t *
 	passkeyRepo := mockgen.NewMockIPasskeyRepository(ctrl)
 	cacheRepo := mockgen.NewMockICache(ctrl)
 
-	svc, err := NewAuthPasskeyService(userRepo, accessRepo, refreshRepo, passkeyRepo, cacheRepo, customConfig)
+	svc, err := NewAuthPasskeyService(userRepo, accessRepo, refreshRepo, passkeyRepo, cacheRepo, NewPasskeyChallengeLimiter(fixtures.NewFakeClock(time.Now()), fixtures.NewConfigSessionTTLs(customConfig), customConfig), fixtures.NewConfigSessionTTLs(customConfig), customConfig)
 	require.NoError(t, err)
 
 	userRepo.EXPECT().GetByID(ctx, testUserID).Return(testUser(), true, nil)
@@ -1005,7 +1100,7 @@ func TestAuthPasskeyService_Security_LoginChallenge_UsesConfiguredTTL(t *testing
 	customConfig := config.Config{
 		WebAuthnRPName:       "TestRP",
 		WebAuthnRPID:         "localhost",
-		WebAuthnRPOrigin:     "http://localhost:8080",
+		WebAuthnRPOrigins:    []string{"http://localhost:8080"},
 		WebAuthnChallengeTTL: 120,
 	}
 
@@ -1015,12 +1110,12 @@ func TestAuthPasskeyService_Security_LoginChallenge_UsesConfiguredTTL(t *testing
 	passkeyRepo := mockgen.NewMockIPasskeyRepository(ctrl)
 	cacheRepo := mockgen.NewMockICache(ctrl)
 
-	svc, err := NewAuthPasskeyService(userRepo, accessRepo, refreshRepo, passkeyRepo, cacheRepo, customConfig)
+	svc, err := NewAuthPasskeyService(userRepo, accessRepo, refreshRepo, passkeyRepo, cacheRepo, NewPasskeyChallengeLimiter(fixtures.NewFakeClock(time.Now()), fixtures.NewConfigSessionTTLs(customConfig), customConfig), fixtures.NewConfigSessionTTLs(customConfig), customConfig)
 	require.NoError(t, err)
 
 	cacheRepo.EXPECT().SetWithTTL(ctx, gomock.Any(), gomock.Any(), uint64(120)).Return(nil)
 
-	_, err = svc.LoginChallenge(ctx)
+	_, _, err = svc.LoginChallenge(ctx)
 	require.NoError(t, err)
 }
 
@@ -1083,7 +1178,7 @@ func TestAuthPasskeyService_Security_LoginChallenge_RequiresUserVerification(t *
 
 	cacheRepo.EXPECT().SetWithTTL(ctx, gomock.Any(), gomock.Any(), uint64(300)).Return(nil)
 
-	options, err := svc.LoginChallenge(ctx)
+	options, _, err := svc.LoginChallenge(ctx)
 	require.NoError(t, err)
 
 	// Verify user verification is set to preferred
@@ -1106,3 +1201,66 @@ func TestBoolPtr(t *testing.T) {
 	// Verify they point to different memory
 	require.NotSame(t, trueVal, falseVal)
 }
+
+// --- RemovePasskeyByAdmin ---
+
+func TestAuthPasskeyService_RemovePasskeyByAdmin(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	tests := []struct {
+		name       string
+		passkeyID  int64
+		setupMocks func(ctx context.Context, userRepo *mockgen.MockIUserRepository, passkeyRepo *mockgen.MockIPasskeyRepository)
+		wantCode   *error_code.ErrorCode
+	}{
+		{
+			name:      "user not found returns error code",
+			passkeyID: 1,
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, passkeyRepo *mockgen.MockIPasskeyRepository) {
+				userRepo.EXPECT().GetByID(ctx, testUserID).Return(entity.UserEntity{}, false, nil)
+			},
+			wantCode: &error_code.UserNotFound,
+		},
+		{
+			name:      "passkey of another user is not found",
+			passkeyID: 2,
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, passkeyRepo *mockgen.MockIPasskeyRepository) {
+				userRepo.EXPECT().GetByID(ctx, testUserID).Return(testUser(), true, nil)
+				passkeyRepo.EXPECT().GetByUserID(ctx, testUserID).Return([]entity.PasskeyEntity{{ID: 1, UserID: testUserID}}, nil)
+			},
+			wantCode: &error_code.PasskeyNotFound,
+		},
+		{
+			name:      "passkey is deleted",
+			passkeyID: 1,
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, passkeyRepo *mockgen.MockIPasskeyRepository) {
+				userRepo.EXPECT().GetByID(ctx, testUserID).Return(testUser(), true, nil)
+				passkeyRepo.EXPECT().GetByUserID(ctx, testUserID).Return([]entity.PasskeyEntity{{ID: 1, UserID: testUserID}}, nil)
+				passkeyRepo.EXPECT().Delete(ctx, int64(1), testUserID).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			svc, userRepo, _, _, passkeyRepo, _ := newTestPasskeyService(ctrl)
+			tt.setupMocks(ctx, userRepo, passkeyRepo)
+
+			err := svc.RemovePasskeyByAdmin(ctx, testUserID, tt.passkeyID)
+
+			if tt.wantCode == nil {
+				require.NoError(t, err)
+				return
+			}
+			var codeErr error_code.ErrorWithErrorCode
+			require.ErrorAs(t, err, &codeErr)
+			require.Equal(t, tt.wantCode.Code, codeErr.ErrorCode.Code)
+		})
+	}
+}
@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/requestid"
 	"ya-tool-craft/internal/domain/client"
 	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/domain/repository"
@@ -22,6 +26,9 @@ func NewAuthService(
 	googleClient client.IGoogleAuthClient,
 	cfg config.Config,
 	twoFAService *TwoFAService,
+	settingsService *SystemSettingsService,
+	auditLogService *AuditLogService,
+	mailer client.IMailer,
 ) *AuthService {
 	return &AuthService{
 		accessTokenRepo:  accessTokenRepo,
@@ -31,6 +38,9 @@ func NewAuthService(
 		googleClient:     googleClient,
 		config:           cfg,
 		twoFAService:     twoFAService,
+		settingsService:  settingsService,
+		auditLogService:  auditLogService,
+		mailer:           mailer,
 	}
 }
 
@@ -42,6 +52,10 @@ type AuthService struct {
 	googleClient     client.IGoogleAuthClient
 	config           config.Config
 	twoFAService     *TwoFAService
+	settingsService  *SystemSettingsService
+	// auditLogService and mailer report a new SSO binding, it is another way into the account
+	auditLogService *AuditLogService
+	mailer          client.IMailer
 }
 
 type AuthLoginResult struct {
@@ -50,8 +64,12 @@ type AuthLoginResult struct {
 	AccessToken  entity.AccessToken
 }
 
+// Login checks the password of the user with the username, or with the email when the username does not match one.
 func (s *AuthService) Login(ctx context.Context, username, password string) (result AuthLoginResult, twoFAToken *string, credentialValid bool, err error) {
 	user, ok, err := s.userRepo.ValidateCredentialsByUsername(ctx, username, password)
+	if !ok && err == nil && strings.Contains(username, "@") {
+		user, ok, err = s.userRepo.ValidateCredentialsByEmail(ctx, normalizeUserEmail(username), password)
+	}
 	if !ok {
 		logger.Infof(ctx, "failed login attempt: username: %s", username)
 		return AuthLoginResult{}, nil, false, nil
@@ -60,6 +78,9 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (res
 		return AuthLoginResult{}, nil, false, errors.Wrapf(err, "fail to check username and password")
 	}
 	logger.Infof(ctx, "user login: username: %s userid: %s", username, user.ID)
+	if err := checkUserEnabled(user); err != nil {
+		return AuthLoginResult{}, nil, true, err
+	}
 
 	// Check if 2FA is required
 	twoFAToken, err = s.twoFAService.Get2FAToken(ctx, user.ID)
@@ -73,6 +94,9 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (res
 	}
 
 	// No 2FA required, issue tokens normally
+	if err := s.userRepo.RecordLogin(ctx, user.ID); err != nil {
+		return AuthLoginResult{}, nil, false, errors.Wrapf(err, "fail to record login for user: %s", user.ID)
+	}
 	refreshToken, err := s.refreshTokenRepo.IssueRefreshToken(ctx, user.ID)
 	if err != nil {
 		return AuthLoginResult{}, nil, false, errors.Wrapf(err, "fail to issue refresh token")
@@ -91,7 +115,11 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (res
 }
 
 func (s *AuthService) LoginOrCreateUserBySSO(ctx context.Context, provider string, providerOauthToken string) (result AuthLoginResult, twoFAToken *string, err error) {
-	providerUserID, providerUsername, providerEmail, err := s.getSSOProviderUserInfo(provider, providerOauthToken)
+	if err := s.checkSSOEnabled(ctx, provider); err != nil {
+		return AuthLoginResult{}, nil, err
+	}
+
+	providerUserID, providerUsername, providerEmail, providerAccessToken, err := s.getSSOProviderUserInfo(provider, providerOauthToken)
 	if err != nil {
 		return AuthLoginResult{}, nil, err
 	}
@@ -102,8 +130,17 @@ func (s *AuthService) LoginOrCreateUserBySSO(ctx context.Context, provider strin
 	}
 	// if user does not exist, create a new user
 	if !userExists {
-		if !s.config.ENABLE_USER_REGISTRATION {
-			return AuthLoginResult{}, nil, error_code.NewErrorWithErrorCodef(error_code.UserRegistrationIsNotEnabled, "user registration is not enabled, please set env: ENABLE_USER_REGISTRATION")
+		// a demo instance lets only its existing accounts in. The settings close registration in demo mode too, this
+		// holds even when a cache shared with instances outside demo mode hands out other settings.
+		if s.config.DemoMode {
+			return AuthLoginResult{}, nil, error_code.NewErrorWithErrorCodef(error_code.DemoModeReadonly, "signing up with SSO is disabled in demo mode")
+		}
+		settings, err := s.settingsService.Settings(ctx)
+		if err != nil {
+			return AuthLoginResult{}, nil, errors.Wrapf(err, "fail to get system settings")
+		}
+		if !settings.EnableUserRegistration {
+			return AuthLoginResult{}, nil, error_code.NewErrorWithErrorCodef(error_code.UserRegistrationIsNotEnabled, "user registration is not enabled")
 		}
 		// generate unique username: providerUsername_randomString
 		randomSuffix, err := gonanoid.New(8)
@@ -117,6 +154,10 @@ func (s *AuthService) LoginOrCreateUserBySSO(ctx context.Context, provider strin
 			return AuthLoginResult{}, nil, errors.Wrapf(err, "fail to create user by SSO")
 		}
 	}
+	if err := checkUserEnabled(user); err != nil {
+		return AuthLoginResult{}, nil, err
+	}
+	s.keepSSOAccessToken(ctx, user.ID, provider, providerAccessToken)
 
 	// Check if 2FA is required
 	twoFAToken, err = s.twoFAService.Get2FAToken(ctx, user.ID)
@@ -130,6 +171,9 @@ func (s *AuthService) LoginOrCreateUserBySSO(ctx context.Context, provider strin
 	}
 
 	// No 2FA required, issue tokens normally
+	if err := s.userRepo.RecordLogin(ctx, user.ID); err != nil {
+		return AuthLoginResult{}, nil, errors.Wrapf(err, "fail to record login for user: %s", user.ID)
+	}
 	refreshToken, err := s.refreshTokenRepo.IssueRefreshToken(ctx, user.ID)
 	if err != nil {
 		return AuthLoginResult{}, nil, errors.Wrapf(err, "fail to issue refresh token")
@@ -146,9 +190,11 @@ func (s *AuthService) LoginOrCreateUserBySSO(ctx context.Context, provider strin
 
 }
 
-func (s *AuthService) AddSSOBindingForUser(ctx context.Context, userID entity.UserIDEntity, provider string, providerOauthToken string) error {
+// AddSSOBindingForUser binds the provider account of the oauth code to the user. A binding is a lasting way into the
+// account, so a user with 2FA confirms it with a 2FA code first, and the user is notified once it is added.
+func (s *AuthService) AddSSOBindingForUser(ctx context.Context, userID entity.UserIDEntity, provider string, providerOauthToken string, twoFACode string) error {
 	// check if user exists first
-	_, userExists, err := s.userRepo.GetByID(ctx, userID)
+	user, userExists, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return errors.Wrapf(err, "fail to get user by id")
 	}
@@ -156,7 +202,16 @@ func (s *AuthService) AddSSOBindingForUser(ctx context.Context, userID entity.Us
 		return error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user not exists")
 	}
 
-	providerUserID, providerUsername, providerEmail, err := s.getSSOProviderUserInfo(provider, providerOauthToken)
+	if err := s.checkSSOEnabled(ctx, provider); err != nil {
+		return err
+	}
+
+	// checked before the oauth code is exchanged, the code can be used once only
+	if err := s.twoFAService.RequireStepUpCode(ctx, userID, twoFACode); err != nil {
+		return err
+	}
+
+	providerUserID, providerUsername, providerEmail, providerAccessToken, err := s.getSSOProviderUserInfo(provider, providerOauthToken)
 	if err != nil {
 		return err
 	}
@@ -187,34 +242,170 @@ func (s *AuthService) AddSSOBindingForUser(ctx context.Context, userID entity.Us
 	if err := s.userRepo.AddUserSSOBinding(ctx, userID, provider, providerUserID, &providerUsername, providerEmail); err != nil {
 		return errors.Wrapf(err, "fail to add user sso binding for provider: %s", provider)
 	}
+	s.keepSSOAccessToken(ctx, userID, provider, providerAccessToken)
+	s.notifySSOBindingAdded(ctx, user, provider, providerUsername)
 
 	return nil
 
 }
 
+// ssoBindingAddedAuditEvent is the route of the audit event of a new sso binding
+const ssoBindingAddedAuditEvent = "sso_binding.added"
+
+type ssoBindingAddedEvent struct {
+	Provider         string              `json:"provider"`
+	ProviderUsername string              `json:"provider_username"`
+	UserID           entity.UserIDEntity `json:"user_id"`
+}
+
+// notifySSOBindingAdded records the new binding as audit event and emails the account, failures are logged and do not
+// fail the binding that is already stored.
+func (s *AuthService) notifySSOBindingAdded(ctx context.Context, user entity.UserEntity, provider string, providerUsername string) {
+	details, err := json.Marshal(ssoBindingAddedEvent{Provider: provider, ProviderUsername: providerUsername, UserID: user.ID})
+	if err != nil {
+		logger.Errorf(ctx, "Failed to marshal sso binding event: %v", err)
+	} else {
+		auditLog := entity.NewAuditEventEntityWithoutID(
+			user.ID,
+			ssoBindingAddedAuditEvent,
+			string(details),
+			requestid.GetRequestID(ctx),
+			requestid.GetClientIP(ctx),
+		)
+		if err := s.auditLogService.Record(ctx, auditLog); err != nil {
+			logger.Errorf(ctx, "Failed to record sso binding of user %s: %v", user.ID, err)
+		}
+	}
+
+	if user.Mail == nil || *user.Mail == "" {
+		logger.Warnf(ctx, "SSO binding notice not sent, user %s has no email", user.ID)
+		return
+	}
+	body := fmt.Sprintf("The %s account %s was linked to the account %s, it can sign in to the account from now on.\n\n"+
+		"If you did not do it, remove the %s login, change your password and contact an administrator right away.\n",
+		provider, providerUsername, user.Name, provider)
+	if err := s.mailer.Send(ctx, entity.MailEntity{To: *user.Mail, Subject: "New sign-in method added", Body: body}); err != nil {
+		logger.Errorf(ctx, "Failed to send sso binding notice to user %s: %v", user.ID, err)
+	}
+}
+
 func (s *AuthService) ValidateAccessToken(ctx context.Context, token string) (entity.AccessToken, bool, error) {
 	accessToken, valid, err := s.accessTokenRepo.ValidateAccessToken(ctx, token)
 	if err != nil {
 		return entity.AccessToken{}, false, errors.Wrapf(err, "fail to validate access token")
 	}
+	// a token issued to an oidc client only reaches the oidc userinfo endpoint, never the api of ToolBake
+	if valid && accessToken.ClientID != "" {
+		logger.Warnf(ctx, "Rejected access token of oidc client %s for user %s", accessToken.ClientID, accessToken.UserID)
+		return entity.AccessToken{}, false, nil
+	}
 	return accessToken, valid, nil
 }
 
-func (s *AuthService) IssueNewAccessToken(ctx context.Context, refreshToken string) (entity.AccessToken, bool, error) {
+// IssueNewAccessToken issues an access token for a valid refresh token. With REFRESH_TOKEN_ROTATION the refresh
+// token is replaced on use and the new one is returned, otherwise the returned refresh token is nil.
+// Presenting a token that was already rotated signs out every session rotated from the same login.
+func (s *AuthService) IssueNewAccessToken(ctx context.Context, refreshToken string) (entity.AccessToken, *entity.RefreshToken, bool, error) {
+	refresh, rotated, valid, err := s.RefreshSession(ctx, refreshToken, "")
+	if err != nil || !valid {
+		return entity.AccessToken{}, nil, false, err
+	}
+
+	accessToken, err := s.accessTokenRepo.IssueAccessToken(ctx, refresh.UserID, refresh.TokenHash)
+	if err != nil {
+		return entity.AccessToken{}, nil, false, errors.Wrapf(err, "fail to issue access token")
+	}
+
+	return accessToken, rotated, true, nil
+}
+
+// RefreshSession validates a refresh token before a new access token is issued for it, the first-party refresh and
+// the oidc refresh grant both go through it. The token must have been issued to clientID, empty for the tokens of
+// ToolBake itself. With REFRESH_TOKEN_ROTATION the token is replaced by a new one of its family, returned as rotated,
+// and a rotated token presented again revokes the whole family. The returned refresh token is the one the new access
+// token belongs to.
+func (s *AuthService) RefreshSession(ctx context.Context, refreshToken string, clientID string) (entity.RefreshToken, *entity.RefreshToken, bool, error) {
 	refresh, valid, err := s.refreshTokenRepo.ValidateRefreshToken(ctx, refreshToken)
 	if err != nil {
-		return entity.AccessToken{}, false, errors.Wrapf(err, "fail to validate refresh token")
+		return entity.RefreshToken{}, nil, false, errors.Wrapf(err, "fail to validate refresh token")
 	}
 	if !valid {
-		return entity.AccessToken{}, false, nil
+		if s.config.RefreshTokenRotation {
+			if err := s.revokeReusedRefreshToken(ctx, refreshToken); err != nil {
+				return entity.RefreshToken{}, nil, false, err
+			}
+		}
+		return entity.RefreshToken{}, nil, false, nil
+	}
+	if refresh.ClientID != clientID {
+		logger.Warnf(ctx, "Rejected refresh token of client %q presented by client %q for user %s", refresh.ClientID, clientID, refresh.UserID)
+		return entity.RefreshToken{}, nil, false, nil
+	}
+	if !s.config.RefreshTokenRotation {
+		return refresh, nil, true, nil
 	}
 
-	accessToken, err := s.accessTokenRepo.IssueAccessToken(ctx, refresh.UserID, refresh.TokenHash)
+	rotated, ok, err := s.refreshTokenRepo.RotateRefreshToken(ctx, refresh)
+	if err != nil {
+		return entity.RefreshToken{}, nil, false, errors.Wrapf(err, "fail to rotate refresh token")
+	}
+	if !ok {
+		// another request rotated the token since it was validated, the token was used twice
+		if err := s.revokeReusedRefreshToken(ctx, refreshToken); err != nil {
+			return entity.RefreshToken{}, nil, false, err
+		}
+		return entity.RefreshToken{}, nil, false, nil
+	}
+	return rotated, &rotated, true, nil
+}
+
+// refreshTokenReusedAuditEvent is the route of the audit event of a rotated refresh token presented again
+const refreshTokenReusedAuditEvent = "refresh_token.reused"
+
+type refreshTokenReusedEvent struct {
+	UserID   entity.UserIDEntity `json:"user_id"`
+	FamilyID string              `json:"family_id"`
+	Revoked  int                 `json:"revoked"`
+}
+
+// revokeReusedRefreshToken handles a refresh token that is not valid anymore. A token that was replaced by a rotation
+// is only presented again when it was copied, so every token of its family and their access tokens are revoked.
+func (s *AuthService) revokeReusedRefreshToken(ctx context.Context, refreshToken string) error {
+	reused, found, err := s.refreshTokenRepo.FindRotatedRefreshToken(ctx, refreshToken)
 	if err != nil {
-		return entity.AccessToken{}, false, errors.Wrapf(err, "fail to issue access token")
+		return errors.Wrapf(err, "fail to look up rotated refresh token")
+	}
+	if !found {
+		return nil
 	}
 
-	return accessToken, true, nil
+	revokedHashes, err := s.refreshTokenRepo.DeleteTokenFamily(ctx, reused.UserID, reused.FamilyID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to revoke refresh token family of user %s", reused.UserID)
+	}
+	for _, hash := range revokedHashes {
+		if err := s.accessTokenRepo.DeleteTokensByRefreshTokenHash(ctx, hash); err != nil {
+			return errors.Wrapf(err, "fail to revoke access tokens of user %s", reused.UserID)
+		}
+	}
+	logger.Warnf(ctx, "Rotated refresh token of user %s was reused, revoked %d tokens of its family", reused.UserID, len(revokedHashes))
+
+	details, err := json.Marshal(refreshTokenReusedEvent{UserID: reused.UserID, FamilyID: reused.FamilyID, Revoked: len(revokedHashes)})
+	if err != nil {
+		logger.Errorf(ctx, "Failed to marshal refresh token reuse event: %v", err)
+		return nil
+	}
+	auditLog := entity.NewAuditEventEntityWithoutID(
+		reused.UserID,
+		refreshTokenReusedAuditEvent,
+		string(details),
+		requestid.GetRequestID(ctx),
+		requestid.GetClientIP(ctx),
+	)
+	if err := s.auditLogService.Record(ctx, auditLog); err != nil {
+		logger.Errorf(ctx, "Failed to record refresh token reuse of user %s: %v", reused.UserID, err)
+	}
+	return nil
 }
 
 func (s *AuthService) Logout(ctx context.Context, token string) error {
@@ -237,6 +428,40 @@ func (s *AuthService) Logout(ctx context.Context, token string) error {
 	return nil
 }
 
+// ActiveSessions returns the signed in sessions of the user, one per refresh token, the newest first.
+func (s *AuthService) ActiveSessions(ctx context.Context, userID entity.UserIDEntity) ([]entity.RefreshToken, error) {
+	sessions, err := s.refreshTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to list active sessions")
+	}
+	slices.SortFunc(sessions, func(a, b entity.RefreshToken) int {
+		return b.IssueAt.Compare(a.IssueAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession signs a session of the user out, the session is identified by the hash of its refresh token. The
+// refresh token is deleted and the access tokens issued for it stop working right away.
+func (s *AuthService) RevokeSession(ctx context.Context, userID entity.UserIDEntity, sessionID string) error {
+	session, valid, err := s.refreshTokenRepo.ValidateRefreshTokenHash(ctx, sessionID)
+	if err != nil {
+		return errors.Wrap(err, "fail to get session")
+	}
+	// a session of another user is answered the same as a missing one
+	if !valid || session.UserID != userID {
+		return error_code.NewErrorWithErrorCodef(error_code.SessionNotFound, "session %s not found for user %s", sessionID, userID)
+	}
+
+	if err := s.refreshTokenRepo.DeleteRefreshTokenByHash(ctx, sessionID); err != nil {
+		return errors.Wrap(err, "fail to delete refresh token")
+	}
+	if err := s.accessTokenRepo.DeleteTokensByRefreshTokenHash(ctx, sessionID); err != nil {
+		return errors.Wrap(err, "fail to revoke session access tokens")
+	}
+	logger.Infof(ctx, "session revoked: userid: %s", userID)
+	return nil
+}
+
 func (s *AuthService) GetUserSSOBindings(ctx context.Context, userID entity.UserIDEntity) ([]entity.UserSSOEntity, error) {
 	bindings, err := s.userRepo.GetUserSSOBindings(ctx, userID)
 	if err != nil {
@@ -263,34 +488,58 @@ func (s *AuthService) DeleteUserSSOBinding(ctx context.Context, userID entity.Us
 	return nil
 }
 
-func (s *AuthService) getSSOProviderUserInfo(provider string, providerOauthToken string) (providerUserID string, providerUsername string, providerEmail *string, err error) {
+// getSSOProviderUserInfo also returns the provider api token, it is only kept for github where it is used by the github import
+func (s *AuthService) getSSOProviderUserInfo(provider string, providerOauthToken string) (providerUserID string, providerUsername string, providerEmail *string, providerAccessToken string, err error) {
 	switch provider {
 	case "github":
 		accessToken, err := s.githubClient.OauthTokenToAccessToken(providerOauthToken)
 		if err != nil {
-			return "", "", nil, errors.Wrapf(err, "fail to exchange oauth token to access token")
+			return "", "", nil, "", errors.Wrapf(err, "fail to exchange oauth token to access token")
 		}
 		githubUserInfo, err := s.githubClient.GetUserInfo(accessToken)
 		if err != nil {
-			return "", "", nil, errors.Wrapf(err, "fail to get github user info by acccess token")
+			return "", "", nil, "", errors.Wrapf(err, "fail to get github user info by acccess token")
 		}
 		// int64 to string
-		return fmt.Sprintf("%d", githubUserInfo.ID), githubUserInfo.Login, githubUserInfo.Email, nil
+		return fmt.Sprintf("%d", githubUserInfo.ID), githubUserInfo.Login, githubUserInfo.Email, accessToken, nil
 	case "google":
 		accessToken, err := s.googleClient.OauthCodeToAccessToken(providerOauthToken)
 		if err != nil {
-			return "", "", nil, errors.Wrapf(err, "fail to exchange oauth code to access token")
+			return "", "", nil, "", errors.Wrapf(err, "fail to exchange oauth code to access token")
 		}
 		googleUserInfo, err := s.googleClient.GetUserInfo(accessToken)
 		if err != nil {
-			return "", "", nil, errors.Wrapf(err, "fail to get google user info by access token")
+			return "", "", nil, "", errors.Wrapf(err, "fail to get google user info by access token")
 		}
 		var email *string
 		if googleUserInfo.Email != "" {
 			email = &googleUserInfo.Email
 		}
-		return googleUserInfo.ID, googleUserInfo.Name, email, nil
+		return googleUserInfo.ID, googleUserInfo.Name, email, "", nil
 	default:
-		return "", "", nil, errors.Errorf("unsupported SSO provider: %s", provider)
+		return "", "", nil, "", errors.Errorf("unsupported SSO provider: %s", provider)
+	}
+}
+
+// keepSSOAccessToken stores the provider api token on the binding, the latest login wins.
+// The login itself does not depend on it, a failure only makes the github import ask to bind again.
+func (s *AuthService) keepSSOAccessToken(ctx context.Context, userID entity.UserIDEntity, provider string, providerAccessToken string) {
+	if providerAccessToken == "" {
+		return
+	}
+	if err := s.userRepo.SetUserSSOAccessToken(ctx, userID, provider, providerAccessToken); err != nil {
+		logger.Warnf(ctx, "fail to keep %s access token for user %s: %v", provider, userID, err)
 	}
 }
+
+// checkSSOEnabled rejects providers that are switched off in the system settings.
+func (s *AuthService) checkSSOEnabled(ctx context.Context, provider string) error {
+	enabled, err := s.settingsService.SSOEnabled(ctx, provider)
+	if err != nil {
+		return errors.Wrapf(err, "fail to check if sso provider %s is enabled", provider)
+	}
+	if !enabled {
+		return error_code.NewErrorWithErrorCodef(error_code.SSOProviderIsNotEnabled, "sso provider %s is not enabled", provider)
+	}
+	return nil
+}
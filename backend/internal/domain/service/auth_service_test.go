@@ -15,6 +15,7 @@ import (
 	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/error_code"
 	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
 	"ya-tool-craft/internal/utils"
 )
 
@@ -37,6 +38,10 @@ func (f *fakeGithubAuthClient) GetUserInfo(accessToken string) (entity.GithubUse
 	return entity.GithubUserInfoEntity{}, nil
 }
 
+func (f *fakeGithubAuthClient) Ping(ctx context.Context) error {
+	return nil
+}
+
 type fakeGoogleAuthClient struct {
 	oauthCodeToAccessTokenFunc func(oauthCode string) (string, error)
 	getUserInfoFunc            func(accessToken string) (entity.GoogleUserInfoEntity, error)
@@ -56,6 +61,10 @@ func (f *fakeGoogleAuthClient) GetUserInfo(accessToken string) (entity.GoogleUse
 	return entity.GoogleUserInfoEntity{}, nil
 }
 
+func (f *fakeGoogleAuthClient) Ping(ctx context.Context) error {
+	return nil
+}
+
 // newTestAuthService creates an AuthService with all mocked dependencies.
 func newTestAuthService(ctrl *gomock.Controller) (
 	*AuthService,
@@ -71,8 +80,9 @@ func newTestAuthService(ctrl *gomock.Controller) (
 	twoFARepo := mockgen.NewMockIAuth2FARepository(ctrl)
 	cacheRepo := mockgen.NewMockICache(ctrl)
 
-	twoFAService, _ := NewTwoFaService(twoFARepo, userRepo, accessRepo, refreshRepo, cacheRepo, config.Config{})
-	svc := NewAuthService(accessRepo, refreshRepo, userRepo, nil, nil, config.Config{ENABLE_USER_REGISTRATION: true}, twoFAService)
+	twoFAService, _ := NewTwoFaService(twoFARepo, userRepo, accessRepo, refreshRepo, cacheRepo, nil, nil, config.Config{})
+	cfg := config.Config{ENABLE_USER_REGISTRATION: true}
+	svc := NewAuthService(accessRepo, refreshRepo, userRepo, nil, nil, cfg, twoFAService, newTestSystemSettingsService(ctrl, cfg), nil, nil)
 
 	return svc, accessRepo, refreshRepo, userRepo, twoFARepo, cacheRepo
 }
@@ -111,8 +121,17 @@ func newTestAuthServiceWithSSOClientsAndConfig(
 	twoFARepo := mockgen.NewMockIAuth2FARepository(ctrl)
 	cacheRepo := mockgen.NewMockICache(ctrl)
 
-	twoFAService, _ := NewTwoFaService(twoFARepo, userRepo, accessRepo, refreshRepo, cacheRepo, config.Config{})
-	svc := NewAuthService(accessRepo, refreshRepo, userRepo, githubClient, googleClient, cfg, twoFAService)
+	twoFAService, _ := NewTwoFaService(twoFARepo, userRepo, accessRepo, refreshRepo, cacheRepo, nil, nil, config.Config{})
+	// sso tests run without client credentials, so both providers are switched on through the settings
+	settingsService := newTestSystemSettingsService(ctrl, cfg,
+		entity.SystemSettingEntity{Key: entity.SystemSettingKeyGithubSSOEnabled, Value: "true"},
+		entity.SystemSettingEntity{Key: entity.SystemSettingKeyGoogleSSOEnabled, Value: "true"},
+	)
+	// a new sso binding is reported, the report is checked in TestAuthService_AddSSOBindingForUser_StepUp
+	auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+	auditLogRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	auditLogService := NewAuditLogService(auditLogRepo, NewSIEMExportService(nil, nil, config.Config{}), fixtures.NewFakeClock(time.Now()))
+	svc := NewAuthService(accessRepo, refreshRepo, userRepo, githubClient, googleClient, cfg, twoFAService, settingsService, auditLogService, &recordingMailer{})
 
 	return svc, accessRepo, refreshRepo, userRepo, twoFARepo, cacheRepo
 }
@@ -128,7 +147,9 @@ func TestAuthService_Login(t *testing.T) {
 	)
 
 	tests := []struct {
-		name       string
+		name string
+		// loginName is what the user logs in with, the username when empty
+		loginName  string
 		setupMocks func(
 			ctx context.Context,
 			accessRepo *mockgen.MockIAuthAccessTokenRepository,
@@ -155,6 +176,43 @@ func TestAuthService_Login(t *testing.T) {
 			},
 			wantCredentialValid: false,
 		},
+		{
+			name:      "unknown email returns false without error",
+			loginName: "nobody@example.com",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository, userRepo *mockgen.MockIUserRepository, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache) {
+				userRepo.EXPECT().
+					ValidateCredentialsByUsername(ctx, "nobody@example.com", password).
+					Return(entity.UserEntity{}, false, nil)
+				userRepo.EXPECT().
+					ValidateCredentialsByEmail(ctx, "nobody@example.com", password).
+					Return(entity.UserEntity{}, false, nil)
+			},
+			wantCredentialValid: false,
+		},
+		{
+			name:      "email is checked when no username matches",
+			loginName: " Alice@Example.com",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository, userRepo *mockgen.MockIUserRepository, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache) {
+				user := entity.UserEntity{ID: "user-1", Name: "Alice"}
+				userRepo.EXPECT().
+					ValidateCredentialsByUsername(ctx, " Alice@Example.com", password).
+					Return(entity.UserEntity{}, false, nil)
+				userRepo.EXPECT().
+					ValidateCredentialsByEmail(ctx, "alice@example.com", password).
+					Return(user, true, nil)
+				twoFARepo.EXPECT().
+					GetByUserID(ctx, user.ID).
+					Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Verified: true, Secret: "secret"}}, nil)
+				twoFARepo.EXPECT().
+					GetPreferredMethod(ctx, user.ID).
+					Return(nil, nil)
+				cacheRepo.EXPECT().
+					SetWithTTL(ctx, gomock.Any(), gomock.Any(), uint64(300)).
+					Return(nil)
+			},
+			wantCredentialValid: true,
+			wantTwoFAToken:      true,
+		},
 		{
 			name: "credential lookup error wraps with context",
 			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository, userRepo *mockgen.MockIUserRepository, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache) {
@@ -164,6 +222,15 @@ func TestAuthService_Login(t *testing.T) {
 			},
 			wantErrSub: "fail to check username and password",
 		},
+		{
+			name: "disabled user is refused before 2FA and tokens",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository, userRepo *mockgen.MockIUserRepository, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache) {
+				userRepo.EXPECT().
+					ValidateCredentialsByUsername(ctx, username, password).
+					Return(entity.UserEntity{ID: "user-1", Name: "Alice", Disabled: true}, true, nil)
+			},
+			wantErrSub: "user user-1 is disabled",
+		},
 		{
 			name: "2FA check error is wrapped",
 			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository, userRepo *mockgen.MockIUserRepository, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache) {
@@ -172,8 +239,8 @@ func TestAuthService_Login(t *testing.T) {
 					ValidateCredentialsByUsername(ctx, username, password).
 					Return(user, true, nil)
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, user.ID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{}, false, errors.New("2fa db error"))
+					GetByUserID(ctx, user.ID).
+					Return(nil, errors.New("2fa db error"))
 			},
 			wantErrSub: "fail to check 2fa status",
 		},
@@ -185,8 +252,11 @@ func TestAuthService_Login(t *testing.T) {
 					ValidateCredentialsByUsername(ctx, username, password).
 					Return(user, true, nil)
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, user.ID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{Verified: true, Secret: "secret"}, true, nil)
+					GetByUserID(ctx, user.ID).
+					Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Verified: true, Secret: "secret"}}, nil)
+				twoFARepo.EXPECT().
+					GetPreferredMethod(ctx, user.ID).
+					Return(nil, nil)
 				cacheRepo.EXPECT().
 					SetWithTTL(ctx, gomock.Any(), gomock.Any(), uint64(300)).
 					Return(nil)
@@ -194,6 +264,22 @@ func TestAuthService_Login(t *testing.T) {
 			wantCredentialValid: true,
 			wantTwoFAToken:      true,
 		},
+		{
+			name: "record login failure",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository, userRepo *mockgen.MockIUserRepository, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache) {
+				user := entity.UserEntity{ID: "user-0", Name: "Alice"}
+				userRepo.EXPECT().
+					ValidateCredentialsByUsername(ctx, username, password).
+					Return(user, true, nil)
+				twoFARepo.EXPECT().
+					GetByUserID(ctx, user.ID).
+					Return([]entity.TwoFAEntity{}, nil)
+				userRepo.EXPECT().
+					RecordLogin(ctx, user.ID).
+					Return(errors.New("db offline"))
+			},
+			wantErrSub: "fail to record login",
+		},
 		{
 			name: "refresh token issuance failure",
 			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository, userRepo *mockgen.MockIUserRepository, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache) {
@@ -202,8 +288,11 @@ func TestAuthService_Login(t *testing.T) {
 					ValidateCredentialsByUsername(ctx, username, password).
 					Return(user, true, nil)
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, user.ID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{}, false, nil)
+					GetByUserID(ctx, user.ID).
+					Return([]entity.TwoFAEntity{}, nil)
+				userRepo.EXPECT().
+					RecordLogin(ctx, user.ID).
+					Return(nil)
 				refreshRepo.EXPECT().
 					IssueRefreshToken(ctx, user.ID).
 					Return(entity.RefreshToken{}, errors.New("cannot persist"))
@@ -220,8 +309,11 @@ func TestAuthService_Login(t *testing.T) {
 					ValidateCredentialsByUsername(ctx, username, password).
 					Return(user, true, nil)
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, user.ID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{}, false, nil)
+					GetByUserID(ctx, user.ID).
+					Return([]entity.TwoFAEntity{}, nil)
+				userRepo.EXPECT().
+					RecordLogin(ctx, user.ID).
+					Return(nil)
 				refreshRepo.EXPECT().
 					IssueRefreshToken(ctx, user.ID).
 					Return(refresh, nil)
@@ -242,8 +334,11 @@ func TestAuthService_Login(t *testing.T) {
 					ValidateCredentialsByUsername(ctx, username, password).
 					Return(user, true, nil)
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, user.ID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{}, false, nil)
+					GetByUserID(ctx, user.ID).
+					Return([]entity.TwoFAEntity{}, nil)
+				userRepo.EXPECT().
+					RecordLogin(ctx, user.ID).
+					Return(nil)
 				refreshRepo.EXPECT().
 					IssueRefreshToken(ctx, user.ID).
 					Return(refresh, nil)
@@ -277,7 +372,11 @@ func TestAuthService_Login(t *testing.T) {
 				tt.setupMocks(ctx, accessRepo, refreshRepo, userRepo, twoFARepo, cacheRepo)
 			}
 
-			result, twoFAToken, credentialValid, err := svc.Login(ctx, username, password)
+			loginName := username
+			if tt.loginName != "" {
+				loginName = tt.loginName
+			}
+			result, twoFAToken, credentialValid, err := svc.Login(ctx, loginName, password)
 
 			if tt.wantErrSub != "" {
 				require.Error(t, err)
@@ -335,6 +434,17 @@ func TestAuthService_IssueNewAccessToken(t *testing.T) {
 			},
 			wantOK: false,
 		},
+		{
+			name: "refresh token of an oidc client returns false",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				refresh := entity.NewRefreshToken("user-1", refreshToken, time.Unix(50, 0), time.Unix(100, 0))
+				refresh.ClientID = "client-1"
+				refreshRepo.EXPECT().
+					ValidateRefreshToken(ctx, refreshToken).
+					Return(refresh, true, nil)
+			},
+			wantOK: false,
+		},
 		{
 			name: "issuing access token error",
 			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
@@ -380,7 +490,9 @@ func TestAuthService_IssueNewAccessToken(t *testing.T) {
 				tt.setupMocks(ctx, accessRepo, refreshRepo)
 			}
 
-			token, ok, err := svc.IssueNewAccessToken(ctx, refreshToken)
+			token, rotated, ok, err := svc.IssueNewAccessToken(ctx, refreshToken)
+			// rotation is disabled by default
+			require.Nil(t, rotated)
 
 			if tt.wantErrSub != "" {
 				require.Error(t, err)
@@ -400,6 +512,108 @@ func TestAuthService_IssueNewAccessToken(t *testing.T) {
 	}
 }
 
+func TestAuthService_IssueNewAccessToken_Rotation(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const refreshToken = "refresh-token"
+	refresh := entity.NewRefreshToken("user-1", refreshToken, time.Unix(10, 0), time.Unix(100, 0))
+	newRefresh := entity.NewRefreshToken("user-1", "refresh-token-2", time.Unix(20, 0), time.Unix(100, 0))
+	newRefresh.FamilyID = refresh.FamilyID
+
+	tests := []struct {
+		name        string
+		setupMocks  func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository)
+		wantOK      bool
+		wantErrSub  string
+		wantRotated *entity.RefreshToken
+	}{
+		{
+			name: "valid token is rotated and the access token is bound to the new one",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				refreshRepo.EXPECT().ValidateRefreshToken(ctx, refreshToken).Return(refresh, true, nil)
+				refreshRepo.EXPECT().RotateRefreshToken(ctx, refresh).Return(newRefresh, true, nil)
+				accessRepo.EXPECT().
+					IssueAccessToken(ctx, newRefresh.UserID, newRefresh.TokenHash).
+					Return(entity.NewAccessToken(newRefresh.UserID, "access", time.Unix(20, 0), time.Unix(40, 0), newRefresh.TokenHash), nil)
+			},
+			wantOK:      true,
+			wantRotated: &newRefresh,
+		},
+		{
+			name: "rotation error is wrapped",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				refreshRepo.EXPECT().ValidateRefreshToken(ctx, refreshToken).Return(refresh, true, nil)
+				refreshRepo.EXPECT().RotateRefreshToken(ctx, refresh).Return(entity.RefreshToken{}, false, errors.New("nutsdb down"))
+			},
+			wantErrSub: "fail to rotate refresh token",
+		},
+		{
+			name: "unknown token is rejected without revoking anything",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				refreshRepo.EXPECT().ValidateRefreshToken(ctx, refreshToken).Return(entity.RefreshToken{}, false, nil)
+				refreshRepo.EXPECT().FindRotatedRefreshToken(ctx, refreshToken).Return(entity.RefreshToken{}, false, nil)
+			},
+		},
+		{
+			name: "reused rotated token revokes the whole family",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				refreshRepo.EXPECT().ValidateRefreshToken(ctx, refreshToken).Return(entity.RefreshToken{}, false, nil)
+				refreshRepo.EXPECT().FindRotatedRefreshToken(ctx, refreshToken).Return(refresh, true, nil)
+				refreshRepo.EXPECT().
+					DeleteTokenFamily(ctx, refresh.UserID, refresh.FamilyID).
+					Return([]string{refresh.TokenHash, newRefresh.TokenHash}, nil)
+				accessRepo.EXPECT().DeleteTokensByRefreshTokenHash(ctx, refresh.TokenHash).Return(nil)
+				accessRepo.EXPECT().DeleteTokensByRefreshTokenHash(ctx, newRefresh.TokenHash).Return(nil)
+			},
+		},
+		{
+			name: "token rotated by a concurrent request counts as reused",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				refreshRepo.EXPECT().ValidateRefreshToken(ctx, refreshToken).Return(refresh, true, nil)
+				refreshRepo.EXPECT().RotateRefreshToken(ctx, refresh).Return(entity.RefreshToken{}, false, nil)
+				refreshRepo.EXPECT().FindRotatedRefreshToken(ctx, refreshToken).Return(refresh, true, nil)
+				refreshRepo.EXPECT().DeleteTokenFamily(ctx, refresh.UserID, refresh.FamilyID).Return([]string{newRefresh.TokenHash}, nil)
+				accessRepo.EXPECT().DeleteTokensByRefreshTokenHash(ctx, newRefresh.TokenHash).Return(nil)
+			},
+		},
+		{
+			name: "family revocation error is wrapped",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				refreshRepo.EXPECT().ValidateRefreshToken(ctx, refreshToken).Return(entity.RefreshToken{}, false, nil)
+				refreshRepo.EXPECT().FindRotatedRefreshToken(ctx, refreshToken).Return(refresh, true, nil)
+				refreshRepo.EXPECT().DeleteTokenFamily(ctx, refresh.UserID, refresh.FamilyID).Return(nil, errors.New("nutsdb down"))
+			},
+			wantErrSub: "fail to revoke refresh token family",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			cfg := config.Config{ENABLE_USER_REGISTRATION: true, RefreshTokenRotation: true}
+			svc, accessRepo, refreshRepo, _, _, _ := newTestAuthServiceWithSSOClientsAndConfig(ctrl, nil, nil, cfg)
+			tt.setupMocks(ctx, accessRepo, refreshRepo)
+
+			_, rotated, ok, err := svc.IssueNewAccessToken(ctx, refreshToken)
+			if tt.wantErrSub != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.wantRotated, rotated)
+		})
+	}
+}
+
 func TestAuthService_Logout(t *testing.T) {
 	t.Parallel()
 
@@ -544,6 +758,16 @@ func TestAuthService_ValidateAccessToken(t *testing.T) {
 			wantValid: true,
 			wantToken: entity.AccessToken{UserID: "user-1", RelativeRefreshToken: "rh"},
 		},
+		{
+			name: "token of an oidc client is refused",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository) {
+				token := entity.AccessToken{UserID: "user-1", RelativeRefreshToken: "rh", ClientID: "client-1", Scopes: []string{"openid"}}
+				accessRepo.EXPECT().
+					ValidateAccessToken(ctx, tokenStr).
+					Return(token, true, nil)
+			},
+			wantValid: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -775,6 +999,7 @@ func TestAuthService_LoginOrCreateUserBySSO(t *testing.T) {
 		name                   string
 		provider               string
 		enableUserRegistration *bool
+		demoMode               bool
 		setupMocks             func(
 			ctx context.Context,
 			accessRepo *mockgen.MockIAuthAccessTokenRepository,
@@ -846,6 +1071,27 @@ func TestAuthService_LoginOrCreateUserBySSO(t *testing.T) {
 			wantErrSub:  "user registration is not enabled",
 			wantErrCode: &error_code.UserRegistrationIsNotEnabled,
 		},
+		{
+			name:     "demo mode refuses to create the sso user",
+			provider: providerGithub,
+			demoMode: true,
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository, userRepo *mockgen.MockIUserRepository, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache, githubClient *fakeGithubAuthClient, googleClient *fakeGoogleAuthClient) {
+				githubClient.oauthTokenToAccessTokenFunc = func(oauthToken string) (string, error) {
+					return "github-access-token", nil
+				}
+				githubClient.getUserInfoFunc = func(accessToken string) (entity.GithubUserInfoEntity, error) {
+					return entity.NewGithubUserInfoEntity(5, "octo-demo", "Octo Demo", &userInfoEmail, ""), nil
+				}
+				userRepo.EXPECT().
+					GetUserBySSO(ctx, providerGithub, "5").
+					Return(entity.UserEntity{}, false, nil)
+				userRepo.EXPECT().
+					CreateUserBySSO(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			wantErrSub:  "disabled in demo mode",
+			wantErrCode: &error_code.DemoModeReadonly,
+		},
 		{
 			name:     "create user by sso error is wrapped",
 			provider: providerGithub,
@@ -886,9 +1132,15 @@ func TestAuthService_LoginOrCreateUserBySSO(t *testing.T) {
 				userRepo.EXPECT().
 					CreateUserBySSO(ctx, providerGithub, "7", gomock.Any(), &userInfoEmail, []entity.UserRoleEntity{entity.UserRoleUser}).
 					Return(user, nil)
+				userRepo.EXPECT().
+					SetUserSSOAccessToken(ctx, user.ID, providerGithub, "github-access-token").
+					Return(nil)
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, user.ID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{}, false, nil)
+					GetByUserID(ctx, user.ID).
+					Return([]entity.TwoFAEntity{}, nil)
+				userRepo.EXPECT().
+					RecordLogin(ctx, user.ID).
+					Return(nil)
 				refreshRepo.EXPECT().
 					IssueRefreshToken(ctx, user.ID).
 					Return(refresh, nil)
@@ -902,6 +1154,46 @@ func TestAuthService_LoginOrCreateUserBySSO(t *testing.T) {
 				AccessToken:  entity.NewAccessToken("user-sso-1", "access-token", time.Unix(100, 0), time.Unix(150, 0), utils.Sha256String("refresh-token")),
 			},
 		},
+		{
+			name:     "failing to keep the github token does not fail the login",
+			provider: providerGithub,
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository, userRepo *mockgen.MockIUserRepository, twoFARepo *mockgen.MockIAuth2FARepository, cacheRepo *mockgen.MockICache, githubClient *fakeGithubAuthClient, googleClient *fakeGoogleAuthClient) {
+				githubClient.oauthTokenToAccessTokenFunc = func(oauthToken string) (string, error) {
+					return "github-access-token", nil
+				}
+				githubClient.getUserInfoFunc = func(accessToken string) (entity.GithubUserInfoEntity, error) {
+					return entity.NewGithubUserInfoEntity(11, "octo11", "Octo 11", &userInfoEmail, ""), nil
+				}
+
+				user := entity.UserEntity{ID: "user-sso-11", Name: "octo11"}
+				refresh := entity.NewRefreshToken(user.ID, "refresh-token", time.Unix(100, 0), time.Unix(200, 0))
+				access := entity.NewAccessToken(user.ID, "access-token", time.Unix(100, 0), time.Unix(150, 0), refresh.TokenHash)
+
+				userRepo.EXPECT().
+					GetUserBySSO(ctx, providerGithub, "11").
+					Return(user, true, nil)
+				userRepo.EXPECT().
+					SetUserSSOAccessToken(ctx, user.ID, providerGithub, "github-access-token").
+					Return(errors.New("db unavailable"))
+				twoFARepo.EXPECT().
+					GetByUserID(ctx, user.ID).
+					Return([]entity.TwoFAEntity{}, nil)
+				userRepo.EXPECT().
+					RecordLogin(ctx, user.ID).
+					Return(nil)
+				refreshRepo.EXPECT().
+					IssueRefreshToken(ctx, user.ID).
+					Return(refresh, nil)
+				accessRepo.EXPECT().
+					IssueAccessToken(ctx, user.ID, refresh.TokenHash).
+					Return(access, nil)
+			},
+			wantResult: AuthLoginResult{
+				User:         entity.UserEntity{ID: "user-sso-11", Name: "octo11"},
+				RefreshToken: entity.NewRefreshToken("user-sso-11", "refresh-token", time.Unix(100, 0), time.Unix(200, 0)),
+				AccessToken:  entity.NewAccessToken("user-sso-11", "access-token", time.Unix(100, 0), time.Unix(150, 0), utils.Sha256String("refresh-token")),
+			},
+		},
 		{
 			name:     "existing sso user with 2fa required returns twofa token",
 			provider: providerGithub,
@@ -917,9 +1209,15 @@ func TestAuthService_LoginOrCreateUserBySSO(t *testing.T) {
 				userRepo.EXPECT().
 					GetUserBySSO(ctx, providerGithub, "8").
 					Return(user, true, nil)
+				userRepo.EXPECT().
+					SetUserSSOAccessToken(ctx, user.ID, providerGithub, "github-access-token").
+					Return(nil)
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, user.ID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{Verified: true, Secret: "secret"}, true, nil)
+					GetByUserID(ctx, user.ID).
+					Return([]entity.TwoFAEntity{{Type: entity.TwoFATypeTOTP, Verified: true, Secret: "secret"}}, nil)
+				twoFARepo.EXPECT().
+					GetPreferredMethod(ctx, user.ID).
+					Return(nil, nil)
 				cacheRepo.EXPECT().
 					SetWithTTL(ctx, gomock.Any(), gomock.Any(), uint64(300)).
 					Return(nil)
@@ -946,9 +1244,12 @@ func TestAuthService_LoginOrCreateUserBySSO(t *testing.T) {
 				userRepo.EXPECT().
 					GetUserBySSO(ctx, providerGithub, "18").
 					Return(user, true, nil)
+				userRepo.EXPECT().
+					SetUserSSOAccessToken(ctx, user.ID, providerGithub, "github-access-token").
+					Return(nil)
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, user.ID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{}, false, errors.New("2fa repo failed"))
+					GetByUserID(ctx, user.ID).
+					Return(nil, errors.New("2fa repo failed"))
 			},
 			wantErrSub: "fail to check 2fa status for user",
 		},
@@ -967,9 +1268,15 @@ func TestAuthService_LoginOrCreateUserBySSO(t *testing.T) {
 				userRepo.EXPECT().
 					GetUserBySSO(ctx, providerGithub, "9").
 					Return(user, true, nil)
+				userRepo.EXPECT().
+					SetUserSSOAccessToken(ctx, user.ID, providerGithub, "github-access-token").
+					Return(nil)
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, user.ID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{}, false, nil)
+					GetByUserID(ctx, user.ID).
+					Return([]entity.TwoFAEntity{}, nil)
+				userRepo.EXPECT().
+					RecordLogin(ctx, user.ID).
+					Return(nil)
 				refreshRepo.EXPECT().
 					IssueRefreshToken(ctx, user.ID).
 					Return(entity.RefreshToken{}, errors.New("refresh repo down"))
@@ -992,9 +1299,15 @@ func TestAuthService_LoginOrCreateUserBySSO(t *testing.T) {
 				userRepo.EXPECT().
 					GetUserBySSO(ctx, providerGithub, "10").
 					Return(user, true, nil)
+				userRepo.EXPECT().
+					SetUserSSOAccessToken(ctx, user.ID, providerGithub, "github-access-token").
+					Return(nil)
 				twoFARepo.EXPECT().
-					GetByUserIDAndType(ctx, user.ID, entity.TwoFATypeTOTP).
-					Return(entity.TwoFAEntity{}, false, nil)
+					GetByUserID(ctx, user.ID).
+					Return([]entity.TwoFAEntity{}, nil)
+				userRepo.EXPECT().
+					RecordLogin(ctx, user.ID).
+					Return(nil)
 				refreshRepo.EXPECT().
 					IssueRefreshToken(ctx, user.ID).
 					Return(refresh, nil)
@@ -1021,6 +1334,7 @@ func TestAuthService_LoginOrCreateUserBySSO(t *testing.T) {
 			if tt.enableUserRegistration != nil {
 				cfg.ENABLE_USER_REGISTRATION = *tt.enableUserRegistration
 			}
+			cfg.DemoMode = tt.demoMode
 			svc, accessRepo, refreshRepo, userRepo, twoFARepo, cacheRepo := newTestAuthServiceWithSSOClientsAndConfig(ctrl, githubClient, googleClient, cfg)
 			if tt.setupMocks != nil {
 				tt.setupMocks(ctx, accessRepo, refreshRepo, userRepo, twoFARepo, cacheRepo, githubClient, googleClient)
@@ -1251,6 +1565,9 @@ func TestAuthService_AddSSOBindingForUser(t *testing.T) {
 				userRepo.EXPECT().
 					AddUserSSOBinding(ctx, userID, providerGithub, "16", gomock.Any(), &email).
 					Return(nil)
+				userRepo.EXPECT().
+					SetUserSSOAccessToken(ctx, userID, providerGithub, "github-access-token").
+					Return(nil)
 			},
 		},
 	}
@@ -1265,13 +1582,14 @@ func TestAuthService_AddSSOBindingForUser(t *testing.T) {
 
 			githubClient := &fakeGithubAuthClient{}
 			googleClient := &fakeGoogleAuthClient{}
-			svc, _, _, userRepo, _, _ := newTestAuthServiceWithSSOClients(ctrl, githubClient, googleClient)
+			svc, _, _, userRepo, twoFARepo, _ := newTestAuthServiceWithSSOClients(ctrl, githubClient, googleClient)
+			twoFARepo.EXPECT().GetByUserID(ctx, userID).Return(nil, nil).AnyTimes()
 
 			if tt.setupMocks != nil {
 				tt.setupMocks(ctx, userRepo, githubClient, googleClient)
 			}
 
-			err := svc.AddSSOBindingForUser(ctx, userID, tt.provider, oauthCode)
+			err := svc.AddSSOBindingForUser(ctx, userID, tt.provider, oauthCode, "")
 			if tt.wantErrSub != "" {
 				require.Error(t, err)
 				require.Contains(t, err.Error(), tt.wantErrSub)
@@ -1300,6 +1618,7 @@ func TestAuthService_getSSOProviderUserInfo(t *testing.T) {
 		wantProviderID   string
 		wantUsername     string
 		wantEmail        *string
+		wantAccessToken  string
 		wantErrSubstring string
 	}{
 		{
@@ -1349,6 +1668,7 @@ func TestAuthService_getSSOProviderUserInfo(t *testing.T) {
 				email := "gh@example.com"
 				return &email
 			}(),
+			wantAccessToken: "access-token",
 		},
 		{
 			name:         "google exchange error is wrapped",
@@ -1413,7 +1733,7 @@ func TestAuthService_getSSOProviderUserInfo(t *testing.T) {
 				googleClient: tt.googleClient,
 			}
 
-			gotProviderID, gotUsername, gotEmail, err := svc.getSSOProviderUserInfo(tt.provider, tt.oauthToken)
+			gotProviderID, gotUsername, gotEmail, gotAccessToken, err := svc.getSSOProviderUserInfo(tt.provider, tt.oauthToken)
 			if tt.wantErrSubstring != "" {
 				require.Error(t, err)
 				require.Contains(t, err.Error(), tt.wantErrSubstring)
@@ -1424,6 +1744,217 @@ func TestAuthService_getSSOProviderUserInfo(t *testing.T) {
 			require.Equal(t, tt.wantProviderID, gotProviderID)
 			require.Equal(t, tt.wantUsername, gotUsername)
 			require.Equal(t, tt.wantEmail, gotEmail)
+			require.Equal(t, tt.wantAccessToken, gotAccessToken)
+		})
+	}
+}
+
+func TestAuthService_SSODisabledBySettings(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	accessRepo := mockgen.NewMockIAuthAccessTokenRepository(ctrl)
+	refreshRepo := mockgen.NewMockIAuthRefreshTokenRepository(ctrl)
+	userRepo := mockgen.NewMockIUserRepository(ctrl)
+	twoFARepo := mockgen.NewMockIAuth2FARepository(ctrl)
+	cacheRepo := mockgen.NewMockICache(ctrl)
+
+	// the github client must not be called when the provider is disabled
+	githubClient := &fakeGithubAuthClient{
+		oauthTokenToAccessTokenFunc: func(string) (string, error) {
+			t.Fatal("github client called for a disabled provider")
+			return "", nil
+		},
+	}
+
+	cfg := config.Config{ENABLE_USER_REGISTRATION: true, SSO_GITHUB_CLIENT_ID: "github-client", SSO_GITHUB_CLIENT_SECRET: "secret"}
+	settingsService := newTestSystemSettingsService(ctrl, cfg,
+		entity.SystemSettingEntity{Key: entity.SystemSettingKeyGithubSSOEnabled, Value: "false"},
+	)
+	twoFAService, _ := NewTwoFaService(twoFARepo, userRepo, accessRepo, refreshRepo, cacheRepo, nil, nil, config.Config{})
+	svc := NewAuthService(accessRepo, refreshRepo, userRepo, githubClient, nil, cfg, twoFAService, settingsService, nil, nil)
+
+	_, _, err := svc.LoginOrCreateUserBySSO(context.Background(), "github", "oauth-code")
+	var codeErr error_code.ErrorWithErrorCode
+	require.ErrorAs(t, err, &codeErr)
+	require.Equal(t, error_code.SSOProviderIsNotEnabled, codeErr.ErrorCode)
+
+	userRepo.EXPECT().GetByID(gomock.Any(), entity.UserIDEntity("user-1")).Return(entity.UserEntity{ID: "user-1"}, true, nil)
+	err = svc.AddSSOBindingForUser(context.Background(), "user-1", "github", "oauth-code", "")
+	require.ErrorAs(t, err, &codeErr)
+	require.Equal(t, error_code.SSOProviderIsNotEnabled, codeErr.ErrorCode)
+}
+
+func TestAuthService_AddSSOBindingForUser_StepUp(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+	mail := "user@example.com"
+	user := entity.UserEntity{ID: userID, Name: "user", Mail: &mail}
+	secret, validCode := generateTestTOTPSecret(t)
+	twoFAs := []entity.TwoFAEntity{{UserID: userID, Type: entity.TwoFATypeTOTP, Secret: secret, Verified: true}}
+
+	newSvc := func(t *testing.T, githubClient *fakeGithubAuthClient) (*AuthService, *mockgen.MockIUserRepository, *mockgen.MockIAuth2FARepository, *mockgen.MockIAuditLogRepository, *recordingMailer) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		userRepo := mockgen.NewMockIUserRepository(ctrl)
+		twoFARepo := mockgen.NewMockIAuth2FARepository(ctrl)
+		auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+		mailer := &recordingMailer{}
+		cfg := config.Config{ENABLE_USER_REGISTRATION: true}
+		twoFAService, _ := NewTwoFaService(twoFARepo, userRepo, nil, nil, nil, nil, nil, config.Config{})
+		settingsService := newTestSystemSettingsService(ctrl, cfg,
+			entity.SystemSettingEntity{Key: entity.SystemSettingKeyGithubSSOEnabled, Value: "true"},
+		)
+		auditLogService := NewAuditLogService(auditLogRepo, NewSIEMExportService(nil, nil, config.Config{}), fixtures.NewFakeClock(time.Now()))
+		svc := NewAuthService(nil, nil, userRepo, githubClient, nil, cfg, twoFAService, settingsService, auditLogService, mailer)
+		return svc, userRepo, twoFARepo, auditLogRepo, mailer
+	}
+
+	t.Run("missing code is refused before the oauth code is used", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		githubClient := &fakeGithubAuthClient{
+			oauthTokenToAccessTokenFunc: func(string) (string, error) {
+				t.Fatal("oauth code exchanged without the 2fa code")
+				return "", nil
+			},
+		}
+		svc, userRepo, twoFARepo, _, mailer := newSvc(t, githubClient)
+		userRepo.EXPECT().GetByID(ctx, userID).Return(user, true, nil)
+		twoFARepo.EXPECT().GetByUserID(ctx, userID).Return(twoFAs, nil)
+
+		err := svc.AddSSOBindingForUser(ctx, userID, "github", "oauth-code", "")
+		var codeErr error_code.ErrorWithErrorCode
+		require.ErrorAs(t, err, &codeErr)
+		require.Equal(t, error_code.TwoFaCodeRequired, codeErr.ErrorCode)
+		require.Empty(t, mailer.sent())
+	})
+
+	t.Run("valid code adds the binding and notifies the user", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		githubClient := &fakeGithubAuthClient{
+			oauthTokenToAccessTokenFunc: func(string) (string, error) { return "github-access-token", nil },
+			getUserInfoFunc: func(string) (entity.GithubUserInfoEntity, error) {
+				return entity.NewGithubUserInfoEntity(16, "octo", "Octo", nil, ""), nil
+			},
+		}
+		svc, userRepo, twoFARepo, auditLogRepo, mailer := newSvc(t, githubClient)
+		userRepo.EXPECT().GetByID(ctx, userID).Return(user, true, nil)
+		twoFARepo.EXPECT().GetByUserID(ctx, userID).Return(twoFAs, nil)
+		userRepo.EXPECT().GetUserSSOBindings(ctx, userID).Return(nil, nil)
+		userRepo.EXPECT().GetUserBySSO(ctx, "github", "16").Return(entity.UserEntity{}, false, nil)
+		userRepo.EXPECT().AddUserSSOBinding(ctx, userID, "github", "16", gomock.Any(), gomock.Any()).Return(nil)
+		userRepo.EXPECT().SetUserSSOAccessToken(ctx, userID, "github", "github-access-token").Return(nil)
+		auditLogRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog entity.AuditLogEntity) error {
+			require.Equal(t, userID, auditLog.ActorID)
+			require.Equal(t, ssoBindingAddedAuditEvent, auditLog.Route)
+			require.Contains(t, auditLog.Payload, `"provider":"github"`)
+			return nil
+		})
+
+		require.NoError(t, svc.AddSSOBindingForUser(ctx, userID, "github", "oauth-code", validCode))
+		sent := mailer.sent()
+		require.Len(t, sent, 1)
+		require.Equal(t, mail, sent[0].To)
+		require.Contains(t, sent[0].Body, "The github account octo was linked to the account user")
+	})
+}
+
+func TestAuthService_ActiveSessions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	svc, _, refreshRepo, _, _, _ := newTestAuthService(ctrl)
+	now := time.Now()
+	older := entity.NewRefreshTokenFromHash("user-1", "hash-older", now.Add(-time.Hour), now.Add(time.Hour))
+	newer := entity.NewRefreshTokenFromHash("user-1", "hash-newer", now, now.Add(2*time.Hour))
+	refreshRepo.EXPECT().ListActiveSessionsByUserID(ctx, entity.UserIDEntity("user-1")).Return([]entity.RefreshToken{older, newer}, nil)
+
+	sessions, err := svc.ActiveSessions(ctx, "user-1")
+	require.NoError(t, err)
+	require.Equal(t, []entity.RefreshToken{newer, older}, sessions)
+}
+
+func TestAuthService_RevokeSession(t *testing.T) {
+	t.Parallel()
+
+	const userID = entity.UserIDEntity("user-1")
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		setupMocks  func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository)
+		wantErrCode *error_code.ErrorCode
+		wantErrSub  string
+	}{
+		{
+			name: "revokes the refresh token and its access tokens",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				refreshRepo.EXPECT().ValidateRefreshTokenHash(ctx, "hash-1").Return(entity.NewRefreshTokenFromHash(userID, "hash-1", now, now.Add(time.Hour)), true, nil)
+				refreshRepo.EXPECT().DeleteRefreshTokenByHash(ctx, "hash-1").Return(nil)
+				accessRepo.EXPECT().DeleteTokensByRefreshTokenHash(ctx, "hash-1").Return(nil)
+			},
+		},
+		{
+			name: "unknown session",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				refreshRepo.EXPECT().ValidateRefreshTokenHash(ctx, "hash-1").Return(entity.RefreshToken{}, false, nil)
+			},
+			wantErrCode: &error_code.SessionNotFound,
+		},
+		{
+			name: "session of another user is not found",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				refreshRepo.EXPECT().ValidateRefreshTokenHash(ctx, "hash-1").Return(entity.NewRefreshTokenFromHash("user-2", "hash-1", now, now.Add(time.Hour)), true, nil)
+			},
+			wantErrCode: &error_code.SessionNotFound,
+		},
+		{
+			name: "delete error is wrapped",
+			setupMocks: func(ctx context.Context, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				refreshRepo.EXPECT().ValidateRefreshTokenHash(ctx, "hash-1").Return(entity.NewRefreshTokenFromHash(userID, "hash-1", now, now.Add(time.Hour)), true, nil)
+				refreshRepo.EXPECT().DeleteRefreshTokenByHash(ctx, "hash-1").Return(errors.New("store offline"))
+			},
+			wantErrSub: "fail to delete refresh token",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			svc, accessRepo, refreshRepo, _, _, _ := newTestAuthService(ctrl)
+			tt.setupMocks(ctx, accessRepo, refreshRepo)
+
+			err := svc.RevokeSession(ctx, userID, "hash-1")
+			switch {
+			case tt.wantErrCode != nil:
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, *tt.wantErrCode, codeErr.ErrorCode)
+			case tt.wantErrSub != "":
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+			default:
+				require.NoError(t, err)
+			}
 		})
 	}
 }
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/scheduler"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	BackupJobDatabaseBackup = "database_backup"
+
+	backupNameTimeLayout = "20060102T150405Z"
+)
+
+func NewBackupService(
+	backupRepo repository.IBackupRepository,
+	rdsMaintenance repository.IRdsMaintenance,
+	keyValueMaintenance repository.IKeyValueStoreMaintenance,
+	settingsService *SystemSettingsService,
+	clock domain_client.IClock,
+	cfg config.Config,
+) *BackupService {
+	return &BackupService{
+		backupRepo:          backupRepo,
+		rdsMaintenance:      rdsMaintenance,
+		keyValueMaintenance: keyValueMaintenance,
+		settingsService:     settingsService,
+		clock:               clock,
+		config:              cfg,
+	}
+}
+
+// BackupService backs up the stores kept on local disk, the sqlite database and the nutsdb store, and restores them.
+// MySQL and Redis are left to their own backup tooling.
+type BackupService struct {
+	backupRepo          repository.IBackupRepository
+	rdsMaintenance      repository.IRdsMaintenance
+	keyValueMaintenance repository.IKeyValueStoreMaintenance
+	settingsService     *SystemSettingsService
+	clock               domain_client.IClock
+	config              config.Config
+
+	// running keeps the scheduled and the manual backup from running at the same time
+	running sync.Mutex
+}
+
+// Jobs returns the backup job, its schedule comes from the system settings.
+func (s *BackupService) Jobs() []scheduler.Job {
+	return []scheduler.Job{
+		{
+			Name:        BackupJobDatabaseBackup,
+			Description: "Backs up the sqlite database and the nutsdb store to BACKUP_DIR and drops the backups beyond BACKUP_RETENTION",
+			Spec: func(ctx context.Context) (string, error) {
+				settings, err := s.settingsService.Settings(ctx)
+				return settings.DatabaseBackupSchedule, err
+			},
+			Run: func(ctx context.Context) error {
+				_, err := s.CreateBackup(ctx)
+				return err
+			},
+		},
+	}
+}
+
+// CreateBackup writes a backup of every local store, then drops the oldest backups beyond the retention.
+func (s *BackupService) CreateBackup(ctx context.Context) (entity.BackupEntity, error) {
+	if !s.running.TryLock() {
+		return entity.BackupEntity{}, error_code.NewErrorWithErrorCodef(error_code.BackupAlreadyRunning, "a backup is already running")
+	}
+	defer s.running.Unlock()
+
+	name := "toolbake-backup-" + s.clock.Now().UTC().Format(backupNameTimeLayout) + ".tar.gz"
+	backup, err := s.backupRepo.Create(ctx, name, func(dir string) ([]string, error) {
+		components := []string{}
+		backedUp, err := s.rdsMaintenance.Backup(ctx, filepath.Join(dir, entity.BackupComponentRds))
+		if err != nil {
+			return nil, err
+		}
+		if backedUp {
+			components = append(components, entity.BackupComponentRds)
+		}
+		backedUp, err = s.keyValueMaintenance.Backup(ctx, filepath.Join(dir, entity.BackupComponentKeyValue))
+		if err != nil {
+			return nil, err
+		}
+		if backedUp {
+			components = append(components, entity.BackupComponentKeyValue)
+		}
+		if len(components) == 0 {
+			return nil, error_code.NewErrorWithErrorCodef(error_code.BackupNotSupported,
+				"DB_TYPE=%s and KEY_VALUE_DB_TYPE=%s keep nothing on local disk", s.config.DBType, s.config.KeyValueDBType)
+		}
+		return components, nil
+	})
+	if err != nil {
+		return entity.BackupEntity{}, err
+	}
+	logger.Infof(ctx, "Created backup %s of %v, %d bytes", backup.Name, backup.Components, backup.Size)
+
+	if err := s.applyRetention(ctx); err != nil {
+		// the new backup is fine, the old ones are dropped on the next run
+		logger.Warnf(ctx, "Failed to drop the backups beyond the retention: %v", err)
+	}
+	return backup, nil
+}
+
+func (s *BackupService) applyRetention(ctx context.Context) error {
+	backups, err := s.backupRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+	pending, _, err := s.backupRepo.PendingRestore(ctx)
+	if err != nil {
+		return err
+	}
+	for i, backup := range backups {
+		// the backup staged for restore stays until the restore happened
+		if i < s.config.BackupRetention || backup.Name == pending {
+			continue
+		}
+		if _, err := s.backupRepo.Delete(ctx, backup.Name); err != nil {
+			return errors.Wrapf(err, "failed to drop backup %s", backup.Name)
+		}
+		logger.Infof(ctx, "Dropped backup %s beyond the retention of %d", backup.Name, s.config.BackupRetention)
+	}
+	return nil
+}
+
+// ListBackups returns the backups newest first and the name of the backup staged for restore, if any.
+func (s *BackupService) ListBackups(ctx context.Context) ([]entity.BackupEntity, *string, error) {
+	backups, err := s.backupRepo.List(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	pending, exists, err := s.backupRepo.PendingRestore(ctx)
+	if err != nil || !exists {
+		return backups, nil, err
+	}
+	return backups, &pending, nil
+}
+
+// StageRestore marks the backup to be restored on the next start, replacing a restore staged before.
+func (s *BackupService) StageRestore(ctx context.Context, name string) error {
+	staged, err := s.backupRepo.StageRestore(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !staged {
+		return error_code.NewErrorWithErrorCodef(error_code.BackupNotFound, "backup %s not found", name)
+	}
+	logger.Warnf(ctx, "Backup %s is restored on the next start, the changes made until then are lost", name)
+	return nil
+}
+
+// CancelRestore drops the staged restore, it returns false when none was staged.
+func (s *BackupService) CancelRestore(ctx context.Context) (bool, error) {
+	return s.backupRepo.CancelRestore(ctx)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+func TestBackupService_CreateBackup(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	tests := []struct {
+		name           string
+		rdsBackedUp    bool
+		keyValueBackup bool
+		existing       []string
+		pending        string
+		wantComponents []string
+		wantDeleted    []string
+		wantErrCode    *error_code.ErrorCode
+	}{
+		{
+			name:           "backs up both stores and drops the backups beyond the retention",
+			rdsBackedUp:    true,
+			keyValueBackup: true,
+			existing:       []string{"new", "b", "c", "d"},
+			wantComponents: []string{entity.BackupComponentRds, entity.BackupComponentKeyValue},
+			wantDeleted:    []string{"c", "d"},
+		},
+		{
+			name:           "keeps the backup staged for restore",
+			keyValueBackup: true,
+			existing:       []string{"new", "b", "c", "d"},
+			pending:        "c",
+			wantComponents: []string{entity.BackupComponentKeyValue},
+			wantDeleted:    []string{"d"},
+		},
+		{
+			name:        "nothing on local disk",
+			wantErrCode: &error_code.BackupNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			backupRepo := mockgen.NewMockIBackupRepository(ctrl)
+			rdsMaintenance := mockgen.NewMockIRdsMaintenance(ctrl)
+			keyValueMaintenance := mockgen.NewMockIKeyValueStoreMaintenance(ctrl)
+			clock := fixtures.NewFakeClock(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+			cfg := config.Config{BackupRetention: 2}
+
+			rdsMaintenance.EXPECT().Backup(gomock.Any(), filepath.Join("staging", entity.BackupComponentRds)).Return(tt.rdsBackedUp, nil)
+			keyValueMaintenance.EXPECT().Backup(gomock.Any(), filepath.Join("staging", entity.BackupComponentKeyValue)).Return(tt.keyValueBackup, nil)
+			backupRepo.EXPECT().Create(gomock.Any(), "toolbake-backup-20260102T030405Z.tar.gz", gomock.Any()).
+				DoAndReturn(func(ctx context.Context, name string, fill func(dir string) ([]string, error)) (entity.BackupEntity, error) {
+					components, err := fill("staging")
+					if err != nil {
+						return entity.BackupEntity{}, err
+					}
+					return entity.BackupEntity{Name: name, Components: components}, nil
+				})
+			if tt.wantErrCode == nil {
+				existing := make([]entity.BackupEntity, 0, len(tt.existing))
+				for _, name := range tt.existing {
+					existing = append(existing, entity.BackupEntity{Name: name})
+				}
+				backupRepo.EXPECT().List(gomock.Any()).Return(existing, nil)
+				backupRepo.EXPECT().PendingRestore(gomock.Any()).Return(tt.pending, tt.pending != "", nil)
+				for _, name := range tt.wantDeleted {
+					backupRepo.EXPECT().Delete(gomock.Any(), name).Return(true, nil)
+				}
+			}
+
+			svc := NewBackupService(backupRepo, rdsMaintenance, keyValueMaintenance, nil, clock, cfg)
+			backup, err := svc.CreateBackup(context.Background())
+			if tt.wantErrCode != nil {
+				var errWithCode error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &errWithCode)
+				require.Equal(t, tt.wantErrCode.Code, errWithCode.ErrorCode.Code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantComponents, backup.Components)
+		})
+	}
+}
+
+func TestBackupService_StageRestore(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	ctrl := gomock.NewController(t)
+	backupRepo := mockgen.NewMockIBackupRepository(ctrl)
+	svc := NewBackupService(backupRepo, nil, nil, nil, fixtures.NewFakeClock(time.Unix(0, 0)), config.Config{})
+
+	backupRepo.EXPECT().StageRestore(gomock.Any(), "missing").Return(false, nil)
+	err := svc.StageRestore(context.Background(), "missing")
+	var errWithCode error_code.ErrorWithErrorCode
+	require.ErrorAs(t, err, &errWithCode)
+	require.Equal(t, error_code.BackupNotFound.Code, errWithCode.ErrorCode.Code)
+
+	backupRepo.EXPECT().StageRestore(gomock.Any(), "toolbake-backup-20260102T030405Z.tar.gz").Return(true, nil)
+	require.NoError(t, svc.StageRestore(context.Background(), "toolbake-backup-20260102T030405Z.tar.gz"))
+}
+
+func TestBackupService_Jobs(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	cfg := config.Config{ScheduleDatabaseBackup: "0 3 * * *"}
+
+	settingsService := newTestSystemSettingsService(ctrl, cfg)
+	svc := NewBackupService(nil, nil, nil, settingsService, fixtures.NewFakeClock(time.Unix(0, 0)), cfg)
+
+	jobs := svc.Jobs()
+	require.Len(t, jobs, 1)
+	require.Equal(t, BackupJobDatabaseBackup, jobs[0].Name)
+	spec, err := jobs[0].Spec(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "0 3 * * *", spec)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/buildinfo"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/utils"
+)
+
+// apiCapabilities are advertised to every client, a capability is only removed after MIN_CLIENT_VERSION left
+// every client relying on it behind.
+var apiCapabilities = []entity.APICapability{
+	entity.APICapabilitySyncV1,
+	entity.APICapabilityToolArchive,
+	entity.APICapabilityToolSchema,
+	entity.APICapabilityToolSearch,
+	entity.APICapabilityToolSecrets,
+	entity.APICapabilityGithubImport,
+	entity.APICapabilityToolNamespaces,
+	entity.APICapabilityToolSharing,
+	entity.APICapabilityToolGallery,
+	entity.APICapabilityToolBundles,
+	entity.APICapabilityPasskeyLogin,
+	entity.APICapabilityRateLimitHeaders,
+	entity.APICapabilityToolExecution,
+	entity.APICapabilityToolSchedules,
+	entity.APICapabilityToolInvocation,
+	entity.APICapabilityToolTags,
+	entity.APICapabilityToolFullTextSearch,
+	entity.APICapabilityToolListPages,
+	entity.APICapabilityToolSyncSince,
+	entity.APICapabilityUserDataExport,
+	entity.APICapabilityAccountDeletionGracePeriod,
+}
+
+func NewClientCompatibilityService(cfg config.Config) *ClientCompatibilityService {
+	return &ClientCompatibilityService{minClientVersion: cfg.MinClientVersion}
+}
+
+// ClientCompatibilityService detects version skew between the clients and the server, so a breaking change such
+// as a new sync protocol can be rolled out while old clients are warned instead of broken.
+type ClientCompatibilityService struct {
+	minClientVersion string
+}
+
+// Compatibility describes the server to a client of the given version, which may be empty.
+func (s *ClientCompatibilityService) Compatibility(clientVersion string) entity.ClientCompatibilityEntity {
+	return entity.ClientCompatibilityEntity{
+		ServerVersion:    buildinfo.Version,
+		MinClientVersion: s.minClientVersion,
+		ClientVersion:    clientVersion,
+		ClientDeprecated: s.IsClientDeprecated(clientVersion),
+		Capabilities:     apiCapabilities,
+	}
+}
+
+// MinClientVersion is the oldest supported client version, empty when every version is supported.
+func (s *ClientCompatibilityService) MinClientVersion() string {
+	return s.minClientVersion
+}
+
+// IsClientDeprecated reports whether a client version is older than MIN_CLIENT_VERSION. Clients that send no
+// version or one that can not be parsed, such as a browser build from a branch, are not flagged.
+func (s *ClientCompatibilityService) IsClientDeprecated(clientVersion string) bool {
+	if s.minClientVersion == "" || clientVersion == "" {
+		return false
+	}
+	compared, err := utils.CompareVersions(clientVersion, s.minClientVersion)
+	return err == nil && compared < 0
+}
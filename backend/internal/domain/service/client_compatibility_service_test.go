@@ -0,0 +1,41 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"ya-tool-craft/internal/config"
+)
+
+func TestClientCompatibilityService_IsClientDeprecated(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		minClientVersion string
+		clientVersion    string
+		want             bool
+	}{
+		{name: "no minimum", minClientVersion: "", clientVersion: "0.1.0", want: false},
+		{name: "no client version", minClientVersion: "1.4.0", clientVersion: "", want: false},
+		{name: "older client", minClientVersion: "1.4.0", clientVersion: "1.3.9", want: true},
+		{name: "same version", minClientVersion: "1.4.0", clientVersion: "v1.4.0", want: false},
+		{name: "newer client", minClientVersion: "1.4.0", clientVersion: "1.10.0", want: false},
+		{name: "unparsable client version", minClientVersion: "1.4.0", clientVersion: "feature-branch", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := NewClientCompatibilityService(config.Config{MinClientVersion: tt.minClientVersion})
+			assert.Equal(t, tt.want, svc.IsClientDeprecated(tt.clientVersion))
+
+			compatibility := svc.Compatibility(tt.clientVersion)
+			assert.Equal(t, tt.want, compatibility.ClientDeprecated)
+			assert.Equal(t, tt.minClientVersion, compatibility.MinClientVersion)
+			assert.NotEmpty(t, compatibility.Capabilities)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+func NewDeploymentCapabilitiesService(cfg config.Config, settingsService *SystemSettingsService) *DeploymentCapabilitiesService {
+	return &DeploymentCapabilitiesService{config: cfg, settingsService: settingsService}
+}
+
+// DeploymentCapabilitiesService reports the optional features enabled on this deployment from the env config and
+// the system settings admins can change at runtime.
+type DeploymentCapabilitiesService struct {
+	config          config.Config
+	settingsService *SystemSettingsService
+}
+
+// Capabilities returns the enabled features, the env config is used when the stored settings can not be read.
+func (s *DeploymentCapabilitiesService) Capabilities(ctx context.Context) entity.DeploymentCapabilitiesEntity {
+	settings, err := s.settingsService.Settings(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "failed to get system settings for deployment capabilities, fall back to env config: %v", err)
+		settings = s.settingsService.DefaultSettings()
+	}
+
+	ssoProviders := []string{}
+	if settings.EnableGithubSSO {
+		ssoProviders = append(ssoProviders, "github")
+	}
+	if settings.EnableGoogleSSO {
+		ssoProviders = append(ssoProviders, "google")
+	}
+	hasMailer := s.config.Mailer != "none"
+	return entity.DeploymentCapabilitiesEntity{
+		UserRegistration: settings.EnableUserRegistration,
+		PasswordLogin:    settings.EnablePasswordLogin,
+		SSOProviders:     ssoProviders,
+		// passkeys need no setup beyond the webauthn config, which the server refuses to start without
+		Passkeys: true,
+		// a reset sets a new password, it is of no use while password login is off
+		PasswordReset:   hasMailer && settings.EnablePasswordLogin,
+		AccountRecovery: hasMailer,
+		OIDCProvider:    s.config.OIDCProviderEnabled,
+		DemoMode:        s.config.DemoMode,
+		Gallery:         true,
+		ToolExecution:   s.config.ToolExecutionEnabled,
+		GraphQL:         s.config.GraphQLEnabled,
+	}
+}
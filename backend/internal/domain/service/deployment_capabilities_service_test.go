@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+func TestDeploymentCapabilitiesService_Capabilities(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		cfg       config.Config
+		overrides []entity.SystemSettingEntity
+		want      entity.DeploymentCapabilitiesEntity
+	}{
+		{
+			name: "nothing optional configured",
+			cfg:  config.Config{Mailer: "none"},
+			want: entity.DeploymentCapabilitiesEntity{SSOProviders: []string{}, Passkeys: true, Gallery: true},
+		},
+		{
+			name: "everything configured",
+			cfg: config.Config{
+				ENABLE_USER_REGISTRATION: true,
+				ENABLE_PASSWORD_LOGIN:    true,
+				SSO_GITHUB_CLIENT_ID:     "github-client",
+				SSO_GOOGLE_CLIENT_ID:     "google-client",
+				Mailer:                   "smtp",
+				OIDCProviderEnabled:      true,
+			},
+			want: entity.DeploymentCapabilitiesEntity{
+				UserRegistration: true,
+				PasswordLogin:    true,
+				SSOProviders:     []string{"github", "google"},
+				Passkeys:         true,
+				PasswordReset:    true,
+				AccountRecovery:  true,
+				OIDCProvider:     true,
+				Gallery:          true,
+			},
+		},
+		{
+			name: "demo mode closes registration",
+			cfg:  config.Config{ENABLE_USER_REGISTRATION: true, Mailer: "none", DemoMode: true},
+			want: entity.DeploymentCapabilitiesEntity{SSOProviders: []string{}, Passkeys: true, DemoMode: true, Gallery: true},
+		},
+		{
+			name: "tool execution follows the env config",
+			cfg:  config.Config{Mailer: "none", ToolExecutionEnabled: true},
+			want: entity.DeploymentCapabilitiesEntity{SSOProviders: []string{}, Passkeys: true, Gallery: true, ToolExecution: true},
+		},
+		{
+			name: "graphql follows the env config",
+			cfg:  config.Config{Mailer: "none", GraphQLEnabled: true},
+			want: entity.DeploymentCapabilitiesEntity{SSOProviders: []string{}, Passkeys: true, Gallery: true, GraphQL: true},
+		},
+		{
+			name: "system settings win over env config",
+			cfg: config.Config{
+				ENABLE_USER_REGISTRATION: true,
+				ENABLE_PASSWORD_LOGIN:    true,
+				SSO_GITHUB_CLIENT_ID:     "github-client",
+				Mailer:                   "log",
+			},
+			overrides: []entity.SystemSettingEntity{
+				{Key: entity.SystemSettingKeyUserRegistrationEnabled, Value: "false"},
+				{Key: entity.SystemSettingKeyPasswordLoginEnabled, Value: "false"},
+				{Key: entity.SystemSettingKeyGithubSSOEnabled, Value: "false"},
+			},
+			want: entity.DeploymentCapabilitiesEntity{SSOProviders: []string{}, Passkeys: true, AccountRecovery: true, Gallery: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			settingsService := newTestSystemSettingsService(gomock.NewController(t), tt.cfg, tt.overrides...)
+			svc := NewDeploymentCapabilitiesService(tt.cfg, settingsService)
+			require.Equal(t, tt.want, svc.Capabilities(context.Background()))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"ya-tool-craft/internal/core/logger"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	galleryDefaultLimit = 20
+	galleryMaxLimit     = 100
+)
+
+func NewGalleryService(
+	galleryRepo repository.IGalleryRepository,
+	toolRepo repository.IToolRepository,
+	toolService *ToolService,
+	clock domain_client.IClock,
+) *GalleryService {
+	return &GalleryService{
+		galleryRepo: galleryRepo,
+		toolRepo:    toolRepo,
+		toolService: toolService,
+		clock:       clock,
+	}
+}
+
+// GalleryService runs the public gallery, where users publish tools for everyone to find and copy into their own
+// tools. The gallery shows the current state of a published tool, archiving it hides it until it is restored.
+type GalleryService struct {
+	galleryRepo repository.IGalleryRepository
+	toolRepo    repository.IToolRepository
+	toolService *ToolService
+	clock       domain_client.IClock
+}
+
+// SetPublished publishes a tool of the owner to the gallery or takes it out, its counters are lost once it is taken out.
+func (s *GalleryService) SetPublished(ctx context.Context, ownerID entity.UserIDEntity, toolUID string, published bool) error {
+	tools, err := s.toolRepo.AllTools(ownerID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get tools of user %s", ownerID)
+	}
+	tool, ok := lo.Find(tools.Tools, func(tool entity.ToolEntity) bool { return tool.UniqueID == toolUID })
+	if !ok {
+		return error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "tool %s not found", toolUID)
+	}
+
+	if !published {
+		if _, err := s.galleryRepo.UnpublishTool(ctx, ownerID, toolUID); err != nil {
+			return errors.Wrapf(err, "fail to unpublish tool %s", toolUID)
+		}
+		return nil
+	}
+	if tool.IsArchived {
+		return error_code.NewErrorWithErrorCodef(error_code.ArchivedToolNotPublishable, "tool %s is archived", toolUID)
+	}
+	if err := s.galleryRepo.PublishTool(ctx, ownerID, toolUID); err != nil {
+		return errors.Wrapf(err, "fail to publish tool %s", toolUID)
+	}
+	return nil
+}
+
+// ListGallery returns a page of the published tools matching the filter and the number of all matching ones.
+func (s *GalleryService) ListGallery(ctx context.Context, filter entity.GalleryFilter) ([]entity.GalleryToolEntity, int, error) {
+	galleryTools, err := s.galleryRepo.ListPublished(ctx, filter)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "fail to list gallery tools")
+	}
+
+	// tags live in a free-form extra info value, so they are matched here instead of in the database
+	if tag := strings.ToLower(strings.TrimSpace(filter.Tag)); tag != "" {
+		galleryTools = lo.Filter(galleryTools, func(galleryTool entity.GalleryToolEntity, _ int) bool {
+			return slices.Contains(entity.ParseToolTags(galleryTool.Tool.ExtraInfo[entity.ToolExtraInfoKeyTags]), tag)
+		})
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = galleryDefaultLimit
+	}
+	limit = min(limit, galleryMaxLimit)
+	total := len(galleryTools)
+	start := min(max(filter.Offset, 0), total)
+	end := min(start+limit, total)
+	return galleryTools[start:end], total, nil
+}
+
+// GetGalleryTool returns a published tool with its source.
+func (s *GalleryService) GetGalleryTool(ctx context.Context, toolUID string) (entity.GalleryToolEntity, error) {
+	galleryTool, exists, err := s.galleryRepo.GetPublished(ctx, toolUID)
+	if err != nil {
+		return entity.GalleryToolEntity{}, errors.Wrapf(err, "fail to get gallery tool %s", toolUID)
+	}
+	if !exists {
+		return entity.GalleryToolEntity{}, error_code.NewErrorWithErrorCodef(error_code.GalleryToolNotFound, "gallery tool %s not found", toolUID)
+	}
+	return galleryTool, nil
+}
+
+// CopyTool saves a copy of a published tool as a tool of the user, with a new unique id, see
+// ToolService.SaveCopiedTool. The tool id gets a number, or a -fork suffix for a fork, when the user already has a
+// tool with the id. A fork records the gallery tool it came from in its extra info. It returns the saved tool and the
+// credentials found in its source.
+func (s *GalleryService) CopyTool(ctx context.Context, userID entity.UserIDEntity, toolUID string, kind entity.GalleryCopyKind) (entity.ToolEntity, []entity.ToolSecretFindingEntity, error) {
+	galleryTool, err := s.GetGalleryTool(ctx, toolUID)
+	if err != nil {
+		return entity.ToolEntity{}, nil, err
+	}
+	if galleryTool.OwnerID == userID {
+		return entity.ToolEntity{}, nil, error_code.NewErrorWithErrorCodef(error_code.GalleryToolIsYours, "gallery tool %s belongs to user %s", toolUID, userID)
+	}
+
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return entity.ToolEntity{}, nil, errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	var copied entity.ToolEntity
+	now := s.clock.Now().UTC()
+	if kind == entity.GalleryCopyFork {
+		copied = copyTool(galleryTool.Tool, tools.Tools, "-fork", map[string]string{entity.ToolExtraInfoKeyForkedFrom: toolUID}, now)
+	} else {
+		copied = copyTool(galleryTool.Tool, tools.Tools, "", nil, now)
+	}
+	saved, findings, err := s.toolService.SaveCopiedTool(ctx, userID, copied)
+	if err != nil {
+		return entity.ToolEntity{}, nil, err
+	}
+
+	// the tool is saved already, a lost count must not fail the copy
+	if err := s.RecordCopy(ctx, toolUID, kind); err != nil {
+		logger.Errorf(ctx, "Failed to count %s of gallery tool %s: %v", kind, toolUID, err)
+	}
+	return saved, findings, nil
+}
+
+// RecordCopy counts an install or a fork of a published tool once the copy was saved, CopyTool calls it.
+func (s *GalleryService) RecordCopy(ctx context.Context, toolUID string, kind entity.GalleryCopyKind) error {
+	increment := s.galleryRepo.IncrementInstallCount
+	if kind == entity.GalleryCopyFork {
+		increment = s.galleryRepo.IncrementForkCount
+	}
+	if err := increment(ctx, toolUID); err != nil {
+		return errors.Wrapf(err, "fail to count %s of gallery tool %s", kind, toolUID)
+	}
+	return nil
+}
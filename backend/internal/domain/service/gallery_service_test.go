@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+func TestGalleryService(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	ownerID := entity.UserIDEntity("owner-1")
+	userID := entity.UserIDEntity("user-1")
+	newService := func(t *testing.T) (*GalleryService, *mockgen.MockIGalleryRepository, *mockgen.MockIToolRepository) {
+		ctrl := gomock.NewController(t)
+		galleryRepo := mockgen.NewMockIGalleryRepository(ctrl)
+		toolService, toolRepo, _, _ := newTestToolBundleService(ctrl, config.Config{})
+		return NewGalleryService(galleryRepo, toolRepo, toolService, fixtures.NewFakeClock(time.Unix(100, 0))), galleryRepo, toolRepo
+	}
+	requireErrorCode := func(t *testing.T, err error, code error_code.ErrorCode) {
+		t.Helper()
+		var ecErr error_code.ErrorWithErrorCode
+		require.True(t, errors.As(err, &ecErr))
+		require.Equal(t, code.Code, ecErr.ErrorCode.Code)
+	}
+
+	t.Run("only tools that are not archived can be published", func(t *testing.T) {
+		t.Parallel()
+		svc, galleryRepo, toolRepo := newService(t)
+		ctx := context.Background()
+		active := fixtures.NewTestTool().WithUniqueID("tool-active").Build()
+		archived := fixtures.NewTestTool().WithUniqueID("tool-archived").WithArchived(true).Build()
+		toolRepo.EXPECT().AllTools(ownerID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{active, archived}}, nil).AnyTimes()
+		galleryRepo.EXPECT().PublishTool(ctx, ownerID, "tool-active").Return(nil)
+		galleryRepo.EXPECT().UnpublishTool(ctx, ownerID, "tool-archived").Return(true, nil)
+
+		requireErrorCode(t, svc.SetPublished(ctx, ownerID, "tool-missing", true), error_code.ToolNotFound)
+		requireErrorCode(t, svc.SetPublished(ctx, ownerID, "tool-archived", true), error_code.ArchivedToolNotPublishable)
+		require.NoError(t, svc.SetPublished(ctx, ownerID, "tool-active", true))
+		require.NoError(t, svc.SetPublished(ctx, ownerID, "tool-archived", false))
+	})
+
+	t.Run("list filters by tag and pages", func(t *testing.T) {
+		t.Parallel()
+		svc, galleryRepo, _ := newService(t)
+		ctx := context.Background()
+		tagged := func(uid string, tags string) entity.GalleryToolEntity {
+			return entity.GalleryToolEntity{Tool: fixtures.NewTestTool().WithUniqueID(uid).WithExtraInfo(map[string]string{entity.ToolExtraInfoKeyTags: tags}).Build()}
+		}
+		galleryRepo.EXPECT().ListPublished(ctx, gomock.Any()).Return([]entity.GalleryToolEntity{
+			tagged("tool-1", "json, format"),
+			tagged("tool-2", "css"),
+			tagged("tool-3", "JSON"),
+			tagged("tool-4", "jsonpath"),
+		}, nil).Times(2)
+
+		galleryTools, total, err := svc.ListGallery(ctx, entity.GalleryFilter{Tag: " Json ", Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		require.Equal(t, 2, total)
+		require.Len(t, galleryTools, 1)
+		require.Equal(t, "tool-3", galleryTools[0].Tool.UniqueID)
+
+		galleryTools, total, err = svc.ListGallery(ctx, entity.GalleryFilter{Offset: 10})
+		require.NoError(t, err)
+		require.Equal(t, 4, total)
+		require.Empty(t, galleryTools)
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		t.Parallel()
+		svc, galleryRepo, toolRepo := newService(t)
+		ctx := context.Background()
+		published := fixtures.NewTestTool().WithUniqueID("tool-published").WithID("json-format").WithExtraInfo(map[string]string{"tags": "json"}).Build()
+		galleryRepo.EXPECT().GetPublished(ctx, "tool-published").Return(entity.GalleryToolEntity{Tool: published, OwnerID: ownerID}, true, nil).AnyTimes()
+		galleryRepo.EXPECT().GetPublished(ctx, "tool-missing").Return(entity.GalleryToolEntity{}, false, nil)
+		toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{
+			fixtures.NewTestTool().WithID("json-format").Build(),
+		}}, nil).AnyTimes()
+		var saved []entity.ToolEntity
+		toolRepo.EXPECT().CreateTool(userID, gomock.Any(), gomock.Any()).DoAndReturn(func(_ entity.UserIDEntity, tool entity.ToolEntity, _ entity.ToolEventSourceEntity) error {
+			saved = append(saved, tool)
+			return nil
+		}).Times(2)
+		galleryRepo.EXPECT().IncrementInstallCount(ctx, "tool-published").Return(nil)
+		// a lost count does not fail the saved copy
+		galleryRepo.EXPECT().IncrementForkCount(ctx, "tool-published").Return(errors.New("db offline"))
+
+		_, _, err := svc.CopyTool(ctx, userID, "tool-missing", entity.GalleryCopyInstall)
+		requireErrorCode(t, err, error_code.GalleryToolNotFound)
+		_, _, err = svc.CopyTool(ctx, ownerID, "tool-published", entity.GalleryCopyInstall)
+		requireErrorCode(t, err, error_code.GalleryToolIsYours)
+
+		installed, _, err := svc.CopyTool(ctx, userID, "tool-published", entity.GalleryCopyInstall)
+		require.NoError(t, err)
+		require.Equal(t, "json-format-2", installed.ID)
+		require.NotEqual(t, published.UniqueID, installed.UniqueID)
+		require.Equal(t, map[string]string{"tags": "json"}, installed.ExtraInfo)
+
+		forked, _, err := svc.CopyTool(ctx, userID, "tool-published", entity.GalleryCopyFork)
+		require.NoError(t, err)
+		require.Equal(t, []entity.ToolEntity{installed, forked}, saved)
+		require.Equal(t, "json-format-fork", forked.ID)
+		require.Equal(t, "tool-published", forked.ExtraInfo[entity.ToolExtraInfoKeyForkedFrom])
+		require.Equal(t, time.Unix(100, 0).Unix(), forked.CreatedAt.Unix())
+		// the extra info of the published tool is not changed
+		require.NotContains(t, published.ExtraInfo, entity.ToolExtraInfoKeyForkedFrom)
+	})
+
+	t.Run("record copy", func(t *testing.T) {
+		t.Parallel()
+		svc, galleryRepo, _ := newService(t)
+		ctx := context.Background()
+		galleryRepo.EXPECT().IncrementInstallCount(ctx, "tool-published").Return(nil)
+		galleryRepo.EXPECT().IncrementForkCount(ctx, "tool-published").Return(nil)
+
+		require.NoError(t, svc.RecordCopy(ctx, "tool-published", entity.GalleryCopyInstall))
+		require.NoError(t, svc.RecordCopy(ctx, "tool-published", entity.GalleryCopyFork))
+	})
+}
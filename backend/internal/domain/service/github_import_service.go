@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	githubSSOProvider = "github"
+	// githubToolSourceExt is the extension of the files that can be imported, a tool source is a javascript handler
+	githubToolSourceExt = ".js"
+	// githubToolHandlerName is the file name of a tool laid out as a directory, the directory names the tool
+	githubToolHandlerName = "handler"
+	// githubToolUiWidgetsFile is read from the directory of the imported file, the tool has no widgets without it
+	githubToolUiWidgetsFile = "uiWidgets.json"
+	// githubGistNamespace is the namespace of the tools imported from gists, gists have no directories
+	githubGistNamespace = "gists"
+	githubToolIDPrefix  = "github-"
+	githubToolIDMaxLen  = 128
+)
+
+var githubToolIDInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func NewGithubImportService(
+	userRepo repository.IUserRepository,
+	toolRepo repository.IToolRepository,
+	githubClient client.IGithubRepoClient,
+	importScanService *ImportScanService,
+	toolService *ToolService,
+	namespaceService *NamespaceService,
+	meteringService *MeteringService,
+	clock client.IClock,
+) *GithubImportService {
+	return &GithubImportService{
+		userRepo:          userRepo,
+		toolRepo:          toolRepo,
+		githubClient:      githubClient,
+		importScanService: importScanService,
+		toolService:       toolService,
+		namespaceService:  namespaceService,
+		meteringService:   meteringService,
+		clock:             clock,
+	}
+}
+
+// GithubImportService imports tools from the repositories and gists of the GitHub account bound to a user,
+// with the api token kept from the GitHub login.
+type GithubImportService struct {
+	userRepo          repository.IUserRepository
+	toolRepo          repository.IToolRepository
+	githubClient      client.IGithubRepoClient
+	importScanService *ImportScanService
+	toolService       *ToolService
+	namespaceService  *NamespaceService
+	meteringService   *MeteringService
+	clock             client.IClock
+}
+
+// githubToolFile is a selected file fetched from GitHub, ready to become a tool
+type githubToolFile struct {
+	path      string
+	origin    string
+	namespace string
+	name      string
+	source    []byte
+	uiWidgets []byte
+}
+
+func (s *GithubImportService) ListRepos(ctx context.Context, userID entity.UserIDEntity, page int) (entity.GithubPageEntity[entity.GithubRepoEntity], error) {
+	token, err := s.accessToken(ctx, userID)
+	if err != nil {
+		return entity.GithubPageEntity[entity.GithubRepoEntity]{}, err
+	}
+	return s.githubClient.ListRepos(ctx, token, page)
+}
+
+func (s *GithubImportService) ListGists(ctx context.Context, userID entity.UserIDEntity, page int) (entity.GithubPageEntity[entity.GithubGistEntity], error) {
+	token, err := s.accessToken(ctx, userID)
+	if err != nil {
+		return entity.GithubPageEntity[entity.GithubGistEntity]{}, err
+	}
+	return s.githubClient.ListGists(ctx, token, page)
+}
+
+// ListRepoFiles lists the files of a repository that can be imported as tools.
+func (s *GithubImportService) ListRepoFiles(ctx context.Context, userID entity.UserIDEntity, owner string, repo string) ([]entity.GithubFileEntity, error) {
+	token, err := s.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.githubClient.ListRepoFiles(ctx, token, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Filter(files, func(file entity.GithubFileEntity, _ int) bool {
+		return path.Ext(file.Path) == githubToolSourceExt
+	}), nil
+}
+
+// ImportRepoFiles imports the selected files of a repository as tools, the directory of a file becomes the namespace.
+func (s *GithubImportService) ImportRepoFiles(ctx context.Context, userID entity.UserIDEntity, owner string, repo string, paths []string) ([]entity.GithubImportResultEntity, error) {
+	token, err := s.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []entity.GithubImportResultEntity
+	var files []githubToolFile
+	// directories often share one uiWidgets.json, fetch it once
+	uiWidgetsByDir := map[string][]byte{}
+	for _, filePath := range lo.Uniq(paths) {
+		if reason := githubToolFileSkipReason(filePath); reason != "" {
+			results = append(results, entity.GithubImportResultEntity{Path: filePath, Reason: reason})
+			continue
+		}
+
+		source, err := s.githubClient.GetRepoFile(ctx, token, owner, repo, filePath)
+		if isErrorCode(err, error_code.GithubRepositoryNotFound) {
+			results = append(results, entity.GithubImportResultEntity{Path: filePath, Reason: "file not found in the default branch"})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		dir := path.Dir(filePath)
+		uiWidgets, ok := uiWidgetsByDir[dir]
+		if !ok {
+			uiWidgets, err = s.githubClient.GetRepoFile(ctx, token, owner, repo, path.Join(dir, githubToolUiWidgetsFile))
+			if isErrorCode(err, error_code.GithubRepositoryNotFound) {
+				uiWidgets, err = nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			uiWidgetsByDir[dir] = uiWidgets
+		}
+
+		namespace, name := githubToolLocation(repo, repo, filePath)
+		files = append(files, githubToolFile{
+			path:      filePath,
+			origin:    fmt.Sprintf("%s/%s/%s", owner, repo, filePath),
+			namespace: namespace,
+			name:      name,
+			source:    source,
+			uiWidgets: uiWidgets,
+		})
+	}
+
+	imported, err := s.importFiles(ctx, userID, files)
+	if err != nil {
+		return nil, err
+	}
+	return append(results, imported...), nil
+}
+
+// ImportGistFiles imports the selected files of a gist as tools in the gists namespace.
+func (s *GithubImportService) ImportGistFiles(ctx context.Context, userID entity.UserIDEntity, gistID string, names []string) ([]entity.GithubImportResultEntity, error) {
+	token, err := s.accessToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	gistFiles, err := s.githubClient.GetGistFiles(ctx, token, gistID)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []entity.GithubImportResultEntity
+	var files []githubToolFile
+	for _, name := range lo.Uniq(names) {
+		if reason := githubToolFileSkipReason(name); reason != "" {
+			results = append(results, entity.GithubImportResultEntity{Path: name, Reason: reason})
+			continue
+		}
+		source, ok := gistFiles[name]
+		if !ok {
+			results = append(results, entity.GithubImportResultEntity{Path: name, Reason: "file not found in the gist"})
+			continue
+		}
+
+		namespace, toolName := githubToolLocation(githubGistNamespace, gistID, name)
+		files = append(files, githubToolFile{
+			path:      name,
+			origin:    fmt.Sprintf("gist/%s/%s", gistID, name),
+			namespace: namespace,
+			name:      toolName,
+			source:    source,
+			uiWidgets: gistFiles[githubToolUiWidgetsFile],
+		})
+	}
+
+	imported, err := s.importFiles(ctx, userID, files)
+	if err != nil {
+		return nil, err
+	}
+	return append(results, imported...), nil
+}
+
+// importFiles passes the fetched files through the import scan and stores every one that is not skipped.
+// A file is skipped when its tool id is taken, its widgets are invalid, its source is blocked by the secret
+// scan or the tool quota is reached, the other files are still imported.
+func (s *GithubImportService) importFiles(ctx context.Context, userID entity.UserIDEntity, files []githubToolFile) ([]entity.GithubImportResultEntity, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	// the widgets are scanned apart from the sources, they must not eat into the files limit of the import
+	sources := lo.Map(files, func(file githubToolFile, _ int) entity.ImportFileEntity {
+		return entity.ImportFileEntity{Name: file.path, Content: file.source}
+	})
+	if err := s.importScanService.ScanImportFiles(ctx, sources); err != nil {
+		return nil, err
+	}
+	uiWidgets := lo.UniqBy(lo.FilterMap(files, func(file githubToolFile, _ int) (entity.ImportFileEntity, bool) {
+		return entity.ImportFileEntity{Name: path.Join(path.Dir(file.path), githubToolUiWidgetsFile), Content: file.uiWidgets}, file.uiWidgets != nil
+	}), func(file entity.ImportFileEntity) string {
+		return file.Name
+	})
+	if err := s.importScanService.ScanImportFiles(ctx, uiWidgets); err != nil {
+		return nil, err
+	}
+
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	existingIDs := lo.SliceToMap(tools.Tools, func(tool entity.ToolEntity) (string, bool) {
+		return tool.ID, true
+	})
+
+	results := make([]entity.GithubImportResultEntity, 0, len(files))
+	quotaExceeded := false
+	for _, file := range files {
+		result := entity.GithubImportResultEntity{Path: file.path, ToolID: githubToolID(file.origin)}
+		if quotaExceeded {
+			result.Reason = "tool quota exceeded"
+			results = append(results, result)
+			continue
+		}
+		if existingIDs[result.ToolID] {
+			result.Reason = "a tool with the same id already exists"
+			results = append(results, result)
+			continue
+		}
+
+		defaults, err := s.namespaceService.ToolDefaults(ctx, userID, file.namespace)
+		if err != nil {
+			return nil, err
+		}
+		// a tool without its own widgets starts from the skeleton of its namespace
+		widgets := "[]"
+		if defaults.DefaultUiWidgets != "" {
+			widgets = defaults.DefaultUiWidgets
+		}
+		if file.uiWidgets != nil {
+			var parsed []json.RawMessage
+			if err := json.Unmarshal(file.uiWidgets, &parsed); err != nil {
+				result.Reason = githubToolUiWidgetsFile + " is not a json array"
+				results = append(results, result)
+				continue
+			}
+			widgets = string(file.uiWidgets)
+		}
+
+		scan := s.toolService.ScanToolSource(string(file.source))
+		result.Warnings = scan.Findings
+		if scan.Blocked {
+			result.Reason = "source contains credentials"
+			results = append(results, result)
+			continue
+		}
+
+		if err := s.toolService.CheckToolQuota(ctx, userID); err != nil {
+			if !isErrorCode(err, error_code.ToolQuotaExceeded) {
+				return nil, err
+			}
+			quotaExceeded = true
+			result.Reason = "tool quota exceeded"
+			results = append(results, result)
+			continue
+		}
+
+		now := s.clock.Now()
+		tool := entity.NewToolEntityWithoutUID(
+			result.ToolID,
+			file.name,
+			file.namespace,
+			defaults.DefaultCategory,
+			true,
+			defaults.DefaultRealtimeExecution,
+			widgets,
+			string(file.source),
+			"",
+			map[string]string{entity.ToolExtraInfoKeyGithubSource: file.origin},
+			now,
+			now,
+		)
+		if err := s.meteringService.CheckToolStorage(ctx, userID, tool); err != nil {
+			if !isErrorCode(err, error_code.UsageLimitExceeded) {
+				return nil, err
+			}
+			result.Reason = "usage limit exceeded"
+			results = append(results, result)
+			continue
+		}
+		if _, err := createToolWithFreeUID(s.toolRepo, userID, tool, ToolEventSourceFromContext(ctx, userID)); err != nil {
+			if !errors.Is(err, repository.ErrToolNameTaken) {
+				return nil, errors.Wrapf(err, "fail to create tool %s imported from github", tool.ID)
+			}
+			result.Reason = "a tool with the same name already exists in the namespace"
+			results = append(results, result)
+			continue
+		}
+		existingIDs[tool.ID] = true
+		result.Imported = true
+		results = append(results, result)
+		logger.Infof(ctx, "Tool %s imported from github %s for user %s", tool.ID, file.origin, userID)
+	}
+	return results, nil
+}
+
+func (s *GithubImportService) accessToken(ctx context.Context, userID entity.UserIDEntity) (string, error) {
+	token, found, err := s.userRepo.GetUserSSOAccessToken(ctx, userID, githubSSOProvider)
+	if err != nil {
+		return "", errors.Wrapf(err, "fail to get github access token of user %s", userID)
+	}
+	if !found {
+		return "", error_code.NewErrorWithErrorCodef(error_code.GithubAccountNotAuthorized, "user %s has no github access token, log in with github or bind it again", userID)
+	}
+	return token, nil
+}
+
+// githubToolFileSkipReason rejects the selected files that cannot be a tool source, empty when it can be
+func githubToolFileSkipReason(filePath string) string {
+	if path.Ext(filePath) != githubToolSourceExt {
+		return "only " + githubToolSourceExt + " files can be imported as tools"
+	}
+	return ""
+}
+
+// githubToolLocation maps the path of a file to the namespace and name of its tool.
+// tools/json/escape.js is escape in tools/json, tools/json/escape/handler.js is escape in tools/json as well,
+// files at the root are put in rootNamespace and a root handler.js is named rootName.
+func githubToolLocation(rootNamespace string, rootName string, filePath string) (namespace string, name string) {
+	dir := path.Dir(filePath)
+	name = strings.TrimSuffix(path.Base(filePath), path.Ext(filePath))
+	if name == githubToolHandlerName {
+		if dir == "." {
+			return rootNamespace, rootName
+		}
+		name = path.Base(dir)
+		dir = path.Dir(dir)
+	}
+	if dir == "." {
+		return rootNamespace, name
+	}
+	return dir, name
+}
+
+// githubToolID derives a stable tool id from where the file came from, importing it again finds the same tool.
+// Ids too long for a tool are cut and made unique again with a hash of the origin.
+func githubToolID(origin string) string {
+	id := githubToolIDPrefix + strings.Trim(githubToolIDInvalidChars.ReplaceAllString(strings.ToLower(origin), "-"), "-")
+	if len(id) <= githubToolIDMaxLen {
+		return id
+	}
+	hash := utils.Sha256String(origin)[:8]
+	return id[:githubToolIDMaxLen-len(hash)-1] + "-" + hash
+}
+
+func isErrorCode(err error, code error_code.ErrorCode) bool {
+	var codeErr error_code.ErrorWithErrorCode
+	return errors.As(err, &codeErr) && codeErr.ErrorCode.Code == code.Code
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGithubRepoClient serves the files of one repository and one gist.
+type fakeGithubRepoClient struct {
+	repoFiles map[string]string
+	gistFiles map[string]string
+}
+
+func (f *fakeGithubRepoClient) ListRepos(ctx context.Context, accessToken string, page int) (entity.GithubPageEntity[entity.GithubRepoEntity], error) {
+	return entity.GithubPageEntity[entity.GithubRepoEntity]{Items: []entity.GithubRepoEntity{{Owner: "octo", Name: "tools"}}}, nil
+}
+
+func (f *fakeGithubRepoClient) ListGists(ctx context.Context, accessToken string, page int) (entity.GithubPageEntity[entity.GithubGistEntity], error) {
+	return entity.GithubPageEntity[entity.GithubGistEntity]{}, nil
+}
+
+func (f *fakeGithubRepoClient) ListRepoFiles(ctx context.Context, accessToken string, owner string, repo string) ([]entity.GithubFileEntity, error) {
+	var files []entity.GithubFileEntity
+	for path, content := range f.repoFiles {
+		files = append(files, entity.GithubFileEntity{Path: path, Size: len(content)})
+	}
+	return files, nil
+}
+
+func (f *fakeGithubRepoClient) GetRepoFile(ctx context.Context, accessToken string, owner string, repo string, path string) ([]byte, error) {
+	content, ok := f.repoFiles[path]
+	if !ok {
+		return nil, error_code.NewErrorWithErrorCodef(error_code.GithubRepositoryNotFound, "github resource not found")
+	}
+	return []byte(content), nil
+}
+
+func (f *fakeGithubRepoClient) GetGistFiles(ctx context.Context, accessToken string, gistID string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for name, content := range f.gistFiles {
+		files[name] = []byte(content)
+	}
+	return files, nil
+}
+
+func newTestGithubImportService(ctrl *gomock.Controller, githubClient *fakeGithubRepoClient, cfg config.Config, overrides ...entity.SystemSettingEntity) (*GithubImportService, *mockgen.MockIUserRepository, *mockgen.MockIToolRepository, *mockgen.MockINamespaceRepository) {
+	userRepo := mockgen.NewMockIUserRepository(ctrl)
+	toolRepo := mockgen.NewMockIToolRepository(ctrl)
+	namespaceRepo := mockgen.NewMockINamespaceRepository(ctrl)
+	clean := scannerFunc(func(context.Context, entity.ImportFileEntity) (entity.ContentScanResultEntity, error) {
+		return entity.ContentScanResultEntity{Clean: true}, nil
+	})
+	importScanService := NewImportScanService(clean, config.Config{ImportMaxFileSize: 1024, ImportMaxFiles: 10})
+	toolService := NewToolService(toolRepo, nil, newTestSystemSettingsService(ctrl, cfg, overrides...), nil, nil, nil, nil, cfg)
+	clock := fixtures.NewFakeClock(time.Unix(100, 0))
+	meteringService := NewMeteringService(nil, toolRepo, nil, clock, cfg)
+	svc := NewGithubImportService(userRepo, toolRepo, githubClient, importScanService, toolService, NewNamespaceService(namespaceRepo), meteringService, clock)
+	return svc, userRepo, toolRepo, namespaceRepo
+}
+
+func TestGithubImportService_ImportRepoFiles(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	userID := entity.UserIDEntity("user-1")
+
+	githubToken := "ghp_" + strings.Repeat("a1B2", 9)
+	githubClient := &fakeGithubRepoClient{repoFiles: map[string]string{
+		"tools/json/escape/handler.js":     "async function handler() {}",
+		"tools/json/escape/uiWidgets.json": `[{"id":"input"}]`,
+		"tools/json/format.js":             "async function handler() { return 1 }",
+		"tools/bad/handler.js":             "async function handler() {}",
+		"tools/bad/uiWidgets.json":         `{"id":"input"}`,
+		"leak.js":                          "const token = '" + githubToken + "'",
+		"existing.js":                      "async function handler() {}",
+		"README.md":                        "# tools",
+	}}
+	svc, userRepo, toolRepo, namespaceRepo := newTestGithubImportService(ctrl, githubClient, config.Config{ToolSecretScanMode: ToolSecretScanModeBlock})
+	namespaceRepo.EXPECT().GetNamespace(ctx, userID, "tools/json").Return(entity.NamespaceEntity{
+		Name: "tools/json", DefaultCategory: "json", DefaultRealtimeExecution: true, DefaultUiWidgets: `[{"id":"skeleton"}]`,
+	}, true, nil).AnyTimes()
+	namespaceRepo.EXPECT().GetNamespace(ctx, userID, gomock.Any()).Return(entity.NamespaceEntity{}, false, nil).AnyTimes()
+
+	userRepo.EXPECT().GetUserSSOAccessToken(ctx, userID, "github").Return("gho_token", true, nil)
+	existing := fixtures.NewTestTool().WithID("github-octo-tools-existing-js").Build()
+	toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{existing}}, nil).AnyTimes()
+
+	var created []entity.ToolEntity
+	toolRepo.EXPECT().CreateTool(userID, gomock.Any(), gomock.Any()).DoAndReturn(func(_ entity.UserIDEntity, tool entity.ToolEntity, _ entity.ToolEventSourceEntity) error {
+		created = append(created, tool)
+		return nil
+	}).Times(2)
+
+	results, err := svc.ImportRepoFiles(ctx, userID, "octo", "tools", []string{
+		"README.md",
+		"missing.js",
+		"tools/json/escape/handler.js",
+		"tools/json/format.js",
+		"tools/json/format.js",
+		"tools/bad/handler.js",
+		"leak.js",
+		"existing.js",
+	})
+	require.NoError(t, err)
+
+	reasons := map[string]string{}
+	for _, result := range results {
+		reasons[result.Path] = result.Reason
+	}
+	require.Equal(t, map[string]string{
+		"README.md":                    "only .js files can be imported as tools",
+		"missing.js":                   "file not found in the default branch",
+		"tools/json/escape/handler.js": "",
+		"tools/json/format.js":         "",
+		"tools/bad/handler.js":         "uiWidgets.json is not a json array",
+		"leak.js":                      "source contains credentials",
+		"existing.js":                  "a tool with the same id already exists",
+	}, reasons)
+
+	require.Len(t, created, 2)
+	require.Equal(t, "github-octo-tools-tools-json-escape-handler-js", created[0].ID)
+	require.Equal(t, "escape", created[0].Name)
+	require.Equal(t, "tools/json", created[0].Namespace)
+	require.Equal(t, `[{"id":"input"}]`, created[0].UiWidgets)
+	require.Equal(t, "octo/tools/tools/json/escape/handler.js", created[0].ExtraInfo[entity.ToolExtraInfoKeyGithubSource])
+	require.Equal(t, "format", created[1].Name)
+	require.Equal(t, "tools/json", created[1].Namespace)
+	// the namespace defaults fill what the files do not bring
+	require.Equal(t, `[{"id":"skeleton"}]`, created[1].UiWidgets)
+	for _, tool := range created {
+		require.Equal(t, "json", tool.Category)
+		require.True(t, tool.RealtimeExecution)
+	}
+}
+
+func TestGithubImportService_ImportGistFilesStopsAtQuota(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	userID := entity.UserIDEntity("user-1")
+
+	githubClient := &fakeGithubRepoClient{gistFiles: map[string]string{
+		"handler.js": "async function handler() {}",
+		"second.js":  "async function handler() {}",
+	}}
+	svc, userRepo, toolRepo, namespaceRepo := newTestGithubImportService(ctrl, githubClient, config.Config{},
+		entity.SystemSettingEntity{Key: entity.SystemSettingKeyMaxToolsPerUser, Value: "1"},
+	)
+
+	userRepo.EXPECT().GetUserSSOAccessToken(ctx, userID, "github").Return("gho_token", true, nil)
+	namespaceRepo.EXPECT().GetNamespace(ctx, userID, "gists").Return(entity.NamespaceEntity{}, false, nil).AnyTimes()
+	var created []entity.ToolEntity
+	toolRepo.EXPECT().AllTools(userID).DoAndReturn(func(entity.UserIDEntity) (entity.ToolsEntity, error) {
+		return entity.ToolsEntity{Tools: created}, nil
+	}).AnyTimes()
+	toolRepo.EXPECT().CreateTool(userID, gomock.Any(), gomock.Any()).DoAndReturn(func(_ entity.UserIDEntity, tool entity.ToolEntity, _ entity.ToolEventSourceEntity) error {
+		created = append(created, tool)
+		return nil
+	})
+
+	results, err := svc.ImportGistFiles(ctx, userID, "abc123", []string{"handler.js", "second.js"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.True(t, results[0].Imported)
+	require.Equal(t, "github-gist-abc123-handler-js", results[0].ToolID)
+	require.Equal(t, "tool quota exceeded", results[1].Reason)
+
+	require.Len(t, created, 1)
+	require.Equal(t, "abc123", created[0].Name)
+	require.Equal(t, "gists", created[0].Namespace)
+	require.Equal(t, "[]", created[0].UiWidgets)
+}
+
+func TestGithubImportService_WithoutToken(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	svc, userRepo, _, _ := newTestGithubImportService(ctrl, &fakeGithubRepoClient{}, config.Config{})
+	userRepo.EXPECT().GetUserSSOAccessToken(ctx, entity.UserIDEntity("user-1"), "github").Return("", false, nil)
+
+	_, err := svc.ListRepos(ctx, "user-1", 1)
+	require.True(t, isErrorCode(err, error_code.GithubAccountNotAuthorized))
+}
+
+func TestGithubToolLocation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path          string
+		wantNamespace string
+		wantName      string
+	}{
+		{path: "escape.js", wantNamespace: "repo", wantName: "escape"},
+		{path: "handler.js", wantNamespace: "repo", wantName: "root"},
+		{path: "json/escape.js", wantNamespace: "json", wantName: "escape"},
+		{path: "json/escape/handler.js", wantNamespace: "json", wantName: "escape"},
+		{path: "escape/handler.js", wantNamespace: "repo", wantName: "escape"},
+	}
+	for _, tt := range tests {
+		namespace, name := githubToolLocation("repo", "root", tt.path)
+		require.Equal(t, tt.wantNamespace, namespace, tt.path)
+		require.Equal(t, tt.wantName, name, tt.path)
+	}
+}
+
+func TestGithubToolID(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "github-octo-my-tools-json-escape-js", githubToolID("octo/My Tools/json/escape.js"))
+
+	long := githubToolID("octo/tools/" + strings.Repeat("nested/", 30) + "handler.js")
+	require.Len(t, long, githubToolIDMaxLen)
+	require.NotEqual(t, long, githubToolID("octo/tools/"+strings.Repeat("nested/", 30)+"other.js"))
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/metrics"
+	"ya-tool-craft/internal/core/scheduler"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	HousekeepingJobRefreshTokenCleanup      = "refresh_token_cleanup"
+	HousekeepingJobKeyValueCompaction       = "key_value_compaction"
+	HousekeepingJobDataRetention            = "data_retention"
+	HousekeepingJobExpiredCachePurge        = "expired_cache_purge"
+	HousekeepingJobPasskeyChallengeEviction = "passkey_challenge_eviction"
+
+	// dataRetentionCategoryAuditLogs labels the purged audit logs in the metrics
+	dataRetentionCategoryAuditLogs = "audit_logs"
+)
+
+func NewHousekeepingService(
+	userRepo repository.IUserRepository,
+	refreshTokenRepo repository.IAuthRefreshTokenRepository,
+	keyValueMaintenance repository.IKeyValueStoreMaintenance,
+	cacheMaintenance repository.ICacheMaintenance,
+	auditLogRepo repository.IAuditLogRepository,
+	challengeLimiter *PasskeyChallengeLimiter,
+	settingsService *SystemSettingsService,
+	clock domain_client.IClock,
+	cfg config.Config,
+) *HousekeepingService {
+	return &HousekeepingService{
+		userRepo:            userRepo,
+		refreshTokenRepo:    refreshTokenRepo,
+		keyValueMaintenance: keyValueMaintenance,
+		cacheMaintenance:    cacheMaintenance,
+		auditLogRepo:        auditLogRepo,
+		challengeLimiter:    challengeLimiter,
+		settingsService:     settingsService,
+		clock:               clock,
+		config:              cfg,
+	}
+}
+
+// HousekeepingService holds the periodic cleanup jobs the scheduler runs.
+type HousekeepingService struct {
+	userRepo            repository.IUserRepository
+	refreshTokenRepo    repository.IAuthRefreshTokenRepository
+	keyValueMaintenance repository.IKeyValueStoreMaintenance
+	cacheMaintenance    repository.ICacheMaintenance
+	auditLogRepo        repository.IAuditLogRepository
+	challengeLimiter    *PasskeyChallengeLimiter
+	settingsService     *SystemSettingsService
+	clock               domain_client.IClock
+	config              config.Config
+}
+
+// Jobs returns the housekeeping jobs, their schedules come from the system settings.
+func (s *HousekeepingService) Jobs() []scheduler.Job {
+	jobs := []scheduler.Job{
+		{
+			Name:        HousekeepingJobRefreshTokenCleanup,
+			Description: "Drops expired refresh tokens from the token index of every user",
+			Spec: func(ctx context.Context) (string, error) {
+				settings, err := s.settingsService.Settings(ctx)
+				return settings.RefreshTokenCleanupSchedule, err
+			},
+			Run: s.CleanupRefreshTokens,
+		},
+		{
+			Name:        HousekeepingJobPasskeyChallengeEviction,
+			Description: "Forgets the expired passkey login challenges counted by the challenge limits",
+			Spec: func(ctx context.Context) (string, error) {
+				settings, err := s.settingsService.Settings(ctx)
+				return settings.PasskeyChallengeEvictionSchedule, err
+			},
+			Run: s.EvictPasskeyChallenges,
+		},
+	}
+	// redis expires and reclaims keys on its own
+	if s.config.KeyValueDBType == "nutsdb" {
+		jobs = append(jobs, scheduler.Job{
+			Name:        HousekeepingJobKeyValueCompaction,
+			Description: "Merges the nutsdb data files to reclaim the space of deleted and expired entries",
+			Spec: func(ctx context.Context) (string, error) {
+				settings, err := s.settingsService.Settings(ctx)
+				return settings.KeyValueCompactionSchedule, err
+			},
+			Run: s.CompactKeyValueStore,
+		})
+	}
+	// the backends expire their keys, only the memory fallback keeps expired 2FA tokens and challenges around
+	if s.config.CacheFallbackEnabled {
+		jobs = append(jobs, scheduler.Job{
+			Name:        HousekeepingJobExpiredCachePurge,
+			Description: "Drops the expired 2FA tokens and passkey challenges from the memory cache fallback",
+			Spec: func(ctx context.Context) (string, error) {
+				settings, err := s.settingsService.Settings(ctx)
+				return settings.ExpiredCachePurgeSchedule, err
+			},
+			Run: s.PurgeExpiredCache,
+		})
+	}
+	// without a retention window there is nothing to delete
+	if s.config.AuditLogRetentionDays > 0 {
+		jobs = append(jobs, scheduler.Job{
+			Name:        HousekeepingJobDataRetention,
+			Description: "Deletes the audit logs older than AUDIT_LOG_RETENTION_DAYS",
+			Spec: func(ctx context.Context) (string, error) {
+				settings, err := s.settingsService.Settings(ctx)
+				return settings.DataRetentionSchedule, err
+			},
+			Run: s.PurgeExpiredData,
+		})
+	}
+	return jobs
+}
+
+// CleanupRefreshTokens walks every user and drops the expired tokens from their token index.
+// A failing user does not stop the others, the failures are reported together.
+func (s *HousekeepingService) CleanupRefreshTokens(ctx context.Context) error {
+	userIDs, err := s.userRepo.AllUserIDs(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to list users")
+	}
+
+	failed := 0
+	var lastErr error
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.refreshTokenRepo.CleanupExpiredTokenHashesForUser(ctx, userID); err != nil {
+			logger.Warnf(ctx, "Failed to clean up the refresh tokens of user %s: %v", userID, err)
+			failed++
+			lastErr = err
+		}
+	}
+	if failed > 0 {
+		return errors.Wrapf(lastErr, "failed to clean up the refresh tokens of %d of %d users, last error", failed, len(userIDs))
+	}
+	logger.Infof(ctx, "Cleaned up the refresh tokens of %d users", len(userIDs))
+	return nil
+}
+
+// CompactKeyValueStore reclaims the disk space of the key-value store.
+func (s *HousekeepingService) CompactKeyValueStore(ctx context.Context) error {
+	return s.keyValueMaintenance.Compact(ctx)
+}
+
+// PurgeExpiredCache drops the expired entries the cache keeps in memory.
+func (s *HousekeepingService) PurgeExpiredCache(ctx context.Context) error {
+	purged, err := s.cacheMaintenance.PurgeExpired(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to purge the expired cache entries")
+	}
+	logger.Infof(ctx, "Purged %d expired cache entries", purged)
+	return nil
+}
+
+// EvictPasskeyChallenges forgets the passkey login challenges that expired without being answered.
+func (s *HousekeepingService) EvictPasskeyChallenges(ctx context.Context) error {
+	evicted := s.challengeLimiter.EvictExpired(ctx)
+	logger.Infof(ctx, "Evicted %d expired passkey login challenges", evicted)
+	return nil
+}
+
+// PurgeExpiredData deletes the records older than the retention window of their category, categories without a
+// window are kept forever.
+func (s *HousekeepingService) PurgeExpiredData(ctx context.Context) error {
+	if s.config.AuditLogRetentionDays > 0 {
+		before := s.clock.Now().AddDate(0, 0, -s.config.AuditLogRetentionDays)
+		deleted, err := s.auditLogRepo.DeleteCreatedBefore(ctx, before)
+		if err != nil {
+			return errors.Wrap(err, "failed to purge audit logs")
+		}
+		metrics.DataRetentionPurgedRowsTotal.WithLabelValues(dataRetentionCategoryAuditLogs).Add(float64(deleted))
+		logger.Infof(ctx, "Purged %d audit logs created before %s", deleted, before.Format(time.RFC3339))
+	}
+	return nil
+}
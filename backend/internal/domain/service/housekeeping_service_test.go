@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+func TestHousekeepingService_Jobs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		cfg       config.Config
+		overrides []entity.SystemSettingEntity
+		wantSpecs map[string]string
+	}{
+		{
+			name: "nutsdb compacts the store, schedules from env config",
+			cfg: config.Config{
+				KeyValueDBType:                   "nutsdb",
+				ScheduleRefreshTokenCleanup:      "0 4 * * *",
+				ScheduleKeyValueCompaction:       "30 4 * * *",
+				SchedulePasskeyChallengeEviction: "@every 5m",
+			},
+			wantSpecs: map[string]string{
+				HousekeepingJobRefreshTokenCleanup:      "0 4 * * *",
+				HousekeepingJobKeyValueCompaction:       "30 4 * * *",
+				HousekeepingJobPasskeyChallengeEviction: "@every 5m",
+			},
+		},
+		{
+			name: "redis has no compaction, schedule overridden by setting",
+			cfg: config.Config{
+				KeyValueDBType:              "redis",
+				ScheduleRefreshTokenCleanup: "0 4 * * *",
+			},
+			overrides: []entity.SystemSettingEntity{
+				{Key: entity.SystemSettingKeyRefreshTokenCleanupSchedule, Value: "@hourly"},
+			},
+			wantSpecs: map[string]string{
+				HousekeepingJobRefreshTokenCleanup:      "@hourly",
+				HousekeepingJobPasskeyChallengeEviction: "",
+			},
+		},
+		{
+			name: "audit log retention adds the data retention job",
+			cfg: config.Config{
+				KeyValueDBType:              "redis",
+				ScheduleRefreshTokenCleanup: "0 4 * * *",
+				ScheduleDataRetention:       "0 5 * * *",
+				AuditLogRetentionDays:       90,
+			},
+			wantSpecs: map[string]string{
+				HousekeepingJobRefreshTokenCleanup:      "0 4 * * *",
+				HousekeepingJobDataRetention:            "0 5 * * *",
+				HousekeepingJobPasskeyChallengeEviction: "",
+			},
+		},
+		{
+			name: "the cache fallback adds the expired cache purge job",
+			cfg: config.Config{
+				KeyValueDBType:                   "redis",
+				CacheFallbackEnabled:             true,
+				ScheduleExpiredCachePurge:        "@every 15m",
+				SchedulePasskeyChallengeEviction: "@every 5m",
+			},
+			overrides: []entity.SystemSettingEntity{
+				{Key: entity.SystemSettingKeyExpiredCachePurgeSchedule, Value: "@every 1m"},
+			},
+			wantSpecs: map[string]string{
+				HousekeepingJobRefreshTokenCleanup:      "",
+				HousekeepingJobExpiredCachePurge:        "@every 1m",
+				HousekeepingJobPasskeyChallengeEviction: "@every 5m",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			settingsService := newTestSystemSettingsService(ctrl, tt.cfg, tt.overrides...)
+			svc := NewHousekeepingService(nil, nil, nil, nil, nil, nil, settingsService, nil, tt.cfg)
+
+			specs := map[string]string{}
+			for _, job := range svc.Jobs() {
+				spec, err := job.Spec(context.Background())
+				require.NoError(t, err)
+				specs[job.Name] = spec
+			}
+			require.Equal(t, tt.wantSpecs, specs)
+		})
+	}
+}
+
+func TestHousekeepingService_CleanupRefreshTokens(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	tests := []struct {
+		name       string
+		setupMocks func(userRepo *mockgen.MockIUserRepository, refreshTokenRepo *mockgen.MockIAuthRefreshTokenRepository)
+		wantErrSub string
+	}{
+		{
+			name: "cleans up every user",
+			setupMocks: func(userRepo *mockgen.MockIUserRepository, refreshTokenRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				userRepo.EXPECT().AllUserIDs(gomock.Any()).Return([]entity.UserIDEntity{"u-1", "u-2"}, nil)
+				refreshTokenRepo.EXPECT().CleanupExpiredTokenHashesForUser(gomock.Any(), entity.UserIDEntity("u-1")).Return(nil)
+				refreshTokenRepo.EXPECT().CleanupExpiredTokenHashesForUser(gomock.Any(), entity.UserIDEntity("u-2")).Return(nil)
+			},
+		},
+		{
+			name: "a failing user does not stop the others",
+			setupMocks: func(userRepo *mockgen.MockIUserRepository, refreshTokenRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				userRepo.EXPECT().AllUserIDs(gomock.Any()).Return([]entity.UserIDEntity{"u-1", "u-2"}, nil)
+				refreshTokenRepo.EXPECT().CleanupExpiredTokenHashesForUser(gomock.Any(), entity.UserIDEntity("u-1")).Return(errors.New("store unavailable"))
+				refreshTokenRepo.EXPECT().CleanupExpiredTokenHashesForUser(gomock.Any(), entity.UserIDEntity("u-2")).Return(nil)
+			},
+			wantErrSub: "1 of 2 users",
+		},
+		{
+			name: "listing users fails",
+			setupMocks: func(userRepo *mockgen.MockIUserRepository, refreshTokenRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				userRepo.EXPECT().AllUserIDs(gomock.Any()).Return(nil, errors.New("db down"))
+			},
+			wantErrSub: "failed to list users",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			userRepo := mockgen.NewMockIUserRepository(ctrl)
+			refreshTokenRepo := mockgen.NewMockIAuthRefreshTokenRepository(ctrl)
+			tt.setupMocks(userRepo, refreshTokenRepo)
+
+			svc := NewHousekeepingService(userRepo, refreshTokenRepo, nil, nil, nil, nil, nil, nil, config.Config{})
+			err := svc.CleanupRefreshTokens(context.Background())
+			if tt.wantErrSub != "" {
+				require.ErrorContains(t, err, tt.wantErrSub)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHousekeepingService_PurgeExpiredCache(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	t.Run("purges the expired entries", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		cacheMaintenance := mockgen.NewMockICacheMaintenance(ctrl)
+		cacheMaintenance.EXPECT().PurgeExpired(gomock.Any()).Return(4, nil)
+
+		svc := NewHousekeepingService(nil, nil, nil, cacheMaintenance, nil, nil, nil, nil, config.Config{})
+		require.NoError(t, svc.PurgeExpiredCache(context.Background()))
+	})
+
+	t.Run("a failing purge fails the job", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		cacheMaintenance := mockgen.NewMockICacheMaintenance(ctrl)
+		cacheMaintenance.EXPECT().PurgeExpired(gomock.Any()).Return(0, errors.New("cache down"))
+
+		svc := NewHousekeepingService(nil, nil, nil, cacheMaintenance, nil, nil, nil, nil, config.Config{})
+		require.ErrorContains(t, svc.PurgeExpiredCache(context.Background()), "failed to purge the expired cache entries")
+	})
+}
+
+func TestHousekeepingService_PurgeExpiredData(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("deletes the audit logs older than the window", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+		auditLogRepo.EXPECT().DeleteCreatedBefore(gomock.Any(), now.AddDate(0, 0, -30)).Return(3, nil)
+
+		svc := NewHousekeepingService(nil, nil, nil, nil, auditLogRepo, nil, nil, fixtures.NewFakeClock(now), config.Config{AuditLogRetentionDays: 30})
+		require.NoError(t, svc.PurgeExpiredData(context.Background()))
+	})
+
+	t.Run("keeps the audit logs without a window", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+
+		svc := NewHousekeepingService(nil, nil, nil, nil, auditLogRepo, nil, nil, fixtures.NewFakeClock(now), config.Config{})
+		require.NoError(t, svc.PurgeExpiredData(context.Background()))
+	})
+
+	t.Run("a failing delete fails the job", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+		auditLogRepo.EXPECT().DeleteCreatedBefore(gomock.Any(), gomock.Any()).Return(0, errors.New("db down"))
+
+		svc := NewHousekeepingService(nil, nil, nil, nil, auditLogRepo, nil, nil, fixtures.NewFakeClock(now), config.Config{AuditLogRetentionDays: 30})
+		require.ErrorContains(t, svc.PurgeExpiredData(context.Background()), "failed to purge audit logs")
+	})
+}
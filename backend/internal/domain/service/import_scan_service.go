@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+)
+
+func NewImportScanService(scanner client.IContentScanner, cfg config.Config) *ImportScanService {
+	return &ImportScanService{scanner: scanner, maxFileSize: cfg.ImportMaxFileSize, maxFiles: cfg.ImportMaxFiles}
+}
+
+// ImportScanService is the gate every tool import (URL, bundle, marketplace) passes before anything is stored.
+type ImportScanService struct {
+	scanner     client.IContentScanner
+	maxFileSize int
+	maxFiles    int
+}
+
+// ScanImportFiles enforces the per import limits and runs the configured scanner on every file.
+// It fails closed: a file that could not be scanned rejects the whole import.
+func (s *ImportScanService) ScanImportFiles(ctx context.Context, files []entity.ImportFileEntity) error {
+	if len(files) > s.maxFiles {
+		return error_code.NewErrorWithErrorCodef(error_code.ImportTooManyFiles, "an import can have at most %d files, got %d", s.maxFiles, len(files))
+	}
+	// check every size first, no need to scan a bundle that is rejected anyway
+	for _, file := range files {
+		if len(file.Content) > s.maxFileSize {
+			return error_code.NewErrorWithErrorCodef(error_code.ImportFileTooLarge, "file %q has %d bytes, the limit is %d", file.Name, len(file.Content), s.maxFileSize)
+		}
+	}
+
+	for _, file := range files {
+		result, err := s.scanner.Scan(ctx, file)
+		if err != nil {
+			logger.Errorf(ctx, "failed to scan imported file %q: %v", file.Name, err)
+			return error_code.NewErrorWithErrorCodef(error_code.ImportScanFailed, "file %q could not be scanned, try again later", file.Name)
+		}
+		if !result.Clean {
+			logger.Infof(ctx, "imported file %q rejected by scanner: %s", file.Name, result.Signature)
+			return error_code.NewErrorWithErrorCodef(error_code.ImportContentRejected, "file %q was rejected: %s", file.Name, result.Signature)
+		}
+	}
+	return nil
+}
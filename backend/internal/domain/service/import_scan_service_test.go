@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+)
+
+// scannerFunc adapts a function to IContentScanner.
+type scannerFunc func(ctx context.Context, file entity.ImportFileEntity) (entity.ContentScanResultEntity, error)
+
+func (f scannerFunc) Scan(ctx context.Context, file entity.ImportFileEntity) (entity.ContentScanResultEntity, error) {
+	return f(ctx, file)
+}
+
+func TestImportScanService_ScanImportFiles(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	cfg := config.Config{ImportMaxFileSize: 8, ImportMaxFiles: 2}
+	clean := scannerFunc(func(context.Context, entity.ImportFileEntity) (entity.ContentScanResultEntity, error) {
+		return entity.ContentScanResultEntity{Clean: true}, nil
+	})
+
+	tests := []struct {
+		name        string
+		scanner     scannerFunc
+		files       []entity.ImportFileEntity
+		wantErrCode *error_code.ErrorCode
+	}{
+		{
+			name:    "clean files within the limits pass",
+			scanner: clean,
+			files:   []entity.ImportFileEntity{{Name: "a.js", Content: []byte("ok")}, {Name: "b.js", Content: []byte("12345678")}},
+		},
+		{
+			name:        "too many files",
+			scanner:     clean,
+			files:       []entity.ImportFileEntity{{Name: "a.js"}, {Name: "b.js"}, {Name: "c.js"}},
+			wantErrCode: &error_code.ImportTooManyFiles,
+		},
+		{
+			name: "oversized file is rejected before anything is scanned",
+			scanner: func(context.Context, entity.ImportFileEntity) (entity.ContentScanResultEntity, error) {
+				panic("scanner must not be called")
+			},
+			files:       []entity.ImportFileEntity{{Name: "a.js", Content: []byte("ok")}, {Name: "big.js", Content: []byte("123456789")}},
+			wantErrCode: &error_code.ImportFileTooLarge,
+		},
+		{
+			name: "infected file is rejected",
+			scanner: func(_ context.Context, file entity.ImportFileEntity) (entity.ContentScanResultEntity, error) {
+				if file.Name == "b.js" {
+					return entity.ContentScanResultEntity{Signature: "Eicar-Signature"}, nil
+				}
+				return entity.ContentScanResultEntity{Clean: true}, nil
+			},
+			files:       []entity.ImportFileEntity{{Name: "a.js"}, {Name: "b.js"}},
+			wantErrCode: &error_code.ImportContentRejected,
+		},
+		{
+			name: "scanner failure fails closed",
+			scanner: func(context.Context, entity.ImportFileEntity) (entity.ContentScanResultEntity, error) {
+				return entity.ContentScanResultEntity{}, errors.New("connection refused")
+			},
+			files:       []entity.ImportFileEntity{{Name: "a.js"}},
+			wantErrCode: &error_code.ImportScanFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := NewImportScanService(tt.scanner, cfg).ScanImportFiles(context.Background(), tt.files)
+			if tt.wantErrCode == nil {
+				require.NoError(t, err)
+				return
+			}
+			var codeErr error_code.ErrorWithErrorCode
+			require.ErrorAs(t, err, &codeErr)
+			require.Equal(t, *tt.wantErrCode, codeErr.ErrorCode)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+func NewMeteringService(
+	usageRepo repository.IUsageRepository,
+	toolRepo repository.IToolRepository,
+	enforcer client.IUsageEnforcer,
+	clock client.IClock,
+	cfg config.Config,
+) *MeteringService {
+	return &MeteringService{
+		usageRepo: usageRepo,
+		toolRepo:  toolRepo,
+		enforcer:  enforcer,
+		clock:     clock,
+		enabled:   cfg.MeteringEnabled,
+	}
+}
+
+// MeteringService records the resources every user uses per month and asks the usage enforcer before more is used.
+// It does nothing unless METERING_ENABLED is set. An enforcer that cannot be reached allows the usage,
+// an outage of the billing service does not take the instance down with it.
+type MeteringService struct {
+	usageRepo repository.IUsageRepository
+	toolRepo  repository.IToolRepository
+	enforcer  client.IUsageEnforcer
+	clock     client.IClock
+	enabled   bool
+}
+
+// RecordAPICall meters an authenticated api request, the request is refused when the enforcer does not allow it.
+func (s *MeteringService) RecordAPICall(ctx context.Context, userID entity.UserIDEntity) error {
+	return s.record(ctx, userID, entity.UsageMetricAPICalls, 1)
+}
+
+// RecordToolExecution meters a tool run on the server as the tool executions its resource class weighs, the run is
+// refused when the enforcer does not allow it.
+func (s *MeteringService) RecordToolExecution(ctx context.Context, userID entity.UserIDEntity, class entity.ToolResourceClass) error {
+	return s.record(ctx, userID, entity.UsageMetricToolExecutions, class.ExecutionWeight())
+}
+
+// record counts amount uses of a counted metric once the enforcer allows it
+func (s *MeteringService) record(ctx context.Context, userID entity.UserIDEntity, metric entity.UsageMetric, amount int64) error {
+	if !s.enabled {
+		return nil
+	}
+
+	period := entity.UsagePeriod(s.clock.Now())
+	usages, err := s.usageRepo.UserUsage(ctx, userID, period)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get usage of user %s", userID)
+	}
+	if err := s.allow(ctx, entity.UsageRequestEntity{
+		UserID: userID,
+		Metric: metric,
+		Period: period,
+		Amount: amount,
+		Usage:  usageAmount(usages, metric),
+	}); err != nil {
+		return err
+	}
+
+	if _, err := s.usageRepo.AddUsage(ctx, userID, metric, period, amount); err != nil {
+		return errors.Wrapf(err, "fail to record %s of user %s", metric, userID)
+	}
+	return nil
+}
+
+// CheckToolStorage is called before tools are saved, a tool whose unique id exists replaces the stored one.
+// Growing the storage needs the enforcer to allow it, the storage usage is set to the size after the write.
+func (s *MeteringService) CheckToolStorage(ctx context.Context, userID entity.UserIDEntity, saving ...entity.ToolEntity) error {
+	if !s.enabled {
+		return nil
+	}
+
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	stored := toolsStorageBytes(tools.Tools)
+	after := toolsStorageBytes(replaceTools(tools.Tools, saving))
+
+	period := entity.UsagePeriod(s.clock.Now())
+	if after > stored {
+		if err := s.allow(ctx, entity.UsageRequestEntity{
+			UserID: userID,
+			Metric: entity.UsageMetricStorageBytes,
+			Period: period,
+			Amount: after - stored,
+			Usage:  stored,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := s.usageRepo.SetUsage(ctx, userID, entity.UsageMetricStorageBytes, period, after); err != nil {
+		return errors.Wrapf(err, "fail to record storage of user %s", userID)
+	}
+	return nil
+}
+
+// RecordToolStorage measures the stored tools again, it is called after tools were removed.
+func (s *MeteringService) RecordToolStorage(ctx context.Context, userID entity.UserIDEntity) error {
+	if !s.enabled {
+		return nil
+	}
+
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	period := entity.UsagePeriod(s.clock.Now())
+	if err := s.usageRepo.SetUsage(ctx, userID, entity.UsageMetricStorageBytes, period, toolsStorageBytes(tools.Tools)); err != nil {
+		return errors.Wrapf(err, "fail to record storage of user %s", userID)
+	}
+	return nil
+}
+
+// UserUsage returns the usage of a user in a period, "" is the current period.
+func (s *MeteringService) UserUsage(ctx context.Context, userID entity.UserIDEntity, period string) (string, []entity.UsageEntity, error) {
+	period, err := s.resolvePeriod(period)
+	if err != nil {
+		return "", nil, err
+	}
+	usages, err := s.usageRepo.UserUsage(ctx, userID, period)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "fail to get usage of user %s", userID)
+	}
+	return period, usages, nil
+}
+
+// PeriodUsage returns the usage of every user in a period, "" is the current period.
+func (s *MeteringService) PeriodUsage(ctx context.Context, period string) (string, []entity.UsageEntity, error) {
+	period, err := s.resolvePeriod(period)
+	if err != nil {
+		return "", nil, err
+	}
+	usages, err := s.usageRepo.PeriodUsage(ctx, period)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "fail to get usage of period %s", period)
+	}
+	return period, usages, nil
+}
+
+func (s *MeteringService) resolvePeriod(period string) (string, error) {
+	if period == "" {
+		return entity.UsagePeriod(s.clock.Now()), nil
+	}
+	if _, err := time.Parse(entity.UsagePeriodLayout, period); err != nil {
+		return "", error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "period %q is not a month like 2026-01", period)
+	}
+	return period, nil
+}
+
+// allow asks the enforcer, only a refusal is returned, an enforcer failure is logged and allows the usage.
+func (s *MeteringService) allow(ctx context.Context, request entity.UsageRequestEntity) error {
+	err := s.enforcer.Allow(ctx, request)
+	if err == nil {
+		return nil
+	}
+	var codeErr error_code.ErrorWithErrorCode
+	if errors.As(err, &codeErr) && codeErr.ErrorCode.Code == error_code.UsageLimitExceeded.Code {
+		logger.Warnf(ctx, "Usage of %d %s refused for user %s: %v", request.Amount, request.Metric, request.UserID, err)
+		return err
+	}
+	logger.Errorf(ctx, "Usage enforcer failed, allowing %d %s for user %s: %v", request.Amount, request.Metric, request.UserID, err)
+	return nil
+}
+
+func usageAmount(usages []entity.UsageEntity, metric entity.UsageMetric) int64 {
+	usage, _ := lo.Find(usages, func(usage entity.UsageEntity) bool { return usage.Metric == metric })
+	return usage.Amount
+}
+
+// replaceTools returns the stored tools with the saved ones in place, saved tools that are not stored yet are added.
+func replaceTools(stored []entity.ToolEntity, saving []entity.ToolEntity) []entity.ToolEntity {
+	savingByUID := lo.KeyBy(saving, func(tool entity.ToolEntity) string { return tool.UniqueID })
+	result := lo.Map(stored, func(tool entity.ToolEntity, _ int) entity.ToolEntity {
+		if saved, ok := savingByUID[tool.UniqueID]; ok {
+			delete(savingByUID, tool.UniqueID)
+			return saved
+		}
+		return tool
+	})
+	for _, tool := range saving {
+		if _, ok := savingByUID[tool.UniqueID]; ok {
+			result = append(result, tool)
+		}
+	}
+	return result
+}
+
+// toolsStorageBytes is the size of the user provided content of the tools.
+func toolsStorageBytes(tools []entity.ToolEntity) int64 {
+	return lo.SumBy(tools, func(tool entity.ToolEntity) int64 {
+		size := len(tool.ID) + len(tool.Name) + len(tool.Namespace) + len(tool.Category) +
+			len(tool.Description) + len(tool.UiWidgets) + len(tool.Source)
+		for key, value := range tool.ExtraInfo {
+			size += len(key) + len(value)
+		}
+		return int64(size)
+	})
+}
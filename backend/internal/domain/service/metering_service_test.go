@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+// usageEnforcerFunc adapts a function to IUsageEnforcer.
+type usageEnforcerFunc func(ctx context.Context, request entity.UsageRequestEntity) error
+
+func (f usageEnforcerFunc) Allow(ctx context.Context, request entity.UsageRequestEntity) error {
+	return f(ctx, request)
+}
+
+func TestMeteringService_RecordAPICall(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	userID := entity.UserIDEntity("user-1")
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	refused := error_code.NewErrorWithErrorCode(error_code.UsageLimitExceeded, "plan limit")
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		enforcerErr error
+		wantRecord  bool
+		wantErrCode *error_code.ErrorCode
+	}{
+		{name: "disabled metering records nothing", enabled: false},
+		{name: "allowed call is recorded", enabled: true, wantRecord: true},
+		{name: "refused call is not recorded", enabled: true, enforcerErr: refused, wantErrCode: &error_code.UsageLimitExceeded},
+		{name: "failing enforcer allows the call", enabled: true, enforcerErr: errors.New("connection refused"), wantRecord: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			usageRepo := mockgen.NewMockIUsageRepository(ctrl)
+			var asked []entity.UsageRequestEntity
+			enforcer := usageEnforcerFunc(func(_ context.Context, request entity.UsageRequestEntity) error {
+				asked = append(asked, request)
+				return tt.enforcerErr
+			})
+			if tt.enabled {
+				usageRepo.EXPECT().UserUsage(gomock.Any(), userID, "2026-03").
+					Return([]entity.UsageEntity{{UserID: userID, Metric: entity.UsageMetricAPICalls, Period: "2026-03", Amount: 41}}, nil)
+			}
+			if tt.wantRecord {
+				usageRepo.EXPECT().AddUsage(gomock.Any(), userID, entity.UsageMetricAPICalls, "2026-03", int64(1)).Return(int64(42), nil)
+			}
+			svc := NewMeteringService(usageRepo, nil, enforcer, fixtures.NewFakeClock(now), config.Config{MeteringEnabled: tt.enabled})
+
+			err := svc.RecordAPICall(context.Background(), userID)
+			if tt.wantErrCode != nil {
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, tt.wantErrCode.Code, codeErr.ErrorCode.Code)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.enabled {
+				require.Equal(t, []entity.UsageRequestEntity{{UserID: userID, Metric: entity.UsageMetricAPICalls, Period: "2026-03", Amount: 1, Usage: 41}}, asked)
+			} else {
+				require.Empty(t, asked)
+			}
+		})
+	}
+}
+
+func TestMeteringService_RecordToolExecution(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	userID := entity.UserIDEntity("user-1")
+	ctrl := gomock.NewController(t)
+	usageRepo := mockgen.NewMockIUsageRepository(ctrl)
+	usageRepo.EXPECT().UserUsage(gomock.Any(), userID, "2026-03").
+		Return([]entity.UsageEntity{
+			{UserID: userID, Metric: entity.UsageMetricAPICalls, Period: "2026-03", Amount: 41},
+			{UserID: userID, Metric: entity.UsageMetricToolExecutions, Period: "2026-03", Amount: 7},
+		}, nil)
+	usageRepo.EXPECT().AddUsage(gomock.Any(), userID, entity.UsageMetricToolExecutions, "2026-03", int64(4)).Return(int64(11), nil)
+	var asked []entity.UsageRequestEntity
+	enforcer := usageEnforcerFunc(func(_ context.Context, request entity.UsageRequestEntity) error {
+		asked = append(asked, request)
+		return nil
+	})
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	svc := NewMeteringService(usageRepo, nil, enforcer, fixtures.NewFakeClock(now), config.Config{MeteringEnabled: true})
+
+	// a medium run weighs 4 tool executions
+	require.NoError(t, svc.RecordToolExecution(context.Background(), userID, entity.ToolResourceClassMedium))
+	require.Equal(t, []entity.UsageRequestEntity{{UserID: userID, Metric: entity.UsageMetricToolExecutions, Period: "2026-03", Amount: 4, Usage: 7}}, asked)
+}
+
+func TestMeteringService_CheckToolStorage(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	userID := entity.UserIDEntity("user-1")
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	stored := fixtures.NewTestTool().WithUniqueID("uid-1").WithSource("12345").Build()
+	storedBytes := toolsStorageBytes([]entity.ToolEntity{stored})
+
+	tests := []struct {
+		name        string
+		saving      entity.ToolEntity
+		enforcerErr error
+		wantAsked   bool
+		wantSet     bool
+		wantErrCode *error_code.ErrorCode
+	}{
+		{
+			name:      "growing tool asks the enforcer",
+			saving:    fixtures.NewTestTool().WithUniqueID("uid-1").WithSource("1234567890").Build(),
+			wantAsked: true,
+			wantSet:   true,
+		},
+		{
+			name:    "shrinking tool does not ask the enforcer",
+			saving:  fixtures.NewTestTool().WithUniqueID("uid-1").WithSource("1").Build(),
+			wantSet: true,
+		},
+		{
+			name:        "refused new tool is not recorded",
+			saving:      fixtures.NewTestTool().WithUniqueID("uid-2").Build(),
+			enforcerErr: error_code.NewErrorWithErrorCode(error_code.UsageLimitExceeded, "plan limit"),
+			wantAsked:   true,
+			wantErrCode: &error_code.UsageLimitExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			usageRepo := mockgen.NewMockIUsageRepository(ctrl)
+			toolRepo := mockgen.NewMockIToolRepository(ctrl)
+			toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{stored}}, nil)
+			after := toolsStorageBytes(replaceTools([]entity.ToolEntity{stored}, []entity.ToolEntity{tt.saving}))
+			var asked []entity.UsageRequestEntity
+			enforcer := usageEnforcerFunc(func(_ context.Context, request entity.UsageRequestEntity) error {
+				asked = append(asked, request)
+				return tt.enforcerErr
+			})
+			if tt.wantSet {
+				usageRepo.EXPECT().SetUsage(gomock.Any(), userID, entity.UsageMetricStorageBytes, "2026-03", after).Return(nil)
+			}
+			svc := NewMeteringService(usageRepo, toolRepo, enforcer, fixtures.NewFakeClock(now), config.Config{MeteringEnabled: true})
+
+			err := svc.CheckToolStorage(context.Background(), userID, tt.saving)
+			if tt.wantErrCode != nil {
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, tt.wantErrCode.Code, codeErr.ErrorCode.Code)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.wantAsked {
+				require.Equal(t, []entity.UsageRequestEntity{{
+					UserID: userID,
+					Metric: entity.UsageMetricStorageBytes,
+					Period: "2026-03",
+					Amount: after - storedBytes,
+					Usage:  storedBytes,
+				}}, asked)
+			} else {
+				require.Empty(t, asked)
+			}
+		})
+	}
+}
+
+func TestMeteringService_UserUsage(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	userID := entity.UserIDEntity("user-1")
+	usageRepo := mockgen.NewMockIUsageRepository(ctrl)
+	usageRepo.EXPECT().UserUsage(gomock.Any(), userID, "2026-03").Return(nil, nil)
+	usageRepo.EXPECT().UserUsage(gomock.Any(), userID, "2025-12").Return(nil, nil)
+	svc := NewMeteringService(usageRepo, nil, nil, fixtures.NewFakeClock(time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)), config.Config{})
+
+	period, _, err := svc.UserUsage(context.Background(), userID, "")
+	require.NoError(t, err)
+	require.Equal(t, "2026-03", period)
+
+	period, _, err = svc.UserUsage(context.Background(), userID, "2025-12")
+	require.NoError(t, err)
+	require.Equal(t, "2025-12", period)
+
+	_, _, err = svc.UserUsage(context.Background(), userID, "2025-13")
+	var codeErr error_code.ErrorWithErrorCode
+	require.ErrorAs(t, err, &codeErr)
+	require.Equal(t, error_code.InvalidRequestParameters.Code, codeErr.ErrorCode.Code)
+}
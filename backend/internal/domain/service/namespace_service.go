@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+)
+
+func NewNamespaceService(namespaceRepo repository.INamespaceRepository) *NamespaceService {
+	return &NamespaceService{namespaceRepo: namespaceRepo}
+}
+
+// NamespaceService manages the settings of the tool namespaces of a user and hands out the defaults of new tools.
+type NamespaceService struct {
+	namespaceRepo repository.INamespaceRepository
+}
+
+func (s *NamespaceService) ListNamespaces(ctx context.Context, userID entity.UserIDEntity) ([]entity.NamespaceEntity, error) {
+	namespaces, err := s.namespaceRepo.ListNamespaces(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to list namespaces of user %s", userID)
+	}
+	return namespaces, nil
+}
+
+func (s *NamespaceService) CreateNamespace(ctx context.Context, userID entity.UserIDEntity, namespace entity.NamespaceEntity) (entity.NamespaceEntity, error) {
+	if err := validateNamespaceDefaults(namespace); err != nil {
+		return entity.NamespaceEntity{}, err
+	}
+	created, err := s.namespaceRepo.CreateNamespace(ctx, userID, namespace)
+	if stdErrors.Is(err, repository.ErrNamespaceAlreadyExists) {
+		return entity.NamespaceEntity{}, error_code.NewErrorWithErrorCodef(error_code.NamespaceAlreadyExists, "namespace %s already exists", namespace.Name)
+	}
+	if err != nil {
+		return entity.NamespaceEntity{}, errors.Wrapf(err, "fail to create namespace %s", namespace.Name)
+	}
+	return created, nil
+}
+
+// UpdateNamespace replaces the description and the defaults of the namespace, tools created before keep their values.
+func (s *NamespaceService) UpdateNamespace(ctx context.Context, userID entity.UserIDEntity, namespace entity.NamespaceEntity) (entity.NamespaceEntity, error) {
+	if err := validateNamespaceDefaults(namespace); err != nil {
+		return entity.NamespaceEntity{}, err
+	}
+	updated, err := s.namespaceRepo.UpdateNamespace(ctx, userID, namespace)
+	if stdErrors.Is(err, repository.ErrNamespaceNotFound) {
+		return entity.NamespaceEntity{}, error_code.NewErrorWithErrorCodef(error_code.NamespaceNotFound, "namespace %s not found", namespace.Name)
+	}
+	if err != nil {
+		return entity.NamespaceEntity{}, errors.Wrapf(err, "fail to update namespace %s", namespace.Name)
+	}
+	return updated, nil
+}
+
+// DeleteNamespace drops the settings of the namespace, its tools are kept and new tools get no defaults.
+func (s *NamespaceService) DeleteNamespace(ctx context.Context, userID entity.UserIDEntity, name string) error {
+	deleted, err := s.namespaceRepo.DeleteNamespace(ctx, userID, name)
+	if err != nil {
+		return errors.Wrapf(err, "fail to delete namespace %s", name)
+	}
+	if !deleted {
+		return error_code.NewErrorWithErrorCodef(error_code.NamespaceNotFound, "namespace %s not found", name)
+	}
+	return nil
+}
+
+// ToolDefaults returns the settings of the namespace a new tool is created in, a namespace without settings has
+// the zero defaults: no category, no realtime execution and no ui widgets skeleton.
+func (s *NamespaceService) ToolDefaults(ctx context.Context, userID entity.UserIDEntity, name string) (entity.NamespaceEntity, error) {
+	namespace, exists, err := s.namespaceRepo.GetNamespace(ctx, userID, name)
+	if err != nil {
+		return entity.NamespaceEntity{}, errors.Wrapf(err, "fail to get namespace %s", name)
+	}
+	if !exists {
+		return entity.NamespaceEntity{Name: name}, nil
+	}
+	return namespace, nil
+}
+
+func validateNamespaceDefaults(namespace entity.NamespaceEntity) error {
+	if namespace.DefaultUiWidgets == "" {
+		return nil
+	}
+	var widgets []json.RawMessage
+	if err := json.Unmarshal([]byte(namespace.DefaultUiWidgets), &widgets); err != nil {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidNamespaceUiWidgets, "default ui widgets of namespace %s are not a json array: %v", namespace.Name, err)
+	}
+	return nil
+}
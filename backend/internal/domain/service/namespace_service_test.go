@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+)
+
+func TestNamespaceService(t *testing.T) {
+	t.Parallel()
+
+	userID := entity.UserIDEntity("user-1")
+	newService := func(t *testing.T) (*NamespaceService, *mockgen.MockINamespaceRepository) {
+		namespaceRepo := mockgen.NewMockINamespaceRepository(gomock.NewController(t))
+		return NewNamespaceService(namespaceRepo), namespaceRepo
+	}
+	requireErrorCode := func(t *testing.T, err error, code error_code.ErrorCode) {
+		t.Helper()
+		var ecErr error_code.ErrorWithErrorCode
+		require.True(t, errors.As(err, &ecErr))
+		require.Equal(t, code.Code, ecErr.ErrorCode.Code)
+	}
+
+	t.Run("default ui widgets must be a json array", func(t *testing.T) {
+		t.Parallel()
+		svc, _ := newService(t)
+		_, err := svc.CreateNamespace(context.Background(), userID, entity.NamespaceEntity{Name: "ns", DefaultUiWidgets: `{"id":"input"}`})
+		requireErrorCode(t, err, error_code.InvalidNamespaceUiWidgets)
+		_, err = svc.UpdateNamespace(context.Background(), userID, entity.NamespaceEntity{Name: "ns", DefaultUiWidgets: "not json"})
+		requireErrorCode(t, err, error_code.InvalidNamespaceUiWidgets)
+	})
+
+	t.Run("repository errors become error codes", func(t *testing.T) {
+		t.Parallel()
+		svc, namespaceRepo := newService(t)
+		ctx := context.Background()
+		namespaceRepo.EXPECT().CreateNamespace(ctx, userID, gomock.Any()).Return(entity.NamespaceEntity{}, repository.ErrNamespaceAlreadyExists)
+		namespaceRepo.EXPECT().UpdateNamespace(ctx, userID, gomock.Any()).Return(entity.NamespaceEntity{}, repository.ErrNamespaceNotFound)
+		namespaceRepo.EXPECT().DeleteNamespace(ctx, userID, "ns").Return(false, nil)
+
+		_, err := svc.CreateNamespace(ctx, userID, entity.NamespaceEntity{Name: "ns", DefaultUiWidgets: "[]"})
+		requireErrorCode(t, err, error_code.NamespaceAlreadyExists)
+		_, err = svc.UpdateNamespace(ctx, userID, entity.NamespaceEntity{Name: "ns"})
+		requireErrorCode(t, err, error_code.NamespaceNotFound)
+		requireErrorCode(t, svc.DeleteNamespace(ctx, userID, "ns"), error_code.NamespaceNotFound)
+	})
+
+	t.Run("tool defaults", func(t *testing.T) {
+		t.Parallel()
+		svc, namespaceRepo := newService(t)
+		ctx := context.Background()
+		configured := entity.NamespaceEntity{Name: "ns", DefaultCategory: "json", DefaultRealtimeExecution: true}
+		namespaceRepo.EXPECT().GetNamespace(ctx, userID, "ns").Return(configured, true, nil)
+		namespaceRepo.EXPECT().GetNamespace(ctx, userID, "other").Return(entity.NamespaceEntity{}, false, nil)
+
+		defaults, err := svc.ToolDefaults(ctx, userID, "ns")
+		require.NoError(t, err)
+		require.Equal(t, configured, defaults)
+		defaults, err = svc.ToolDefaults(ctx, userID, "other")
+		require.NoError(t, err)
+		require.Equal(t, entity.NamespaceEntity{Name: "other"}, defaults)
+	})
+}
@@ -0,0 +1,681 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	oidcAuthorizationRequestKeyPrefix = "oidc_authorization_request:"
+	oidcAuthorizationCodeKeyPrefix    = "oidc_authorization_code:"
+	// oidcAuthorizationRequestTTL is the seconds the user has to approve an authorization request
+	oidcAuthorizationRequestTTL = 600
+	// oidcAuthorizationCodeTTL is the seconds a client has to redeem an authorization code
+	oidcAuthorizationCodeTTL = 60
+	oidcSecretBytes          = 32
+	oidcSigningKeyBits       = 2048
+
+	OidcGrantTypeAuthorizationCode = "authorization_code"
+	OidcGrantTypeRefreshToken      = "refresh_token"
+	oidcCodeChallengeMethodS256    = "S256"
+	// OidcConsentPath is the frontend page the user approves an authorization request on
+	OidcConsentPath = "/oidc/consent"
+)
+
+func NewOidcService(
+	clientRepo repository.IOidcClientRepository,
+	signingKeyRepo repository.IOidcSigningKeyRepository,
+	userRepo repository.IUserRepository,
+	accessTokenRepo repository.IAuthAccessTokenRepository,
+	refreshTokenRepo repository.IAuthRefreshTokenRepository,
+	authService *AuthService,
+	cacheRepo repository.ICache,
+	clock domain_client.IClock,
+	cfg config.Config,
+) *OidcService {
+	return &OidcService{
+		clientRepo:       clientRepo,
+		signingKeyRepo:   signingKeyRepo,
+		userRepo:         userRepo,
+		accessTokenRepo:  accessTokenRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		authService:      authService,
+		cacheRepo:        cacheRepo,
+		clock:            clock,
+		config:           cfg,
+	}
+}
+
+// OidcService lets other apps sign their users in with ToolBake through the OpenID Connect authorization code flow.
+// The client sends the user to the authorization endpoint, the user approves the request on the consent page and
+// the client redeems the code for ToolBake access and refresh tokens and an id token signed with an RSA key kept
+// in the database. PKCE with S256 is required for every client.
+type OidcService struct {
+	clientRepo       repository.IOidcClientRepository
+	signingKeyRepo   repository.IOidcSigningKeyRepository
+	userRepo         repository.IUserRepository
+	accessTokenRepo  repository.IAuthAccessTokenRepository
+	refreshTokenRepo repository.IAuthRefreshTokenRepository
+	authService      *AuthService
+	cacheRepo        repository.ICache
+	clock            domain_client.IClock
+	config           config.Config
+
+	// signingKeyLock keeps two requests from creating the first signing key at the same time
+	signingKeyLock sync.Mutex
+	signingKey     *oidcSigningKey
+}
+
+type oidcSigningKey struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+// oidcAuthorizationCode is an approved authorization request waiting to be redeemed, kept in the cache under the
+// sha256 of the code
+type oidcAuthorizationCode struct {
+	ClientID      string              `json:"client_id"`
+	RedirectURI   string              `json:"redirect_uri"`
+	UserID        entity.UserIDEntity `json:"user_id"`
+	Scopes        []string            `json:"scopes"`
+	Nonce         string              `json:"nonce"`
+	CodeChallenge string              `json:"code_challenge"`
+}
+
+type OidcAuthorizeParams struct {
+	ClientID            string
+	RedirectURI         string
+	ResponseType        string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+type OidcTokenParams struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+}
+
+// OidcUserInfo are the claims of the userinfo endpoint and the id token, the profile and email claims are only set
+// when the user granted their scope
+type OidcUserInfo struct {
+	Subject           string  `json:"sub"`
+	PreferredUsername string  `json:"preferred_username,omitempty"`
+	Email             *string `json:"email,omitempty"`
+}
+
+// OidcJwk is a public signing key in the JSON Web Key format
+type OidcJwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (s *OidcService) Enabled() bool {
+	return s.config.OIDCProviderEnabled
+}
+
+func (s *OidcService) checkEnabled() error {
+	if !s.Enabled() {
+		return error_code.NewErrorWithErrorCodef(error_code.OidcProviderDisabled, "OIDC_PROVIDER_ENABLED is false")
+	}
+	return nil
+}
+
+func (s *OidcService) endpoint(path string) string {
+	return s.config.OIDCIssuer + path
+}
+
+// Discovery returns the provider metadata served at /.well-known/openid-configuration.
+func (s *OidcService) Discovery() (entity.OidcDiscoveryEntity, error) {
+	if err := s.checkEnabled(); err != nil {
+		return entity.OidcDiscoveryEntity{}, err
+	}
+	return entity.OidcDiscoveryEntity{
+		Issuer:                            s.config.OIDCIssuer,
+		AuthorizationEndpoint:             s.endpoint("/api/v1/oidc/authorize"),
+		TokenEndpoint:                     s.endpoint("/api/v1/oidc/token"),
+		UserinfoEndpoint:                  s.endpoint("/api/v1/oidc/userinfo"),
+		JwksURI:                           s.endpoint("/api/v1/oidc/jwks"),
+		ScopesSupported:                   []string{entity.OidcScopeOpenID, entity.OidcScopeProfile, entity.OidcScopeEmail},
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{OidcGrantTypeAuthorizationCode, OidcGrantTypeRefreshToken},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{jwt.SigningMethodRS256.Alg()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post"},
+		CodeChallengeMethodsSupported:     []string{oidcCodeChallengeMethodS256},
+		ClaimsSupported:                   []string{"iss", "sub", "aud", "exp", "iat", "nonce", "preferred_username", "email"},
+	}, nil
+}
+
+// RegisterClient registers an app, the returned secret is not stored and can not be shown again.
+func (s *OidcService) RegisterClient(ctx context.Context, name string, redirectURIs []string, createdBy entity.UserIDEntity) (entity.OidcClientEntity, string, error) {
+	if err := s.checkEnabled(); err != nil {
+		return entity.OidcClientEntity{}, "", err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return entity.OidcClientEntity{}, "", error_code.NewErrorWithErrorCodef(error_code.InvalidOidcRequest, "client name is empty")
+	}
+	if len(redirectURIs) == 0 {
+		return entity.OidcClientEntity{}, "", error_code.NewErrorWithErrorCodef(error_code.InvalidOidcRequest, "at least one redirect uri is required")
+	}
+	for _, redirectURI := range redirectURIs {
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || !parsed.IsAbs() || parsed.Host == "" || parsed.Fragment != "" {
+			return entity.OidcClientEntity{}, "", error_code.NewErrorWithErrorCodef(error_code.InvalidOidcRequest,
+				"redirect uri %q must be an absolute url without fragment", redirectURI)
+		}
+	}
+
+	secret, err := oidcRandomToken()
+	if err != nil {
+		return entity.OidcClientEntity{}, "", err
+	}
+	oidcClient := entity.OidcClientEntity{
+		ID:           uuid.New().String(),
+		Name:         name,
+		SecretHash:   utils.Sha256String(secret),
+		RedirectURIs: slices.Compact(slices.Sorted(slices.Values(redirectURIs))),
+		CreatedBy:    createdBy,
+		CreatedAt:    s.clock.Now(),
+	}
+	if err := s.clientRepo.Create(ctx, oidcClient); err != nil {
+		return entity.OidcClientEntity{}, "", err
+	}
+	logger.Infof(ctx, "OIDC client %s (%s) registered by %s", oidcClient.ID, oidcClient.Name, createdBy)
+	return oidcClient, secret, nil
+}
+
+func (s *OidcService) ListClients(ctx context.Context) ([]entity.OidcClientEntity, error) {
+	if err := s.checkEnabled(); err != nil {
+		return nil, err
+	}
+	return s.clientRepo.List(ctx)
+}
+
+// DeleteClient removes the client, the tokens it was issued stay valid until they expire.
+func (s *OidcService) DeleteClient(ctx context.Context, clientID string) error {
+	if err := s.checkEnabled(); err != nil {
+		return err
+	}
+	deleted, err := s.clientRepo.Delete(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return error_code.NewErrorWithErrorCodef(error_code.OidcClientNotFound, "oidc client %s not found", clientID)
+	}
+	logger.Infof(ctx, "OIDC client %s deleted", clientID)
+	return nil
+}
+
+// Authorize validates an authorization request and returns where to send the browser. The request is kept for
+// the consent page, errors found once the redirect uri is trusted are sent back to the client as the spec asks.
+func (s *OidcService) Authorize(ctx context.Context, params OidcAuthorizeParams) (string, error) {
+	if err := s.checkEnabled(); err != nil {
+		return "", err
+	}
+	oidcClient, exists, err := s.clientRepo.GetByID(ctx, params.ClientID)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", error_code.NewErrorWithErrorCodef(error_code.InvalidOidcClient, "unknown client_id %q", params.ClientID)
+	}
+	if !oidcClient.AllowsRedirectURI(params.RedirectURI) {
+		return "", error_code.NewErrorWithErrorCodef(error_code.InvalidOidcRequest, "redirect_uri %q is not registered", params.RedirectURI)
+	}
+
+	redirectError := func(code string, description string) (string, error) {
+		return oidcRedirect(params.RedirectURI, url.Values{
+			"error":             {code},
+			"error_description": {description},
+			"state":             {params.State},
+		}), nil
+	}
+	if params.ResponseType != "code" {
+		return redirectError("unsupported_response_type", "only the authorization code flow is supported")
+	}
+	scopes := strings.Fields(params.Scope)
+	if !slices.Contains(scopes, entity.OidcScopeOpenID) {
+		return redirectError("invalid_scope", "the openid scope is required")
+	}
+	if params.CodeChallenge == "" || params.CodeChallengeMethod != oidcCodeChallengeMethodS256 {
+		return redirectError("invalid_request", "PKCE with the S256 code challenge method is required")
+	}
+
+	// unknown scopes are ignored, as the spec asks
+	granted := []string{}
+	for _, scope := range []string{entity.OidcScopeOpenID, entity.OidcScopeProfile, entity.OidcScopeEmail} {
+		if slices.Contains(scopes, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	request := entity.OidcAuthorizationRequestEntity{
+		ID:                  uuid.New().String(),
+		ClientID:            oidcClient.ID,
+		RedirectURI:         params.RedirectURI,
+		Scopes:              granted,
+		State:               params.State,
+		Nonce:               params.Nonce,
+		CodeChallenge:       params.CodeChallenge,
+		CodeChallengeMethod: params.CodeChallengeMethod,
+		ExpiresAt:           s.clock.Now().Add(oidcAuthorizationRequestTTL * time.Second).Unix(),
+	}
+	encoded, err := json.Marshal(request)
+	if err != nil {
+		return "", errors.Wrap(err, "fail to encode oidc authorization request")
+	}
+	if err := s.cacheRepo.SetWithTTL(ctx, oidcAuthorizationRequestKeyPrefix+request.ID, string(encoded), oidcAuthorizationRequestTTL); err != nil {
+		return "", errors.Wrap(err, "fail to keep oidc authorization request")
+	}
+	return oidcRedirect(s.endpoint(OidcConsentPath), url.Values{"request_id": {request.ID}}), nil
+}
+
+// GetAuthorizationRequest returns a pending authorization request and its client for the consent page.
+func (s *OidcService) GetAuthorizationRequest(ctx context.Context, requestID string) (entity.OidcAuthorizationRequestEntity, entity.OidcClientEntity, error) {
+	if err := s.checkEnabled(); err != nil {
+		return entity.OidcAuthorizationRequestEntity{}, entity.OidcClientEntity{}, err
+	}
+	raw, exists, err := s.cacheRepo.Get(ctx, oidcAuthorizationRequestKeyPrefix+requestID)
+	if err != nil {
+		return entity.OidcAuthorizationRequestEntity{}, entity.OidcClientEntity{}, errors.Wrap(err, "fail to get oidc authorization request")
+	}
+	if !exists {
+		return entity.OidcAuthorizationRequestEntity{}, entity.OidcClientEntity{},
+			error_code.NewErrorWithErrorCodef(error_code.OidcAuthorizationRequestNotFound, "oidc authorization request %s not found", requestID)
+	}
+	var request entity.OidcAuthorizationRequestEntity
+	if err := json.Unmarshal([]byte(raw), &request); err != nil {
+		return entity.OidcAuthorizationRequestEntity{}, entity.OidcClientEntity{}, errors.Wrap(err, "fail to decode oidc authorization request")
+	}
+	oidcClient, exists, err := s.clientRepo.GetByID(ctx, request.ClientID)
+	if err != nil {
+		return entity.OidcAuthorizationRequestEntity{}, entity.OidcClientEntity{}, err
+	}
+	if !exists {
+		// the client was deleted while the request was pending
+		return entity.OidcAuthorizationRequestEntity{}, entity.OidcClientEntity{},
+			error_code.NewErrorWithErrorCodef(error_code.OidcAuthorizationRequestNotFound, "client of oidc authorization request %s not found", requestID)
+	}
+	return request, oidcClient, nil
+}
+
+// DecideAuthorization approves or denies a pending authorization request for the user and returns the redirect to
+// the client, carrying the authorization code when approved. A request can be decided once.
+func (s *OidcService) DecideAuthorization(ctx context.Context, userID entity.UserIDEntity, requestID string, approve bool) (string, error) {
+	request, _, err := s.GetAuthorizationRequest(ctx, requestID)
+	if err != nil {
+		return "", err
+	}
+	if err := s.cacheRepo.Delete(ctx, oidcAuthorizationRequestKeyPrefix+requestID); err != nil {
+		return "", errors.Wrap(err, "fail to drop oidc authorization request")
+	}
+	if !approve {
+		logger.Infof(ctx, "user %s denied oidc client %s", userID, request.ClientID)
+		return oidcRedirect(request.RedirectURI, url.Values{
+			"error":             {"access_denied"},
+			"error_description": {"the user denied the request"},
+			"state":             {request.State},
+		}), nil
+	}
+
+	code, err := oidcRandomToken()
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(oidcAuthorizationCode{
+		ClientID:      request.ClientID,
+		RedirectURI:   request.RedirectURI,
+		UserID:        userID,
+		Scopes:        request.Scopes,
+		Nonce:         request.Nonce,
+		CodeChallenge: request.CodeChallenge,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "fail to encode oidc authorization code")
+	}
+	if err := s.cacheRepo.SetWithTTL(ctx, oidcAuthorizationCodeKeyPrefix+utils.Sha256String(code), string(encoded), oidcAuthorizationCodeTTL); err != nil {
+		return "", errors.Wrap(err, "fail to keep oidc authorization code")
+	}
+	logger.Infof(ctx, "user %s approved oidc client %s", userID, request.ClientID)
+	return oidcRedirect(request.RedirectURI, url.Values{"code": {code}, "state": {request.State}}), nil
+}
+
+// Token serves the token endpoint, it authenticates the client and redeems an authorization code or refreshes an
+// access token.
+func (s *OidcService) Token(ctx context.Context, params OidcTokenParams) (entity.OidcTokenEntity, error) {
+	if err := s.checkEnabled(); err != nil {
+		return entity.OidcTokenEntity{}, err
+	}
+	oidcClient, err := s.authenticateClient(ctx, params.ClientID, params.ClientSecret)
+	if err != nil {
+		return entity.OidcTokenEntity{}, err
+	}
+	switch params.GrantType {
+	case OidcGrantTypeAuthorizationCode:
+		return s.redeemAuthorizationCode(ctx, oidcClient, params)
+	case OidcGrantTypeRefreshToken:
+		return s.refreshAccessToken(ctx, oidcClient, params.RefreshToken)
+	default:
+		return entity.OidcTokenEntity{}, error_code.NewErrorWithErrorCodef(error_code.UnsupportedOidcGrantType, "grant_type %q is not supported", params.GrantType)
+	}
+}
+
+func (s *OidcService) authenticateClient(ctx context.Context, clientID string, clientSecret string) (entity.OidcClientEntity, error) {
+	oidcClient, exists, err := s.clientRepo.GetByID(ctx, clientID)
+	if err != nil {
+		return entity.OidcClientEntity{}, err
+	}
+	if !exists || subtle.ConstantTimeCompare([]byte(utils.Sha256String(clientSecret)), []byte(oidcClient.SecretHash)) != 1 {
+		return entity.OidcClientEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcClient, "client authentication failed for %q", clientID)
+	}
+	return oidcClient, nil
+}
+
+func (s *OidcService) redeemAuthorizationCode(ctx context.Context, oidcClient entity.OidcClientEntity, params OidcTokenParams) (entity.OidcTokenEntity, error) {
+	key := oidcAuthorizationCodeKeyPrefix + utils.Sha256String(params.Code)
+	raw, exists, err := s.cacheRepo.Get(ctx, key)
+	if err != nil {
+		return entity.OidcTokenEntity{}, errors.Wrap(err, "fail to get oidc authorization code")
+	}
+	if !exists {
+		return entity.OidcTokenEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcGrant, "authorization code is invalid or expired")
+	}
+	// a code is redeemed once, whatever the outcome
+	if err := s.cacheRepo.Delete(ctx, key); err != nil {
+		return entity.OidcTokenEntity{}, errors.Wrap(err, "fail to drop oidc authorization code")
+	}
+	var code oidcAuthorizationCode
+	if err := json.Unmarshal([]byte(raw), &code); err != nil {
+		return entity.OidcTokenEntity{}, errors.Wrap(err, "fail to decode oidc authorization code")
+	}
+	if code.ClientID != oidcClient.ID || code.RedirectURI != params.RedirectURI {
+		return entity.OidcTokenEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcGrant, "authorization code was issued to another client or redirect uri")
+	}
+	verifierHash := sha256.Sum256([]byte(params.CodeVerifier))
+	challenge := base64.RawURLEncoding.EncodeToString(verifierHash[:])
+	if params.CodeVerifier == "" || subtle.ConstantTimeCompare([]byte(challenge), []byte(code.CodeChallenge)) != 1 {
+		return entity.OidcTokenEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcGrant, "code_verifier does not match the code challenge")
+	}
+
+	user, exists, err := s.userRepo.GetByID(ctx, code.UserID)
+	if err != nil {
+		return entity.OidcTokenEntity{}, err
+	}
+	if !exists {
+		return entity.OidcTokenEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcGrant, "user %s of the authorization code is gone", code.UserID)
+	}
+	if reason := oidcInactiveUserReason(user); reason != "" {
+		return entity.OidcTokenEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcGrant, "user %s %s", user.ID, reason)
+	}
+	refreshToken, err := s.refreshTokenRepo.IssueClientRefreshToken(ctx, user.ID, oidcClient.ID, code.Scopes)
+	if err != nil {
+		return entity.OidcTokenEntity{}, errors.Wrap(err, "fail to issue refresh token")
+	}
+	accessToken, err := s.accessTokenRepo.IssueClientAccessToken(ctx, refreshToken)
+	if err != nil {
+		return entity.OidcTokenEntity{}, errors.Wrap(err, "fail to issue access token")
+	}
+	idToken, err := s.signIDToken(ctx, oidcClient.ID, user, code.Scopes, code.Nonce, accessToken.ExpireAt)
+	if err != nil {
+		return entity.OidcTokenEntity{}, err
+	}
+	logger.Infof(ctx, "oidc client %s signed in user %s", oidcClient.ID, user.ID)
+	return entity.OidcTokenEntity{
+		AccessToken:  accessToken,
+		ExpiresIn:    s.expiresIn(accessToken),
+		RefreshToken: refreshToken.Token,
+		IDToken:      idToken,
+	}, nil
+}
+
+// refreshAccessToken only accepts a refresh token issued to the client, the tokens of ToolBake itself and of other
+// clients are refused like an invalid token.
+func (s *OidcService) refreshAccessToken(ctx context.Context, oidcClient entity.OidcClientEntity, refreshToken string) (entity.OidcTokenEntity, error) {
+	refresh, rotated, valid, err := s.authService.RefreshSession(ctx, refreshToken, oidcClient.ID)
+	if err != nil {
+		return entity.OidcTokenEntity{}, err
+	}
+	if !valid {
+		return entity.OidcTokenEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcGrant, "refresh token is invalid or expired")
+	}
+	user, exists, err := s.userRepo.GetByID(ctx, refresh.UserID)
+	if err != nil {
+		return entity.OidcTokenEntity{}, err
+	}
+	if !exists {
+		return entity.OidcTokenEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcGrant, "user %s of the refresh token is gone", refresh.UserID)
+	}
+	if reason := oidcInactiveUserReason(user); reason != "" {
+		return entity.OidcTokenEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcGrant, "user %s %s", user.ID, reason)
+	}
+	accessToken, err := s.accessTokenRepo.IssueClientAccessToken(ctx, refresh)
+	if err != nil {
+		return entity.OidcTokenEntity{}, errors.Wrap(err, "fail to issue access token")
+	}
+	token := entity.OidcTokenEntity{AccessToken: accessToken, ExpiresIn: s.expiresIn(accessToken)}
+	if rotated != nil {
+		token.RefreshToken = rotated.Token
+	}
+	return token, nil
+}
+
+func (s *OidcService) expiresIn(accessToken entity.AccessToken) int64 {
+	return max(int64(accessToken.ExpireAt.Sub(s.clock.Now()).Seconds()), 0)
+}
+
+// UserInfo returns the claims of the user the access token belongs to.
+func (s *OidcService) UserInfo(ctx context.Context, token string) (OidcUserInfo, error) {
+	if err := s.checkEnabled(); err != nil {
+		return OidcUserInfo{}, err
+	}
+	accessToken, valid, err := s.accessTokenRepo.ValidateAccessToken(ctx, token)
+	if err != nil {
+		return OidcUserInfo{}, errors.Wrap(err, "fail to validate access token")
+	}
+	if !valid {
+		return OidcUserInfo{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcAccessToken, "access token is invalid or expired")
+	}
+	// the first-party tokens of ToolBake are not oidc access tokens, they carry no granted scopes
+	if accessToken.ClientID == "" {
+		return OidcUserInfo{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcAccessToken, "access token was not issued to an oidc client")
+	}
+	user, exists, err := s.userRepo.GetByID(ctx, accessToken.UserID)
+	if err != nil {
+		return OidcUserInfo{}, err
+	}
+	if !exists {
+		return OidcUserInfo{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcAccessToken, "user %s of the access token is gone", accessToken.UserID)
+	}
+	if reason := oidcInactiveUserReason(user); reason != "" {
+		return OidcUserInfo{}, error_code.NewErrorWithErrorCodef(error_code.InvalidOidcAccessToken, "user %s %s", user.ID, reason)
+	}
+	info := OidcUserInfo{Subject: string(user.ID)}
+	if slices.Contains(accessToken.Scopes, entity.OidcScopeProfile) {
+		info.PreferredUsername = user.Name
+	}
+	if slices.Contains(accessToken.Scopes, entity.OidcScopeEmail) {
+		info.Email = user.Mail
+	}
+	return info, nil
+}
+
+// oidcInactiveUserReason tells why no token or claim is handed out for the user, like the first-party api refuses a
+// disabled user and a user who must change the password. It is empty for an active user.
+func oidcInactiveUserReason(user entity.UserEntity) string {
+	if user.Disabled {
+		return "is disabled"
+	}
+	if user.PasswordChangeRequired {
+		return "must change the password first"
+	}
+	return ""
+}
+
+// Jwks returns the public keys the id tokens are verified with.
+func (s *OidcService) Jwks(ctx context.Context) ([]OidcJwk, error) {
+	if err := s.checkEnabled(); err != nil {
+		return nil, err
+	}
+	// make sure there is a key to publish before the first id token is signed
+	if _, err := s.currentSigningKey(ctx); err != nil {
+		return nil, err
+	}
+	keys, err := s.signingKeyRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jwks := make([]OidcJwk, 0, len(keys))
+	for _, key := range keys {
+		privateKey, err := parseOidcSigningKey(key.PrivateKeyPEM)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fail to parse oidc signing key %s", key.KID)
+		}
+		jwks = append(jwks, OidcJwk{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			Kid: key.KID,
+			N:   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+		})
+	}
+	return jwks, nil
+}
+
+func (s *OidcService) signIDToken(ctx context.Context, clientID string, user entity.UserEntity, scopes []string, nonce string, expireAt time.Time) (string, error) {
+	signingKey, err := s.currentSigningKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	claims := jwt.MapClaims{
+		"iss": s.config.OIDCIssuer,
+		"sub": string(user.ID),
+		"aud": clientID,
+		"iat": s.clock.Now().Unix(),
+		"exp": expireAt.Unix(),
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	if slices.Contains(scopes, entity.OidcScopeProfile) {
+		claims["preferred_username"] = user.Name
+	}
+	if slices.Contains(scopes, entity.OidcScopeEmail) && user.Mail != nil {
+		claims["email"] = *user.Mail
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = signingKey.kid
+	signed, err := token.SignedString(signingKey.key)
+	if err != nil {
+		return "", errors.Wrap(err, "fail to sign id token")
+	}
+	return signed, nil
+}
+
+// currentSigningKey returns the newest signing key, the first one is created on first use.
+func (s *OidcService) currentSigningKey(ctx context.Context) (*oidcSigningKey, error) {
+	s.signingKeyLock.Lock()
+	defer s.signingKeyLock.Unlock()
+	if s.signingKey != nil {
+		return s.signingKey, nil
+	}
+
+	keys, err := s.signingKeyRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > 0 {
+		privateKey, err := parseOidcSigningKey(keys[0].PrivateKeyPEM)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fail to parse oidc signing key %s", keys[0].KID)
+		}
+		s.signingKey = &oidcSigningKey{kid: keys[0].KID, key: privateKey}
+		return s.signingKey, nil
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, oidcSigningKeyBits)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to generate oidc signing key")
+	}
+	key := entity.OidcSigningKeyEntity{
+		KID:           uuid.New().String(),
+		PrivateKeyPEM: string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})),
+		CreatedAt:     s.clock.Now(),
+	}
+	if err := s.signingKeyRepo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	logger.Infof(ctx, "Created oidc signing key %s", key.KID)
+	s.signingKey = &oidcSigningKey{kid: key.KID, key: privateKey}
+	return s.signingKey, nil
+}
+
+func parseOidcSigningKey(privateKeyPEM string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+func oidcRandomToken() (string, error) {
+	buf := make([]byte, oidcSecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "fail to generate random token")
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// oidcRedirect appends the values to the query of the redirect uri, keeping the query it already has.
+func oidcRedirect(redirectURI string, values url.Values) string {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil {
+		// the redirect uris are validated when the client is registered
+		return redirectURI
+	}
+	query := parsed.Query()
+	for key, value := range values {
+		if len(value) > 0 && value[0] != "" {
+			query[key] = value
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
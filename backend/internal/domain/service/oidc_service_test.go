@@ -0,0 +1,444 @@
+package service
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+const oidcTestIssuer = "https://toolbake.example.com"
+
+type oidcTestEnv struct {
+	svc              *OidcService
+	clientRepo       *mockgen.MockIOidcClientRepository
+	accessTokenRepo  *mockgen.MockIAuthAccessTokenRepository
+	refreshTokenRepo *mockgen.MockIAuthRefreshTokenRepository
+	clock            *fixtures.FakeClock
+	user             entity.UserEntity
+	client           entity.OidcClientEntity
+	secret           string
+}
+
+func newOidcTestEnv(t *testing.T) oidcTestEnv {
+	return newOidcTestEnvWithConfig(t, config.Config{OIDCProviderEnabled: true, OIDCIssuer: oidcTestIssuer})
+}
+
+func newOidcTestEnvWithConfig(t *testing.T, cfg config.Config) oidcTestEnv {
+	ctrl := gomock.NewController(t)
+	env := oidcTestEnv{
+		clientRepo:       mockgen.NewMockIOidcClientRepository(ctrl),
+		accessTokenRepo:  mockgen.NewMockIAuthAccessTokenRepository(ctrl),
+		refreshTokenRepo: mockgen.NewMockIAuthRefreshTokenRepository(ctrl),
+		clock:            fixtures.NewFakeClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)),
+		user:             fixtures.NewTestUser().WithID("user-1").Build(),
+	}
+	mail := "user-1@example.com"
+	env.user.Mail = &mail
+
+	userRepo := mockgen.NewMockIUserRepository(ctrl)
+	userRepo.EXPECT().GetByID(gomock.Any(), env.user.ID).Return(env.user, true, nil).AnyTimes()
+	// users the first-party api refuses get no oidc token or claim either
+	disabled := fixtures.NewTestUser().WithID("user-disabled").Build()
+	disabled.Disabled = true
+	userRepo.EXPECT().GetByID(gomock.Any(), disabled.ID).Return(disabled, true, nil).AnyTimes()
+	mustChangePassword := fixtures.NewTestUser().WithID("user-must-change-password").Build()
+	mustChangePassword.PasswordChangeRequired = true
+	userRepo.EXPECT().GetByID(gomock.Any(), mustChangePassword.ID).Return(mustChangePassword, true, nil).AnyTimes()
+
+	// the signing keys are kept in memory
+	signingKeyRepo := mockgen.NewMockIOidcSigningKeyRepository(ctrl)
+	keys := []entity.OidcSigningKeyEntity{}
+	signingKeyRepo.EXPECT().List(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]entity.OidcSigningKeyEntity, error) {
+		return keys, nil
+	}).AnyTimes()
+	signingKeyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key entity.OidcSigningKeyEntity) error {
+		keys = append([]entity.OidcSigningKeyEntity{key}, keys...)
+		return nil
+	}).AnyTimes()
+
+	auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+	auditLogRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	auditLogService := NewAuditLogService(auditLogRepo, NewSIEMExportService(nil, nil, config.Config{}), env.clock)
+	authService := NewAuthService(env.accessTokenRepo, env.refreshTokenRepo, userRepo, nil, nil, cfg, nil, nil, auditLogService, nil)
+	env.svc = NewOidcService(env.clientRepo, signingKeyRepo, userRepo, env.accessTokenRepo, env.refreshTokenRepo, authService,
+		fixtures.NewFakeCache(env.clock), env.clock, cfg)
+
+	env.clientRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, oidcClient entity.OidcClientEntity) error {
+		env.client = oidcClient
+		return nil
+	})
+	_, secret, err := env.svc.RegisterClient(context.Background(), " Wiki ", []string{"https://wiki.example.com/callback?tenant=a"}, "admin-1")
+	require.NoError(t, err)
+	env.secret = secret
+	env.clientRepo.EXPECT().GetByID(gomock.Any(), env.client.ID).Return(env.client, true, nil).AnyTimes()
+	env.clientRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(entity.OidcClientEntity{}, false, nil).AnyTimes()
+	return env
+}
+
+func (env oidcTestEnv) authorizeParams(verifier string) OidcAuthorizeParams {
+	challenge := sha256.Sum256([]byte(verifier))
+	return OidcAuthorizeParams{
+		ClientID:            env.client.ID,
+		RedirectURI:         env.client.RedirectURIs[0],
+		ResponseType:        "code",
+		Scope:               "openid profile email offline_access",
+		State:               "state-1",
+		Nonce:               "nonce-1",
+		CodeChallenge:       base64.RawURLEncoding.EncodeToString(challenge[:]),
+		CodeChallengeMethod: "S256",
+	}
+}
+
+// approve runs the authorization request through the consent page and returns the authorization code.
+func (env oidcTestEnv) approve(t *testing.T, params OidcAuthorizeParams) string {
+	ctx := context.Background()
+	redirect, err := env.svc.Authorize(ctx, params)
+	require.NoError(t, err)
+	consent, err := url.Parse(redirect)
+	require.NoError(t, err)
+	require.Equal(t, oidcTestIssuer+OidcConsentPath, consent.Scheme+"://"+consent.Host+consent.Path)
+
+	redirect, err = env.svc.DecideAuthorization(ctx, env.user.ID, consent.Query().Get("request_id"), true)
+	require.NoError(t, err)
+	callback, err := url.Parse(redirect)
+	require.NoError(t, err)
+	assert.Equal(t, "a", callback.Query().Get("tenant"), "the query of the redirect uri is kept")
+	assert.Equal(t, params.State, callback.Query().Get("state"))
+	require.NotEmpty(t, callback.Query().Get("code"))
+	return callback.Query().Get("code")
+}
+
+// clientRefreshToken builds a refresh token of the test client, valid for an hour
+func (env oidcTestEnv) clientRefreshToken(userID entity.UserIDEntity, token string, scopes []string) entity.RefreshToken {
+	refreshToken := entity.NewRefreshToken(userID, token, env.clock.Now(), env.clock.Now().Add(time.Hour))
+	refreshToken.ClientID = env.client.ID
+	refreshToken.Scopes = scopes
+	return refreshToken
+}
+
+// clientAccessToken builds the access token issued for a refresh token of the test client
+func (env oidcTestEnv) clientAccessToken(userID entity.UserIDEntity, token string, refreshToken entity.RefreshToken) entity.AccessToken {
+	accessToken := entity.NewAccessToken(userID, token, env.clock.Now(), env.clock.Now().Add(15*time.Minute), refreshToken.TokenHash)
+	accessToken.ClientID = refreshToken.ClientID
+	accessToken.Scopes = refreshToken.Scopes
+	return accessToken
+}
+
+func requireOidcErrorCode(t *testing.T, err error, want error_code.ErrorCode) {
+	t.Helper()
+	var codeErr error_code.ErrorWithErrorCode
+	require.ErrorAs(t, err, &codeErr)
+	assert.Equal(t, want.Code, codeErr.ErrorCode.Code)
+}
+
+func TestOidcService_Disabled(t *testing.T) {
+	t.Parallel()
+	svc := NewOidcService(nil, nil, nil, nil, nil, nil, nil, nil, config.Config{})
+	_, err := svc.Discovery()
+	requireOidcErrorCode(t, err, error_code.OidcProviderDisabled)
+	_, err = svc.Token(context.Background(), OidcTokenParams{})
+	requireOidcErrorCode(t, err, error_code.OidcProviderDisabled)
+}
+
+func TestOidcService_Discovery(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	env := newOidcTestEnv(t)
+
+	discovery, err := env.svc.Discovery()
+	require.NoError(t, err)
+	assert.Equal(t, oidcTestIssuer, discovery.Issuer)
+	assert.Equal(t, oidcTestIssuer+"/api/v1/oidc/token", discovery.TokenEndpoint)
+	assert.Equal(t, []string{"S256"}, discovery.CodeChallengeMethodsSupported)
+	assert.Equal(t, "Wiki", env.client.Name)
+	assert.NotEqual(t, env.secret, env.client.SecretHash, "only the hash of the secret is stored")
+}
+
+func TestOidcService_RegisterClient_Validation(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	env := newOidcTestEnv(t)
+	ctx := context.Background()
+
+	_, _, err := env.svc.RegisterClient(ctx, "  ", []string{"https://a.example.com/cb"}, "admin-1")
+	requireOidcErrorCode(t, err, error_code.InvalidOidcRequest)
+	_, _, err = env.svc.RegisterClient(ctx, "App", nil, "admin-1")
+	requireOidcErrorCode(t, err, error_code.InvalidOidcRequest)
+	_, _, err = env.svc.RegisterClient(ctx, "App", []string{"/relative/cb"}, "admin-1")
+	requireOidcErrorCode(t, err, error_code.InvalidOidcRequest)
+	_, _, err = env.svc.RegisterClient(ctx, "App", []string{"https://a.example.com/cb#frag"}, "admin-1")
+	requireOidcErrorCode(t, err, error_code.InvalidOidcRequest)
+}
+
+func TestOidcService_Authorize_Errors(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	env := newOidcTestEnv(t)
+	ctx := context.Background()
+
+	params := env.authorizeParams("verifier")
+	params.ClientID = "unknown"
+	_, err := env.svc.Authorize(ctx, params)
+	requireOidcErrorCode(t, err, error_code.InvalidOidcClient)
+
+	params = env.authorizeParams("verifier")
+	params.RedirectURI = "https://evil.example.com/callback"
+	_, err = env.svc.Authorize(ctx, params)
+	requireOidcErrorCode(t, err, error_code.InvalidOidcRequest)
+
+	cases := map[string]func(p *OidcAuthorizeParams){
+		"unsupported_response_type": func(p *OidcAuthorizeParams) { p.ResponseType = "token" },
+		"invalid_scope":             func(p *OidcAuthorizeParams) { p.Scope = "profile" },
+		"invalid_request":           func(p *OidcAuthorizeParams) { p.CodeChallengeMethod = "plain" },
+	}
+	for want, mutate := range cases {
+		params := env.authorizeParams("verifier")
+		mutate(&params)
+		redirect, err := env.svc.Authorize(ctx, params)
+		require.NoError(t, err)
+		callback, err := url.Parse(redirect)
+		require.NoError(t, err)
+		assert.Equal(t, want, callback.Query().Get("error"))
+		assert.Equal(t, "state-1", callback.Query().Get("state"))
+	}
+
+	_, _, err = env.svc.GetAuthorizationRequest(ctx, "missing")
+	requireOidcErrorCode(t, err, error_code.OidcAuthorizationRequestNotFound)
+}
+
+func TestOidcService_DenyAuthorization(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	env := newOidcTestEnv(t)
+	ctx := context.Background()
+
+	redirect, err := env.svc.Authorize(ctx, env.authorizeParams("verifier"))
+	require.NoError(t, err)
+	consent, err := url.Parse(redirect)
+	require.NoError(t, err)
+	requestID := consent.Query().Get("request_id")
+
+	request, oidcClient, err := env.svc.GetAuthorizationRequest(ctx, requestID)
+	require.NoError(t, err)
+	assert.Equal(t, env.client.ID, oidcClient.ID)
+	assert.Equal(t, []string{"openid", "profile", "email"}, request.Scopes, "unknown scopes are dropped")
+
+	redirect, err = env.svc.DecideAuthorization(ctx, env.user.ID, requestID, false)
+	require.NoError(t, err)
+	callback, err := url.Parse(redirect)
+	require.NoError(t, err)
+	assert.Equal(t, "access_denied", callback.Query().Get("error"))
+	assert.Empty(t, callback.Query().Get("code"))
+
+	_, err = env.svc.DecideAuthorization(ctx, env.user.ID, requestID, true)
+	requireOidcErrorCode(t, err, error_code.OidcAuthorizationRequestNotFound)
+}
+
+func TestOidcService_AuthorizationCodeFlow(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	env := newOidcTestEnv(t)
+	ctx := context.Background()
+
+	params := env.authorizeParams("the-code-verifier")
+	code := env.approve(t, params)
+	tokenParams := OidcTokenParams{
+		GrantType:    OidcGrantTypeAuthorizationCode,
+		ClientID:     env.client.ID,
+		ClientSecret: env.secret,
+		Code:         code,
+		RedirectURI:  params.RedirectURI,
+		CodeVerifier: "the-code-verifier",
+	}
+
+	wrongSecret := tokenParams
+	wrongSecret.ClientSecret = "wrong"
+	_, err := env.svc.Token(ctx, wrongSecret)
+	requireOidcErrorCode(t, err, error_code.InvalidOidcClient)
+
+	// the tokens are bound to the client and the granted scopes
+	grantedScopes := []string{entity.OidcScopeOpenID, entity.OidcScopeProfile, entity.OidcScopeEmail}
+	refreshToken := env.clientRefreshToken(env.user.ID, "refresh-1", grantedScopes)
+	accessToken := env.clientAccessToken(env.user.ID, "access-1", refreshToken)
+	env.refreshTokenRepo.EXPECT().IssueClientRefreshToken(gomock.Any(), env.user.ID, env.client.ID, grantedScopes).Return(refreshToken, nil)
+	env.accessTokenRepo.EXPECT().IssueClientAccessToken(gomock.Any(), refreshToken).Return(accessToken, nil)
+	token, err := env.svc.Token(ctx, tokenParams)
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken.Token)
+	assert.Equal(t, "refresh-1", token.RefreshToken)
+	assert.Equal(t, int64(900), token.ExpiresIn)
+
+	// the id token verifies with the published key
+	jwks, err := env.svc.Jwks(ctx)
+	require.NoError(t, err)
+	require.Len(t, jwks, 1)
+	parsed, err := jwt.Parse(token.IDToken, func(token *jwt.Token) (any, error) {
+		assert.Equal(t, jwks[0].Kid, token.Header["kid"])
+		n, err := base64.RawURLEncoding.DecodeString(jwks[0].N)
+		require.NoError(t, err)
+		e, err := base64.RawURLEncoding.DecodeString(jwks[0].E)
+		require.NoError(t, err)
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithTimeFunc(env.clock.Now))
+	require.NoError(t, err)
+	claims := parsed.Claims.(jwt.MapClaims)
+	assert.Equal(t, oidcTestIssuer, claims["iss"])
+	assert.Equal(t, "user-1", claims["sub"])
+	assert.Equal(t, env.client.ID, claims["aud"])
+	assert.Equal(t, "nonce-1", claims["nonce"])
+	assert.Equal(t, env.user.Name, claims["preferred_username"])
+	assert.Equal(t, "user-1@example.com", claims["email"])
+
+	_, err = env.svc.Token(ctx, tokenParams)
+	requireOidcErrorCode(t, err, error_code.InvalidOidcGrant)
+
+	t.Run("wrong verifier burns the code", func(t *testing.T) {
+		code := env.approve(t, params)
+		wrongVerifier := tokenParams
+		wrongVerifier.Code = code
+		wrongVerifier.CodeVerifier = "another-verifier"
+		_, err := env.svc.Token(ctx, wrongVerifier)
+		requireOidcErrorCode(t, err, error_code.InvalidOidcGrant)
+		wrongVerifier.CodeVerifier = "the-code-verifier"
+		_, err = env.svc.Token(ctx, wrongVerifier)
+		requireOidcErrorCode(t, err, error_code.InvalidOidcGrant)
+	})
+
+	t.Run("expired code", func(t *testing.T) {
+		code := env.approve(t, params)
+		env.clock.Advance(2 * time.Minute)
+		expired := tokenParams
+		expired.Code = code
+		_, err := env.svc.Token(ctx, expired)
+		requireOidcErrorCode(t, err, error_code.InvalidOidcGrant)
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		refreshed := env.clientAccessToken(env.user.ID, "access-2", refreshToken)
+		env.refreshTokenRepo.EXPECT().ValidateRefreshToken(gomock.Any(), "refresh-1").Return(refreshToken, true, nil)
+		env.refreshTokenRepo.EXPECT().ValidateRefreshToken(gomock.Any(), "revoked").Return(entity.RefreshToken{}, false, nil)
+		env.accessTokenRepo.EXPECT().IssueClientAccessToken(gomock.Any(), refreshToken).Return(refreshed, nil)
+
+		token, err := env.svc.Token(ctx, OidcTokenParams{GrantType: OidcGrantTypeRefreshToken, ClientID: env.client.ID, ClientSecret: env.secret, RefreshToken: "refresh-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "access-2", token.AccessToken.Token)
+		assert.Empty(t, token.IDToken)
+
+		_, err = env.svc.Token(ctx, OidcTokenParams{GrantType: OidcGrantTypeRefreshToken, ClientID: env.client.ID, ClientSecret: env.secret, RefreshToken: "revoked"})
+		requireOidcErrorCode(t, err, error_code.InvalidOidcGrant)
+		_, err = env.svc.Token(ctx, OidcTokenParams{GrantType: "password", ClientID: env.client.ID, ClientSecret: env.secret})
+		requireOidcErrorCode(t, err, error_code.UnsupportedOidcGrantType)
+	})
+
+	t.Run("refresh token of another client", func(t *testing.T) {
+		// a first-party refresh token and the token of another client are refused before anything is issued
+		firstParty := entity.NewRefreshToken(env.user.ID, "first-party", env.clock.Now(), env.clock.Now().Add(time.Hour))
+		otherClient := env.clientRefreshToken(env.user.ID, "other-client", grantedScopes)
+		otherClient.ClientID = "another-client"
+		env.refreshTokenRepo.EXPECT().ValidateRefreshToken(gomock.Any(), "first-party").Return(firstParty, true, nil)
+		env.refreshTokenRepo.EXPECT().ValidateRefreshToken(gomock.Any(), "other-client").Return(otherClient, true, nil)
+
+		for _, presented := range []string{"first-party", "other-client"} {
+			_, err := env.svc.Token(ctx, OidcTokenParams{GrantType: OidcGrantTypeRefreshToken, ClientID: env.client.ID, ClientSecret: env.secret, RefreshToken: presented})
+			requireOidcErrorCode(t, err, error_code.InvalidOidcGrant)
+		}
+	})
+
+	t.Run("refresh of an inactive user", func(t *testing.T) {
+		for _, userID := range []entity.UserIDEntity{"user-disabled", "user-must-change-password"} {
+			inactive := env.clientRefreshToken(userID, "refresh-"+string(userID), grantedScopes)
+			env.refreshTokenRepo.EXPECT().ValidateRefreshToken(gomock.Any(), inactive.Token).Return(inactive, true, nil)
+			_, err := env.svc.Token(ctx, OidcTokenParams{GrantType: OidcGrantTypeRefreshToken, ClientID: env.client.ID, ClientSecret: env.secret, RefreshToken: inactive.Token})
+			requireOidcErrorCode(t, err, error_code.InvalidOidcGrant)
+		}
+	})
+
+	t.Run("userinfo", func(t *testing.T) {
+		env.accessTokenRepo.EXPECT().ValidateAccessToken(gomock.Any(), "access-1").Return(accessToken, true, nil)
+		env.accessTokenRepo.EXPECT().ValidateAccessToken(gomock.Any(), "expired").Return(entity.AccessToken{}, false, nil)
+
+		info, err := env.svc.UserInfo(ctx, "access-1")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", info.Subject)
+		assert.Equal(t, env.user.Name, info.PreferredUsername)
+		assert.Equal(t, env.user.Mail, info.Email)
+		_, err = env.svc.UserInfo(ctx, "expired")
+		requireOidcErrorCode(t, err, error_code.InvalidOidcAccessToken)
+	})
+
+	t.Run("userinfo claims follow the granted scopes", func(t *testing.T) {
+		openIDOnly := env.clientAccessToken(env.user.ID, "access-openid", env.clientRefreshToken(env.user.ID, "refresh-openid", []string{entity.OidcScopeOpenID}))
+		env.accessTokenRepo.EXPECT().ValidateAccessToken(gomock.Any(), "access-openid").Return(openIDOnly, true, nil)
+
+		info, err := env.svc.UserInfo(ctx, "access-openid")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", info.Subject)
+		assert.Empty(t, info.PreferredUsername)
+		assert.Nil(t, info.Email)
+	})
+
+	t.Run("userinfo refuses first-party tokens and inactive users", func(t *testing.T) {
+		firstParty := entity.NewAccessToken(env.user.ID, "access-first-party", env.clock.Now(), env.clock.Now().Add(15*time.Minute), refreshToken.TokenHash)
+		env.accessTokenRepo.EXPECT().ValidateAccessToken(gomock.Any(), "access-first-party").Return(firstParty, true, nil)
+		_, err := env.svc.UserInfo(ctx, "access-first-party")
+		requireOidcErrorCode(t, err, error_code.InvalidOidcAccessToken)
+
+		for _, userID := range []entity.UserIDEntity{"user-disabled", "user-must-change-password"} {
+			inactive := env.clientAccessToken(userID, "access-"+string(userID), env.clientRefreshToken(userID, "refresh-"+string(userID), grantedScopes))
+			env.accessTokenRepo.EXPECT().ValidateAccessToken(gomock.Any(), inactive.Token).Return(inactive, true, nil)
+			_, err := env.svc.UserInfo(ctx, inactive.Token)
+			requireOidcErrorCode(t, err, error_code.InvalidOidcAccessToken)
+		}
+	})
+}
+
+func TestOidcService_RefreshRotation(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	env := newOidcTestEnvWithConfig(t, config.Config{OIDCProviderEnabled: true, OIDCIssuer: oidcTestIssuer, RefreshTokenRotation: true})
+	ctx := context.Background()
+	refreshParams := func(refreshToken string) OidcTokenParams {
+		return OidcTokenParams{GrantType: OidcGrantTypeRefreshToken, ClientID: env.client.ID, ClientSecret: env.secret, RefreshToken: refreshToken}
+	}
+
+	refreshToken := env.clientRefreshToken(env.user.ID, "refresh-1", []string{entity.OidcScopeOpenID})
+	rotated := env.clientRefreshToken(env.user.ID, "refresh-2", refreshToken.Scopes)
+	rotated.FamilyID = refreshToken.FamilyID
+	accessToken := env.clientAccessToken(env.user.ID, "access-2", rotated)
+
+	// the presented token is replaced and the new one is returned to the client
+	env.refreshTokenRepo.EXPECT().ValidateRefreshToken(gomock.Any(), "refresh-1").Return(refreshToken, true, nil)
+	env.refreshTokenRepo.EXPECT().RotateRefreshToken(gomock.Any(), refreshToken).Return(rotated, true, nil)
+	env.accessTokenRepo.EXPECT().IssueClientAccessToken(gomock.Any(), rotated).Return(accessToken, nil)
+	token, err := env.svc.Token(ctx, refreshParams("refresh-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", token.AccessToken.Token)
+	assert.Equal(t, "refresh-2", token.RefreshToken)
+
+	// presenting the replaced token again revokes its family and the access tokens of the family
+	env.refreshTokenRepo.EXPECT().ValidateRefreshToken(gomock.Any(), "refresh-1").Return(entity.RefreshToken{}, false, nil)
+	env.refreshTokenRepo.EXPECT().FindRotatedRefreshToken(gomock.Any(), "refresh-1").Return(refreshToken, true, nil)
+	env.refreshTokenRepo.EXPECT().DeleteTokenFamily(gomock.Any(), env.user.ID, refreshToken.FamilyID).
+		Return([]string{refreshToken.TokenHash, rotated.TokenHash}, nil)
+	env.accessTokenRepo.EXPECT().DeleteTokensByRefreshTokenHash(gomock.Any(), refreshToken.TokenHash).Return(nil)
+	env.accessTokenRepo.EXPECT().DeleteTokensByRefreshTokenHash(gomock.Any(), rotated.TokenHash).Return(nil)
+	_, err = env.svc.Token(ctx, refreshParams("refresh-1"))
+	requireOidcErrorCode(t, err, error_code.InvalidOidcGrant)
+}
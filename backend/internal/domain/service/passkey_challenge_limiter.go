@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/metrics"
+	"ya-tool-craft/internal/core/requestid"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+)
+
+func NewPasskeyChallengeLimiter(clock client.IClock, ttls repository.ISessionTTLProvider, cfg config.Config) *PasskeyChallengeLimiter {
+	return &PasskeyChallengeLimiter{
+		clock:       clock,
+		ttls:        ttls,
+		config:      cfg,
+		ips:         map[string][]time.Time{},
+		outstanding: map[string]time.Time{},
+	}
+}
+
+// PasskeyChallengeLimiter bounds the anonymous passkey login challenges, each of them is a session in the cache
+// until it expires. A client ip gets PASSKEY_LOGIN_CHALLENGE_IP_LIMIT challenges per window, and no more than
+// PASSKEY_LOGIN_CHALLENGE_MAX_OUTSTANDING challenges wait to be completed at a time.
+// The counts are kept in memory, every instance of the server limits the challenges it serves.
+type PasskeyChallengeLimiter struct {
+	clock  client.IClock
+	ttls   repository.ISessionTTLProvider
+	config config.Config
+
+	mu        sync.Mutex
+	ips       map[string][]time.Time
+	lastSweep time.Time
+	// outstanding holds when every unfinished challenge expires
+	outstanding map[string]time.Time
+}
+
+// Acquire counts a new login challenge of the requesting client, it fails when a limit is reached.
+// It returns the ip limit of the client after this challenge, zero when the ip limit is off.
+func (l *PasskeyChallengeLimiter) Acquire(ctx context.Context, challenge string) (entity.RateLimitEntity, error) {
+	ip := requestid.GetClientIP(ctx)
+	// read before locking, the setting may come from the cache
+	ttl := time.Duration(l.ttls.WebAuthnChallengeTTL(ctx)) * time.Second
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	window := time.Duration(l.config.PasskeyLoginChallengeIPWindow) * time.Second
+	since := now.Add(-window)
+	l.sweep(now, since, window)
+
+	var rateLimit entity.RateLimitEntity
+	if limit := l.config.PasskeyLoginChallengeIPLimit; limit > 0 {
+		issued := pruneIssuanceTimes(l.ips[ip], since)
+		l.ips[ip] = issued
+		if len(issued) >= limit {
+			metrics.PasskeyLoginChallengesTotal.WithLabelValues("rate_limited").Inc()
+			logger.Warnf(ctx, "Passkey login challenge refused, ip %s requested %d within %s", ip, len(issued), window)
+			return entity.RateLimitEntity{}, error_code.NewErrorWithErrorCodeFAppendExtraData(
+				error_code.PasskeyChallengeRateLimited,
+				windowRateLimit(limit, issued, window),
+				"at most %d passkey login challenges per %s", limit, window,
+			)
+		}
+	}
+	if limit := l.config.PasskeyLoginChallengeMaxOutstanding; limit > 0 && len(l.outstanding) >= limit {
+		metrics.PasskeyLoginChallengesTotal.WithLabelValues("limit_reached").Inc()
+		logger.Warnf(ctx, "Passkey login challenge refused, %d challenges are outstanding", len(l.outstanding))
+		return entity.RateLimitEntity{}, error_code.NewErrorWithErrorCodef(error_code.PasskeyChallengeLimitReached, "%d passkey login challenges are outstanding", len(l.outstanding))
+	}
+
+	if limit := l.config.PasskeyLoginChallengeIPLimit; limit > 0 {
+		l.ips[ip] = append(l.ips[ip], now)
+		rateLimit = windowRateLimit(limit, l.ips[ip], window)
+	}
+	l.outstanding[challenge] = now.Add(ttl)
+	metrics.PasskeyLoginChallengesTotal.WithLabelValues("created").Inc()
+	metrics.PasskeyLoginChallengesOutstanding.Set(float64(len(l.outstanding)))
+	return rateLimit, nil
+}
+
+// windowRateLimit is the limit of a caller that was let through at the times issued within the window,
+// a slot frees up once the oldest of them leaves the window
+func windowRateLimit(limit int, issued []time.Time, window time.Duration) entity.RateLimitEntity {
+	return entity.RateLimitEntity{
+		Limit:     limit,
+		Remaining: max(0, limit-len(issued)),
+		ResetAt:   issued[0].Add(window),
+	}
+}
+
+// Release forgets a challenge, completed tells whether it ended in a login or was never handed out.
+func (l *PasskeyChallengeLimiter) Release(challenge string, completed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.outstanding[challenge]; !ok {
+		return
+	}
+	delete(l.outstanding, challenge)
+	if completed {
+		metrics.PasskeyLoginChallengesTotal.WithLabelValues("completed").Inc()
+	}
+	metrics.PasskeyLoginChallengesOutstanding.Set(float64(len(l.outstanding)))
+}
+
+// EvictExpired drops the expired challenges and the ips quiet for a window. The challenges are also swept when a
+// new one is requested, the scheduled eviction keeps an idle server from reporting expired ones as outstanding.
+// It returns how many challenges expired.
+func (l *PasskeyChallengeLimiter) EvictExpired(ctx context.Context) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	window := time.Duration(l.config.PasskeyLoginChallengeIPWindow) * time.Second
+	evicted := l.evictChallenges(now)
+	l.lastSweep = now
+	l.evictIPs(now.Add(-window))
+	return evicted
+}
+
+// sweep drops the expired challenges and the ips quiet for a window, the challenges are checked on every call
+// so the outstanding limit frees up as soon as challenges expire
+func (l *PasskeyChallengeLimiter) sweep(now time.Time, since time.Time, window time.Duration) {
+	l.evictChallenges(now)
+
+	if now.Sub(l.lastSweep) < window {
+		return
+	}
+	l.lastSweep = now
+	l.evictIPs(since)
+}
+
+// evictChallenges drops the expired challenges and returns how many, the caller holds mu
+func (l *PasskeyChallengeLimiter) evictChallenges(now time.Time) int {
+	evicted := 0
+	for challenge, expiresAt := range l.outstanding {
+		if !now.Before(expiresAt) {
+			delete(l.outstanding, challenge)
+			evicted++
+		}
+	}
+	metrics.PasskeyLoginChallengesOutstanding.Set(float64(len(l.outstanding)))
+	return evicted
+}
+
+// evictIPs forgets the ips without a challenge issued since, the caller holds mu
+func (l *PasskeyChallengeLimiter) evictIPs(since time.Time) {
+	for ip, issued := range l.ips {
+		if issued = pruneIssuanceTimes(issued, since); len(issued) == 0 {
+			delete(l.ips, ip)
+		} else {
+			l.ips[ip] = issued
+		}
+	}
+}
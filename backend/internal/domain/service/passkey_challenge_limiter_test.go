@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/unittest/fixtures"
+	"ya-tool-craft/internal/utils"
+)
+
+func TestPasskeyChallengeLimiter(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	type step struct {
+		advance time.Duration
+		ip      string
+		// release completes the challenge of an earlier step instead of acquiring one
+		release     int
+		wantErrCode *error_code.ErrorCode
+	}
+
+	tests := []struct {
+		name  string
+		cfg   config.Config
+		steps []step
+	}{
+		{
+			name: "an ip is limited within the window",
+			cfg:  config.Config{PasskeyLoginChallengeIPLimit: 2, PasskeyLoginChallengeIPWindow: 60, WebAuthnChallengeTTL: 300},
+			steps: []step{
+				{ip: "10.0.0.1"},
+				{ip: "10.0.0.1"},
+				{ip: "10.0.0.1", wantErrCode: &error_code.PasskeyChallengeRateLimited},
+				{ip: "10.0.0.2"},
+				{advance: 61 * time.Second, ip: "10.0.0.1"},
+			},
+		},
+		{
+			name: "outstanding challenges are capped until they complete or expire",
+			cfg:  config.Config{PasskeyLoginChallengeMaxOutstanding: 2, PasskeyLoginChallengeIPWindow: 60, WebAuthnChallengeTTL: 300},
+			steps: []step{
+				{ip: "10.0.0.1"},
+				{ip: "10.0.0.2"},
+				{ip: "10.0.0.3", wantErrCode: &error_code.PasskeyChallengeLimitReached},
+				{release: 1},
+				{ip: "10.0.0.3"},
+				{ip: "10.0.0.4", wantErrCode: &error_code.PasskeyChallengeLimitReached},
+				{advance: 300 * time.Second, ip: "10.0.0.4"},
+			},
+		},
+		{
+			name: "zero disables the limits",
+			cfg:  config.Config{PasskeyLoginChallengeIPWindow: 60, WebAuthnChallengeTTL: 300},
+			steps: []step{
+				{ip: "10.0.0.1"},
+				{ip: "10.0.0.1"},
+				{ip: "10.0.0.1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := fixtures.NewFakeClock(time.Date(2026, 1, 14, 3, 0, 0, 0, time.UTC))
+			limiter := NewPasskeyChallengeLimiter(clock, fixtures.NewConfigSessionTTLs(tt.cfg), tt.cfg)
+			for i, step := range tt.steps {
+				clock.Advance(step.advance)
+				if step.release > 0 {
+					limiter.Release(fmt.Sprintf("challenge-%d", step.release), true)
+					continue
+				}
+				ctx := utils.NewValueContext(context.Background())
+				ctx.Set("client-ip", step.ip)
+				_, err := limiter.Acquire(ctx, fmt.Sprintf("challenge-%d", i))
+				if step.wantErrCode == nil {
+					require.NoError(t, err, "step %d", i)
+					continue
+				}
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr, "step %d", i)
+				require.Equal(t, step.wantErrCode.Code, codeErr.ErrorCode.Code, "step %d", i)
+			}
+		})
+	}
+}
+
+func TestPasskeyChallengeLimiter_RateLimit(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	start := time.Date(2026, 1, 14, 3, 0, 0, 0, time.UTC)
+	clock := fixtures.NewFakeClock(start)
+	cfg := config.Config{PasskeyLoginChallengeIPLimit: 2, PasskeyLoginChallengeIPWindow: 60, WebAuthnChallengeTTL: 300}
+	limiter := NewPasskeyChallengeLimiter(clock, fixtures.NewConfigSessionTTLs(cfg), cfg)
+	ctx := utils.NewValueContext(context.Background())
+	ctx.Set("client-ip", "10.0.0.1")
+
+	rateLimit, err := limiter.Acquire(ctx, "challenge-1")
+	require.NoError(t, err)
+	require.Equal(t, entity.RateLimitEntity{Limit: 2, Remaining: 1, ResetAt: start.Add(time.Minute)}, rateLimit)
+
+	clock.Advance(10 * time.Second)
+	rateLimit, err = limiter.Acquire(ctx, "challenge-2")
+	require.NoError(t, err)
+	require.Equal(t, entity.RateLimitEntity{Limit: 2, Remaining: 0, ResetAt: start.Add(time.Minute)}, rateLimit)
+
+	// the refusal tells when the oldest challenge leaves the window
+	_, err = limiter.Acquire(ctx, "challenge-3")
+	var codeErr error_code.ErrorWithErrorCode
+	require.ErrorAs(t, err, &codeErr)
+	require.Equal(t, entity.RateLimitEntity{Limit: 2, Remaining: 0, ResetAt: start.Add(time.Minute)}, codeErr.ExtraData)
+
+	// without an ip limit there is nothing to report
+	cfg = config.Config{PasskeyLoginChallengeIPWindow: 60}
+	rateLimit, err = NewPasskeyChallengeLimiter(clock, fixtures.NewConfigSessionTTLs(cfg), cfg).Acquire(ctx, "challenge-4")
+	require.NoError(t, err)
+	require.Zero(t, rateLimit)
+}
+
+func TestPasskeyChallengeLimiter_EvictExpired(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	clock := fixtures.NewFakeClock(time.Date(2026, 1, 14, 3, 0, 0, 0, time.UTC))
+	cfg := config.Config{PasskeyLoginChallengeIPLimit: 5, PasskeyLoginChallengeIPWindow: 60, WebAuthnChallengeTTL: 300}
+	limiter := NewPasskeyChallengeLimiter(clock, fixtures.NewConfigSessionTTLs(cfg), cfg)
+	ctx := utils.NewValueContext(context.Background())
+	ctx.Set("client-ip", "10.0.0.1")
+
+	_, err := limiter.Acquire(ctx, "challenge-1")
+	require.NoError(t, err)
+	clock.Advance(200 * time.Second)
+	_, err = limiter.Acquire(ctx, "challenge-2")
+	require.NoError(t, err)
+
+	// nothing expired yet, the ip still has a challenge in its window
+	require.Equal(t, 0, limiter.EvictExpired(context.Background()))
+	require.Len(t, limiter.outstanding, 2)
+	require.Len(t, limiter.ips, 1)
+
+	clock.Advance(100 * time.Second)
+	require.Equal(t, 1, limiter.EvictExpired(context.Background()))
+	require.Equal(t, []string{"challenge-2"}, lo.Keys(limiter.outstanding))
+	require.Empty(t, limiter.ips)
+}
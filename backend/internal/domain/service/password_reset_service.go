@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	passwordResetTokenKeyPrefix    = "password_reset_token:"
+	passwordResetUserKeyPrefix     = "password_reset_user:"
+	passwordResetThrottleKeyPrefix = "password_reset_throttle:"
+	// passwordResetResendInterval is the seconds between two tokens sent to the same email
+	passwordResetResendInterval = 60
+	passwordResetTokenBytes     = 32
+)
+
+func NewPasswordResetService(
+	userRepo repository.IUserRepository,
+	cacheRepo repository.ICache,
+	userService *UserService,
+	mailer domain_client.IMailer,
+	cfg config.Config,
+) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:    userRepo,
+		cacheRepo:   cacheRepo,
+		userService: userService,
+		mailer:      mailer,
+		config:      cfg,
+	}
+}
+
+// PasswordResetService lets a user who forgot the password set a new one with a token emailed to the account.
+// The token is single-use and expires after PASSWORD_RESET_TOKEN_TTL, the 2FA methods of the account are kept.
+type PasswordResetService struct {
+	userRepo    repository.IUserRepository
+	cacheRepo   repository.ICache
+	userService *UserService
+	mailer      domain_client.IMailer
+	config      config.Config
+}
+
+// StartReset emails a reset token to the account with the email and drops the token sent before. Nothing tells the
+// caller whether an account uses the email, an unknown email and a throttled one succeed without sending anything.
+func (s *PasswordResetService) StartReset(ctx context.Context, email string) error {
+	if s.config.Mailer == "none" {
+		return error_code.NewErrorWithErrorCodef(error_code.PasswordResetUnavailable, "password reset needs a mailer, MAILER is none")
+	}
+
+	// emails are stored normalized, see normalizeUserEmail
+	email = normalizeUserEmail(email)
+	throttleKey := passwordResetThrottleKeyPrefix + passwordResetSecretHash(email)
+	throttled, err := s.cacheRepo.Has(ctx, throttleKey)
+	if err != nil {
+		return errors.Wrap(err, "fail to check password reset throttle")
+	}
+	if throttled {
+		logger.Infof(ctx, "Password reset token not sent, one was sent less than %d seconds ago", passwordResetResendInterval)
+		return nil
+	}
+	if err := s.cacheRepo.SetWithTTL(ctx, throttleKey, "1", passwordResetResendInterval); err != nil {
+		return errors.Wrap(err, "fail to set password reset throttle")
+	}
+
+	user, exists, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return errors.Wrap(err, "fail to get user by email")
+	}
+	if !exists || user.Mail == nil {
+		logger.Infof(ctx, "Password reset token not sent, no account uses the email")
+		return nil
+	}
+
+	token, err := generatePasswordResetToken()
+	if err != nil {
+		return err
+	}
+	tokenHash := passwordResetSecretHash(token)
+	ttl := uint64(s.config.PasswordResetTokenTTL)
+	if err := s.dropPendingToken(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := s.cacheRepo.SetWithTTL(ctx, passwordResetTokenKeyPrefix+tokenHash, string(user.ID), ttl); err != nil {
+		return errors.Wrap(err, "fail to store password reset token")
+	}
+	if err := s.cacheRepo.SetWithTTL(ctx, passwordResetUserKeyPrefix+string(user.ID), tokenHash, ttl); err != nil {
+		return errors.Wrap(err, "fail to store password reset token of the user")
+	}
+
+	s.notify(ctx, user, "Reset your password", fmt.Sprintf(
+		"Someone asked to reset the password of the account %s.\n\n"+
+			"The reset token is %s, it expires in %d minutes and works once.\n\n"+
+			"If you did not ask for it, ignore this email: the password does not change without the token.\n",
+		user.Name, token, s.config.PasswordResetTokenTTL/60))
+	logger.Infof(ctx, "Password reset token sent to user %s", user.ID)
+	return nil
+}
+
+// CompleteReset sets the new password of the account the token was sent to and signs it out of every device.
+// The token is consumed even when setting the password fails, a new one has to be requested then.
+func (s *PasswordResetService) CompleteReset(ctx context.Context, token string, newPassword string) error {
+	tokenKey := passwordResetTokenKeyPrefix + passwordResetSecretHash(strings.TrimSpace(token))
+	invalid := error_code.NewErrorWithErrorCodef(error_code.InvalidPasswordResetToken, "password reset token is expired or invalid")
+
+	rawUserID, exists, err := s.cacheRepo.Get(ctx, tokenKey)
+	if err != nil {
+		return errors.Wrap(err, "fail to get password reset token")
+	}
+	if !exists {
+		return invalid
+	}
+	userID := entity.UserIDEntity(rawUserID)
+	if err := s.cacheRepo.Delete(ctx, tokenKey); err != nil {
+		return errors.Wrap(err, "fail to delete password reset token")
+	}
+	if err := s.cacheRepo.Delete(ctx, passwordResetUserKeyPrefix+rawUserID); err != nil {
+		return errors.Wrap(err, "fail to delete password reset token of the user")
+	}
+
+	user, exists, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "fail to get user by id")
+	}
+	if !exists {
+		return invalid
+	}
+	if err := s.userRepo.UpdatePassword(ctx, userID, newPassword); err != nil {
+		return errors.Wrap(err, "fail to update password")
+	}
+	if err := s.userService.RevokeSessions(ctx, userID); err != nil {
+		return err
+	}
+
+	s.notify(ctx, user, "Your password was reset", fmt.Sprintf(
+		"The password of the account %s was reset and every device signed out.\n\n"+
+			"If you did not do it, contact an administrator right away.\n", user.Name))
+	logger.Infof(ctx, "Password of user %s reset", userID)
+	return nil
+}
+
+// dropPendingToken invalidates the token sent to the user before, so only the latest email works
+func (s *PasswordResetService) dropPendingToken(ctx context.Context, userID entity.UserIDEntity) error {
+	previousHash, exists, err := s.cacheRepo.Get(ctx, passwordResetUserKeyPrefix+string(userID))
+	if err != nil {
+		return errors.Wrap(err, "fail to get password reset token of the user")
+	}
+	if !exists {
+		return nil
+	}
+	if err := s.cacheRepo.Delete(ctx, passwordResetTokenKeyPrefix+previousHash); err != nil {
+		return errors.Wrap(err, "fail to delete previous password reset token")
+	}
+	return nil
+}
+
+// notify emails the account, a failed notice is logged and does not fail the step it reports
+func (s *PasswordResetService) notify(ctx context.Context, user entity.UserEntity, subject string, body string) {
+	if user.Mail == nil || *user.Mail == "" {
+		logger.Warnf(ctx, "Password reset mail %q not sent, user %s has no email", subject, user.ID)
+		return
+	}
+	if err := s.mailer.Send(ctx, entity.MailEntity{To: *user.Mail, Subject: subject, Body: body}); err != nil {
+		logger.Errorf(ctx, "Failed to send password reset mail %q to user %s: %v", subject, user.ID, err)
+	}
+}
+
+// passwordResetSecretHash keeps the tokens and emails out of the cache keys
+func passwordResetSecretHash(secret string) string {
+	return utils.Sha256String(secret)
+}
+
+func generatePasswordResetToken() (string, error) {
+	buf := make([]byte, passwordResetTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "fail to generate password reset token")
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
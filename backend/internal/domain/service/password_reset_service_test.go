@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+var passwordResetTokenPattern = regexp.MustCompile(`token is ([A-Za-z0-9_-]+),`)
+
+type passwordResetTestEnv struct {
+	svc              *PasswordResetService
+	userRepo         *mockgen.MockIUserRepository
+	accessTokenRepo  *mockgen.MockIAuthAccessTokenRepository
+	refreshTokenRepo *mockgen.MockIAuthRefreshTokenRepository
+	mailer           *recordingMailer
+	clock            *fixtures.FakeClock
+	user             entity.UserEntity
+}
+
+func newPasswordResetTestEnv(t *testing.T, mailer string) passwordResetTestEnv {
+	ctrl := gomock.NewController(t)
+	env := passwordResetTestEnv{
+		userRepo:         mockgen.NewMockIUserRepository(ctrl),
+		accessTokenRepo:  mockgen.NewMockIAuthAccessTokenRepository(ctrl),
+		refreshTokenRepo: mockgen.NewMockIAuthRefreshTokenRepository(ctrl),
+		mailer:           &recordingMailer{},
+		clock:            fixtures.NewFakeClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)),
+		user:             fixtures.NewTestUser().WithID("user-1").Build(),
+	}
+	mail := "forgetful@example.com"
+	env.user.Mail = &mail
+	env.userRepo.EXPECT().GetByID(gomock.Any(), env.user.ID).Return(env.user, true, nil).AnyTimes()
+	env.userRepo.EXPECT().GetByEmail(gomock.Any(), mail).Return(env.user, true, nil).AnyTimes()
+	env.userRepo.EXPECT().GetByEmail(gomock.Any(), gomock.Any()).Return(entity.UserEntity{}, false, nil).AnyTimes()
+
+	cfg := config.Config{Mailer: mailer, PasswordResetTokenTTL: 1800}
+	userService := NewUserService(env.userRepo, env.accessTokenRepo, env.refreshTokenRepo, nil, nil, cfg)
+	env.svc = NewPasswordResetService(env.userRepo, fixtures.NewFakeCache(env.clock), userService, env.mailer, cfg)
+	return env
+}
+
+// sentResetToken returns the token of the latest reset mail
+func (env passwordResetTestEnv) sentResetToken(t *testing.T) string {
+	t.Helper()
+	mails := env.mailer.sent()
+	require.NotEmpty(t, mails)
+	match := passwordResetTokenPattern.FindStringSubmatch(mails[len(mails)-1].Body)
+	require.Len(t, match, 2)
+	return match[1]
+}
+
+func (env passwordResetTestEnv) expectPasswordReset(password string) {
+	env.userRepo.EXPECT().UpdatePassword(gomock.Any(), env.user.ID, password).Return(nil)
+	env.accessTokenRepo.EXPECT().DeleteAllTokensByUserID(gomock.Any(), env.user.ID).Return(nil)
+	env.refreshTokenRepo.EXPECT().DeleteAllTokensByUserID(gomock.Any(), env.user.ID).Return(nil)
+}
+
+func TestPasswordResetService_StartReset(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	t.Run("needs a mailer", func(t *testing.T) {
+		t.Parallel()
+		env := newPasswordResetTestEnv(t, "none")
+		requireAccountRecoveryErrorCode(t, env.svc.StartReset(context.Background(), *env.user.Mail), error_code.PasswordResetUnavailable)
+	})
+
+	t.Run("unknown email sends nothing", func(t *testing.T) {
+		t.Parallel()
+		env := newPasswordResetTestEnv(t, "log")
+		require.NoError(t, env.svc.StartReset(context.Background(), "nobody@example.com"))
+		assert.Empty(t, env.mailer.sent())
+	})
+
+	t.Run("mixed case email finds the account", func(t *testing.T) {
+		t.Parallel()
+		env := newPasswordResetTestEnv(t, "log")
+		require.NoError(t, env.svc.StartReset(context.Background(), " Forgetful@Example.COM "))
+		require.Len(t, env.mailer.sent(), 1)
+		assert.Equal(t, *env.user.Mail, env.mailer.sent()[0].To)
+	})
+
+	t.Run("token is sent once per interval", func(t *testing.T) {
+		t.Parallel()
+		env := newPasswordResetTestEnv(t, "log")
+		ctx := context.Background()
+
+		require.NoError(t, env.svc.StartReset(ctx, *env.user.Mail))
+		require.Len(t, env.mailer.sent(), 1)
+		assert.Equal(t, *env.user.Mail, env.mailer.sent()[0].To)
+		assert.NotEmpty(t, env.sentResetToken(t))
+
+		require.NoError(t, env.svc.StartReset(ctx, " FORGETFUL@example.com "))
+		assert.Len(t, env.mailer.sent(), 1)
+
+		env.clock.Advance(passwordResetResendInterval * time.Second)
+		require.NoError(t, env.svc.StartReset(ctx, *env.user.Mail))
+		assert.Len(t, env.mailer.sent(), 2)
+	})
+}
+
+func TestPasswordResetService_CompleteReset(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	t.Run("sets the password and signs out once", func(t *testing.T) {
+		t.Parallel()
+		env := newPasswordResetTestEnv(t, "log")
+		ctx := context.Background()
+		require.NoError(t, env.svc.StartReset(ctx, *env.user.Mail))
+		token := env.sentResetToken(t)
+
+		env.expectPasswordReset("new-password")
+		require.NoError(t, env.svc.CompleteReset(ctx, token, "new-password"))
+		assert.Len(t, env.mailer.sent(), 2)
+
+		requireAccountRecoveryErrorCode(t, env.svc.CompleteReset(ctx, token, "other-password"), error_code.InvalidPasswordResetToken)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		t.Parallel()
+		env := newPasswordResetTestEnv(t, "log")
+		requireAccountRecoveryErrorCode(t, env.svc.CompleteReset(context.Background(), "made-up", "new-password"), error_code.InvalidPasswordResetToken)
+	})
+
+	t.Run("token expires", func(t *testing.T) {
+		t.Parallel()
+		env := newPasswordResetTestEnv(t, "log")
+		ctx := context.Background()
+		require.NoError(t, env.svc.StartReset(ctx, *env.user.Mail))
+		token := env.sentResetToken(t)
+
+		env.clock.Advance(1800 * time.Second)
+		requireAccountRecoveryErrorCode(t, env.svc.CompleteReset(ctx, token, "new-password"), error_code.InvalidPasswordResetToken)
+	})
+
+	t.Run("a new token drops the previous one", func(t *testing.T) {
+		t.Parallel()
+		env := newPasswordResetTestEnv(t, "log")
+		ctx := context.Background()
+		require.NoError(t, env.svc.StartReset(ctx, *env.user.Mail))
+		first := env.sentResetToken(t)
+		env.clock.Advance(passwordResetResendInterval * time.Second)
+		require.NoError(t, env.svc.StartReset(ctx, *env.user.Mail))
+		second := env.sentResetToken(t)
+
+		requireAccountRecoveryErrorCode(t, env.svc.CompleteReset(ctx, first, "new-password"), error_code.InvalidPasswordResetToken)
+		env.expectPasswordReset("new-password")
+		require.NoError(t, env.svc.CompleteReset(ctx, second, "new-password"))
+	})
+}
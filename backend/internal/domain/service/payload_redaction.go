@@ -0,0 +1,94 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const payloadRedacted = "[REDACTED]"
+
+// sensitivePayloadKeys are matched as substrings of lower cased json keys, their values never leave RedactPayload.
+var sensitivePayloadKeys = []string{"password", "secret", "token", "credential", "otp", "code"}
+
+// RedactPayload replaces the values of sensitive json keys (passwords, tokens, TOTP codes, ...) at any depth
+// and truncates the result to maxLength bytes. A non json body is only described by its size, it cannot be redacted.
+func RedactPayload(payload string, maxLength int) string {
+	if strings.TrimSpace(payload) == "" {
+		return ""
+	}
+
+	var decoded any
+	if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
+		return fmt.Sprintf("<%d bytes non-json body>", len(payload))
+	}
+	encoded, err := json.Marshal(redactPayloadValue(decoded))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes body>", len(payload))
+	}
+
+	summary := string(encoded)
+	if len(summary) <= maxLength {
+		return summary
+	}
+	cut := maxLength
+	for cut > 0 && !utf8.RuneStart(summary[cut]) {
+		cut--
+	}
+	return summary[:cut] + "...(truncated)"
+}
+
+// RedactQuery replaces the values of sensitive query parameters, such as an OAuth code or a token, and returns the
+// decoded parameters sorted by key.
+func RedactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return fmt.Sprintf("<%d bytes unparsable query>", len(rawQuery))
+	}
+
+	var params []string
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		for _, value := range values[key] {
+			if isSensitivePayloadKey(key) {
+				value = payloadRedacted
+			}
+			params = append(params, key+"="+value)
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func redactPayloadValue(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		for key, value := range node {
+			if isSensitivePayloadKey(key) {
+				node[key] = payloadRedacted
+				continue
+			}
+			node[key] = redactPayloadValue(value)
+		}
+	case []any:
+		for i, value := range node {
+			node[i] = redactPayloadValue(value)
+		}
+	}
+	return v
+}
+
+func isSensitivePayloadKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitivePayloadKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	readinessProbeKeyPrefix = "readiness:probe:"
+	// readinessProbeTTL keeps a leftover probe key from a timed out check from piling up, in seconds
+	readinessProbeTTL = 60
+)
+
+func NewReadinessService(
+	rdsClient repository.IRdsClient,
+	cache repository.ICache,
+	githubClient client.IGithubAuthClient,
+	googleClient client.IGoogleAuthClient,
+	cfg config.Config,
+	clock client.IClock,
+) *ReadinessService {
+	// the fallback answers from memory while its backend fails, the probe has to reach the backend
+	keyValueStore, fallback := cache, false
+	if cacheWithFallback, ok := cache.(repository.ICacheWithFallback); ok {
+		keyValueStore, fallback = cacheWithFallback.Backend(), true
+	}
+	return &ReadinessService{
+		rdsClient:        rdsClient,
+		keyValueStore:    keyValueStore,
+		keyValueFallback: fallback,
+		githubClient:     githubClient,
+		googleClient:     googleClient,
+		cfg:              cfg,
+		clock:            clock,
+	}
+}
+
+// ReadinessService actively probes the dependencies the service needs to serve requests.
+type ReadinessService struct {
+	rdsClient repository.IRdsClient
+	// keyValueStore is the backend of the cache, keyValueFallback is set when a fallback serves while it fails
+	keyValueStore    repository.ICache
+	keyValueFallback bool
+	githubClient     client.IGithubAuthClient
+	googleClient     client.IGoogleAuthClient
+	cfg              config.Config
+	clock            client.IClock
+}
+
+type readinessCheck struct {
+	name     string
+	backend  string
+	probe    func(ctx context.Context) error
+	optional bool
+}
+
+// Check runs every probe concurrently, each bounded by the readiness timeout, and reports them in a stable order.
+func (s *ReadinessService) Check(ctx context.Context) entity.ReadinessReportEntity {
+	checks := []readinessCheck{
+		{name: "database", backend: s.cfg.DBType, probe: s.probeDatabase},
+		{name: "key_value_store", backend: s.cfg.KeyValueDBType, probe: s.probeKeyValueStore, optional: s.keyValueFallback},
+	}
+	if s.cfg.ReadinessCheckSSO {
+		if s.cfg.SSO_GITHUB_CLIENT_ID != "" {
+			checks = append(checks, readinessCheck{name: "sso_github", backend: "github", probe: s.githubClient.Ping})
+		}
+		if s.cfg.SSO_GOOGLE_CLIENT_ID != "" {
+			checks = append(checks, readinessCheck{name: "sso_google", backend: "google", probe: s.googleClient.Ping})
+		}
+	}
+
+	timeout := time.Duration(s.cfg.ReadinessCheckTimeout) * time.Second
+	results := make([]entity.ReadinessCheckEntity, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.run(ctx, check, timeout)
+		}()
+	}
+	wg.Wait()
+
+	report := entity.ReadinessReportEntity{Ready: true, Checks: results}
+	for _, result := range results {
+		report.Ready = report.Ready && (result.Healthy || result.Optional)
+	}
+	return report
+}
+
+func (s *ReadinessService) run(ctx context.Context, check readinessCheck, timeout time.Duration) entity.ReadinessCheckEntity {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := s.clock.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- errors.Errorf("probe panicked: %v", r)
+			}
+		}()
+		done <- check.probe(ctx)
+	}()
+
+	// a probe that ignores the context must not hang the readiness endpoint
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errors.Errorf("timed out after %s", timeout)
+	}
+
+	result := entity.ReadinessCheckEntity{
+		Name:     check.name,
+		Backend:  check.backend,
+		Healthy:  err == nil,
+		Latency:  s.clock.Now().Sub(start),
+		Optional: check.optional,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (s *ReadinessService) probeDatabase(ctx context.Context) error {
+	var one int
+	if err := s.rdsClient.DB().GetContext(ctx, &one, "SELECT 1"); err != nil {
+		return errors.Wrap(err, "database query failed")
+	}
+	return nil
+}
+
+// probeKeyValueStore writes, reads back and deletes a unique key, it proves the store is writable and not only open.
+func (s *ReadinessService) probeKeyValueStore(ctx context.Context) error {
+	key := readinessProbeKeyPrefix + uuid.New().String()
+	value := fmt.Sprintf("%d", s.clock.Now().UnixNano())
+
+	if err := s.keyValueStore.SetWithTTL(ctx, key, value, readinessProbeTTL); err != nil {
+		return errors.Wrap(err, "key value store write failed")
+	}
+	got, found, err := s.keyValueStore.Get(ctx, key)
+	if err != nil {
+		return errors.Wrap(err, "key value store read failed")
+	}
+	if !found || got != value {
+		return errors.New("key value store did not return the value just written")
+	}
+	if err := s.keyValueStore.Delete(ctx, key); err != nil {
+		return errors.Wrap(err, "key value store delete failed")
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+type fakeRdsClient struct {
+	db *sqlx.DB
+}
+
+func (f fakeRdsClient) DB() *sqlx.DB { return f.db }
+func (f fakeRdsClient) Close() error { return f.db.Close() }
+
+func newReadinessTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Open("sqlite", filepath.Join(t.TempDir(), "readiness.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestReadinessService_Check(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		DBType:                "sqlite",
+		KeyValueDBType:        "nutsdb",
+		ReadinessCheckTimeout: 1,
+		ReadinessCheckSSO:     true,
+		SSO_GITHUB_CLIENT_ID:  "github-client",
+	}
+
+	t.Run("every dependency healthy", func(t *testing.T) {
+		t.Parallel()
+
+		clock := fixtures.NewFakeClock(time.Now())
+		cache := fixtures.NewFakeCache(clock)
+		svc := NewReadinessService(fakeRdsClient{db: newReadinessTestDB(t)}, cache, &fakeGithubAuthClient{}, &fakeGoogleAuthClient{}, cfg, clock)
+
+		report := svc.Check(context.Background())
+		require.True(t, report.Ready)
+		require.Equal(t, []string{"database", "key_value_store", "sso_github"}, checkNames(report))
+		require.Equal(t, "sqlite", report.Checks[0].Backend)
+		require.Equal(t, "nutsdb", report.Checks[1].Backend)
+		// the probe key is cleaned up
+		require.Empty(t, cache.Keys())
+	})
+
+	t.Run("failed and hanging dependencies are reported", func(t *testing.T) {
+		t.Parallel()
+
+		db := newReadinessTestDB(t)
+		require.NoError(t, db.Close())
+		clock := fixtures.NewFakeClock(time.Now())
+		github := &hangingGithubAuthClient{}
+		svc := NewReadinessService(fakeRdsClient{db: db}, fixtures.NewFakeCache(clock), github, &fakeGoogleAuthClient{}, cfg, clock)
+
+		report := svc.Check(context.Background())
+		require.False(t, report.Ready)
+		require.False(t, report.Checks[0].Healthy)
+		require.Contains(t, report.Checks[0].Error, "database query failed")
+		require.True(t, report.Checks[1].Healthy)
+		require.False(t, report.Checks[2].Healthy)
+		require.Contains(t, report.Checks[2].Error, "timed out after 1s")
+	})
+
+	t.Run("sso providers are only probed when enabled and configured", func(t *testing.T) {
+		t.Parallel()
+
+		clock := fixtures.NewFakeClock(time.Now())
+		noSSO := cfg
+		noSSO.ReadinessCheckSSO = false
+		svc := NewReadinessService(fakeRdsClient{db: newReadinessTestDB(t)}, fixtures.NewFakeCache(clock), &hangingGithubAuthClient{}, &fakeGoogleAuthClient{}, noSSO, clock)
+
+		report := svc.Check(context.Background())
+		require.True(t, report.Ready)
+		require.Equal(t, []string{"database", "key_value_store"}, checkNames(report))
+	})
+
+	t.Run("a failed cache backend behind the fallback is reported without failing the probe", func(t *testing.T) {
+		t.Parallel()
+
+		clock := fixtures.NewFakeClock(time.Now())
+		cache := memoryFallbackCache{FakeCache: fixtures.NewFakeCache(clock), backend: brokenCache{}}
+		svc := NewReadinessService(fakeRdsClient{db: newReadinessTestDB(t)}, cache, &fakeGithubAuthClient{}, &fakeGoogleAuthClient{}, cfg, clock)
+
+		report := svc.Check(context.Background())
+		require.True(t, report.Ready)
+		require.False(t, report.Checks[1].Healthy)
+		require.True(t, report.Checks[1].Optional)
+		require.Contains(t, report.Checks[1].Error, "key value store write failed")
+	})
+}
+
+// brokenCache fails every operation, like a key value store that is down.
+type brokenCache struct{}
+
+func (brokenCache) Set(ctx context.Context, key string, value string) error {
+	return errors.New("connection refused")
+}
+func (brokenCache) SetWithTTL(ctx context.Context, key string, value string, ttl uint64) error {
+	return errors.New("connection refused")
+}
+func (brokenCache) Get(ctx context.Context, key string) (string, bool, error) {
+	return "", false, errors.New("connection refused")
+}
+func (brokenCache) Increment(ctx context.Context, key string, ttl uint64) (int64, error) {
+	return 0, errors.New("connection refused")
+}
+func (brokenCache) Delete(ctx context.Context, key string) error {
+	return errors.New("connection refused")
+}
+func (brokenCache) Has(ctx context.Context, key string) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+// memoryFallbackCache answers from memory whatever its backend does, like the cache fallback.
+type memoryFallbackCache struct {
+	*fixtures.FakeCache
+	backend repository.ICache
+}
+
+func (c memoryFallbackCache) Backend() repository.ICache { return c.backend }
+
+// hangingGithubAuthClient never answers a ping, like a provider behind a black holed network.
+type hangingGithubAuthClient struct {
+	fakeGithubAuthClient
+}
+
+func (f *hangingGithubAuthClient) Ping(ctx context.Context) error {
+	select {}
+}
+
+func checkNames(report entity.ReadinessReportEntity) []string {
+	names := make([]string, len(report.Checks))
+	for i, check := range report.Checks {
+		names[i] = check.Name
+	}
+	return names
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/requestid"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+type SessionRevocationReason string
+
+const (
+	// SessionRevocationReasonPasswordChanged revokes the other sessions when REVOKE_SESSIONS_ON_PASSWORD_CHANGE is set
+	SessionRevocationReasonPasswordChanged SessionRevocationReason = "password_changed"
+	// SessionRevocationReason2FAEnabled revokes the other sessions when REVOKE_SESSIONS_ON_2FA_ENABLE is set
+	SessionRevocationReason2FAEnabled SessionRevocationReason = "2fa_enabled"
+
+	// sessionRevocationAuditEventPrefix prefixes the reason in the route of the audit events
+	sessionRevocationAuditEventPrefix = "sessions_revoked."
+)
+
+type sessionRevocationEvent struct {
+	Reason SessionRevocationReason `json:"reason"`
+	UserID entity.UserIDEntity     `json:"user_id"`
+}
+
+func NewSessionRevocationService(
+	userRepo repository.IUserRepository,
+	accessTokenRepo repository.IAuthAccessTokenRepository,
+	refreshTokenRepo repository.IAuthRefreshTokenRepository,
+	auditLogService *AuditLogService,
+	mailer domain_client.IMailer,
+	cfg config.Config,
+) *SessionRevocationService {
+	return &SessionRevocationService{
+		userRepo:         userRepo,
+		accessTokenRepo:  accessTokenRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		auditLogService:  auditLogService,
+		mailer:           mailer,
+		config:           cfg,
+	}
+}
+
+// SessionRevocationService signs a user out of every other device once the password changed or a 2FA method was
+// enabled, as configured. The session making the change keeps its refresh token and access tokens.
+type SessionRevocationService struct {
+	userRepo         repository.IUserRepository
+	accessTokenRepo  repository.IAuthAccessTokenRepository
+	refreshTokenRepo repository.IAuthRefreshTokenRepository
+	auditLogService  *AuditLogService
+	mailer           domain_client.IMailer
+	config           config.Config
+}
+
+// Enabled reports whether the other sessions are revoked for the reason.
+func (s *SessionRevocationService) Enabled(reason SessionRevocationReason) bool {
+	switch reason {
+	case SessionRevocationReasonPasswordChanged:
+		return s.config.RevokeSessionsOnPasswordChange
+	case SessionRevocationReason2FAEnabled:
+		return s.config.RevokeSessionsOn2FAEnable
+	default:
+		return false
+	}
+}
+
+// RevokeOtherSessions deletes the user's refresh and access tokens but those of the session holding the refresh token
+// with currentRefreshTokenHash, when the reason is enabled. It is called before the change is stored, so a failed
+// revocation leaves the credentials as they were. Returns whether sessions were revoked, NotifyRevokedSessions is
+// called once the change is stored.
+func (s *SessionRevocationService) RevokeOtherSessions(ctx context.Context, userID entity.UserIDEntity, currentRefreshTokenHash string, reason SessionRevocationReason) (bool, error) {
+	if !s.Enabled(reason) {
+		return false, nil
+	}
+
+	if err := s.refreshTokenRepo.DeleteOtherTokensByUserID(ctx, userID, currentRefreshTokenHash); err != nil {
+		return false, errors.Wrapf(err, "fail to delete other refresh tokens")
+	}
+	if err := s.accessTokenRepo.DeleteOtherTokensByUserID(ctx, userID, currentRefreshTokenHash); err != nil {
+		return false, errors.Wrapf(err, "fail to delete other access tokens")
+	}
+
+	logger.Infof(ctx, "other sessions revoked: userid: %s reason: %s", userID, reason)
+	return true, nil
+}
+
+// NotifyRevokedSessions records the revocation as audit event and emails the account, failures are logged and do not
+// fail the change that is already stored.
+func (s *SessionRevocationService) NotifyRevokedSessions(ctx context.Context, userID entity.UserIDEntity, reason SessionRevocationReason) {
+	details, err := json.Marshal(sessionRevocationEvent{Reason: reason, UserID: userID})
+	if err != nil {
+		logger.Errorf(ctx, "Failed to marshal session revocation event: %v", err)
+	} else {
+		auditLog := entity.NewAuditEventEntityWithoutID(
+			userID,
+			sessionRevocationAuditEventPrefix+string(reason),
+			string(details),
+			requestid.GetRequestID(ctx),
+			requestid.GetClientIP(ctx),
+		)
+		if err := s.auditLogService.Record(ctx, auditLog); err != nil {
+			logger.Errorf(ctx, "Failed to record session revocation of user %s: %v", userID, err)
+		}
+	}
+
+	user, exists, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || !exists {
+		logger.Errorf(ctx, "Session revocation notice not sent, fail to get user %s: exists: %v err: %v", userID, exists, err)
+		return
+	}
+	if user.Mail == nil || *user.Mail == "" {
+		logger.Warnf(ctx, "Session revocation notice not sent, user %s has no email", userID)
+		return
+	}
+	subject, body := sessionRevocationNotice(user, reason)
+	if err := s.mailer.Send(ctx, entity.MailEntity{To: *user.Mail, Subject: subject, Body: body}); err != nil {
+		logger.Errorf(ctx, "Failed to send session revocation notice to user %s: %v", userID, err)
+	}
+}
+
+func sessionRevocationNotice(user entity.UserEntity, reason SessionRevocationReason) (subject string, body string) {
+	change := "The password of the account %s was changed"
+	subject = "Your password was changed"
+	if reason == SessionRevocationReason2FAEnabled {
+		change = "Two-factor authentication was enabled on the account %s"
+		subject = "Two-factor authentication enabled"
+	}
+	return subject, fmt.Sprintf(change+" and every other device signed out.\n\n"+
+		"If you did not do it, sign in again, change your password and contact an administrator right away.\n", user.Name)
+}
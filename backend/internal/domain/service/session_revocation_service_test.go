@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+// newTestSessionRevocationService builds the service without dependencies, for tests where the config leaves it disabled.
+func newTestSessionRevocationService(cfg config.Config) *SessionRevocationService {
+	return NewSessionRevocationService(nil, nil, nil, nil, nil, cfg)
+}
+
+type sessionRevocationTestEnv struct {
+	svc              *SessionRevocationService
+	userRepo         *mockgen.MockIUserRepository
+	accessTokenRepo  *mockgen.MockIAuthAccessTokenRepository
+	refreshTokenRepo *mockgen.MockIAuthRefreshTokenRepository
+	auditLogRepo     *mockgen.MockIAuditLogRepository
+	mailer           *recordingMailer
+}
+
+func newSessionRevocationTestEnv(t *testing.T, cfg config.Config) sessionRevocationTestEnv {
+	logger.InitLogger(config.Config{})
+	ctrl := gomock.NewController(t)
+	env := sessionRevocationTestEnv{
+		userRepo:         mockgen.NewMockIUserRepository(ctrl),
+		accessTokenRepo:  mockgen.NewMockIAuthAccessTokenRepository(ctrl),
+		refreshTokenRepo: mockgen.NewMockIAuthRefreshTokenRepository(ctrl),
+		auditLogRepo:     mockgen.NewMockIAuditLogRepository(ctrl),
+		mailer:           &recordingMailer{},
+	}
+	auditLogService := NewAuditLogService(env.auditLogRepo, NewSIEMExportService(nil, nil, config.Config{}), fixtures.NewFakeClock(time.Now()))
+	env.svc = NewSessionRevocationService(env.userRepo, env.accessTokenRepo, env.refreshTokenRepo, auditLogService, env.mailer, cfg)
+	return env
+}
+
+func TestSessionRevocationService_RevokeOtherSessions(t *testing.T) {
+	const userID = entity.UserIDEntity("user-1")
+	ctx := context.Background()
+
+	t.Run("disabled reasons leave the sessions alone", func(t *testing.T) {
+		env := newSessionRevocationTestEnv(t, config.Config{RevokeSessionsOnPasswordChange: true})
+
+		revoked, err := env.svc.RevokeOtherSessions(ctx, userID, "current", SessionRevocationReason2FAEnabled)
+
+		require.NoError(t, err)
+		assert.False(t, revoked)
+	})
+
+	t.Run("both token kinds are revoked but the current session", func(t *testing.T) {
+		env := newSessionRevocationTestEnv(t, config.Config{RevokeSessionsOn2FAEnable: true})
+		env.refreshTokenRepo.EXPECT().DeleteOtherTokensByUserID(ctx, userID, "current").Return(nil)
+		env.accessTokenRepo.EXPECT().DeleteOtherTokensByUserID(ctx, userID, "current").Return(nil)
+
+		revoked, err := env.svc.RevokeOtherSessions(ctx, userID, "current", SessionRevocationReason2FAEnabled)
+
+		require.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("a failed refresh token revocation stops before the access tokens", func(t *testing.T) {
+		env := newSessionRevocationTestEnv(t, config.Config{RevokeSessionsOnPasswordChange: true})
+		env.refreshTokenRepo.EXPECT().DeleteOtherTokensByUserID(ctx, userID, "current").Return(errors.New("redis offline"))
+
+		revoked, err := env.svc.RevokeOtherSessions(ctx, userID, "current", SessionRevocationReasonPasswordChanged)
+
+		require.ErrorContains(t, err, "fail to delete other refresh tokens")
+		assert.False(t, revoked)
+	})
+}
+
+func TestSessionRevocationService_NotifyRevokedSessions(t *testing.T) {
+	const userID = entity.UserIDEntity("user-1")
+	ctx := context.Background()
+
+	t.Run("records an audit event and emails the account", func(t *testing.T) {
+		env := newSessionRevocationTestEnv(t, config.Config{})
+		mail := "alice@example.com"
+		env.userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID, Name: "alice", Mail: &mail}, true, nil)
+		var recorded entity.AuditLogEntity
+		env.auditLogRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, auditLog entity.AuditLogEntity) error {
+			recorded = auditLog
+			return nil
+		})
+
+		env.svc.NotifyRevokedSessions(ctx, userID, SessionRevocationReason2FAEnabled)
+
+		assert.Equal(t, "sessions_revoked.2fa_enabled", recorded.Route)
+		assert.Equal(t, userID, recorded.ActorID)
+		var event sessionRevocationEvent
+		require.NoError(t, json.Unmarshal([]byte(recorded.Payload), &event))
+		assert.Equal(t, SessionRevocationReason2FAEnabled, event.Reason)
+
+		sent := env.mailer.sent()
+		require.Len(t, sent, 1)
+		assert.Equal(t, mail, sent[0].To)
+		assert.Equal(t, "Two-factor authentication enabled", sent[0].Subject)
+		assert.Contains(t, sent[0].Body, "every other device signed out")
+	})
+
+	t.Run("accounts without email only get the audit event", func(t *testing.T) {
+		env := newSessionRevocationTestEnv(t, config.Config{})
+		env.userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID, Name: "alice"}, true, nil)
+		env.auditLogRepo.EXPECT().Create(ctx, gomock.Any()).Return(errors.New("db offline"))
+
+		env.svc.NotifyRevokedSessions(ctx, userID, SessionRevocationReasonPasswordChanged)
+
+		assert.Empty(t, env.mailer.sent())
+	})
+}
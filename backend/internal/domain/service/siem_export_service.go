@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/metrics"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// siemRetryBackoff is the wait before the first retry of a failed batch, it doubles for every further retry
+	siemRetryBackoff    = time.Second
+	siemRetryMaxBackoff = time.Minute
+)
+
+func NewSIEMExportService(sink client.ISIEMSink, clock client.IClock, cfg config.Config) *SIEMExportService {
+	return &SIEMExportService{
+		sink:          sink,
+		clock:         clock,
+		config:        cfg,
+		enabled:       cfg.SIEMExport != "" && cfg.SIEMExport != "none",
+		events:        make(chan entity.SIEMEventEntity, max(cfg.SIEMBufferSize, 1)),
+		retryBackoff:  siemRetryBackoff,
+		flushInterval: time.Duration(max(cfg.SIEMFlushInterval, 1)) * time.Second,
+	}
+}
+
+// SIEMExportService streams security events to the configured SIEM sink in batches, in the background.
+// Events wait in a buffer of SIEM_BUFFER_SIZE, when it is full new events are dropped rather than slowing down requests.
+// A batch that cannot be delivered is retried SIEM_MAX_RETRIES times with a growing backoff, then dropped.
+// Drops are logged and counted in toolbake_siem_events_total.
+type SIEMExportService struct {
+	sink    client.ISIEMSink
+	clock   client.IClock
+	config  config.Config
+	enabled bool
+	events  chan entity.SIEMEventEntity
+
+	retryBackoff  time.Duration
+	flushInterval time.Duration
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// Enabled reports whether events are exported, callers can skip preparing events otherwise.
+func (s *SIEMExportService) Enabled() bool {
+	return s.enabled
+}
+
+// Export queues an event for the SIEM, it never blocks.
+func (s *SIEMExportService) Export(ctx context.Context, category entity.SIEMEventCategory, event entity.AuditLogEntity) {
+	if !s.enabled {
+		return
+	}
+	select {
+	case s.events <- entity.SIEMEventEntity{Category: category, Event: event}:
+	default:
+		metrics.SIEMEventsTotal.WithLabelValues("buffer_full").Inc()
+		logger.Warnf(ctx, "SIEM export buffer is full, dropped %s event %s", category, event.ID)
+	}
+}
+
+// Start runs the export loop in the background until Stop.
+func (s *SIEMExportService) Start() {
+	if !s.enabled {
+		return
+	}
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.stop = make(chan struct{})
+	s.stopped = make(chan struct{})
+	s.mu.Unlock()
+
+	go s.loop()
+}
+
+// Stop ends the export loop after the queued events were written once more, failed batches are not retried then.
+func (s *SIEMExportService) Stop() {
+	s.mu.Lock()
+	stop, stopped := s.stop, s.stopped
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-stopped
+}
+
+// Flush writes the queued events now, without retrying failed batches. It is for processes frozen between requests,
+// such as a Lambda instance, where the background loop can not be relied on.
+func (s *SIEMExportService) Flush() {
+	if !s.enabled {
+		return
+	}
+	s.drain(nil)
+}
+
+func (s *SIEMExportService) loop() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	var batch []entity.SIEMEventEntity
+	for {
+		select {
+		case event := <-s.events:
+			batch = append(batch, event)
+			if len(batch) < s.config.SIEMBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-s.stop:
+			s.drain(batch)
+			return
+		}
+		if len(batch) > 0 {
+			s.flush(batch, true)
+			batch = nil
+		}
+	}
+}
+
+// drain writes the pending batch and everything still queued, in batches of SIEM_BATCH_SIZE
+func (s *SIEMExportService) drain(batch []entity.SIEMEventEntity) {
+	for {
+		select {
+		case event := <-s.events:
+			batch = append(batch, event)
+			if len(batch) < s.config.SIEMBatchSize {
+				continue
+			}
+			s.flush(batch, false)
+			batch = nil
+		default:
+			if len(batch) > 0 {
+				s.flush(batch, false)
+			}
+			return
+		}
+	}
+}
+
+// flush writes a batch, retrying with backoff unless the service is stopping
+func (s *SIEMExportService) flush(batch []entity.SIEMEventEntity, retry bool) {
+	ctx := utils.NewValueContext(context.Background())
+	ctx.Set("x-request-id", uuid.New().String())
+	ctx.Set("request-start-time", s.clock.Now())
+
+	payload, err := FormatSIEMEvents(s.config.SIEMFormat, batch)
+	if err != nil {
+		metrics.SIEMEventsTotal.WithLabelValues("delivery_failed").Add(float64(len(batch)))
+		logger.Errorf(ctx, "Failed to format %d SIEM events, dropped: %v", len(batch), err)
+		return
+	}
+
+	attempts := 1
+	if retry {
+		attempts += s.config.SIEMMaxRetries
+	}
+	backoff := s.retryBackoff
+	for attempt := 1; ; attempt++ {
+		err = s.sink.Write(ctx, payload)
+		if err == nil {
+			metrics.SIEMEventsTotal.WithLabelValues("exported").Add(float64(len(batch)))
+			return
+		}
+		if attempt >= attempts {
+			break
+		}
+		logger.Warnf(ctx, "Failed to export %d SIEM events (attempt %d of %d), retrying in %s: %v", len(batch), attempt, attempts, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-s.stop:
+			// shutting down, the last attempt is made right away
+			attempts = attempt + 1
+		}
+		backoff = min(backoff*2, siemRetryMaxBackoff)
+	}
+	metrics.SIEMEventsTotal.WithLabelValues("delivery_failed").Add(float64(len(batch)))
+	logger.Errorf(ctx, "Failed to export %d SIEM events after %d attempts, dropped: %v", len(batch), attempts, err)
+}
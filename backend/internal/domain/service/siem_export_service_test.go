@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+// flakySIEMSink fails the first writes, then keeps the batches written to it.
+type flakySIEMSink struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	batches  []string
+}
+
+func (s *flakySIEMSink) Write(_ context.Context, batch []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("collector unavailable")
+	}
+	s.batches = append(s.batches, string(batch))
+	return nil
+}
+
+func TestSIEMExportService(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	event := func(id string) entity.AuditLogEntity {
+		return entity.AuditLogEntity{ID: id, Method: "POST", Route: "/api/v1/auth/login", Outcome: entity.AuditOutcomeSuccess}
+	}
+
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		failures int
+		events   []string
+		// writes the loop makes before Stop, Stop writes whatever is still queued once more
+		attemptsBeforeStop int
+		wantAttempts       int
+		wantBatches        int
+	}{
+		{
+			name:         "disabled export writes nothing",
+			cfg:          config.Config{SIEMExport: "none"},
+			events:       []string{"a"},
+			wantAttempts: 0,
+		},
+		{
+			name:               "events are written in batches",
+			cfg:                config.Config{SIEMExport: "http", SIEMFormat: "jsonl", SIEMBufferSize: 10, SIEMBatchSize: 2, SIEMFlushInterval: 60},
+			events:             []string{"a", "b", "c"},
+			attemptsBeforeStop: 1,
+			wantAttempts:       2,
+			wantBatches:        2,
+		},
+		{
+			name:               "failed batch is retried",
+			cfg:                config.Config{SIEMExport: "http", SIEMFormat: "jsonl", SIEMBufferSize: 10, SIEMBatchSize: 1, SIEMFlushInterval: 60, SIEMMaxRetries: 3},
+			failures:           2,
+			events:             []string{"a"},
+			attemptsBeforeStop: 3,
+			wantAttempts:       3,
+			wantBatches:        1,
+		},
+		{
+			name:               "batch is dropped after the retries",
+			cfg:                config.Config{SIEMExport: "http", SIEMFormat: "jsonl", SIEMBufferSize: 10, SIEMBatchSize: 1, SIEMFlushInterval: 60, SIEMMaxRetries: 1},
+			failures:           5,
+			events:             []string{"a"},
+			attemptsBeforeStop: 2,
+			wantAttempts:       2,
+			wantBatches:        0,
+		},
+		{
+			name:         "events beyond the buffer are dropped",
+			cfg:          config.Config{SIEMExport: "file", SIEMFormat: "jsonl", SIEMBufferSize: 2, SIEMBatchSize: 10, SIEMFlushInterval: 60},
+			events:       []string{"a", "b", "c"},
+			wantAttempts: 1,
+			wantBatches:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sink := &flakySIEMSink{failures: tt.failures}
+			svc := NewSIEMExportService(sink, fixtures.NewFakeClock(time.Unix(100, 0)), tt.cfg)
+			svc.retryBackoff = time.Millisecond
+
+			// queued before the loop runs, so the buffer limit is deterministic
+			for _, id := range tt.events {
+				svc.Export(context.Background(), entity.SIEMEventCategoryAuth, event(id))
+			}
+			svc.Start()
+			require.Eventually(t, func() bool {
+				sink.mu.Lock()
+				defer sink.mu.Unlock()
+				return sink.attempts >= tt.attemptsBeforeStop
+			}, time.Second, time.Millisecond)
+			svc.Stop()
+
+			require.Equal(t, tt.wantAttempts, sink.attempts)
+			require.Len(t, sink.batches, tt.wantBatches)
+		})
+	}
+}
+
+func TestSIEMExportService_Flush(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	sink := &flakySIEMSink{failures: 1}
+	cfg := config.Config{SIEMExport: "http", SIEMFormat: "jsonl", SIEMBufferSize: 10, SIEMBatchSize: 2, SIEMFlushInterval: 60, SIEMMaxRetries: 3}
+	svc := NewSIEMExportService(sink, fixtures.NewFakeClock(time.Unix(100, 0)), cfg)
+	for _, id := range []string{"a", "b", "c"} {
+		svc.Export(context.Background(), entity.SIEMEventCategoryAuth, entity.AuditLogEntity{ID: id})
+	}
+
+	// without the loop running, the first batch fails and is not retried
+	svc.Flush()
+	require.Equal(t, 2, sink.attempts)
+	require.Len(t, sink.batches, 1)
+
+	svc.Flush()
+	require.Equal(t, 2, sink.attempts, "nothing is left to write")
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	siemFormatJSONL = "jsonl"
+	siemFormatCEF   = "cef"
+
+	siemCEFVendor        = "WonderfulSoap"
+	siemCEFProduct       = "ToolBake"
+	siemCEFDeviceVersion = "1.0"
+)
+
+var (
+	siemCEFHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	siemCEFExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+type siemCEFExtension struct {
+	key   string
+	value string
+}
+
+// siemJSONEvent is one line of the jsonl export
+type siemJSONEvent struct {
+	ID        string `json:"id"`
+	Time      string `json:"time"`
+	Category  string `json:"category"`
+	ActorID   string `json:"actor_id,omitempty"`
+	Method    string `json:"method"`
+	Route     string `json:"route"`
+	Path      string `json:"path,omitempty"`
+	Payload   string `json:"payload,omitempty"`
+	Status    int    `json:"status,omitempty"`
+	Outcome   string `json:"outcome"`
+	RequestID string `json:"request_id,omitempty"`
+	ClientIP  string `json:"client_ip,omitempty"`
+}
+
+// FormatSIEMEvents renders a batch of events, one per line: a json object for jsonl or an ArcSight CEF record for cef.
+func FormatSIEMEvents(format string, events []entity.SIEMEventEntity) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, event := range events {
+		switch format {
+		case siemFormatCEF:
+			buf.WriteString(formatSIEMEventCEF(event))
+		case siemFormatJSONL:
+			line, err := json.Marshal(siemJSONEvent{
+				ID:        event.Event.ID,
+				Time:      event.Event.CreatedAt.UTC().Format(time.RFC3339Nano),
+				Category:  string(event.Category),
+				ActorID:   string(event.Event.ActorID),
+				Method:    event.Event.Method,
+				Route:     event.Event.Route,
+				Path:      event.Event.Path,
+				Payload:   event.Event.Payload,
+				Status:    event.Event.Status,
+				Outcome:   string(event.Event.Outcome),
+				RequestID: event.Event.RequestID,
+				ClientIP:  event.Event.ClientIP,
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "fail to marshal siem event %s", event.Event.ID)
+			}
+			buf.Write(line)
+		default:
+			return nil, errors.Errorf("unknown siem format %q", format)
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// formatSIEMEventCEF renders CEF:Version|Vendor|Product|Version|Signature ID|Name|Severity|Extension,
+// the signature id is the category with the method and route, e.g. auth:POST /api/v1/auth/login
+func formatSIEMEventCEF(event entity.SIEMEventEntity) string {
+	record := event.Event
+	name := record.Method + " " + record.Route
+	if record.Method == entity.AuditMethodEvent {
+		name = record.Route
+	}
+	signatureID := string(event.Category) + ":" + name
+
+	extensions := []string{
+		"rt=" + fmt.Sprint(record.CreatedAt.UnixMilli()),
+		"cat=" + siemCEFExtensionEscaper.Replace(string(event.Category)),
+		"externalId=" + siemCEFExtensionEscaper.Replace(record.ID),
+		"outcome=" + siemCEFExtensionEscaper.Replace(string(record.Outcome)),
+	}
+	optional := []siemCEFExtension{{"suser", string(record.ActorID)}}
+	if record.Method != entity.AuditMethodEvent {
+		// events the server raised have no request
+		optional = append(optional, siemCEFExtension{"requestMethod", record.Method}, siemCEFExtension{"request", record.Path})
+	}
+	if record.Status != 0 {
+		optional = append(optional, siemCEFExtension{"cn1Label", "status"}, siemCEFExtension{"cn1", fmt.Sprint(record.Status)})
+	}
+	if record.RequestID != "" {
+		optional = append(optional, siemCEFExtension{"cs1Label", "requestId"}, siemCEFExtension{"cs1", record.RequestID})
+	}
+	optional = append(optional, siemCEFExtension{"src", record.ClientIP}, siemCEFExtension{"msg", record.Payload})
+	for _, extension := range optional {
+		if extension.value == "" {
+			continue
+		}
+		extensions = append(extensions, extension.key+"="+siemCEFExtensionEscaper.Replace(extension.value))
+	}
+
+	return fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s",
+		siemCEFVendor,
+		siemCEFProduct,
+		siemCEFDeviceVersion,
+		siemCEFHeaderEscaper.Replace(signatureID),
+		siemCEFHeaderEscaper.Replace(name),
+		siemCEFSeverity(record.Outcome),
+		strings.Join(extensions, " "),
+	)
+}
+
+// siemCEFSeverity maps the outcome to the CEF scale of 0 to 10
+func siemCEFSeverity(outcome entity.AuditOutcome) int {
+	switch outcome {
+	case entity.AuditOutcomeAlert:
+		return 8
+	case entity.AuditOutcomeFailure:
+		return 5
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/domain/entity"
+)
+
+func TestFormatSIEMEvents(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	login := entity.AuditLogEntity{
+		ID:        "audit-1",
+		ActorID:   "user-1",
+		Method:    http.MethodPost,
+		Route:     "/api/v1/auth/login",
+		Path:      "/api/v1/auth/login",
+		Payload:   `{"password":"[REDACTED]","username":"a=b|c"}`,
+		Status:    http.StatusUnauthorized,
+		Outcome:   entity.AuditOutcomeFailure,
+		RequestID: "req-1",
+		ClientIP:  "10.0.0.1",
+		CreatedAt: createdAt,
+	}
+	alert := entity.NewAuditEventEntityWithoutID("user-2", "token_issuance.user_spike", `{"count":21}`, "", "10.0.0.2")
+	alert.ID = "audit-2"
+	alert.CreatedAt = createdAt
+	events := []entity.SIEMEventEntity{
+		{Category: entity.SIEMEventCategoryAuth, Event: login},
+		{Category: entity.SIEMEventCategoryAudit, Event: alert},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "jsonl",
+			format: "jsonl",
+			want: []string{
+				`{"id":"audit-1","time":"2026-01-02T03:04:05Z","category":"auth","actor_id":"user-1","method":"POST","route":"/api/v1/auth/login","path":"/api/v1/auth/login","payload":"{\"password\":\"[REDACTED]\",\"username\":\"a=b|c\"}","status":401,"outcome":"failure","request_id":"req-1","client_ip":"10.0.0.1"}`,
+				`{"id":"audit-2","time":"2026-01-02T03:04:05Z","category":"audit","actor_id":"user-2","method":"EVENT","route":"token_issuance.user_spike","payload":"{\"count\":21}","outcome":"alert","client_ip":"10.0.0.2"}`,
+			},
+		},
+		{
+			name:   "cef escapes the header and the extension values",
+			format: "cef",
+			want: []string{
+				`CEF:0|WonderfulSoap|ToolBake|1.0|auth:POST /api/v1/auth/login|POST /api/v1/auth/login|5|rt=1767323045000 cat=auth externalId=audit-1 outcome=failure suser=user-1 requestMethod=POST request=/api/v1/auth/login cn1Label=status cn1=401 cs1Label=requestId cs1=req-1 src=10.0.0.1 msg={"password":"[REDACTED]","username":"a\=b|c"}`,
+				`CEF:0|WonderfulSoap|ToolBake|1.0|audit:token_issuance.user_spike|token_issuance.user_spike|8|rt=1767323045000 cat=audit externalId=audit-2 outcome=alert suser=user-2 src=10.0.0.2 msg={"count":21}`,
+			},
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := FormatSIEMEvents(tt.format, events)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, strings.Join(tt.want, "\n")+"\n", string(got))
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+func NewSourceEncryptionService(
+	toolRepo repository.IToolRepository,
+	globalScriptRepo repository.IGlobalScriptRepository,
+	cfg config.Config,
+) *SourceEncryptionService {
+	return &SourceEncryptionService{toolRepo: toolRepo, globalScriptRepo: globalScriptRepo, config: cfg}
+}
+
+// SourceEncryptionService encrypts the tool sources and global scripts stored before ENCRYPT_SOURCES_AT_REST was
+// turned on, the repositories seal the ones saved since and open both transparently.
+type SourceEncryptionService struct {
+	toolRepo         repository.IToolRepository
+	globalScriptRepo repository.IGlobalScriptRepository
+	config           config.Config
+}
+
+// EncryptStoredSources seals the tool sources and global scripts still stored in plaintext or shared between users. It refuses to run without
+// ENCRYPT_SOURCES_AT_REST, the sources saved afterwards would be stored in plaintext again.
+func (s *SourceEncryptionService) EncryptStoredSources(ctx context.Context) (entity.SourceEncryptionResultEntity, error) {
+	if !s.config.EncryptSourcesAtRest {
+		return entity.SourceEncryptionResultEntity{}, errors.New("set ENCRYPT_SOURCES_AT_REST=true before encrypting the stored sources")
+	}
+
+	toolSources, err := s.toolRepo.EncryptStoredSources()
+	if err != nil {
+		return entity.SourceEncryptionResultEntity{}, errors.Wrap(err, "fail to encrypt stored tool sources")
+	}
+	globalScripts, err := s.globalScriptRepo.EncryptGlobalScripts()
+	if err != nil {
+		return entity.SourceEncryptionResultEntity{}, errors.Wrap(err, "fail to encrypt stored global scripts")
+	}
+
+	logger.Infof(ctx, "encrypted %d tool sources and %d global scripts", toolSources, globalScripts)
+	return entity.SourceEncryptionResultEntity{ToolSources: toolSources, GlobalScripts: globalScripts}, nil
+}
+
+// DropSearchSources empties the sources the full text search kept from before ENCRYPT_SOURCES_AT_REST was turned on,
+// it runs at startup so they do not wait for migrate -encrypt-sources.
+func (s *SourceEncryptionService) DropSearchSources(ctx context.Context) error {
+	if !s.config.EncryptSourcesAtRest {
+		return nil
+	}
+	if err := s.toolRepo.DropSearchSources(); err != nil {
+		return errors.Wrap(err, "fail to drop sources from the full text search")
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+)
+
+func TestSourceEncryptionService_EncryptStoredSources(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	t.Run("refuses without ENCRYPT_SOURCES_AT_REST", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		toolRepo := mockgen.NewMockIToolRepository(ctrl)
+		globalScriptRepo := mockgen.NewMockIGlobalScriptRepository(ctrl)
+
+		_, err := NewSourceEncryptionService(toolRepo, globalScriptRepo, config.Config{}).EncryptStoredSources(context.Background())
+		require.ErrorContains(t, err, "ENCRYPT_SOURCES_AT_REST")
+	})
+
+	t.Run("encrypts tool sources and global scripts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		toolRepo := mockgen.NewMockIToolRepository(ctrl)
+		globalScriptRepo := mockgen.NewMockIGlobalScriptRepository(ctrl)
+		toolRepo.EXPECT().EncryptStoredSources().Return(3, nil)
+		globalScriptRepo.EXPECT().EncryptGlobalScripts().Return(1, nil)
+
+		cfg := config.Config{EncryptSourcesAtRest: true}
+		result, err := NewSourceEncryptionService(toolRepo, globalScriptRepo, cfg).EncryptStoredSources(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, entity.SourceEncryptionResultEntity{ToolSources: 3, GlobalScripts: 1}, result)
+	})
+}
+
+func TestSourceEncryptionService_DropSearchSources(t *testing.T) {
+	t.Parallel()
+
+	t.Run("does nothing without ENCRYPT_SOURCES_AT_REST", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		toolRepo := mockgen.NewMockIToolRepository(ctrl)
+		globalScriptRepo := mockgen.NewMockIGlobalScriptRepository(ctrl)
+
+		require.NoError(t, NewSourceEncryptionService(toolRepo, globalScriptRepo, config.Config{}).DropSearchSources(context.Background()))
+	})
+
+	t.Run("drops the search sources", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		toolRepo := mockgen.NewMockIToolRepository(ctrl)
+		globalScriptRepo := mockgen.NewMockIGlobalScriptRepository(ctrl)
+		toolRepo.EXPECT().DropSearchSources().Return(nil)
+
+		cfg := config.Config{EncryptSourcesAtRest: true}
+		require.NoError(t, NewSourceEncryptionService(toolRepo, globalScriptRepo, cfg).DropSearchSources(context.Background()))
+	})
+}
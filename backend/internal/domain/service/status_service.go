@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+	"ya-tool-craft/internal/core/buildinfo"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+// statusCheckInterval is how long a readiness report is reused, the status route is public and must not
+// let anonymous callers hammer the database and the key value store with probes.
+const statusCheckInterval = 10 * time.Second
+
+func NewStatusService(readinessService *ReadinessService, clock client.IClock) *StatusService {
+	return &StatusService{readinessService: readinessService, clock: clock, startedAt: buildinfo.StartedAt()}
+}
+
+// StatusService reports the public status of the instance for status banners and uptime monitors.
+type StatusService struct {
+	readinessService *ReadinessService
+	clock            client.IClock
+	startedAt        time.Time
+
+	mu        sync.Mutex
+	report    entity.ReadinessReportEntity
+	checkedAt time.Time
+}
+
+// Status returns the build, the uptime and the health of every component from a readiness report at most
+// statusCheckInterval old.
+func (s *StatusService) Status(ctx context.Context) entity.StatusEntity {
+	report := s.readinessReport(ctx)
+
+	// a failed optional component leaves the instance ready, but degraded
+	healthy := true
+	components := make(map[string]bool, len(report.Checks))
+	for _, check := range report.Checks {
+		components[check.Name] = check.Healthy
+		healthy = healthy && check.Healthy
+	}
+	return entity.StatusEntity{
+		Healthy:    healthy,
+		Version:    buildinfo.Version,
+		Commit:     buildinfo.Revision(),
+		Uptime:     s.clock.Now().Sub(s.startedAt),
+		Components: components,
+	}
+}
+
+func (s *StatusService) readinessReport(ctx context.Context) entity.ReadinessReportEntity {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if s.checkedAt.IsZero() || now.Sub(s.checkedAt) >= statusCheckInterval {
+		s.report = s.readinessService.Check(ctx)
+		s.checkedAt = now
+	}
+	return s.report
+}
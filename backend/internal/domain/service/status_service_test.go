@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/buildinfo"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+func TestStatusService_Status(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{DBType: "sqlite", KeyValueDBType: "nutsdb", ReadinessCheckTimeout: 1}
+	clock := fixtures.NewFakeClock(time.Now())
+	db := newReadinessTestDB(t)
+	readiness := NewReadinessService(fakeRdsClient{db: db}, fixtures.NewFakeCache(clock), &fakeGithubAuthClient{}, &fakeGoogleAuthClient{}, cfg, clock)
+	svc := NewStatusService(readiness, clock)
+	svc.startedAt = clock.Now().Add(-time.Hour)
+
+	status := svc.Status(context.Background())
+	require.True(t, status.Healthy)
+	require.Equal(t, map[string]bool{"database": true, "key_value_store": true}, status.Components)
+	require.Equal(t, buildinfo.Version, status.Version)
+	require.NotEmpty(t, status.Commit)
+	require.Equal(t, time.Hour, status.Uptime)
+
+	// the report is reused within the check interval, the database is not probed again
+	require.NoError(t, db.Close())
+	clock.Advance(statusCheckInterval - time.Second)
+	status = svc.Status(context.Background())
+	require.True(t, status.Healthy)
+
+	clock.Advance(time.Second)
+	status = svc.Status(context.Background())
+	require.False(t, status.Healthy)
+	require.Equal(t, map[string]bool{"database": false, "key_value_store": true}, status.Components)
+}
+
+func TestStatusService_Status_DegradedCacheBackend(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{DBType: "sqlite", KeyValueDBType: "redis", ReadinessCheckTimeout: 1}
+	clock := fixtures.NewFakeClock(time.Now())
+	cache := memoryFallbackCache{FakeCache: fixtures.NewFakeCache(clock), backend: brokenCache{}}
+	readiness := NewReadinessService(fakeRdsClient{db: newReadinessTestDB(t)}, cache, &fakeGithubAuthClient{}, &fakeGoogleAuthClient{}, cfg, clock)
+
+	// the instance stays ready on the fallback, the status tells it is degraded
+	status := NewStatusService(readiness, clock).Status(context.Background())
+	require.False(t, status.Healthy)
+	require.Equal(t, map[string]bool{"database": true, "key_value_store": false}, status.Components)
+}
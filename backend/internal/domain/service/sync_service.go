@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// deviceNameMaxLength is the size of user_devices.name
+	deviceNameMaxLength = 255
+	// syncToolChangesPageSize is the tool changes read at a time, it bounds the tools a sync holds in memory
+	syncToolChangesPageSize = 100
+)
+
+func NewSyncService(toolRepo repository.IToolRepository, deviceRepo repository.IUserDeviceRepository) *SyncService {
+	return &SyncService{toolRepo: toolRepo, deviceRepo: deviceRepo}
+}
+
+// SyncService tracks the devices of a user and how far each of them has synced the user's tools.
+// Progress is a server side change sequence number, not a time, so it is exact no matter how skewed client clocks are.
+type SyncService struct {
+	toolRepo   repository.IToolRepository
+	deviceRepo repository.IUserDeviceRepository
+}
+
+// RegisterDevice issues a device for a login, name is a hint for the user such as the user agent.
+func (s *SyncService) RegisterDevice(ctx context.Context, userID entity.UserIDEntity, name string) (entity.UserDeviceEntity, error) {
+	if runes := []rune(name); len(runes) > deviceNameMaxLength {
+		name = string(runes[:deviceNameMaxLength])
+	}
+
+	device, err := s.deviceRepo.Create(ctx, userID, name)
+	if err != nil {
+		return entity.UserDeviceEntity{}, errors.Wrap(err, "fail to register device")
+	}
+	logger.Infof(ctx, "device registered: userid: %s, device: %s", userID, device.ID)
+	return device, nil
+}
+
+// Devices returns the devices of the user, last seen first.
+func (s *SyncService) Devices(ctx context.Context, userID entity.UserIDEntity) ([]entity.UserDeviceEntity, error) {
+	devices, err := s.deviceRepo.AllDevices(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get devices")
+	}
+	return devices, nil
+}
+
+// DeleteDevice forgets a device, it has to log in again to sync.
+func (s *SyncService) DeleteDevice(ctx context.Context, userID entity.UserIDEntity, deviceID string) error {
+	if _, err := s.device(ctx, userID, deviceID); err != nil {
+		return err
+	}
+	if err := s.deviceRepo.Delete(ctx, userID, deviceID); err != nil {
+		return errors.Wrap(err, "fail to delete device")
+	}
+	return nil
+}
+
+// StreamToolChanges passes the tool changes the device has not acknowledged yet to visit, in change order.
+// The changes are read a page at a time, so a user with many tools does not hold all of them in memory per request.
+// The cursor of the response is fixed before the first page and passed to start, changes made while streaming come
+// with the next sync. The uids of the deleted tools are returned at the end.
+// The device stays at its cursor until it acknowledges the returned one, so a lost response is simply fetched again.
+func (s *SyncService) StreamToolChanges(
+	ctx context.Context,
+	userID entity.UserIDEntity,
+	deviceID string,
+	start func(cursor int64) error,
+	visit func(tool entity.ToolEntity) error,
+) ([]string, error) {
+	device, err := s.device(ctx, userID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.toolRepo.LatestToolChangeCursor(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get latest tool change cursor")
+	}
+	if err := s.deviceRepo.Touch(ctx, userID, deviceID); err != nil {
+		return nil, errors.Wrap(err, "fail to update device last seen time")
+	}
+	// the cursor of a device never goes back, even when its changes were deleted with a merged account
+	upTo := max(latest, device.SyncCursor)
+	if err := start(upTo); err != nil {
+		return nil, err
+	}
+
+	deleted := []string{}
+	for cursor := device.SyncCursor; cursor < upTo; {
+		page, err := s.toolRepo.ToolChangesPage(userID, cursor, upTo, syncToolChangesPageSize)
+		if err != nil {
+			return nil, errors.Wrap(err, "fail to get tool changes")
+		}
+		if page.Cursor <= cursor {
+			break
+		}
+		for _, tool := range page.Tools {
+			if err := visit(tool); err != nil {
+				return nil, err
+			}
+		}
+		deleted = append(deleted, page.DeletedToolUIDs...)
+		cursor = page.Cursor
+	}
+	return deleted, nil
+}
+
+// AcknowledgeToolChanges moves the cursor of the device to the given one after the device applied the changes.
+// A cursor behind the stored one is allowed, the device then receives those changes again.
+func (s *SyncService) AcknowledgeToolChanges(ctx context.Context, userID entity.UserIDEntity, deviceID string, cursor int64) error {
+	if _, err := s.device(ctx, userID, deviceID); err != nil {
+		return err
+	}
+
+	latest, err := s.toolRepo.LatestToolChangeCursor(userID)
+	if err != nil {
+		return errors.Wrap(err, "fail to get latest tool change cursor")
+	}
+	if cursor < 0 || cursor > latest {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidSyncCursor, "cursor %d is not between 0 and the latest change %d", cursor, latest)
+	}
+
+	if err := s.deviceRepo.UpdateSyncCursor(ctx, userID, deviceID, cursor); err != nil {
+		return errors.Wrap(err, "fail to update device sync cursor")
+	}
+	return nil
+}
+
+// ToolsUpdatedSince returns the tools created and updated and the uids of the tools deleted at or after since, a zero
+// since returns every tool. It needs no device, but it goes by the server clock, so a change committed while a
+// slower change of the same moment is still in flight can be missed. Devices should sync with StreamToolChanges.
+func (s *SyncService) ToolsUpdatedSince(ctx context.Context, userID entity.UserIDEntity, since time.Time) (entity.ToolsUpdatedSinceEntity, error) {
+	changes, err := s.toolRepo.ToolsUpdatedSince(userID, since)
+	if err != nil {
+		return entity.ToolsUpdatedSinceEntity{}, errors.Wrap(err, "fail to get tools updated since")
+	}
+	return changes, nil
+}
+
+func (s *SyncService) device(ctx context.Context, userID entity.UserIDEntity, deviceID string) (entity.UserDeviceEntity, error) {
+	device, exists, err := s.deviceRepo.Get(ctx, userID, deviceID)
+	if err != nil {
+		return entity.UserDeviceEntity{}, errors.Wrap(err, "fail to get device")
+	}
+	if !exists {
+		return entity.UserDeviceEntity{}, error_code.NewErrorWithErrorCodef(error_code.DeviceNotFound, "device %s not found", deviceID)
+	}
+	return device, nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+)
+
+func TestSyncService_RegisterDevice(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	toolRepo := mockgen.NewMockIToolRepository(ctrl)
+	deviceRepo := mockgen.NewMockIUserDeviceRepository(ctrl)
+
+	// long user agents are cut to the column size
+	longName := strings.Repeat("あ", deviceNameMaxLength+10)
+	deviceRepo.EXPECT().
+		Create(ctx, entity.UserIDEntity("user-1"), strings.Repeat("あ", deviceNameMaxLength)).
+		Return(entity.UserDeviceEntity{ID: "d-1"}, nil)
+
+	device, err := NewSyncService(toolRepo, deviceRepo).RegisterDevice(ctx, "user-1", longName)
+	require.NoError(t, err)
+	require.Equal(t, "d-1", device.ID)
+}
+
+func TestSyncService_StreamToolChanges(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const (
+		userID   = entity.UserIDEntity("user-1")
+		deviceID = "d-1"
+	)
+
+	tests := []struct {
+		name        string
+		setupMocks  func(ctx context.Context, toolRepo *mockgen.MockIToolRepository, deviceRepo *mockgen.MockIUserDeviceRepository)
+		wantCursor  int64
+		wantToolIDs []string
+		wantDeleted []string
+		wantErrSub  string
+		wantErrCode *error_code.ErrorCode
+	}{
+		{
+			name: "unknown device returns error code",
+			setupMocks: func(ctx context.Context, toolRepo *mockgen.MockIToolRepository, deviceRepo *mockgen.MockIUserDeviceRepository) {
+				deviceRepo.EXPECT().Get(ctx, userID, deviceID).Return(entity.UserDeviceEntity{}, false, nil)
+			},
+			wantErrSub:  "device d-1 not found",
+			wantErrCode: &error_code.DeviceNotFound,
+		},
+		{
+			name: "ToolChangesPage error is wrapped",
+			setupMocks: func(ctx context.Context, toolRepo *mockgen.MockIToolRepository, deviceRepo *mockgen.MockIUserDeviceRepository) {
+				deviceRepo.EXPECT().Get(ctx, userID, deviceID).Return(entity.UserDeviceEntity{ID: deviceID, SyncCursor: 5}, true, nil)
+				toolRepo.EXPECT().LatestToolChangeCursor(userID).Return(int64(8), nil)
+				deviceRepo.EXPECT().Touch(ctx, userID, deviceID).Return(nil)
+				toolRepo.EXPECT().ToolChangesPage(userID, int64(5), int64(8), syncToolChangesPageSize).Return(entity.ToolChangesEntity{}, errors.New("db offline"))
+			},
+			wantErrSub: "fail to get tool changes",
+		},
+		{
+			name: "changes after the device cursor are streamed page by page",
+			setupMocks: func(ctx context.Context, toolRepo *mockgen.MockIToolRepository, deviceRepo *mockgen.MockIUserDeviceRepository) {
+				deviceRepo.EXPECT().Get(ctx, userID, deviceID).Return(entity.UserDeviceEntity{ID: deviceID, SyncCursor: 5}, true, nil)
+				toolRepo.EXPECT().LatestToolChangeCursor(userID).Return(int64(9), nil)
+				deviceRepo.EXPECT().Touch(ctx, userID, deviceID).Return(nil)
+				gomock.InOrder(
+					toolRepo.EXPECT().ToolChangesPage(userID, int64(5), int64(9), syncToolChangesPageSize).Return(entity.ToolChangesEntity{
+						Cursor:          7,
+						Tools:           []entity.ToolEntity{{ID: "tool-a"}},
+						DeletedToolUIDs: []string{"uid-1"},
+					}, nil),
+					toolRepo.EXPECT().ToolChangesPage(userID, int64(7), int64(9), syncToolChangesPageSize).Return(entity.ToolChangesEntity{
+						Cursor: 9,
+						Tools:  []entity.ToolEntity{{ID: "tool-b"}, {ID: "tool-c"}},
+					}, nil),
+				)
+			},
+			wantCursor:  9,
+			wantToolIDs: []string{"tool-a", "tool-b", "tool-c"},
+			wantDeleted: []string{"uid-1"},
+		},
+		{
+			name: "device ahead of the changes keeps its cursor",
+			setupMocks: func(ctx context.Context, toolRepo *mockgen.MockIToolRepository, deviceRepo *mockgen.MockIUserDeviceRepository) {
+				deviceRepo.EXPECT().Get(ctx, userID, deviceID).Return(entity.UserDeviceEntity{ID: deviceID, SyncCursor: 12}, true, nil)
+				toolRepo.EXPECT().LatestToolChangeCursor(userID).Return(int64(4), nil)
+				deviceRepo.EXPECT().Touch(ctx, userID, deviceID).Return(nil)
+			},
+			wantCursor:  12,
+			wantDeleted: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			toolRepo := mockgen.NewMockIToolRepository(ctrl)
+			deviceRepo := mockgen.NewMockIUserDeviceRepository(ctrl)
+			tt.setupMocks(ctx, toolRepo, deviceRepo)
+
+			var cursor int64
+			var toolIDs []string
+			deleted, err := NewSyncService(toolRepo, deviceRepo).StreamToolChanges(ctx, userID, deviceID,
+				func(c int64) error {
+					cursor = c
+					return nil
+				},
+				func(tool entity.ToolEntity) error {
+					toolIDs = append(toolIDs, tool.ID)
+					return nil
+				},
+			)
+
+			if tt.wantErrSub != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+				if tt.wantErrCode != nil {
+					var ecErr error_code.ErrorWithErrorCode
+					require.True(t, errors.As(err, &ecErr))
+					require.Equal(t, tt.wantErrCode.Code, ecErr.ErrorCode.Code)
+				}
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantCursor, cursor)
+				require.Equal(t, tt.wantToolIDs, toolIDs)
+				require.Equal(t, tt.wantDeleted, deleted)
+			}
+		})
+	}
+}
+
+func TestSyncService_AcknowledgeToolChanges(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const (
+		userID   = entity.UserIDEntity("user-1")
+		deviceID = "d-1"
+	)
+
+	tests := []struct {
+		name        string
+		cursor      int64
+		setupMocks  func(ctx context.Context, toolRepo *mockgen.MockIToolRepository, deviceRepo *mockgen.MockIUserDeviceRepository)
+		wantErrCode *error_code.ErrorCode
+	}{
+		{
+			name:   "unknown device returns error code",
+			cursor: 3,
+			setupMocks: func(ctx context.Context, toolRepo *mockgen.MockIToolRepository, deviceRepo *mockgen.MockIUserDeviceRepository) {
+				deviceRepo.EXPECT().Get(ctx, userID, deviceID).Return(entity.UserDeviceEntity{}, false, nil)
+			},
+			wantErrCode: &error_code.DeviceNotFound,
+		},
+		{
+			name:   "cursor ahead of the latest change is rejected",
+			cursor: 11,
+			setupMocks: func(ctx context.Context, toolRepo *mockgen.MockIToolRepository, deviceRepo *mockgen.MockIUserDeviceRepository) {
+				deviceRepo.EXPECT().Get(ctx, userID, deviceID).Return(entity.UserDeviceEntity{ID: deviceID}, true, nil)
+				toolRepo.EXPECT().LatestToolChangeCursor(userID).Return(int64(10), nil)
+			},
+			wantErrCode: &error_code.InvalidSyncCursor,
+		},
+		{
+			name:   "cursor behind the stored one is stored",
+			cursor: 2,
+			setupMocks: func(ctx context.Context, toolRepo *mockgen.MockIToolRepository, deviceRepo *mockgen.MockIUserDeviceRepository) {
+				deviceRepo.EXPECT().Get(ctx, userID, deviceID).Return(entity.UserDeviceEntity{ID: deviceID, SyncCursor: 8}, true, nil)
+				toolRepo.EXPECT().LatestToolChangeCursor(userID).Return(int64(10), nil)
+				deviceRepo.EXPECT().UpdateSyncCursor(ctx, userID, deviceID, int64(2)).Return(nil)
+			},
+		},
+		{
+			name:   "latest cursor is stored",
+			cursor: 10,
+			setupMocks: func(ctx context.Context, toolRepo *mockgen.MockIToolRepository, deviceRepo *mockgen.MockIUserDeviceRepository) {
+				deviceRepo.EXPECT().Get(ctx, userID, deviceID).Return(entity.UserDeviceEntity{ID: deviceID, SyncCursor: 8}, true, nil)
+				toolRepo.EXPECT().LatestToolChangeCursor(userID).Return(int64(10), nil)
+				deviceRepo.EXPECT().UpdateSyncCursor(ctx, userID, deviceID, int64(10)).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			toolRepo := mockgen.NewMockIToolRepository(ctrl)
+			deviceRepo := mockgen.NewMockIUserDeviceRepository(ctrl)
+			tt.setupMocks(ctx, toolRepo, deviceRepo)
+
+			err := NewSyncService(toolRepo, deviceRepo).AcknowledgeToolChanges(ctx, userID, deviceID, tt.cursor)
+
+			if tt.wantErrCode != nil {
+				var ecErr error_code.ErrorWithErrorCode
+				require.True(t, errors.As(err, &ecErr))
+				require.Equal(t, tt.wantErrCode.Code, ecErr.ErrorCode.Code)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+func NewSystemInfoService(migration repository.IMigration) *SystemInfoService {
+	return &SystemInfoService{migration: migration}
+}
+
+// SystemInfoService reports facts about the running instance that operators compare across a fleet.
+type SystemInfoService struct {
+	migration repository.IMigration
+}
+
+// SchemaVersion returns the schema version applied to the database and the one this build expects.
+func (s *SystemInfoService) SchemaVersion(ctx context.Context) (entity.SchemaVersionEntity, error) {
+	version, err := s.migration.SchemaVersion(ctx)
+	if err != nil {
+		return entity.SchemaVersionEntity{}, errors.Wrap(err, "fail to get schema version")
+	}
+	return version, nil
+}
@@ -0,0 +1,445 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/scheduler"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// systemSettingsCacheKey caches the json encoded overrides read from the database.
+const systemSettingsCacheKey = "system_settings"
+
+const systemSettingMaxStringLength = 2000
+
+// ranges in seconds the token and challenge lifetimes can be set to
+const (
+	accessTokenTTLMin       = 60
+	accessTokenTTLMax       = 86400    // 1 day
+	refreshTokenTTLMin      = 3600     // 1 hour
+	refreshTokenTTLMax      = 63115200 // 2 years
+	webAuthnChallengeTTLMin = 30
+	webAuthnChallengeTTLMax = 3600
+)
+
+// systemSettingDefinition declares a runtime setting, the env config is the default when no override is stored.
+type systemSettingDefinition struct {
+	Key          string
+	Type         entity.SystemSettingType
+	Description  string
+	DefaultValue func(cfg config.Config) string
+	// Validate checks a new value after it was parsed as Type
+	Validate func(cfg config.Config, value string) error
+}
+
+var systemSettingDefinitions = []systemSettingDefinition{
+	{
+		Key:          entity.SystemSettingKeyUserRegistrationEnabled,
+		Type:         entity.SystemSettingTypeBool,
+		Description:  "Allow new users to sign up, by password or by SSO",
+		DefaultValue: func(cfg config.Config) string { return strconv.FormatBool(cfg.ENABLE_USER_REGISTRATION) },
+	},
+	{
+		Key:          entity.SystemSettingKeyPasswordLoginEnabled,
+		Type:         entity.SystemSettingTypeBool,
+		Description:  "Allow login with username and password",
+		DefaultValue: func(cfg config.Config) string { return strconv.FormatBool(cfg.ENABLE_PASSWORD_LOGIN) },
+	},
+	{
+		Key:          entity.SystemSettingKeyGithubSSOEnabled,
+		Type:         entity.SystemSettingTypeBool,
+		Description:  "Allow login with GitHub, requires SSO_GITHUB_CLIENT_ID and SSO_GITHUB_CLIENT_SECRET",
+		DefaultValue: func(cfg config.Config) string { return strconv.FormatBool(cfg.SSO_GITHUB_CLIENT_ID != "") },
+		Validate: func(cfg config.Config, value string) error {
+			if value == "true" && (cfg.SSO_GITHUB_CLIENT_ID == "" || cfg.SSO_GITHUB_CLIENT_SECRET == "") {
+				return errors.New("github sso is not configured, set SSO_GITHUB_CLIENT_ID and SSO_GITHUB_CLIENT_SECRET first")
+			}
+			return nil
+		},
+	},
+	{
+		Key:          entity.SystemSettingKeyGoogleSSOEnabled,
+		Type:         entity.SystemSettingTypeBool,
+		Description:  "Allow login with Google, requires SSO_GOOGLE_CLIENT_ID and SSO_GOOGLE_CLIENT_SECRET",
+		DefaultValue: func(cfg config.Config) string { return strconv.FormatBool(cfg.SSO_GOOGLE_CLIENT_ID != "") },
+		Validate: func(cfg config.Config, value string) error {
+			if value == "true" && (cfg.SSO_GOOGLE_CLIENT_ID == "" || cfg.SSO_GOOGLE_CLIENT_SECRET == "") {
+				return errors.New("google sso is not configured, set SSO_GOOGLE_CLIENT_ID and SSO_GOOGLE_CLIENT_SECRET first")
+			}
+			return nil
+		},
+	},
+	{
+		Key:          entity.SystemSettingKeyMaintenanceMessage,
+		Type:         entity.SystemSettingTypeString,
+		Description:  "Message shown to every user, e.g. an upcoming maintenance, empty to hide it",
+		DefaultValue: func(cfg config.Config) string { return "" },
+	},
+	{
+		Key:          entity.SystemSettingKeyMaxToolsPerUser,
+		Type:         entity.SystemSettingTypeInt,
+		Description:  "Max tools a user can create, 0 means unlimited",
+		DefaultValue: func(cfg config.Config) string { return "0" },
+		Validate: func(cfg config.Config, value string) error {
+			limit, _ := strconv.Atoi(value)
+			if limit < 0 || limit > 100000 {
+				return errors.New("must be between 0 and 100000")
+			}
+			return nil
+		},
+	},
+	{
+		Key:          entity.SystemSettingKeyAccessTokenTTL,
+		Type:         entity.SystemSettingTypeInt,
+		Description:  "Seconds an access token is valid, applies to the tokens issued from now on",
+		DefaultValue: func(cfg config.Config) string { return strconv.FormatUint(cfg.AccessTokenTTL, 10) },
+		Validate:     validateIntRangeSetting(accessTokenTTLMin, accessTokenTTLMax),
+	},
+	{
+		Key:          entity.SystemSettingKeyRefreshTokenTTL,
+		Type:         entity.SystemSettingTypeInt,
+		Description:  "Seconds a session lasts without signing in again, applies to the sessions started from now on",
+		DefaultValue: func(cfg config.Config) string { return strconv.FormatUint(cfg.RefreshTokenTTL, 10) },
+		Validate:     validateIntRangeSetting(refreshTokenTTLMin, refreshTokenTTLMax),
+	},
+	{
+		Key:          entity.SystemSettingKeyWebAuthnChallengeTTL,
+		Type:         entity.SystemSettingTypeInt,
+		Description:  "Seconds a passkey registration or login challenge can be answered",
+		DefaultValue: func(cfg config.Config) string { return strconv.Itoa(cfg.WebAuthnChallengeTTL) },
+		Validate:     validateIntRangeSetting(webAuthnChallengeTTLMin, webAuthnChallengeTTLMax),
+	},
+	{
+		Key:          entity.SystemSettingKeyRefreshTokenCleanupSchedule,
+		Type:         entity.SystemSettingTypeString,
+		Description:  "Cron expression of the job removing expired refresh tokens from the user indexes, empty disables it",
+		DefaultValue: func(cfg config.Config) string { return cfg.ScheduleRefreshTokenCleanup },
+		Validate:     validateScheduleSetting,
+	},
+	{
+		Key:          entity.SystemSettingKeyKeyValueCompactionSchedule,
+		Type:         entity.SystemSettingTypeString,
+		Description:  "Cron expression of the job compacting the nutsdb files, empty disables it",
+		DefaultValue: func(cfg config.Config) string { return cfg.ScheduleKeyValueCompaction },
+		Validate:     validateScheduleSetting,
+	},
+	{
+		Key:          entity.SystemSettingKeyDatabaseBackupSchedule,
+		Type:         entity.SystemSettingTypeString,
+		Description:  "Cron expression of the job backing up the sqlite database and the nutsdb store, empty disables it",
+		DefaultValue: func(cfg config.Config) string { return cfg.ScheduleDatabaseBackup },
+		Validate:     validateScheduleSetting,
+	},
+	{
+		Key:          entity.SystemSettingKeyDataRetentionSchedule,
+		Type:         entity.SystemSettingTypeString,
+		Description:  "Cron expression of the job deleting the records older than their retention window, empty disables it",
+		DefaultValue: func(cfg config.Config) string { return cfg.ScheduleDataRetention },
+		Validate:     validateScheduleSetting,
+	},
+	{
+		Key:          entity.SystemSettingKeyExpiredCachePurgeSchedule,
+		Type:         entity.SystemSettingTypeString,
+		Description:  "Cron expression of the job purging the expired 2FA and passkey entries from the memory cache fallback, empty disables it",
+		DefaultValue: func(cfg config.Config) string { return cfg.ScheduleExpiredCachePurge },
+		Validate:     validateScheduleSetting,
+	},
+	{
+		Key:          entity.SystemSettingKeyPasskeyChallengeEvictionSchedule,
+		Type:         entity.SystemSettingTypeString,
+		Description:  "Cron expression of the job forgetting the expired passkey login challenges counted by the challenge limits, empty disables it",
+		DefaultValue: func(cfg config.Config) string { return cfg.SchedulePasskeyChallengeEviction },
+		Validate:     validateScheduleSetting,
+	},
+}
+
+func validateIntRangeSetting(min int, max int) func(cfg config.Config, value string) error {
+	return func(cfg config.Config, value string) error {
+		parsed, _ := strconv.Atoi(value)
+		if parsed < min || parsed > max {
+			return fmt.Errorf("must be between %d and %d", min, max)
+		}
+		return nil
+	}
+}
+
+func validateScheduleSetting(cfg config.Config, value string) error {
+	if value == "" {
+		return nil
+	}
+	_, err := scheduler.ParseCron(value)
+	return err
+}
+
+var _ repository.ISessionTTLProvider = (*SystemSettingsService)(nil)
+
+func NewSystemSettingsService(
+	settingRepo repository.ISystemSettingRepository,
+	cache repository.ICache,
+	cfg config.Config,
+	clock client.IClock,
+) *SystemSettingsService {
+	return &SystemSettingsService{
+		settingRepo: settingRepo,
+		cache:       cache,
+		config:      cfg,
+		clock:       clock,
+	}
+}
+
+// SystemSettingsService serves the settings admins can change at runtime, layered on top of the env config.
+type SystemSettingsService struct {
+	settingRepo repository.ISystemSettingRepository
+	cache       repository.ICache
+	config      config.Config
+	clock       client.IClock
+}
+
+// Settings returns the effective typed settings.
+func (s *SystemSettingsService) Settings(ctx context.Context) (entity.SystemSettingsEntity, error) {
+	overrides, err := s.overrides(ctx)
+	if err != nil {
+		return entity.SystemSettingsEntity{}, err
+	}
+	return s.buildSettings(overrides), nil
+}
+
+// DefaultSettings returns the settings from env config only, used when the stored settings can not be read.
+func (s *SystemSettingsService) DefaultSettings() entity.SystemSettingsEntity {
+	return s.buildSettings(map[string]entity.SystemSettingEntity{})
+}
+
+// SSOEnabled reports if login and binding with the SSO provider is enabled.
+// Providers without a setting are reported enabled, they are rejected as unsupported by the provider lookup.
+func (s *SystemSettingsService) SSOEnabled(ctx context.Context, provider string) (bool, error) {
+	settings, err := s.Settings(ctx)
+	if err != nil {
+		return false, err
+	}
+	switch provider {
+	case "github":
+		return settings.EnableGithubSSO, nil
+	case "google":
+		return settings.EnableGoogleSSO, nil
+	default:
+		return true, nil
+	}
+}
+
+// AccessTokenTTL returns the seconds the access tokens issued now are valid.
+func (s *SystemSettingsService) AccessTokenTTL(ctx context.Context) uint64 {
+	return s.sessionSettings(ctx).AccessTokenTTL
+}
+
+// MaxAccessTokenTTL returns the longest an access token can live, whatever the setting was when it was issued.
+func (s *SystemSettingsService) MaxAccessTokenTTL() uint64 {
+	return max(s.config.AccessTokenTTL, accessTokenTTLMax)
+}
+
+// RefreshTokenTTL returns the seconds the refresh tokens issued now are valid.
+func (s *SystemSettingsService) RefreshTokenTTL(ctx context.Context) uint64 {
+	return s.sessionSettings(ctx).RefreshTokenTTL
+}
+
+// WebAuthnChallengeTTL returns the seconds the passkey challenges created now can be answered.
+func (s *SystemSettingsService) WebAuthnChallengeTTL(ctx context.Context) int {
+	return s.sessionSettings(ctx).WebAuthnChallengeTTL
+}
+
+// sessionSettings returns the settings or, when they can not be read, the env defaults: signing in must not fail
+// because the settings store does.
+func (s *SystemSettingsService) sessionSettings(ctx context.Context) entity.SystemSettingsEntity {
+	settings, err := s.Settings(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to read the system settings, the env token lifetimes apply: %v", err)
+		return s.DefaultSettings()
+	}
+	return settings
+}
+
+// AllSettingDetails lists every known setting with its effective value, for the admin.
+func (s *SystemSettingsService) AllSettingDetails(ctx context.Context) ([]entity.SystemSettingDetailEntity, error) {
+	overrides, err := s.overrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(systemSettingDefinitions, func(def systemSettingDefinition, _ int) entity.SystemSettingDetailEntity {
+		detail := entity.SystemSettingDetailEntity{
+			Key:          def.Key,
+			Type:         def.Type,
+			Description:  def.Description,
+			DefaultValue: def.DefaultValue(s.config),
+		}
+		detail.Value = detail.DefaultValue
+		if override, ok := overrides[def.Key]; ok {
+			updatedAt := override.UpdatedAt
+			detail.Value = override.Value
+			detail.Overridden = true
+			detail.UpdatedBy = override.UpdatedBy
+			detail.UpdatedAt = &updatedAt
+		}
+		return detail
+	}), nil
+}
+
+// UpdateSetting validates and stores an override of a setting.
+func (s *SystemSettingsService) UpdateSetting(ctx context.Context, actor entity.UserIDEntity, key string, value string) error {
+	def, ok := findSystemSettingDefinition(key)
+	if !ok {
+		return error_code.NewErrorWithErrorCodef(error_code.SystemSettingNotFound, "unknown setting: %s", key)
+	}
+
+	normalized, err := normalizeSystemSettingValue(def.Type, value)
+	if err == nil && def.Validate != nil {
+		err = def.Validate(s.config, normalized)
+	}
+	if err != nil {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidSystemSettingValue, "invalid value for setting %s: %s", key, err.Error())
+	}
+
+	if err := s.settingRepo.SaveSetting(ctx, entity.SystemSettingEntity{
+		Key:       key,
+		Value:     normalized,
+		UpdatedBy: actor,
+		UpdatedAt: s.clock.Now(),
+	}); err != nil {
+		return errors.Wrapf(err, "fail to save setting %s", key)
+	}
+
+	return s.invalidateCache(ctx)
+}
+
+// ResetSetting drops the override of a setting, its env config default applies again.
+func (s *SystemSettingsService) ResetSetting(ctx context.Context, key string) error {
+	if _, ok := findSystemSettingDefinition(key); !ok {
+		return error_code.NewErrorWithErrorCodef(error_code.SystemSettingNotFound, "unknown setting: %s", key)
+	}
+
+	if err := s.settingRepo.DeleteSetting(ctx, key); err != nil {
+		return errors.Wrapf(err, "fail to reset setting %s", key)
+	}
+
+	return s.invalidateCache(ctx)
+}
+
+func (s *SystemSettingsService) overrides(ctx context.Context) (map[string]entity.SystemSettingEntity, error) {
+	cached, found, err := s.cache.Get(ctx, systemSettingsCacheKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get system settings from cache")
+	}
+
+	var settings []entity.SystemSettingEntity
+	if found {
+		if err := json.Unmarshal([]byte(cached), &settings); err != nil {
+			return nil, errors.Wrap(err, "fail to decode cached system settings")
+		}
+	} else {
+		settings, err = s.settingRepo.AllSettings(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "fail to get system settings")
+		}
+
+		encoded, err := json.Marshal(settings)
+		if err != nil {
+			return nil, errors.Wrap(err, "fail to encode system settings for cache")
+		}
+		if err := s.cache.Set(ctx, systemSettingsCacheKey, string(encoded)); err != nil {
+			return nil, errors.Wrap(err, "fail to cache system settings")
+		}
+	}
+
+	return lo.SliceToMap(settings, func(setting entity.SystemSettingEntity) (string, entity.SystemSettingEntity) {
+		return setting.Key, setting
+	}), nil
+}
+
+func (s *SystemSettingsService) invalidateCache(ctx context.Context) error {
+	if err := s.cache.Delete(ctx, systemSettingsCacheKey); err != nil {
+		return errors.Wrap(err, "fail to delete system settings cache")
+	}
+	return nil
+}
+
+// buildSettings resolves every setting from its override or env default.
+// Stored values are validated on write, a value that does not parse anymore falls back to the default.
+func (s *SystemSettingsService) buildSettings(overrides map[string]entity.SystemSettingEntity) entity.SystemSettingsEntity {
+	value := func(key string) string {
+		def, _ := findSystemSettingDefinition(key)
+		if override, ok := overrides[key]; ok {
+			if normalized, err := normalizeSystemSettingValue(def.Type, override.Value); err == nil {
+				return normalized
+			}
+		}
+		return def.DefaultValue(s.config)
+	}
+	boolValue := func(key string) bool {
+		parsed, _ := strconv.ParseBool(value(key))
+		return parsed
+	}
+	intValue := func(key string) int {
+		parsed, _ := strconv.Atoi(value(key))
+		return parsed
+	}
+
+	return entity.SystemSettingsEntity{
+		// a demo instance never stores new accounts, whatever the setting says
+		EnableUserRegistration: boolValue(entity.SystemSettingKeyUserRegistrationEnabled) && !s.config.DemoMode,
+		EnablePasswordLogin:    boolValue(entity.SystemSettingKeyPasswordLoginEnabled),
+		EnableGithubSSO:        boolValue(entity.SystemSettingKeyGithubSSOEnabled),
+		EnableGoogleSSO:        boolValue(entity.SystemSettingKeyGoogleSSOEnabled),
+		MaintenanceMessage:     value(entity.SystemSettingKeyMaintenanceMessage),
+		MaxToolsPerUser:        intValue(entity.SystemSettingKeyMaxToolsPerUser),
+		AccessTokenTTL:         uint64(intValue(entity.SystemSettingKeyAccessTokenTTL)),
+		RefreshTokenTTL:        uint64(intValue(entity.SystemSettingKeyRefreshTokenTTL)),
+		WebAuthnChallengeTTL:   intValue(entity.SystemSettingKeyWebAuthnChallengeTTL),
+
+		RefreshTokenCleanupSchedule:      value(entity.SystemSettingKeyRefreshTokenCleanupSchedule),
+		KeyValueCompactionSchedule:       value(entity.SystemSettingKeyKeyValueCompactionSchedule),
+		DatabaseBackupSchedule:           value(entity.SystemSettingKeyDatabaseBackupSchedule),
+		DataRetentionSchedule:            value(entity.SystemSettingKeyDataRetentionSchedule),
+		ExpiredCachePurgeSchedule:        value(entity.SystemSettingKeyExpiredCachePurgeSchedule),
+		PasskeyChallengeEvictionSchedule: value(entity.SystemSettingKeyPasskeyChallengeEvictionSchedule),
+	}
+}
+
+func findSystemSettingDefinition(key string) (systemSettingDefinition, bool) {
+	return lo.Find(systemSettingDefinitions, func(def systemSettingDefinition) bool {
+		return def.Key == key
+	})
+}
+
+// normalizeSystemSettingValue parses a value as the setting type and returns its canonical string form.
+func normalizeSystemSettingValue(settingType entity.SystemSettingType, value string) (string, error) {
+	switch settingType {
+	case entity.SystemSettingTypeBool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", errors.New("must be true or false")
+		}
+		return strconv.FormatBool(parsed), nil
+	case entity.SystemSettingTypeInt:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return "", errors.New("must be an integer")
+		}
+		return strconv.Itoa(parsed), nil
+	case entity.SystemSettingTypeString:
+		if len(value) > systemSettingMaxStringLength {
+			return "", fmt.Errorf("must be at most %d characters", systemSettingMaxStringLength)
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("unsupported setting type %s", settingType)
+	}
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+// newTestSystemSettingsService creates a SystemSettingsService backed by an in-memory cache that reads the given overrides.
+func newTestSystemSettingsService(ctrl *gomock.Controller, cfg config.Config, overrides ...entity.SystemSettingEntity) *SystemSettingsService {
+	settingRepo := mockgen.NewMockISystemSettingRepository(ctrl)
+	settingRepo.EXPECT().AllSettings(gomock.Any()).Return(overrides, nil).AnyTimes()
+
+	clock := fixtures.NewFakeClock(time.Now())
+	return NewSystemSettingsService(settingRepo, fixtures.NewFakeCache(clock), cfg, clock)
+}
+
+func TestSystemSettingsService_Settings(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		ENABLE_USER_REGISTRATION: true,
+		ENABLE_PASSWORD_LOGIN:    false,
+		SSO_GITHUB_CLIENT_ID:     "github-client",
+	}
+
+	tests := []struct {
+		name       string
+		demoMode   bool
+		setupMocks func(settingRepo *mockgen.MockISystemSettingRepository, cacheRepo *mockgen.MockICache)
+		want       entity.SystemSettingsEntity
+		wantErrSub string
+	}{
+		{
+			name: "env config is the default",
+			setupMocks: func(settingRepo *mockgen.MockISystemSettingRepository, cacheRepo *mockgen.MockICache) {
+				cacheRepo.EXPECT().Get(gomock.Any(), systemSettingsCacheKey).Return("", false, nil)
+				settingRepo.EXPECT().AllSettings(gomock.Any()).Return([]entity.SystemSettingEntity{}, nil)
+				cacheRepo.EXPECT().Set(gomock.Any(), systemSettingsCacheKey, "[]").Return(nil)
+			},
+			want: entity.SystemSettingsEntity{EnableUserRegistration: true, EnableGithubSSO: true},
+		},
+		{
+			name: "stored overrides win over env config",
+			setupMocks: func(settingRepo *mockgen.MockISystemSettingRepository, cacheRepo *mockgen.MockICache) {
+				cacheRepo.EXPECT().Get(gomock.Any(), systemSettingsCacheKey).Return("", false, nil)
+				settingRepo.EXPECT().AllSettings(gomock.Any()).Return([]entity.SystemSettingEntity{
+					{Key: entity.SystemSettingKeyUserRegistrationEnabled, Value: "false"},
+					{Key: entity.SystemSettingKeyPasswordLoginEnabled, Value: "true"},
+					{Key: entity.SystemSettingKeyMaintenanceMessage, Value: "down at 10pm"},
+					{Key: entity.SystemSettingKeyMaxToolsPerUser, Value: "10"},
+				}, nil)
+				cacheRepo.EXPECT().Set(gomock.Any(), systemSettingsCacheKey, gomock.Any()).Return(nil)
+			},
+			want: entity.SystemSettingsEntity{
+				EnableUserRegistration: false,
+				EnablePasswordLogin:    true,
+				EnableGithubSSO:        true,
+				MaintenanceMessage:     "down at 10pm",
+				MaxToolsPerUser:        10,
+			},
+		},
+		{
+			name: "cached overrides skip the repository and broken values fall back to the default",
+			setupMocks: func(settingRepo *mockgen.MockISystemSettingRepository, cacheRepo *mockgen.MockICache) {
+				cacheRepo.EXPECT().Get(gomock.Any(), systemSettingsCacheKey).
+					Return(`[{"Key":"registration.enabled","Value":"not-a-bool"},{"Key":"sso.github.enabled","Value":"false"}]`, true, nil)
+			},
+			want: entity.SystemSettingsEntity{EnableUserRegistration: true, EnableGithubSSO: false},
+		},
+		{
+			name:     "demo mode closes registration",
+			demoMode: true,
+			setupMocks: func(settingRepo *mockgen.MockISystemSettingRepository, cacheRepo *mockgen.MockICache) {
+				cacheRepo.EXPECT().Get(gomock.Any(), systemSettingsCacheKey).
+					Return(`[{"Key":"registration.enabled","Value":"true"}]`, true, nil)
+			},
+			want: entity.SystemSettingsEntity{EnableUserRegistration: false, EnableGithubSSO: true},
+		},
+		{
+			name: "repository error is wrapped",
+			setupMocks: func(settingRepo *mockgen.MockISystemSettingRepository, cacheRepo *mockgen.MockICache) {
+				cacheRepo.EXPECT().Get(gomock.Any(), systemSettingsCacheKey).Return("", false, nil)
+				settingRepo.EXPECT().AllSettings(gomock.Any()).Return(nil, errors.New("db offline"))
+			},
+			wantErrSub: "fail to get system settings",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			settingRepo := mockgen.NewMockISystemSettingRepository(ctrl)
+			cacheRepo := mockgen.NewMockICache(ctrl)
+			tt.setupMocks(settingRepo, cacheRepo)
+
+			cfg := cfg
+			cfg.DemoMode = tt.demoMode
+			svc := NewSystemSettingsService(settingRepo, cacheRepo, cfg, fixtures.NewFakeClock(time.Now()))
+			got, err := svc.Settings(context.Background())
+			if tt.wantErrSub != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSystemSettingsService_UpdateSetting(t *testing.T) {
+	t.Parallel()
+
+	const actor = entity.UserIDEntity("admin-1")
+
+	tests := []struct {
+		name        string
+		key         string
+		value       string
+		setupMocks  func(settingRepo *mockgen.MockISystemSettingRepository, cacheRepo *mockgen.MockICache)
+		wantErrCode *error_code.ErrorCode
+		wantErrSub  string
+	}{
+		{
+			name:        "unknown key",
+			key:         "unknown.key",
+			value:       "true",
+			wantErrCode: &error_code.SystemSettingNotFound,
+		},
+		{
+			name:        "value must match the setting type",
+			key:         entity.SystemSettingKeyUserRegistrationEnabled,
+			value:       "yes please",
+			wantErrCode: &error_code.InvalidSystemSettingValue,
+		},
+		{
+			name:        "sso can not be enabled without credentials",
+			key:         entity.SystemSettingKeyGoogleSSOEnabled,
+			value:       "true",
+			wantErrCode: &error_code.InvalidSystemSettingValue,
+		},
+		{
+			name:        "int range is validated",
+			key:         entity.SystemSettingKeyMaxToolsPerUser,
+			value:       "-1",
+			wantErrCode: &error_code.InvalidSystemSettingValue,
+		},
+		{
+			name:        "token lifetimes are bounded",
+			key:         entity.SystemSettingKeyAccessTokenTTL,
+			value:       "30",
+			wantErrCode: &error_code.InvalidSystemSettingValue,
+		},
+		{
+			name:        "challenge lifetime is bounded",
+			key:         entity.SystemSettingKeyWebAuthnChallengeTTL,
+			value:       "7200",
+			wantErrCode: &error_code.InvalidSystemSettingValue,
+		},
+		{
+			name:  "value is normalized, saved and the cache dropped",
+			key:   entity.SystemSettingKeyUserRegistrationEnabled,
+			value: "0",
+			setupMocks: func(settingRepo *mockgen.MockISystemSettingRepository, cacheRepo *mockgen.MockICache) {
+				settingRepo.EXPECT().SaveSetting(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, setting entity.SystemSettingEntity) error {
+					require.Equal(t, entity.SystemSettingKeyUserRegistrationEnabled, setting.Key)
+					require.Equal(t, "false", setting.Value)
+					require.Equal(t, actor, setting.UpdatedBy)
+					require.WithinDuration(t, time.Now(), setting.UpdatedAt, time.Minute)
+					return nil
+				})
+				cacheRepo.EXPECT().Delete(gomock.Any(), systemSettingsCacheKey).Return(nil)
+			},
+		},
+		{
+			name:  "repository error is wrapped",
+			key:   entity.SystemSettingKeyMaintenanceMessage,
+			value: "maintenance",
+			setupMocks: func(settingRepo *mockgen.MockISystemSettingRepository, cacheRepo *mockgen.MockICache) {
+				settingRepo.EXPECT().SaveSetting(gomock.Any(), gomock.Any()).Return(errors.New("db offline"))
+			},
+			wantErrSub: "fail to save setting",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			settingRepo := mockgen.NewMockISystemSettingRepository(ctrl)
+			cacheRepo := mockgen.NewMockICache(ctrl)
+			if tt.setupMocks != nil {
+				tt.setupMocks(settingRepo, cacheRepo)
+			}
+
+			svc := NewSystemSettingsService(settingRepo, cacheRepo, config.Config{}, fixtures.NewFakeClock(time.Now()))
+			err := svc.UpdateSetting(context.Background(), actor, tt.key, tt.value)
+			switch {
+			case tt.wantErrCode != nil:
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, *tt.wantErrCode, codeErr.ErrorCode)
+			case tt.wantErrSub != "":
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+			default:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSystemSettingsService_ResetSetting(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	settingRepo := mockgen.NewMockISystemSettingRepository(ctrl)
+	cacheRepo := mockgen.NewMockICache(ctrl)
+	svc := NewSystemSettingsService(settingRepo, cacheRepo, config.Config{}, fixtures.NewFakeClock(time.Now()))
+
+	var codeErr error_code.ErrorWithErrorCode
+	require.ErrorAs(t, svc.ResetSetting(context.Background(), "unknown.key"), &codeErr)
+	require.Equal(t, error_code.SystemSettingNotFound, codeErr.ErrorCode)
+
+	settingRepo.EXPECT().DeleteSetting(gomock.Any(), entity.SystemSettingKeyMaintenanceMessage).Return(nil)
+	cacheRepo.EXPECT().Delete(gomock.Any(), systemSettingsCacheKey).Return(nil)
+	require.NoError(t, svc.ResetSetting(context.Background(), entity.SystemSettingKeyMaintenanceMessage))
+}
+
+func TestSystemSettingsService_AllSettingDetails(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	updatedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	svc := newTestSystemSettingsService(ctrl, config.Config{ENABLE_PASSWORD_LOGIN: true}, entity.SystemSettingEntity{
+		Key:       entity.SystemSettingKeyPasswordLoginEnabled,
+		Value:     "false",
+		UpdatedBy: "admin-1",
+		UpdatedAt: updatedAt,
+	})
+
+	details, err := svc.AllSettingDetails(context.Background())
+	require.NoError(t, err)
+	require.Len(t, details, len(systemSettingDefinitions))
+
+	for _, detail := range details {
+		if detail.Key != entity.SystemSettingKeyPasswordLoginEnabled {
+			require.False(t, detail.Overridden, detail.Key)
+			require.Equal(t, detail.DefaultValue, detail.Value, detail.Key)
+			continue
+		}
+		require.True(t, detail.Overridden)
+		require.Equal(t, "false", detail.Value)
+		require.Equal(t, "true", detail.DefaultValue)
+		require.Equal(t, entity.UserIDEntity("admin-1"), detail.UpdatedBy)
+		require.Equal(t, &updatedAt, detail.UpdatedAt)
+	}
+}
+
+func TestSystemSettingsService_SessionTTLs(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{AccessTokenTTL: 300, RefreshTokenTTL: 86400, WebAuthnChallengeTTL: 300}
+
+	t.Run("env config is the default", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		svc := newTestSystemSettingsService(ctrl, cfg)
+		ctx := context.Background()
+		require.Equal(t, uint64(300), svc.AccessTokenTTL(ctx))
+		require.Equal(t, uint64(86400), svc.RefreshTokenTTL(ctx))
+		require.Equal(t, 300, svc.WebAuthnChallengeTTL(ctx))
+		require.Equal(t, uint64(accessTokenTTLMax), svc.MaxAccessTokenTTL())
+	})
+
+	t.Run("stored overrides win over env config", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		svc := newTestSystemSettingsService(ctrl, cfg,
+			entity.SystemSettingEntity{Key: entity.SystemSettingKeyAccessTokenTTL, Value: "900"},
+			entity.SystemSettingEntity{Key: entity.SystemSettingKeyRefreshTokenTTL, Value: "3600"},
+			entity.SystemSettingEntity{Key: entity.SystemSettingKeyWebAuthnChallengeTTL, Value: "60"},
+		)
+		ctx := context.Background()
+		require.Equal(t, uint64(900), svc.AccessTokenTTL(ctx))
+		require.Equal(t, uint64(3600), svc.RefreshTokenTTL(ctx))
+		require.Equal(t, 60, svc.WebAuthnChallengeTTL(ctx))
+	})
+
+	t.Run("env config applies when the settings can not be read", func(t *testing.T) {
+		t.Parallel()
+		logger.InitLogger(config.Config{})
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		cacheRepo := mockgen.NewMockICache(ctrl)
+		cacheRepo.EXPECT().Get(gomock.Any(), systemSettingsCacheKey).Return("", false, errors.New("cache offline"))
+		svc := NewSystemSettingsService(mockgen.NewMockISystemSettingRepository(ctrl), cacheRepo, cfg, fixtures.NewFakeClock(time.Now()))
+		require.Equal(t, uint64(300), svc.AccessTokenTTL(context.Background()))
+	})
+
+	t.Run("revocations outlive an env ttl above the allowed range", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		svc := newTestSystemSettingsService(ctrl, config.Config{AccessTokenTTL: accessTokenTTLMax * 2})
+		require.Equal(t, uint64(accessTokenTTLMax*2), svc.MaxAccessTokenTTL())
+	})
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/metrics"
+	"ya-tool-craft/internal/core/requestid"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/samber/lo"
+)
+
+type TokenIssuanceAnomalyKind string
+
+const (
+	// TokenIssuanceAnomalyUserSpike is one user getting more refresh tokens than TOKEN_ANOMALY_USER_THRESHOLD
+	TokenIssuanceAnomalyUserSpike TokenIssuanceAnomalyKind = "user_spike"
+	// TokenIssuanceAnomalyUserManyIPs is one user getting refresh tokens from more ips than TOKEN_ANOMALY_USER_IP_THRESHOLD
+	TokenIssuanceAnomalyUserManyIPs TokenIssuanceAnomalyKind = "user_many_ips"
+	// TokenIssuanceAnomalyGlobalSpike is all users together getting more refresh tokens than TOKEN_ANOMALY_GLOBAL_THRESHOLD
+	TokenIssuanceAnomalyGlobalSpike TokenIssuanceAnomalyKind = "global_spike"
+
+	// tokenIssuanceAuditEventPrefix prefixes the kind in the route of the audit events
+	tokenIssuanceAuditEventPrefix = "token_issuance."
+)
+
+type tokenIssuance struct {
+	at time.Time
+	ip string
+}
+
+type tokenIssuanceAnomaly struct {
+	Kind   TokenIssuanceAnomalyKind `json:"kind"`
+	UserID entity.UserIDEntity      `json:"user_id,omitempty"`
+	Count  int                      `json:"count"`
+	// Window is in seconds
+	Window int      `json:"window"`
+	IPs    []string `json:"ips,omitempty"`
+}
+
+func NewTokenIssuanceMonitor(auditLogService *AuditLogService, clock client.IClock, cfg config.Config) *TokenIssuanceMonitor {
+	return &TokenIssuanceMonitor{
+		auditLogService: auditLogService,
+		clock:           clock,
+		config:          cfg,
+		users:           map[entity.UserIDEntity][]tokenIssuance{},
+		flagged:         map[string]time.Time{},
+	}
+}
+
+// TokenIssuanceMonitor watches the refresh token issuance rate for the spikes credential stuffing causes and flags them
+// with an audit event, a warning log and the toolbake_token_issuance_anomalies_total metric.
+// The counts are kept in memory, every instance of the server watches the logins it serves.
+type TokenIssuanceMonitor struct {
+	auditLogService *AuditLogService
+	clock           client.IClock
+	config          config.Config
+
+	mu        sync.Mutex
+	global    []time.Time
+	users     map[entity.UserIDEntity][]tokenIssuance
+	lastSweep time.Time
+	// flagged holds when an anomaly was last flagged, it is not flagged again within the window
+	flagged map[string]time.Time
+}
+
+// Observe wraps the refresh token repository so every issued refresh token is recorded.
+func (m *TokenIssuanceMonitor) Observe(repo repository.IAuthRefreshTokenRepository) repository.IAuthRefreshTokenRepository {
+	return &observedRefreshTokenRepository{IAuthRefreshTokenRepository: repo, monitor: m}
+}
+
+// RecordIssuance counts a refresh token issued to the user and flags the anomalies it causes.
+func (m *TokenIssuanceMonitor) RecordIssuance(ctx context.Context, userID entity.UserIDEntity) {
+	anomalies := m.record(userID, requestid.GetClientIP(ctx))
+	for _, anomaly := range anomalies {
+		m.flag(ctx, anomaly)
+	}
+}
+
+func (m *TokenIssuanceMonitor) record(userID entity.UserIDEntity, ip string) []tokenIssuanceAnomaly {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	window := time.Duration(m.config.TokenAnomalyWindow) * time.Second
+	since := now.Add(-window)
+	m.sweep(now, since, window)
+
+	m.global = append(pruneIssuanceTimes(m.global, since), now)
+	issuances := append(pruneIssuances(m.users[userID], since), tokenIssuance{at: now, ip: ip})
+	m.users[userID] = issuances
+
+	var anomalies []tokenIssuanceAnomaly
+	windowSeconds := m.config.TokenAnomalyWindow
+	if threshold := m.config.TokenAnomalyUserThreshold; threshold > 0 && len(issuances) > threshold {
+		anomalies = append(anomalies, tokenIssuanceAnomaly{Kind: TokenIssuanceAnomalyUserSpike, UserID: userID, Count: len(issuances), Window: windowSeconds})
+	}
+	if threshold := m.config.TokenAnomalyUserIPThreshold; threshold > 0 {
+		ips := lo.Uniq(lo.FilterMap(issuances, func(issuance tokenIssuance, _ int) (string, bool) {
+			return issuance.ip, issuance.ip != ""
+		}))
+		if len(ips) > threshold {
+			anomalies = append(anomalies, tokenIssuanceAnomaly{Kind: TokenIssuanceAnomalyUserManyIPs, UserID: userID, Count: len(ips), Window: windowSeconds, IPs: ips})
+		}
+	}
+	if threshold := m.config.TokenAnomalyGlobalThreshold; threshold > 0 && len(m.global) > threshold {
+		anomalies = append(anomalies, tokenIssuanceAnomaly{Kind: TokenIssuanceAnomalyGlobalSpike, Count: len(m.global), Window: windowSeconds})
+	}
+
+	// an ongoing spike is flagged once per window, not on every token
+	return lo.Filter(anomalies, func(anomaly tokenIssuanceAnomaly, _ int) bool {
+		key := string(anomaly.Kind) + ":" + string(anomaly.UserID)
+		if flaggedAt, ok := m.flagged[key]; ok && flaggedAt.After(since) {
+			return false
+		}
+		m.flagged[key] = now
+		return true
+	})
+}
+
+// sweep drops the users and flags that have been quiet for a window, so the maps do not grow with every user ever seen
+func (m *TokenIssuanceMonitor) sweep(now time.Time, since time.Time, window time.Duration) {
+	if now.Sub(m.lastSweep) < window {
+		return
+	}
+	m.lastSweep = now
+	for userID, issuances := range m.users {
+		if issuances = pruneIssuances(issuances, since); len(issuances) == 0 {
+			delete(m.users, userID)
+		} else {
+			m.users[userID] = issuances
+		}
+	}
+	for key, flaggedAt := range m.flagged {
+		if !flaggedAt.After(since) {
+			delete(m.flagged, key)
+		}
+	}
+}
+
+func (m *TokenIssuanceMonitor) flag(ctx context.Context, anomaly tokenIssuanceAnomaly) {
+	logger.Warnf(ctx, "Token issuance anomaly %s: user: %s count: %d in %ds ips: %v", anomaly.Kind, anomaly.UserID, anomaly.Count, anomaly.Window, anomaly.IPs)
+	metrics.TokenIssuanceAnomaliesTotal.WithLabelValues(string(anomaly.Kind)).Inc()
+
+	details, err := json.Marshal(anomaly)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to marshal token issuance anomaly: %v", err)
+		return
+	}
+	auditLog := entity.NewAuditEventEntityWithoutID(
+		anomaly.UserID,
+		tokenIssuanceAuditEventPrefix+string(anomaly.Kind),
+		string(details),
+		requestid.GetRequestID(ctx),
+		requestid.GetClientIP(ctx),
+	)
+	// the token is already issued, a failed record must not fail the login
+	if err := m.auditLogService.Record(ctx, auditLog); err != nil {
+		logger.Errorf(ctx, "Failed to record token issuance anomaly: %v", err)
+	}
+}
+
+// pruneIssuances drops the issuances older than since, they are kept in issue order
+func pruneIssuances(issuances []tokenIssuance, since time.Time) []tokenIssuance {
+	for i, issuance := range issuances {
+		if issuance.at.After(since) {
+			return issuances[i:]
+		}
+	}
+	return nil
+}
+
+func pruneIssuanceTimes(times []time.Time, since time.Time) []time.Time {
+	for i, at := range times {
+		if at.After(since) {
+			return times[i:]
+		}
+	}
+	return nil
+}
+
+// observedRefreshTokenRepository records every refresh token it issues with the monitor
+type observedRefreshTokenRepository struct {
+	repository.IAuthRefreshTokenRepository
+	monitor *TokenIssuanceMonitor
+}
+
+func (r *observedRefreshTokenRepository) IssueRefreshToken(ctx context.Context, userID entity.UserIDEntity) (entity.RefreshToken, error) {
+	refreshToken, err := r.IAuthRefreshTokenRepository.IssueRefreshToken(ctx, userID)
+	if err != nil {
+		return refreshToken, err
+	}
+	r.monitor.RecordIssuance(ctx, userID)
+	return refreshToken, nil
+}
+
+func (r *observedRefreshTokenRepository) IssueClientRefreshToken(ctx context.Context, userID entity.UserIDEntity, clientID string, scopes []string) (entity.RefreshToken, error) {
+	refreshToken, err := r.IAuthRefreshTokenRepository.IssueClientRefreshToken(ctx, userID, clientID, scopes)
+	if err != nil {
+		return refreshToken, err
+	}
+	r.monitor.RecordIssuance(ctx, userID)
+	return refreshToken, nil
+}
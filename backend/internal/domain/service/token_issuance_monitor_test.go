@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+	"ya-tool-craft/internal/utils"
+)
+
+type tokenIssuanceStep struct {
+	userID entity.UserIDEntity
+	ip     string
+	// advance moves the clock before the issuance
+	advance time.Duration
+}
+
+func TestTokenIssuanceMonitor_RecordIssuance(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	cfg := config.Config{
+		TokenAnomalyWindow:          60,
+		TokenAnomalyUserThreshold:   3,
+		TokenAnomalyUserIPThreshold: 2,
+		TokenAnomalyGlobalThreshold: 5,
+	}
+	repeat := func(step tokenIssuanceStep, n int) []tokenIssuanceStep {
+		steps := make([]tokenIssuanceStep, n)
+		for i := range steps {
+			steps[i] = step
+		}
+		return steps
+	}
+
+	tests := []struct {
+		name       string
+		cfg        config.Config
+		steps      []tokenIssuanceStep
+		wantEvents []string
+	}{
+		{
+			name:  "normal logins are not flagged",
+			cfg:   cfg,
+			steps: repeat(tokenIssuanceStep{userID: "u-1", ip: "10.0.0.1"}, 3),
+		},
+		{
+			name:       "user spike is flagged once per window",
+			cfg:        cfg,
+			steps:      repeat(tokenIssuanceStep{userID: "u-1", ip: "10.0.0.1"}, 5),
+			wantEvents: []string{"token_issuance.user_spike"},
+		},
+		{
+			name:  "spread out logins are not a spike",
+			cfg:   cfg,
+			steps: repeat(tokenIssuanceStep{userID: "u-1", ip: "10.0.0.1", advance: 30 * time.Second}, 6),
+		},
+		{
+			name: "many ips for one account",
+			cfg:  cfg,
+			steps: []tokenIssuanceStep{
+				{userID: "u-1", ip: "10.0.0.1"},
+				{userID: "u-1", ip: "10.0.0.2"},
+				{userID: "u-1", ip: "10.0.0.3"},
+			},
+			wantEvents: []string{"token_issuance.user_many_ips"},
+		},
+		{
+			name: "global spike across users",
+			cfg:  cfg,
+			steps: []tokenIssuanceStep{
+				{userID: "u-1"}, {userID: "u-2"}, {userID: "u-3"}, {userID: "u-4"}, {userID: "u-5"}, {userID: "u-6"},
+			},
+			wantEvents: []string{"token_issuance.global_spike"},
+		},
+		{
+			name:  "zero thresholds disable the checks",
+			cfg:   config.Config{TokenAnomalyWindow: 60},
+			steps: repeat(tokenIssuanceStep{userID: "u-1", ip: "10.0.0.1"}, 10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			clock := fixtures.NewFakeClock(time.Date(2026, 1, 14, 3, 0, 0, 0, time.UTC))
+			auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+			var events []string
+			auditLogRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, auditLog entity.AuditLogEntity) error {
+				require.Equal(t, entity.AuditMethodEvent, auditLog.Method)
+				require.Equal(t, entity.AuditOutcomeAlert, auditLog.Outcome)
+				events = append(events, auditLog.Route)
+				return nil
+			}).AnyTimes()
+
+			monitor := NewTokenIssuanceMonitor(NewAuditLogService(auditLogRepo, NewSIEMExportService(nil, clock, config.Config{}), clock), clock, tt.cfg)
+			for _, step := range tt.steps {
+				clock.Advance(step.advance)
+				ctx := utils.NewValueContext(context.Background())
+				ctx.Set("client-ip", step.ip)
+				monitor.RecordIssuance(ctx, step.userID)
+			}
+
+			require.Equal(t, tt.wantEvents, events)
+		})
+	}
+}
+
+func TestTokenIssuanceMonitor_Observe(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	ctrl := gomock.NewController(t)
+
+	clock := fixtures.NewFakeClock(time.Now())
+	auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+	auditLogRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	refreshTokenRepo := mockgen.NewMockIAuthRefreshTokenRepository(ctrl)
+	refreshTokenRepo.EXPECT().IssueRefreshToken(gomock.Any(), entity.UserIDEntity("u-1")).Return(entity.RefreshToken{TokenHash: "hash"}, nil).Times(2)
+
+	cfg := config.Config{TokenAnomalyWindow: 60, TokenAnomalyUserThreshold: 1}
+	observed := NewTokenIssuanceMonitor(NewAuditLogService(auditLogRepo, NewSIEMExportService(nil, clock, config.Config{}), clock), clock, cfg).Observe(refreshTokenRepo)
+
+	for range 2 {
+		token, err := observed.IssueRefreshToken(context.Background(), "u-1")
+		require.NoError(t, err)
+		require.Equal(t, "hash", token.TokenHash)
+	}
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	// toolBundleUiWidgetsFile and toolBundleGlobalScriptFile name the widgets of a tool and the global script for the import scan
+	toolBundleUiWidgetsFile    = "uiWidgets.json"
+	toolBundleGlobalScriptFile = "global-script.js"
+
+	toolImportReasonQuotaExceeded = "tool quota exceeded"
+)
+
+// ExportTools returns a bundle of all tools of the user, archived ones included, the global script and the preferences.
+func (s *ToolService) ExportTools(ctx context.Context, userID entity.UserIDEntity) (entity.ToolBundleEntity, error) {
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return entity.ToolBundleEntity{}, errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	globalScript, err := s.globalScriptRepo.GetGlobalScript(userID)
+	if err != nil {
+		return entity.ToolBundleEntity{}, errors.Wrapf(err, "fail to get global script of user %s", userID)
+	}
+	settings, err := s.userSettingsService.StoredSettings(ctx, userID)
+	if err != nil {
+		return entity.ToolBundleEntity{}, err
+	}
+
+	bundle := entity.ToolBundleEntity{
+		FormatVersion: entity.ToolBundleFormatVersion,
+		ExportedAt:    s.clock.Now().UTC(),
+		Tools:         tools.Tools,
+		Settings:      settings,
+	}
+	if globalScript != nil {
+		bundle.GlobalScript = &globalScript.Script
+	}
+	logger.Infof(ctx, "Exported %d tools of user %s", len(bundle.Tools), userID)
+	return bundle, nil
+}
+
+// ImportTools saves the tools of a bundle as tools of the user. A tool is matched to an existing one by namespace and
+// name, the conflict tells whether the existing tool is kept, overwritten or the imported one is renamed. Every tool
+// passes the secret scan, quota and storage checks of a created tool, a tool that fails them is skipped and the
+// others are still imported. Reserved extra info keys are dropped, they describe the instance the bundle came from.
+// The global script and the preferences of the bundle replace the user's own ones only when overwriting.
+func (s *ToolService) ImportTools(ctx context.Context, userID entity.UserIDEntity, bundle entity.ToolBundleEntity, conflict entity.ToolImportConflict) (entity.ToolBundleImportEntity, error) {
+	if bundle.FormatVersion < 1 || bundle.FormatVersion > entity.ToolBundleFormatVersion {
+		return entity.ToolBundleImportEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidToolBundle, "bundle format version %d is not supported, the newest supported is %d", bundle.FormatVersion, entity.ToolBundleFormatVersion)
+	}
+	if conflict == "" {
+		conflict = entity.ToolImportConflictSkip
+	}
+
+	// the widgets and the global script are scanned apart from the sources, they must not eat into the files limit
+	sources := lo.Map(bundle.Tools, func(tool entity.ToolEntity, _ int) entity.ImportFileEntity {
+		return entity.ImportFileEntity{Name: path.Join(tool.Namespace, tool.Name), Content: []byte(tool.Source)}
+	})
+	if err := s.importScanService.ScanImportFiles(ctx, sources); err != nil {
+		return entity.ToolBundleImportEntity{}, err
+	}
+	others := lo.FilterMap(bundle.Tools, func(tool entity.ToolEntity, _ int) (entity.ImportFileEntity, bool) {
+		return entity.ImportFileEntity{Name: path.Join(tool.Namespace, tool.Name, toolBundleUiWidgetsFile), Content: []byte(tool.UiWidgets)}, tool.UiWidgets != ""
+	})
+	if bundle.GlobalScript != nil {
+		others = append(others, entity.ImportFileEntity{Name: toolBundleGlobalScriptFile, Content: []byte(*bundle.GlobalScript)})
+	}
+	if err := s.importScanService.ScanImportFiles(ctx, others); err != nil {
+		return entity.ToolBundleImportEntity{}, err
+	}
+
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return entity.ToolBundleImportEntity{}, errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	userTools := tools.Tools
+
+	results := make([]entity.ToolImportResultEntity, 0, len(bundle.Tools))
+	quotaExceeded := false
+	for _, imported := range bundle.Tools {
+		outcome, err := s.importTool(ctx, userID, userTools, imported, conflict, quotaExceeded)
+		if err != nil {
+			return entity.ToolBundleImportEntity{}, err
+		}
+		if outcome.Reason == toolImportReasonQuotaExceeded {
+			quotaExceeded = true
+		}
+		if outcome.saved.UniqueID != "" {
+			userTools = append(lo.Reject(userTools, func(tool entity.ToolEntity, _ int) bool {
+				return tool.UniqueID == outcome.saved.UniqueID
+			}), outcome.saved)
+		}
+		results = append(results, outcome.ToolImportResultEntity)
+	}
+
+	globalScriptImported, err := s.importGlobalScript(userID, bundle.GlobalScript, conflict)
+	if err != nil {
+		return entity.ToolBundleImportEntity{}, err
+	}
+	settingsImported, err := s.userSettingsService.ImportSettings(ctx, userID, bundle.Settings, conflict == entity.ToolImportConflictOverwrite)
+	if err != nil {
+		return entity.ToolBundleImportEntity{}, err
+	}
+
+	logger.Infof(ctx, "Imported %d of %d bundled tools for user %s", lo.CountBy(results, func(result entity.ToolImportResultEntity) bool {
+		return result.Action != entity.ToolImportActionSkipped
+	}), len(results), userID)
+	return entity.ToolBundleImportEntity{
+		Tools:                results,
+		GlobalScriptImported: globalScriptImported,
+		SettingsImported:     settingsImported,
+	}, nil
+}
+
+// toolImportOutcome is the result of importing one tool and the tool as it was saved, saved is empty when skipped
+type toolImportOutcome struct {
+	entity.ToolImportResultEntity
+	saved entity.ToolEntity
+}
+
+func (s *ToolService) importTool(
+	ctx context.Context,
+	userID entity.UserIDEntity,
+	userTools []entity.ToolEntity,
+	imported entity.ToolEntity,
+	conflict entity.ToolImportConflict,
+	quotaExceeded bool,
+) (toolImportOutcome, error) {
+	outcome := toolImportOutcome{ToolImportResultEntity: entity.ToolImportResultEntity{
+		Namespace: imported.Namespace,
+		Name:      imported.Name,
+		ToolID:    imported.ID,
+		Action:    entity.ToolImportActionSkipped,
+		Warnings:  []entity.ToolSecretFindingEntity{},
+	}}
+	skip := func(reason string) (toolImportOutcome, error) {
+		outcome.Reason = reason
+		return outcome, nil
+	}
+
+	if imported.ID == "" || imported.Name == "" {
+		return skip("tool id and name are required")
+	}
+	imported.ExtraInfo = entity.WithoutReservedToolExtraInfo(imported.ExtraInfo)
+	if err := entity.ValidateToolExtraInfo(imported.ExtraInfo); err != nil {
+		return skip(err.Error())
+	}
+	if imported.UiWidgets == "" {
+		imported.UiWidgets = "[]"
+	}
+	var widgets []json.RawMessage
+	if err := json.Unmarshal([]byte(imported.UiWidgets), &widgets); err != nil {
+		return skip("ui widgets are not a json array")
+	}
+
+	existing, exists := lo.Find(userTools, func(tool entity.ToolEntity) bool {
+		return tool.Namespace == imported.Namespace && tool.Name == imported.Name
+	})
+	if exists && conflict == entity.ToolImportConflictSkip {
+		return skip("a tool with the same namespace and name already exists")
+	}
+
+	scan := s.ScanToolSource(imported.Source)
+	outcome.Warnings = scan.Findings
+	if scan.Blocked {
+		return skip("source contains credentials")
+	}
+
+	now := s.clock.Now()
+	var tool entity.ToolEntity
+	if exists && conflict == entity.ToolImportConflictOverwrite {
+		tool = entity.NewToolEntityWithUID(
+			existing.UniqueID,
+			existing.ID,
+			imported.Name,
+			imported.Namespace,
+			imported.Category,
+			imported.IsActivate,
+			imported.RealtimeExecution,
+			imported.UiWidgets,
+			imported.Source,
+			imported.Description,
+			imported.ExtraInfo,
+			existing.CreatedAt,
+			now,
+		)
+		tool.IsArchived = existing.IsArchived
+	} else {
+		if quotaExceeded {
+			return skip(toolImportReasonQuotaExceeded)
+		}
+		if err := s.CheckToolQuota(ctx, userID); err != nil {
+			if !isErrorCode(err, error_code.ToolQuotaExceeded) {
+				return toolImportOutcome{}, err
+			}
+			return skip(toolImportReasonQuotaExceeded)
+		}
+		if exists {
+			imported.Name = freeToolName(userTools, imported.Namespace, imported.Name)
+		}
+		tool = copyTool(imported, userTools, "", nil, now)
+	}
+
+	if err := s.meteringService.CheckToolStorage(ctx, userID, tool); err != nil {
+		if !isErrorCode(err, error_code.UsageLimitExceeded) {
+			return toolImportOutcome{}, err
+		}
+		return skip("usage limit exceeded")
+	}
+
+	if exists && conflict == entity.ToolImportConflictOverwrite {
+		if err := s.toolRepo.UpdateTool(userID, tool, ToolEventSourceFromContext(ctx, userID)); err != nil {
+			return toolImportOutcome{}, errors.Wrapf(err, "fail to overwrite tool %s with the imported one", tool.UniqueID)
+		}
+		outcome.Action = entity.ToolImportActionOverwritten
+	} else {
+		created, err := s.CreateTool(ctx, userID, tool)
+		if err != nil {
+			return toolImportOutcome{}, errors.Wrapf(err, "fail to create imported tool %s", tool.ID)
+		}
+		tool = created
+		outcome.Action = entity.ToolImportActionCreated
+	}
+	if tool.IsArchived != imported.IsArchived {
+		if err := s.toolRepo.SetToolArchived(userID, tool.UniqueID, imported.IsArchived, ToolEventSourceFromContext(ctx, userID)); err != nil {
+			return toolImportOutcome{}, errors.Wrapf(err, "fail to set archived state of imported tool %s", tool.UniqueID)
+		}
+		tool.IsArchived = imported.IsArchived
+	}
+
+	outcome.Name = tool.Name
+	outcome.ToolID = tool.ID
+	outcome.ToolUniqueID = tool.UniqueID
+	outcome.saved = tool
+	return outcome, nil
+}
+
+// importGlobalScript saves the global script of a bundle, a different script of the user is only replaced when
+// overwriting. Returns whether the user's global script is the one of the bundle afterwards.
+func (s *ToolService) importGlobalScript(userID entity.UserIDEntity, script *string, conflict entity.ToolImportConflict) (bool, error) {
+	if script == nil {
+		return false, nil
+	}
+	existing, err := s.globalScriptRepo.GetGlobalScript(userID)
+	if err != nil {
+		return false, errors.Wrapf(err, "fail to get global script of user %s", userID)
+	}
+	if existing != nil && existing.Script == *script {
+		return true, nil
+	}
+	if existing != nil && existing.Script != "" && conflict != entity.ToolImportConflictOverwrite {
+		return false, nil
+	}
+	if err := s.globalScriptRepo.UpdateGlobalScript(userID, *script); err != nil {
+		return false, errors.Wrapf(err, "fail to import global script of user %s", userID)
+	}
+	return true, nil
+}
+
+// freeToolName numbers the name, "name (2)", "name (3)" and so on, until no tool of the namespace has it
+func freeToolName(userTools []entity.ToolEntity, namespace string, name string) string {
+	taken := map[string]bool{}
+	for _, tool := range userTools {
+		if tool.Namespace == namespace {
+			taken[tool.Name] = true
+		}
+	}
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s (%d)", name, i)
+	}
+	return candidate
+}
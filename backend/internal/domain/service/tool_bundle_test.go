@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/golang/mock/gomock"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestToolBundleService(ctrl *gomock.Controller, cfg config.Config, overrides ...entity.SystemSettingEntity) (*ToolService, *mockgen.MockIToolRepository, *mockgen.MockIGlobalScriptRepository, *mockgen.MockIUserSettingRepository) {
+	toolRepo := mockgen.NewMockIToolRepository(ctrl)
+	globalScriptRepo := mockgen.NewMockIGlobalScriptRepository(ctrl)
+	userSettingRepo := mockgen.NewMockIUserSettingRepository(ctrl)
+	clean := scannerFunc(func(context.Context, entity.ImportFileEntity) (entity.ContentScanResultEntity, error) {
+		return entity.ContentScanResultEntity{Clean: true}, nil
+	})
+	clock := fixtures.NewFakeClock(time.Unix(100, 0))
+	svc := NewToolService(
+		toolRepo,
+		globalScriptRepo,
+		newTestSystemSettingsService(ctrl, cfg, overrides...),
+		NewImportScanService(clean, config.Config{ImportMaxFileSize: 1024, ImportMaxFiles: 10}),
+		NewMeteringService(nil, toolRepo, nil, clock, cfg),
+		NewUserSettingsService(userSettingRepo, clock),
+		clock,
+		cfg,
+	)
+	return svc, toolRepo, globalScriptRepo, userSettingRepo
+}
+
+func TestToolService_ExportTools(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	userID := entity.UserIDEntity("user-1")
+	svc, toolRepo, globalScriptRepo, userSettingRepo := newTestToolBundleService(ctrl, config.Config{})
+
+	tools := []entity.ToolEntity{
+		fixtures.NewTestTool().WithID("tool-a").Build(),
+		fixtures.NewTestTool().WithID("tool-b").WithArchived(true).Build(),
+	}
+	toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: tools}, nil)
+	globalScriptRepo.EXPECT().GetGlobalScript(userID).Return(&entity.GlobalScriptEntity{Script: "const shared = 1"}, nil)
+	userSettingRepo.EXPECT().AllSettings(ctx, userID).Return([]entity.UserSettingEntity{
+		{Key: entity.UserSettingKeyTheme, Value: "dark"},
+		{Key: "removed.setting", Value: "1"},
+	}, nil)
+
+	bundle, err := svc.ExportTools(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, entity.ToolBundleFormatVersion, bundle.FormatVersion)
+	require.Equal(t, time.Unix(100, 0).UTC(), bundle.ExportedAt)
+	require.Equal(t, tools, bundle.Tools)
+	require.Equal(t, "const shared = 1", *bundle.GlobalScript)
+	require.Equal(t, map[string]string{entity.UserSettingKeyTheme: "dark"}, bundle.Settings)
+
+	// a user without a global script exports none
+	toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{}, nil)
+	globalScriptRepo.EXPECT().GetGlobalScript(userID).Return(nil, nil)
+	userSettingRepo.EXPECT().AllSettings(ctx, userID).Return(nil, nil)
+	bundle, err = svc.ExportTools(ctx, userID)
+	require.NoError(t, err)
+	require.Nil(t, bundle.GlobalScript)
+	require.Empty(t, bundle.Settings)
+}
+
+func TestToolService_ImportTools(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	userID := entity.UserIDEntity("user-1")
+	existing := fixtures.NewTestTool().WithUniqueID("tool-existing").WithID("format").WithNamespace("json").WithName("Format").
+		WithArchived(true).WithTimes(time.Unix(10, 0), time.Unix(10, 0)).Build()
+	other := fixtures.NewTestTool().WithUniqueID("tool-other").WithID("escape").WithNamespace("json").WithName("Escape").Build()
+	bundled := func(id, namespace, name string) entity.ToolEntity {
+		return fixtures.NewTestTool().WithUniqueID("").WithID(id).WithNamespace(namespace).WithName(name).
+			WithSource("async function handler() { return '" + name + "' }").WithUiWidgets("[]").Build()
+	}
+
+	tests := []struct {
+		name     string
+		conflict entity.ToolImportConflict
+		// want is the action and the saved name of each bundled tool
+		want          [][2]string
+		wantOverwrite bool
+	}{
+		{
+			name:     "skip keeps the existing tool",
+			conflict: "",
+			want:     [][2]string{{"skipped", "Format"}, {"created", "Upper"}},
+		},
+		{
+			name:          "overwrite replaces the existing tool",
+			conflict:      entity.ToolImportConflictOverwrite,
+			want:          [][2]string{{"overwritten", "Format"}, {"created", "Upper"}},
+			wantOverwrite: true,
+		},
+		{
+			name:     "rename imports next to the existing tool",
+			conflict: entity.ToolImportConflictRename,
+			want:     [][2]string{{"created", "Format (2)"}, {"created", "Upper"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			svc, toolRepo, globalScriptRepo, _ := newTestToolBundleService(ctrl, config.Config{})
+			toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{existing, other}}, nil).AnyTimes()
+			globalScriptRepo.EXPECT().GetGlobalScript(userID).Return(nil, nil)
+			globalScriptRepo.EXPECT().UpdateGlobalScript(userID, "const shared = 1").Return(nil)
+			if tt.wantOverwrite {
+				// the bundled tool is not archived, so the archived tool it overwrites is restored
+				toolRepo.EXPECT().SetToolArchived(userID, existing.UniqueID, false, gomock.Any()).Return(nil)
+			}
+
+			var saved []entity.ToolEntity
+			toolRepo.EXPECT().CreateTool(userID, gomock.Any(), gomock.Any()).DoAndReturn(func(_ entity.UserIDEntity, tool entity.ToolEntity, _ entity.ToolEventSourceEntity) error {
+				saved = append(saved, tool)
+				return nil
+			}).AnyTimes()
+			toolRepo.EXPECT().UpdateTool(userID, gomock.Any(), gomock.Any()).DoAndReturn(func(_ entity.UserIDEntity, tool entity.ToolEntity, _ entity.ToolEventSourceEntity) error {
+				saved = append(saved, tool)
+				return nil
+			}).AnyTimes()
+
+			format := bundled("format", "json", "Format")
+			// the tool id is taken by another tool of the user
+			upper := bundled("escape", "text", "Upper")
+			upper.ExtraInfo = map[string]string{"tags": "text", entity.ToolExtraInfoKeyForkedFrom: "tool-elsewhere"}
+			script := "const shared = 1"
+			result, err := svc.ImportTools(ctx, userID, entity.ToolBundleEntity{
+				FormatVersion: entity.ToolBundleFormatVersion,
+				GlobalScript:  &script,
+				Tools:         []entity.ToolEntity{format, upper},
+			}, tt.conflict)
+			require.NoError(t, err)
+			require.True(t, result.GlobalScriptImported)
+
+			got := lo.Map(result.Tools, func(tool entity.ToolImportResultEntity, _ int) [2]string {
+				return [2]string{string(tool.Action), tool.Name}
+			})
+			require.Equal(t, tt.want, got)
+
+			upperSaved, ok := lo.Find(saved, func(tool entity.ToolEntity) bool { return tool.Name == "Upper" })
+			require.True(t, ok)
+			require.Equal(t, "escape-2", upperSaved.ID)
+			require.NotEmpty(t, upperSaved.UniqueID)
+			require.Equal(t, map[string]string{"tags": "text"}, upperSaved.ExtraInfo)
+
+			if tt.wantOverwrite {
+				overwritten, ok := lo.Find(saved, func(tool entity.ToolEntity) bool { return tool.UniqueID == existing.UniqueID })
+				require.True(t, ok)
+				require.Equal(t, existing.ID, overwritten.ID)
+				require.Equal(t, format.Source, overwritten.Source)
+				require.Equal(t, existing.CreatedAt, overwritten.CreatedAt)
+			}
+			if tt.conflict == entity.ToolImportConflictRename {
+				renamed, ok := lo.Find(saved, func(tool entity.ToolEntity) bool { return tool.Name == "Format (2)" })
+				require.True(t, ok)
+				require.Equal(t, "format-2", renamed.ID)
+			}
+		})
+	}
+}
+
+func TestToolService_ImportTools_Skipped(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	ctx := context.Background()
+	userID := entity.UserIDEntity("user-1")
+
+	t.Run("a bundle of an unsupported version is rejected", func(t *testing.T) {
+		t.Parallel()
+		svc, _, _, _ := newTestToolBundleService(gomock.NewController(t), config.Config{})
+		for _, version := range []int{0, entity.ToolBundleFormatVersion + 1} {
+			_, err := svc.ImportTools(ctx, userID, entity.ToolBundleEntity{FormatVersion: version}, entity.ToolImportConflictSkip)
+			require.True(t, isErrorCode(err, error_code.InvalidToolBundle))
+		}
+	})
+
+	t.Run("invalid, blocked and over quota tools are skipped", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		svc, toolRepo, globalScriptRepo, _ := newTestToolBundleService(ctrl, config.Config{ToolSecretScanMode: ToolSecretScanModeBlock},
+			entity.SystemSettingEntity{Key: entity.SystemSettingKeyMaxToolsPerUser, Value: "1"})
+		existing := fixtures.NewTestTool().WithUniqueID("tool-existing").WithNamespace("json").WithName("Format").Build()
+		toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{existing}}, nil).AnyTimes()
+		globalScriptRepo.EXPECT().GetGlobalScript(userID).Return(&entity.GlobalScriptEntity{Script: "const mine = 1"}, nil)
+		globalScriptRepo.EXPECT().UpdateGlobalScript(userID, "const theirs = 1").Return(nil)
+		toolRepo.EXPECT().UpdateTool(userID, gomock.Any(), gomock.Any()).Return(nil)
+
+		tool := func(namespace, name string) entity.ToolEntity {
+			return fixtures.NewTestTool().WithUniqueID("").WithNamespace(namespace).WithName(name).WithSource("async function handler() {}").Build()
+		}
+		noName := tool("text", "")
+		badWidgets := tool("text", "Widgets")
+		badWidgets.UiWidgets = `{"id":"input"}`
+		badExtraInfo := tool("text", "Interval")
+		badExtraInfo.ExtraInfo = map[string]string{entity.ToolExtraInfoKeyExecInterval: "soon"}
+		leak := tool("text", "Leak")
+		leak.Source = "const token = 'ghp_" + strings.Repeat("a1B2", 9) + "'"
+		script := "const theirs = 1"
+
+		result, err := svc.ImportTools(ctx, userID, entity.ToolBundleEntity{
+			FormatVersion: entity.ToolBundleFormatVersion,
+			GlobalScript:  &script,
+			Tools:         []entity.ToolEntity{noName, badWidgets, badExtraInfo, leak, tool("text", "New"), tool("json", "Format")},
+		}, entity.ToolImportConflictOverwrite)
+		require.NoError(t, err)
+		require.True(t, result.GlobalScriptImported)
+
+		reasons := lo.Map(result.Tools, func(tool entity.ToolImportResultEntity, _ int) string { return tool.Reason })
+		require.Equal(t, []string{
+			"tool id and name are required",
+			"ui widgets are not a json array",
+			`extra_info "execInterval" must be a positive integer in milliseconds`,
+			"source contains credentials",
+			"tool quota exceeded",
+			// overwriting does not add a tool, the quota does not apply
+			"",
+		}, reasons)
+		require.NotEmpty(t, result.Tools[3].Warnings)
+		require.Equal(t, entity.ToolImportActionOverwritten, result.Tools[5].Action)
+		require.True(t, result.HasSavedTools())
+	})
+	t.Run("the global script of the user is kept unless overwriting", func(t *testing.T) {
+		t.Parallel()
+		svc, toolRepo, globalScriptRepo, _ := newTestToolBundleService(gomock.NewController(t), config.Config{})
+		toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{}, nil)
+		globalScriptRepo.EXPECT().GetGlobalScript(userID).Return(&entity.GlobalScriptEntity{Script: "const mine = 1"}, nil)
+
+		script := "const theirs = 1"
+		result, err := svc.ImportTools(ctx, userID, entity.ToolBundleEntity{FormatVersion: entity.ToolBundleFormatVersion, GlobalScript: &script}, entity.ToolImportConflictRename)
+		require.NoError(t, err)
+		require.False(t, result.GlobalScriptImported)
+		require.False(t, result.HasSavedTools())
+	})
+	t.Run("the preferences of the user are kept unless overwriting", func(t *testing.T) {
+		t.Parallel()
+		for _, conflict := range []entity.ToolImportConflict{entity.ToolImportConflictSkip, entity.ToolImportConflictOverwrite} {
+			svc, toolRepo, _, userSettingRepo := newTestToolBundleService(gomock.NewController(t), config.Config{})
+			toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{}, nil)
+			userSettingRepo.EXPECT().AllSettings(ctx, userID).Return([]entity.UserSettingEntity{{Key: entity.UserSettingKeyTheme, Value: "light"}}, nil)
+
+			want := []string{entity.UserSettingKeyEditorFontSize}
+			if conflict == entity.ToolImportConflictOverwrite {
+				want = []string{entity.UserSettingKeyEditorFontSize, entity.UserSettingKeyTheme}
+			}
+			userSettingRepo.EXPECT().SaveSettings(ctx, userID, gomock.Any()).DoAndReturn(func(_ context.Context, _ entity.UserIDEntity, settings []entity.UserSettingEntity) error {
+				require.Equal(t, want, lo.Map(settings, func(setting entity.UserSettingEntity, _ int) string { return setting.Key }))
+				return nil
+			})
+
+			result, err := svc.ImportTools(ctx, userID, entity.ToolBundleEntity{
+				FormatVersion: entity.ToolBundleFormatVersion,
+				// the unknown key and the invalid value come from another version of the instance
+				Settings: map[string]string{
+					entity.UserSettingKeyTheme:          "dark",
+					entity.UserSettingKeyEditorFontSize: "16",
+					entity.UserSettingKeyEditorTabSize:  "100",
+					"removed.setting":                   "1",
+				},
+			}, conflict)
+			require.NoError(t, err)
+			require.Equal(t, len(want), result.SettingsImported)
+		}
+	})
+}
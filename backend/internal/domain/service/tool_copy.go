@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"time"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+)
+
+// SaveCopiedTool saves a tool copied from another user, from the gallery or a share, as a new tool of the user.
+// The copy comes from outside the user's own tools, so its source and widgets pass the import scan before the checks
+// of SaveNewTool but the extra info one, the extra info was checked when the original was saved and the copy adds
+// reserved keys to it. It is renamed rather than refused when its name is taken, the user did not choose it.
+// It returns the saved tool and the credentials found in its source.
+func (s *ToolService) SaveCopiedTool(ctx context.Context, userID entity.UserIDEntity, tool entity.ToolEntity) (entity.ToolEntity, []entity.ToolSecretFindingEntity, error) {
+	// the widgets are scanned apart from the source, like the widgets of a bundle
+	if err := s.importScanService.ScanImportFiles(ctx, []entity.ImportFileEntity{
+		{Name: path.Join(tool.Namespace, tool.Name), Content: []byte(tool.Source)},
+	}); err != nil {
+		return entity.ToolEntity{}, nil, err
+	}
+	if tool.UiWidgets != "" {
+		if err := s.importScanService.ScanImportFiles(ctx, []entity.ImportFileEntity{
+			{Name: path.Join(tool.Namespace, tool.Name, toolBundleUiWidgetsFile), Content: []byte(tool.UiWidgets)},
+		}); err != nil {
+			return entity.ToolEntity{}, nil, err
+		}
+	}
+
+	name, err := s.AvailableToolName(ctx, userID, tool)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to find a free tool name for user %s: %v", userID, err)
+		return entity.ToolEntity{}, nil, error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "Unexpected create tool error")
+	}
+	tool.Name = name
+
+	return s.saveNewTool(ctx, userID, tool)
+}
+
+// copyTool returns a copy of a tool of another user to be saved as a new tool of the user, with a new unique id.
+// When the user already has the tool id, the id gets the suffix, then the suffix and a number, until it is free.
+// The extra info is added to the one of the original.
+func copyTool(original entity.ToolEntity, userTools []entity.ToolEntity, suffix string, extraInfo map[string]string, now time.Time) entity.ToolEntity {
+	usedIDs := make(map[string]bool, len(userTools))
+	for _, tool := range userTools {
+		usedIDs[tool.ID] = true
+	}
+	toolID := original.ID
+	if usedIDs[toolID] {
+		toolID = original.ID + suffix
+		for i := 2; toolID == original.ID || usedIDs[toolID]; i++ {
+			toolID = fmt.Sprintf("%s%s-%d", original.ID, suffix, i)
+		}
+	}
+
+	info := maps.Clone(original.ExtraInfo)
+	if info == nil {
+		info = map[string]string{}
+	}
+	maps.Copy(info, extraInfo)
+
+	return entity.NewToolEntityWithoutUID(
+		toolID,
+		original.Name,
+		original.Namespace,
+		original.Category,
+		original.IsActivate,
+		original.RealtimeExecution,
+		original.UiWidgets,
+		original.Source,
+		original.Description,
+		info,
+		now,
+		now,
+	)
+}
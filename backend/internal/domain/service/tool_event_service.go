@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"ya-tool-craft/internal/core/requestid"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	toolEventDefaultLimit = 50
+	toolEventMaxLimit     = 200
+)
+
+// ToolEventSourceFromContext returns the source of a tool change the actor makes in the request of ctx.
+func ToolEventSourceFromContext(ctx context.Context, actorID entity.UserIDEntity) entity.ToolEventSourceEntity {
+	return entity.ToolEventSourceEntity{
+		ActorID:   actorID,
+		DeviceID:  requestid.GetDeviceID(ctx),
+		RequestID: requestid.GetRequestID(ctx),
+	}
+}
+
+func NewToolEventService(toolEventRepo repository.IToolEventRepository) *ToolEventService {
+	return &ToolEventService{toolEventRepo: toolEventRepo}
+}
+
+// ToolEventService reads the event log of the tools of a user, the events are appended by the tool repository.
+type ToolEventService struct {
+	toolEventRepo repository.IToolEventRepository
+}
+
+// ListEvents returns a page of the events of the user newest first, an empty toolUID returns the events of every tool.
+// Events of deleted tools are kept, so toolUID does not have to be a tool the user still has.
+// The next page is read with the id of the last event of a page as before.
+func (s *ToolEventService) ListEvents(ctx context.Context, userID entity.UserIDEntity, toolUID string, before int64, limit int) ([]entity.ToolEventEntity, error) {
+	if limit <= 0 {
+		limit = toolEventDefaultLimit
+	}
+	limit = min(limit, toolEventMaxLimit)
+
+	events, err := s.toolEventRepo.ListEvents(ctx, userID, toolUID, before, limit)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to list tool events of user %s", userID)
+	}
+	return events, nil
+}
+
+// LatestEventID returns the id of the newest event of the user, 0 when there is none. An empty toolUID looks at the
+// events of every tool. Pass it to EventsAfter to read only the events that come after now.
+func (s *ToolEventService) LatestEventID(ctx context.Context, userID entity.UserIDEntity, toolUID string) (int64, error) {
+	events, err := s.toolEventRepo.ListEvents(ctx, userID, toolUID, 0, 1)
+	if err != nil {
+		return 0, errors.Wrapf(err, "fail to get the latest tool event of user %s", userID)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+	return events[0].ID, nil
+}
+
+// EventsAfter returns every event of the user with an id above after, oldest first, an empty toolUID returns the
+// events of every tool. The event log is read back from the newest event page by page until it reaches after, so a
+// client polling for new events gets all of them however many came in between.
+func (s *ToolEventService) EventsAfter(ctx context.Context, userID entity.UserIDEntity, toolUID string, after int64) ([]entity.ToolEventEntity, error) {
+	events := []entity.ToolEventEntity{}
+	var before int64
+	for {
+		page, err := s.toolEventRepo.ListEvents(ctx, userID, toolUID, before, toolEventMaxLimit)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fail to list tool events of user %s", userID)
+		}
+		for _, event := range page {
+			if event.ID <= after {
+				slices.Reverse(events)
+				return events, nil
+			}
+			events = append(events, event)
+		}
+		if len(page) < toolEventMaxLimit {
+			slices.Reverse(events)
+			return events, nil
+		}
+		before = page[len(page)-1].ID
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/domain/entity"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+)
+
+func TestToolEventService_ListEvents(t *testing.T) {
+	t.Parallel()
+
+	userID := entity.UserIDEntity("user-1")
+	tests := []struct {
+		name       string
+		limit      int
+		wantLimit  int
+		repoErr    error
+		wantErrSub string
+	}{
+		{name: "missing limit uses the default", limit: 0, wantLimit: toolEventDefaultLimit},
+		{name: "limit is kept", limit: 10, wantLimit: 10},
+		{name: "limit is capped", limit: 1000, wantLimit: toolEventMaxLimit},
+		{name: "repo error", limit: 10, wantLimit: 10, repoErr: errors.New("db down"), wantErrSub: "fail to list tool events"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			repo := mockgen.NewMockIToolEventRepository(ctrl)
+			events := []entity.ToolEventEntity{{ID: 7, UserID: userID, ToolUID: "tool-1", Type: entity.ToolEventTypeCreated}}
+			repo.EXPECT().ListEvents(gomock.Any(), userID, "tool-1", int64(9), tt.wantLimit).Return(events, tt.repoErr)
+
+			svc := NewToolEventService(repo)
+			got, err := svc.ListEvents(context.Background(), userID, "tool-1", 9, tt.limit)
+			if tt.wantErrSub != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, events, got)
+		})
+	}
+}
+
+func TestToolEventService_LatestEventID(t *testing.T) {
+	t.Parallel()
+
+	userID := entity.UserIDEntity("user-1")
+	tests := []struct {
+		name    string
+		events  []entity.ToolEventEntity
+		want    int64
+		repoErr error
+	}{
+		{name: "newest event", events: []entity.ToolEventEntity{{ID: 42}}, want: 42},
+		{name: "no events", events: nil, want: 0},
+		{name: "repo error", repoErr: errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			repo := mockgen.NewMockIToolEventRepository(ctrl)
+			repo.EXPECT().ListEvents(gomock.Any(), userID, "", int64(0), 1).Return(tt.events, tt.repoErr)
+
+			got, err := NewToolEventService(repo).LatestEventID(context.Background(), userID, "")
+			if tt.repoErr != nil {
+				require.ErrorContains(t, err, "fail to get the latest tool event")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestToolEventService_EventsAfter(t *testing.T) {
+	t.Parallel()
+
+	userID := entity.UserIDEntity("user-1")
+	// eventsDescending returns the events with the ids from down to to, newest first like the repository
+	eventsDescending := func(from, to int64) []entity.ToolEventEntity {
+		events := []entity.ToolEventEntity{}
+		for id := from; id >= to; id-- {
+			events = append(events, entity.ToolEventEntity{ID: id})
+		}
+		return events
+	}
+	ids := func(events []entity.ToolEventEntity) []int64 {
+		result := []int64{}
+		for _, event := range events {
+			result = append(result, event.ID)
+		}
+		return result
+	}
+
+	t.Run("stops at the known event and returns oldest first", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		repo := mockgen.NewMockIToolEventRepository(ctrl)
+		repo.EXPECT().ListEvents(gomock.Any(), userID, "tool-1", int64(0), toolEventMaxLimit).Return(eventsDescending(12, 8), nil)
+
+		got, err := NewToolEventService(repo).EventsAfter(context.Background(), userID, "tool-1", 10)
+		require.NoError(t, err)
+		require.Equal(t, []int64{11, 12}, ids(got))
+	})
+
+	t.Run("reads more pages until the known event", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		repo := mockgen.NewMockIToolEventRepository(ctrl)
+		newest := int64(toolEventMaxLimit + 5)
+		gomock.InOrder(
+			repo.EXPECT().ListEvents(gomock.Any(), userID, "", int64(0), toolEventMaxLimit).Return(eventsDescending(newest, 6), nil),
+			repo.EXPECT().ListEvents(gomock.Any(), userID, "", int64(6), toolEventMaxLimit).Return(eventsDescending(5, 1), nil),
+		)
+
+		got, err := NewToolEventService(repo).EventsAfter(context.Background(), userID, "", 3)
+		require.NoError(t, err)
+		require.Len(t, got, int(newest-3))
+		require.Equal(t, int64(4), got[0].ID)
+		require.Equal(t, newest, got[len(got)-1].ID)
+	})
+
+	t.Run("nothing new", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		repo := mockgen.NewMockIToolEventRepository(ctrl)
+		repo.EXPECT().ListEvents(gomock.Any(), userID, "", int64(0), toolEventMaxLimit).Return(eventsDescending(10, 1), nil)
+
+		got, err := NewToolEventService(repo).EventsAfter(context.Background(), userID, "", 10)
+		require.NoError(t, err)
+		require.Empty(t, got)
+	})
+
+	t.Run("repo error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		repo := mockgen.NewMockIToolEventRepository(ctrl)
+		repo.EXPECT().ListEvents(gomock.Any(), userID, "", int64(0), toolEventMaxLimit).Return(nil, errors.New("db down"))
+
+		_, err := NewToolEventService(repo).EventsAfter(context.Background(), userID, "", 10)
+		require.ErrorContains(t, err, "fail to list tool events")
+	})
+}
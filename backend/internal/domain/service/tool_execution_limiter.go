@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/metrics"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+)
+
+// toolExecutionRateWindow is the window of TOOL_EXECUTION_RATE_LIMIT
+const toolExecutionRateWindow = time.Minute
+
+func NewToolExecutionLimiter(clock client.IClock, cfg config.Config) *ToolExecutionLimiter {
+	return &ToolExecutionLimiter{
+		clock:   clock,
+		config:  cfg,
+		started: map[entity.UserIDEntity][]time.Time{},
+		running: map[entity.UserIDEntity]int{},
+	}
+}
+
+// ToolExecutionLimiter bounds the tool runs on the server of every user. A user gets TOOL_EXECUTION_RATE_LIMIT runs
+// per minute, and no more than TOOL_EXECUTION_MAX_CONCURRENT runs at a time.
+// The counts are kept in memory, every instance of the server limits the runs it serves.
+type ToolExecutionLimiter struct {
+	clock  client.IClock
+	config config.Config
+
+	mu        sync.Mutex
+	started   map[entity.UserIDEntity][]time.Time
+	running   map[entity.UserIDEntity]int
+	lastSweep time.Time
+}
+
+// Acquire counts a new run of the user, it fails when a limit is reached. Every successful Acquire must be followed
+// by a Release once the run ends. It returns the rate limit of the user after this run, zero when the rate limit is off.
+func (l *ToolExecutionLimiter) Acquire(ctx context.Context, userID entity.UserIDEntity) (entity.RateLimitEntity, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	since := now.Add(-toolExecutionRateWindow)
+	l.sweep(now, since)
+
+	var rateLimit entity.RateLimitEntity
+	if limit := l.config.ToolExecutionRateLimit; limit > 0 {
+		started := pruneIssuanceTimes(l.started[userID], since)
+		l.started[userID] = started
+		if len(started) >= limit {
+			metrics.ToolExecutionsTotal.WithLabelValues("rate_limited").Inc()
+			logger.Warnf(ctx, "Tool execution refused, user %s started %d runs within %s", userID, len(started), toolExecutionRateWindow)
+			return entity.RateLimitEntity{}, error_code.NewErrorWithErrorCodeFAppendExtraData(
+				error_code.ToolExecutionRateLimited,
+				windowRateLimit(limit, started, toolExecutionRateWindow),
+				"at most %d tool runs per %s", limit, toolExecutionRateWindow,
+			)
+		}
+	}
+	if limit := l.config.ToolExecutionMaxConcurrent; l.running[userID] >= limit {
+		metrics.ToolExecutionsTotal.WithLabelValues("busy").Inc()
+		return entity.RateLimitEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolExecutionBusy, "%d tool runs are in progress", l.running[userID])
+	}
+
+	if limit := l.config.ToolExecutionRateLimit; limit > 0 {
+		l.started[userID] = append(l.started[userID], now)
+		rateLimit = windowRateLimit(limit, l.started[userID], toolExecutionRateWindow)
+	}
+	l.running[userID]++
+	return rateLimit, nil
+}
+
+// Release ends a run counted by Acquire.
+func (l *ToolExecutionLimiter) Release(userID entity.UserIDEntity) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running[userID] <= 1 {
+		delete(l.running, userID)
+		return
+	}
+	l.running[userID]--
+}
+
+// sweep forgets the users without a run started within the window, the caller holds mu
+func (l *ToolExecutionLimiter) sweep(now time.Time, since time.Time) {
+	if now.Sub(l.lastSweep) < toolExecutionRateWindow {
+		return
+	}
+	l.lastSweep = now
+	for userID, started := range l.started {
+		if started = pruneIssuanceTimes(started, since); len(started) == 0 {
+			delete(l.started, userID)
+		} else {
+			l.started[userID] = started
+		}
+	}
+}
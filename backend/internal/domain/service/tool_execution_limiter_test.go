@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+func TestToolExecutionLimiter(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	type step struct {
+		advance time.Duration
+		user    entity.UserIDEntity
+		// release ends a run of user instead of starting one
+		release     bool
+		wantErrCode *error_code.ErrorCode
+	}
+
+	tests := []struct {
+		name  string
+		cfg   config.Config
+		steps []step
+	}{
+		{
+			name: "a user is limited within the minute",
+			cfg:  config.Config{ToolExecutionRateLimit: 2, ToolExecutionMaxConcurrent: 10},
+			steps: []step{
+				{user: "1"},
+				{user: "1"},
+				{user: "1", wantErrCode: &error_code.ToolExecutionRateLimited},
+				{user: "2"},
+				{advance: 61 * time.Second, user: "1"},
+			},
+		},
+		{
+			name: "concurrent runs of a user are capped until they end",
+			cfg:  config.Config{ToolExecutionMaxConcurrent: 2},
+			steps: []step{
+				{user: "1"},
+				{user: "1"},
+				{user: "1", wantErrCode: &error_code.ToolExecutionBusy},
+				{user: "2"},
+				{user: "1", release: true},
+				{user: "1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := fixtures.NewFakeClock(time.Date(2026, 1, 14, 3, 0, 0, 0, time.UTC))
+			limiter := NewToolExecutionLimiter(clock, tt.cfg)
+			for i, step := range tt.steps {
+				clock.Advance(step.advance)
+				if step.release {
+					limiter.Release(step.user)
+					continue
+				}
+				_, err := limiter.Acquire(context.Background(), step.user)
+				if step.wantErrCode == nil {
+					require.NoError(t, err, "step %d", i)
+					continue
+				}
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr, "step %d", i)
+				require.Equal(t, step.wantErrCode.Code, codeErr.ErrorCode.Code, "step %d", i)
+			}
+		})
+	}
+}
+
+func TestToolExecutionLimiter_RateLimit(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	start := time.Date(2026, 1, 14, 3, 0, 0, 0, time.UTC)
+	clock := fixtures.NewFakeClock(start)
+	limiter := NewToolExecutionLimiter(clock, config.Config{ToolExecutionRateLimit: 2, ToolExecutionMaxConcurrent: 5})
+
+	rateLimit, err := limiter.Acquire(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, entity.RateLimitEntity{Limit: 2, Remaining: 1, ResetAt: start.Add(time.Minute)}, rateLimit)
+	limiter.Release("1")
+
+	clock.Advance(10 * time.Second)
+	_, err = limiter.Acquire(context.Background(), "1")
+	require.NoError(t, err)
+	limiter.Release("1")
+
+	// the refusal tells when the oldest run leaves the window
+	_, err = limiter.Acquire(context.Background(), "1")
+	var codeErr error_code.ErrorWithErrorCode
+	require.ErrorAs(t, err, &codeErr)
+	require.Equal(t, entity.RateLimitEntity{Limit: 2, Remaining: 0, ResetAt: start.Add(time.Minute)}, codeErr.ExtraData)
+	require.Empty(t, limiter.running)
+
+	// without a rate limit there is nothing to report
+	rateLimit, err = NewToolExecutionLimiter(clock, config.Config{ToolExecutionMaxConcurrent: 1}).Acquire(context.Background(), "1")
+	require.NoError(t, err)
+	require.Zero(t, rateLimit)
+}
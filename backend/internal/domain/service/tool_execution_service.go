@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/metrics"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+)
+
+func NewToolExecutionService(
+	toolRepo repository.IToolRepository,
+	toolSecretService *ToolSecretService,
+	meteringService *MeteringService,
+	limiter *ToolExecutionLimiter,
+	runner client.IToolRunner,
+	cfg config.Config,
+) *ToolExecutionService {
+	return &ToolExecutionService{
+		toolRepo:          toolRepo,
+		toolSecretService: toolSecretService,
+		meteringService:   meteringService,
+		limiter:           limiter,
+		runner:            runner,
+		config:            cfg,
+	}
+}
+
+// ToolExecutionService runs the handler of a stored tool on the server, so tools can be used without a browser.
+// It is off unless TOOL_EXECUTION_ENABLED is set. Runs are limited per user and metered as tool_executions by the
+// weight of their resource class, the secrets of the tool are injected like in the browser.
+type ToolExecutionService struct {
+	toolRepo          repository.IToolRepository
+	toolSecretService *ToolSecretService
+	meteringService   *MeteringService
+	limiter           *ToolExecutionLimiter
+	runner            client.IToolRunner
+	config            config.Config
+}
+
+// ExecuteTool runs the tool of the user with the given inputs. It returns the rate limit of the user after this run,
+// zero when the rate limit is off.
+func (s *ToolExecutionService) ExecuteTool(ctx context.Context, userID entity.UserIDEntity, toolUID string, request entity.ToolExecutionRequestEntity) (entity.ToolExecutionResultEntity, entity.RateLimitEntity, error) {
+	if !s.config.ToolExecutionEnabled {
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolExecutionDisabled, "set TOOL_EXECUTION_ENABLED to run tools on the server")
+	}
+	tool, exists, err := s.toolRepo.GetTool(userID, toolUID)
+	if err != nil {
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, errors.Wrapf(err, "fail to get tool %s of user %s", toolUID, userID)
+	}
+	if !exists {
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "tool %s not found", toolUID)
+	}
+
+	return s.runTool(ctx, userID, tool, request)
+}
+
+// runTool runs a tool of the user the caller looked up.
+func (s *ToolExecutionService) runTool(ctx context.Context, userID entity.UserIDEntity, tool entity.ToolEntity, request entity.ToolExecutionRequestEntity) (entity.ToolExecutionResultEntity, entity.RateLimitEntity, error) {
+	toolUID := tool.UniqueID
+	class, err := entity.ParseToolResourceClass(string(request.ResourceClass))
+	if err != nil {
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, error_code.NewErrorWithErrorCode(error_code.InvalidRequestParameters, err.Error())
+	}
+	inputs, err := json.Marshal(request.Inputs)
+	if err != nil {
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "inputs are not valid JSON: %v", err)
+	}
+	if len(inputs) > s.config.ToolExecutionMaxPayload {
+		metrics.ToolExecutionsTotal.WithLabelValues("too_large").Inc()
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolExecutionTooLarge, "the inputs exceed %d bytes", s.config.ToolExecutionMaxPayload)
+	}
+
+	rateLimit, err := s.limiter.Acquire(ctx, userID)
+	if err != nil {
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, err
+	}
+	defer s.limiter.Release(userID)
+
+	if err := s.meteringService.RecordToolExecution(ctx, userID, class); err != nil {
+		return entity.ToolExecutionResultEntity{}, rateLimit, err
+	}
+	secrets, err := s.toolSecretService.ResolveSecrets(ctx, userID, toolUID)
+	if err != nil {
+		return entity.ToolExecutionResultEntity{}, rateLimit, err
+	}
+
+	result, err := s.runner.Run(ctx, tool.Source, secrets, request, s.toolExecutionLimits(class))
+	metrics.ToolExecutionsTotal.WithLabelValues(toolExecutionResult(err)).Inc()
+	if err != nil {
+		logger.Infof(ctx, "Tool %s of user %s failed on the server after %s: %v", toolUID, userID, result.Duration, err)
+		return entity.ToolExecutionResultEntity{}, rateLimit, err
+	}
+	return result, rateLimit, nil
+}
+
+// toolExecutionLimits maps the resource class of a run onto the limits of the runner
+func (s *ToolExecutionService) toolExecutionLimits(class entity.ToolResourceClass) entity.ToolExecutionLimitsEntity {
+	limits := entity.ToolExecutionLimitsEntity{
+		Timeout:         time.Duration(s.config.ToolExecutionTimeout) * time.Second,
+		MaxPayloadBytes: s.config.ToolExecutionMaxPayload,
+		MaxMemoryBytes:  s.config.ToolExecutionMaxMemory,
+	}
+	switch class {
+	case entity.ToolResourceClassMedium:
+		limits.Timeout = time.Duration(s.config.ToolExecutionMediumTimeout) * time.Second
+		limits.MaxMemoryBytes = s.config.ToolExecutionMediumMaxMemory
+	case entity.ToolResourceClassLarge:
+		limits.Timeout = time.Duration(s.config.ToolExecutionLargeTimeout) * time.Second
+		limits.MaxMemoryBytes = s.config.ToolExecutionLargeMaxMemory
+	}
+	return limits
+}
+
+// toolExecutionResult is the metric label of a finished run
+func toolExecutionResult(err error) string {
+	var codeErr error_code.ErrorWithErrorCode
+	switch {
+	case err == nil:
+		return "success"
+	case errors.As(err, &codeErr) && codeErr.ErrorCode.Code == error_code.ToolExecutionTimedOut.Code:
+		return "timed_out"
+	case errors.As(err, &codeErr) && codeErr.ErrorCode.Code == error_code.ToolExecutionTooLarge.Code:
+		return "too_large"
+	case errors.As(err, &codeErr) && codeErr.ErrorCode.Code == error_code.ToolExecutionOutOfMemory.Code:
+		return "out_of_memory"
+	default:
+		return "failed"
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+// toolRunnerFunc adapts a function to IToolRunner.
+type toolRunnerFunc func(ctx context.Context, source string, secrets map[string]string, request entity.ToolExecutionRequestEntity, limits entity.ToolExecutionLimitsEntity) (entity.ToolExecutionResultEntity, error)
+
+func (f toolRunnerFunc) Run(ctx context.Context, source string, secrets map[string]string, request entity.ToolExecutionRequestEntity, limits entity.ToolExecutionLimitsEntity) (entity.ToolExecutionResultEntity, error) {
+	return f(ctx, source, secrets, request, limits)
+}
+
+func TestToolExecutionService_ExecuteTool(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	enabled := config.Config{ToolExecutionEnabled: true, ToolExecutionTimeout: 5, ToolExecutionMaxPayload: 64, ToolExecutionMaxConcurrent: 1}
+	failed := error_code.NewErrorWithErrorCodef(error_code.ToolExecutionFailed, "boom")
+
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		toolUID     string
+		inputs      map[string]any
+		runErr      error
+		wantRun     bool
+		wantErrCode *error_code.ErrorCode
+	}{
+		{name: "runs the tool with its secrets", cfg: enabled, toolUID: "tool-1", inputs: map[string]any{"text": "hi"}, wantRun: true},
+		{name: "disabled deployment", cfg: config.Config{}, toolUID: "tool-1", wantErrCode: &error_code.ToolExecutionDisabled},
+		{name: "unknown tool", cfg: enabled, toolUID: "tool-2", wantErrCode: &error_code.ToolNotFound},
+		{name: "too large inputs", cfg: enabled, toolUID: "tool-1", inputs: map[string]any{"text": strings.Repeat("x", 64)}, wantErrCode: &error_code.ToolExecutionTooLarge},
+		{name: "failing handler", cfg: enabled, toolUID: "tool-1", runErr: failed, wantRun: true, wantErrCode: &error_code.ToolExecutionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			secretService, secretRepo, user := newToolSecretServiceForTest(t)
+			secretRepo.EXPECT().ListSecrets(gomock.Any(), user.ID, "").Return([]entity.ToolSecretEntity{sealedToolSecret(t, "", "API_KEY", "account")}, nil).AnyTimes()
+			secretRepo.EXPECT().ListSecrets(gomock.Any(), user.ID, "tool-1").Return(nil, nil).AnyTimes()
+
+			// the tool newToolSecretServiceForTest stores
+			tool := fixtures.NewTestTool().WithUniqueID("tool-1").Build()
+			ran := false
+			runner := toolRunnerFunc(func(_ context.Context, source string, secrets map[string]string, request entity.ToolExecutionRequestEntity, limits entity.ToolExecutionLimitsEntity) (entity.ToolExecutionResultEntity, error) {
+				ran = true
+				require.Equal(t, tool.Source, source)
+				require.Equal(t, map[string]string{"API_KEY": "account"}, secrets)
+				require.Equal(t, tt.inputs, request.Inputs)
+				require.Equal(t, entity.ToolExecutionLimitsEntity{Timeout: 5 * time.Second, MaxPayloadBytes: 64}, limits)
+				return entity.ToolExecutionResultEntity{Outputs: map[string]any{"ok": true}}, tt.runErr
+			})
+			clock := fixtures.NewFakeClock(time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC))
+			svc := NewToolExecutionService(
+				secretService.toolRepo,
+				secretService,
+				NewMeteringService(nil, nil, nil, clock, config.Config{}),
+				NewToolExecutionLimiter(clock, tt.cfg),
+				runner,
+				tt.cfg,
+			)
+
+			result, _, err := svc.ExecuteTool(context.Background(), user.ID, tt.toolUID, entity.ToolExecutionRequestEntity{Inputs: tt.inputs})
+			require.Equal(t, tt.wantRun, ran)
+			if tt.wantErrCode != nil {
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, tt.wantErrCode.Code, codeErr.ErrorCode.Code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, map[string]any{"ok": true}, result.Outputs)
+
+			// the run ended, the only concurrent slot is free again
+			_, _, err = svc.ExecuteTool(context.Background(), user.ID, tt.toolUID, entity.ToolExecutionRequestEntity{Inputs: tt.inputs})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestToolExecutionService_ResourceClass(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	cfg := config.Config{
+		ToolExecutionEnabled:         true,
+		ToolExecutionTimeout:         5,
+		ToolExecutionMaxPayload:      64,
+		ToolExecutionMaxMemory:       1 << 20,
+		ToolExecutionMediumTimeout:   30,
+		ToolExecutionMediumMaxMemory: 4 << 20,
+		ToolExecutionLargeTimeout:    120,
+		ToolExecutionLargeMaxMemory:  16 << 20,
+		ToolExecutionMaxConcurrent:   1,
+	}
+
+	tests := []struct {
+		name        string
+		class       entity.ToolResourceClass
+		wantLimits  entity.ToolExecutionLimitsEntity
+		wantErrCode *error_code.ErrorCode
+	}{
+		{name: "no class runs as small", wantLimits: entity.ToolExecutionLimitsEntity{Timeout: 5 * time.Second, MaxPayloadBytes: 64, MaxMemoryBytes: 1 << 20}},
+		{name: "small", class: entity.ToolResourceClassSmall, wantLimits: entity.ToolExecutionLimitsEntity{Timeout: 5 * time.Second, MaxPayloadBytes: 64, MaxMemoryBytes: 1 << 20}},
+		{name: "medium", class: entity.ToolResourceClassMedium, wantLimits: entity.ToolExecutionLimitsEntity{Timeout: 30 * time.Second, MaxPayloadBytes: 64, MaxMemoryBytes: 4 << 20}},
+		{name: "large", class: entity.ToolResourceClassLarge, wantLimits: entity.ToolExecutionLimitsEntity{Timeout: 120 * time.Second, MaxPayloadBytes: 64, MaxMemoryBytes: 16 << 20}},
+		{name: "unknown class", class: "huge", wantErrCode: &error_code.InvalidRequestParameters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			secretService, secretRepo, user := newToolSecretServiceForTest(t)
+			secretRepo.EXPECT().ListSecrets(gomock.Any(), user.ID, gomock.Any()).Return(nil, nil).AnyTimes()
+			var gotLimits *entity.ToolExecutionLimitsEntity
+			runner := toolRunnerFunc(func(_ context.Context, _ string, _ map[string]string, _ entity.ToolExecutionRequestEntity, limits entity.ToolExecutionLimitsEntity) (entity.ToolExecutionResultEntity, error) {
+				gotLimits = &limits
+				return entity.ToolExecutionResultEntity{}, nil
+			})
+			clock := fixtures.NewFakeClock(time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC))
+			svc := NewToolExecutionService(
+				secretService.toolRepo,
+				secretService,
+				NewMeteringService(nil, nil, nil, clock, config.Config{}),
+				NewToolExecutionLimiter(clock, cfg),
+				runner,
+				cfg,
+			)
+
+			_, _, err := svc.ExecuteTool(context.Background(), user.ID, "tool-1", entity.ToolExecutionRequestEntity{ResourceClass: tt.class})
+			if tt.wantErrCode != nil {
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, tt.wantErrCode.Code, codeErr.ErrorCode.Code)
+				require.Nil(t, gotLimits)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, &tt.wantLimits, gotLimits)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// InvocableTools returns the active tools of the user with their schemas, ordered by namespace and name, to describe them
+// in the tool invocation spec. Tools sharing a namespace and name with another active tool, and tools whose schema can not
+// be resolved, can not be invoked and are left out.
+func (s *ToolService) InvocableTools(ctx context.Context, userID entity.UserIDEntity) ([]entity.InvocableToolEntity, error) {
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+
+	active := lo.Filter(tools.Tools, func(tool entity.ToolEntity, _ int) bool { return isInvocableTool(tool) })
+	counts := lo.CountValuesBy(active, invocableToolKey)
+	invocable := make([]entity.InvocableToolEntity, 0, len(active))
+	for _, tool := range active {
+		if counts[invocableToolKey(tool)] > 1 {
+			continue
+		}
+		schema, err := resolveToolSchema(tool)
+		if err != nil {
+			logger.Infof(ctx, "Leave tool %s of user %s out of the invocation spec: %v", tool.UniqueID, userID, err)
+			continue
+		}
+		invocable = append(invocable, entity.InvocableToolEntity{Tool: tool, Schema: schema})
+	}
+
+	slices.SortFunc(invocable, func(a, b entity.InvocableToolEntity) int {
+		return cmp.Or(cmp.Compare(a.Tool.Namespace, b.Tool.Namespace), cmp.Compare(a.Tool.Name, b.Tool.Name))
+	})
+	return invocable, nil
+}
+
+// InvokeTool runs the active tool of the user with the namespace and name on the server. The inputs are checked against
+// the input schema of the tool first, a mismatch fails with ToolInputInvalid and lists the problems in the extra data.
+func (s *ToolExecutionService) InvokeTool(ctx context.Context, userID entity.UserIDEntity, namespace, name string, request entity.ToolExecutionRequestEntity) (entity.ToolExecutionResultEntity, entity.RateLimitEntity, error) {
+	if !s.config.ToolExecutionEnabled {
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolExecutionDisabled, "set TOOL_EXECUTION_ENABLED to run tools on the server")
+	}
+
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	matches := lo.Filter(tools.Tools, func(tool entity.ToolEntity, _ int) bool {
+		return isInvocableTool(tool) && tool.Namespace == namespace && tool.Name == name
+	})
+	switch len(matches) {
+	case 0:
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "no active tool %q in namespace %q", name, namespace)
+	case 1:
+	default:
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolNameAmbiguous, "%d active tools are named %q in namespace %q", len(matches), name, namespace)
+	}
+	tool := matches[0]
+
+	schema, err := resolveToolSchema(tool)
+	if err != nil {
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, err
+	}
+	problems := []string{}
+	validateToolSchemaValue(schema.Input, lo.Ternary(request.Inputs == nil, map[string]any{}, request.Inputs), "$", &problems)
+	if len(problems) > 0 {
+		return entity.ToolExecutionResultEntity{}, entity.RateLimitEntity{}, error_code.NewErrorWithErrorCodeFAppendExtraData(
+			error_code.ToolInputInvalid,
+			map[string]any{"problems": problems},
+			"%d problems in the inputs of tool %q", len(problems), name,
+		)
+	}
+
+	return s.runTool(ctx, userID, tool, request)
+}
+
+// isInvocableTool tells whether the tool is active, archived and deactivated tools are not invoked by name
+func isInvocableTool(tool entity.ToolEntity) bool {
+	return tool.IsActivate && !tool.IsArchived
+}
+
+func invocableToolKey(tool entity.ToolEntity) [2]string {
+	return [2]string{tool.Namespace, tool.Name}
+}
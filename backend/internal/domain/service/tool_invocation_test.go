@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+const invocationTestWidgets = `[{"id": "text", "type": "TextInput"}, {"id": "echo", "type": "TextInput", "mode": "output"}]`
+
+func invocationTestTools() []entity.ToolEntity {
+	return []entity.ToolEntity{
+		fixtures.NewTestTool().WithUniqueID("tool-echo").WithNamespace("utils").WithName("Echo").WithUiWidgets(invocationTestWidgets).Build(),
+		fixtures.NewTestTool().WithUniqueID("tool-archived").WithNamespace("utils").WithName("Old").WithArchived(true).Build(),
+		fixtures.NewTestTool().WithUniqueID("tool-inactive").WithNamespace("utils").WithName("Off").WithActivate(false).Build(),
+		fixtures.NewTestTool().WithUniqueID("tool-dup-1").WithNamespace("dup").WithName("Twin").Build(),
+		fixtures.NewTestTool().WithUniqueID("tool-dup-2").WithNamespace("dup").WithName("Twin").Build(),
+		fixtures.NewTestTool().WithUniqueID("tool-bad").WithNamespace("a").WithName("Bad").WithExtraInfo(map[string]string{entity.ToolExtraInfoKeyInputSchema: "not json"}).Build(),
+		fixtures.NewTestTool().WithUniqueID("tool-first").WithNamespace("a").WithName("First").WithUiWidgets("[]").Build(),
+	}
+}
+
+func TestToolService_InvocableTools(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	ctrl := gomock.NewController(t)
+	toolRepo := mockgen.NewMockIToolRepository(ctrl)
+	toolRepo.EXPECT().AllTools(entity.UserIDEntity("user-1")).Return(entity.ToolsEntity{Tools: invocationTestTools()}, nil)
+	svc := NewToolService(toolRepo, nil, nil, nil, nil, nil, nil, config.Config{})
+
+	invocable, err := svc.InvocableTools(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, invocable, 2)
+	require.Equal(t, "tool-first", invocable[0].Tool.UniqueID)
+	require.Equal(t, "tool-echo", invocable[1].Tool.UniqueID)
+	require.Contains(t, invocable[1].Schema.Input["properties"], "text")
+	require.Contains(t, invocable[1].Schema.Output["properties"], "echo")
+}
+
+func TestToolExecutionService_InvokeTool(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	enabled := config.Config{ToolExecutionEnabled: true, ToolExecutionTimeout: 5, ToolExecutionMaxPayload: 1024, ToolExecutionMaxConcurrent: 1}
+
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		namespace   string
+		toolName    string
+		inputs      map[string]any
+		wantErrCode *error_code.ErrorCode
+	}{
+		{name: "runs the tool by namespace and name", cfg: enabled, namespace: "utils", toolName: "Echo", inputs: map[string]any{"text": "hi"}},
+		{name: "disabled deployment", cfg: config.Config{}, namespace: "utils", toolName: "Echo", inputs: map[string]any{"text": "hi"}, wantErrCode: &error_code.ToolExecutionDisabled},
+		{name: "inputs not matching the schema", cfg: enabled, namespace: "utils", toolName: "Echo", inputs: map[string]any{"text": 1}, wantErrCode: &error_code.ToolInputInvalid},
+		{name: "archived tool", cfg: enabled, namespace: "utils", toolName: "Old", wantErrCode: &error_code.ToolNotFound},
+		{name: "inactive tool", cfg: enabled, namespace: "utils", toolName: "Off", wantErrCode: &error_code.ToolNotFound},
+		{name: "name of another namespace", cfg: enabled, namespace: "dup", toolName: "Echo", wantErrCode: &error_code.ToolNotFound},
+		{name: "several tools with the name", cfg: enabled, namespace: "dup", toolName: "Twin", wantErrCode: &error_code.ToolNameAmbiguous},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			toolRepo := mockgen.NewMockIToolRepository(ctrl)
+			toolRepo.EXPECT().AllTools(entity.UserIDEntity("user-1")).Return(entity.ToolsEntity{Tools: invocationTestTools()}, nil).AnyTimes()
+			toolRepo.EXPECT().GetTool(entity.UserIDEntity("user-1"), gomock.Any()).DoAndReturn(func(_ entity.UserIDEntity, toolUID string) (entity.ToolEntity, bool, error) {
+				tool, ok := lo.Find(invocationTestTools(), func(tool entity.ToolEntity) bool { return tool.UniqueID == toolUID })
+				return tool, ok, nil
+			}).AnyTimes()
+			secretRepo := mockgen.NewMockIToolSecretRepository(ctrl)
+			secretRepo.EXPECT().ListSecrets(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+			userRepo := mockgen.NewMockIUserRepository(ctrl)
+			userRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(fixtures.NewTestUser().WithID("user-1").Build(), true, nil).AnyTimes()
+
+			var ran string
+			runner := toolRunnerFunc(func(_ context.Context, source string, _ map[string]string, request entity.ToolExecutionRequestEntity, _ entity.ToolExecutionLimitsEntity) (entity.ToolExecutionResultEntity, error) {
+				ran = source
+				return entity.ToolExecutionResultEntity{Outputs: map[string]any{"echo": request.Inputs["text"]}}, nil
+			})
+			clock := fixtures.NewFakeClock(time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC))
+			svc := NewToolExecutionService(
+				toolRepo,
+				NewToolSecretService(secretRepo, toolRepo, userRepo),
+				NewMeteringService(nil, nil, nil, clock, config.Config{}),
+				NewToolExecutionLimiter(clock, tt.cfg),
+				runner,
+				tt.cfg,
+			)
+
+			result, _, err := svc.InvokeTool(context.Background(), "user-1", tt.namespace, tt.toolName, entity.ToolExecutionRequestEntity{Inputs: tt.inputs})
+			if tt.wantErrCode != nil {
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, tt.wantErrCode.Code, codeErr.ErrorCode.Code)
+				require.Empty(t, ran)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "source code here", ran)
+			require.Equal(t, map[string]any{"echo": "hi"}, result.Outputs)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// toolMergeMaxDiffCells caps the line pairs compared when diffing a source, above it the changed
+// range (after the common leading and trailing lines) is treated as one block.
+const toolMergeMaxDiffCells = 4_000_000
+
+type toolMergeField struct {
+	name string
+	get  func(entity.ToolEntity) string
+	set  func(*entity.ToolEntity, string)
+}
+
+var toolMergeFields = []toolMergeField{
+	{entity.ToolMergeFieldID, func(t entity.ToolEntity) string { return t.ID }, func(t *entity.ToolEntity, v string) { t.ID = v }},
+	{entity.ToolMergeFieldName, func(t entity.ToolEntity) string { return t.Name }, func(t *entity.ToolEntity, v string) { t.Name = v }},
+	{entity.ToolMergeFieldNamespace, func(t entity.ToolEntity) string { return t.Namespace }, func(t *entity.ToolEntity, v string) { t.Namespace = v }},
+	{entity.ToolMergeFieldCategory, func(t entity.ToolEntity) string { return t.Category }, func(t *entity.ToolEntity, v string) { t.Category = v }},
+	{entity.ToolMergeFieldDescription, func(t entity.ToolEntity) string { return t.Description }, func(t *entity.ToolEntity, v string) { t.Description = v }},
+	{
+		entity.ToolMergeFieldIsActivate,
+		func(t entity.ToolEntity) string { return strconv.FormatBool(t.IsActivate) },
+		func(t *entity.ToolEntity, v string) { t.IsActivate = v == "true" },
+	},
+	{
+		entity.ToolMergeFieldRealtimeExecution,
+		func(t entity.ToolEntity) string { return strconv.FormatBool(t.RealtimeExecution) },
+		func(t *entity.ToolEntity, v string) { t.RealtimeExecution = v == "true" },
+	},
+	{entity.ToolMergeFieldUiWidgets, func(t entity.ToolEntity) string { return t.UiWidgets }, func(t *entity.ToolEntity, v string) { t.UiWidgets = v }},
+	{entity.ToolMergeFieldSource, func(t entity.ToolEntity) string { return t.Source }, func(t *entity.ToolEntity, v string) { t.Source = v }},
+}
+
+// MergeTool three-way merges the edited version of a tool into the server version, base is the version the edit started from.
+// A field changed by one side only takes that side's value, the source is merged line by line so edits to different
+// lines are combined. The server version is not modified, saving the merged tool is up to the caller.
+func (s *ToolService) MergeTool(ctx context.Context, userID entity.UserIDEntity, toolUID string, base, edited entity.ToolEntity) (entity.ToolMergeEntity, error) {
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return entity.ToolMergeEntity{}, errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	server, ok := lo.Find(tools.Tools, func(tool entity.ToolEntity) bool { return tool.UniqueID == toolUID })
+	if !ok {
+		return entity.ToolMergeEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "tool %s not found", toolUID)
+	}
+
+	return mergeTool(base, edited, server), nil
+}
+
+func mergeTool(base, local, server entity.ToolEntity) entity.ToolMergeEntity {
+	result := entity.ToolMergeEntity{Server: server, Fields: []entity.ToolMergeFieldEntity{}, SourceConflicts: []entity.ToolSourceConflictEntity{}}
+	merged := server
+	merged.ExtraInfo = map[string]string{}
+
+	for _, field := range toolMergeFields {
+		baseValue, localValue, serverValue := field.get(base), field.get(local), field.get(server)
+		diff, value, changed := mergeToolValue(field.name, &baseValue, &localValue, &serverValue)
+		if field.name == entity.ToolMergeFieldSource && diff.Status == entity.ToolMergeStatusConflict {
+			source, conflicts := mergeSource(baseValue, localValue, serverValue)
+			if len(conflicts) == 0 {
+				diff.Status = entity.ToolMergeStatusMerged
+				value = &source
+			}
+			result.SourceConflicts = conflicts
+		}
+		field.set(&merged, *value)
+		if changed {
+			result.Fields = append(result.Fields, diff)
+		}
+	}
+
+	keys := lo.Uniq(slices.Concat(lo.Keys(base.ExtraInfo), lo.Keys(local.ExtraInfo), lo.Keys(server.ExtraInfo)))
+	slices.Sort(keys)
+	for _, key := range keys {
+		diff, value, changed := mergeToolValue(
+			entity.ToolMergeFieldExtraInfoPrefix+key,
+			extraInfoValue(base.ExtraInfo, key),
+			extraInfoValue(local.ExtraInfo, key),
+			extraInfoValue(server.ExtraInfo, key),
+		)
+		if value != nil {
+			merged.ExtraInfo[key] = *value
+		}
+		if changed {
+			result.Fields = append(result.Fields, diff)
+		}
+	}
+
+	if !result.HasConflicts() {
+		result.Merged = &merged
+	}
+	return result
+}
+
+// mergeToolValue compares a field of both sides with the base, it returns the diff, the merged value
+// and whether any side changed the field. A conflicting field keeps the server value.
+func mergeToolValue(name string, base, local, server *string) (entity.ToolMergeFieldEntity, *string, bool) {
+	diff := entity.ToolMergeFieldEntity{Field: name, Base: base, Local: local, Server: server}
+	localChanged, serverChanged := !equalValue(base, local), !equalValue(base, server)
+
+	switch {
+	case !localChanged && !serverChanged:
+		return diff, server, false
+	case localChanged && !serverChanged:
+		diff.Status = entity.ToolMergeStatusLocal
+		return diff, local, true
+	case !localChanged && serverChanged:
+		diff.Status = entity.ToolMergeStatusServer
+		return diff, server, true
+	case equalValue(local, server):
+		diff.Status = entity.ToolMergeStatusBoth
+		return diff, local, true
+	default:
+		diff.Status = entity.ToolMergeStatusConflict
+		return diff, server, true
+	}
+}
+
+func equalValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func extraInfoValue(info map[string]string, key string) *string {
+	value, ok := info[key]
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+// mergeSource merges the line changes of local and server since base (diff3).
+// Lines kept by both sides split the sources into blocks, a block changed by one side only takes that side,
+// a block changed by both sides differently is a conflict and keeps the server lines in the returned source.
+func mergeSource(base, local, server string) (string, []entity.ToolSourceConflictEntity) {
+	o, a, b := strings.Split(base, "\n"), strings.Split(local, "\n"), strings.Split(server, "\n")
+	matchA, matchB := matchLines(o, a), matchLines(o, b)
+
+	out := make([]string, 0, len(b))
+	conflicts := []entity.ToolSourceConflictEntity{}
+	io, ia, ib := 0, 0, 0
+	for {
+		stable := 0
+		for io+stable < len(o) && matchA[io+stable] == ia+stable && matchB[io+stable] == ib+stable {
+			stable++
+		}
+		if stable > 0 {
+			out = append(out, o[io:io+stable]...)
+			io, ia, ib = io+stable, ia+stable, ib+stable
+			continue
+		}
+
+		// the next base line kept by both sides ends the changed block
+		next := io
+		for next < len(o) && (matchA[next] < 0 || matchB[next] < 0) {
+			next++
+		}
+		endA, endB := len(a), len(b)
+		if next < len(o) {
+			endA, endB = matchA[next], matchB[next]
+		}
+		if next == io && endA == ia && endB == ib {
+			break
+		}
+
+		blockO, blockA, blockB := o[io:next], a[ia:endA], b[ib:endB]
+		switch {
+		case slices.Equal(blockA, blockO):
+			out = append(out, blockB...)
+		case slices.Equal(blockB, blockO), slices.Equal(blockA, blockB):
+			out = append(out, blockA...)
+		default:
+			conflicts = append(conflicts, entity.ToolSourceConflictEntity{
+				BaseLine:    io + 1,
+				LocalLine:   ia + 1,
+				ServerLine:  ib + 1,
+				BaseLines:   slices.Clone(blockO),
+				LocalLines:  slices.Clone(blockA),
+				ServerLines: slices.Clone(blockB),
+			})
+			out = append(out, blockB...)
+		}
+		io, ia, ib = next, endA, endB
+	}
+
+	return strings.Join(out, "\n"), conflicts
+}
+
+// matchLines returns for each base line the index of the same line in other along their longest common
+// subsequence, -1 for a line other removed or changed.
+func matchLines(base, other []string) []int {
+	match := make([]int, len(base))
+	for i := range match {
+		match[i] = -1
+	}
+
+	prefix := 0
+	for prefix < len(base) && prefix < len(other) && base[prefix] == other[prefix] {
+		match[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(base)-prefix && suffix < len(other)-prefix && base[len(base)-1-suffix] == other[len(other)-1-suffix] {
+		match[len(base)-1-suffix] = len(other) - 1 - suffix
+		suffix++
+	}
+
+	o, x := base[prefix:len(base)-suffix], other[prefix:len(other)-suffix]
+	n, m := len(o), len(x)
+	if n == 0 || m == 0 || n*m > toolMergeMaxDiffCells {
+		return match
+	}
+
+	// lcs[i*(m+1)+j] is the length of the longest common subsequence of o[i:] and x[j:]
+	lcs := make([]int32, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if o[i] == x[j] {
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+			} else {
+				lcs[i*(m+1)+j] = max(lcs[(i+1)*(m+1)+j], lcs[i*(m+1)+j+1])
+			}
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case o[i] == x[j]:
+			match[prefix+i] = prefix + j
+			i++
+			j++
+		case lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return match
+}
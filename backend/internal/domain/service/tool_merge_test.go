@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+)
+
+func TestMergeSource(t *testing.T) {
+	t.Parallel()
+
+	const base = "a\nb\nc\nd\ne"
+
+	tests := []struct {
+		name          string
+		local         string
+		server        string
+		want          string
+		wantConflicts []entity.ToolSourceConflictEntity
+	}{
+		{
+			name:   "only local changed",
+			local:  "a\nB\nc\nd\ne",
+			server: base,
+			want:   "a\nB\nc\nd\ne",
+		},
+		{
+			name:   "changes to different lines are combined",
+			local:  "a\nB\nc\nd\ne",
+			server: "a\nb\nc\nD\ne\nf",
+			want:   "a\nB\nc\nD\ne\nf",
+		},
+		{
+			name:   "local insert and server delete",
+			local:  "start\na\nb\nc\nd\ne",
+			server: "a\nb\nd\ne",
+			want:   "start\na\nb\nd\ne",
+		},
+		{
+			name:   "same change on both sides",
+			local:  "a\nb\nC\nd\ne",
+			server: "a\nb\nC\nd\ne",
+			want:   "a\nb\nC\nd\ne",
+		},
+		{
+			name:   "overlapping changes conflict and keep the server lines",
+			local:  "a\nb\nlocal\nd\ne",
+			server: "a\nb\nserver1\nserver2\nd\ne",
+			want:   "a\nb\nserver1\nserver2\nd\ne",
+			wantConflicts: []entity.ToolSourceConflictEntity{
+				{
+					BaseLine:    3,
+					LocalLine:   3,
+					ServerLine:  3,
+					BaseLines:   []string{"c"},
+					LocalLines:  []string{"local"},
+					ServerLines: []string{"server1", "server2"},
+				},
+			},
+		},
+		{
+			name:   "both appending conflicts",
+			local:  base + "\nlocal",
+			server: base + "\nserver",
+			want:   base + "\nserver",
+			wantConflicts: []entity.ToolSourceConflictEntity{
+				{BaseLine: 6, LocalLine: 6, ServerLine: 6, BaseLines: []string{}, LocalLines: []string{"local"}, ServerLines: []string{"server"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, conflicts := mergeSource(base, tt.local, tt.server)
+			require.Equal(t, tt.want, got)
+			if tt.wantConflicts == nil {
+				tt.wantConflicts = []entity.ToolSourceConflictEntity{}
+			}
+			require.Equal(t, tt.wantConflicts, conflicts)
+		})
+	}
+}
+
+func TestToolService_MergeTool(t *testing.T) {
+	t.Parallel()
+
+	const userID = entity.UserIDEntity("user-1")
+
+	base := entity.ToolEntity{
+		UniqueID:  "tool-u1",
+		ID:        "json",
+		Name:      "JSON",
+		Namespace: "default",
+		Source:    "line1\nline2\nline3",
+		ExtraInfo: map[string]string{"color": "red", "size": "s"},
+	}
+	withChanges := func(change func(*entity.ToolEntity)) entity.ToolEntity {
+		tool := base
+		tool.ExtraInfo = map[string]string{"color": "red", "size": "s"}
+		change(&tool)
+		return tool
+	}
+	strPtr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name          string
+		edited        entity.ToolEntity
+		server        entity.ToolEntity
+		toolUID       string
+		repoErr       error
+		wantFields    []entity.ToolMergeFieldEntity
+		wantMerged    func(*entity.ToolEntity)
+		wantConflict  bool
+		wantErrCode   string
+		wantErrSubstr string
+	}{
+		{
+			name: "non overlapping changes are merged",
+			edited: withChanges(func(tool *entity.ToolEntity) {
+				tool.Name = "JSON Pretty"
+				tool.Source = "line1 local\nline2\nline3"
+				delete(tool.ExtraInfo, "size")
+			}),
+			server: withChanges(func(tool *entity.ToolEntity) {
+				tool.IsActivate = true
+				tool.Source = "line1\nline2\nline3 server"
+				tool.ExtraInfo["owner"] = "me"
+			}),
+			wantFields: []entity.ToolMergeFieldEntity{
+				{Field: entity.ToolMergeFieldName, Base: strPtr("JSON"), Local: strPtr("JSON Pretty"), Server: strPtr("JSON"), Status: entity.ToolMergeStatusLocal},
+				{Field: entity.ToolMergeFieldIsActivate, Base: strPtr("false"), Local: strPtr("false"), Server: strPtr("true"), Status: entity.ToolMergeStatusServer},
+				{
+					Field:  entity.ToolMergeFieldSource,
+					Base:   strPtr("line1\nline2\nline3"),
+					Local:  strPtr("line1 local\nline2\nline3"),
+					Server: strPtr("line1\nline2\nline3 server"),
+					Status: entity.ToolMergeStatusMerged,
+				},
+				{Field: "extra_info.owner", Local: nil, Server: strPtr("me"), Status: entity.ToolMergeStatusServer},
+				{Field: "extra_info.size", Base: strPtr("s"), Local: nil, Server: strPtr("s"), Status: entity.ToolMergeStatusLocal},
+			},
+			wantMerged: func(tool *entity.ToolEntity) {
+				tool.Name = "JSON Pretty"
+				tool.IsActivate = true
+				tool.Source = "line1 local\nline2\nline3 server"
+				tool.ExtraInfo = map[string]string{"color": "red", "owner": "me"}
+			},
+		},
+		{
+			name:   "same change on both sides",
+			edited: withChanges(func(tool *entity.ToolEntity) { tool.Category = "format" }),
+			server: withChanges(func(tool *entity.ToolEntity) { tool.Category = "format" }),
+			wantFields: []entity.ToolMergeFieldEntity{
+				{Field: entity.ToolMergeFieldCategory, Base: strPtr(""), Local: strPtr("format"), Server: strPtr("format"), Status: entity.ToolMergeStatusBoth},
+			},
+			wantMerged: func(tool *entity.ToolEntity) { tool.Category = "format" },
+		},
+		{
+			name:   "conflicting field is reported without a merged tool",
+			edited: withChanges(func(tool *entity.ToolEntity) { tool.ExtraInfo["color"] = "blue" }),
+			server: withChanges(func(tool *entity.ToolEntity) { tool.ExtraInfo["color"] = "green" }),
+			wantFields: []entity.ToolMergeFieldEntity{
+				{Field: "extra_info.color", Base: strPtr("red"), Local: strPtr("blue"), Server: strPtr("green"), Status: entity.ToolMergeStatusConflict},
+			},
+			wantConflict: true,
+		},
+		{
+			name:        "unknown tool",
+			edited:      base,
+			server:      base,
+			toolUID:     "tool-missing",
+			wantErrCode: error_code.ToolNotFound.Code,
+		},
+		{
+			name:          "repository error is wrapped",
+			edited:        base,
+			repoErr:       errors.New("db offline"),
+			wantErrSubstr: "fail to get tools of user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			toolRepo := mockgen.NewMockIToolRepository(ctrl)
+			toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{tt.server}}, tt.repoErr)
+
+			toolUID := tt.toolUID
+			if toolUID == "" {
+				toolUID = base.UniqueID
+			}
+
+			svc := NewToolService(toolRepo, nil, nil, nil, nil, nil, nil, config.Config{})
+			result, err := svc.MergeTool(context.Background(), userID, toolUID, base, tt.edited)
+
+			if tt.wantErrSubstr != "" {
+				require.ErrorContains(t, err, tt.wantErrSubstr)
+				return
+			}
+			if tt.wantErrCode != "" {
+				var ecErr error_code.ErrorWithErrorCode
+				require.True(t, errors.As(err, &ecErr))
+				require.Equal(t, tt.wantErrCode, ecErr.ErrorCode.Code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.server, result.Server)
+			require.Equal(t, tt.wantFields, result.Fields)
+			require.Equal(t, tt.wantConflict, result.HasConflicts())
+
+			if tt.wantConflict {
+				require.Nil(t, result.Merged)
+				return
+			}
+			want := tt.server
+			want.ExtraInfo = map[string]string{"color": "red", "size": "s"}
+			tt.wantMerged(&want)
+			require.Equal(t, &want, result.Merged)
+		})
+	}
+}
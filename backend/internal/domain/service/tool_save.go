@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// toolSecretWarning is a credential found in the source as the extra data of ToolSourceContainsSecret shows it
+type toolSecretWarning struct {
+	Rule   string `json:"rule"`
+	Line   int    `json:"line"`
+	Masked string `json:"masked"`
+}
+
+// SaveNewTool creates a tool of the user after the checks every api runs before a tool is created: the extra info is
+// validated, the source is scanned for credentials, and the tool quota and the storage of the user are checked.
+// It returns the created tool and the credentials found in the source.
+func (s *ToolService) SaveNewTool(ctx context.Context, userID entity.UserIDEntity, tool entity.ToolEntity) (entity.ToolEntity, []entity.ToolSecretFindingEntity, error) {
+	if err := validateSavedToolExtraInfo(ctx, tool); err != nil {
+		return entity.ToolEntity{}, nil, err
+	}
+	return s.saveNewTool(ctx, userID, tool)
+}
+
+// saveNewTool creates the tool after the checks of SaveNewTool but the extra info one
+func (s *ToolService) saveNewTool(ctx context.Context, userID entity.UserIDEntity, tool entity.ToolEntity) (entity.ToolEntity, []entity.ToolSecretFindingEntity, error) {
+	findings, err := s.checkToolSave(ctx, userID, tool, true)
+	if err != nil {
+		return entity.ToolEntity{}, nil, err
+	}
+	created, err := s.CreateTool(ctx, userID, tool)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to create tool for user %s: %v", userID, err)
+		return entity.ToolEntity{}, nil, s.toolSaveError(ctx, userID, tool, err, "Unexpected create tool error")
+	}
+	return created, findings, nil
+}
+
+// SaveToolUpdate saves a changed tool of the user after the checks of SaveNewTool, but the tool quota.
+// It returns the credentials found in the source.
+func (s *ToolService) SaveToolUpdate(ctx context.Context, userID entity.UserIDEntity, tool entity.ToolEntity) ([]entity.ToolSecretFindingEntity, error) {
+	if err := validateSavedToolExtraInfo(ctx, tool); err != nil {
+		return nil, err
+	}
+	findings, err := s.checkToolSave(ctx, userID, tool, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.toolRepo.UpdateTool(userID, tool, ToolEventSourceFromContext(ctx, userID)); err != nil {
+		logger.Errorf(ctx, "Failed to update tool %s for user %s: %v", tool.UniqueID, userID, err)
+		return nil, s.toolSaveError(ctx, userID, tool, err, "Unexpected update tool error")
+	}
+	return findings, nil
+}
+
+// CheckToolSource runs the secret scan on the source to save, the returned error rejects the save when the scan mode
+// blocks. The error lists the credentials found in its extra data.
+func (s *ToolService) CheckToolSource(ctx context.Context, source string) ([]entity.ToolSecretFindingEntity, error) {
+	result := s.ScanToolSource(source)
+	if result.Blocked {
+		logger.Errorf(ctx, "Tool source rejected, %d credentials found", len(result.Findings))
+		warnings := lo.Map(result.Findings, func(finding entity.ToolSecretFindingEntity, _ int) toolSecretWarning {
+			return toolSecretWarning{Rule: finding.Rule, Line: finding.Line, Masked: finding.Masked}
+		})
+		return nil, error_code.NewErrorWithErrorCodeFAppendExtraData(
+			error_code.ToolSourceContainsSecret,
+			map[string]any{"warnings": warnings},
+			"remove the credentials from the tool source before saving",
+		)
+	}
+	if len(result.Findings) > 0 {
+		logger.Infof(ctx, "Tool source contains %d credentials, saving with warnings", len(result.Findings))
+	}
+	return result.Findings, nil
+}
+
+func validateSavedToolExtraInfo(ctx context.Context, tool entity.ToolEntity) error {
+	if err := entity.ValidateToolExtraInfo(tool.ExtraInfo); err != nil {
+		logger.Errorf(ctx, "Invalid tool extra info: %v", err)
+		return error_code.NewErrorWithErrorCode(error_code.InvalidToolExtraInfo, err.Error())
+	}
+	return nil
+}
+
+func (s *ToolService) checkToolSave(ctx context.Context, userID entity.UserIDEntity, tool entity.ToolEntity, creating bool) ([]entity.ToolSecretFindingEntity, error) {
+	findings, err := s.CheckToolSource(ctx, tool.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	if creating {
+		if err := s.CheckToolQuota(ctx, userID); err != nil {
+			logger.Errorf(ctx, "Tool quota check failed for user %s: %v", userID, err)
+			return nil, err
+		}
+	}
+
+	if err := s.meteringService.CheckToolStorage(ctx, userID, tool); err != nil {
+		logger.Errorf(ctx, "Tool storage check failed for user %s: %v", userID, err)
+		return nil, err
+	}
+	return findings, nil
+}
+
+// toolSaveError maps an error of saving a tool to the error answered to the client. A name another tool of the
+// namespace has is a conflict with a suggested free name, an id another tool of the user has is a conflict too, an
+// error with an error code is answered as it is and anything else is unexpected.
+func (s *ToolService) toolSaveError(ctx context.Context, userID entity.UserIDEntity, tool entity.ToolEntity, err error, message string) error {
+	if errors.Is(err, repository.ErrToolNameTaken) {
+		return s.ToolNameTakenError(ctx, userID, tool)
+	}
+	if errors.Is(err, repository.ErrToolIDTaken) {
+		return error_code.NewErrorWithErrorCodef(error_code.ToolIDAlreadyExists, "tool id %q already exists", tool.ID)
+	}
+	var codeErr error_code.ErrorWithErrorCode
+	if errors.As(err, &codeErr) {
+		return err
+	}
+	return error_code.NewErrorWithErrorCodef(error_code.InternalServerError, "%s", message)
+}
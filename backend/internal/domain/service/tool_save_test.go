@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func requireToolSaveErrorCode(t *testing.T, err error, want error_code.ErrorCode) error_code.ErrorWithErrorCode {
+	t.Helper()
+	var codeErr error_code.ErrorWithErrorCode
+	require.ErrorAs(t, err, &codeErr)
+	require.Equal(t, want.Code, codeErr.ErrorCode.Code)
+	return codeErr
+}
+
+func TestToolService_SaveNewTool(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+	leakySource := "const token = \"ghp_" + strings.Repeat("a1B2", 9) + "\";"
+
+	t.Run("creates the tool and returns the credentials found", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		svc, toolRepo, _, _ := newTestToolBundleService(ctrl, config.Config{ToolSecretScanMode: ToolSecretScanModeWarn})
+		tool := fixtures.NewTestTool().WithSource(leakySource).Build()
+		toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{}, nil).AnyTimes()
+		toolRepo.EXPECT().CreateTool(userID, tool, gomock.Any()).Return(nil)
+
+		created, findings, err := svc.SaveNewTool(context.Background(), userID, tool)
+		require.NoError(t, err)
+		require.Equal(t, tool, created)
+		require.Len(t, findings, 1)
+		require.Equal(t, "github_token", findings[0].Rule)
+	})
+
+	t.Run("a blocked source is not saved", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		svc, _, _, _ := newTestToolBundleService(ctrl, config.Config{ToolSecretScanMode: ToolSecretScanModeBlock})
+
+		_, _, err := svc.SaveNewTool(context.Background(), userID, fixtures.NewTestTool().WithSource(leakySource).Build())
+		codeErr := requireToolSaveErrorCode(t, err, error_code.ToolSourceContainsSecret)
+		warnings, ok := codeErr.ExtraData.(map[string]any)["warnings"].([]toolSecretWarning)
+		require.True(t, ok)
+		require.Len(t, warnings, 1)
+		require.Equal(t, 1, warnings[0].Line)
+	})
+
+	t.Run("invalid extra info is not saved", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		svc, _, _, _ := newTestToolBundleService(ctrl, config.Config{})
+
+		_, _, err := svc.SaveNewTool(context.Background(), userID, fixtures.NewTestTool().WithExtraInfo(map[string]string{" ": "x"}).Build())
+		requireToolSaveErrorCode(t, err, error_code.InvalidToolExtraInfo)
+	})
+
+	t.Run("the tool quota is checked", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		svc, toolRepo, _, _ := newTestToolBundleService(ctrl, config.Config{},
+			entity.SystemSettingEntity{Key: entity.SystemSettingKeyMaxToolsPerUser, Value: "1"},
+		)
+		toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{fixtures.NewTestTool().Build()}}, nil)
+
+		_, _, err := svc.SaveNewTool(context.Background(), userID, fixtures.NewTestTool().Build())
+		requireToolSaveErrorCode(t, err, error_code.ToolQuotaExceeded)
+	})
+
+	t.Run("a taken name suggests a free one", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		svc, toolRepo, _, _ := newTestToolBundleService(ctrl, config.Config{UniqueToolNames: true})
+		existing := fixtures.NewTestTool().WithNamespace("json").WithName("Format").Build()
+		tool := fixtures.NewTestTool().WithNamespace("json").WithName("Format").Build()
+		toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{existing}}, nil).AnyTimes()
+		toolRepo.EXPECT().CreateTool(userID, tool, gomock.Any()).Return(repository.ErrToolNameTaken)
+
+		_, _, err := svc.SaveNewTool(context.Background(), userID, tool)
+		codeErr := requireToolSaveErrorCode(t, err, error_code.ToolNameAlreadyExists)
+		require.Equal(t, map[string]any{"suggested_name": "Format (2)"}, codeErr.ExtraData)
+	})
+}
+
+func TestToolService_SaveToolUpdate(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+
+	t.Run("updates the tool", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		svc, toolRepo, _, _ := newTestToolBundleService(ctrl, config.Config{})
+		tool := fixtures.NewTestTool().Build()
+		toolRepo.EXPECT().UpdateTool(userID, tool, gomock.Any()).Return(nil)
+
+		findings, err := svc.SaveToolUpdate(context.Background(), userID, tool)
+		require.NoError(t, err)
+		require.Empty(t, findings)
+	})
+
+	t.Run("a taken id is a conflict", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		svc, toolRepo, _, _ := newTestToolBundleService(ctrl, config.Config{})
+		tool := fixtures.NewTestTool().Build()
+		toolRepo.EXPECT().UpdateTool(userID, tool, gomock.Any()).Return(repository.ErrToolIDTaken)
+
+		_, err := svc.SaveToolUpdate(context.Background(), userID, tool)
+		requireToolSaveErrorCode(t, err, error_code.ToolIDAlreadyExists)
+	})
+}
+
+func TestToolService_SaveCopiedTool(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+
+	t.Run("keeps the reserved extra info and renames a taken name", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		svc, toolRepo, _, _ := newTestToolBundleService(ctrl, config.Config{UniqueToolNames: true})
+		existing := fixtures.NewTestTool().WithNamespace("json").WithName("Format").Build()
+		tool := fixtures.NewTestTool().WithNamespace("json").WithName("Format").
+			WithExtraInfo(map[string]string{entity.ToolExtraInfoKeyForkedFrom: "user-2/tool-1"}).Build()
+		toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{existing}}, nil).AnyTimes()
+		var saved entity.ToolEntity
+		toolRepo.EXPECT().CreateTool(userID, gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ entity.UserIDEntity, tool entity.ToolEntity, _ entity.ToolEventSourceEntity) error {
+				saved = tool
+				return nil
+			},
+		)
+
+		created, _, err := svc.SaveCopiedTool(context.Background(), userID, tool)
+		require.NoError(t, err)
+		require.Equal(t, "Format (2)", saved.Name)
+		require.Equal(t, saved, created)
+		require.Equal(t, "user-2/tool-1", created.ExtraInfo[entity.ToolExtraInfoKeyForkedFrom])
+	})
+	t.Run("infected widgets are not saved", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		svc, _, _, _ := newTestToolBundleService(ctrl, config.Config{})
+		svc.importScanService = NewImportScanService(scannerFunc(func(_ context.Context, file entity.ImportFileEntity) (entity.ContentScanResultEntity, error) {
+			if strings.HasSuffix(file.Name, toolBundleUiWidgetsFile) {
+				return entity.ContentScanResultEntity{Signature: "Eicar-Signature"}, nil
+			}
+			return entity.ContentScanResultEntity{Clean: true}, nil
+		}), config.Config{ImportMaxFileSize: 1024, ImportMaxFiles: 10})
+
+		_, _, err := svc.SaveCopiedTool(context.Background(), userID, fixtures.NewTestTool().WithUiWidgets("[]").Build())
+		requireToolSaveErrorCode(t, err, error_code.ImportContentRejected)
+	})
+}
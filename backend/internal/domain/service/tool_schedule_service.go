@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/scheduler"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	ToolScheduleJobRunDueSchedules = "tool_schedules"
+
+	// toolScheduleMaxNameLength is the longest schedule name in characters
+	toolScheduleMaxNameLength = 128
+	// toolScheduleDueBatch is how many due schedules one tick of the job runs, the rest wait for the next tick
+	toolScheduleDueBatch = 100
+)
+
+func NewToolScheduleService(
+	toolRepo repository.IToolRepository,
+	scheduleRepo repository.IToolScheduleRepository,
+	toolExecutionService *ToolExecutionService,
+	clock domain_client.IClock,
+	cfg config.Config,
+) *ToolScheduleService {
+	return &ToolScheduleService{
+		toolRepo:             toolRepo,
+		scheduleRepo:         scheduleRepo,
+		toolExecutionService: toolExecutionService,
+		clock:                clock,
+		config:               cfg,
+	}
+}
+
+// ToolScheduleService runs tools of users on cron expressions with stored inputs and keeps the history of the runs.
+// The runs go through ToolExecutionService, they need TOOL_EXECUTION_ENABLED and count against its limits.
+type ToolScheduleService struct {
+	toolRepo             repository.IToolRepository
+	scheduleRepo         repository.IToolScheduleRepository
+	toolExecutionService *ToolExecutionService
+	clock                domain_client.IClock
+	config               config.Config
+}
+
+// Jobs returns the job running the due schedules, it is off while server side tool execution is.
+func (s *ToolScheduleService) Jobs() []scheduler.Job {
+	return []scheduler.Job{
+		{
+			Name:        ToolScheduleJobRunDueSchedules,
+			Description: "Runs the tool schedules of users that are due and records their runs",
+			Spec: func(ctx context.Context) (string, error) {
+				if !s.config.ToolExecutionEnabled {
+					return "", nil
+				}
+				return "@every 1m", nil
+			},
+			Run: s.RunDueSchedules,
+		},
+	}
+}
+
+// ListSchedules returns the schedules of the user, of every tool when toolUID is empty.
+func (s *ToolScheduleService) ListSchedules(ctx context.Context, userID entity.UserIDEntity, toolUID string) ([]entity.ToolScheduleEntity, error) {
+	schedules, err := s.scheduleRepo.ListSchedules(ctx, userID, toolUID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to list tool schedules of user %s", userID)
+	}
+	return schedules, nil
+}
+
+func (s *ToolScheduleService) GetSchedule(ctx context.Context, userID entity.UserIDEntity, toolUID, id string) (entity.ToolScheduleEntity, error) {
+	schedule, found, err := s.scheduleRepo.GetSchedule(ctx, userID, id)
+	if err != nil {
+		return entity.ToolScheduleEntity{}, errors.Wrapf(err, "fail to get tool schedule %s", id)
+	}
+	if !found || schedule.ToolUID != toolUID {
+		return entity.ToolScheduleEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolScheduleNotFound, "tool schedule %s not found", id)
+	}
+	return schedule, nil
+}
+
+func (s *ToolScheduleService) CreateSchedule(
+	ctx context.Context,
+	userID entity.UserIDEntity,
+	toolUID string,
+	name string,
+	cron string,
+	inputs map[string]any,
+	resourceClass entity.ToolResourceClass,
+	enabled bool,
+) (entity.ToolScheduleEntity, error) {
+	if !s.config.ToolExecutionEnabled {
+		return entity.ToolScheduleEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolExecutionDisabled, "set TOOL_EXECUTION_ENABLED to schedule tool runs")
+	}
+	if err := s.checkToolExists(userID, toolUID); err != nil {
+		return entity.ToolScheduleEntity{}, err
+	}
+	count, err := s.scheduleRepo.CountSchedules(ctx, userID)
+	if err != nil {
+		return entity.ToolScheduleEntity{}, errors.Wrapf(err, "fail to count tool schedules of user %s", userID)
+	}
+	if count >= s.config.ToolScheduleMaxPerUser {
+		return entity.ToolScheduleEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolScheduleQuotaExceeded, "a user keeps at most %d tool schedules", s.config.ToolScheduleMaxPerUser)
+	}
+
+	schedule := entity.NewToolScheduleEntityWithoutID(userID, toolUID, strings.TrimSpace(name), strings.TrimSpace(cron), inputs, resourceClass, enabled)
+	now := s.now()
+	if err := s.prepareSchedule(&schedule, now); err != nil {
+		return entity.ToolScheduleEntity{}, err
+	}
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+	if err := s.scheduleRepo.CreateSchedule(ctx, schedule); err != nil {
+		return entity.ToolScheduleEntity{}, errors.Wrap(err, "fail to create tool schedule")
+	}
+	return schedule, nil
+}
+
+// UpdateSchedule replaces the name, cron expression, inputs, resource class and enabled state of a schedule. The next run is
+// computed again from now, so changing a schedule never fires a run that was missed meanwhile.
+func (s *ToolScheduleService) UpdateSchedule(
+	ctx context.Context,
+	userID entity.UserIDEntity,
+	toolUID string,
+	id string,
+	name string,
+	cron string,
+	inputs map[string]any,
+	resourceClass entity.ToolResourceClass,
+	enabled bool,
+) (entity.ToolScheduleEntity, error) {
+	schedule, err := s.GetSchedule(ctx, userID, toolUID, id)
+	if err != nil {
+		return entity.ToolScheduleEntity{}, err
+	}
+	if enabled && !s.config.ToolExecutionEnabled {
+		return entity.ToolScheduleEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolExecutionDisabled, "set TOOL_EXECUTION_ENABLED to schedule tool runs")
+	}
+
+	schedule.Name = strings.TrimSpace(name)
+	schedule.Cron = strings.TrimSpace(cron)
+	schedule.Inputs = inputs
+	schedule.ResourceClass = resourceClass
+	schedule.Enabled = enabled
+	now := s.now()
+	if err := s.prepareSchedule(&schedule, now); err != nil {
+		return entity.ToolScheduleEntity{}, err
+	}
+	schedule.UpdatedAt = now
+	if err := s.scheduleRepo.UpdateSchedule(ctx, schedule); err != nil {
+		if errors.Is(err, repository.ErrToolScheduleNotFound) {
+			return entity.ToolScheduleEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolScheduleNotFound, "tool schedule %s not found", id)
+		}
+		return entity.ToolScheduleEntity{}, errors.Wrapf(err, "fail to update tool schedule %s", id)
+	}
+	return schedule, nil
+}
+
+// DeleteSchedule deletes a schedule with the history of its runs.
+func (s *ToolScheduleService) DeleteSchedule(ctx context.Context, userID entity.UserIDEntity, toolUID, id string) error {
+	if _, err := s.GetSchedule(ctx, userID, toolUID, id); err != nil {
+		return err
+	}
+	if err := s.scheduleRepo.DeleteSchedule(ctx, userID, id); err != nil {
+		if errors.Is(err, repository.ErrToolScheduleNotFound) {
+			return error_code.NewErrorWithErrorCodef(error_code.ToolScheduleNotFound, "tool schedule %s not found", id)
+		}
+		return errors.Wrapf(err, "fail to delete tool schedule %s", id)
+	}
+	return nil
+}
+
+// ListRuns returns the recorded runs of a schedule, newest first.
+func (s *ToolScheduleService) ListRuns(ctx context.Context, userID entity.UserIDEntity, toolUID, id string) ([]entity.ToolRunEntity, error) {
+	if _, err := s.GetSchedule(ctx, userID, toolUID, id); err != nil {
+		return nil, err
+	}
+	runs, err := s.scheduleRepo.ListRuns(ctx, userID, id, s.config.ToolRunHistoryLimit)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to list runs of tool schedule %s", id)
+	}
+	return runs, nil
+}
+
+// RunDueSchedules runs every schedule whose next run has come. A schedule is claimed before it runs,
+// so each due run happens once even with several instances sharing the database.
+func (s *ToolScheduleService) RunDueSchedules(ctx context.Context) error {
+	now := s.now()
+	schedules, err := s.scheduleRepo.DueSchedules(ctx, now, toolScheduleDueBatch)
+	if err != nil {
+		return errors.Wrap(err, "fail to get due tool schedules")
+	}
+
+	for _, schedule := range schedules {
+		cron, err := scheduler.ParseCron(schedule.Cron)
+		if err != nil {
+			logger.Errorf(ctx, "Skip tool schedule %s with invalid cron expression %q: %v", schedule.ID, schedule.Cron, err)
+			continue
+		}
+		claimed, err := s.scheduleRepo.ClaimSchedule(ctx, schedule.ID, now, cron.Next(now))
+		if err != nil {
+			return errors.Wrapf(err, "fail to claim tool schedule %s", schedule.ID)
+		}
+		if !claimed {
+			continue
+		}
+		if err := s.runSchedule(ctx, schedule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ToolScheduleService) runSchedule(ctx context.Context, schedule entity.ToolScheduleEntity) error {
+	run := entity.NewToolRunEntityWithoutID(schedule, s.now())
+	startedAt := s.clock.Now()
+	result, _, err := s.toolExecutionService.ExecuteTool(ctx, schedule.UserID, schedule.ToolUID, entity.ToolExecutionRequestEntity{
+		Inputs:        schedule.Inputs,
+		ResourceClass: schedule.ResourceClass,
+	})
+	run.Duration = s.clock.Now().Sub(startedAt)
+	if err != nil {
+		run.Status = entity.ToolRunStatusFailed
+		run.ErrorCode, run.ErrorMessage, run.Logs = toolRunFailure(err)
+		logger.Infof(ctx, "Scheduled run of tool %s of user %s failed: %v", schedule.ToolUID, schedule.UserID, err)
+	} else {
+		run.Status = entity.ToolRunStatusSuccess
+		run.Outputs = result.Outputs
+		run.Logs = result.Logs
+	}
+
+	if err := s.scheduleRepo.AddRun(ctx, run, s.config.ToolRunHistoryLimit); err != nil {
+		return errors.Wrapf(err, "fail to record run of tool schedule %s", schedule.ID)
+	}
+	return nil
+}
+
+// prepareSchedule validates the schedule and computes its next run
+func (s *ToolScheduleService) prepareSchedule(schedule *entity.ToolScheduleEntity, now time.Time) error {
+	if schedule.Name == "" || utf8.RuneCountInString(schedule.Name) > toolScheduleMaxNameLength {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidToolSchedule, "the name must have 1 to %d characters", toolScheduleMaxNameLength)
+	}
+	cron, err := scheduler.ParseCron(schedule.Cron)
+	if err != nil {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidToolSchedule, "invalid cron expression %q: %v", schedule.Cron, err)
+	}
+	class, err := entity.ParseToolResourceClass(string(schedule.ResourceClass))
+	if err != nil {
+		return error_code.NewErrorWithErrorCode(error_code.InvalidToolSchedule, err.Error())
+	}
+	schedule.ResourceClass = class
+	if schedule.Inputs == nil {
+		schedule.Inputs = map[string]any{}
+	}
+	inputs, err := json.Marshal(schedule.Inputs)
+	if err != nil {
+		return error_code.NewErrorWithErrorCodef(error_code.InvalidToolSchedule, "inputs are not valid JSON: %v", err)
+	}
+	if len(inputs) > s.config.ToolExecutionMaxPayload {
+		return error_code.NewErrorWithErrorCodef(error_code.ToolExecutionTooLarge, "the inputs exceed %d bytes", s.config.ToolExecutionMaxPayload)
+	}
+
+	schedule.NextRunAt = nil
+	if schedule.Enabled {
+		next := cron.Next(now)
+		schedule.NextRunAt = &next
+	}
+	return nil
+}
+
+func (s *ToolScheduleService) checkToolExists(userID entity.UserIDEntity, toolUID string) error {
+	_, exists, err := s.toolRepo.GetTool(userID, toolUID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get tool %s of user %s", toolUID, userID)
+	}
+	if !exists {
+		return error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "tool %s not found", toolUID)
+	}
+	return nil
+}
+
+// now is the current time as stored in the schedules, cron expressions are evaluated in UTC
+func (s *ToolScheduleService) now() time.Time {
+	return s.clock.Now().UTC().Truncate(time.Second)
+}
+
+// toolRunFailure is the error code, message and console output recorded for a failed run
+func toolRunFailure(err error) (string, string, []entity.ToolExecutionLogEntity) {
+	var codeErr error_code.ErrorWithErrorCode
+	if !errors.As(err, &codeErr) {
+		return error_code.InternalServerError.Code, "the run failed unexpectedly", nil
+	}
+
+	var logs []entity.ToolExecutionLogEntity
+	if extra, ok := codeErr.ExtraData.(map[string]any); ok {
+		if extraLogs, ok := extra["logs"].([]map[string]string); ok {
+			logs = lo.Map(extraLogs, func(log map[string]string, _ int) entity.ToolExecutionLogEntity {
+				return entity.ToolExecutionLogEntity{Level: log["level"], Message: log["message"]}
+			})
+		}
+	}
+	return codeErr.ErrorCode.Code, codeErr.ExtraMessage, logs
+}
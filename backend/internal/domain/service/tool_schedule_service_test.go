@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+var toolScheduleTestNow = time.Date(2026, 3, 15, 12, 0, 30, 0, time.UTC)
+
+func newToolScheduleServiceForTest(t *testing.T, cfg config.Config, runner toolRunnerFunc) (*ToolScheduleService, *mockgen.MockIToolScheduleRepository, entity.UserEntity) {
+	secretService, secretRepo, user := newToolSecretServiceForTest(t)
+	secretRepo.EXPECT().ListSecrets(gomock.Any(), user.ID, gomock.Any()).Return(nil, nil).AnyTimes()
+	scheduleRepo := mockgen.NewMockIToolScheduleRepository(gomock.NewController(t))
+
+	clock := fixtures.NewFakeClock(toolScheduleTestNow)
+	executionService := NewToolExecutionService(
+		secretService.toolRepo,
+		secretService,
+		NewMeteringService(nil, nil, nil, clock, config.Config{}),
+		NewToolExecutionLimiter(clock, cfg),
+		runner,
+		cfg,
+	)
+	return NewToolScheduleService(secretService.toolRepo, scheduleRepo, executionService, clock, cfg), scheduleRepo, user
+}
+
+func TestToolScheduleService_CreateSchedule(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	enabled := config.Config{ToolExecutionEnabled: true, ToolExecutionTimeout: 5, ToolExecutionMaxPayload: 1024, ToolExecutionMaxConcurrent: 1, ToolScheduleMaxPerUser: 2}
+	hourlyNextRun := time.Date(2026, 3, 15, 13, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		cfg           config.Config
+		toolUID       string
+		scheduleName  string
+		cron          string
+		resourceClass entity.ToolResourceClass
+		enabled       bool
+		existing      int
+		wantNextRun   *time.Time
+		wantClass     entity.ToolResourceClass
+		wantErrCode   *error_code.ErrorCode
+	}{
+		{name: "enabled schedule is due on the next match", cfg: enabled, toolUID: "tool-1", scheduleName: "Hourly", cron: "0 * * * *", enabled: true, wantNextRun: &hourlyNextRun, wantClass: entity.ToolResourceClassSmall},
+		{name: "disabled schedule is never due", cfg: enabled, toolUID: "tool-1", scheduleName: "Paused", cron: "@daily", wantClass: entity.ToolResourceClassSmall},
+		{name: "resource class is kept", cfg: enabled, toolUID: "tool-1", scheduleName: "Heavy", cron: "@daily", resourceClass: entity.ToolResourceClassLarge, wantClass: entity.ToolResourceClassLarge},
+		{name: "unknown resource class", cfg: enabled, toolUID: "tool-1", scheduleName: "Heavy", cron: "@daily", resourceClass: "huge", wantErrCode: &error_code.InvalidToolSchedule},
+		{name: "server side execution is off", cfg: config.Config{ToolScheduleMaxPerUser: 2}, toolUID: "tool-1", scheduleName: "Hourly", cron: "@hourly", wantErrCode: &error_code.ToolExecutionDisabled},
+		{name: "unknown tool", cfg: enabled, toolUID: "tool-2", scheduleName: "Hourly", cron: "@hourly", wantErrCode: &error_code.ToolNotFound},
+		{name: "quota reached", cfg: enabled, toolUID: "tool-1", scheduleName: "Hourly", cron: "@hourly", existing: 2, wantErrCode: &error_code.ToolScheduleQuotaExceeded},
+		{name: "invalid cron expression", cfg: enabled, toolUID: "tool-1", scheduleName: "Hourly", cron: "every hour", wantErrCode: &error_code.InvalidToolSchedule},
+		{name: "blank name", cfg: enabled, toolUID: "tool-1", scheduleName: "  ", cron: "@hourly", wantErrCode: &error_code.InvalidToolSchedule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, scheduleRepo, user := newToolScheduleServiceForTest(t, tt.cfg, nil)
+			scheduleRepo.EXPECT().CountSchedules(gomock.Any(), user.ID).Return(tt.existing, nil).AnyTimes()
+			var created *entity.ToolScheduleEntity
+			scheduleRepo.EXPECT().CreateSchedule(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, schedule entity.ToolScheduleEntity) error {
+				created = &schedule
+				return nil
+			}).MaxTimes(1)
+
+			schedule, err := svc.CreateSchedule(context.Background(), user.ID, tt.toolUID, tt.scheduleName, tt.cron, nil, tt.resourceClass, tt.enabled)
+			if tt.wantErrCode != nil {
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, tt.wantErrCode.Code, codeErr.ErrorCode.Code)
+				require.Nil(t, created)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, created)
+			require.Equal(t, schedule, *created)
+			require.Equal(t, map[string]any{}, schedule.Inputs)
+			require.Equal(t, tt.wantNextRun, schedule.NextRunAt)
+			require.Equal(t, tt.wantClass, schedule.ResourceClass)
+		})
+	}
+}
+
+func TestToolScheduleService_RunDueSchedules(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	cfg := config.Config{ToolExecutionEnabled: true, ToolExecutionTimeout: 5, ToolExecutionLargeTimeout: 60, ToolExecutionMaxPayload: 1024, ToolExecutionMaxConcurrent: 1, ToolRunHistoryLimit: 10}
+	now := toolScheduleTestNow.Truncate(time.Second)
+	ok := entity.ToolScheduleEntity{ID: "schedule-ok", UserID: "user-1", ToolUID: "tool-1", Cron: "@hourly", Inputs: map[string]any{"text": "ok"}, ResourceClass: entity.ToolResourceClassLarge, Enabled: true}
+	failing := entity.ToolScheduleEntity{ID: "schedule-failing", UserID: "user-1", ToolUID: "tool-1", Cron: "@hourly", Inputs: map[string]any{"text": "fail"}, Enabled: true}
+	taken := entity.ToolScheduleEntity{ID: "schedule-taken", UserID: "user-1", ToolUID: "tool-1", Cron: "@hourly", Inputs: map[string]any{"text": "taken"}, Enabled: true}
+
+	runner := toolRunnerFunc(func(_ context.Context, _ string, _ map[string]string, request entity.ToolExecutionRequestEntity, limits entity.ToolExecutionLimitsEntity) (entity.ToolExecutionResultEntity, error) {
+		switch request.Inputs["text"] {
+		case "ok":
+			// the run gets the limits of the resource class of its schedule
+			require.Equal(t, 60*time.Second, limits.Timeout)
+			return entity.ToolExecutionResultEntity{Outputs: map[string]any{"ok": true}, Logs: []entity.ToolExecutionLogEntity{{Level: "log", Message: "done"}}}, nil
+		case "fail":
+			logs := map[string]any{"logs": []map[string]string{{"level": "error", "message": "about to fail"}}}
+			return entity.ToolExecutionResultEntity{}, error_code.NewErrorWithErrorCodeFAppendExtraData(error_code.ToolExecutionFailed, logs, "boom")
+		}
+		t.Fatalf("schedule %v ran without being claimed", request.Inputs)
+		return entity.ToolExecutionResultEntity{}, nil
+	})
+	svc, scheduleRepo, _ := newToolScheduleServiceForTest(t, cfg, runner)
+
+	nextRun := time.Date(2026, 3, 15, 13, 0, 0, 0, time.UTC)
+	scheduleRepo.EXPECT().DueSchedules(gomock.Any(), now, toolScheduleDueBatch).Return([]entity.ToolScheduleEntity{ok, failing, taken}, nil)
+	scheduleRepo.EXPECT().ClaimSchedule(gomock.Any(), ok.ID, now, nextRun).Return(true, nil)
+	scheduleRepo.EXPECT().ClaimSchedule(gomock.Any(), failing.ID, now, nextRun).Return(true, nil)
+	// another instance ran it first
+	scheduleRepo.EXPECT().ClaimSchedule(gomock.Any(), taken.ID, now, nextRun).Return(false, nil)
+
+	runs := map[string]entity.ToolRunEntity{}
+	scheduleRepo.EXPECT().AddRun(gomock.Any(), gomock.Any(), 10).DoAndReturn(func(_ context.Context, run entity.ToolRunEntity, _ int) error {
+		runs[run.ScheduleID] = run
+		return nil
+	}).Times(2)
+
+	require.NoError(t, svc.RunDueSchedules(context.Background()))
+	require.Len(t, runs, 2)
+
+	require.Equal(t, entity.ToolRunStatusSuccess, runs[ok.ID].Status)
+	require.Equal(t, map[string]any{"ok": true}, runs[ok.ID].Outputs)
+	require.Equal(t, []entity.ToolExecutionLogEntity{{Level: "log", Message: "done"}}, runs[ok.ID].Logs)
+	require.Equal(t, now, runs[ok.ID].StartedAt)
+
+	require.Equal(t, entity.ToolRunStatusFailed, runs[failing.ID].Status)
+	require.Equal(t, error_code.ToolExecutionFailed.Code, runs[failing.ID].ErrorCode)
+	require.Equal(t, "boom", runs[failing.ID].ErrorMessage)
+	require.Equal(t, []entity.ToolExecutionLogEntity{{Level: "error", Message: "about to fail"}}, runs[failing.ID].Logs)
+	require.Nil(t, runs[failing.ID].Outputs)
+}
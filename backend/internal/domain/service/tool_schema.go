@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// toolWidgetValueSchemas are the JSON Schemas of the values the ui widgets hold, by widget type.
+// File widgets are missing on purpose, a file cannot be passed as JSON.
+var toolWidgetValueSchemas = map[string]func(props map[string]any) map[string]any{
+	"TextInput":         fixedWidgetSchema(map[string]any{"type": "string"}),
+	"TextareaInput":     fixedWidgetSchema(map[string]any{"type": "string"}),
+	"ColorInput":        fixedWidgetSchema(map[string]any{"type": "string"}),
+	"ColorPickerInput":  fixedWidgetSchema(map[string]any{"type": "string"}),
+	"SelectListInput":   optionsWidgetSchema,
+	"RadioGroupInput":   optionsWidgetSchema,
+	"NumberInput":       rangeWidgetSchema,
+	"SliderInput":       rangeWidgetSchema,
+	"ButtonInput":       fixedWidgetSchema(map[string]any{"type": "number"}),
+	"ToggleInput":       fixedWidgetSchema(map[string]any{"type": "boolean"}),
+	"TagInput":          fixedWidgetSchema(map[string]any{"type": "array", "items": map[string]any{"type": "string"}}),
+	"SortableListInput": fixedWidgetSchema(map[string]any{"type": "array"}),
+	"MultiTextInput":    fixedWidgetSchema(map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}}),
+	"ProgressBarInput":  fixedWidgetSchema(map[string]any{"type": "object"}),
+	"DividerInput":      fixedWidgetSchema(map[string]any{"type": "null"}),
+	"LabelInput":        fixedWidgetSchema(map[string]any{}),
+	"RawHtmlInput":      fixedWidgetSchema(map[string]any{}),
+}
+
+// toolUiWidget is the part of a ui widget the schema is derived from.
+type toolUiWidget struct {
+	ID    string         `json:"id"`
+	Type  string         `json:"type"`
+	Title string         `json:"title"`
+	Mode  string         `json:"mode"`
+	Props map[string]any `json:"props"`
+}
+
+// ToolSchema returns the input and output schemas of a tool.
+func (s *ToolService) ToolSchema(ctx context.Context, userID entity.UserIDEntity, toolUID string) (entity.ToolSchemaEntity, error) {
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return entity.ToolSchemaEntity{}, errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	tool, ok := lo.Find(tools.Tools, func(tool entity.ToolEntity) bool { return tool.UniqueID == toolUID })
+	if !ok {
+		return entity.ToolSchemaEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "tool %s not found", toolUID)
+	}
+	return resolveToolSchema(tool)
+}
+
+// ValidateToolRun checks the arguments of a headless run and, when given, the object the handler returned against the tool schemas.
+func (s *ToolService) ValidateToolRun(ctx context.Context, userID entity.UserIDEntity, toolUID string, input map[string]any, output map[string]any) (entity.ToolSchemaValidationEntity, error) {
+	schema, err := s.ToolSchema(ctx, userID, toolUID)
+	if err != nil {
+		return entity.ToolSchemaValidationEntity{}, err
+	}
+
+	result := entity.ToolSchemaValidationEntity{InputProblems: []string{}, OutputProblems: []string{}}
+	validateToolSchemaValue(schema.Input, input, "$", &result.InputProblems)
+	if output != nil {
+		validateToolSchemaValue(schema.Output, output, "$", &result.OutputProblems)
+	}
+	return result, nil
+}
+
+// resolveToolSchema takes the schemas declared in the extra info and derives the missing ones from the ui widgets.
+func resolveToolSchema(tool entity.ToolEntity) (entity.ToolSchemaEntity, error) {
+	var result entity.ToolSchemaEntity
+	for _, side := range []struct {
+		key     string
+		mode    string
+		schema  *map[string]any
+		derived *bool
+	}{
+		{entity.ToolExtraInfoKeyInputSchema, "input", &result.Input, &result.InputDerived},
+		{entity.ToolExtraInfoKeyOutputSchema, "output", &result.Output, &result.OutputDerived},
+	} {
+		if declared, ok := tool.ExtraInfo[side.key]; ok {
+			schema, err := entity.ParseToolSchema(declared)
+			if err != nil {
+				return entity.ToolSchemaEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolSchemaUnavailable, "extra_info %q %s", side.key, err.Error())
+			}
+			*side.schema = schema
+			continue
+		}
+
+		schema, err := deriveToolSchema(tool.UiWidgets, side.mode)
+		if err != nil {
+			return entity.ToolSchemaEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolSchemaUnavailable, "ui widgets of tool %s: %s", tool.UniqueID, err.Error())
+		}
+		*side.schema = schema
+		*side.derived = true
+	}
+	return result, nil
+}
+
+// deriveToolSchema builds an object schema with a property for every ui widget of the mode, a widget without a mode is an input.
+// The ui widgets are rows, a row is a widget or an array of widgets.
+func deriveToolSchema(uiWidgets string, mode string) (map[string]any, error) {
+	var rows []json.RawMessage
+	if strings.TrimSpace(uiWidgets) != "" {
+		if err := json.Unmarshal([]byte(uiWidgets), &rows); err != nil {
+			return nil, fmt.Errorf("not a json array")
+		}
+	}
+
+	properties := map[string]any{}
+	for _, row := range rows {
+		var widgets []toolUiWidget
+		if err := json.Unmarshal(row, &widgets); err != nil {
+			var widget toolUiWidget
+			if err := json.Unmarshal(row, &widget); err != nil {
+				return nil, fmt.Errorf("a row is neither a widget nor an array of widgets")
+			}
+			widgets = []toolUiWidget{widget}
+		}
+
+		for _, widget := range widgets {
+			widgetMode := lo.Ternary(widget.Mode == "", "input", widget.Mode)
+			if widget.ID == "" || widgetMode != mode {
+				continue
+			}
+			valueSchema, ok := toolWidgetValueSchemas[widget.Type]
+			if !ok {
+				continue
+			}
+			property := valueSchema(widget.Props)
+			if widget.Title != "" {
+				property["title"] = widget.Title
+			}
+			properties[widget.ID] = property
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}, nil
+}
+
+// fixedWidgetSchema is the schema of a widget whose value does not depend on its props.
+func fixedWidgetSchema(schema map[string]any) func(props map[string]any) map[string]any {
+	return func(map[string]any) map[string]any {
+		return maps.Clone(schema)
+	}
+}
+
+// optionsWidgetSchema limits the value to the option values of a select or radio group.
+func optionsWidgetSchema(props map[string]any) map[string]any {
+	schema := map[string]any{"type": "string"}
+	options, _ := props["options"].([]any)
+	var values []any
+	for _, rawOption := range options {
+		if option, ok := rawOption.(map[string]any); ok {
+			if value, ok := option["value"].(string); ok {
+				values = append(values, value)
+			}
+		}
+	}
+	if len(values) > 0 {
+		schema["enum"] = values
+	}
+	return schema
+}
+
+// rangeWidgetSchema keeps the bounds of a number input or slider.
+func rangeWidgetSchema(props map[string]any) map[string]any {
+	schema := map[string]any{"type": "number"}
+	if min, ok := props["min"].(float64); ok {
+		schema["minimum"] = min
+	}
+	if max, ok := props["max"].(float64); ok {
+		schema["maximum"] = max
+	}
+	return schema
+}
+
+// validateToolSchemaValue checks a decoded JSON value against the keywords ParseToolSchema accepts and appends every problem found.
+func validateToolSchemaValue(schema map[string]any, value any, path string, problems *[]string) {
+	addProblem := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if rawType, ok := schema["type"]; ok {
+		types, ok := rawType.([]any)
+		if !ok {
+			types = []any{rawType}
+		}
+		if !lo.SomeBy(types, func(t any) bool { return toolSchemaTypeMatches(t, value) }) {
+			addProblem("must be %s", strings.Join(lo.Map(types, func(t any, _ int) string { return fmt.Sprint(t) }), " or "))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		if !lo.SomeBy(enum, func(allowed any) bool { return reflect.DeepEqual(allowed, value) }) {
+			addProblem("must be one of %v", enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if min, ok := schema["minimum"].(float64); ok && v < min {
+			addProblem("must be at least %v", min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && v > max {
+			addProblem("must be at most %v", max)
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if min, ok := schema["minLength"].(float64); ok && length < min {
+			addProblem("must be at least %v characters", min)
+		}
+		if max, ok := schema["maxLength"].(float64); ok && length > max {
+			addProblem("must be at most %v characters", max)
+		}
+	case []any:
+		if min, ok := schema["minItems"].(float64); ok && float64(len(v)) < min {
+			addProblem("must have at least %v items", min)
+		}
+		if max, ok := schema["maxItems"].(float64); ok && float64(len(v)) > max {
+			addProblem("must have at most %v items", max)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				validateToolSchemaValue(items, item, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case map[string]any:
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := v[name.(string)]; !ok {
+				addProblem("missing required property %q", name)
+			}
+		}
+
+		properties, _ := schema["properties"].(map[string]any)
+		// sorted so the problems come in a stable order
+		names := lo.Keys(v)
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := properties[name].(map[string]any); ok {
+				validateToolSchemaValue(property, v[name], path+"."+name, problems)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					addProblem("unknown property %q", name)
+				}
+			case map[string]any:
+				validateToolSchemaValue(additional, v[name], path+"."+name, problems)
+			}
+		}
+	}
+}
+
+func toolSchemaTypeMatches(schemaType any, value any) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return false
+}
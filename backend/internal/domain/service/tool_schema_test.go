@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+const testToolSchemaUiWidgets = `[
+	[{"id":"text","type":"TextareaInput","title":"Text","mode":"input"}],
+	{"id":"format","type":"SelectListInput","props":{"options":[{"value":"json","label":"JSON"},{"value":"yaml","label":"YAML"}]}},
+	[{"id":"indent","type":"SliderInput","props":{"min":0,"max":8}},{"id":"sorted","type":"ToggleInput"},{"id":"file","type":"FileUploadInput"}],
+	[{"id":"result","type":"TextareaInput","mode":"output"}]
+]`
+
+func TestDeriveToolSchema(t *testing.T) {
+	t.Parallel()
+
+	input, err := deriveToolSchema(testToolSchemaUiWidgets, "input")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"text":   map[string]any{"type": "string", "title": "Text"},
+			"format": map[string]any{"type": "string", "enum": []any{"json", "yaml"}},
+			"indent": map[string]any{"type": "number", "minimum": float64(0), "maximum": float64(8)},
+			"sorted": map[string]any{"type": "boolean"},
+		},
+		"additionalProperties": false,
+	}, input)
+
+	output, err := deriveToolSchema(testToolSchemaUiWidgets, "output")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"result": map[string]any{"type": "string"}}, output["properties"])
+
+	empty, err := deriveToolSchema("", "input")
+	require.NoError(t, err)
+	require.Empty(t, empty["properties"])
+
+	_, err = deriveToolSchema(`{"id":"text"}`, "input")
+	require.Error(t, err)
+}
+
+func TestValidateToolSchemaValue(t *testing.T) {
+	t.Parallel()
+
+	schema, err := entity.ParseToolSchema(`{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"count": {"type": "integer", "minimum": 1},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+			"mode": {"enum": ["a", "b"]}
+		},
+		"additionalProperties": false
+	}`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		value any
+		want  []string
+	}{
+		{
+			name:  "valid",
+			value: map[string]any{"name": "x", "count": float64(2), "tags": []any{"a"}, "mode": "b"},
+			want:  nil,
+		},
+		{
+			name:  "wrong root type",
+			value: "x",
+			want:  []string{"$: must be object"},
+		},
+		{
+			name:  "every problem is reported",
+			value: map[string]any{"count": 1.5, "tags": []any{"a", float64(1), "c"}, "mode": "c", "extra": true},
+			want: []string{
+				`$: missing required property "name"`,
+				"$.count: must be integer",
+				`$: unknown property "extra"`,
+				"$.mode: must be one of [a b]",
+				"$.tags: must have at most 2 items",
+				"$.tags[1]: must be string",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var problems []string
+			validateToolSchemaValue(schema, tt.value, "$", &problems)
+			require.Equal(t, tt.want, problems)
+		})
+	}
+}
+
+func TestToolService_ValidateToolRun(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	userID := entity.UserIDEntity("user-1")
+	tool := fixtures.NewTestTool().
+		WithUiWidgets(testToolSchemaUiWidgets).
+		WithExtraInfo(map[string]string{entity.ToolExtraInfoKeyOutputSchema: `{"type":"object","required":["result","count"]}`}).
+		Build()
+	toolRepo := mockgen.NewMockIToolRepository(ctrl)
+	toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{tool}}, nil).AnyTimes()
+	svc := NewToolService(toolRepo, nil, nil, nil, nil, nil, nil, config.Config{})
+
+	schema, err := svc.ToolSchema(context.Background(), userID, tool.UniqueID)
+	require.NoError(t, err)
+	require.True(t, schema.InputDerived)
+	require.False(t, schema.OutputDerived)
+
+	result, err := svc.ValidateToolRun(context.Background(), userID, tool.UniqueID,
+		map[string]any{"text": "hello", "indent": float64(9)},
+		map[string]any{"result": "x"},
+	)
+	require.NoError(t, err)
+	require.False(t, result.Valid())
+	require.Equal(t, []string{"$.indent: must be at most 8"}, result.InputProblems)
+	require.Equal(t, []string{`$: missing required property "count"`}, result.OutputProblems)
+
+	result, err = svc.ValidateToolRun(context.Background(), userID, tool.UniqueID, map[string]any{"format": "yaml"}, nil)
+	require.NoError(t, err)
+	require.True(t, result.Valid())
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+const (
+	ToolSecretScanModeOff   = "off"
+	ToolSecretScanModeWarn  = "warn"
+	ToolSecretScanModeBlock = "block"
+
+	// toolSecretMaskPrefixLength is the characters of a match kept when masking it
+	toolSecretMaskPrefixLength = 4
+)
+
+// toolSecretRules only detect credentials with a distinctive shape, generic "password = ..." heuristics
+// would flag most tools that merely handle passwords.
+var toolSecretRules = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"aws_access_key_id", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"aws_secret_access_key", regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|private).{0,20}?['"][0-9a-zA-Z/+]{40}['"]`)},
+	{"github_token", regexp.MustCompile(`\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,}\b`)},
+	{"github_fine_grained_token", regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`)},
+	{"private_key", regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+}
+
+// ScanToolSource looks for obvious credentials in tool source, users may publish or share a tool without
+// noticing a key embedded in it. Whether findings block the save depends on the configured scan mode.
+func (s *ToolService) ScanToolSource(source string) entity.ToolSecretScanResultEntity {
+	if s.secretScanMode == ToolSecretScanModeOff {
+		return entity.ToolSecretScanResultEntity{Findings: []entity.ToolSecretFindingEntity{}}
+	}
+
+	findings := scanToolSourceForSecrets(source)
+	return entity.ToolSecretScanResultEntity{
+		Findings: findings,
+		Blocked:  s.secretScanMode == ToolSecretScanModeBlock && len(findings) > 0,
+	}
+}
+
+func scanToolSourceForSecrets(source string) []entity.ToolSecretFindingEntity {
+	findings := []entity.ToolSecretFindingEntity{}
+	for i, line := range strings.Split(source, "\n") {
+		for _, rule := range toolSecretRules {
+			for _, match := range rule.pattern.FindAllString(line, -1) {
+				findings = append(findings, entity.ToolSecretFindingEntity{
+					Rule:   rule.name,
+					Line:   i + 1,
+					Masked: maskToolSecret(match),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func maskToolSecret(match string) string {
+	runes := []rune(match)
+	if len(runes) <= toolSecretMaskPrefixLength {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:toolSecretMaskPrefixLength]) + strings.Repeat("*", len(runes)-toolSecretMaskPrefixLength)
+}
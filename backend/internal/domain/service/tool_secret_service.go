@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"unicode/utf8"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	// toolSecretMaxValueBytes bounds a secret value, secrets are api keys and passwords, not files
+	toolSecretMaxValueBytes = 8 * 1024
+	// toolSecretsMaxPerScope bounds the secrets of a tool and the account level secrets of a user
+	toolSecretsMaxPerScope = 50
+	// toolSecretMaskHintMinLength is the shortest value whose last characters are shown in a masked listing,
+	// shorter values would give away too much of themselves
+	toolSecretMaskHintMinLength = 16
+	toolSecretMaskHintLength    = 4
+	toolSecretMask              = "********"
+)
+
+// toolSecretNamePattern keeps secret names usable as environment variable names
+var toolSecretNamePattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]{0,63}$`)
+
+func NewToolSecretService(
+	secretRepo repository.IToolSecretRepository,
+	toolRepo repository.IToolRepository,
+	userRepo repository.IUserRepository,
+) *ToolSecretService {
+	return &ToolSecretService{secretRepo: secretRepo, toolRepo: toolRepo, userRepo: userRepo}
+}
+
+// ToolSecretService keeps the secret variables injected into tool runs. A secret belongs to a tool or, with an
+// empty tool uid, to the whole account. Values are sealed with the user's encrypt key and only ResolveSecrets
+// returns them in plaintext, for ToolExecutionService to inject into a run on the server. No route returns them.
+type ToolSecretService struct {
+	secretRepo repository.IToolSecretRepository
+	toolRepo   repository.IToolRepository
+	userRepo   repository.IUserRepository
+}
+
+// ListSecrets returns the secrets of a tool, or of the account when toolUID is empty, with masked values.
+func (s *ToolSecretService) ListSecrets(ctx context.Context, userID entity.UserIDEntity, toolUID string) ([]entity.ToolSecretInfoEntity, error) {
+	if err := s.checkToolExists(userID, toolUID); err != nil {
+		return nil, err
+	}
+	user, err := s.existingUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := s.secretRepo.ListSecrets(ctx, userID, toolUID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to list secrets of tool %q", toolUID)
+	}
+	infos := make([]entity.ToolSecretInfoEntity, 0, len(secrets))
+	for _, secret := range secrets {
+		value, err := utils.DecryptString(user.EncrypKey, secret.EncryptedValue)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fail to decrypt secret %s", secret.Name)
+		}
+		infos = append(infos, toToolSecretInfo(secret, value))
+	}
+	return infos, nil
+}
+
+// PutSecret creates a secret, or rotates it when the name is already used: the value is replaced and the version increased.
+func (s *ToolSecretService) PutSecret(ctx context.Context, userID entity.UserIDEntity, toolUID string, name string, value string) (entity.ToolSecretInfoEntity, error) {
+	if !toolSecretNamePattern.MatchString(name) {
+		return entity.ToolSecretInfoEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidToolSecret,
+			"secret name must be 1 to 64 upper case letters, digits and underscores, not starting with a digit")
+	}
+	if value == "" || len(value) > toolSecretMaxValueBytes {
+		return entity.ToolSecretInfoEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidToolSecret,
+			"secret value must be 1 to %d bytes", toolSecretMaxValueBytes)
+	}
+	if err := s.checkToolExists(userID, toolUID); err != nil {
+		return entity.ToolSecretInfoEntity{}, err
+	}
+	user, err := s.existingUser(ctx, userID)
+	if err != nil {
+		return entity.ToolSecretInfoEntity{}, err
+	}
+
+	existing, err := s.secretRepo.ListSecrets(ctx, userID, toolUID)
+	if err != nil {
+		return entity.ToolSecretInfoEntity{}, errors.Wrapf(err, "fail to list secrets of tool %q", toolUID)
+	}
+	rotating := lo.ContainsBy(existing, func(secret entity.ToolSecretEntity) bool { return secret.Name == name })
+	if !rotating && len(existing) >= toolSecretsMaxPerScope {
+		return entity.ToolSecretInfoEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolSecretQuotaExceeded,
+			"a tool or an account can have at most %d secrets", toolSecretsMaxPerScope)
+	}
+
+	encrypted, err := utils.EncryptString(user.EncrypKey, value)
+	if err != nil {
+		return entity.ToolSecretInfoEntity{}, errors.Wrap(err, "fail to encrypt secret")
+	}
+	secret, err := s.secretRepo.PutSecret(ctx, userID, toolUID, name, encrypted)
+	if err != nil {
+		return entity.ToolSecretInfoEntity{}, errors.Wrapf(err, "fail to put secret %s", name)
+	}
+	return toToolSecretInfo(secret, value), nil
+}
+
+// DeleteSecret deletes a secret of a tool, or of the account when toolUID is empty.
+func (s *ToolSecretService) DeleteSecret(ctx context.Context, userID entity.UserIDEntity, toolUID string, name string) error {
+	if err := s.checkToolExists(userID, toolUID); err != nil {
+		return err
+	}
+	deleted, err := s.secretRepo.DeleteSecret(ctx, userID, toolUID, name)
+	if err != nil {
+		return errors.Wrapf(err, "fail to delete secret %s", name)
+	}
+	if !deleted {
+		return error_code.NewErrorWithErrorCodef(error_code.ToolSecretNotFound, "secret %s not found", name)
+	}
+	return nil
+}
+
+// ResolveSecrets returns the plaintext secrets injected into a run of the tool, by name.
+// The account level secrets are shared by every tool, a tool secret with the same name overrides them.
+func (s *ToolSecretService) ResolveSecrets(ctx context.Context, userID entity.UserIDEntity, toolUID string) (map[string]string, error) {
+	if toolUID == "" {
+		return nil, error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "tool uid is empty")
+	}
+	if err := s.checkToolExists(userID, toolUID); err != nil {
+		return nil, err
+	}
+	user, err := s.existingUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := map[string]string{}
+	for _, scope := range []string{"", toolUID} {
+		secrets, err := s.secretRepo.ListSecrets(ctx, userID, scope)
+		if err != nil {
+			return nil, errors.Wrapf(err, "fail to list secrets of tool %q", scope)
+		}
+		for _, secret := range secrets {
+			value, err := utils.DecryptString(user.EncrypKey, secret.EncryptedValue)
+			if err != nil {
+				return nil, errors.Wrapf(err, "fail to decrypt secret %s", secret.Name)
+			}
+			resolved[secret.Name] = value
+		}
+	}
+	return resolved, nil
+}
+
+// checkToolExists returns ToolNotFound unless the user has the tool, an empty toolUID is the account scope.
+func (s *ToolSecretService) checkToolExists(userID entity.UserIDEntity, toolUID string) error {
+	if toolUID == "" {
+		return nil
+	}
+	_, exists, err := s.toolRepo.GetTool(userID, toolUID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get tool %s of user %s", toolUID, userID)
+	}
+	if !exists {
+		return error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "tool %s not found", toolUID)
+	}
+	return nil
+}
+
+func (s *ToolSecretService) existingUser(ctx context.Context, userID entity.UserIDEntity) (entity.UserEntity, error) {
+	user, exists, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return entity.UserEntity{}, errors.Wrapf(err, "fail to get user by id")
+	}
+	if !exists {
+		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user not found")
+	}
+	return user, nil
+}
+
+func toToolSecretInfo(secret entity.ToolSecretEntity, value string) entity.ToolSecretInfoEntity {
+	return entity.ToolSecretInfoEntity{
+		ToolUID:     secret.ToolUID,
+		Name:        secret.Name,
+		MaskedValue: maskToolSecretValue(value),
+		Version:     secret.Version,
+		CreatedAt:   secret.CreatedAt,
+		UpdatedAt:   secret.UpdatedAt,
+	}
+}
+
+// maskToolSecretValue hides a value, only the last characters of a long value are kept so a rotation can be told apart.
+func maskToolSecretValue(value string) string {
+	if utf8.RuneCountInString(value) < toolSecretMaskHintMinLength {
+		return toolSecretMask
+	}
+	runes := []rune(value)
+	return toolSecretMask + string(runes[len(runes)-toolSecretMaskHintLength:])
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+	"ya-tool-craft/internal/utils"
+)
+
+const toolSecretTestEncryptKey = "encry-key-test"
+
+func newToolSecretServiceForTest(t *testing.T) (*ToolSecretService, *mockgen.MockIToolSecretRepository, entity.UserEntity) {
+	ctrl := gomock.NewController(t)
+	secretRepo := mockgen.NewMockIToolSecretRepository(ctrl)
+	toolRepo := mockgen.NewMockIToolRepository(ctrl)
+	userRepo := mockgen.NewMockIUserRepository(ctrl)
+
+	user := fixtures.NewTestUser().WithID("user-1").WithEncryptKey(toolSecretTestEncryptKey).Build()
+	tool := fixtures.NewTestTool().WithUniqueID("tool-1").Build()
+	toolRepo.EXPECT().GetTool(user.ID, gomock.Any()).DoAndReturn(func(_ entity.UserIDEntity, toolUID string) (entity.ToolEntity, bool, error) {
+		return tool, toolUID == tool.UniqueID, nil
+	}).AnyTimes()
+	userRepo.EXPECT().GetByID(gomock.Any(), user.ID).Return(user, true, nil).AnyTimes()
+
+	return NewToolSecretService(secretRepo, toolRepo, userRepo), secretRepo, user
+}
+
+func sealedToolSecret(t *testing.T, toolUID string, name string, value string) entity.ToolSecretEntity {
+	encrypted, err := utils.EncryptString(toolSecretTestEncryptKey, value)
+	require.NoError(t, err)
+	return entity.ToolSecretEntity{UserID: "user-1", ToolUID: toolUID, Name: name, EncryptedValue: encrypted, Version: 1}
+}
+
+func TestToolSecretService_PutSecret(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	tooMany := make([]entity.ToolSecretEntity, toolSecretsMaxPerScope)
+	for i := range tooMany {
+		tooMany[i] = entity.ToolSecretEntity{Name: "SECRET_" + strings.Repeat("X", i+1)}
+	}
+
+	tests := []struct {
+		name        string
+		toolUID     string
+		secretName  string
+		value       string
+		existing    []entity.ToolSecretEntity
+		wantPut     bool
+		wantMasked  string
+		wantErrCode *error_code.ErrorCode
+	}{
+		{name: "account secret", secretName: "API_KEY", value: "sk-1234567890abcdef", wantPut: true, wantMasked: "********cdef"},
+		{name: "short value is fully masked", toolUID: "tool-1", secretName: "PIN", value: "1234", wantPut: true, wantMasked: "********"},
+		{name: "rotating at the quota", secretName: "SECRET_X", value: "value", existing: tooMany, wantPut: true, wantMasked: "********"},
+		{name: "new secret over the quota", secretName: "API_KEY", value: "value", existing: tooMany, wantErrCode: &error_code.ToolSecretQuotaExceeded},
+		{name: "lower case name", secretName: "api_key", value: "value", wantErrCode: &error_code.InvalidToolSecret},
+		{name: "name starting with a digit", secretName: "1KEY", value: "value", wantErrCode: &error_code.InvalidToolSecret},
+		{name: "too long name", secretName: strings.Repeat("K", 65), value: "value", wantErrCode: &error_code.InvalidToolSecret},
+		{name: "empty value", secretName: "API_KEY", value: "", wantErrCode: &error_code.InvalidToolSecret},
+		{name: "too large value", secretName: "API_KEY", value: strings.Repeat("v", toolSecretMaxValueBytes+1), wantErrCode: &error_code.InvalidToolSecret},
+		{name: "unknown tool", toolUID: "tool-2", secretName: "API_KEY", value: "value", wantErrCode: &error_code.ToolNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, secretRepo, user := newToolSecretServiceForTest(t)
+			secretRepo.EXPECT().ListSecrets(gomock.Any(), user.ID, tt.toolUID).Return(tt.existing, nil).MaxTimes(1)
+			if tt.wantPut {
+				secretRepo.EXPECT().PutSecret(gomock.Any(), user.ID, tt.toolUID, tt.secretName, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ entity.UserIDEntity, toolUID string, name string, encrypted string) (entity.ToolSecretEntity, error) {
+						// the value is stored sealed with the user's key
+						assert.NotContains(t, encrypted, tt.value)
+						value, err := utils.DecryptString(toolSecretTestEncryptKey, encrypted)
+						require.NoError(t, err)
+						assert.Equal(t, tt.value, value)
+						return entity.ToolSecretEntity{ToolUID: toolUID, Name: name, EncryptedValue: encrypted, Version: 2}, nil
+					})
+			}
+
+			info, err := svc.PutSecret(context.Background(), user.ID, tt.toolUID, tt.secretName, tt.value)
+			if tt.wantErrCode != nil {
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				assert.Equal(t, tt.wantErrCode.Code, codeErr.ErrorCode.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.secretName, info.Name)
+			assert.Equal(t, tt.wantMasked, info.MaskedValue)
+			assert.Equal(t, 2, info.Version)
+		})
+	}
+}
+
+func TestToolSecretService_ListSecrets_MasksValues(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	svc, secretRepo, user := newToolSecretServiceForTest(t)
+	secretRepo.EXPECT().ListSecrets(gomock.Any(), user.ID, "tool-1").Return([]entity.ToolSecretEntity{
+		sealedToolSecret(t, "tool-1", "API_KEY", "sk-1234567890abcdef"),
+		sealedToolSecret(t, "tool-1", "PIN", "1234"),
+	}, nil)
+
+	infos, err := svc.ListSecrets(context.Background(), user.ID, "tool-1")
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, "********cdef", infos[0].MaskedValue)
+	assert.Equal(t, "********", infos[1].MaskedValue)
+}
+
+func TestToolSecretService_DeleteSecret(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	tests := []struct {
+		name        string
+		toolUID     string
+		deleted     bool
+		wantErrCode *error_code.ErrorCode
+	}{
+		{name: "deleted", toolUID: "tool-1", deleted: true},
+		{name: "missing secret", deleted: false, wantErrCode: &error_code.ToolSecretNotFound},
+		{name: "unknown tool", toolUID: "tool-2", wantErrCode: &error_code.ToolNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc, secretRepo, user := newToolSecretServiceForTest(t)
+			secretRepo.EXPECT().DeleteSecret(gomock.Any(), user.ID, tt.toolUID, "API_KEY").Return(tt.deleted, nil).MaxTimes(1)
+
+			err := svc.DeleteSecret(context.Background(), user.ID, tt.toolUID, "API_KEY")
+			if tt.wantErrCode != nil {
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				assert.Equal(t, tt.wantErrCode.Code, codeErr.ErrorCode.Code)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestToolSecretService_ResolveSecrets(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	svc, secretRepo, user := newToolSecretServiceForTest(t)
+	secretRepo.EXPECT().ListSecrets(gomock.Any(), user.ID, "").Return([]entity.ToolSecretEntity{
+		sealedToolSecret(t, "", "API_KEY", "account-key"),
+		sealedToolSecret(t, "", "REGION", "eu-west-1"),
+	}, nil)
+	secretRepo.EXPECT().ListSecrets(gomock.Any(), user.ID, "tool-1").Return([]entity.ToolSecretEntity{
+		sealedToolSecret(t, "tool-1", "API_KEY", "tool-key"),
+	}, nil)
+
+	resolved, err := svc.ResolveSecrets(context.Background(), user.ID, "tool-1")
+	require.NoError(t, err)
+	// the tool secret overrides the account secret with the same name
+	assert.Equal(t, map[string]string{"API_KEY": "tool-key", "REGION": "eu-west-1"}, resolved)
+
+	_, err = svc.ResolveSecrets(context.Background(), user.ID, "tool-2")
+	var codeErr error_code.ErrorWithErrorCode
+	require.ErrorAs(t, err, &codeErr)
+	assert.Equal(t, error_code.ToolNotFound.Code, codeErr.ErrorCode.Code)
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	// toolSearchMaxSourceMatches caps the source lines returned per tool, a common token can match every line.
+	toolSearchMaxSourceMatches = 20
+	// toolSearchMaxSnippetLength is the max runes of a snippet, longer lines (e.g. minified code) are windowed around the first match.
+	toolSearchMaxSnippetLength = 160
+	// toolSearchSnippetLeadingContext is the runes kept before the first match when a line is windowed.
+	toolSearchSnippetLeadingContext = 40
+	// toolUIDAttempts is how often a new tool is saved with a fresh unique id when another tool has its unique id
+	toolUIDAttempts = 3
+)
+
+func NewToolService(
+	toolRepo repository.IToolRepository,
+	globalScriptRepo repository.IGlobalScriptRepository,
+	settingsService *SystemSettingsService,
+	importScanService *ImportScanService,
+	meteringService *MeteringService,
+	userSettingsService *UserSettingsService,
+	clock client.IClock,
+	cfg config.Config,
+) *ToolService {
+	return &ToolService{
+		toolRepo:            toolRepo,
+		globalScriptRepo:    globalScriptRepo,
+		settingsService:     settingsService,
+		importScanService:   importScanService,
+		meteringService:     meteringService,
+		userSettingsService: userSettingsService,
+		clock:               clock,
+		secretScanMode:      cfg.ToolSecretScanMode,
+		uniqueToolNames:     cfg.UniqueToolNames,
+	}
+}
+
+type ToolService struct {
+	toolRepo            repository.IToolRepository
+	globalScriptRepo    repository.IGlobalScriptRepository
+	settingsService     *SystemSettingsService
+	importScanService   *ImportScanService
+	meteringService     *MeteringService
+	userSettingsService *UserSettingsService
+	clock               client.IClock
+	// secretScanMode is one of ToolSecretScanModeOff, ToolSecretScanModeWarn and ToolSecretScanModeBlock
+	secretScanMode string
+	// uniqueToolNames is true when the tools of a namespace must have different names
+	uniqueToolNames bool
+}
+
+// CheckToolQuota rejects creating another tool when the user reached the max tools per user setting.
+// Archived tools count too, they still hold their data.
+func (s *ToolService) CheckToolQuota(ctx context.Context, userID entity.UserIDEntity) error {
+	settings, err := s.settingsService.Settings(ctx)
+	if err != nil {
+		return errors.Wrap(err, "fail to get system settings")
+	}
+	if settings.MaxToolsPerUser <= 0 {
+		return nil
+	}
+
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	if len(tools.Tools) >= settings.MaxToolsPerUser {
+		return error_code.NewErrorWithErrorCodef(error_code.ToolQuotaExceeded, "a user can have at most %d tools", settings.MaxToolsPerUser)
+	}
+	return nil
+}
+
+// AvailableToolName returns the name to save a new tool with. With unique tool names it is the first of the tool name,
+// "name (2)", "name (3)" and so on that no other tool of the namespace has, otherwise the tool name as it is.
+func (s *ToolService) AvailableToolName(ctx context.Context, userID entity.UserIDEntity, tool entity.ToolEntity) (string, error) {
+	if !s.uniqueToolNames {
+		return tool.Name, nil
+	}
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return "", errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	others := make([]entity.ToolEntity, 0, len(tools.Tools))
+	for _, other := range tools.Tools {
+		if other.UniqueID != tool.UniqueID {
+			others = append(others, other)
+		}
+	}
+	return freeToolName(others, tool.Namespace, tool.Name), nil
+}
+
+// ToolNameTakenError returns the error for a tool refused with repository.ErrToolNameTaken.
+// The extra data suggests a name that is free in the namespace, so the client can offer to save under it.
+func (s *ToolService) ToolNameTakenError(ctx context.Context, userID entity.UserIDEntity, tool entity.ToolEntity) error {
+	suggested, err := s.AvailableToolName(ctx, userID, tool)
+	if err != nil {
+		return err
+	}
+	return error_code.NewErrorWithErrorCodeFAppendExtraData(
+		error_code.ToolNameAlreadyExists,
+		map[string]any{"suggested_name": suggested},
+		"tool %q already exists in namespace %q", tool.Name, tool.Namespace,
+	)
+}
+
+// GetTool returns the tool of the user with the unique id, it fails with ToolNotFound when the user has no such tool.
+func (s *ToolService) GetTool(ctx context.Context, userID entity.UserIDEntity, toolUID string) (entity.ToolEntity, error) {
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return entity.ToolEntity{}, errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	tool, ok := lo.Find(tools.Tools, func(tool entity.ToolEntity) bool { return tool.UniqueID == toolUID })
+	if !ok {
+		return entity.ToolEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "tool %s not found", toolUID)
+	}
+	return tool, nil
+}
+
+// CreateTool saves a new tool of the user. A unique id another tool already has is replaced with a fresh one, the
+// returned tool has the unique id it was saved with.
+func (s *ToolService) CreateTool(ctx context.Context, userID entity.UserIDEntity, tool entity.ToolEntity) (entity.ToolEntity, error) {
+	return createToolWithFreeUID(s.toolRepo, userID, tool, ToolEventSourceFromContext(ctx, userID))
+}
+
+// createToolWithFreeUID creates the tool, it gives up with ToolUIDConflict when every fresh unique id was taken too.
+func createToolWithFreeUID(toolRepo repository.IToolRepository, userID entity.UserIDEntity, tool entity.ToolEntity, eventSource entity.ToolEventSourceEntity) (entity.ToolEntity, error) {
+	for attempt := 1; ; attempt++ {
+		err := toolRepo.CreateTool(userID, tool, eventSource)
+		if !errors.Is(err, repository.ErrToolUIDTaken) {
+			return tool, err
+		}
+		if attempt == toolUIDAttempts {
+			return entity.ToolEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolUIDConflict,
+				"no free unique id for tool %s after %d attempts", tool.ID, toolUIDAttempts)
+		}
+		tool.UniqueID = entity.NewToolUniqueID()
+	}
+}
+
+// SearchTools finds the tools of a user whose metadata contains the query, case-insensitively.
+// When includeSource is true the tool source is searched as well and the matched lines are returned with highlights.
+func (s *ToolService) SearchTools(ctx context.Context, userID entity.UserIDEntity, query string, includeSource bool) ([]entity.ToolSearchResultEntity, error) {
+	needle := lowerRunes(query)
+	if len(needle) == 0 {
+		return []entity.ToolSearchResultEntity{}, nil
+	}
+
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+
+	results := []entity.ToolSearchResultEntity{}
+	for _, tool := range tools.Tools {
+		result := entity.ToolSearchResultEntity{Tool: tool, MatchedFields: []string{}, SourceMatches: []entity.ToolSourceMatchEntity{}}
+
+		fields := []struct {
+			name  string
+			value string
+		}{
+			{entity.ToolSearchFieldID, tool.ID},
+			{entity.ToolSearchFieldName, tool.Name},
+			{entity.ToolSearchFieldNamespace, tool.Namespace},
+			{entity.ToolSearchFieldCategory, tool.Category},
+			{entity.ToolSearchFieldDescription, tool.Description},
+		}
+		for _, field := range fields {
+			if len(findRuneMatches([]rune(field.value), needle)) > 0 {
+				result.MatchedFields = append(result.MatchedFields, field.name)
+			}
+		}
+
+		if includeSource {
+			result.SourceMatches = searchToolSource(tool.Source, needle)
+			if len(result.SourceMatches) > 0 {
+				result.MatchedFields = append(result.MatchedFields, entity.ToolSearchFieldSource)
+			}
+		}
+
+		if len(result.MatchedFields) > 0 {
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// searchToolSource returns the lines of source containing needle, line numbers start at 1.
+func searchToolSource(source string, needle []rune) []entity.ToolSourceMatchEntity {
+	matches := []entity.ToolSourceMatchEntity{}
+	for i, line := range strings.Split(source, "\n") {
+		if len(matches) >= toolSearchMaxSourceMatches {
+			break
+		}
+
+		lineRunes := []rune(strings.TrimSuffix(line, "\r"))
+		highlights := findRuneMatches(lineRunes, needle)
+		if len(highlights) == 0 {
+			continue
+		}
+
+		snippet, highlights := windowSnippet(lineRunes, highlights)
+		matches = append(matches, entity.ToolSourceMatchEntity{Line: i + 1, Snippet: snippet, Highlights: highlights})
+	}
+	return matches
+}
+
+// windowSnippet cuts long lines down to toolSearchMaxSnippetLength runes around the first highlight
+// and shifts the highlights into the window, highlights outside of it are dropped and ones on its edge are clipped.
+func windowSnippet(line []rune, highlights []entity.TextRangeEntity) (string, []entity.TextRangeEntity) {
+	if len(line) <= toolSearchMaxSnippetLength {
+		return string(line), highlights
+	}
+
+	start := max(highlights[0].Start-toolSearchSnippetLeadingContext, 0)
+	end := min(start+toolSearchMaxSnippetLength, len(line))
+	start = max(end-toolSearchMaxSnippetLength, 0)
+
+	windowed := []entity.TextRangeEntity{}
+	for _, h := range highlights {
+		if h.End <= start || h.Start >= end {
+			continue
+		}
+		windowed = append(windowed, entity.TextRangeEntity{Start: max(h.Start, start) - start, End: min(h.End, end) - start})
+	}
+	return string(line[start:end]), windowed
+}
+
+// findRuneMatches returns the non-overlapping case-insensitive occurrences of needle (already lowered) in text.
+// Matching is done rune by rune so the returned offsets are valid for text.
+func findRuneMatches(text []rune, needle []rune) []entity.TextRangeEntity {
+	ranges := []entity.TextRangeEntity{}
+	for i := 0; i+len(needle) <= len(text); {
+		matched := true
+		for j, r := range needle {
+			if unicode.ToLower(text[i+j]) != r {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			ranges = append(ranges, entity.TextRangeEntity{Start: i, End: i + len(needle)})
+			i += len(needle)
+			continue
+		}
+		i++
+	}
+	return ranges
+}
+
+func lowerRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
@@ -0,0 +1,415 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+)
+
+func TestToolService_SearchTools(t *testing.T) {
+	t.Parallel()
+
+	const userID = entity.UserIDEntity("user-1")
+
+	fetchTool := entity.ToolEntity{
+		UniqueID:    "tool-u1",
+		ID:          "http-client",
+		Name:        "HTTP Client",
+		Description: "Send requests",
+		Source:      "const url = input.url\r\nreturn Fetch(url)\nconsole.log(fetch, fetch)",
+	}
+	jsonTool := entity.ToolEntity{
+		UniqueID: "tool-u2",
+		ID:       "json-formatter",
+		Name:     "JSON Formatter",
+		Category: "fetch helpers",
+		Source:   "return JSON.stringify(input)",
+	}
+	longLine := strings.Repeat("a", 100) + "fetch" + strings.Repeat("b", 200)
+	longLineTool := entity.ToolEntity{UniqueID: "tool-u3", ID: "minified", Name: "Minified", Source: longLine}
+	unicodeTool := entity.ToolEntity{UniqueID: "tool-u4", ID: "unicode", Name: "Ünïcode", Source: "ÄÖÜ fetch"}
+
+	tests := []struct {
+		name          string
+		query         string
+		includeSource bool
+		tools         []entity.ToolEntity
+		repoErr       error
+		skipRepo      bool
+		want          []entity.ToolSearchResultEntity
+		wantErrSub    string
+	}{
+		{
+			name:     "empty query returns nothing without loading tools",
+			query:    "",
+			skipRepo: true,
+			want:     []entity.ToolSearchResultEntity{},
+		},
+		{
+			name:       "repository error is wrapped",
+			query:      "fetch",
+			repoErr:    errors.New("db offline"),
+			wantErrSub: "fail to get tools of user",
+		},
+		{
+			name:  "metadata only search ignores source",
+			query: "FETCH",
+			tools: []entity.ToolEntity{fetchTool, jsonTool},
+			want: []entity.ToolSearchResultEntity{
+				{Tool: jsonTool, MatchedFields: []string{entity.ToolSearchFieldCategory}, SourceMatches: []entity.ToolSourceMatchEntity{}},
+			},
+		},
+		{
+			name:          "source search returns lines and highlights",
+			query:         "fetch",
+			includeSource: true,
+			tools:         []entity.ToolEntity{fetchTool, jsonTool},
+			want: []entity.ToolSearchResultEntity{
+				{
+					Tool:          fetchTool,
+					MatchedFields: []string{entity.ToolSearchFieldSource},
+					SourceMatches: []entity.ToolSourceMatchEntity{
+						{Line: 2, Snippet: "return Fetch(url)", Highlights: []entity.TextRangeEntity{{Start: 7, End: 12}}},
+						{Line: 3, Snippet: "console.log(fetch, fetch)", Highlights: []entity.TextRangeEntity{{Start: 12, End: 17}, {Start: 19, End: 24}}},
+					},
+				},
+				{Tool: jsonTool, MatchedFields: []string{entity.ToolSearchFieldCategory}, SourceMatches: []entity.ToolSourceMatchEntity{}},
+			},
+		},
+		{
+			name:          "long lines are windowed around the first match",
+			query:         "fetch",
+			includeSource: true,
+			tools:         []entity.ToolEntity{longLineTool},
+			want: []entity.ToolSearchResultEntity{
+				{
+					Tool:          longLineTool,
+					MatchedFields: []string{entity.ToolSearchFieldSource},
+					SourceMatches: []entity.ToolSourceMatchEntity{
+						{Line: 1, Snippet: longLine[60:220], Highlights: []entity.TextRangeEntity{{Start: 40, End: 45}}},
+					},
+				},
+			},
+		},
+		{
+			name:          "metadata matching is unicode case-insensitive",
+			query:         "ÜNÏ",
+			includeSource: true,
+			tools:         []entity.ToolEntity{unicodeTool},
+			want: []entity.ToolSearchResultEntity{
+				{Tool: unicodeTool, MatchedFields: []string{entity.ToolSearchFieldName}, SourceMatches: []entity.ToolSourceMatchEntity{}},
+			},
+		},
+		{
+			name:          "unicode source highlights use character offsets",
+			query:         "fetch",
+			includeSource: true,
+			tools:         []entity.ToolEntity{unicodeTool},
+			want: []entity.ToolSearchResultEntity{
+				{
+					Tool:          unicodeTool,
+					MatchedFields: []string{entity.ToolSearchFieldSource},
+					SourceMatches: []entity.ToolSourceMatchEntity{
+						{Line: 1, Snippet: "ÄÖÜ fetch", Highlights: []entity.TextRangeEntity{{Start: 4, End: 9}}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			toolRepo := mockgen.NewMockIToolRepository(ctrl)
+			if !tt.skipRepo {
+				toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: tt.tools}, tt.repoErr)
+			}
+
+			svc := NewToolService(toolRepo, nil, newTestSystemSettingsService(ctrl, config.Config{}), nil, nil, nil, nil, config.Config{})
+			got, err := svc.SearchTools(context.Background(), userID, tt.query, tt.includeSource)
+			if tt.wantErrSub != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestToolService_CheckToolQuota(t *testing.T) {
+	t.Parallel()
+
+	const userID = entity.UserIDEntity("user-1")
+	twoTools := entity.ToolsEntity{Tools: []entity.ToolEntity{{UniqueID: "tool-u1"}, {UniqueID: "tool-u2", IsArchived: true}}}
+
+	tests := []struct {
+		name        string
+		limit       string
+		setupMocks  func(toolRepo *mockgen.MockIToolRepository)
+		wantErrCode *error_code.ErrorCode
+		wantErrSub  string
+	}{
+		{
+			name:  "unlimited skips counting",
+			limit: "0",
+		},
+		{
+			name:  "below the limit",
+			limit: "3",
+			setupMocks: func(toolRepo *mockgen.MockIToolRepository) {
+				toolRepo.EXPECT().AllTools(userID).Return(twoTools, nil)
+			},
+		},
+		{
+			name:  "archived tools count toward the limit",
+			limit: "2",
+			setupMocks: func(toolRepo *mockgen.MockIToolRepository) {
+				toolRepo.EXPECT().AllTools(userID).Return(twoTools, nil)
+			},
+			wantErrCode: &error_code.ToolQuotaExceeded,
+		},
+		{
+			name:  "repository error is wrapped",
+			limit: "2",
+			setupMocks: func(toolRepo *mockgen.MockIToolRepository) {
+				toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{}, errors.New("db offline"))
+			},
+			wantErrSub: "fail to get tools of user",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			toolRepo := mockgen.NewMockIToolRepository(ctrl)
+			if tt.setupMocks != nil {
+				tt.setupMocks(toolRepo)
+			}
+			settingsService := newTestSystemSettingsService(ctrl, config.Config{},
+				entity.SystemSettingEntity{Key: entity.SystemSettingKeyMaxToolsPerUser, Value: tt.limit},
+			)
+
+			err := NewToolService(toolRepo, nil, settingsService, nil, nil, nil, nil, config.Config{}).CheckToolQuota(context.Background(), userID)
+			switch {
+			case tt.wantErrCode != nil:
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, *tt.wantErrCode, codeErr.ErrorCode)
+			case tt.wantErrSub != "":
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+			default:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestToolService_AvailableToolName(t *testing.T) {
+	t.Parallel()
+
+	const userID = entity.UserIDEntity("user-1")
+	tools := entity.ToolsEntity{Tools: []entity.ToolEntity{
+		{UniqueID: "tool-u1", Namespace: "json", Name: "Format"},
+		{UniqueID: "tool-u2", Namespace: "json", Name: "Format (2)"},
+		{UniqueID: "tool-u3", Namespace: "yaml", Name: "Minify"},
+	}}
+
+	tests := []struct {
+		name   string
+		unique bool
+		tool   entity.ToolEntity
+		want   string
+	}{
+		{
+			name: "duplicates allowed keeps the name",
+			tool: entity.ToolEntity{UniqueID: "tool-new", Namespace: "json", Name: "Format"},
+			want: "Format",
+		},
+		{
+			name:   "taken name is numbered past the taken numbers",
+			unique: true,
+			tool:   entity.ToolEntity{UniqueID: "tool-new", Namespace: "json", Name: "Format"},
+			want:   "Format (3)",
+		},
+		{
+			name:   "names of other namespaces are free",
+			unique: true,
+			tool:   entity.ToolEntity{UniqueID: "tool-new", Namespace: "json", Name: "Minify"},
+			want:   "Minify",
+		},
+		{
+			name:   "the tool itself does not take its name",
+			unique: true,
+			tool:   entity.ToolEntity{UniqueID: "tool-u2", Namespace: "json", Name: "Format (2)"},
+			want:   "Format (2)",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			toolRepo := mockgen.NewMockIToolRepository(ctrl)
+			if tt.unique {
+				toolRepo.EXPECT().AllTools(userID).Return(tools, nil)
+			}
+
+			svc := NewToolService(toolRepo, nil, nil, nil, nil, nil, nil, config.Config{UniqueToolNames: tt.unique})
+			name, err := svc.AvailableToolName(context.Background(), userID, tt.tool)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, name)
+		})
+	}
+}
+
+func TestToolService_ToolNameTakenError(t *testing.T) {
+	t.Parallel()
+
+	const userID = entity.UserIDEntity("user-1")
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	toolRepo := mockgen.NewMockIToolRepository(ctrl)
+	toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{
+		{UniqueID: "tool-u1", Namespace: "json", Name: "Format"},
+	}}, nil)
+
+	svc := NewToolService(toolRepo, nil, nil, nil, nil, nil, nil, config.Config{UniqueToolNames: true})
+	err := svc.ToolNameTakenError(context.Background(), userID, entity.ToolEntity{UniqueID: "tool-new", Namespace: "json", Name: "Format"})
+
+	var codeErr error_code.ErrorWithErrorCode
+	require.ErrorAs(t, err, &codeErr)
+	require.Equal(t, error_code.ToolNameAlreadyExists, codeErr.ErrorCode)
+	require.Equal(t, map[string]any{"suggested_name": "Format (2)"}, codeErr.ExtraData)
+}
+
+func TestToolService_GetTool(t *testing.T) {
+	t.Parallel()
+
+	const userID = entity.UserIDEntity("user-1")
+	tools := entity.ToolsEntity{Tools: []entity.ToolEntity{
+		{UniqueID: "tool-uid-1", ID: "tool-1"},
+		{UniqueID: "tool-uid-2", ID: "tool-2"},
+	}}
+
+	tests := []struct {
+		name     string
+		toolUID  string
+		repoErr  error
+		wantID   string
+		wantCode string
+	}{
+		{name: "found", toolUID: "tool-uid-2", wantID: "tool-2"},
+		{name: "unknown unique id", toolUID: "tool-uid-3", wantCode: error_code.ToolNotFound.Code},
+		{name: "repo error", toolUID: "tool-uid-1", repoErr: errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			toolRepo := mockgen.NewMockIToolRepository(ctrl)
+			toolRepo.EXPECT().AllTools(userID).Return(tools, tt.repoErr)
+
+			svc := NewToolService(toolRepo, nil, nil, nil, nil, nil, nil, config.Config{})
+			tool, err := svc.GetTool(context.Background(), userID, tt.toolUID)
+			switch {
+			case tt.repoErr != nil:
+				require.ErrorContains(t, err, "fail to get tools")
+			case tt.wantCode != "":
+				var ecErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &ecErr)
+				require.Equal(t, tt.wantCode, ecErr.ErrorCode.Code)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tt.wantID, tool.ID)
+			}
+		})
+	}
+}
+
+func TestToolService_CreateTool(t *testing.T) {
+	t.Parallel()
+
+	const userID = entity.UserIDEntity("user-1")
+
+	t.Run("a taken unique id is replaced", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		var tried []string
+		toolRepo := mockgen.NewMockIToolRepository(ctrl)
+		toolRepo.EXPECT().CreateTool(userID, gomock.Any(), gomock.Any()).DoAndReturn(func(_ entity.UserIDEntity, tool entity.ToolEntity, _ entity.ToolEventSourceEntity) error {
+			tried = append(tried, tool.UniqueID)
+			if len(tried) == 1 {
+				return repository.ErrToolUIDTaken
+			}
+			return nil
+		}).Times(2)
+
+		svc := NewToolService(toolRepo, nil, nil, nil, nil, nil, nil, config.Config{})
+		created, err := svc.CreateTool(context.Background(), userID, entity.ToolEntity{ID: "tool-1", UniqueID: "tool-taken"})
+		require.NoError(t, err)
+		require.Equal(t, "tool-taken", tried[0])
+		require.NotEqual(t, "tool-taken", created.UniqueID)
+		require.Equal(t, tried[1], created.UniqueID)
+	})
+
+	t.Run("gives up when every unique id is taken", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		toolRepo := mockgen.NewMockIToolRepository(ctrl)
+		toolRepo.EXPECT().CreateTool(userID, gomock.Any(), gomock.Any()).Return(repository.ErrToolUIDTaken).Times(toolUIDAttempts)
+
+		svc := NewToolService(toolRepo, nil, nil, nil, nil, nil, nil, config.Config{})
+		_, err := svc.CreateTool(context.Background(), userID, entity.ToolEntity{ID: "tool-1", UniqueID: "tool-taken"})
+		var codeErr error_code.ErrorWithErrorCode
+		require.ErrorAs(t, err, &codeErr)
+		require.Equal(t, error_code.ToolUIDConflict, codeErr.ErrorCode)
+	})
+
+	t.Run("other errors are returned as they are", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		toolRepo := mockgen.NewMockIToolRepository(ctrl)
+		toolRepo.EXPECT().CreateTool(userID, gomock.Any(), gomock.Any()).Return(repository.ErrToolIDTaken)
+
+		svc := NewToolService(toolRepo, nil, nil, nil, nil, nil, nil, config.Config{})
+		_, err := svc.CreateTool(context.Background(), userID, entity.ToolEntity{ID: "tool-1", UniqueID: "tool-new"})
+		require.ErrorIs(t, err, repository.ErrToolIDTaken)
+	})
+}
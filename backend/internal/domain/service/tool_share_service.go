@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+func NewToolShareService(
+	toolShareRepo repository.IToolShareRepository,
+	toolRepo repository.IToolRepository,
+	userRepo repository.IUserRepository,
+	toolService *ToolService,
+	clock domain_client.IClock,
+) *ToolShareService {
+	return &ToolShareService{
+		toolShareRepo: toolShareRepo,
+		toolRepo:      toolRepo,
+		userRepo:      userRepo,
+		toolService:   toolService,
+		clock:         clock,
+	}
+}
+
+// ToolShareService lets the owner of a tool grant another user, or anyone with the link, read or fork access to it.
+// A shared tool is read from the owner's tools every time, so the grantee always sees its current state.
+type ToolShareService struct {
+	toolShareRepo repository.IToolShareRepository
+	toolRepo      repository.IToolRepository
+	userRepo      repository.IUserRepository
+	toolService   *ToolService
+	clock         domain_client.IClock
+}
+
+// ListShares returns the shares of a tool of the owner.
+func (s *ToolShareService) ListShares(ctx context.Context, ownerID entity.UserIDEntity, toolUID string) ([]entity.ToolShareEntity, error) {
+	if _, err := s.ownedTool(ownerID, toolUID); err != nil {
+		return nil, err
+	}
+	shares, err := s.toolShareRepo.ListSharesOfTool(ctx, ownerID, toolUID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to list shares of tool %s", toolUID)
+	}
+	return shares, nil
+}
+
+// ShareWithUser grants the user with the username access to the tool, sharing it again changes the permission.
+func (s *ToolShareService) ShareWithUser(ctx context.Context, ownerID entity.UserIDEntity, toolUID string, username string, permission entity.ToolSharePermission) (entity.ToolShareEntity, error) {
+	if _, err := s.ownedTool(ownerID, toolUID); err != nil {
+		return entity.ToolShareEntity{}, err
+	}
+	grantee, exists, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return entity.ToolShareEntity{}, errors.Wrapf(err, "fail to get user %s", username)
+	}
+	if !exists {
+		return entity.ToolShareEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user %s not found", username)
+	}
+	if grantee.ID == ownerID {
+		return entity.ToolShareEntity{}, error_code.NewErrorWithErrorCodef(error_code.InvalidToolShare, "tool %s can not be shared with its owner", toolUID)
+	}
+	return s.putShare(ctx, entity.ToolShareEntity{OwnerID: ownerID, ToolUniqueID: toolUID, GranteeID: grantee.ID, Permission: permission})
+}
+
+// ShareByLink makes the tool public to anyone with the link, the id of the returned share is the link token.
+// A tool has one link, sharing it again changes the permission and keeps the link.
+func (s *ToolShareService) ShareByLink(ctx context.Context, ownerID entity.UserIDEntity, toolUID string, permission entity.ToolSharePermission) (entity.ToolShareEntity, error) {
+	if _, err := s.ownedTool(ownerID, toolUID); err != nil {
+		return entity.ToolShareEntity{}, err
+	}
+	return s.putShare(ctx, entity.ToolShareEntity{OwnerID: ownerID, ToolUniqueID: toolUID, Permission: permission})
+}
+
+// RevokeShare deletes a share of a tool of the owner, revoking the link of a tool makes the link stop working.
+func (s *ToolShareService) RevokeShare(ctx context.Context, ownerID entity.UserIDEntity, toolUID string, shareID string) error {
+	share, exists, err := s.toolShareRepo.GetShare(ctx, shareID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get tool share %s", shareID)
+	}
+	if !exists || share.OwnerID != ownerID || share.ToolUniqueID != toolUID {
+		return error_code.NewErrorWithErrorCodef(error_code.ToolShareNotFound, "tool %s has no share %s", toolUID, shareID)
+	}
+	if _, err := s.toolShareRepo.DeleteShare(ctx, ownerID, shareID); err != nil {
+		return errors.Wrapf(err, "fail to delete tool share %s", shareID)
+	}
+	return nil
+}
+
+// SharedWithUser returns the tools other users shared with the user, newest share first.
+func (s *ToolShareService) SharedWithUser(ctx context.Context, userID entity.UserIDEntity) ([]entity.SharedToolEntity, error) {
+	shares, err := s.toolShareRepo.ListSharesWithUser(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to list tools shared with user %s", userID)
+	}
+
+	sharedTools := make([]entity.SharedToolEntity, 0, len(shares))
+	for _, share := range shares {
+		sharedTool, found, err := s.sharedTool(ctx, share)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			sharedTools = append(sharedTools, sharedTool)
+		}
+	}
+	return sharedTools, nil
+}
+
+// GetSharedTool returns the tool of a share the user may open, an empty userID opens public links only.
+// A share of another user is reported as not found, so the caller can not tell it exists.
+func (s *ToolShareService) GetSharedTool(ctx context.Context, userID entity.UserIDEntity, shareID string) (entity.SharedToolEntity, error) {
+	notFound := error_code.NewErrorWithErrorCodef(error_code.ToolShareNotFound, "shared tool %s not found", shareID)
+
+	share, exists, err := s.toolShareRepo.GetShare(ctx, shareID)
+	if err != nil {
+		return entity.SharedToolEntity{}, errors.Wrapf(err, "fail to get tool share %s", shareID)
+	}
+	if !exists || (!share.IsPublic() && (userID == "" || share.GranteeID != userID)) {
+		return entity.SharedToolEntity{}, notFound
+	}
+	sharedTool, found, err := s.sharedTool(ctx, share)
+	if err != nil {
+		return entity.SharedToolEntity{}, err
+	}
+	if !found {
+		return entity.SharedToolEntity{}, notFound
+	}
+	return sharedTool, nil
+}
+
+// ForkTool saves a copy of the shared tool as a tool of the user, with a new unique id, see
+// ToolService.SaveCopiedTool. The tool id gets a -fork suffix when the user already has a tool with the id.
+// It returns the saved tool and the credentials found in its source.
+func (s *ToolShareService) ForkTool(ctx context.Context, userID entity.UserIDEntity, shareID string) (entity.ToolEntity, []entity.ToolSecretFindingEntity, error) {
+	sharedTool, err := s.GetSharedTool(ctx, userID, shareID)
+	if err != nil {
+		return entity.ToolEntity{}, nil, err
+	}
+	if !sharedTool.Share.CanFork() {
+		return entity.ToolEntity{}, nil, error_code.NewErrorWithErrorCodef(error_code.ToolShareForkNotAllowed, "shared tool %s can only be read", shareID)
+	}
+	if sharedTool.Share.OwnerID == userID {
+		return entity.ToolEntity{}, nil, error_code.NewErrorWithErrorCodef(error_code.InvalidToolShare, "tool %s is already yours", sharedTool.Tool.UniqueID)
+	}
+
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return entity.ToolEntity{}, nil, errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	return s.toolService.SaveCopiedTool(ctx, userID, copyTool(sharedTool.Tool, tools.Tools, "-fork", nil, s.clock.Now().UTC()))
+}
+
+func (s *ToolShareService) putShare(ctx context.Context, share entity.ToolShareEntity) (entity.ToolShareEntity, error) {
+	stored, err := s.toolShareRepo.PutShare(ctx, share)
+	if err != nil {
+		return entity.ToolShareEntity{}, errors.Wrapf(err, "fail to share tool %s", share.ToolUniqueID)
+	}
+	return stored, nil
+}
+
+func (s *ToolShareService) ownedTool(ownerID entity.UserIDEntity, toolUID string) (entity.ToolEntity, error) {
+	tools, err := s.toolRepo.AllTools(ownerID)
+	if err != nil {
+		return entity.ToolEntity{}, errors.Wrapf(err, "fail to get tools of user %s", ownerID)
+	}
+	tool, ok := lo.Find(tools.Tools, func(tool entity.ToolEntity) bool { return tool.UniqueID == toolUID })
+	if !ok {
+		return entity.ToolEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "tool %s not found", toolUID)
+	}
+	return tool, nil
+}
+
+// sharedTool loads the tool and the owner name of a share, found is false when the tool or its owner is gone
+func (s *ToolShareService) sharedTool(ctx context.Context, share entity.ToolShareEntity) (entity.SharedToolEntity, bool, error) {
+	owner, exists, err := s.userRepo.GetByID(ctx, share.OwnerID)
+	if err != nil {
+		return entity.SharedToolEntity{}, false, errors.Wrapf(err, "fail to get owner of tool share %s", share.ID)
+	}
+	if !exists {
+		return entity.SharedToolEntity{}, false, nil
+	}
+	tools, err := s.toolRepo.AllTools(share.OwnerID)
+	if err != nil {
+		return entity.SharedToolEntity{}, false, errors.Wrapf(err, "fail to get tools of user %s", share.OwnerID)
+	}
+	tool, ok := lo.Find(tools.Tools, func(tool entity.ToolEntity) bool { return tool.UniqueID == share.ToolUniqueID })
+	if !ok {
+		return entity.SharedToolEntity{}, false, nil
+	}
+	return entity.SharedToolEntity{Share: share, OwnerName: owner.Name, Tool: tool}, true, nil
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+func TestToolShareService(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	owner := fixtures.NewTestUser().WithID("owner-1").WithName("owner").Build()
+	grantee := fixtures.NewTestUser().WithID("grantee-1").WithName("grantee").Build()
+	tool := fixtures.NewTestTool().WithUniqueID("tool-shared").WithID("json-format").Build()
+
+	type mocks struct {
+		shareRepo *mockgen.MockIToolShareRepository
+		toolRepo  *mockgen.MockIToolRepository
+		userRepo  *mockgen.MockIUserRepository
+	}
+	newService := func(t *testing.T) (*ToolShareService, mocks) {
+		ctrl := gomock.NewController(t)
+		toolService, toolRepo, _, _ := newTestToolBundleService(ctrl, config.Config{})
+		m := mocks{
+			shareRepo: mockgen.NewMockIToolShareRepository(ctrl),
+			toolRepo:  toolRepo,
+			userRepo:  mockgen.NewMockIUserRepository(ctrl),
+		}
+		return NewToolShareService(m.shareRepo, m.toolRepo, m.userRepo, toolService, fixtures.NewFakeClock(time.Unix(100, 0))), m
+	}
+	requireErrorCode := func(t *testing.T, err error, code error_code.ErrorCode) {
+		t.Helper()
+		var ecErr error_code.ErrorWithErrorCode
+		require.True(t, errors.As(err, &ecErr))
+		require.Equal(t, code.Code, ecErr.ErrorCode.Code)
+	}
+
+	t.Run("share with user", func(t *testing.T) {
+		t.Parallel()
+		svc, m := newService(t)
+		ctx := context.Background()
+		m.toolRepo.EXPECT().AllTools(owner.ID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{tool}}, nil).AnyTimes()
+		m.userRepo.EXPECT().GetByUsername(ctx, "nobody").Return(entity.UserEntity{}, false, nil)
+		m.userRepo.EXPECT().GetByUsername(ctx, "owner").Return(owner, true, nil)
+		m.userRepo.EXPECT().GetByUsername(ctx, "grantee").Return(grantee, true, nil)
+		m.shareRepo.EXPECT().PutShare(ctx, entity.ToolShareEntity{
+			OwnerID: owner.ID, ToolUniqueID: tool.UniqueID, GranteeID: grantee.ID, Permission: entity.ToolSharePermissionRead,
+		}).Return(entity.ToolShareEntity{ID: "share-1"}, nil)
+
+		_, err := svc.ShareWithUser(ctx, owner.ID, "tool-missing", "grantee", entity.ToolSharePermissionRead)
+		requireErrorCode(t, err, error_code.ToolNotFound)
+		_, err = svc.ShareWithUser(ctx, owner.ID, tool.UniqueID, "nobody", entity.ToolSharePermissionRead)
+		requireErrorCode(t, err, error_code.UserNotFound)
+		_, err = svc.ShareWithUser(ctx, owner.ID, tool.UniqueID, "owner", entity.ToolSharePermissionRead)
+		requireErrorCode(t, err, error_code.InvalidToolShare)
+		share, err := svc.ShareWithUser(ctx, owner.ID, tool.UniqueID, "grantee", entity.ToolSharePermissionRead)
+		require.NoError(t, err)
+		require.Equal(t, "share-1", share.ID)
+	})
+
+	t.Run("only the owner can revoke", func(t *testing.T) {
+		t.Parallel()
+		svc, m := newService(t)
+		ctx := context.Background()
+		share := entity.ToolShareEntity{ID: "share-1", OwnerID: owner.ID, ToolUniqueID: tool.UniqueID, GranteeID: grantee.ID}
+		m.shareRepo.EXPECT().GetShare(ctx, "share-1").Return(share, true, nil).Times(2)
+		m.shareRepo.EXPECT().DeleteShare(ctx, owner.ID, "share-1").Return(true, nil)
+
+		requireErrorCode(t, svc.RevokeShare(ctx, grantee.ID, tool.UniqueID, "share-1"), error_code.ToolShareNotFound)
+		require.NoError(t, svc.RevokeShare(ctx, owner.ID, tool.UniqueID, "share-1"))
+	})
+
+	t.Run("a share can only be opened by its grantee, a link by anyone", func(t *testing.T) {
+		t.Parallel()
+		svc, m := newService(t)
+		ctx := context.Background()
+		direct := entity.ToolShareEntity{ID: "share-direct", OwnerID: owner.ID, ToolUniqueID: tool.UniqueID, GranteeID: grantee.ID, Permission: entity.ToolSharePermissionRead}
+		link := entity.ToolShareEntity{ID: "share-link", OwnerID: owner.ID, ToolUniqueID: tool.UniqueID, Permission: entity.ToolSharePermissionRead}
+		m.shareRepo.EXPECT().GetShare(ctx, direct.ID).Return(direct, true, nil).AnyTimes()
+		m.shareRepo.EXPECT().GetShare(ctx, link.ID).Return(link, true, nil).AnyTimes()
+		m.userRepo.EXPECT().GetByID(ctx, owner.ID).Return(owner, true, nil).AnyTimes()
+		m.toolRepo.EXPECT().AllTools(owner.ID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{tool}}, nil).AnyTimes()
+
+		_, err := svc.GetSharedTool(ctx, "", direct.ID)
+		requireErrorCode(t, err, error_code.ToolShareNotFound)
+		_, err = svc.GetSharedTool(ctx, "someone-else", direct.ID)
+		requireErrorCode(t, err, error_code.ToolShareNotFound)
+		sharedTool, err := svc.GetSharedTool(ctx, grantee.ID, direct.ID)
+		require.NoError(t, err)
+		require.Equal(t, "owner", sharedTool.OwnerName)
+		require.Equal(t, tool.UniqueID, sharedTool.Tool.UniqueID)
+		_, err = svc.GetSharedTool(ctx, "", link.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("fork", func(t *testing.T) {
+		t.Parallel()
+		svc, m := newService(t)
+		ctx := context.Background()
+		readOnly := entity.ToolShareEntity{ID: "share-read", OwnerID: owner.ID, ToolUniqueID: tool.UniqueID, GranteeID: grantee.ID, Permission: entity.ToolSharePermissionRead}
+		forkable := entity.ToolShareEntity{ID: "share-fork", OwnerID: owner.ID, ToolUniqueID: tool.UniqueID, GranteeID: grantee.ID, Permission: entity.ToolSharePermissionFork}
+		m.shareRepo.EXPECT().GetShare(ctx, readOnly.ID).Return(readOnly, true, nil)
+		m.shareRepo.EXPECT().GetShare(ctx, forkable.ID).Return(forkable, true, nil)
+		m.userRepo.EXPECT().GetByID(ctx, owner.ID).Return(owner, true, nil).AnyTimes()
+		m.toolRepo.EXPECT().AllTools(owner.ID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{tool}}, nil).AnyTimes()
+		// the grantee already has tools with the id and the first fork suffix
+		m.toolRepo.EXPECT().AllTools(grantee.ID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{
+			fixtures.NewTestTool().WithID("json-format").Build(),
+			fixtures.NewTestTool().WithID("json-format-fork").Build(),
+		}}, nil).AnyTimes()
+		m.toolRepo.EXPECT().CreateTool(grantee.ID, gomock.Any(), gomock.Any()).Return(nil)
+
+		_, _, err := svc.ForkTool(ctx, grantee.ID, readOnly.ID)
+		requireErrorCode(t, err, error_code.ToolShareForkNotAllowed)
+		forked, _, err := svc.ForkTool(ctx, grantee.ID, forkable.ID)
+		require.NoError(t, err)
+		require.Equal(t, "json-format-fork-2", forked.ID)
+		require.NotEqual(t, tool.UniqueID, forked.UniqueID)
+		require.Equal(t, tool.Source, forked.Source)
+		require.Equal(t, time.Unix(100, 0).Unix(), forked.CreatedAt.Unix())
+	})
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// toolFullTextMaxTerms caps the words of a full text search, the words after it are ignored.
+const toolFullTextMaxTerms = 10
+
+// AddToolTag adds the tag to the tool of the user and returns it as stored, lower cased. Adding a tag the tool has
+// changes nothing, a new tag must fit the tag limits of the tags extra info.
+func (s *ToolService) AddToolTag(ctx context.Context, userID entity.UserIDEntity, toolUID string, tag string, eventSource entity.ToolEventSourceEntity) (string, error) {
+	tag, err := entity.NormalizeToolTag(tag)
+	if err != nil {
+		return "", error_code.NewErrorWithErrorCode(error_code.InvalidToolTag, err.Error())
+	}
+
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return "", errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	tool, ok := lo.Find(tools.Tools, func(tool entity.ToolEntity) bool { return tool.UniqueID == toolUID })
+	if !ok {
+		return "", error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "tool %s not found", toolUID)
+	}
+	tags := entity.ParseToolTags(tool.ExtraInfo[entity.ToolExtraInfoKeyTags])
+	if !lo.Contains(tags, tag) {
+		if err := entity.ValidateToolTags(entity.ToolTagsValue(append(tags, tag))); err != nil {
+			return "", error_code.NewErrorWithErrorCode(error_code.InvalidToolTag, err.Error())
+		}
+	}
+
+	if err := s.toolRepo.AddTag(userID, toolUID, tag, eventSource); err != nil {
+		return "", toolTagError(err, toolUID)
+	}
+	return tag, nil
+}
+
+// RemoveToolTag removes the tag from the tool of the user, removing a tag the tool does not have changes nothing.
+func (s *ToolService) RemoveToolTag(ctx context.Context, userID entity.UserIDEntity, toolUID string, tag string, eventSource entity.ToolEventSourceEntity) error {
+	tag, err := entity.NormalizeToolTag(tag)
+	if err != nil {
+		return error_code.NewErrorWithErrorCode(error_code.InvalidToolTag, err.Error())
+	}
+
+	if err := s.toolRepo.RemoveTag(userID, toolUID, tag, eventSource); err != nil {
+		return toolTagError(err, toolUID)
+	}
+	return nil
+}
+
+// ToolsByTag returns the tools of the user with the tag, the tag is matched case-insensitively.
+func (s *ToolService) ToolsByTag(ctx context.Context, userID entity.UserIDEntity, tag string) ([]entity.ToolEntity, error) {
+	tag, err := entity.NormalizeToolTag(tag)
+	if err != nil {
+		return nil, error_code.NewErrorWithErrorCode(error_code.InvalidToolTag, err.Error())
+	}
+
+	tools, err := s.toolRepo.SearchByTag(userID, tag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to get tools of user %s with tag %s", userID, tag)
+	}
+	return tools, nil
+}
+
+// FullTextSearchTools returns at most limit tools of the user with a word starting with each word of the query in
+// their name, description or source, the most relevant first. Words are runs of letters and digits, a query without
+// any finds nothing.
+func (s *ToolService) FullTextSearchTools(ctx context.Context, userID entity.UserIDEntity, query string, limit int) ([]entity.ToolEntity, error) {
+	terms := lo.Uniq(strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+	if len(terms) == 0 {
+		return []entity.ToolEntity{}, nil
+	}
+	if len(terms) > toolFullTextMaxTerms {
+		terms = terms[:toolFullTextMaxTerms]
+	}
+
+	tools, err := s.toolRepo.SearchFullText(userID, terms, limit)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to search tools of user %s", userID)
+	}
+	return tools, nil
+}
+
+func toolTagError(err error, toolUID string) error {
+	if errors.Is(err, repository.ErrToolNotFound) {
+		return error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "tool %s not found", toolUID)
+	}
+	return errors.Wrapf(err, "fail to change tags of tool %s", toolUID)
+}
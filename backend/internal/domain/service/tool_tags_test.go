@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+func TestToolService_AddToolTag(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	fullTags := make([]string, 20)
+	for i := range fullTags {
+		fullTags[i] = fmt.Sprintf("tag-%d", i)
+	}
+	tools := []entity.ToolEntity{
+		fixtures.NewTestTool().WithUniqueID("tool-1").WithExtraInfo(map[string]string{entity.ToolExtraInfoKeyTags: "json"}).Build(),
+		fixtures.NewTestTool().WithUniqueID("tool-full").WithExtraInfo(map[string]string{entity.ToolExtraInfoKeyTags: strings.Join(fullTags, ",")}).Build(),
+	}
+
+	tests := []struct {
+		name        string
+		toolUID     string
+		tag         string
+		wantTag     string
+		wantErrCode *error_code.ErrorCode
+	}{
+		{name: "tag is stored lower cased", toolUID: "tool-1", tag: " Text ", wantTag: "text"},
+		{name: "tag the tool has", toolUID: "tool-1", tag: "JSON", wantTag: "json"},
+		{name: "tag the full tool has", toolUID: "tool-full", tag: "tag-3", wantTag: "tag-3"},
+		{name: "one tag too many", toolUID: "tool-full", tag: "text", wantErrCode: &error_code.InvalidToolTag},
+		{name: "tag with a comma", toolUID: "tool-1", tag: "a,b", wantErrCode: &error_code.InvalidToolTag},
+		{name: "blank tag", toolUID: "tool-1", tag: " ", wantErrCode: &error_code.InvalidToolTag},
+		{name: "unknown tool", toolUID: "tool-2", tag: "text", wantErrCode: &error_code.ToolNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			toolRepo := mockgen.NewMockIToolRepository(gomock.NewController(t))
+			toolRepo.EXPECT().AllTools(entity.UserIDEntity("user-1")).Return(entity.ToolsEntity{Tools: tools}, nil).AnyTimes()
+			if tt.wantErrCode == nil {
+				toolRepo.EXPECT().AddTag(entity.UserIDEntity("user-1"), tt.toolUID, tt.wantTag, gomock.Any()).Return(nil)
+			}
+			svc := NewToolService(toolRepo, nil, nil, nil, nil, nil, nil, config.Config{})
+
+			tag, err := svc.AddToolTag(context.Background(), "user-1", tt.toolUID, tt.tag, entity.ToolEventSourceEntity{})
+			if tt.wantErrCode != nil {
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, tt.wantErrCode.Code, codeErr.ErrorCode.Code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantTag, tag)
+		})
+	}
+}
+
+func TestToolService_FullTextSearchTools(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	toolRepo := mockgen.NewMockIToolRepository(gomock.NewController(t))
+	found := []entity.ToolEntity{fixtures.NewTestTool().Build()}
+	toolRepo.EXPECT().SearchFullText(entity.UserIDEntity("user-1"), []string{"json", "pretty", "print", "v2"}, 5).Return(found, nil)
+	svc := NewToolService(toolRepo, nil, nil, nil, nil, nil, nil, config.Config{})
+
+	// the query is split into words, punctuation and repeated words are dropped
+	tools, err := svc.FullTextSearchTools(context.Background(), "user-1", `JSON "pretty-print" json? v2`, 5)
+	require.NoError(t, err)
+	require.Equal(t, found, tools)
+
+	// a query without words does not search
+	tools, err = svc.FullTextSearchTools(context.Background(), "user-1", ` "-" `, 5)
+	require.NoError(t, err)
+	require.Empty(t, tools)
+}
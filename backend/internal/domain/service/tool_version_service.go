@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+func NewToolVersionService(toolRepo repository.IToolRepository, toolVersionRepo repository.IToolVersionRepository) *ToolVersionService {
+	return &ToolVersionService{
+		toolRepo:        toolRepo,
+		toolVersionRepo: toolVersionRepo,
+	}
+}
+
+// ToolVersionService reads the version history of a tool and rolls a tool back to one of its versions.
+// The versions are recorded by the tool repository whenever the source or the ui widgets of a tool change.
+type ToolVersionService struct {
+	toolRepo        repository.IToolRepository
+	toolVersionRepo repository.IToolVersionRepository
+}
+
+// ListVersions returns the versions of the tool newest first, without their source and ui widgets.
+func (s *ToolVersionService) ListVersions(ctx context.Context, userID entity.UserIDEntity, toolUID string) ([]entity.ToolVersionEntity, error) {
+	if _, err := s.findTool(userID, toolUID); err != nil {
+		return nil, err
+	}
+	versions, err := s.toolVersionRepo.ListVersions(ctx, userID, toolUID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to list versions of tool %s", toolUID)
+	}
+	return versions, nil
+}
+
+// DiffVersions returns the lines of the source and the ui widgets that changed from one version of the tool to another.
+func (s *ToolVersionService) DiffVersions(ctx context.Context, userID entity.UserIDEntity, toolUID string, fromVersion, toVersion int) (entity.ToolVersionDiffEntity, error) {
+	from, err := s.getVersion(ctx, userID, toolUID, fromVersion)
+	if err != nil {
+		return entity.ToolVersionDiffEntity{}, err
+	}
+	to, err := s.getVersion(ctx, userID, toolUID, toVersion)
+	if err != nil {
+		return entity.ToolVersionDiffEntity{}, err
+	}
+
+	return entity.ToolVersionDiffEntity{
+		ToolUniqueID: toolUID,
+		FromVersion:  fromVersion,
+		ToVersion:    toVersion,
+		Source:       diffLines(from.Source, to.Source),
+		UiWidgets:    diffLines(from.UiWidgets, to.UiWidgets),
+	}, nil
+}
+
+// RestoreVersion sets the source and the ui widgets of the tool back to the ones of the version, the other fields
+// of the tool are kept. The restored state is recorded as a new version, so the versions after it stay available.
+func (s *ToolVersionService) RestoreVersion(ctx context.Context, userID entity.UserIDEntity, toolUID string, version int) (entity.ToolEntity, error) {
+	tool, err := s.findTool(userID, toolUID)
+	if err != nil {
+		return entity.ToolEntity{}, err
+	}
+	restored, err := s.getVersion(ctx, userID, toolUID, version)
+	if err != nil {
+		return entity.ToolEntity{}, err
+	}
+
+	tool.Source = restored.Source
+	tool.UiWidgets = restored.UiWidgets
+	tool.SourceHash = restored.SourceHash
+	if err := s.toolRepo.UpdateTool(userID, tool, ToolEventSourceFromContext(ctx, userID)); err != nil {
+		return entity.ToolEntity{}, errors.Wrapf(err, "fail to restore version %d of tool %s", version, toolUID)
+	}
+	return tool, nil
+}
+
+func (s *ToolVersionService) findTool(userID entity.UserIDEntity, toolUID string) (entity.ToolEntity, error) {
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return entity.ToolEntity{}, errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	tool, ok := lo.Find(tools.Tools, func(tool entity.ToolEntity) bool { return tool.UniqueID == toolUID })
+	if !ok {
+		return entity.ToolEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolNotFound, "tool %s not found", toolUID)
+	}
+	return tool, nil
+}
+
+func (s *ToolVersionService) getVersion(ctx context.Context, userID entity.UserIDEntity, toolUID string, version int) (entity.ToolVersionEntity, error) {
+	toolVersion, exists, err := s.toolVersionRepo.GetVersion(ctx, userID, toolUID, version)
+	if err != nil {
+		return entity.ToolVersionEntity{}, errors.Wrapf(err, "fail to get version %d of tool %s", version, toolUID)
+	}
+	if !exists {
+		return entity.ToolVersionEntity{}, error_code.NewErrorWithErrorCodef(error_code.ToolVersionNotFound, "version %d of tool %s not found", version, toolUID)
+	}
+	return toolVersion, nil
+}
+
+// diffLines returns the lines removed from and added to the older text along their longest common subsequence.
+func diffLines(older, newer string) []entity.ToolVersionDiffLineEntity {
+	a, b := splitDiffLines(older), splitDiffLines(newer)
+	match := matchLines(a, b)
+
+	changes := []entity.ToolVersionDiffLineEntity{}
+	ia, ib := 0, 0
+	for ia < len(a) || ib < len(b) {
+		switch {
+		case ia < len(a) && match[ia] == ib:
+			ia++
+			ib++
+		case ia < len(a) && match[ia] < 0:
+			changes = append(changes, entity.ToolVersionDiffLineEntity{
+				Kind: entity.ToolVersionDiffLineRemoved, FromLine: ia + 1, ToLine: ib + 1, Text: a[ia],
+			})
+			ia++
+		default:
+			changes = append(changes, entity.ToolVersionDiffLineEntity{
+				Kind: entity.ToolVersionDiffLineAdded, FromLine: ia + 1, ToLine: ib + 1, Text: b[ib],
+			})
+			ib++
+		}
+	}
+	return changes
+}
+
+// splitDiffLines splits a text into lines, an empty text has none
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
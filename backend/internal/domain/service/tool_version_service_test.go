@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+)
+
+func TestDiffLines(t *testing.T) {
+	t.Parallel()
+
+	removed := func(from, to int, text string) entity.ToolVersionDiffLineEntity {
+		return entity.ToolVersionDiffLineEntity{Kind: entity.ToolVersionDiffLineRemoved, FromLine: from, ToLine: to, Text: text}
+	}
+	added := func(from, to int, text string) entity.ToolVersionDiffLineEntity {
+		return entity.ToolVersionDiffLineEntity{Kind: entity.ToolVersionDiffLineAdded, FromLine: from, ToLine: to, Text: text}
+	}
+
+	tests := []struct {
+		name  string
+		older string
+		newer string
+		want  []entity.ToolVersionDiffLineEntity
+	}{
+		{name: "same text", older: "a\nb", newer: "a\nb", want: []entity.ToolVersionDiffLineEntity{}},
+		{name: "changed line", older: "a\nb\nc", newer: "a\nB\nc", want: []entity.ToolVersionDiffLineEntity{removed(2, 2, "b"), added(3, 2, "B")}},
+		{name: "added and removed lines", older: "a\nb\nc", newer: "b\nc\nd", want: []entity.ToolVersionDiffLineEntity{removed(1, 1, "a"), added(4, 3, "d")}},
+		{name: "from empty", older: "", newer: "a", want: []entity.ToolVersionDiffLineEntity{added(1, 1, "a")}},
+		{name: "to empty", older: "a", newer: "", want: []entity.ToolVersionDiffLineEntity{removed(1, 1, "a")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, diffLines(tt.older, tt.newer))
+		})
+	}
+}
+
+func TestToolVersionService(t *testing.T) {
+	t.Parallel()
+
+	userID := entity.UserIDEntity("user-1")
+	current := entity.ToolEntity{UniqueID: "uid-1", Name: "renamed", Source: "a\nc", SourceHash: entity.ToolSourceHash("a\nc"), UiWidgets: "[]"}
+	first := entity.ToolVersionEntity{ToolUniqueID: "uid-1", Version: 1, Source: "a\nb", SourceHash: entity.ToolSourceHash("a\nb"), UiWidgets: "[]"}
+
+	newService := func(t *testing.T) (*ToolVersionService, *mockgen.MockIToolRepository, *mockgen.MockIToolVersionRepository) {
+		ctrl := gomock.NewController(t)
+		toolRepo := mockgen.NewMockIToolRepository(ctrl)
+		toolVersionRepo := mockgen.NewMockIToolVersionRepository(ctrl)
+		toolRepo.EXPECT().AllTools(userID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{current}}, nil).AnyTimes()
+		toolVersionRepo.EXPECT().GetVersion(gomock.Any(), userID, "uid-1", 1).Return(first, true, nil).AnyTimes()
+		toolVersionRepo.EXPECT().GetVersion(gomock.Any(), userID, "uid-1", 2).Return(entity.ToolVersionEntity{
+			ToolUniqueID: "uid-1", Version: 2, Source: current.Source, SourceHash: current.SourceHash, UiWidgets: current.UiWidgets,
+		}, true, nil).AnyTimes()
+		toolVersionRepo.EXPECT().GetVersion(gomock.Any(), userID, "uid-1", gomock.Any()).Return(entity.ToolVersionEntity{}, false, nil).AnyTimes()
+		return NewToolVersionService(toolRepo, toolVersionRepo), toolRepo, toolVersionRepo
+	}
+	requireErrorCode := func(t *testing.T, err error, code error_code.ErrorCode) {
+		t.Helper()
+		var ecErr error_code.ErrorWithErrorCode
+		require.True(t, errors.As(err, &ecErr))
+		require.Equal(t, code.Code, ecErr.ErrorCode.Code)
+	}
+
+	t.Run("list needs the tool", func(t *testing.T) {
+		t.Parallel()
+		svc, _, _ := newService(t)
+		_, err := svc.ListVersions(context.Background(), userID, "missing")
+		requireErrorCode(t, err, error_code.ToolNotFound)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		t.Parallel()
+		svc, _, _ := newService(t)
+		diff, err := svc.DiffVersions(context.Background(), userID, "uid-1", 1, 2)
+		require.NoError(t, err)
+		require.Equal(t, []entity.ToolVersionDiffLineEntity{
+			{Kind: entity.ToolVersionDiffLineRemoved, FromLine: 2, ToLine: 2, Text: "b"},
+			{Kind: entity.ToolVersionDiffLineAdded, FromLine: 3, ToLine: 2, Text: "c"},
+		}, diff.Source)
+		require.Empty(t, diff.UiWidgets)
+
+		_, err = svc.DiffVersions(context.Background(), userID, "uid-1", 1, 7)
+		requireErrorCode(t, err, error_code.ToolVersionNotFound)
+	})
+
+	t.Run("restore keeps the other fields", func(t *testing.T) {
+		t.Parallel()
+		svc, toolRepo, _ := newService(t)
+		want := current
+		want.Source = first.Source
+		want.SourceHash = first.SourceHash
+		toolRepo.EXPECT().UpdateTool(userID, want, gomock.Any()).Return(nil)
+
+		restored, err := svc.RestoreVersion(context.Background(), userID, "uid-1", 1)
+		require.NoError(t, err)
+		require.Equal(t, want, restored)
+
+		_, err = svc.RestoreVersion(context.Background(), userID, "uid-1", 7)
+		requireErrorCode(t, err, error_code.ToolVersionNotFound)
+	})
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/scheduler"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	UserDataExportJobBuildExports = "user_data_exports"
+
+	// userDataExportBatch is how many waiting exports one pass of the job reads at a time
+	userDataExportBatch = 10
+	// userDataExportStaleAfter is how long a running export may go without progress before another run takes it over,
+	// its instance is assumed to have stopped
+	userDataExportStaleAfter = 10 * time.Minute
+)
+
+// userDataExportStep adds one part of the user's data to the archive
+type userDataExportStep func(ctx context.Context, userID entity.UserIDEntity, archive *entity.UserDataArchiveEntity) error
+
+func NewUserDataExportService(
+	exportRepo repository.IUserDataExportRepository,
+	userRepo repository.IUserRepository,
+	toolRepo repository.IToolRepository,
+	globalScriptRepo repository.IGlobalScriptRepository,
+	passkeyRepo repository.IPasskeyRepository,
+	auditLogRepo repository.IAuditLogRepository,
+	scheduler *scheduler.Scheduler,
+	clock domain_client.IClock,
+	cfg config.Config,
+) *UserDataExportService {
+	return &UserDataExportService{
+		exportRepo:       exportRepo,
+		userRepo:         userRepo,
+		toolRepo:         toolRepo,
+		globalScriptRepo: globalScriptRepo,
+		passkeyRepo:      passkeyRepo,
+		auditLogRepo:     auditLogRepo,
+		scheduler:        scheduler,
+		clock:            clock,
+		config:           cfg,
+	}
+}
+
+// UserDataExportService builds archives of everything kept about a user in the background: the profile, the SSO
+// bindings, the tools, the global script, the passkeys and the audit records. A request is stored and picked up by
+// the export job, the user polls the export for its progress and downloads the archive once it is done.
+type UserDataExportService struct {
+	exportRepo       repository.IUserDataExportRepository
+	userRepo         repository.IUserRepository
+	toolRepo         repository.IToolRepository
+	globalScriptRepo repository.IGlobalScriptRepository
+	passkeyRepo      repository.IPasskeyRepository
+	auditLogRepo     repository.IAuditLogRepository
+	scheduler        *scheduler.Scheduler
+	clock            domain_client.IClock
+	config           config.Config
+}
+
+// Jobs returns the job building the requested exports. A request starts it right away, the schedule picks up the
+// requests it missed and the exports of stopped instances.
+func (s *UserDataExportService) Jobs() []scheduler.Job {
+	return []scheduler.Job{
+		{
+			Name:        UserDataExportJobBuildExports,
+			Description: "Builds the data exports users requested and deletes the archives past USER_DATA_EXPORT_RETENTION_HOURS",
+			Spec: func(ctx context.Context) (string, error) {
+				return "@every 1m", nil
+			},
+			Run: s.BuildPendingExports,
+		},
+	}
+}
+
+// RequestExport stores a request for an archive of the user's data and starts building it. A user has one export in
+// progress at a time.
+func (s *UserDataExportService) RequestExport(ctx context.Context, userID entity.UserIDEntity) (entity.UserDataExportEntity, error) {
+	active, found, err := s.exportRepo.ActiveExport(ctx, userID)
+	if err != nil {
+		return entity.UserDataExportEntity{}, errors.Wrapf(err, "fail to get active data export of user %s", userID)
+	}
+	if found {
+		return entity.UserDataExportEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserDataExportInProgress, "data export %s is in progress", active.ID)
+	}
+
+	export := entity.NewUserDataExportEntityWithoutID(userID, len(s.steps()))
+	now := s.clock.Now().UTC()
+	export.CreatedAt = now
+	export.UpdatedAt = now
+	if err := s.exportRepo.CreateExport(ctx, export); err != nil {
+		return entity.UserDataExportEntity{}, errors.Wrap(err, "fail to create data export")
+	}
+
+	// a job already running picks the export up before it finishes
+	if err := s.scheduler.Trigger(ctx, UserDataExportJobBuildExports); err != nil {
+		logger.Infof(ctx, "Data export %s waits for the export job: %v", export.ID, err)
+	}
+	return export, nil
+}
+
+func (s *UserDataExportService) GetExport(ctx context.Context, userID entity.UserIDEntity, id string) (entity.UserDataExportEntity, error) {
+	export, found, err := s.exportRepo.GetExport(ctx, userID, id)
+	if err != nil {
+		return entity.UserDataExportEntity{}, errors.Wrapf(err, "fail to get data export %s", id)
+	}
+	if !found {
+		return entity.UserDataExportEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserDataExportNotFound, "data export %s not found", id)
+	}
+	return export, nil
+}
+
+// DownloadExport returns the archive of a done export of the user.
+func (s *UserDataExportService) DownloadExport(ctx context.Context, userID entity.UserIDEntity, id string) (entity.UserDataArchiveEntity, error) {
+	export, err := s.GetExport(ctx, userID, id)
+	if err != nil {
+		return entity.UserDataArchiveEntity{}, err
+	}
+	if export.Status != entity.UserDataExportStatusDone {
+		return entity.UserDataArchiveEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserDataExportNotReady, "data export %s is %s", id, export.Status)
+	}
+
+	archive, found, err := s.exportRepo.GetArchive(ctx, userID, id)
+	if err != nil {
+		return entity.UserDataArchiveEntity{}, errors.Wrapf(err, "fail to get archive of data export %s", id)
+	}
+	if !found {
+		return entity.UserDataArchiveEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserDataExportNotFound, "data export %s not found", id)
+	}
+	return archive, nil
+}
+
+// BuildPendingExports deletes the expired exports, then builds the waiting ones until none is left. An export that
+// fails is marked failed and the others are still built.
+func (s *UserDataExportService) BuildPendingExports(ctx context.Context) error {
+	now := s.clock.Now().UTC()
+	retention := time.Duration(s.config.UserDataExportRetentionHours) * time.Hour
+	deleted, err := s.exportRepo.DeleteFinishedBefore(ctx, now.Add(-retention))
+	if err != nil {
+		return errors.Wrap(err, "fail to delete expired data exports")
+	}
+	if deleted > 0 {
+		logger.Infof(ctx, "Deleted %d expired data exports", deleted)
+	}
+
+	for {
+		staleBefore := s.clock.Now().UTC().Add(-userDataExportStaleAfter)
+		exports, err := s.exportRepo.PendingExports(ctx, staleBefore, userDataExportBatch)
+		if err != nil {
+			return errors.Wrap(err, "fail to get pending data exports")
+		}
+		if len(exports) == 0 {
+			return nil
+		}
+
+		for _, export := range exports {
+			claimed, err := s.exportRepo.ClaimExport(ctx, export.ID, staleBefore, s.clock.Now().UTC())
+			if err != nil {
+				return errors.Wrapf(err, "fail to claim data export %s", export.ID)
+			}
+			if !claimed {
+				continue
+			}
+			if err := s.buildExport(ctx, export); err != nil {
+				logger.Errorf(ctx, "Data export %s of user %s failed: %v", export.ID, export.UserID, err)
+				if err := s.exportRepo.FailExport(ctx, export.ID, err.Error(), s.clock.Now().UTC()); err != nil {
+					return errors.Wrapf(err, "fail to mark data export %s failed", export.ID)
+				}
+			}
+		}
+	}
+}
+
+// buildExport runs the steps of the archive one after another, recording the progress after each.
+func (s *UserDataExportService) buildExport(ctx context.Context, export entity.UserDataExportEntity) error {
+	archive := entity.UserDataArchiveEntity{ExportedAt: s.clock.Now().UTC()}
+	for i, step := range s.steps() {
+		if err := step(ctx, export.UserID, &archive); err != nil {
+			return err
+		}
+		if err := s.exportRepo.UpdateProgress(ctx, export.ID, i+1, s.clock.Now().UTC()); err != nil {
+			return errors.Wrap(err, "fail to record data export progress")
+		}
+	}
+
+	if err := s.exportRepo.FinishExport(ctx, export.ID, archive, s.clock.Now().UTC()); err != nil {
+		return errors.Wrap(err, "fail to store data export archive")
+	}
+	logger.Infof(ctx, "Data export %s of user %s is done with %d tools", export.ID, export.UserID, len(archive.Tools))
+	return nil
+}
+
+func (s *UserDataExportService) steps() []userDataExportStep {
+	return []userDataExportStep{
+		s.exportProfile,
+		s.exportSSOBindings,
+		s.exportTools,
+		s.exportGlobalScript,
+		s.exportPasskeys,
+		s.exportAuditLogs,
+	}
+}
+
+func (s *UserDataExportService) exportProfile(ctx context.Context, userID entity.UserIDEntity, archive *entity.UserDataArchiveEntity) error {
+	user, found, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get user %s", userID)
+	}
+	if !found {
+		return errors.Errorf("user %s not found", userID)
+	}
+	archive.Profile = entity.UserDataProfileEntity{
+		ID:          user.ID,
+		Name:        user.Name,
+		Mail:        user.Mail,
+		Roles:       lo.Map(user.Roles, func(role entity.UserRoleEntity, _ int) string { return role.RoleName }),
+		HasPassword: user.PasswordHash != nil,
+		LastLoginAt: user.LastLoginAt,
+		LoginCount:  user.LoginCount,
+	}
+	return nil
+}
+
+func (s *UserDataExportService) exportSSOBindings(ctx context.Context, userID entity.UserIDEntity, archive *entity.UserDataArchiveEntity) error {
+	bindings, err := s.userRepo.GetUserSSOBindings(ctx, userID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get sso bindings of user %s", userID)
+	}
+	archive.SSOBindings = bindings
+	return nil
+}
+
+func (s *UserDataExportService) exportTools(ctx context.Context, userID entity.UserIDEntity, archive *entity.UserDataArchiveEntity) error {
+	tools, err := s.toolRepo.AllTools(userID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get tools of user %s", userID)
+	}
+	archive.Tools = tools.Tools
+	return nil
+}
+
+func (s *UserDataExportService) exportGlobalScript(ctx context.Context, userID entity.UserIDEntity, archive *entity.UserDataArchiveEntity) error {
+	globalScript, err := s.globalScriptRepo.GetGlobalScript(userID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get global script of user %s", userID)
+	}
+	if globalScript != nil {
+		archive.GlobalScript = &globalScript.Script
+	}
+	return nil
+}
+
+func (s *UserDataExportService) exportPasskeys(ctx context.Context, userID entity.UserIDEntity, archive *entity.UserDataArchiveEntity) error {
+	passkeys, err := s.passkeyRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return errors.Wrapf(err, "fail to get passkeys of user %s", userID)
+	}
+	archive.Passkeys = lo.Map(passkeys, func(passkey entity.PasskeyEntity, _ int) entity.UserDataPasskeyEntity {
+		return entity.UserDataPasskeyEntity{
+			ID:             passkey.ID,
+			DeviceName:     passkey.DeviceName,
+			AAGUID:         hex.EncodeToString(passkey.AAGUID),
+			Transports:     passkey.Transports,
+			BackupEligible: passkey.BackupEligible,
+			BackupState:    passkey.BackupState,
+			CreatedAt:      passkey.CreatedAt,
+			LastUsedAt:     passkey.LastUsedAt,
+		}
+	})
+	return nil
+}
+
+func (s *UserDataExportService) exportAuditLogs(ctx context.Context, userID entity.UserIDEntity, archive *entity.UserDataArchiveEntity) error {
+	auditLogs, _, err := s.auditLogRepo.List(ctx, entity.AuditLogFilter{ActorID: userID})
+	if err != nil {
+		return errors.Wrapf(err, "fail to get audit logs of user %s", userID)
+	}
+	archive.AuditLogs = auditLogs
+	return nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/scheduler"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+type userDataExportMocks struct {
+	exportRepo       *mockgen.MockIUserDataExportRepository
+	userRepo         *mockgen.MockIUserRepository
+	toolRepo         *mockgen.MockIToolRepository
+	globalScriptRepo *mockgen.MockIGlobalScriptRepository
+	passkeyRepo      *mockgen.MockIPasskeyRepository
+	auditLogRepo     *mockgen.MockIAuditLogRepository
+}
+
+func newTestUserDataExportService(t *testing.T, clock *fixtures.FakeClock) (*UserDataExportService, userDataExportMocks) {
+	ctrl := gomock.NewController(t)
+	mocks := userDataExportMocks{
+		exportRepo:       mockgen.NewMockIUserDataExportRepository(ctrl),
+		userRepo:         mockgen.NewMockIUserRepository(ctrl),
+		toolRepo:         mockgen.NewMockIToolRepository(ctrl),
+		globalScriptRepo: mockgen.NewMockIGlobalScriptRepository(ctrl),
+		passkeyRepo:      mockgen.NewMockIPasskeyRepository(ctrl),
+		auditLogRepo:     mockgen.NewMockIAuditLogRepository(ctrl),
+	}
+	service := NewUserDataExportService(
+		mocks.exportRepo,
+		mocks.userRepo,
+		mocks.toolRepo,
+		mocks.globalScriptRepo,
+		mocks.passkeyRepo,
+		mocks.auditLogRepo,
+		scheduler.NewScheduler(clock),
+		clock,
+		config.Config{UserDataExportRetentionHours: 24},
+	)
+	return service, mocks
+}
+
+func TestUserDataExportService_RequestExport(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+	ctx := context.Background()
+
+	t.Run("one export in progress at a time", func(t *testing.T) {
+		service, mocks := newTestUserDataExportService(t, fixtures.NewFakeClock(time.Unix(1000, 0).UTC()))
+		mocks.exportRepo.EXPECT().ActiveExport(ctx, userID).Return(entity.UserDataExportEntity{ID: "export-1"}, true, nil)
+
+		_, err := service.RequestExport(ctx, userID)
+		var ecErr error_code.ErrorWithErrorCode
+		require.True(t, errors.As(err, &ecErr))
+		require.Equal(t, error_code.UserDataExportInProgress.Code, ecErr.ErrorCode.Code)
+	})
+
+	t.Run("stores a pending export", func(t *testing.T) {
+		service, mocks := newTestUserDataExportService(t, fixtures.NewFakeClock(time.Unix(1000, 0).UTC()))
+		mocks.exportRepo.EXPECT().ActiveExport(ctx, userID).Return(entity.UserDataExportEntity{}, false, nil)
+		mocks.exportRepo.EXPECT().CreateExport(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, export entity.UserDataExportEntity) error {
+			require.Equal(t, entity.UserDataExportStatusPending, export.Status)
+			require.Equal(t, 6, export.Total)
+			return nil
+		})
+
+		export, err := service.RequestExport(ctx, userID)
+		require.NoError(t, err)
+		require.Equal(t, userID, export.UserID)
+		require.True(t, export.CreatedAt.Equal(time.Unix(1000, 0)))
+	})
+}
+
+func TestUserDataExportService_BuildPendingExports(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	ctx := context.Background()
+	now := time.Unix(100000, 0).UTC()
+	staleBefore := now.Add(-userDataExportStaleAfter)
+	passwordHash := "hash"
+	script := "globalThis.x = 1"
+	done := entity.NewUserDataExportEntityWithoutID("user-1", 6)
+	failing := entity.NewUserDataExportEntityWithoutID("user-2", 6)
+
+	service, mocks := newTestUserDataExportService(t, fixtures.NewFakeClock(now))
+	mocks.exportRepo.EXPECT().DeleteFinishedBefore(ctx, now.Add(-24*time.Hour)).Return(0, nil)
+	gomock.InOrder(
+		mocks.exportRepo.EXPECT().PendingExports(ctx, staleBefore, userDataExportBatch).Return([]entity.UserDataExportEntity{done, failing}, nil),
+		mocks.exportRepo.EXPECT().PendingExports(ctx, staleBefore, userDataExportBatch).Return(nil, nil),
+	)
+	mocks.exportRepo.EXPECT().ClaimExport(ctx, done.ID, staleBefore, now).Return(true, nil)
+	mocks.exportRepo.EXPECT().ClaimExport(ctx, failing.ID, staleBefore, now).Return(true, nil)
+
+	// the first export is built step by step, the secrets of the user stay out of the archive
+	mocks.userRepo.EXPECT().GetByID(ctx, done.UserID).Return(entity.UserEntity{
+		ID: done.UserID, Name: "alice", PasswordHash: &passwordHash, EncrypKey: "encry-key-1",
+		Roles: []entity.UserRoleEntity{entity.UserRoleUser},
+	}, true, nil)
+	mocks.userRepo.EXPECT().GetUserSSOBindings(ctx, done.UserID).Return([]entity.UserSSOEntity{{Provider: "github"}}, nil)
+	mocks.toolRepo.EXPECT().AllTools(done.UserID).Return(entity.ToolsEntity{Tools: []entity.ToolEntity{fixtures.NewTestTool().Build()}}, nil)
+	mocks.globalScriptRepo.EXPECT().GetGlobalScript(done.UserID).Return(&entity.GlobalScriptEntity{Script: script}, nil)
+	mocks.passkeyRepo.EXPECT().GetByUserID(ctx, done.UserID).Return([]entity.PasskeyEntity{{ID: 1, PublicKey: []byte("public"), AAGUID: []byte{0xab}}}, nil)
+	mocks.auditLogRepo.EXPECT().List(ctx, entity.AuditLogFilter{ActorID: done.UserID}).Return([]entity.AuditLogEntity{{ID: "audit-1"}}, 1, nil)
+	for progress := 1; progress <= 6; progress++ {
+		mocks.exportRepo.EXPECT().UpdateProgress(ctx, done.ID, progress, now).Return(nil)
+	}
+	mocks.exportRepo.EXPECT().FinishExport(ctx, done.ID, gomock.Any(), now).DoAndReturn(
+		func(_ context.Context, _ string, archive entity.UserDataArchiveEntity, _ time.Time) error {
+			require.Equal(t, "alice", archive.Profile.Name)
+			require.True(t, archive.Profile.HasPassword)
+			require.Equal(t, []string{entity.UserRoleUser.RoleName}, archive.Profile.Roles)
+			require.Len(t, archive.SSOBindings, 1)
+			require.Len(t, archive.Tools, 1)
+			require.Equal(t, script, *archive.GlobalScript)
+			require.Equal(t, "ab", archive.Passkeys[0].AAGUID)
+			require.Len(t, archive.AuditLogs, 1)
+			return nil
+		})
+
+	// the second fails on its first step and the job goes on
+	mocks.userRepo.EXPECT().GetByID(ctx, failing.UserID).Return(entity.UserEntity{}, false, errors.New("database is gone"))
+	mocks.exportRepo.EXPECT().FailExport(ctx, failing.ID, gomock.Any(), now).Return(nil)
+
+	require.NoError(t, service.BuildPendingExports(ctx))
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	userEmailCodeKeyPrefix     = "user_email_code:"
+	userEmailThrottleKeyPrefix = "user_email_throttle:"
+	userEmailCodeTTL           = 900 // 15 minutes
+	// userEmailResendInterval is the seconds between two codes sent for the same user
+	userEmailResendInterval = 60
+	// userEmailMaxCodeAttempts wrong codes drop the pending code, a new one has to be requested
+	userEmailMaxCodeAttempts = 5
+)
+
+func NewUserEmailService(
+	userRepo repository.IUserRepository,
+	cacheRepo repository.ICache,
+	mailer domain_client.IMailer,
+	clock domain_client.IClock,
+	cfg config.Config,
+) *UserEmailService {
+	return &UserEmailService{
+		userRepo:  userRepo,
+		cacheRepo: cacheRepo,
+		mailer:    mailer,
+		clock:     clock,
+		config:    cfg,
+	}
+}
+
+// UserEmailService sets the email of a user once the user proves to own it with a code sent to it. The email is
+// then used to log in, to reset the password and to recover the account.
+type UserEmailService struct {
+	userRepo  repository.IUserRepository
+	cacheRepo repository.ICache
+	mailer    domain_client.IMailer
+	clock     domain_client.IClock
+	config    config.Config
+}
+
+// userEmailCode is the pending email of a user and the proof it is owned, kept in the cache
+type userEmailCode struct {
+	Email     string `json:"email"`
+	CodeHash  string `json:"code_hash"`
+	Attempts  int    `json:"attempts"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// StartVerification emails a code to the new email of the user and drops the code sent before. The email of the
+// user does not change until the code is verified.
+func (s *UserEmailService) StartVerification(ctx context.Context, userID entity.UserIDEntity, email string) error {
+	if s.config.Mailer == "none" {
+		return error_code.NewErrorWithErrorCodef(error_code.EmailVerificationUnavailable, "email verification needs a mailer, MAILER is none")
+	}
+
+	email = normalizeUserEmail(email)
+	user, err := s.existingUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkEmailFree(ctx, userID, email); err != nil {
+		return err
+	}
+
+	throttleKey := userEmailThrottleKeyPrefix + string(userID)
+	throttled, err := s.cacheRepo.Has(ctx, throttleKey)
+	if err != nil {
+		return errors.Wrap(err, "fail to check email verification throttle")
+	}
+	if throttled {
+		return error_code.NewErrorWithErrorCodef(error_code.EmailVerificationThrottled,
+			"an email verification code was sent to user %s less than %d seconds ago", userID, userEmailResendInterval)
+	}
+	if err := s.cacheRepo.SetWithTTL(ctx, throttleKey, "1", userEmailResendInterval); err != nil {
+		return errors.Wrap(err, "fail to set email verification throttle")
+	}
+
+	code, err := generateAccountRecoveryCode()
+	if err != nil {
+		return err
+	}
+	pending, err := json.Marshal(userEmailCode{
+		Email:     email,
+		CodeHash:  hashAccountRecoverySecret(code),
+		ExpiresAt: s.clock.Now().Add(userEmailCodeTTL * time.Second).Unix(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "fail to marshal email verification code")
+	}
+	if err := s.cacheRepo.SetWithTTL(ctx, userEmailCodeKeyPrefix+string(userID), string(pending), userEmailCodeTTL); err != nil {
+		return errors.Wrap(err, "fail to store email verification code")
+	}
+
+	mail := entity.MailEntity{To: email, Subject: "Verify your email", Body: fmt.Sprintf(
+		"Someone asked to use this email for the account %s.\n\n"+
+			"The verification code is %s, it expires in %d minutes.\n\n"+
+			"If you did not ask for it, ignore this email: it is not used without the code.\n",
+		user.Name, code, userEmailCodeTTL/60)}
+	if err := s.mailer.Send(ctx, mail); err != nil {
+		return errors.Wrapf(err, "fail to send email verification code to user %s", userID)
+	}
+	logger.Infof(ctx, "Email verification code sent for user %s", userID)
+	return nil
+}
+
+// Verify checks the emailed code and sets the pending email as the email of the user. The email the user had before
+// is told about the change.
+func (s *UserEmailService) Verify(ctx context.Context, userID entity.UserIDEntity, code string) (string, error) {
+	email, err := s.checkCode(ctx, userID, code)
+	if err != nil {
+		return "", err
+	}
+	user, err := s.existingUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	// the email may have been taken since the code was sent
+	if err := s.checkEmailFree(ctx, userID, email); err != nil {
+		return "", err
+	}
+
+	previous := user.Mail
+	user.Mail = &email
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return "", errors.Wrap(err, "fail to update email of the user")
+	}
+
+	if previous != nil && *previous != "" && *previous != email {
+		s.notify(ctx, *previous, "The email of your account was changed", fmt.Sprintf(
+			"The email of the account %s was changed to %s, this address is no longer used for it.\n\n"+
+				"If you did not do it, contact an administrator right away.\n", user.Name, email))
+	}
+	logger.Infof(ctx, "Email of user %s verified", userID)
+	return email, nil
+}
+
+// Remove clears the email of the user, the account can no longer log in with it, reset its password or be recovered.
+func (s *UserEmailService) Remove(ctx context.Context, userID entity.UserIDEntity) error {
+	user, err := s.existingUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Mail == nil {
+		return nil
+	}
+
+	previous := *user.Mail
+	user.Mail = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return errors.Wrap(err, "fail to remove email of the user")
+	}
+	if err := s.cacheRepo.Delete(ctx, userEmailCodeKeyPrefix+string(userID)); err != nil {
+		return errors.Wrap(err, "fail to delete email verification code")
+	}
+
+	s.notify(ctx, previous, "The email of your account was removed", fmt.Sprintf(
+		"This address is no longer the email of the account %s.\n\n"+
+			"If you did not do it, contact an administrator right away.\n", user.Name))
+	logger.Infof(ctx, "Email of user %s removed", userID)
+	return nil
+}
+
+// checkCode returns the pending email of the user, a wrong code counts as an attempt
+func (s *UserEmailService) checkCode(ctx context.Context, userID entity.UserIDEntity, code string) (string, error) {
+	key := userEmailCodeKeyPrefix + string(userID)
+	invalid := error_code.NewErrorWithErrorCodef(error_code.InvalidEmailVerificationCode, "email verification code is expired or invalid")
+
+	raw, exists, err := s.cacheRepo.Get(ctx, key)
+	if err != nil {
+		return "", errors.Wrap(err, "fail to get email verification code")
+	}
+	if !exists {
+		return "", invalid
+	}
+	var pending userEmailCode
+	if err := json.Unmarshal([]byte(raw), &pending); err != nil {
+		return "", errors.Wrap(err, "fail to unmarshal email verification code")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAccountRecoverySecret(strings.TrimSpace(code))), []byte(pending.CodeHash)) == 1 {
+		_ = s.cacheRepo.Delete(ctx, key)
+		return pending.Email, nil
+	}
+
+	pending.Attempts++
+	remaining := pending.ExpiresAt - s.clock.Now().Unix()
+	if pending.Attempts >= userEmailMaxCodeAttempts || remaining <= 0 {
+		_ = s.cacheRepo.Delete(ctx, key)
+		return "", invalid
+	}
+	updated, err := json.Marshal(pending)
+	if err != nil {
+		return "", errors.Wrap(err, "fail to marshal email verification code")
+	}
+	if err := s.cacheRepo.SetWithTTL(ctx, key, string(updated), uint64(remaining)); err != nil {
+		return "", errors.Wrap(err, "fail to update email verification code")
+	}
+	return "", invalid
+}
+
+func (s *UserEmailService) existingUser(ctx context.Context, userID entity.UserIDEntity) (entity.UserEntity, error) {
+	user, exists, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return entity.UserEntity{}, errors.Wrap(err, "fail to get user by id")
+	}
+	if !exists {
+		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user %s not found", userID)
+	}
+	return user, nil
+}
+
+// checkEmailFree fails when another user already uses the email
+func (s *UserEmailService) checkEmailFree(ctx context.Context, userID entity.UserIDEntity, email string) error {
+	owner, exists, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return errors.Wrap(err, "fail to get user by email")
+	}
+	if exists && owner.ID != userID {
+		return error_code.NewErrorWithErrorCodef(error_code.EmailAlreadyInUse, "the email is already used by user %s", owner.ID)
+	}
+	return nil
+}
+
+// notify emails an address the user no longer uses, a failed notice is logged and does not fail the change it reports
+func (s *UserEmailService) notify(ctx context.Context, to string, subject string, body string) {
+	if err := s.mailer.Send(ctx, entity.MailEntity{To: to, Subject: subject, Body: body}); err != nil {
+		logger.Errorf(ctx, "Failed to send email change notice %q: %v", subject, err)
+	}
+}
+
+// normalizeUserEmail is the form the emails of the users are stored and looked up in
+func normalizeUserEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
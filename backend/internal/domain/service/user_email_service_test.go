@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+var userEmailCodePattern = regexp.MustCompile(`code is (\d+),`)
+
+type userEmailTestEnv struct {
+	svc      *UserEmailService
+	userRepo *mockgen.MockIUserRepository
+	mailer   *recordingMailer
+	clock    *fixtures.FakeClock
+	user     entity.UserEntity
+}
+
+func newUserEmailTestEnv(t *testing.T, mailer string) userEmailTestEnv {
+	ctrl := gomock.NewController(t)
+	env := userEmailTestEnv{
+		userRepo: mockgen.NewMockIUserRepository(ctrl),
+		mailer:   &recordingMailer{},
+		clock:    fixtures.NewFakeClock(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)),
+		user:     fixtures.NewTestUser().WithID("user-1").Build(),
+	}
+	old := "old@example.com"
+	env.user.Mail = &old
+	env.userRepo.EXPECT().GetByID(gomock.Any(), env.user.ID).Return(env.user, true, nil).AnyTimes()
+	env.userRepo.EXPECT().GetByEmail(gomock.Any(), "taken@example.com").Return(fixtures.NewTestUser().WithID("user-2").Build(), true, nil).AnyTimes()
+	env.userRepo.EXPECT().GetByEmail(gomock.Any(), gomock.Any()).Return(entity.UserEntity{}, false, nil).AnyTimes()
+
+	env.svc = NewUserEmailService(env.userRepo, fixtures.NewFakeCache(env.clock), env.mailer, env.clock, config.Config{Mailer: mailer})
+	return env
+}
+
+// sentCode returns the code of the latest verification mail
+func (env userEmailTestEnv) sentCode(t *testing.T) string {
+	t.Helper()
+	mails := env.mailer.sent()
+	require.NotEmpty(t, mails)
+	match := userEmailCodePattern.FindStringSubmatch(mails[len(mails)-1].Body)
+	require.Len(t, match, 2)
+	return match[1]
+}
+
+func TestUserEmailService_StartVerification(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	t.Run("needs a mailer", func(t *testing.T) {
+		t.Parallel()
+		env := newUserEmailTestEnv(t, "none")
+		requireAccountRecoveryErrorCode(t, env.svc.StartVerification(context.Background(), env.user.ID, "new@example.com"), error_code.EmailVerificationUnavailable)
+	})
+
+	t.Run("email of another user is refused", func(t *testing.T) {
+		t.Parallel()
+		env := newUserEmailTestEnv(t, "log")
+		requireAccountRecoveryErrorCode(t, env.svc.StartVerification(context.Background(), env.user.ID, " Taken@example.com"), error_code.EmailAlreadyInUse)
+		assert.Empty(t, env.mailer.sent())
+	})
+
+	t.Run("code is sent to the new email once per interval", func(t *testing.T) {
+		t.Parallel()
+		env := newUserEmailTestEnv(t, "log")
+		ctx := context.Background()
+
+		require.NoError(t, env.svc.StartVerification(ctx, env.user.ID, " New@Example.com "))
+		require.Len(t, env.mailer.sent(), 1)
+		assert.Equal(t, "new@example.com", env.mailer.sent()[0].To)
+		assert.NotEmpty(t, env.sentCode(t))
+
+		requireAccountRecoveryErrorCode(t, env.svc.StartVerification(ctx, env.user.ID, "new@example.com"), error_code.EmailVerificationThrottled)
+
+		env.clock.Advance(userEmailResendInterval * time.Second)
+		require.NoError(t, env.svc.StartVerification(ctx, env.user.ID, "new@example.com"))
+		assert.Len(t, env.mailer.sent(), 2)
+	})
+}
+
+func TestUserEmailService_Verify(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	t.Run("sets the email and tells the previous one", func(t *testing.T) {
+		t.Parallel()
+		env := newUserEmailTestEnv(t, "log")
+		ctx := context.Background()
+		require.NoError(t, env.svc.StartVerification(ctx, env.user.ID, "new@example.com"))
+		code := env.sentCode(t)
+
+		env.userRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user entity.UserEntity) error {
+			require.NotNil(t, user.Mail)
+			assert.Equal(t, "new@example.com", *user.Mail)
+			return nil
+		})
+		email, err := env.svc.Verify(ctx, env.user.ID, code)
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", email)
+		require.Len(t, env.mailer.sent(), 2)
+		assert.Equal(t, "old@example.com", env.mailer.sent()[1].To)
+
+		_, err = env.svc.Verify(ctx, env.user.ID, code)
+		requireAccountRecoveryErrorCode(t, err, error_code.InvalidEmailVerificationCode)
+	})
+
+	t.Run("wrong codes drop the pending code", func(t *testing.T) {
+		t.Parallel()
+		env := newUserEmailTestEnv(t, "log")
+		ctx := context.Background()
+		require.NoError(t, env.svc.StartVerification(ctx, env.user.ID, "new@example.com"))
+		code := env.sentCode(t)
+
+		for range userEmailMaxCodeAttempts {
+			_, err := env.svc.Verify(ctx, env.user.ID, "not-the-code")
+			requireAccountRecoveryErrorCode(t, err, error_code.InvalidEmailVerificationCode)
+		}
+		_, err := env.svc.Verify(ctx, env.user.ID, code)
+		requireAccountRecoveryErrorCode(t, err, error_code.InvalidEmailVerificationCode)
+	})
+
+	t.Run("code expires", func(t *testing.T) {
+		t.Parallel()
+		env := newUserEmailTestEnv(t, "log")
+		ctx := context.Background()
+		require.NoError(t, env.svc.StartVerification(ctx, env.user.ID, "new@example.com"))
+		code := env.sentCode(t)
+
+		env.clock.Advance(userEmailCodeTTL * time.Second)
+		_, err := env.svc.Verify(ctx, env.user.ID, code)
+		requireAccountRecoveryErrorCode(t, err, error_code.InvalidEmailVerificationCode)
+	})
+}
+
+func TestUserEmailService_Remove(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+
+	env := newUserEmailTestEnv(t, "log")
+	env.userRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user entity.UserEntity) error {
+		assert.Nil(t, user.Mail)
+		return nil
+	})
+	require.NoError(t, env.svc.Remove(context.Background(), env.user.ID))
+	require.Len(t, env.mailer.sent(), 1)
+	assert.Equal(t, "old@example.com", env.mailer.sent()[0].To)
+}
@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/core/logger"
 	"ya-tool-craft/internal/domain/entity"
@@ -9,32 +12,43 @@ import (
 	"ya-tool-craft/internal/error_code"
 
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
 )
 
 func NewUserService(
 	userRepo repository.IUserRepository,
 	accessTokenRepo repository.IAuthAccessTokenRepository,
 	refreshTokenRepo repository.IAuthRefreshTokenRepository,
+	settingsService *SystemSettingsService,
+	sessionRevocationService *SessionRevocationService,
 	cfg config.Config,
 ) *UserService {
 	return &UserService{
-		userRepo:         userRepo,
-		accessTokenRepo:  accessTokenRepo,
-		refreshTokenRepo: refreshTokenRepo,
-		config:           cfg,
+		userRepo:                 userRepo,
+		accessTokenRepo:          accessTokenRepo,
+		refreshTokenRepo:         refreshTokenRepo,
+		settingsService:          settingsService,
+		sessionRevocationService: sessionRevocationService,
+		config:                   cfg,
 	}
 }
 
 type UserService struct {
-	userRepo         repository.IUserRepository
-	accessTokenRepo  repository.IAuthAccessTokenRepository
-	refreshTokenRepo repository.IAuthRefreshTokenRepository
-	config           config.Config
+	userRepo                 repository.IUserRepository
+	accessTokenRepo          repository.IAuthAccessTokenRepository
+	refreshTokenRepo         repository.IAuthRefreshTokenRepository
+	settingsService          *SystemSettingsService
+	sessionRevocationService *SessionRevocationService
+	config                   config.Config
 }
 
 func (s *UserService) CreateUser(ctx context.Context, username string, password string) (entity.UserEntity, error) {
-	if !s.config.ENABLE_USER_REGISTRATION {
-		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserRegistrationIsNotEnabled, "user registration is not enabled, please set env: ENABLE_USER_REGISTRATION")
+	settings, err := s.settingsService.Settings(ctx)
+	if err != nil {
+		return entity.UserEntity{}, errors.Wrapf(err, "fail to get system settings")
+	}
+	if !settings.EnableUserRegistration {
+		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserRegistrationIsNotEnabled, "user registration is not enabled")
 	}
 
 	// Check if username already exists
@@ -134,3 +148,371 @@ func (s *UserService) DeleteUser(ctx context.Context, userID entity.UserIDEntity
 	logger.Infof(ctx, "user deleted: userid: %s", userID)
 	return nil
 }
+
+// DeleteUserIfDeletionDue deletes the user with all related data when the account deletion is scheduled at or before
+// the time. The schedule is checked when the data is deleted, so a deletion cancelled meanwhile keeps the account.
+// The sessions are revoked once the user is gone. Returns false when the user was kept.
+func (s *UserService) DeleteUserIfDeletionDue(ctx context.Context, userID entity.UserIDEntity, before time.Time) (bool, error) {
+	deleted, err := s.userRepo.DeleteUserIfDeletionDue(ctx, userID, before)
+	if err != nil {
+		return false, errors.Wrapf(err, "fail to delete user and related data")
+	}
+	if !deleted {
+		return false, nil
+	}
+
+	if err := s.accessTokenRepo.DeleteAllTokensByUserID(ctx, userID); err != nil {
+		return true, errors.Wrapf(err, "fail to delete access tokens")
+	}
+	if err := s.refreshTokenRepo.DeleteAllTokensByUserID(ctx, userID); err != nil {
+		return true, errors.Wrapf(err, "fail to delete refresh tokens")
+	}
+
+	logger.Infof(ctx, "user deleted: userid: %s", userID)
+	return true, nil
+}
+
+// MergeUsers moves tools, global script, passkeys and sso bindings of the duplicate user to the survivor,
+// then deletes the duplicate. The duplicate's sessions are revoked before the merge.
+func (s *UserService) MergeUsers(ctx context.Context, survivorID entity.UserIDEntity, duplicateID entity.UserIDEntity) (entity.UserMergeResultEntity, error) {
+	if survivorID == duplicateID {
+		return entity.UserMergeResultEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserMergeSameUser, "can not merge user %s into itself", survivorID)
+	}
+
+	for _, userID := range []entity.UserIDEntity{survivorID, duplicateID} {
+		_, exists, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return entity.UserMergeResultEntity{}, errors.Wrapf(err, "fail to get user by id")
+		}
+		if !exists {
+			return entity.UserMergeResultEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user not found")
+		}
+	}
+
+	// a user can only bind one account per provider
+	survivorBindings, err := s.userRepo.GetUserSSOBindings(ctx, survivorID)
+	if err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrapf(err, "fail to get survivor sso bindings")
+	}
+	duplicateBindings, err := s.userRepo.GetUserSSOBindings(ctx, duplicateID)
+	if err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrapf(err, "fail to get duplicate sso bindings")
+	}
+	for _, duplicateBinding := range duplicateBindings {
+		for _, survivorBinding := range survivorBindings {
+			if duplicateBinding.Provider == survivorBinding.Provider {
+				return entity.UserMergeResultEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserMergeConflict, "both users are bound to sso provider %s", duplicateBinding.Provider)
+			}
+		}
+	}
+
+	if err := s.accessTokenRepo.DeleteAllTokensByUserID(ctx, duplicateID); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrapf(err, "fail to delete access tokens")
+	}
+	if err := s.refreshTokenRepo.DeleteAllTokensByUserID(ctx, duplicateID); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrapf(err, "fail to delete refresh tokens")
+	}
+
+	result, err := s.userRepo.MergeUsers(ctx, survivorID, duplicateID)
+	if err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrapf(err, "fail to merge users")
+	}
+
+	logger.Infof(ctx, "user merged: survivor: %s, duplicate: %s, tools: %d, renamed tools: %d, passkeys: %d, sso bindings: %d",
+		survivorID, duplicateID, result.MovedTools, len(result.RenamedTools), result.MovedPasskeys, result.MovedSSOBindings)
+	return result, nil
+}
+
+// ChangePassword sets a new password for the user, the current password is required when the user has one.
+// It also satisfies a password change required by an admin. With REVOKE_SESSIONS_ON_PASSWORD_CHANGE every other
+// session is signed out, the one holding the refresh token with currentRefreshTokenHash stays signed in.
+func (s *UserService) ChangePassword(ctx context.Context, userID entity.UserIDEntity, currentPassword string, newPassword string, currentRefreshTokenHash string) error {
+	user, err := s.existingUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.PasswordHash != nil {
+		_, valid, err := s.userRepo.ValidateCredentialsByUsername(ctx, user.Name, currentPassword)
+		if err != nil {
+			return errors.Wrapf(err, "fail to check current password")
+		}
+		if !valid {
+			return error_code.NewErrorWithErrorCodef(error_code.InvalidCredentials, "current password is invalid")
+		}
+		if currentPassword == newPassword {
+			return error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "the new password must differ from the current one")
+		}
+	}
+
+	revoked, err := s.sessionRevocationService.RevokeOtherSessions(ctx, userID, currentRefreshTokenHash, SessionRevocationReasonPasswordChanged)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, userID, newPassword); err != nil {
+		return errors.Wrapf(err, "fail to update password")
+	}
+
+	logger.Infof(ctx, "user changed password: userid: %s", userID)
+	if revoked {
+		s.sessionRevocationService.NotifyRevokedSessions(ctx, userID, SessionRevocationReasonPasswordChanged)
+	}
+	return nil
+}
+
+// RevokeSessions signs the user out of every device by deleting all of the user's access and refresh tokens.
+func (s *UserService) RevokeSessions(ctx context.Context, userID entity.UserIDEntity) error {
+	if _, err := s.existingUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.accessTokenRepo.DeleteAllTokensByUserID(ctx, userID); err != nil {
+		return errors.Wrapf(err, "fail to delete access tokens")
+	}
+	if err := s.refreshTokenRepo.DeleteAllTokensByUserID(ctx, userID); err != nil {
+		return errors.Wrapf(err, "fail to delete refresh tokens")
+	}
+
+	logger.Infof(ctx, "user sessions revoked: userid: %s", userID)
+	return nil
+}
+
+// RequirePasswordChange makes the user change the password before doing anything else, on every session.
+// Users without a password set one.
+func (s *UserService) RequirePasswordChange(ctx context.Context, userID entity.UserIDEntity) error {
+	if _, err := s.existingUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.SetPasswordChangeRequired(ctx, userID, true); err != nil {
+		return errors.Wrapf(err, "fail to require password change")
+	}
+
+	logger.Infof(ctx, "user password change required: userid: %s", userID)
+	return nil
+}
+
+const (
+	userListDefaultLimit = 50
+	userListMaxLimit     = 200
+)
+
+// ListUsers returns a page of users with their tool counts, oldest first, and the total count of matching users.
+func (s *UserService) ListUsers(ctx context.Context, filter entity.UserFilter) ([]entity.UserSummaryEntity, int, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = userListDefaultLimit
+	}
+	filter.Limit = min(filter.Limit, userListMaxLimit)
+	filter.Offset = max(filter.Offset, 0)
+
+	users, total, err := s.userRepo.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "fail to list users")
+	}
+	return users, total, nil
+}
+
+// SetUserDisabled disables or enables the account of the user. A disabled user is signed out of every device and can
+// not log in until enabled again. Admins can not disable their own account.
+func (s *UserService) SetUserDisabled(ctx context.Context, actorID entity.UserIDEntity, userID entity.UserIDEntity, disabled bool) error {
+	if disabled && actorID == userID {
+		return error_code.NewErrorWithErrorCodef(error_code.AdminSelfLockout, "admins can not disable their own account")
+	}
+	if _, err := s.existingUser(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.SetDisabled(ctx, userID, disabled); err != nil {
+		return errors.Wrapf(err, "fail to set user disabled")
+	}
+	if disabled {
+		if err := s.RevokeSessions(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof(ctx, "user disabled set to %t: userid: %s", disabled, userID)
+	return nil
+}
+
+// SetUserRoles replaces the roles of the user, every role must be the admin or the user role or name a permission.
+// Admins can not remove their own admin role, so an admin is always left to undo a change.
+func (s *UserService) SetUserRoles(ctx context.Context, actorID entity.UserIDEntity, userID entity.UserIDEntity, roleNames []string) ([]entity.UserRoleEntity, error) {
+	roles := make([]entity.UserRoleEntity, 0, len(roleNames))
+	for _, name := range lo.Uniq(roleNames) {
+		role := entity.UserRoleEntity{RoleName: name}
+		if !role.Known() {
+			return nil, error_code.NewErrorWithErrorCodef(error_code.UnknownUserRole, "unknown user role %s", name)
+		}
+		roles = append(roles, role)
+	}
+	if actorID == userID && !lo.Contains(roles, entity.UserRoleAdmin) {
+		return nil, error_code.NewErrorWithErrorCodef(error_code.AdminSelfLockout, "admins can not remove their own admin role")
+	}
+	if _, err := s.existingUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetRoles(ctx, userID, roles); err != nil {
+		return nil, errors.Wrapf(err, "fail to set user roles")
+	}
+
+	logger.Infof(ctx, "user roles set to %v: userid: %s", roleNames, userID)
+	return roles, nil
+}
+
+func (s *UserService) existingUser(ctx context.Context, userID entity.UserIDEntity) (entity.UserEntity, error) {
+	user, exists, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return entity.UserEntity{}, errors.Wrapf(err, "fail to get user by id")
+	}
+	if !exists {
+		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user not found")
+	}
+	return user, nil
+}
+
+// checkUserEnabled refuses the login of a user an admin disabled
+func checkUserEnabled(user entity.UserEntity) error {
+	if user.Disabled {
+		return error_code.NewErrorWithErrorCodef(error_code.UserDisabled, "user %s is disabled", user.ID)
+	}
+	return nil
+}
+
+const (
+	adminPasswordMinLength = 8
+	adminPasswordMaxLength = 32
+	// generatedAdminPasswordBytes is base64 encoded into a 24 character password
+	generatedAdminPasswordBytes = 18
+)
+
+// CreateAdmin creates a user with the admin role, the registration setting does not apply.
+// An empty password generates one, the password is returned so it can be shown once.
+func (s *UserService) CreateAdmin(ctx context.Context, username string, password string) (entity.UserEntity, string, error) {
+	return s.createUserWithPassword(ctx, username, password, []entity.UserRoleEntity{entity.UserRoleUser, entity.UserRoleAdmin})
+}
+
+// CreateUserByAdmin creates a user with the user role, the registration setting does not apply.
+// An empty password generates one, the password is returned so it can be shown once.
+func (s *UserService) CreateUserByAdmin(ctx context.Context, username string, password string) (entity.UserEntity, string, error) {
+	return s.createUserWithPassword(ctx, username, password, []entity.UserRoleEntity{entity.UserRoleUser})
+}
+
+func (s *UserService) createUserWithPassword(ctx context.Context, username string, password string, roles []entity.UserRoleEntity) (entity.UserEntity, string, error) {
+	if len(username) < 3 || len(username) > 32 {
+		return entity.UserEntity{}, "", error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "username must be 3 to 32 characters")
+	}
+	password, err := passwordOrGenerated(password)
+	if err != nil {
+		return entity.UserEntity{}, "", err
+	}
+
+	_, exists, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return entity.UserEntity{}, "", errors.Wrapf(err, "fail to check existing user")
+	}
+	if exists {
+		return entity.UserEntity{}, "", error_code.NewErrorWithErrorCodef(error_code.UserAlreadyExists, "username already exists")
+	}
+
+	user, err := s.userRepo.Create(ctx, username, roles)
+	if err != nil {
+		return entity.UserEntity{}, "", errors.Wrapf(err, "fail to create user")
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, password); err != nil {
+		return entity.UserEntity{}, "", errors.Wrapf(err, "fail to set user password")
+	}
+
+	logger.Infof(ctx, "user created: username: %s userid: %s roles: %v", username, user.ID, roles)
+	return user, password, nil
+}
+
+// ResetPassword sets a new password for the user without the current one, for a user who can not sign in any more.
+// An empty password generates one, the password is returned so it can be shown once. The user is signed out of every
+// device and a password change required by an admin is cleared.
+func (s *UserService) ResetPassword(ctx context.Context, userID entity.UserIDEntity, password string) (string, error) {
+	if _, err := s.existingUser(ctx, userID); err != nil {
+		return "", err
+	}
+	password, err := passwordOrGenerated(password)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, userID, password); err != nil {
+		return "", errors.Wrapf(err, "fail to reset password")
+	}
+	if err := s.RevokeSessions(ctx, userID); err != nil {
+		return "", err
+	}
+
+	logger.Infof(ctx, "user password reset: userid: %s", userID)
+	return password, nil
+}
+
+// GetUserByUsername returns the user with the username.
+func (s *UserService) GetUserByUsername(ctx context.Context, username string) (entity.UserEntity, error) {
+	user, exists, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return entity.UserEntity{}, errors.Wrapf(err, "fail to get user by username")
+	}
+	if !exists {
+		return entity.UserEntity{}, error_code.NewErrorWithErrorCodef(error_code.UserNotFound, "user %s not found", username)
+	}
+	return user, nil
+}
+
+// BootstrapAdmin creates the admin from BOOTSTRAP_ADMIN_USERNAME when there is no admin yet, so a fresh install
+// can be administered without touching the database. It does nothing once any admin exists.
+func (s *UserService) BootstrapAdmin(ctx context.Context) error {
+	if s.config.BootstrapAdminUsername == "" {
+		return nil
+	}
+
+	hasAdmin, err := s.userRepo.HasUserWithRole(ctx, entity.UserRoleAdmin)
+	if err != nil {
+		return errors.Wrapf(err, "fail to check existing admin")
+	}
+	if hasAdmin {
+		return nil
+	}
+
+	user, password, err := s.CreateAdmin(ctx, s.config.BootstrapAdminUsername, s.config.BootstrapAdminPassword)
+	if err != nil {
+		return errors.Wrapf(err, "fail to create bootstrap admin %s", s.config.BootstrapAdminUsername)
+	}
+
+	if s.config.BootstrapAdminPassword == "" {
+		// the generated password is not stored anywhere else, this log line is the only place it can be read
+		logger.Warnf(ctx, "Bootstrap admin %s created with the generated password %s, change it after the first login", user.Name, password)
+	} else {
+		logger.Warnf(ctx, "Bootstrap admin %s created with BOOTSTRAP_ADMIN_PASSWORD, change it after the first login", user.Name)
+	}
+
+	settings, err := s.settingsService.Settings(ctx)
+	if err == nil && !settings.EnablePasswordLogin {
+		logger.Warnf(ctx, "Password login is disabled, enable ENABLE_PASSWORD_LOGIN to sign in as the bootstrap admin")
+	}
+	return nil
+}
+
+// passwordOrGenerated checks the length of a given password, an empty one is generated
+func passwordOrGenerated(password string) (string, error) {
+	if password == "" {
+		return generateAdminPassword()
+	}
+	if len(password) < adminPasswordMinLength || len(password) > adminPasswordMaxLength {
+		return "", error_code.NewErrorWithErrorCodef(error_code.InvalidRequestParameters, "password must be %d to %d characters", adminPasswordMinLength, adminPasswordMaxLength)
+	}
+	return password, nil
+}
+
+func generateAdminPassword() (string, error) {
+	buf := make([]byte, generatedAdminPasswordBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "fail to generate admin password")
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
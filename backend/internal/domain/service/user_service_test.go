@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -13,6 +14,7 @@ import (
 	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/error_code"
 	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
 )
 
 func TestUserService_CreateUser(t *testing.T) {
@@ -125,7 +127,7 @@ func TestUserService_CreateUser(t *testing.T) {
 			if tt.enableUserRegistration != nil {
 				cfg.ENABLE_USER_REGISTRATION = *tt.enableUserRegistration
 			}
-			svc := NewUserService(userRepo, accessRepo, refreshRepo, cfg)
+			svc := NewUserService(userRepo, accessRepo, refreshRepo, newTestSystemSettingsService(ctrl, cfg), nil, cfg)
 
 			user, err := svc.CreateUser(ctx, username, password)
 
@@ -203,7 +205,7 @@ func TestUserService_CheckUsernameExists(t *testing.T) {
 				tt.setupMocks(ctx, userRepo)
 			}
 
-			svc := NewUserService(userRepo, accessRepo, refreshRepo, config.Config{ENABLE_USER_REGISTRATION: true})
+			svc := NewUserService(userRepo, accessRepo, refreshRepo, newTestSystemSettingsService(ctrl, config.Config{ENABLE_USER_REGISTRATION: true}), nil, config.Config{ENABLE_USER_REGISTRATION: true})
 
 			exists, err := svc.CheckUsernameExists(ctx, username)
 
@@ -355,7 +357,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 				tt.setupMocks(ctx, userRepo)
 			}
 
-			svc := NewUserService(userRepo, accessRepo, refreshRepo, config.Config{ENABLE_USER_REGISTRATION: true})
+			svc := NewUserService(userRepo, accessRepo, refreshRepo, newTestSystemSettingsService(ctrl, config.Config{ENABLE_USER_REGISTRATION: true}), nil, config.Config{ENABLE_USER_REGISTRATION: true})
 
 			err := svc.UpdateUser(ctx, userID, struct{ Username *string }{Username: tt.params.Username})
 
@@ -485,7 +487,7 @@ func TestUserService_DeleteUser(t *testing.T) {
 				tt.setupMocks(ctx, userRepo, accessRepo, refreshRepo)
 			}
 
-			svc := NewUserService(userRepo, accessRepo, refreshRepo, config.Config{ENABLE_USER_REGISTRATION: true})
+			svc := NewUserService(userRepo, accessRepo, refreshRepo, newTestSystemSettingsService(ctrl, config.Config{ENABLE_USER_REGISTRATION: true}), nil, config.Config{ENABLE_USER_REGISTRATION: true})
 
 			err := svc.DeleteUser(ctx, userID)
 
@@ -504,6 +506,797 @@ func TestUserService_DeleteUser(t *testing.T) {
 	}
 }
 
+func TestUserService_MergeUsers(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const (
+		survivorID  = entity.UserIDEntity("user-1")
+		duplicateID = entity.UserIDEntity("user-2")
+	)
+
+	expectUsersExist := func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+		userRepo.EXPECT().GetByID(ctx, survivorID).Return(entity.UserEntity{ID: survivorID}, true, nil)
+		userRepo.EXPECT().GetByID(ctx, duplicateID).Return(entity.UserEntity{ID: duplicateID}, true, nil)
+	}
+
+	tests := []struct {
+		name        string
+		duplicateID entity.UserIDEntity
+		setupMocks  func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository)
+		wantResult  entity.UserMergeResultEntity
+		wantErrSub  string
+		wantErrCode *error_code.ErrorCode
+	}{
+		{
+			name:        "merging a user into itself returns error code",
+			duplicateID: survivorID,
+			wantErrSub:  "into itself",
+			wantErrCode: &error_code.UserMergeSameUser,
+		},
+		{
+			name: "duplicate not found returns error code",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				userRepo.EXPECT().GetByID(ctx, survivorID).Return(entity.UserEntity{ID: survivorID}, true, nil)
+				userRepo.EXPECT().GetByID(ctx, duplicateID).Return(entity.UserEntity{}, false, nil)
+			},
+			wantErrSub:  "user not found",
+			wantErrCode: &error_code.UserNotFound,
+		},
+		{
+			name: "both users bound to the same provider returns error code",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				expectUsersExist(ctx, userRepo)
+				userRepo.EXPECT().GetUserSSOBindings(ctx, survivorID).Return([]entity.UserSSOEntity{{Provider: "github", ProviderUserID: "gh-1"}}, nil)
+				userRepo.EXPECT().GetUserSSOBindings(ctx, duplicateID).Return([]entity.UserSSOEntity{{Provider: "github", ProviderUserID: "gh-2"}}, nil)
+			},
+			wantErrSub:  "sso provider github",
+			wantErrCode: &error_code.UserMergeConflict,
+		},
+		{
+			name: "delete access tokens error is wrapped",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				expectUsersExist(ctx, userRepo)
+				userRepo.EXPECT().GetUserSSOBindings(ctx, survivorID).Return(nil, nil)
+				userRepo.EXPECT().GetUserSSOBindings(ctx, duplicateID).Return(nil, nil)
+				accessRepo.EXPECT().DeleteAllTokensByUserID(ctx, duplicateID).Return(errors.New("redis down"))
+			},
+			wantErrSub: "fail to delete access tokens",
+		},
+		{
+			name: "MergeUsers error is wrapped",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				expectUsersExist(ctx, userRepo)
+				userRepo.EXPECT().GetUserSSOBindings(ctx, survivorID).Return(nil, nil)
+				userRepo.EXPECT().GetUserSSOBindings(ctx, duplicateID).Return(nil, nil)
+				accessRepo.EXPECT().DeleteAllTokensByUserID(ctx, duplicateID).Return(nil)
+				refreshRepo.EXPECT().DeleteAllTokensByUserID(ctx, duplicateID).Return(nil)
+				userRepo.EXPECT().MergeUsers(ctx, survivorID, duplicateID).Return(entity.UserMergeResultEntity{}, errors.New("tx failed"))
+			},
+			wantErrSub: "fail to merge users",
+		},
+		{
+			name: "successful merge revokes the duplicate sessions",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				expectUsersExist(ctx, userRepo)
+				userRepo.EXPECT().GetUserSSOBindings(ctx, survivorID).Return(nil, nil)
+				userRepo.EXPECT().GetUserSSOBindings(ctx, duplicateID).Return([]entity.UserSSOEntity{{Provider: "github", ProviderUserID: "gh-2"}}, nil)
+				accessRepo.EXPECT().DeleteAllTokensByUserID(ctx, duplicateID).Return(nil)
+				refreshRepo.EXPECT().DeleteAllTokensByUserID(ctx, duplicateID).Return(nil)
+				userRepo.EXPECT().MergeUsers(ctx, survivorID, duplicateID).Return(entity.UserMergeResultEntity{SurvivorID: survivorID, DuplicateID: duplicateID, MovedSSOBindings: 1}, nil)
+			},
+			wantResult: entity.UserMergeResultEntity{SurvivorID: survivorID, DuplicateID: duplicateID, MovedSSOBindings: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			userRepo := mockgen.NewMockIUserRepository(ctrl)
+			accessRepo := mockgen.NewMockIAuthAccessTokenRepository(ctrl)
+			refreshRepo := mockgen.NewMockIAuthRefreshTokenRepository(ctrl)
+
+			if tt.setupMocks != nil {
+				tt.setupMocks(ctx, userRepo, accessRepo, refreshRepo)
+			}
+
+			svc := NewUserService(userRepo, accessRepo, refreshRepo, newTestSystemSettingsService(ctrl, config.Config{ENABLE_USER_REGISTRATION: true}), nil, config.Config{ENABLE_USER_REGISTRATION: true})
+
+			dupID := duplicateID
+			if tt.duplicateID != "" {
+				dupID = tt.duplicateID
+			}
+			result, err := svc.MergeUsers(ctx, survivorID, dupID)
+
+			if tt.wantErrSub != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+				if tt.wantErrCode != nil {
+					var ecErr error_code.ErrorWithErrorCode
+					require.True(t, errors.As(err, &ecErr))
+					require.Equal(t, tt.wantErrCode.Code, ecErr.ErrorCode.Code)
+				}
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantResult, result)
+			}
+		})
+	}
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+	passwordHash := "hash"
+
+	tests := []struct {
+		name        string
+		current     string
+		setupMocks  func(ctx context.Context, userRepo *mockgen.MockIUserRepository)
+		wantErrSub  string
+		wantErrCode *error_code.ErrorCode
+	}{
+		{
+			name: "user not found returns error code",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{}, false, nil)
+			},
+			wantErrSub:  "user not found",
+			wantErrCode: &error_code.UserNotFound,
+		},
+		{
+			name:    "wrong current password returns error code",
+			current: "wrong-password",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID, Name: "alice", PasswordHash: &passwordHash}, true, nil)
+				userRepo.EXPECT().ValidateCredentialsByUsername(ctx, "alice", "wrong-password").Return(entity.UserEntity{}, false, nil)
+			},
+			wantErrSub:  "current password is invalid",
+			wantErrCode: &error_code.InvalidCredentials,
+		},
+		{
+			name:    "reusing the current password is refused",
+			current: "new-password",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID, Name: "alice", PasswordHash: &passwordHash}, true, nil)
+				userRepo.EXPECT().ValidateCredentialsByUsername(ctx, "alice", "new-password").Return(entity.UserEntity{ID: userID}, true, nil)
+			},
+			wantErrSub:  "must differ",
+			wantErrCode: &error_code.InvalidRequestParameters,
+		},
+		{
+			name:    "current password is checked before the update",
+			current: "old-password",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID, Name: "alice", PasswordHash: &passwordHash}, true, nil)
+				userRepo.EXPECT().ValidateCredentialsByUsername(ctx, "alice", "old-password").Return(entity.UserEntity{ID: userID}, true, nil)
+				userRepo.EXPECT().UpdatePassword(ctx, userID, "new-password").Return(nil)
+			},
+		},
+		{
+			name: "user without a password sets one",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID, Name: "alice", PasswordChangeRequired: true}, true, nil)
+				userRepo.EXPECT().UpdatePassword(ctx, userID, "new-password").Return(nil)
+			},
+		},
+		{
+			name: "UpdatePassword error is wrapped",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID, Name: "alice"}, true, nil)
+				userRepo.EXPECT().UpdatePassword(ctx, userID, "new-password").Return(errors.New("db offline"))
+			},
+			wantErrSub: "fail to update password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			userRepo := mockgen.NewMockIUserRepository(ctrl)
+			tt.setupMocks(ctx, userRepo)
+
+			svc := NewUserService(userRepo, nil, nil, nil, newTestSessionRevocationService(config.Config{}), config.Config{})
+
+			err := svc.ChangePassword(ctx, userID, tt.current, "new-password", "current-refresh-token-hash")
+
+			if tt.wantErrSub == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErrSub)
+			if tt.wantErrCode != nil {
+				var ecErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &ecErr)
+				require.Equal(t, tt.wantErrCode.Code, ecErr.ErrorCode.Code)
+			}
+		})
+	}
+}
+
+func TestUserService_ChangePassword_RevokesOtherSessions(t *testing.T) {
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	userRepo := mockgen.NewMockIUserRepository(ctrl)
+	accessRepo := mockgen.NewMockIAuthAccessTokenRepository(ctrl)
+	refreshRepo := mockgen.NewMockIAuthRefreshTokenRepository(ctrl)
+	auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+	mailer := &recordingMailer{}
+	cfg := config.Config{RevokeSessionsOnPasswordChange: true}
+	sessionRevocationService := NewSessionRevocationService(userRepo, accessRepo, refreshRepo,
+		NewAuditLogService(auditLogRepo, NewSIEMExportService(nil, nil, config.Config{}), fixtures.NewFakeClock(time.Now())), mailer, cfg)
+	svc := NewUserService(userRepo, accessRepo, refreshRepo, nil, sessionRevocationService, cfg)
+
+	userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID, Name: "alice"}, true, nil).Times(2)
+	// the other sessions are signed out before the password is stored
+	gomock.InOrder(
+		refreshRepo.EXPECT().DeleteOtherTokensByUserID(ctx, userID, "current-refresh-token-hash").Return(nil),
+		accessRepo.EXPECT().DeleteOtherTokensByUserID(ctx, userID, "current-refresh-token-hash").Return(nil),
+		userRepo.EXPECT().UpdatePassword(ctx, userID, "new-password").Return(nil),
+		auditLogRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil),
+	)
+
+	require.NoError(t, svc.ChangePassword(ctx, userID, "", "new-password", "current-refresh-token-hash"))
+
+	t.Run("a failed revocation keeps the password", func(t *testing.T) {
+		userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID, Name: "alice"}, true, nil)
+		refreshRepo.EXPECT().DeleteOtherTokensByUserID(ctx, userID, "current-refresh-token-hash").Return(errors.New("redis offline"))
+
+		err := svc.ChangePassword(ctx, userID, "", "new-password", "current-refresh-token-hash")
+
+		require.ErrorContains(t, err, "fail to delete other refresh tokens")
+	})
+}
+
+func TestUserService_RevokeSessions(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+
+	tests := []struct {
+		name        string
+		setupMocks  func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository)
+		wantErrSub  string
+		wantErrCode *error_code.ErrorCode
+	}{
+		{
+			name: "user not found returns error code",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{}, false, nil)
+			},
+			wantErrSub:  "user not found",
+			wantErrCode: &error_code.UserNotFound,
+		},
+		{
+			name: "delete refresh tokens error is wrapped",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID}, true, nil)
+				accessRepo.EXPECT().DeleteAllTokensByUserID(ctx, userID).Return(nil)
+				refreshRepo.EXPECT().DeleteAllTokensByUserID(ctx, userID).Return(errors.New("redis down"))
+			},
+			wantErrSub: "fail to delete refresh tokens",
+		},
+		{
+			name: "every token of the user is deleted",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessRepo *mockgen.MockIAuthAccessTokenRepository, refreshRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID}, true, nil)
+				accessRepo.EXPECT().DeleteAllTokensByUserID(ctx, userID).Return(nil)
+				refreshRepo.EXPECT().DeleteAllTokensByUserID(ctx, userID).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			userRepo := mockgen.NewMockIUserRepository(ctrl)
+			accessRepo := mockgen.NewMockIAuthAccessTokenRepository(ctrl)
+			refreshRepo := mockgen.NewMockIAuthRefreshTokenRepository(ctrl)
+			tt.setupMocks(ctx, userRepo, accessRepo, refreshRepo)
+
+			svc := NewUserService(userRepo, accessRepo, refreshRepo, nil, nil, config.Config{})
+
+			err := svc.RevokeSessions(ctx, userID)
+
+			if tt.wantErrSub == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErrSub)
+			if tt.wantErrCode != nil {
+				var ecErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &ecErr)
+				require.Equal(t, tt.wantErrCode.Code, ecErr.ErrorCode.Code)
+			}
+		})
+	}
+}
+
+func TestUserService_RequirePasswordChange(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const userID = entity.UserIDEntity("user-1")
+
+	t.Run("user not found returns error code", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		ctrl := gomock.NewController(t)
+		userRepo := mockgen.NewMockIUserRepository(ctrl)
+		userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{}, false, nil)
+
+		err := NewUserService(userRepo, nil, nil, nil, nil, config.Config{}).RequirePasswordChange(ctx, userID)
+
+		var ecErr error_code.ErrorWithErrorCode
+		require.ErrorAs(t, err, &ecErr)
+		require.Equal(t, error_code.UserNotFound.Code, ecErr.ErrorCode.Code)
+	})
+
+	t.Run("flags the user", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		ctrl := gomock.NewController(t)
+		userRepo := mockgen.NewMockIUserRepository(ctrl)
+		userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID}, true, nil)
+		userRepo.EXPECT().SetPasswordChangeRequired(ctx, userID, true).Return(nil)
+
+		require.NoError(t, NewUserService(userRepo, nil, nil, nil, nil, config.Config{}).RequirePasswordChange(ctx, userID))
+	})
+}
+
+func TestUserService_ListUsers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		filter     entity.UserFilter
+		wantFilter entity.UserFilter
+	}{
+		{name: "default page size", filter: entity.UserFilter{Query: "ali"}, wantFilter: entity.UserFilter{Query: "ali", Limit: 50}},
+		{name: "page size is capped", filter: entity.UserFilter{Limit: 1000, Offset: 10}, wantFilter: entity.UserFilter{Limit: 200, Offset: 10}},
+		{name: "negative offset starts at the first user", filter: entity.UserFilter{Limit: 5, Offset: -3}, wantFilter: entity.UserFilter{Limit: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			userRepo := mockgen.NewMockIUserRepository(ctrl)
+			summaries := []entity.UserSummaryEntity{{User: entity.UserEntity{ID: "user-1"}, ToolCount: 3}}
+			userRepo.EXPECT().ListUsers(ctx, tt.wantFilter).Return(summaries, 7, nil)
+
+			users, total, err := NewUserService(userRepo, nil, nil, nil, nil, config.Config{}).ListUsers(ctx, tt.filter)
+			require.NoError(t, err)
+			require.Equal(t, summaries, users)
+			require.Equal(t, 7, total)
+		})
+	}
+}
+
+func TestUserService_SetUserDisabled(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const (
+		adminID = entity.UserIDEntity("admin-1")
+		userID  = entity.UserIDEntity("user-1")
+	)
+
+	t.Run("admins can not disable themselves", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		userRepo := mockgen.NewMockIUserRepository(ctrl)
+
+		err := NewUserService(userRepo, nil, nil, nil, nil, config.Config{}).SetUserDisabled(context.Background(), adminID, adminID, true)
+		require.True(t, isErrorCode(err, error_code.AdminSelfLockout))
+	})
+
+	t.Run("user not found returns error code", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		ctrl := gomock.NewController(t)
+		userRepo := mockgen.NewMockIUserRepository(ctrl)
+		userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{}, false, nil)
+
+		err := NewUserService(userRepo, nil, nil, nil, nil, config.Config{}).SetUserDisabled(ctx, adminID, userID, true)
+		require.True(t, isErrorCode(err, error_code.UserNotFound))
+	})
+
+	t.Run("disabling signs the user out", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		ctrl := gomock.NewController(t)
+		userRepo := mockgen.NewMockIUserRepository(ctrl)
+		accessRepo := mockgen.NewMockIAuthAccessTokenRepository(ctrl)
+		refreshRepo := mockgen.NewMockIAuthRefreshTokenRepository(ctrl)
+		userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID}, true, nil).Times(2)
+		userRepo.EXPECT().SetDisabled(ctx, userID, true).Return(nil)
+		accessRepo.EXPECT().DeleteAllTokensByUserID(ctx, userID).Return(nil)
+		refreshRepo.EXPECT().DeleteAllTokensByUserID(ctx, userID).Return(nil)
+
+		require.NoError(t, NewUserService(userRepo, accessRepo, refreshRepo, nil, nil, config.Config{}).SetUserDisabled(ctx, adminID, userID, true))
+	})
+
+	t.Run("admins can enable themselves", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		ctrl := gomock.NewController(t)
+		userRepo := mockgen.NewMockIUserRepository(ctrl)
+		userRepo.EXPECT().GetByID(ctx, adminID).Return(entity.UserEntity{ID: adminID}, true, nil)
+		userRepo.EXPECT().SetDisabled(ctx, adminID, false).Return(nil)
+
+		require.NoError(t, NewUserService(userRepo, nil, nil, nil, nil, config.Config{}).SetUserDisabled(ctx, adminID, adminID, false))
+	})
+}
+
+func TestUserService_SetUserRoles(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const (
+		adminID = entity.UserIDEntity("admin-1")
+		userID  = entity.UserIDEntity("user-1")
+	)
+
+	tests := []struct {
+		name        string
+		actorID     entity.UserIDEntity
+		roles       []string
+		setupMocks  func(ctx context.Context, userRepo *mockgen.MockIUserRepository)
+		wantRoles   []entity.UserRoleEntity
+		wantErrCode *error_code.ErrorCode
+	}{
+		{
+			name:        "unknown role is refused",
+			actorID:     adminID,
+			roles:       []string{"user", "superuser"},
+			setupMocks:  func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {},
+			wantErrCode: &error_code.UnknownUserRole,
+		},
+		{
+			name:        "admins can not remove their own admin role",
+			actorID:     userID,
+			roles:       []string{"user"},
+			setupMocks:  func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {},
+			wantErrCode: &error_code.AdminSelfLockout,
+		},
+		{
+			name:    "user not found returns error code",
+			actorID: adminID,
+			roles:   []string{"user"},
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{}, false, nil)
+			},
+			wantErrCode: &error_code.UserNotFound,
+		},
+		{
+			name:    "roles and permissions replace the roles once each",
+			actorID: adminID,
+			roles:   []string{"user", string(entity.UserPermissionListUsers), "user"},
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID}, true, nil)
+				userRepo.EXPECT().SetRoles(ctx, userID, []entity.UserRoleEntity{entity.UserRoleUser, {RoleName: "users:list"}}).Return(nil)
+			},
+			wantRoles: []entity.UserRoleEntity{entity.UserRoleUser, {RoleName: "users:list"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			userRepo := mockgen.NewMockIUserRepository(ctrl)
+			tt.setupMocks(ctx, userRepo)
+
+			roles, err := NewUserService(userRepo, nil, nil, nil, nil, config.Config{}).SetUserRoles(ctx, tt.actorID, userID, tt.roles)
+
+			if tt.wantErrCode != nil {
+				require.True(t, isErrorCode(err, *tt.wantErrCode), "%v", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRoles, roles)
+		})
+	}
+}
+
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestUserService_CreateAdmin(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	const username = "root"
+	adminRoles := []entity.UserRoleEntity{entity.UserRoleUser, entity.UserRoleAdmin}
+
+	tests := []struct {
+		name         string
+		username     string
+		password     string
+		setupMocks   func(ctx context.Context, userRepo *mockgen.MockIUserRepository)
+		wantPassword string
+		wantErrCode  *error_code.ErrorCode
+	}{
+		{
+			name:        "short username is rejected",
+			username:    "ab",
+			wantErrCode: &error_code.InvalidRequestParameters,
+		},
+		{
+			name:        "short password is rejected",
+			username:    username,
+			password:    "short",
+			wantErrCode: &error_code.InvalidRequestParameters,
+		},
+		{
+			name:     "existing username is rejected",
+			username: username,
+			password: "secret123",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByUsername(ctx, username).Return(entity.UserEntity{Name: username}, true, nil)
+			},
+			wantErrCode: &error_code.UserAlreadyExists,
+		},
+		{
+			name:     "given password is set",
+			username: username,
+			password: "secret123",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByUsername(ctx, username).Return(entity.UserEntity{}, false, nil)
+				userRepo.EXPECT().Create(ctx, username, adminRoles).Return(entity.UserEntity{ID: "admin-1", Name: username}, nil)
+				userRepo.EXPECT().UpdatePassword(ctx, entity.UserIDEntity("admin-1"), "secret123").Return(nil)
+			},
+			wantPassword: "secret123",
+		},
+		{
+			name:     "empty password is generated",
+			username: username,
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().GetByUsername(ctx, username).Return(entity.UserEntity{}, false, nil)
+				userRepo.EXPECT().Create(ctx, username, adminRoles).Return(entity.UserEntity{ID: "admin-1", Name: username}, nil)
+				userRepo.EXPECT().UpdatePassword(ctx, entity.UserIDEntity("admin-1"), gomock.Any()).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			userRepo := mockgen.NewMockIUserRepository(ctrl)
+			if tt.setupMocks != nil {
+				tt.setupMocks(ctx, userRepo)
+			}
+
+			svc := NewUserService(userRepo, nil, nil, nil, nil, config.Config{})
+			user, password, err := svc.CreateAdmin(ctx, tt.username, tt.password)
+
+			if tt.wantErrCode != nil {
+				var ecErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &ecErr)
+				require.Equal(t, tt.wantErrCode.Code, ecErr.ErrorCode.Code)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, entity.UserIDEntity("admin-1"), user.ID)
+			if tt.wantPassword != "" {
+				require.Equal(t, tt.wantPassword, password)
+			} else {
+				require.Len(t, password, 24)
+			}
+		})
+	}
+}
+
+func TestUserService_CreateUserByAdmin(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	userRepo := mockgen.NewMockIUserRepository(ctrl)
+
+	// the registration setting is not read, the settings service is nil
+	userRepo.EXPECT().GetByUsername(ctx, "alice").Return(entity.UserEntity{}, false, nil)
+	userRepo.EXPECT().Create(ctx, "alice", []entity.UserRoleEntity{entity.UserRoleUser}).Return(entity.UserEntity{ID: "user-1", Name: "alice"}, nil)
+	userRepo.EXPECT().UpdatePassword(ctx, entity.UserIDEntity("user-1"), gomock.Any()).Return(nil)
+
+	svc := NewUserService(userRepo, nil, nil, nil, nil, config.Config{})
+	user, password, err := svc.CreateUserByAdmin(ctx, "alice", "")
+	require.NoError(t, err)
+	require.Equal(t, entity.UserIDEntity("user-1"), user.ID)
+	require.Len(t, password, 24)
+}
+
+func TestUserService_ResetPassword(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+	const userID = entity.UserIDEntity("user-1")
+
+	tests := []struct {
+		name         string
+		password     string
+		setupMocks   func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessTokenRepo *mockgen.MockIAuthAccessTokenRepository, refreshTokenRepo *mockgen.MockIAuthRefreshTokenRepository)
+		wantPassword string
+		wantErrCode  *error_code.ErrorCode
+	}{
+		{
+			name: "unknown user is rejected",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, _ *mockgen.MockIAuthAccessTokenRepository, _ *mockgen.MockIAuthRefreshTokenRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{}, false, nil)
+			},
+			wantErrCode: &error_code.UserNotFound,
+		},
+		{
+			name:     "short password is rejected",
+			password: "short",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, _ *mockgen.MockIAuthAccessTokenRepository, _ *mockgen.MockIAuthRefreshTokenRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID}, true, nil)
+			},
+			wantErrCode: &error_code.InvalidRequestParameters,
+		},
+		{
+			name:     "given password is set and every session is revoked",
+			password: "secret123",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessTokenRepo *mockgen.MockIAuthAccessTokenRepository, refreshTokenRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID}, true, nil).Times(2)
+				userRepo.EXPECT().UpdatePassword(ctx, userID, "secret123").Return(nil)
+				accessTokenRepo.EXPECT().DeleteAllTokensByUserID(ctx, userID).Return(nil)
+				refreshTokenRepo.EXPECT().DeleteAllTokensByUserID(ctx, userID).Return(nil)
+			},
+			wantPassword: "secret123",
+		},
+		{
+			name: "empty password is generated",
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository, accessTokenRepo *mockgen.MockIAuthAccessTokenRepository, refreshTokenRepo *mockgen.MockIAuthRefreshTokenRepository) {
+				userRepo.EXPECT().GetByID(ctx, userID).Return(entity.UserEntity{ID: userID}, true, nil).Times(2)
+				userRepo.EXPECT().UpdatePassword(ctx, userID, gomock.Any()).Return(nil)
+				accessTokenRepo.EXPECT().DeleteAllTokensByUserID(ctx, userID).Return(nil)
+				refreshTokenRepo.EXPECT().DeleteAllTokensByUserID(ctx, userID).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			userRepo := mockgen.NewMockIUserRepository(ctrl)
+			accessTokenRepo := mockgen.NewMockIAuthAccessTokenRepository(ctrl)
+			refreshTokenRepo := mockgen.NewMockIAuthRefreshTokenRepository(ctrl)
+			tt.setupMocks(ctx, userRepo, accessTokenRepo, refreshTokenRepo)
+
+			svc := NewUserService(userRepo, accessTokenRepo, refreshTokenRepo, nil, nil, config.Config{})
+			password, err := svc.ResetPassword(ctx, userID, tt.password)
+
+			if tt.wantErrCode != nil {
+				var ecErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &ecErr)
+				require.Equal(t, tt.wantErrCode.Code, ecErr.ErrorCode.Code)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantPassword != "" {
+				require.Equal(t, tt.wantPassword, password)
+			} else {
+				require.Len(t, password, 24)
+			}
+		})
+	}
+}
+
+func TestUserService_BootstrapAdmin(t *testing.T) {
+	t.Parallel()
+
+	logger.InitLogger(config.Config{})
+
+	tests := []struct {
+		name       string
+		cfg        config.Config
+		setupMocks func(ctx context.Context, userRepo *mockgen.MockIUserRepository)
+		wantErrSub string
+	}{
+		{
+			name: "nothing configured does nothing",
+			cfg:  config.Config{},
+		},
+		{
+			name: "existing admin is left alone",
+			cfg:  config.Config{BootstrapAdminUsername: "root"},
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().HasUserWithRole(ctx, entity.UserRoleAdmin).Return(true, nil)
+			},
+		},
+		{
+			name: "first run creates the admin",
+			cfg:  config.Config{BootstrapAdminUsername: "root", BootstrapAdminPassword: "secret123"},
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().HasUserWithRole(ctx, entity.UserRoleAdmin).Return(false, nil)
+				userRepo.EXPECT().GetByUsername(ctx, "root").Return(entity.UserEntity{}, false, nil)
+				userRepo.EXPECT().
+					Create(ctx, "root", []entity.UserRoleEntity{entity.UserRoleUser, entity.UserRoleAdmin}).
+					Return(entity.UserEntity{ID: "admin-1", Name: "root"}, nil)
+				userRepo.EXPECT().UpdatePassword(ctx, entity.UserIDEntity("admin-1"), "secret123").Return(nil)
+			},
+		},
+		{
+			name: "username taken by a non admin fails",
+			cfg:  config.Config{BootstrapAdminUsername: "root"},
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().HasUserWithRole(ctx, entity.UserRoleAdmin).Return(false, nil)
+				userRepo.EXPECT().GetByUsername(ctx, "root").Return(entity.UserEntity{Name: "root"}, true, nil)
+			},
+			wantErrSub: "fail to create bootstrap admin root",
+		},
+		{
+			name: "checking admins fails",
+			cfg:  config.Config{BootstrapAdminUsername: "root"},
+			setupMocks: func(ctx context.Context, userRepo *mockgen.MockIUserRepository) {
+				userRepo.EXPECT().HasUserWithRole(ctx, entity.UserRoleAdmin).Return(false, errors.New("db offline"))
+			},
+			wantErrSub: "fail to check existing admin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ctrl := gomock.NewController(t)
+			userRepo := mockgen.NewMockIUserRepository(ctrl)
+			if tt.setupMocks != nil {
+				tt.setupMocks(ctx, userRepo)
+			}
+
+			svc := NewUserService(userRepo, nil, nil, newTestSystemSettingsService(ctrl, tt.cfg), nil, tt.cfg)
+			err := svc.BootstrapAdmin(ctx)
+
+			if tt.wantErrSub != "" {
+				require.ErrorContains(t, err, tt.wantErrSub)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// userSettingDefinition declares a preference users can store, the default applies until the user sets it.
+type userSettingDefinition struct {
+	Key          string
+	Type         entity.SystemSettingType
+	DefaultValue string
+	// Validate checks a new value after it was parsed as Type
+	Validate func(value string) error
+}
+
+var userSettingDefinitions = []userSettingDefinition{
+	{
+		Key:          entity.UserSettingKeyTheme,
+		Type:         entity.SystemSettingTypeString,
+		DefaultValue: "light",
+		Validate:     validateUserSettingOneOf("light", "dark"),
+	},
+	{
+		Key:          entity.UserSettingKeyEditorFontSize,
+		Type:         entity.SystemSettingTypeInt,
+		DefaultValue: "14",
+		Validate:     validateUserSettingRange(8, 40),
+	},
+	{
+		Key:          entity.UserSettingKeyEditorTabSize,
+		Type:         entity.SystemSettingTypeInt,
+		DefaultValue: "2",
+		Validate:     validateUserSettingRange(1, 8),
+	},
+	{
+		Key:          entity.UserSettingKeyEditorWordWrap,
+		Type:         entity.SystemSettingTypeBool,
+		DefaultValue: "false",
+	},
+	{
+		Key:          entity.UserSettingKeySyncEnabled,
+		Type:         entity.SystemSettingTypeBool,
+		DefaultValue: "true",
+	},
+}
+
+func validateUserSettingOneOf(values ...string) func(value string) error {
+	return func(value string) error {
+		if !slices.Contains(values, value) {
+			return errors.Errorf("must be one of %v", values)
+		}
+		return nil
+	}
+}
+
+func validateUserSettingRange(min int, max int) func(value string) error {
+	return func(value string) error {
+		parsed, _ := strconv.Atoi(value)
+		if parsed < min || parsed > max {
+			return errors.Errorf("must be between %d and %d", min, max)
+		}
+		return nil
+	}
+}
+
+func NewUserSettingsService(settingRepo repository.IUserSettingRepository, clock client.IClock) *UserSettingsService {
+	return &UserSettingsService{
+		settingRepo: settingRepo,
+		clock:       clock,
+	}
+}
+
+// UserSettingsService serves the preferences of users, stored on the server so they follow the user to every device.
+type UserSettingsService struct {
+	settingRepo repository.IUserSettingRepository
+	clock       client.IClock
+}
+
+// Settings lists every known preference with its effective value for the user.
+func (s *UserSettingsService) Settings(ctx context.Context, userID entity.UserIDEntity) ([]entity.UserSettingDetailEntity, error) {
+	stored, err := s.settingRepo.AllSettings(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to get settings of user %s", userID)
+	}
+	storedByKey := lo.SliceToMap(stored, func(setting entity.UserSettingEntity) (string, entity.UserSettingEntity) {
+		return setting.Key, setting
+	})
+
+	return lo.Map(userSettingDefinitions, func(def userSettingDefinition, _ int) entity.UserSettingDetailEntity {
+		detail := entity.UserSettingDetailEntity{
+			Key:          def.Key,
+			Type:         def.Type,
+			Value:        def.DefaultValue,
+			DefaultValue: def.DefaultValue,
+		}
+		// stored values are validated on write, a value that does not pass anymore falls back to the default
+		if setting, ok := storedByKey[def.Key]; ok {
+			if normalized, err := def.normalize(setting.Value); err == nil {
+				updatedAt := setting.UpdatedAt
+				detail.Value = normalized
+				detail.UpdatedAt = &updatedAt
+			}
+		}
+		return detail
+	}), nil
+}
+
+// StoredSettings returns the preferences the user has set, keyed by setting key, for exports.
+func (s *UserSettingsService) StoredSettings(ctx context.Context, userID entity.UserIDEntity) (map[string]string, error) {
+	details, err := s.Settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	stored := map[string]string{}
+	for _, detail := range details {
+		if detail.UpdatedAt != nil {
+			stored[detail.Key] = detail.Value
+		}
+	}
+	return stored, nil
+}
+
+// UpdateSettings sets several preferences of the user at once, a nil value resets the preference to its default.
+// Every value is validated before anything is stored, an unknown key or invalid value rejects the whole update.
+func (s *UserSettingsService) UpdateSettings(ctx context.Context, userID entity.UserIDEntity, values map[string]*string) ([]entity.UserSettingDetailEntity, error) {
+	now := s.clock.Now()
+	var saved []entity.UserSettingEntity
+	var reset []string
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		def, ok := findUserSettingDefinition(key)
+		if !ok {
+			return nil, error_code.NewErrorWithErrorCodeFAppendExtraData(error_code.InvalidUserSetting,
+				map[string]any{"key": key},
+				"unknown setting: %s", key)
+		}
+		if values[key] == nil {
+			reset = append(reset, key)
+			continue
+		}
+		normalized, err := def.normalize(*values[key])
+		if err != nil {
+			return nil, error_code.NewErrorWithErrorCodeFAppendExtraData(error_code.InvalidUserSetting,
+				map[string]any{"key": key},
+				"invalid value for setting %s: %s", key, err.Error())
+		}
+		saved = append(saved, entity.UserSettingEntity{Key: key, Value: normalized, UpdatedAt: now})
+	}
+
+	if err := s.settingRepo.SaveSettings(ctx, userID, saved); err != nil {
+		return nil, errors.Wrapf(err, "fail to save settings of user %s", userID)
+	}
+	if err := s.settingRepo.DeleteSettings(ctx, userID, reset); err != nil {
+		return nil, errors.Wrapf(err, "fail to reset settings of user %s", userID)
+	}
+
+	logger.Infof(ctx, "Updated %d and reset %d settings of user %s", len(saved), len(reset), userID)
+	return s.Settings(ctx, userID)
+}
+
+// ImportSettings stores the preferences of an export. The user's own preferences are kept unless overwrite is set.
+// Unknown keys and invalid values are skipped, the export may come from another version of the instance.
+// Returns how many preferences were imported.
+func (s *UserSettingsService) ImportSettings(ctx context.Context, userID entity.UserIDEntity, values map[string]string, overwrite bool) (int, error) {
+	if len(values) == 0 {
+		return 0, nil
+	}
+	existing, err := s.StoredSettings(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.clock.Now()
+	var saved []entity.UserSettingEntity
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		def, ok := findUserSettingDefinition(key)
+		if !ok {
+			continue
+		}
+		normalized, err := def.normalize(values[key])
+		if err != nil {
+			continue
+		}
+		if _, set := existing[key]; set && !overwrite {
+			continue
+		}
+		saved = append(saved, entity.UserSettingEntity{Key: key, Value: normalized, UpdatedAt: now})
+	}
+
+	if err := s.settingRepo.SaveSettings(ctx, userID, saved); err != nil {
+		return 0, errors.Wrapf(err, "fail to import settings of user %s", userID)
+	}
+	return len(saved), nil
+}
+
+// normalize parses a value as the setting type and validates it, returns its canonical string form.
+func (def userSettingDefinition) normalize(value string) (string, error) {
+	normalized, err := normalizeSystemSettingValue(def.Type, value)
+	if err == nil && def.Validate != nil {
+		err = def.Validate(normalized)
+	}
+	return normalized, err
+}
+
+func findUserSettingDefinition(key string) (userSettingDefinition, bool) {
+	return lo.Find(userSettingDefinitions, func(def userSettingDefinition) bool {
+		return def.Key == key
+	})
+}
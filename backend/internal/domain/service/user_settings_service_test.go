@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+)
+
+func TestUserSettingsService_Settings(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	userID := entity.UserIDEntity("user-1")
+	updatedAt := time.Unix(100, 0)
+	settingRepo := mockgen.NewMockIUserSettingRepository(ctrl)
+	settingRepo.EXPECT().AllSettings(gomock.Any(), userID).Return([]entity.UserSettingEntity{
+		{Key: entity.UserSettingKeyTheme, Value: "dark", UpdatedAt: updatedAt},
+		// a value that does not pass the validation anymore falls back to the default
+		{Key: entity.UserSettingKeyEditorTabSize, Value: "100", UpdatedAt: updatedAt},
+		{Key: "removed.setting", Value: "1", UpdatedAt: updatedAt},
+	}, nil)
+
+	svc := NewUserSettingsService(settingRepo, fixtures.NewFakeClock(time.Now()))
+	settings, err := svc.Settings(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, settings, len(userSettingDefinitions))
+
+	byKey := lo.KeyBy(settings, func(setting entity.UserSettingDetailEntity) string { return setting.Key })
+	require.Equal(t, "dark", byKey[entity.UserSettingKeyTheme].Value)
+	require.Equal(t, "light", byKey[entity.UserSettingKeyTheme].DefaultValue)
+	require.Equal(t, updatedAt, *byKey[entity.UserSettingKeyTheme].UpdatedAt)
+	require.Equal(t, "2", byKey[entity.UserSettingKeyEditorTabSize].Value)
+	require.Nil(t, byKey[entity.UserSettingKeyEditorTabSize].UpdatedAt)
+	require.Equal(t, entity.SystemSettingTypeBool, byKey[entity.UserSettingKeySyncEnabled].Type)
+	require.Equal(t, "true", byKey[entity.UserSettingKeySyncEnabled].Value)
+}
+
+func TestUserSettingsService_UpdateSettings(t *testing.T) {
+	t.Parallel()
+
+	userID := entity.UserIDEntity("user-1")
+	now := time.Unix(200, 0)
+
+	tests := []struct {
+		name        string
+		values      map[string]*string
+		setupMocks  func(settingRepo *mockgen.MockIUserSettingRepository)
+		wantErrCode *error_code.ErrorCode
+		wantErrSub  string
+	}{
+		{
+			name:        "unknown key rejects the whole update",
+			values:      map[string]*string{entity.UserSettingKeyTheme: lo.ToPtr("dark"), "unknown.key": lo.ToPtr("1")},
+			wantErrCode: &error_code.InvalidUserSetting,
+		},
+		{
+			name:        "value must match the setting type",
+			values:      map[string]*string{entity.UserSettingKeyEditorWordWrap: lo.ToPtr("sometimes")},
+			wantErrCode: &error_code.InvalidUserSetting,
+		},
+		{
+			name:        "enum is validated",
+			values:      map[string]*string{entity.UserSettingKeyTheme: lo.ToPtr("purple")},
+			wantErrCode: &error_code.InvalidUserSetting,
+		},
+		{
+			name:        "int range is validated",
+			values:      map[string]*string{entity.UserSettingKeyEditorFontSize: lo.ToPtr("4")},
+			wantErrCode: &error_code.InvalidUserSetting,
+		},
+		{
+			name: "values are normalized and saved, nil resets to the default",
+			values: map[string]*string{
+				entity.UserSettingKeyEditorWordWrap: lo.ToPtr("1"),
+				entity.UserSettingKeyEditorFontSize: lo.ToPtr("16"),
+				entity.UserSettingKeyTheme:          nil,
+			},
+			setupMocks: func(settingRepo *mockgen.MockIUserSettingRepository) {
+				settingRepo.EXPECT().SaveSettings(gomock.Any(), userID, []entity.UserSettingEntity{
+					{Key: entity.UserSettingKeyEditorFontSize, Value: "16", UpdatedAt: now},
+					{Key: entity.UserSettingKeyEditorWordWrap, Value: "true", UpdatedAt: now},
+				}).Return(nil)
+				settingRepo.EXPECT().DeleteSettings(gomock.Any(), userID, []string{entity.UserSettingKeyTheme}).Return(nil)
+				settingRepo.EXPECT().AllSettings(gomock.Any(), userID).Return(nil, nil)
+			},
+		},
+		{
+			name:   "repository error is wrapped",
+			values: map[string]*string{entity.UserSettingKeyTheme: lo.ToPtr("dark")},
+			setupMocks: func(settingRepo *mockgen.MockIUserSettingRepository) {
+				settingRepo.EXPECT().SaveSettings(gomock.Any(), userID, gomock.Any()).Return(errors.New("db offline"))
+			},
+			wantErrSub: "fail to save settings",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			settingRepo := mockgen.NewMockIUserSettingRepository(ctrl)
+			if tt.setupMocks != nil {
+				tt.setupMocks(settingRepo)
+			}
+
+			svc := NewUserSettingsService(settingRepo, fixtures.NewFakeClock(now))
+			_, err := svc.UpdateSettings(context.Background(), userID, tt.values)
+			switch {
+			case tt.wantErrCode != nil:
+				var codeErr error_code.ErrorWithErrorCode
+				require.ErrorAs(t, err, &codeErr)
+				require.Equal(t, *tt.wantErrCode, codeErr.ErrorCode)
+			case tt.wantErrSub != "":
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErrSub)
+			default:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
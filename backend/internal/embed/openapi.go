@@ -0,0 +1,9 @@
+package embed
+
+import _ "embed"
+
+// OpenAPISpec embeds the swagger spec generated by `task swag-generate`.
+// It is the static base document, the openapi controller adapts it to the running instance.
+//
+//go:embed openapi/swagger.json
+var OpenAPISpec []byte
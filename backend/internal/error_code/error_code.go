@@ -19,6 +19,8 @@ var (
 	// SystemError
 	InternalServerError      = reg(ErrorCode{"InternalServerError", "Internal server error", 500})
 	InvalidRequestParameters = reg(ErrorCode{"InvalidParameters", "Invalid Request parameters", 400})
+	ServiceNotReady          = reg(ErrorCode{"ServiceNotReady", "Service is not ready", 503})
+	DemoModeReadonly         = reg(ErrorCode{"DemoModeReadonly", "This is a read-only demo, changes are not saved", 403})
 
 	// AuthError
 	Unauthorized                    = reg(ErrorCode{"Unauthorized", "Unauthorized", 401})
@@ -28,18 +30,158 @@ var (
 	OauthTokenUnavailable           = reg(ErrorCode{"OauthTokenUnavailable", "OAuth token unavailable", 400})
 	PasswordLoginIsNotEnabled       = reg(ErrorCode{"PasswordLoginIsNotEnabled", "Password login is not enabled", 403})
 	UserRegistrationIsNotEnabled    = reg(ErrorCode{"UserRegistrationIsNotEnabled", "User registration is not enabled", 403})
+	SSOProviderIsNotEnabled         = reg(ErrorCode{"SSOProviderIsNotEnabled", "SSO provider is not enabled", 403})
 	SSOProviderAccountAlreadyBinded = reg(ErrorCode{"SSOProviderAccountAlreadyBinded", "A SSO provider account is already binded to this user, please remove binding first", 409})
 	CannotDeleteLastSSOBinding      = reg(ErrorCode{"CannotDeleteLastSSOBinding", "Cannot delete the last SSO binding, user must have at least one login method", 400})
 	TwoFaAlreadyEnabled             = reg(ErrorCode{"TwoFaAlreadyEnabled", "Two-factor authentication is already enabled", 409})
 	TwoFaTotpIsRequiredForLogin     = reg(ErrorCode{"TwoFaTotpIsRequiredForLogin", "Two-factor TOTP code is required for login", 401})
 	InvalidRecoveryCode             = reg(ErrorCode{"InvalidRecoveryCode", "Invalid recovery code", 400})
+	TwoFaTokenInvalid               = reg(ErrorCode{"TwoFaTokenInvalid", "Two-factor setup token is expired or invalid", 400})
+	TwoFaMethodNotEnabled           = reg(ErrorCode{"TwoFaMethodNotEnabled", "The two-factor method is not enabled for the user", 400})
+	TwoFaCodeRequired               = reg(ErrorCode{"TwoFaCodeRequired", "A two-factor code is required to confirm this change", 403})
+	PasskeyChallengeRateLimited     = reg(ErrorCode{"PasskeyChallengeRateLimited", "Too many passkey login attempts, try again later", 429})
+	PasskeyChallengeLimitReached    = reg(ErrorCode{"PasskeyChallengeLimitReached", "Too many passkey logins in progress, try again later", 503})
+	PasskeyNotFound                 = reg(ErrorCode{"PasskeyNotFound", "Passkey not found", 404})
+	SessionNotFound                 = reg(ErrorCode{"SessionNotFound", "Session not found, it may have expired or been signed out", 404})
+	TooManyAttempts                 = reg(ErrorCode{"TooManyAttempts", "Too many failed attempts, sign in again", 429})
 
 	InvalidTotpCode = reg(ErrorCode{"InvalidTotpCode", "Invalid TOTP code", 400})
 	// UserError
-	UserNotFound       = reg(ErrorCode{"UserNotFound", "User not found", 404})
-	InvalidCredentials = reg(ErrorCode{"InvalidCredentials", "Invalid username or password", 401})
-	UserAlreadyExists  = reg(ErrorCode{"UserAlreadyExists", "User already exists", 409})
-	Forbidden          = reg(ErrorCode{"Forbidden", "Forbidden", 403})
+	UserNotFound           = reg(ErrorCode{"UserNotFound", "User not found", 404})
+	InvalidCredentials     = reg(ErrorCode{"InvalidCredentials", "Invalid username or password", 401})
+	UserAlreadyExists      = reg(ErrorCode{"UserAlreadyExists", "User already exists", 409})
+	Forbidden              = reg(ErrorCode{"Forbidden", "Forbidden", 403})
+	UserMergeSameUser      = reg(ErrorCode{"UserMergeSameUser", "An account can not be merged into itself", 400})
+	UserMergeConflict      = reg(ErrorCode{"UserMergeConflict", "Both accounts are bound to the same SSO provider, unbind one first", 409})
+	PasswordChangeRequired = reg(ErrorCode{"PasswordChangeRequired", "The password must be changed before continuing", 403})
+	UserDisabled           = reg(ErrorCode{"UserDisabled", "The account is disabled, contact an administrator", 403})
+	AdminSelfLockout       = reg(ErrorCode{"AdminSelfLockout", "Admins can not disable their own account or remove their own admin role", 400})
+	UnknownUserRole        = reg(ErrorCode{"UnknownUserRole", "Unknown user role", 400})
+
+	// AccountRecoveryError
+	AccountRecoveryUnavailable      = reg(ErrorCode{"AccountRecoveryUnavailable", "Account recovery is not available, contact an administrator", 503})
+	InvalidAccountRecoveryCode      = reg(ErrorCode{"InvalidAccountRecoveryCode", "Account recovery code is expired or invalid", 400})
+	AccountRecoveryAlreadyRequested = reg(ErrorCode{"AccountRecoveryAlreadyRequested", "An account recovery is already in progress for this account", 409})
+	AccountRecoveryNotFound         = reg(ErrorCode{"AccountRecoveryNotFound", "Account recovery request not found", 404})
+	AccountRecoveryNotApproved      = reg(ErrorCode{"AccountRecoveryNotApproved", "Account recovery request is not approved yet", 403})
+	AccountRecoveryNotYetAvailable  = reg(ErrorCode{"AccountRecoveryNotYetAvailable", "Account recovery can not be completed before its delay is over", 403})
+	AccountRecoveryClosed           = reg(ErrorCode{"AccountRecoveryClosed", "Account recovery request was rejected, cancelled or already completed", 409})
+
+	// AccountDeletionError
+	AccountDeletionNotScheduled = reg(ErrorCode{"AccountDeletionNotScheduled", "No deletion is scheduled for this account", 409})
+
+	// EmailVerificationError
+	EmailVerificationUnavailable = reg(ErrorCode{"EmailVerificationUnavailable", "Email verification is not available, contact an administrator", 503})
+	EmailVerificationThrottled   = reg(ErrorCode{"EmailVerificationThrottled", "A verification code was sent less than a minute ago, try again later", 429})
+	InvalidEmailVerificationCode = reg(ErrorCode{"InvalidEmailVerificationCode", "Email verification code is expired or invalid", 400})
+	EmailAlreadyInUse            = reg(ErrorCode{"EmailAlreadyInUse", "The email is already used by another account", 409})
+
+	// PasswordResetError
+	PasswordResetUnavailable  = reg(ErrorCode{"PasswordResetUnavailable", "Password reset is not available, contact an administrator", 503})
+	InvalidPasswordResetToken = reg(ErrorCode{"InvalidPasswordResetToken", "Password reset token is expired or invalid", 400})
+
+	// ToolError
+	ToolNotFound              = reg(ErrorCode{"ToolNotFound", "Tool not found", 404})
+	ToolCategoryNotFound      = reg(ErrorCode{"ToolCategoryNotFound", "Tool category not found", 404})
+	ToolCategoryAlreadyExists = reg(ErrorCode{"ToolCategoryAlreadyExists", "Tool category already exists, merge the categories instead", 409})
+	ToolNameAlreadyExists     = reg(ErrorCode{"ToolNameAlreadyExists", "A tool with the same name already exists in the namespace", 409})
+	ToolIDAlreadyExists       = reg(ErrorCode{"ToolIDAlreadyExists", "A tool with the same id already exists", 409})
+	ToolUIDConflict           = reg(ErrorCode{"ToolUIDConflict", "No free unique id could be generated for the tool, try again", 409})
+	InvalidToolExtraInfo      = reg(ErrorCode{"InvalidToolExtraInfo", "Invalid tool extra info", 400})
+	InvalidToolTag            = reg(ErrorCode{"InvalidToolTag", "Invalid tool tag", 400})
+	ToolQuotaExceeded         = reg(ErrorCode{"ToolQuotaExceeded", "Tool quota exceeded", 403})
+	ToolSourceContainsSecret  = reg(ErrorCode{"ToolSourceContainsSecret", "Tool source contains credentials", 400})
+	ToolSchemaUnavailable     = reg(ErrorCode{"ToolSchemaUnavailable", "Tool schema can not be resolved, check the ui widgets and the declared schemas", 422})
+	ToolNameAmbiguous         = reg(ErrorCode{"ToolNameAmbiguous", "Several active tools have this namespace and name, rename one of them", 409})
+	ToolInputInvalid          = reg(ErrorCode{"ToolInputInvalid", "The inputs do not match the input schema of the tool", 400})
+	ToolSecretNotFound        = reg(ErrorCode{"ToolSecretNotFound", "Tool secret not found", 404})
+	InvalidToolSecret         = reg(ErrorCode{"InvalidToolSecret", "Invalid tool secret", 400})
+	ToolSecretQuotaExceeded   = reg(ErrorCode{"ToolSecretQuotaExceeded", "Too many tool secrets", 403})
+
+	ToolExecutionDisabled    = reg(ErrorCode{"ToolExecutionDisabled", "Running tools on the server is disabled on this deployment", 403})
+	ToolExecutionFailed      = reg(ErrorCode{"ToolExecutionFailed", "The tool handler failed", 422})
+	ToolExecutionTimedOut    = reg(ErrorCode{"ToolExecutionTimedOut", "The tool handler did not finish in time", 422})
+	ToolExecutionTooLarge    = reg(ErrorCode{"ToolExecutionTooLarge", "The tool inputs or outputs exceed the size limit", 413})
+	ToolExecutionOutOfMemory = reg(ErrorCode{"ToolExecutionOutOfMemory", "The tool handler used more memory than allowed", 422})
+	ToolExecutionRateLimited = reg(ErrorCode{"ToolExecutionRateLimited", "Too many tool runs, try again later", 429})
+	ToolExecutionBusy        = reg(ErrorCode{"ToolExecutionBusy", "Too many tool runs at the same time, wait for one to finish", 429})
+
+	ToolScheduleNotFound      = reg(ErrorCode{"ToolScheduleNotFound", "Tool schedule not found", 404})
+	InvalidToolSchedule       = reg(ErrorCode{"InvalidToolSchedule", "Invalid tool schedule", 400})
+	ToolScheduleQuotaExceeded = reg(ErrorCode{"ToolScheduleQuotaExceeded", "Too many tool schedules, delete one first", 403})
+
+	ToolVersionNotFound = reg(ErrorCode{"ToolVersionNotFound", "Tool version not found", 404})
+
+	NamespaceNotFound         = reg(ErrorCode{"NamespaceNotFound", "Namespace not found", 404})
+	NamespaceAlreadyExists    = reg(ErrorCode{"NamespaceAlreadyExists", "Namespace already exists", 409})
+	InvalidNamespaceUiWidgets = reg(ErrorCode{"InvalidNamespaceUiWidgets", "Default ui widgets of the namespace must be a json array", 400})
+
+	ToolShareNotFound       = reg(ErrorCode{"ToolShareNotFound", "Shared tool not found", 404})
+	InvalidToolShare        = reg(ErrorCode{"InvalidToolShare", "A tool can not be shared with its owner", 400})
+	ToolShareForkNotAllowed = reg(ErrorCode{"ToolShareForkNotAllowed", "The share does not allow forking the tool", 403})
+
+	// GalleryError
+	GalleryToolNotFound        = reg(ErrorCode{"GalleryToolNotFound", "Gallery tool not found", 404})
+	ArchivedToolNotPublishable = reg(ErrorCode{"ArchivedToolNotPublishable", "An archived tool can not be published, restore it first", 400})
+	GalleryToolIsYours         = reg(ErrorCode{"GalleryToolIsYours", "The gallery tool is already yours", 400})
+
+	// SyncError
+	DeviceNotFound    = reg(ErrorCode{"DeviceNotFound", "Device not found, log in again to register this device", 404})
+	InvalidSyncCursor = reg(ErrorCode{"InvalidSyncCursor", "Sync cursor is ahead of the latest change", 400})
+
+	// ImportError
+	ImportTooManyFiles    = reg(ErrorCode{"ImportTooManyFiles", "Import has too many files", 400})
+	ImportFileTooLarge    = reg(ErrorCode{"ImportFileTooLarge", "Imported file exceeds the size limit", 413})
+	ImportContentRejected = reg(ErrorCode{"ImportContentRejected", "Imported content was rejected by the scanner", 400})
+	ImportScanFailed      = reg(ErrorCode{"ImportScanFailed", "Imported content could not be scanned", 503})
+	InvalidToolBundle     = reg(ErrorCode{"InvalidToolBundle", "The tool bundle is invalid or of an unsupported version", 400})
+
+	// GithubImportError
+	GithubAccountNotAuthorized = reg(ErrorCode{"GithubAccountNotAuthorized", "No usable GitHub authorization, bind the GitHub account again", 403})
+	GithubRepositoryNotFound   = reg(ErrorCode{"GithubRepositoryNotFound", "GitHub repository or gist not found", 404})
+	GithubRateLimited          = reg(ErrorCode{"GithubRateLimited", "GitHub API rate limit exceeded, try again later", 429})
+	GithubUnavailable          = reg(ErrorCode{"GithubUnavailable", "GitHub API request failed", 502})
+
+	// UsageError
+	UsageLimitExceeded = reg(ErrorCode{"UsageLimitExceeded", "Usage limit exceeded", 402})
+
+	// SystemSettingError
+	SystemSettingNotFound     = reg(ErrorCode{"SystemSettingNotFound", "System setting not found", 404})
+	InvalidSystemSettingValue = reg(ErrorCode{"InvalidSystemSettingValue", "Invalid system setting value", 400})
+
+	// UserSettingError
+	InvalidUserSetting = reg(ErrorCode{"InvalidUserSetting", "Invalid user setting", 400})
+
+	// ScheduledJobError
+	ScheduledJobNotFound       = reg(ErrorCode{"ScheduledJobNotFound", "Scheduled job not found", 404})
+	ScheduledJobAlreadyRunning = reg(ErrorCode{"ScheduledJobAlreadyRunning", "Scheduled job is already running", 409})
+
+	// UserDataExportError
+	UserDataExportNotFound   = reg(ErrorCode{"UserDataExportNotFound", "Data export not found or expired", 404})
+	UserDataExportInProgress = reg(ErrorCode{"UserDataExportInProgress", "A data export is already in progress", 409})
+	UserDataExportNotReady   = reg(ErrorCode{"UserDataExportNotReady", "The data export is not finished", 409})
+
+	// BackupError
+	BackupNotFound       = reg(ErrorCode{"BackupNotFound", "Backup not found", 404})
+	BackupAlreadyRunning = reg(ErrorCode{"BackupAlreadyRunning", "A backup is already running", 409})
+	BackupNotSupported   = reg(ErrorCode{"BackupNotSupported", "This instance keeps no store on local disk to back up", 400})
+
+	// OidcError
+	OidcProviderDisabled             = reg(ErrorCode{"OidcProviderDisabled", "OpenID Connect provider is disabled", 404})
+	OidcClientNotFound               = reg(ErrorCode{"OidcClientNotFound", "OpenID Connect client not found", 404})
+	InvalidOidcClient                = reg(ErrorCode{"InvalidOidcClient", "Invalid OpenID Connect client", 401})
+	InvalidOidcRequest               = reg(ErrorCode{"InvalidOidcRequest", "Invalid OpenID Connect request", 400})
+	InvalidOidcGrant                 = reg(ErrorCode{"InvalidOidcGrant", "Invalid or expired OpenID Connect grant", 400})
+	UnsupportedOidcGrantType         = reg(ErrorCode{"UnsupportedOidcGrantType", "Unsupported OpenID Connect grant type", 400})
+	InvalidOidcAccessToken           = reg(ErrorCode{"InvalidOidcAccessToken", "Invalid OpenID Connect access token", 401})
+	OidcAuthorizationRequestNotFound = reg(ErrorCode{"OidcAuthorizationRequestNotFound", "OpenID Connect authorization request not found or expired", 404})
+
+	// GraphQLError
+	GraphQLDisabled = reg(ErrorCode{"GraphQLDisabled", "GraphQL endpoint is disabled", 404})
+
+	// AnnouncementError
+	AnnouncementNotFound = reg(ErrorCode{"AnnouncementNotFound", "Announcement not found", 404})
+	InvalidAnnouncement  = reg(ErrorCode{"InvalidAnnouncement", "Invalid announcement", 400})
 
 	// FileStorageError
 	FileNotFound         = reg(ErrorCode{"FileNotFound", "File not found", 404})
@@ -4,32 +4,133 @@ package error_code
 type ErrorCodeConst string
 
 const (
-	ErrorCodeCannotDeleteLastSSOBinding      ErrorCodeConst = "CannotDeleteLastSSOBinding"
-	ErrorCodeDirectoryNotFound               ErrorCodeConst = "DirectoryNotFound"
-	ErrorCodeFileAlreadyExists               ErrorCodeConst = "FileAlreadyExists"
-	ErrorCodeFileNotFound                    ErrorCodeConst = "FileNotFound"
-	ErrorCodeFileOperationFailed             ErrorCodeConst = "FileOperationFailed"
-	ErrorCodeFileTooLarge                    ErrorCodeConst = "FileTooLarge"
-	ErrorCodeForbidden                       ErrorCodeConst = "Forbidden"
-	ErrorCodeInternalServerError             ErrorCodeConst = "InternalServerError"
-	ErrorCodeInvalidAccessToken              ErrorCodeConst = "InvalidAccessToken"
-	ErrorCodeInvalidCredentials              ErrorCodeConst = "InvalidCredentials"
-	ErrorCodeInvalidFilePath                 ErrorCodeConst = "InvalidFilePath"
-	ErrorCodeInvalidFileType                 ErrorCodeConst = "InvalidFileType"
-	ErrorCodeInvalidParameter                ErrorCodeConst = "InvalidParameter"
-	ErrorCodeInvalidParameters               ErrorCodeConst = "InvalidParameters"
-	ErrorCodeInvalidRecoveryCode             ErrorCodeConst = "InvalidRecoveryCode"
-	ErrorCodeInvalidRefreshToken             ErrorCodeConst = "InvalidRefreshToken"
-	ErrorCodeInvalidTotpCode                 ErrorCodeConst = "InvalidTotpCode"
-	ErrorCodeOauthTokenUnavailable           ErrorCodeConst = "OauthTokenUnavailable"
-	ErrorCodePasswordLoginIsNotEnabled       ErrorCodeConst = "PasswordLoginIsNotEnabled"
-	ErrorCodeSSOProviderAccountAlreadyBinded ErrorCodeConst = "SSOProviderAccountAlreadyBinded"
-	ErrorCodeStorageQuotaExceeded            ErrorCodeConst = "StorageQuotaExceeded"
-	ErrorCodeTokenNotFound                   ErrorCodeConst = "TokenNotFound"
-	ErrorCodeTwoFaAlreadyEnabled             ErrorCodeConst = "TwoFaAlreadyEnabled"
-	ErrorCodeTwoFaTotpIsRequiredForLogin     ErrorCodeConst = "TwoFaTotpIsRequiredForLogin"
-	ErrorCodeUnauthorized                    ErrorCodeConst = "Unauthorized"
-	ErrorCodeUserAlreadyExists               ErrorCodeConst = "UserAlreadyExists"
-	ErrorCodeUserNotFound                    ErrorCodeConst = "UserNotFound"
-	ErrorCodeUserRegistrationIsNotEnabled    ErrorCodeConst = "UserRegistrationIsNotEnabled"
+	ErrorCodeAccountDeletionNotScheduled      ErrorCodeConst = "AccountDeletionNotScheduled"
+	ErrorCodeAccountRecoveryAlreadyRequested  ErrorCodeConst = "AccountRecoveryAlreadyRequested"
+	ErrorCodeAccountRecoveryClosed            ErrorCodeConst = "AccountRecoveryClosed"
+	ErrorCodeAccountRecoveryNotApproved       ErrorCodeConst = "AccountRecoveryNotApproved"
+	ErrorCodeAccountRecoveryNotFound          ErrorCodeConst = "AccountRecoveryNotFound"
+	ErrorCodeAccountRecoveryNotYetAvailable   ErrorCodeConst = "AccountRecoveryNotYetAvailable"
+	ErrorCodeAccountRecoveryUnavailable       ErrorCodeConst = "AccountRecoveryUnavailable"
+	ErrorCodeAdminSelfLockout                 ErrorCodeConst = "AdminSelfLockout"
+	ErrorCodeAnnouncementNotFound             ErrorCodeConst = "AnnouncementNotFound"
+	ErrorCodeArchivedToolNotPublishable       ErrorCodeConst = "ArchivedToolNotPublishable"
+	ErrorCodeBackupAlreadyRunning             ErrorCodeConst = "BackupAlreadyRunning"
+	ErrorCodeBackupNotFound                   ErrorCodeConst = "BackupNotFound"
+	ErrorCodeBackupNotSupported               ErrorCodeConst = "BackupNotSupported"
+	ErrorCodeCannotDeleteLastSSOBinding       ErrorCodeConst = "CannotDeleteLastSSOBinding"
+	ErrorCodeDemoModeReadonly                 ErrorCodeConst = "DemoModeReadonly"
+	ErrorCodeDeviceNotFound                   ErrorCodeConst = "DeviceNotFound"
+	ErrorCodeDirectoryNotFound                ErrorCodeConst = "DirectoryNotFound"
+	ErrorCodeEmailAlreadyInUse                ErrorCodeConst = "EmailAlreadyInUse"
+	ErrorCodeEmailVerificationThrottled       ErrorCodeConst = "EmailVerificationThrottled"
+	ErrorCodeEmailVerificationUnavailable     ErrorCodeConst = "EmailVerificationUnavailable"
+	ErrorCodeFileAlreadyExists                ErrorCodeConst = "FileAlreadyExists"
+	ErrorCodeFileNotFound                     ErrorCodeConst = "FileNotFound"
+	ErrorCodeFileOperationFailed              ErrorCodeConst = "FileOperationFailed"
+	ErrorCodeFileTooLarge                     ErrorCodeConst = "FileTooLarge"
+	ErrorCodeForbidden                        ErrorCodeConst = "Forbidden"
+	ErrorCodeGalleryToolIsYours               ErrorCodeConst = "GalleryToolIsYours"
+	ErrorCodeGalleryToolNotFound              ErrorCodeConst = "GalleryToolNotFound"
+	ErrorCodeGithubAccountNotAuthorized       ErrorCodeConst = "GithubAccountNotAuthorized"
+	ErrorCodeGithubRateLimited                ErrorCodeConst = "GithubRateLimited"
+	ErrorCodeGithubRepositoryNotFound         ErrorCodeConst = "GithubRepositoryNotFound"
+	ErrorCodeGithubUnavailable                ErrorCodeConst = "GithubUnavailable"
+	ErrorCodeGraphQLDisabled                  ErrorCodeConst = "GraphQLDisabled"
+	ErrorCodeImportContentRejected            ErrorCodeConst = "ImportContentRejected"
+	ErrorCodeImportFileTooLarge               ErrorCodeConst = "ImportFileTooLarge"
+	ErrorCodeImportScanFailed                 ErrorCodeConst = "ImportScanFailed"
+	ErrorCodeImportTooManyFiles               ErrorCodeConst = "ImportTooManyFiles"
+	ErrorCodeInternalServerError              ErrorCodeConst = "InternalServerError"
+	ErrorCodeInvalidAccessToken               ErrorCodeConst = "InvalidAccessToken"
+	ErrorCodeInvalidAccountRecoveryCode       ErrorCodeConst = "InvalidAccountRecoveryCode"
+	ErrorCodeInvalidAnnouncement              ErrorCodeConst = "InvalidAnnouncement"
+	ErrorCodeInvalidCredentials               ErrorCodeConst = "InvalidCredentials"
+	ErrorCodeInvalidEmailVerificationCode     ErrorCodeConst = "InvalidEmailVerificationCode"
+	ErrorCodeInvalidFilePath                  ErrorCodeConst = "InvalidFilePath"
+	ErrorCodeInvalidFileType                  ErrorCodeConst = "InvalidFileType"
+	ErrorCodeInvalidNamespaceUiWidgets        ErrorCodeConst = "InvalidNamespaceUiWidgets"
+	ErrorCodeInvalidOidcAccessToken           ErrorCodeConst = "InvalidOidcAccessToken"
+	ErrorCodeInvalidOidcClient                ErrorCodeConst = "InvalidOidcClient"
+	ErrorCodeInvalidOidcGrant                 ErrorCodeConst = "InvalidOidcGrant"
+	ErrorCodeInvalidOidcRequest               ErrorCodeConst = "InvalidOidcRequest"
+	ErrorCodeInvalidParameter                 ErrorCodeConst = "InvalidParameter"
+	ErrorCodeInvalidParameters                ErrorCodeConst = "InvalidParameters"
+	ErrorCodeInvalidPasswordResetToken        ErrorCodeConst = "InvalidPasswordResetToken"
+	ErrorCodeInvalidRecoveryCode              ErrorCodeConst = "InvalidRecoveryCode"
+	ErrorCodeInvalidRefreshToken              ErrorCodeConst = "InvalidRefreshToken"
+	ErrorCodeInvalidSyncCursor                ErrorCodeConst = "InvalidSyncCursor"
+	ErrorCodeInvalidSystemSettingValue        ErrorCodeConst = "InvalidSystemSettingValue"
+	ErrorCodeInvalidToolBundle                ErrorCodeConst = "InvalidToolBundle"
+	ErrorCodeInvalidToolExtraInfo             ErrorCodeConst = "InvalidToolExtraInfo"
+	ErrorCodeInvalidToolSchedule              ErrorCodeConst = "InvalidToolSchedule"
+	ErrorCodeInvalidToolSecret                ErrorCodeConst = "InvalidToolSecret"
+	ErrorCodeInvalidToolShare                 ErrorCodeConst = "InvalidToolShare"
+	ErrorCodeInvalidToolTag                   ErrorCodeConst = "InvalidToolTag"
+	ErrorCodeInvalidTotpCode                  ErrorCodeConst = "InvalidTotpCode"
+	ErrorCodeInvalidUserSetting               ErrorCodeConst = "InvalidUserSetting"
+	ErrorCodeNamespaceAlreadyExists           ErrorCodeConst = "NamespaceAlreadyExists"
+	ErrorCodeNamespaceNotFound                ErrorCodeConst = "NamespaceNotFound"
+	ErrorCodeOauthTokenUnavailable            ErrorCodeConst = "OauthTokenUnavailable"
+	ErrorCodeOidcAuthorizationRequestNotFound ErrorCodeConst = "OidcAuthorizationRequestNotFound"
+	ErrorCodeOidcClientNotFound               ErrorCodeConst = "OidcClientNotFound"
+	ErrorCodeOidcProviderDisabled             ErrorCodeConst = "OidcProviderDisabled"
+	ErrorCodePasskeyChallengeLimitReached     ErrorCodeConst = "PasskeyChallengeLimitReached"
+	ErrorCodePasskeyChallengeRateLimited      ErrorCodeConst = "PasskeyChallengeRateLimited"
+	ErrorCodePasskeyNotFound                  ErrorCodeConst = "PasskeyNotFound"
+	ErrorCodePasswordChangeRequired           ErrorCodeConst = "PasswordChangeRequired"
+	ErrorCodePasswordLoginIsNotEnabled        ErrorCodeConst = "PasswordLoginIsNotEnabled"
+	ErrorCodePasswordResetUnavailable         ErrorCodeConst = "PasswordResetUnavailable"
+	ErrorCodeSSOProviderAccountAlreadyBinded  ErrorCodeConst = "SSOProviderAccountAlreadyBinded"
+	ErrorCodeSSOProviderIsNotEnabled          ErrorCodeConst = "SSOProviderIsNotEnabled"
+	ErrorCodeScheduledJobAlreadyRunning       ErrorCodeConst = "ScheduledJobAlreadyRunning"
+	ErrorCodeScheduledJobNotFound             ErrorCodeConst = "ScheduledJobNotFound"
+	ErrorCodeServiceNotReady                  ErrorCodeConst = "ServiceNotReady"
+	ErrorCodeSessionNotFound                  ErrorCodeConst = "SessionNotFound"
+	ErrorCodeStorageQuotaExceeded             ErrorCodeConst = "StorageQuotaExceeded"
+	ErrorCodeSystemSettingNotFound            ErrorCodeConst = "SystemSettingNotFound"
+	ErrorCodeTokenNotFound                    ErrorCodeConst = "TokenNotFound"
+	ErrorCodeTooManyAttempts                  ErrorCodeConst = "TooManyAttempts"
+	ErrorCodeToolCategoryAlreadyExists        ErrorCodeConst = "ToolCategoryAlreadyExists"
+	ErrorCodeToolCategoryNotFound             ErrorCodeConst = "ToolCategoryNotFound"
+	ErrorCodeToolExecutionBusy                ErrorCodeConst = "ToolExecutionBusy"
+	ErrorCodeToolExecutionDisabled            ErrorCodeConst = "ToolExecutionDisabled"
+	ErrorCodeToolExecutionFailed              ErrorCodeConst = "ToolExecutionFailed"
+	ErrorCodeToolExecutionOutOfMemory         ErrorCodeConst = "ToolExecutionOutOfMemory"
+	ErrorCodeToolExecutionRateLimited         ErrorCodeConst = "ToolExecutionRateLimited"
+	ErrorCodeToolExecutionTimedOut            ErrorCodeConst = "ToolExecutionTimedOut"
+	ErrorCodeToolExecutionTooLarge            ErrorCodeConst = "ToolExecutionTooLarge"
+	ErrorCodeToolIDAlreadyExists              ErrorCodeConst = "ToolIDAlreadyExists"
+	ErrorCodeToolInputInvalid                 ErrorCodeConst = "ToolInputInvalid"
+	ErrorCodeToolNameAlreadyExists            ErrorCodeConst = "ToolNameAlreadyExists"
+	ErrorCodeToolNameAmbiguous                ErrorCodeConst = "ToolNameAmbiguous"
+	ErrorCodeToolNotFound                     ErrorCodeConst = "ToolNotFound"
+	ErrorCodeToolQuotaExceeded                ErrorCodeConst = "ToolQuotaExceeded"
+	ErrorCodeToolScheduleNotFound             ErrorCodeConst = "ToolScheduleNotFound"
+	ErrorCodeToolScheduleQuotaExceeded        ErrorCodeConst = "ToolScheduleQuotaExceeded"
+	ErrorCodeToolSchemaUnavailable            ErrorCodeConst = "ToolSchemaUnavailable"
+	ErrorCodeToolSecretNotFound               ErrorCodeConst = "ToolSecretNotFound"
+	ErrorCodeToolSecretQuotaExceeded          ErrorCodeConst = "ToolSecretQuotaExceeded"
+	ErrorCodeToolShareForkNotAllowed          ErrorCodeConst = "ToolShareForkNotAllowed"
+	ErrorCodeToolShareNotFound                ErrorCodeConst = "ToolShareNotFound"
+	ErrorCodeToolSourceContainsSecret         ErrorCodeConst = "ToolSourceContainsSecret"
+	ErrorCodeToolUIDConflict                  ErrorCodeConst = "ToolUIDConflict"
+	ErrorCodeToolVersionNotFound              ErrorCodeConst = "ToolVersionNotFound"
+	ErrorCodeTwoFaAlreadyEnabled              ErrorCodeConst = "TwoFaAlreadyEnabled"
+	ErrorCodeTwoFaCodeRequired                ErrorCodeConst = "TwoFaCodeRequired"
+	ErrorCodeTwoFaMethodNotEnabled            ErrorCodeConst = "TwoFaMethodNotEnabled"
+	ErrorCodeTwoFaTokenInvalid                ErrorCodeConst = "TwoFaTokenInvalid"
+	ErrorCodeTwoFaTotpIsRequiredForLogin      ErrorCodeConst = "TwoFaTotpIsRequiredForLogin"
+	ErrorCodeUnauthorized                     ErrorCodeConst = "Unauthorized"
+	ErrorCodeUnknownUserRole                  ErrorCodeConst = "UnknownUserRole"
+	ErrorCodeUnsupportedOidcGrantType         ErrorCodeConst = "UnsupportedOidcGrantType"
+	ErrorCodeUsageLimitExceeded               ErrorCodeConst = "UsageLimitExceeded"
+	ErrorCodeUserAlreadyExists                ErrorCodeConst = "UserAlreadyExists"
+	ErrorCodeUserDataExportInProgress         ErrorCodeConst = "UserDataExportInProgress"
+	ErrorCodeUserDataExportNotFound           ErrorCodeConst = "UserDataExportNotFound"
+	ErrorCodeUserDataExportNotReady           ErrorCodeConst = "UserDataExportNotReady"
+	ErrorCodeUserDisabled                     ErrorCodeConst = "UserDisabled"
+	ErrorCodeUserMergeConflict                ErrorCodeConst = "UserMergeConflict"
+	ErrorCodeUserMergeSameUser                ErrorCodeConst = "UserMergeSameUser"
+	ErrorCodeUserNotFound                     ErrorCodeConst = "UserNotFound"
+	ErrorCodeUserRegistrationIsNotEnabled     ErrorCodeConst = "UserRegistrationIsNotEnabled"
 )
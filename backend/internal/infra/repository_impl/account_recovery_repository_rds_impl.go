@@ -0,0 +1,144 @@
+package repository_impl
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/config"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+type AccountRecoveryRequestRdsModel struct {
+	ID          string    `db:"id"`
+	UserID      string    `db:"user_id"`
+	Status      string    `db:"status"`
+	TokenHash   string    `db:"token_hash"`
+	CreatedAt   time.Time `db:"created_at"`
+	AvailableAt time.Time `db:"available_at"`
+	DecidedBy   *string   `db:"decided_by"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+func NewAccountRecoveryRepositoryRdsImpl(config config.Config, client repository.IRdsClient, clock domain_client.IClock) *AccountRecoveryRepositoryRdsImpl {
+	return &AccountRecoveryRepositoryRdsImpl{config: config, client: client, clock: clock}
+}
+
+type AccountRecoveryRepositoryRdsImpl struct {
+	config config.Config
+	client repository.IRdsClient
+	clock  domain_client.IClock
+}
+
+func (r *AccountRecoveryRepositoryRdsImpl) Create(ctx context.Context, request entity.AccountRecoveryRequestEntity) error {
+	_, err := r.client.DB().ExecContext(ctx,
+		`INSERT INTO account_recovery_requests (id, user_id, status, token_hash, created_at, available_at, decided_by, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		request.ID, string(request.UserID), string(request.Status), request.TokenHash,
+		request.CreatedAt, request.AvailableAt, userIDPtrToString(request.DecidedBy), request.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create account recovery request in rds")
+	}
+	return nil
+}
+
+func (r *AccountRecoveryRepositoryRdsImpl) GetByID(ctx context.Context, id string) (entity.AccountRecoveryRequestEntity, bool, error) {
+	var model AccountRecoveryRequestRdsModel
+	err := r.client.DB().GetContext(ctx, &model, "SELECT * FROM account_recovery_requests WHERE id = ?", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.AccountRecoveryRequestEntity{}, false, nil
+		}
+		return entity.AccountRecoveryRequestEntity{}, false, errors.Wrap(err, "failed to get account recovery request from rds")
+	}
+	return toAccountRecoveryRequestEntity(model), true, nil
+}
+
+func (r *AccountRecoveryRepositoryRdsImpl) List(ctx context.Context, status entity.AccountRecoveryStatus) ([]entity.AccountRecoveryRequestEntity, error) {
+	query := "SELECT * FROM account_recovery_requests ORDER BY created_at DESC, id"
+	args := []any{}
+	if status != "" {
+		query = "SELECT * FROM account_recovery_requests WHERE status = ? ORDER BY created_at DESC, id"
+		args = append(args, string(status))
+	}
+	return r.selectRequests(ctx, query, args...)
+}
+
+func (r *AccountRecoveryRepositoryRdsImpl) ListByUserID(ctx context.Context, userID entity.UserIDEntity) ([]entity.AccountRecoveryRequestEntity, error) {
+	return r.selectRequests(ctx,
+		"SELECT * FROM account_recovery_requests WHERE user_id = ? ORDER BY created_at DESC, id",
+		string(userID),
+	)
+}
+
+func (r *AccountRecoveryRepositoryRdsImpl) UpdateStatus(ctx context.Context, id string, from []entity.AccountRecoveryStatus, to entity.AccountRecoveryStatus, decidedBy *entity.UserIDEntity) (bool, error) {
+	if len(from) == 0 {
+		return false, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(from)), ", ")
+	args := []any{string(to), r.clock.Now()}
+	query := "UPDATE account_recovery_requests SET status = ?, updated_at = ?"
+	if decidedBy != nil {
+		query += ", decided_by = ?"
+		args = append(args, string(*decidedBy))
+	}
+	query += " WHERE id = ? AND status IN (" + placeholders + ")"
+	args = append(args, id)
+	for _, status := range from {
+		args = append(args, string(status))
+	}
+
+	result, err := r.client.DB().ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to update account recovery request in rds")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get affected rows")
+	}
+	return affected > 0, nil
+}
+
+func (r *AccountRecoveryRepositoryRdsImpl) selectRequests(ctx context.Context, query string, args ...any) ([]entity.AccountRecoveryRequestEntity, error) {
+	var models []AccountRecoveryRequestRdsModel
+	if err := r.client.DB().SelectContext(ctx, &models, query, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to list account recovery requests from rds")
+	}
+
+	requests := make([]entity.AccountRecoveryRequestEntity, 0, len(models))
+	for _, model := range models {
+		requests = append(requests, toAccountRecoveryRequestEntity(model))
+	}
+	return requests, nil
+}
+
+func userIDPtrToString(userID *entity.UserIDEntity) *string {
+	if userID == nil {
+		return nil
+	}
+	value := string(*userID)
+	return &value
+}
+
+func toAccountRecoveryRequestEntity(model AccountRecoveryRequestRdsModel) entity.AccountRecoveryRequestEntity {
+	var decidedBy *entity.UserIDEntity
+	if model.DecidedBy != nil {
+		userID := entity.UserIDEntity(*model.DecidedBy)
+		decidedBy = &userID
+	}
+	return entity.AccountRecoveryRequestEntity{
+		ID:          model.ID,
+		UserID:      entity.UserIDEntity(model.UserID),
+		Status:      entity.AccountRecoveryStatus(model.Status),
+		TokenHash:   model.TokenHash,
+		CreatedAt:   model.CreatedAt,
+		AvailableAt: model.AvailableAt,
+		DecidedBy:   decidedBy,
+		UpdatedAt:   model.UpdatedAt,
+	}
+}
@@ -0,0 +1,95 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountRecoveryRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		clock := fixtures.NewFakeClock(time.Unix(100, 0))
+		repo := NewAccountRecoveryRepositoryRdsImpl(config.Config{DBType: "sqlite"}, rdsClient, clock)
+		userID := entity.UserIDEntity("account-recovery-user-1")
+		adminID := entity.UserIDEntity("account-recovery-admin")
+
+		// the sqlite file is shared between tests, start from an empty table
+		_, err := rdsClient.DB().Exec("DELETE FROM account_recovery_requests")
+		assert.Nil(t, err)
+
+		_, exists, err := repo.GetByID(ctx, "missing")
+		assert.Nil(t, err)
+		assert.False(t, exists)
+
+		newRequest := func(id string, userID entity.UserIDEntity) entity.AccountRecoveryRequestEntity {
+			now := clock.Now()
+			return entity.AccountRecoveryRequestEntity{
+				ID:          id,
+				UserID:      userID,
+				Status:      entity.AccountRecoveryStatusPending,
+				TokenHash:   "hash-" + id,
+				CreatedAt:   now,
+				AvailableAt: now.Add(time.Hour),
+				UpdatedAt:   now,
+			}
+		}
+		assert.Nil(t, repo.Create(ctx, newRequest("request-1", userID)))
+		clock.Advance(time.Minute)
+		assert.Nil(t, repo.Create(ctx, newRequest("request-2", userID)))
+		assert.Nil(t, repo.Create(ctx, newRequest("request-3", "account-recovery-user-2")))
+
+		request, exists, err := repo.GetByID(ctx, "request-1")
+		assert.Nil(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, userID, request.UserID)
+		assert.Equal(t, "hash-request-1", request.TokenHash)
+		assert.Nil(t, request.DecidedBy)
+		assert.True(t, request.AvailableAt.Equal(time.Unix(100, 0).Add(time.Hour)))
+
+		requests, err := repo.ListByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.Len(t, requests, 2)
+		assert.Equal(t, "request-2", requests[0].ID)
+
+		// the transition only happens from the expected statuses
+		clock.Advance(time.Minute)
+		updated, err := repo.UpdateStatus(ctx, "request-1", []entity.AccountRecoveryStatus{entity.AccountRecoveryStatusPending}, entity.AccountRecoveryStatusApproved, &adminID)
+		assert.Nil(t, err)
+		assert.True(t, updated)
+		updated, err = repo.UpdateStatus(ctx, "request-1", []entity.AccountRecoveryStatus{entity.AccountRecoveryStatusPending}, entity.AccountRecoveryStatusRejected, &adminID)
+		assert.Nil(t, err)
+		assert.False(t, updated)
+		updated, err = repo.UpdateStatus(ctx, "missing", []entity.AccountRecoveryStatus{entity.AccountRecoveryStatusPending}, entity.AccountRecoveryStatusRejected, &adminID)
+		assert.Nil(t, err)
+		assert.False(t, updated)
+
+		// completing keeps the deciding admin
+		updated, err = repo.UpdateStatus(ctx, "request-1",
+			[]entity.AccountRecoveryStatus{entity.AccountRecoveryStatusPending, entity.AccountRecoveryStatusApproved}, entity.AccountRecoveryStatusCompleted, nil)
+		assert.Nil(t, err)
+		assert.True(t, updated)
+		request, _, err = repo.GetByID(ctx, "request-1")
+		assert.Nil(t, err)
+		assert.Equal(t, entity.AccountRecoveryStatusCompleted, request.Status)
+		if assert.NotNil(t, request.DecidedBy) {
+			assert.Equal(t, adminID, *request.DecidedBy)
+		}
+		assert.True(t, request.UpdatedAt.After(request.CreatedAt))
+
+		requests, err = repo.List(ctx, entity.AccountRecoveryStatusPending)
+		assert.Nil(t, err)
+		assert.Len(t, requests, 2)
+		requests, err = repo.List(ctx, "")
+		assert.Nil(t, err)
+		assert.Len(t, requests, 3)
+	})
+}
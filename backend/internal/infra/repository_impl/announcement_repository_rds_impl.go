@@ -0,0 +1,137 @@
+package repository_impl
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+type AnnouncementRdsModel struct {
+	ID          string       `db:"id"`
+	Message     string       `db:"message"`
+	Severity    string       `db:"severity"`
+	StartsAt    sql.NullTime `db:"starts_at"`
+	EndsAt      sql.NullTime `db:"ends_at"`
+	Dismissible bool         `db:"dismissible"`
+	CreatedBy   string       `db:"created_by"`
+	CreatedAt   time.Time    `db:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at"`
+}
+
+func NewAnnouncementRepositoryRdsImpl(client repository.IRdsClient) *AnnouncementRepositoryRdsImpl {
+	return &AnnouncementRepositoryRdsImpl{client: client}
+}
+
+type AnnouncementRepositoryRdsImpl struct {
+	client repository.IRdsClient
+}
+
+func (r *AnnouncementRepositoryRdsImpl) All(ctx context.Context) ([]entity.AnnouncementEntity, error) {
+	db := r.client.DB()
+
+	var models []AnnouncementRdsModel
+	if err := db.SelectContext(ctx, &models, "SELECT * FROM announcements ORDER BY created_at DESC, id"); err != nil {
+		return nil, errors.Wrap(err, "failed to select announcements from rds")
+	}
+
+	announcements := make([]entity.AnnouncementEntity, len(models))
+	for i, model := range models {
+		announcements[i] = toAnnouncementEntity(model)
+	}
+	return announcements, nil
+}
+
+func (r *AnnouncementRepositoryRdsImpl) Create(ctx context.Context, announcement entity.AnnouncementEntity) error {
+	db := r.client.DB()
+
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO announcements (id, message, severity, starts_at, ends_at, dismissible, created_by, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		announcement.ID,
+		announcement.Message,
+		string(announcement.Severity),
+		toNullTime(announcement.StartsAt),
+		toNullTime(announcement.EndsAt),
+		announcement.Dismissible,
+		string(announcement.CreatedBy),
+		announcement.CreatedAt,
+		announcement.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert announcement into rds")
+	}
+	return nil
+}
+
+func (r *AnnouncementRepositoryRdsImpl) Update(ctx context.Context, announcement entity.AnnouncementEntity) error {
+	db := r.client.DB()
+
+	result, err := db.ExecContext(ctx,
+		`UPDATE announcements SET message = ?, severity = ?, starts_at = ?, ends_at = ?, dismissible = ?, updated_at = ?
+		 WHERE id = ?`,
+		announcement.Message,
+		string(announcement.Severity),
+		toNullTime(announcement.StartsAt),
+		toNullTime(announcement.EndsAt),
+		announcement.Dismissible,
+		announcement.UpdatedAt,
+		announcement.ID,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update announcement in rds")
+	}
+	return checkAnnouncementAffected(result, announcement.ID)
+}
+
+func (r *AnnouncementRepositoryRdsImpl) Delete(ctx context.Context, id string) error {
+	db := r.client.DB()
+
+	result, err := db.ExecContext(ctx, "DELETE FROM announcements WHERE id = ?", id)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete announcement from rds")
+	}
+	return checkAnnouncementAffected(result, id)
+}
+
+func checkAnnouncementAffected(result sql.Result, id string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get affected rows of announcement")
+	}
+	if affected == 0 {
+		return errors.Wrapf(repository.ErrAnnouncementNotFound, "announcement %q", id)
+	}
+	return nil
+}
+
+func toAnnouncementEntity(model AnnouncementRdsModel) entity.AnnouncementEntity {
+	announcement := entity.AnnouncementEntity{
+		ID:          model.ID,
+		Message:     model.Message,
+		Severity:    entity.AnnouncementSeverity(model.Severity),
+		Dismissible: model.Dismissible,
+		CreatedBy:   entity.UserIDEntity(model.CreatedBy),
+		CreatedAt:   model.CreatedAt,
+		UpdatedAt:   model.UpdatedAt,
+	}
+	if model.StartsAt.Valid {
+		startsAt := model.StartsAt.Time
+		announcement.StartsAt = &startsAt
+	}
+	if model.EndsAt.Valid {
+		endsAt := model.EndsAt.Time
+		announcement.EndsAt = &endsAt
+	}
+	return announcement
+}
+
+func toNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
@@ -0,0 +1,74 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/unittest"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnouncementRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewAnnouncementRepositoryRdsImpl(rdsClient)
+
+		// the sqlite file is shared between tests, start from an empty table
+		_, err := rdsClient.DB().Exec("DELETE FROM announcements")
+		assert.Nil(t, err)
+
+		announcements, err := repo.All(ctx)
+		assert.Nil(t, err)
+		assert.Empty(t, announcements)
+
+		createdAt := time.Now().UTC().Truncate(time.Second)
+		endsAt := createdAt.Add(time.Hour)
+		older := entity.NewAnnouncementEntityWithoutID("release notes", entity.AnnouncementSeverityInfo, nil, nil, true, "admin-1")
+		older.CreatedAt = createdAt.Add(-time.Minute)
+		older.UpdatedAt = older.CreatedAt
+		newer := entity.NewAnnouncementEntityWithoutID("maintenance", entity.AnnouncementSeverityWarning, &createdAt, &endsAt, false, "admin-1")
+		newer.CreatedAt = createdAt
+		newer.UpdatedAt = createdAt
+		assert.Nil(t, repo.Create(ctx, older))
+		assert.Nil(t, repo.Create(ctx, newer))
+
+		announcements, err = repo.All(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, announcements, 2)
+		assert.Equal(t, newer.ID, announcements[0].ID)
+		assert.True(t, createdAt.Equal(*announcements[0].StartsAt))
+		assert.True(t, endsAt.Equal(*announcements[0].EndsAt))
+		assert.False(t, announcements[0].Dismissible)
+		assert.Equal(t, older.ID, announcements[1].ID)
+		assert.Nil(t, announcements[1].StartsAt)
+		assert.Nil(t, announcements[1].EndsAt)
+
+		newer.Message = "maintenance extended"
+		newer.Severity = entity.AnnouncementSeverityCritical
+		newer.EndsAt = nil
+		newer.UpdatedAt = createdAt.Add(time.Minute)
+		assert.Nil(t, repo.Update(ctx, newer))
+
+		announcements, err = repo.All(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, "maintenance extended", announcements[0].Message)
+		assert.Equal(t, entity.AnnouncementSeverityCritical, announcements[0].Severity)
+		assert.Nil(t, announcements[0].EndsAt)
+
+		missing := newer
+		missing.ID = "ann-missing"
+		assert.True(t, errors.Is(repo.Update(ctx, missing), repository.ErrAnnouncementNotFound))
+		assert.True(t, errors.Is(repo.Delete(ctx, "ann-missing"), repository.ErrAnnouncementNotFound))
+
+		assert.Nil(t, repo.Delete(ctx, older.ID))
+		announcements, err = repo.All(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, announcements, 1)
+	})
+}
@@ -0,0 +1,123 @@
+package repository_impl
+
+import (
+	"context"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+type AuditLogRdsModel struct {
+	ID        string    `db:"id"`
+	ActorID   string    `db:"actor_id"`
+	Method    string    `db:"method"`
+	Route     string    `db:"route"`
+	Path      string    `db:"path"`
+	Payload   string    `db:"payload"`
+	Status    int       `db:"status"`
+	Outcome   string    `db:"outcome"`
+	RequestID string    `db:"request_id"`
+	ClientIP  string    `db:"client_ip"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func NewAuditLogRepositoryRdsImpl(client repository.IRdsClient) *AuditLogRepositoryRdsImpl {
+	return &AuditLogRepositoryRdsImpl{client: client}
+}
+
+type AuditLogRepositoryRdsImpl struct {
+	client repository.IRdsClient
+}
+
+func (r *AuditLogRepositoryRdsImpl) Create(ctx context.Context, auditLog entity.AuditLogEntity) error {
+	db := r.client.DB()
+
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO audit_logs (id, actor_id, method, route, path, payload, status, outcome, request_id, client_ip, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		auditLog.ID,
+		string(auditLog.ActorID),
+		auditLog.Method,
+		auditLog.Route,
+		auditLog.Path,
+		auditLog.Payload,
+		auditLog.Status,
+		string(auditLog.Outcome),
+		auditLog.RequestID,
+		auditLog.ClientIP,
+		auditLog.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert audit log into rds")
+	}
+	return nil
+}
+
+func (r *AuditLogRepositoryRdsImpl) DeleteCreatedBefore(ctx context.Context, before time.Time) (int, error) {
+	db := r.client.DB()
+
+	result, err := db.ExecContext(ctx, "DELETE FROM audit_logs WHERE created_at < ?", before)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete audit logs from rds")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count deleted audit logs")
+	}
+	return int(deleted), nil
+}
+
+func (r *AuditLogRepositoryRdsImpl) List(ctx context.Context, filter entity.AuditLogFilter) ([]entity.AuditLogEntity, int, error) {
+	db := r.client.DB()
+
+	var conditions []string
+	var args []any
+	if filter.ActorID != "" {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, string(filter.ActorID))
+	}
+	if filter.Outcome != "" {
+		conditions = append(conditions, "outcome = ?")
+		args = append(args, string(filter.Outcome))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := db.GetContext(ctx, &total, "SELECT COUNT(*) FROM audit_logs"+where, args...); err != nil {
+		return nil, 0, errors.Wrap(err, "failed to count audit logs in rds")
+	}
+
+	query := "SELECT * FROM audit_logs" + where + " ORDER BY created_at DESC, id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+	var models []AuditLogRdsModel
+	if err := db.SelectContext(ctx, &models, query, args...); err != nil {
+		return nil, 0, errors.Wrap(err, "failed to select audit logs from rds")
+	}
+
+	auditLogs := make([]entity.AuditLogEntity, len(models))
+	for i, model := range models {
+		auditLogs[i] = entity.AuditLogEntity{
+			ID:        model.ID,
+			ActorID:   entity.UserIDEntity(model.ActorID),
+			Method:    model.Method,
+			Route:     model.Route,
+			Path:      model.Path,
+			Payload:   model.Payload,
+			Status:    model.Status,
+			Outcome:   entity.AuditOutcome(model.Outcome),
+			RequestID: model.RequestID,
+			ClientIP:  model.ClientIP,
+			CreatedAt: model.CreatedAt,
+		}
+	}
+	return auditLogs, total, nil
+}
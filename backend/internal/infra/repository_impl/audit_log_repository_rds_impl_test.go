@@ -0,0 +1,84 @@
+package repository_impl
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLogRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewAuditLogRepositoryRdsImpl(rdsClient)
+
+		// the sqlite file is shared between tests, start from an empty table
+		_, err := rdsClient.DB().Exec("DELETE FROM audit_logs")
+		assert.Nil(t, err)
+
+		auditLogs, total, err := repo.List(ctx, entity.AuditLogFilter{})
+		assert.Nil(t, err)
+		assert.Empty(t, auditLogs)
+		assert.Equal(t, 0, total)
+
+		createdAt := time.Now().UTC().Truncate(time.Second)
+		records := []entity.AuditLogEntity{
+			entity.NewAuditLogEntityWithoutID("admin-1", http.MethodPut, "/api/v1/admin/settings/:key", "/api/v1/admin/settings/maintenance.message", `{"value":"down"}`, http.StatusOK, "req-1", "127.0.0.1"),
+			entity.NewAuditLogEntityWithoutID("admin-2", http.MethodPost, "/api/v1/admin/announcements", "/api/v1/admin/announcements", `{"message":"hi"}`, http.StatusOK, "req-2", "127.0.0.1"),
+			entity.NewAuditLogEntityWithoutID("", http.MethodDelete, "/api/v1/admin/announcements/:id", "/api/v1/admin/announcements/ann-1", "", http.StatusUnauthorized, "req-3", "10.0.0.1"),
+		}
+		for i := range records {
+			records[i].CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+			assert.Nil(t, repo.Create(ctx, records[i]))
+		}
+
+		// newest first, limit and offset page through the records while the total stays the same
+		auditLogs, total, err = repo.List(ctx, entity.AuditLogFilter{Limit: 2})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, total)
+		assert.Len(t, auditLogs, 2)
+		assert.Equal(t, records[2].ID, auditLogs[0].ID)
+		assert.Equal(t, entity.AuditOutcomeFailure, auditLogs[0].Outcome)
+		assert.Equal(t, records[1].ID, auditLogs[1].ID)
+
+		auditLogs, total, err = repo.List(ctx, entity.AuditLogFilter{Limit: 2, Offset: 2})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, total)
+		assert.Len(t, auditLogs, 1)
+		assert.Equal(t, records[0].ID, auditLogs[0].ID)
+		assert.Equal(t, entity.UserIDEntity("admin-1"), auditLogs[0].ActorID)
+		assert.Equal(t, "/api/v1/admin/settings/:key", auditLogs[0].Route)
+		assert.Equal(t, "/api/v1/admin/settings/maintenance.message", auditLogs[0].Path)
+		assert.Equal(t, `{"value":"down"}`, auditLogs[0].Payload)
+		assert.Equal(t, http.StatusOK, auditLogs[0].Status)
+		assert.Equal(t, entity.AuditOutcomeSuccess, auditLogs[0].Outcome)
+		assert.Equal(t, "req-1", auditLogs[0].RequestID)
+		assert.True(t, createdAt.Equal(auditLogs[0].CreatedAt))
+
+		auditLogs, total, err = repo.List(ctx, entity.AuditLogFilter{ActorID: "admin-2"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, records[1].ID, auditLogs[0].ID)
+
+		auditLogs, total, err = repo.List(ctx, entity.AuditLogFilter{Outcome: entity.AuditOutcomeFailure})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, records[2].ID, auditLogs[0].ID)
+
+		// records created before the time are deleted, the time itself is kept
+		deleted, err := repo.DeleteCreatedBefore(ctx, records[1].CreatedAt)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, deleted)
+		auditLogs, total, err = repo.List(ctx, entity.AuditLogFilter{})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, records[1].ID, auditLogs[1].ID)
+	})
+}
@@ -127,22 +127,144 @@ func (r *Auth2FARepositoryRdsImpl) GetRecoveryCode(ctx context.Context, userID e
 	return &code.String, nil
 }
 
-// ClearRecoveryCode removes recovery code for a user
+// ClearRecoveryCode removes the recovery code and the single-use recovery codes of a user
 func (r *Auth2FARepositoryRdsImpl) ClearRecoveryCode(ctx context.Context, userID entity.UserIDEntity) error {
+	tx, err := r.client.DB().Beginx()
+	if err != nil {
+		return errors.Wrap(err, "fail to begin transaction")
+	}
+
+	if _, err := tx.Exec(
+		"UPDATE users SET recovery_code = NULL, updated_at = ? WHERE id = ?",
+		time.Now(), string(userID),
+	); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "fail to clear recovery code in rds")
+	}
+	if _, err := tx.Exec("DELETE FROM user_recovery_codes WHERE user_id = ?", string(userID)); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "fail to delete recovery codes from rds")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "fail to commit clear recovery code transaction")
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes stores the hashes of a new set of single-use recovery codes, the previous set is dropped
+func (r *Auth2FARepositoryRdsImpl) ReplaceRecoveryCodes(ctx context.Context, userID entity.UserIDEntity, codeHashes []string) error {
+	tx, err := r.client.DB().Beginx()
+	if err != nil {
+		return errors.Wrap(err, "fail to begin transaction")
+	}
+
+	if _, err := tx.Exec("DELETE FROM user_recovery_codes WHERE user_id = ?", string(userID)); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "fail to delete previous recovery codes from rds")
+	}
+	now := time.Now()
+	for _, codeHash := range codeHashes {
+		if _, err := tx.Exec(
+			"INSERT INTO user_recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)",
+			string(userID), codeHash, now,
+		); err != nil {
+			tx.Rollback()
+			return errors.Wrap(err, "fail to insert recovery code into rds")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "fail to commit replace recovery codes transaction")
+	}
+	return nil
+}
+
+// UseRecoveryCode marks the unused single-use recovery code with the hash as used, false when there is none.
+// The condition on used_at makes concurrent uses of one code succeed only once.
+func (r *Auth2FARepositoryRdsImpl) UseRecoveryCode(ctx context.Context, userID entity.UserIDEntity, codeHash string) (bool, error) {
+	db := r.client.DB()
+
+	result, err := db.Exec(
+		"UPDATE user_recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL",
+		time.Now(), string(userID), codeHash,
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "fail to use recovery code in rds")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "fail to get affected rows")
+	}
+
+	return affected > 0, nil
+}
+
+// GetRecoveryCodesStatus counts the single-use recovery codes of the current set of a user
+func (r *Auth2FARepositoryRdsImpl) GetRecoveryCodesStatus(ctx context.Context, userID entity.UserIDEntity) (entity.RecoveryCodesStatusEntity, error) {
+	db := r.client.DB()
+	var codes []struct {
+		UsedAt    sql.NullTime `db:"used_at"`
+		CreatedAt time.Time    `db:"created_at"`
+	}
+
+	if err := db.Select(&codes, "SELECT used_at, created_at FROM user_recovery_codes WHERE user_id = ?", string(userID)); err != nil {
+		return entity.RecoveryCodesStatusEntity{}, errors.Wrap(err, "fail to get recovery codes from rds")
+	}
+
+	status := entity.RecoveryCodesStatusEntity{Total: len(codes)}
+	for _, code := range codes {
+		if !code.UsedAt.Valid {
+			status.Remaining++
+		}
+		createdAt := code.CreatedAt
+		status.GeneratedAt = &createdAt
+	}
+	return status, nil
+}
+
+// SetPreferredMethod stores the 2FA method offered first at login, nil clears it
+func (r *Auth2FARepositoryRdsImpl) SetPreferredMethod(ctx context.Context, userID entity.UserIDEntity, method *entity.TwoFAType) error {
 	db := r.client.DB()
 	now := time.Now()
 
+	var value sql.NullString
+	if method != nil {
+		value = sql.NullString{String: string(*method), Valid: true}
+	}
+
 	_, err := db.Exec(
-		"UPDATE users SET recovery_code = NULL, updated_at = ? WHERE id = ?",
-		now, string(userID),
+		"UPDATE users SET preferred_2fa_method = ?, updated_at = ? WHERE id = ?",
+		value, now, string(userID),
 	)
 	if err != nil {
-		return errors.Wrap(err, "fail to clear recovery code in rds")
+		return errors.Wrap(err, "fail to set preferred 2fa method in rds")
 	}
 
 	return nil
 }
 
+// GetPreferredMethod retrieves the preferred 2FA method of a user, nil when none is set
+func (r *Auth2FARepositoryRdsImpl) GetPreferredMethod(ctx context.Context, userID entity.UserIDEntity) (*entity.TwoFAType, error) {
+	db := r.client.DB()
+	var method sql.NullString
+
+	err := db.Get(&method, "SELECT preferred_2fa_method FROM users WHERE id = ?", string(userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "fail to get preferred 2fa method from rds")
+	}
+
+	if !method.Valid {
+		return nil, nil
+	}
+
+	twoFAType := entity.TwoFAType(method.String)
+	return &twoFAType, nil
+}
+
 // toEntity converts TwoFARdsModel to TwoFAEntity
 func (r *Auth2FARepositoryRdsImpl) toEntity(model *TwoFARdsModel) entity.TwoFAEntity {
 	return entity.TwoFAEntity{
@@ -2,46 +2,92 @@ package repository_impl
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/core/logger"
+	domain_client "ya-tool-craft/internal/domain/client"
 	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/utils"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/pkg/errors"
 )
 
-func NewAuthAccessTokenRepositoryJWTImpl(cfg config.Config, writable config.WritableConfig) *AuthAccessTokenRepositoryJWTImpl {
+const (
+	// cache key of a single revoked access token, keyed by the sha256 of the token
+	accessTokenDenylistCacheKeyPrefix = "access_token_denylist:"
+	// cache key holding the unix time in seconds with milliseconds, like 1700000000.123, up to which all access tokens
+	// of a user are revoked, optionally followed by ":" and the hash of the refresh token whose access tokens are spared
+	accessTokenRevokedBeforeCacheKeyPrefix = "access_token_revoked_before:"
+	// cache key marking the access tokens of a revoked session as revoked, keyed by the hash of its refresh token
+	accessTokenRevokedSessionCacheKeyPrefix = "access_token_revoked_session:"
+)
+
+func NewAuthAccessTokenRepositoryJWTImpl(cfg config.Config, writable config.WritableConfig, cache repository.ICache, clock domain_client.IClock, ttls repository.ISessionTTLProvider) *AuthAccessTokenRepositoryJWTImpl {
 	return &AuthAccessTokenRepositoryJWTImpl{
 		config:         cfg,
 		writableConfig: writable,
+		cache:          cache,
+		clock:          clock,
+		ttls:           ttls,
 	}
 }
 
+// AuthAccessTokenRepositoryJWTImpl issues stateless JWT access tokens.
+// Revocation is done with a short-lived denylist in ICache, entries only live as long as the
+// longest token they can affect, so the denylist never grows beyond the longest access token ttl the settings allow.
 type AuthAccessTokenRepositoryJWTImpl struct {
 	config         config.Config
 	writableConfig config.WritableConfig
+	cache          repository.ICache
+	clock          domain_client.IClock
+	ttls           repository.ISessionTTLProvider
 }
 
 // JWTClaims represents the JWT claims for access token
 type JWTClaims struct {
 	UserID                   string `json:"user_id"`
 	RelativeRefreshTokenHash string `json:"relative_refresh_token"`
+	// IssuedAtMilli is the issue time in unix milliseconds, iat has seconds only and can not tell a token issued
+	// right after a revocation from one issued right before it. Tokens of older versions do not have it.
+	IssuedAtMilli int64 `json:"iat_ms,omitempty"`
+	// ClientID and Scope, the space separated scopes, are set for the tokens of an oidc client
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // IssueAccessToken generates a new JWT access token for the given user
 func (r *AuthAccessTokenRepositoryJWTImpl) IssueAccessToken(ctx context.Context, userID entity.UserIDEntity, relativeRefreshTokenHash string) (entity.AccessToken, error) {
+	return r.issueAccessToken(ctx, userID, relativeRefreshTokenHash, "", nil)
+}
+
+// IssueClientAccessToken generates a new JWT access token for a refresh token of an oidc client
+func (r *AuthAccessTokenRepositoryJWTImpl) IssueClientAccessToken(ctx context.Context, refreshToken entity.RefreshToken) (entity.AccessToken, error) {
+	if refreshToken.ClientID == "" {
+		return entity.AccessToken{}, errors.New("refresh token is not bound to an oidc client")
+	}
+	return r.issueAccessToken(ctx, refreshToken.UserID, refreshToken.TokenHash, refreshToken.ClientID, refreshToken.Scopes)
+}
+
+func (r *AuthAccessTokenRepositoryJWTImpl) issueAccessToken(ctx context.Context, userID entity.UserIDEntity, relativeRefreshTokenHash string, clientID string, scopes []string) (entity.AccessToken, error) {
 	// calculate issue and expire time
-	issueAt := utils.NowToSecond()
-	ttl := utils.TTLInSecondToTimeDuration(r.config.AccessTokenTTL)
+	now := r.clock.Now()
+	issueAt := utils.ToSecond(now)
+	ttl := utils.TTLInSecondToTimeDuration(r.ttls.AccessTokenTTL(ctx))
 	expireAt := issueAt.Add(ttl)
 
 	// create JWT claims
 	claims := JWTClaims{
 		UserID:                   string(userID),
 		RelativeRefreshTokenHash: relativeRefreshTokenHash,
+		IssuedAtMilli:            now.UnixMilli(),
+		ClientID:                 clientID,
+		Scope:                    strings.Join(scopes, " "),
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(issueAt),
 			ExpiresAt: jwt.NewNumericDate(expireAt),
@@ -59,7 +105,10 @@ func (r *AuthAccessTokenRepositoryJWTImpl) IssueAccessToken(ctx context.Context,
 	}
 
 	// return the access token entity
-	return entity.NewAccessToken(userID, tokenString, issueAt, expireAt, relativeRefreshTokenHash), nil
+	accessToken := entity.NewAccessToken(userID, tokenString, issueAt, expireAt, relativeRefreshTokenHash)
+	accessToken.ClientID = clientID
+	accessToken.Scopes = scopes
+	return accessToken, nil
 }
 
 // ValidateAccessToken validates the given JWT token and returns the access token entity
@@ -72,7 +121,7 @@ func (r *AuthAccessTokenRepositoryJWTImpl) ValidateAccessToken(ctx context.Conte
 			return nil, errors.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(r.writableConfig.Value.JWTSecret), nil
-	})
+	}, jwt.WithTimeFunc(r.clock.Now))
 
 	if err != nil {
 		logger.Errorf(ctx, "validate jwt fail: %v", err)
@@ -98,7 +147,20 @@ func (r *AuthAccessTokenRepositoryJWTImpl) ValidateAccessToken(ctx context.Conte
 	}
 
 	// check if token is expired (double check)
-	if time.Now().After(claims.ExpiresAt.Time) {
+	if r.clock.Now().After(claims.ExpiresAt.Time) {
+		return entity.AccessToken{}, false, nil
+	}
+
+	// check the denylist so logout and revocation take effect before the token expires
+	issuedAtMilli := claims.IssuedAtMilli
+	if issuedAtMilli == 0 {
+		issuedAtMilli = claims.IssuedAt.Time.UnixMilli()
+	}
+	revoked, err := r.isRevoked(ctx, tokenString, claims.UserID, claims.RelativeRefreshTokenHash, issuedAtMilli)
+	if err != nil {
+		return entity.AccessToken{}, false, err
+	}
+	if revoked {
 		return entity.AccessToken{}, false, nil
 	}
 
@@ -110,16 +172,119 @@ func (r *AuthAccessTokenRepositoryJWTImpl) ValidateAccessToken(ctx context.Conte
 		claims.ExpiresAt.Time,
 		claims.RelativeRefreshTokenHash,
 	)
+	accessToken.ClientID = claims.ClientID
+	accessToken.Scopes = strings.Fields(claims.Scope)
 
 	return accessToken, true, nil
 }
 
-// DeleteAccessToken currently always succeeds because JWT access tokens are stateless.
+// DeleteAccessToken puts the token into the denylist until it expires.
 func (r *AuthAccessTokenRepositoryJWTImpl) DeleteAccessToken(ctx context.Context, token entity.AccessToken) error {
+	ttl := token.ExpireAt.Sub(r.clock.Now())
+	if ttl <= 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s%s", accessTokenDenylistCacheKeyPrefix, utils.Sha256String(token.Token))
+	if err := r.cache.SetWithTTL(ctx, key, "1", uint64(ttl.Seconds())+1); err != nil {
+		return errors.Wrap(err, "fail to add access token to denylist")
+	}
 	return nil
 }
 
-// DeleteAllTokensByUserID currently always succeeds because JWT access tokens are stateless.
+// DeleteAllTokensByUserID revokes every access token of the user issued up to now.
 func (r *AuthAccessTokenRepositoryJWTImpl) DeleteAllTokensByUserID(ctx context.Context, userID entity.UserIDEntity) error {
+	return r.revokeUserTokens(ctx, userID, "")
+}
+
+// DeleteOtherTokensByUserID revokes every access token of the user issued up to now, but those issued for the refresh
+// token with the given hash, so the session making the request stays signed in.
+func (r *AuthAccessTokenRepositoryJWTImpl) DeleteOtherTokensByUserID(ctx context.Context, userID entity.UserIDEntity, keepRefreshTokenHash string) error {
+	return r.revokeUserTokens(ctx, userID, keepRefreshTokenHash)
+}
+
+// DeleteTokensByRefreshTokenHash revokes every access token issued for the refresh token with the given hash. The
+// refresh token is deleted with it, so no later access token is issued for it and the mark outlives the last one.
+func (r *AuthAccessTokenRepositoryJWTImpl) DeleteTokensByRefreshTokenHash(ctx context.Context, refreshTokenHash string) error {
+	if refreshTokenHash == "" {
+		return nil
+	}
+	key := fmt.Sprintf("%s%s", accessTokenRevokedSessionCacheKeyPrefix, refreshTokenHash)
+	if err := r.cache.SetWithTTL(ctx, key, "1", r.ttls.MaxAccessTokenTTL()+1); err != nil {
+		return errors.Wrap(err, "fail to revoke session access tokens")
+	}
+	return nil
+}
+
+func (r *AuthAccessTokenRepositoryJWTImpl) revokeUserTokens(ctx context.Context, userID entity.UserIDEntity, keepRefreshTokenHash string) error {
+	key := fmt.Sprintf("%s%s", accessTokenRevokedBeforeCacheKeyPrefix, userID)
+	revokedBefore := formatRevokedBefore(r.clock.Now())
+	if keepRefreshTokenHash != "" {
+		revokedBefore += ":" + keepRefreshTokenHash
+	}
+	if err := r.cache.SetWithTTL(ctx, key, revokedBefore, r.ttls.MaxAccessTokenTTL()); err != nil {
+		return errors.Wrap(err, "fail to revoke user access tokens")
+	}
 	return nil
 }
+
+// isRevoked checks whether the token itself, or all tokens of its user, have been revoked.
+func (r *AuthAccessTokenRepositoryJWTImpl) isRevoked(ctx context.Context, tokenString string, userID string, relativeRefreshTokenHash string, issuedAtMilli int64) (bool, error) {
+	denied, err := r.cache.Has(ctx, fmt.Sprintf("%s%s", accessTokenDenylistCacheKeyPrefix, utils.Sha256String(tokenString)))
+	if err != nil {
+		return false, errors.Wrap(err, "fail to check access token denylist")
+	}
+	if denied {
+		return true, nil
+	}
+	if relativeRefreshTokenHash != "" {
+		revokedSession, err := r.cache.Has(ctx, fmt.Sprintf("%s%s", accessTokenRevokedSessionCacheKeyPrefix, relativeRefreshTokenHash))
+		if err != nil {
+			return false, errors.Wrap(err, "fail to check session access token revocation")
+		}
+		if revokedSession {
+			return true, nil
+		}
+	}
+
+	revokedBefore, exists, err := r.cache.Get(ctx, fmt.Sprintf("%s%s", accessTokenRevokedBeforeCacheKeyPrefix, userID))
+	if err != nil {
+		return false, errors.Wrap(err, "fail to check user access token revocation")
+	}
+	if !exists {
+		return false, nil
+	}
+	revokedBefore, keepRefreshTokenHash, _ := strings.Cut(revokedBefore, ":")
+	if keepRefreshTokenHash != "" && keepRefreshTokenHash == relativeRefreshTokenHash {
+		return false, nil
+	}
+	revokedBeforeMilli, err := parseRevokedBefore(revokedBefore)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid access token revocation time: %s", revokedBefore)
+	}
+	// a token issued in the same millisecond as the revocation is revoked too
+	return issuedAtMilli <= revokedBeforeMilli, nil
+}
+
+func formatRevokedBefore(t time.Time) string {
+	milli := t.UnixMilli()
+	return fmt.Sprintf("%d.%03d", milli/1000, milli%1000)
+}
+
+// parseRevokedBefore returns the revocation time in unix milliseconds. Older versions stored whole seconds and
+// revoked every token of that second, so such a time covers its whole second.
+func parseRevokedBefore(value string) (int64, error) {
+	secondsPart, milliPart, hasMilli := strings.Cut(value, ".")
+	seconds, err := strconv.ParseInt(secondsPart, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if !hasMilli {
+		return seconds*1000 + 999, nil
+	}
+	milli, err := strconv.ParseInt(milliPart, 10, 64)
+	if err != nil || len(milliPart) != 3 || milli < 0 {
+		return 0, errors.Errorf("invalid milliseconds: %s", milliPart)
+	}
+	return seconds*1000 + milli, nil
+}
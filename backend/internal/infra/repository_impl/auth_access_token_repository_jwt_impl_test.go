@@ -2,10 +2,14 @@ package repository_impl
 
 import (
 	"context"
+	"strconv"
 	"testing"
 	"time"
+	domain_client "ya-tool-craft/internal/domain/client"
 	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/infra/repository_impl/client"
 	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
@@ -14,7 +18,7 @@ import (
 func TestAuthAccessTokenRepositoryImpl_IssueAccessToken(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	repo := NewAuthAccessTokenRepositoryJWTImpl(unitTestCtx.Config, unitTestCtx.WritableConfig)
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, client.NewSystemClock())
 
 	// Test issuing an access token
 	userID := entity.UserIDEntity("u-test-user-123")
@@ -37,10 +41,37 @@ func TestAuthAccessTokenRepositoryImpl_IssueAccessToken(t *testing.T) {
 	assert.Equal(t, refreshToken, validatedToken.RelativeRefreshToken)
 }
 
+func TestAuthAccessTokenRepositoryImpl_IssueClientAccessToken(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, client.NewSystemClock())
+
+	// a first-party refresh token has no client to bind the access token to
+	firstParty := entity.NewRefreshToken("u-test-user-client", "rt-test-first-party", time.Now(), time.Now().Add(time.Hour))
+	_, err := repo.IssueClientAccessToken(context.Background(), firstParty)
+	assert.NotNil(t, err)
+
+	refreshToken := firstParty
+	refreshToken.ClientID = "client-1"
+	refreshToken.Scopes = []string{"openid", "email"}
+	token, err := repo.IssueClientAccessToken(context.Background(), refreshToken)
+	assert.Nil(t, err)
+	assert.Equal(t, "client-1", token.ClientID)
+
+	// the client and the scopes survive the round trip through the jwt
+	validatedToken, valid, err := repo.ValidateAccessToken(context.Background(), token.Token)
+	assert.Nil(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, refreshToken.UserID, validatedToken.UserID)
+	assert.Equal(t, refreshToken.TokenHash, validatedToken.RelativeRefreshToken)
+	assert.Equal(t, "client-1", validatedToken.ClientID)
+	assert.Equal(t, []string{"openid", "email"}, validatedToken.Scopes)
+}
+
 func TestAuthAccessTokenRepositoryImpl_ValidateAccessToken(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	repo := NewAuthAccessTokenRepositoryJWTImpl(unitTestCtx.Config, unitTestCtx.WritableConfig)
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, client.NewSystemClock())
 	// Issue a token first
 	userID := entity.UserIDEntity("u-test-user-456")
 	refreshToken := "rt-test-refresh-token-456"
@@ -67,7 +98,7 @@ func TestAuthAccessTokenRepositoryImpl_ValidateAccessToken(t *testing.T) {
 
 func TestAuthAccessTokenRepositoryImpl_ValidateAccessToken_MissingTimeClaims(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
-	repo := NewAuthAccessTokenRepositoryJWTImpl(unitTestCtx.Config, unitTestCtx.WritableConfig)
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, client.NewSystemClock())
 
 	userID := entity.UserIDEntity("u-test-user-missing-claims")
 	refreshToken := "rt-test-refresh-token-missing-claims"
@@ -115,7 +146,7 @@ func TestAuthAccessTokenRepositoryImpl_ValidateAccessToken_MissingTimeClaims(t *
 func TestAuthAccessTokenRepositoryImpl_TokenExpiration(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	repo := NewAuthAccessTokenRepositoryJWTImpl(unitTestCtx.Config, unitTestCtx.WritableConfig)
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, client.NewSystemClock())
 
 	// Verify config value matches .env.test: ACCESS_TOKEN_TTL="300"
 	assert.Equal(t, uint64(300), unitTestCtx.Config.AccessTokenTTL, "AccessTokenTTL should be 300 seconds as configured in .env.test")
@@ -135,10 +166,34 @@ func TestAuthAccessTokenRepositoryImpl_TokenExpiration(t *testing.T) {
 	assert.InDelta(t, 300.0, actualTTL.Seconds(), 1.0, "Access token TTL should be 300 seconds")
 }
 
+func TestAuthAccessTokenRepositoryImpl_ExpiresAtTTLBoundary(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	clock := fixtures.NewFakeClock(time.Now())
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, clock)
+
+	token, err := repo.IssueAccessToken(context.Background(), entity.UserIDEntity("u-test-user-ttl-boundary"), "rt-test-refresh-token-ttl-boundary")
+	assert.Nil(t, err)
+
+	// still valid on the last second of its lifetime
+	clock.Set(token.ExpireAt.Add(-time.Second))
+	_, valid, err := repo.ValidateAccessToken(context.Background(), token.Token)
+	assert.Nil(t, err)
+	assert.True(t, valid)
+
+	clock.Set(token.ExpireAt.Add(time.Second))
+	_, valid, err = repo.ValidateAccessToken(context.Background(), token.Token)
+	assert.Nil(t, err)
+	assert.False(t, valid)
+
+	// an expired token has nothing left to revoke
+	assert.Nil(t, repo.DeleteAccessToken(context.Background(), token))
+}
+
 func TestAuthAccessTokenRepositoryImpl_MultipleTokens(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	repo := NewAuthAccessTokenRepositoryJWTImpl(unitTestCtx.Config, unitTestCtx.WritableConfig)
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, client.NewSystemClock())
 
 	// Issue multiple tokens for different users
 	userID1 := entity.UserIDEntity("u-test-user-001")
@@ -172,7 +227,7 @@ func TestAuthAccessTokenRepositoryImpl_MultipleTokens(t *testing.T) {
 func TestAuthAccessTokenRepositoryImpl_JWTStructure(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	repo := NewAuthAccessTokenRepositoryJWTImpl(unitTestCtx.Config, unitTestCtx.WritableConfig)
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, client.NewSystemClock())
 
 	// Issue a token
 	userID := entity.UserIDEntity("u-test-user-jwt")
@@ -197,7 +252,7 @@ func TestAuthAccessTokenRepositoryImpl_JWTStructure(t *testing.T) {
 func TestAuthAccessTokenRepositoryImpl_DeleteAllTokensByUserID(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	repo := NewAuthAccessTokenRepositoryJWTImpl(unitTestCtx.Config, unitTestCtx.WritableConfig)
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, client.NewSystemClock())
 
 	// Issue a token
 	userID := entity.UserIDEntity("u-test-user-delete-all")
@@ -210,12 +265,153 @@ func TestAuthAccessTokenRepositoryImpl_DeleteAllTokensByUserID(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, valid)
 
-	// DeleteAllTokensByUserID should return nil (JWT is stateless)
 	err = repo.DeleteAllTokensByUserID(context.Background(), userID)
 	assert.Nil(t, err)
 
-	// Token is still valid because JWT is stateless
+	// Token is rejected by the denylist even though the JWT itself has not expired
 	_, valid, err = repo.ValidateAccessToken(context.Background(), token.Token)
 	assert.Nil(t, err)
+	assert.False(t, valid)
+
+	// Tokens of other users are not affected
+	otherToken, err := repo.IssueAccessToken(context.Background(), entity.UserIDEntity("u-test-user-delete-all-other"), refreshToken)
+	assert.Nil(t, err)
+	_, valid, err = repo.ValidateAccessToken(context.Background(), otherToken.Token)
+	assert.Nil(t, err)
+	assert.True(t, valid)
+}
+
+func TestAuthAccessTokenRepositoryImpl_DeleteOtherTokensByUserID(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, client.NewSystemClock())
+
+	userID := entity.UserIDEntity("u-test-user-delete-other")
+	current, err := repo.IssueAccessToken(context.Background(), userID, "rt-test-refresh-token-current")
+	assert.Nil(t, err)
+	other, err := repo.IssueAccessToken(context.Background(), userID, "rt-test-refresh-token-other")
+	assert.Nil(t, err)
+
+	err = repo.DeleteOtherTokensByUserID(context.Background(), userID, "rt-test-refresh-token-current")
+	assert.Nil(t, err)
+
+	// the token of the kept session stays valid
+	_, valid, err := repo.ValidateAccessToken(context.Background(), current.Token)
+	assert.Nil(t, err)
+	assert.True(t, valid)
+
+	// the tokens of every other session are revoked
+	_, valid, err = repo.ValidateAccessToken(context.Background(), other.Token)
+	assert.Nil(t, err)
+	assert.False(t, valid)
+
+	// revoking all tokens afterwards does not spare the kept session anymore
+	err = repo.DeleteAllTokensByUserID(context.Background(), userID)
+	assert.Nil(t, err)
+	_, valid, err = repo.ValidateAccessToken(context.Background(), current.Token)
+	assert.Nil(t, err)
+	assert.False(t, valid)
+}
+
+func TestAuthAccessTokenRepositoryImpl_DeleteAllTokensByUserID_SameSecond(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	clock := fixtures.NewFakeClock(time.Now().Truncate(time.Second).Add(200 * time.Millisecond))
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, clock)
+	userID := entity.UserIDEntity("u-test-user-revoke-same-second")
+
+	before, err := repo.IssueAccessToken(context.Background(), userID, "rt-test-refresh-token-before")
+	assert.Nil(t, err)
+	clock.Advance(300 * time.Millisecond)
+	assert.Nil(t, repo.DeleteAllTokensByUserID(context.Background(), userID))
+	clock.Advance(300 * time.Millisecond)
+	after, err := repo.IssueAccessToken(context.Background(), userID, "rt-test-refresh-token-after")
+	assert.Nil(t, err)
+
+	// both tokens have the same iat, only the one issued before the revocation is revoked
+	_, valid, err := repo.ValidateAccessToken(context.Background(), before.Token)
+	assert.Nil(t, err)
+	assert.False(t, valid)
+	_, valid, err = repo.ValidateAccessToken(context.Background(), after.Token)
+	assert.Nil(t, err)
+	assert.True(t, valid)
+
+	// a revocation stored in whole seconds by an older version still revokes every token of its second
+	key := accessTokenRevokedBeforeCacheKeyPrefix + string(userID)
+	assert.Nil(t, repo.cache.SetWithTTL(context.Background(), key, strconv.FormatInt(clock.Now().Unix(), 10), 60))
+	_, valid, err = repo.ValidateAccessToken(context.Background(), after.Token)
+	assert.Nil(t, err)
+	assert.False(t, valid)
+	clock.Advance(time.Second)
+	next, err := repo.IssueAccessToken(context.Background(), userID, "rt-test-refresh-token-next")
+	assert.Nil(t, err)
+	_, valid, err = repo.ValidateAccessToken(context.Background(), next.Token)
+	assert.Nil(t, err)
+	assert.True(t, valid)
+}
+
+func TestAuthAccessTokenRepositoryImpl_DeleteTokensByRefreshTokenHash(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, client.NewSystemClock())
+
+	userID := entity.UserIDEntity("u-test-user-delete-session")
+	revoked, err := repo.IssueAccessToken(context.Background(), userID, "rt-test-refresh-token-revoked")
+	assert.Nil(t, err)
+	kept, err := repo.IssueAccessToken(context.Background(), userID, "rt-test-refresh-token-kept")
+	assert.Nil(t, err)
+
+	err = repo.DeleteTokensByRefreshTokenHash(context.Background(), "rt-test-refresh-token-revoked")
+	assert.Nil(t, err)
+
+	// only the tokens of the revoked session stop working
+	_, valid, err := repo.ValidateAccessToken(context.Background(), revoked.Token)
+	assert.Nil(t, err)
+	assert.False(t, valid)
+	_, valid, err = repo.ValidateAccessToken(context.Background(), kept.Token)
+	assert.Nil(t, err)
+	assert.True(t, valid)
+}
+
+func TestAuthAccessTokenRepositoryImpl_DeleteAccessToken(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	repo := newTestJWTAccessTokenRepo(t, unitTestCtx, client.NewSystemClock())
+
+	userID := entity.UserIDEntity("u-test-user-delete-one")
+	token, err := repo.IssueAccessToken(context.Background(), userID, "rt-test-refresh-token-delete-one")
+	assert.Nil(t, err)
+	anotherToken, err := repo.IssueAccessToken(context.Background(), userID, "rt-test-refresh-token-delete-one-another")
+	assert.Nil(t, err)
+
+	err = repo.DeleteAccessToken(context.Background(), token)
+	assert.Nil(t, err)
+
+	// the deleted token is denied immediately
+	_, valid, err := repo.ValidateAccessToken(context.Background(), token.Token)
+	assert.Nil(t, err)
+	assert.False(t, valid)
+
+	// other tokens of the same user keep working
+	_, valid, err = repo.ValidateAccessToken(context.Background(), anotherToken.Token)
+	assert.Nil(t, err)
 	assert.True(t, valid)
+
+	// deleting an already expired token is a no-op
+	expired := entity.NewAccessToken(userID, "expired-token", time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour), "")
+	assert.Nil(t, repo.DeleteAccessToken(context.Background(), expired))
+}
+
+// newTestJWTAccessTokenRepo creates a JWT access token repository backed by a nutsdb cache for the denylist.
+func newTestJWTAccessTokenRepo(t *testing.T, unitTestCtx unittest.UnitTestContext, clock domain_client.IClock) *AuthAccessTokenRepositoryJWTImpl {
+	t.Helper()
+
+	nutsDBClient, err := client.NewNutsDBClient(unitTestCtx.Config)
+	if err != nil {
+		t.Fatalf("fail to create nutsdb client: %v", err)
+	}
+	t.Cleanup(func() { nutsDBClient.Close() })
+
+	cache := NewCacheNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+	return NewAuthAccessTokenRepositoryJWTImpl(unitTestCtx.Config, unitTestCtx.WritableConfig, cache, clock, fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 }
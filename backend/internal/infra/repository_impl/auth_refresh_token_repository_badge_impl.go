@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"time"
 	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	domain_client "ya-tool-craft/internal/domain/client"
 	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/infra/repository_impl/client"
 	"ya-tool-craft/internal/utils"
 
@@ -15,47 +18,115 @@ import (
 	"github.com/pkg/errors"
 )
 
-func NewAuthRefreshTokenRepositoryBadgerImpl(config config.Config, client *client.BadgerClient) *AuthRefreshTokenRepositoryBadgerImpl {
+func NewAuthRefreshTokenRepositoryBadgerImpl(config config.Config, client *client.BadgerClient, clock domain_client.IClock, ttls repository.ISessionTTLProvider) *AuthRefreshTokenRepositoryBadgerImpl {
 	return &AuthRefreshTokenRepositoryBadgerImpl{
 		config: config,
 		client: *client,
+		clock:  clock,
+		ttls:   ttls,
 	}
 }
 
 type AuthRefreshTokenRepositoryBadgerImpl struct {
 	config config.Config
 	client client.BadgerClient
+	clock  domain_client.IClock
+	ttls   repository.ISessionTTLProvider
 }
 
-// RefreshTokenModel represents the refresh token data stored in BadgerDB
+// the badger impl is not bound in DI, the assertion keeps it implementing the whole repository interface
+var _ repository.IAuthRefreshTokenRepository = (*AuthRefreshTokenRepositoryBadgerImpl)(nil)
+
+// RefreshTokenModel represents the refresh token data stored in BadgerDB, NutsDB and redis.
+// Only the hash of the token is stored, a presented token is looked up by its hash.
 type RefreshTokenModel struct {
 	UserID    string    `json:"user_id"`
-	Token     string    `json:"token"`
 	TokenHash string    `json:"token_hash"`
 	IssueAt   time.Time `json:"issue_at"`
 	ExpireAt  time.Time `json:"expire_at"`
+	// LegacyToken is the raw token older versions stored next to the hash,
+	// such entries are rewritten without it when they are read
+	LegacyToken string `json:"token,omitempty"`
+	// FamilyID is empty for tokens stored before rotation, they are the first of their family
+	FamilyID string `json:"family_id,omitempty"`
+	// RotatedAt is set once the token was replaced by a rotation, it is kept until it expires to detect its reuse
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	// ClientID and Scopes are set for the tokens issued to an oidc client
+	ClientID string   `json:"client_id,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+}
+
+func newRefreshTokenModel(refreshToken entity.RefreshToken) RefreshTokenModel {
+	return RefreshTokenModel{
+		UserID:    string(refreshToken.UserID),
+		TokenHash: refreshToken.TokenHash,
+		IssueAt:   refreshToken.IssueAt,
+		ExpireAt:  refreshToken.ExpireAt,
+		FamilyID:  refreshToken.FamilyID,
+		ClientID:  refreshToken.ClientID,
+		Scopes:    refreshToken.Scopes,
+	}
+}
+
+func (m RefreshTokenModel) toEntity(tokenHash string) entity.RefreshToken {
+	refreshToken := entity.NewRefreshTokenFromHash(entity.UserIDEntity(m.UserID), tokenHash, m.IssueAt, m.ExpireAt)
+	if m.FamilyID != "" {
+		refreshToken.FamilyID = m.FamilyID
+	}
+	refreshToken.ClientID = m.ClientID
+	refreshToken.Scopes = m.Scopes
+	return refreshToken
+}
+
+// rotatedEntity builds the token replacing the stored one, it keeps the family, the client and the expiry,
+// rotation does not extend the session
+func (m RefreshTokenModel) rotatedEntity(tokenHash string, issueAt time.Time) entity.RefreshToken {
+	stored := m.toEntity(tokenHash)
+	rotated := entity.NewRefreshToken(stored.UserID, fmt.Sprintf("rt-%s", uuid.New().String()), issueAt, stored.ExpireAt)
+	rotated.FamilyID = stored.FamilyID
+	rotated.ClientID = stored.ClientID
+	rotated.Scopes = stored.Scopes
+	return rotated
+}
+
+// withoutLegacyToken returns the JSON of the model without the raw token, ok is false when there is nothing to rewrite
+func withoutLegacyToken(val []byte) (data []byte, ok bool, err error) {
+	var model RefreshTokenModel
+	if err := json.Unmarshal(val, &model); err != nil {
+		return nil, false, err
+	}
+	if model.LegacyToken == "" {
+		return nil, false, nil
+	}
+	model.LegacyToken = ""
+	data, err = json.Marshal(model)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
 }
 
 // IssueRefreshToken generates a new refresh token for the given user
 func (r *AuthRefreshTokenRepositoryBadgerImpl) IssueRefreshToken(ctx context.Context, userID entity.UserIDEntity) (entity.RefreshToken, error) {
+	return r.IssueClientRefreshToken(ctx, userID, "", nil)
+}
+
+// IssueClientRefreshToken generates a new refresh token of the user for an oidc client, the tokens of ToolBake itself
+// have no client id
+func (r *AuthRefreshTokenRepositoryBadgerImpl) IssueClientRefreshToken(ctx context.Context, userID entity.UserIDEntity, clientID string, scopes []string) (entity.RefreshToken, error) {
 	// generate a unique token
 	token := fmt.Sprintf("rt-%s", uuid.New().String())
 
 	// calculate issue and expire time
-	issueAt := utils.NowToSecond()
-	ttl := utils.TTLInSecondToTimeDuration(r.config.RefreshTokenTTL)
+	issueAt := utils.ToSecond(r.clock.Now())
+	ttl := utils.TTLInSecondToTimeDuration(r.ttls.RefreshTokenTTL(ctx))
 	expireAt := issueAt.Add(ttl)
 
 	refreshToken := entity.NewRefreshToken(userID, token, issueAt, expireAt)
+	refreshToken.ClientID = clientID
+	refreshToken.Scopes = scopes
 
-	// create token model
-	model := RefreshTokenModel{
-		UserID:    string(refreshToken.UserID),
-		Token:     refreshToken.Token,
-		TokenHash: refreshToken.TokenHash,
-		IssueAt:   refreshToken.IssueAt,
-		ExpireAt:  refreshToken.ExpireAt,
-	}
+	model := newRefreshTokenModel(refreshToken)
 
 	// serialize to JSON
 	data, err := json.Marshal(model)
@@ -78,7 +149,11 @@ func (r *AuthRefreshTokenRepositoryBadgerImpl) IssueRefreshToken(ctx context.Con
 
 // ValidateRefreshToken checks if the given token is valid and not expired
 func (r *AuthRefreshTokenRepositoryBadgerImpl) ValidateRefreshToken(ctx context.Context, token string) (entity.RefreshToken, bool, error) {
-	return r.ValidateRefreshTokenHash(ctx, utils.Sha256String(token))
+	refreshToken, valid, err := r.ValidateRefreshTokenHash(ctx, utils.Sha256String(token))
+	if valid {
+		refreshToken.Token = token
+	}
+	return refreshToken, valid, err
 }
 
 // ValidateRefreshTokenHash validates an already hashed refresh token key
@@ -106,19 +181,15 @@ func (r *AuthRefreshTokenRepositoryBadgerImpl) ValidateRefreshTokenHash(ctx cont
 	}
 
 	// check if token is expired (double check, BadgerDB TTL should handle this)
-	if time.Now().After(model.ExpireAt) {
+	if r.clock.Now().After(model.ExpireAt) || model.RotatedAt != nil {
 		return entity.RefreshToken{}, false, nil
 	}
 
-	// convert model to entity via factory to keep hashing logic centralized
-	refreshToken := entity.NewRefreshToken(
-		entity.UserIDEntity(model.UserID),
-		model.Token,
-		model.IssueAt,
-		model.ExpireAt,
-	)
+	if model.LegacyToken != "" {
+		r.rewriteLegacyToken(ctx, tokenHash)
+	}
 
-	return refreshToken, true, nil
+	return model.toEntity(tokenHash), true, nil
 }
 
 // DeleteRefreshToken removes the given token from storage
@@ -158,6 +229,12 @@ func (r *AuthRefreshTokenRepositoryBadgerImpl) DeleteRefreshTokenByHash(ctx cont
 
 // DeleteAllTokensByUserID removes all refresh tokens for the given user.
 func (r *AuthRefreshTokenRepositoryBadgerImpl) DeleteAllTokensByUserID(ctx context.Context, userID entity.UserIDEntity) error {
+	return r.DeleteOtherTokensByUserID(ctx, userID, "")
+}
+
+// DeleteOtherTokensByUserID removes all refresh tokens for the given user but the one with keepTokenHash, an empty
+// hash keeps none.
+func (r *AuthRefreshTokenRepositoryBadgerImpl) DeleteOtherTokensByUserID(ctx context.Context, userID entity.UserIDEntity, keepTokenHash string) error {
 	var keysToDelete [][]byte
 
 	// first, collect all keys belonging to the user
@@ -174,7 +251,7 @@ func (r *AuthRefreshTokenRepositoryBadgerImpl) DeleteAllTokensByUserID(ctx conte
 				if err := json.Unmarshal(val, &model); err != nil {
 					return nil // skip invalid entries
 				}
-				if model.UserID == string(userID) {
+				if model.UserID == string(userID) && string(item.Key()) != keepTokenHash {
 					keysToDelete = append(keysToDelete, append([]byte{}, item.Key()...))
 				}
 				return nil
@@ -202,3 +279,183 @@ func (r *AuthRefreshTokenRepositoryBadgerImpl) DeleteAllTokensByUserID(ctx conte
 
 	return nil
 }
+
+// ListActiveSessionsByUserID returns the unexpired refresh tokens of the user that were not rotated.
+func (r *AuthRefreshTokenRepositoryBadgerImpl) ListActiveSessionsByUserID(ctx context.Context, userID entity.UserIDEntity) ([]entity.RefreshToken, error) {
+	sessions := []entity.RefreshToken{}
+	now := r.clock.Now()
+	err := r.client.DB.View(func(txn *badger.Txn) error {
+		return forEachBadgerRefreshToken(txn, userID, func(tokenHash string, model RefreshTokenModel) error {
+			if now.After(model.ExpireAt) || model.RotatedAt != nil {
+				return nil
+			}
+			sessions = append(sessions, model.toEntity(tokenHash))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to list user refresh tokens from badger")
+	}
+	return sessions, nil
+}
+
+// RotateRefreshToken replaces a valid refresh token with a new one of the same family in a single transaction.
+// The replaced token stays stored with its remaining TTL, marked as rotated.
+func (r *AuthRefreshTokenRepositoryBadgerImpl) RotateRefreshToken(ctx context.Context, refreshToken entity.RefreshToken) (entity.RefreshToken, bool, error) {
+	now := r.clock.Now()
+	var rotated entity.RefreshToken
+	var ok bool
+
+	err := r.client.DB.Update(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(refreshToken.TokenHash))
+		if err != nil {
+			if err == badger.ErrKeyNotFound {
+				return nil
+			}
+			return err
+		}
+		var model RefreshTokenModel
+		if err := item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &model)
+		}); err != nil {
+			return err
+		}
+		if model.RotatedAt != nil || !now.Before(model.ExpireAt) {
+			return nil
+		}
+
+		rotated = model.rotatedEntity(refreshToken.TokenHash, utils.ToSecond(now))
+		data, err := json.Marshal(newRefreshTokenModel(rotated))
+		if err != nil {
+			return err
+		}
+		entry := badger.NewEntry([]byte(rotated.TokenHash), data)
+		entry.ExpiresAt = item.ExpiresAt()
+		if err := txn.SetEntry(entry); err != nil {
+			return err
+		}
+
+		model.FamilyID = rotated.FamilyID
+		model.RotatedAt = &now
+		model.LegacyToken = ""
+		data, err = json.Marshal(model)
+		if err != nil {
+			return err
+		}
+		entry = badger.NewEntry([]byte(refreshToken.TokenHash), data)
+		entry.ExpiresAt = item.ExpiresAt()
+		if err := txn.SetEntry(entry); err != nil {
+			return err
+		}
+		ok = true
+		return nil
+	})
+	if err != nil {
+		return entity.RefreshToken{}, false, errors.Wrap(err, "fail to rotate refresh token in badger")
+	}
+	return rotated, ok, nil
+}
+
+// FindRotatedRefreshToken looks up a token that was replaced by a rotation and did not expire yet.
+func (r *AuthRefreshTokenRepositoryBadgerImpl) FindRotatedRefreshToken(ctx context.Context, token string) (entity.RefreshToken, bool, error) {
+	tokenHash := utils.Sha256String(token)
+	var model RefreshTokenModel
+	err := r.client.DB.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(tokenHash))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &model)
+		})
+	})
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return entity.RefreshToken{}, false, nil
+		}
+		return entity.RefreshToken{}, false, errors.Wrap(err, "fail to retrieve refresh token from badger")
+	}
+	if model.RotatedAt == nil || r.clock.Now().After(model.ExpireAt) {
+		return entity.RefreshToken{}, false, nil
+	}
+	return model.toEntity(tokenHash), true, nil
+}
+
+// DeleteTokenFamily removes the tokens of the user that belong to the family.
+func (r *AuthRefreshTokenRepositoryBadgerImpl) DeleteTokenFamily(ctx context.Context, userID entity.UserIDEntity, familyID string) ([]string, error) {
+	var deleted []string
+	err := r.client.DB.Update(func(txn *badger.Txn) error {
+		err := forEachBadgerRefreshToken(txn, userID, func(tokenHash string, model RefreshTokenModel) error {
+			if model.toEntity(tokenHash).FamilyID == familyID {
+				deleted = append(deleted, tokenHash)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, tokenHash := range deleted {
+			if err := txn.Delete([]byte(tokenHash)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to delete refresh token family from badger")
+	}
+	return deleted, nil
+}
+
+// CleanupExpiredTokenHashesForUser is a no-op, BadgerDB keeps no per-user index and drops expired tokens by their TTL.
+func (r *AuthRefreshTokenRepositoryBadgerImpl) CleanupExpiredTokenHashesForUser(ctx context.Context, userID entity.UserIDEntity) error {
+	return nil
+}
+
+// forEachBadgerRefreshToken calls fn for every stored refresh token of the user, entries that are not refresh tokens are skipped.
+func forEachBadgerRefreshToken(txn *badger.Txn, userID entity.UserIDEntity, fn func(tokenHash string, model RefreshTokenModel) error) error {
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+
+	for it.Rewind(); it.Valid(); it.Next() {
+		item := it.Item()
+		var model RefreshTokenModel
+		if err := item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &model)
+		}); err != nil {
+			continue
+		}
+		if model.UserID != string(userID) {
+			continue
+		}
+		if err := fn(string(item.KeyCopy(nil)), model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteLegacyToken drops the raw token an older version stored with the entry, keeping its expiry.
+// The entry is read again in the same transaction, so a token deleted meanwhile is not written back.
+func (r *AuthRefreshTokenRepositoryBadgerImpl) rewriteLegacyToken(ctx context.Context, tokenHash string) {
+	err := r.client.DB.Update(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(tokenHash))
+		if err != nil {
+			return err
+		}
+		val, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		data, ok, err := withoutLegacyToken(val)
+		if err != nil || !ok {
+			return err
+		}
+		entry := badger.NewEntry([]byte(tokenHash), data)
+		entry.ExpiresAt = item.ExpiresAt()
+		return txn.SetEntry(entry)
+	})
+	if err != nil && err != badger.ErrKeyNotFound {
+		logger.Warnf(ctx, "fail to rewrite legacy refresh token %s: %v", tokenHash, err)
+	}
+}
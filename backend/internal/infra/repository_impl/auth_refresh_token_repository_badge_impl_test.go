@@ -9,8 +9,10 @@ import (
 	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/infra/repository_impl/client"
 	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
 	"ya-tool-craft/internal/utils"
 
+	badger "github.com/dgraph-io/badger/v4"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,7 +20,7 @@ func TestAuthRefreshTokenRepositoryImpl_IssueRefreshToken(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Test issuing a refresh token
 		userID := entity.UserIDEntity("u-test-user-123")
@@ -43,7 +45,7 @@ func TestAuthRefreshTokenRepositoryImpl_ValidateRefreshToken(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Issue a token first
 		userID := entity.UserIDEntity("u-test-user-456")
@@ -63,7 +65,9 @@ func TestAuthRefreshTokenRepositoryImpl_ValidateRefreshToken(t *testing.T) {
 		refreshTokenByHash, valid, err := authTokenRepo.ValidateRefreshTokenHash(ctx, tokenHash)
 		assert.Nil(t, err)
 		assert.True(t, valid)
-		assert.Equal(t, refreshToken, refreshTokenByHash)
+		// the raw token is not stored, a lookup by hash cannot return it
+		assert.Empty(t, refreshTokenByHash.Token)
+		assert.Equal(t, entity.NewRefreshTokenFromHash(userID, refreshToken.TokenHash, refreshToken.IssueAt, refreshToken.ExpireAt), refreshTokenByHash)
 
 		// Test validating non-existent token
 		_, valid, err = authTokenRepo.ValidateRefreshToken(ctx, "rt-non-existent-token")
@@ -86,7 +90,7 @@ func TestAuthRefreshTokenRepositoryImpl_DeleteRefreshToken(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Issue a token first
 		userID := entity.UserIDEntity("u-test-user-789")
@@ -117,7 +121,7 @@ func TestAuthRefreshTokenRepositoryImpl_DeleteRefreshTokenByHash(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Issue a token first
 		userID := entity.UserIDEntity("u-test-user-hash")
@@ -150,7 +154,7 @@ func TestAuthRefreshTokenRepositoryImpl_MultipleTokens(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Issue multiple tokens for different users
 		userID1 := entity.UserIDEntity("u-test-user-001")
@@ -196,7 +200,7 @@ func TestAuthRefreshTokenRepositoryImpl_TokenExpiration(t *testing.T) {
 	assert.Equal(t, uint64(15778463), unitTestCtx.Config.RefreshTokenTTL, "RefreshTokenTTL should be 15778463 seconds as configured in .env.test")
 
 	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Issue a token
 		userID := entity.UserIDEntity("u-test-user-expiry")
@@ -217,7 +221,7 @@ func TestAuthRefreshTokenRepositoryImpl_ConcurrentIssueRefreshToken(t *testing.T
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Number of concurrent goroutines
 		concurrency := 50
@@ -295,7 +299,7 @@ func TestAuthRefreshTokenRepositoryImpl_DeleteAllTokensByUserID(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Create 5 users, each with 4 tokens
 		numUsers := 5
@@ -363,7 +367,7 @@ func TestAuthRefreshTokenRepositoryImpl_DeleteAllTokensByUserID_NoTokens(t *test
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Delete tokens for a user that has no tokens (should not error)
 		userID := entity.UserIDEntity("u-test-user-no-tokens")
@@ -371,3 +375,146 @@ func TestAuthRefreshTokenRepositoryImpl_DeleteAllTokensByUserID_NoTokens(t *test
 		assert.Nil(t, err)
 	})
 }
+
+func TestAuthRefreshTokenRepositoryImpl_DeleteOtherTokensByUserID(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
+		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userID := entity.UserIDEntity("u-test-user-delete-other")
+		var tokens []entity.RefreshToken
+		for i := 0; i < 3; i++ {
+			token, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+			assert.Nil(t, err)
+			tokens = append(tokens, token)
+		}
+		otherUserToken, err := authTokenRepo.IssueRefreshToken(ctx, entity.UserIDEntity("u-test-user-delete-other-another"))
+		assert.Nil(t, err)
+
+		err = authTokenRepo.DeleteOtherTokensByUserID(ctx, userID, tokens[0].TokenHash)
+		assert.Nil(t, err)
+
+		// only the kept token of the user is still valid
+		for i, token := range tokens {
+			_, valid, err := authTokenRepo.ValidateRefreshToken(ctx, token.Token)
+			assert.Nil(t, err)
+			assert.Equal(t, i == 0, valid, "token %d", i)
+		}
+		_, valid, err := authTokenRepo.ValidateRefreshToken(ctx, otherUserToken.Token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+
+		// the kept token is still known as a token of the user
+		err = authTokenRepo.DeleteAllTokensByUserID(ctx, userID)
+		assert.Nil(t, err)
+		_, valid, err = authTokenRepo.ValidateRefreshToken(ctx, tokens[0].Token)
+		assert.Nil(t, err)
+		assert.False(t, valid)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryImpl_RewritesLegacyToken(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
+		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		readStored := func(tokenHash string) (string, uint64) {
+			var data []byte
+			var expiresAt uint64
+			err := badgerClient.DB.View(func(txn *badger.Txn) error {
+				item, err := txn.Get([]byte(tokenHash))
+				if err != nil {
+					return err
+				}
+				expiresAt = item.ExpiresAt()
+				data, err = item.ValueCopy(nil)
+				return err
+			})
+			assert.Nil(t, err)
+			return string(data), expiresAt
+		}
+
+		// newly issued tokens are stored by hash only
+		issued, err := authTokenRepo.IssueRefreshToken(ctx, entity.UserIDEntity("u-test-user-hash-only"))
+		assert.Nil(t, err)
+		stored, _ := readStored(issued.TokenHash)
+		assert.NotContains(t, stored, issued.Token)
+
+		// an entry written by an older version still holds the raw token
+		userID := entity.UserIDEntity("u-test-user-legacy")
+		token := "rt-test-token-legacy"
+		tokenHash := utils.Sha256String(token)
+		now := time.Now().UTC()
+		data := fmt.Sprintf(`{"user_id":%q,"token":%q,"token_hash":%q,"issue_at":%q,"expire_at":%q}`,
+			userID, token, tokenHash, now.Format(time.RFC3339), now.Add(time.Hour).Format(time.RFC3339))
+		err = badgerClient.DB.Update(func(txn *badger.Txn) error {
+			return txn.SetEntry(badger.NewEntry([]byte(tokenHash), []byte(data)).WithTTL(time.Hour))
+		})
+		assert.Nil(t, err)
+		_, legacyExpiresAt := readStored(tokenHash)
+
+		refreshToken, valid, err := authTokenRepo.ValidateRefreshToken(ctx, token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+		assert.Equal(t, userID, refreshToken.UserID)
+		assert.Equal(t, token, refreshToken.Token)
+
+		// the lookup rewrote the entry without the raw token and kept its expiry
+		stored, expiresAt := readStored(tokenHash)
+		assert.NotContains(t, stored, token)
+		assert.Equal(t, legacyExpiresAt, expiresAt)
+
+		_, valid, err = authTokenRepo.ValidateRefreshToken(ctx, token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryImpl_RotateRefreshToken(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
+		authTokenRepo := NewAuthRefreshTokenRepositoryBadgerImpl(unitTestCtx.Config, badgerClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userID := entity.UserIDEntity("u-test-user-rotate")
+		token, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+		otherFamily, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+
+		rotated, ok, err := authTokenRepo.RotateRefreshToken(ctx, token)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, token.FamilyID, rotated.FamilyID)
+		assert.Equal(t, token.ExpireAt, rotated.ExpireAt)
+
+		// the replaced token is no longer valid but is found as rotated, it can not be rotated twice
+		_, valid, err := authTokenRepo.ValidateRefreshToken(ctx, token.Token)
+		assert.Nil(t, err)
+		assert.False(t, valid)
+		found, ok, err := authTokenRepo.FindRotatedRefreshToken(ctx, token.Token)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, token.FamilyID, found.FamilyID)
+		_, ok, err = authTokenRepo.RotateRefreshToken(ctx, token)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		sessions, err := authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{rotated.TokenHash, otherFamily.TokenHash}, []string{sessions[0].TokenHash, sessions[1].TokenHash})
+
+		// deleting the family keeps the user's other sessions
+		deleted, err := authTokenRepo.DeleteTokenFamily(ctx, userID, token.FamilyID)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{token.TokenHash, rotated.TokenHash}, deleted)
+		_, valid, err = authTokenRepo.ValidateRefreshToken(ctx, rotated.Token)
+		assert.Nil(t, err)
+		assert.False(t, valid)
+		_, valid, err = authTokenRepo.ValidateRefreshToken(ctx, otherFamily.Token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
 	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	domain_client "ya-tool-craft/internal/domain/client"
 	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/infra/repository_impl/client"
 	"ya-tool-craft/internal/utils"
 
@@ -16,11 +18,11 @@ import (
 )
 
 const (
-	nutsdbRefreshTokenBucket      = "refresh_token"
-	nutsdbRefreshTokenUserBucket  = "refresh_token_user"
+	nutsdbRefreshTokenBucket     = "refresh_token"
+	nutsdbRefreshTokenUserBucket = "refresh_token_user"
 )
 
-func NewAuthRefreshTokenRepositoryNutsDBImpl(config config.Config, client *client.NutsDBClient) *AuthRefreshTokenRepositoryNutsDBImpl {
+func NewAuthRefreshTokenRepositoryNutsDBImpl(config config.Config, client *client.NutsDBClient, clock domain_client.IClock, ttls repository.ISessionTTLProvider) *AuthRefreshTokenRepositoryNutsDBImpl {
 	// ensure buckets exist
 	if err := client.DB.Update(func(tx *nutsdb.Tx) error {
 		if !tx.ExistBucket(nutsdb.DataStructureBTree, nutsdbRefreshTokenBucket) {
@@ -41,31 +43,38 @@ func NewAuthRefreshTokenRepositoryNutsDBImpl(config config.Config, client *clien
 	return &AuthRefreshTokenRepositoryNutsDBImpl{
 		config: config,
 		client: *client,
+		clock:  clock,
+		ttls:   ttls,
 	}
 }
 
 type AuthRefreshTokenRepositoryNutsDBImpl struct {
 	config config.Config
 	client client.NutsDBClient
+	clock  domain_client.IClock
+	ttls   repository.ISessionTTLProvider
 }
 
 // IssueRefreshToken generates a new refresh token for the given user
 func (r *AuthRefreshTokenRepositoryNutsDBImpl) IssueRefreshToken(ctx context.Context, userID entity.UserIDEntity) (entity.RefreshToken, error) {
+	return r.IssueClientRefreshToken(ctx, userID, "", nil)
+}
+
+// IssueClientRefreshToken generates a new refresh token of the user for an oidc client, the tokens of ToolBake itself
+// have no client id
+func (r *AuthRefreshTokenRepositoryNutsDBImpl) IssueClientRefreshToken(ctx context.Context, userID entity.UserIDEntity, clientID string, scopes []string) (entity.RefreshToken, error) {
 	token := fmt.Sprintf("rt-%s", uuid.New().String())
 
-	issueAt := utils.NowToSecond()
-	ttl := utils.TTLInSecondToTimeDuration(r.config.RefreshTokenTTL)
+	issueAt := utils.ToSecond(r.clock.Now())
+	ttlSeconds := r.ttls.RefreshTokenTTL(ctx)
+	ttl := utils.TTLInSecondToTimeDuration(ttlSeconds)
 	expireAt := issueAt.Add(ttl)
 
 	refreshToken := entity.NewRefreshToken(userID, token, issueAt, expireAt)
+	refreshToken.ClientID = clientID
+	refreshToken.Scopes = scopes
 
-	model := RefreshTokenModel{
-		UserID:    string(refreshToken.UserID),
-		Token:     refreshToken.Token,
-		TokenHash: refreshToken.TokenHash,
-		IssueAt:   refreshToken.IssueAt,
-		ExpireAt:  refreshToken.ExpireAt,
-	}
+	model := newRefreshTokenModel(refreshToken)
 
 	data, err := json.Marshal(model)
 	if err != nil {
@@ -73,7 +82,7 @@ func (r *AuthRefreshTokenRepositoryNutsDBImpl) IssueRefreshToken(ctx context.Con
 	}
 
 	err = r.client.DB.Update(func(tx *nutsdb.Tx) error {
-		if err := tx.Put(nutsdbRefreshTokenBucket, []byte(refreshToken.TokenHash), data, uint32(r.config.RefreshTokenTTL)); err != nil {
+		if err := tx.Put(nutsdbRefreshTokenBucket, []byte(refreshToken.TokenHash), data, uint32(ttlSeconds)); err != nil {
 			return err
 		}
 		// store token hash in user's set for fast lookup by userID
@@ -90,7 +99,11 @@ func (r *AuthRefreshTokenRepositoryNutsDBImpl) IssueRefreshToken(ctx context.Con
 
 // ValidateRefreshToken checks if the given token is valid and not expired
 func (r *AuthRefreshTokenRepositoryNutsDBImpl) ValidateRefreshToken(ctx context.Context, token string) (entity.RefreshToken, bool, error) {
-	return r.ValidateRefreshTokenHash(ctx, utils.Sha256String(token))
+	refreshToken, valid, err := r.ValidateRefreshTokenHash(ctx, utils.Sha256String(token))
+	if valid {
+		refreshToken.Token = token
+	}
+	return refreshToken, valid, err
 }
 
 // ValidateRefreshTokenHash validates an already hashed refresh token key
@@ -113,18 +126,15 @@ func (r *AuthRefreshTokenRepositoryNutsDBImpl) ValidateRefreshTokenHash(ctx cont
 	}
 
 	// check if token is expired (double check, NutsDB TTL should handle this)
-	if time.Now().After(model.ExpireAt) {
+	if r.clock.Now().After(model.ExpireAt) || model.RotatedAt != nil {
 		return entity.RefreshToken{}, false, nil
 	}
 
-	refreshToken := entity.NewRefreshToken(
-		entity.UserIDEntity(model.UserID),
-		model.Token,
-		model.IssueAt,
-		model.ExpireAt,
-	)
+	if model.LegacyToken != "" {
+		r.rewriteLegacyToken(ctx, tokenHash)
+	}
 
-	return refreshToken, true, nil
+	return model.toEntity(tokenHash), true, nil
 }
 
 // DeleteRefreshToken removes the given token from storage
@@ -184,6 +194,12 @@ func (r *AuthRefreshTokenRepositoryNutsDBImpl) DeleteRefreshTokenByHash(ctx cont
 
 // DeleteAllTokensByUserID removes all refresh tokens for the given user.
 func (r *AuthRefreshTokenRepositoryNutsDBImpl) DeleteAllTokensByUserID(ctx context.Context, userID entity.UserIDEntity) error {
+	return r.DeleteOtherTokensByUserID(ctx, userID, "")
+}
+
+// DeleteOtherTokensByUserID removes all refresh tokens for the given user but the one with keepTokenHash, an empty
+// hash keeps none.
+func (r *AuthRefreshTokenRepositoryNutsDBImpl) DeleteOtherTokensByUserID(ctx context.Context, userID entity.UserIDEntity, keepTokenHash string) error {
 	var tokenHashes [][]byte
 
 	// get all token hashes from the user's set
@@ -192,7 +208,11 @@ func (r *AuthRefreshTokenRepositoryNutsDBImpl) DeleteAllTokensByUserID(ctx conte
 		if err != nil {
 			return err
 		}
-		tokenHashes = members
+		for _, member := range members {
+			if string(member) != keepTokenHash {
+				tokenHashes = append(tokenHashes, member)
+			}
+		}
 		return nil
 	})
 
@@ -227,9 +247,177 @@ func (r *AuthRefreshTokenRepositoryNutsDBImpl) DeleteAllTokensByUserID(ctx conte
 	return nil
 }
 
+// ListActiveSessionsByUserID returns the unexpired refresh tokens in the user's token hash set.
+func (r *AuthRefreshTokenRepositoryNutsDBImpl) ListActiveSessionsByUserID(ctx context.Context, userID entity.UserIDEntity) ([]entity.RefreshToken, error) {
+	sessions := []entity.RefreshToken{}
+	now := r.clock.Now()
+	err := r.client.DB.View(func(tx *nutsdb.Tx) error {
+		members, err := tx.SMembers(nutsdbRefreshTokenUserBucket, []byte(string(userID)))
+		if err != nil {
+			return err
+		}
+		for _, hash := range members {
+			val, err := tx.Get(nutsdbRefreshTokenBucket, hash)
+			if err != nil {
+				if nutsdb.IsKeyNotFound(err) {
+					continue
+				}
+				return err
+			}
+			var model RefreshTokenModel
+			if err := json.Unmarshal(val, &model); err != nil {
+				return err
+			}
+			// the nutsdb TTL may not have fired yet, the stored expiry is authoritative
+			if model.UserID != string(userID) || now.After(model.ExpireAt) || model.RotatedAt != nil {
+				continue
+			}
+			sessions = append(sessions, model.toEntity(string(hash)))
+		}
+		return nil
+	})
+	if err != nil {
+		if nutsdb.IsBucketNotFound(err) || nutsdb.IsBucketEmpty(err) || nutsdb.IsKeyNotFound(err) || err.Error() == "set not exist" {
+			return sessions, nil
+		}
+		return nil, errors.Wrap(err, "fail to list user refresh tokens from nutsdb")
+	}
+	return sessions, nil
+}
+
+// RotateRefreshToken replaces a valid refresh token with a new one of the same family in a single transaction.
+// The replaced token stays stored with its remaining TTL, marked as rotated.
+func (r *AuthRefreshTokenRepositoryNutsDBImpl) RotateRefreshToken(ctx context.Context, refreshToken entity.RefreshToken) (entity.RefreshToken, bool, error) {
+	now := r.clock.Now()
+	var rotated entity.RefreshToken
+	var ok bool
+
+	err := r.client.DB.Update(func(tx *nutsdb.Tx) error {
+		val, err := tx.Get(nutsdbRefreshTokenBucket, []byte(refreshToken.TokenHash))
+		if err != nil {
+			if nutsdb.IsKeyNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		var model RefreshTokenModel
+		if err := json.Unmarshal(val, &model); err != nil {
+			return err
+		}
+		if model.RotatedAt != nil || !now.Before(model.ExpireAt) {
+			return nil
+		}
+
+		// the new token keeps the expiry, rotation does not extend the session
+		ttl := uint32(model.ExpireAt.Sub(now).Seconds()) + 1
+		rotated = model.rotatedEntity(refreshToken.TokenHash, utils.ToSecond(now))
+		data, err := json.Marshal(newRefreshTokenModel(rotated))
+		if err != nil {
+			return err
+		}
+		if err := tx.Put(nutsdbRefreshTokenBucket, []byte(rotated.TokenHash), data, ttl); err != nil {
+			return err
+		}
+		if err := tx.SAdd(nutsdbRefreshTokenUserBucket, []byte(model.UserID), []byte(rotated.TokenHash)); err != nil {
+			return err
+		}
+
+		model.FamilyID = rotated.FamilyID
+		model.RotatedAt = &now
+		model.LegacyToken = ""
+		data, err = json.Marshal(model)
+		if err != nil {
+			return err
+		}
+		if err := tx.Put(nutsdbRefreshTokenBucket, []byte(refreshToken.TokenHash), data, ttl); err != nil {
+			return err
+		}
+		ok = true
+		return nil
+	})
+	if err != nil {
+		return entity.RefreshToken{}, false, errors.Wrap(err, "fail to rotate refresh token in nutsdb")
+	}
+	return rotated, ok, nil
+}
+
+// FindRotatedRefreshToken looks up a token that was replaced by a rotation and did not expire yet.
+func (r *AuthRefreshTokenRepositoryNutsDBImpl) FindRotatedRefreshToken(ctx context.Context, token string) (entity.RefreshToken, bool, error) {
+	tokenHash := utils.Sha256String(token)
+	var model RefreshTokenModel
+	err := r.client.DB.View(func(tx *nutsdb.Tx) error {
+		val, err := tx.Get(nutsdbRefreshTokenBucket, []byte(tokenHash))
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(val, &model)
+	})
+	if err != nil {
+		if nutsdb.IsKeyNotFound(err) || nutsdb.IsBucketNotFound(err) {
+			return entity.RefreshToken{}, false, nil
+		}
+		return entity.RefreshToken{}, false, errors.Wrap(err, "fail to retrieve refresh token from nutsdb")
+	}
+	if model.RotatedAt == nil || r.clock.Now().After(model.ExpireAt) {
+		return entity.RefreshToken{}, false, nil
+	}
+	return model.toEntity(tokenHash), true, nil
+}
+
+// DeleteTokenFamily removes the tokens of the user's set that belong to the family.
+func (r *AuthRefreshTokenRepositoryNutsDBImpl) DeleteTokenFamily(ctx context.Context, userID entity.UserIDEntity, familyID string) ([]string, error) {
+	var deleted []string
+	err := r.client.DB.Update(func(tx *nutsdb.Tx) error {
+		members, err := tx.SMembers(nutsdbRefreshTokenUserBucket, []byte(string(userID)))
+		if err != nil {
+			return err
+		}
+		var familyHashes [][]byte
+		for _, hash := range members {
+			val, err := tx.Get(nutsdbRefreshTokenBucket, hash)
+			if err != nil {
+				if nutsdb.IsKeyNotFound(err) {
+					continue
+				}
+				return err
+			}
+			var model RefreshTokenModel
+			if err := json.Unmarshal(val, &model); err != nil {
+				return err
+			}
+			if model.toEntity(string(hash)).FamilyID != familyID {
+				continue
+			}
+			if err := tx.Delete(nutsdbRefreshTokenBucket, hash); err != nil && !nutsdb.IsKeyNotFound(err) {
+				return err
+			}
+			familyHashes = append(familyHashes, hash)
+			deleted = append(deleted, string(hash))
+		}
+		if len(familyHashes) == 0 {
+			return nil
+		}
+		if err := tx.SRem(nutsdbRefreshTokenUserBucket, []byte(string(userID)), familyHashes...); err != nil &&
+			err != nutsdb.ErrSetNotExist &&
+			err != nutsdb.ErrSetMemberNotExist {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		if nutsdb.IsBucketNotFound(err) || nutsdb.IsBucketEmpty(err) || nutsdb.IsKeyNotFound(err) || err.Error() == "set not exist" {
+			return deleted, nil
+		}
+		return nil, errors.Wrap(err, "fail to delete refresh token family from nutsdb")
+	}
+	return deleted, nil
+}
+
 // CleanupExpiredTokenHashesForUser removes stale entries from the user's token hash set.
 // It checks each token hash in the set; if the corresponding refresh token is expired or
 // no longer exists, the hash is removed from the set.
+// Expired tokens whose nutsdb TTL has not fired yet are left in the token nutsdbRefreshTokenBucket, they are
+// already rejected by ValidateRefreshTokenHash and nutsdb drops them shortly after.
 func (r *AuthRefreshTokenRepositoryNutsDBImpl) CleanupExpiredTokenHashesForUser(ctx context.Context, userID entity.UserIDEntity) error {
 	var tokenHashes [][]byte
 
@@ -251,9 +439,17 @@ func (r *AuthRefreshTokenRepositoryNutsDBImpl) CleanupExpiredTokenHashesForUser(
 
 	var staleHashes [][]byte
 
+	now := r.clock.Now()
 	err = r.client.DB.View(func(tx *nutsdb.Tx) error {
 		for _, hash := range tokenHashes {
-			if _, err := tx.Get(nutsdbRefreshTokenBucket, hash); err != nil {
+			val, err := tx.Get(nutsdbRefreshTokenBucket, hash)
+			if err != nil {
+				staleHashes = append(staleHashes, hash)
+				continue
+			}
+			// the nutsdb TTL may not have fired yet, the stored expiry is authoritative
+			var model RefreshTokenModel
+			if err := json.Unmarshal(val, &model); err == nil && now.After(model.ExpireAt) {
 				staleHashes = append(staleHashes, hash)
 			}
 		}
@@ -272,3 +468,29 @@ func (r *AuthRefreshTokenRepositoryNutsDBImpl) CleanupExpiredTokenHashesForUser(
 		return tx.SRem(nutsdbRefreshTokenUserBucket, []byte(string(userID)), staleHashes...)
 	})
 }
+
+// rewriteLegacyToken drops the raw token an older version stored with the entry, keeping its remaining TTL.
+// The entry is read again in the same transaction, so a token deleted meanwhile is not written back.
+func (r *AuthRefreshTokenRepositoryNutsDBImpl) rewriteLegacyToken(ctx context.Context, tokenHash string) {
+	err := r.client.DB.Update(func(tx *nutsdb.Tx) error {
+		val, err := tx.Get(nutsdbRefreshTokenBucket, []byte(tokenHash))
+		if err != nil {
+			return err
+		}
+		data, ok, err := withoutLegacyToken(val)
+		if err != nil || !ok {
+			return err
+		}
+		ttl, err := tx.GetTTL(nutsdbRefreshTokenBucket, []byte(tokenHash))
+		if err != nil {
+			return err
+		}
+		if ttl < 0 {
+			ttl = int64(nutsdb.Persistent)
+		}
+		return tx.Put(nutsdbRefreshTokenBucket, []byte(tokenHash), data, uint32(ttl))
+	})
+	if err != nil && !nutsdb.IsKeyNotFound(err) {
+		logger.Warnf(ctx, "fail to rewrite legacy refresh token %s: %v", tokenHash, err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/infra/repository_impl/client"
 	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
 	"ya-tool-craft/internal/utils"
 
 	"github.com/nutsdb/nutsdb"
@@ -20,7 +21,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_IssueRefreshToken(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Test issuing a refresh token
 		userID := entity.UserIDEntity("u-test-user-123")
@@ -45,7 +46,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_ValidateRefreshToken(t *testing.T)
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Issue a token first
 		userID := entity.UserIDEntity("u-test-user-456")
@@ -65,7 +66,9 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_ValidateRefreshToken(t *testing.T)
 		refreshTokenByHash, valid, err := authTokenRepo.ValidateRefreshTokenHash(ctx, tokenHash)
 		assert.Nil(t, err)
 		assert.True(t, valid)
-		assert.Equal(t, refreshToken, refreshTokenByHash)
+		// the raw token is not stored, a lookup by hash cannot return it
+		assert.Empty(t, refreshTokenByHash.Token)
+		assert.Equal(t, entity.NewRefreshTokenFromHash(userID, refreshToken.TokenHash, refreshToken.IssueAt, refreshToken.ExpireAt), refreshTokenByHash)
 
 		// Test validating non-existent token
 		_, valid, err = authTokenRepo.ValidateRefreshToken(ctx, "rt-non-existent-token")
@@ -88,7 +91,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_DeleteRefreshToken(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Issue a token first
 		userID := entity.UserIDEntity("u-test-user-789")
@@ -119,7 +122,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_DeleteRefreshTokenByHash(t *testin
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Issue a token first
 		userID := entity.UserIDEntity("u-test-user-hash")
@@ -152,7 +155,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_DeleteRefreshTokenByHash_ReturnErr
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		tokenHash := utils.Sha256String("rt-corrupted-token")
 		err := nutsDBClient.DB.Update(func(tx *nutsdb.Tx) error {
@@ -170,7 +173,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_DeleteRefreshTokenByHash_ReturnErr
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		userID := entity.UserIDEntity("u-test-user-delete-hash-error")
 		token := "rt-test-token-delete-hash-error"
@@ -179,7 +182,6 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_DeleteRefreshTokenByHash_ReturnErr
 
 		model := RefreshTokenModel{
 			UserID:    string(userID),
-			Token:     token,
 			TokenHash: tokenHash,
 			IssueAt:   now,
 			ExpireAt:  now.Add(time.Hour),
@@ -205,7 +207,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_MultipleTokens(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Issue multiple tokens for different users
 		userID1 := entity.UserIDEntity("u-test-user-001")
@@ -250,7 +252,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_TokenExpiration(t *testing.T) {
 	assert.Equal(t, uint64(15778463), unitTestCtx.Config.RefreshTokenTTL, "RefreshTokenTTL should be 15778463 seconds as configured in .env.test")
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Issue a token
 		userID := entity.UserIDEntity("u-test-user-expiry")
@@ -269,7 +271,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_ConcurrentIssueRefreshToken(t *tes
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		concurrency := 50
 		tokensPerGoroutine := 10
@@ -336,7 +338,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_DeleteAllTokensByUserID(t *testing
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		numUsers := 5
 		tokensPerUser := 4
@@ -394,7 +396,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_DeleteAllTokensByUserID_NoTokens(t
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Delete tokens for a user that has no tokens (should not error)
 		userID := entity.UserIDEntity("u-test-user-no-tokens")
@@ -407,7 +409,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_DeleteAllTokensByUserID_ReturnErro
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		userID := entity.UserIDEntity("u-test-user-delete-all-error")
 		err := nutsDBClient.DB.Update(func(tx *nutsdb.Tx) error {
@@ -424,6 +426,155 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_DeleteAllTokensByUserID_ReturnErro
 	})
 }
 
+func TestAuthRefreshTokenRepositoryNutsDBImpl_DeleteOtherTokensByUserID(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userID := entity.UserIDEntity("u-test-user-delete-other")
+		var tokens []entity.RefreshToken
+		for i := 0; i < 3; i++ {
+			token, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+			assert.Nil(t, err)
+			tokens = append(tokens, token)
+		}
+		otherUserToken, err := authTokenRepo.IssueRefreshToken(ctx, entity.UserIDEntity("u-test-user-delete-other-another"))
+		assert.Nil(t, err)
+
+		err = authTokenRepo.DeleteOtherTokensByUserID(ctx, userID, tokens[0].TokenHash)
+		assert.Nil(t, err)
+
+		// only the kept token of the user is still valid
+		for i, token := range tokens {
+			_, valid, err := authTokenRepo.ValidateRefreshToken(ctx, token.Token)
+			assert.Nil(t, err)
+			assert.Equal(t, i == 0, valid, "token %d", i)
+		}
+		_, valid, err := authTokenRepo.ValidateRefreshToken(ctx, otherUserToken.Token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+
+		// the kept token is still known as a token of the user
+		err = authTokenRepo.DeleteAllTokensByUserID(ctx, userID)
+		assert.Nil(t, err)
+		_, valid, err = authTokenRepo.ValidateRefreshToken(ctx, tokens[0].Token)
+		assert.Nil(t, err)
+		assert.False(t, valid)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryNutsDBImpl_ListActiveSessionsByUserID(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
+		clock := fixtures.NewFakeClock(time.Now())
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, clock, fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userID := entity.UserIDEntity("u-test-user-sessions")
+		// the nutsdb store is shared between runs, drop the tokens a previous run left behind
+		assert.Nil(t, authTokenRepo.DeleteAllTokensByUserID(ctx, userID))
+		sessions, err := authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.Empty(t, sessions)
+
+		first, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+		second, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+		_, err = authTokenRepo.IssueRefreshToken(ctx, entity.UserIDEntity("u-test-user-sessions-another"))
+		assert.Nil(t, err)
+
+		sessions, err = authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []entity.RefreshToken{
+			entity.NewRefreshTokenFromHash(userID, first.TokenHash, first.IssueAt, first.ExpireAt),
+			entity.NewRefreshTokenFromHash(userID, second.TokenHash, second.IssueAt, second.ExpireAt),
+		}, sessions)
+
+		// deleted and expired tokens are no sessions anymore
+		assert.Nil(t, authTokenRepo.DeleteRefreshTokenByHash(ctx, first.TokenHash))
+		sessions, err = authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.Len(t, sessions, 1)
+		assert.Equal(t, second.TokenHash, sessions[0].TokenHash)
+
+		clock.Advance(utils.TTLInSecondToTimeDuration(unitTestCtx.Config.RefreshTokenTTL) + time.Second)
+		sessions, err = authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.Empty(t, sessions)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryNutsDBImpl_RotateRefreshToken(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
+		clock := fixtures.NewFakeClock(time.Now())
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, clock, fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userID := entity.UserIDEntity("u-test-user-rotate")
+		// the nutsdb store is shared between runs, drop the tokens a previous run left behind
+		assert.Nil(t, authTokenRepo.DeleteAllTokensByUserID(ctx, userID))
+		first, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+		other, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+
+		clock.Advance(time.Minute)
+		second, ok, err := authTokenRepo.RotateRefreshToken(ctx, first)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.NotEqual(t, first.Token, second.Token)
+		assert.Equal(t, first.TokenHash, second.FamilyID)
+		// rotation does not extend the session
+		assert.Equal(t, first.ExpireAt, second.ExpireAt)
+
+		// the replaced token is not valid anymore but can be found as rotated
+		_, valid, err := authTokenRepo.ValidateRefreshToken(ctx, first.Token)
+		assert.Nil(t, err)
+		assert.False(t, valid)
+		rotated, found, err := authTokenRepo.FindRotatedRefreshToken(ctx, first.Token)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, first.FamilyID, rotated.FamilyID)
+		_, found, err = authTokenRepo.FindRotatedRefreshToken(ctx, second.Token)
+		assert.Nil(t, err)
+		assert.False(t, found)
+
+		validated, valid, err := authTokenRepo.ValidateRefreshToken(ctx, second.Token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+		assert.Equal(t, second.FamilyID, validated.FamilyID)
+
+		// a token is rotated only once
+		_, ok, err = authTokenRepo.RotateRefreshToken(ctx, first)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		third, ok, err := authTokenRepo.RotateRefreshToken(ctx, validated)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, first.TokenHash, third.FamilyID)
+
+		sessions, err := authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{other.TokenHash, third.TokenHash}, []string{sessions[0].TokenHash, sessions[1].TokenHash})
+
+		// deleting the family keeps the tokens of other logins
+		deleted, err := authTokenRepo.DeleteTokenFamily(ctx, userID, first.TokenHash)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{first.TokenHash, second.TokenHash, third.TokenHash}, deleted)
+		_, found, err = authTokenRepo.FindRotatedRefreshToken(ctx, first.Token)
+		assert.Nil(t, err)
+		assert.False(t, found)
+		sessions, err = authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.Len(t, sessions, 1)
+		assert.Equal(t, other.TokenHash, sessions[0].TokenHash)
+	})
+}
+
 func TestAuthRefreshTokenRepositoryNutsDBImpl_CleanupExpiredTokenHashesForUser(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
@@ -432,7 +583,8 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_CleanupExpiredTokenHashesForUser(t
 		shortTTLConfig := unitTestCtx.Config
 		shortTTLConfig.RefreshTokenTTL = 2 // 2 seconds
 
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(shortTTLConfig, nutsDBClient)
+		clock := fixtures.NewFakeClock(time.Now())
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(shortTTLConfig, nutsDBClient, clock, fixtures.NewConfigSessionTTLs(shortTTLConfig))
 
 		userID := entity.UserIDEntity(fmt.Sprintf("u-test-cleanup-user-%d", time.Now().UnixNano()))
 
@@ -445,7 +597,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_CleanupExpiredTokenHashesForUser(t
 		// Switch to long TTL and issue 2 more tokens that won't expire
 		longTTLConfig := unitTestCtx.Config
 		longTTLConfig.RefreshTokenTTL = 3600
-		authTokenRepoLong := NewAuthRefreshTokenRepositoryNutsDBImpl(longTTLConfig, nutsDBClient)
+		authTokenRepoLong := NewAuthRefreshTokenRepositoryNutsDBImpl(longTTLConfig, nutsDBClient, clock, fixtures.NewConfigSessionTTLs(longTTLConfig))
 
 		var validTokens []entity.RefreshToken
 		for i := 0; i < 2; i++ {
@@ -466,8 +618,8 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_CleanupExpiredTokenHashesForUser(t
 		})
 		assert.Equal(t, 5, memberCount)
 
-		// Wait for the short-TTL tokens to expire
-		time.Sleep(3 * time.Second)
+		// Move past the expiry of the short-TTL tokens
+		clock.Advance(3 * time.Second)
 
 		// Run cleanup
 		err := authTokenRepoLong.CleanupExpiredTokenHashesForUser(ctx, userID)
@@ -497,7 +649,7 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_CleanupExpiredTokenHashesForUser_N
 	unitTestCtx := unittest.GetUnitTestCtx()
 
 	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
-		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
 
 		// Cleanup for a user with no tokens (should not error)
 		userID := entity.UserIDEntity("u-test-user-no-tokens-cleanup")
@@ -505,3 +657,100 @@ func TestAuthRefreshTokenRepositoryNutsDBImpl_CleanupExpiredTokenHashesForUser_N
 		assert.Nil(t, err)
 	})
 }
+
+func TestAuthRefreshTokenRepositoryNutsDBImpl_RewritesLegacyToken(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		readStored := func(tokenHash string) (string, int64) {
+			var data []byte
+			var ttl int64
+			err := nutsDBClient.DB.View(func(tx *nutsdb.Tx) error {
+				val, err := tx.Get(nutsdbRefreshTokenBucket, []byte(tokenHash))
+				if err != nil {
+					return err
+				}
+				data = val
+				ttl, err = tx.GetTTL(nutsdbRefreshTokenBucket, []byte(tokenHash))
+				return err
+			})
+			assert.Nil(t, err)
+			return string(data), ttl
+		}
+
+		// newly issued tokens are stored by hash only
+		issued, err := authTokenRepo.IssueRefreshToken(ctx, entity.UserIDEntity("u-test-user-hash-only"))
+		assert.Nil(t, err)
+		stored, _ := readStored(issued.TokenHash)
+		assert.NotContains(t, stored, issued.Token)
+
+		// an entry written by an older version still holds the raw token
+		userID := entity.UserIDEntity("u-test-user-legacy")
+		token := "rt-test-token-legacy"
+		tokenHash := utils.Sha256String(token)
+		now := time.Now().UTC()
+		data := fmt.Sprintf(`{"user_id":%q,"token":%q,"token_hash":%q,"issue_at":%q,"expire_at":%q}`,
+			userID, token, tokenHash, now.Format(time.RFC3339), now.Add(time.Hour).Format(time.RFC3339))
+		err = nutsDBClient.DB.Update(func(tx *nutsdb.Tx) error {
+			return tx.Put(nutsdbRefreshTokenBucket, []byte(tokenHash), []byte(data), 3600)
+		})
+		assert.Nil(t, err)
+
+		refreshToken, valid, err := authTokenRepo.ValidateRefreshToken(ctx, token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+		assert.Equal(t, userID, refreshToken.UserID)
+		assert.Equal(t, token, refreshToken.Token)
+
+		// the lookup rewrote the entry without the raw token and kept its TTL
+		stored, ttl := readStored(tokenHash)
+		assert.NotContains(t, stored, token)
+		assert.Greater(t, ttl, int64(0))
+		assert.LessOrEqual(t, ttl, int64(3600))
+
+		_, valid, err = authTokenRepo.ValidateRefreshToken(ctx, token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryNutsDBImpl_ClientRefreshToken(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
+		authTokenRepo := NewAuthRefreshTokenRepositoryNutsDBImpl(unitTestCtx.Config, nutsDBClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userID := entity.UserIDEntity("u-test-user-client")
+		issued, err := authTokenRepo.IssueClientRefreshToken(ctx, userID, "client-1", []string{"openid", "profile"})
+		assert.Nil(t, err)
+		assert.Equal(t, "client-1", issued.ClientID)
+
+		// the client and the scopes are stored with the token and kept by a rotation
+		validated, valid, err := authTokenRepo.ValidateRefreshToken(ctx, issued.Token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+		assert.Equal(t, "client-1", validated.ClientID)
+		assert.Equal(t, []string{"openid", "profile"}, validated.Scopes)
+
+		rotated, ok, err := authTokenRepo.RotateRefreshToken(ctx, validated)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "client-1", rotated.ClientID)
+		assert.Equal(t, []string{"openid", "profile"}, rotated.Scopes)
+		validated, valid, err = authTokenRepo.ValidateRefreshToken(ctx, rotated.Token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+		assert.Equal(t, "client-1", validated.ClientID)
+
+		// first-party tokens have no client
+		firstParty, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+		validated, valid, err = authTokenRepo.ValidateRefreshToken(ctx, firstParty.Token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+		assert.Empty(t, validated.ClientID)
+		assert.Nil(t, validated.Scopes)
+	})
+}
@@ -0,0 +1,441 @@
+package repository_impl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/infra/repository_impl/client"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// string key holding a refresh token, keyed by the sha256 of the token
+	redisRefreshTokenKeyPrefix = "refresh_token:"
+	// set key holding the token hashes of a user
+	redisRefreshTokenUserKeyPrefix = "refresh_token_user:"
+)
+
+func redisRefreshTokenKey(tokenHash string) string {
+	return redisRefreshTokenKeyPrefix + tokenHash
+}
+
+func redisRefreshTokenUserKey(userID entity.UserIDEntity) string {
+	return redisRefreshTokenUserKeyPrefix + string(userID)
+}
+
+func NewAuthRefreshTokenRepositoryRedisImpl(config config.Config, client *client.RedisClient, clock domain_client.IClock, ttls repository.ISessionTTLProvider) *AuthRefreshTokenRepositoryRedisImpl {
+	return &AuthRefreshTokenRepositoryRedisImpl{
+		config: config,
+		client: client,
+		clock:  clock,
+		ttls:   ttls,
+	}
+}
+
+// AuthRefreshTokenRepositoryRedisImpl stores refresh tokens in redis, so they are shared by every instance.
+// Tokens expire by the redis TTL, the user's set of token hashes expires with the newest token of the user.
+type AuthRefreshTokenRepositoryRedisImpl struct {
+	config config.Config
+	client *client.RedisClient
+	clock  domain_client.IClock
+	ttls   repository.ISessionTTLProvider
+}
+
+// IssueRefreshToken generates a new refresh token for the given user
+func (r *AuthRefreshTokenRepositoryRedisImpl) IssueRefreshToken(ctx context.Context, userID entity.UserIDEntity) (entity.RefreshToken, error) {
+	return r.IssueClientRefreshToken(ctx, userID, "", nil)
+}
+
+// IssueClientRefreshToken generates a new refresh token of the user for an oidc client, the tokens of ToolBake itself
+// have no client id
+func (r *AuthRefreshTokenRepositoryRedisImpl) IssueClientRefreshToken(ctx context.Context, userID entity.UserIDEntity, clientID string, scopes []string) (entity.RefreshToken, error) {
+	token := fmt.Sprintf("rt-%s", uuid.New().String())
+
+	issueAt := utils.ToSecond(r.clock.Now())
+	ttl := utils.TTLInSecondToTimeDuration(r.ttls.RefreshTokenTTL(ctx))
+	expireAt := issueAt.Add(ttl)
+
+	refreshToken := entity.NewRefreshToken(userID, token, issueAt, expireAt)
+	refreshToken.ClientID = clientID
+	refreshToken.Scopes = scopes
+
+	model := newRefreshTokenModel(refreshToken)
+
+	data, err := json.Marshal(model)
+	if err != nil {
+		return entity.RefreshToken{}, errors.Wrap(err, "fail to marshal refresh token to json")
+	}
+
+	userKey := redisRefreshTokenUserKey(userID)
+	_, err = r.client.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, redisRefreshTokenKey(refreshToken.TokenHash), data, ttl)
+		// store token hash in user's set for fast lookup by userID
+		pipe.SAdd(ctx, userKey, refreshToken.TokenHash)
+		pipe.Expire(ctx, userKey, ttl)
+		return nil
+	})
+	if err != nil {
+		return entity.RefreshToken{}, errors.Wrap(err, "fail to store refresh token to redis")
+	}
+
+	go func() {
+		if err := r.CleanupExpiredTokenHashesForUser(context.WithoutCancel(ctx), userID); err != nil {
+			logger.Errorf(ctx, "fail to cleanup expired refresh token hashes of user %s: %v", userID, err)
+		}
+	}()
+
+	return refreshToken, nil
+}
+
+// ValidateRefreshToken checks if the given token is valid and not expired
+func (r *AuthRefreshTokenRepositoryRedisImpl) ValidateRefreshToken(ctx context.Context, token string) (entity.RefreshToken, bool, error) {
+	refreshToken, valid, err := r.ValidateRefreshTokenHash(ctx, utils.Sha256String(token))
+	if valid {
+		refreshToken.Token = token
+	}
+	return refreshToken, valid, err
+}
+
+// ValidateRefreshTokenHash validates an already hashed refresh token key
+func (r *AuthRefreshTokenRepositoryRedisImpl) ValidateRefreshTokenHash(ctx context.Context, tokenHash string) (entity.RefreshToken, bool, error) {
+	model, exists, err := r.getModel(ctx, tokenHash)
+	if err != nil {
+		return entity.RefreshToken{}, false, errors.Wrap(err, "fail to retrieve refresh token from redis")
+	}
+	if !exists {
+		return entity.RefreshToken{}, false, nil
+	}
+
+	// check if token is expired (double check, redis TTL should handle this)
+	if r.clock.Now().After(model.ExpireAt) || model.RotatedAt != nil {
+		return entity.RefreshToken{}, false, nil
+	}
+
+	if model.LegacyToken != "" {
+		r.rewriteLegacyToken(ctx, tokenHash)
+	}
+
+	return model.toEntity(tokenHash), true, nil
+}
+
+// DeleteRefreshToken removes the given token from storage
+func (r *AuthRefreshTokenRepositoryRedisImpl) DeleteRefreshToken(ctx context.Context, token string) error {
+	return r.DeleteRefreshTokenByHash(ctx, utils.Sha256String(token))
+}
+
+// DeleteRefreshTokenByHash removes the refresh token whose hash is already provided.
+func (r *AuthRefreshTokenRepositoryRedisImpl) DeleteRefreshTokenByHash(ctx context.Context, tokenHash string) error {
+	// look up userID first so we can remove from the user's set
+	model, exists, err := r.getModel(ctx, tokenHash)
+	if err != nil {
+		return errors.Wrap(err, "fail to lookup refresh token before delete")
+	}
+	if !exists {
+		// token already deleted or expired
+		return nil
+	}
+
+	_, err = r.client.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, redisRefreshTokenKey(tokenHash))
+		if model.UserID != "" {
+			pipe.SRem(ctx, redisRefreshTokenUserKey(entity.UserIDEntity(model.UserID)), tokenHash)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "fail to delete refresh token from redis")
+	}
+	return nil
+}
+
+// DeleteAllTokensByUserID removes all refresh tokens for the given user.
+func (r *AuthRefreshTokenRepositoryRedisImpl) DeleteAllTokensByUserID(ctx context.Context, userID entity.UserIDEntity) error {
+	userKey := redisRefreshTokenUserKey(userID)
+	tokenHashes, err := r.client.Client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return errors.Wrap(err, "fail to get user token hashes from redis")
+	}
+
+	keys := make([]string, 0, len(tokenHashes)+1)
+	for _, hash := range tokenHashes {
+		keys = append(keys, redisRefreshTokenKey(hash))
+	}
+	keys = append(keys, userKey)
+
+	if err := r.client.Client.Del(ctx, keys...).Err(); err != nil {
+		return errors.Wrap(err, "fail to delete user refresh tokens from redis")
+	}
+	return nil
+}
+
+// DeleteOtherTokensByUserID removes all refresh tokens for the given user but the one with keepTokenHash.
+func (r *AuthRefreshTokenRepositoryRedisImpl) DeleteOtherTokensByUserID(ctx context.Context, userID entity.UserIDEntity, keepTokenHash string) error {
+	if keepTokenHash == "" {
+		return r.DeleteAllTokensByUserID(ctx, userID)
+	}
+
+	userKey := redisRefreshTokenUserKey(userID)
+	tokenHashes, err := r.client.Client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return errors.Wrap(err, "fail to get user token hashes from redis")
+	}
+
+	var otherHashes []any
+	var keys []string
+	for _, hash := range tokenHashes {
+		if hash == keepTokenHash {
+			continue
+		}
+		otherHashes = append(otherHashes, hash)
+		keys = append(keys, redisRefreshTokenKey(hash))
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err = r.client.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+		pipe.SRem(ctx, userKey, otherHashes...)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "fail to delete user refresh tokens from redis")
+	}
+	return nil
+}
+
+// ListActiveSessionsByUserID returns the refresh tokens in the user's set that redis did not expire yet.
+func (r *AuthRefreshTokenRepositoryRedisImpl) ListActiveSessionsByUserID(ctx context.Context, userID entity.UserIDEntity) ([]entity.RefreshToken, error) {
+	tokenHashes, err := r.client.Client.SMembers(ctx, redisRefreshTokenUserKey(userID)).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get user token hashes from redis")
+	}
+
+	values := make([]*redis.StringCmd, len(tokenHashes))
+	_, err = r.client.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, hash := range tokenHashes {
+			values[i] = pipe.Get(ctx, redisRefreshTokenKey(hash))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, errors.Wrap(err, "fail to get user refresh tokens from redis")
+	}
+
+	sessions := []entity.RefreshToken{}
+	now := r.clock.Now()
+	for i, hash := range tokenHashes {
+		data, err := values[i].Bytes()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			return nil, errors.Wrap(err, "fail to get user refresh token from redis")
+		}
+		var model RefreshTokenModel
+		if err := json.Unmarshal(data, &model); err != nil {
+			return nil, errors.Wrap(err, "fail to unmarshal refresh token")
+		}
+		if model.UserID != string(userID) || now.After(model.ExpireAt) || model.RotatedAt != nil {
+			continue
+		}
+		sessions = append(sessions, model.toEntity(hash))
+	}
+	return sessions, nil
+}
+
+// RotateRefreshToken replaces a valid refresh token with a new one of the same family. The replaced token is
+// watched, a concurrent rotation or delete fails the transaction and the token counts as not valid anymore.
+func (r *AuthRefreshTokenRepositoryRedisImpl) RotateRefreshToken(ctx context.Context, refreshToken entity.RefreshToken) (entity.RefreshToken, bool, error) {
+	now := r.clock.Now()
+	var rotated entity.RefreshToken
+	var ok bool
+
+	oldKey := redisRefreshTokenKey(refreshToken.TokenHash)
+	err := r.client.Client.Watch(ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, oldKey).Bytes()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				return nil
+			}
+			return err
+		}
+		var model RefreshTokenModel
+		if err := json.Unmarshal(data, &model); err != nil {
+			return errors.Wrap(err, "fail to unmarshal refresh token")
+		}
+		if model.RotatedAt != nil || !now.Before(model.ExpireAt) {
+			return nil
+		}
+
+		// the new token keeps the expiry, rotation does not extend the session
+		ttl := model.ExpireAt.Sub(now) + time.Second
+		rotated = model.rotatedEntity(refreshToken.TokenHash, utils.ToSecond(now))
+		rotatedData, err := json.Marshal(newRefreshTokenModel(rotated))
+		if err != nil {
+			return errors.Wrap(err, "fail to marshal refresh token to json")
+		}
+		model.FamilyID = rotated.FamilyID
+		model.RotatedAt = &now
+		model.LegacyToken = ""
+		oldData, err := json.Marshal(model)
+		if err != nil {
+			return errors.Wrap(err, "fail to marshal refresh token to json")
+		}
+
+		userKey := redisRefreshTokenUserKey(rotated.UserID)
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, redisRefreshTokenKey(rotated.TokenHash), rotatedData, ttl)
+			pipe.SAdd(ctx, userKey, rotated.TokenHash)
+			pipe.Set(ctx, oldKey, oldData, ttl)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		ok = true
+		return nil
+	}, oldKey)
+	if err != nil {
+		if errors.Is(err, redis.TxFailedErr) {
+			return entity.RefreshToken{}, false, nil
+		}
+		return entity.RefreshToken{}, false, errors.Wrap(err, "fail to rotate refresh token in redis")
+	}
+	return rotated, ok, nil
+}
+
+// FindRotatedRefreshToken looks up a token that was replaced by a rotation and did not expire yet.
+func (r *AuthRefreshTokenRepositoryRedisImpl) FindRotatedRefreshToken(ctx context.Context, token string) (entity.RefreshToken, bool, error) {
+	tokenHash := utils.Sha256String(token)
+	model, exists, err := r.getModel(ctx, tokenHash)
+	if err != nil {
+		return entity.RefreshToken{}, false, errors.Wrap(err, "fail to retrieve refresh token from redis")
+	}
+	if !exists || model.RotatedAt == nil || r.clock.Now().After(model.ExpireAt) {
+		return entity.RefreshToken{}, false, nil
+	}
+	return model.toEntity(tokenHash), true, nil
+}
+
+// DeleteTokenFamily removes the tokens of the user's set that belong to the family.
+func (r *AuthRefreshTokenRepositoryRedisImpl) DeleteTokenFamily(ctx context.Context, userID entity.UserIDEntity, familyID string) ([]string, error) {
+	userKey := redisRefreshTokenUserKey(userID)
+	tokenHashes, err := r.client.Client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get user token hashes from redis")
+	}
+
+	var deleted []string
+	var familyHashes []any
+	var keys []string
+	for _, hash := range tokenHashes {
+		model, exists, err := r.getModel(ctx, hash)
+		if err != nil {
+			return nil, errors.Wrap(err, "fail to get user refresh token from redis")
+		}
+		if !exists || model.toEntity(hash).FamilyID != familyID {
+			continue
+		}
+		deleted = append(deleted, hash)
+		familyHashes = append(familyHashes, hash)
+		keys = append(keys, redisRefreshTokenKey(hash))
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	_, err = r.client.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+		pipe.SRem(ctx, userKey, familyHashes...)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to delete refresh token family from redis")
+	}
+	return deleted, nil
+}
+
+// CleanupExpiredTokenHashesForUser removes the hashes of tokens redis already expired from the user's set.
+func (r *AuthRefreshTokenRepositoryRedisImpl) CleanupExpiredTokenHashesForUser(ctx context.Context, userID entity.UserIDEntity) error {
+	userKey := redisRefreshTokenUserKey(userID)
+	tokenHashes, err := r.client.Client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return errors.Wrap(err, "fail to get user token hashes from redis")
+	}
+	if len(tokenHashes) == 0 {
+		return nil
+	}
+
+	exists := make([]*redis.IntCmd, len(tokenHashes))
+	_, err = r.client.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, hash := range tokenHashes {
+			exists[i] = pipe.Exists(ctx, redisRefreshTokenKey(hash))
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "fail to check token hashes in redis")
+	}
+
+	var staleHashes []any
+	for i, hash := range tokenHashes {
+		if exists[i].Val() == 0 {
+			staleHashes = append(staleHashes, hash)
+		}
+	}
+	if len(staleHashes) == 0 {
+		return nil
+	}
+
+	if err := r.client.Client.SRem(ctx, userKey, staleHashes...).Err(); err != nil {
+		return errors.Wrap(err, "fail to remove stale token hashes from redis")
+	}
+	return nil
+}
+
+func (r *AuthRefreshTokenRepositoryRedisImpl) getModel(ctx context.Context, tokenHash string) (RefreshTokenModel, bool, error) {
+	data, err := r.client.Client.Get(ctx, redisRefreshTokenKey(tokenHash)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return RefreshTokenModel{}, false, nil
+		}
+		return RefreshTokenModel{}, false, err
+	}
+
+	var model RefreshTokenModel
+	if err := json.Unmarshal(data, &model); err != nil {
+		return RefreshTokenModel{}, false, errors.Wrap(err, "fail to unmarshal refresh token")
+	}
+	return model, true, nil
+}
+
+// rewriteLegacyToken drops the raw token an older version stored with the entry, keeping its TTL.
+// The write only applies to an existing key, so a token deleted meanwhile is not written back.
+func (r *AuthRefreshTokenRepositoryRedisImpl) rewriteLegacyToken(ctx context.Context, tokenHash string) {
+	key := redisRefreshTokenKey(tokenHash)
+	val, err := r.client.Client.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.Warnf(ctx, "fail to rewrite legacy refresh token %s: %v", tokenHash, err)
+		}
+		return
+	}
+	data, ok, err := withoutLegacyToken(val)
+	if err == nil && ok {
+		err = r.client.Client.SetArgs(ctx, key, data, redis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+	}
+	if err != nil && !errors.Is(err, redis.Nil) {
+		logger.Warnf(ctx, "fail to rewrite legacy refresh token %s: %v", tokenHash, err)
+	}
+}
@@ -0,0 +1,333 @@
+package repository_impl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/infra/repository_impl/client"
+	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthRefreshTokenRepositoryRedisImpl_IssueAndValidate(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		authTokenRepo := NewAuthRefreshTokenRepositoryRedisImpl(unitTestCtx.Config, redisClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userID := entity.UserIDEntity("u-test-user-123")
+		token, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+		assert.Equal(t, userID, token.UserID)
+		assert.True(t, token.Token[:3] == "rt-") // token should start with "rt-"
+		assert.Equal(t, utils.Sha256String(token.Token), token.TokenHash)
+
+		// the token expires with the refresh token TTL
+		ttl := redisClient.Client.TTL(ctx, redisRefreshTokenKey(token.TokenHash)).Val()
+		assert.Greater(t, ttl, time.Duration(0))
+		assert.LessOrEqual(t, ttl, utils.TTLInSecondToTimeDuration(unitTestCtx.Config.RefreshTokenTTL))
+
+		refreshToken, valid, err := authTokenRepo.ValidateRefreshToken(ctx, token.Token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+		assert.Equal(t, token.Token, refreshToken.Token)
+
+		refreshTokenByHash, valid, err := authTokenRepo.ValidateRefreshTokenHash(ctx, token.TokenHash)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+		// the raw token is not stored, a lookup by hash cannot return it
+		assert.Empty(t, refreshTokenByHash.Token)
+		assert.Equal(t, entity.NewRefreshTokenFromHash(userID, refreshToken.TokenHash, refreshToken.IssueAt, refreshToken.ExpireAt), refreshTokenByHash)
+
+		// Test validating non-existent token
+		_, valid, err = authTokenRepo.ValidateRefreshToken(ctx, "rt-non-existent-token")
+		assert.Nil(t, err)
+		assert.False(t, valid)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryRedisImpl_TokenExpiration(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		clock := fixtures.NewFakeClock(time.Now())
+		authTokenRepo := NewAuthRefreshTokenRepositoryRedisImpl(unitTestCtx.Config, redisClient, clock, fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		token, err := authTokenRepo.IssueRefreshToken(ctx, entity.UserIDEntity("u-test-user-expire"))
+		assert.Nil(t, err)
+
+		// the stored expiry is authoritative even before redis drops the key
+		clock.Advance(utils.TTLInSecondToTimeDuration(unitTestCtx.Config.RefreshTokenTTL) + time.Second)
+		_, valid, err := authTokenRepo.ValidateRefreshToken(ctx, token.Token)
+		assert.Nil(t, err)
+		assert.False(t, valid)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryRedisImpl_DeleteRefreshToken(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		authTokenRepo := NewAuthRefreshTokenRepositoryRedisImpl(unitTestCtx.Config, redisClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userID := entity.UserIDEntity("u-test-user-789")
+		token, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+
+		err = authTokenRepo.DeleteRefreshToken(ctx, token.Token)
+		assert.Nil(t, err)
+
+		_, valid, err := authTokenRepo.ValidateRefreshToken(ctx, token.Token)
+		assert.Nil(t, err)
+		assert.False(t, valid)
+		assert.False(t, redisClient.Client.SIsMember(ctx, redisRefreshTokenUserKey(userID), token.TokenHash).Val())
+
+		// Test deleting non-existent token (should not error)
+		err = authTokenRepo.DeleteRefreshToken(ctx, "rt-non-existent-token")
+		assert.Nil(t, err)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryRedisImpl_DeleteAllTokensByUserID(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		authTokenRepo := NewAuthRefreshTokenRepositoryRedisImpl(unitTestCtx.Config, redisClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userTokens := make(map[int][]entity.RefreshToken)
+		for userIdx := 0; userIdx < 3; userIdx++ {
+			userID := entity.UserIDEntity(fmt.Sprintf("u-test-user-delete-all-%d", userIdx))
+			for tokenIdx := 0; tokenIdx < 3; tokenIdx++ {
+				token, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+				assert.Nil(t, err)
+				userTokens[userIdx] = append(userTokens[userIdx], token)
+			}
+		}
+
+		err := authTokenRepo.DeleteAllTokensByUserID(ctx, entity.UserIDEntity("u-test-user-delete-all-1"))
+		assert.Nil(t, err)
+
+		for userIdx, tokens := range userTokens {
+			for _, token := range tokens {
+				_, valid, err := authTokenRepo.ValidateRefreshToken(ctx, token.Token)
+				assert.Nil(t, err)
+				assert.Equal(t, userIdx != 1, valid, "token of user %d", userIdx)
+			}
+		}
+
+		// Delete tokens for a user that has no tokens (should not error)
+		err = authTokenRepo.DeleteAllTokensByUserID(ctx, entity.UserIDEntity("u-test-user-no-tokens"))
+		assert.Nil(t, err)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryRedisImpl_DeleteOtherTokensByUserID(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		authTokenRepo := NewAuthRefreshTokenRepositoryRedisImpl(unitTestCtx.Config, redisClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userID := entity.UserIDEntity("u-test-user-delete-other")
+		var tokens []entity.RefreshToken
+		for i := 0; i < 3; i++ {
+			token, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+			assert.Nil(t, err)
+			tokens = append(tokens, token)
+		}
+		otherUserToken, err := authTokenRepo.IssueRefreshToken(ctx, entity.UserIDEntity("u-test-user-delete-other-another"))
+		assert.Nil(t, err)
+
+		err = authTokenRepo.DeleteOtherTokensByUserID(ctx, userID, tokens[0].TokenHash)
+		assert.Nil(t, err)
+
+		// only the kept token of the user is still valid
+		for i, token := range tokens {
+			_, valid, err := authTokenRepo.ValidateRefreshToken(ctx, token.Token)
+			assert.Nil(t, err)
+			assert.Equal(t, i == 0, valid, "token %d", i)
+		}
+		_, valid, err := authTokenRepo.ValidateRefreshToken(ctx, otherUserToken.Token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+
+		// the kept token is still known as a token of the user
+		err = authTokenRepo.DeleteAllTokensByUserID(ctx, userID)
+		assert.Nil(t, err)
+		_, valid, err = authTokenRepo.ValidateRefreshToken(ctx, tokens[0].Token)
+		assert.Nil(t, err)
+		assert.False(t, valid)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryRedisImpl_ListActiveSessionsByUserID(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		clock := fixtures.NewFakeClock(time.Now())
+		authTokenRepo := NewAuthRefreshTokenRepositoryRedisImpl(unitTestCtx.Config, redisClient, clock, fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userID := entity.UserIDEntity("u-test-user-sessions")
+		sessions, err := authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.Empty(t, sessions)
+
+		first, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+		second, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+		_, err = authTokenRepo.IssueRefreshToken(ctx, entity.UserIDEntity("u-test-user-sessions-another"))
+		assert.Nil(t, err)
+
+		sessions, err = authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []entity.RefreshToken{
+			entity.NewRefreshTokenFromHash(userID, first.TokenHash, first.IssueAt, first.ExpireAt),
+			entity.NewRefreshTokenFromHash(userID, second.TokenHash, second.IssueAt, second.ExpireAt),
+		}, sessions)
+
+		// deleted and expired tokens are no sessions anymore
+		assert.Nil(t, authTokenRepo.DeleteRefreshTokenByHash(ctx, first.TokenHash))
+		sessions, err = authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.Len(t, sessions, 1)
+		assert.Equal(t, second.TokenHash, sessions[0].TokenHash)
+
+		clock.Advance(utils.TTLInSecondToTimeDuration(unitTestCtx.Config.RefreshTokenTTL) + time.Second)
+		sessions, err = authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.Empty(t, sessions)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryRedisImpl_RotateRefreshToken(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		clock := fixtures.NewFakeClock(time.Now())
+		authTokenRepo := NewAuthRefreshTokenRepositoryRedisImpl(unitTestCtx.Config, redisClient, clock, fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userID := entity.UserIDEntity("u-test-user-rotate")
+		first, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+		other, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+
+		clock.Advance(time.Minute)
+		second, ok, err := authTokenRepo.RotateRefreshToken(ctx, first)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.NotEqual(t, first.Token, second.Token)
+		assert.Equal(t, first.TokenHash, second.FamilyID)
+		// rotation does not extend the session
+		assert.Equal(t, first.ExpireAt, second.ExpireAt)
+
+		// the replaced token is not valid anymore but can be found as rotated
+		_, valid, err := authTokenRepo.ValidateRefreshToken(ctx, first.Token)
+		assert.Nil(t, err)
+		assert.False(t, valid)
+		rotated, found, err := authTokenRepo.FindRotatedRefreshToken(ctx, first.Token)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, first.FamilyID, rotated.FamilyID)
+		_, found, err = authTokenRepo.FindRotatedRefreshToken(ctx, second.Token)
+		assert.Nil(t, err)
+		assert.False(t, found)
+
+		validated, valid, err := authTokenRepo.ValidateRefreshToken(ctx, second.Token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+		assert.Equal(t, second.FamilyID, validated.FamilyID)
+
+		// a token is rotated only once
+		_, ok, err = authTokenRepo.RotateRefreshToken(ctx, first)
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		third, ok, err := authTokenRepo.RotateRefreshToken(ctx, validated)
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, first.TokenHash, third.FamilyID)
+
+		sessions, err := authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{other.TokenHash, third.TokenHash}, []string{sessions[0].TokenHash, sessions[1].TokenHash})
+
+		// deleting the family keeps the tokens of other logins
+		deleted, err := authTokenRepo.DeleteTokenFamily(ctx, userID, first.TokenHash)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{first.TokenHash, second.TokenHash, third.TokenHash}, deleted)
+		_, found, err = authTokenRepo.FindRotatedRefreshToken(ctx, first.Token)
+		assert.Nil(t, err)
+		assert.False(t, found)
+		sessions, err = authTokenRepo.ListActiveSessionsByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.Len(t, sessions, 1)
+		assert.Equal(t, other.TokenHash, sessions[0].TokenHash)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryRedisImpl_CleanupExpiredTokenHashesForUser(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		authTokenRepo := NewAuthRefreshTokenRepositoryRedisImpl(unitTestCtx.Config, redisClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		userID := entity.UserIDEntity("u-test-user-cleanup")
+		kept, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+		expired, err := authTokenRepo.IssueRefreshToken(ctx, userID)
+		assert.Nil(t, err)
+
+		// simulate redis expiring one of the tokens
+		assert.Nil(t, redisClient.Client.Del(ctx, redisRefreshTokenKey(expired.TokenHash)).Err())
+
+		err = authTokenRepo.CleanupExpiredTokenHashesForUser(ctx, userID)
+		assert.Nil(t, err)
+
+		members := redisClient.Client.SMembers(ctx, redisRefreshTokenUserKey(userID)).Val()
+		assert.Equal(t, []string{kept.TokenHash}, members)
+	})
+}
+
+func TestAuthRefreshTokenRepositoryRedisImpl_RewritesLegacyToken(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		authTokenRepo := NewAuthRefreshTokenRepositoryRedisImpl(unitTestCtx.Config, redisClient, client.NewSystemClock(), fixtures.NewConfigSessionTTLs(unitTestCtx.Config))
+
+		// newly issued tokens are stored by hash only
+		issued, err := authTokenRepo.IssueRefreshToken(ctx, entity.UserIDEntity("u-test-user-hash-only"))
+		assert.Nil(t, err)
+		assert.NotContains(t, redisClient.Client.Get(ctx, redisRefreshTokenKey(issued.TokenHash)).Val(), issued.Token)
+
+		// an entry written by an older version still holds the raw token
+		userID := entity.UserIDEntity("u-test-user-legacy")
+		token := "rt-test-token-legacy"
+		tokenHash := utils.Sha256String(token)
+		now := time.Now().UTC()
+		data := fmt.Sprintf(`{"user_id":%q,"token":%q,"token_hash":%q,"issue_at":%q,"expire_at":%q}`,
+			userID, token, tokenHash, now.Format(time.RFC3339), now.Add(time.Hour).Format(time.RFC3339))
+		assert.Nil(t, redisClient.Client.Set(ctx, redisRefreshTokenKey(tokenHash), data, time.Hour).Err())
+
+		refreshToken, valid, err := authTokenRepo.ValidateRefreshToken(ctx, token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+		assert.Equal(t, userID, refreshToken.UserID)
+		assert.Equal(t, token, refreshToken.Token)
+
+		// the lookup rewrote the entry without the raw token and kept its TTL
+		assert.NotContains(t, redisClient.Client.Get(ctx, redisRefreshTokenKey(tokenHash)).Val(), token)
+		ttl := redisClient.Client.TTL(ctx, redisRefreshTokenKey(tokenHash)).Val()
+		assert.Greater(t, ttl, time.Duration(0))
+		assert.LessOrEqual(t, ttl, time.Hour)
+
+		_, valid, err = authTokenRepo.ValidateRefreshToken(ctx, token)
+		assert.Nil(t, err)
+		assert.True(t, valid)
+	})
+}
@@ -0,0 +1,393 @@
+package repository_impl
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	backupManifestName   = "manifest.json"
+	backupRestoreMarker  = "restore.pending"
+	backupStagingPattern = ".staging-*"
+	backupRestorePattern = ".restore-*"
+	// backupBeforeRestoreSuffix marks the stores a restore replaced, the previous ones are overwritten
+	backupBeforeRestoreSuffix = ".before-restore"
+)
+
+// backupNamePattern also keeps the names from leaving the backup dir
+var backupNamePattern = regexp.MustCompile(`^toolbake-backup-[0-9]{8}T[0-9]{6}Z\.tar\.gz$`)
+
+type backupManifest struct {
+	CreatedAt  time.Time `json:"created_at"`
+	Components []string  `json:"components"`
+}
+
+func NewBackupRepositoryLocalImpl(config config.Config, clock domain_client.IClock) *BackupRepositoryLocalImpl {
+	return &BackupRepositoryLocalImpl{config: config, clock: clock}
+}
+
+// BackupRepositoryLocalImpl keeps the backups as tar.gz archives in BACKUP_DIR. An archive starts with a manifest,
+// followed by one directory per component.
+type BackupRepositoryLocalImpl struct {
+	config config.Config
+	clock  domain_client.IClock
+}
+
+func (r *BackupRepositoryLocalImpl) Create(ctx context.Context, name string, fill func(dir string) ([]string, error)) (entity.BackupEntity, error) {
+	if !backupNamePattern.MatchString(name) {
+		return entity.BackupEntity{}, errors.Errorf("invalid backup name %q", name)
+	}
+	if err := os.MkdirAll(r.config.BackupDir, 0700); err != nil {
+		return entity.BackupEntity{}, errors.Wrapf(err, "failed to create backup dir %s", r.config.BackupDir)
+	}
+	staging, err := os.MkdirTemp(r.config.BackupDir, backupStagingPattern)
+	if err != nil {
+		return entity.BackupEntity{}, errors.Wrap(err, "failed to create backup staging dir")
+	}
+	defer os.RemoveAll(staging)
+
+	components, err := fill(staging)
+	if err != nil {
+		return entity.BackupEntity{}, err
+	}
+	manifest := backupManifest{CreatedAt: r.clock.Now().UTC(), Components: components}
+
+	path := filepath.Join(r.config.BackupDir, name)
+	if _, err := os.Stat(path); err == nil {
+		return entity.BackupEntity{}, errors.Errorf("backup %s already exists", name)
+	}
+	// the archive only gets its name once complete, a crash leaves a .tmp file behind, never a truncated backup
+	tmpPath := path + ".tmp"
+	if err := writeBackupArchive(tmpPath, staging, manifest); err != nil {
+		os.Remove(tmpPath)
+		return entity.BackupEntity{}, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return entity.BackupEntity{}, errors.Wrapf(err, "failed to name backup %s", name)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return entity.BackupEntity{}, errors.Wrapf(err, "failed to stat backup %s", name)
+	}
+	return entity.BackupEntity{Name: name, Size: info.Size(), CreatedAt: manifest.CreatedAt, Components: components}, nil
+}
+
+func (r *BackupRepositoryLocalImpl) List(ctx context.Context) ([]entity.BackupEntity, error) {
+	entries, err := os.ReadDir(r.config.BackupDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []entity.BackupEntity{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read backup dir %s", r.config.BackupDir)
+	}
+
+	backups := []entity.BackupEntity{}
+	for _, dirEntry := range entries {
+		if dirEntry.IsDir() || !backupNamePattern.MatchString(dirEntry.Name()) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		backup := entity.BackupEntity{Name: dirEntry.Name(), Size: info.Size(), CreatedAt: info.ModTime().UTC()}
+		if manifest, err := readBackupManifest(filepath.Join(r.config.BackupDir, dirEntry.Name())); err != nil {
+			logger.Warnf(ctx, "Backup %s has no readable manifest: %v", dirEntry.Name(), err)
+		} else {
+			backup.CreatedAt = manifest.CreatedAt
+			backup.Components = manifest.Components
+		}
+		backups = append(backups, backup)
+	}
+	sort.SliceStable(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+func (r *BackupRepositoryLocalImpl) Delete(ctx context.Context, name string) (bool, error) {
+	if !backupNamePattern.MatchString(name) {
+		return false, nil
+	}
+	err := os.Remove(filepath.Join(r.config.BackupDir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to delete backup %s", name)
+	}
+	return true, nil
+}
+
+func (r *BackupRepositoryLocalImpl) StageRestore(ctx context.Context, name string) (bool, error) {
+	if !backupNamePattern.MatchString(name) {
+		return false, nil
+	}
+	// a backup that can not be read now would fail the next start
+	if _, err := readBackupManifest(filepath.Join(r.config.BackupDir, name)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "backup %s can not be read", name)
+	}
+	if err := os.WriteFile(filepath.Join(r.config.BackupDir, backupRestoreMarker), []byte(name), 0600); err != nil {
+		return false, errors.Wrap(err, "failed to stage restore")
+	}
+	return true, nil
+}
+
+func (r *BackupRepositoryLocalImpl) CancelRestore(ctx context.Context) (bool, error) {
+	err := os.Remove(filepath.Join(r.config.BackupDir, backupRestoreMarker))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "failed to cancel restore")
+	}
+	return true, nil
+}
+
+func (r *BackupRepositoryLocalImpl) PendingRestore(ctx context.Context) (string, bool, error) {
+	content, err := os.ReadFile(filepath.Join(r.config.BackupDir, backupRestoreMarker))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to read staged restore")
+	}
+	return strings.TrimSpace(string(content)), true, nil
+}
+
+func (r *BackupRepositoryLocalImpl) ApplyPendingRestore(ctx context.Context) (string, bool, error) {
+	name, pending, err := r.PendingRestore(ctx)
+	if err != nil || !pending {
+		return "", false, err
+	}
+	if !backupNamePattern.MatchString(name) {
+		return "", false, errors.Errorf("staged restore names an invalid backup %q, remove %s", name, backupRestoreMarker)
+	}
+
+	extracted, err := os.MkdirTemp(r.config.BackupDir, backupRestorePattern)
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to create restore dir")
+	}
+	defer os.RemoveAll(extracted)
+	manifest, err := extractBackupArchive(filepath.Join(r.config.BackupDir, name), extracted)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, component := range manifest.Components {
+		switch {
+		case component == entity.BackupComponentRds && r.config.DBType == "sqlite" && r.config.SqlitePath != "memory":
+			// the wal and shm files belong to the replaced database, sqlite would replay them into the restored one
+			for _, suffix := range []string{"-wal", "-shm"} {
+				walPath := r.config.SqlitePath + suffix
+				if err := os.Rename(walPath, walPath+backupBeforeRestoreSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return "", false, errors.Wrapf(err, "failed to keep %s aside", walPath)
+				}
+			}
+			if err := replaceBackedUpPath(filepath.Join(extracted, component, SqliteBackupFileName), r.config.SqlitePath); err != nil {
+				return "", false, err
+			}
+		case component == entity.BackupComponentKeyValue && r.config.KeyValueDBType == "nutsdb":
+			if err := replaceBackedUpPath(filepath.Join(extracted, component), r.config.NutsDBPath); err != nil {
+				return "", false, err
+			}
+		default:
+			logger.Warnf(ctx, "Backup %s component %s is not restored, this instance does not keep it on local disk", name, component)
+		}
+	}
+
+	if _, err := r.CancelRestore(ctx); err != nil {
+		return "", false, err
+	}
+	return name, true, nil
+}
+
+// replaceBackedUpPath moves the restored file or dir to target, the replaced one is kept next to it
+func replaceBackedUpPath(restored string, target string) error {
+	info, err := os.Stat(restored)
+	if err != nil {
+		return errors.Wrapf(err, "backup misses %s", filepath.Base(restored))
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create %s", filepath.Dir(target))
+	}
+	previous := target + backupBeforeRestoreSuffix
+	if err := os.RemoveAll(previous); err != nil {
+		return errors.Wrapf(err, "failed to remove %s", previous)
+	}
+	if err := os.Rename(target, previous); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return errors.Wrapf(err, "failed to keep %s aside", target)
+	}
+	if err := os.Rename(restored, target); err != nil {
+		// BACKUP_DIR may be on another file system, the copy is as good
+		if info.IsDir() {
+			err = os.CopyFS(target, os.DirFS(restored))
+		} else {
+			err = copyBackedUpFile(restored, target)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to restore %s", target)
+		}
+	}
+	return nil
+}
+
+func copyBackedUpFile(source string, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func writeBackupArchive(path string, dir string, manifest backupManifest) (err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create backup archive %s", path)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = errors.Wrap(closeErr, "failed to close backup archive")
+		}
+	}()
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal backup manifest")
+	}
+	if err := tarWriter.WriteHeader(&tar.Header{Name: backupManifestName, Mode: 0600, Size: int64(len(content)), ModTime: manifest.CreatedAt}); err != nil {
+		return errors.Wrap(err, "failed to write backup manifest")
+	}
+	if _, err := tarWriter.Write(content); err != nil {
+		return errors.Wrap(err, "failed to write backup manifest")
+	}
+	if err := tarWriter.AddFS(os.DirFS(dir)); err != nil {
+		return errors.Wrap(err, "failed to write backup files")
+	}
+	if err := tarWriter.Close(); err != nil {
+		return errors.Wrap(err, "failed to finish backup archive")
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return errors.Wrap(err, "failed to finish backup archive")
+	}
+	return file.Sync()
+}
+
+// readBackupManifest only reads the first entry of the archive, the manifest
+func readBackupManifest(path string) (backupManifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return backupManifest{}, err
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return backupManifest{}, errors.Wrap(err, "not a gzip archive")
+	}
+	tarReader := tar.NewReader(gzipReader)
+	header, err := tarReader.Next()
+	if err != nil {
+		return backupManifest{}, errors.Wrap(err, "not a tar archive")
+	}
+	if header.Name != backupManifestName {
+		return backupManifest{}, errors.Errorf("archive starts with %s instead of the manifest", header.Name)
+	}
+	var manifest backupManifest
+	if err := json.NewDecoder(tarReader).Decode(&manifest); err != nil {
+		return backupManifest{}, errors.Wrap(err, "invalid backup manifest")
+	}
+	return manifest, nil
+}
+
+func extractBackupArchive(path string, dir string) (backupManifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return backupManifest{}, errors.Wrapf(err, "failed to open backup %s", path)
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return backupManifest{}, errors.Wrapf(err, "backup %s is not a gzip archive", path)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	var manifest *backupManifest
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return backupManifest{}, errors.Wrapf(err, "failed to read backup %s", path)
+		}
+		if header.Name == backupManifestName {
+			manifest = &backupManifest{}
+			if err := json.NewDecoder(tarReader).Decode(manifest); err != nil {
+				return backupManifest{}, errors.Wrap(err, "invalid backup manifest")
+			}
+			continue
+		}
+
+		// only plain files and dirs below the extraction dir are written
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if !filepath.IsLocal(name) {
+			return backupManifest{}, errors.Errorf("backup %s holds the unsafe path %q", path, header.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return backupManifest{}, errors.Wrapf(err, "failed to create %s", target)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return backupManifest{}, errors.Wrapf(err, "failed to create %s", filepath.Dir(target))
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+			if err != nil {
+				return backupManifest{}, errors.Wrapf(err, "failed to create %s", target)
+			}
+			_, copyErr := io.Copy(out, tarReader)
+			closeErr := out.Close()
+			if copyErr != nil || closeErr != nil {
+				return backupManifest{}, errors.Errorf("failed to extract %s: %v %v", header.Name, copyErr, closeErr)
+			}
+		default:
+			return backupManifest{}, errors.Errorf("backup %s holds the unsupported entry %q", path, header.Name)
+		}
+	}
+	if manifest == nil {
+		return backupManifest{}, errors.Errorf("backup %s has no manifest", path)
+	}
+	return *manifest, nil
+}
@@ -0,0 +1,138 @@
+package repository_impl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupRepositoryLocalImpl(t *testing.T) {
+	t.Parallel()
+	logger.InitLogger(config.Config{})
+	ctx := context.Background()
+
+	root := t.TempDir()
+	cfg := config.Config{
+		BackupDir:      filepath.Join(root, "backups"),
+		DBType:         "sqlite",
+		SqlitePath:     filepath.Join(root, "data", "sqlite.db"),
+		KeyValueDBType: "nutsdb",
+		NutsDBPath:     filepath.Join(root, "data", "nutsdb"),
+	}
+	clock := fixtures.NewFakeClock(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	repo := NewBackupRepositoryLocalImpl(cfg, clock)
+
+	backups, err := repo.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	fill := func(rds string, keyValue string) func(dir string) ([]string, error) {
+		return func(dir string) ([]string, error) {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, entity.BackupComponentRds), 0700))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, entity.BackupComponentRds, SqliteBackupFileName), []byte(rds), 0600))
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, entity.BackupComponentKeyValue, "bucket"), 0700))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, entity.BackupComponentKeyValue, "bucket", "0.dat"), []byte(keyValue), 0600))
+			return []string{entity.BackupComponentRds, entity.BackupComponentKeyValue}, nil
+		}
+	}
+
+	_, err = repo.Create(ctx, "../escape.tar.gz", fill("", ""))
+	assert.Error(t, err)
+
+	first, err := repo.Create(ctx, "toolbake-backup-20260102T030405Z.tar.gz", fill("old rds", "old kv"))
+	require.NoError(t, err)
+	assert.Positive(t, first.Size)
+	assert.Equal(t, []string{entity.BackupComponentRds, entity.BackupComponentKeyValue}, first.Components)
+
+	clock.Advance(time.Hour)
+	_, err = repo.Create(ctx, "toolbake-backup-20260102T040405Z.tar.gz", fill("new rds", "new kv"))
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, "toolbake-backup-20260102T040405Z.tar.gz", fill("", ""))
+	assert.Error(t, err, "an existing backup is never overwritten")
+
+	// staging dirs and unrelated files are not listed
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.BackupDir, "notes.txt"), []byte("x"), 0600))
+	backups, err = repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, "toolbake-backup-20260102T040405Z.tar.gz", backups[0].Name)
+	assert.True(t, backups[1].CreatedAt.Equal(first.CreatedAt))
+
+	t.Run("stage and cancel a restore", func(t *testing.T) {
+		staged, err := repo.StageRestore(ctx, "toolbake-backup-20000101T000000Z.tar.gz")
+		require.NoError(t, err)
+		assert.False(t, staged)
+		staged, err = repo.StageRestore(ctx, "../notes.txt")
+		require.NoError(t, err)
+		assert.False(t, staged)
+
+		staged, err = repo.StageRestore(ctx, first.Name)
+		require.NoError(t, err)
+		assert.True(t, staged)
+		name, pending, err := repo.PendingRestore(ctx)
+		require.NoError(t, err)
+		assert.True(t, pending)
+		assert.Equal(t, first.Name, name)
+
+		cancelled, err := repo.CancelRestore(ctx)
+		require.NoError(t, err)
+		assert.True(t, cancelled)
+		cancelled, err = repo.CancelRestore(ctx)
+		require.NoError(t, err)
+		assert.False(t, cancelled)
+	})
+
+	t.Run("apply the staged restore and keep the replaced stores", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(cfg.NutsDBPath, 0700))
+		require.NoError(t, os.WriteFile(cfg.SqlitePath, []byte("current rds"), 0600))
+		require.NoError(t, os.WriteFile(cfg.SqlitePath+"-wal", []byte("current wal"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(cfg.NutsDBPath, "0.dat"), []byte("current kv"), 0600))
+
+		_, applied, err := repo.ApplyPendingRestore(ctx)
+		require.NoError(t, err)
+		assert.False(t, applied)
+
+		_, err = repo.StageRestore(ctx, first.Name)
+		require.NoError(t, err)
+		name, applied, err := repo.ApplyPendingRestore(ctx)
+		require.NoError(t, err)
+		assert.True(t, applied)
+		assert.Equal(t, first.Name, name)
+
+		content, err := os.ReadFile(cfg.SqlitePath)
+		require.NoError(t, err)
+		assert.Equal(t, "old rds", string(content))
+		assert.NoFileExists(t, cfg.SqlitePath+"-wal")
+		content, err = os.ReadFile(cfg.SqlitePath + backupBeforeRestoreSuffix)
+		require.NoError(t, err)
+		assert.Equal(t, "current rds", string(content))
+		assert.FileExists(t, cfg.SqlitePath+"-wal"+backupBeforeRestoreSuffix)
+
+		content, err = os.ReadFile(filepath.Join(cfg.NutsDBPath, "bucket", "0.dat"))
+		require.NoError(t, err)
+		assert.Equal(t, "old kv", string(content))
+		assert.FileExists(t, filepath.Join(cfg.NutsDBPath+backupBeforeRestoreSuffix, "0.dat"))
+
+		_, pending, err := repo.PendingRestore(ctx)
+		require.NoError(t, err)
+		assert.False(t, pending)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		deleted, err := repo.Delete(ctx, first.Name)
+		require.NoError(t, err)
+		assert.True(t, deleted)
+		deleted, err = repo.Delete(ctx, first.Name)
+		require.NoError(t, err)
+		assert.False(t, deleted)
+	})
+}
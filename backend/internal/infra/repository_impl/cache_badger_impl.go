@@ -2,6 +2,7 @@ package repository_impl
 
 import (
 	"context"
+	"strconv"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/infra/repository_impl/client"
 	"ya-tool-craft/internal/utils"
@@ -81,6 +82,46 @@ func (c *CacheBadgerImpl) Get(ctx context.Context, key string) (string, bool, er
 	return value, true, nil
 }
 
+// Increment adds one to the counter at key in a transaction, which is retried when a concurrent increment
+// conflicts with it
+func (c *CacheBadgerImpl) Increment(ctx context.Context, key string, ttl uint64) (int64, error) {
+	for {
+		var count int64
+		err := c.client.DB.Update(func(txn *badger.Txn) error {
+			entry := badger.NewEntry([]byte(key), nil)
+			if ttl > 0 {
+				entry = entry.WithTTL(utils.TTLInSecondToTimeDuration(ttl))
+			}
+			item, err := txn.Get([]byte(key))
+			switch {
+			case err == badger.ErrKeyNotFound:
+			case err != nil:
+				return err
+			default:
+				if err := item.Value(func(val []byte) error {
+					count, err = strconv.ParseInt(string(val), 10, 64)
+					return err
+				}); err != nil {
+					return err
+				}
+				// the counter keeps the expiry it got when it was created
+				entry.ExpiresAt = item.ExpiresAt()
+			}
+
+			count++
+			entry.Value = []byte(strconv.FormatInt(count, 10))
+			return txn.SetEntry(entry)
+		})
+		if err == badger.ErrConflict {
+			continue
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, "fail to increment cache counter in badger")
+		}
+		return count, nil
+	}
+}
+
 // Delete removes a key-value pair
 func (c *CacheBadgerImpl) Delete(ctx context.Context, key string) error {
 	err := c.client.DB.Update(func(txn *badger.Txn) error {
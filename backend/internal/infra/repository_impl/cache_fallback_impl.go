@@ -0,0 +1,239 @@
+package repository_impl
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/metrics"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/repository"
+)
+
+func NewCacheFallbackImpl(config config.Config, cache repository.ICache, clock domain_client.IClock) *CacheFallbackImpl {
+	return &CacheFallbackImpl{
+		cache:   cache,
+		clock:   clock,
+		maxKeys: config.CacheFallbackMaxKeys,
+		entries: map[string]cacheFallbackEntry{},
+	}
+}
+
+// CacheFallbackImpl wraps an ICache and keeps it working when its backend fails, so the 2FA and passkey challenges
+// do not fail with it. A failed operation is served by a memory store of at most CACHE_FALLBACK_MAX_KEYS keys, the
+// oldest keys are dropped past it. Once the backend answers again it is used again, the keys written meanwhile are
+// still read from the memory store until they expire, are deleted or are set in the backend.
+type CacheFallbackImpl struct {
+	cache    repository.ICache
+	clock    domain_client.IClock
+	maxKeys  int
+	degraded atomic.Bool
+
+	mu      sync.Mutex
+	entries map[string]cacheFallbackEntry
+	seq     uint64
+}
+
+type cacheFallbackEntry struct {
+	value string
+	// expiresAt is zero for a key without ttl
+	expiresAt time.Time
+	// seq orders the keys by the time they were set, the lowest is dropped first
+	seq uint64
+}
+
+func (e cacheFallbackEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+func (c *CacheFallbackImpl) Set(ctx context.Context, key string, value string) error {
+	return c.SetWithTTL(ctx, key, value, 0)
+}
+
+func (c *CacheFallbackImpl) SetWithTTL(ctx context.Context, key string, value string, ttl uint64) error {
+	var err error
+	if ttl == 0 {
+		err = c.cache.Set(ctx, key, value)
+	} else {
+		err = c.cache.SetWithTTL(ctx, key, value, ttl)
+	}
+	if err != nil {
+		c.fallback(ctx, "set", err)
+		c.memorySet(key, value, ttl)
+		return nil
+	}
+	c.recovered(ctx)
+	// the backend holds the latest value, an older one in memory must not outlive it
+	c.memoryDelete(key)
+	return nil
+}
+
+func (c *CacheFallbackImpl) Get(ctx context.Context, key string) (string, bool, error) {
+	value, exists, err := c.cache.Get(ctx, key)
+	if err != nil {
+		c.fallback(ctx, "get", err)
+		value, exists = c.memoryGet(key)
+		return value, exists, nil
+	}
+	c.recovered(ctx)
+	if exists {
+		return value, true, nil
+	}
+	value, exists = c.memoryGet(key)
+	return value, exists, nil
+}
+
+func (c *CacheFallbackImpl) Increment(ctx context.Context, key string, ttl uint64) (int64, error) {
+	count, err := c.cache.Increment(ctx, key, ttl)
+	if err != nil {
+		c.fallback(ctx, "increment", err)
+		return c.memoryIncrement(key, ttl), nil
+	}
+	c.recovered(ctx)
+	return count, nil
+}
+
+func (c *CacheFallbackImpl) Delete(ctx context.Context, key string) error {
+	c.memoryDelete(key)
+	if err := c.cache.Delete(ctx, key); err != nil {
+		c.fallback(ctx, "delete", err)
+		return nil
+	}
+	c.recovered(ctx)
+	return nil
+}
+
+func (c *CacheFallbackImpl) Has(ctx context.Context, key string) (bool, error) {
+	exists, err := c.cache.Has(ctx, key)
+	if err != nil {
+		c.fallback(ctx, "has", err)
+		_, exists = c.memoryGet(key)
+		return exists, nil
+	}
+	c.recovered(ctx)
+	if exists {
+		return true, nil
+	}
+	_, exists = c.memoryGet(key)
+	return exists, nil
+}
+
+// Backend returns the wrapped cache, whose failures the fallback hides
+func (c *CacheFallbackImpl) Backend() repository.ICache {
+	return c.cache
+}
+
+// fallback counts an operation the memory store serves, the switch to it is logged once
+func (c *CacheFallbackImpl) fallback(ctx context.Context, operation string, err error) {
+	metrics.CacheFallbackOperationsTotal.WithLabelValues(operation).Inc()
+	if c.degraded.CompareAndSwap(false, true) {
+		metrics.CacheDegraded.Set(1)
+		logger.Warnf(ctx, "Cache backend failed, the cache falls back to memory until it answers again: %v", err)
+	}
+}
+
+// recovered logs the backend answering again after a failure
+func (c *CacheFallbackImpl) recovered(ctx context.Context) {
+	if c.degraded.CompareAndSwap(true, false) {
+		metrics.CacheDegraded.Set(0)
+		logger.Warnf(ctx, "Cache backend answers again, the cache no longer falls back to memory")
+	}
+}
+
+func (c *CacheFallbackImpl) memorySet(key string, value string, ttl uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxKeys {
+		c.evict(now)
+	}
+	c.seq++
+	entry := cacheFallbackEntry{value: value, seq: c.seq}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(time.Duration(ttl) * time.Second)
+	}
+	c.entries[key] = entry
+}
+
+// memoryIncrement adds one to the counter at key in the memory store, a counter that is not a number starts over
+func (c *CacheFallbackImpl) memoryIncrement(key string, ttl uint64) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	entry, exists := c.entries[key]
+	if !exists || entry.expired(now) {
+		if !exists && len(c.entries) >= c.maxKeys {
+			c.evict(now)
+		}
+		c.seq++
+		entry = cacheFallbackEntry{value: "0", seq: c.seq}
+		if ttl > 0 {
+			entry.expiresAt = now.Add(time.Duration(ttl) * time.Second)
+		}
+	}
+	count, _ := strconv.ParseInt(entry.value, 10, 64)
+	count++
+	entry.value = strconv.FormatInt(count, 10)
+	c.entries[key] = entry
+	return count
+}
+
+func (c *CacheFallbackImpl) memoryGet(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[key]
+	if !exists {
+		return "", false
+	}
+	if entry.expired(c.clock.Now()) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.value, true
+}
+
+func (c *CacheFallbackImpl) memoryDelete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// PurgeExpired drops the expired keys of the memory store, they are otherwise only dropped when read or when the
+// store is full. The backend expires its keys on its own.
+func (c *CacheFallbackImpl) PurgeExpired(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	purged := 0
+	for key, entry := range c.entries {
+		if entry.expired(now) {
+			delete(c.entries, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// evict drops the expired keys, or the oldest key when none expired. The caller holds mu
+func (c *CacheFallbackImpl) evict(now time.Time) {
+	oldestKey, oldestSeq := "", uint64(0)
+	for key, entry := range c.entries {
+		if entry.expired(now) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.seq < oldestSeq {
+			oldestKey, oldestSeq = key, entry.seq
+		}
+	}
+	if len(c.entries) >= c.maxKeys {
+		delete(c.entries, oldestKey)
+	}
+}
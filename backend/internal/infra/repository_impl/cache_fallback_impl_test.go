@@ -0,0 +1,178 @@
+package repository_impl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingCache is a FakeCache whose every operation fails while down is set
+type failingCache struct {
+	*fixtures.FakeCache
+	down bool
+}
+
+var errCacheDown = errors.New("cache backend is down")
+
+func (c *failingCache) Set(ctx context.Context, key string, value string) error {
+	if c.down {
+		return errCacheDown
+	}
+	return c.FakeCache.Set(ctx, key, value)
+}
+
+func (c *failingCache) SetWithTTL(ctx context.Context, key string, value string, ttl uint64) error {
+	if c.down {
+		return errCacheDown
+	}
+	return c.FakeCache.SetWithTTL(ctx, key, value, ttl)
+}
+
+func (c *failingCache) Get(ctx context.Context, key string) (string, bool, error) {
+	if c.down {
+		return "", false, errCacheDown
+	}
+	return c.FakeCache.Get(ctx, key)
+}
+
+func (c *failingCache) Delete(ctx context.Context, key string) error {
+	if c.down {
+		return errCacheDown
+	}
+	return c.FakeCache.Delete(ctx, key)
+}
+
+func (c *failingCache) Has(ctx context.Context, key string) (bool, error) {
+	if c.down {
+		return false, errCacheDown
+	}
+	return c.FakeCache.Has(ctx, key)
+}
+
+func (c *failingCache) Increment(ctx context.Context, key string, ttl uint64) (int64, error) {
+	if c.down {
+		return 0, errCacheDown
+	}
+	return c.FakeCache.Increment(ctx, key, ttl)
+}
+
+func TestCacheFallbackImpl(t *testing.T) {
+	logger.InitLogger(config.Config{})
+	ctx := context.Background()
+	clock := fixtures.NewFakeClock(time.Unix(1000, 0))
+	backend := &failingCache{FakeCache: fixtures.NewFakeCache(clock)}
+	cache := NewCacheFallbackImpl(config.Config{CacheFallbackMaxKeys: 2}, backend, clock)
+
+	assert.Nil(t, cache.SetWithTTL(ctx, "before", "backend", 60))
+
+	// while the backend is down the keys are kept in memory
+	backend.down = true
+	assert.Nil(t, cache.SetWithTTL(ctx, "challenge", "memory", 60))
+	value, exists, err := cache.Get(ctx, "challenge")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, "memory", value)
+	_, exists, err = cache.Get(ctx, "before")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+	assert.True(t, cache.degraded.Load())
+
+	// the keys written meanwhile are still found once the backend answers again
+	backend.down = false
+	has, err := cache.Has(ctx, "challenge")
+	assert.Nil(t, err)
+	assert.True(t, has)
+	assert.False(t, cache.degraded.Load())
+	value, exists, err = cache.Get(ctx, "before")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, "backend", value)
+
+	assert.Nil(t, cache.Delete(ctx, "challenge"))
+	_, exists, err = cache.Get(ctx, "challenge")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
+	// memory keys expire with their ttl
+	backend.down = true
+	assert.Nil(t, cache.SetWithTTL(ctx, "short", "memory", 10))
+	clock.Advance(10 * time.Second)
+	_, exists, err = cache.Get(ctx, "short")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
+	// past the bound the oldest key is dropped
+	assert.Nil(t, cache.Set(ctx, "first", "1"))
+	assert.Nil(t, cache.Set(ctx, "second", "2"))
+	assert.Nil(t, cache.Set(ctx, "third", "3"))
+	has, err = cache.Has(ctx, "first")
+	assert.Nil(t, err)
+	assert.False(t, has)
+	has, err = cache.Has(ctx, "third")
+	assert.Nil(t, err)
+	assert.True(t, has)
+
+	// a value set in the backend replaces the one kept in memory
+	backend.down = false
+	assert.Nil(t, cache.Set(ctx, "third", "backend"))
+	assert.Nil(t, backend.FakeCache.Delete(ctx, "third"))
+	_, exists, err = cache.Get(ctx, "third")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+}
+
+func TestCacheFallbackImpl_Increment(t *testing.T) {
+	logger.InitLogger(config.Config{})
+	ctx := context.Background()
+	clock := fixtures.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	backend := &failingCache{FakeCache: fixtures.NewFakeCache(clock)}
+	cache := NewCacheFallbackImpl(config.Config{CacheFallbackMaxKeys: 2}, backend, clock)
+
+	count, err := cache.Increment(ctx, "attempts", 60)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// while the backend is down the counter is kept in memory, starting over
+	backend.down = true
+	for want := int64(1); want <= 3; want++ {
+		count, err = cache.Increment(ctx, "attempts", 60)
+		assert.Nil(t, err)
+		assert.Equal(t, want, count)
+	}
+
+	// the memory counter expires with the ttl it got when it was created
+	clock.Advance(61 * time.Second)
+	count, err = cache.Increment(ctx, "attempts", 60)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestCacheFallbackImpl_PurgeExpired(t *testing.T) {
+	logger.InitLogger(config.Config{})
+	ctx := context.Background()
+	clock := fixtures.NewFakeClock(time.Unix(1000, 0))
+	backend := &failingCache{FakeCache: fixtures.NewFakeCache(clock), down: true}
+	cache := NewCacheFallbackImpl(config.Config{CacheFallbackMaxKeys: 10}, backend, clock)
+
+	assert.Nil(t, cache.SetWithTTL(ctx, "totp_verify:expired", "memory", 10))
+	assert.Nil(t, cache.SetWithTTL(ctx, "totp_verify:live", "memory", 60))
+	assert.Nil(t, cache.Set(ctx, "forever", "memory"))
+	clock.Advance(10 * time.Second)
+
+	purged, err := cache.PurgeExpired(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, purged)
+	assert.Len(t, cache.entries, 2)
+	assert.Contains(t, cache.entries, "totp_verify:live")
+	assert.Contains(t, cache.entries, "forever")
+
+	purged, err = cache.PurgeExpired(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, purged)
+}
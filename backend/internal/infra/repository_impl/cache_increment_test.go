@@ -0,0 +1,100 @@
+package repository_impl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/infra/repository_impl/client"
+	"ya-tool-craft/internal/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertCacheIncrementCounts checks that concurrent increments are all counted
+func assertCacheIncrementCounts(t *testing.T, ctx context.Context, cache repository.ICache) {
+	const concurrency = 20
+	// the nutsdb store is kept between runs
+	key := uniqueCacheKey("counter")
+
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for range concurrency {
+		go func() {
+			defer wg.Done()
+			_, err := cache.Increment(ctx, key, 0)
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	count, err := cache.Increment(ctx, key, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(concurrency+1), count)
+}
+
+// assertCacheIncrementWindow checks that a counter keeps the ttl it got when it was created
+func assertCacheIncrementWindow(t *testing.T, ctx context.Context, cache repository.ICache) {
+	key := uniqueCacheKey("window")
+	count, err := cache.Increment(ctx, key, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+	time.Sleep(1 * time.Second)
+	// a later increment does not move the expiry
+	count, err = cache.Increment(ctx, key, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+	time.Sleep(1500 * time.Millisecond)
+
+	count, err = cache.Increment(ctx, key, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func uniqueCacheKey(name string) string {
+	return fmt.Sprintf("%s-%d", name, time.Now().UnixNano())
+}
+
+func TestCacheBadgerImpl_Increment(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearBadger(func(ctx context.Context, badgerClient *client.BadgerClient) {
+		cache := NewCacheBadgerImpl(unitTestCtx.Config, badgerClient)
+		assertCacheIncrementCounts(t, ctx, cache)
+		assertCacheIncrementWindow(t, ctx, cache)
+	})
+}
+
+func TestCacheNutsDBImpl_Increment(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearNutsDB(func(ctx context.Context, nutsDBClient *client.NutsDBClient) {
+		cache := NewCacheNutsDBImpl(unitTestCtx.Config, nutsDBClient)
+		assertCacheIncrementCounts(t, ctx, cache)
+		assertCacheIncrementWindow(t, ctx, cache)
+	})
+}
+
+func TestCacheRedisImpl_Increment(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		cache := NewCacheRedisImpl(unitTestCtx.Config, redisClient)
+		assertCacheIncrementCounts(t, ctx, cache)
+
+		_, err := cache.Increment(ctx, "persistent", 0)
+		assert.Nil(t, err)
+		assert.Equal(t, time.Duration(-1), redisClient.Client.TTL(ctx, "cache:persistent").Val())
+
+		// the ttl is set by the first increment only
+		_, err = cache.Increment(ctx, "window", 60)
+		assert.Nil(t, err)
+		assert.Nil(t, redisClient.Client.Expire(ctx, "cache:window", 30*time.Second).Err())
+		count, err := cache.Increment(ctx, "window", 60)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+		assert.LessOrEqual(t, redisClient.Client.TTL(ctx, "cache:window").Val(), 30*time.Second)
+	})
+}
@@ -0,0 +1,66 @@
+package repository_impl
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/repository"
+)
+
+func NewCacheJitterImpl(config config.Config, cache repository.ICache) *CacheJitterImpl {
+	return &CacheJitterImpl{
+		config:    config,
+		cache:     cache,
+		randFloat: rand.Float64,
+	}
+}
+
+// CacheJitterImpl wraps an ICache and randomly moves every ttl by up to CACHE_TTL_JITTER_PERCENT,
+// so hot keys set by many users at the same time spread their expiry instead of hitting the database together.
+type CacheJitterImpl struct {
+	config config.Config
+	cache  repository.ICache
+	// randFloat returns a number in [0, 1), replaced in tests
+	randFloat func() float64
+}
+
+func (c *CacheJitterImpl) Set(ctx context.Context, key string, value string) error {
+	return c.cache.Set(ctx, key, value)
+}
+
+// SetWithTTL stores a key-value pair with a jittered TTL (time to live in seconds), 0 still means no expiry
+func (c *CacheJitterImpl) SetWithTTL(ctx context.Context, key string, value string, ttl uint64) error {
+	return c.cache.SetWithTTL(ctx, key, value, c.jitter(ttl))
+}
+
+func (c *CacheJitterImpl) Get(ctx context.Context, key string) (string, bool, error) {
+	return c.cache.Get(ctx, key)
+}
+
+// Increment adds one to the counter at key, the ttl of a new counter is jittered like SetWithTTL
+func (c *CacheJitterImpl) Increment(ctx context.Context, key string, ttl uint64) (int64, error) {
+	return c.cache.Increment(ctx, key, c.jitter(ttl))
+}
+
+func (c *CacheJitterImpl) Delete(ctx context.Context, key string) error {
+	return c.cache.Delete(ctx, key)
+}
+
+func (c *CacheJitterImpl) Has(ctx context.Context, key string) (bool, error) {
+	return c.cache.Has(ctx, key)
+}
+
+// jitter returns ttl moved by a random amount within ±CacheTTLJitterPercent, never below 1 second
+func (c *CacheJitterImpl) jitter(ttl uint64) uint64 {
+	if ttl == 0 || c.config.CacheTTLJitterPercent <= 0 {
+		return ttl
+	}
+
+	maxDelta := float64(ttl) * float64(c.config.CacheTTLJitterPercent) / 100
+	jittered := math.Round(float64(ttl) + maxDelta*(2*c.randFloat()-1))
+	if jittered < 1 {
+		return 1
+	}
+	return uint64(jittered)
+}
@@ -0,0 +1,59 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheJitterImpl_SetWithTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		percent int
+		ttl     uint64
+		random  float64
+		wantTTL uint64
+	}{
+		{name: "lowest random shortens by percent", percent: 20, ttl: 100, random: 0, wantTTL: 80},
+		{name: "middle random keeps ttl", percent: 20, ttl: 100, random: 0.5, wantTTL: 100},
+		{name: "highest random extends by percent", percent: 20, ttl: 100, random: 0.9999, wantTTL: 120},
+		{name: "zero percent disables jitter", percent: 0, ttl: 100, random: 0, wantTTL: 100},
+		{name: "no expiry stays no expiry", percent: 20, ttl: 0, random: 0, wantTTL: 0},
+		{name: "never below one second", percent: 50, ttl: 1, random: 0, wantTTL: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clock := fixtures.NewFakeClock(time.Unix(1000, 0))
+			fakeCache := fixtures.NewFakeCache(clock)
+			cache := NewCacheJitterImpl(config.Config{CacheTTLJitterPercent: tt.percent}, fakeCache)
+			cache.randFloat = func() float64 { return tt.random }
+
+			assert.Nil(t, cache.SetWithTTL(ctx, "key", "value", tt.ttl))
+
+			if tt.wantTTL == 0 {
+				clock.Advance(time.Hour)
+				_, exists, err := cache.Get(ctx, "key")
+				assert.Nil(t, err)
+				assert.True(t, exists)
+				return
+			}
+
+			// alive one second before the jittered ttl, gone at it
+			clock.Advance(time.Duration(tt.wantTTL-1) * time.Second)
+			_, exists, err := cache.Get(ctx, "key")
+			assert.Nil(t, err)
+			assert.True(t, exists)
+
+			clock.Advance(time.Second)
+			_, exists, err = cache.Get(ctx, "key")
+			assert.Nil(t, err)
+			assert.False(t, exists)
+		})
+	}
+}
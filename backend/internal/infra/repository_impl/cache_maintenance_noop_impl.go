@@ -0,0 +1,14 @@
+package repository_impl
+
+import "context"
+
+func NewCacheMaintenanceNoopImpl() *CacheMaintenanceNoopImpl {
+	return &CacheMaintenanceNoopImpl{}
+}
+
+// CacheMaintenanceNoopImpl serves caches without a memory fallback, nutsdb and redis expire the keys on their own
+type CacheMaintenanceNoopImpl struct{}
+
+func (m *CacheMaintenanceNoopImpl) PurgeExpired(ctx context.Context) (int, error) {
+	return 0, nil
+}
@@ -2,6 +2,7 @@ package repository_impl
 
 import (
 	"context"
+	"strconv"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/infra/repository_impl/client"
 
@@ -81,6 +82,41 @@ func (c *CacheNutsDBImpl) Get(ctx context.Context, key string) (string, bool, er
 	return value, true, nil
 }
 
+// Increment adds one to the counter at key, nutsdb runs one update transaction at a time
+func (c *CacheNutsDBImpl) Increment(ctx context.Context, key string, ttl uint64) (int64, error) {
+	var count int64
+	err := c.client.DB.Update(func(tx *nutsdb.Tx) error {
+		expiry := uint32(ttl)
+		val, err := tx.Get(nutsdbCacheBucket, []byte(key))
+		switch {
+		case nutsdb.IsKeyNotFound(err):
+		case err != nil:
+			return err
+		default:
+			if count, err = strconv.ParseInt(string(val), 10, 64); err != nil {
+				return err
+			}
+			// the counter keeps the expiry it got when it was created
+			remaining, err := tx.GetTTL(nutsdbCacheBucket, []byte(key))
+			if err != nil {
+				return err
+			}
+			if remaining < 0 {
+				expiry = nutsdb.Persistent
+			} else {
+				expiry = uint32(max(remaining, 1))
+			}
+		}
+
+		count++
+		return tx.Put(nutsdbCacheBucket, []byte(key), []byte(strconv.FormatInt(count, 10)), expiry)
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "fail to increment cache counter in nutsdb")
+	}
+	return count, nil
+}
+
 // Delete removes a key-value pair
 func (c *CacheNutsDBImpl) Delete(ctx context.Context, key string) error {
 	err := c.client.DB.Update(func(tx *nutsdb.Tx) error {
@@ -0,0 +1,92 @@
+package repository_impl
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/infra/repository_impl/client"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisCacheKeyPrefix keeps cache entries apart from the refresh tokens stored in the same redis database
+const redisCacheKeyPrefix = "cache:"
+
+func NewCacheRedisImpl(config config.Config, client *client.RedisClient) *CacheRedisImpl {
+	return &CacheRedisImpl{
+		config: config,
+		client: client,
+	}
+}
+
+type CacheRedisImpl struct {
+	config config.Config
+	client *client.RedisClient
+}
+
+// Set stores a key-value pair without expiration
+func (c *CacheRedisImpl) Set(ctx context.Context, key string, value string) error {
+	if err := c.client.Client.Set(ctx, redisCacheKeyPrefix+key, value, 0).Err(); err != nil {
+		return errors.Wrap(err, "fail to set cache value in redis")
+	}
+	return nil
+}
+
+// SetWithTTL stores a key-value pair with TTL (time to live in seconds)
+func (c *CacheRedisImpl) SetWithTTL(ctx context.Context, key string, value string, ttl uint64) error {
+	if err := c.client.Client.Set(ctx, redisCacheKeyPrefix+key, value, time.Duration(ttl)*time.Second).Err(); err != nil {
+		return errors.Wrap(err, "fail to set cache value with TTL in redis")
+	}
+	return nil
+}
+
+// Get retrieves a value by key
+// Returns (value, true, nil) if key exists
+// Returns ("", false, nil) if key does not exist
+// Returns ("", false, error) if an error occurred
+func (c *CacheRedisImpl) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := c.client.Client.Get(ctx, redisCacheKeyPrefix+key).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", false, nil
+		}
+		return "", false, errors.Wrap(err, "fail to get cache value from redis")
+	}
+	return value, true, nil
+}
+
+// redisIncrementScript sets the ttl in the same step as the first increment, a key is never left without it
+var redisIncrementScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call("EXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// Increment adds one to the counter at key with INCR, the ttl is set when the key is created
+func (c *CacheRedisImpl) Increment(ctx context.Context, key string, ttl uint64) (int64, error) {
+	count, err := redisIncrementScript.Run(ctx, c.client.Client, []string{redisCacheKeyPrefix + key}, ttl).Int64()
+	if err != nil {
+		return 0, errors.Wrap(err, "fail to increment cache counter in redis")
+	}
+	return count, nil
+}
+
+// Delete removes a key-value pair
+func (c *CacheRedisImpl) Delete(ctx context.Context, key string) error {
+	if err := c.client.Client.Del(ctx, redisCacheKeyPrefix+key).Err(); err != nil {
+		return errors.Wrap(err, "fail to delete cache value from redis")
+	}
+	return nil
+}
+
+// Has checks if a key exists
+func (c *CacheRedisImpl) Has(ctx context.Context, key string) (bool, error) {
+	count, err := c.client.Client.Exists(ctx, redisCacheKeyPrefix+key).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "fail to check cache key existence in redis")
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,83 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/infra/repository_impl/client"
+	"ya-tool-craft/internal/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheRedisImpl_SetAndGet(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		cache := NewCacheRedisImpl(unitTestCtx.Config, redisClient)
+
+		err := cache.Set(ctx, "test-key", "test-value")
+		assert.Nil(t, err)
+
+		value, exists, err := cache.Get(ctx, "test-key")
+		assert.Nil(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "test-value", value)
+
+		// entries are stored under the cache prefix
+		assert.Equal(t, int64(1), redisClient.Client.Exists(ctx, "cache:test-key").Val())
+
+		// Test getting a non-existing value
+		value, exists, err = cache.Get(ctx, "non-existing-key")
+		assert.Nil(t, err)
+		assert.False(t, exists)
+		assert.Equal(t, "", value)
+	})
+}
+
+func TestCacheRedisImpl_SetWithTTL(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		cache := NewCacheRedisImpl(unitTestCtx.Config, redisClient)
+
+		err := cache.SetWithTTL(ctx, "ttl-key", "ttl-value", 60)
+		assert.Nil(t, err)
+
+		value, exists, err := cache.Get(ctx, "ttl-key")
+		assert.Nil(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "ttl-value", value)
+
+		ttl := redisClient.Client.TTL(ctx, "cache:ttl-key").Val()
+		assert.Greater(t, ttl, 50*time.Second)
+		assert.LessOrEqual(t, ttl, 60*time.Second)
+
+		// Set without TTL never expires
+		assert.Nil(t, cache.Set(ctx, "persistent-key", "value"))
+		assert.Equal(t, time.Duration(-1), redisClient.Client.TTL(ctx, "cache:persistent-key").Val())
+	})
+}
+
+func TestCacheRedisImpl_DeleteAndHas(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRedis(func(ctx context.Context, redisClient *client.RedisClient) {
+		cache := NewCacheRedisImpl(unitTestCtx.Config, redisClient)
+
+		assert.Nil(t, cache.Set(ctx, "delete-key", "value"))
+
+		has, err := cache.Has(ctx, "delete-key")
+		assert.Nil(t, err)
+		assert.True(t, has)
+
+		assert.Nil(t, cache.Delete(ctx, "delete-key"))
+
+		has, err = cache.Has(ctx, "delete-key")
+		assert.Nil(t, err)
+		assert.False(t, has)
+
+		// Deleting a missing key is not an error
+		assert.Nil(t, cache.Delete(ctx, "delete-key"))
+	})
+}
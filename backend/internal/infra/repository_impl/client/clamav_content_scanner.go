@@ -0,0 +1,123 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	clamavDialTimeout = 5 * time.Second
+	clamavScanTimeout = 30 * time.Second
+	// clamavChunkSize must stay below clamd StreamMaxLength, clamd reads the stream chunk by chunk
+	clamavChunkSize = 64 * 1024
+)
+
+func NewClamAVContentScanner(config config.Config) (*ClamAVContentScanner, error) {
+	network, address, err := parseClamAVAddress(config.ImportScannerClamAVAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &ClamAVContentScanner{network: network, address: address}, nil
+}
+
+// ClamAVContentScanner streams files to a clamd daemon with the INSTREAM command.
+type ClamAVContentScanner struct {
+	network string
+	address string
+}
+
+// parseClamAVAddress accepts tcp://host:port and unix:///path/to/clamd.sock.
+func parseClamAVAddress(raw string) (string, string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "invalid clamav address %q", raw)
+	}
+	switch u.Scheme {
+	case "tcp":
+		if u.Host == "" {
+			return "", "", errors.Errorf("invalid clamav address %q, host is empty", raw)
+		}
+		return "tcp", u.Host, nil
+	case "unix":
+		if u.Path == "" {
+			return "", "", errors.Errorf("invalid clamav address %q, socket path is empty", raw)
+		}
+		return "unix", u.Path, nil
+	default:
+		return "", "", errors.Errorf("invalid clamav address %q, expected tcp://host:port or unix:///path", raw)
+	}
+}
+
+func (s *ClamAVContentScanner) Scan(ctx context.Context, file entity.ImportFileEntity) (entity.ContentScanResultEntity, error) {
+	dialer := net.Dialer{Timeout: clamavDialTimeout}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return entity.ContentScanResultEntity{}, errors.Wrapf(err, "failed to connect to clamav at %s", s.address)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(clamavScanTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return entity.ContentScanResultEntity{}, errors.Wrap(err, "failed to set clamav deadline")
+	}
+
+	// INSTREAM: chunks prefixed by their 4 byte big endian length, terminated by a zero length chunk
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return entity.ContentScanResultEntity{}, errors.Wrap(err, "failed to send clamav command")
+	}
+	content := file.Content
+	for len(content) > 0 {
+		chunk := content[:min(len(content), clamavChunkSize)]
+		content = content[len(chunk):]
+		if err := writeClamAVChunk(conn, chunk); err != nil {
+			return entity.ContentScanResultEntity{}, err
+		}
+	}
+	if err := writeClamAVChunk(conn, nil); err != nil {
+		return entity.ContentScanResultEntity{}, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil {
+		return entity.ContentScanResultEntity{}, errors.Wrap(err, "failed to read clamav reply")
+	}
+	return parseClamAVReply(strings.TrimRight(reply, "\x00"))
+}
+
+func writeClamAVChunk(conn net.Conn, chunk []byte) error {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(chunk)))
+	if _, err := conn.Write(size[:]); err != nil {
+		return errors.Wrap(err, "failed to send clamav chunk size")
+	}
+	if _, err := conn.Write(chunk); err != nil {
+		return errors.Wrap(err, "failed to send clamav chunk")
+	}
+	return nil
+}
+
+// parseClamAVReply reads replies such as "stream: OK" and "stream: Eicar-Signature FOUND".
+func parseClamAVReply(reply string) (entity.ContentScanResultEntity, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return entity.ContentScanResultEntity{Clean: true}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return entity.ContentScanResultEntity{Signature: strings.TrimSuffix(result, " FOUND")}, nil
+	default:
+		// e.g. "INSTREAM size limit exceeded. ERROR"
+		return entity.ContentScanResultEntity{}, errors.Errorf("unexpected clamav reply: %q", reply)
+	}
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClamd accepts one INSTREAM session per connection, reassembles the stream and answers with reply(stream).
+func fakeClamd(t *testing.T, reply func(stream []byte) string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				command, err := reader.ReadString('\x00')
+				if err != nil || command != "zINSTREAM\x00" {
+					return
+				}
+				var stream bytes.Buffer
+				for {
+					var size uint32
+					if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					if _, err := io.CopyN(&stream, reader, int64(size)); err != nil {
+						return
+					}
+				}
+				conn.Write([]byte(reply(stream.Bytes()) + "\x00"))
+			}(conn)
+		}
+	}()
+	return "tcp://" + listener.Addr().String()
+}
+
+func TestClamAVContentScanner_Scan(t *testing.T) {
+	eicar := []byte(`X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`)
+	address := fakeClamd(t, func(stream []byte) string {
+		if bytes.Contains(stream, []byte("EICAR")) {
+			return "stream: Eicar-Signature FOUND"
+		}
+		if len(stream) == 0 {
+			return "INSTREAM size limit exceeded. ERROR"
+		}
+		return "stream: OK"
+	})
+
+	scanner, err := NewClamAVContentScanner(config.Config{ImportScannerClamAVAddress: address})
+	require.NoError(t, err)
+
+	// larger than one chunk, the content must be reassembled in order
+	large := bytes.Repeat([]byte("console.log(1);\n"), clamavChunkSize/8)
+	result, err := scanner.Scan(context.Background(), entity.ImportFileEntity{Name: "large.js", Content: large})
+	require.NoError(t, err)
+	require.Equal(t, entity.ContentScanResultEntity{Clean: true}, result)
+
+	result, err = scanner.Scan(context.Background(), entity.ImportFileEntity{Name: "eicar.txt", Content: eicar})
+	require.NoError(t, err)
+	require.Equal(t, entity.ContentScanResultEntity{Signature: "Eicar-Signature"}, result)
+
+	_, err = scanner.Scan(context.Background(), entity.ImportFileEntity{Name: "empty.txt"})
+	require.ErrorContains(t, err, "unexpected clamav reply")
+}
+
+func TestParseClamAVAddress(t *testing.T) {
+	network, address, err := parseClamAVAddress("unix:///var/run/clamav/clamd.ctl")
+	require.NoError(t, err)
+	require.Equal(t, "unix", network)
+	require.Equal(t, "/var/run/clamav/clamd.ctl", address)
+
+	network, address, err = parseClamAVAddress("tcp://clamav:3310")
+	require.NoError(t, err)
+	require.Equal(t, "tcp", network)
+	require.Equal(t, "clamav:3310", address)
+
+	_, _, err = parseClamAVAddress("clamav:3310")
+	require.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"os"
+	"sync"
+	"ya-tool-craft/internal/config"
+
+	"github.com/pkg/errors"
+)
+
+func NewFileSIEMSink(config config.Config) (*FileSIEMSink, error) {
+	if config.SIEMFilePath == "" {
+		return nil, errors.New("siem file path is empty, please check SIEM_FILE_PATH in config")
+	}
+	return &FileSIEMSink{path: config.SIEMFilePath}, nil
+}
+
+// FileSIEMSink appends every batch to a file a log shipper of the SIEM tails.
+// The file is opened for each batch, so it can be rotated by moving it away.
+type FileSIEMSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (s *FileSIEMSink) Write(ctx context.Context, batch []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open siem file %s", s.path)
+	}
+	if _, err := file.Write(batch); err != nil {
+		file.Close()
+		return errors.Wrapf(err, "failed to write siem file %s", s.path)
+	}
+	if err := file.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close siem file %s", s.path)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"time"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/domain/entity"
@@ -13,12 +14,15 @@ import (
 
 func NewGithubClient(config config.Config) (*GithubClient, error) {
 	return &GithubClient{
-		config: config,
+		config:     config,
+		apiBaseURL: githubAPIBaseURL,
 	}, nil
 }
 
 type GithubClient struct {
 	config config.Config
+	// apiBaseURL is the base url of the repository and gist api calls
+	apiBaseURL string
 }
 
 type GithubUserInfo struct {
@@ -128,3 +132,18 @@ func (c *GithubClient) GetUserInfo(accessToken string) (entity.GithubUserInfoEnt
 		result.AvatarURL,
 	), nil
 }
+
+// Ping reports the API as reachable when it answers at all, an unauthenticated request is expected to get a 4xx.
+func (c *GithubClient) Ping(ctx context.Context) error {
+	client := resty.New()
+	defer client.Close()
+
+	resp, err := client.R().SetContext(ctx).Get("https://api.github.com")
+	if err != nil {
+		return errors.Wrap(err, "failed to reach github api")
+	}
+	if resp.StatusCode() >= 500 {
+		return errors.Errorf("github api returned status %d", resp.StatusCode())
+	}
+	return nil
+}
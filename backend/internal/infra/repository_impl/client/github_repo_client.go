@@ -0,0 +1,286 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"resty.dev/v3"
+)
+
+const (
+	githubAPIBaseURL = "https://api.github.com"
+	// githubPageSize is the items per page of the repository and gist listings, GitHub allows at most 100
+	githubPageSize = 50
+)
+
+// githubNextPageRegexp finds the page number of the rel="next" link of the Link header GitHub paginates with
+var githubNextPageRegexp = regexp.MustCompile(`<[^>]*[?&]page=(\d+)[^>]*>;\s*rel="next"`)
+
+type githubRepo struct {
+	Name        string  `json:"name"`
+	FullName    string  `json:"full_name"`
+	Description *string `json:"description"`
+	Private     bool    `json:"private"`
+	Owner       struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	DefaultBranch string    `json:"default_branch"`
+	HTMLURL       string    `json:"html_url"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type githubGistFile struct {
+	Filename  string `json:"filename"`
+	Size      int    `json:"size"`
+	Content   string `json:"content"`
+	Truncated bool   `json:"truncated"`
+}
+
+type githubGist struct {
+	ID          string                    `json:"id"`
+	Description *string                   `json:"description"`
+	Public      bool                      `json:"public"`
+	Files       map[string]githubGistFile `json:"files"`
+	HTMLURL     string                    `json:"html_url"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+type githubTree struct {
+	Truncated bool `json:"truncated"`
+	Tree      []struct {
+		Path string `json:"path"`
+		Type string `json:"type"`
+		Size int    `json:"size"`
+	} `json:"tree"`
+}
+
+func (c *GithubClient) ListRepos(ctx context.Context, accessToken string, page int) (entity.GithubPageEntity[entity.GithubRepoEntity], error) {
+	var repos []githubRepo
+	resp, err := c.apiGet(ctx, accessToken, "/user/repos", map[string]string{
+		"sort":     "updated",
+		"per_page": strconv.Itoa(githubPageSize),
+		"page":     strconv.Itoa(max(page, 1)),
+	}, &repos)
+	if err != nil {
+		return entity.GithubPageEntity[entity.GithubRepoEntity]{}, errors.Wrap(err, "fail to list github repositories")
+	}
+
+	items := make([]entity.GithubRepoEntity, 0, len(repos))
+	for _, repo := range repos {
+		items = append(items, entity.GithubRepoEntity{
+			Owner:         repo.Owner.Login,
+			Name:          repo.Name,
+			FullName:      repo.FullName,
+			Description:   stringOrEmpty(repo.Description),
+			Private:       repo.Private,
+			DefaultBranch: repo.DefaultBranch,
+			HTMLURL:       repo.HTMLURL,
+			UpdatedAt:     repo.UpdatedAt,
+		})
+	}
+	return entity.GithubPageEntity[entity.GithubRepoEntity]{Items: items, NextPage: githubNextPage(resp)}, nil
+}
+
+func (c *GithubClient) ListGists(ctx context.Context, accessToken string, page int) (entity.GithubPageEntity[entity.GithubGistEntity], error) {
+	var gists []githubGist
+	resp, err := c.apiGet(ctx, accessToken, "/gists", map[string]string{
+		"per_page": strconv.Itoa(githubPageSize),
+		"page":     strconv.Itoa(max(page, 1)),
+	}, &gists)
+	if err != nil {
+		return entity.GithubPageEntity[entity.GithubGistEntity]{}, errors.Wrap(err, "fail to list github gists")
+	}
+
+	items := make([]entity.GithubGistEntity, 0, len(gists))
+	for _, gist := range gists {
+		files := make([]string, 0, len(gist.Files))
+		for name := range gist.Files {
+			files = append(files, name)
+		}
+		slices.Sort(files)
+		items = append(items, entity.GithubGistEntity{
+			ID:          gist.ID,
+			Description: stringOrEmpty(gist.Description),
+			Public:      gist.Public,
+			Files:       files,
+			HTMLURL:     gist.HTMLURL,
+			UpdatedAt:   gist.UpdatedAt,
+		})
+	}
+	return entity.GithubPageEntity[entity.GithubGistEntity]{Items: items, NextPage: githubNextPage(resp)}, nil
+}
+
+// ListRepoFiles reads the tree of HEAD, which is the default branch, in one request
+func (c *GithubClient) ListRepoFiles(ctx context.Context, accessToken string, owner string, repo string) ([]entity.GithubFileEntity, error) {
+	var tree githubTree
+	path := fmt.Sprintf("/repos/%s/%s/git/trees/HEAD", url.PathEscape(owner), url.PathEscape(repo))
+	if _, err := c.apiGet(ctx, accessToken, path, map[string]string{"recursive": "1"}, &tree); err != nil {
+		return nil, errors.Wrapf(err, "fail to list files of github repository %s/%s", owner, repo)
+	}
+
+	if tree.Truncated {
+		logger.Warnf(ctx, "github tree of %s/%s is truncated, only the first %d entries are listed", owner, repo, len(tree.Tree))
+	}
+
+	files := make([]entity.GithubFileEntity, 0, len(tree.Tree))
+	for _, node := range tree.Tree {
+		if node.Type == "blob" {
+			files = append(files, entity.GithubFileEntity{Path: node.Path, Size: node.Size})
+		}
+	}
+	return files, nil
+}
+
+func (c *GithubClient) GetRepoFile(ctx context.Context, accessToken string, owner string, repo string, path string) ([]byte, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	apiPath := fmt.Sprintf("/repos/%s/%s/contents/%s", url.PathEscape(owner), url.PathEscape(repo), strings.Join(segments, "/"))
+
+	client := resty.New().SetTimeout(10 * time.Second)
+	defer client.Close()
+
+	// the raw media type returns the file itself instead of the base64 json envelope
+	resp, err := client.R().
+		SetContext(ctx).
+		SetHeader("Accept", "application/vnd.github.raw+json").
+		SetHeader("User-Agent", "ya-tool-craft").
+		SetAuthToken(accessToken).
+		SetResponseBodyLimit(int64(c.config.ImportMaxFileSize)).
+		Get(c.apiURL(apiPath))
+	if err != nil {
+		if errors.Is(err, resty.ErrReadExceedsThresholdLimit) {
+			return nil, error_code.NewErrorWithErrorCodef(error_code.ImportFileTooLarge, "file %q exceeds the size limit of %d bytes", path, c.config.ImportMaxFileSize)
+		}
+		return nil, errors.Wrapf(err, "fail to call github contents api for %s", path)
+	}
+	if err := githubResponseError(resp); err != nil {
+		return nil, errors.Wrapf(err, "fail to get file %s of github repository %s/%s", path, owner, repo)
+	}
+	// the body limit stops a download only once it is past the limit, the last read can still go over
+	if len(resp.Bytes()) > c.config.ImportMaxFileSize {
+		return nil, error_code.NewErrorWithErrorCodef(error_code.ImportFileTooLarge, "file %q exceeds the size limit of %d bytes", path, c.config.ImportMaxFileSize)
+	}
+	return resp.Bytes(), nil
+}
+
+func (c *GithubClient) GetGistFiles(ctx context.Context, accessToken string, gistID string) (map[string][]byte, error) {
+	var gist githubGist
+	if _, err := c.apiGet(ctx, accessToken, "/gists/"+url.PathEscape(gistID), nil, &gist); err != nil {
+		return nil, errors.Wrapf(err, "fail to get github gist %s", gistID)
+	}
+
+	files := make(map[string][]byte, len(gist.Files))
+	for name, file := range gist.Files {
+		// GitHub cuts the content of files over 1MB in the gist api
+		if file.Truncated || file.Size > c.config.ImportMaxFileSize {
+			return nil, error_code.NewErrorWithErrorCodef(error_code.ImportFileTooLarge, "file %q exceeds the size limit of %d bytes", name, c.config.ImportMaxFileSize)
+		}
+		files[name] = []byte(file.Content)
+	}
+	return files, nil
+}
+
+func (c *GithubClient) apiURL(path string) string {
+	return c.apiBaseURL + path
+}
+
+// apiGet calls a json endpoint of the GitHub api and decodes the response into result
+func (c *GithubClient) apiGet(ctx context.Context, accessToken string, path string, query map[string]string, result any) (*resty.Response, error) {
+	if accessToken == "" {
+		return nil, error_code.NewErrorWithErrorCodef(error_code.GithubAccountNotAuthorized, "github access token is empty")
+	}
+
+	client := resty.New().SetTimeout(10 * time.Second)
+	defer client.Close()
+
+	resp, err := client.R().
+		SetContext(ctx).
+		SetHeader("Accept", "application/vnd.github+json").
+		SetHeader("User-Agent", "ya-tool-craft").
+		SetAuthToken(accessToken).
+		SetQueryParams(query).
+		SetResult(result).
+		Get(c.apiURL(path))
+	if err != nil {
+		return nil, errors.Wrapf(err, "fail to call github api %s", path)
+	}
+	if err := githubResponseError(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// githubResponseError turns a failed GitHub api response into an error code, nil when the request succeeded.
+// GitHub answers an exhausted rate limit with 403 or 429 and X-RateLimit-Remaining 0, or with Retry-After for the secondary limits.
+func githubResponseError(resp *resty.Response) error {
+	if !resp.IsError() {
+		return nil
+	}
+
+	status := resp.StatusCode()
+	header := resp.Header()
+	if status == http.StatusTooManyRequests || (status == http.StatusForbidden && (header.Get("X-RateLimit-Remaining") == "0" || header.Get("Retry-After") != "")) {
+		resetAt := githubRateLimitReset(header)
+		// the secondary limits send no X-RateLimit-Limit, the limit stays unknown then
+		limit, _ := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+		return error_code.NewErrorWithErrorCodeFAppendExtraData(
+			error_code.GithubRateLimited,
+			entity.RateLimitEntity{Limit: limit, ResetAt: resetAt},
+			"github api rate limit exceeded, try again after %s", resetAt.Format(time.RFC3339),
+		)
+	}
+
+	switch status {
+	case http.StatusUnauthorized:
+		return error_code.NewErrorWithErrorCodef(error_code.GithubAccountNotAuthorized, "github rejected the access token, it was revoked or expired")
+	case http.StatusNotFound:
+		return error_code.NewErrorWithErrorCodef(error_code.GithubRepositoryNotFound, "github resource not found")
+	default:
+		return error_code.NewErrorWithErrorCodef(error_code.GithubUnavailable, "github api request failed with status: %s, body: %s", resp.Status(), resp.String())
+	}
+}
+
+// githubRateLimitReset is when the rate limit allows requests again, by Retry-After or X-RateLimit-Reset in epoch seconds
+func githubRateLimitReset(header http.Header) time.Time {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Now().Add(time.Duration(seconds) * time.Second).UTC()
+	}
+	if epoch, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC()
+	}
+	// GitHub asks to wait at least a minute when it gives no hint
+	return time.Now().Add(time.Minute).UTC()
+}
+
+// githubNextPage is the page the Link header points to as next, 0 on the last page
+func githubNextPage(resp *resty.Response) int {
+	match := githubNextPageRegexp.FindStringSubmatch(resp.Header().Get("Link"))
+	if match == nil {
+		return 0
+	}
+	page, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0
+	}
+	return page
+}
+
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGithubClient(t *testing.T, handler http.HandlerFunc) *GithubClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &GithubClient{config: config.Config{ImportMaxFileSize: 64}, apiBaseURL: server.URL}
+}
+
+func requireGithubErrorCode(t *testing.T, err error, want error_code.ErrorCode) error_code.ErrorWithErrorCode {
+	t.Helper()
+
+	var codeErr error_code.ErrorWithErrorCode
+	require.True(t, errors.As(err, &codeErr), "error has no error code: %v", err)
+	require.Equal(t, want.Code, codeErr.ErrorCode.Code)
+	return codeErr
+}
+
+func TestGithubClient_ListRepos(t *testing.T) {
+	client := newTestGithubClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/user/repos", r.URL.Path)
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/user/repos?per_page=50&page=2>; rel="next", <%s/user/repos?per_page=50&page=3>; rel="last"`, "http://"+r.Host, "http://"+r.Host))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"name":"tools","full_name":"octo/tools","description":null,"private":false,"owner":{"login":"octo"},"default_branch":"main","html_url":"https://github.com/octo/tools","updated_at":"2026-01-02T03:04:05Z"}]`))
+	})
+
+	page, err := client.ListRepos(context.Background(), "token", 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, page.NextPage)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, "octo", page.Items[0].Owner)
+	require.Equal(t, "octo/tools", page.Items[0].FullName)
+	require.Equal(t, "main", page.Items[0].DefaultBranch)
+
+	// the last page has no next link
+	page, err = client.ListRepos(context.Background(), "token", 3)
+	require.NoError(t, err)
+	require.Equal(t, 0, page.NextPage)
+}
+
+func TestGithubClient_ListRepoFiles(t *testing.T) {
+	client := newTestGithubClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/repos/octo/tools/git/trees/HEAD", r.URL.Path)
+		require.Equal(t, "1", r.URL.Query().Get("recursive"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"truncated":false,"tree":[{"path":"tools","type":"tree"},{"path":"tools/hello/handler.js","type":"blob","size":12}]}`))
+	})
+
+	files, err := client.ListRepoFiles(context.Background(), "token", "octo", "tools")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "tools/hello/handler.js", files[0].Path)
+	require.Equal(t, 12, files[0].Size)
+}
+
+func TestGithubClient_GetRepoFile(t *testing.T) {
+	client := newTestGithubClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/vnd.github.raw+json", r.Header.Get("Accept"))
+		switch r.URL.Path {
+		case "/repos/octo/tools/contents/my tools/handler.js":
+			w.Write([]byte("async function handler() {}"))
+		case "/repos/octo/tools/contents/large.js":
+			w.Write([]byte(strings.Repeat("a", 65)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	content, err := client.GetRepoFile(context.Background(), "token", "octo", "tools", "my tools/handler.js")
+	require.NoError(t, err)
+	require.Equal(t, "async function handler() {}", string(content))
+
+	_, err = client.GetRepoFile(context.Background(), "token", "octo", "tools", "large.js")
+	requireGithubErrorCode(t, err, error_code.ImportFileTooLarge)
+
+	_, err = client.GetRepoFile(context.Background(), "token", "octo", "tools", "missing.js")
+	requireGithubErrorCode(t, err, error_code.GithubRepositoryNotFound)
+}
+
+func TestGithubClient_GetGistFiles(t *testing.T) {
+	client := newTestGithubClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/gists/small":
+			w.Write([]byte(`{"id":"small","files":{"handler.js":{"filename":"handler.js","size":5,"content":"hello"}}}`))
+		default:
+			w.Write([]byte(`{"id":"large","files":{"handler.js":{"filename":"handler.js","size":2000000,"content":"cut","truncated":true}}}`))
+		}
+	})
+
+	files, err := client.GetGistFiles(context.Background(), "token", "small")
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"handler.js": []byte("hello")}, files)
+
+	_, err = client.GetGistFiles(context.Background(), "token", "large")
+	requireGithubErrorCode(t, err, error_code.ImportFileTooLarge)
+}
+
+func TestGithubClient_ErrorResponses(t *testing.T) {
+	reset := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		status    int
+		header    map[string]string
+		wantCode  error_code.ErrorCode
+		wantReset *time.Time
+	}{
+		{
+			name:      "exhausted rate limit",
+			status:    http.StatusForbidden,
+			header:    map[string]string{"X-RateLimit-Limit": "60", "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": fmt.Sprint(reset.Unix())},
+			wantCode:  error_code.GithubRateLimited,
+			wantReset: &reset,
+		},
+		{
+			name:     "secondary rate limit",
+			status:   http.StatusTooManyRequests,
+			header:   map[string]string{"Retry-After": "30"},
+			wantCode: error_code.GithubRateLimited,
+		},
+		{
+			name:     "forbidden without rate limit is not rate limited",
+			status:   http.StatusForbidden,
+			header:   map[string]string{"X-RateLimit-Remaining": "10"},
+			wantCode: error_code.GithubUnavailable,
+		},
+		{
+			name:     "revoked token",
+			status:   http.StatusUnauthorized,
+			wantCode: error_code.GithubAccountNotAuthorized,
+		},
+		{
+			name:     "server error",
+			status:   http.StatusBadGateway,
+			wantCode: error_code.GithubUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestGithubClient(t, func(w http.ResponseWriter, r *http.Request) {
+				for key, value := range tt.header {
+					w.Header().Set(key, value)
+				}
+				w.WriteHeader(tt.status)
+			})
+
+			_, err := client.ListGists(context.Background(), "token", 1)
+			codeErr := requireGithubErrorCode(t, err, tt.wantCode)
+			if tt.wantReset != nil {
+				require.Equal(t, entity.RateLimitEntity{Limit: 60, ResetAt: *tt.wantReset}, codeErr.ExtraData)
+			}
+		})
+	}
+
+	client := newTestGithubClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("github api called without a token")
+	})
+	_, err := client.ListRepos(context.Background(), "", 1)
+	requireGithubErrorCode(t, err, error_code.GithubAccountNotAuthorized)
+}
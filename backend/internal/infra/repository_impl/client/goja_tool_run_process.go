@@ -0,0 +1,126 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// toolRunProcessEnv marks the child process a tool runs in, main hands the process over to ServeToolRunProcess
+	toolRunProcessEnv = "TOOLBAKE_TOOL_RUN_PROCESS"
+	// toolRunProcessKillDelay is how long the child may outlive the timeout, it stops the handler itself and only a
+	// child that does not is killed
+	toolRunProcessKillDelay = 2 * time.Second
+	// toolRunProcessMemoryHeadroom is what the child may map besides the memory limit of the handler, for the
+	// runtime, the collector and the result
+	toolRunProcessMemoryHeadroom = 32 << 20
+	// toolRunProcessMaxStderr bounds what is kept of the stderr of a child that failed
+	toolRunProcessMaxStderr = 4096
+)
+
+// toolRunProcessInput is what the parent sends the child on stdin
+type toolRunProcessInput struct {
+	Source          string            `json:"source"`
+	Secrets         map[string]string `json:"secrets"`
+	Inputs          map[string]any    `json:"inputs"`
+	ChangedWidgetID string            `json:"changed_widget_id"`
+	Timeout         time.Duration     `json:"timeout"`
+	MaxPayloadBytes int               `json:"max_payload_bytes"`
+	MaxMemoryBytes  int               `json:"max_memory_bytes"`
+}
+
+// toolRunProcessOutput is what the child sends the parent on stdout, ErrorCode is set when the run failed
+type toolRunProcessOutput struct {
+	Outputs   map[string]any                  `json:"outputs"`
+	Updates   []map[string]any                `json:"updates"`
+	Logs      []entity.ToolExecutionLogEntity `json:"logs"`
+	ErrorCode *error_code.ErrorCode           `json:"error_code"`
+	Message   string                          `json:"message"`
+}
+
+// IsToolRunProcess tells whether this process was started by GojaToolRunner to run a tool.
+func IsToolRunProcess() bool {
+	return os.Getenv(toolRunProcessEnv) == "1"
+}
+
+// ServeToolRunProcess runs the tool the parent sent on stdin and writes the result to stdout. It returns the exit
+// code of the process. The memory limit of the handler is set on the whole process, so an allocation past it fails
+// and the runtime exits with "out of memory".
+func ServeToolRunProcess() int {
+	var input toolRunProcessInput
+	if err := json.NewDecoder(os.Stdin).Decode(&input); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read the tool run:", err)
+		return 1
+	}
+	if input.MaxMemoryBytes > 0 {
+		// the collector works harder close to the limit, instead of leaving garbage in the room of the handler
+		debug.SetMemoryLimit(int64(input.MaxMemoryBytes) + toolRunProcessMemoryHeadroom)
+		if err := limitProcessMemory(uint64(input.MaxMemoryBytes) + toolRunProcessMemoryHeadroom); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to limit the memory of the tool run:", err)
+			return 1
+		}
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(runToolInProcess(input)); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write the tool result:", err)
+		return 1
+	}
+	return 0
+}
+
+// runToolProcess runs the tool in a child process of the same executable, which gets nothing of the environment of
+// the server. A child that dies because it ran out of memory fails the run with ToolExecutionOutOfMemory, the logs
+// are lost then.
+func runToolProcess(ctx context.Context, input toolRunProcessInput) (toolRunProcessOutput, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return toolRunProcessOutput{}, errors.Wrap(err, "failed to find the executable to run the tool in")
+	}
+	stdin, err := json.Marshal(input)
+	if err != nil {
+		return toolRunProcessOutput{}, errors.Wrap(err, "failed to encode the tool run")
+	}
+
+	processCtx, cancel := context.WithTimeout(ctx, input.Timeout+toolRunProcessKillDelay)
+	defer cancel()
+	cmd := exec.CommandContext(processCtx, executable)
+	cmd.Env = []string{toolRunProcessEnv + "=1"}
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	runErr := cmd.Run()
+
+	if runErr == nil {
+		var output toolRunProcessOutput
+		if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+			return toolRunProcessOutput{}, errors.Wrap(err, "failed to decode the tool result")
+		}
+		return output, nil
+	}
+	if processCtx.Err() != nil {
+		code := error_code.ToolExecutionTimedOut
+		return toolRunProcessOutput{ErrorCode: &code, Message: errToolRunTimedOut.Error()}, nil
+	}
+	// the runtime exits with "out of memory" when an allocation fails, and the kernel kills a process that ran out of
+	// memory with a signal
+	var exitErr *exec.ExitError
+	if strings.Contains(stderr.String(), "out of memory") || (errors.As(runErr, &exitErr) && exitErr.ExitCode() == -1) {
+		code := error_code.ToolExecutionOutOfMemory
+		return toolRunProcessOutput{ErrorCode: &code, Message: fmt.Sprintf("the handler used more than %d bytes of memory", input.MaxMemoryBytes)}, nil
+	}
+	message := stderr.String()
+	if len(message) > toolRunProcessMaxStderr {
+		message = message[:toolRunProcessMaxStderr]
+	}
+	return toolRunProcessOutput{}, errors.Wrapf(runErr, "the tool process failed: %s", message)
+}
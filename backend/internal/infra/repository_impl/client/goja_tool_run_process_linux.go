@@ -0,0 +1,47 @@
+package client
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// limitProcessMemory lets the process map maxBytes of data on top of what it maps already, the go runtime reserves
+// memory it never touches, so an absolute limit would depend on the build
+func limitProcessMemory(maxBytes uint64) error {
+	mapped, err := processDataBytes()
+	if err != nil {
+		return err
+	}
+	limit := mapped + maxBytes
+	return syscall.Setrlimit(syscall.RLIMIT_DATA, &syscall.Rlimit{Cur: limit, Max: limit})
+}
+
+// processDataBytes reads the size of the data segment of the process, which is what RLIMIT_DATA bounds
+func processDataBytes() (uint64, error) {
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		value, found := strings.CutPrefix(scanner.Text(), "VmData:")
+		if !found {
+			continue
+		}
+		kilobytes, err := strconv.ParseUint(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "kB")), 10, 64)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to parse VmData")
+		}
+		return kilobytes << 10, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("VmData is missing in /proc/self/status")
+}
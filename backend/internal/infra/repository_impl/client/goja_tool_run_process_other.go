@@ -0,0 +1,8 @@
+//go:build !linux
+
+package client
+
+// limitProcessMemory is a no-op outside of linux, only the heap guard of the run bounds the memory there
+func limitProcessMemory(maxBytes uint64) error {
+	return nil
+}
@@ -0,0 +1,356 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+)
+
+const (
+	// toolRunnerMaxCallStackSize bounds the recursion of a handler, a runaway recursion fails the run
+	toolRunnerMaxCallStackSize = 1024
+	// toolRunnerMaxLogs bounds the console calls kept of a run, later ones are dropped
+	toolRunnerMaxLogs = 200
+	// toolRunnerMaxLogLength cuts every console message to this many bytes
+	toolRunnerMaxLogLength = 2048
+	// toolRunnerMemoryCheckInterval is how often the heap is measured while a handler runs
+	toolRunnerMemoryCheckInterval = 10 * time.Millisecond
+	// heapObjectsMetric is the memory taken by the objects on the heap, live or not yet swept
+	heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+)
+
+var (
+	errToolRunTimedOut    = errors.New("the tool handler did not finish in time")
+	errToolRunTooLarge    = errors.New("the tool handler returned more than the payload limit")
+	errToolRunOutOfMemory = errors.New("the tool handler allocated more than the memory limit")
+)
+
+func NewGojaToolRunner() *GojaToolRunner {
+	return &GojaToolRunner{}
+}
+
+// GojaToolRunner runs tool handlers in goja, a javascript runtime written in go. Every run gets a fresh runtime that
+// reaches nothing of the server: there is no file, network or process access, no timers and no requirePackage.
+// goja can not bound the memory of a runtime, so every run happens in a child process of its own, see
+// ServeToolRunProcess, which the kernel stops once it maps more than the memory limit.
+type GojaToolRunner struct{}
+
+func (r *GojaToolRunner) Run(ctx context.Context, source string, secrets map[string]string, request entity.ToolExecutionRequestEntity, limits entity.ToolExecutionLimitsEntity) (entity.ToolExecutionResultEntity, error) {
+	started := time.Now()
+	output, err := runToolProcess(ctx, toolRunProcessInput{
+		Source:          source,
+		Secrets:         secrets,
+		Inputs:          request.Inputs,
+		ChangedWidgetID: request.ChangedWidgetID,
+		Timeout:         limits.Timeout,
+		MaxPayloadBytes: limits.MaxPayloadBytes,
+		MaxMemoryBytes:  limits.MaxMemoryBytes,
+	})
+	result := entity.ToolExecutionResultEntity{
+		Outputs:  output.Outputs,
+		Updates:  output.Updates,
+		Logs:     output.Logs,
+		Duration: time.Since(started),
+	}
+	if err != nil {
+		return result, err
+	}
+	if output.ErrorCode != nil {
+		logs := make([]map[string]string, len(output.Logs))
+		for i, log := range output.Logs {
+			logs[i] = map[string]string{"level": log.Level, "message": log.Message}
+		}
+		return result, error_code.NewErrorWithErrorCodeFAppendExtraData(*output.ErrorCode, map[string]any{"logs": logs}, "%s", output.Message)
+	}
+	return result, nil
+}
+
+// runToolInProcess runs the handler in this process, which is the child process started for the run
+func runToolInProcess(input toolRunProcessInput) toolRunProcessOutput {
+	vm := goja.New()
+	vm.SetMaxCallStackSize(toolRunnerMaxCallStackSize)
+	run := &gojaToolRun{vm: vm, maxPayloadBytes: input.MaxPayloadBytes, maxMemoryBytes: input.MaxMemoryBytes}
+	if err := run.installGlobals(input.Secrets); err != nil {
+		code := error_code.InternalServerError
+		return toolRunProcessOutput{ErrorCode: &code, Message: "failed to set up the tool runtime: " + err.Error()}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), input.Timeout)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() { vm.Interrupt(errToolRunTimedOut) })
+	defer stop()
+	if input.MaxMemoryBytes > 0 {
+		go guardMemory(ctx, vm, uint64(input.MaxMemoryBytes))
+	}
+
+	request := entity.ToolExecutionRequestEntity{Inputs: input.Inputs, ChangedWidgetID: input.ChangedWidgetID}
+	outputs, err := run.execute(input.Source, request)
+	output := toolRunProcessOutput{Outputs: outputs, Updates: run.updates, Logs: run.logs}
+	if err != nil {
+		code, message := run.errorCode(err)
+		output.ErrorCode, output.Message = &code, message
+	}
+	return output
+}
+
+// guardMemory interrupts the handler once the heap grew by more than maxBytes since the run started, until ctx is
+// done. The process runs nothing but this handler, so the heap growth is what the handler allocated. Garbage is
+// collected before the run is stopped, so only memory still in use counts. The guard only samples the heap, an
+// allocation too large for the limit is stopped by the kernel instead, see limitProcessMemory.
+func guardMemory(ctx context.Context, vm *goja.Runtime, maxBytes uint64) {
+	limit := heapObjectsBytes() + maxBytes
+	ticker := time.NewTicker(toolRunnerMemoryCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if heapObjectsBytes() <= limit {
+			continue
+		}
+		runtime.GC()
+		if heapObjectsBytes() > limit {
+			vm.Interrupt(errToolRunOutOfMemory)
+			return
+		}
+	}
+}
+
+func heapObjectsBytes() uint64 {
+	sample := []metrics.Sample{{Name: heapObjectsMetric}}
+	metrics.Read(sample)
+	return sample[0].Value.Uint64()
+}
+
+// gojaToolRun is the state of one run, a goja runtime is not safe for concurrent use and is never shared
+type gojaToolRun struct {
+	vm              *goja.Runtime
+	stringify       goja.Callable
+	maxPayloadBytes int
+	maxMemoryBytes  int
+	payloadBytes    int
+	updates         []map[string]any
+	logs            []entity.ToolExecutionLogEntity
+}
+
+// installGlobals exposes what the handler may use besides the language itself, window and self are the global
+// object like in the browser sandbox
+func (r *gojaToolRun) installGlobals(secrets map[string]string) error {
+	stringify, ok := goja.AssertFunction(r.vm.Get("JSON").ToObject(r.vm).Get("stringify"))
+	if !ok {
+		return errors.New("JSON.stringify is not a function")
+	}
+	r.stringify = stringify
+
+	console := r.vm.NewObject()
+	for _, level := range []string{"log", "info", "warn", "error", "debug"} {
+		if err := console.Set(level, r.consoleFunc(level)); err != nil {
+			return err
+		}
+	}
+
+	secretsObject := r.vm.NewObject()
+	for name, value := range secrets {
+		if err := secretsObject.Set(name, value); err != nil {
+			return err
+		}
+	}
+	freeze, _ := goja.AssertFunction(r.vm.Get("Object").ToObject(r.vm).Get("freeze"))
+	if _, err := freeze(goja.Undefined(), secretsObject); err != nil {
+		return err
+	}
+
+	globals := map[string]any{
+		"console": console,
+		"secrets": secretsObject,
+		"btoa":    r.btoa,
+		"atob":    r.atob,
+		"requirePackage": func(call goja.FunctionCall) goja.Value {
+			panic(r.vm.NewGoError(errors.New("requirePackage is not available when the tool runs on the server")))
+		},
+		"window": r.vm.GlobalObject(),
+		"self":   r.vm.GlobalObject(),
+	}
+	for name, value := range globals {
+		if err := r.vm.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execute evaluates the source and calls its handler like the browser sandbox does, an awaited promise has to
+// settle without timers or network, which are not available
+func (r *gojaToolRun) execute(source string, request entity.ToolExecutionRequestEntity) (map[string]any, error) {
+	program, err := goja.Compile("handler.js", "(function() {\n"+source+"\n;return typeof handler === \"function\" ? handler : undefined;\n})()", false)
+	if err != nil {
+		return nil, err
+	}
+	value, err := r.vm.RunProgram(program)
+	if err != nil {
+		return nil, err
+	}
+	handler, ok := goja.AssertFunction(value)
+	if !ok {
+		return nil, errors.New("the handler function is not defined in the tool source")
+	}
+
+	inputsJSON, err := json.Marshal(request.Inputs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode the inputs")
+	}
+	inputs, err := r.vm.RunString("(" + string(inputsJSON) + ")")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the inputs")
+	}
+	changedWidgetID := goja.Undefined()
+	if request.ChangedWidgetID != "" {
+		changedWidgetID = r.vm.ToValue(request.ChangedWidgetID)
+	}
+
+	returned, err := handler(goja.Undefined(), inputs, changedWidgetID, r.vm.ToValue(r.callback))
+	if err != nil {
+		return nil, err
+	}
+	if promise, ok := returned.Export().(*goja.Promise); ok {
+		switch promise.State() {
+		case goja.PromiseStateFulfilled:
+			returned = promise.Result()
+		case goja.PromiseStateRejected:
+			return nil, errors.Errorf("the handler rejected: %s", promise.Result().String())
+		default:
+			return nil, errors.New("the handler awaits something that never settles, timers and network are not available on the server")
+		}
+	}
+
+	if goja.IsUndefined(returned) || goja.IsNull(returned) {
+		return nil, nil
+	}
+	_, isObject := returned.(*goja.Object)
+	_, isFunction := goja.AssertFunction(returned)
+	if !isObject || isFunction {
+		return nil, errors.New("the handler must return an object, undefined, or null")
+	}
+	return r.decodeObject(returned, "the handler must return an object, undefined, or null")
+}
+
+// callback receives the ui updates the handler sends while it runs
+func (r *gojaToolRun) callback(call goja.FunctionCall) goja.Value {
+	update, err := r.decodeObject(call.Argument(0), "the callback takes an object")
+	if err != nil {
+		if errors.Is(err, errToolRunTooLarge) {
+			r.vm.Interrupt(errToolRunTooLarge)
+			return goja.Undefined()
+		}
+		panic(r.vm.NewGoError(err))
+	}
+	r.updates = append(r.updates, update)
+	return goja.Undefined()
+}
+
+// decodeObject turns a javascript object into its JSON form and counts it towards the payload limit
+func (r *gojaToolRun) decodeObject(value goja.Value, notObject string) (map[string]any, error) {
+	encoded, err := r.stringify(goja.Undefined(), value)
+	if err != nil {
+		return nil, err
+	}
+	if goja.IsUndefined(encoded) {
+		return nil, errors.New(notObject)
+	}
+	text := encoded.String()
+	r.payloadBytes += len(text)
+	if r.payloadBytes > r.maxPayloadBytes {
+		return nil, errToolRunTooLarge
+	}
+	var object map[string]any
+	if err := json.Unmarshal([]byte(text), &object); err != nil || object == nil {
+		return nil, errors.New(notObject)
+	}
+	return object, nil
+}
+
+func (r *gojaToolRun) consoleFunc(level string) func(call goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		if len(r.logs) >= toolRunnerMaxLogs {
+			return goja.Undefined()
+		}
+		parts := make([]string, len(call.Arguments))
+		for i, argument := range call.Arguments {
+			parts[i] = r.formatLogArgument(argument)
+		}
+		message := strings.Join(parts, " ")
+		if len(message) > toolRunnerMaxLogLength {
+			message = message[:toolRunnerMaxLogLength]
+		}
+		r.logs = append(r.logs, entity.ToolExecutionLogEntity{Level: level, Message: message})
+		return goja.Undefined()
+	}
+}
+
+// formatLogArgument prints strings as they are and objects as JSON, like the browser console shows them
+func (r *gojaToolRun) formatLogArgument(argument goja.Value) string {
+	if object, ok := argument.(*goja.Object); ok {
+		if _, isFunction := goja.AssertFunction(object); !isFunction && object.ClassName() != "Error" {
+			if encoded, err := r.stringify(goja.Undefined(), object); err == nil && !goja.IsUndefined(encoded) {
+				return encoded.String()
+			}
+		}
+	}
+	return argument.String()
+}
+
+// btoa encodes a string of latin1 characters in base64, like the browser function
+func (r *gojaToolRun) btoa(value string) string {
+	bytes := make([]byte, 0, len(value))
+	for _, char := range value {
+		if char > 0xff {
+			panic(r.vm.NewGoError(errors.New("btoa: the string contains characters outside of the latin1 range")))
+		}
+		bytes = append(bytes, byte(char))
+	}
+	return base64.StdEncoding.EncodeToString(bytes)
+}
+
+// atob decodes base64 into a string of latin1 characters, like the browser function the padding is optional
+func (r *gojaToolRun) atob(value string) string {
+	decoded, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		panic(r.vm.NewGoError(errors.New("atob: the string is not valid base64")))
+	}
+	runes := make([]rune, len(decoded))
+	for i, b := range decoded {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// errorCode tells a handler that failed from one that was stopped
+func (r *gojaToolRun) errorCode(err error) (error_code.ErrorCode, string) {
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		reason, _ := interrupted.Value().(error)
+		if errors.Is(reason, errToolRunTooLarge) {
+			return error_code.ToolExecutionTooLarge, fmt.Sprintf("the outputs exceed %d bytes", r.maxPayloadBytes)
+		}
+		if errors.Is(reason, errToolRunOutOfMemory) {
+			return error_code.ToolExecutionOutOfMemory, fmt.Sprintf("the handler used more than %d bytes of memory", r.maxMemoryBytes)
+		}
+		return error_code.ToolExecutionTimedOut, errToolRunTimedOut.Error()
+	}
+	if errors.Is(err, errToolRunTooLarge) {
+		return error_code.ToolExecutionTooLarge, fmt.Sprintf("the outputs exceed %d bytes", r.maxPayloadBytes)
+	}
+	return error_code.ToolExecutionFailed, err.Error()
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testToolExecutionLimits = entity.ToolExecutionLimitsEntity{Timeout: time.Second, MaxPayloadBytes: 1024}
+
+// TestMain serves the tool runs, GojaToolRunner runs every tool in a child process of the test binary
+func TestMain(m *testing.M) {
+	if IsToolRunProcess() {
+		os.Exit(ServeToolRunProcess())
+	}
+	os.Exit(m.Run())
+}
+
+func TestGojaToolRunner_Run(t *testing.T) {
+	source := `
+async function handler(inputWidgets, changedWidgetIds, callback) {
+	console.log("changed", changedWidgetIds, { count: inputWidgets.items.length });
+	callback({ status: "working" });
+	const total = await Promise.resolve(inputWidgets.items.reduce((sum, item) => sum + item, 0));
+	return { total, token: secrets.TOKEN, encoded: btoa(inputWidgets.name), isWindow: window === globalThis };
+}`
+	request := entity.ToolExecutionRequestEntity{
+		Inputs:          map[string]any{"items": []any{1, 2, 3}, "name": "bake"},
+		ChangedWidgetID: "items",
+	}
+
+	result, err := NewGojaToolRunner().Run(context.Background(), source, map[string]string{"TOKEN": "secret"}, request, testToolExecutionLimits)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"total": float64(6), "token": "secret", "encoded": "YmFrZQ==", "isWindow": true}, result.Outputs)
+	require.Equal(t, []map[string]any{{"status": "working"}}, result.Updates)
+	require.Equal(t, []entity.ToolExecutionLogEntity{{Level: "log", Message: `changed items {"count":3}`}}, result.Logs)
+}
+
+func TestGojaToolRunner_Run_EmptyResult(t *testing.T) {
+	result, err := NewGojaToolRunner().Run(context.Background(), `function handler() { return null; }`, nil, entity.ToolExecutionRequestEntity{}, testToolExecutionLimits)
+	require.NoError(t, err)
+	require.Nil(t, result.Outputs)
+}
+
+func TestGojaToolRunner_Run_Failures(t *testing.T) {
+	cases := []struct {
+		name     string
+		source   string
+		wantCode error_code.ErrorCode
+		wantText string
+	}{
+		{"syntax error", `function handler( {`, error_code.ToolExecutionFailed, ""},
+		{"no handler", `const answer = 42;`, error_code.ToolExecutionFailed, "handler function is not defined"},
+		{"throws", `function handler() { console.warn("about to fail"); throw new Error("boom"); }`, error_code.ToolExecutionFailed, "boom"},
+		{"rejects", `async function handler() { throw new Error("rejected"); }`, error_code.ToolExecutionFailed, "rejected"},
+		{"not an object", `function handler() { return 42; }`, error_code.ToolExecutionFailed, "must return an object"},
+		{"never settles", `function handler() { return new Promise(() => {}); }`, error_code.ToolExecutionFailed, "never settles"},
+		{"no packages", `function handler() { return requirePackage("lodash"); }`, error_code.ToolExecutionFailed, "not available"},
+		{"endless loop", `function handler() { for (;;) {} }`, error_code.ToolExecutionTimedOut, ""},
+		{"catches the timeout", `function handler() { for (;;) { try { for (;;) {} } catch (e) {} } }`, error_code.ToolExecutionTimedOut, ""},
+		{"recursion", `function handler() { return handler(); }`, error_code.ToolExecutionFailed, ""},
+		{"large output", `function handler() { return { text: "x".repeat(2048) }; }`, error_code.ToolExecutionTooLarge, ""},
+		{"large updates", `function handler(inputs, changed, callback) { for (;;) { try { callback({ text: "x".repeat(100) }); } catch (e) {} } }`, error_code.ToolExecutionTooLarge, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			limits := entity.ToolExecutionLimitsEntity{Timeout: 100 * time.Millisecond, MaxPayloadBytes: 1024}
+			_, err := NewGojaToolRunner().Run(context.Background(), c.source, nil, entity.ToolExecutionRequestEntity{}, limits)
+			codeErr := requireGithubErrorCode(t, err, c.wantCode)
+			require.Contains(t, codeErr.ExtraMessage, c.wantText)
+		})
+	}
+}
+
+func TestGojaToolRunner_Run_MemoryLimit(t *testing.T) {
+	source := `function handler() { const kept = []; for (;;) { kept.push("x".repeat(1024) + kept.length); } }`
+	// the timeout is far off, so only the memory limit can stop the handler in time
+	limits := entity.ToolExecutionLimitsEntity{Timeout: 30 * time.Second, MaxPayloadBytes: 1024, MaxMemoryBytes: 16 << 20}
+	started := time.Now()
+	_, err := NewGojaToolRunner().Run(context.Background(), source, nil, entity.ToolExecutionRequestEntity{}, limits)
+	requireGithubErrorCode(t, err, error_code.ToolExecutionOutOfMemory)
+	require.Less(t, time.Since(started), 10*time.Second)
+}
+
+func TestGojaToolRunner_Run_MemoryLimit_LargeAllocation(t *testing.T) {
+	cases := map[string]string{
+		"one allocation": `function handler() { return { text: "x".repeat(1 << 30) }; }`,
+		"doubling":       `function handler() { let text = "x"; for (;;) { text += text; } }`,
+	}
+	for name, source := range cases {
+		t.Run(name, func(t *testing.T) {
+			limits := entity.ToolExecutionLimitsEntity{Timeout: 30 * time.Second, MaxPayloadBytes: 1024, MaxMemoryBytes: 16 << 20}
+			started := time.Now()
+			_, err := NewGojaToolRunner().Run(context.Background(), source, nil, entity.ToolExecutionRequestEntity{}, limits)
+			requireGithubErrorCode(t, err, error_code.ToolExecutionOutOfMemory)
+			require.Less(t, time.Since(started), 10*time.Second)
+		})
+	}
+}
+
+func TestGojaToolRunner_Run_WithinMemoryLimit(t *testing.T) {
+	source := `function handler() { const kept = []; for (let i = 0; i < 4096; i++) { kept.push("x".repeat(1024) + i); } return { count: kept.length }; }`
+	limits := entity.ToolExecutionLimitsEntity{Timeout: 5 * time.Second, MaxPayloadBytes: 1024, MaxMemoryBytes: 16 << 20}
+	result, err := NewGojaToolRunner().Run(context.Background(), source, nil, entity.ToolExecutionRequestEntity{}, limits)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"count": float64(4096)}, result.Outputs)
+}
+
+func TestGojaToolRunner_Run_KeepsLogsOfFailedRun(t *testing.T) {
+	source := `function handler() { console.info("step", 1); throw new Error("boom"); }`
+	result, err := NewGojaToolRunner().Run(context.Background(), source, nil, entity.ToolExecutionRequestEntity{}, testToolExecutionLimits)
+	codeErr := requireGithubErrorCode(t, err, error_code.ToolExecutionFailed)
+	require.Equal(t, map[string]any{"logs": []map[string]string{{"level": "info", "message": "step 1"}}}, codeErr.ExtraData)
+	require.Equal(t, []entity.ToolExecutionLogEntity{{Level: "info", Message: "step 1"}}, result.Logs)
+}
+
+func TestGojaToolRunner_Run_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	_, err := NewGojaToolRunner().Run(ctx, `function handler() { for (;;) {} }`, nil, entity.ToolExecutionRequestEntity{}, entity.ToolExecutionLimitsEntity{Timeout: time.Minute, MaxPayloadBytes: 1024})
+	requireGithubErrorCode(t, err, error_code.ToolExecutionTimedOut)
+}
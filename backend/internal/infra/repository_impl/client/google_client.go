@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"time"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/domain/entity"
@@ -145,3 +146,18 @@ func (c *GoogleClient) GetUserInfo(accessToken string) (entity.GoogleUserInfoEnt
 		result.Locale,
 	), nil
 }
+
+// Ping reports the API as reachable when it answers at all, an unauthenticated request is expected to get a 4xx.
+func (c *GoogleClient) Ping(ctx context.Context) error {
+	client := resty.New()
+	defer client.Close()
+
+	resp, err := client.R().SetContext(ctx).Get("https://www.googleapis.com/oauth2/v2/userinfo")
+	if err != nil {
+		return errors.Wrap(err, "failed to reach google api")
+	}
+	if resp.StatusCode() >= 500 {
+		return errors.Errorf("google api returned status %d", resp.StatusCode())
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/pkg/errors"
+	"resty.dev/v3"
+)
+
+const httpContentScannerTimeout = 30 * time.Second
+
+func NewHTTPContentScanner(config config.Config) (*HTTPContentScanner, error) {
+	if config.ImportScannerHTTPURL == "" {
+		return nil, errors.New("import scanner http url is empty, please check IMPORT_SCANNER_HTTP_URL in config")
+	}
+	return &HTTPContentScanner{url: config.ImportScannerHTTPURL}, nil
+}
+
+// HTTPContentScanner posts each file as the raw request body to an external scanner,
+// the file name is sent in the X-File-Name header. The scanner answers 200 with
+// {"clean": true} or {"clean": false, "signature": "..."}, any other status is a scan failure.
+type HTTPContentScanner struct {
+	url string
+}
+
+func (s *HTTPContentScanner) Scan(ctx context.Context, file entity.ImportFileEntity) (entity.ContentScanResultEntity, error) {
+	var result struct {
+		Clean     bool   `json:"clean"`
+		Signature string `json:"signature"`
+	}
+
+	client := resty.New().SetTimeout(httpContentScannerTimeout)
+	defer client.Close()
+
+	resp, err := client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/octet-stream").
+		SetHeader("X-File-Name", file.Name).
+		SetBody(file.Content).
+		SetResult(&result).
+		Post(s.url)
+	if err != nil {
+		return entity.ContentScanResultEntity{}, errors.Wrap(err, "failed to call import scanner")
+	}
+	if resp.StatusCode() != 200 {
+		return entity.ContentScanResultEntity{}, errors.Errorf("import scanner returned status %d: %s", resp.StatusCode(), resp.String())
+	}
+	if !result.Clean && result.Signature == "" {
+		result.Signature = "rejected by scanner"
+	}
+	return entity.ContentScanResultEntity{Clean: result.Clean, Signature: result.Signature}, nil
+}
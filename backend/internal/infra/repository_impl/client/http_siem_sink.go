@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/config"
+
+	"github.com/pkg/errors"
+	"resty.dev/v3"
+)
+
+const httpSIEMSinkTimeout = 10 * time.Second
+
+func NewHTTPSIEMSink(config config.Config) (*HTTPSIEMSink, error) {
+	if config.SIEMHTTPURL == "" {
+		return nil, errors.New("siem http url is empty, please check SIEM_HTTP_URL in config")
+	}
+	contentType := "application/x-ndjson"
+	if config.SIEMFormat == "cef" {
+		contentType = "text/plain"
+	}
+	return &HTTPSIEMSink{url: config.SIEMHTTPURL, contentType: contentType}, nil
+}
+
+// HTTPSIEMSink posts every batch to a collector endpoint, such as an HTTP event collector of the SIEM.
+// Any status other than 2xx is a failure.
+type HTTPSIEMSink struct {
+	url         string
+	contentType string
+}
+
+func (s *HTTPSIEMSink) Write(ctx context.Context, batch []byte) error {
+	client := resty.New().SetTimeout(httpSIEMSinkTimeout)
+	defer client.Close()
+
+	resp, err := client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", s.contentType).
+		SetBody(batch).
+		Post(s.url)
+	if err != nil {
+		return errors.Wrap(err, "failed to post siem events")
+	}
+	if !resp.IsSuccess() {
+		return errors.Errorf("siem collector returned status %d: %s", resp.StatusCode(), resp.String())
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/pkg/errors"
+	"resty.dev/v3"
+)
+
+const httpUsageEnforcerTimeout = 5 * time.Second
+
+func NewHTTPUsageEnforcer(config config.Config) (*HTTPUsageEnforcer, error) {
+	if config.UsageEnforcerHTTPURL == "" {
+		return nil, errors.New("usage enforcer http url is empty, please check USAGE_ENFORCER_HTTP_URL in config")
+	}
+	return &HTTPUsageEnforcer{url: config.UsageEnforcerHTTPURL}, nil
+}
+
+// HTTPUsageEnforcer posts every usage request as json to an external billing service:
+// {"user_id": "...", "metric": "api_calls", "period": "2026-01", "amount": 1, "usage": 41}.
+// The service answers 200 with {"allow": true} or {"allow": false, "reason": "..."}, any other status is a failure.
+type HTTPUsageEnforcer struct {
+	url string
+}
+
+func (e *HTTPUsageEnforcer) Allow(ctx context.Context, request entity.UsageRequestEntity) error {
+	var result struct {
+		Allow  bool   `json:"allow"`
+		Reason string `json:"reason"`
+	}
+
+	client := resty.New().SetTimeout(httpUsageEnforcerTimeout)
+	defer client.Close()
+
+	resp, err := client.R().
+		SetContext(ctx).
+		SetBody(map[string]any{
+			"user_id": request.UserID,
+			"metric":  request.Metric,
+			"period":  request.Period,
+			"amount":  request.Amount,
+			"usage":   request.Usage,
+		}).
+		SetResult(&result).
+		Post(e.url)
+	if err != nil {
+		return errors.Wrap(err, "failed to call usage enforcer")
+	}
+	if resp.StatusCode() != 200 {
+		return errors.Errorf("usage enforcer returned status %d: %s", resp.StatusCode(), resp.String())
+	}
+	if !result.Allow {
+		return error_code.NewErrorWithErrorCodeFAppendExtraData(error_code.UsageLimitExceeded,
+			map[string]any{"metric": request.Metric, "reason": result.Reason},
+			"usage enforcer refused %d %s for user %s: %s", request.Amount, request.Metric, request.UserID, result.Reason)
+	}
+	return nil
+}
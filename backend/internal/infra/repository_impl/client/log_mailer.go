@@ -0,0 +1,20 @@
+package client
+
+import (
+	"context"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// LogMailer writes every email to the log instead of sending it, for development only:
+// the logged bodies hold the recovery codes.
+type LogMailer struct{}
+
+func (m *LogMailer) Send(ctx context.Context, mail entity.MailEntity) error {
+	logger.Warnf(ctx, "Mail to %s not sent, MAILER=log: subject: %q, body:\n%s", mail.To, mail.Subject, mail.Body)
+	return nil
+}
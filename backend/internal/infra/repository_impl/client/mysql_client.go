@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"time"
 	"ya-tool-craft/internal/config"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -9,6 +10,11 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	serverlessMysqlMaxOpenConns    = 2
+	serverlessMysqlConnMaxLifetime = 5 * time.Minute
+)
+
 func NewMysqlClient(config config.Config) (*MysqlClient, error) {
 	if config.MysqlHost == "" || config.MysqlUser == "" || config.MysqlDB == "" {
 		return nil, errors.Errorf("invalid mysql config: host=%s user=%s db=%s", config.MysqlHost, config.MysqlUser, config.MysqlDB)
@@ -27,11 +33,20 @@ func NewMysqlClient(config config.Config) (*MysqlClient, error) {
 		config.MysqlDB,
 	)
 
-	db, err := sqlx.Open("mysql", dsn)
+	db, err := openRdsDB("mysql", dsn, config)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to open mysql: %s:%s/%s", config.MysqlHost, port, config.MysqlDB)
 	}
 
+	if config.Serverless {
+		// a Lambda instance serves one request at a time, many instances must not exhaust the connections of mysql.
+		// The connections survive between invocations, the ones older than the lifetime are replaced when taken,
+		// mysql may have closed them while the instance was frozen
+		db.SetMaxOpenConns(serverlessMysqlMaxOpenConns)
+		db.SetMaxIdleConns(serverlessMysqlMaxOpenConns)
+		db.SetConnMaxLifetime(serverlessMysqlConnMaxLifetime)
+	}
+
 	if err := db.Ping(); err != nil {
 		return nil, errors.Wrapf(err, "failed to ping mysql: %s:%s/%s", config.MysqlHost, port, config.MysqlDB)
 	}
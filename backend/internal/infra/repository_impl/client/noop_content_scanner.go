@@ -0,0 +1,18 @@
+package client
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+func NewNoopContentScanner() *NoopContentScanner {
+	return &NoopContentScanner{}
+}
+
+// NoopContentScanner accepts every file, it is used when no scanner is configured.
+// The import size and count limits still apply.
+type NoopContentScanner struct{}
+
+func (s *NoopContentScanner) Scan(ctx context.Context, file entity.ImportFileEntity) (entity.ContentScanResultEntity, error) {
+	return entity.ContentScanResultEntity{Clean: true}, nil
+}
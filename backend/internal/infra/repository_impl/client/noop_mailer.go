@@ -0,0 +1,20 @@
+package client
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/pkg/errors"
+)
+
+func NewNoopMailer() *NoopMailer {
+	return &NoopMailer{}
+}
+
+// NoopMailer sends nothing, it is used when no mailer is configured.
+// The flows needing an email refuse to start, so a send failing here is a bug.
+type NoopMailer struct{}
+
+func (m *NoopMailer) Send(ctx context.Context, mail entity.MailEntity) error {
+	return errors.New("no mailer is configured, please check MAILER in config")
+}
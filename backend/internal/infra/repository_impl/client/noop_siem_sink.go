@@ -0,0 +1,14 @@
+package client
+
+import "context"
+
+func NewNoopSIEMSink() *NoopSIEMSink {
+	return &NoopSIEMSink{}
+}
+
+// NoopSIEMSink discards every batch, it is used when no siem export is configured.
+type NoopSIEMSink struct{}
+
+func (s *NoopSIEMSink) Write(ctx context.Context, batch []byte) error {
+	return nil
+}
@@ -0,0 +1,18 @@
+package client
+
+import (
+	"context"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+func NewNoopUsageEnforcer() *NoopUsageEnforcer {
+	return &NoopUsageEnforcer{}
+}
+
+// NoopUsageEnforcer allows any usage, it is used when no enforcer is configured.
+// The max tools per user setting still applies.
+type NoopUsageEnforcer struct{}
+
+func (e *NoopUsageEnforcer) Allow(ctx context.Context, request entity.UsageRequestEntity) error {
+	return nil
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"net"
+	"net/url"
+	"time"
+	"ya-tool-craft/internal/config"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	serverlessPostgresMaxOpenConns    = 2
+	serverlessPostgresConnMaxLifetime = 5 * time.Minute
+)
+
+func NewPostgresClient(config config.Config) (*PostgresClient, error) {
+	if config.PostgresHost == "" || config.PostgresUser == "" || config.PostgresDB == "" {
+		return nil, errors.Errorf("invalid postgres config: host=%s user=%s db=%s", config.PostgresHost, config.PostgresUser, config.PostgresDB)
+	}
+
+	port := config.PostgresPort
+	if port == "" {
+		port = "5432"
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.PostgresUser, config.PostgresPass),
+		Host:     net.JoinHostPort(config.PostgresHost, port),
+		Path:     "/" + config.PostgresDB,
+		RawQuery: url.Values{"sslmode": {config.PostgresSSLMode}}.Encode(),
+	}
+
+	connector, err := openRdsConnector("pgx", dsn.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open postgres: %s:%s/%s", config.PostgresHost, port, config.PostgresDB)
+	}
+	// the repositories are written with ? placeholders, postgres numbers them
+	db := newRdsDB(dollarPlaceholderConnector{connector: connector}, "pgx", config)
+
+	if config.Serverless {
+		// same as mysql, many Lambda instances must not exhaust the connections of postgres
+		db.SetMaxOpenConns(serverlessPostgresMaxOpenConns)
+		db.SetMaxIdleConns(serverlessPostgresMaxOpenConns)
+		db.SetConnMaxLifetime(serverlessPostgresConnMaxLifetime)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, errors.Wrapf(err, "failed to ping postgres: %s:%s/%s", config.PostgresHost, port, config.PostgresDB)
+	}
+
+	return &PostgresClient{
+		db:     db,
+		config: config,
+	}, nil
+}
+
+type PostgresClient struct {
+	db     *sqlx.DB
+	config config.Config
+}
+
+func (c *PostgresClient) DB() *sqlx.DB {
+	return c.db
+}
+
+func (c *PostgresClient) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"database/sql/driver"
+	"strconv"
+	"strings"
+)
+
+// rebindDollarPlaceholders numbers the ? placeholders of query as $1, $2, ... for postgres, like sqlx.Rebind with
+// sqlx.DOLLAR does, so ?? is two placeholders too. Unlike sqlx.Rebind, question marks in string literals, quoted
+// identifiers, dollar quoted strings and comments are left alone, as postgres reads them.
+func rebindDollarPlaceholders(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(query) + 8)
+	n := 0
+	for i := 0; i < len(query); {
+		c := query[i]
+		end := i + 1
+		switch {
+		case c == '?':
+			n++
+			sb.WriteByte('$')
+			sb.WriteString(strconv.Itoa(n))
+			i++
+			continue
+		case c == '\'' || c == '"':
+			// a doubled quote escapes itself, it is read as the end of one literal and the start of the next
+			end = skipPast(query, i+1, string(c))
+		case strings.HasPrefix(query[i:], "--"):
+			end = skipPast(query, i+2, "\n")
+		case strings.HasPrefix(query[i:], "/*"):
+			end = skipBlockComment(query, i)
+		case c == '$':
+			if tag := dollarQuoteTag(query, i); tag != "" {
+				end = skipPast(query, i+len(tag), tag)
+			}
+		}
+		sb.WriteString(query[i:end])
+		i = end
+	}
+	return sb.String()
+}
+
+// skipPast returns the index after the first closing delimiter from start on, the end of the query when there is none
+func skipPast(query string, start int, delimiter string) int {
+	end := strings.Index(query[start:], delimiter)
+	if end < 0 {
+		return len(query)
+	}
+	return start + end + len(delimiter)
+}
+
+// skipBlockComment returns the index after the block comment starting at start, postgres nests block comments
+func skipBlockComment(query string, start int) int {
+	depth := 0
+	for i := start; i < len(query)-1; i++ {
+		switch query[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(query)
+}
+
+// dollarQuoteTag returns the tag, such as $$ or $body$, of the dollar quoted string starting at start, empty when
+// the $ does not start one, like the $ of a numbered placeholder or inside an identifier
+func dollarQuoteTag(query string, start int) string {
+	if start > 0 && isIdentifierByte(query[start-1]) {
+		return ""
+	}
+	end := start + 1
+	for end < len(query) && query[end] != '$' && isIdentifierByte(query[end]) {
+		end++
+	}
+	if end >= len(query) || query[end] != '$' || (end > start+1 && query[start+1] >= '0' && query[start+1] <= '9') {
+		return ""
+	}
+	return query[start : end+1]
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// dollarPlaceholderConnector rebinds the statements of every connection with rebindDollarPlaceholders.
+type dollarPlaceholderConnector struct {
+	connector driver.Connector
+}
+
+func (c dollarPlaceholderConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &dollarPlaceholderConn{Conn: conn}, nil
+}
+
+func (c dollarPlaceholderConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+type dollarPlaceholderConn struct {
+	driver.Conn
+}
+
+func (c *dollarPlaceholderConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	query = rebindDollarPlaceholders(query)
+	if prepare, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return prepare.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *dollarPlaceholderConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *dollarPlaceholderConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return execer.ExecContext(ctx, rebindDollarPlaceholders(query), args)
+}
+
+func (c *dollarPlaceholderConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return queryer.QueryContext(ctx, rebindDollarPlaceholders(query), args)
+}
+
+func (c *dollarPlaceholderConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if begin, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return begin.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *dollarPlaceholderConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *dollarPlaceholderConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *dollarPlaceholderConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *dollarPlaceholderConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
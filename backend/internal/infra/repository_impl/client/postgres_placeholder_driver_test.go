@@ -0,0 +1,52 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebindDollarPlaceholders(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "no placeholder", query: "SELECT COUNT(*) FROM users", want: "SELECT COUNT(*) FROM users"},
+		{name: "numbered in order", query: "UPDATE users SET email = ? WHERE id = ? LIMIT ?", want: "UPDATE users SET email = $1 WHERE id = $2 LIMIT $3"},
+		{name: "string literal", query: "SELECT '?', ? FROM t WHERE a = 'it''s ?' AND b = ?", want: "SELECT '?', $1 FROM t WHERE a = 'it''s ?' AND b = $2"},
+		{name: "quoted identifier", query: `SELECT "what?" FROM t WHERE a = ?`, want: `SELECT "what?" FROM t WHERE a = $1`},
+		{name: "comments", query: "-- why?\nSELECT ? /* really? */, ?", want: "-- why?\nSELECT $1 /* really? */, $2"},
+		{name: "unterminated literal", query: "SELECT ? WHERE a = 'x?", want: "SELECT $1 WHERE a = 'x?"},
+		{name: "already numbered", query: "SELECT $1", want: "SELECT $1"},
+		{name: "doubled placeholder", query: "SELECT ?? FROM t WHERE a = ?", want: "SELECT $1$2 FROM t WHERE a = $3"},
+		{name: "placeholders side by side", query: "VALUES (?,?),(?, ?)", want: "VALUES ($1,$2),($3, $4)"},
+		{name: "quoted identifier with doubled quote", query: `SELECT "a""?" FROM t WHERE a = ?`, want: `SELECT "a""?" FROM t WHERE a = $1`},
+		{name: "comment at the end", query: "SELECT ? -- why?", want: "SELECT $1 -- why?"},
+		{name: "nested block comment", query: "SELECT ? /* a /* b? */ c? */ , ?", want: "SELECT $1 /* a /* b? */ c? */ , $2"},
+		{name: "unterminated block comment", query: "SELECT ? /* why?", want: "SELECT $1 /* why?"},
+		{name: "dollar quoted string", query: "SELECT $$it's ?$$, ?", want: "SELECT $$it's ?$$, $1"},
+		{name: "tagged dollar quoted string", query: "SELECT $q$ $$ ? $q$, ?", want: "SELECT $q$ $$ ? $q$, $1"},
+		{name: "dollar in identifier", query: "SELECT a$b$ FROM t WHERE a = ?", want: "SELECT a$b$ FROM t WHERE a = $1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rebindDollarPlaceholders(tt.query))
+		})
+	}
+}
+
+// outside of literals and comments the placeholders are numbered like sqlx numbers them
+func TestRebindDollarPlaceholders_MatchesSqlx(t *testing.T) {
+	queries := []string{
+		"SELECT * FROM tools WHERE user_id = ? AND unique_id = ?",
+		"INSERT INTO tool_changes (user_id, tool_unique_id, changed_at) VALUES (?, ?, ?), (?, ?, ?)",
+		"SELECT ?? FROM t",
+		"UPDATE t SET a = ?, b = ? WHERE c IN (?, ?, ?)",
+	}
+	for _, query := range queries {
+		assert.Equal(t, sqlx.Rebind(sqlx.DOLLAR, query), rebindDollarPlaceholders(query), query)
+	}
+}
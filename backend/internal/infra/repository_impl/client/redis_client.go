@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strconv"
+	"time"
+	"ya-tool-craft/internal/config"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+const redisPingTimeout = 5 * time.Second
+
+// NewRedisClient creates a new Redis client, the connection is verified before it is returned
+func NewRedisClient(config config.Config) (*RedisClient, error) {
+	if config.RedisHost == "" {
+		return nil, errors.Errorf("invalid redis config: host=%s", config.RedisHost)
+	}
+
+	addr := net.JoinHostPort(config.RedisHost, strconv.Itoa(config.RedisPort))
+	options := &redis.Options{
+		Addr:     addr,
+		Password: config.RedisPassword,
+		DB:       config.RedisDB,
+	}
+	if config.RedisTLS {
+		options.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, ServerName: config.RedisHost}
+	}
+
+	rdb := redis.NewClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisPingTimeout)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
+		return nil, errors.Wrapf(err, "failed to ping redis: %s/%d", addr, config.RedisDB)
+	}
+
+	return &RedisClient{Client: rdb}, nil
+}
+
+type RedisClient struct {
+	Client *redis.Client
+}
+
+func (c *RedisClient) Close() error {
+	return c.Client.Close()
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"ya-tool-craft/internal/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSIEMSink(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewFileSIEMSink(config.Config{})
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "siem.log")
+	sink, err := NewFileSIEMSink(config.Config{SIEMFilePath: path})
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write(context.Background(), []byte("a\n")))
+	// a rotated file is created again
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, sink.Write(context.Background(), []byte("b\n")))
+	require.NoError(t, sink.Write(context.Background(), []byte("c\n")))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "b\nc\n", string(content))
+}
+
+func TestHTTPSIEMSink(t *testing.T) {
+	t.Parallel()
+
+	status := http.StatusOK
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r.Header.Get("Content-Type")+" "+string(body))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := NewHTTPSIEMSink(config.Config{})
+	require.Error(t, err)
+
+	sink, err := NewHTTPSIEMSink(config.Config{SIEMHTTPURL: server.URL, SIEMFormat: "cef"})
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(context.Background(), []byte("CEF:0|a\n")))
+
+	status = http.StatusServiceUnavailable
+	require.Error(t, sink.Write(context.Background(), []byte("CEF:0|b\n")))
+	require.Equal(t, []string{"text/plain CEF:0|a\n", "text/plain CEF:0|b\n"}, received)
+}
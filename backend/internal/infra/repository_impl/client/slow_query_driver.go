@@ -0,0 +1,259 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/metrics"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// slowQueryMaxSQLLength caps the logged statement, generated IN (...) lists can get long.
+const slowQueryMaxSQLLength = 1000
+
+var (
+	sqlStringLiteralRegex  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumberLiteralRegex  = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	sqlWhitespaceRunsRegex = regexp.MustCompile(`\s+`)
+)
+
+// openRdsDB opens the database, when SLOW_QUERY_THRESHOLD is set every connection is wrapped
+// to log the statements taking longer than it.
+func openRdsDB(driverName string, dsn string, config config.Config) (*sqlx.DB, error) {
+	if config.SlowQueryThreshold <= 0 {
+		return sqlx.Open(driverName, dsn)
+	}
+
+	connector, err := openRdsConnector(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return newRdsDB(connector, driverName, config), nil
+}
+
+// openRdsConnector opens a connector of the registered driver, for the clients wrapping the connections.
+func openRdsConnector(driverName string, dsn string) (driver.Connector, error) {
+	// sql.Open does not connect, it is only used to look up the registered driver
+	probe, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find sql driver %s", driverName)
+	}
+	d := probe.Driver()
+	_ = probe.Close()
+
+	if dc, ok := d.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to open %s connector", driverName)
+		}
+		return connector, nil
+	}
+	return dsnConnector{dsn: dsn, driver: d}, nil
+}
+
+// newRdsDB opens the database on the connector, wrapped to log the slow statements when SLOW_QUERY_THRESHOLD is set.
+func newRdsDB(connector driver.Connector, driverName string, config config.Config) *sqlx.DB {
+	if config.SlowQueryThreshold > 0 {
+		slowLog := slowQueryLogger{threshold: time.Duration(config.SlowQueryThreshold) * time.Millisecond}
+		connector = slowQueryConnector{connector: connector, logger: slowLog}
+	}
+	return sqlx.NewDb(sql.OpenDB(connector), driverName)
+}
+
+// slowQueryLogger logs a statement at WARN level and counts it in toolbake_slow_queries_total when it took
+// at least threshold. Literals are removed from the logged sql, only the number of bound parameters is logged.
+type slowQueryLogger struct {
+	threshold time.Duration
+}
+
+func (l slowQueryLogger) observe(ctx context.Context, operation string, query string, params int, elapsed time.Duration) {
+	if elapsed < l.threshold {
+		return
+	}
+	metrics.SlowQueriesTotal.WithLabelValues(operation).Inc()
+	logger.Warnf(ctx, "Slow sql %s took %s (threshold %s) with %d bound parameters: %s", operation, elapsed, l.threshold, params, sanitizeSQL(query))
+}
+
+// sanitizeSQL replaces string and number literals with ? and collapses whitespace,
+// so values written inline into a statement never reach the log.
+func sanitizeSQL(query string) string {
+	query = sqlStringLiteralRegex.ReplaceAllString(query, "?")
+	query = sqlNumberLiteralRegex.ReplaceAllString(query, "?")
+	query = strings.TrimSpace(sqlWhitespaceRunsRegex.ReplaceAllString(query, " "))
+	if len(query) > slowQueryMaxSQLLength {
+		query = query[:slowQueryMaxSQLLength] + "..."
+	}
+	return query
+}
+
+// dsnConnector opens connections of a driver without its own connector, like sql.Open does.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type slowQueryConnector struct {
+	connector driver.Connector
+	logger    slowQueryLogger
+}
+
+func (c slowQueryConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &slowQueryConn{Conn: conn, logger: c.logger}, nil
+}
+
+func (c slowQueryConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// slowQueryConn times the statements run on a connection, the optional driver interfaces are passed
+// through and driver.ErrSkip lets database/sql fall back when the wrapped connection lacks one.
+// A query is timed until its first rows are returned, reading the rows is not included.
+type slowQueryConn struct {
+	driver.Conn
+	logger slowQueryLogger
+}
+
+func (c *slowQueryConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if prepare, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = prepare.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &slowQueryStmt{Stmt: stmt, query: query, logger: c.logger}, nil
+}
+
+func (c *slowQueryConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *slowQueryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if !errors.Is(err, driver.ErrSkip) {
+		c.logger.observe(ctx, "exec", query, len(args), time.Since(start))
+	}
+	return result, err
+}
+
+func (c *slowQueryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if !errors.Is(err, driver.ErrSkip) {
+		c.logger.observe(ctx, "query", query, len(args), time.Since(start))
+	}
+	return rows, err
+}
+
+func (c *slowQueryConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if begin, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return begin.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *slowQueryConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *slowQueryConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *slowQueryConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *slowQueryConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+type slowQueryStmt struct {
+	driver.Stmt
+	query  string
+	logger slowQueryLogger
+}
+
+func (s *slowQueryStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(namedValuesToValues(args))
+	}
+	s.logger.observe(ctx, "exec", s.query, len(args), time.Since(start))
+	return result, err
+}
+
+func (s *slowQueryStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedValuesToValues(args))
+	}
+	s.logger.observe(ctx, "query", s.query, len(args), time.Since(start))
+	return rows, err
+}
+
+func (s *slowQueryStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func namedValuesToValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeSQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "placeholders are kept and whitespace collapsed",
+			query: "SELECT *\n\tFROM tools\n  WHERE user_id = ? AND uid IN (?, ?)",
+			want:  "SELECT * FROM tools WHERE user_id = ? AND uid IN (?, ?)",
+		},
+		{
+			name:  "inline literals are removed",
+			query: "UPDATE users SET password = 'it''s secret', login_count = 12 WHERE id = 'u-1' LIMIT 1.5",
+			want:  "UPDATE users SET password = ?, login_count = ? WHERE id = ? LIMIT ?",
+		},
+		{
+			name:  "digits inside identifiers are kept",
+			query: "SELECT col1 FROM user_2fa",
+			want:  "SELECT col1 FROM user_2fa",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, sanitizeSQL(tt.query))
+		})
+	}
+}
+
+func TestOpenRdsDB_SlowQueries(t *testing.T) {
+	logger.InitLogger(config.Config{})
+
+	db, err := openRdsDB("sqlite", filepath.Join(t.TempDir(), "slow.db"), config.Config{SlowQueryThreshold: 1})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	// the wrapped connection behaves like the plain one for exec, query, prepared statements and transactions
+	_, err = db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	tx, err := db.Beginx()
+	require.NoError(t, err)
+	stmt, err := tx.Preparex("INSERT INTO items (name) VALUES (?)")
+	require.NoError(t, err)
+	for _, name := range []string{"a", "b"} {
+		_, err = stmt.Exec(name)
+		require.NoError(t, err)
+	}
+	require.NoError(t, stmt.Close())
+	require.NoError(t, tx.Commit())
+
+	var names []string
+	require.NoError(t, db.Select(&names, "SELECT name FROM items WHERE id > ? ORDER BY id", 0))
+	require.Equal(t, []string{"a", "b"}, names)
+
+	before := testutil.ToFloat64(metrics.SlowQueriesTotal.WithLabelValues("query"))
+	var count int
+	require.NoError(t, db.Get(&count, `
+WITH RECURSIVE n(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM n WHERE x < 300000)
+SELECT count(*) FROM n WHERE x > ?`, 0))
+	require.Equal(t, 300000, count)
+	require.Equal(t, before+1, testutil.ToFloat64(metrics.SlowQueriesTotal.WithLabelValues("query")))
+}
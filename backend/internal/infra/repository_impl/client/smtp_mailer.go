@@ -0,0 +1,142 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	smtpMailerDialTimeout = 5 * time.Second
+	smtpMailerSendTimeout = 30 * time.Second
+	// smtpImplicitTLSPort speaks tls from the first byte, the other ports are upgraded with STARTTLS when offered
+	smtpImplicitTLSPort = 465
+)
+
+func NewSMTPMailer(config config.Config) (*SMTPMailer, error) {
+	if config.SMTPHost == "" {
+		return nil, errors.New("smtp host is empty, please check SMTP_HOST in config")
+	}
+	if config.MailFrom == "" {
+		return nil, errors.New("mail sender is empty, please check MAIL_FROM in config")
+	}
+	return &SMTPMailer{
+		host:     config.SMTPHost,
+		port:     config.SMTPPort,
+		username: config.SMTPUsername,
+		password: config.SMTPPassword,
+		from:     config.MailFrom,
+	}, nil
+}
+
+// SMTPMailer sends emails through an SMTP relay, authenticating with PLAIN when a username is configured.
+type SMTPMailer struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, mail entity.MailEntity) error {
+	message, err := buildMailMessage(m.from, mail, time.Now())
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(m.host, strconv.Itoa(m.port))
+	dialer := net.Dialer{Timeout: smtpMailerDialTimeout}
+	var conn net.Conn
+	if m.port == smtpImplicitTLSPort {
+		conn, err = (&tls.Dialer{NetDialer: &dialer, Config: &tls.Config{ServerName: m.host}}).DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to smtp server at %s", address)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(smtpMailerSendTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return errors.Wrap(err, "failed to set smtp deadline")
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		return errors.Wrap(err, "failed to start smtp session")
+	}
+	defer client.Close()
+
+	if m.port != smtpImplicitTLSPort {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+				return errors.Wrap(err, "failed to start tls")
+			}
+		}
+	}
+	if m.username != "" {
+		// net/smtp refuses PLAIN over an unencrypted connection to a remote host
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return errors.Wrap(err, "failed to authenticate to smtp server")
+		}
+	}
+	if err := client.Mail(m.from); err != nil {
+		return errors.Wrap(err, "smtp server refused the sender")
+	}
+	if err := client.Rcpt(mail.To); err != nil {
+		return errors.Wrapf(err, "smtp server refused the recipient %s", mail.To)
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return errors.Wrap(err, "failed to start mail data")
+	}
+	if _, err := writer.Write(message); err != nil {
+		return errors.Wrap(err, "failed to write mail data")
+	}
+	if err := writer.Close(); err != nil {
+		return errors.Wrap(err, "smtp server refused the mail")
+	}
+	return client.Quit()
+}
+
+// buildMailMessage renders a plain text utf-8 message. Header values come from users, line breaks in them are refused
+// so they can not add headers or recipients.
+func buildMailMessage(from string, mail entity.MailEntity, now time.Time) ([]byte, error) {
+	for _, value := range []string{from, mail.To, mail.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, errors.New("mail header contains a line break")
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", mail.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	body := strings.ReplaceAll(strings.ReplaceAll(mail.Body, "\r\n", "\n"), "\n", "\r\n")
+	// a line holding a single dot ends the DATA section, the writer of net/smtp escapes it
+	buf.WriteString(body)
+	if !strings.HasSuffix(body, "\r\n") {
+		buf.WriteString("\r\n")
+	}
+	return buf.Bytes(), nil
+}
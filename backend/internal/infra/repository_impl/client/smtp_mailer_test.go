@@ -0,0 +1,115 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts one session without STARTTLS nor AUTH and sends the received envelope and data to the channel.
+func fakeSMTPServer(t *testing.T) (int, chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		var session strings.Builder
+		reply("220 fake smtp")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.Fields(line)[0])
+			switch command {
+			case "EHLO", "HELO":
+				reply("250 fake")
+			case "MAIL", "RCPT":
+				session.WriteString(line)
+				reply("250 ok")
+			case "DATA":
+				reply("354 go ahead")
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil || dataLine == ".\r\n" {
+						break
+					}
+					session.WriteString(dataLine)
+				}
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				received <- session.String()
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	port, received := fakeSMTPServer(t)
+	mailer, err := NewSMTPMailer(config.Config{SMTPHost: "127.0.0.1", SMTPPort: port, MailFrom: "toolbake@example.com"})
+	require.NoError(t, err)
+
+	err = mailer.Send(context.Background(), entity.MailEntity{
+		To:      "user@example.com",
+		Subject: "Récupération du compte",
+		Body:    "Your code is 123456\n.\nbye",
+	})
+	require.NoError(t, err)
+
+	select {
+	case session := <-received:
+		assert.Contains(t, session, "MAIL FROM:<toolbake@example.com>")
+		assert.Contains(t, session, "RCPT TO:<user@example.com>")
+		assert.Contains(t, session, "To: user@example.com\r\n")
+		assert.Contains(t, session, "Subject: =?utf-8?q?R=C3=A9cup=C3=A9ration_du_compte?=\r\n")
+		assert.Contains(t, session, "Your code is 123456\r\n")
+		// the lone dot of the body is escaped so it does not end the data early
+		assert.Contains(t, session, "\r\n..\r\nbye\r\n")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fake smtp server received nothing")
+	}
+}
+
+func TestNewSMTPMailer_RequiresHostAndSender(t *testing.T) {
+	_, err := NewSMTPMailer(config.Config{MailFrom: "toolbake@example.com", SMTPPort: 587})
+	assert.Error(t, err)
+	_, err = NewSMTPMailer(config.Config{SMTPHost: "smtp.example.com", SMTPPort: 587})
+	assert.Error(t, err)
+}
+
+func TestBuildMailMessage_RefusesHeaderInjection(t *testing.T) {
+	now := time.Unix(0, 0)
+	_, err := buildMailMessage("toolbake@example.com", entity.MailEntity{To: "user@example.com\r\nBcc: other@example.com", Subject: "hi"}, now)
+	assert.Error(t, err)
+	_, err = buildMailMessage("toolbake@example.com", entity.MailEntity{To: "user@example.com", Subject: "hi\nBcc: other@example.com"}, now)
+	assert.Error(t, err)
+
+	message, err := buildMailMessage("toolbake@example.com", entity.MailEntity{To: "user@example.com", Subject: "hi", Body: "line"}, now)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(message), "\r\n\r\nline\r\n"), strconv.Quote(string(message)))
+}
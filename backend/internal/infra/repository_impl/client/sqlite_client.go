@@ -27,7 +27,7 @@ func NewSqliteClient(config config.Config) (*SqliteClient, error) {
 		}
 	}
 
-	db, err := sqlx.Open("sqlite", path)
+	db, err := openRdsDB("sqlite", path, config)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to open sqlite: %s", path)
 	}
@@ -0,0 +1,14 @@
+package client
+
+import "time"
+
+func NewSystemClock() *SystemClock {
+	return &SystemClock{}
+}
+
+// SystemClock reads the wall clock.
+type SystemClock struct{}
+
+func (c *SystemClock) Now() time.Time {
+	return time.Now()
+}
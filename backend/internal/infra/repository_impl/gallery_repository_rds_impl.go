@@ -0,0 +1,170 @@
+package repository_impl
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/config"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+// GalleryToolRdsModel is a published tool joined with the tool and the username of its owner
+type GalleryToolRdsModel struct {
+	ToolRdsModel
+	InstallCount int64     `db:"install_count"`
+	ForkCount    int64     `db:"fork_count"`
+	PublishedAt  time.Time `db:"published_at"`
+	OwnerName    string    `db:"owner_name"`
+}
+
+const (
+	galleryToolRdsColumns = toolRdsColumns + `, g.install_count, g.fork_count, g.published_at, COALESCE(u.username, '') AS owner_name`
+	galleryToolRdsFrom    = `gallery_tools g
+		JOIN tools t ON t.user_id = g.owner_id AND t.unique_id = g.tool_unique_id
+		LEFT JOIN tool_sources s ON s.hash = t.source_hash
+		LEFT JOIN users u ON u.id = g.owner_id
+		` + toolRdsOwnerKeyJoin
+)
+
+func NewGalleryRepositoryRdsImpl(config config.Config, client repository.IRdsClient, clock domain_client.IClock) *GalleryRepositoryRdsImpl {
+	return &GalleryRepositoryRdsImpl{config: config, client: client, clock: clock}
+}
+
+type GalleryRepositoryRdsImpl struct {
+	config config.Config
+	client repository.IRdsClient
+	clock  domain_client.IClock
+}
+
+func (r *GalleryRepositoryRdsImpl) PublishTool(ctx context.Context, ownerID entity.UserIDEntity, toolUID string) error {
+	tx, err := r.client.DB().BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM gallery_tools WHERE tool_unique_id = ?", toolUID); err != nil {
+		return errors.Wrap(err, "failed to check gallery tool in rds")
+	}
+	if count == 0 {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO gallery_tools (tool_unique_id, owner_id, install_count, fork_count, published_at) VALUES (?, ?, 0, 0, ?)",
+			toolUID, string(ownerID), r.clock.Now(),
+		); err != nil {
+			return errors.Wrap(err, "failed to insert gallery tool in rds")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (r *GalleryRepositoryRdsImpl) UnpublishTool(ctx context.Context, ownerID entity.UserIDEntity, toolUID string) (bool, error) {
+	deleted, err := r.client.DB().ExecContext(ctx, "DELETE FROM gallery_tools WHERE owner_id = ? AND tool_unique_id = ?", string(ownerID), toolUID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to delete gallery tool from rds")
+	}
+	affected, err := deleted.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get deleted gallery tool count")
+	}
+	return affected > 0, nil
+}
+
+func (r *GalleryRepositoryRdsImpl) ListPublished(ctx context.Context, filter entity.GalleryFilter) ([]entity.GalleryToolEntity, error) {
+	conditions := []string{"t.is_archived = ?"}
+	args := []any{false}
+	if filter.Query != "" {
+		conditions = append(conditions, "LOWER(t.name) LIKE ? ESCAPE '!'")
+		args = append(args, "%"+escapeLikePattern(strings.ToLower(filter.Query))+"%")
+	}
+	if filter.Category != "" {
+		conditions = append(conditions, "t.category = ?")
+		args = append(args, filter.Category)
+	}
+
+	orderBy := "g.published_at DESC, g.tool_unique_id"
+	if filter.Sort == entity.GallerySortPopular {
+		orderBy = "g.install_count + g.fork_count DESC, g.published_at DESC, g.tool_unique_id"
+	}
+
+	var models []GalleryToolRdsModel
+	if err := r.client.DB().SelectContext(ctx, &models,
+		"SELECT "+galleryToolRdsColumns+" FROM "+galleryToolRdsFrom+" WHERE "+strings.Join(conditions, " AND ")+" ORDER BY "+orderBy,
+		args...,
+	); err != nil {
+		return nil, errors.Wrap(err, "failed to list gallery tools from rds")
+	}
+
+	galleryTools := make([]entity.GalleryToolEntity, 0, len(models))
+	for _, model := range models {
+		galleryTool, err := toGalleryToolEntity(r.config.SourceEncryptionSecret, model)
+		if err != nil {
+			return nil, err
+		}
+		galleryTools = append(galleryTools, galleryTool)
+	}
+	return galleryTools, nil
+}
+
+func (r *GalleryRepositoryRdsImpl) GetPublished(ctx context.Context, toolUID string) (entity.GalleryToolEntity, bool, error) {
+	var model GalleryToolRdsModel
+	err := r.client.DB().GetContext(ctx, &model,
+		"SELECT "+galleryToolRdsColumns+" FROM "+galleryToolRdsFrom+" WHERE g.tool_unique_id = ? AND t.is_archived = ?",
+		toolUID, false,
+	)
+	if err != nil {
+		if stdErrors.Is(err, sql.ErrNoRows) {
+			return entity.GalleryToolEntity{}, false, nil
+		}
+		return entity.GalleryToolEntity{}, false, errors.Wrap(err, "failed to get gallery tool from rds")
+	}
+	galleryTool, err := toGalleryToolEntity(r.config.SourceEncryptionSecret, model)
+	if err != nil {
+		return entity.GalleryToolEntity{}, false, err
+	}
+	return galleryTool, true, nil
+}
+
+func (r *GalleryRepositoryRdsImpl) IncrementInstallCount(ctx context.Context, toolUID string) error {
+	if _, err := r.client.DB().ExecContext(ctx, "UPDATE gallery_tools SET install_count = install_count + 1 WHERE tool_unique_id = ?", toolUID); err != nil {
+		return errors.Wrap(err, "failed to increment gallery tool install count in rds")
+	}
+	return nil
+}
+
+func (r *GalleryRepositoryRdsImpl) IncrementForkCount(ctx context.Context, toolUID string) error {
+	if _, err := r.client.DB().ExecContext(ctx, "UPDATE gallery_tools SET fork_count = fork_count + 1 WHERE tool_unique_id = ?", toolUID); err != nil {
+		return errors.Wrap(err, "failed to increment gallery tool fork count in rds")
+	}
+	return nil
+}
+
+// escapeLikePattern escapes the LIKE wildcards of a user supplied string for a LIKE ... ESCAPE '!' condition
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
+
+func toGalleryToolEntity(secret string, model GalleryToolRdsModel) (entity.GalleryToolEntity, error) {
+	tool, err := toToolEntity(secret, model.ToolRdsModel)
+	if err != nil {
+		return entity.GalleryToolEntity{}, err
+	}
+	return entity.GalleryToolEntity{
+		Tool:         tool,
+		OwnerID:      entity.UserIDEntity(model.UserID),
+		OwnerName:    model.OwnerName,
+		InstallCount: model.InstallCount,
+		ForkCount:    model.ForkCount,
+		PublishedAt:  model.PublishedAt,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package repository_impl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/infra/repository_impl/client"
+	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGalleryRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		clock := fixtures.NewFakeClock(time.Unix(100, 0))
+		repo := NewGalleryRepositoryRdsImpl(rdsConfig, rdsClient, clock)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+
+		// the sqlite file is shared between tests, start from an empty table
+		_, err := rdsClient.DB().Exec("DELETE FROM gallery_tools")
+		assert.Nil(t, err)
+
+		owner, err := userRdsImpl.Create(ctx, fmt.Sprintf("gallery-owner-%s", uuid.New().String()[:8]), []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		jsonTool := fixtures.NewTestTool().WithID("json-format").WithName("JSON Formatter").WithCategory("format").WithExtraInfo(map[string]string{"tags": "json, Format"}).Build()
+		percentTool := fixtures.NewTestTool().WithID("full-width").WithName("100% Width").WithCategory("css").Build()
+		privateTool := fixtures.NewTestTool().WithID("private-json").WithName("Private JSON").WithCategory("format").Build()
+		for _, tool := range []entity.ToolEntity{jsonTool, percentTool, privateTool} {
+			assert.Nil(t, toolRdsImpl.CreateTool(owner.ID, tool, entity.ToolEventSourceEntity{}))
+		}
+
+		assert.Nil(t, repo.PublishTool(ctx, owner.ID, jsonTool.UniqueID))
+		clock.Advance(time.Minute)
+		assert.Nil(t, repo.PublishTool(ctx, owner.ID, percentTool.UniqueID))
+
+		// newest first, the unpublished tool is not listed
+		galleryTools, err := repo.ListPublished(ctx, entity.GalleryFilter{})
+		assert.Nil(t, err)
+		assert.Len(t, galleryTools, 2)
+		assert.Equal(t, percentTool.UniqueID, galleryTools[0].Tool.UniqueID)
+		assert.Equal(t, owner.Name, galleryTools[0].OwnerName)
+		assert.Equal(t, "json, Format", galleryTools[1].Tool.ExtraInfo["tags"])
+
+		galleryTools, err = repo.ListPublished(ctx, entity.GalleryFilter{Query: "json", Category: "format"})
+		assert.Nil(t, err)
+		assert.Len(t, galleryTools, 1)
+		assert.Equal(t, jsonTool.UniqueID, galleryTools[0].Tool.UniqueID)
+		// LIKE wildcards in the query are matched literally
+		galleryTools, err = repo.ListPublished(ctx, entity.GalleryFilter{Query: "0%"})
+		assert.Nil(t, err)
+		assert.Len(t, galleryTools, 1)
+		galleryTools, err = repo.ListPublished(ctx, entity.GalleryFilter{Query: "%"})
+		assert.Nil(t, err)
+		assert.Len(t, galleryTools, 1)
+
+		// publishing again keeps the counters, popular sorts by installs and forks
+		assert.Nil(t, repo.IncrementInstallCount(ctx, jsonTool.UniqueID))
+		assert.Nil(t, repo.IncrementForkCount(ctx, jsonTool.UniqueID))
+		assert.Nil(t, repo.PublishTool(ctx, owner.ID, jsonTool.UniqueID))
+		galleryTools, err = repo.ListPublished(ctx, entity.GalleryFilter{Sort: entity.GallerySortPopular})
+		assert.Nil(t, err)
+		assert.Equal(t, jsonTool.UniqueID, galleryTools[0].Tool.UniqueID)
+		assert.Equal(t, int64(1), galleryTools[0].InstallCount)
+		assert.Equal(t, int64(1), galleryTools[0].ForkCount)
+		assert.Equal(t, time.Unix(100, 0).Unix(), galleryTools[0].PublishedAt.Unix())
+
+		// archived tools are hidden
+		assert.Nil(t, toolRdsImpl.SetToolArchived(owner.ID, percentTool.UniqueID, true, entity.ToolEventSourceEntity{}))
+		_, exists, err := repo.GetPublished(ctx, percentTool.UniqueID)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+		galleryTool, exists, err := repo.GetPublished(ctx, jsonTool.UniqueID)
+		assert.Nil(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, jsonTool.Source, galleryTool.Tool.Source)
+
+		unpublished, err := repo.UnpublishTool(ctx, "someone-else", jsonTool.UniqueID)
+		assert.Nil(t, err)
+		assert.False(t, unpublished)
+		unpublished, err = repo.UnpublishTool(ctx, owner.ID, jsonTool.UniqueID)
+		assert.Nil(t, err)
+		assert.True(t, unpublished)
+
+		// deleting a tool takes it out of the gallery
+		assert.Nil(t, toolRdsImpl.DeleteTool(owner.ID, percentTool.UniqueID, entity.ToolEventSourceEntity{}))
+		var count int
+		assert.Nil(t, rdsClient.DB().Get(&count, "SELECT COUNT(*) FROM gallery_tools"))
+		assert.Equal(t, 0, count)
+	})
+}
@@ -24,13 +24,20 @@ type GlobalScriptRdsModel struct {
 	UserID    string    `db:"user_id"`
 	Script    string    `db:"script"`
 	UpdatedAt time.Time `db:"updated_at"`
+	// ScriptKey is the data key of the script sealed with OwnerEncryptKey, empty for a plaintext script
+	ScriptKey       string `db:"script_key"`
+	OwnerEncryptKey string `db:"owner_encrypt_key"`
 }
 
 func (r *GlobalScriptRepositoryRdsImpl) GetGlobalScript(userID entity.UserIDEntity) (*entity.GlobalScriptEntity, error) {
 	db := r.client.DB()
 	var model GlobalScriptRdsModel
 
-	err := db.Get(&model, "SELECT user_id, script, updated_at FROM global_scripts WHERE user_id = ?", string(userID))
+	err := db.Get(&model,
+		`SELECT g.user_id, g.script, g.updated_at, g.script_key, COALESCE(k.encrypt_key, '') AS owner_encrypt_key
+		 FROM global_scripts g LEFT JOIN users k ON k.id = g.user_id WHERE g.user_id = ?`,
+		string(userID),
+	)
 	if err != nil {
 		if stdErrors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -38,7 +45,14 @@ func (r *GlobalScriptRepositoryRdsImpl) GetGlobalScript(userID entity.UserIDEnti
 		return nil, pkgerrors.Wrap(err, "fail to get global script")
 	}
 
-	entity := entity.NewGlobalScriptEntity(model.Script, model.UpdatedAt)
+	script := model.Script
+	if model.ScriptKey != "" {
+		if script, err = openSealedText(r.config.SourceEncryptionSecret, model.OwnerEncryptKey, model.ScriptKey, model.Script); err != nil {
+			return nil, pkgerrors.Wrap(err, "fail to open global script")
+		}
+	}
+
+	entity := entity.NewGlobalScriptEntity(script, model.UpdatedAt)
 	return &entity, nil
 }
 
@@ -46,22 +60,69 @@ func (r *GlobalScriptRepositoryRdsImpl) UpdateGlobalScript(userID entity.UserIDE
 	db := r.client.DB()
 	now := time.Now()
 
+	scriptKey := ""
+	if r.config.EncryptSourcesAtRest {
+		var err error
+		if script, scriptKey, err = sealOwnedText(db, r.config.SourceEncryptionSecret, userID, script); err != nil {
+			return err
+		}
+	}
+
 	var query string
 	switch r.config.DBType {
 	case "mysql":
-		query = `INSERT INTO global_scripts (user_id, script, updated_at)
-		 VALUES (?, ?, ?)
-		 ON DUPLICATE KEY UPDATE script = ?, updated_at = ?`
+		query = `INSERT INTO global_scripts (user_id, script, script_key, updated_at)
+		 VALUES (?, ?, ?, ?)
+		 ON DUPLICATE KEY UPDATE script = ?, script_key = ?, updated_at = ?`
 	default:
-		query = `INSERT INTO global_scripts (user_id, script, updated_at)
-		 VALUES (?, ?, ?)
-		 ON CONFLICT(user_id) DO UPDATE SET script = ?, updated_at = ?`
+		query = `INSERT INTO global_scripts (user_id, script, script_key, updated_at)
+		 VALUES (?, ?, ?, ?)
+		 ON CONFLICT(user_id) DO UPDATE SET script = ?, script_key = ?, updated_at = ?`
 	}
 
-	_, err := db.Exec(query, string(userID), script, now, script, now)
+	_, err := db.Exec(query, string(userID), script, scriptKey, now, script, scriptKey, now)
 	if err != nil {
 		return pkgerrors.Wrap(err, "fail to upsert global script")
 	}
 
 	return nil
 }
+
+func (r *GlobalScriptRepositoryRdsImpl) EncryptGlobalScripts() (int, error) {
+	db := r.client.DB()
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, pkgerrors.Wrap(err, "fail to begin global script encryption transaction")
+	}
+
+	var models []GlobalScriptRdsModel
+	if err := tx.Select(&models, "SELECT user_id, script, updated_at, script_key FROM global_scripts WHERE script_key = ''"); err != nil {
+		tx.Rollback()
+		return 0, pkgerrors.Wrap(err, "fail to select plaintext global scripts")
+	}
+
+	sealedCount := 0
+	for _, model := range models {
+		sealed, scriptKey, err := sealOwnedText(tx, r.config.SourceEncryptionSecret, entity.UserIDEntity(model.UserID), model.Script)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if scriptKey == "" {
+			continue
+		}
+		if _, err := tx.Exec(
+			"UPDATE global_scripts SET script = ?, script_key = ? WHERE user_id = ?",
+			sealed, scriptKey, model.UserID,
+		); err != nil {
+			tx.Rollback()
+			return 0, pkgerrors.Wrap(err, "fail to update global script")
+		}
+		sealedCount++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, pkgerrors.Wrap(err, "fail to commit global script encryption transaction")
+	}
+	return sealedCount, nil
+}
@@ -4,8 +4,9 @@ import (
 	"context"
 	"testing"
 	"time"
+	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/domain/entity"
-	"ya-tool-craft/internal/infra/repository_impl/client"
+	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/unittest"
 
 	"github.com/stretchr/testify/assert"
@@ -14,8 +15,8 @@ import (
 func TestGlobalScriptRepositoryRdsImpl_GetGlobalScript_NotFound(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		repo := NewGlobalScriptRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewGlobalScriptRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		result, err := repo.GetGlobalScript(entity.UserIDEntity("non-existent-user"))
 		assert.Nil(t, err)
@@ -26,8 +27,8 @@ func TestGlobalScriptRepositoryRdsImpl_GetGlobalScript_NotFound(t *testing.T) {
 func TestGlobalScriptRepositoryRdsImpl_UpdateGlobalScript(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		repo := NewGlobalScriptRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewGlobalScriptRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		userID := entity.UserIDEntity("test-user-1")
 		script := "console.log('hello world')"
@@ -48,8 +49,8 @@ func TestGlobalScriptRepositoryRdsImpl_UpdateGlobalScript(t *testing.T) {
 func TestGlobalScriptRepositoryRdsImpl_UpdateGlobalScript_Upsert(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		repo := NewGlobalScriptRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewGlobalScriptRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		userID := entity.UserIDEntity("test-user-1")
 
@@ -76,8 +77,8 @@ func TestGlobalScriptRepositoryRdsImpl_UpdateGlobalScript_Upsert(t *testing.T) {
 func TestGlobalScriptRepositoryRdsImpl_UpdateGlobalScript_EmptyScript(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		repo := NewGlobalScriptRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewGlobalScriptRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		userID := entity.UserIDEntity("test-user-1")
 
@@ -95,8 +96,8 @@ func TestGlobalScriptRepositoryRdsImpl_UpdateGlobalScript_EmptyScript(t *testing
 func TestGlobalScriptRepositoryRdsImpl_DifferentUsers(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		repo := NewGlobalScriptRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewGlobalScriptRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		userID1 := entity.UserIDEntity("user-1")
 		userID2 := entity.UserIDEntity("user-2")
@@ -123,8 +124,8 @@ func TestGlobalScriptRepositoryRdsImpl_DifferentUsers(t *testing.T) {
 func TestGlobalScriptRepositoryRdsImpl_UpdateGlobalScript_UpdatedAtChanges(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		repo := NewGlobalScriptRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewGlobalScriptRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		userID := entity.UserIDEntity("test-user-1")
 
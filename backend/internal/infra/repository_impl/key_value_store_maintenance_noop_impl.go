@@ -0,0 +1,18 @@
+package repository_impl
+
+import "context"
+
+func NewKeyValueStoreMaintenanceNoopImpl() *KeyValueStoreMaintenanceNoopImpl {
+	return &KeyValueStoreMaintenanceNoopImpl{}
+}
+
+// KeyValueStoreMaintenanceNoopImpl serves stores like redis that reclaim space on their own and have their own backups
+type KeyValueStoreMaintenanceNoopImpl struct{}
+
+func (m *KeyValueStoreMaintenanceNoopImpl) Compact(ctx context.Context) error {
+	return nil
+}
+
+func (m *KeyValueStoreMaintenanceNoopImpl) Backup(ctx context.Context, dir string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,37 @@
+package repository_impl
+
+import (
+	"context"
+	"ya-tool-craft/internal/infra/repository_impl/client"
+
+	"github.com/nutsdb/nutsdb"
+	"github.com/pkg/errors"
+)
+
+func NewKeyValueStoreMaintenanceNutsDBImpl(client *client.NutsDBClient) *KeyValueStoreMaintenanceNutsDBImpl {
+	return &KeyValueStoreMaintenanceNutsDBImpl{client: client}
+}
+
+type KeyValueStoreMaintenanceNutsDBImpl struct {
+	client *client.NutsDBClient
+}
+
+// Compact merges the nutsdb data files, dropping the deleted and expired entries they still hold
+func (m *KeyValueStoreMaintenanceNutsDBImpl) Compact(ctx context.Context) error {
+	if err := m.client.DB.Merge(); err != nil {
+		// a single data file has nothing to merge
+		if errors.Is(err, nutsdb.ErrDontNeedMerge) {
+			return nil
+		}
+		return errors.Wrap(err, "failed to merge nutsdb data files")
+	}
+	return nil
+}
+
+// Backup copies the nutsdb data files inside a read transaction, so no write lands halfway through the copy
+func (m *KeyValueStoreMaintenanceNutsDBImpl) Backup(ctx context.Context, dir string) (bool, error) {
+	if err := m.client.DB.Backup(dir); err != nil {
+		return false, errors.Wrap(err, "failed to back up nutsdb")
+	}
+	return true, nil
+}
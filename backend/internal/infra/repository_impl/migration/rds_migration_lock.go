@@ -0,0 +1,150 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"ya-tool-craft/internal/core/logger"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	migrationLockName = "toolbake_schema_migration"
+	// migrationLockStaleAfter releases a table lock whose holder crashed without cleaning up,
+	// no migration of this project is expected to run that long
+	migrationLockStaleAfter = 10 * time.Minute
+	migrationLockRetryDelay = 500 * time.Millisecond
+)
+
+// acquireMigrationLock makes sure only one instance runs migrations at a time, the others wait up to
+// the configured timeout. The returned release function must be called once the migrations are done.
+func (r *RdsMigrationImpl) acquireMigrationLock(ctx context.Context) (func(), error) {
+	timeout := time.Duration(r.config.MigrationLockTimeout) * time.Second
+	switch r.config.DBType {
+	case "mysql":
+		return acquireMysqlMigrationLock(ctx, r.clienet.DB(), timeout)
+	case "postgres":
+		return acquirePostgresMigrationLock(ctx, r.clienet.DB(), timeout)
+	}
+	return acquireTableMigrationLock(ctx, r.clienet.DB(), timeout)
+}
+
+// acquireMysqlMigrationLock uses a named lock, it is bound to the session so it is held on a dedicated
+// connection and released by mysql itself if the instance dies.
+func acquireMysqlMigrationLock(ctx context.Context, db *sqlx.DB, timeout time.Duration) (func(), error) {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get connection for migration lock")
+	}
+
+	var acquired sql.NullInt64
+	if err := conn.GetContext(ctx, &acquired, "SELECT GET_LOCK(?, ?)", migrationLockName, int(timeout.Seconds())); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "fail to acquire migration lock")
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		conn.Close()
+		return nil, errors.Errorf("fail to acquire migration lock within %s, another instance is running migrations", timeout)
+	}
+
+	return func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationLockName); err != nil {
+			logger.Errorf(ctx, "fail to release migration lock: %v", err)
+		}
+		conn.Close()
+	}, nil
+}
+
+// acquirePostgresMigrationLock uses a session advisory lock keyed by the hash of the lock name, like the mysql one it
+// is held on a dedicated connection. pg_advisory_lock has no timeout, so pg_try_advisory_lock is polled instead.
+func acquirePostgresMigrationLock(ctx context.Context, db *sqlx.DB, timeout time.Duration) (func(), error) {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get connection for migration lock")
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		var acquired bool
+		if err := conn.GetContext(ctx, &acquired, "SELECT pg_try_advisory_lock(hashtext(?))", migrationLockName); err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "fail to acquire migration lock")
+		}
+		if acquired {
+			break
+		}
+		if time.Now().After(deadline) {
+			conn.Close()
+			return nil, errors.Errorf("fail to acquire migration lock within %s, another instance is running migrations", timeout)
+		}
+
+		logger.Infof(ctx, "waiting for the migration lock held by another instance")
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			return nil, errors.Wrap(ctx.Err(), "interrupted while waiting for migration lock")
+		case <-time.After(migrationLockRetryDelay):
+		}
+	}
+
+	return func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext(?))", migrationLockName); err != nil {
+			logger.Errorf(ctx, "fail to release migration lock: %v", err)
+		}
+		conn.Close()
+	}, nil
+}
+
+// acquireTableMigrationLock emulates an advisory lock with a single row table for databases without one (sqlite).
+func acquireTableMigrationLock(ctx context.Context, db *sqlx.DB, timeout time.Duration) (func(), error) {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migration_lock (
+	id INTEGER PRIMARY KEY,
+	owner VARCHAR(255) NOT NULL,
+	locked_at TIMESTAMP NOT NULL
+)`); err != nil {
+		return nil, errors.Wrap(err, "fail to create schema_migration_lock table")
+	}
+
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), uuid.New().String())
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, err := db.ExecContext(ctx, "DELETE FROM schema_migration_lock WHERE locked_at < ?", time.Now().Add(-migrationLockStaleAfter)); err != nil {
+			return nil, errors.Wrap(err, "fail to clear stale migration lock")
+		}
+		_, err := db.ExecContext(ctx, "INSERT INTO schema_migration_lock (id, owner, locked_at) VALUES (1, ?, ?)", owner, time.Now())
+		if err == nil {
+			break
+		}
+		if !isUniqueViolation(err) {
+			return nil, errors.Wrap(err, "fail to acquire migration lock")
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("fail to acquire migration lock within %s, another instance is running migrations", timeout)
+		}
+
+		logger.Infof(ctx, "waiting for the migration lock held by another instance")
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "interrupted while waiting for migration lock")
+		case <-time.After(migrationLockRetryDelay):
+		}
+	}
+
+	return func() {
+		if _, err := db.ExecContext(context.Background(), "DELETE FROM schema_migration_lock WHERE owner = ?", owner); err != nil {
+			logger.Errorf(ctx, "fail to release migration lock: %v", err)
+		}
+	}, nil
+}
+
+func isUniqueViolation(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "unique constraint") || strings.Contains(message, "duplicate")
+}
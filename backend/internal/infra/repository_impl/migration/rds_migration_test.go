@@ -0,0 +1,312 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/infra/repository_impl/client"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMigration(t *testing.T) *RdsMigrationImpl {
+	t.Helper()
+	logger.InitLogger(config.Config{})
+
+	cfg := config.Config{DBType: "sqlite", SqlitePath: filepath.Join(t.TempDir(), "migration.db"), MigrationLockTimeout: 1}
+	sqliteClient, err := client.NewSqliteClient(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { sqliteClient.Close() })
+	return NewRdsMigrationImpl(sqliteClient, cfg)
+}
+
+// destructiveStatement matches statements that break the previous release while both run during a deploy.
+var destructiveStatement = regexp.MustCompile(`(?i)\bDROP\s+(TABLE|COLUMN|INDEX)\b|\bRENAME\b|\bALTER\s+TABLE\s+\w+\s+DROP\b|\bMODIFY\s+COLUMN\b|\bCHANGE\s+COLUMN\b`)
+
+func TestRdsMigrationsAreZeroDowntime(t *testing.T) {
+	lastVersion := 0
+	for _, m := range rdsMigrations {
+		require.Greater(t, m.Version, lastVersion, "migration versions must be unique and increasing: %s", m.Name)
+		lastVersion = m.Version
+		require.NotEmpty(t, m.Name)
+		require.NotEmpty(t, m.Sqlite, "migration %d has no sqlite statement", m.Version)
+		require.NotEmpty(t, m.Mysql, "migration %d has no mysql statement", m.Version)
+		require.NotEmpty(t, m.Postgres, "migration %d has no postgres statement", m.Version)
+
+		if m.AllowDestructive != "" {
+			continue
+		}
+		for _, statement := range []string{m.Sqlite, m.Mysql, m.Postgres} {
+			require.False(t, destructiveStatement.MatchString(statement),
+				"migration %d %s drops or renames schema the running release may still use, split it over two releases or set AllowDestructive",
+				m.Version, m.Name)
+		}
+	}
+}
+
+func TestRdsMigrationImpl_Hooks(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMigration(t)
+	require.NoError(t, r.RunMigrate(ctx))
+
+	var calls []string
+	migrations := []rdsMigration{
+		{
+			Version: 1001,
+			Name:    "create_hook_test",
+			Sqlite:  "CREATE TABLE hook_test (id INTEGER PRIMARY KEY, value TEXT NOT NULL)",
+			Mysql:   "CREATE TABLE hook_test (id INTEGER PRIMARY KEY, value TEXT NOT NULL)",
+			Pre: func(ctx context.Context, tx *sqlx.Tx, dbType string) error {
+				calls = append(calls, "pre:"+dbType)
+				return nil
+			},
+			Post: func(ctx context.Context, tx *sqlx.Tx, dbType string) error {
+				calls = append(calls, "post")
+				_, err := tx.ExecContext(ctx, "INSERT INTO hook_test (id, value) VALUES (1, 'backfilled')")
+				return err
+			},
+		},
+	}
+	require.NoError(t, r.applyMigrations(ctx, migrations))
+	require.Equal(t, []string{"pre:sqlite", "post"}, calls)
+
+	var value string
+	require.NoError(t, r.clienet.DB().Get(&value, "SELECT value FROM hook_test WHERE id = 1"))
+	require.Equal(t, "backfilled", value)
+
+	// applied migrations and their hooks do not run again
+	require.NoError(t, r.applyMigrations(ctx, migrations))
+	require.Len(t, calls, 2)
+}
+
+func TestRdsMigrationImpl_FailedPostHookRollsBack(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMigration(t)
+	require.NoError(t, r.RunMigrate(ctx))
+
+	err := r.applyMigrations(ctx, []rdsMigration{
+		{
+			Version: 1002,
+			Name:    "create_verified_table",
+			Sqlite:  "CREATE TABLE verified_table (id INTEGER PRIMARY KEY)",
+			Mysql:   "CREATE TABLE verified_table (id INTEGER PRIMARY KEY)",
+			Post: func(ctx context.Context, tx *sqlx.Tx, dbType string) error {
+				return errors.New("verification failed")
+			},
+		},
+	})
+	require.ErrorContains(t, err, "fail to run post hook of schema migration 1002")
+
+	var tables int
+	require.NoError(t, r.clienet.DB().Get(&tables, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'verified_table'"))
+	require.Zero(t, tables)
+	var recorded int
+	require.NoError(t, r.clienet.DB().Get(&recorded, "SELECT COUNT(*) FROM schema_migrations WHERE version = 1002"))
+	require.Zero(t, recorded)
+}
+
+func TestRdsMigration_BackfillToolSources(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMigration(t)
+	require.NoError(t, r.RunMigrate(ctx))
+	db := r.clienet.DB()
+
+	// tools written before the deduplication, two of them share a source
+	for i, source := range []string{"shared", "shared", "own"} {
+		_, err := db.Exec(`INSERT INTO tools (user_id, id, unique_id, name, namespace, category, is_activate, realtime_execution,
+			ui_widgets, source, description, extra_info, created_at, updated_at)
+			VALUES ('user-1', ?, ?, 'tool', 'ns', '', 1, 0, '[]', ?, '', '{}', ?, ?)`,
+			fmt.Sprintf("tool-%d", i), fmt.Sprintf("uid-%d", i), source, time.Now(), time.Now())
+		require.NoError(t, err)
+	}
+
+	tx, err := db.Beginx()
+	require.NoError(t, err)
+	require.NoError(t, backfillToolSources(ctx, tx, "sqlite"))
+	require.NoError(t, tx.Commit())
+
+	var refCount int
+	require.NoError(t, db.Get(&refCount, "SELECT ref_count FROM tool_sources WHERE hash = ?", entity.ToolSourceHash("shared")))
+	require.Equal(t, 2, refCount)
+	require.NoError(t, db.Get(&refCount, "SELECT ref_count FROM tool_sources WHERE hash = ?", entity.ToolSourceHash("own")))
+	require.Equal(t, 1, refCount)
+	var leftInTools int
+	require.NoError(t, db.Get(&leftInTools, "SELECT COUNT(*) FROM tools WHERE source != '' OR source_hash = ''"))
+	require.Zero(t, leftInTools)
+}
+
+func TestRdsMigration_BackfillToolVersions(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMigration(t)
+	require.NoError(t, r.RunMigrate(ctx))
+	db := r.clienet.DB()
+
+	// tools written before the version history, their source is already deduplicated
+	for i, source := range []string{"shared", "shared"} {
+		_, err := db.Exec(`INSERT INTO tools (user_id, id, unique_id, name, namespace, category, is_activate, realtime_execution,
+			ui_widgets, source, description, extra_info, created_at, updated_at)
+			VALUES ('user-1', ?, ?, 'tool', 'ns', '', 1, 0, '[]', ?, '', '{}', ?, ?)`,
+			fmt.Sprintf("tool-%d", i), fmt.Sprintf("uid-%d", i), source, time.Now(), time.Now())
+		require.NoError(t, err)
+	}
+	tx, err := db.Beginx()
+	require.NoError(t, err)
+	require.NoError(t, backfillToolSources(ctx, tx, "sqlite"))
+	require.NoError(t, backfillToolVersions(ctx, tx, "sqlite"))
+	require.NoError(t, tx.Commit())
+
+	var versions []struct {
+		ToolUniqueID string `db:"tool_unique_id"`
+		Version      int    `db:"version"`
+		SourceHash   string `db:"source_hash"`
+	}
+	require.NoError(t, db.Select(&versions, "SELECT tool_unique_id, version, source_hash FROM tool_versions ORDER BY tool_unique_id"))
+	require.Len(t, versions, 2)
+	for i, version := range versions {
+		require.Equal(t, fmt.Sprintf("uid-%d", i), version.ToolUniqueID)
+		require.Equal(t, 1, version.Version)
+		require.Equal(t, entity.ToolSourceHash("shared"), version.SourceHash)
+	}
+	// the source is referenced by both tools and both versions
+	var refCount int
+	require.NoError(t, db.Get(&refCount, "SELECT ref_count FROM tool_sources WHERE hash = ?", entity.ToolSourceHash("shared")))
+	require.Equal(t, 4, refCount)
+}
+
+func TestRdsMigration_BackfillToolTagsAndSearch(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMigration(t)
+	require.NoError(t, r.RunMigrate(ctx))
+	db := r.clienet.DB()
+
+	// a tool written before the tags and the search were indexed
+	_, err := db.Exec(`INSERT INTO tools (user_id, id, unique_id, name, namespace, category, is_activate, realtime_execution,
+		ui_widgets, source, description, extra_info, created_at, updated_at)
+		VALUES ('user-1', 'tool-1', 'uid-1', 'JSON Viewer', 'ns', '', 1, 0, '[]', 'return input', 'Shows a tree', '{"tags":"JSON, text"}', ?, ?)`,
+		time.Now(), time.Now())
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO tool_extra_info (user_id, tool_unique_id, info_key, info_value) VALUES ('user-1', 'uid-1', 'tags', 'JSON, text')")
+	require.NoError(t, err)
+
+	tx, err := db.Beginx()
+	require.NoError(t, err)
+	require.NoError(t, backfillToolTagsAndSearch(ctx, tx, "sqlite"))
+	require.NoError(t, tx.Commit())
+
+	var tags []string
+	require.NoError(t, db.Select(&tags, "SELECT tag FROM tool_tags WHERE user_id = 'user-1' AND tool_unique_id = 'uid-1' ORDER BY tag"))
+	require.Equal(t, []string{"json", "text"}, tags)
+	var found []string
+	require.NoError(t, db.Select(&found, "SELECT tool_unique_id FROM tool_search WHERE tool_search MATCH 'tree'"))
+	require.Equal(t, []string{"uid-1"}, found)
+}
+
+func TestRdsMigration_DedupeToolUniqueIDs(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMigration(t)
+	require.NoError(t, r.RunMigrate(ctx))
+	db := r.clienet.DB()
+
+	// tools written before the unique index, uid-1 is used by two users and twice by user-2
+	_, err := db.Exec("DROP INDEX idx_tools_unique_id_unique")
+	require.NoError(t, err)
+	created := time.Now()
+	for i, tool := range []struct{ userID, uid string }{{"user-1", "uid-1"}, {"user-2", "uid-1"}, {"user-2", "uid-1"}, {"user-2", "uid-2"}} {
+		_, err := db.Exec(`INSERT INTO tools (user_id, id, unique_id, name, namespace, category, is_activate, realtime_execution,
+			ui_widgets, source, description, extra_info, created_at, updated_at)
+			VALUES (?, ?, ?, 'tool', 'ns', '', 1, 0, '[]', '', '', '{}', ?, ?)`,
+			tool.userID, fmt.Sprintf("tool-%d", i), tool.uid, created.Add(time.Duration(i)*time.Second), created)
+		require.NoError(t, err)
+	}
+	_, err = db.Exec("INSERT INTO tool_extra_info (user_id, tool_unique_id, info_key, info_value) VALUES ('user-2', 'uid-1', 'key', 'value')")
+	require.NoError(t, err)
+
+	tx, err := db.Beginx()
+	require.NoError(t, err)
+	require.NoError(t, dedupeToolUniqueIDs(ctx, tx, "sqlite"))
+	require.NoError(t, tx.Commit())
+
+	uids := map[string]string{}
+	var tools []struct {
+		ID       string `db:"id"`
+		UniqueID string `db:"unique_id"`
+	}
+	require.NoError(t, db.Select(&tools, "SELECT id, unique_id FROM tools"))
+	for _, tool := range tools {
+		uids[tool.ID] = tool.UniqueID
+	}
+	// the oldest tool keeps the uid, the others get new ones, a uid used once is untouched
+	require.Equal(t, "uid-1", uids["tool-0"])
+	require.NotEqual(t, "uid-1", uids["tool-1"])
+	require.NotEqual(t, "uid-1", uids["tool-2"])
+	require.NotEqual(t, uids["tool-1"], uids["tool-2"])
+	require.Equal(t, "uid-2", uids["tool-3"])
+
+	// the rows of user-2 moved with its first tool, the devices of user-2 see the old uid and the new ones changed
+	var extraInfoUID string
+	require.NoError(t, db.Get(&extraInfoUID, "SELECT tool_unique_id FROM tool_extra_info WHERE user_id = 'user-2'"))
+	require.Equal(t, uids["tool-1"], extraInfoUID)
+	var changed []string
+	require.NoError(t, db.Select(&changed, "SELECT tool_unique_id FROM tool_changes WHERE user_id = 'user-2'"))
+	require.ElementsMatch(t, []string{"uid-1", uids["tool-1"], uids["tool-2"]}, changed)
+
+	_, err = db.Exec("CREATE UNIQUE INDEX idx_tools_unique_id_unique ON tools (unique_id)")
+	require.NoError(t, err)
+}
+
+func TestRdsMigrationImpl_Lock(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMigration(t)
+
+	release, err := r.acquireMigrationLock(ctx)
+	require.NoError(t, err)
+
+	// a second instance gives up after the lock timeout, running migrations fails too
+	_, err = r.acquireMigrationLock(ctx)
+	require.ErrorContains(t, err, "another instance is running migrations")
+	require.Error(t, r.RunMigrate(ctx))
+
+	release()
+	require.NoError(t, r.RunMigrate(ctx))
+
+	// a lock left behind by a crashed instance expires
+	_, err = r.clienet.DB().Exec("INSERT INTO schema_migration_lock (id, owner, locked_at) VALUES (1, 'crashed', ?)", time.Now().Add(-2*migrationLockStaleAfter))
+	require.NoError(t, err)
+	release, err = r.acquireMigrationLock(ctx)
+	require.NoError(t, err)
+	release()
+}
+
+func TestRdsMigrationImpl_SchemaVersion(t *testing.T) {
+	ctx := context.Background()
+	r := newTestMigration(t)
+
+	// schema_migrations does not exist before the first migration
+	_, err := r.SchemaVersion(ctx)
+	require.Error(t, err)
+
+	require.NoError(t, r.RunMigrate(ctx))
+	version, err := r.SchemaVersion(ctx)
+	require.NoError(t, err)
+	require.Equal(t, rdsMigrations[len(rdsMigrations)-1].Version, version.LatestVersion)
+	require.Equal(t, version.LatestVersion, version.AppliedVersion)
+	require.False(t, version.AppliedAt.IsZero())
+	require.True(t, version.UpToDate())
+
+	// a database migrated by a newer release is reported as not matching this build
+	require.NoError(t, r.applyMigrations(ctx, []rdsMigration{
+		{Version: 1003, Name: "newer_release", Sqlite: "CREATE TABLE newer_release (id INTEGER PRIMARY KEY)", Mysql: "CREATE TABLE newer_release (id INTEGER PRIMARY KEY)"},
+	}))
+	version, err = r.SchemaVersion(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1003, version.AppliedVersion)
+	require.False(t, version.UpToDate())
+}
@@ -0,0 +1,1296 @@
+package migration
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// rdsMigrationHook runs Go code as part of a migration, such as a data backfill or a verification query.
+// It shares the transaction of the migration, an error rolls the migration back. MySQL commits DDL
+// implicitly, so there only the hook's own statements are rolled back.
+type rdsMigrationHook func(ctx context.Context, tx *sqlx.Tx, dbType string) error
+
+// rdsMigration is a schema change applied once on top of the base schema and recorded in schema_migrations.
+// Versions must be unique and increasing, never edit a migration that has been released, add a new one instead.
+//
+// Migrations must stay compatible with the previous release running next to the new one during a rolling
+// deploy: add columns and tables, backfill in Post, and drop or rename only in a later release once nothing
+// reads the old shape. TestRdsMigrationsAreZeroDowntime rejects destructive statements unless
+// AllowDestructive explains why it is safe.
+//
+// Postgres is the statement for DB_TYPE=postgres, its timestamps are TIMESTAMPTZ like in postgresSchema.
+type rdsMigration struct {
+	Version  int
+	Name     string
+	Sqlite   string
+	Mysql    string
+	Postgres string
+
+	// Pre runs before the statement, Post after it, both are optional
+	Pre  rdsMigrationHook
+	Post rdsMigrationHook
+
+	// AllowDestructive is the reason a DROP or RENAME in this migration can not break the running release
+	AllowDestructive string
+}
+
+var rdsMigrations = []rdsMigration{
+	{
+		Version: 1,
+		Name:    "create_tool_extra_info",
+		// tool_extra_info indexes the keys of tools.extra_info so tools can be filtered by them,
+		// existing tools are backfilled from the json column.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS tool_extra_info (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	info_key VARCHAR(255) NOT NULL,
+	info_value TEXT NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id, info_key)
+);
+CREATE INDEX IF NOT EXISTS idx_tool_extra_info_key_value ON tool_extra_info (user_id, info_key, info_value);
+
+INSERT INTO tool_extra_info (user_id, tool_unique_id, info_key, info_value)
+SELECT t.user_id, t.unique_id, j.key, CAST(j.value AS TEXT)
+FROM tools t, json_each(t.extra_info) j
+WHERE json_valid(t.extra_info) AND j.type = 'text'
+ON CONFLICT DO NOTHING;
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS tool_extra_info (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	info_key VARCHAR(255) NOT NULL,
+	info_value TEXT NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id, info_key),
+	INDEX idx_tool_extra_info_key_value (user_id, info_key, info_value(191))
+);
+
+INSERT IGNORE INTO tool_extra_info (user_id, tool_unique_id, info_key, info_value)
+SELECT t.user_id, t.unique_id, k.info_key, JSON_UNQUOTE(JSON_EXTRACT(t.extra_info, CONCAT('$."', k.info_key, '"')))
+FROM tools t,
+	JSON_TABLE(JSON_KEYS(t.extra_info), '$[*]' COLUMNS (info_key VARCHAR(255) PATH '$')) k
+WHERE JSON_VALID(t.extra_info);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS tool_extra_info (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	info_key VARCHAR(255) NOT NULL,
+	info_value TEXT NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id, info_key)
+);
+CREATE INDEX IF NOT EXISTS idx_tool_extra_info_key_value ON tool_extra_info (user_id, info_key, info_value);
+
+INSERT INTO tool_extra_info (user_id, tool_unique_id, info_key, info_value)
+SELECT t.user_id, t.unique_id, j.key, j.value #>> '{}'
+FROM tools t, jsonb_each(t.extra_info::jsonb) j
+WHERE jsonb_typeof(j.value) = 'string'
+ON CONFLICT DO NOTHING;
+`,
+	},
+	{
+		Version:  2,
+		Name:     "add_tools_is_archived",
+		Sqlite:   `ALTER TABLE tools ADD COLUMN is_archived BOOLEAN NOT NULL DEFAULT 0;`,
+		Mysql:    `ALTER TABLE tools ADD COLUMN is_archived BOOLEAN NOT NULL DEFAULT FALSE;`,
+		Postgres: `ALTER TABLE tools ADD COLUMN is_archived BOOLEAN NOT NULL DEFAULT FALSE;`,
+	},
+	{
+		Version: 3,
+		Name:    "create_system_settings",
+		// system_settings holds the runtime settings edited by admins, env config stays the default of every key.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS system_settings (
+	setting_key VARCHAR(255) PRIMARY KEY,
+	setting_value TEXT NOT NULL,
+	updated_by VARCHAR(255) NOT NULL DEFAULT '',
+	updated_at TIMESTAMP NOT NULL
+);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS system_settings (
+	setting_key VARCHAR(255) PRIMARY KEY,
+	setting_value TEXT NOT NULL,
+	updated_by VARCHAR(255) NOT NULL DEFAULT '',
+	updated_at TIMESTAMP NOT NULL
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS system_settings (
+	setting_key VARCHAR(255) PRIMARY KEY,
+	setting_value TEXT NOT NULL,
+	updated_by VARCHAR(255) NOT NULL DEFAULT '',
+	updated_at TIMESTAMPTZ NOT NULL
+);
+`,
+	},
+	{
+		Version: 4,
+		Name:    "create_announcements",
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS announcements (
+	id VARCHAR(64) PRIMARY KEY,
+	message TEXT NOT NULL,
+	severity VARCHAR(32) NOT NULL,
+	starts_at TIMESTAMP NULL,
+	ends_at TIMESTAMP NULL,
+	dismissible BOOLEAN NOT NULL DEFAULT 1,
+	created_by VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS announcements (
+	id VARCHAR(64) PRIMARY KEY,
+	message TEXT NOT NULL,
+	severity VARCHAR(32) NOT NULL,
+	starts_at TIMESTAMP NULL,
+	ends_at TIMESTAMP NULL,
+	dismissible BOOLEAN NOT NULL DEFAULT TRUE,
+	created_by VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS announcements (
+	id VARCHAR(64) PRIMARY KEY,
+	message TEXT NOT NULL,
+	severity VARCHAR(32) NOT NULL,
+	starts_at TIMESTAMPTZ NULL,
+	ends_at TIMESTAMPTZ NULL,
+	dismissible BOOLEAN NOT NULL DEFAULT TRUE,
+	created_by VARCHAR(255) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+`,
+	},
+	{
+		Version: 5,
+		Name:    "create_audit_logs",
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS audit_logs (
+	id VARCHAR(64) PRIMARY KEY,
+	actor_id VARCHAR(255) NOT NULL,
+	method VARCHAR(16) NOT NULL,
+	route VARCHAR(255) NOT NULL,
+	path VARCHAR(1024) NOT NULL,
+	payload TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	outcome VARCHAR(16) NOT NULL,
+	request_id VARCHAR(64) NOT NULL,
+	client_ip VARCHAR(64) NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS audit_logs (
+	id VARCHAR(64) PRIMARY KEY,
+	actor_id VARCHAR(255) NOT NULL,
+	method VARCHAR(16) NOT NULL,
+	route VARCHAR(255) NOT NULL,
+	path VARCHAR(1024) NOT NULL,
+	payload TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	outcome VARCHAR(16) NOT NULL,
+	request_id VARCHAR(64) NOT NULL,
+	client_ip VARCHAR(64) NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	INDEX idx_audit_logs_created_at (created_at),
+	INDEX idx_audit_logs_actor_id (actor_id)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS audit_logs (
+	id VARCHAR(64) PRIMARY KEY,
+	actor_id VARCHAR(255) NOT NULL,
+	method VARCHAR(16) NOT NULL,
+	route VARCHAR(255) NOT NULL,
+	path VARCHAR(1024) NOT NULL,
+	payload TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	outcome VARCHAR(16) NOT NULL,
+	request_id VARCHAR(64) NOT NULL,
+	client_ip VARCHAR(64) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+`,
+	}, {
+		Version: 6,
+		Name:    "add_users_login_stats",
+		// last_login_at stays NULL for users that never logged in since the column was added
+		Sqlite: `
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN login_count INTEGER NOT NULL DEFAULT 0;
+`,
+		Mysql: `
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMP NULL;
+ALTER TABLE users ADD COLUMN login_count INTEGER NOT NULL DEFAULT 0;
+`,
+		Postgres: `
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMPTZ NULL;
+ALTER TABLE users ADD COLUMN login_count INTEGER NOT NULL DEFAULT 0;
+`,
+	}, {
+		Version: 7,
+		Name:    "create_tool_changes_and_user_devices",
+		// tool_changes keeps the sequence number of the latest change of every tool, rows of deleted tools stay as
+		// tombstones. Devices sync by sequence number instead of time, so client clock skew does not matter.
+		// Existing tools are backfilled so a new device receives all of them.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS tool_changes (
+	seq INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	changed_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tool_changes_user_seq ON tool_changes (user_id, seq);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tool_changes_user_tool ON tool_changes (user_id, tool_unique_id);
+
+INSERT INTO tool_changes (user_id, tool_unique_id, changed_at)
+SELECT user_id, unique_id, updated_at FROM tools ORDER BY updated_at;
+
+CREATE TABLE IF NOT EXISTS user_devices (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	sync_cursor BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL,
+	last_seen_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_user_devices_user_id ON user_devices (user_id);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS tool_changes (
+	seq BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	changed_at TIMESTAMP NOT NULL,
+	INDEX idx_tool_changes_user_seq (user_id, seq),
+	UNIQUE INDEX idx_tool_changes_user_tool (user_id, tool_unique_id)
+);
+
+INSERT INTO tool_changes (user_id, tool_unique_id, changed_at)
+SELECT user_id, unique_id, updated_at FROM tools ORDER BY updated_at;
+
+CREATE TABLE IF NOT EXISTS user_devices (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	sync_cursor BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL,
+	last_seen_at TIMESTAMP NOT NULL,
+	INDEX idx_user_devices_user_id (user_id)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS tool_changes (
+	seq BIGSERIAL PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	changed_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tool_changes_user_seq ON tool_changes (user_id, seq);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tool_changes_user_tool ON tool_changes (user_id, tool_unique_id);
+
+INSERT INTO tool_changes (user_id, tool_unique_id, changed_at)
+SELECT user_id, unique_id, updated_at FROM tools ORDER BY updated_at;
+
+CREATE TABLE IF NOT EXISTS user_devices (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	sync_cursor BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL,
+	last_seen_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_user_devices_user_id ON user_devices (user_id);
+`,
+	}, {
+		Version: 8,
+		Name:    "add_users_preferred_2fa_method",
+		// NULL means the user did not pick a method, the first enabled method is used
+		Sqlite: `
+ALTER TABLE users ADD COLUMN preferred_2fa_method VARCHAR(32) NULL;
+`,
+		Mysql: `
+ALTER TABLE users ADD COLUMN preferred_2fa_method VARCHAR(32) NULL;
+`,
+		Postgres: `
+ALTER TABLE users ADD COLUMN preferred_2fa_method VARCHAR(32) NULL;
+`,
+	}, {
+		Version: 9,
+		Name:    "add_user_sso_provider_access_token",
+		// the provider api token of the binding, NULL for the bindings made before it was kept and for google
+		Sqlite: `
+ALTER TABLE user_sso ADD COLUMN provider_access_token TEXT NULL;
+`,
+		Mysql: `
+ALTER TABLE user_sso ADD COLUMN provider_access_token TEXT NULL;
+`,
+		Postgres: `
+ALTER TABLE user_sso ADD COLUMN provider_access_token TEXT NULL;
+`,
+	}, {
+		Version: 10,
+		Name:    "create_user_usage",
+		// one row per user, metric and month, kept when the user is deleted so the usage can still be billed
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS user_usage (
+	user_id VARCHAR(255) NOT NULL,
+	metric VARCHAR(64) NOT NULL,
+	period VARCHAR(7) NOT NULL,
+	amount BIGINT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, metric, period)
+);
+CREATE INDEX IF NOT EXISTS idx_user_usage_period ON user_usage (period);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS user_usage (
+	user_id VARCHAR(255) NOT NULL,
+	metric VARCHAR(64) NOT NULL,
+	period VARCHAR(7) NOT NULL,
+	amount BIGINT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, metric, period),
+	INDEX idx_user_usage_period (period)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS user_usage (
+	user_id VARCHAR(255) NOT NULL,
+	metric VARCHAR(64) NOT NULL,
+	period VARCHAR(7) NOT NULL,
+	amount BIGINT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (user_id, metric, period)
+);
+CREATE INDEX IF NOT EXISTS idx_user_usage_period ON user_usage (period);
+`,
+	}, {
+		Version: 11,
+		Name:    "add_users_password_change_required",
+		// set by an admin, cleared when the user sets a new password
+		Sqlite:   `ALTER TABLE users ADD COLUMN password_change_required BOOLEAN NOT NULL DEFAULT 0;`,
+		Mysql:    `ALTER TABLE users ADD COLUMN password_change_required BOOLEAN NOT NULL DEFAULT FALSE;`,
+		Postgres: `ALTER TABLE users ADD COLUMN password_change_required BOOLEAN NOT NULL DEFAULT FALSE;`,
+	}, {
+		Version: 12,
+		Name:    "create_tool_secrets",
+		// tool_unique_id is empty for an account level secret, the value is encrypted with the user's encrypt_key
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS tool_secrets (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL DEFAULT '',
+	name VARCHAR(64) NOT NULL,
+	encrypted_value TEXT NOT NULL,
+	version INTEGER NOT NULL DEFAULT 1,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id, name)
+);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS tool_secrets (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL DEFAULT '',
+	name VARCHAR(64) NOT NULL,
+	encrypted_value TEXT NOT NULL,
+	version INT NOT NULL DEFAULT 1,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id, name)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS tool_secrets (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL DEFAULT '',
+	name VARCHAR(64) NOT NULL,
+	encrypted_value TEXT NOT NULL,
+	version INTEGER NOT NULL DEFAULT 1,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id, name)
+);
+`,
+	}, {
+		Version: 13,
+		Name:    "create_account_recovery_requests",
+		// token_hash is the sha256 of the recovery token, decided_by the admin who approved or rejected, or the user who cancelled
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS account_recovery_requests (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	token_hash VARCHAR(64) NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	available_at TIMESTAMP NOT NULL,
+	decided_by VARCHAR(255),
+	updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_account_recovery_requests_user_id ON account_recovery_requests (user_id);
+CREATE INDEX IF NOT EXISTS idx_account_recovery_requests_status ON account_recovery_requests (status, created_at);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS account_recovery_requests (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	token_hash VARCHAR(64) NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	available_at TIMESTAMP NOT NULL,
+	decided_by VARCHAR(255) NULL,
+	updated_at TIMESTAMP NOT NULL,
+	INDEX idx_account_recovery_requests_user_id (user_id),
+	INDEX idx_account_recovery_requests_status (status, created_at)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS account_recovery_requests (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	token_hash VARCHAR(64) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	available_at TIMESTAMPTZ NOT NULL,
+	decided_by VARCHAR(255) NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_account_recovery_requests_user_id ON account_recovery_requests (user_id);
+CREATE INDEX IF NOT EXISTS idx_account_recovery_requests_status ON account_recovery_requests (status, created_at);
+`,
+	}, {
+		Version: 14,
+		Name:    "create_tool_sources",
+		// tool sources are stored once per sha256 in tool_sources, ref_count is the number of tools using one.
+		// Existing sources are moved there by backfillToolSources, tools.source stays empty for the moved rows.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS tool_sources (
+	hash VARCHAR(64) PRIMARY KEY,
+	source TEXT NOT NULL,
+	ref_count BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL
+);
+ALTER TABLE tools ADD COLUMN source_hash VARCHAR(64) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_tools_source_hash ON tools (source_hash);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS tool_sources (
+	hash VARCHAR(64) PRIMARY KEY,
+	source TEXT NOT NULL,
+	ref_count BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL
+);
+ALTER TABLE tools ADD COLUMN source_hash VARCHAR(64) NOT NULL DEFAULT '';
+CREATE INDEX idx_tools_source_hash ON tools (source_hash);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS tool_sources (
+	hash VARCHAR(64) PRIMARY KEY,
+	source TEXT NOT NULL,
+	ref_count BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL
+);
+ALTER TABLE tools ADD COLUMN source_hash VARCHAR(64) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_tools_source_hash ON tools (source_hash);
+`,
+		Post: backfillToolSources,
+	}, {
+		Version: 15,
+		Name:    "create_oidc_provider_tables",
+		// oidc_clients are the apps signing their users in with ToolBake, secret_hash is the sha256 of the client secret
+		// and redirect_uris a json array. oidc_signing_keys keeps the RSA keys the id tokens are signed with, as PEM.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS oidc_clients (
+	id VARCHAR(64) PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	secret_hash VARCHAR(64) NOT NULL,
+	redirect_uris TEXT NOT NULL,
+	created_by VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS oidc_signing_keys (
+	kid VARCHAR(64) PRIMARY KEY,
+	private_key TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS oidc_clients (
+	id VARCHAR(64) PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	secret_hash VARCHAR(64) NOT NULL,
+	redirect_uris TEXT NOT NULL,
+	created_by VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS oidc_signing_keys (
+	kid VARCHAR(64) PRIMARY KEY,
+	private_key TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS oidc_clients (
+	id VARCHAR(64) PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	secret_hash VARCHAR(64) NOT NULL,
+	redirect_uris TEXT NOT NULL,
+	created_by VARCHAR(255) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+CREATE TABLE IF NOT EXISTS oidc_signing_keys (
+	kid VARCHAR(64) PRIMARY KEY,
+	private_key TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+`,
+	},
+	{
+		Version: 16,
+		Name:    "create_user_recovery_codes",
+		// the single-use recovery codes of the current set of a user, code_hash is the sha256 of the normalized code
+		// and used_at is set once the code was used. Regenerating the set replaces all rows of the user.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id VARCHAR(255) NOT NULL,
+	code_hash VARCHAR(64) NOT NULL,
+	used_at TIMESTAMP NULL,
+	created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user_id ON user_recovery_codes (user_id);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	code_hash VARCHAR(64) NOT NULL,
+	used_at TIMESTAMP NULL,
+	created_at TIMESTAMP NOT NULL,
+	INDEX idx_user_recovery_codes_user_id (user_id)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+	id BIGSERIAL PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	code_hash VARCHAR(64) NOT NULL,
+	used_at TIMESTAMPTZ NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user_id ON user_recovery_codes (user_id);
+`,
+	},
+	{
+		Version: 17,
+		Name:    "create_tool_versions",
+		// tool_versions keeps the saved states of the source and ui widgets of every tool, numbered from 1 per tool.
+		// The source is a reference to tool_sources like the one of the tool, so ref_count counts the versions too.
+		// Existing tools get their current state as version 1 in backfillToolVersions. A tool deleted by the previous
+		// release keeps the references of its versions, its sources are only left behind in tool_sources.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS tool_versions (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	version INTEGER NOT NULL,
+	source_hash VARCHAR(64) NOT NULL,
+	ui_widgets TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id, version)
+);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS tool_versions (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	version INTEGER NOT NULL,
+	source_hash VARCHAR(64) NOT NULL,
+	ui_widgets TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id, version)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS tool_versions (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	version INTEGER NOT NULL,
+	source_hash VARCHAR(64) NOT NULL,
+	ui_widgets TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id, version)
+);
+`,
+		Post: backfillToolVersions,
+	},
+	{
+		Version: 18,
+		Name:    "create_namespaces",
+		// namespaces keeps the description and the defaults of new tools per tool namespace. The tools still name
+		// their namespace themselves, a namespace only gets a row once its settings are saved.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS namespaces (
+	user_id VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	description TEXT NOT NULL,
+	default_category VARCHAR(255) NOT NULL,
+	default_realtime_execution BOOLEAN NOT NULL,
+	default_ui_widgets TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, name)
+);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS namespaces (
+	user_id VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	description TEXT NOT NULL,
+	default_category VARCHAR(255) NOT NULL,
+	default_realtime_execution BOOLEAN NOT NULL,
+	default_ui_widgets TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, name)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS namespaces (
+	user_id VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	description TEXT NOT NULL,
+	default_category VARCHAR(255) NOT NULL,
+	default_realtime_execution BOOLEAN NOT NULL,
+	default_ui_widgets TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (user_id, name)
+);
+`,
+	},
+	{
+		Version: 19,
+		Name:    "create_shared_tools",
+		// shared_tools grants another user read or fork access to a tool, an empty grantee_id is the public link of
+		// the tool whose id is the link token. A tool has at most one share per grantee.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS shared_tools (
+	id VARCHAR(64) NOT NULL PRIMARY KEY,
+	owner_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	grantee_id VARCHAR(255) NOT NULL,
+	permission VARCHAR(16) NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	UNIQUE (owner_id, tool_unique_id, grantee_id)
+);
+CREATE INDEX IF NOT EXISTS idx_shared_tools_grantee_id ON shared_tools (grantee_id);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS shared_tools (
+	id VARCHAR(64) NOT NULL PRIMARY KEY,
+	owner_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	grantee_id VARCHAR(255) NOT NULL,
+	permission VARCHAR(16) NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	UNIQUE (owner_id, tool_unique_id, grantee_id),
+	INDEX idx_shared_tools_grantee_id (grantee_id)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS shared_tools (
+	id VARCHAR(64) NOT NULL PRIMARY KEY,
+	owner_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	grantee_id VARCHAR(255) NOT NULL,
+	permission VARCHAR(16) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	UNIQUE (owner_id, tool_unique_id, grantee_id)
+);
+CREATE INDEX IF NOT EXISTS idx_shared_tools_grantee_id ON shared_tools (grantee_id);
+`,
+	},
+	{
+		Version: 20,
+		Name:    "create_gallery_tools",
+		// gallery_tools lists the tools their owners published to the public gallery, with how often other users
+		// installed or forked them. Tool unique ids are global, so they key the gallery too.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS gallery_tools (
+	tool_unique_id VARCHAR(255) NOT NULL PRIMARY KEY,
+	owner_id VARCHAR(255) NOT NULL,
+	install_count BIGINT NOT NULL DEFAULT 0,
+	fork_count BIGINT NOT NULL DEFAULT 0,
+	published_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_gallery_tools_owner_id ON gallery_tools (owner_id);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS gallery_tools (
+	tool_unique_id VARCHAR(255) NOT NULL PRIMARY KEY,
+	owner_id VARCHAR(255) NOT NULL,
+	install_count BIGINT NOT NULL DEFAULT 0,
+	fork_count BIGINT NOT NULL DEFAULT 0,
+	published_at TIMESTAMP NOT NULL,
+	INDEX idx_gallery_tools_owner_id (owner_id)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS gallery_tools (
+	tool_unique_id VARCHAR(255) NOT NULL PRIMARY KEY,
+	owner_id VARCHAR(255) NOT NULL,
+	install_count BIGINT NOT NULL DEFAULT 0,
+	fork_count BIGINT NOT NULL DEFAULT 0,
+	published_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_gallery_tools_owner_id ON gallery_tools (owner_id);
+`,
+	}, {
+		Version: 21,
+		Name:    "add_users_disabled",
+		// set and cleared by an admin, a disabled user can not log in
+		Sqlite:   `ALTER TABLE users ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT 0;`,
+		Mysql:    `ALTER TABLE users ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT FALSE;`,
+		Postgres: `ALTER TABLE users ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT FALSE;`,
+	},
+	{
+		Version: 22,
+		Name:    "create_user_settings",
+		// user_settings holds the preferences of a user, a key the user did not set uses its default.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS user_settings (
+	user_id VARCHAR(255) NOT NULL,
+	setting_key VARCHAR(255) NOT NULL,
+	setting_value TEXT NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, setting_key)
+);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS user_settings (
+	user_id VARCHAR(255) NOT NULL,
+	setting_key VARCHAR(255) NOT NULL,
+	setting_value TEXT NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (user_id, setting_key)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS user_settings (
+	user_id VARCHAR(255) NOT NULL,
+	setting_key VARCHAR(255) NOT NULL,
+	setting_value TEXT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (user_id, setting_key)
+);
+`,
+	},
+	{
+		Version: 23,
+		Name:    "create_tool_events",
+		// tool_events is the append-only log of the tool changes, written in the transaction of the change.
+		// changed_fields is a JSON array of the fields an update changed.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS tool_events (
+	seq INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	event_type VARCHAR(32) NOT NULL,
+	actor_id VARCHAR(255) NOT NULL,
+	device_id VARCHAR(64) NOT NULL,
+	request_id VARCHAR(64) NOT NULL,
+	changed_fields TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tool_events_user_seq ON tool_events (user_id, seq);
+CREATE INDEX IF NOT EXISTS idx_tool_events_user_tool_seq ON tool_events (user_id, tool_unique_id, seq);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS tool_events (
+	seq BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	event_type VARCHAR(32) NOT NULL,
+	actor_id VARCHAR(255) NOT NULL,
+	device_id VARCHAR(64) NOT NULL,
+	request_id VARCHAR(64) NOT NULL,
+	changed_fields TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	INDEX idx_tool_events_user_seq (user_id, seq),
+	INDEX idx_tool_events_user_tool_seq (user_id, tool_unique_id, seq)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS tool_events (
+	seq BIGSERIAL PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	event_type VARCHAR(32) NOT NULL,
+	actor_id VARCHAR(255) NOT NULL,
+	device_id VARCHAR(64) NOT NULL,
+	request_id VARCHAR(64) NOT NULL,
+	changed_fields TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tool_events_user_seq ON tool_events (user_id, seq);
+CREATE INDEX IF NOT EXISTS idx_tool_events_user_tool_seq ON tool_events (user_id, tool_unique_id, seq);
+`,
+	},
+	{
+		Version: 24,
+		Name:    "unique_tool_unique_ids",
+		// a new tool is checked for a free unique id, the index refuses a duplicate created at the same time.
+		// Tools sharing a unique id get new ones first, the oldest of them keeps it.
+		Pre:      dedupeToolUniqueIDs,
+		Sqlite:   `CREATE UNIQUE INDEX IF NOT EXISTS idx_tools_unique_id_unique ON tools (unique_id);`,
+		Mysql:    `CREATE UNIQUE INDEX idx_tools_unique_id_unique ON tools (unique_id);`,
+		Postgres: `CREATE UNIQUE INDEX IF NOT EXISTS idx_tools_unique_id_unique ON tools (unique_id);`,
+	},
+	{
+		Version: 25,
+		Name:    "create_tool_schedules_and_runs",
+		// tool_schedules run a tool on the server on a cron expression with stored JSON inputs, next_run_at is
+		// NULL while a schedule is disabled. tool_runs keeps the latest results of every schedule, outputs and logs
+		// are JSON. In mysql the JSON columns are MEDIUMTEXT, the outputs of a run may be larger than 64 KiB.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS tool_schedules (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	cron_expr VARCHAR(255) NOT NULL,
+	inputs TEXT NOT NULL,
+	enabled BOOLEAN NOT NULL,
+	next_run_at TIMESTAMP NULL,
+	last_run_at TIMESTAMP NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tool_schedules_user_tool ON tool_schedules (user_id, tool_unique_id);
+CREATE INDEX IF NOT EXISTS idx_tool_schedules_due ON tool_schedules (enabled, next_run_at);
+CREATE TABLE IF NOT EXISTS tool_runs (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	schedule_id VARCHAR(64) NOT NULL,
+	status VARCHAR(32) NOT NULL,
+	outputs TEXT NOT NULL,
+	logs TEXT NOT NULL,
+	error_code VARCHAR(64) NOT NULL,
+	error_message TEXT NOT NULL,
+	started_at TIMESTAMP NOT NULL,
+	duration_ms BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tool_runs_schedule ON tool_runs (schedule_id, started_at);
+CREATE INDEX IF NOT EXISTS idx_tool_runs_user_tool ON tool_runs (user_id, tool_unique_id);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS tool_schedules (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	cron_expr VARCHAR(255) NOT NULL,
+	inputs MEDIUMTEXT NOT NULL,
+	enabled BOOLEAN NOT NULL,
+	next_run_at TIMESTAMP NULL,
+	last_run_at TIMESTAMP NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_tool_schedules_user_tool ON tool_schedules (user_id, tool_unique_id);
+CREATE INDEX idx_tool_schedules_due ON tool_schedules (enabled, next_run_at);
+CREATE TABLE IF NOT EXISTS tool_runs (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	schedule_id VARCHAR(64) NOT NULL,
+	status VARCHAR(32) NOT NULL,
+	outputs MEDIUMTEXT NOT NULL,
+	logs MEDIUMTEXT NOT NULL,
+	error_code VARCHAR(64) NOT NULL,
+	error_message TEXT NOT NULL,
+	started_at TIMESTAMP NOT NULL,
+	duration_ms BIGINT NOT NULL
+);
+CREATE INDEX idx_tool_runs_schedule ON tool_runs (schedule_id, started_at);
+CREATE INDEX idx_tool_runs_user_tool ON tool_runs (user_id, tool_unique_id);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS tool_schedules (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	cron_expr VARCHAR(255) NOT NULL,
+	inputs TEXT NOT NULL,
+	enabled BOOLEAN NOT NULL,
+	next_run_at TIMESTAMPTZ NULL,
+	last_run_at TIMESTAMPTZ NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tool_schedules_user_tool ON tool_schedules (user_id, tool_unique_id);
+CREATE INDEX IF NOT EXISTS idx_tool_schedules_due ON tool_schedules (enabled, next_run_at);
+CREATE TABLE IF NOT EXISTS tool_runs (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	schedule_id VARCHAR(64) NOT NULL,
+	status VARCHAR(32) NOT NULL,
+	outputs TEXT NOT NULL,
+	logs TEXT NOT NULL,
+	error_code VARCHAR(64) NOT NULL,
+	error_message TEXT NOT NULL,
+	started_at TIMESTAMPTZ NOT NULL,
+	duration_ms BIGINT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_tool_runs_schedule ON tool_runs (schedule_id, started_at);
+CREATE INDEX IF NOT EXISTS idx_tool_runs_user_tool ON tool_runs (user_id, tool_unique_id);
+`,
+	},
+	{
+		Version: 26,
+		Name:    "create_tool_tags_and_search",
+		// tool_tags indexes the tags extra info of the tools, one row per tag. tool_search holds the name, description
+		// and source of every tool for the full text search: an fts5 table in sqlite, FULLTEXT indexes in mysql and a
+		// weighted tsvector in postgres. The rows of the existing tools are filled in by the post hook.
+		Post: backfillToolTagsAndSearch,
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS tool_tags (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	tag VARCHAR(255) NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id, tag)
+);
+CREATE INDEX IF NOT EXISTS idx_tool_tags_tag ON tool_tags (user_id, tag);
+CREATE VIRTUAL TABLE IF NOT EXISTS tool_search USING fts5(
+	user_id UNINDEXED,
+	tool_unique_id UNINDEXED,
+	name,
+	description,
+	source
+);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS tool_tags (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	tag VARCHAR(255) NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id, tag),
+	INDEX idx_tool_tags_tag (user_id, tag)
+);
+CREATE TABLE IF NOT EXISTS tool_search (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	description TEXT NOT NULL,
+	source MEDIUMTEXT NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id),
+	FULLTEXT INDEX idx_tool_search_text (name, description, source),
+	FULLTEXT INDEX idx_tool_search_name (name),
+	FULLTEXT INDEX idx_tool_search_description (description)
+);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS tool_tags (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	tag VARCHAR(255) NOT NULL,
+	PRIMARY KEY (user_id, tool_unique_id, tag)
+);
+CREATE INDEX IF NOT EXISTS idx_tool_tags_tag ON tool_tags (user_id, tag);
+CREATE TABLE IF NOT EXISTS tool_search (
+	user_id VARCHAR(255) NOT NULL,
+	tool_unique_id VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	description TEXT NOT NULL,
+	source TEXT NOT NULL,
+	document TSVECTOR GENERATED ALWAYS AS (
+		setweight(to_tsvector('simple', name), 'A') ||
+		setweight(to_tsvector('simple', description), 'B') ||
+		setweight(to_tsvector('simple', source), 'C')
+	) STORED,
+	PRIMARY KEY (user_id, tool_unique_id)
+);
+CREATE INDEX IF NOT EXISTS idx_tool_search_document ON tool_search USING GIN (document);
+`,
+	},
+	{
+		Version: 27,
+		Name:    "add_source_encryption_keys",
+		// source_key and script_key are the data key of the source or script sealed with the encrypt key of the owner,
+		// empty for the rows written before. tool_sources.encrypted tells a sealed source from a plaintext one. In
+		// mysql the sealed text is a third larger than the plaintext, so the columns grow to MEDIUMTEXT.
+		AllowDestructive: "widening TEXT to MEDIUMTEXT keeps every value, the running release reads them the same",
+		Sqlite: `
+ALTER TABLE tools ADD COLUMN source_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE tool_versions ADD COLUMN source_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE tool_sources ADD COLUMN encrypted BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE global_scripts ADD COLUMN script_key VARCHAR(255) NOT NULL DEFAULT '';
+`,
+		Mysql: `
+ALTER TABLE tools ADD COLUMN source_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE tool_versions ADD COLUMN source_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE tool_sources ADD COLUMN encrypted BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tool_sources MODIFY COLUMN source MEDIUMTEXT NOT NULL;
+ALTER TABLE global_scripts ADD COLUMN script_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE global_scripts MODIFY COLUMN script MEDIUMTEXT NOT NULL;
+`,
+		Postgres: `
+ALTER TABLE tools ADD COLUMN source_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE tool_versions ADD COLUMN source_key VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE tool_sources ADD COLUMN encrypted BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE global_scripts ADD COLUMN script_key VARCHAR(255) NOT NULL DEFAULT '';
+`,
+	},
+	{
+		Version: 28,
+		Name:    "create_user_data_exports",
+		// user_data_exports holds the data exports users requested, archive is the JSON archive once it is built,
+		// sealed with a data key when archive_key is not empty. In mysql the archive is LONGTEXT, it holds every
+		// tool of the user.
+		Sqlite: `
+CREATE TABLE IF NOT EXISTS user_data_exports (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	status VARCHAR(32) NOT NULL,
+	progress INTEGER NOT NULL,
+	total INTEGER NOT NULL,
+	error TEXT NOT NULL,
+	archive TEXT NOT NULL,
+	archive_key VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	finished_at TIMESTAMP NULL
+);
+CREATE INDEX IF NOT EXISTS idx_user_data_exports_user ON user_data_exports (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_user_data_exports_status ON user_data_exports (status, updated_at);
+`,
+		Mysql: `
+CREATE TABLE IF NOT EXISTS user_data_exports (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	status VARCHAR(32) NOT NULL,
+	progress INTEGER NOT NULL,
+	total INTEGER NOT NULL,
+	error TEXT NOT NULL,
+	archive LONGTEXT NOT NULL,
+	archive_key VARCHAR(255) NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	finished_at TIMESTAMP NULL
+);
+CREATE INDEX idx_user_data_exports_user ON user_data_exports (user_id, created_at);
+CREATE INDEX idx_user_data_exports_status ON user_data_exports (status, updated_at);
+`,
+		Postgres: `
+CREATE TABLE IF NOT EXISTS user_data_exports (
+	id VARCHAR(64) PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	status VARCHAR(32) NOT NULL,
+	progress INTEGER NOT NULL,
+	total INTEGER NOT NULL,
+	error TEXT NOT NULL,
+	archive TEXT NOT NULL,
+	archive_key VARCHAR(255) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	finished_at TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS idx_user_data_exports_user ON user_data_exports (user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_user_data_exports_status ON user_data_exports (status, updated_at);
+`,
+	},
+	{
+		Version: 29,
+		Name:    "add_users_deletion_scheduled_at",
+		// set when the user asks to delete the account, the account is purged once the time has passed
+		Sqlite: `
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP NULL;
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users (deletion_scheduled_at);
+`,
+		Mysql: `
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP NULL;
+CREATE INDEX idx_users_deletion_scheduled_at ON users (deletion_scheduled_at);
+`,
+		Postgres: `
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMPTZ NULL;
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users (deletion_scheduled_at);
+`,
+	},
+	{
+		Version: 30,
+		Name:    "add_tool_schedules_resource_class",
+		// the limits the scheduled runs get, the existing schedules keep running as small
+		Sqlite:   `ALTER TABLE tool_schedules ADD COLUMN resource_class VARCHAR(16) NOT NULL DEFAULT 'small';`,
+		Mysql:    `ALTER TABLE tool_schedules ADD COLUMN resource_class VARCHAR(16) NOT NULL DEFAULT 'small';`,
+		Postgres: `ALTER TABLE tool_schedules ADD COLUMN resource_class VARCHAR(16) NOT NULL DEFAULT 'small';`,
+	},
+}
+
+// dedupeToolUniqueIDs gives a new unique id to every tool whose unique id an older tool has. The rows keyed by the
+// unique id move with the tool unless the older tool belongs to the same user, then they can not be told apart and
+// stay with it. Both ids are recorded as changed, so the devices of the user drop the old id and sync the new one.
+func dedupeToolUniqueIDs(ctx context.Context, tx *sqlx.Tx, dbType string) error {
+	var tools []struct {
+		UserID   string `db:"user_id"`
+		ID       string `db:"id"`
+		UniqueID string `db:"unique_id"`
+	}
+	if err := tx.SelectContext(ctx, &tools,
+		`SELECT user_id, id, unique_id FROM tools
+		 WHERE unique_id IN (SELECT unique_id FROM tools GROUP BY unique_id HAVING COUNT(*) > 1)
+		 ORDER BY unique_id, created_at, user_id, id`,
+	); err != nil {
+		return errors.Wrap(err, "fail to select tools sharing a unique id")
+	}
+
+	// keptBy is the user of the tool keeping each unique id
+	keptBy := map[string]string{}
+	now := time.Now()
+	for _, tool := range tools {
+		owner, seen := keptBy[tool.UniqueID]
+		if !seen {
+			keptBy[tool.UniqueID] = tool.UserID
+			continue
+		}
+
+		uid := entity.NewToolUniqueID()
+		if _, err := tx.ExecContext(ctx, "UPDATE tools SET unique_id = ? WHERE user_id = ? AND id = ?", uid, tool.UserID, tool.ID); err != nil {
+			return errors.Wrapf(err, "fail to give tool %s of user %s a new unique id", tool.ID, tool.UserID)
+		}
+		if owner != tool.UserID {
+			for _, table := range []string{"tool_extra_info", "tool_versions", "tool_events", "tool_secrets", "tool_changes"} {
+				if _, err := tx.ExecContext(ctx, "UPDATE "+table+" SET tool_unique_id = ? WHERE user_id = ? AND tool_unique_id = ?", uid, tool.UserID, tool.UniqueID); err != nil {
+					return errors.Wrapf(err, "fail to move %s of tool %s to its new unique id", table, tool.ID)
+				}
+			}
+			for _, table := range []string{"shared_tools", "gallery_tools"} {
+				if _, err := tx.ExecContext(ctx, "UPDATE "+table+" SET tool_unique_id = ? WHERE owner_id = ? AND tool_unique_id = ?", uid, tool.UserID, tool.UniqueID); err != nil {
+					return errors.Wrapf(err, "fail to move %s of tool %s to its new unique id", table, tool.ID)
+				}
+			}
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM tool_changes WHERE user_id = ? AND tool_unique_id IN (?, ?)", tool.UserID, tool.UniqueID, uid); err != nil {
+			return errors.Wrapf(err, "fail to delete the changes of tool %s", tool.ID)
+		}
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO tool_changes (user_id, tool_unique_id, changed_at) VALUES (?, ?, ?), (?, ?, ?)",
+			tool.UserID, tool.UniqueID, now, tool.UserID, uid, now,
+		); err != nil {
+			return errors.Wrapf(err, "fail to record the new unique id of tool %s", tool.ID)
+		}
+	}
+	return nil
+}
+
+// backfillToolSources moves the source of every tool into tool_sources. The keys are read first, mysql can not
+// run statements on a connection while it streams a result.
+func backfillToolSources(ctx context.Context, tx *sqlx.Tx, dbType string) error {
+	var tools []struct {
+		UserID   string `db:"user_id"`
+		UniqueID string `db:"unique_id"`
+	}
+	if err := tx.SelectContext(ctx, &tools, "SELECT user_id, unique_id FROM tools WHERE source_hash = ''"); err != nil {
+		return errors.Wrap(err, "fail to select tools to backfill")
+	}
+
+	upsert := `INSERT INTO tool_sources (hash, source, ref_count, created_at) VALUES (?, ?, 1, ?)
+	 ON CONFLICT(hash) DO UPDATE SET ref_count = tool_sources.ref_count + 1`
+	if dbType == "mysql" {
+		upsert = `INSERT INTO tool_sources (hash, source, ref_count, created_at) VALUES (?, ?, 1, ?)
+		 ON DUPLICATE KEY UPDATE ref_count = ref_count + 1`
+	}
+	now := time.Now()
+	for _, tool := range tools {
+		var source string
+		if err := tx.GetContext(ctx, &source, "SELECT source FROM tools WHERE user_id = ? AND unique_id = ?", tool.UserID, tool.UniqueID); err != nil {
+			return errors.Wrapf(err, "fail to read source of tool %s", tool.UniqueID)
+		}
+		hash := entity.ToolSourceHash(source)
+		if _, err := tx.ExecContext(ctx, upsert, hash, source, now); err != nil {
+			return errors.Wrapf(err, "fail to store source of tool %s", tool.UniqueID)
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE tools SET source = '', source_hash = ? WHERE user_id = ? AND unique_id = ?",
+			hash, tool.UserID, tool.UniqueID,
+		); err != nil {
+			return errors.Wrapf(err, "fail to point tool %s at its source", tool.UniqueID)
+		}
+	}
+	return nil
+}
+
+// backfillToolVersions records the current state of every tool as its first version and adds the reference of the
+// version to the source.
+func backfillToolVersions(ctx context.Context, tx *sqlx.Tx, dbType string) error {
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO tool_versions (user_id, tool_unique_id, version, source_hash, ui_widgets, created_at)
+		 SELECT user_id, unique_id, 1, source_hash, ui_widgets, updated_at FROM tools
+		 WHERE source_hash != '' AND NOT EXISTS (
+			SELECT 1 FROM tool_versions v WHERE v.user_id = tools.user_id AND v.tool_unique_id = tools.unique_id
+		 )`,
+	); err != nil {
+		return errors.Wrap(err, "fail to backfill tool versions")
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE tool_sources SET ref_count = ref_count + (
+			SELECT COUNT(*) FROM tool_versions v WHERE v.source_hash = tool_sources.hash
+		) WHERE hash IN (SELECT source_hash FROM tool_versions)`,
+	); err != nil {
+		return errors.Wrap(err, "fail to add the tool version references to the sources")
+	}
+	return nil
+}
+
+// backfillToolTagsAndSearch indexes the tags extra info and the searchable text of the existing tools.
+func backfillToolTagsAndSearch(ctx context.Context, tx *sqlx.Tx, dbType string) error {
+	var infos []struct {
+		UserID  string `db:"user_id"`
+		ToolUID string `db:"tool_unique_id"`
+		Value   string `db:"info_value"`
+	}
+	if err := tx.SelectContext(ctx, &infos,
+		"SELECT user_id, tool_unique_id, info_value FROM tool_extra_info WHERE info_key = ?", entity.ToolExtraInfoKeyTags,
+	); err != nil {
+		return errors.Wrap(err, "fail to select tool tags to backfill")
+	}
+	for _, info := range infos {
+		for _, tag := range entity.ParseToolTags(info.Value) {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT INTO tool_tags (user_id, tool_unique_id, tag) VALUES (?, ?, ?)",
+				info.UserID, info.ToolUID, tag,
+			); err != nil {
+				return errors.Wrapf(err, "fail to backfill tag %q of tool %s", tag, info.ToolUID)
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO tool_search (user_id, tool_unique_id, name, description, source)
+		 SELECT t.user_id, t.unique_id, t.name, t.description, COALESCE(s.source, t.source)
+		 FROM tools t LEFT JOIN tool_sources s ON s.hash = t.source_hash`,
+	); err != nil {
+		return errors.Wrap(err, "fail to backfill tool search documents")
+	}
+	return nil
+}
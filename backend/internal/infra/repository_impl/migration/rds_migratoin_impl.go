@@ -2,7 +2,11 @@ package migration
 
 import (
 	"context"
+	"database/sql"
+	"time"
 	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
 	iRepository "ya-tool-craft/internal/domain/repository"
 
 	"github.com/pkg/errors"
@@ -18,20 +22,134 @@ type RdsMigrationImpl struct {
 }
 
 func (r *RdsMigrationImpl) RunMigrate(ctx context.Context) error {
+	release, err := r.acquireMigrationLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	var schema string
 	switch r.config.DBType {
 	case "mysql":
 		schema = mysqlSchema()
+	case "postgres":
+		schema = postgresSchema()
 	default:
 		schema = sqliteSchema()
 	}
 
 	db := r.clienet.DB()
-	_, err := db.Exec(schema)
-	if err != nil {
+	if _, err := db.Exec(schema); err != nil {
 		return errors.Wrapf(err, "fail to migration tables")
 	}
-	return err
+
+	return r.runVersionedMigrations(ctx)
+}
+
+// runVersionedMigrations applies every migration in rdsMigrations that is not recorded in schema_migrations yet.
+// The base schema above is frozen, all later schema changes must be added as versioned migrations.
+func (r *RdsMigrationImpl) runVersionedMigrations(ctx context.Context) error {
+	return r.applyMigrations(ctx, rdsMigrations)
+}
+
+func (r *RdsMigrationImpl) applyMigrations(ctx context.Context, migrations []rdsMigration) error {
+	db := r.clienet.DB()
+
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+	version INTEGER PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	applied_at TIMESTAMP NOT NULL
+)`); err != nil {
+		return errors.Wrap(err, "fail to create schema_migrations table")
+	}
+
+	var applied []int
+	if err := db.Select(&applied, "SELECT version FROM schema_migrations"); err != nil {
+		return errors.Wrap(err, "fail to select applied schema migrations")
+	}
+	appliedSet := make(map[int]bool, len(applied))
+	for _, version := range applied {
+		appliedSet[version] = true
+	}
+
+	for _, m := range migrations {
+		if appliedSet[m.Version] {
+			continue
+		}
+
+		statement := m.Sqlite
+		switch r.config.DBType {
+		case "mysql":
+			statement = m.Mysql
+		case "postgres":
+			statement = m.Postgres
+		}
+
+		logger.Infof(ctx, "apply schema migration %d: %s", m.Version, m.Name)
+		tx, err := db.Beginx()
+		if err != nil {
+			return errors.Wrapf(err, "fail to begin schema migration %d", m.Version)
+		}
+		if m.Pre != nil {
+			if err := m.Pre(ctx, tx, r.config.DBType); err != nil {
+				tx.Rollback()
+				return errors.Wrapf(err, "fail to run pre hook of schema migration %d: %s", m.Version, m.Name)
+			}
+		}
+		if _, err := tx.Exec(statement); err != nil {
+			tx.Rollback()
+			return errors.Wrapf(err, "fail to apply schema migration %d: %s", m.Version, m.Name)
+		}
+		if m.Post != nil {
+			if err := m.Post(ctx, tx, r.config.DBType); err != nil {
+				tx.Rollback()
+				return errors.Wrapf(err, "fail to run post hook of schema migration %d: %s", m.Version, m.Name)
+			}
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+			m.Version,
+			m.Name,
+			time.Now(),
+		); err != nil {
+			tx.Rollback()
+			return errors.Wrapf(err, "fail to record schema migration %d", m.Version)
+		}
+		if err := tx.Commit(); err != nil {
+			return errors.Wrapf(err, "fail to commit schema migration %d", m.Version)
+		}
+	}
+
+	return nil
+}
+
+// SchemaVersion reads the newest applied versioned migration, the base schema has no version of its own.
+func (r *RdsMigrationImpl) SchemaVersion(ctx context.Context) (entity.SchemaVersionEntity, error) {
+	version := entity.SchemaVersionEntity{LatestVersion: latestRdsMigrationVersion(rdsMigrations)}
+
+	var applied struct {
+		Version   int       `db:"version"`
+		AppliedAt time.Time `db:"applied_at"`
+	}
+	err := r.clienet.DB().GetContext(ctx, &applied, "SELECT version, applied_at FROM schema_migrations ORDER BY version DESC LIMIT 1")
+	if errors.Is(err, sql.ErrNoRows) {
+		return version, nil
+	}
+	if err != nil {
+		return entity.SchemaVersionEntity{}, errors.Wrap(err, "fail to select applied schema version")
+	}
+
+	version.AppliedVersion = applied.Version
+	version.AppliedAt = applied.AppliedAt
+	return version, nil
+}
+
+func latestRdsMigrationVersion(migrations []rdsMigration) int {
+	latest := 0
+	for _, m := range migrations {
+		latest = max(latest, m.Version)
+	}
+	return latest
 }
 
 func sqliteSchema() string {
@@ -224,3 +342,95 @@ CREATE TABLE IF NOT EXISTS user_2fa (
 );
 `
 }
+
+// postgresSchema is the base schema for postgres, which came after it was frozen. Timestamps are TIMESTAMPTZ,
+// a plain TIMESTAMP would drop the zone of the times the repositories write.
+func postgresSchema() string {
+	return `
+CREATE TABLE IF NOT EXISTS users (
+	id VARCHAR(255) PRIMARY KEY,
+	username VARCHAR(255) NOT NULL UNIQUE,
+	email VARCHAR(255) UNIQUE,
+	password_hash VARCHAR(255),
+	roles TEXT NOT NULL,
+	encrypt_key VARCHAR(255) NOT NULL,
+	recovery_code TEXT,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at);
+CREATE INDEX IF NOT EXISTS idx_users_updated_at ON users (updated_at);
+
+CREATE TABLE IF NOT EXISTS user_sso (
+	user_id VARCHAR(255) NOT NULL,
+	provider VARCHAR(255) NOT NULL,
+	provider_user_id VARCHAR(255) NOT NULL,
+	provider_username VARCHAR(255),
+	provider_email VARCHAR(255),
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_user_sso_user_id ON user_sso (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_sso_provider_user_id ON user_sso (provider, provider_user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_sso_user_id_provider ON user_sso (user_id, provider);
+
+CREATE TABLE IF NOT EXISTS tools (
+	user_id VARCHAR(255) NOT NULL,
+	id VARCHAR(255) NOT NULL,
+	unique_id VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	namespace VARCHAR(255) NOT NULL,
+	category VARCHAR(255) NOT NULL,
+	is_activate BOOLEAN NOT NULL,
+	realtime_execution BOOLEAN NOT NULL,
+	ui_widgets TEXT NOT NULL,
+	source TEXT NOT NULL,
+	description TEXT NOT NULL,
+	extra_info TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (user_id, id)
+);
+CREATE INDEX IF NOT EXISTS idx_tools_user_id ON tools (user_id);
+CREATE INDEX IF NOT EXISTS idx_tools_unique_id ON tools (unique_id);
+
+CREATE TABLE IF NOT EXISTS tools_last_update_at (
+	user_id VARCHAR(255) PRIMARY KEY,
+	last_updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS global_scripts (
+	user_id VARCHAR(255) PRIMARY KEY,
+	script TEXT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS user_passkeys (
+	id BIGSERIAL PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	credential_id BYTEA NOT NULL,
+	public_key BYTEA NOT NULL,
+	sign_count INTEGER NOT NULL DEFAULT 0,
+	aaguid BYTEA,
+	transports VARCHAR(255),
+	device_name VARCHAR(255),
+	extra_info TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	last_used_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_passkeys_credential_id ON user_passkeys (credential_id);
+CREATE INDEX IF NOT EXISTS idx_user_passkeys_user_id ON user_passkeys (user_id);
+
+CREATE TABLE IF NOT EXISTS user_2fa (
+	id BIGSERIAL PRIMARY KEY,
+	user_id VARCHAR(255) NOT NULL,
+	type VARCHAR(50) NOT NULL,
+	secret VARCHAR(255) NOT NULL,
+	verified BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	UNIQUE(user_id, type)
+);
+CREATE INDEX IF NOT EXISTS idx_user_2fa_user_id ON user_2fa (user_id);
+`
+}
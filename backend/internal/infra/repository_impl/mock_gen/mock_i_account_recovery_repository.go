@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IAccountRecoveryRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIAccountRecoveryRepository is a mock of IAccountRecoveryRepository interface.
+type MockIAccountRecoveryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAccountRecoveryRepositoryMockRecorder
+}
+
+// MockIAccountRecoveryRepositoryMockRecorder is the mock recorder for MockIAccountRecoveryRepository.
+type MockIAccountRecoveryRepositoryMockRecorder struct {
+	mock *MockIAccountRecoveryRepository
+}
+
+// NewMockIAccountRecoveryRepository creates a new mock instance.
+func NewMockIAccountRecoveryRepository(ctrl *gomock.Controller) *MockIAccountRecoveryRepository {
+	mock := &MockIAccountRecoveryRepository{ctrl: ctrl}
+	mock.recorder = &MockIAccountRecoveryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAccountRecoveryRepository) EXPECT() *MockIAccountRecoveryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIAccountRecoveryRepository) Create(arg0 context.Context, arg1 entity.AccountRecoveryRequestEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIAccountRecoveryRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIAccountRecoveryRepository)(nil).Create), arg0, arg1)
+}
+
+// GetByID mocks base method.
+func (m *MockIAccountRecoveryRepository) GetByID(arg0 context.Context, arg1 string) (entity.AccountRecoveryRequestEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", arg0, arg1)
+	ret0, _ := ret[0].(entity.AccountRecoveryRequestEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockIAccountRecoveryRepositoryMockRecorder) GetByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockIAccountRecoveryRepository)(nil).GetByID), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockIAccountRecoveryRepository) List(arg0 context.Context, arg1 entity.AccountRecoveryStatus) ([]entity.AccountRecoveryRequestEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]entity.AccountRecoveryRequestEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIAccountRecoveryRepositoryMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIAccountRecoveryRepository)(nil).List), arg0, arg1)
+}
+
+// ListByUserID mocks base method.
+func (m *MockIAccountRecoveryRepository) ListByUserID(arg0 context.Context, arg1 entity.UserIDEntity) ([]entity.AccountRecoveryRequestEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]entity.AccountRecoveryRequestEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUserID indicates an expected call of ListByUserID.
+func (mr *MockIAccountRecoveryRepositoryMockRecorder) ListByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUserID", reflect.TypeOf((*MockIAccountRecoveryRepository)(nil).ListByUserID), arg0, arg1)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockIAccountRecoveryRepository) UpdateStatus(arg0 context.Context, arg1 string, arg2 []entity.AccountRecoveryStatus, arg3 entity.AccountRecoveryStatus, arg4 *entity.UserIDEntity) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockIAccountRecoveryRepositoryMockRecorder) UpdateStatus(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockIAccountRecoveryRepository)(nil).UpdateStatus), arg0, arg1, arg2, arg3, arg4)
+}
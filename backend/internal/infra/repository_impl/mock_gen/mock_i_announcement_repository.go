@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IAnnouncementRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIAnnouncementRepository is a mock of IAnnouncementRepository interface.
+type MockIAnnouncementRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAnnouncementRepositoryMockRecorder
+}
+
+// MockIAnnouncementRepositoryMockRecorder is the mock recorder for MockIAnnouncementRepository.
+type MockIAnnouncementRepositoryMockRecorder struct {
+	mock *MockIAnnouncementRepository
+}
+
+// NewMockIAnnouncementRepository creates a new mock instance.
+func NewMockIAnnouncementRepository(ctrl *gomock.Controller) *MockIAnnouncementRepository {
+	mock := &MockIAnnouncementRepository{ctrl: ctrl}
+	mock.recorder = &MockIAnnouncementRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAnnouncementRepository) EXPECT() *MockIAnnouncementRepositoryMockRecorder {
+	return m.recorder
+}
+
+// All mocks base method.
+func (m *MockIAnnouncementRepository) All(arg0 context.Context) ([]entity.AnnouncementEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "All", arg0)
+	ret0, _ := ret[0].([]entity.AnnouncementEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// All indicates an expected call of All.
+func (mr *MockIAnnouncementRepositoryMockRecorder) All(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "All", reflect.TypeOf((*MockIAnnouncementRepository)(nil).All), arg0)
+}
+
+// Create mocks base method.
+func (m *MockIAnnouncementRepository) Create(arg0 context.Context, arg1 entity.AnnouncementEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIAnnouncementRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIAnnouncementRepository)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockIAnnouncementRepository) Delete(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIAnnouncementRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIAnnouncementRepository)(nil).Delete), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockIAnnouncementRepository) Update(arg0 context.Context, arg1 entity.AnnouncementEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockIAnnouncementRepositoryMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIAnnouncementRepository)(nil).Update), arg0, arg1)
+}
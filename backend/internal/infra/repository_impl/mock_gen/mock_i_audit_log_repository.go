@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IAuditLogRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIAuditLogRepository is a mock of IAuditLogRepository interface.
+type MockIAuditLogRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAuditLogRepositoryMockRecorder
+}
+
+// MockIAuditLogRepositoryMockRecorder is the mock recorder for MockIAuditLogRepository.
+type MockIAuditLogRepositoryMockRecorder struct {
+	mock *MockIAuditLogRepository
+}
+
+// NewMockIAuditLogRepository creates a new mock instance.
+func NewMockIAuditLogRepository(ctrl *gomock.Controller) *MockIAuditLogRepository {
+	mock := &MockIAuditLogRepository{ctrl: ctrl}
+	mock.recorder = &MockIAuditLogRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAuditLogRepository) EXPECT() *MockIAuditLogRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIAuditLogRepository) Create(arg0 context.Context, arg1 entity.AuditLogEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIAuditLogRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIAuditLogRepository)(nil).Create), arg0, arg1)
+}
+
+// DeleteCreatedBefore mocks base method.
+func (m *MockIAuditLogRepository) DeleteCreatedBefore(arg0 context.Context, arg1 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCreatedBefore", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCreatedBefore indicates an expected call of DeleteCreatedBefore.
+func (mr *MockIAuditLogRepositoryMockRecorder) DeleteCreatedBefore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCreatedBefore", reflect.TypeOf((*MockIAuditLogRepository)(nil).DeleteCreatedBefore), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockIAuditLogRepository) List(arg0 context.Context, arg1 entity.AuditLogFilter) ([]entity.AuditLogEntity, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]entity.AuditLogEntity)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// List indicates an expected call of List.
+func (mr *MockIAuditLogRepositoryMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIAuditLogRepository)(nil).List), arg0, arg1)
+}
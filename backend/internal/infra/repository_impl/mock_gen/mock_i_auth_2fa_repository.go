@@ -108,6 +108,21 @@ func (mr *MockIAuth2FARepositoryMockRecorder) GetByUserIDAndType(arg0, arg1, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserIDAndType", reflect.TypeOf((*MockIAuth2FARepository)(nil).GetByUserIDAndType), arg0, arg1, arg2)
 }
 
+// GetPreferredMethod mocks base method.
+func (m *MockIAuth2FARepository) GetPreferredMethod(arg0 context.Context, arg1 entity.UserIDEntity) (*entity.TwoFAType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferredMethod", arg0, arg1)
+	ret0, _ := ret[0].(*entity.TwoFAType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferredMethod indicates an expected call of GetPreferredMethod.
+func (mr *MockIAuth2FARepositoryMockRecorder) GetPreferredMethod(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferredMethod", reflect.TypeOf((*MockIAuth2FARepository)(nil).GetPreferredMethod), arg0, arg1)
+}
+
 // GetRecoveryCode mocks base method.
 func (m *MockIAuth2FARepository) GetRecoveryCode(arg0 context.Context, arg1 entity.UserIDEntity) (*string, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +138,49 @@ func (mr *MockIAuth2FARepositoryMockRecorder) GetRecoveryCode(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecoveryCode", reflect.TypeOf((*MockIAuth2FARepository)(nil).GetRecoveryCode), arg0, arg1)
 }
 
+// GetRecoveryCodesStatus mocks base method.
+func (m *MockIAuth2FARepository) GetRecoveryCodesStatus(arg0 context.Context, arg1 entity.UserIDEntity) (entity.RecoveryCodesStatusEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecoveryCodesStatus", arg0, arg1)
+	ret0, _ := ret[0].(entity.RecoveryCodesStatusEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecoveryCodesStatus indicates an expected call of GetRecoveryCodesStatus.
+func (mr *MockIAuth2FARepositoryMockRecorder) GetRecoveryCodesStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecoveryCodesStatus", reflect.TypeOf((*MockIAuth2FARepository)(nil).GetRecoveryCodesStatus), arg0, arg1)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockIAuth2FARepository) ReplaceRecoveryCodes(arg0 context.Context, arg1 entity.UserIDEntity, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockIAuth2FARepositoryMockRecorder) ReplaceRecoveryCodes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockIAuth2FARepository)(nil).ReplaceRecoveryCodes), arg0, arg1, arg2)
+}
+
+// SetPreferredMethod mocks base method.
+func (m *MockIAuth2FARepository) SetPreferredMethod(arg0 context.Context, arg1 entity.UserIDEntity, arg2 *entity.TwoFAType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPreferredMethod", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPreferredMethod indicates an expected call of SetPreferredMethod.
+func (mr *MockIAuth2FARepositoryMockRecorder) SetPreferredMethod(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreferredMethod", reflect.TypeOf((*MockIAuth2FARepository)(nil).SetPreferredMethod), arg0, arg1, arg2)
+}
+
 // SetRecoveryCode mocks base method.
 func (m *MockIAuth2FARepository) SetRecoveryCode(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -136,3 +194,18 @@ func (mr *MockIAuth2FARepositoryMockRecorder) SetRecoveryCode(arg0, arg1, arg2 i
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRecoveryCode", reflect.TypeOf((*MockIAuth2FARepository)(nil).SetRecoveryCode), arg0, arg1, arg2)
 }
+
+// UseRecoveryCode mocks base method.
+func (m *MockIAuth2FARepository) UseRecoveryCode(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockIAuth2FARepositoryMockRecorder) UseRecoveryCode(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockIAuth2FARepository)(nil).UseRecoveryCode), arg0, arg1, arg2)
+}
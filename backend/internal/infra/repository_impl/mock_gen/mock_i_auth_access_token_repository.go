@@ -63,6 +63,34 @@ func (mr *MockIAuthAccessTokenRepositoryMockRecorder) DeleteAllTokensByUserID(ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllTokensByUserID", reflect.TypeOf((*MockIAuthAccessTokenRepository)(nil).DeleteAllTokensByUserID), arg0, arg1)
 }
 
+// DeleteOtherTokensByUserID mocks base method.
+func (m *MockIAuthAccessTokenRepository) DeleteOtherTokensByUserID(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOtherTokensByUserID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOtherTokensByUserID indicates an expected call of DeleteOtherTokensByUserID.
+func (mr *MockIAuthAccessTokenRepositoryMockRecorder) DeleteOtherTokensByUserID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOtherTokensByUserID", reflect.TypeOf((*MockIAuthAccessTokenRepository)(nil).DeleteOtherTokensByUserID), arg0, arg1, arg2)
+}
+
+// DeleteTokensByRefreshTokenHash mocks base method.
+func (m *MockIAuthAccessTokenRepository) DeleteTokensByRefreshTokenHash(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTokensByRefreshTokenHash", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTokensByRefreshTokenHash indicates an expected call of DeleteTokensByRefreshTokenHash.
+func (mr *MockIAuthAccessTokenRepositoryMockRecorder) DeleteTokensByRefreshTokenHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTokensByRefreshTokenHash", reflect.TypeOf((*MockIAuthAccessTokenRepository)(nil).DeleteTokensByRefreshTokenHash), arg0, arg1)
+}
+
 // IssueAccessToken mocks base method.
 func (m *MockIAuthAccessTokenRepository) IssueAccessToken(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) (entity.AccessToken, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +106,21 @@ func (mr *MockIAuthAccessTokenRepositoryMockRecorder) IssueAccessToken(arg0, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueAccessToken", reflect.TypeOf((*MockIAuthAccessTokenRepository)(nil).IssueAccessToken), arg0, arg1, arg2)
 }
 
+// IssueClientAccessToken mocks base method.
+func (m *MockIAuthAccessTokenRepository) IssueClientAccessToken(arg0 context.Context, arg1 entity.RefreshToken) (entity.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueClientAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(entity.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueClientAccessToken indicates an expected call of IssueClientAccessToken.
+func (mr *MockIAuthAccessTokenRepositoryMockRecorder) IssueClientAccessToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueClientAccessToken", reflect.TypeOf((*MockIAuthAccessTokenRepository)(nil).IssueClientAccessToken), arg0, arg1)
+}
+
 // ValidateAccessToken mocks base method.
 func (m *MockIAuthAccessTokenRepository) ValidateAccessToken(arg0 context.Context, arg1 string) (entity.AccessToken, bool, error) {
 	m.ctrl.T.Helper()
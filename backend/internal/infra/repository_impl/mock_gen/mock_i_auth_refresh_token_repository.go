@@ -35,6 +35,20 @@ func (m *MockIAuthRefreshTokenRepository) EXPECT() *MockIAuthRefreshTokenReposit
 	return m.recorder
 }
 
+// CleanupExpiredTokenHashesForUser mocks base method.
+func (m *MockIAuthRefreshTokenRepository) CleanupExpiredTokenHashesForUser(arg0 context.Context, arg1 entity.UserIDEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CleanupExpiredTokenHashesForUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CleanupExpiredTokenHashesForUser indicates an expected call of CleanupExpiredTokenHashesForUser.
+func (mr *MockIAuthRefreshTokenRepositoryMockRecorder) CleanupExpiredTokenHashesForUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupExpiredTokenHashesForUser", reflect.TypeOf((*MockIAuthRefreshTokenRepository)(nil).CleanupExpiredTokenHashesForUser), arg0, arg1)
+}
+
 // DeleteAllTokensByUserID mocks base method.
 func (m *MockIAuthRefreshTokenRepository) DeleteAllTokensByUserID(arg0 context.Context, arg1 entity.UserIDEntity) error {
 	m.ctrl.T.Helper()
@@ -49,6 +63,20 @@ func (mr *MockIAuthRefreshTokenRepositoryMockRecorder) DeleteAllTokensByUserID(a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllTokensByUserID", reflect.TypeOf((*MockIAuthRefreshTokenRepository)(nil).DeleteAllTokensByUserID), arg0, arg1)
 }
 
+// DeleteOtherTokensByUserID mocks base method.
+func (m *MockIAuthRefreshTokenRepository) DeleteOtherTokensByUserID(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOtherTokensByUserID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOtherTokensByUserID indicates an expected call of DeleteOtherTokensByUserID.
+func (mr *MockIAuthRefreshTokenRepositoryMockRecorder) DeleteOtherTokensByUserID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOtherTokensByUserID", reflect.TypeOf((*MockIAuthRefreshTokenRepository)(nil).DeleteOtherTokensByUserID), arg0, arg1, arg2)
+}
+
 // DeleteRefreshToken mocks base method.
 func (m *MockIAuthRefreshTokenRepository) DeleteRefreshToken(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -77,6 +105,52 @@ func (mr *MockIAuthRefreshTokenRepositoryMockRecorder) DeleteRefreshTokenByHash(
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshTokenByHash", reflect.TypeOf((*MockIAuthRefreshTokenRepository)(nil).DeleteRefreshTokenByHash), arg0, arg1)
 }
 
+// DeleteTokenFamily mocks base method.
+func (m *MockIAuthRefreshTokenRepository) DeleteTokenFamily(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTokenFamily", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTokenFamily indicates an expected call of DeleteTokenFamily.
+func (mr *MockIAuthRefreshTokenRepositoryMockRecorder) DeleteTokenFamily(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTokenFamily", reflect.TypeOf((*MockIAuthRefreshTokenRepository)(nil).DeleteTokenFamily), arg0, arg1, arg2)
+}
+
+// FindRotatedRefreshToken mocks base method.
+func (m *MockIAuthRefreshTokenRepository) FindRotatedRefreshToken(arg0 context.Context, arg1 string) (entity.RefreshToken, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRotatedRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(entity.RefreshToken)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindRotatedRefreshToken indicates an expected call of FindRotatedRefreshToken.
+func (mr *MockIAuthRefreshTokenRepositoryMockRecorder) FindRotatedRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRotatedRefreshToken", reflect.TypeOf((*MockIAuthRefreshTokenRepository)(nil).FindRotatedRefreshToken), arg0, arg1)
+}
+
+// IssueClientRefreshToken mocks base method.
+func (m *MockIAuthRefreshTokenRepository) IssueClientRefreshToken(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string, arg3 []string) (entity.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueClientRefreshToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(entity.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueClientRefreshToken indicates an expected call of IssueClientRefreshToken.
+func (mr *MockIAuthRefreshTokenRepositoryMockRecorder) IssueClientRefreshToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueClientRefreshToken", reflect.TypeOf((*MockIAuthRefreshTokenRepository)(nil).IssueClientRefreshToken), arg0, arg1, arg2, arg3)
+}
+
 // IssueRefreshToken mocks base method.
 func (m *MockIAuthRefreshTokenRepository) IssueRefreshToken(arg0 context.Context, arg1 entity.UserIDEntity) (entity.RefreshToken, error) {
 	m.ctrl.T.Helper()
@@ -92,6 +166,37 @@ func (mr *MockIAuthRefreshTokenRepositoryMockRecorder) IssueRefreshToken(arg0, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueRefreshToken", reflect.TypeOf((*MockIAuthRefreshTokenRepository)(nil).IssueRefreshToken), arg0, arg1)
 }
 
+// ListActiveSessionsByUserID mocks base method.
+func (m *MockIAuthRefreshTokenRepository) ListActiveSessionsByUserID(arg0 context.Context, arg1 entity.UserIDEntity) ([]entity.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveSessionsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]entity.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveSessionsByUserID indicates an expected call of ListActiveSessionsByUserID.
+func (mr *MockIAuthRefreshTokenRepositoryMockRecorder) ListActiveSessionsByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveSessionsByUserID", reflect.TypeOf((*MockIAuthRefreshTokenRepository)(nil).ListActiveSessionsByUserID), arg0, arg1)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockIAuthRefreshTokenRepository) RotateRefreshToken(arg0 context.Context, arg1 entity.RefreshToken) (entity.RefreshToken, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(entity.RefreshToken)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockIAuthRefreshTokenRepositoryMockRecorder) RotateRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockIAuthRefreshTokenRepository)(nil).RotateRefreshToken), arg0, arg1)
+}
+
 // ValidateRefreshToken mocks base method.
 func (m *MockIAuthRefreshTokenRepository) ValidateRefreshToken(arg0 context.Context, arg1 string) (entity.RefreshToken, bool, error) {
 	m.ctrl.T.Helper()
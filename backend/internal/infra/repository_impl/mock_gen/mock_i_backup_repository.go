@@ -0,0 +1,143 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IBackupRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIBackupRepository is a mock of IBackupRepository interface.
+type MockIBackupRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIBackupRepositoryMockRecorder
+}
+
+// MockIBackupRepositoryMockRecorder is the mock recorder for MockIBackupRepository.
+type MockIBackupRepositoryMockRecorder struct {
+	mock *MockIBackupRepository
+}
+
+// NewMockIBackupRepository creates a new mock instance.
+func NewMockIBackupRepository(ctrl *gomock.Controller) *MockIBackupRepository {
+	mock := &MockIBackupRepository{ctrl: ctrl}
+	mock.recorder = &MockIBackupRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIBackupRepository) EXPECT() *MockIBackupRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ApplyPendingRestore mocks base method.
+func (m *MockIBackupRepository) ApplyPendingRestore(arg0 context.Context) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyPendingRestore", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ApplyPendingRestore indicates an expected call of ApplyPendingRestore.
+func (mr *MockIBackupRepositoryMockRecorder) ApplyPendingRestore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyPendingRestore", reflect.TypeOf((*MockIBackupRepository)(nil).ApplyPendingRestore), arg0)
+}
+
+// CancelRestore mocks base method.
+func (m *MockIBackupRepository) CancelRestore(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelRestore", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelRestore indicates an expected call of CancelRestore.
+func (mr *MockIBackupRepositoryMockRecorder) CancelRestore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelRestore", reflect.TypeOf((*MockIBackupRepository)(nil).CancelRestore), arg0)
+}
+
+// Create mocks base method.
+func (m *MockIBackupRepository) Create(arg0 context.Context, arg1 string, arg2 func(string) ([]string, error)) (entity.BackupEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.BackupEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIBackupRepositoryMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIBackupRepository)(nil).Create), arg0, arg1, arg2)
+}
+
+// Delete mocks base method.
+func (m *MockIBackupRepository) Delete(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIBackupRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIBackupRepository)(nil).Delete), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockIBackupRepository) List(arg0 context.Context) ([]entity.BackupEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0)
+	ret0, _ := ret[0].([]entity.BackupEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIBackupRepositoryMockRecorder) List(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIBackupRepository)(nil).List), arg0)
+}
+
+// PendingRestore mocks base method.
+func (m *MockIBackupRepository) PendingRestore(arg0 context.Context) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingRestore", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PendingRestore indicates an expected call of PendingRestore.
+func (mr *MockIBackupRepositoryMockRecorder) PendingRestore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingRestore", reflect.TypeOf((*MockIBackupRepository)(nil).PendingRestore), arg0)
+}
+
+// StageRestore mocks base method.
+func (m *MockIBackupRepository) StageRestore(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StageRestore", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StageRestore indicates an expected call of StageRestore.
+func (mr *MockIBackupRepositoryMockRecorder) StageRestore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StageRestore", reflect.TypeOf((*MockIBackupRepository)(nil).StageRestore), arg0, arg1)
+}
@@ -79,6 +79,21 @@ func (mr *MockICacheMockRecorder) Has(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Has", reflect.TypeOf((*MockICache)(nil).Has), arg0, arg1)
 }
 
+// Increment mocks base method.
+func (m *MockICache) Increment(arg0 context.Context, arg1 string, arg2 uint64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Increment", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Increment indicates an expected call of Increment.
+func (mr *MockICacheMockRecorder) Increment(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Increment", reflect.TypeOf((*MockICache)(nil).Increment), arg0, arg1, arg2)
+}
+
 // Set mocks base method.
 func (m *MockICache) Set(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: ICacheMaintenance)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockICacheMaintenance is a mock of ICacheMaintenance interface.
+type MockICacheMaintenance struct {
+	ctrl     *gomock.Controller
+	recorder *MockICacheMaintenanceMockRecorder
+}
+
+// MockICacheMaintenanceMockRecorder is the mock recorder for MockICacheMaintenance.
+type MockICacheMaintenanceMockRecorder struct {
+	mock *MockICacheMaintenance
+}
+
+// NewMockICacheMaintenance creates a new mock instance.
+func NewMockICacheMaintenance(ctrl *gomock.Controller) *MockICacheMaintenance {
+	mock := &MockICacheMaintenance{ctrl: ctrl}
+	mock.recorder = &MockICacheMaintenanceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockICacheMaintenance) EXPECT() *MockICacheMaintenanceMockRecorder {
+	return m.recorder
+}
+
+// PurgeExpired mocks base method.
+func (m *MockICacheMaintenance) PurgeExpired(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpired", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpired indicates an expected call of PurgeExpired.
+func (mr *MockICacheMaintenanceMockRecorder) PurgeExpired(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpired", reflect.TypeOf((*MockICacheMaintenance)(nil).PurgeExpired), arg0)
+}
@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IGalleryRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIGalleryRepository is a mock of IGalleryRepository interface.
+type MockIGalleryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIGalleryRepositoryMockRecorder
+}
+
+// MockIGalleryRepositoryMockRecorder is the mock recorder for MockIGalleryRepository.
+type MockIGalleryRepositoryMockRecorder struct {
+	mock *MockIGalleryRepository
+}
+
+// NewMockIGalleryRepository creates a new mock instance.
+func NewMockIGalleryRepository(ctrl *gomock.Controller) *MockIGalleryRepository {
+	mock := &MockIGalleryRepository{ctrl: ctrl}
+	mock.recorder = &MockIGalleryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIGalleryRepository) EXPECT() *MockIGalleryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetPublished mocks base method.
+func (m *MockIGalleryRepository) GetPublished(arg0 context.Context, arg1 string) (entity.GalleryToolEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublished", arg0, arg1)
+	ret0, _ := ret[0].(entity.GalleryToolEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPublished indicates an expected call of GetPublished.
+func (mr *MockIGalleryRepositoryMockRecorder) GetPublished(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublished", reflect.TypeOf((*MockIGalleryRepository)(nil).GetPublished), arg0, arg1)
+}
+
+// IncrementForkCount mocks base method.
+func (m *MockIGalleryRepository) IncrementForkCount(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementForkCount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementForkCount indicates an expected call of IncrementForkCount.
+func (mr *MockIGalleryRepositoryMockRecorder) IncrementForkCount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementForkCount", reflect.TypeOf((*MockIGalleryRepository)(nil).IncrementForkCount), arg0, arg1)
+}
+
+// IncrementInstallCount mocks base method.
+func (m *MockIGalleryRepository) IncrementInstallCount(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementInstallCount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementInstallCount indicates an expected call of IncrementInstallCount.
+func (mr *MockIGalleryRepositoryMockRecorder) IncrementInstallCount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementInstallCount", reflect.TypeOf((*MockIGalleryRepository)(nil).IncrementInstallCount), arg0, arg1)
+}
+
+// ListPublished mocks base method.
+func (m *MockIGalleryRepository) ListPublished(arg0 context.Context, arg1 entity.GalleryFilter) ([]entity.GalleryToolEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPublished", arg0, arg1)
+	ret0, _ := ret[0].([]entity.GalleryToolEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPublished indicates an expected call of ListPublished.
+func (mr *MockIGalleryRepositoryMockRecorder) ListPublished(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPublished", reflect.TypeOf((*MockIGalleryRepository)(nil).ListPublished), arg0, arg1)
+}
+
+// PublishTool mocks base method.
+func (m *MockIGalleryRepository) PublishTool(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishTool", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishTool indicates an expected call of PublishTool.
+func (mr *MockIGalleryRepositoryMockRecorder) PublishTool(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishTool", reflect.TypeOf((*MockIGalleryRepository)(nil).PublishTool), arg0, arg1, arg2)
+}
+
+// UnpublishTool mocks base method.
+func (m *MockIGalleryRepository) UnpublishTool(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnpublishTool", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnpublishTool indicates an expected call of UnpublishTool.
+func (mr *MockIGalleryRepositoryMockRecorder) UnpublishTool(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpublishTool", reflect.TypeOf((*MockIGalleryRepository)(nil).UnpublishTool), arg0, arg1, arg2)
+}
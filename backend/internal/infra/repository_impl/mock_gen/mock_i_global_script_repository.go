@@ -34,6 +34,21 @@ func (m *MockIGlobalScriptRepository) EXPECT() *MockIGlobalScriptRepositoryMockR
 	return m.recorder
 }
 
+// EncryptGlobalScripts mocks base method.
+func (m *MockIGlobalScriptRepository) EncryptGlobalScripts() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EncryptGlobalScripts")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EncryptGlobalScripts indicates an expected call of EncryptGlobalScripts.
+func (mr *MockIGlobalScriptRepositoryMockRecorder) EncryptGlobalScripts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EncryptGlobalScripts", reflect.TypeOf((*MockIGlobalScriptRepository)(nil).EncryptGlobalScripts))
+}
+
 // GetGlobalScript mocks base method.
 func (m *MockIGlobalScriptRepository) GetGlobalScript(arg0 entity.UserIDEntity) (*entity.GlobalScriptEntity, error) {
 	m.ctrl.T.Helper()
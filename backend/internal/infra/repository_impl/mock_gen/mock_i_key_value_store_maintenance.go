@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IKeyValueStoreMaintenance)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIKeyValueStoreMaintenance is a mock of IKeyValueStoreMaintenance interface.
+type MockIKeyValueStoreMaintenance struct {
+	ctrl     *gomock.Controller
+	recorder *MockIKeyValueStoreMaintenanceMockRecorder
+}
+
+// MockIKeyValueStoreMaintenanceMockRecorder is the mock recorder for MockIKeyValueStoreMaintenance.
+type MockIKeyValueStoreMaintenanceMockRecorder struct {
+	mock *MockIKeyValueStoreMaintenance
+}
+
+// NewMockIKeyValueStoreMaintenance creates a new mock instance.
+func NewMockIKeyValueStoreMaintenance(ctrl *gomock.Controller) *MockIKeyValueStoreMaintenance {
+	mock := &MockIKeyValueStoreMaintenance{ctrl: ctrl}
+	mock.recorder = &MockIKeyValueStoreMaintenanceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIKeyValueStoreMaintenance) EXPECT() *MockIKeyValueStoreMaintenanceMockRecorder {
+	return m.recorder
+}
+
+// Backup mocks base method.
+func (m *MockIKeyValueStoreMaintenance) Backup(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Backup", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Backup indicates an expected call of Backup.
+func (mr *MockIKeyValueStoreMaintenanceMockRecorder) Backup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Backup", reflect.TypeOf((*MockIKeyValueStoreMaintenance)(nil).Backup), arg0, arg1)
+}
+
+// Compact mocks base method.
+func (m *MockIKeyValueStoreMaintenance) Compact(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compact", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Compact indicates an expected call of Compact.
+func (mr *MockIKeyValueStoreMaintenanceMockRecorder) Compact(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compact", reflect.TypeOf((*MockIKeyValueStoreMaintenance)(nil).Compact), arg0)
+}
@@ -7,6 +7,7 @@ package mock_gen
 import (
 	context "context"
 	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -47,3 +48,18 @@ func (mr *MockIMigrationMockRecorder) RunMigrate(arg0 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunMigrate", reflect.TypeOf((*MockIMigration)(nil).RunMigrate), arg0)
 }
+
+// SchemaVersion mocks base method.
+func (m *MockIMigration) SchemaVersion(arg0 context.Context) (entity.SchemaVersionEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchemaVersion", arg0)
+	ret0, _ := ret[0].(entity.SchemaVersionEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SchemaVersion indicates an expected call of SchemaVersion.
+func (mr *MockIMigrationMockRecorder) SchemaVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchemaVersion", reflect.TypeOf((*MockIMigration)(nil).SchemaVersion), arg0)
+}
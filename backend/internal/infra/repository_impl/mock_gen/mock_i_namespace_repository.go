@@ -0,0 +1,112 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: INamespaceRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockINamespaceRepository is a mock of INamespaceRepository interface.
+type MockINamespaceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockINamespaceRepositoryMockRecorder
+}
+
+// MockINamespaceRepositoryMockRecorder is the mock recorder for MockINamespaceRepository.
+type MockINamespaceRepositoryMockRecorder struct {
+	mock *MockINamespaceRepository
+}
+
+// NewMockINamespaceRepository creates a new mock instance.
+func NewMockINamespaceRepository(ctrl *gomock.Controller) *MockINamespaceRepository {
+	mock := &MockINamespaceRepository{ctrl: ctrl}
+	mock.recorder = &MockINamespaceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockINamespaceRepository) EXPECT() *MockINamespaceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateNamespace mocks base method.
+func (m *MockINamespaceRepository) CreateNamespace(arg0 context.Context, arg1 entity.UserIDEntity, arg2 entity.NamespaceEntity) (entity.NamespaceEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNamespace", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.NamespaceEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNamespace indicates an expected call of CreateNamespace.
+func (mr *MockINamespaceRepositoryMockRecorder) CreateNamespace(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNamespace", reflect.TypeOf((*MockINamespaceRepository)(nil).CreateNamespace), arg0, arg1, arg2)
+}
+
+// DeleteNamespace mocks base method.
+func (m *MockINamespaceRepository) DeleteNamespace(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNamespace", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteNamespace indicates an expected call of DeleteNamespace.
+func (mr *MockINamespaceRepositoryMockRecorder) DeleteNamespace(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNamespace", reflect.TypeOf((*MockINamespaceRepository)(nil).DeleteNamespace), arg0, arg1, arg2)
+}
+
+// GetNamespace mocks base method.
+func (m *MockINamespaceRepository) GetNamespace(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) (entity.NamespaceEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNamespace", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.NamespaceEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetNamespace indicates an expected call of GetNamespace.
+func (mr *MockINamespaceRepositoryMockRecorder) GetNamespace(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNamespace", reflect.TypeOf((*MockINamespaceRepository)(nil).GetNamespace), arg0, arg1, arg2)
+}
+
+// ListNamespaces mocks base method.
+func (m *MockINamespaceRepository) ListNamespaces(arg0 context.Context, arg1 entity.UserIDEntity) ([]entity.NamespaceEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNamespaces", arg0, arg1)
+	ret0, _ := ret[0].([]entity.NamespaceEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNamespaces indicates an expected call of ListNamespaces.
+func (mr *MockINamespaceRepositoryMockRecorder) ListNamespaces(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNamespaces", reflect.TypeOf((*MockINamespaceRepository)(nil).ListNamespaces), arg0, arg1)
+}
+
+// UpdateNamespace mocks base method.
+func (m *MockINamespaceRepository) UpdateNamespace(arg0 context.Context, arg1 entity.UserIDEntity, arg2 entity.NamespaceEntity) (entity.NamespaceEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNamespace", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.NamespaceEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNamespace indicates an expected call of UpdateNamespace.
+func (mr *MockINamespaceRepositoryMockRecorder) UpdateNamespace(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNamespace", reflect.TypeOf((*MockINamespaceRepository)(nil).UpdateNamespace), arg0, arg1, arg2)
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IOidcClientRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIOidcClientRepository is a mock of IOidcClientRepository interface.
+type MockIOidcClientRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIOidcClientRepositoryMockRecorder
+}
+
+// MockIOidcClientRepositoryMockRecorder is the mock recorder for MockIOidcClientRepository.
+type MockIOidcClientRepositoryMockRecorder struct {
+	mock *MockIOidcClientRepository
+}
+
+// NewMockIOidcClientRepository creates a new mock instance.
+func NewMockIOidcClientRepository(ctrl *gomock.Controller) *MockIOidcClientRepository {
+	mock := &MockIOidcClientRepository{ctrl: ctrl}
+	mock.recorder = &MockIOidcClientRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIOidcClientRepository) EXPECT() *MockIOidcClientRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIOidcClientRepository) Create(arg0 context.Context, arg1 entity.OidcClientEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIOidcClientRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIOidcClientRepository)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockIOidcClientRepository) Delete(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIOidcClientRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIOidcClientRepository)(nil).Delete), arg0, arg1)
+}
+
+// GetByID mocks base method.
+func (m *MockIOidcClientRepository) GetByID(arg0 context.Context, arg1 string) (entity.OidcClientEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", arg0, arg1)
+	ret0, _ := ret[0].(entity.OidcClientEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockIOidcClientRepositoryMockRecorder) GetByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockIOidcClientRepository)(nil).GetByID), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockIOidcClientRepository) List(arg0 context.Context) ([]entity.OidcClientEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0)
+	ret0, _ := ret[0].([]entity.OidcClientEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIOidcClientRepositoryMockRecorder) List(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIOidcClientRepository)(nil).List), arg0)
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IOidcSigningKeyRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIOidcSigningKeyRepository is a mock of IOidcSigningKeyRepository interface.
+type MockIOidcSigningKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIOidcSigningKeyRepositoryMockRecorder
+}
+
+// MockIOidcSigningKeyRepositoryMockRecorder is the mock recorder for MockIOidcSigningKeyRepository.
+type MockIOidcSigningKeyRepositoryMockRecorder struct {
+	mock *MockIOidcSigningKeyRepository
+}
+
+// NewMockIOidcSigningKeyRepository creates a new mock instance.
+func NewMockIOidcSigningKeyRepository(ctrl *gomock.Controller) *MockIOidcSigningKeyRepository {
+	mock := &MockIOidcSigningKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockIOidcSigningKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIOidcSigningKeyRepository) EXPECT() *MockIOidcSigningKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIOidcSigningKeyRepository) Create(arg0 context.Context, arg1 entity.OidcSigningKeyEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIOidcSigningKeyRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIOidcSigningKeyRepository)(nil).Create), arg0, arg1)
+}
+
+// List mocks base method.
+func (m *MockIOidcSigningKeyRepository) List(arg0 context.Context) ([]entity.OidcSigningKeyEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0)
+	ret0, _ := ret[0].([]entity.OidcSigningKeyEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIOidcSigningKeyRepositoryMockRecorder) List(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIOidcSigningKeyRepository)(nil).List), arg0)
+}
@@ -122,6 +122,20 @@ func (mr *MockIPasskeyRepositoryMockRecorder) UpdateLastUsedAt(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastUsedAt", reflect.TypeOf((*MockIPasskeyRepository)(nil).UpdateLastUsedAt), arg0, arg1)
 }
 
+// UpdateName mocks base method.
+func (m *MockIPasskeyRepository) UpdateName(arg0 context.Context, arg1 int64, arg2 entity.UserIDEntity, arg3 *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateName", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateName indicates an expected call of UpdateName.
+func (mr *MockIPasskeyRepositoryMockRecorder) UpdateName(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateName", reflect.TypeOf((*MockIPasskeyRepository)(nil).UpdateName), arg0, arg1, arg2, arg3)
+}
+
 // UpdateSignCount mocks base method.
 func (m *MockIPasskeyRepository) UpdateSignCount(arg0 context.Context, arg1, arg2 int64) error {
 	m.ctrl.T.Helper()
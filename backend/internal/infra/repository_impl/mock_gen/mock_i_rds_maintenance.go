@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IRdsMaintenance)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIRdsMaintenance is a mock of IRdsMaintenance interface.
+type MockIRdsMaintenance struct {
+	ctrl     *gomock.Controller
+	recorder *MockIRdsMaintenanceMockRecorder
+}
+
+// MockIRdsMaintenanceMockRecorder is the mock recorder for MockIRdsMaintenance.
+type MockIRdsMaintenanceMockRecorder struct {
+	mock *MockIRdsMaintenance
+}
+
+// NewMockIRdsMaintenance creates a new mock instance.
+func NewMockIRdsMaintenance(ctrl *gomock.Controller) *MockIRdsMaintenance {
+	mock := &MockIRdsMaintenance{ctrl: ctrl}
+	mock.recorder = &MockIRdsMaintenanceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIRdsMaintenance) EXPECT() *MockIRdsMaintenanceMockRecorder {
+	return m.recorder
+}
+
+// Backup mocks base method.
+func (m *MockIRdsMaintenance) Backup(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Backup", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Backup indicates an expected call of Backup.
+func (mr *MockIRdsMaintenanceMockRecorder) Backup(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Backup", reflect.TypeOf((*MockIRdsMaintenance)(nil).Backup), arg0, arg1)
+}
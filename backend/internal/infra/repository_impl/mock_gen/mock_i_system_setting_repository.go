@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: ISystemSettingRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockISystemSettingRepository is a mock of ISystemSettingRepository interface.
+type MockISystemSettingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockISystemSettingRepositoryMockRecorder
+}
+
+// MockISystemSettingRepositoryMockRecorder is the mock recorder for MockISystemSettingRepository.
+type MockISystemSettingRepositoryMockRecorder struct {
+	mock *MockISystemSettingRepository
+}
+
+// NewMockISystemSettingRepository creates a new mock instance.
+func NewMockISystemSettingRepository(ctrl *gomock.Controller) *MockISystemSettingRepository {
+	mock := &MockISystemSettingRepository{ctrl: ctrl}
+	mock.recorder = &MockISystemSettingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISystemSettingRepository) EXPECT() *MockISystemSettingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AllSettings mocks base method.
+func (m *MockISystemSettingRepository) AllSettings(arg0 context.Context) ([]entity.SystemSettingEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllSettings", arg0)
+	ret0, _ := ret[0].([]entity.SystemSettingEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllSettings indicates an expected call of AllSettings.
+func (mr *MockISystemSettingRepositoryMockRecorder) AllSettings(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllSettings", reflect.TypeOf((*MockISystemSettingRepository)(nil).AllSettings), arg0)
+}
+
+// DeleteSetting mocks base method.
+func (m *MockISystemSettingRepository) DeleteSetting(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSetting", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSetting indicates an expected call of DeleteSetting.
+func (mr *MockISystemSettingRepositoryMockRecorder) DeleteSetting(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSetting", reflect.TypeOf((*MockISystemSettingRepository)(nil).DeleteSetting), arg0, arg1)
+}
+
+// SaveSetting mocks base method.
+func (m *MockISystemSettingRepository) SaveSetting(arg0 context.Context, arg1 entity.SystemSettingEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSetting", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSetting indicates an expected call of SaveSetting.
+func (mr *MockISystemSettingRepositoryMockRecorder) SaveSetting(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSetting", reflect.TypeOf((*MockISystemSettingRepository)(nil).SaveSetting), arg0, arg1)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IToolEventRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIToolEventRepository is a mock of IToolEventRepository interface.
+type MockIToolEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIToolEventRepositoryMockRecorder
+}
+
+// MockIToolEventRepositoryMockRecorder is the mock recorder for MockIToolEventRepository.
+type MockIToolEventRepositoryMockRecorder struct {
+	mock *MockIToolEventRepository
+}
+
+// NewMockIToolEventRepository creates a new mock instance.
+func NewMockIToolEventRepository(ctrl *gomock.Controller) *MockIToolEventRepository {
+	mock := &MockIToolEventRepository{ctrl: ctrl}
+	mock.recorder = &MockIToolEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIToolEventRepository) EXPECT() *MockIToolEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ListEvents mocks base method.
+func (m *MockIToolEventRepository) ListEvents(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string, arg3 int64, arg4 int) ([]entity.ToolEventEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEvents", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]entity.ToolEventEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEvents indicates an expected call of ListEvents.
+func (mr *MockIToolEventRepositoryMockRecorder) ListEvents(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEvents", reflect.TypeOf((*MockIToolEventRepository)(nil).ListEvents), arg0, arg1, arg2, arg3, arg4)
+}
@@ -35,6 +35,50 @@ func (m *MockIToolRepository) EXPECT() *MockIToolRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddTag mocks base method.
+func (m *MockIToolRepository) AddTag(arg0 entity.UserIDEntity, arg1, arg2 string, arg3 entity.ToolEventSourceEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTag", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTag indicates an expected call of AddTag.
+func (mr *MockIToolRepositoryMockRecorder) AddTag(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTag", reflect.TypeOf((*MockIToolRepository)(nil).AddTag), arg0, arg1, arg2, arg3)
+}
+
+// AllCategories mocks base method.
+func (m *MockIToolRepository) AllCategories(arg0 entity.UserIDEntity) ([]entity.ToolCategoryEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllCategories", arg0)
+	ret0, _ := ret[0].([]entity.ToolCategoryEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllCategories indicates an expected call of AllCategories.
+func (mr *MockIToolRepositoryMockRecorder) AllCategories(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllCategories", reflect.TypeOf((*MockIToolRepository)(nil).AllCategories), arg0)
+}
+
+// AllTags mocks base method.
+func (m *MockIToolRepository) AllTags(arg0 entity.UserIDEntity) ([]entity.ToolTagEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllTags", arg0)
+	ret0, _ := ret[0].([]entity.ToolTagEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllTags indicates an expected call of AllTags.
+func (mr *MockIToolRepositoryMockRecorder) AllTags(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllTags", reflect.TypeOf((*MockIToolRepository)(nil).AllTags), arg0)
+}
+
 // AllTools mocks base method.
 func (m *MockIToolRepository) AllTools(arg0 entity.UserIDEntity) (entity.ToolsEntity, error) {
 	m.ctrl.T.Helper()
@@ -51,31 +95,240 @@ func (mr *MockIToolRepositoryMockRecorder) AllTools(arg0 interface{}) *gomock.Ca
 }
 
 // CreateTool mocks base method.
-func (m *MockIToolRepository) CreateTool(arg0 entity.UserIDEntity, arg1 entity.ToolEntity) error {
+func (m *MockIToolRepository) CreateTool(arg0 entity.UserIDEntity, arg1 entity.ToolEntity, arg2 entity.ToolEventSourceEntity) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTool", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateTool", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateTool indicates an expected call of CreateTool.
-func (mr *MockIToolRepositoryMockRecorder) CreateTool(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockIToolRepositoryMockRecorder) CreateTool(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTool", reflect.TypeOf((*MockIToolRepository)(nil).CreateTool), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTool", reflect.TypeOf((*MockIToolRepository)(nil).CreateTool), arg0, arg1, arg2)
 }
 
 // DeleteTool mocks base method.
-func (m *MockIToolRepository) DeleteTool(arg0 entity.UserIDEntity, arg1 string) error {
+func (m *MockIToolRepository) DeleteTool(arg0 entity.UserIDEntity, arg1 string, arg2 entity.ToolEventSourceEntity) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTool", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteTool", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteTool indicates an expected call of DeleteTool.
-func (mr *MockIToolRepositoryMockRecorder) DeleteTool(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockIToolRepositoryMockRecorder) DeleteTool(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTool", reflect.TypeOf((*MockIToolRepository)(nil).DeleteTool), arg0, arg1, arg2)
+}
+
+// DropSearchSources mocks base method.
+func (m *MockIToolRepository) DropSearchSources() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropSearchSources")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropSearchSources indicates an expected call of DropSearchSources.
+func (mr *MockIToolRepositoryMockRecorder) DropSearchSources() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropSearchSources", reflect.TypeOf((*MockIToolRepository)(nil).DropSearchSources))
+}
+
+// EncryptStoredSources mocks base method.
+func (m *MockIToolRepository) EncryptStoredSources() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EncryptStoredSources")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EncryptStoredSources indicates an expected call of EncryptStoredSources.
+func (mr *MockIToolRepositoryMockRecorder) EncryptStoredSources() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EncryptStoredSources", reflect.TypeOf((*MockIToolRepository)(nil).EncryptStoredSources))
+}
+
+// FilterToolsByExtraInfo mocks base method.
+func (m *MockIToolRepository) FilterToolsByExtraInfo(arg0 entity.UserIDEntity, arg1 map[string]string) ([]entity.ToolEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterToolsByExtraInfo", arg0, arg1)
+	ret0, _ := ret[0].([]entity.ToolEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterToolsByExtraInfo indicates an expected call of FilterToolsByExtraInfo.
+func (mr *MockIToolRepositoryMockRecorder) FilterToolsByExtraInfo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterToolsByExtraInfo", reflect.TypeOf((*MockIToolRepository)(nil).FilterToolsByExtraInfo), arg0, arg1)
+}
+
+// GetTool mocks base method.
+func (m *MockIToolRepository) GetTool(arg0 entity.UserIDEntity, arg1 string) (entity.ToolEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTool", arg0, arg1)
+	ret0, _ := ret[0].(entity.ToolEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTool indicates an expected call of GetTool.
+func (mr *MockIToolRepositoryMockRecorder) GetTool(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTool", reflect.TypeOf((*MockIToolRepository)(nil).GetTool), arg0, arg1)
+}
+
+// LatestToolChangeCursor mocks base method.
+func (m *MockIToolRepository) LatestToolChangeCursor(arg0 entity.UserIDEntity) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestToolChangeCursor", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LatestToolChangeCursor indicates an expected call of LatestToolChangeCursor.
+func (mr *MockIToolRepositoryMockRecorder) LatestToolChangeCursor(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestToolChangeCursor", reflect.TypeOf((*MockIToolRepository)(nil).LatestToolChangeCursor), arg0)
+}
+
+// ListTools mocks base method.
+func (m *MockIToolRepository) ListTools(arg0 entity.UserIDEntity, arg1 entity.ToolListOptions) ([]entity.ToolEntity, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTools", arg0, arg1)
+	ret0, _ := ret[0].([]entity.ToolEntity)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListTools indicates an expected call of ListTools.
+func (mr *MockIToolRepositoryMockRecorder) ListTools(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTools", reflect.TypeOf((*MockIToolRepository)(nil).ListTools), arg0, arg1)
+}
+
+// MergeCategories mocks base method.
+func (m *MockIToolRepository) MergeCategories(arg0 entity.UserIDEntity, arg1, arg2 string, arg3 entity.ToolEventSourceEntity) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeCategories", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeCategories indicates an expected call of MergeCategories.
+func (mr *MockIToolRepositoryMockRecorder) MergeCategories(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeCategories", reflect.TypeOf((*MockIToolRepository)(nil).MergeCategories), arg0, arg1, arg2, arg3)
+}
+
+// RemoveTag mocks base method.
+func (m *MockIToolRepository) RemoveTag(arg0 entity.UserIDEntity, arg1, arg2 string, arg3 entity.ToolEventSourceEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTag", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTag indicates an expected call of RemoveTag.
+func (mr *MockIToolRepositoryMockRecorder) RemoveTag(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTag", reflect.TypeOf((*MockIToolRepository)(nil).RemoveTag), arg0, arg1, arg2, arg3)
+}
+
+// RenameCategory mocks base method.
+func (m *MockIToolRepository) RenameCategory(arg0 entity.UserIDEntity, arg1, arg2 string, arg3 entity.ToolEventSourceEntity) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameCategory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameCategory indicates an expected call of RenameCategory.
+func (mr *MockIToolRepositoryMockRecorder) RenameCategory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameCategory", reflect.TypeOf((*MockIToolRepository)(nil).RenameCategory), arg0, arg1, arg2, arg3)
+}
+
+// SearchByTag mocks base method.
+func (m *MockIToolRepository) SearchByTag(arg0 entity.UserIDEntity, arg1 string) ([]entity.ToolEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchByTag", arg0, arg1)
+	ret0, _ := ret[0].([]entity.ToolEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchByTag indicates an expected call of SearchByTag.
+func (mr *MockIToolRepositoryMockRecorder) SearchByTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchByTag", reflect.TypeOf((*MockIToolRepository)(nil).SearchByTag), arg0, arg1)
+}
+
+// SearchFullText mocks base method.
+func (m *MockIToolRepository) SearchFullText(arg0 entity.UserIDEntity, arg1 []string, arg2 int) ([]entity.ToolEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchFullText", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entity.ToolEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchFullText indicates an expected call of SearchFullText.
+func (mr *MockIToolRepositoryMockRecorder) SearchFullText(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFullText", reflect.TypeOf((*MockIToolRepository)(nil).SearchFullText), arg0, arg1, arg2)
+}
+
+// SetToolArchived mocks base method.
+func (m *MockIToolRepository) SetToolArchived(arg0 entity.UserIDEntity, arg1 string, arg2 bool, arg3 entity.ToolEventSourceEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetToolArchived", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetToolArchived indicates an expected call of SetToolArchived.
+func (mr *MockIToolRepositoryMockRecorder) SetToolArchived(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTool", reflect.TypeOf((*MockIToolRepository)(nil).DeleteTool), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetToolArchived", reflect.TypeOf((*MockIToolRepository)(nil).SetToolArchived), arg0, arg1, arg2, arg3)
+}
+
+// ToolChangesPage mocks base method.
+func (m *MockIToolRepository) ToolChangesPage(arg0 entity.UserIDEntity, arg1, arg2 int64, arg3 int) (entity.ToolChangesEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ToolChangesPage", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(entity.ToolChangesEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ToolChangesPage indicates an expected call of ToolChangesPage.
+func (mr *MockIToolRepositoryMockRecorder) ToolChangesPage(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToolChangesPage", reflect.TypeOf((*MockIToolRepository)(nil).ToolChangesPage), arg0, arg1, arg2, arg3)
+}
+
+// ToolChangesSince mocks base method.
+func (m *MockIToolRepository) ToolChangesSince(arg0 entity.UserIDEntity, arg1 int64) (entity.ToolChangesEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ToolChangesSince", arg0, arg1)
+	ret0, _ := ret[0].(entity.ToolChangesEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ToolChangesSince indicates an expected call of ToolChangesSince.
+func (mr *MockIToolRepositoryMockRecorder) ToolChangesSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToolChangesSince", reflect.TypeOf((*MockIToolRepository)(nil).ToolChangesSince), arg0, arg1)
 }
 
 // ToolsLastUpdatedAt mocks base method.
@@ -93,16 +346,31 @@ func (mr *MockIToolRepositoryMockRecorder) ToolsLastUpdatedAt(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToolsLastUpdatedAt", reflect.TypeOf((*MockIToolRepository)(nil).ToolsLastUpdatedAt), arg0)
 }
 
+// ToolsUpdatedSince mocks base method.
+func (m *MockIToolRepository) ToolsUpdatedSince(arg0 entity.UserIDEntity, arg1 time.Time) (entity.ToolsUpdatedSinceEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ToolsUpdatedSince", arg0, arg1)
+	ret0, _ := ret[0].(entity.ToolsUpdatedSinceEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ToolsUpdatedSince indicates an expected call of ToolsUpdatedSince.
+func (mr *MockIToolRepositoryMockRecorder) ToolsUpdatedSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToolsUpdatedSince", reflect.TypeOf((*MockIToolRepository)(nil).ToolsUpdatedSince), arg0, arg1)
+}
+
 // UpdateTool mocks base method.
-func (m *MockIToolRepository) UpdateTool(arg0 entity.UserIDEntity, arg1 entity.ToolEntity) error {
+func (m *MockIToolRepository) UpdateTool(arg0 entity.UserIDEntity, arg1 entity.ToolEntity, arg2 entity.ToolEventSourceEntity) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateTool", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateTool", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateTool indicates an expected call of UpdateTool.
-func (mr *MockIToolRepositoryMockRecorder) UpdateTool(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockIToolRepositoryMockRecorder) UpdateTool(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTool", reflect.TypeOf((*MockIToolRepository)(nil).UpdateTool), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTool", reflect.TypeOf((*MockIToolRepository)(nil).UpdateTool), arg0, arg1, arg2)
 }
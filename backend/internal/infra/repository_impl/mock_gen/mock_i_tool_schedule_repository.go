@@ -0,0 +1,184 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IToolScheduleRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIToolScheduleRepository is a mock of IToolScheduleRepository interface.
+type MockIToolScheduleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIToolScheduleRepositoryMockRecorder
+}
+
+// MockIToolScheduleRepositoryMockRecorder is the mock recorder for MockIToolScheduleRepository.
+type MockIToolScheduleRepositoryMockRecorder struct {
+	mock *MockIToolScheduleRepository
+}
+
+// NewMockIToolScheduleRepository creates a new mock instance.
+func NewMockIToolScheduleRepository(ctrl *gomock.Controller) *MockIToolScheduleRepository {
+	mock := &MockIToolScheduleRepository{ctrl: ctrl}
+	mock.recorder = &MockIToolScheduleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIToolScheduleRepository) EXPECT() *MockIToolScheduleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddRun mocks base method.
+func (m *MockIToolScheduleRepository) AddRun(arg0 context.Context, arg1 entity.ToolRunEntity, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRun", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRun indicates an expected call of AddRun.
+func (mr *MockIToolScheduleRepositoryMockRecorder) AddRun(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRun", reflect.TypeOf((*MockIToolScheduleRepository)(nil).AddRun), arg0, arg1, arg2)
+}
+
+// ClaimSchedule mocks base method.
+func (m *MockIToolScheduleRepository) ClaimSchedule(arg0 context.Context, arg1 string, arg2, arg3 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimSchedule", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimSchedule indicates an expected call of ClaimSchedule.
+func (mr *MockIToolScheduleRepositoryMockRecorder) ClaimSchedule(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimSchedule", reflect.TypeOf((*MockIToolScheduleRepository)(nil).ClaimSchedule), arg0, arg1, arg2, arg3)
+}
+
+// CountSchedules mocks base method.
+func (m *MockIToolScheduleRepository) CountSchedules(arg0 context.Context, arg1 entity.UserIDEntity) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSchedules", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSchedules indicates an expected call of CountSchedules.
+func (mr *MockIToolScheduleRepositoryMockRecorder) CountSchedules(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSchedules", reflect.TypeOf((*MockIToolScheduleRepository)(nil).CountSchedules), arg0, arg1)
+}
+
+// CreateSchedule mocks base method.
+func (m *MockIToolScheduleRepository) CreateSchedule(arg0 context.Context, arg1 entity.ToolScheduleEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSchedule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSchedule indicates an expected call of CreateSchedule.
+func (mr *MockIToolScheduleRepositoryMockRecorder) CreateSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedule", reflect.TypeOf((*MockIToolScheduleRepository)(nil).CreateSchedule), arg0, arg1)
+}
+
+// DeleteSchedule mocks base method.
+func (m *MockIToolScheduleRepository) DeleteSchedule(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSchedule", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSchedule indicates an expected call of DeleteSchedule.
+func (mr *MockIToolScheduleRepositoryMockRecorder) DeleteSchedule(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSchedule", reflect.TypeOf((*MockIToolScheduleRepository)(nil).DeleteSchedule), arg0, arg1, arg2)
+}
+
+// DueSchedules mocks base method.
+func (m *MockIToolScheduleRepository) DueSchedules(arg0 context.Context, arg1 time.Time, arg2 int) ([]entity.ToolScheduleEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DueSchedules", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entity.ToolScheduleEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DueSchedules indicates an expected call of DueSchedules.
+func (mr *MockIToolScheduleRepositoryMockRecorder) DueSchedules(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DueSchedules", reflect.TypeOf((*MockIToolScheduleRepository)(nil).DueSchedules), arg0, arg1, arg2)
+}
+
+// GetSchedule mocks base method.
+func (m *MockIToolScheduleRepository) GetSchedule(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) (entity.ToolScheduleEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedule", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.ToolScheduleEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSchedule indicates an expected call of GetSchedule.
+func (mr *MockIToolScheduleRepositoryMockRecorder) GetSchedule(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedule", reflect.TypeOf((*MockIToolScheduleRepository)(nil).GetSchedule), arg0, arg1, arg2)
+}
+
+// ListRuns mocks base method.
+func (m *MockIToolScheduleRepository) ListRuns(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string, arg3 int) ([]entity.ToolRunEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRuns", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]entity.ToolRunEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRuns indicates an expected call of ListRuns.
+func (mr *MockIToolScheduleRepositoryMockRecorder) ListRuns(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRuns", reflect.TypeOf((*MockIToolScheduleRepository)(nil).ListRuns), arg0, arg1, arg2, arg3)
+}
+
+// ListSchedules mocks base method.
+func (m *MockIToolScheduleRepository) ListSchedules(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) ([]entity.ToolScheduleEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSchedules", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entity.ToolScheduleEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSchedules indicates an expected call of ListSchedules.
+func (mr *MockIToolScheduleRepositoryMockRecorder) ListSchedules(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSchedules", reflect.TypeOf((*MockIToolScheduleRepository)(nil).ListSchedules), arg0, arg1, arg2)
+}
+
+// UpdateSchedule mocks base method.
+func (m *MockIToolScheduleRepository) UpdateSchedule(arg0 context.Context, arg1 entity.ToolScheduleEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSchedule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSchedule indicates an expected call of UpdateSchedule.
+func (mr *MockIToolScheduleRepositoryMockRecorder) UpdateSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSchedule", reflect.TypeOf((*MockIToolScheduleRepository)(nil).UpdateSchedule), arg0, arg1)
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IToolSecretRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIToolSecretRepository is a mock of IToolSecretRepository interface.
+type MockIToolSecretRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIToolSecretRepositoryMockRecorder
+}
+
+// MockIToolSecretRepositoryMockRecorder is the mock recorder for MockIToolSecretRepository.
+type MockIToolSecretRepositoryMockRecorder struct {
+	mock *MockIToolSecretRepository
+}
+
+// NewMockIToolSecretRepository creates a new mock instance.
+func NewMockIToolSecretRepository(ctrl *gomock.Controller) *MockIToolSecretRepository {
+	mock := &MockIToolSecretRepository{ctrl: ctrl}
+	mock.recorder = &MockIToolSecretRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIToolSecretRepository) EXPECT() *MockIToolSecretRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteSecret mocks base method.
+func (m *MockIToolSecretRepository) DeleteSecret(arg0 context.Context, arg1 entity.UserIDEntity, arg2, arg3 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSecret", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSecret indicates an expected call of DeleteSecret.
+func (mr *MockIToolSecretRepositoryMockRecorder) DeleteSecret(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSecret", reflect.TypeOf((*MockIToolSecretRepository)(nil).DeleteSecret), arg0, arg1, arg2, arg3)
+}
+
+// ListSecrets mocks base method.
+func (m *MockIToolSecretRepository) ListSecrets(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) ([]entity.ToolSecretEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecrets", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entity.ToolSecretEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecrets indicates an expected call of ListSecrets.
+func (mr *MockIToolSecretRepositoryMockRecorder) ListSecrets(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecrets", reflect.TypeOf((*MockIToolSecretRepository)(nil).ListSecrets), arg0, arg1, arg2)
+}
+
+// PutSecret mocks base method.
+func (m *MockIToolSecretRepository) PutSecret(arg0 context.Context, arg1 entity.UserIDEntity, arg2, arg3, arg4 string) (entity.ToolSecretEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutSecret", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(entity.ToolSecretEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutSecret indicates an expected call of PutSecret.
+func (mr *MockIToolSecretRepositoryMockRecorder) PutSecret(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutSecret", reflect.TypeOf((*MockIToolSecretRepository)(nil).PutSecret), arg0, arg1, arg2, arg3, arg4)
+}
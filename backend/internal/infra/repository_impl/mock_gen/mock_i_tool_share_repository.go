@@ -0,0 +1,112 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IToolShareRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIToolShareRepository is a mock of IToolShareRepository interface.
+type MockIToolShareRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIToolShareRepositoryMockRecorder
+}
+
+// MockIToolShareRepositoryMockRecorder is the mock recorder for MockIToolShareRepository.
+type MockIToolShareRepositoryMockRecorder struct {
+	mock *MockIToolShareRepository
+}
+
+// NewMockIToolShareRepository creates a new mock instance.
+func NewMockIToolShareRepository(ctrl *gomock.Controller) *MockIToolShareRepository {
+	mock := &MockIToolShareRepository{ctrl: ctrl}
+	mock.recorder = &MockIToolShareRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIToolShareRepository) EXPECT() *MockIToolShareRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteShare mocks base method.
+func (m *MockIToolShareRepository) DeleteShare(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteShare", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteShare indicates an expected call of DeleteShare.
+func (mr *MockIToolShareRepositoryMockRecorder) DeleteShare(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteShare", reflect.TypeOf((*MockIToolShareRepository)(nil).DeleteShare), arg0, arg1, arg2)
+}
+
+// GetShare mocks base method.
+func (m *MockIToolShareRepository) GetShare(arg0 context.Context, arg1 string) (entity.ToolShareEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShare", arg0, arg1)
+	ret0, _ := ret[0].(entity.ToolShareEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetShare indicates an expected call of GetShare.
+func (mr *MockIToolShareRepositoryMockRecorder) GetShare(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShare", reflect.TypeOf((*MockIToolShareRepository)(nil).GetShare), arg0, arg1)
+}
+
+// ListSharesOfTool mocks base method.
+func (m *MockIToolShareRepository) ListSharesOfTool(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) ([]entity.ToolShareEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSharesOfTool", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entity.ToolShareEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSharesOfTool indicates an expected call of ListSharesOfTool.
+func (mr *MockIToolShareRepositoryMockRecorder) ListSharesOfTool(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSharesOfTool", reflect.TypeOf((*MockIToolShareRepository)(nil).ListSharesOfTool), arg0, arg1, arg2)
+}
+
+// ListSharesWithUser mocks base method.
+func (m *MockIToolShareRepository) ListSharesWithUser(arg0 context.Context, arg1 entity.UserIDEntity) ([]entity.ToolShareEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSharesWithUser", arg0, arg1)
+	ret0, _ := ret[0].([]entity.ToolShareEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSharesWithUser indicates an expected call of ListSharesWithUser.
+func (mr *MockIToolShareRepositoryMockRecorder) ListSharesWithUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSharesWithUser", reflect.TypeOf((*MockIToolShareRepository)(nil).ListSharesWithUser), arg0, arg1)
+}
+
+// PutShare mocks base method.
+func (m *MockIToolShareRepository) PutShare(arg0 context.Context, arg1 entity.ToolShareEntity) (entity.ToolShareEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutShare", arg0, arg1)
+	ret0, _ := ret[0].(entity.ToolShareEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutShare indicates an expected call of PutShare.
+func (mr *MockIToolShareRepositoryMockRecorder) PutShare(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutShare", reflect.TypeOf((*MockIToolShareRepository)(nil).PutShare), arg0, arg1)
+}
@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IToolVersionRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIToolVersionRepository is a mock of IToolVersionRepository interface.
+type MockIToolVersionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIToolVersionRepositoryMockRecorder
+}
+
+// MockIToolVersionRepositoryMockRecorder is the mock recorder for MockIToolVersionRepository.
+type MockIToolVersionRepositoryMockRecorder struct {
+	mock *MockIToolVersionRepository
+}
+
+// NewMockIToolVersionRepository creates a new mock instance.
+func NewMockIToolVersionRepository(ctrl *gomock.Controller) *MockIToolVersionRepository {
+	mock := &MockIToolVersionRepository{ctrl: ctrl}
+	mock.recorder = &MockIToolVersionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIToolVersionRepository) EXPECT() *MockIToolVersionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetVersion mocks base method.
+func (m *MockIToolVersionRepository) GetVersion(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string, arg3 int) (entity.ToolVersionEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(entity.ToolVersionEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockIToolVersionRepositoryMockRecorder) GetVersion(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockIToolVersionRepository)(nil).GetVersion), arg0, arg1, arg2, arg3)
+}
+
+// ListVersions mocks base method.
+func (m *MockIToolVersionRepository) ListVersions(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) ([]entity.ToolVersionEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVersions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entity.ToolVersionEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVersions indicates an expected call of ListVersions.
+func (mr *MockIToolVersionRepositoryMockRecorder) ListVersions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVersions", reflect.TypeOf((*MockIToolVersionRepository)(nil).ListVersions), arg0, arg1, arg2)
+}
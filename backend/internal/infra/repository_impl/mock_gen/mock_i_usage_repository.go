@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IUsageRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIUsageRepository is a mock of IUsageRepository interface.
+type MockIUsageRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIUsageRepositoryMockRecorder
+}
+
+// MockIUsageRepositoryMockRecorder is the mock recorder for MockIUsageRepository.
+type MockIUsageRepositoryMockRecorder struct {
+	mock *MockIUsageRepository
+}
+
+// NewMockIUsageRepository creates a new mock instance.
+func NewMockIUsageRepository(ctrl *gomock.Controller) *MockIUsageRepository {
+	mock := &MockIUsageRepository{ctrl: ctrl}
+	mock.recorder = &MockIUsageRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIUsageRepository) EXPECT() *MockIUsageRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddUsage mocks base method.
+func (m *MockIUsageRepository) AddUsage(arg0 context.Context, arg1 entity.UserIDEntity, arg2 entity.UsageMetric, arg3 string, arg4 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUsage", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddUsage indicates an expected call of AddUsage.
+func (mr *MockIUsageRepositoryMockRecorder) AddUsage(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUsage", reflect.TypeOf((*MockIUsageRepository)(nil).AddUsage), arg0, arg1, arg2, arg3, arg4)
+}
+
+// PeriodUsage mocks base method.
+func (m *MockIUsageRepository) PeriodUsage(arg0 context.Context, arg1 string) ([]entity.UsageEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeriodUsage", arg0, arg1)
+	ret0, _ := ret[0].([]entity.UsageEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeriodUsage indicates an expected call of PeriodUsage.
+func (mr *MockIUsageRepositoryMockRecorder) PeriodUsage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeriodUsage", reflect.TypeOf((*MockIUsageRepository)(nil).PeriodUsage), arg0, arg1)
+}
+
+// SetUsage mocks base method.
+func (m *MockIUsageRepository) SetUsage(arg0 context.Context, arg1 entity.UserIDEntity, arg2 entity.UsageMetric, arg3 string, arg4 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUsage", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUsage indicates an expected call of SetUsage.
+func (mr *MockIUsageRepositoryMockRecorder) SetUsage(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUsage", reflect.TypeOf((*MockIUsageRepository)(nil).SetUsage), arg0, arg1, arg2, arg3, arg4)
+}
+
+// UserUsage mocks base method.
+func (m *MockIUsageRepository) UserUsage(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) ([]entity.UsageEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserUsage", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entity.UsageEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserUsage indicates an expected call of UserUsage.
+func (mr *MockIUsageRepositoryMockRecorder) UserUsage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserUsage", reflect.TypeOf((*MockIUsageRepository)(nil).UserUsage), arg0, arg1, arg2)
+}
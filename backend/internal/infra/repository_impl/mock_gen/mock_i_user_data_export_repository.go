@@ -0,0 +1,186 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IUserDataExportRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIUserDataExportRepository is a mock of IUserDataExportRepository interface.
+type MockIUserDataExportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIUserDataExportRepositoryMockRecorder
+}
+
+// MockIUserDataExportRepositoryMockRecorder is the mock recorder for MockIUserDataExportRepository.
+type MockIUserDataExportRepositoryMockRecorder struct {
+	mock *MockIUserDataExportRepository
+}
+
+// NewMockIUserDataExportRepository creates a new mock instance.
+func NewMockIUserDataExportRepository(ctrl *gomock.Controller) *MockIUserDataExportRepository {
+	mock := &MockIUserDataExportRepository{ctrl: ctrl}
+	mock.recorder = &MockIUserDataExportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIUserDataExportRepository) EXPECT() *MockIUserDataExportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ActiveExport mocks base method.
+func (m *MockIUserDataExportRepository) ActiveExport(arg0 context.Context, arg1 entity.UserIDEntity) (entity.UserDataExportEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActiveExport", arg0, arg1)
+	ret0, _ := ret[0].(entity.UserDataExportEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ActiveExport indicates an expected call of ActiveExport.
+func (mr *MockIUserDataExportRepositoryMockRecorder) ActiveExport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveExport", reflect.TypeOf((*MockIUserDataExportRepository)(nil).ActiveExport), arg0, arg1)
+}
+
+// ClaimExport mocks base method.
+func (m *MockIUserDataExportRepository) ClaimExport(arg0 context.Context, arg1 string, arg2, arg3 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimExport", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimExport indicates an expected call of ClaimExport.
+func (mr *MockIUserDataExportRepositoryMockRecorder) ClaimExport(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimExport", reflect.TypeOf((*MockIUserDataExportRepository)(nil).ClaimExport), arg0, arg1, arg2, arg3)
+}
+
+// CreateExport mocks base method.
+func (m *MockIUserDataExportRepository) CreateExport(arg0 context.Context, arg1 entity.UserDataExportEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExport", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateExport indicates an expected call of CreateExport.
+func (mr *MockIUserDataExportRepositoryMockRecorder) CreateExport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExport", reflect.TypeOf((*MockIUserDataExportRepository)(nil).CreateExport), arg0, arg1)
+}
+
+// DeleteFinishedBefore mocks base method.
+func (m *MockIUserDataExportRepository) DeleteFinishedBefore(arg0 context.Context, arg1 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFinishedBefore", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFinishedBefore indicates an expected call of DeleteFinishedBefore.
+func (mr *MockIUserDataExportRepositoryMockRecorder) DeleteFinishedBefore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFinishedBefore", reflect.TypeOf((*MockIUserDataExportRepository)(nil).DeleteFinishedBefore), arg0, arg1)
+}
+
+// FailExport mocks base method.
+func (m *MockIUserDataExportRepository) FailExport(arg0 context.Context, arg1, arg2 string, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailExport", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailExport indicates an expected call of FailExport.
+func (mr *MockIUserDataExportRepositoryMockRecorder) FailExport(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailExport", reflect.TypeOf((*MockIUserDataExportRepository)(nil).FailExport), arg0, arg1, arg2, arg3)
+}
+
+// FinishExport mocks base method.
+func (m *MockIUserDataExportRepository) FinishExport(arg0 context.Context, arg1 string, arg2 entity.UserDataArchiveEntity, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishExport", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishExport indicates an expected call of FinishExport.
+func (mr *MockIUserDataExportRepositoryMockRecorder) FinishExport(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishExport", reflect.TypeOf((*MockIUserDataExportRepository)(nil).FinishExport), arg0, arg1, arg2, arg3)
+}
+
+// GetArchive mocks base method.
+func (m *MockIUserDataExportRepository) GetArchive(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) (entity.UserDataArchiveEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArchive", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.UserDataArchiveEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetArchive indicates an expected call of GetArchive.
+func (mr *MockIUserDataExportRepositoryMockRecorder) GetArchive(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArchive", reflect.TypeOf((*MockIUserDataExportRepository)(nil).GetArchive), arg0, arg1, arg2)
+}
+
+// GetExport mocks base method.
+func (m *MockIUserDataExportRepository) GetExport(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) (entity.UserDataExportEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExport", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.UserDataExportEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetExport indicates an expected call of GetExport.
+func (mr *MockIUserDataExportRepositoryMockRecorder) GetExport(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExport", reflect.TypeOf((*MockIUserDataExportRepository)(nil).GetExport), arg0, arg1, arg2)
+}
+
+// PendingExports mocks base method.
+func (m *MockIUserDataExportRepository) PendingExports(arg0 context.Context, arg1 time.Time, arg2 int) ([]entity.UserDataExportEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingExports", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entity.UserDataExportEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingExports indicates an expected call of PendingExports.
+func (mr *MockIUserDataExportRepositoryMockRecorder) PendingExports(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingExports", reflect.TypeOf((*MockIUserDataExportRepository)(nil).PendingExports), arg0, arg1, arg2)
+}
+
+// UpdateProgress mocks base method.
+func (m *MockIUserDataExportRepository) UpdateProgress(arg0 context.Context, arg1 string, arg2 int, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProgress", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProgress indicates an expected call of UpdateProgress.
+func (mr *MockIUserDataExportRepositoryMockRecorder) UpdateProgress(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProgress", reflect.TypeOf((*MockIUserDataExportRepository)(nil).UpdateProgress), arg0, arg1, arg2, arg3)
+}
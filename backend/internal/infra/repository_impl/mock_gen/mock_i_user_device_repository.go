@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IUserDeviceRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIUserDeviceRepository is a mock of IUserDeviceRepository interface.
+type MockIUserDeviceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIUserDeviceRepositoryMockRecorder
+}
+
+// MockIUserDeviceRepositoryMockRecorder is the mock recorder for MockIUserDeviceRepository.
+type MockIUserDeviceRepositoryMockRecorder struct {
+	mock *MockIUserDeviceRepository
+}
+
+// NewMockIUserDeviceRepository creates a new mock instance.
+func NewMockIUserDeviceRepository(ctrl *gomock.Controller) *MockIUserDeviceRepository {
+	mock := &MockIUserDeviceRepository{ctrl: ctrl}
+	mock.recorder = &MockIUserDeviceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIUserDeviceRepository) EXPECT() *MockIUserDeviceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AllDevices mocks base method.
+func (m *MockIUserDeviceRepository) AllDevices(arg0 context.Context, arg1 entity.UserIDEntity) ([]entity.UserDeviceEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllDevices", arg0, arg1)
+	ret0, _ := ret[0].([]entity.UserDeviceEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllDevices indicates an expected call of AllDevices.
+func (mr *MockIUserDeviceRepositoryMockRecorder) AllDevices(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllDevices", reflect.TypeOf((*MockIUserDeviceRepository)(nil).AllDevices), arg0, arg1)
+}
+
+// Create mocks base method.
+func (m *MockIUserDeviceRepository) Create(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) (entity.UserDeviceEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.UserDeviceEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIUserDeviceRepositoryMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIUserDeviceRepository)(nil).Create), arg0, arg1, arg2)
+}
+
+// Delete mocks base method.
+func (m *MockIUserDeviceRepository) Delete(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIUserDeviceRepositoryMockRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIUserDeviceRepository)(nil).Delete), arg0, arg1, arg2)
+}
+
+// Get mocks base method.
+func (m *MockIUserDeviceRepository) Get(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) (entity.UserDeviceEntity, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.UserDeviceEntity)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockIUserDeviceRepositoryMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockIUserDeviceRepository)(nil).Get), arg0, arg1, arg2)
+}
+
+// Touch mocks base method.
+func (m *MockIUserDeviceRepository) Touch(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockIUserDeviceRepositoryMockRecorder) Touch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockIUserDeviceRepository)(nil).Touch), arg0, arg1, arg2)
+}
+
+// UpdateSyncCursor mocks base method.
+func (m *MockIUserDeviceRepository) UpdateSyncCursor(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string, arg3 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSyncCursor", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSyncCursor indicates an expected call of UpdateSyncCursor.
+func (mr *MockIUserDeviceRepositoryMockRecorder) UpdateSyncCursor(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSyncCursor", reflect.TypeOf((*MockIUserDeviceRepository)(nil).UpdateSyncCursor), arg0, arg1, arg2, arg3)
+}
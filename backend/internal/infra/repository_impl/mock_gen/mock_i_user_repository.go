@@ -7,6 +7,7 @@ package mock_gen
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 	entity "ya-tool-craft/internal/domain/entity"
 
 	gomock "github.com/golang/mock/gomock"
@@ -49,6 +50,21 @@ func (mr *MockIUserRepositoryMockRecorder) AddUserSSOBinding(arg0, arg1, arg2, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserSSOBinding", reflect.TypeOf((*MockIUserRepository)(nil).AddUserSSOBinding), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+// AllUserIDs mocks base method.
+func (m *MockIUserRepository) AllUserIDs(arg0 context.Context) ([]entity.UserIDEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllUserIDs", arg0)
+	ret0, _ := ret[0].([]entity.UserIDEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllUserIDs indicates an expected call of AllUserIDs.
+func (mr *MockIUserRepositoryMockRecorder) AllUserIDs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllUserIDs", reflect.TypeOf((*MockIUserRepository)(nil).AllUserIDs), arg0)
+}
+
 // Create mocks base method.
 func (m *MockIUserRepository) Create(arg0 context.Context, arg1 string, arg2 []entity.UserRoleEntity) (entity.UserEntity, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +109,21 @@ func (mr *MockIUserRepositoryMockRecorder) Delete(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIUserRepository)(nil).Delete), arg0, arg1)
 }
 
+// DeleteUserIfDeletionDue mocks base method.
+func (m *MockIUserRepository) DeleteUserIfDeletionDue(arg0 context.Context, arg1 entity.UserIDEntity, arg2 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserIfDeletionDue", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserIfDeletionDue indicates an expected call of DeleteUserIfDeletionDue.
+func (mr *MockIUserRepositoryMockRecorder) DeleteUserIfDeletionDue(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserIfDeletionDue", reflect.TypeOf((*MockIUserRepository)(nil).DeleteUserIfDeletionDue), arg0, arg1, arg2)
+}
+
 // DeleteUserSSOBinding mocks base method.
 func (m *MockIUserRepository) DeleteUserSSOBinding(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -185,6 +216,22 @@ func (mr *MockIUserRepositoryMockRecorder) GetUserBySSO(arg0, arg1, arg2 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserBySSO", reflect.TypeOf((*MockIUserRepository)(nil).GetUserBySSO), arg0, arg1, arg2)
 }
 
+// GetUserSSOAccessToken mocks base method.
+func (m *MockIUserRepository) GetUserSSOAccessToken(arg0 context.Context, arg1 entity.UserIDEntity, arg2 string) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSSOAccessToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUserSSOAccessToken indicates an expected call of GetUserSSOAccessToken.
+func (mr *MockIUserRepositoryMockRecorder) GetUserSSOAccessToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSSOAccessToken", reflect.TypeOf((*MockIUserRepository)(nil).GetUserSSOAccessToken), arg0, arg1, arg2)
+}
+
 // GetUserSSOBindings mocks base method.
 func (m *MockIUserRepository) GetUserSSOBindings(arg0 context.Context, arg1 entity.UserIDEntity) ([]entity.UserSSOEntity, error) {
 	m.ctrl.T.Helper()
@@ -200,6 +247,136 @@ func (mr *MockIUserRepositoryMockRecorder) GetUserSSOBindings(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSSOBindings", reflect.TypeOf((*MockIUserRepository)(nil).GetUserSSOBindings), arg0, arg1)
 }
 
+// HasUserWithRole mocks base method.
+func (m *MockIUserRepository) HasUserWithRole(arg0 context.Context, arg1 entity.UserRoleEntity) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasUserWithRole", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasUserWithRole indicates an expected call of HasUserWithRole.
+func (mr *MockIUserRepositoryMockRecorder) HasUserWithRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasUserWithRole", reflect.TypeOf((*MockIUserRepository)(nil).HasUserWithRole), arg0, arg1)
+}
+
+// ListUsers mocks base method.
+func (m *MockIUserRepository) ListUsers(arg0 context.Context, arg1 entity.UserFilter) ([]entity.UserSummaryEntity, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]entity.UserSummaryEntity)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockIUserRepositoryMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockIUserRepository)(nil).ListUsers), arg0, arg1)
+}
+
+// MergeUsers mocks base method.
+func (m *MockIUserRepository) MergeUsers(arg0 context.Context, arg1, arg2 entity.UserIDEntity) (entity.UserMergeResultEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entity.UserMergeResultEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeUsers indicates an expected call of MergeUsers.
+func (mr *MockIUserRepositoryMockRecorder) MergeUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeUsers", reflect.TypeOf((*MockIUserRepository)(nil).MergeUsers), arg0, arg1, arg2)
+}
+
+// RecordLogin mocks base method.
+func (m *MockIUserRepository) RecordLogin(arg0 context.Context, arg1 entity.UserIDEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLogin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordLogin indicates an expected call of RecordLogin.
+func (mr *MockIUserRepositoryMockRecorder) RecordLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLogin", reflect.TypeOf((*MockIUserRepository)(nil).RecordLogin), arg0, arg1)
+}
+
+// SetDeletionScheduledAt mocks base method.
+func (m *MockIUserRepository) SetDeletionScheduledAt(arg0 context.Context, arg1 entity.UserIDEntity, arg2 *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDeletionScheduledAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDeletionScheduledAt indicates an expected call of SetDeletionScheduledAt.
+func (mr *MockIUserRepositoryMockRecorder) SetDeletionScheduledAt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeletionScheduledAt", reflect.TypeOf((*MockIUserRepository)(nil).SetDeletionScheduledAt), arg0, arg1, arg2)
+}
+
+// SetDisabled mocks base method.
+func (m *MockIUserRepository) SetDisabled(arg0 context.Context, arg1 entity.UserIDEntity, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDisabled", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDisabled indicates an expected call of SetDisabled.
+func (mr *MockIUserRepositoryMockRecorder) SetDisabled(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDisabled", reflect.TypeOf((*MockIUserRepository)(nil).SetDisabled), arg0, arg1, arg2)
+}
+
+// SetPasswordChangeRequired mocks base method.
+func (m *MockIUserRepository) SetPasswordChangeRequired(arg0 context.Context, arg1 entity.UserIDEntity, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPasswordChangeRequired", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPasswordChangeRequired indicates an expected call of SetPasswordChangeRequired.
+func (mr *MockIUserRepositoryMockRecorder) SetPasswordChangeRequired(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPasswordChangeRequired", reflect.TypeOf((*MockIUserRepository)(nil).SetPasswordChangeRequired), arg0, arg1, arg2)
+}
+
+// SetRoles mocks base method.
+func (m *MockIUserRepository) SetRoles(arg0 context.Context, arg1 entity.UserIDEntity, arg2 []entity.UserRoleEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRoles", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRoles indicates an expected call of SetRoles.
+func (mr *MockIUserRepositoryMockRecorder) SetRoles(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRoles", reflect.TypeOf((*MockIUserRepository)(nil).SetRoles), arg0, arg1, arg2)
+}
+
+// SetUserSSOAccessToken mocks base method.
+func (m *MockIUserRepository) SetUserSSOAccessToken(arg0 context.Context, arg1 entity.UserIDEntity, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserSSOAccessToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserSSOAccessToken indicates an expected call of SetUserSSOAccessToken.
+func (mr *MockIUserRepositoryMockRecorder) SetUserSSOAccessToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserSSOAccessToken", reflect.TypeOf((*MockIUserRepository)(nil).SetUserSSOAccessToken), arg0, arg1, arg2, arg3)
+}
+
 // Update mocks base method.
 func (m *MockIUserRepository) Update(arg0 context.Context, arg1 entity.UserEntity) error {
 	m.ctrl.T.Helper()
@@ -228,6 +405,21 @@ func (mr *MockIUserRepositoryMockRecorder) UpdatePassword(arg0, arg1, arg2 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockIUserRepository)(nil).UpdatePassword), arg0, arg1, arg2)
 }
 
+// UsersDueForDeletion mocks base method.
+func (m *MockIUserRepository) UsersDueForDeletion(arg0 context.Context, arg1 time.Time) ([]entity.UserIDEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsersDueForDeletion", arg0, arg1)
+	ret0, _ := ret[0].([]entity.UserIDEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UsersDueForDeletion indicates an expected call of UsersDueForDeletion.
+func (mr *MockIUserRepositoryMockRecorder) UsersDueForDeletion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsersDueForDeletion", reflect.TypeOf((*MockIUserRepository)(nil).UsersDueForDeletion), arg0, arg1)
+}
+
 // ValidateCredentialsByEmail mocks base method.
 func (m *MockIUserRepository) ValidateCredentialsByEmail(arg0 context.Context, arg1, arg2 string) (entity.UserEntity, bool, error) {
 	m.ctrl.T.Helper()
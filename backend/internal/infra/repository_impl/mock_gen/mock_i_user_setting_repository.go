@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ya-tool-craft/internal/domain/repository (interfaces: IUserSettingRepository)
+
+// Package mock_gen is a generated GoMock package.
+package mock_gen
+
+import (
+	context "context"
+	reflect "reflect"
+	entity "ya-tool-craft/internal/domain/entity"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIUserSettingRepository is a mock of IUserSettingRepository interface.
+type MockIUserSettingRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIUserSettingRepositoryMockRecorder
+}
+
+// MockIUserSettingRepositoryMockRecorder is the mock recorder for MockIUserSettingRepository.
+type MockIUserSettingRepositoryMockRecorder struct {
+	mock *MockIUserSettingRepository
+}
+
+// NewMockIUserSettingRepository creates a new mock instance.
+func NewMockIUserSettingRepository(ctrl *gomock.Controller) *MockIUserSettingRepository {
+	mock := &MockIUserSettingRepository{ctrl: ctrl}
+	mock.recorder = &MockIUserSettingRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIUserSettingRepository) EXPECT() *MockIUserSettingRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AllSettings mocks base method.
+func (m *MockIUserSettingRepository) AllSettings(arg0 context.Context, arg1 entity.UserIDEntity) ([]entity.UserSettingEntity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AllSettings", arg0, arg1)
+	ret0, _ := ret[0].([]entity.UserSettingEntity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllSettings indicates an expected call of AllSettings.
+func (mr *MockIUserSettingRepositoryMockRecorder) AllSettings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllSettings", reflect.TypeOf((*MockIUserSettingRepository)(nil).AllSettings), arg0, arg1)
+}
+
+// DeleteSettings mocks base method.
+func (m *MockIUserSettingRepository) DeleteSettings(arg0 context.Context, arg1 entity.UserIDEntity, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSettings", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSettings indicates an expected call of DeleteSettings.
+func (mr *MockIUserSettingRepositoryMockRecorder) DeleteSettings(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSettings", reflect.TypeOf((*MockIUserSettingRepository)(nil).DeleteSettings), arg0, arg1, arg2)
+}
+
+// SaveSettings mocks base method.
+func (m *MockIUserSettingRepository) SaveSettings(arg0 context.Context, arg1 entity.UserIDEntity, arg2 []entity.UserSettingEntity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSettings", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSettings indicates an expected call of SaveSettings.
+func (mr *MockIUserSettingRepositoryMockRecorder) SaveSettings(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSettings", reflect.TypeOf((*MockIUserSettingRepository)(nil).SaveSettings), arg0, arg1, arg2)
+}
@@ -0,0 +1,148 @@
+package repository_impl
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"time"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+type NamespaceRdsModel struct {
+	UserID                   string    `db:"user_id"`
+	Name                     string    `db:"name"`
+	Description              string    `db:"description"`
+	DefaultCategory          string    `db:"default_category"`
+	DefaultRealtimeExecution bool      `db:"default_realtime_execution"`
+	DefaultUiWidgets         string    `db:"default_ui_widgets"`
+	ToolCount                int       `db:"tool_count"`
+	CreatedAt                time.Time `db:"created_at"`
+	UpdatedAt                time.Time `db:"updated_at"`
+}
+
+func NewNamespaceRepositoryRdsImpl(client repository.IRdsClient, clock domain_client.IClock) *NamespaceRepositoryRdsImpl {
+	return &NamespaceRepositoryRdsImpl{client: client, clock: clock}
+}
+
+type NamespaceRepositoryRdsImpl struct {
+	client repository.IRdsClient
+	clock  domain_client.IClock
+}
+
+func (r *NamespaceRepositoryRdsImpl) ListNamespaces(ctx context.Context, userID entity.UserIDEntity) ([]entity.NamespaceEntity, error) {
+	var models []NamespaceRdsModel
+	err := r.client.DB().SelectContext(ctx, &models,
+		`SELECT n.*, (SELECT COUNT(*) FROM tools t WHERE t.user_id = n.user_id AND t.namespace = n.name) AS tool_count
+		 FROM namespaces n WHERE n.user_id = ? ORDER BY n.name`,
+		string(userID),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list namespaces from rds")
+	}
+
+	namespaces := make([]entity.NamespaceEntity, 0, len(models))
+	for _, model := range models {
+		namespaces = append(namespaces, toNamespaceEntity(model))
+	}
+	return namespaces, nil
+}
+
+func (r *NamespaceRepositoryRdsImpl) GetNamespace(ctx context.Context, userID entity.UserIDEntity, name string) (entity.NamespaceEntity, bool, error) {
+	var model NamespaceRdsModel
+	err := r.client.DB().GetContext(ctx, &model,
+		`SELECT n.*, (SELECT COUNT(*) FROM tools t WHERE t.user_id = n.user_id AND t.namespace = n.name) AS tool_count
+		 FROM namespaces n WHERE n.user_id = ? AND n.name = ?`,
+		string(userID), name,
+	)
+	if err != nil {
+		if stdErrors.Is(err, sql.ErrNoRows) {
+			return entity.NamespaceEntity{}, false, nil
+		}
+		return entity.NamespaceEntity{}, false, errors.Wrap(err, "failed to get namespace from rds")
+	}
+	return toNamespaceEntity(model), true, nil
+}
+
+func (r *NamespaceRepositoryRdsImpl) CreateNamespace(ctx context.Context, userID entity.UserIDEntity, namespace entity.NamespaceEntity) (entity.NamespaceEntity, error) {
+	tx, err := r.client.DB().BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.NamespaceEntity{}, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var existing int
+	if err := tx.GetContext(ctx, &existing, "SELECT COUNT(*) FROM namespaces WHERE user_id = ? AND name = ?", string(userID), namespace.Name); err != nil {
+		return entity.NamespaceEntity{}, errors.Wrap(err, "failed to check namespace in rds")
+	}
+	if existing > 0 {
+		return entity.NamespaceEntity{}, repository.ErrNamespaceAlreadyExists
+	}
+
+	now := r.clock.Now()
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO namespaces (user_id, name, description, default_category, default_realtime_execution, default_ui_widgets, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		string(userID), namespace.Name, namespace.Description, namespace.DefaultCategory, namespace.DefaultRealtimeExecution,
+		namespace.DefaultUiWidgets, now, now,
+	); err != nil {
+		return entity.NamespaceEntity{}, errors.Wrap(err, "failed to insert namespace in rds")
+	}
+	if err := tx.Commit(); err != nil {
+		return entity.NamespaceEntity{}, errors.Wrap(err, "failed to commit transaction")
+	}
+
+	stored, _, err := r.GetNamespace(ctx, userID, namespace.Name)
+	return stored, err
+}
+
+func (r *NamespaceRepositoryRdsImpl) UpdateNamespace(ctx context.Context, userID entity.UserIDEntity, namespace entity.NamespaceEntity) (entity.NamespaceEntity, error) {
+	// mysql reports no affected rows for an update that changes nothing, the existence is checked on its own
+	_, exists, err := r.GetNamespace(ctx, userID, namespace.Name)
+	if err != nil {
+		return entity.NamespaceEntity{}, err
+	}
+	if !exists {
+		return entity.NamespaceEntity{}, repository.ErrNamespaceNotFound
+	}
+
+	if _, err := r.client.DB().ExecContext(ctx,
+		`UPDATE namespaces SET description = ?, default_category = ?, default_realtime_execution = ?, default_ui_widgets = ?, updated_at = ?
+		 WHERE user_id = ? AND name = ?`,
+		namespace.Description, namespace.DefaultCategory, namespace.DefaultRealtimeExecution, namespace.DefaultUiWidgets, r.clock.Now(),
+		string(userID), namespace.Name,
+	); err != nil {
+		return entity.NamespaceEntity{}, errors.Wrap(err, "failed to update namespace in rds")
+	}
+
+	stored, _, err := r.GetNamespace(ctx, userID, namespace.Name)
+	return stored, err
+}
+
+func (r *NamespaceRepositoryRdsImpl) DeleteNamespace(ctx context.Context, userID entity.UserIDEntity, name string) (bool, error) {
+	deleted, err := r.client.DB().ExecContext(ctx, "DELETE FROM namespaces WHERE user_id = ? AND name = ?", string(userID), name)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to delete namespace from rds")
+	}
+	affected, err := deleted.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get deleted namespace count")
+	}
+	return affected > 0, nil
+}
+
+func toNamespaceEntity(model NamespaceRdsModel) entity.NamespaceEntity {
+	return entity.NamespaceEntity{
+		Name:                     model.Name,
+		Description:              model.Description,
+		DefaultCategory:          model.DefaultCategory,
+		DefaultRealtimeExecution: model.DefaultRealtimeExecution,
+		DefaultUiWidgets:         model.DefaultUiWidgets,
+		ToolCount:                model.ToolCount,
+		CreatedAt:                model.CreatedAt,
+		UpdatedAt:                model.UpdatedAt,
+	}
+}
@@ -0,0 +1,95 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/infra/repository_impl/client"
+	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaceRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		clock := fixtures.NewFakeClock(time.Unix(100, 0))
+		repo := NewNamespaceRepositoryRdsImpl(rdsClient, clock)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+		userID := entity.UserIDEntity("namespace-user-1")
+
+		// the sqlite file is shared between tests, start from an empty table
+		_, err := rdsClient.DB().Exec("DELETE FROM namespaces")
+		assert.Nil(t, err)
+
+		namespaces, err := repo.ListNamespaces(ctx, userID)
+		assert.Nil(t, err)
+		assert.Empty(t, namespaces)
+
+		// a namespace can be configured after its tools were created
+		tool := fixtures.NewTestTool().WithNamespace("tools/json").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{}))
+		created, err := repo.CreateNamespace(ctx, userID, entity.NamespaceEntity{
+			Name:                     "tools/json",
+			Description:              "JSON helpers",
+			DefaultCategory:          "json",
+			DefaultRealtimeExecution: true,
+			DefaultUiWidgets:         `[{"id":"input"}]`,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "json", created.DefaultCategory)
+		assert.True(t, created.DefaultRealtimeExecution)
+		assert.Equal(t, 1, created.ToolCount)
+		assert.Equal(t, time.Unix(100, 0).Unix(), created.CreatedAt.Unix())
+
+		_, err = repo.CreateNamespace(ctx, userID, entity.NamespaceEntity{Name: "tools/json"})
+		assert.ErrorIs(t, err, repository.ErrNamespaceAlreadyExists)
+		_, err = repo.CreateNamespace(ctx, "namespace-user-2", entity.NamespaceEntity{Name: "tools/json"})
+		assert.Nil(t, err)
+		_, err = repo.CreateNamespace(ctx, userID, entity.NamespaceEntity{Name: "alpha"})
+		assert.Nil(t, err)
+
+		namespaces, err = repo.ListNamespaces(ctx, userID)
+		assert.Nil(t, err)
+		assert.Len(t, namespaces, 2)
+		assert.Equal(t, "alpha", namespaces[0].Name)
+		assert.Equal(t, 0, namespaces[0].ToolCount)
+		assert.Equal(t, "tools/json", namespaces[1].Name)
+
+		// an update replaces every setting, an unchanged one included
+		clock.Advance(time.Minute)
+		updated, err := repo.UpdateNamespace(ctx, userID, entity.NamespaceEntity{Name: "tools/json", DefaultCategory: "json"})
+		assert.Nil(t, err)
+		assert.Equal(t, "", updated.Description)
+		assert.False(t, updated.DefaultRealtimeExecution)
+		assert.Equal(t, "", updated.DefaultUiWidgets)
+		assert.Equal(t, time.Unix(160, 0).Unix(), updated.UpdatedAt.Unix())
+		_, err = repo.UpdateNamespace(ctx, userID, entity.NamespaceEntity{Name: "tools/json", DefaultCategory: "json"})
+		assert.Nil(t, err)
+		_, err = repo.UpdateNamespace(ctx, userID, entity.NamespaceEntity{Name: "missing"})
+		assert.ErrorIs(t, err, repository.ErrNamespaceNotFound)
+
+		_, exists, err := repo.GetNamespace(ctx, userID, "missing")
+		assert.Nil(t, err)
+		assert.False(t, exists)
+
+		// deleting the settings keeps the tools
+		deleted, err := repo.DeleteNamespace(ctx, userID, "tools/json")
+		assert.Nil(t, err)
+		assert.True(t, deleted)
+		deleted, err = repo.DeleteNamespace(ctx, userID, "tools/json")
+		assert.Nil(t, err)
+		assert.False(t, deleted)
+		tools, err := toolRdsImpl.AllTools(userID)
+		assert.Nil(t, err)
+		assert.Len(t, tools.Tools, 1)
+		_, exists, err = repo.GetNamespace(ctx, "namespace-user-2", "tools/json")
+		assert.Nil(t, err)
+		assert.True(t, exists)
+	})
+}
@@ -0,0 +1,151 @@
+package repository_impl
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+type OidcClientRdsModel struct {
+	ID           string    `db:"id"`
+	Name         string    `db:"name"`
+	SecretHash   string    `db:"secret_hash"`
+	RedirectURIs string    `db:"redirect_uris"`
+	CreatedBy    string    `db:"created_by"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+type OidcSigningKeyRdsModel struct {
+	KID        string    `db:"kid"`
+	PrivateKey string    `db:"private_key"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func NewOidcClientRepositoryRdsImpl(config config.Config, client repository.IRdsClient) *OidcClientRepositoryRdsImpl {
+	return &OidcClientRepositoryRdsImpl{config: config, client: client}
+}
+
+type OidcClientRepositoryRdsImpl struct {
+	config config.Config
+	client repository.IRdsClient
+}
+
+func (r *OidcClientRepositoryRdsImpl) Create(ctx context.Context, oidcClient entity.OidcClientEntity) error {
+	redirectURIs, err := json.Marshal(oidcClient.RedirectURIs)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode oidc client redirect uris")
+	}
+	_, err = r.client.DB().ExecContext(ctx,
+		`INSERT INTO oidc_clients (id, name, secret_hash, redirect_uris, created_by, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		oidcClient.ID, oidcClient.Name, oidcClient.SecretHash, string(redirectURIs),
+		string(oidcClient.CreatedBy), oidcClient.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create oidc client in rds")
+	}
+	return nil
+}
+
+func (r *OidcClientRepositoryRdsImpl) GetByID(ctx context.Context, id string) (entity.OidcClientEntity, bool, error) {
+	var model OidcClientRdsModel
+	err := r.client.DB().GetContext(ctx, &model, "SELECT * FROM oidc_clients WHERE id = ?", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return entity.OidcClientEntity{}, false, nil
+		}
+		return entity.OidcClientEntity{}, false, errors.Wrap(err, "failed to get oidc client from rds")
+	}
+	oidcClient, err := toOidcClientEntity(model)
+	if err != nil {
+		return entity.OidcClientEntity{}, false, err
+	}
+	return oidcClient, true, nil
+}
+
+func (r *OidcClientRepositoryRdsImpl) List(ctx context.Context) ([]entity.OidcClientEntity, error) {
+	var models []OidcClientRdsModel
+	if err := r.client.DB().SelectContext(ctx, &models, "SELECT * FROM oidc_clients ORDER BY created_at DESC, id"); err != nil {
+		return nil, errors.Wrap(err, "failed to list oidc clients from rds")
+	}
+
+	clients := make([]entity.OidcClientEntity, 0, len(models))
+	for _, model := range models {
+		oidcClient, err := toOidcClientEntity(model)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, oidcClient)
+	}
+	return clients, nil
+}
+
+func (r *OidcClientRepositoryRdsImpl) Delete(ctx context.Context, id string) (bool, error) {
+	result, err := r.client.DB().ExecContext(ctx, "DELETE FROM oidc_clients WHERE id = ?", id)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to delete oidc client from rds")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get affected rows")
+	}
+	return affected > 0, nil
+}
+
+func toOidcClientEntity(model OidcClientRdsModel) (entity.OidcClientEntity, error) {
+	var redirectURIs []string
+	if err := json.Unmarshal([]byte(model.RedirectURIs), &redirectURIs); err != nil {
+		return entity.OidcClientEntity{}, errors.Wrapf(err, "failed to decode redirect uris of oidc client %s", model.ID)
+	}
+	return entity.OidcClientEntity{
+		ID:           model.ID,
+		Name:         model.Name,
+		SecretHash:   model.SecretHash,
+		RedirectURIs: redirectURIs,
+		CreatedBy:    entity.UserIDEntity(model.CreatedBy),
+		CreatedAt:    model.CreatedAt,
+	}, nil
+}
+
+func NewOidcSigningKeyRepositoryRdsImpl(config config.Config, client repository.IRdsClient) *OidcSigningKeyRepositoryRdsImpl {
+	return &OidcSigningKeyRepositoryRdsImpl{config: config, client: client}
+}
+
+type OidcSigningKeyRepositoryRdsImpl struct {
+	config config.Config
+	client repository.IRdsClient
+}
+
+func (r *OidcSigningKeyRepositoryRdsImpl) Create(ctx context.Context, key entity.OidcSigningKeyEntity) error {
+	_, err := r.client.DB().ExecContext(ctx,
+		"INSERT INTO oidc_signing_keys (kid, private_key, created_at) VALUES (?, ?, ?)",
+		key.KID, key.PrivateKeyPEM, key.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create oidc signing key in rds")
+	}
+	return nil
+}
+
+func (r *OidcSigningKeyRepositoryRdsImpl) List(ctx context.Context) ([]entity.OidcSigningKeyEntity, error) {
+	var models []OidcSigningKeyRdsModel
+	if err := r.client.DB().SelectContext(ctx, &models, "SELECT * FROM oidc_signing_keys ORDER BY created_at DESC, kid"); err != nil {
+		return nil, errors.Wrap(err, "failed to list oidc signing keys from rds")
+	}
+
+	keys := make([]entity.OidcSigningKeyEntity, 0, len(models))
+	for _, model := range models {
+		keys = append(keys, entity.OidcSigningKeyEntity{
+			KID:           model.KID,
+			PrivateKeyPEM: model.PrivateKey,
+			CreatedAt:     model.CreatedAt,
+		})
+	}
+	return keys, nil
+}
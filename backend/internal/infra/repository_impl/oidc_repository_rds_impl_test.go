@@ -0,0 +1,92 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOidcClientRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewOidcClientRepositoryRdsImpl(config.Config{DBType: "sqlite"}, rdsClient)
+
+		// the sqlite file is shared between tests, start from an empty table
+		_, err := rdsClient.DB().Exec("DELETE FROM oidc_clients")
+		assert.Nil(t, err)
+
+		_, exists, err := repo.GetByID(ctx, "missing")
+		assert.Nil(t, err)
+		assert.False(t, exists)
+
+		first := entity.OidcClientEntity{
+			ID:           "oidc-client-1",
+			Name:         "Wiki",
+			SecretHash:   "hash-1",
+			RedirectURIs: []string{"https://wiki.example.com/callback", "http://localhost:3000/callback"},
+			CreatedBy:    "oidc-admin",
+			CreatedAt:    time.Unix(100, 0),
+		}
+		second := first
+		second.ID = "oidc-client-2"
+		second.Name = "Git"
+		second.RedirectURIs = []string{"https://git.example.com/callback"}
+		second.CreatedAt = time.Unix(200, 0)
+		assert.Nil(t, repo.Create(ctx, first))
+		assert.Nil(t, repo.Create(ctx, second))
+		assert.NotNil(t, repo.Create(ctx, first), "the client id is unique")
+
+		got, exists, err := repo.GetByID(ctx, first.ID)
+		assert.Nil(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "Wiki", got.Name)
+		assert.Equal(t, "hash-1", got.SecretHash)
+		assert.Equal(t, first.RedirectURIs, got.RedirectURIs)
+		assert.Equal(t, entity.UserIDEntity("oidc-admin"), got.CreatedBy)
+		assert.True(t, got.AllowsRedirectURI("http://localhost:3000/callback"))
+		assert.False(t, got.AllowsRedirectURI("http://localhost:3000/callback/"))
+
+		clients, err := repo.List(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, clients, 2)
+		assert.Equal(t, second.ID, clients[0].ID)
+
+		deleted, err := repo.Delete(ctx, first.ID)
+		assert.Nil(t, err)
+		assert.True(t, deleted)
+		deleted, err = repo.Delete(ctx, first.ID)
+		assert.Nil(t, err)
+		assert.False(t, deleted)
+	})
+}
+
+func TestOidcSigningKeyRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewOidcSigningKeyRepositoryRdsImpl(config.Config{DBType: "sqlite"}, rdsClient)
+
+		_, err := rdsClient.DB().Exec("DELETE FROM oidc_signing_keys")
+		assert.Nil(t, err)
+
+		keys, err := repo.List(ctx)
+		assert.Nil(t, err)
+		assert.Empty(t, keys)
+
+		assert.Nil(t, repo.Create(ctx, entity.OidcSigningKeyEntity{KID: "kid-1", PrivateKeyPEM: "pem-1", CreatedAt: time.Unix(100, 0)}))
+		assert.Nil(t, repo.Create(ctx, entity.OidcSigningKeyEntity{KID: "kid-2", PrivateKeyPEM: "pem-2", CreatedAt: time.Unix(200, 0)}))
+
+		keys, err = repo.List(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, keys, 2)
+		assert.Equal(t, "kid-2", keys[0].KID)
+		assert.Equal(t, "pem-2", keys[0].PrivateKeyPEM)
+	})
+}
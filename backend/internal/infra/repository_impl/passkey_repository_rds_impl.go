@@ -67,6 +67,9 @@ func (r *PasskeyRepositoryRdsImpl) Create(ctx context.Context, passkey entity.Pa
 		passkey.CreatedAt,
 	)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return errors.Wrap(repository.ErrPasskeyCredentialExists, "failed to insert passkey into rds")
+		}
 		return errors.Wrap(err, "failed to insert passkey into rds")
 	}
 
@@ -128,6 +131,38 @@ func (r *PasskeyRepositoryRdsImpl) UpdateLastUsedAt(ctx context.Context, id int6
 	return nil
 }
 
+func (r *PasskeyRepositoryRdsImpl) UpdateName(ctx context.Context, id int64, userID entity.UserIDEntity, deviceName *string) error {
+	db := r.client.DB()
+
+	name := sql.NullString{}
+	if deviceName != nil {
+		name.String = *deviceName
+		name.Valid = true
+	}
+
+	result, err := db.ExecContext(ctx, "UPDATE user_passkeys SET device_name = ? WHERE id = ? AND user_id = ?", name, id, string(userID))
+	if err != nil {
+		return errors.Wrap(err, "failed to update passkey device_name in rds")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get affected rows of passkey")
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	// mysql reports no affected row when the name did not change
+	var count int
+	if err := db.GetContext(ctx, &count, "SELECT COUNT(*) FROM user_passkeys WHERE id = ? AND user_id = ?", id, string(userID)); err != nil {
+		return errors.Wrap(err, "failed to check passkey in rds")
+	}
+	if count == 0 {
+		return errors.Wrapf(repository.ErrPasskeyNotFound, "passkey %d", id)
+	}
+	return nil
+}
+
 func (r *PasskeyRepositoryRdsImpl) Delete(ctx context.Context, id int64, userID entity.UserIDEntity) error {
 	db := r.client.DB()
 
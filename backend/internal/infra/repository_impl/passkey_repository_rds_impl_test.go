@@ -4,8 +4,9 @@ import (
 	"context"
 	"testing"
 	"time"
+	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/domain/entity"
-	"ya-tool-craft/internal/infra/repository_impl/client"
+	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/unittest"
 
 	"github.com/stretchr/testify/assert"
@@ -30,23 +31,21 @@ func createTestPasskeyEntity(userID entity.UserIDEntity) entity.PasskeyEntity {
 	)
 }
 
-func setupPasskeyTest(t *testing.T, ctx context.Context, sqliteClient *client.SqliteClient) (*PasskeyRepositoryRdsImpl, entity.UserIDEntity) {
-	unitTestCtx := unittest.GetUnitTestCtx()
-
+func setupPasskeyTest(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) (*PasskeyRepositoryRdsImpl, entity.UserIDEntity) {
 	// Create a user first since passkeys reference user_id
-	userRdsImpl := NewUserRepositoryRdsImpl(unitTestCtx.Config, sqliteClient)
+	userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
 	user, err := userRdsImpl.Create(ctx, "passkeyuser", []entity.UserRoleEntity{entity.UserRoleUser})
 	assert.Nil(t, err)
 
-	passkeyRepo := NewPasskeyRepositoryRdsImpl(sqliteClient)
+	passkeyRepo := NewPasskeyRepositoryRdsImpl(rdsClient)
 	return passkeyRepo, user.ID
 }
 
 func TestPasskeyRepositoryRdsImpl_Create(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	unitTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		passkeyRepo, userID := setupPasskeyTest(t, ctx, sqliteClient)
+	unitTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		passkeyRepo, userID := setupPasskeyTest(t, ctx, rdsConfig, rdsClient)
 
 		passkey := createTestPasskeyEntity(userID)
 		err := passkeyRepo.Create(ctx, passkey)
@@ -72,11 +71,30 @@ func TestPasskeyRepositoryRdsImpl_Create(t *testing.T) {
 	})
 }
 
+func TestPasskeyRepositoryRdsImpl_Create_DuplicateCredentialID(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		passkeyRepo, userID := setupPasskeyTest(t, ctx, rdsConfig, rdsClient)
+
+		passkey := createTestPasskeyEntity(userID)
+		assert.Nil(t, passkeyRepo.Create(ctx, passkey))
+
+		// the unique index rejects the same credential, whoever registers it
+		err := passkeyRepo.Create(ctx, createTestPasskeyEntity("another-user"))
+		assert.ErrorIs(t, err, repository.ErrPasskeyCredentialExists)
+
+		passkeys, err := passkeyRepo.GetByUserID(ctx, userID)
+		assert.Nil(t, err)
+		assert.Len(t, passkeys, 1)
+	})
+}
+
 func TestPasskeyRepositoryRdsImpl_Create_NilOptionalFields(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	unitTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		passkeyRepo, userID := setupPasskeyTest(t, ctx, sqliteClient)
+	unitTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		passkeyRepo, userID := setupPasskeyTest(t, ctx, rdsConfig, rdsClient)
 
 		passkey := entity.NewPasskeyEntity(
 			userID,
@@ -102,8 +120,8 @@ func TestPasskeyRepositoryRdsImpl_Create_NilOptionalFields(t *testing.T) {
 func TestPasskeyRepositoryRdsImpl_GetByCredentialID(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	unitTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		passkeyRepo, userID := setupPasskeyTest(t, ctx, sqliteClient)
+	unitTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		passkeyRepo, userID := setupPasskeyTest(t, ctx, rdsConfig, rdsClient)
 
 		passkey := createTestPasskeyEntity(userID)
 		err := passkeyRepo.Create(ctx, passkey)
@@ -125,8 +143,8 @@ func TestPasskeyRepositoryRdsImpl_GetByCredentialID(t *testing.T) {
 func TestPasskeyRepositoryRdsImpl_GetByUserID(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	unitTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		passkeyRepo, userID := setupPasskeyTest(t, ctx, sqliteClient)
+	unitTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		passkeyRepo, userID := setupPasskeyTest(t, ctx, rdsConfig, rdsClient)
 
 		// Create multiple passkeys for the same user
 		passkey1 := createTestPasskeyEntity(userID)
@@ -158,8 +176,8 @@ func TestPasskeyRepositoryRdsImpl_GetByUserID(t *testing.T) {
 func TestPasskeyRepositoryRdsImpl_UpdateSignCount(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	unitTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		passkeyRepo, userID := setupPasskeyTest(t, ctx, sqliteClient)
+	unitTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		passkeyRepo, userID := setupPasskeyTest(t, ctx, rdsConfig, rdsClient)
 
 		passkey := createTestPasskeyEntity(userID)
 		err := passkeyRepo.Create(ctx, passkey)
@@ -186,8 +204,8 @@ func TestPasskeyRepositoryRdsImpl_UpdateSignCount(t *testing.T) {
 func TestPasskeyRepositoryRdsImpl_UpdateLastUsedAt(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	unitTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		passkeyRepo, userID := setupPasskeyTest(t, ctx, sqliteClient)
+	unitTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		passkeyRepo, userID := setupPasskeyTest(t, ctx, rdsConfig, rdsClient)
 
 		passkey := createTestPasskeyEntity(userID)
 		err := passkeyRepo.Create(ctx, passkey)
@@ -214,8 +232,8 @@ func TestPasskeyRepositoryRdsImpl_UpdateLastUsedAt(t *testing.T) {
 func TestPasskeyRepositoryRdsImpl_Delete(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	unitTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		passkeyRepo, userID := setupPasskeyTest(t, ctx, sqliteClient)
+	unitTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		passkeyRepo, userID := setupPasskeyTest(t, ctx, rdsConfig, rdsClient)
 
 		passkey := createTestPasskeyEntity(userID)
 		err := passkeyRepo.Create(ctx, passkey)
@@ -240,8 +258,8 @@ func TestPasskeyRepositoryRdsImpl_Delete(t *testing.T) {
 func TestPasskeyRepositoryRdsImpl_Delete_WrongUserID(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	unitTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		passkeyRepo, userID := setupPasskeyTest(t, ctx, sqliteClient)
+	unitTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		passkeyRepo, userID := setupPasskeyTest(t, ctx, rdsConfig, rdsClient)
 
 		passkey := createTestPasskeyEntity(userID)
 		err := passkeyRepo.Create(ctx, passkey)
@@ -262,11 +280,55 @@ func TestPasskeyRepositoryRdsImpl_Delete_WrongUserID(t *testing.T) {
 	})
 }
 
+func TestPasskeyRepositoryRdsImpl_UpdateName(t *testing.T) {
+	unitTestCtx := unittest.GetUnitTestCtx()
+
+	unitTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		passkeyRepo, userID := setupPasskeyTest(t, ctx, rdsConfig, rdsClient)
+
+		passkey := createTestPasskeyEntity(userID)
+		assert.Nil(t, passkeyRepo.Create(ctx, passkey))
+		retrieved, _, err := passkeyRepo.GetByCredentialID(ctx, passkey.CredentialID)
+		assert.Nil(t, err)
+
+		t.Run("renames the passkey", func(t *testing.T) {
+			name := "Work Laptop"
+			assert.Nil(t, passkeyRepo.UpdateName(ctx, retrieved.ID, userID, &name))
+
+			updated, _, err := passkeyRepo.GetByCredentialID(ctx, passkey.CredentialID)
+			assert.Nil(t, err)
+			assert.Equal(t, "Work Laptop", *updated.DeviceName)
+
+			// the same name again is not a missing passkey
+			assert.Nil(t, passkeyRepo.UpdateName(ctx, retrieved.ID, userID, &name))
+		})
+
+		t.Run("nil name clears it", func(t *testing.T) {
+			assert.Nil(t, passkeyRepo.UpdateName(ctx, retrieved.ID, userID, nil))
+
+			updated, _, err := passkeyRepo.GetByCredentialID(ctx, passkey.CredentialID)
+			assert.Nil(t, err)
+			assert.Nil(t, updated.DeviceName)
+		})
+
+		t.Run("passkey of another user", func(t *testing.T) {
+			name := "Stolen"
+			err := passkeyRepo.UpdateName(ctx, retrieved.ID, entity.UserIDEntity("other-user"), &name)
+			assert.ErrorIs(t, err, repository.ErrPasskeyNotFound)
+		})
+
+		t.Run("unknown passkey", func(t *testing.T) {
+			err := passkeyRepo.UpdateName(ctx, retrieved.ID+100, userID, nil)
+			assert.ErrorIs(t, err, repository.ErrPasskeyNotFound)
+		})
+	})
+}
+
 func TestPasskeyRepositoryRdsImpl_DeleteByUserID(t *testing.T) {
 	unitTestCtx := unittest.GetUnitTestCtx()
 
-	unitTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		passkeyRepo, userID := setupPasskeyTest(t, ctx, sqliteClient)
+	unitTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		passkeyRepo, userID := setupPasskeyTest(t, ctx, rdsConfig, rdsClient)
 
 		// Create multiple passkeys
 		passkey1 := createTestPasskeyEntity(userID)
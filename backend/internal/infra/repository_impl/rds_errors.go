@@ -0,0 +1,32 @@
+package repository_impl
+
+import (
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// mysqlErrDuplicateEntry is ER_DUP_ENTRY, returned when an insert or update violates a unique index
+const mysqlErrDuplicateEntry = 1062
+
+// postgresErrUniqueViolation is the SQLSTATE of unique_violation
+const postgresErrUniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a unique index violation of sqlite, mysql or postgres
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlErrDuplicateEntry
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == postgresErrUniqueViolation
+	}
+	return false
+}
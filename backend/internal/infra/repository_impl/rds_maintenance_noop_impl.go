@@ -0,0 +1,14 @@
+package repository_impl
+
+import "context"
+
+func NewRdsMaintenanceNoopImpl() *RdsMaintenanceNoopImpl {
+	return &RdsMaintenanceNoopImpl{}
+}
+
+// RdsMaintenanceNoopImpl serves databases like mysql that live outside ToolBake and have their own backups
+type RdsMaintenanceNoopImpl struct{}
+
+func (m *RdsMaintenanceNoopImpl) Backup(ctx context.Context, dir string) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,38 @@
+package repository_impl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+// SqliteBackupFileName is the name of the database copy in the rds component of a backup
+const SqliteBackupFileName = "sqlite.db"
+
+func NewRdsMaintenanceSqliteImpl(config config.Config, client repository.IRdsClient) *RdsMaintenanceSqliteImpl {
+	return &RdsMaintenanceSqliteImpl{config: config, client: client}
+}
+
+type RdsMaintenanceSqliteImpl struct {
+	config config.Config
+	client repository.IRdsClient
+}
+
+// Backup writes a compacted copy of the database with VACUUM INTO, it sees one consistent state while writes go on.
+// An in-memory database has nothing worth keeping.
+func (m *RdsMaintenanceSqliteImpl) Backup(ctx context.Context, dir string) (bool, error) {
+	if m.config.SqlitePath == "memory" {
+		return false, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return false, errors.Wrapf(err, "failed to create sqlite backup dir %s", dir)
+	}
+	if _, err := m.client.DB().ExecContext(ctx, "VACUUM INTO ?", filepath.Join(dir, SqliteBackupFileName)); err != nil {
+		return false, errors.Wrap(err, "failed to back up sqlite")
+	}
+	return true, nil
+}
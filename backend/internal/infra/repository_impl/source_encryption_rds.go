@@ -0,0 +1,272 @@
+package repository_impl
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	stdErrors "errors"
+	"fmt"
+	"strings"
+
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/jmoiron/sqlx"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Tool sources and global scripts are encrypted at rest with envelope encryption: the text is sealed with a random
+// data key, and the data key is sealed with the encrypt key of the owner and kept on the row that references the text.
+// Rows without a sealed data key hold plaintext.
+//
+// The encrypt keys of the owners are stored in users next to the sealed keys, so the data keys are sealed with a
+// wrapping key derived from SOURCE_ENCRYPTION_SECRET and the owner's key, and a copy of the database alone opens
+// nothing. Those sealed keys start with sealedKeyV2Prefix, the ones sealed with the owner's key alone before the
+// secret existed stay readable until migrate -encrypt-sources seals them again.
+//
+// A sealed tool source is not shared between users. It is stored under a hash keyed with the secret and the owner's
+// key, so the tools and tool versions of one owner still share it, and nobody without the secret can tell which
+// sources two rows hold or confirm a guessed one.
+
+// sealedKeyV2Prefix marks a data key sealed with the wrapping key of SOURCE_ENCRYPTION_SECRET
+const sealedKeyV2Prefix = "v2:"
+
+// ownerWrappingKey returns the key the data keys of an owner are sealed with under SOURCE_ENCRYPTION_SECRET.
+func ownerWrappingKey(secret string, encryptKey string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encryptKey))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sealedToolSourceHash returns the hash a sealed source of an owner is stored under in tool_sources. It is keyed
+// apart from the wrapping key, the two are never the same key.
+func sealedToolSourceHash(secret string, encryptKey string, source string) string {
+	keyMac := hmac.New(sha256.New, []byte(secret))
+	keyMac.Write([]byte("tool source hash\x00" + encryptKey))
+	mac := hmac.New(sha256.New, keyMac.Sum(nil))
+	mac.Write([]byte(source))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newDataKey returns a random data key.
+func newDataKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", pkgerrors.Wrap(err, "fail to generate data key")
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// sealDataKey seals a data key with the encrypt key of its owner, or with its wrapping key when the secret is set. An
+// owner without a key gets no sealed key, its texts stay in plaintext.
+func sealDataKey(secret string, encryptKey string, dataKey string) (string, error) {
+	if encryptKey == "" {
+		return "", nil
+	}
+	if secret == "" {
+		sealed, err := utils.EncryptString(encryptKey, dataKey)
+		if err != nil {
+			return "", pkgerrors.Wrap(err, "fail to seal data key")
+		}
+		return sealed, nil
+	}
+	sealed, err := utils.EncryptString(ownerWrappingKey(secret, encryptKey), dataKey)
+	if err != nil {
+		return "", pkgerrors.Wrap(err, "fail to seal data key")
+	}
+	return sealedKeyV2Prefix + sealed, nil
+}
+
+// openDataKey opens a data key sealed by sealDataKey, with or without the secret.
+func openDataKey(secret string, encryptKey string, sealedKey string) (string, error) {
+	wrapped, ok := strings.CutPrefix(sealedKey, sealedKeyV2Prefix)
+	if !ok {
+		dataKey, err := utils.DecryptString(encryptKey, sealedKey)
+		if err != nil {
+			return "", pkgerrors.Wrap(err, "fail to open data key")
+		}
+		return dataKey, nil
+	}
+	if secret == "" {
+		return "", pkgerrors.New("data key is sealed with SOURCE_ENCRYPTION_SECRET, which is not set")
+	}
+	dataKey, err := utils.DecryptString(ownerWrappingKey(secret, encryptKey), wrapped)
+	if err != nil {
+		return "", pkgerrors.Wrap(err, "fail to open data key, SOURCE_ENCRYPTION_SECRET may have changed")
+	}
+	return dataKey, nil
+}
+
+// openSealedText opens a text sealed with the data key that sealedKey holds.
+func openSealedText(secret string, encryptKey string, sealedKey string, sealed string) (string, error) {
+	dataKey, err := openDataKey(secret, encryptKey, sealedKey)
+	if err != nil {
+		return "", err
+	}
+	text, err := utils.DecryptString(dataKey, sealed)
+	if err != nil {
+		return "", pkgerrors.Wrap(err, "fail to open sealed text")
+	}
+	return text, nil
+}
+
+// resealDataKey seals the data key held by sealedKey with another encrypt key, for the rows that change owner.
+// Empty keys stay empty, and a key sealed for an owner without a key is dropped.
+func resealDataKey(secret string, fromKey string, toKey string, sealedKey string) (string, error) {
+	if sealedKey == "" {
+		return "", nil
+	}
+	dataKey, err := openDataKey(secret, fromKey, sealedKey)
+	if err != nil {
+		return "", err
+	}
+	return sealDataKey(secret, toKey, dataKey)
+}
+
+// sealOwnedText seals a text of the user that is never shared with a new data key, and returns it with the data key
+// sealed with the encrypt key of the user. A user without an encrypt key keeps the text in plaintext.
+func sealOwnedText(q sqlx.Queryer, secret string, userID entity.UserIDEntity, text string) (string, string, error) {
+	encryptKey, err := ownerEncryptKey(q, userID)
+	if err != nil || encryptKey == "" {
+		return text, "", err
+	}
+	dataKey, err := newDataKey()
+	if err != nil {
+		return "", "", err
+	}
+	sealed, err := utils.EncryptString(dataKey, text)
+	if err != nil {
+		return "", "", pkgerrors.Wrap(err, "fail to seal text")
+	}
+	textKey, err := sealDataKey(secret, encryptKey, dataKey)
+	if err != nil {
+		return "", "", err
+	}
+	return sealed, textKey, nil
+}
+
+// ownerEncryptKey returns the encrypt key of the user, empty when there is no such user.
+func ownerEncryptKey(q sqlx.Queryer, userID entity.UserIDEntity) (string, error) {
+	var key string
+	if err := sqlx.Get(q, &key, "SELECT encrypt_key FROM users WHERE id = ?", string(userID)); err != nil {
+		if stdErrors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", pkgerrors.Wrap(err, "fail to get encrypt key of user")
+	}
+	return key, nil
+}
+
+// resealDataKeysOfUser seals the data keys of the tools, tool versions and global script of a user with the encrypt
+// key of another user, a merge moves them to it afterwards.
+func resealDataKeysOfUser(tx *sqlx.Tx, secret string, fromUserID entity.UserIDEntity, toUserID entity.UserIDEntity) error {
+	fromKey, err := ownerEncryptKey(tx, fromUserID)
+	if err != nil {
+		return err
+	}
+	toKey, err := ownerEncryptKey(tx, toUserID)
+	if err != nil {
+		return err
+	}
+
+	var tools []struct {
+		UniqueID  string `db:"unique_id"`
+		SourceKey string `db:"source_key"`
+	}
+	if err := tx.Select(&tools, "SELECT unique_id, source_key FROM tools WHERE user_id = ? AND source_key != ''", string(fromUserID)); err != nil {
+		return pkgerrors.Wrap(err, "fail to select tool source keys")
+	}
+	for _, tool := range tools {
+		sourceKey, err := resealDataKey(secret, fromKey, toKey, tool.SourceKey)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "fail to reseal source key of tool %s", tool.UniqueID)
+		}
+		if _, err := tx.Exec(
+			"UPDATE tools SET source_key = ? WHERE user_id = ? AND unique_id = ?",
+			sourceKey, string(fromUserID), tool.UniqueID,
+		); err != nil {
+			return pkgerrors.Wrap(err, "fail to update tool source key")
+		}
+	}
+
+	var versions []struct {
+		ToolUniqueID string `db:"tool_unique_id"`
+		Version      int    `db:"version"`
+		SourceKey    string `db:"source_key"`
+	}
+	if err := tx.Select(&versions,
+		"SELECT tool_unique_id, version, source_key FROM tool_versions WHERE user_id = ? AND source_key != ''",
+		string(fromUserID),
+	); err != nil {
+		return pkgerrors.Wrap(err, "fail to select tool version source keys")
+	}
+	for _, version := range versions {
+		sourceKey, err := resealDataKey(secret, fromKey, toKey, version.SourceKey)
+		if err != nil {
+			return pkgerrors.Wrapf(err, "fail to reseal source key of tool %s version %d", version.ToolUniqueID, version.Version)
+		}
+		if _, err := tx.Exec(
+			"UPDATE tool_versions SET source_key = ? WHERE user_id = ? AND tool_unique_id = ? AND version = ?",
+			sourceKey, string(fromUserID), version.ToolUniqueID, version.Version,
+		); err != nil {
+			return pkgerrors.Wrap(err, "fail to update tool version source key")
+		}
+	}
+
+	var scriptKeys []string
+	if err := tx.Select(&scriptKeys, "SELECT script_key FROM global_scripts WHERE user_id = ? AND script_key != ''", string(fromUserID)); err != nil {
+		return pkgerrors.Wrap(err, "fail to select global script key")
+	}
+	for _, scriptKey := range scriptKeys {
+		resealed, err := resealDataKey(secret, fromKey, toKey, scriptKey)
+		if err != nil {
+			return pkgerrors.Wrap(err, "fail to reseal global script key")
+		}
+		if _, err := tx.Exec("UPDATE global_scripts SET script_key = ? WHERE user_id = ?", resealed, string(fromUserID)); err != nil {
+			return pkgerrors.Wrap(err, "fail to update global script key")
+		}
+	}
+	return nil
+}
+
+// legacySealedKeyColumns hold the data keys of the texts that are never shared, a key sealed before
+// SOURCE_ENCRYPTION_SECRET was set has no sealedKeyV2Prefix. The tool sources are sealed again with new keys instead.
+var legacySealedKeyColumns = []struct {
+	table  string
+	column string
+}{
+	{table: "global_scripts", column: "script_key"},
+	{table: "user_data_exports", column: "archive_key"},
+}
+
+// resealLegacyDataKeys seals the data keys sealed with the encrypt key of the owner alone with the wrapping key of the
+// secret. A sealed key is random, so it tells the row it is on apart from every other.
+func resealLegacyDataKeys(tx *sqlx.Tx, secret string, encryptKeys map[string]string) error {
+	for _, keyColumn := range legacySealedKeyColumns {
+		var rows []struct {
+			UserID    string `db:"user_id"`
+			SealedKey string `db:"sealed_key"`
+		}
+		if err := tx.Select(&rows, fmt.Sprintf(
+			"SELECT user_id, %[2]s AS sealed_key FROM %[1]s WHERE %[2]s != '' AND %[2]s NOT LIKE ?",
+			keyColumn.table, keyColumn.column,
+		), sealedKeyV2Prefix+"%"); err != nil {
+			return pkgerrors.Wrapf(err, "fail to select legacy data keys of %s", keyColumn.table)
+		}
+		for _, row := range rows {
+			resealed, err := resealDataKey(secret, encryptKeys[row.UserID], encryptKeys[row.UserID], row.SealedKey)
+			if err != nil {
+				return pkgerrors.Wrapf(err, "fail to reseal data key of %s of user %s", keyColumn.table, row.UserID)
+			}
+			if _, err := tx.Exec(fmt.Sprintf(
+				"UPDATE %[1]s SET %[2]s = ? WHERE user_id = ? AND %[2]s = ?",
+				keyColumn.table, keyColumn.column,
+			), resealed, row.UserID, row.SealedKey); err != nil {
+				return pkgerrors.Wrapf(err, "fail to update data key of %s", keyColumn.table)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package repository_impl
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+type SystemSettingRdsModel struct {
+	SettingKey   string    `db:"setting_key"`
+	SettingValue string    `db:"setting_value"`
+	UpdatedBy    string    `db:"updated_by"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+func NewSystemSettingRepositoryRdsImpl(client repository.IRdsClient) *SystemSettingRepositoryRdsImpl {
+	return &SystemSettingRepositoryRdsImpl{client: client}
+}
+
+type SystemSettingRepositoryRdsImpl struct {
+	client repository.IRdsClient
+}
+
+func (r *SystemSettingRepositoryRdsImpl) AllSettings(ctx context.Context) ([]entity.SystemSettingEntity, error) {
+	db := r.client.DB()
+
+	var models []SystemSettingRdsModel
+	if err := db.SelectContext(ctx, &models, "SELECT setting_key, setting_value, updated_by, updated_at FROM system_settings ORDER BY setting_key"); err != nil {
+		return nil, errors.Wrap(err, "fail to select system settings")
+	}
+
+	settings := make([]entity.SystemSettingEntity, 0, len(models))
+	for _, model := range models {
+		settings = append(settings, entity.SystemSettingEntity{
+			Key:       model.SettingKey,
+			Value:     model.SettingValue,
+			UpdatedBy: entity.UserIDEntity(model.UpdatedBy),
+			UpdatedAt: model.UpdatedAt,
+		})
+	}
+	return settings, nil
+}
+
+func (r *SystemSettingRepositoryRdsImpl) SaveSetting(ctx context.Context, setting entity.SystemSettingEntity) error {
+	db := r.client.DB()
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "fail to begin save system setting transaction")
+	}
+
+	// delete + insert instead of a dialect specific upsert
+	if _, err = tx.ExecContext(ctx, "DELETE FROM system_settings WHERE setting_key = ?", setting.Key); err != nil {
+		tx.Rollback()
+		return errors.Wrapf(err, "fail to delete system setting %s", setting.Key)
+	}
+
+	if _, err = tx.ExecContext(ctx,
+		"INSERT INTO system_settings (setting_key, setting_value, updated_by, updated_at) VALUES (?, ?, ?, ?)",
+		setting.Key,
+		setting.Value,
+		string(setting.UpdatedBy),
+		setting.UpdatedAt,
+	); err != nil {
+		tx.Rollback()
+		return errors.Wrapf(err, "fail to insert system setting %s", setting.Key)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "fail to commit save system setting transaction")
+	}
+	return nil
+}
+
+func (r *SystemSettingRepositoryRdsImpl) DeleteSetting(ctx context.Context, key string) error {
+	db := r.client.DB()
+	if _, err := db.ExecContext(ctx, "DELETE FROM system_settings WHERE setting_key = ?", key); err != nil {
+		return errors.Wrapf(err, "fail to delete system setting %s", key)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemSettingRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewSystemSettingRepositoryRdsImpl(rdsClient)
+
+		// the sqlite file is shared between tests, start from an empty table
+		_, err := rdsClient.DB().Exec("DELETE FROM system_settings")
+		assert.Nil(t, err)
+
+		settings, err := repo.AllSettings(ctx)
+		assert.Nil(t, err)
+		assert.Empty(t, settings)
+
+		updatedAt := time.Now().UTC().Truncate(time.Second)
+		assert.Nil(t, repo.SaveSetting(ctx, entity.SystemSettingEntity{Key: "registration.enabled", Value: "false", UpdatedBy: "admin-1", UpdatedAt: updatedAt}))
+		assert.Nil(t, repo.SaveSetting(ctx, entity.SystemSettingEntity{Key: "maintenance.message", Value: "soon", UpdatedBy: "admin-1", UpdatedAt: updatedAt}))
+
+		// saving again replaces the value
+		assert.Nil(t, repo.SaveSetting(ctx, entity.SystemSettingEntity{Key: "registration.enabled", Value: "true", UpdatedBy: "admin-2", UpdatedAt: updatedAt}))
+
+		settings, err = repo.AllSettings(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, settings, 2)
+		assert.Equal(t, "maintenance.message", settings[0].Key)
+		assert.Equal(t, "registration.enabled", settings[1].Key)
+		assert.Equal(t, "true", settings[1].Value)
+		assert.Equal(t, entity.UserIDEntity("admin-2"), settings[1].UpdatedBy)
+		assert.True(t, updatedAt.Equal(settings[1].UpdatedAt))
+
+		assert.Nil(t, repo.DeleteSetting(ctx, "registration.enabled"))
+		assert.Nil(t, repo.DeleteSetting(ctx, "missing.key"))
+
+		settings, err = repo.AllSettings(ctx)
+		assert.Nil(t, err)
+		assert.Len(t, settings, 1)
+		assert.Equal(t, "maintenance.message", settings[0].Key)
+	})
+}
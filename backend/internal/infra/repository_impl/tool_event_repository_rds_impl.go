@@ -0,0 +1,140 @@
+package repository_impl
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+type ToolEventRdsModel struct {
+	Seq           int64     `db:"seq"`
+	UserID        string    `db:"user_id"`
+	ToolUniqueID  string    `db:"tool_unique_id"`
+	EventType     string    `db:"event_type"`
+	ActorID       string    `db:"actor_id"`
+	DeviceID      string    `db:"device_id"`
+	RequestID     string    `db:"request_id"`
+	ChangedFields string    `db:"changed_fields"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+func NewToolEventRepositoryRdsImpl(client repository.IRdsClient) *ToolEventRepositoryRdsImpl {
+	return &ToolEventRepositoryRdsImpl{client: client}
+}
+
+type ToolEventRepositoryRdsImpl struct {
+	client repository.IRdsClient
+}
+
+func (r *ToolEventRepositoryRdsImpl) ListEvents(ctx context.Context, userID entity.UserIDEntity, toolUID string, before int64, limit int) ([]entity.ToolEventEntity, error) {
+	db := r.client.DB()
+	if before <= 0 {
+		before = math.MaxInt64
+	}
+
+	query := `SELECT seq, user_id, tool_unique_id, event_type, actor_id, device_id, request_id, changed_fields, created_at
+		 FROM tool_events WHERE user_id = ? AND seq < ?`
+	args := []any{string(userID), before}
+	if toolUID != "" {
+		query += " AND tool_unique_id = ?"
+		args = append(args, toolUID)
+	}
+	query += " ORDER BY seq DESC LIMIT ?"
+	args = append(args, limit)
+
+	var models []ToolEventRdsModel
+	if err := db.SelectContext(ctx, &models, query, args...); err != nil {
+		return nil, pkgerrors.Wrap(err, "fail to select tool events from rds")
+	}
+
+	events := make([]entity.ToolEventEntity, 0, len(models))
+	for _, model := range models {
+		events = append(events, toToolEventEntity(model))
+	}
+	return events, nil
+}
+
+// recordToolEvent appends an event of the tool, it runs in the transaction of the change it records.
+func recordToolEvent(exec execer, userID entity.UserIDEntity, toolUID string, eventType entity.ToolEventType, eventSource entity.ToolEventSourceEntity, changedFields []string, now time.Time) error {
+	if changedFields == nil {
+		changedFields = []string{}
+	}
+	fieldsJSON, err := json.Marshal(changedFields)
+	if err != nil {
+		return pkgerrors.Wrap(err, "fail to encode changed fields of tool event")
+	}
+
+	if _, err := exec.Exec(
+		`INSERT INTO tool_events (user_id, tool_unique_id, event_type, actor_id, device_id, request_id, changed_fields, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		string(userID),
+		toolUID,
+		string(eventType),
+		string(eventSource.ActorID),
+		eventSource.DeviceID,
+		eventSource.RequestID,
+		string(fieldsJSON),
+		now,
+	); err != nil {
+		return pkgerrors.Wrapf(err, "fail to insert %s event of tool %s", eventType, toolUID)
+	}
+	return nil
+}
+
+// changedToolFields names the fields of the stored tool an update to tool changes. The source is compared by hash.
+func changedToolFields(stored ToolRdsModel, tool entity.ToolEntity, sourceHash string) []string {
+	storedExtraInfo := decodeExtraInfo(stored.ExtraInfo)
+	extraInfoChanged := len(storedExtraInfo) != len(tool.ExtraInfo)
+	for key, value := range tool.ExtraInfo {
+		if storedValue, ok := storedExtraInfo[key]; !ok || storedValue != value {
+			extraInfoChanged = true
+		}
+	}
+
+	fields := []struct {
+		name    string
+		changed bool
+	}{
+		{entity.ToolEventFieldID, stored.ID != tool.ID},
+		{entity.ToolEventFieldName, stored.Name != tool.Name},
+		{entity.ToolEventFieldNamespace, stored.Namespace != tool.Namespace},
+		{entity.ToolEventFieldCategory, stored.Category != tool.Category},
+		{entity.ToolEventFieldIsActivate, stored.IsActivate != tool.IsActivate},
+		{entity.ToolEventFieldRealtimeExecution, stored.RealtimeExecution != tool.RealtimeExecution},
+		{entity.ToolEventFieldUiWidgets, stored.UiWidgets != tool.UiWidgets},
+		{entity.ToolEventFieldSource, stored.SourceHash != sourceHash},
+		{entity.ToolEventFieldDescription, stored.Description != tool.Description},
+		{entity.ToolEventFieldExtraInfo, extraInfoChanged},
+	}
+	changed := []string{}
+	for _, field := range fields {
+		if field.changed {
+			changed = append(changed, field.name)
+		}
+	}
+	return changed
+}
+
+func toToolEventEntity(model ToolEventRdsModel) entity.ToolEventEntity {
+	changedFields := []string{}
+	if model.ChangedFields != "" {
+		_ = json.Unmarshal([]byte(model.ChangedFields), &changedFields)
+	}
+	return entity.ToolEventEntity{
+		ID:            model.Seq,
+		UserID:        entity.UserIDEntity(model.UserID),
+		ToolUID:       model.ToolUniqueID,
+		Type:          entity.ToolEventType(model.EventType),
+		ActorID:       entity.UserIDEntity(model.ActorID),
+		DeviceID:      model.DeviceID,
+		RequestID:     model.RequestID,
+		ChangedFields: changedFields,
+		CreatedAt:     model.CreatedAt,
+	}
+}
@@ -0,0 +1,97 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/infra/repository_impl/client"
+	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToolEventRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		_, err := rdsClient.DB().Exec("DELETE FROM tool_events")
+		assert.Nil(t, err)
+
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+		eventRdsImpl := NewToolEventRepositoryRdsImpl(rdsClient)
+		userID := entity.UserIDEntity("tool-event-user-1")
+		source := entity.ToolEventSourceEntity{ActorID: userID, DeviceID: "device-1", RequestID: "request-1"}
+		eventTypes := func(toolUID string) []entity.ToolEventType {
+			events, err := eventRdsImpl.ListEvents(ctx, userID, toolUID, 0, 100)
+			assert.Nil(t, err)
+			return lo.Map(events, func(event entity.ToolEventEntity, _ int) entity.ToolEventType { return event.Type })
+		}
+
+		tool := fixtures.NewTestTool().WithUniqueID("tool-event-uid-1").WithCategory("first").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, tool, source))
+		events, err := eventRdsImpl.ListEvents(ctx, userID, tool.UniqueID, 0, 100)
+		assert.Nil(t, err)
+		assert.Len(t, events, 1)
+		assert.Equal(t, entity.ToolEventTypeCreated, events[0].Type)
+		assert.Equal(t, userID, events[0].ActorID)
+		assert.Equal(t, "device-1", events[0].DeviceID)
+		assert.Equal(t, "request-1", events[0].RequestID)
+		assert.Empty(t, events[0].ChangedFields)
+
+		// an update that changes nothing records nothing
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, tool, source))
+		assert.Equal(t, []entity.ToolEventType{entity.ToolEventTypeCreated}, eventTypes(tool.UniqueID))
+
+		tool.Name = "renamed"
+		tool.Source = "new source"
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, tool, source))
+		events, err = eventRdsImpl.ListEvents(ctx, userID, tool.UniqueID, 0, 1)
+		assert.Nil(t, err)
+		assert.Len(t, events, 1)
+		assert.Equal(t, entity.ToolEventTypeUpdated, events[0].Type)
+		assert.Equal(t, []string{entity.ToolEventFieldName, entity.ToolEventFieldSource}, events[0].ChangedFields)
+
+		// archiving twice records a single event
+		assert.Nil(t, toolRdsImpl.SetToolArchived(userID, tool.UniqueID, true, source))
+		assert.Nil(t, toolRdsImpl.SetToolArchived(userID, tool.UniqueID, true, source))
+		assert.Nil(t, toolRdsImpl.SetToolArchived(userID, tool.UniqueID, false, source))
+
+		moved, err := toolRdsImpl.RenameCategory(userID, "first", "second", source)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), moved)
+		events, err = eventRdsImpl.ListEvents(ctx, userID, tool.UniqueID, 0, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{entity.ToolEventFieldCategory}, events[0].ChangedFields)
+
+		other := fixtures.NewTestTool().WithUniqueID("tool-event-uid-2").WithID("tool-2").WithName("Other Tool").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, other, source))
+		assert.Nil(t, toolRdsImpl.DeleteTool(userID, tool.UniqueID, source))
+
+		assert.Equal(t, []entity.ToolEventType{
+			entity.ToolEventTypeDeleted,
+			entity.ToolEventTypeUpdated,
+			entity.ToolEventTypeRestored,
+			entity.ToolEventTypeArchived,
+			entity.ToolEventTypeUpdated,
+			entity.ToolEventTypeCreated,
+		}, eventTypes(tool.UniqueID))
+		assert.Equal(t, []entity.ToolEventType{entity.ToolEventTypeCreated}, eventTypes(other.UniqueID))
+
+		// pages continue below the id of the last event seen
+		all, err := eventRdsImpl.ListEvents(ctx, userID, "", 0, 100)
+		assert.Nil(t, err)
+		assert.Len(t, all, 7)
+		page, err := eventRdsImpl.ListEvents(ctx, userID, "", all[2].ID, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{all[3].ID, all[4].ID}, lo.Map(page, func(event entity.ToolEventEntity, _ int) int64 { return event.ID }))
+
+		// events of other users are not visible
+		events, err = eventRdsImpl.ListEvents(ctx, "tool-event-user-2", "", 0, 100)
+		assert.Nil(t, err)
+		assert.Empty(t, events)
+	})
+}
@@ -4,22 +4,30 @@ import (
 	"database/sql"
 	"encoding/json"
 	stdErrors "errors"
+	"math"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"ya-tool-craft/internal/config"
+	domain_client "ya-tool-craft/internal/domain/client"
 	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/domain/repository"
 
+	"github.com/jmoiron/sqlx"
 	pkgerrors "github.com/pkg/errors"
+	"github.com/samber/lo"
 )
 
-func NewToolRepositoryRdsImpl(config config.Config, client repository.IRdsClient) *ToolRepositoryRdsImpl {
-	return &ToolRepositoryRdsImpl{config: config, client: client}
+func NewToolRepositoryRdsImpl(config config.Config, client repository.IRdsClient, clock domain_client.IClock) *ToolRepositoryRdsImpl {
+	return &ToolRepositoryRdsImpl{config: config, client: client, clock: clock}
 }
 
 type ToolRepositoryRdsImpl struct {
 	config config.Config
 	client repository.IRdsClient
+	clock  domain_client.IClock
 }
 
 type ToolRdsModel struct {
@@ -29,22 +37,28 @@ type ToolRdsModel struct {
 	Name              string    `db:"name"`
 	Namespace         string    `db:"namespace"`
 	IsActivate        bool      `db:"is_activate"`
+	IsArchived        bool      `db:"is_archived"`
 	RealtimeExecution bool      `db:"realtime_execution"`
 	UiWidgets         string    `db:"ui_widgets"`
 	Source            string    `db:"source"`
+	SourceHash        string    `db:"source_hash"`
 	Description       string    `db:"description"`
 	ExtraInfo         string    `db:"extra_info"`
 	Category          string    `db:"category"`
 	CreatedAt         time.Time `db:"created_at"`
 	UpdatedAt         time.Time `db:"updated_at"`
+	// SourceKey is the data key of the source sealed with OwnerEncryptKey, empty when it was stored before
+	SourceKey       string `db:"source_key"`
+	SourceEncrypted bool   `db:"source_encrypted"`
+	OwnerEncryptKey string `db:"owner_encrypt_key"`
 }
 
 type execer interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
 }
 
-func (r *ToolRepositoryRdsImpl) CreateTool(userID entity.UserIDEntity, tool entity.ToolEntity) error {
-	now := time.Now()
+func (r *ToolRepositoryRdsImpl) CreateTool(userID entity.UserIDEntity, tool entity.ToolEntity, eventSource entity.ToolEventSourceEntity) error {
+	now := r.clock.Now()
 	tool.CreatedAt = now
 	tool.UpdatedAt = now
 	db := r.client.DB()
@@ -59,6 +73,26 @@ func (r *ToolRepositoryRdsImpl) CreateTool(userID entity.UserIDEntity, tool enti
 		return pkgerrors.Wrap(err, "fail to encode extra info")
 	}
 
+	if err = checkNewToolIDsFree(tx, userID, tool); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = r.checkToolNameFree(tx, userID, tool); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	sourceRef, err := newToolSourceRef(tx, r.config, userID, tool.Source)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = retainToolSource(tx, r.config.DBType, sourceRef, tool.Source, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	_, err = tx.Exec(
 		`INSERT INTO tools (
 			user_id,
@@ -71,11 +105,13 @@ func (r *ToolRepositoryRdsImpl) CreateTool(userID entity.UserIDEntity, tool enti
 			realtime_execution,
 			ui_widgets,
 			source,
+			source_hash,
+			source_key,
 			description,
 			extra_info,
 			created_at,
 			updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		string(userID),
 		tool.ID,
 		tool.UniqueID,
@@ -85,7 +121,9 @@ func (r *ToolRepositoryRdsImpl) CreateTool(userID entity.UserIDEntity, tool enti
 		tool.IsActivate,
 		tool.RealtimeExecution,
 		tool.UiWidgets,
-		tool.Source,
+		"",
+		sourceRef.Hash,
+		sourceRef.Key,
 		tool.Description,
 		extraInfoJSON,
 		now,
@@ -96,7 +134,32 @@ func (r *ToolRepositoryRdsImpl) CreateTool(userID entity.UserIDEntity, tool enti
 		return pkgerrors.Wrap(err, "fail to insert tool into rds")
 	}
 
-	if err = r.upsertToolsLastUpdatedAt(tx, userID, time.Now()); err != nil {
+	if err = recordToolVersion(tx, r.config.DBType, userID, tool, sourceRef, now, r.config.ToolVersionLimit); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = r.replaceToolExtraInfo(tx, userID, tool.UniqueID, tool.ExtraInfo); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = replaceToolSearchDocument(tx, userID, tool.UniqueID, r.searchDocument(tool)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = r.recordToolChange(tx, userID, tool.UniqueID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = recordToolEvent(tx, userID, tool.UniqueID, entity.ToolEventTypeCreated, eventSource, nil, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = r.upsertToolsLastUpdatedAt(tx, userID, r.clock.Now()); err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -108,8 +171,8 @@ func (r *ToolRepositoryRdsImpl) CreateTool(userID entity.UserIDEntity, tool enti
 	return nil
 }
 
-func (r *ToolRepositoryRdsImpl) UpdateTool(userID entity.UserIDEntity, tool entity.ToolEntity) error {
-	now := time.Now()
+func (r *ToolRepositoryRdsImpl) UpdateTool(userID entity.UserIDEntity, tool entity.ToolEntity, eventSource entity.ToolEventSourceEntity) error {
+	now := r.clock.Now()
 	tool.UpdatedAt = now
 
 	db := r.client.DB()
@@ -124,6 +187,35 @@ func (r *ToolRepositoryRdsImpl) UpdateTool(userID entity.UserIDEntity, tool enti
 		return pkgerrors.Wrap(err, "fail to encode extra info")
 	}
 
+	if err = r.checkToolNameFree(tx, userID, tool); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// the stored source only changes hands when the source changed
+	sourceRef, err := newToolSourceRef(tx, r.config, userID, tool.Source)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	sourceHash := sourceRef.Hash
+	stored, exists, err := r.storedTool(tx, userID, tool.UniqueID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	previousHash := stored.SourceHash
+	if exists && previousHash != sourceHash {
+		if err = retainToolSource(tx, r.config.DBType, sourceRef, tool.Source, now); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err = releaseToolSource(tx, previousHash); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
 	_, err = tx.Exec(
 		`UPDATE tools SET
 			id = ?,
@@ -134,6 +226,8 @@ func (r *ToolRepositoryRdsImpl) UpdateTool(userID entity.UserIDEntity, tool enti
 			realtime_execution = ?,
 			ui_widgets = ?,
 			source = ?,
+			source_hash = ?,
+			source_key = ?,
 			description = ?,
 			extra_info = ?,
 			updated_at = ?
@@ -145,7 +239,9 @@ func (r *ToolRepositoryRdsImpl) UpdateTool(userID entity.UserIDEntity, tool enti
 		tool.IsActivate,
 		tool.RealtimeExecution,
 		tool.UiWidgets,
-		tool.Source,
+		"",
+		sourceHash,
+		sourceRef.Key,
 		tool.Description,
 		extraInfoJSON,
 		now,
@@ -157,7 +253,37 @@ func (r *ToolRepositoryRdsImpl) UpdateTool(userID entity.UserIDEntity, tool enti
 		return pkgerrors.Wrap(err, "fail to update tool in rds")
 	}
 
-	if err = r.upsertToolsLastUpdatedAt(tx, userID, time.Now()); err != nil {
+	if exists {
+		if err = recordToolVersion(tx, r.config.DBType, userID, tool, sourceRef, now, r.config.ToolVersionLimit); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err = r.replaceToolExtraInfo(tx, userID, tool.UniqueID, tool.ExtraInfo); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = replaceToolSearchDocument(tx, userID, tool.UniqueID, r.searchDocument(tool)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = r.recordToolChange(tx, userID, tool.UniqueID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// an update that changes nothing is not an event
+	if changed := changedToolFields(stored, tool, sourceHash); exists && len(changed) > 0 {
+		if err = recordToolEvent(tx, userID, tool.UniqueID, entity.ToolEventTypeUpdated, eventSource, changed, now); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err = r.upsertToolsLastUpdatedAt(tx, userID, r.clock.Now()); err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -169,20 +295,89 @@ func (r *ToolRepositoryRdsImpl) UpdateTool(userID entity.UserIDEntity, tool enti
 	return nil
 }
 
-func (r *ToolRepositoryRdsImpl) DeleteTool(userID entity.UserIDEntity, toolUID string) error {
+func (r *ToolRepositoryRdsImpl) DeleteTool(userID entity.UserIDEntity, toolUID string, eventSource entity.ToolEventSourceEntity) error {
 	db := r.client.DB()
 	tx, err := db.Beginx()
 	if err != nil {
 		return pkgerrors.Wrap(err, "fail to begin tool delete transaction")
 	}
 
+	sourceHash, exists, err := r.toolSourceHash(tx, userID, toolUID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	_, err = tx.Exec("DELETE FROM tools WHERE user_id = ? AND unique_id = ?", string(userID), toolUID)
 	if err != nil {
 		tx.Rollback()
 		return pkgerrors.Wrap(err, "fail to delete tool from rds")
 	}
 
-	if err = r.upsertToolsLastUpdatedAt(tx, userID, time.Now()); err != nil {
+	if exists {
+		if err = releaseToolSource(tx, sourceHash); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err = deleteToolVersions(tx, userID, toolUID, -1); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err = r.replaceToolExtraInfo(tx, userID, toolUID, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = replaceToolSearchDocument(tx, userID, toolUID, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM tool_secrets WHERE user_id = ? AND tool_unique_id = ?", string(userID), toolUID)
+	if err != nil {
+		tx.Rollback()
+		return pkgerrors.Wrap(err, "fail to delete tool secrets from rds")
+	}
+
+	_, err = tx.Exec("DELETE FROM tool_schedules WHERE user_id = ? AND tool_unique_id = ?", string(userID), toolUID)
+	if err != nil {
+		tx.Rollback()
+		return pkgerrors.Wrap(err, "fail to delete tool schedules from rds")
+	}
+
+	_, err = tx.Exec("DELETE FROM tool_runs WHERE user_id = ? AND tool_unique_id = ?", string(userID), toolUID)
+	if err != nil {
+		tx.Rollback()
+		return pkgerrors.Wrap(err, "fail to delete tool runs from rds")
+	}
+
+	_, err = tx.Exec("DELETE FROM shared_tools WHERE owner_id = ? AND tool_unique_id = ?", string(userID), toolUID)
+	if err != nil {
+		tx.Rollback()
+		return pkgerrors.Wrap(err, "fail to delete tool shares from rds")
+	}
+
+	_, err = tx.Exec("DELETE FROM gallery_tools WHERE owner_id = ? AND tool_unique_id = ?", string(userID), toolUID)
+	if err != nil {
+		tx.Rollback()
+		return pkgerrors.Wrap(err, "fail to delete gallery tool from rds")
+	}
+
+	if err = r.recordToolChange(tx, userID, toolUID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if exists {
+		if err = recordToolEvent(tx, userID, toolUID, entity.ToolEventTypeDeleted, eventSource, nil, r.clock.Now()); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err = r.upsertToolsLastUpdatedAt(tx, userID, r.clock.Now()); err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -194,17 +389,85 @@ func (r *ToolRepositoryRdsImpl) DeleteTool(userID entity.UserIDEntity, toolUID s
 	return nil
 }
 
+func (r *ToolRepositoryRdsImpl) SetToolArchived(userID entity.UserIDEntity, toolUID string, archived bool, eventSource entity.ToolEventSourceEntity) error {
+	db := r.client.DB()
+	tx, err := db.Beginx()
+	if err != nil {
+		return pkgerrors.Wrap(err, "fail to begin tool archive transaction")
+	}
+
+	stored, exists, err := r.storedTool(tx, userID, toolUID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if !exists {
+		tx.Rollback()
+		return pkgerrors.Wrapf(repository.ErrToolNotFound, "tool %q", toolUID)
+	}
+
+	now := r.clock.Now()
+	result, err := tx.Exec(
+		"UPDATE tools SET is_archived = ?, updated_at = ? WHERE user_id = ? AND unique_id = ?",
+		archived,
+		now,
+		string(userID),
+		toolUID,
+	)
+	if err != nil {
+		tx.Rollback()
+		return pkgerrors.Wrap(err, "fail to update tool archived state in rds")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return pkgerrors.Wrap(err, "fail to get affected rows of tool archive")
+	}
+	if affected == 0 {
+		tx.Rollback()
+		return pkgerrors.Wrapf(repository.ErrToolNotFound, "tool %q", toolUID)
+	}
+
+	if err = r.recordToolChange(tx, userID, toolUID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if stored.IsArchived != archived {
+		eventType := entity.ToolEventTypeRestored
+		if archived {
+			eventType = entity.ToolEventTypeArchived
+		}
+		if err = recordToolEvent(tx, userID, toolUID, eventType, eventSource, []string{entity.ToolEventFieldIsArchived}, now); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err = r.upsertToolsLastUpdatedAt(tx, userID, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return pkgerrors.Wrap(err, "fail to commit tool archive transaction")
+	}
+
+	return nil
+}
+
 func (r *ToolRepositoryRdsImpl) AllTools(userID entity.UserIDEntity) (entity.ToolsEntity, error) {
 	db := r.client.DB()
 	var models []ToolRdsModel
 
-	if err := db.Select(&models, "SELECT * FROM tools WHERE user_id = ?", string(userID)); err != nil {
+	if err := db.Select(&models, "SELECT "+toolRdsColumns+" FROM "+toolRdsFrom+" WHERE t.user_id = ?", string(userID)); err != nil {
 		return entity.ToolsEntity{}, pkgerrors.Wrap(err, "fail to select tools")
 	}
 
-	tools := make([]entity.ToolEntity, 0, len(models))
-	for _, model := range models {
-		tools = append(tools, toToolEntity(model))
+	tools, err := toToolEntities(r.config.SourceEncryptionSecret, models)
+	if err != nil {
+		return entity.ToolsEntity{}, err
 	}
 
 	lastUpdatedAt, err := r.ToolsLastUpdatedAt(userID)
@@ -220,6 +483,103 @@ func (r *ToolRepositoryRdsImpl) AllTools(userID entity.UserIDEntity) (entity.Too
 	return result, nil
 }
 
+func (r *ToolRepositoryRdsImpl) GetTool(userID entity.UserIDEntity, toolUID string) (entity.ToolEntity, bool, error) {
+	db := r.client.DB()
+	var model ToolRdsModel
+	err := db.Get(&model, "SELECT "+toolRdsColumns+" FROM "+toolRdsFrom+" WHERE t.user_id = ? AND t.unique_id = ?", string(userID), toolUID)
+	if err != nil {
+		if stdErrors.Is(err, sql.ErrNoRows) {
+			return entity.ToolEntity{}, false, nil
+		}
+		return entity.ToolEntity{}, false, pkgerrors.Wrap(err, "fail to get tool")
+	}
+
+	tool, err := toToolEntity(r.config.SourceEncryptionSecret, model)
+	if err != nil {
+		return entity.ToolEntity{}, false, err
+	}
+	return tool, true, nil
+}
+
+func (r *ToolRepositoryRdsImpl) ListTools(userID entity.UserIDEntity, options entity.ToolListOptions) ([]entity.ToolEntity, int, error) {
+	db := r.client.DB()
+
+	conditions := []string{"t.user_id = ?"}
+	args := []any{string(userID)}
+	switch options.Archived {
+	case entity.ToolArchiveFilterInclude:
+	case entity.ToolArchiveFilterOnly:
+		conditions = append(conditions, "t.is_archived = ?")
+		args = append(args, true)
+	default:
+		conditions = append(conditions, "t.is_archived = ?")
+		args = append(args, false)
+	}
+	if options.Category != nil {
+		conditions = append(conditions, "t.category = ?")
+		args = append(args, *options.Category)
+	}
+	if options.Namespace != nil {
+		conditions = append(conditions, "t.namespace = ?")
+		args = append(args, *options.Namespace)
+	}
+	if options.IsActivate != nil {
+		conditions = append(conditions, "t.is_activate = ?")
+		args = append(args, *options.IsActivate)
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := db.Get(&total, "SELECT COUNT(*) FROM tools t"+where, args...); err != nil {
+		return nil, 0, pkgerrors.Wrap(err, "fail to count tools")
+	}
+
+	orderBy := "t.created_at, t.unique_id"
+	switch options.SortBy {
+	case entity.ToolSortByUpdatedAt:
+		orderBy = "t.updated_at DESC, t.unique_id"
+	case entity.ToolSortByName:
+		orderBy = "t.name, t.unique_id"
+	}
+	query := "SELECT " + toolRdsColumns + " FROM " + toolRdsFrom + where + " ORDER BY " + orderBy
+	if options.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, options.Limit, options.Offset)
+	}
+
+	var models []ToolRdsModel
+	if err := db.Select(&models, query, args...); err != nil {
+		return nil, 0, pkgerrors.Wrap(err, "fail to select tools")
+	}
+
+	tools, err := toToolEntities(r.config.SourceEncryptionSecret, models)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tools, total, nil
+}
+
+func (r *ToolRepositoryRdsImpl) FilterToolsByExtraInfo(userID entity.UserIDEntity, filters map[string]string) ([]entity.ToolEntity, error) {
+	db := r.client.DB()
+
+	query := "SELECT " + toolRdsColumns + " FROM " + toolRdsFrom + " WHERE t.user_id = ?"
+	args := []any{string(userID)}
+	keys := lo.Keys(filters)
+	sort.Strings(keys)
+	for _, key := range keys {
+		query += " AND t.unique_id IN (SELECT tool_unique_id FROM tool_extra_info WHERE user_id = ? AND info_key = ? AND info_value = ?)"
+		args = append(args, string(userID), key, filters[key])
+	}
+	query += " ORDER BY t.created_at"
+
+	var models []ToolRdsModel
+	if err := db.Select(&models, query, args...); err != nil {
+		return nil, pkgerrors.Wrap(err, "fail to filter tools by extra info")
+	}
+
+	return toToolEntities(r.config.SourceEncryptionSecret, models)
+}
+
 func (r *ToolRepositoryRdsImpl) ToolsLastUpdatedAt(userID entity.UserIDEntity) (*time.Time, error) {
 	db := r.client.DB()
 	var lastUpdated time.Time
@@ -239,6 +599,523 @@ func (r *ToolRepositoryRdsImpl) ToolsLastUpdatedAt(userID entity.UserIDEntity) (
 	return &lastUpdated, nil
 }
 
+type toolCategoryRdsModel struct {
+	Category  string `db:"category"`
+	ToolCount int    `db:"tool_count"`
+}
+
+func (r *ToolRepositoryRdsImpl) ToolChangesSince(userID entity.UserIDEntity, cursor int64) (entity.ToolChangesEntity, error) {
+	return r.toolChanges(userID, cursor, math.MaxInt64, 0)
+}
+
+func (r *ToolRepositoryRdsImpl) ToolChangesPage(userID entity.UserIDEntity, cursor int64, upTo int64, limit int) (entity.ToolChangesEntity, error) {
+	return r.toolChanges(userID, cursor, upTo, limit)
+}
+
+// toolChanges reads the changes after the cursor up to and including upTo, limit 0 reads all of them.
+func (r *ToolRepositoryRdsImpl) toolChanges(userID entity.UserIDEntity, cursor int64, upTo int64, limit int) (entity.ToolChangesEntity, error) {
+	db := r.client.DB()
+
+	query := "SELECT seq, tool_unique_id FROM tool_changes WHERE user_id = ? AND seq > ? AND seq <= ? ORDER BY seq"
+	args := []any{string(userID), cursor, upTo}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	var changes []struct {
+		Seq          int64  `db:"seq"`
+		ToolUniqueID string `db:"tool_unique_id"`
+	}
+	if err := db.Select(&changes, query, args...); err != nil {
+		return entity.ToolChangesEntity{}, pkgerrors.Wrap(err, "fail to select tool changes from rds")
+	}
+
+	result := entity.ToolChangesEntity{
+		Cursor:          cursor,
+		Tools:           []entity.ToolEntity{},
+		DeletedToolUIDs: []string{},
+	}
+	if len(changes) == 0 {
+		return result, nil
+	}
+
+	var models []ToolRdsModel
+	if err := db.Select(
+		&models,
+		`SELECT `+toolRdsColumns+` FROM `+toolRdsFrom+`
+		 JOIN tool_changes c ON c.user_id = t.user_id AND c.tool_unique_id = t.unique_id
+		 WHERE c.user_id = ? AND c.seq > ? AND c.seq <= ?`,
+		string(userID),
+		cursor,
+		changes[len(changes)-1].Seq,
+	); err != nil {
+		return entity.ToolChangesEntity{}, pkgerrors.Wrap(err, "fail to select changed tools from rds")
+	}
+	toolsByUID := lo.SliceToMap(models, func(model ToolRdsModel) (string, ToolRdsModel) { return model.UniqueID, model })
+
+	// changes without a tool are deletions, the result keeps the order of the changes
+	for _, change := range changes {
+		if model, ok := toolsByUID[change.ToolUniqueID]; ok {
+			tool, err := toToolEntity(r.config.SourceEncryptionSecret, model)
+			if err != nil {
+				return entity.ToolChangesEntity{}, err
+			}
+			result.Tools = append(result.Tools, tool)
+		} else {
+			result.DeletedToolUIDs = append(result.DeletedToolUIDs, change.ToolUniqueID)
+		}
+		result.Cursor = change.Seq
+	}
+	return result, nil
+}
+
+func (r *ToolRepositoryRdsImpl) LatestToolChangeCursor(userID entity.UserIDEntity) (int64, error) {
+	db := r.client.DB()
+
+	var cursor int64
+	if err := db.Get(&cursor, "SELECT COALESCE(MAX(seq), 0) FROM tool_changes WHERE user_id = ?", string(userID)); err != nil {
+		return 0, pkgerrors.Wrap(err, "fail to get latest tool change from rds")
+	}
+	return cursor, nil
+}
+
+func (r *ToolRepositoryRdsImpl) ToolsUpdatedSince(userID entity.UserIDEntity, since time.Time) (entity.ToolsUpdatedSinceEntity, error) {
+	db := r.client.DB()
+
+	type toolChange struct {
+		Seq          int64     `db:"seq"`
+		ToolUniqueID string    `db:"tool_unique_id"`
+		ChangedAt    time.Time `db:"changed_at"`
+	}
+	var allChanges []toolChange
+	if err := db.Select(
+		&allChanges,
+		"SELECT seq, tool_unique_id, changed_at FROM tool_changes WHERE user_id = ? ORDER BY seq",
+		string(userID),
+	); err != nil {
+		return entity.ToolsUpdatedSinceEntity{}, pkgerrors.Wrap(err, "fail to select tool changes from rds")
+	}
+	// a user has one change per tool, the times are compared here because sqlite keeps them as text that only
+	// compares correctly within one time zone
+	changes := lo.Filter(allChanges, func(change toolChange, _ int) bool { return !change.ChangedAt.Before(since) })
+
+	result := entity.ToolsUpdatedSinceEntity{
+		Until:           since,
+		CreatedTools:    []entity.ToolEntity{},
+		UpdatedTools:    []entity.ToolEntity{},
+		DeletedToolUIDs: []string{},
+	}
+	if len(changes) == 0 {
+		return result, nil
+	}
+
+	var models []ToolRdsModel
+	if err := db.Select(
+		&models,
+		`SELECT `+toolRdsColumns+` FROM `+toolRdsFrom+`
+		 JOIN tool_changes c ON c.user_id = t.user_id AND c.tool_unique_id = t.unique_id
+		 WHERE c.user_id = ? AND c.seq >= ?`,
+		string(userID),
+		changes[0].Seq,
+	); err != nil {
+		return entity.ToolsUpdatedSinceEntity{}, pkgerrors.Wrap(err, "fail to select changed tools from rds")
+	}
+	toolsByUID := lo.SliceToMap(models, func(model ToolRdsModel) (string, ToolRdsModel) { return model.UniqueID, model })
+
+	// changes without a tool are deletions, the result keeps the order of the changes
+	for _, change := range changes {
+		model, ok := toolsByUID[change.ToolUniqueID]
+		if !ok {
+			result.DeletedToolUIDs = append(result.DeletedToolUIDs, change.ToolUniqueID)
+		} else if tool, err := toToolEntity(r.config.SourceEncryptionSecret, model); err != nil {
+			return entity.ToolsUpdatedSinceEntity{}, err
+		} else if model.CreatedAt.Before(since) {
+			result.UpdatedTools = append(result.UpdatedTools, tool)
+		} else {
+			result.CreatedTools = append(result.CreatedTools, tool)
+		}
+		if change.ChangedAt.After(result.Until) {
+			result.Until = change.ChangedAt
+		}
+	}
+	return result, nil
+}
+
+func (r *ToolRepositoryRdsImpl) AllCategories(userID entity.UserIDEntity) ([]entity.ToolCategoryEntity, error) {
+	db := r.client.DB()
+	var models []toolCategoryRdsModel
+
+	if err := db.Select(
+		&models,
+		"SELECT category, COUNT(*) AS tool_count FROM tools WHERE user_id = ? GROUP BY category ORDER BY category",
+		string(userID),
+	); err != nil {
+		return nil, pkgerrors.Wrap(err, "fail to select tool categories")
+	}
+
+	categories := make([]entity.ToolCategoryEntity, 0, len(models))
+	for _, model := range models {
+		categories = append(categories, entity.NewToolCategoryEntity(model.Category, model.ToolCount))
+	}
+	return categories, nil
+}
+
+func (r *ToolRepositoryRdsImpl) RenameCategory(userID entity.UserIDEntity, from string, to string, eventSource entity.ToolEventSourceEntity) (int64, error) {
+	return r.moveCategory(userID, from, to, false, eventSource)
+}
+
+func (r *ToolRepositoryRdsImpl) MergeCategories(userID entity.UserIDEntity, source string, target string, eventSource entity.ToolEventSourceEntity) (int64, error) {
+	return r.moveCategory(userID, source, target, true, eventSource)
+}
+
+// moveCategory reassigns every tool of the "from" category to the "to" category in one transaction.
+// When targetMustExist is false the target must be unused (rename), otherwise it must be in use (merge).
+func (r *ToolRepositoryRdsImpl) moveCategory(userID entity.UserIDEntity, from string, to string, targetMustExist bool, eventSource entity.ToolEventSourceEntity) (int64, error) {
+	db := r.client.DB()
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, pkgerrors.Wrap(err, "fail to begin tool category transaction")
+	}
+
+	var targetCount int
+	if err := tx.Get(&targetCount, "SELECT COUNT(*) FROM tools WHERE user_id = ? AND category = ?", string(userID), to); err != nil {
+		tx.Rollback()
+		return 0, pkgerrors.Wrap(err, "fail to count tools of target category")
+	}
+	if targetMustExist && targetCount == 0 {
+		tx.Rollback()
+		return 0, pkgerrors.Wrapf(repository.ErrToolCategoryNotFound, "target category %q", to)
+	}
+	if !targetMustExist && targetCount > 0 {
+		tx.Rollback()
+		return 0, pkgerrors.Wrapf(repository.ErrToolCategoryAlreadyExists, "category %q", to)
+	}
+
+	var movedToolUIDs []string
+	if err := tx.Select(&movedToolUIDs, "SELECT unique_id FROM tools WHERE user_id = ? AND category = ?", string(userID), from); err != nil {
+		tx.Rollback()
+		return 0, pkgerrors.Wrap(err, "fail to select tools of source category")
+	}
+
+	now := r.clock.Now()
+	result, err := tx.Exec(
+		"UPDATE tools SET category = ?, updated_at = ? WHERE user_id = ? AND category = ?",
+		to,
+		now,
+		string(userID),
+		from,
+	)
+	if err != nil {
+		tx.Rollback()
+		return 0, pkgerrors.Wrap(err, "fail to update tool category in rds")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, pkgerrors.Wrap(err, "fail to get affected tool count")
+	}
+	if affected == 0 {
+		tx.Rollback()
+		return 0, pkgerrors.Wrapf(repository.ErrToolCategoryNotFound, "category %q", from)
+	}
+
+	for _, toolUID := range movedToolUIDs {
+		if err = r.recordToolChange(tx, userID, toolUID); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if err = recordToolEvent(tx, userID, toolUID, entity.ToolEventTypeUpdated, eventSource, []string{entity.ToolEventFieldCategory}, now); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	if err = r.upsertToolsLastUpdatedAt(tx, userID, now); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, pkgerrors.Wrap(err, "fail to commit tool category transaction")
+	}
+
+	return affected, nil
+}
+
+func (r *ToolRepositoryRdsImpl) AddTag(userID entity.UserIDEntity, toolUID string, tag string, eventSource entity.ToolEventSourceEntity) error {
+	return r.changeTags(userID, toolUID, eventSource, func(tags []string) []string {
+		if lo.Contains(tags, tag) {
+			return tags
+		}
+		return append(tags, tag)
+	})
+}
+
+func (r *ToolRepositoryRdsImpl) RemoveTag(userID entity.UserIDEntity, toolUID string, tag string, eventSource entity.ToolEventSourceEntity) error {
+	return r.changeTags(userID, toolUID, eventSource, func(tags []string) []string {
+		return lo.Without(tags, tag)
+	})
+}
+
+// changeTags rewrites the tags extra info of a tool with the tags returned by change, the tags extra info is removed
+// with the last tag. Tags that come out unchanged leave the tool as it is.
+func (r *ToolRepositoryRdsImpl) changeTags(userID entity.UserIDEntity, toolUID string, eventSource entity.ToolEventSourceEntity, change func(tags []string) []string) error {
+	db := r.client.DB()
+	tx, err := db.Beginx()
+	if err != nil {
+		return pkgerrors.Wrap(err, "fail to begin tool tags transaction")
+	}
+
+	stored, exists, err := r.storedTool(tx, userID, toolUID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if !exists {
+		tx.Rollback()
+		return pkgerrors.Wrapf(repository.ErrToolNotFound, "tool %q", toolUID)
+	}
+
+	info := decodeExtraInfo(stored.ExtraInfo)
+	tags := entity.ParseToolTags(info[entity.ToolExtraInfoKeyTags])
+	changed := change(slices.Clone(tags))
+	if slices.Equal(tags, changed) {
+		tx.Rollback()
+		return nil
+	}
+	if len(changed) == 0 {
+		delete(info, entity.ToolExtraInfoKeyTags)
+	} else {
+		info[entity.ToolExtraInfoKeyTags] = entity.ToolTagsValue(changed)
+	}
+
+	extraInfoJSON, err := encodeExtraInfo(info)
+	if err != nil {
+		tx.Rollback()
+		return pkgerrors.Wrap(err, "fail to encode extra info")
+	}
+
+	now := r.clock.Now()
+	if _, err = tx.Exec(
+		"UPDATE tools SET extra_info = ?, updated_at = ? WHERE user_id = ? AND unique_id = ?",
+		extraInfoJSON,
+		now,
+		string(userID),
+		toolUID,
+	); err != nil {
+		tx.Rollback()
+		return pkgerrors.Wrap(err, "fail to update tool tags in rds")
+	}
+
+	if err = r.replaceToolExtraInfo(tx, userID, toolUID, info); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = r.recordToolChange(tx, userID, toolUID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = recordToolEvent(tx, userID, toolUID, entity.ToolEventTypeUpdated, eventSource, []string{entity.ToolEventFieldExtraInfo}, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = r.upsertToolsLastUpdatedAt(tx, userID, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return pkgerrors.Wrap(err, "fail to commit tool tags transaction")
+	}
+
+	return nil
+}
+
+func (r *ToolRepositoryRdsImpl) SearchByTag(userID entity.UserIDEntity, tag string) ([]entity.ToolEntity, error) {
+	db := r.client.DB()
+
+	var models []ToolRdsModel
+	if err := db.Select(
+		&models,
+		"SELECT "+toolRdsColumns+" FROM "+toolRdsFrom+` WHERE t.user_id = ?
+		 AND t.unique_id IN (SELECT tool_unique_id FROM tool_tags WHERE user_id = ? AND tag = ?)
+		 ORDER BY t.created_at`,
+		string(userID),
+		string(userID),
+		tag,
+	); err != nil {
+		return nil, pkgerrors.Wrap(err, "fail to select tools by tag")
+	}
+
+	return toToolEntities(r.config.SourceEncryptionSecret, models)
+}
+
+type toolTagRdsModel struct {
+	Tag       string `db:"tag"`
+	ToolCount int    `db:"tool_count"`
+}
+
+func (r *ToolRepositoryRdsImpl) AllTags(userID entity.UserIDEntity) ([]entity.ToolTagEntity, error) {
+	db := r.client.DB()
+	var models []toolTagRdsModel
+
+	if err := db.Select(
+		&models,
+		"SELECT tag, COUNT(*) AS tool_count FROM tool_tags WHERE user_id = ? GROUP BY tag ORDER BY tag",
+		string(userID),
+	); err != nil {
+		return nil, pkgerrors.Wrap(err, "fail to select tool tags")
+	}
+
+	tags := make([]entity.ToolTagEntity, 0, len(models))
+	for _, model := range models {
+		tags = append(tags, entity.NewToolTagEntity(model.Tag, model.ToolCount))
+	}
+	return tags, nil
+}
+
+func (r *ToolRepositoryRdsImpl) SearchFullText(userID entity.UserIDEntity, terms []string, limit int) ([]entity.ToolEntity, error) {
+	if len(terms) == 0 {
+		return []entity.ToolEntity{}, nil
+	}
+
+	db := r.client.DB()
+	query, args := toolFullTextQuery(r.config.DBType, userID, terms, limit)
+
+	var models []ToolRdsModel
+	if err := db.Select(&models, query, args...); err != nil {
+		return nil, pkgerrors.Wrap(err, "fail to search tools by full text")
+	}
+
+	return toToolEntities(r.config.SourceEncryptionSecret, models)
+}
+
+// toolSourceHash returns the source hash stored for the tool, false when the user has no such tool.
+// checkToolNameFree returns ErrToolNameTaken with UNIQUE_TOOL_NAMES when another tool of the user has the namespace
+// and name of the tool. A tool that keeps its namespace and name passes, so duplicates saved before the setting was
+// turned on can still be updated.
+func (r *ToolRepositoryRdsImpl) checkToolNameFree(tx *sqlx.Tx, userID entity.UserIDEntity, tool entity.ToolEntity) error {
+	if !r.config.UniqueToolNames {
+		return nil
+	}
+
+	var stored []struct {
+		Namespace string `db:"namespace"`
+		Name      string `db:"name"`
+	}
+	if err := tx.Select(&stored, "SELECT namespace, name FROM tools WHERE user_id = ? AND unique_id = ?", string(userID), tool.UniqueID); err != nil {
+		return pkgerrors.Wrap(err, "fail to select stored tool name from rds")
+	}
+	if len(stored) > 0 && stored[0].Namespace == tool.Namespace && stored[0].Name == tool.Name {
+		return nil
+	}
+
+	var count int
+	if err := tx.Get(&count,
+		"SELECT COUNT(*) FROM tools WHERE user_id = ? AND namespace = ? AND name = ? AND unique_id != ?",
+		string(userID), tool.Namespace, tool.Name, tool.UniqueID,
+	); err != nil {
+		return pkgerrors.Wrap(err, "fail to count tools with the same name in rds")
+	}
+	if count > 0 {
+		return pkgerrors.Wrapf(repository.ErrToolNameTaken, "tool %q in namespace %q", tool.Name, tool.Namespace)
+	}
+	return nil
+}
+
+// checkNewToolIDsFree returns ErrToolIDTaken when the user already has a tool with the id of the new tool, and
+// ErrToolUIDTaken when any tool has its unique id. The unique indexes on the ids refuse a duplicate inserted meanwhile.
+func checkNewToolIDsFree(tx *sqlx.Tx, userID entity.UserIDEntity, tool entity.ToolEntity) error {
+	var count int
+	if err := tx.Get(&count, "SELECT COUNT(*) FROM tools WHERE user_id = ? AND id = ?", string(userID), tool.ID); err != nil {
+		return pkgerrors.Wrap(err, "fail to count tools with the same id in rds")
+	}
+	if count > 0 {
+		return pkgerrors.Wrapf(repository.ErrToolIDTaken, "tool id %q", tool.ID)
+	}
+	if err := tx.Get(&count, "SELECT COUNT(*) FROM tools WHERE unique_id = ?", tool.UniqueID); err != nil {
+		return pkgerrors.Wrap(err, "fail to count tools with the same unique id in rds")
+	}
+	if count > 0 {
+		return pkgerrors.Wrapf(repository.ErrToolUIDTaken, "tool unique id %q", tool.UniqueID)
+	}
+	return nil
+}
+
+// storedTool returns the stored tool, false when the user has no such tool.
+func (r *ToolRepositoryRdsImpl) storedTool(tx *sqlx.Tx, userID entity.UserIDEntity, toolUID string) (ToolRdsModel, bool, error) {
+	var model ToolRdsModel
+	err := tx.Get(&model, "SELECT "+toolRdsColumns+" FROM "+toolRdsFrom+" WHERE t.user_id = ? AND t.unique_id = ?", string(userID), toolUID)
+	if err != nil {
+		if stdErrors.Is(err, sql.ErrNoRows) {
+			return ToolRdsModel{}, false, nil
+		}
+		return ToolRdsModel{}, false, pkgerrors.Wrap(err, "fail to get stored tool")
+	}
+	return model, true, nil
+}
+
+func (r *ToolRepositoryRdsImpl) toolSourceHash(tx *sqlx.Tx, userID entity.UserIDEntity, toolUID string) (string, bool, error) {
+	var hash string
+	err := tx.Get(&hash, "SELECT source_hash FROM tools WHERE user_id = ? AND unique_id = ?", string(userID), toolUID)
+	if err != nil {
+		if stdErrors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, pkgerrors.Wrap(err, "fail to get tool source hash")
+	}
+	return hash, true, nil
+}
+
+// replaceToolExtraInfo rewrites the indexed extra info rows and the tags of a tool, a nil info only removes them.
+func (r *ToolRepositoryRdsImpl) replaceToolExtraInfo(exec execer, userID entity.UserIDEntity, toolUID string, info map[string]string) error {
+	if _, err := exec.Exec(
+		"DELETE FROM tool_extra_info WHERE user_id = ? AND tool_unique_id = ?",
+		string(userID),
+		toolUID,
+	); err != nil {
+		return pkgerrors.Wrap(err, "fail to delete tool extra info")
+	}
+
+	for key, value := range info {
+		if _, err := exec.Exec(
+			"INSERT INTO tool_extra_info (user_id, tool_unique_id, info_key, info_value) VALUES (?, ?, ?, ?)",
+			string(userID),
+			toolUID,
+			key,
+			value,
+		); err != nil {
+			return pkgerrors.Wrapf(err, "fail to insert tool extra info %s", key)
+		}
+	}
+	return replaceToolTags(exec, userID, toolUID, info)
+}
+
+// recordToolChange gives the tool a new change sequence number, the previous one of the tool is dropped
+// so the log holds one row per tool.
+func (r *ToolRepositoryRdsImpl) recordToolChange(exec execer, userID entity.UserIDEntity, toolUID string) error {
+	if _, err := exec.Exec(
+		"DELETE FROM tool_changes WHERE user_id = ? AND tool_unique_id = ?",
+		string(userID),
+		toolUID,
+	); err != nil {
+		return pkgerrors.Wrap(err, "fail to delete previous tool change")
+	}
+
+	if _, err := exec.Exec(
+		"INSERT INTO tool_changes (user_id, tool_unique_id, changed_at) VALUES (?, ?, ?)",
+		string(userID),
+		toolUID,
+		r.clock.Now(),
+	); err != nil {
+		return pkgerrors.Wrap(err, "fail to insert tool change")
+	}
+	return nil
+}
+
 func (r *ToolRepositoryRdsImpl) upsertToolsLastUpdatedAt(exec execer, userID entity.UserIDEntity, updatedAt time.Time) error {
 	var query string
 	switch r.config.DBType {
@@ -260,8 +1137,14 @@ func (r *ToolRepositoryRdsImpl) upsertToolsLastUpdatedAt(exec execer, userID ent
 	return nil
 }
 
-func toToolEntity(model ToolRdsModel) entity.ToolEntity {
-	return entity.NewToolEntityWithUID(
+// toToolEntity converts a row read with toolRdsColumns, opening its source when it is sealed.
+func toToolEntity(secret string, model ToolRdsModel) (entity.ToolEntity, error) {
+	source, err := openToolSource(secret, model.Source, model.SourceEncrypted, model.SourceKey, model.OwnerEncryptKey)
+	if err != nil {
+		return entity.ToolEntity{}, pkgerrors.Wrapf(err, "fail to open source of tool %s", model.UniqueID)
+	}
+
+	tool := entity.NewToolEntityWithUID(
 		model.UniqueID,
 		model.ID,
 		model.Name,
@@ -270,12 +1153,26 @@ func toToolEntity(model ToolRdsModel) entity.ToolEntity {
 		model.IsActivate,
 		model.RealtimeExecution,
 		model.UiWidgets,
-		model.Source,
+		source,
 		model.Description,
 		decodeExtraInfo(model.ExtraInfo),
 		model.CreatedAt,
 		model.UpdatedAt,
 	)
+	tool.IsArchived = model.IsArchived
+	return tool, nil
+}
+
+func toToolEntities(secret string, models []ToolRdsModel) ([]entity.ToolEntity, error) {
+	tools := make([]entity.ToolEntity, 0, len(models))
+	for _, model := range models {
+		tool, err := toToolEntity(secret, model)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
 }
 
 func encodeExtraInfo(info map[string]string) (string, error) {
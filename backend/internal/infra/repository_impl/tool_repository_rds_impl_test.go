@@ -3,12 +3,19 @@ package repository_impl
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
+	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/infra/repository_impl/client"
+	"ya-tool-craft/internal/infra/repository_impl/migration"
 	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
+	"ya-tool-craft/internal/utils"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,9 +26,9 @@ func newTestToolMeta(tag string) (string, map[string]string, string) {
 func TestToolRepositoryRdsImpl_CreateTool(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -31,22 +38,13 @@ func TestToolRepositoryRdsImpl_CreateTool(t *testing.T) {
 		// Test basic tool creation
 		userID := entity.UserIDEntity(user.ID)
 		description, extraInfo, category := newTestToolMeta("create")
-		tool := entity.NewToolEntityWithoutUID(
-			"tool-1",
-			"Test Tool",
-			"test-namespace",
-			category,
-			true,
-			false,
-			`[{"type": "text"}]`,
-			"source code here",
-			description,
-			extraInfo,
-			time.Now(),
-			time.Now(),
-		)
-
-		err = toolRdsImpl.CreateTool(userID, tool)
+		tool := fixtures.NewTestTool().
+			WithCategory(category).
+			WithDescription(description).
+			WithExtraInfo(extraInfo).
+			Build()
+
+		err = toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		// Verify tool was created
@@ -68,9 +66,9 @@ func TestToolRepositoryRdsImpl_CreateTool(t *testing.T) {
 func TestToolRepositoryRdsImpl_CreateTool_MultipleTools(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -82,21 +80,14 @@ func TestToolRepositoryRdsImpl_CreateTool_MultipleTools(t *testing.T) {
 		// Create multiple tools
 		for i := 0; i < 3; i++ {
 			description, extraInfo, category := newTestToolMeta(fmt.Sprintf("multi-%d", i))
-			tool := entity.NewToolEntityWithoutUID(
-				"tool-"+string(rune(i)),
-				"Test Tool "+string(rune(i)),
-				"test-namespace",
-				category,
-				true,
-				false,
-				`[{"type": "text"}]`,
-				"source code here",
-				description,
-				extraInfo,
-				time.Now(),
-				time.Now(),
-			)
-			err = toolRdsImpl.CreateTool(userID, tool)
+			tool := fixtures.NewTestTool().
+				WithID("tool-" + string(rune(i))).
+				WithName("Test Tool " + string(rune(i))).
+				WithCategory(category).
+				WithDescription(description).
+				WithExtraInfo(extraInfo).
+				Build()
+			err = toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{})
 			assert.Nil(t, err)
 		}
 
@@ -110,9 +101,9 @@ func TestToolRepositoryRdsImpl_CreateTool_MultipleTools(t *testing.T) {
 func TestToolRepositoryRdsImpl_UpdateTool(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -123,21 +114,15 @@ func TestToolRepositoryRdsImpl_UpdateTool(t *testing.T) {
 
 		// Create a test tool
 		description, extraInfo, category := newTestToolMeta("update-original")
-		tool := entity.NewToolEntityWithoutUID(
-			"tool-1",
-			"Original Name",
-			"original-namespace",
-			category,
-			true,
-			false,
-			`[{"type": "text"}]`,
-			"original source",
-			description,
-			extraInfo,
-			time.Now(),
-			time.Now(),
-		)
-		err = toolRdsImpl.CreateTool(userID, tool)
+		tool := fixtures.NewTestTool().
+			WithName("Original Name").
+			WithNamespace("original-namespace").
+			WithCategory(category).
+			WithSource("original source").
+			WithDescription(description).
+			WithExtraInfo(extraInfo).
+			Build()
+		err = toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		// Get the created tool
@@ -165,7 +150,7 @@ func TestToolRepositoryRdsImpl_UpdateTool(t *testing.T) {
 		createdTool.UiWidgets = `[{"type": "number"}]`
 		createdTool.Source = "updated source"
 
-		err = toolRdsImpl.UpdateTool(userID, createdTool)
+		err = toolRdsImpl.UpdateTool(userID, createdTool, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		afterUpdate := time.Now()
@@ -200,9 +185,9 @@ func TestToolRepositoryRdsImpl_UpdateTool(t *testing.T) {
 func TestToolRepositoryRdsImpl_DeleteTool(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -213,21 +198,13 @@ func TestToolRepositoryRdsImpl_DeleteTool(t *testing.T) {
 
 		// Create a test tool
 		description, extraInfo, category := newTestToolMeta("delete")
-		tool := entity.NewToolEntityWithoutUID(
-			"tool-1",
-			"Test Tool",
-			"test-namespace",
-			category,
-			true,
-			false,
-			`[{"type": "text"}]`,
-			"source code",
-			description,
-			extraInfo,
-			time.Now(),
-			time.Now(),
-		)
-		err = toolRdsImpl.CreateTool(userID, tool)
+		tool := fixtures.NewTestTool().
+			WithCategory(category).
+			WithSource("source code").
+			WithDescription(description).
+			WithExtraInfo(extraInfo).
+			Build()
+		err = toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		// Verify tool exists
@@ -236,23 +213,32 @@ func TestToolRepositoryRdsImpl_DeleteTool(t *testing.T) {
 		assert.Equal(t, 1, len(allTools.Tools))
 		toolUID := allTools.Tools[0].UniqueID
 
+		secretRdsImpl := NewToolSecretRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+		_, err = secretRdsImpl.PutSecret(ctx, userID, toolUID, "API_KEY", "sealed")
+		assert.Nil(t, err)
+
 		// Delete tool
-		err = toolRdsImpl.DeleteTool(user.ID, toolUID)
+		err = toolRdsImpl.DeleteTool(user.ID, toolUID, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		// Verify tool is deleted
 		allTools, err = toolRdsImpl.AllTools(userID)
 		assert.Nil(t, err)
 		assert.Equal(t, 0, len(allTools.Tools))
+
+		// Verify the tool secrets are deleted with it
+		secrets, err := secretRdsImpl.ListSecrets(ctx, userID, toolUID)
+		assert.Nil(t, err)
+		assert.Empty(t, secrets)
 	})
 }
 
 func TestToolRepositoryRdsImpl_DeleteTool_SpecificToolOnly(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -263,39 +249,28 @@ func TestToolRepositoryRdsImpl_DeleteTool_SpecificToolOnly(t *testing.T) {
 
 		// Create multiple tools
 		description1, extraInfo1, category1 := newTestToolMeta("delete-specific-1")
-		tool1 := entity.NewToolEntityWithoutUID(
-			"tool-1",
-			"Tool 1",
-			"namespace-1",
-			category1,
-			true,
-			false,
-			`[{"type": "text"}]`,
-			"source 1",
-			description1,
-			extraInfo1,
-			time.Now(),
-			time.Now(),
-		)
+		tool1 := fixtures.NewTestTool().
+			WithName("Tool 1").
+			WithNamespace("namespace-1").
+			WithCategory(category1).
+			WithSource("source 1").
+			WithDescription(description1).
+			WithExtraInfo(extraInfo1).
+			Build()
 		description2, extraInfo2, category2 := newTestToolMeta("delete-specific-2")
-		tool2 := entity.NewToolEntityWithoutUID(
-			"tool-2",
-			"Tool 2",
-			"namespace-2",
-			category2,
-			true,
-			false,
-			`[{"type": "text"}]`,
-			"source 2",
-			description2,
-			extraInfo2,
-			time.Now(),
-			time.Now(),
-		)
-
-		err = toolRdsImpl.CreateTool(userID, tool1)
-		assert.Nil(t, err)
-		err = toolRdsImpl.CreateTool(userID, tool2)
+		tool2 := fixtures.NewTestTool().
+			WithID("tool-2").
+			WithName("Tool 2").
+			WithNamespace("namespace-2").
+			WithCategory(category2).
+			WithSource("source 2").
+			WithDescription(description2).
+			WithExtraInfo(extraInfo2).
+			Build()
+
+		err = toolRdsImpl.CreateTool(userID, tool1, entity.ToolEventSourceEntity{})
+		assert.Nil(t, err)
+		err = toolRdsImpl.CreateTool(userID, tool2, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		// Verify both tools exist
@@ -305,7 +280,7 @@ func TestToolRepositoryRdsImpl_DeleteTool_SpecificToolOnly(t *testing.T) {
 
 		// Delete first tool
 		toolUID1 := allTools.Tools[0].UniqueID
-		err = toolRdsImpl.DeleteTool(userID, toolUID1)
+		err = toolRdsImpl.DeleteTool(userID, toolUID1, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		// Verify only second tool remains
@@ -319,9 +294,9 @@ func TestToolRepositoryRdsImpl_DeleteTool_SpecificToolOnly(t *testing.T) {
 func TestToolRepositoryRdsImpl_AllTools(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create test users
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -335,59 +310,43 @@ func TestToolRepositoryRdsImpl_AllTools(t *testing.T) {
 
 		// Create tools for user1
 		description1, extraInfo1, category1 := newTestToolMeta("alltools-user1-1")
-		tool1 := entity.NewToolEntityWithoutUID(
-			"tool-1",
-			"User1 Tool 1",
-			"namespace-1",
-			category1,
-			true,
-			false,
-			`[{"type": "text"}]`,
-			"source 1",
-			description1,
-			extraInfo1,
-			time.Now(),
-			time.Now(),
-		)
+		tool1 := fixtures.NewTestTool().
+			WithName("User1 Tool 1").
+			WithNamespace("namespace-1").
+			WithCategory(category1).
+			WithSource("source 1").
+			WithDescription(description1).
+			WithExtraInfo(extraInfo1).
+			Build()
 		description2, extraInfo2, category2 := newTestToolMeta("alltools-user1-2")
-		tool2 := entity.NewToolEntityWithoutUID(
-			"tool-2",
-			"User1 Tool 2",
-			"namespace-2",
-			category2,
-			true,
-			false,
-			`[{"type": "text"}]`,
-			"source 2",
-			description2,
-			extraInfo2,
-			time.Now(),
-			time.Now(),
-		)
-
-		err = toolRdsImpl.CreateTool(userID1, tool1)
-		assert.Nil(t, err)
-		err = toolRdsImpl.CreateTool(userID1, tool2)
+		tool2 := fixtures.NewTestTool().
+			WithID("tool-2").
+			WithName("User1 Tool 2").
+			WithNamespace("namespace-2").
+			WithCategory(category2).
+			WithSource("source 2").
+			WithDescription(description2).
+			WithExtraInfo(extraInfo2).
+			Build()
+
+		err = toolRdsImpl.CreateTool(userID1, tool1, entity.ToolEventSourceEntity{})
+		assert.Nil(t, err)
+		err = toolRdsImpl.CreateTool(userID1, tool2, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		// Create tool for user2
 		description3, extraInfo3, category3 := newTestToolMeta("alltools-user2-1")
-		tool3 := entity.NewToolEntityWithoutUID(
-			"tool-3",
-			"User2 Tool 1",
-			"namespace-3",
-			category3,
-			true,
-			false,
-			`[{"type": "text"}]`,
-			"source 3",
-			description3,
-			extraInfo3,
-			time.Now(),
-			time.Now(),
-		)
-
-		err = toolRdsImpl.CreateTool(userID2, tool3)
+		tool3 := fixtures.NewTestTool().
+			WithID("tool-3").
+			WithName("User2 Tool 1").
+			WithNamespace("namespace-3").
+			WithCategory(category3).
+			WithSource("source 3").
+			WithDescription(description3).
+			WithExtraInfo(extraInfo3).
+			Build()
+
+		err = toolRdsImpl.CreateTool(userID2, tool3, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		// Test getting all tools for user1
@@ -405,12 +364,46 @@ func TestToolRepositoryRdsImpl_AllTools(t *testing.T) {
 	})
 }
 
+func TestToolRepositoryRdsImpl_GetTool(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		owner, err := userRdsImpl.Create(ctx, "owner", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		other, err := userRdsImpl.Create(ctx, "other", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		ownerID := entity.UserIDEntity(owner.ID)
+
+		tool := fixtures.NewTestTool().WithSource("function handler() { return {}; }").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(ownerID, tool, entity.ToolEventSourceEntity{}))
+
+		got, exists, err := toolRdsImpl.GetTool(ownerID, tool.UniqueID)
+		assert.Nil(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, tool.UniqueID, got.UniqueID)
+		assert.Equal(t, tool.Name, got.Name)
+		assert.Equal(t, tool.Source, got.Source)
+
+		// another user does not see the tool
+		_, exists, err = toolRdsImpl.GetTool(entity.UserIDEntity(other.ID), tool.UniqueID)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+
+		_, exists, err = toolRdsImpl.GetTool(ownerID, "missing")
+		assert.Nil(t, err)
+		assert.False(t, exists)
+	})
+}
+
 func TestToolRepositoryRdsImpl_AllTools_Empty(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create a test user without tools
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -429,9 +422,9 @@ func TestToolRepositoryRdsImpl_AllTools_Empty(t *testing.T) {
 func TestToolRepositoryRdsImpl_ToolsLastUpdatedAt(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -448,21 +441,13 @@ func TestToolRepositoryRdsImpl_ToolsLastUpdatedAt(t *testing.T) {
 		// Create a tool
 		beforeCreation := time.Now()
 		description, extraInfo, category := newTestToolMeta("tools-last-updated")
-		tool := entity.NewToolEntityWithoutUID(
-			"tool-1",
-			"Test Tool",
-			"test-namespace",
-			category,
-			true,
-			false,
-			`[{"type": "text"}]`,
-			"source code",
-			description,
-			extraInfo,
-			time.Now(),
-			time.Now(),
-		)
-		err = toolRdsImpl.CreateTool(userID, tool)
+		tool := fixtures.NewTestTool().
+			WithCategory(category).
+			WithSource("source code").
+			WithDescription(description).
+			WithExtraInfo(extraInfo).
+			Build()
+		err = toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 		afterCreation := time.Now()
 
@@ -478,9 +463,9 @@ func TestToolRepositoryRdsImpl_ToolsLastUpdatedAt(t *testing.T) {
 func TestToolRepositoryRdsImpl_ToolsLastUpdatedAt_UpdatedOnModification(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -491,21 +476,13 @@ func TestToolRepositoryRdsImpl_ToolsLastUpdatedAt_UpdatedOnModification(t *testi
 
 		// Create a tool
 		description, extraInfo, category := newTestToolMeta("tools-last-updated-update")
-		tool := entity.NewToolEntityWithoutUID(
-			"tool-1",
-			"Test Tool",
-			"test-namespace",
-			category,
-			true,
-			false,
-			`[{"type": "text"}]`,
-			"source code",
-			description,
-			extraInfo,
-			time.Now(),
-			time.Now(),
-		)
-		err = toolRdsImpl.CreateTool(userID, tool)
+		tool := fixtures.NewTestTool().
+			WithCategory(category).
+			WithSource("source code").
+			WithDescription(description).
+			WithExtraInfo(extraInfo).
+			Build()
+		err = toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		// Get initial last updated time
@@ -524,7 +501,7 @@ func TestToolRepositoryRdsImpl_ToolsLastUpdatedAt_UpdatedOnModification(t *testi
 		updatedTool.ExtraInfo = updatedExtraInfo
 		updatedTool.Category = updatedCategory
 
-		err = toolRdsImpl.UpdateTool(userID, updatedTool)
+		err = toolRdsImpl.UpdateTool(userID, updatedTool, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		// Get new last updated time
@@ -541,9 +518,9 @@ func TestToolRepositoryRdsImpl_ToolsLastUpdatedAt_UpdatedOnModification(t *testi
 func TestToolRepositoryRdsImpl_ToolsLastUpdatedAt_UpdatedOnDeletion(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -554,21 +531,13 @@ func TestToolRepositoryRdsImpl_ToolsLastUpdatedAt_UpdatedOnDeletion(t *testing.T
 
 		// Create a tool
 		description, extraInfo, category := newTestToolMeta("tools-last-updated-delete")
-		tool := entity.NewToolEntityWithoutUID(
-			"tool-1",
-			"Test Tool",
-			"test-namespace",
-			category,
-			true,
-			false,
-			`[{"type": "text"}]`,
-			"source code",
-			description,
-			extraInfo,
-			time.Now(),
-			time.Now(),
-		)
-		err = toolRdsImpl.CreateTool(userID, tool)
+		tool := fixtures.NewTestTool().
+			WithCategory(category).
+			WithSource("source code").
+			WithDescription(description).
+			WithExtraInfo(extraInfo).
+			Build()
+		err = toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		// Get initial last updated time
@@ -579,7 +548,7 @@ func TestToolRepositoryRdsImpl_ToolsLastUpdatedAt_UpdatedOnDeletion(t *testing.T
 		assert.Nil(t, err)
 		toolUID := allTools.Tools[0].UniqueID
 
-		err = toolRdsImpl.DeleteTool(userID, toolUID)
+		err = toolRdsImpl.DeleteTool(userID, toolUID, entity.ToolEventSourceEntity{})
 		assert.Nil(t, err)
 
 		// Get new last updated time
@@ -597,9 +566,9 @@ func TestToolRepositoryRdsImpl_ToolsLastUpdatedAt_UpdatedOnDeletion(t *testing.T
 func TestToolRepositoryRdsImpl_CreateTool_Concurrent(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create multiple test users (sequential, before concurrent operations)
 		numUsers := 5
@@ -626,22 +595,17 @@ func TestToolRepositoryRdsImpl_CreateTool_Concurrent(t *testing.T) {
 				// Each user creates multiple tools
 				for j := 0; j < numToolsPerUser; j++ {
 					description, extraInfo, category := newTestToolMeta(fmt.Sprintf("concurrent-create-%d-%d", userIdx, j))
-					tool := entity.NewToolEntityWithoutUID(
-						"tool-"+string(rune(userIdx))+"-"+string(rune(j)),
-						"Tool "+string(rune(userIdx))+"-"+string(rune(j)),
-						"namespace-"+string(rune(userIdx)),
-						category,
-						true,
-						false,
-						`[{"type": "text"}]`,
-						"source "+string(rune(userIdx))+"-"+string(rune(j)),
-						description,
-						extraInfo,
-						time.Now(),
-						time.Now(),
-					)
-
-					err := toolRdsImpl.CreateTool(userID, tool)
+					tool := fixtures.NewTestTool().
+						WithID("tool-" + string(rune(userIdx)) + "-" + string(rune(j))).
+						WithName("Tool " + string(rune(userIdx)) + "-" + string(rune(j))).
+						WithNamespace("namespace-" + string(rune(userIdx))).
+						WithCategory(category).
+						WithSource("source " + string(rune(userIdx)) + "-" + string(rune(j))).
+						WithDescription(description).
+						WithExtraInfo(extraInfo).
+						Build()
+
+					err := toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{})
 					if err != nil {
 						errChan <- err
 					}
@@ -675,9 +639,9 @@ func TestToolRepositoryRdsImpl_CreateTool_Concurrent(t *testing.T) {
 func TestToolRepositoryRdsImpl_UpdateTool_Concurrent(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create multiple test users and tools
 		numUsers := 5
@@ -697,22 +661,17 @@ func TestToolRepositoryRdsImpl_UpdateTool_Concurrent(t *testing.T) {
 			// Create tools for this user
 			for j := 0; j < numToolsPerUser; j++ {
 				description, extraInfo, category := newTestToolMeta(fmt.Sprintf("concurrent-update-%d-%d", i, j))
-				tool := entity.NewToolEntityWithoutUID(
-					"tool-"+string(rune(i))+"-"+string(rune(j)),
-					"Tool "+string(rune(i))+"-"+string(rune(j)),
-					"namespace-"+string(rune(i)),
-					category,
-					true,
-					false,
-					`[{"type": "text"}]`,
-					"source "+string(rune(i))+"-"+string(rune(j)),
-					description,
-					extraInfo,
-					time.Now(),
-					time.Now(),
-				)
-
-				err = toolRdsImpl.CreateTool(userID, tool)
+				tool := fixtures.NewTestTool().
+					WithID("tool-" + string(rune(i)) + "-" + string(rune(j))).
+					WithName("Tool " + string(rune(i)) + "-" + string(rune(j))).
+					WithNamespace("namespace-" + string(rune(i))).
+					WithCategory(category).
+					WithSource("source " + string(rune(i)) + "-" + string(rune(j))).
+					WithDescription(description).
+					WithExtraInfo(extraInfo).
+					Build()
+
+				err = toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{})
 				assert.Nil(t, err)
 			}
 
@@ -739,7 +698,7 @@ func TestToolRepositoryRdsImpl_UpdateTool_Concurrent(t *testing.T) {
 					tool.IsActivate = !tool.IsActivate
 					tool.Source = "updated source " + string(rune(userIdx)) + "-" + string(rune(j))
 
-					err := toolRdsImpl.UpdateTool(userID, tool)
+					err := toolRdsImpl.UpdateTool(userID, tool, entity.ToolEventSourceEntity{})
 					if err != nil {
 						errChan <- err
 					}
@@ -780,9 +739,9 @@ func TestToolRepositoryRdsImpl_UpdateTool_Concurrent(t *testing.T) {
 func TestToolRepositoryRdsImpl_DeleteTool_Concurrent(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create multiple test users and tools
 		numUsers := 5
@@ -802,22 +761,17 @@ func TestToolRepositoryRdsImpl_DeleteTool_Concurrent(t *testing.T) {
 			// Create tools for this user
 			for j := 0; j < numToolsPerUser; j++ {
 				description, extraInfo, category := newTestToolMeta(fmt.Sprintf("concurrent-delete-%d-%d", i, j))
-				tool := entity.NewToolEntityWithoutUID(
-					"tool-"+string(rune(i))+"-"+string(rune(j)),
-					"Tool "+string(rune(i))+"-"+string(rune(j)),
-					"namespace-"+string(rune(i)),
-					category,
-					true,
-					false,
-					`[{"type": "text"}]`,
-					"source "+string(rune(i))+"-"+string(rune(j)),
-					description,
-					extraInfo,
-					time.Now(),
-					time.Now(),
-				)
-
-				err = toolRdsImpl.CreateTool(userID, tool)
+				tool := fixtures.NewTestTool().
+					WithID("tool-" + string(rune(i)) + "-" + string(rune(j))).
+					WithName("Tool " + string(rune(i)) + "-" + string(rune(j))).
+					WithNamespace("namespace-" + string(rune(i))).
+					WithCategory(category).
+					WithSource("source " + string(rune(i)) + "-" + string(rune(j))).
+					WithDescription(description).
+					WithExtraInfo(extraInfo).
+					Build()
+
+				err = toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{})
 				assert.Nil(t, err)
 			}
 
@@ -851,7 +805,7 @@ func TestToolRepositoryRdsImpl_DeleteTool_Concurrent(t *testing.T) {
 				// Delete each tool
 				for j := 0; j < len(userToolIDs[userIdx]); j++ {
 					toolID := userToolIDs[userIdx][j]
-					err := toolRdsImpl.DeleteTool(userID, toolID)
+					err := toolRdsImpl.DeleteTool(userID, toolID, entity.ToolEventSourceEntity{})
 					if err != nil {
 						errChan <- err
 					}
@@ -885,9 +839,9 @@ func TestToolRepositoryRdsImpl_DeleteTool_Concurrent(t *testing.T) {
 func TestToolRepositoryRdsImpl_MixedOperations_Concurrent(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
-		toolRdsImpl := NewToolRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
 
 		// Create multiple test users
 		numUsers := 3
@@ -913,22 +867,17 @@ func TestToolRepositoryRdsImpl_MixedOperations_Concurrent(t *testing.T) {
 				// Create initial tools
 				for j := 0; j < 5; j++ {
 					description, extraInfo, category := newTestToolMeta(fmt.Sprintf("mixed-create-initial-%d-%d", userIdx, j))
-					tool := entity.NewToolEntityWithoutUID(
-						"tool-"+string(rune(userIdx))+"-"+string(rune(j)),
-						"Tool "+string(rune(userIdx))+"-"+string(rune(j)),
-						"namespace-"+string(rune(userIdx)),
-						category,
-						true,
-						false,
-						`[{"type": "text"}]`,
-						"source "+string(rune(userIdx))+"-"+string(rune(j)),
-						description,
-						extraInfo,
-						time.Now(),
-						time.Now(),
-					)
-
-					err := toolRdsImpl.CreateTool(userID, tool)
+					tool := fixtures.NewTestTool().
+						WithID("tool-" + string(rune(userIdx)) + "-" + string(rune(j))).
+						WithName("Tool " + string(rune(userIdx)) + "-" + string(rune(j))).
+						WithNamespace("namespace-" + string(rune(userIdx))).
+						WithCategory(category).
+						WithSource("source " + string(rune(userIdx)) + "-" + string(rune(j))).
+						WithDescription(description).
+						WithExtraInfo(extraInfo).
+						Build()
+
+					err := toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{})
 					if err != nil {
 						errChan <- err
 					}
@@ -941,7 +890,7 @@ func TestToolRepositoryRdsImpl_MixedOperations_Concurrent(t *testing.T) {
 				} else if len(allTools.Tools) > 0 {
 					tool := allTools.Tools[0]
 					tool.Name = "Updated-" + string(rune(userIdx))
-					err = toolRdsImpl.UpdateTool(userID, tool)
+					err = toolRdsImpl.UpdateTool(userID, tool, entity.ToolEventSourceEntity{})
 					if err != nil {
 						errChan <- err
 					}
@@ -950,22 +899,17 @@ func TestToolRepositoryRdsImpl_MixedOperations_Concurrent(t *testing.T) {
 				// Create more tools
 				for j := 5; j < 8; j++ {
 					description, extraInfo, category := newTestToolMeta(fmt.Sprintf("mixed-create-more-%d-%d", userIdx, j))
-					tool := entity.NewToolEntityWithoutUID(
-						"tool-"+string(rune(userIdx))+"-"+string(rune(j)),
-						"Tool "+string(rune(userIdx))+"-"+string(rune(j)),
-						"namespace-"+string(rune(userIdx)),
-						category,
-						true,
-						false,
-						`[{"type": "text"}]`,
-						"source "+string(rune(userIdx))+"-"+string(rune(j)),
-						description,
-						extraInfo,
-						time.Now(),
-						time.Now(),
-					)
-
-					err := toolRdsImpl.CreateTool(userID, tool)
+					tool := fixtures.NewTestTool().
+						WithID("tool-" + string(rune(userIdx)) + "-" + string(rune(j))).
+						WithName("Tool " + string(rune(userIdx)) + "-" + string(rune(j))).
+						WithNamespace("namespace-" + string(rune(userIdx))).
+						WithCategory(category).
+						WithSource("source " + string(rune(userIdx)) + "-" + string(rune(j))).
+						WithDescription(description).
+						WithExtraInfo(extraInfo).
+						Build()
+
+					err := toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{})
 					if err != nil {
 						errChan <- err
 					}
@@ -977,11 +921,11 @@ func TestToolRepositoryRdsImpl_MixedOperations_Concurrent(t *testing.T) {
 					errChan <- err
 				} else if len(allTools.Tools) > 2 {
 					// Delete the first 2 tools
-					err = toolRdsImpl.DeleteTool(userID, allTools.Tools[0].UniqueID)
+					err = toolRdsImpl.DeleteTool(userID, allTools.Tools[0].UniqueID, entity.ToolEventSourceEntity{})
 					if err != nil {
 						errChan <- err
 					}
-					err = toolRdsImpl.DeleteTool(userID, allTools.Tools[1].UniqueID)
+					err = toolRdsImpl.DeleteTool(userID, allTools.Tools[1].UniqueID, entity.ToolEventSourceEntity{})
 					if err != nil {
 						errChan <- err
 					}
@@ -1011,3 +955,997 @@ func TestToolRepositoryRdsImpl_MixedOperations_Concurrent(t *testing.T) {
 		}
 	})
 }
+
+func TestToolRepositoryRdsImpl_Categories(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID := entity.UserIDEntity(user.ID)
+
+		otherUser, err := userRdsImpl.Create(ctx, "otheruser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		otherUserID := entity.UserIDEntity(otherUser.ID)
+
+		createTool := func(userID entity.UserIDEntity, id string, category string) {
+			tool := fixtures.NewTestTool().WithID(id).WithCategory(category).Build()
+			assert.Nil(t, toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{}))
+		}
+		createTool(userID, "tool-1", "analytics")
+		createTool(userID, "tool-2", "analytics")
+		createTool(userID, "tool-3", "charts")
+		createTool(userID, "tool-4", "text")
+		createTool(otherUserID, "tool-1", "analytics")
+
+		categories, err := toolRdsImpl.AllCategories(userID)
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.ToolCategoryEntity{
+			entity.NewToolCategoryEntity("analytics", 2),
+			entity.NewToolCategoryEntity("charts", 1),
+			entity.NewToolCategoryEntity("text", 1),
+		}, categories)
+
+		// rename to an unused name moves every tool of the category
+		lastUpdatedBefore, err := toolRdsImpl.ToolsLastUpdatedAt(userID)
+		assert.Nil(t, err)
+		time.Sleep(10 * time.Millisecond)
+		updated, err := toolRdsImpl.RenameCategory(userID, "analytics", "data", entity.ToolEventSourceEntity{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), updated)
+		lastUpdatedAfter, err := toolRdsImpl.ToolsLastUpdatedAt(userID)
+		assert.Nil(t, err)
+		assert.True(t, lastUpdatedAfter.After(*lastUpdatedBefore))
+
+		// rename into an existing category is rejected
+		_, err = toolRdsImpl.RenameCategory(userID, "charts", "text", entity.ToolEventSourceEntity{})
+		assert.ErrorIs(t, err, repository.ErrToolCategoryAlreadyExists)
+
+		// rename of an unknown category is rejected
+		_, err = toolRdsImpl.RenameCategory(userID, "missing", "new", entity.ToolEventSourceEntity{})
+		assert.ErrorIs(t, err, repository.ErrToolCategoryNotFound)
+
+		// merge requires the target to exist
+		_, err = toolRdsImpl.MergeCategories(userID, "charts", "missing", entity.ToolEventSourceEntity{})
+		assert.ErrorIs(t, err, repository.ErrToolCategoryNotFound)
+
+		updated, err = toolRdsImpl.MergeCategories(userID, "charts", "data", entity.ToolEventSourceEntity{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), updated)
+
+		categories, err = toolRdsImpl.AllCategories(userID)
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.ToolCategoryEntity{
+			entity.NewToolCategoryEntity("data", 3),
+			entity.NewToolCategoryEntity("text", 1),
+		}, categories)
+
+		// other users are not affected
+		otherCategories, err := toolRdsImpl.AllCategories(otherUserID)
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.ToolCategoryEntity{entity.NewToolCategoryEntity("analytics", 1)}, otherCategories)
+	})
+}
+
+func TestToolRepositoryRdsImpl_FilterToolsByExtraInfo(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID := entity.UserIDEntity(user.ID)
+
+		otherUser, err := userRdsImpl.Create(ctx, "otheruser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		otherUserID := entity.UserIDEntity(otherUser.ID)
+
+		createTool := func(userID entity.UserIDEntity, id string, extraInfo map[string]string) entity.ToolEntity {
+			tool := fixtures.NewTestTool().WithID(id).WithExtraInfo(extraInfo).Build()
+			assert.Nil(t, toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{}))
+			return tool
+		}
+		toolIDs := func(tools []entity.ToolEntity) []string {
+			ids := make([]string, 0, len(tools))
+			for _, tool := range tools {
+				ids = append(ids, tool.ID)
+			}
+			return ids
+		}
+
+		pythonTool := createTool(userID, "tool-1", map[string]string{"language": "python", "level": "beta"})
+		createTool(userID, "tool-2", map[string]string{"language": "python", "level": "stable"})
+		goTool := createTool(userID, "tool-3", map[string]string{"language": "go"})
+		createTool(otherUserID, "tool-1", map[string]string{"language": "python"})
+
+		tools, err := toolRdsImpl.FilterToolsByExtraInfo(userID, map[string]string{"language": "python"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"tool-1", "tool-2"}, toolIDs(tools))
+		assert.Equal(t, pythonTool.ExtraInfo, tools[0].ExtraInfo)
+
+		// every filter must match
+		tools, err = toolRdsImpl.FilterToolsByExtraInfo(userID, map[string]string{"language": "python", "level": "stable"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"tool-2"}, toolIDs(tools))
+
+		// updates replace the indexed extra info
+		goTool.ExtraInfo = map[string]string{"language": "python"}
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, goTool, entity.ToolEventSourceEntity{}))
+		tools, err = toolRdsImpl.FilterToolsByExtraInfo(userID, map[string]string{"language": "go"})
+		assert.Nil(t, err)
+		assert.Empty(t, tools)
+		tools, err = toolRdsImpl.FilterToolsByExtraInfo(userID, map[string]string{"language": "python"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"tool-1", "tool-2", "tool-3"}, toolIDs(tools))
+
+		// deleted tools are no longer matched
+		assert.Nil(t, toolRdsImpl.DeleteTool(userID, pythonTool.UniqueID, entity.ToolEventSourceEntity{}))
+		tools, err = toolRdsImpl.FilterToolsByExtraInfo(userID, map[string]string{"language": "python"})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"tool-2", "tool-3"}, toolIDs(tools))
+
+		var rows int
+		assert.Nil(t, rdsClient.DB().Get(&rows, "SELECT COUNT(*) FROM tool_extra_info WHERE tool_unique_id = ?", pythonTool.UniqueID))
+		assert.Equal(t, 0, rows)
+	})
+}
+
+func TestToolRepositoryRdsImpl_FilterToolsByExtraInfo_BackfilledByMigration(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID := entity.UserIDEntity(user.ID)
+
+		tool := fixtures.NewTestTool().WithExtraInfo(map[string]string{"language": "python"}).Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{}))
+
+		// simulate a tool stored before extra info was indexed
+		_, err = rdsClient.DB().Exec("DELETE FROM tool_extra_info WHERE user_id = ?", userID)
+		assert.Nil(t, err)
+		_, err = rdsClient.DB().Exec("DELETE FROM schema_migrations WHERE name = ?", "create_tool_extra_info")
+		assert.Nil(t, err)
+
+		tools, err := toolRdsImpl.FilterToolsByExtraInfo(userID, map[string]string{"language": "python"})
+		assert.Nil(t, err)
+		assert.Empty(t, tools)
+
+		assert.Nil(t, migration.NewRdsMigrationImpl(rdsClient, rdsConfig).RunMigrate(ctx))
+
+		tools, err = toolRdsImpl.FilterToolsByExtraInfo(userID, map[string]string{"language": "python"})
+		assert.Nil(t, err)
+		assert.Len(t, tools, 1)
+		assert.Equal(t, tool.UniqueID, tools[0].UniqueID)
+	})
+}
+
+func TestToolRepositoryRdsImpl_SourceDeduplication(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		// the sqlite file is shared between tests, start from empty tables
+		_, err := rdsClient.DB().Exec("DELETE FROM tool_sources")
+		assert.Nil(t, err)
+		_, err = rdsClient.DB().Exec("DELETE FROM tool_versions")
+		assert.Nil(t, err)
+		refCounts := func() map[string]int {
+			var rows []struct {
+				Hash     string `db:"hash"`
+				RefCount int    `db:"ref_count"`
+			}
+			assert.Nil(t, rdsClient.DB().Select(&rows, "SELECT hash, ref_count FROM tool_sources"))
+			counts := map[string]int{}
+			for _, row := range rows {
+				counts[row.Hash] = row.RefCount
+			}
+			return counts
+		}
+
+		user1, err := userRdsImpl.Create(ctx, "dedup-user-1", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		user2, err := userRdsImpl.Create(ctx, "dedup-user-2", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID1 := entity.UserIDEntity(user1.ID)
+		userID2 := entity.UserIDEntity(user2.ID)
+
+		shared := "console.log('template')"
+		sharedHash := entity.ToolSourceHash(shared)
+		tool1 := fixtures.NewTestTool().WithSource(shared).Build()
+		tool2 := fixtures.NewTestTool().WithSource(shared).Build()
+		tool3 := fixtures.NewTestTool().WithID("tool-3").WithSource(shared).Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID1, tool1, entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(userID1, tool3, entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(userID2, tool2, entity.ToolEventSourceEntity{}))
+		// every tool and its first version reference the source
+		assert.Equal(t, map[string]int{sharedHash: 6}, refCounts())
+
+		// the source is stored once, the tools only point at it
+		var storedSource string
+		assert.Nil(t, rdsClient.DB().Get(&storedSource, "SELECT source FROM tools WHERE unique_id = ?", tool1.UniqueID))
+		assert.Equal(t, "", storedSource)
+		tools, err := toolRdsImpl.AllTools(userID1)
+		assert.Nil(t, err)
+		assert.Len(t, tools.Tools, 2)
+		for _, tool := range tools.Tools {
+			assert.Equal(t, shared, tool.Source)
+			assert.Equal(t, sharedHash, tool.SourceHash)
+		}
+
+		// an update with the same source keeps the counts, a new source moves the reference of the tool
+		// and adds one for the new version, the old version keeps its own
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID1, tool1, entity.ToolEventSourceEntity{}))
+		assert.Equal(t, map[string]int{sharedHash: 6}, refCounts())
+		tool1.Source = "console.log('changed')"
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID1, tool1, entity.ToolEventSourceEntity{}))
+		assert.Equal(t, map[string]int{sharedHash: 5, entity.ToolSourceHash(tool1.Source): 2}, refCounts())
+
+		// the last reference deletes the source, deleting a tool releases its versions too
+		assert.Nil(t, toolRdsImpl.DeleteTool(userID1, tool1.UniqueID, entity.ToolEventSourceEntity{}))
+		assert.Equal(t, map[string]int{sharedHash: 4}, refCounts())
+
+		// a tool written before the deduplication keeps its source in the tools table
+		_, err = rdsClient.DB().Exec("UPDATE tools SET source = ?, source_hash = '' WHERE unique_id = ?", "legacy", tool3.UniqueID)
+		assert.Nil(t, err)
+		_, err = rdsClient.DB().Exec("UPDATE tool_sources SET ref_count = ref_count - 1 WHERE hash = ?", sharedHash)
+		assert.Nil(t, err)
+		tools, err = toolRdsImpl.AllTools(userID1)
+		assert.Nil(t, err)
+		assert.Len(t, tools.Tools, 1)
+		assert.Equal(t, "legacy", tools.Tools[0].Source)
+		assert.Equal(t, entity.ToolSourceHash("legacy"), tools.Tools[0].SourceHash)
+		tool3.Source = shared
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID1, tool3, entity.ToolEventSourceEntity{}))
+		assert.Equal(t, map[string]int{sharedHash: 4}, refCounts())
+
+		// deleting a user releases the sources of all its tools and versions
+		assert.Nil(t, userRdsImpl.DeleteUserWithAllData(ctx, userID1))
+		assert.Equal(t, map[string]int{sharedHash: 2}, refCounts())
+		assert.Nil(t, userRdsImpl.DeleteUserWithAllData(ctx, userID2))
+		assert.Empty(t, refCounts())
+	})
+}
+
+func TestToolRepositoryRdsImpl_SetToolArchived(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID := entity.UserIDEntity(user.ID)
+
+		otherUser, err := userRdsImpl.Create(ctx, "otheruser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		otherUserID := entity.UserIDEntity(otherUser.ID)
+
+		tool := fixtures.NewTestTool().Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{}))
+		activeTool := fixtures.NewTestTool().WithID("tool-2").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, activeTool, entity.ToolEventSourceEntity{}))
+
+		lastUpdatedBefore, err := toolRdsImpl.ToolsLastUpdatedAt(userID)
+		assert.Nil(t, err)
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Nil(t, toolRdsImpl.SetToolArchived(userID, tool.UniqueID, true, entity.ToolEventSourceEntity{}))
+
+		lastUpdatedAfter, err := toolRdsImpl.ToolsLastUpdatedAt(userID)
+		assert.Nil(t, err)
+		assert.True(t, lastUpdatedAfter.After(*lastUpdatedBefore))
+
+		allTools, err := toolRdsImpl.AllTools(userID)
+		assert.Nil(t, err)
+		archived := allTools.FilterArchived(entity.ToolArchiveFilterOnly).Tools
+		assert.Len(t, archived, 1)
+		assert.Equal(t, tool.UniqueID, archived[0].UniqueID)
+		assert.Len(t, allTools.FilterArchived(entity.ToolArchiveFilterExclude).Tools, 1)
+		assert.Len(t, allTools.FilterArchived(entity.ToolArchiveFilterInclude).Tools, 2)
+
+		// editing an archived tool keeps it archived
+		tool.Name = "renamed"
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, tool, entity.ToolEventSourceEntity{}))
+		allTools, err = toolRdsImpl.AllTools(userID)
+		assert.Nil(t, err)
+		archived = allTools.FilterArchived(entity.ToolArchiveFilterOnly).Tools
+		assert.Len(t, archived, 1)
+		assert.Equal(t, "renamed", archived[0].Name)
+
+		assert.Nil(t, toolRdsImpl.SetToolArchived(userID, tool.UniqueID, false, entity.ToolEventSourceEntity{}))
+		allTools, err = toolRdsImpl.AllTools(userID)
+		assert.Nil(t, err)
+		assert.Empty(t, allTools.FilterArchived(entity.ToolArchiveFilterOnly).Tools)
+
+		// tools of other users cannot be archived
+		err = toolRdsImpl.SetToolArchived(otherUserID, tool.UniqueID, true, entity.ToolEventSourceEntity{})
+		assert.ErrorIs(t, err, repository.ErrToolNotFound)
+		err = toolRdsImpl.SetToolArchived(userID, "tool-missing", true, entity.ToolEventSourceEntity{})
+		assert.ErrorIs(t, err, repository.ErrToolNotFound)
+	})
+}
+
+func TestToolRepositoryRdsImpl_UniqueToolNames(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		cfg := rdsConfig
+		cfg.UniqueToolNames = true
+		userRdsImpl := NewUserRepositoryRdsImpl(cfg, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(cfg, rdsClient, client.NewSystemClock())
+		allowingRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID := entity.UserIDEntity(user.ID)
+		otherUser, err := userRdsImpl.Create(ctx, "otheruser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		otherUserID := entity.UserIDEntity(otherUser.ID)
+
+		tool := fixtures.NewTestTool().WithID("tool-1").WithName("Format").WithNamespace("json").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{}))
+
+		// the same name is refused in the namespace, allowed in another namespace and for another user
+		duplicate := fixtures.NewTestTool().WithID("tool-2").WithName("Format").WithNamespace("json").Build()
+		err = toolRdsImpl.CreateTool(userID, duplicate, entity.ToolEventSourceEntity{})
+		assert.ErrorIs(t, err, repository.ErrToolNameTaken)
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, fixtures.NewTestTool().WithID("tool-3").WithName("Format").WithNamespace("yaml").Build(), entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(otherUserID, fixtures.NewTestTool().WithID("tool-1").WithName("Format").WithNamespace("json").Build(), entity.ToolEventSourceEntity{}))
+
+		// renaming into a taken name is refused, saving a tool under its own name is not
+		other := fixtures.NewTestTool().WithID("tool-4").WithName("Minify").WithNamespace("json").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, other, entity.ToolEventSourceEntity{}))
+		other.Name = "Format"
+		assert.ErrorIs(t, toolRdsImpl.UpdateTool(userID, other, entity.ToolEventSourceEntity{}), repository.ErrToolNameTaken)
+		tool.Source = "async function handler() { return 2 }"
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, tool, entity.ToolEventSourceEntity{}))
+
+		// duplicates saved before the setting was turned on can still be edited
+		assert.Nil(t, allowingRdsImpl.CreateTool(userID, duplicate, entity.ToolEventSourceEntity{}))
+		duplicate.Description = "edited"
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, duplicate, entity.ToolEventSourceEntity{}))
+
+		allTools, err := toolRdsImpl.AllTools(userID)
+		assert.Nil(t, err)
+		assert.Len(t, allTools.Tools, 4)
+	})
+}
+
+func TestToolRepositoryRdsImpl_CreateTool_TakenIDs(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID := entity.UserIDEntity(user.ID)
+		otherUser, err := userRdsImpl.Create(ctx, "otheruser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		otherUserID := entity.UserIDEntity(otherUser.ID)
+
+		tool := fixtures.NewTestTool().WithID("tool-1").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{}))
+
+		// the id is taken for the user only, the unique id for every user
+		assert.ErrorIs(t, toolRdsImpl.CreateTool(userID, fixtures.NewTestTool().WithID("tool-1").Build(), entity.ToolEventSourceEntity{}), repository.ErrToolIDTaken)
+		assert.ErrorIs(t, toolRdsImpl.CreateTool(otherUserID, fixtures.NewTestTool().WithID("tool-2").WithUniqueID(tool.UniqueID).Build(), entity.ToolEventSourceEntity{}), repository.ErrToolUIDTaken)
+		assert.Nil(t, toolRdsImpl.CreateTool(otherUserID, fixtures.NewTestTool().WithID("tool-1").Build(), entity.ToolEventSourceEntity{}))
+
+		allTools, err := toolRdsImpl.AllTools(userID)
+		assert.Nil(t, err)
+		assert.Len(t, allTools.Tools, 1)
+	})
+}
+
+func TestToolRepositoryRdsImpl_ToolChangesSince(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID := user.ID
+
+		// no tools, no changes
+		changes, err := toolRdsImpl.ToolChangesSince(userID, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), changes.Cursor)
+		assert.Empty(t, changes.Tools)
+		assert.Empty(t, changes.DeletedToolUIDs)
+
+		first := fixtures.NewTestTool().WithUniqueID("uid-sync-1").WithID("sync-1").WithCategory("a").Build()
+		second := fixtures.NewTestTool().WithUniqueID("uid-sync-2").WithID("sync-2").WithCategory("a").Build()
+		third := fixtures.NewTestTool().WithUniqueID("uid-sync-3").WithID("sync-3").WithCategory("b").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, first, entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, second, entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, third, entity.ToolEventSourceEntity{}))
+
+		// a new device gets every tool
+		changes, err = toolRdsImpl.ToolChangesSince(userID, 0)
+		assert.Nil(t, err)
+		assert.Len(t, changes.Tools, 3)
+		assert.Empty(t, changes.DeletedToolUIDs)
+		latest, err := toolRdsImpl.LatestToolChangeCursor(userID)
+		assert.Nil(t, err)
+		assert.Equal(t, latest, changes.Cursor)
+		synced := changes.Cursor
+
+		// update, delete, archive and a category move after the cursor
+		first.Name = "renamed"
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, first, entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.DeleteTool(userID, second.UniqueID, entity.ToolEventSourceEntity{}))
+		_, err = toolRdsImpl.RenameCategory(userID, "b", "c", entity.ToolEventSourceEntity{})
+		assert.Nil(t, err)
+
+		changes, err = toolRdsImpl.ToolChangesSince(userID, synced)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{second.UniqueID}, changes.DeletedToolUIDs)
+		assert.Len(t, changes.Tools, 2)
+		assert.Equal(t, "renamed", changes.Tools[0].Name)
+		assert.Equal(t, "c", changes.Tools[1].Category)
+		assert.Greater(t, changes.Cursor, synced)
+
+		// nothing after the newest cursor
+		changes, err = toolRdsImpl.ToolChangesSince(userID, changes.Cursor)
+		assert.Nil(t, err)
+		assert.Empty(t, changes.Tools)
+		assert.Empty(t, changes.DeletedToolUIDs)
+
+		// other users see nothing
+		other, err := toolRdsImpl.ToolChangesSince(entity.UserIDEntity("u-other"), 0)
+		assert.Nil(t, err)
+		assert.Empty(t, other.Tools)
+	})
+}
+
+func TestToolRepositoryRdsImpl_ToolsUpdatedSince(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		clock := fixtures.NewFakeClock(time.Unix(1000, 0).UTC())
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, clock)
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID := user.ID
+
+		// no tools, nothing changed and until stays at since
+		changes, err := toolRdsImpl.ToolsUpdatedSince(userID, time.Time{})
+		assert.Nil(t, err)
+		assert.True(t, changes.Until.IsZero())
+		assert.Empty(t, changes.CreatedTools)
+		assert.Empty(t, changes.UpdatedTools)
+		assert.Empty(t, changes.DeletedToolUIDs)
+
+		first := fixtures.NewTestTool().WithUniqueID("uid-since-1").WithID("since-1").Build()
+		second := fixtures.NewTestTool().WithUniqueID("uid-since-2").WithID("since-2").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, first, entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, second, entity.ToolEventSourceEntity{}))
+
+		// a zero since returns every tool as created
+		changes, err = toolRdsImpl.ToolsUpdatedSince(userID, time.Time{})
+		assert.Nil(t, err)
+		assert.Len(t, changes.CreatedTools, 2)
+		assert.Empty(t, changes.UpdatedTools)
+		assert.True(t, changes.Until.Equal(time.Unix(1000, 0)))
+
+		// an update, a deletion and a new tool later
+		clock.Advance(time.Minute)
+		since := clock.Now()
+		first.Name = "renamed"
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, first, entity.ToolEventSourceEntity{}))
+		clock.Advance(time.Second)
+		assert.Nil(t, toolRdsImpl.DeleteTool(userID, second.UniqueID, entity.ToolEventSourceEntity{}))
+		third := fixtures.NewTestTool().WithUniqueID("uid-since-3").WithID("since-3").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, third, entity.ToolEventSourceEntity{}))
+
+		// since is compared as a time, not as text in the stored time zone
+		changes, err = toolRdsImpl.ToolsUpdatedSince(userID, since.In(time.FixedZone("JST", 9*60*60)))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{third.UniqueID}, lo.Map(changes.CreatedTools, func(tool entity.ToolEntity, _ int) string { return tool.UniqueID }))
+		assert.Len(t, changes.UpdatedTools, 1)
+		assert.Equal(t, "renamed", changes.UpdatedTools[0].Name)
+		assert.Equal(t, []string{second.UniqueID}, changes.DeletedToolUIDs)
+		assert.True(t, changes.Until.Equal(since.Add(time.Second)))
+
+		// the changes at until come again, later ones do not exist yet
+		changes, err = toolRdsImpl.ToolsUpdatedSince(userID, changes.Until)
+		assert.Nil(t, err)
+		assert.Len(t, changes.CreatedTools, 1)
+		assert.Empty(t, changes.UpdatedTools)
+		assert.Equal(t, []string{second.UniqueID}, changes.DeletedToolUIDs)
+
+		// other users see nothing
+		other, err := toolRdsImpl.ToolsUpdatedSince(entity.UserIDEntity("u-other"), time.Time{})
+		assert.Nil(t, err)
+		assert.Empty(t, other.CreatedTools)
+	})
+}
+
+func TestToolRepositoryRdsImpl_ToolChangesPage(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID := user.ID
+
+		for i := 1; i <= 5; i++ {
+			tool := fixtures.NewTestTool().WithUniqueID(fmt.Sprintf("uid-page-%d", i)).WithID(fmt.Sprintf("page-%d", i)).Build()
+			assert.Nil(t, toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{}))
+		}
+		assert.Nil(t, toolRdsImpl.DeleteTool(userID, "uid-page-2", entity.ToolEventSourceEntity{}))
+		upTo, err := toolRdsImpl.LatestToolChangeCursor(userID)
+		assert.Nil(t, err)
+
+		// a change after upTo is left for the next sync
+		late := fixtures.NewTestTool().WithUniqueID("uid-page-late").WithID("page-late").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, late, entity.ToolEventSourceEntity{}))
+
+		var uids, deleted []string
+		cursor := int64(0)
+		pages := 0
+		for {
+			page, err := toolRdsImpl.ToolChangesPage(userID, cursor, upTo, 2)
+			assert.Nil(t, err)
+			if len(page.Tools) == 0 && len(page.DeletedToolUIDs) == 0 {
+				assert.Equal(t, cursor, page.Cursor)
+				break
+			}
+			assert.LessOrEqual(t, len(page.Tools)+len(page.DeletedToolUIDs), 2)
+			for _, tool := range page.Tools {
+				uids = append(uids, tool.UniqueID)
+			}
+			deleted = append(deleted, page.DeletedToolUIDs...)
+			assert.Greater(t, page.Cursor, cursor)
+			cursor = page.Cursor
+			pages++
+		}
+		assert.Equal(t, []string{"uid-page-1", "uid-page-3", "uid-page-4", "uid-page-5"}, uids)
+		assert.Equal(t, []string{"uid-page-2"}, deleted)
+		assert.Equal(t, upTo, cursor)
+		assert.Equal(t, 3, pages)
+	})
+}
+
+func TestToolRepositoryRdsImpl_Tags(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID := entity.UserIDEntity(user.ID)
+
+		jsonTool := fixtures.NewTestTool().WithID("tool-1").WithExtraInfo(map[string]string{entity.ToolExtraInfoKeyTags: "JSON, Text"}).Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, jsonTool, entity.ToolEventSourceEntity{}))
+		plainTool := fixtures.NewTestTool().WithID("tool-2").WithExtraInfo(map[string]string{"language": "go"}).Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, plainTool, entity.ToolEventSourceEntity{}))
+
+		tags, err := toolRdsImpl.AllTags(userID)
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.ToolTagEntity{{Name: "json", ToolCount: 1}, {Name: "text", ToolCount: 1}}, tags)
+
+		// adding a tag writes it to the extra info the clients see
+		assert.Nil(t, toolRdsImpl.AddTag(userID, plainTool.UniqueID, "text", entity.ToolEventSourceEntity{}))
+		tools, err := toolRdsImpl.SearchByTag(userID, "text")
+		assert.Nil(t, err)
+		assert.Len(t, tools, 2)
+		assert.Equal(t, plainTool.UniqueID, tools[1].UniqueID)
+		assert.Equal(t, map[string]string{"language": "go", entity.ToolExtraInfoKeyTags: "text"}, tools[1].ExtraInfo)
+
+		// adding a tag twice changes nothing
+		cursor, err := toolRdsImpl.LatestToolChangeCursor(userID)
+		assert.Nil(t, err)
+		assert.Nil(t, toolRdsImpl.AddTag(userID, plainTool.UniqueID, "text", entity.ToolEventSourceEntity{}))
+		unchanged, err := toolRdsImpl.LatestToolChangeCursor(userID)
+		assert.Nil(t, err)
+		assert.Equal(t, cursor, unchanged)
+
+		// the last tag takes the tags extra info with it
+		assert.Nil(t, toolRdsImpl.RemoveTag(userID, plainTool.UniqueID, "text", entity.ToolEventSourceEntity{}))
+		tools, err = toolRdsImpl.SearchByTag(userID, "text")
+		assert.Nil(t, err)
+		assert.Len(t, tools, 1)
+		assert.Equal(t, jsonTool.UniqueID, tools[0].UniqueID)
+		tools, err = toolRdsImpl.FilterToolsByExtraInfo(userID, map[string]string{"language": "go"})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"language": "go"}, tools[0].ExtraInfo)
+
+		err = toolRdsImpl.AddTag(userID, "missing-tool", "text", entity.ToolEventSourceEntity{})
+		assert.ErrorIs(t, err, repository.ErrToolNotFound)
+
+		// updates and deletes keep the tags in step with the extra info
+		jsonTool.ExtraInfo = map[string]string{entity.ToolExtraInfoKeyTags: "json"}
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, jsonTool, entity.ToolEventSourceEntity{}))
+		tags, err = toolRdsImpl.AllTags(userID)
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.ToolTagEntity{{Name: "json", ToolCount: 1}}, tags)
+
+		assert.Nil(t, toolRdsImpl.DeleteTool(userID, jsonTool.UniqueID, entity.ToolEventSourceEntity{}))
+		tags, err = toolRdsImpl.AllTags(userID)
+		assert.Nil(t, err)
+		assert.Empty(t, tags)
+	})
+}
+
+func TestToolRepositoryRdsImpl_SearchFullText(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID := entity.UserIDEntity(user.ID)
+		otherUser, err := userRdsImpl.Create(ctx, "otheruser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		otherUserID := entity.UserIDEntity(otherUser.ID)
+
+		inSource := fixtures.NewTestTool().WithID("tool-1").WithName("Formatter").WithDescription("Pretty prints documents").
+			WithSource("function handler(input) { return JSON.stringify(input, null, 2) }").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, inSource, entity.ToolEventSourceEntity{}))
+		inName := fixtures.NewTestTool().WithID("tool-2").WithName("JSON Viewer").WithDescription("Shows a tree").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, inName, entity.ToolEventSourceEntity{}))
+		unrelated := fixtures.NewTestTool().WithID("tool-3").WithName("Timer").WithDescription("Counts down").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, unrelated, entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(otherUserID, fixtures.NewTestTool().WithName("JSON Viewer").Build(), entity.ToolEventSourceEntity{}))
+
+		toolUIDs := func(tools []entity.ToolEntity) []string {
+			return lo.Map(tools, func(tool entity.ToolEntity, _ int) string { return tool.UniqueID })
+		}
+
+		// terms are word prefixes, a match in the name ranks above one in the source
+		tools, err := toolRdsImpl.SearchFullText(userID, []string{"json"}, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{inName.UniqueID, inSource.UniqueID}, toolUIDs(tools))
+		assert.Equal(t, inSource.Source, tools[1].Source)
+
+		// every term must match
+		tools, err = toolRdsImpl.SearchFullText(userID, []string{"json", "tree"}, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{inName.UniqueID}, toolUIDs(tools))
+
+		tools, err = toolRdsImpl.SearchFullText(userID, []string{"json"}, 1)
+		assert.Nil(t, err)
+		assert.Len(t, tools, 1)
+
+		// updates and deletes keep the search in step with the tools
+		unrelated.Description = "Counts down to a json deadline"
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, unrelated, entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.DeleteTool(userID, inName.UniqueID, entity.ToolEventSourceEntity{}))
+		tools, err = toolRdsImpl.SearchFullText(userID, []string{"json"}, 10)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{inSource.UniqueID, unrelated.UniqueID}, toolUIDs(tools))
+
+		tools, err = toolRdsImpl.SearchFullText(userID, nil, 10)
+		assert.Nil(t, err)
+		assert.Empty(t, tools)
+	})
+}
+
+func TestToolRepositoryRdsImpl_ListTools(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		userID := entity.UserIDEntity(user.ID)
+
+		tools := []entity.ToolEntity{
+			fixtures.NewTestTool().WithID("tool-1").WithName("Charlie").WithNamespace("utils").WithCategory("text").Build(),
+			fixtures.NewTestTool().WithID("tool-2").WithName("Alpha").WithNamespace("utils").WithActivate(false).Build(),
+			fixtures.NewTestTool().WithID("tool-3").WithName("Bravo").WithNamespace("dev").WithCategory("text").Build(),
+			fixtures.NewTestTool().WithID("tool-4").WithName("Delta").WithNamespace("utils").Build(),
+		}
+		for _, tool := range tools {
+			assert.Nil(t, toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{}))
+			// the creation times tell the tools apart
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Nil(t, toolRdsImpl.SetToolArchived(userID, tools[3].UniqueID, true, entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool("other-user", fixtures.NewTestTool().Build(), entity.ToolEventSourceEntity{}))
+
+		toolIDs := func(tools []entity.ToolEntity) []string {
+			return lo.Map(tools, func(tool entity.ToolEntity, _ int) string { return tool.ID })
+		}
+
+		// archived tools are left out by default, the oldest come first
+		page, total, err := toolRdsImpl.ListTools(userID, entity.ToolListOptions{Limit: 2})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, total)
+		assert.Equal(t, []string{"tool-1", "tool-2"}, toolIDs(page))
+		assert.Equal(t, tools[0].Source, page[0].Source)
+
+		page, total, err = toolRdsImpl.ListTools(userID, entity.ToolListOptions{Limit: 2, Offset: 2})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, total)
+		assert.Equal(t, []string{"tool-3"}, toolIDs(page))
+
+		utils, active, noCategory := "utils", true, ""
+		page, total, err = toolRdsImpl.ListTools(userID, entity.ToolListOptions{Namespace: &utils, SortBy: entity.ToolSortByName})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, []string{"tool-2", "tool-1"}, toolIDs(page))
+
+		page, _, err = toolRdsImpl.ListTools(userID, entity.ToolListOptions{IsActivate: &active, Archived: entity.ToolArchiveFilterInclude, SortBy: entity.ToolSortByUpdatedAt})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"tool-4", "tool-3", "tool-1"}, toolIDs(page))
+
+		page, total, err = toolRdsImpl.ListTools(userID, entity.ToolListOptions{Category: &noCategory, Archived: entity.ToolArchiveFilterOnly})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, []string{"tool-4"}, toolIDs(page))
+	})
+}
+
+func TestToolRepositoryRdsImpl_EncryptSourcesAtRest(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		plainToolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+		plainGlobalScriptRdsImpl := NewGlobalScriptRepositoryRdsImpl(rdsConfig, rdsClient)
+		encryptingConfig := rdsConfig
+		encryptingConfig.EncryptSourcesAtRest = true
+		encryptingConfig.SourceEncryptionSecret = "a source encryption secret of the tests"
+		toolRdsImpl := NewToolRepositoryRdsImpl(encryptingConfig, rdsClient, client.NewSystemClock())
+		globalScriptRdsImpl := NewGlobalScriptRepositoryRdsImpl(encryptingConfig, rdsClient)
+		versionRdsImpl := NewToolVersionRepositoryRdsImpl(encryptingConfig, rdsClient)
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		otherUser, err := userRdsImpl.Create(ctx, "otheruser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+
+		type storedToolSource struct {
+			Hash      string `db:"hash"`
+			Source    string `db:"source"`
+			Encrypted bool   `db:"encrypted"`
+			RefCount  int    `db:"ref_count"`
+		}
+		storedSource := func(userID entity.UserIDEntity, toolUID string) storedToolSource {
+			var row storedToolSource
+			assert.Nil(t, rdsClient.DB().Get(&row,
+				`SELECT s.hash, s.source, s.encrypted, s.ref_count FROM tools t JOIN tool_sources s ON s.hash = t.source_hash
+				 WHERE t.user_id = ? AND t.unique_id = ?`,
+				string(userID), toolUID,
+			))
+			return row
+		}
+
+		// the sources stored before stay in plaintext until they are encrypted, two users share one of them
+		const sharedSource = "function handler() { return 'shared plaintext' }"
+		mine := fixtures.NewTestTool().WithID("mine").WithName("Mine").WithSource(sharedSource).Build()
+		theirs := fixtures.NewTestTool().WithID("theirs").WithName("Theirs").WithSource(sharedSource).Build()
+		assert.Nil(t, plainToolRdsImpl.CreateTool(user.ID, mine, entity.ToolEventSourceEntity{}))
+		assert.Nil(t, plainToolRdsImpl.CreateTool(otherUser.ID, theirs, entity.ToolEventSourceEntity{}))
+		assert.Nil(t, plainGlobalScriptRdsImpl.UpdateGlobalScript(user.ID, "globalThis.plain = true"))
+		stored := storedSource(user.ID, mine.UniqueID)
+		assert.Equal(t, entity.ToolSourceHash(sharedSource), stored.Hash)
+		assert.Equal(t, sharedSource, stored.Source)
+		assert.False(t, stored.Encrypted)
+		assert.Equal(t, 4, stored.RefCount)
+
+		// the plaintext search copies are dropped at startup
+		matches, err := toolRdsImpl.SearchFullText(user.ID, []string{"plaintext"}, 10)
+		assert.Nil(t, err)
+		assert.Len(t, matches, 1)
+		assert.Nil(t, toolRdsImpl.DropSearchSources())
+		matches, err = toolRdsImpl.SearchFullText(user.ID, []string{"plaintext"}, 10)
+		assert.Nil(t, err)
+		assert.Empty(t, matches)
+
+		// each user gets a copy sealed for it alone, the tool and its version still share theirs
+		moved, err := toolRdsImpl.EncryptStoredSources()
+		assert.Nil(t, err)
+		assert.Equal(t, 4, moved)
+		sealed, err := globalScriptRdsImpl.EncryptGlobalScripts()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, sealed)
+		var plainCopies int
+		assert.Nil(t, rdsClient.DB().Get(&plainCopies, "SELECT COUNT(*) FROM tool_sources WHERE hash = ?", entity.ToolSourceHash(sharedSource)))
+		assert.Equal(t, 0, plainCopies)
+		stored = storedSource(user.ID, mine.UniqueID)
+		theirStored := storedSource(otherUser.ID, theirs.UniqueID)
+		assert.NotEqual(t, stored.Hash, theirStored.Hash)
+		assert.NotEqual(t, stored.Source, theirStored.Source)
+		for _, row := range []storedToolSource{stored, theirStored} {
+			assert.NotContains(t, row.Source, "shared plaintext")
+			assert.True(t, row.Encrypted)
+			assert.Equal(t, 2, row.RefCount)
+		}
+
+		// a second run finds nothing left to seal
+		moved, err = toolRdsImpl.EncryptStoredSources()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, moved)
+
+		// both owners read their source back, and so do the versions with the sha256 of the source
+		for _, owner := range []entity.UserIDEntity{user.ID, otherUser.ID} {
+			tools, err := toolRdsImpl.AllTools(owner)
+			assert.Nil(t, err)
+			assert.Len(t, tools.Tools, 1)
+			assert.Equal(t, sharedSource, tools.Tools[0].Source)
+			assert.Equal(t, entity.ToolSourceHash(sharedSource), tools.Tools[0].SourceHash)
+		}
+		version, found, err := versionRdsImpl.GetVersion(ctx, user.ID, mine.UniqueID, 1)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, sharedSource, version.Source)
+		assert.Equal(t, entity.ToolSourceHash(sharedSource), version.SourceHash)
+		script, err := globalScriptRdsImpl.GetGlobalScript(user.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "globalThis.plain = true", script.Script)
+
+		// the sources saved from now on are sealed right away, a save that changes nothing adds no version
+		assert.Nil(t, toolRdsImpl.UpdateTool(user.ID, mine, entity.ToolEventSourceEntity{}))
+		mine.Source = "function handler() { return 'new plaintext' }"
+		assert.Nil(t, toolRdsImpl.UpdateTool(user.ID, mine, entity.ToolEventSourceEntity{}))
+		stored = storedSource(user.ID, mine.UniqueID)
+		assert.NotEqual(t, entity.ToolSourceHash(mine.Source), stored.Hash)
+		assert.NotContains(t, stored.Source, "new plaintext")
+		assert.True(t, stored.Encrypted)
+		tools, err := toolRdsImpl.AllTools(user.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, mine.Source, tools.Tools[0].Source)
+		versions, err := versionRdsImpl.ListVersions(ctx, user.ID, mine.UniqueID)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{entity.ToolSourceHash(mine.Source), entity.ToolSourceHash(sharedSource)},
+			lo.Map(versions, func(version entity.ToolVersionEntity, _ int) string { return version.SourceHash }))
+		version, _, err = versionRdsImpl.GetVersion(ctx, user.ID, mine.UniqueID, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, mine.Source, version.Source)
+
+		// the same source saved by another user is sealed apart
+		theirs.Source = mine.Source
+		assert.Nil(t, toolRdsImpl.UpdateTool(otherUser.ID, theirs, entity.ToolEventSourceEntity{}))
+		theirStored = storedSource(otherUser.ID, theirs.UniqueID)
+		assert.NotEqual(t, stored.Hash, theirStored.Hash)
+		assert.Equal(t, 2, theirStored.RefCount)
+
+		assert.Nil(t, globalScriptRdsImpl.UpdateGlobalScript(user.ID, "globalThis.sealed = true"))
+		var storedScript string
+		assert.Nil(t, rdsClient.DB().Get(&storedScript, "SELECT script FROM global_scripts WHERE user_id = ?", string(user.ID)))
+		assert.NotContains(t, storedScript, "globalThis")
+		script, err = globalScriptRdsImpl.GetGlobalScript(user.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "globalThis.sealed = true", script.Script)
+
+		// the full text search leaves the sealed sources out, names are still found
+		matches, err = toolRdsImpl.SearchFullText(user.ID, []string{"plaintext"}, 10)
+		assert.Nil(t, err)
+		assert.Empty(t, matches)
+		matches, err = toolRdsImpl.SearchFullText(user.ID, []string{"mine"}, 10)
+		assert.Nil(t, err)
+		assert.Len(t, matches, 1)
+		assert.Equal(t, mine.Source, matches[0].Source)
+	})
+}
+
+func TestToolRepositoryRdsImpl_SourceEncryptionSecret(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		plainToolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+		noSecretConfig := rdsConfig
+		noSecretConfig.EncryptSourcesAtRest = true
+		noSecretToolRdsImpl := NewToolRepositoryRdsImpl(noSecretConfig, rdsClient, client.NewSystemClock())
+		legacyGlobalScriptRdsImpl := NewGlobalScriptRepositoryRdsImpl(noSecretConfig, rdsClient)
+		secretConfig := noSecretConfig
+		secretConfig.SourceEncryptionSecret = "a source encryption secret of the tests"
+		toolRdsImpl := NewToolRepositoryRdsImpl(secretConfig, rdsClient, client.NewSystemClock())
+		globalScriptRdsImpl := NewGlobalScriptRepositoryRdsImpl(secretConfig, rdsClient)
+		versionRdsImpl := NewToolVersionRepositoryRdsImpl(secretConfig, rdsClient)
+		otherSecretConfig := noSecretConfig
+		otherSecretConfig.SourceEncryptionSecret = "another source encryption secret"
+		otherSecretToolRdsImpl := NewToolRepositoryRdsImpl(otherSecretConfig, rdsClient, client.NewSystemClock())
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		var encryptKey string
+		assert.Nil(t, rdsClient.DB().Get(&encryptKey, "SELECT encrypt_key FROM users WHERE id = ?", string(user.ID)))
+
+		sealedKeys := func() []string {
+			var keys []string
+			assert.Nil(t, rdsClient.DB().Select(&keys,
+				`SELECT source_key FROM tools UNION ALL SELECT source_key FROM tool_versions
+				 UNION ALL SELECT script_key FROM global_scripts`,
+			))
+			return keys
+		}
+
+		// no source is sealed without the secret
+		unsealed := fixtures.NewTestTool().WithID("unsealed").WithName("Unsealed").Build()
+		assert.NotNil(t, noSecretToolRdsImpl.CreateTool(user.ID, unsealed, entity.ToolEventSourceEntity{}))
+
+		// the sources sealed before the secret existed are shared under their sha256, with the data key sealed with
+		// the encrypt key of the owner alone
+		legacy := fixtures.NewTestTool().WithID("legacy").WithName("Legacy").WithSource("function handler() { return 'legacy' }").Build()
+		assert.Nil(t, plainToolRdsImpl.CreateTool(user.ID, legacy, entity.ToolEventSourceEntity{}))
+		dataKey, err := newDataKey()
+		assert.Nil(t, err)
+		sealedSource, err := utils.EncryptString(dataKey, legacy.Source)
+		assert.Nil(t, err)
+		legacyKey, err := utils.EncryptString(encryptKey, dataKey)
+		assert.Nil(t, err)
+		_, err = rdsClient.DB().Exec("UPDATE tool_sources SET source = ?, encrypted = ? WHERE hash = ?", sealedSource, true, legacy.SourceHash)
+		assert.Nil(t, err)
+		for _, table := range []string{"tools", "tool_versions"} {
+			_, err = rdsClient.DB().Exec("UPDATE "+table+" SET source_key = ? WHERE user_id = ?", legacyKey, string(user.ID))
+			assert.Nil(t, err)
+		}
+		assert.Nil(t, legacyGlobalScriptRdsImpl.UpdateGlobalScript(user.ID, "globalThis.legacy = true"))
+		for _, key := range sealedKeys() {
+			assert.NotEmpty(t, key)
+			assert.False(t, strings.HasPrefix(key, sealedKeyV2Prefix))
+		}
+		tools, err := toolRdsImpl.AllTools(user.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, legacy.Source, tools.Tools[0].Source)
+
+		// the ones sealed with the secret are not opened without it, or with another one
+		wrapped := fixtures.NewTestTool().WithID("wrapped").WithName("Wrapped").WithSource("function handler() { return 'wrapped' }").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(user.ID, wrapped, entity.ToolEventSourceEntity{}))
+		var wrappedKey string
+		assert.Nil(t, rdsClient.DB().Get(&wrappedKey, "SELECT source_key FROM tools WHERE unique_id = ?", wrapped.UniqueID))
+		assert.True(t, strings.HasPrefix(wrappedKey, sealedKeyV2Prefix))
+		_, err = noSecretToolRdsImpl.AllTools(user.ID)
+		assert.NotNil(t, err)
+		_, err = otherSecretToolRdsImpl.AllTools(user.ID)
+		assert.NotNil(t, err)
+
+		// encrypting the stored sources needs the secret, and seals the legacy sources again with new data keys
+		_, err = noSecretToolRdsImpl.EncryptStoredSources()
+		assert.NotNil(t, err)
+		moved, err := toolRdsImpl.EncryptStoredSources()
+		assert.Nil(t, err)
+		assert.Equal(t, 2, moved)
+		for _, key := range sealedKeys() {
+			assert.True(t, strings.HasPrefix(key, sealedKeyV2Prefix))
+		}
+		var legacyCopies int
+		assert.Nil(t, rdsClient.DB().Get(&legacyCopies, "SELECT COUNT(*) FROM tool_sources WHERE hash = ?", legacy.SourceHash))
+		assert.Equal(t, 0, legacyCopies)
+		tools, err = toolRdsImpl.AllTools(user.ID)
+		assert.Nil(t, err)
+		assert.Len(t, tools.Tools, 2)
+		for _, tool := range tools.Tools {
+			assert.Contains(t, []string{legacy.Source, wrapped.Source}, tool.Source)
+		}
+		version, found, err := versionRdsImpl.GetVersion(ctx, user.ID, legacy.UniqueID, 1)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, legacy.Source, version.Source)
+		script, err := globalScriptRdsImpl.GetGlobalScript(user.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "globalThis.legacy = true", script.Script)
+	})
+}
@@ -0,0 +1,357 @@
+package repository_impl
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+type ToolScheduleRdsModel struct {
+	ID            string       `db:"id"`
+	UserID        string       `db:"user_id"`
+	ToolUniqueID  string       `db:"tool_unique_id"`
+	Name          string       `db:"name"`
+	CronExpr      string       `db:"cron_expr"`
+	Inputs        string       `db:"inputs"`
+	Enabled       bool         `db:"enabled"`
+	NextRunAt     sql.NullTime `db:"next_run_at"`
+	LastRunAt     sql.NullTime `db:"last_run_at"`
+	CreatedAt     time.Time    `db:"created_at"`
+	UpdatedAt     time.Time    `db:"updated_at"`
+	ResourceClass string       `db:"resource_class"`
+}
+
+type ToolRunRdsModel struct {
+	ID           string    `db:"id"`
+	UserID       string    `db:"user_id"`
+	ToolUniqueID string    `db:"tool_unique_id"`
+	ScheduleID   string    `db:"schedule_id"`
+	Status       string    `db:"status"`
+	Outputs      string    `db:"outputs"`
+	Logs         string    `db:"logs"`
+	ErrorCode    string    `db:"error_code"`
+	ErrorMessage string    `db:"error_message"`
+	StartedAt    time.Time `db:"started_at"`
+	DurationMs   int64     `db:"duration_ms"`
+}
+
+// toolRunLogModel is a console call in the logs JSON of a run
+type toolRunLogModel struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+func NewToolScheduleRepositoryRdsImpl(client repository.IRdsClient) *ToolScheduleRepositoryRdsImpl {
+	return &ToolScheduleRepositoryRdsImpl{client: client}
+}
+
+type ToolScheduleRepositoryRdsImpl struct {
+	client repository.IRdsClient
+}
+
+func (r *ToolScheduleRepositoryRdsImpl) ListSchedules(ctx context.Context, userID entity.UserIDEntity, toolUID string) ([]entity.ToolScheduleEntity, error) {
+	query := "SELECT * FROM tool_schedules WHERE user_id = ? ORDER BY id"
+	args := []any{string(userID)}
+	if toolUID != "" {
+		query = "SELECT * FROM tool_schedules WHERE user_id = ? AND tool_unique_id = ? ORDER BY id"
+		args = append(args, toolUID)
+	}
+
+	var models []ToolScheduleRdsModel
+	if err := r.client.DB().SelectContext(ctx, &models, query, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to list tool schedules from rds")
+	}
+	return toToolScheduleEntities(models)
+}
+
+func (r *ToolScheduleRepositoryRdsImpl) GetSchedule(ctx context.Context, userID entity.UserIDEntity, id string) (entity.ToolScheduleEntity, bool, error) {
+	var model ToolScheduleRdsModel
+	err := r.client.DB().GetContext(ctx, &model, "SELECT * FROM tool_schedules WHERE user_id = ? AND id = ?", string(userID), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ToolScheduleEntity{}, false, nil
+		}
+		return entity.ToolScheduleEntity{}, false, errors.Wrap(err, "failed to get tool schedule from rds")
+	}
+	schedule, err := toToolScheduleEntity(model)
+	if err != nil {
+		return entity.ToolScheduleEntity{}, false, err
+	}
+	return schedule, true, nil
+}
+
+func (r *ToolScheduleRepositoryRdsImpl) CountSchedules(ctx context.Context, userID entity.UserIDEntity) (int, error) {
+	var count int
+	if err := r.client.DB().GetContext(ctx, &count, "SELECT COUNT(*) FROM tool_schedules WHERE user_id = ?", string(userID)); err != nil {
+		return 0, errors.Wrap(err, "failed to count tool schedules in rds")
+	}
+	return count, nil
+}
+
+func (r *ToolScheduleRepositoryRdsImpl) CreateSchedule(ctx context.Context, schedule entity.ToolScheduleEntity) error {
+	inputs, err := json.Marshal(schedule.Inputs)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode tool schedule inputs")
+	}
+
+	_, err = r.client.DB().ExecContext(ctx,
+		`INSERT INTO tool_schedules (id, user_id, tool_unique_id, name, cron_expr, inputs, resource_class, enabled, next_run_at, last_run_at, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		schedule.ID,
+		string(schedule.UserID),
+		schedule.ToolUID,
+		schedule.Name,
+		schedule.Cron,
+		string(inputs),
+		string(schedule.ResourceClass),
+		schedule.Enabled,
+		toNullTime(schedule.NextRunAt),
+		toNullTime(schedule.LastRunAt),
+		schedule.CreatedAt,
+		schedule.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert tool schedule into rds")
+	}
+	return nil
+}
+
+func (r *ToolScheduleRepositoryRdsImpl) UpdateSchedule(ctx context.Context, schedule entity.ToolScheduleEntity) error {
+	inputs, err := json.Marshal(schedule.Inputs)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode tool schedule inputs")
+	}
+
+	result, err := r.client.DB().ExecContext(ctx,
+		`UPDATE tool_schedules SET name = ?, cron_expr = ?, inputs = ?, resource_class = ?, enabled = ?, next_run_at = ?, updated_at = ?
+		 WHERE user_id = ? AND id = ?`,
+		schedule.Name,
+		schedule.Cron,
+		string(inputs),
+		string(schedule.ResourceClass),
+		schedule.Enabled,
+		toNullTime(schedule.NextRunAt),
+		schedule.UpdatedAt,
+		string(schedule.UserID),
+		schedule.ID,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update tool schedule in rds")
+	}
+	return checkToolScheduleAffected(result, schedule.ID)
+}
+
+func (r *ToolScheduleRepositoryRdsImpl) DeleteSchedule(ctx context.Context, userID entity.UserIDEntity, id string) error {
+	tx, err := r.client.DB().BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM tool_schedules WHERE user_id = ? AND id = ?", string(userID), id)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete tool schedule from rds")
+	}
+	if err := checkToolScheduleAffected(result, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tool_runs WHERE user_id = ? AND schedule_id = ?", string(userID), id); err != nil {
+		return errors.Wrap(err, "failed to delete tool runs from rds")
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (r *ToolScheduleRepositoryRdsImpl) DueSchedules(ctx context.Context, now time.Time, limit int) ([]entity.ToolScheduleEntity, error) {
+	var models []ToolScheduleRdsModel
+	err := r.client.DB().SelectContext(ctx, &models,
+		"SELECT * FROM tool_schedules WHERE enabled = ? AND next_run_at <= ? ORDER BY next_run_at, id LIMIT ?",
+		true, now, limit,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select due tool schedules from rds")
+	}
+	return toToolScheduleEntities(models)
+}
+
+func (r *ToolScheduleRepositoryRdsImpl) ClaimSchedule(ctx context.Context, id string, now time.Time, next time.Time) (bool, error) {
+	result, err := r.client.DB().ExecContext(ctx,
+		"UPDATE tool_schedules SET next_run_at = ?, last_run_at = ? WHERE id = ? AND enabled = ? AND next_run_at <= ?",
+		next, now, id, true, now,
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to claim tool schedule in rds")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get affected rows of tool schedule")
+	}
+	return affected > 0, nil
+}
+
+func (r *ToolScheduleRepositoryRdsImpl) AddRun(ctx context.Context, run entity.ToolRunEntity, keep int) error {
+	outputs, err := json.Marshal(run.Outputs)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode tool run outputs")
+	}
+	logs := make([]toolRunLogModel, len(run.Logs))
+	for i, log := range run.Logs {
+		logs[i] = toolRunLogModel{Level: log.Level, Message: log.Message}
+	}
+	logsJSON, err := json.Marshal(logs)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode tool run logs")
+	}
+
+	tx, err := r.client.DB().BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO tool_runs (id, user_id, tool_unique_id, schedule_id, status, outputs, logs, error_code, error_message, started_at, duration_ms)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.ID,
+		string(run.UserID),
+		run.ToolUID,
+		run.ScheduleID,
+		string(run.Status),
+		string(outputs),
+		string(logsJSON),
+		run.ErrorCode,
+		run.ErrorMessage,
+		run.StartedAt,
+		run.Duration.Milliseconds(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert tool run into rds")
+	}
+
+	// run ids grow with time, every run older than the oldest kept one is dropped
+	var oldestKept string
+	err = tx.GetContext(ctx, &oldestKept,
+		"SELECT id FROM tool_runs WHERE schedule_id = ? ORDER BY id DESC LIMIT 1 OFFSET ?",
+		run.ScheduleID, max(keep-1, 0),
+	)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return errors.Wrap(err, "failed to find the oldest tool run to keep")
+	}
+	if err == nil {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM tool_runs WHERE schedule_id = ? AND id < ?", run.ScheduleID, oldestKept); err != nil {
+			return errors.Wrap(err, "failed to delete old tool runs from rds")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+	return nil
+}
+
+func (r *ToolScheduleRepositoryRdsImpl) ListRuns(ctx context.Context, userID entity.UserIDEntity, scheduleID string, limit int) ([]entity.ToolRunEntity, error) {
+	var models []ToolRunRdsModel
+	err := r.client.DB().SelectContext(ctx, &models,
+		"SELECT * FROM tool_runs WHERE user_id = ? AND schedule_id = ? ORDER BY id DESC LIMIT ?",
+		string(userID), scheduleID, limit,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list tool runs from rds")
+	}
+
+	runs := make([]entity.ToolRunEntity, len(models))
+	for i, model := range models {
+		run, err := toToolRunEntity(model)
+		if err != nil {
+			return nil, err
+		}
+		runs[i] = run
+	}
+	return runs, nil
+}
+
+func checkToolScheduleAffected(result sql.Result, id string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get affected rows of tool schedule")
+	}
+	if affected == 0 {
+		return errors.Wrapf(repository.ErrToolScheduleNotFound, "tool schedule %q", id)
+	}
+	return nil
+}
+
+func toToolScheduleEntities(models []ToolScheduleRdsModel) ([]entity.ToolScheduleEntity, error) {
+	schedules := make([]entity.ToolScheduleEntity, len(models))
+	for i, model := range models {
+		schedule, err := toToolScheduleEntity(model)
+		if err != nil {
+			return nil, err
+		}
+		schedules[i] = schedule
+	}
+	return schedules, nil
+}
+
+func toToolScheduleEntity(model ToolScheduleRdsModel) (entity.ToolScheduleEntity, error) {
+	var inputs map[string]any
+	if err := json.Unmarshal([]byte(model.Inputs), &inputs); err != nil {
+		return entity.ToolScheduleEntity{}, errors.Wrapf(err, "failed to decode inputs of tool schedule %s", model.ID)
+	}
+	schedule := entity.ToolScheduleEntity{
+		ID:            model.ID,
+		UserID:        entity.UserIDEntity(model.UserID),
+		ToolUID:       model.ToolUniqueID,
+		Name:          model.Name,
+		Cron:          model.CronExpr,
+		Inputs:        inputs,
+		ResourceClass: entity.ToolResourceClass(model.ResourceClass),
+		Enabled:       model.Enabled,
+		CreatedAt:     model.CreatedAt,
+		UpdatedAt:     model.UpdatedAt,
+	}
+	if model.NextRunAt.Valid {
+		nextRunAt := model.NextRunAt.Time
+		schedule.NextRunAt = &nextRunAt
+	}
+	if model.LastRunAt.Valid {
+		lastRunAt := model.LastRunAt.Time
+		schedule.LastRunAt = &lastRunAt
+	}
+	return schedule, nil
+}
+
+func toToolRunEntity(model ToolRunRdsModel) (entity.ToolRunEntity, error) {
+	var outputs map[string]any
+	if err := json.Unmarshal([]byte(model.Outputs), &outputs); err != nil {
+		return entity.ToolRunEntity{}, errors.Wrapf(err, "failed to decode outputs of tool run %s", model.ID)
+	}
+	var logModels []toolRunLogModel
+	if err := json.Unmarshal([]byte(model.Logs), &logModels); err != nil {
+		return entity.ToolRunEntity{}, errors.Wrapf(err, "failed to decode logs of tool run %s", model.ID)
+	}
+	logs := make([]entity.ToolExecutionLogEntity, len(logModels))
+	for i, log := range logModels {
+		logs[i] = entity.ToolExecutionLogEntity{Level: log.Level, Message: log.Message}
+	}
+	return entity.ToolRunEntity{
+		ID:           model.ID,
+		UserID:       entity.UserIDEntity(model.UserID),
+		ToolUID:      model.ToolUniqueID,
+		ScheduleID:   model.ScheduleID,
+		Status:       entity.ToolRunStatus(model.Status),
+		Outputs:      outputs,
+		Logs:         logs,
+		ErrorCode:    model.ErrorCode,
+		ErrorMessage: model.ErrorMessage,
+		StartedAt:    model.StartedAt,
+		Duration:     time.Duration(model.DurationMs) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,135 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToolScheduleRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewToolScheduleRepositoryRdsImpl(rdsClient)
+		userID := entity.UserIDEntity("tool-schedule-user-1")
+		now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+
+		// the sqlite file is shared between tests, start from empty tables
+		_, err := rdsClient.DB().Exec("DELETE FROM tool_schedules")
+		assert.Nil(t, err)
+		_, err = rdsClient.DB().Exec("DELETE FROM tool_runs")
+		assert.Nil(t, err)
+
+		next := now.Add(time.Minute)
+		schedule := entity.NewToolScheduleEntityWithoutID(userID, "tool-1", "Nightly", "@daily", map[string]any{"text": "hi"}, entity.ToolResourceClassSmall, true)
+		schedule.NextRunAt = &next
+		schedule.CreatedAt = now
+		schedule.UpdatedAt = now
+		assert.Nil(t, repo.CreateSchedule(ctx, schedule))
+
+		other := entity.NewToolScheduleEntityWithoutID(userID, "tool-2", "Paused", "0 * * * *", map[string]any{}, entity.ToolResourceClassLarge, false)
+		other.CreatedAt = now
+		other.UpdatedAt = now
+		assert.Nil(t, repo.CreateSchedule(ctx, other))
+
+		schedules, err := repo.ListSchedules(ctx, userID, "tool-1")
+		assert.Nil(t, err)
+		assert.Len(t, schedules, 1)
+		assert.Equal(t, map[string]any{"text": "hi"}, schedules[0].Inputs)
+		assert.Equal(t, entity.ToolResourceClassSmall, schedules[0].ResourceClass)
+		assert.True(t, schedules[0].NextRunAt.Equal(next))
+		assert.Nil(t, schedules[0].LastRunAt)
+
+		count, err := repo.CountSchedules(ctx, userID)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, count)
+
+		_, found, err := repo.GetSchedule(ctx, "tool-schedule-user-2", schedule.ID)
+		assert.Nil(t, err)
+		assert.False(t, found)
+
+		// only enabled schedules past their next run are due, and only one caller claims a run
+		due, err := repo.DueSchedules(ctx, now, 10)
+		assert.Nil(t, err)
+		assert.Empty(t, due)
+		due, err = repo.DueSchedules(ctx, next, 10)
+		assert.Nil(t, err)
+		assert.Len(t, due, 1)
+		assert.Equal(t, schedule.ID, due[0].ID)
+
+		claimed, err := repo.ClaimSchedule(ctx, schedule.ID, next, next.Add(24*time.Hour))
+		assert.Nil(t, err)
+		assert.True(t, claimed)
+		claimed, err = repo.ClaimSchedule(ctx, schedule.ID, next, next.Add(24*time.Hour))
+		assert.Nil(t, err)
+		assert.False(t, claimed)
+
+		got, found, err := repo.GetSchedule(ctx, userID, schedule.ID)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.True(t, got.LastRunAt.Equal(next))
+		assert.True(t, got.NextRunAt.Equal(next.Add(24*time.Hour)))
+
+		got.Name = "Daily"
+		got.ResourceClass = entity.ToolResourceClassMedium
+		got.Enabled = false
+		got.NextRunAt = nil
+		assert.Nil(t, repo.UpdateSchedule(ctx, got))
+		got, _, err = repo.GetSchedule(ctx, userID, schedule.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "Daily", got.Name)
+		assert.Equal(t, entity.ToolResourceClassMedium, got.ResourceClass)
+		assert.False(t, got.Enabled)
+		assert.Nil(t, got.NextRunAt)
+
+		missing := got
+		missing.UserID = "tool-schedule-user-2"
+		assert.ErrorIs(t, repo.UpdateSchedule(ctx, missing), repository.ErrToolScheduleNotFound)
+
+		// only the newest runs of a schedule are kept
+		var runIDs []string
+		for i := range 3 {
+			run := entity.NewToolRunEntityWithoutID(schedule, now.Add(time.Duration(i)*time.Minute))
+			run.Status = entity.ToolRunStatusSuccess
+			run.Outputs = map[string]any{"n": float64(i)}
+			run.Logs = []entity.ToolExecutionLogEntity{{Level: "log", Message: "ran"}}
+			run.Duration = 12 * time.Millisecond
+			assert.Nil(t, repo.AddRun(ctx, run, 2))
+			runIDs = append(runIDs, run.ID)
+		}
+		failed := entity.NewToolRunEntityWithoutID(other, now)
+		failed.Status = entity.ToolRunStatusFailed
+		failed.ErrorCode = "ToolExecutionTimedOut"
+		failed.ErrorMessage = "timed out"
+		assert.Nil(t, repo.AddRun(ctx, failed, 2))
+
+		runs, err := repo.ListRuns(ctx, userID, schedule.ID, 10)
+		assert.Nil(t, err)
+		assert.Len(t, runs, 2)
+		assert.Equal(t, runIDs[2], runs[0].ID)
+		assert.Equal(t, runIDs[1], runs[1].ID)
+		assert.Equal(t, map[string]any{"n": float64(2)}, runs[0].Outputs)
+		assert.Equal(t, []entity.ToolExecutionLogEntity{{Level: "log", Message: "ran"}}, runs[0].Logs)
+		assert.Equal(t, 12*time.Millisecond, runs[0].Duration)
+
+		runs, err = repo.ListRuns(ctx, userID, other.ID, 10)
+		assert.Nil(t, err)
+		assert.Len(t, runs, 1)
+		assert.Equal(t, entity.ToolRunStatusFailed, runs[0].Status)
+		assert.Equal(t, "ToolExecutionTimedOut", runs[0].ErrorCode)
+		assert.Nil(t, runs[0].Outputs)
+
+		// deleting a schedule drops its runs
+		assert.Nil(t, repo.DeleteSchedule(ctx, userID, schedule.ID))
+		assert.ErrorIs(t, repo.DeleteSchedule(ctx, userID, schedule.ID), repository.ErrToolScheduleNotFound)
+		runs, err = repo.ListRuns(ctx, userID, schedule.ID, 10)
+		assert.Nil(t, err)
+		assert.Empty(t, runs)
+	})
+}
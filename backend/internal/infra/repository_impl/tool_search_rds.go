@@ -0,0 +1,95 @@
+package repository_impl
+
+import (
+	"strings"
+
+	"ya-tool-craft/internal/domain/entity"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// tool_tags indexes the tags extra info of the tools, the extra info stays the value clients read and write.
+// tool_search keeps the name, description and source of every tool for the full text search, each database searches
+// it with its own full text index.
+
+// replaceToolTags rewrites the tag rows of a tool from its extra info, a nil info only removes them.
+func replaceToolTags(exec execer, userID entity.UserIDEntity, toolUID string, info map[string]string) error {
+	if _, err := exec.Exec("DELETE FROM tool_tags WHERE user_id = ? AND tool_unique_id = ?", string(userID), toolUID); err != nil {
+		return pkgerrors.Wrap(err, "fail to delete tool tags")
+	}
+
+	for _, tag := range entity.ParseToolTags(info[entity.ToolExtraInfoKeyTags]) {
+		if _, err := exec.Exec(
+			"INSERT INTO tool_tags (user_id, tool_unique_id, tag) VALUES (?, ?, ?)",
+			string(userID),
+			toolUID,
+			tag,
+		); err != nil {
+			return pkgerrors.Wrapf(err, "fail to insert tool tag %s", tag)
+		}
+	}
+	return nil
+}
+
+// replaceToolSearchDocument rewrites the searchable text of a tool, a nil tool only removes it.
+func replaceToolSearchDocument(exec execer, userID entity.UserIDEntity, toolUID string, tool *entity.ToolEntity) error {
+	if _, err := exec.Exec("DELETE FROM tool_search WHERE user_id = ? AND tool_unique_id = ?", string(userID), toolUID); err != nil {
+		return pkgerrors.Wrap(err, "fail to delete tool search document")
+	}
+	if tool == nil {
+		return nil
+	}
+
+	if _, err := exec.Exec(
+		"INSERT INTO tool_search (user_id, tool_unique_id, name, description, source) VALUES (?, ?, ?, ?, ?)",
+		string(userID),
+		toolUID,
+		tool.Name,
+		tool.Description,
+		tool.Source,
+	); err != nil {
+		return pkgerrors.Wrap(err, "fail to insert tool search document")
+	}
+	return nil
+}
+
+// searchDocument returns the tool as the full text search indexes it, the index leaves the sources out while they are
+// encrypted at rest.
+func (r *ToolRepositoryRdsImpl) searchDocument(tool entity.ToolEntity) *entity.ToolEntity {
+	if r.config.EncryptSourcesAtRest {
+		tool.Source = ""
+	}
+	return &tool
+}
+
+// toolFullTextQuery returns the query of the tools of the user matching every term as a word prefix, the most relevant
+// first. The terms are letters and digits only, they are safe inside the full text query syntax of every database.
+func toolFullTextQuery(dbType string, userID entity.UserIDEntity, terms []string, limit int) (string, []any) {
+	from := `tool_search JOIN tools t ON t.user_id = tool_search.user_id AND t.unique_id = tool_search.tool_unique_id
+		LEFT JOIN tool_sources s ON s.hash = t.source_hash ` + toolRdsOwnerKeyJoin
+
+	switch dbType {
+	case "mysql":
+		match := strings.Join(lo.Map(terms, func(term string, _ int) string { return "+" + term + "*" }), " ")
+		return "SELECT " + toolRdsColumns + " FROM " + from + `
+			WHERE tool_search.user_id = ? AND MATCH(tool_search.name, tool_search.description, tool_search.source) AGAINST (? IN BOOLEAN MODE)
+			ORDER BY MATCH(tool_search.name) AGAINST (? IN BOOLEAN MODE) * 10
+				+ MATCH(tool_search.description) AGAINST (? IN BOOLEAN MODE) * 5
+				+ MATCH(tool_search.name, tool_search.description, tool_search.source) AGAINST (? IN BOOLEAN MODE) DESC, t.created_at
+			LIMIT ?`, []any{string(userID), match, match, match, match, limit}
+	case "postgres":
+		match := strings.Join(lo.Map(terms, func(term string, _ int) string { return term + ":*" }), " & ")
+		return "SELECT " + toolRdsColumns + " FROM " + from + `
+			WHERE tool_search.user_id = ? AND tool_search.document @@ to_tsquery('simple', ?)
+			ORDER BY ts_rank(tool_search.document, to_tsquery('simple', ?)) DESC, t.created_at
+			LIMIT ?`, []any{string(userID), match, match, limit}
+	default:
+		// bm25 is lower for better matches, the weights follow the columns of the table
+		match := strings.Join(lo.Map(terms, func(term string, _ int) string { return `"` + term + `"*` }), " ")
+		return "SELECT " + toolRdsColumns + " FROM " + from + `
+			WHERE tool_search.user_id = ? AND tool_search MATCH ?
+			ORDER BY bm25(tool_search, 0, 0, 10, 5, 1), t.created_at
+			LIMIT ?`, []any{string(userID), match, limit}
+	}
+}
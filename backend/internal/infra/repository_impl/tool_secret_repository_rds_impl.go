@@ -0,0 +1,113 @@
+package repository_impl
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/config"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+type ToolSecretRdsModel struct {
+	UserID         string    `db:"user_id"`
+	ToolUniqueID   string    `db:"tool_unique_id"`
+	Name           string    `db:"name"`
+	EncryptedValue string    `db:"encrypted_value"`
+	Version        int       `db:"version"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+func NewToolSecretRepositoryRdsImpl(config config.Config, client repository.IRdsClient, clock domain_client.IClock) *ToolSecretRepositoryRdsImpl {
+	return &ToolSecretRepositoryRdsImpl{config: config, client: client, clock: clock}
+}
+
+type ToolSecretRepositoryRdsImpl struct {
+	config config.Config
+	client repository.IRdsClient
+	clock  domain_client.IClock
+}
+
+func (r *ToolSecretRepositoryRdsImpl) ListSecrets(ctx context.Context, userID entity.UserIDEntity, toolUID string) ([]entity.ToolSecretEntity, error) {
+	var models []ToolSecretRdsModel
+	err := r.client.DB().SelectContext(ctx, &models,
+		"SELECT * FROM tool_secrets WHERE user_id = ? AND tool_unique_id = ? ORDER BY name",
+		string(userID), toolUID,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list tool secrets from rds")
+	}
+
+	secrets := make([]entity.ToolSecretEntity, 0, len(models))
+	for _, model := range models {
+		secrets = append(secrets, toToolSecretEntity(model))
+	}
+	return secrets, nil
+}
+
+func (r *ToolSecretRepositoryRdsImpl) PutSecret(ctx context.Context, userID entity.UserIDEntity, toolUID string, name string, encryptedValue string) (entity.ToolSecretEntity, error) {
+	tx, err := r.client.DB().BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.ToolSecretEntity{}, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var query string
+	switch r.config.DBType {
+	case "mysql":
+		query = `INSERT INTO tool_secrets (user_id, tool_unique_id, name, encrypted_value, version, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, 1, ?, ?)
+		 ON DUPLICATE KEY UPDATE encrypted_value = VALUES(encrypted_value), version = version + 1, updated_at = VALUES(updated_at)`
+	default:
+		// sqlite and postgres, postgres needs the table name to tell the stored version from the inserted one
+		query = `INSERT INTO tool_secrets (user_id, tool_unique_id, name, encrypted_value, version, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, 1, ?, ?)
+		 ON CONFLICT(user_id, tool_unique_id, name) DO UPDATE SET encrypted_value = excluded.encrypted_value, version = tool_secrets.version + 1, updated_at = excluded.updated_at`
+	}
+	now := r.clock.Now()
+	if _, err := tx.ExecContext(ctx, query, string(userID), toolUID, name, encryptedValue, now, now); err != nil {
+		return entity.ToolSecretEntity{}, errors.Wrap(err, "failed to put tool secret in rds")
+	}
+
+	var model ToolSecretRdsModel
+	if err := tx.GetContext(ctx, &model,
+		"SELECT * FROM tool_secrets WHERE user_id = ? AND tool_unique_id = ? AND name = ?",
+		string(userID), toolUID, name,
+	); err != nil {
+		return entity.ToolSecretEntity{}, errors.Wrap(err, "failed to get tool secret from rds")
+	}
+	if err := tx.Commit(); err != nil {
+		return entity.ToolSecretEntity{}, errors.Wrap(err, "failed to commit transaction")
+	}
+	return toToolSecretEntity(model), nil
+}
+
+func (r *ToolSecretRepositoryRdsImpl) DeleteSecret(ctx context.Context, userID entity.UserIDEntity, toolUID string, name string) (bool, error) {
+	result, err := r.client.DB().ExecContext(ctx,
+		"DELETE FROM tool_secrets WHERE user_id = ? AND tool_unique_id = ? AND name = ?",
+		string(userID), toolUID, name,
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to delete tool secret from rds")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get affected rows")
+	}
+	return affected > 0, nil
+}
+
+func toToolSecretEntity(model ToolSecretRdsModel) entity.ToolSecretEntity {
+	return entity.ToolSecretEntity{
+		UserID:         entity.UserIDEntity(model.UserID),
+		ToolUID:        model.ToolUniqueID,
+		Name:           model.Name,
+		EncryptedValue: model.EncryptedValue,
+		Version:        model.Version,
+		CreatedAt:      model.CreatedAt,
+		UpdatedAt:      model.UpdatedAt,
+	}
+}
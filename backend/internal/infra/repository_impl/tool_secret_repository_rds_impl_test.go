@@ -0,0 +1,78 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToolSecretRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		clock := fixtures.NewFakeClock(time.Unix(100, 0))
+		repo := NewToolSecretRepositoryRdsImpl(config.Config{DBType: "sqlite"}, rdsClient, clock)
+		userID := entity.UserIDEntity("tool-secret-user-1")
+
+		// the sqlite file is shared between tests, start from an empty table
+		_, err := rdsClient.DB().Exec("DELETE FROM tool_secrets")
+		assert.Nil(t, err)
+
+		secrets, err := repo.ListSecrets(ctx, userID, "")
+		assert.Nil(t, err)
+		assert.Empty(t, secrets)
+
+		created, err := repo.PutSecret(ctx, userID, "", "API_KEY", "sealed-1")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, created.Version)
+		assert.Equal(t, "sealed-1", created.EncryptedValue)
+
+		_, err = repo.PutSecret(ctx, userID, "tool-1", "API_KEY", "sealed-tool")
+		assert.Nil(t, err)
+		_, err = repo.PutSecret(ctx, "tool-secret-user-2", "", "API_KEY", "sealed-other")
+		assert.Nil(t, err)
+
+		// putting an existing name rotates the value
+		clock.Advance(time.Minute)
+		rotated, err := repo.PutSecret(ctx, userID, "", "API_KEY", "sealed-2")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, rotated.Version)
+		assert.Equal(t, "sealed-2", rotated.EncryptedValue)
+		assert.True(t, rotated.CreatedAt.Equal(created.CreatedAt))
+		assert.True(t, rotated.UpdatedAt.After(created.UpdatedAt))
+
+		_, err = repo.PutSecret(ctx, userID, "", "DB_PASSWORD", "sealed-3")
+		assert.Nil(t, err)
+
+		secrets, err = repo.ListSecrets(ctx, userID, "")
+		assert.Nil(t, err)
+		assert.Len(t, secrets, 2)
+		assert.Equal(t, "API_KEY", secrets[0].Name)
+		assert.Equal(t, "DB_PASSWORD", secrets[1].Name)
+		assert.Equal(t, "sealed-2", secrets[0].EncryptedValue)
+
+		secrets, err = repo.ListSecrets(ctx, userID, "tool-1")
+		assert.Nil(t, err)
+		assert.Len(t, secrets, 1)
+		assert.Equal(t, "sealed-tool", secrets[0].EncryptedValue)
+
+		deleted, err := repo.DeleteSecret(ctx, userID, "", "API_KEY")
+		assert.Nil(t, err)
+		assert.True(t, deleted)
+		deleted, err = repo.DeleteSecret(ctx, userID, "", "API_KEY")
+		assert.Nil(t, err)
+		assert.False(t, deleted)
+
+		// the tool secret with the same name is kept
+		secrets, err = repo.ListSecrets(ctx, userID, "tool-1")
+		assert.Nil(t, err)
+		assert.Len(t, secrets, 1)
+	})
+}
@@ -0,0 +1,152 @@
+package repository_impl
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"fmt"
+	"time"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type ToolShareRdsModel struct {
+	ID           string    `db:"id"`
+	OwnerID      string    `db:"owner_id"`
+	ToolUniqueID string    `db:"tool_unique_id"`
+	GranteeID    string    `db:"grantee_id"`
+	Permission   string    `db:"permission"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+func NewToolShareRepositoryRdsImpl(client repository.IRdsClient, clock domain_client.IClock) *ToolShareRepositoryRdsImpl {
+	return &ToolShareRepositoryRdsImpl{client: client, clock: clock}
+}
+
+type ToolShareRepositoryRdsImpl struct {
+	client repository.IRdsClient
+	clock  domain_client.IClock
+}
+
+func (r *ToolShareRepositoryRdsImpl) ListSharesOfTool(ctx context.Context, ownerID entity.UserIDEntity, toolUID string) ([]entity.ToolShareEntity, error) {
+	var models []ToolShareRdsModel
+	if err := r.client.DB().SelectContext(ctx, &models,
+		"SELECT * FROM shared_tools WHERE owner_id = ? AND tool_unique_id = ? ORDER BY created_at, id",
+		string(ownerID), toolUID,
+	); err != nil {
+		return nil, errors.Wrap(err, "failed to list tool shares from rds")
+	}
+	return toToolShareEntities(models), nil
+}
+
+func (r *ToolShareRepositoryRdsImpl) ListSharesWithUser(ctx context.Context, granteeID entity.UserIDEntity) ([]entity.ToolShareEntity, error) {
+	var models []ToolShareRdsModel
+	if err := r.client.DB().SelectContext(ctx, &models,
+		"SELECT * FROM shared_tools WHERE grantee_id = ? AND grantee_id != '' ORDER BY created_at DESC, id",
+		string(granteeID),
+	); err != nil {
+		return nil, errors.Wrap(err, "failed to list shares with user from rds")
+	}
+	return toToolShareEntities(models), nil
+}
+
+func (r *ToolShareRepositoryRdsImpl) GetShare(ctx context.Context, shareID string) (entity.ToolShareEntity, bool, error) {
+	var model ToolShareRdsModel
+	err := r.client.DB().GetContext(ctx, &model, "SELECT * FROM shared_tools WHERE id = ?", shareID)
+	if err != nil {
+		if stdErrors.Is(err, sql.ErrNoRows) {
+			return entity.ToolShareEntity{}, false, nil
+		}
+		return entity.ToolShareEntity{}, false, errors.Wrap(err, "failed to get tool share from rds")
+	}
+	return toToolShareEntity(model), true, nil
+}
+
+func (r *ToolShareRepositoryRdsImpl) PutShare(ctx context.Context, share entity.ToolShareEntity) (entity.ToolShareEntity, error) {
+	tx, err := r.client.DB().BeginTxx(ctx, nil)
+	if err != nil {
+		return entity.ToolShareEntity{}, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	now := r.clock.Now()
+	var existing []ToolShareRdsModel
+	if err := tx.SelectContext(ctx, &existing,
+		"SELECT * FROM shared_tools WHERE owner_id = ? AND tool_unique_id = ? AND grantee_id = ?",
+		string(share.OwnerID), share.ToolUniqueID, string(share.GranteeID),
+	); err != nil {
+		return entity.ToolShareEntity{}, errors.Wrap(err, "failed to check tool share in rds")
+	}
+
+	var model ToolShareRdsModel
+	if len(existing) > 0 {
+		model = existing[0]
+		model.Permission = string(share.Permission)
+		model.UpdatedAt = now
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE shared_tools SET permission = ?, updated_at = ? WHERE id = ?",
+			model.Permission, model.UpdatedAt, model.ID,
+		); err != nil {
+			return entity.ToolShareEntity{}, errors.Wrap(err, "failed to update tool share in rds")
+		}
+	} else {
+		model = ToolShareRdsModel{
+			ID:           fmt.Sprintf("share-%s", uuid.New().String()),
+			OwnerID:      string(share.OwnerID),
+			ToolUniqueID: share.ToolUniqueID,
+			GranteeID:    string(share.GranteeID),
+			Permission:   string(share.Permission),
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO shared_tools (id, owner_id, tool_unique_id, grantee_id, permission, created_at, updated_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			model.ID, model.OwnerID, model.ToolUniqueID, model.GranteeID, model.Permission, model.CreatedAt, model.UpdatedAt,
+		); err != nil {
+			return entity.ToolShareEntity{}, errors.Wrap(err, "failed to insert tool share in rds")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.ToolShareEntity{}, errors.Wrap(err, "failed to commit transaction")
+	}
+	return toToolShareEntity(model), nil
+}
+
+func (r *ToolShareRepositoryRdsImpl) DeleteShare(ctx context.Context, ownerID entity.UserIDEntity, shareID string) (bool, error) {
+	deleted, err := r.client.DB().ExecContext(ctx, "DELETE FROM shared_tools WHERE owner_id = ? AND id = ?", string(ownerID), shareID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to delete tool share from rds")
+	}
+	affected, err := deleted.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get deleted tool share count")
+	}
+	return affected > 0, nil
+}
+
+func toToolShareEntities(models []ToolShareRdsModel) []entity.ToolShareEntity {
+	shares := make([]entity.ToolShareEntity, 0, len(models))
+	for _, model := range models {
+		shares = append(shares, toToolShareEntity(model))
+	}
+	return shares
+}
+
+func toToolShareEntity(model ToolShareRdsModel) entity.ToolShareEntity {
+	return entity.ToolShareEntity{
+		ID:           model.ID,
+		OwnerID:      entity.UserIDEntity(model.OwnerID),
+		ToolUniqueID: model.ToolUniqueID,
+		GranteeID:    entity.UserIDEntity(model.GranteeID),
+		Permission:   entity.ToolSharePermission(model.Permission),
+		CreatedAt:    model.CreatedAt,
+		UpdatedAt:    model.UpdatedAt,
+	}
+}
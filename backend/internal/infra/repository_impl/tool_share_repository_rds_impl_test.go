@@ -0,0 +1,85 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/infra/repository_impl/client"
+	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToolShareRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		clock := fixtures.NewFakeClock(time.Unix(100, 0))
+		repo := NewToolShareRepositoryRdsImpl(rdsClient, clock)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+		ownerID := entity.UserIDEntity("share-owner-1")
+		granteeID := entity.UserIDEntity("share-grantee-1")
+
+		// the sqlite file is shared between tests, start from an empty table
+		_, err := rdsClient.DB().Exec("DELETE FROM shared_tools")
+		assert.Nil(t, err)
+
+		tool := fixtures.NewTestTool().Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(ownerID, tool, entity.ToolEventSourceEntity{}))
+
+		shared, err := repo.PutShare(ctx, entity.ToolShareEntity{OwnerID: ownerID, ToolUniqueID: tool.UniqueID, GranteeID: granteeID, Permission: entity.ToolSharePermissionRead})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, shared.ID)
+		assert.Equal(t, time.Unix(100, 0).Unix(), shared.CreatedAt.Unix())
+		link, err := repo.PutShare(ctx, entity.ToolShareEntity{OwnerID: ownerID, ToolUniqueID: tool.UniqueID, Permission: entity.ToolSharePermissionRead})
+		assert.Nil(t, err)
+		assert.True(t, link.IsPublic())
+
+		// sharing again changes the permission and keeps the share id
+		clock.Advance(time.Minute)
+		reshared, err := repo.PutShare(ctx, entity.ToolShareEntity{OwnerID: ownerID, ToolUniqueID: tool.UniqueID, GranteeID: granteeID, Permission: entity.ToolSharePermissionFork})
+		assert.Nil(t, err)
+		assert.Equal(t, shared.ID, reshared.ID)
+		assert.True(t, reshared.CanFork())
+		assert.Equal(t, time.Unix(160, 0).Unix(), reshared.UpdatedAt.Unix())
+
+		shares, err := repo.ListSharesOfTool(ctx, ownerID, tool.UniqueID)
+		assert.Nil(t, err)
+		assert.Len(t, shares, 2)
+
+		// the public link is not listed as shared with anyone
+		withGrantee, err := repo.ListSharesWithUser(ctx, granteeID)
+		assert.Nil(t, err)
+		assert.Len(t, withGrantee, 1)
+		assert.Equal(t, entity.ToolSharePermissionFork, withGrantee[0].Permission)
+		withNobody, err := repo.ListSharesWithUser(ctx, "")
+		assert.Nil(t, err)
+		assert.Empty(t, withNobody)
+
+		got, exists, err := repo.GetShare(ctx, link.ID)
+		assert.Nil(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, link.ID, got.ID)
+
+		// only the owner can delete a share
+		deleted, err := repo.DeleteShare(ctx, granteeID, shared.ID)
+		assert.Nil(t, err)
+		assert.False(t, deleted)
+		deleted, err = repo.DeleteShare(ctx, ownerID, shared.ID)
+		assert.Nil(t, err)
+		assert.True(t, deleted)
+		_, exists, err = repo.GetShare(ctx, shared.ID)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+
+		// deleting the tool deletes its shares
+		assert.Nil(t, toolRdsImpl.DeleteTool(ownerID, tool.UniqueID, entity.ToolEventSourceEntity{}))
+		shares, err = repo.ListSharesOfTool(ctx, ownerID, tool.UniqueID)
+		assert.Nil(t, err)
+		assert.Empty(t, shares)
+	})
+}
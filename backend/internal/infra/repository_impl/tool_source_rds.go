@@ -0,0 +1,294 @@
+package repository_impl
+
+import (
+	"time"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/utils"
+
+	"github.com/jmoiron/sqlx"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// tool sources are content addressed, tool_sources keeps one row per distinct source keyed by its sha256 and counts
+// the tools and tool versions referencing it, a sealed source is keyed by sealedToolSourceHash instead. tools.source
+// is only read for the rows written before, their source_hash is empty.
+const (
+	toolRdsColumns = `t.user_id, t.id, t.unique_id, t.name, t.namespace, t.category, t.is_activate, t.is_archived,
+		t.realtime_execution, t.ui_widgets, COALESCE(s.source, t.source) AS source, t.source_hash, t.description,
+		t.extra_info, t.created_at, t.updated_at,
+		t.source_key, COALESCE(s.encrypted, FALSE) AS source_encrypted, COALESCE(k.encrypt_key, '') AS owner_encrypt_key`
+	// toolRdsOwnerKeyJoin joins the encrypt key of the owner the sealed sources are opened with
+	toolRdsOwnerKeyJoin = `LEFT JOIN users k ON k.id = t.user_id`
+	toolRdsFrom         = `tools t LEFT JOIN tool_sources s ON s.hash = t.source_hash ` + toolRdsOwnerKeyJoin
+)
+
+// toolSourceRef is how a tool or a tool version references its source in tool_sources.
+type toolSourceRef struct {
+	Hash string
+	// Key is the data key of the source sealed with the encrypt key of the owner, empty when the source is plaintext
+	Key string
+	// DataKey is the data key the source is sealed with, empty when the source is plaintext
+	DataKey string
+}
+
+// newToolSourceRef returns the reference of a source the user saves. With ENCRYPT_SOURCES_AT_REST the source is
+// sealed and stored for the user alone, unless the user has no encrypt key to open it with.
+func newToolSourceRef(q sqlx.Queryer, cfg config.Config, userID entity.UserIDEntity, source string) (toolSourceRef, error) {
+	if !cfg.EncryptSourcesAtRest {
+		return toolSourceRef{Hash: entity.ToolSourceHash(source)}, nil
+	}
+	encryptKey, err := ownerEncryptKey(q, userID)
+	if err != nil {
+		return toolSourceRef{}, err
+	}
+	return sealedToolSourceRef(q, cfg.SourceEncryptionSecret, encryptKey, source)
+}
+
+// sealedToolSourceRef returns the reference of a source sealed for the owner of encryptKey. A source the owner has
+// stored already keeps its data key, a new one gets a random one.
+func sealedToolSourceRef(q sqlx.Queryer, secret string, encryptKey string, source string) (toolSourceRef, error) {
+	if encryptKey == "" {
+		return toolSourceRef{Hash: entity.ToolSourceHash(source)}, nil
+	}
+	if secret == "" {
+		return toolSourceRef{}, pkgerrors.New("ENCRYPT_SOURCES_AT_REST needs SOURCE_ENCRYPTION_SECRET to seal tool sources")
+	}
+
+	hash := sealedToolSourceHash(secret, encryptKey, source)
+	var refs []struct {
+		SourceKey       string `db:"source_key"`
+		OwnerEncryptKey string `db:"owner_encrypt_key"`
+	}
+	if err := sqlx.Select(q, &refs,
+		`SELECT t.source_key, COALESCE(k.encrypt_key, '') AS owner_encrypt_key FROM tools t LEFT JOIN users k ON k.id = t.user_id
+		 WHERE t.source_hash = ? AND t.source_key != ''
+		 UNION ALL SELECT v.source_key, COALESCE(k.encrypt_key, '') AS owner_encrypt_key FROM tool_versions v LEFT JOIN users k ON k.id = v.user_id
+		 WHERE v.source_hash = ? AND v.source_key != ''`,
+		hash, hash,
+	); err != nil {
+		return toolSourceRef{}, pkgerrors.Wrap(err, "fail to select data key of stored tool source")
+	}
+
+	var dataKey string
+	var err error
+	if len(refs) > 0 {
+		dataKey, err = openDataKey(secret, refs[0].OwnerEncryptKey, refs[0].SourceKey)
+	} else {
+		dataKey, err = newDataKey()
+	}
+	if err != nil {
+		return toolSourceRef{}, err
+	}
+	key, err := sealDataKey(secret, encryptKey, dataKey)
+	if err != nil {
+		return toolSourceRef{}, err
+	}
+	return toolSourceRef{Hash: hash, Key: key, DataKey: dataKey}, nil
+}
+
+// retainToolSource stores the source under its hash, or adds a reference when it is stored already. A sealed source
+// is checked to open with the data key of the reference, the same source saved concurrently may have stored another.
+func retainToolSource(exec sqlx.Ext, dbType string, ref toolSourceRef, source string, now time.Time) error {
+	stored := source
+	if ref.DataKey != "" {
+		sealed, err := utils.EncryptString(ref.DataKey, source)
+		if err != nil {
+			return pkgerrors.Wrap(err, "fail to seal tool source")
+		}
+		stored = sealed
+	}
+
+	var query string
+	switch dbType {
+	case "mysql":
+		query = `INSERT INTO tool_sources (hash, source, encrypted, ref_count, created_at)
+		 VALUES (?, ?, ?, 1, ?)
+		 ON DUPLICATE KEY UPDATE ref_count = ref_count + 1`
+	default:
+		// sqlite and postgres, postgres needs the table name to tell the stored value from the inserted one
+		query = `INSERT INTO tool_sources (hash, source, encrypted, ref_count, created_at)
+		 VALUES (?, ?, ?, 1, ?)
+		 ON CONFLICT(hash) DO UPDATE SET ref_count = tool_sources.ref_count + 1`
+	}
+	if _, err := exec.Exec(query, ref.Hash, stored, ref.DataKey != "", now); err != nil {
+		return pkgerrors.Wrap(err, "fail to retain tool source")
+	}
+
+	if ref.DataKey == "" {
+		return nil
+	}
+	if err := sqlx.Get(exec, &stored, "SELECT source FROM tool_sources WHERE hash = ?", ref.Hash); err != nil {
+		return pkgerrors.Wrap(err, "fail to get retained tool source")
+	}
+	if _, err := utils.DecryptString(ref.DataKey, stored); err != nil {
+		return pkgerrors.Wrap(err, "tool source is stored with another data key, save it again")
+	}
+	return nil
+}
+
+// openToolSource returns the plaintext of a source read with toolRdsColumns or the version columns.
+func openToolSource(secret string, source string, encrypted bool, sourceKey string, ownerEncryptKey string) (string, error) {
+	if !encrypted {
+		return source, nil
+	}
+	if sourceKey == "" {
+		return "", pkgerrors.New("tool source is encrypted but the tool has no data key")
+	}
+	return openSealedText(secret, ownerEncryptKey, sourceKey, source)
+}
+
+// releaseToolSource drops a reference of the source, the source is deleted with its last reference.
+// An empty hash is a tool written before the sources were deduplicated and is ignored.
+func releaseToolSource(exec execer, hash string) error {
+	if hash == "" {
+		return nil
+	}
+	if _, err := exec.Exec("UPDATE tool_sources SET ref_count = ref_count - 1 WHERE hash = ?", hash); err != nil {
+		return pkgerrors.Wrap(err, "fail to release tool source")
+	}
+	if _, err := exec.Exec("DELETE FROM tool_sources WHERE hash = ? AND ref_count <= 0", hash); err != nil {
+		return pkgerrors.Wrap(err, "fail to delete unreferenced tool source")
+	}
+	return nil
+}
+
+// releaseToolSourcesOfUser drops the references of every tool of the user, it must run before the tools are deleted.
+func releaseToolSourcesOfUser(exec execer, userID string) error {
+	if _, err := exec.Exec(
+		`UPDATE tool_sources SET ref_count = ref_count - (
+			SELECT COUNT(*) FROM tools t WHERE t.user_id = ? AND t.source_hash = tool_sources.hash
+		) WHERE hash IN (SELECT source_hash FROM tools WHERE user_id = ?)`,
+		userID,
+		userID,
+	); err != nil {
+		return pkgerrors.Wrap(err, "fail to release tool sources of user")
+	}
+	if _, err := exec.Exec("DELETE FROM tool_sources WHERE ref_count <= 0"); err != nil {
+		return pkgerrors.Wrap(err, "fail to delete unreferenced tool sources")
+	}
+	return nil
+}
+
+func (r *ToolRepositoryRdsImpl) EncryptStoredSources() (int, error) {
+	db := r.client.DB()
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, pkgerrors.Wrap(err, "fail to begin source encryption transaction")
+	}
+
+	sealed, err := encryptStoredSources(tx, r.config.DBType, r.config.SourceEncryptionSecret, r.clock.Now())
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, pkgerrors.Wrap(err, "fail to commit source encryption transaction")
+	}
+	return sealed, nil
+}
+
+func (r *ToolRepositoryRdsImpl) DropSearchSources() error {
+	return dropSearchSources(r.client.DB())
+}
+
+// encryptStoredSources moves the tools and tool versions of the users with an encrypt key onto sources sealed for
+// their owner alone, the plaintext sources and the ones shared under their sha256 are released. It returns the
+// number of tools and tool versions moved. The data keys sealed without the secret are sealed with it too.
+func encryptStoredSources(tx *sqlx.Tx, dbType string, secret string, now time.Time) (int, error) {
+	if secret == "" {
+		return 0, pkgerrors.New("set SOURCE_ENCRYPTION_SECRET before encrypting the stored sources")
+	}
+
+	var owners []struct {
+		ID         string `db:"id"`
+		EncryptKey string `db:"encrypt_key"`
+	}
+	if err := tx.Select(&owners, "SELECT id, encrypt_key FROM users WHERE encrypt_key != ''"); err != nil {
+		return 0, pkgerrors.Wrap(err, "fail to select encrypt keys of users")
+	}
+	encryptKeys := make(map[string]string, len(owners))
+	for _, owner := range owners {
+		encryptKeys[owner.ID] = owner.EncryptKey
+	}
+	if err := resealLegacyDataKeys(tx, secret, encryptKeys); err != nil {
+		return 0, err
+	}
+
+	// the tools written before the sources were deduplicated have no hash, the migration moved them all
+	var refs []struct {
+		Table           string `db:"ref_table"`
+		UserID          string `db:"user_id"`
+		ToolUniqueID    string `db:"tool_unique_id"`
+		Version         int    `db:"version"`
+		Source          string `db:"source"`
+		SourceHash      string `db:"source_hash"`
+		SourceKey       string `db:"source_key"`
+		SourceEncrypted bool   `db:"source_encrypted"`
+	}
+	if err := tx.Select(&refs,
+		`SELECT 'tools' AS ref_table, t.user_id, t.unique_id AS tool_unique_id, 0 AS version, s.source, t.source_hash,
+			t.source_key, s.encrypted AS source_encrypted
+		 FROM tools t JOIN tool_sources s ON s.hash = t.source_hash JOIN users k ON k.id = t.user_id
+		 WHERE k.encrypt_key != ''
+		 UNION ALL SELECT 'tool_versions' AS ref_table, v.user_id, v.tool_unique_id, v.version, s.source, v.source_hash,
+			v.source_key, s.encrypted AS source_encrypted
+		 FROM tool_versions v JOIN tool_sources s ON s.hash = v.source_hash JOIN users k ON k.id = v.user_id
+		 WHERE k.encrypt_key != ''`,
+	); err != nil {
+		return 0, pkgerrors.Wrap(err, "fail to select tool source references")
+	}
+
+	moved := 0
+	for _, ref := range refs {
+		encryptKey := encryptKeys[ref.UserID]
+		source, err := openToolSource(secret, ref.Source, ref.SourceEncrypted, ref.SourceKey, encryptKey)
+		if err != nil {
+			return 0, pkgerrors.Wrapf(err, "fail to open source of tool %s", ref.ToolUniqueID)
+		}
+		sourceRef, err := sealedToolSourceRef(tx, secret, encryptKey, source)
+		if err != nil {
+			return 0, err
+		}
+		if sourceRef.Hash == ref.SourceHash {
+			continue
+		}
+
+		if err := retainToolSource(tx, dbType, sourceRef, source, now); err != nil {
+			return 0, err
+		}
+		if err := releaseToolSource(tx, ref.SourceHash); err != nil {
+			return 0, err
+		}
+		if ref.Table == "tools" {
+			_, err = tx.Exec(
+				"UPDATE tools SET source_hash = ?, source_key = ? WHERE user_id = ? AND unique_id = ?",
+				sourceRef.Hash, sourceRef.Key, ref.UserID, ref.ToolUniqueID,
+			)
+		} else {
+			_, err = tx.Exec(
+				"UPDATE tool_versions SET source_hash = ?, source_key = ? WHERE user_id = ? AND tool_unique_id = ? AND version = ?",
+				sourceRef.Hash, sourceRef.Key, ref.UserID, ref.ToolUniqueID, ref.Version,
+			)
+		}
+		if err != nil {
+			return 0, pkgerrors.Wrapf(err, "fail to update source reference of %s", ref.Table)
+		}
+		moved++
+	}
+
+	if err := dropSearchSources(tx); err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// dropSearchSources empties the sources kept for the full text search, the index leaves them out while the sources
+// are encrypted at rest.
+func dropSearchSources(exec execer) error {
+	if _, err := exec.Exec("UPDATE tool_search SET source = '' WHERE source != ''"); err != nil {
+		return pkgerrors.Wrap(err, "fail to drop sources from tool search")
+	}
+	return nil
+}
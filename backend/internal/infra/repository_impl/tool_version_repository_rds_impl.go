@@ -0,0 +1,214 @@
+package repository_impl
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"math"
+	"time"
+
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/jmoiron/sqlx"
+	pkgerrors "github.com/pkg/errors"
+)
+
+type ToolVersionRdsModel struct {
+	ToolUniqueID string    `db:"tool_unique_id"`
+	Version      int       `db:"version"`
+	UiWidgets    string    `db:"ui_widgets"`
+	Source       string    `db:"source"`
+	SourceHash   string    `db:"source_hash"`
+	CreatedAt    time.Time `db:"created_at"`
+	// SourceKey is the data key of the source sealed with OwnerEncryptKey, empty when it was stored before
+	SourceKey       string `db:"source_key"`
+	SourceEncrypted bool   `db:"source_encrypted"`
+	OwnerEncryptKey string `db:"owner_encrypt_key"`
+}
+
+func NewToolVersionRepositoryRdsImpl(config config.Config, client repository.IRdsClient) *ToolVersionRepositoryRdsImpl {
+	return &ToolVersionRepositoryRdsImpl{config: config, client: client}
+}
+
+type ToolVersionRepositoryRdsImpl struct {
+	config config.Config
+	client repository.IRdsClient
+}
+
+func (r *ToolVersionRepositoryRdsImpl) ListVersions(ctx context.Context, userID entity.UserIDEntity, toolUID string) ([]entity.ToolVersionEntity, error) {
+	db := r.client.DB()
+
+	// a sealed source is stored under a keyed hash, its sha256 is only known once it is opened
+	var models []ToolVersionRdsModel
+	if err := db.SelectContext(ctx, &models,
+		`SELECT v.tool_unique_id, v.version, CASE WHEN s.encrypted THEN s.source ELSE '' END AS source, v.source_hash,
+			v.created_at, v.source_key, COALESCE(s.encrypted, FALSE) AS source_encrypted, COALESCE(k.encrypt_key, '') AS owner_encrypt_key
+		 FROM tool_versions v LEFT JOIN tool_sources s ON s.hash = v.source_hash LEFT JOIN users k ON k.id = v.user_id
+		 WHERE v.user_id = ? AND v.tool_unique_id = ? ORDER BY v.version DESC`,
+		string(userID),
+		toolUID,
+	); err != nil {
+		return nil, pkgerrors.Wrap(err, "fail to select tool versions from rds")
+	}
+
+	versions := make([]entity.ToolVersionEntity, 0, len(models))
+	for _, model := range models {
+		version, err := r.toOpenedToolVersionEntity(model)
+		if err != nil {
+			return nil, err
+		}
+		// the list leaves the sources out
+		version.Source = ""
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+func (r *ToolVersionRepositoryRdsImpl) GetVersion(ctx context.Context, userID entity.UserIDEntity, toolUID string, version int) (entity.ToolVersionEntity, bool, error) {
+	db := r.client.DB()
+
+	var model ToolVersionRdsModel
+	err := db.GetContext(ctx, &model,
+		`SELECT v.tool_unique_id, v.version, v.ui_widgets, COALESCE(s.source, '') AS source, v.source_hash, v.created_at,
+			v.source_key, COALESCE(s.encrypted, FALSE) AS source_encrypted, COALESCE(k.encrypt_key, '') AS owner_encrypt_key
+		 FROM tool_versions v LEFT JOIN tool_sources s ON s.hash = v.source_hash LEFT JOIN users k ON k.id = v.user_id
+		 WHERE v.user_id = ? AND v.tool_unique_id = ? AND v.version = ?`,
+		string(userID),
+		toolUID,
+		version,
+	)
+	if err != nil {
+		if stdErrors.Is(err, sql.ErrNoRows) {
+			return entity.ToolVersionEntity{}, false, nil
+		}
+		return entity.ToolVersionEntity{}, false, pkgerrors.Wrap(err, "fail to get tool version from rds")
+	}
+	toolVersion, err := r.toOpenedToolVersionEntity(model)
+	if err != nil {
+		return entity.ToolVersionEntity{}, false, err
+	}
+	return toolVersion, true, nil
+}
+
+// toOpenedToolVersionEntity converts a version row, opening its source when it is sealed.
+func (r *ToolVersionRepositoryRdsImpl) toOpenedToolVersionEntity(model ToolVersionRdsModel) (entity.ToolVersionEntity, error) {
+	if !model.SourceEncrypted {
+		return toToolVersionEntity(model), nil
+	}
+	source, err := openToolSource(r.config.SourceEncryptionSecret, model.Source, model.SourceEncrypted, model.SourceKey, model.OwnerEncryptKey)
+	if err != nil {
+		return entity.ToolVersionEntity{}, pkgerrors.Wrapf(err, "fail to open source of tool %s version %d", model.ToolUniqueID, model.Version)
+	}
+	model.Source = source
+	model.SourceHash = entity.ToolSourceHash(source)
+	return toToolVersionEntity(model), nil
+}
+
+// recordToolVersion adds the source and ui widgets of the tool as its next version unless they are the ones of the
+// latest version, then drops the versions past the limit. The source must be stored in tool_sources already.
+func recordToolVersion(tx *sqlx.Tx, dbType string, userID entity.UserIDEntity, tool entity.ToolEntity, sourceRef toolSourceRef, now time.Time, limit int) error {
+	var latest []ToolVersionRdsModel
+	if err := tx.Select(&latest,
+		`SELECT version, source_hash, ui_widgets FROM tool_versions
+		 WHERE user_id = ? AND tool_unique_id = ? ORDER BY version DESC LIMIT 1`,
+		string(userID),
+		tool.UniqueID,
+	); err != nil {
+		return pkgerrors.Wrap(err, "fail to get latest tool version")
+	}
+	next := 1
+	if len(latest) > 0 {
+		if latest[0].SourceHash == sourceRef.Hash && latest[0].UiWidgets == tool.UiWidgets {
+			return nil
+		}
+		next = latest[0].Version + 1
+	}
+
+	if err := retainToolSource(tx, dbType, sourceRef, tool.Source, now); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO tool_versions (user_id, tool_unique_id, version, source_hash, source_key, ui_widgets, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		string(userID),
+		tool.UniqueID,
+		next,
+		sourceRef.Hash,
+		sourceRef.Key,
+		tool.UiWidgets,
+		now,
+	); err != nil {
+		return pkgerrors.Wrap(err, "fail to insert tool version")
+	}
+
+	if limit <= 0 || next <= limit {
+		return nil
+	}
+	return deleteToolVersions(tx, userID, tool.UniqueID, next-limit)
+}
+
+// deleteToolVersions deletes the versions of a tool up to and including the given one and releases their sources,
+// a negative version deletes all of them.
+func deleteToolVersions(tx *sqlx.Tx, userID entity.UserIDEntity, toolUID string, upTo int) error {
+	if upTo < 0 {
+		upTo = math.MaxInt32
+	}
+
+	var hashes []string
+	if err := tx.Select(&hashes,
+		"SELECT source_hash FROM tool_versions WHERE user_id = ? AND tool_unique_id = ? AND version <= ?",
+		string(userID),
+		toolUID,
+		upTo,
+	); err != nil {
+		return pkgerrors.Wrap(err, "fail to select tool versions to delete")
+	}
+	for _, hash := range hashes {
+		if err := releaseToolSource(tx, hash); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(
+		"DELETE FROM tool_versions WHERE user_id = ? AND tool_unique_id = ? AND version <= ?",
+		string(userID),
+		toolUID,
+		upTo,
+	); err != nil {
+		return pkgerrors.Wrap(err, "fail to delete tool versions")
+	}
+	return nil
+}
+
+// releaseToolVersionSourcesOfUser drops the references of every tool version of the user and deletes the versions.
+func releaseToolVersionSourcesOfUser(exec execer, userID string) error {
+	if _, err := exec.Exec(
+		`UPDATE tool_sources SET ref_count = ref_count - (
+			SELECT COUNT(*) FROM tool_versions v WHERE v.user_id = ? AND v.source_hash = tool_sources.hash
+		) WHERE hash IN (SELECT source_hash FROM tool_versions WHERE user_id = ?)`,
+		userID,
+		userID,
+	); err != nil {
+		return pkgerrors.Wrap(err, "fail to release tool version sources of user")
+	}
+	if _, err := exec.Exec("DELETE FROM tool_sources WHERE ref_count <= 0"); err != nil {
+		return pkgerrors.Wrap(err, "fail to delete unreferenced tool sources")
+	}
+	if _, err := exec.Exec("DELETE FROM tool_versions WHERE user_id = ?", userID); err != nil {
+		return pkgerrors.Wrap(err, "fail to delete tool versions of user")
+	}
+	return nil
+}
+
+func toToolVersionEntity(model ToolVersionRdsModel) entity.ToolVersionEntity {
+	return entity.ToolVersionEntity{
+		ToolUniqueID: model.ToolUniqueID,
+		Version:      model.Version,
+		UiWidgets:    model.UiWidgets,
+		Source:       model.Source,
+		SourceHash:   model.SourceHash,
+		CreatedAt:    model.CreatedAt,
+	}
+}
@@ -0,0 +1,73 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/infra/repository_impl/client"
+	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToolVersionRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		cfg := rdsConfig
+		cfg.ToolVersionLimit = 3
+		toolRdsImpl := NewToolRepositoryRdsImpl(cfg, rdsClient, client.NewSystemClock())
+		versionRdsImpl := NewToolVersionRepositoryRdsImpl(rdsConfig, rdsClient)
+		userID := entity.UserIDEntity("tool-version-user-1")
+		versionNumbers := func(toolUID string) []int {
+			versions, err := versionRdsImpl.ListVersions(ctx, userID, toolUID)
+			assert.Nil(t, err)
+			return lo.Map(versions, func(version entity.ToolVersionEntity, _ int) int { return version.Version })
+		}
+
+		// creating a tool records its first version
+		tool := fixtures.NewTestTool().WithSource("v1").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(userID, tool, entity.ToolEventSourceEntity{}))
+		assert.Equal(t, []int{1}, versionNumbers(tool.UniqueID))
+
+		// an update that keeps the source and the ui widgets records nothing
+		tool.Name = "renamed"
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, tool, entity.ToolEventSourceEntity{}))
+		assert.Equal(t, []int{1}, versionNumbers(tool.UniqueID))
+
+		tool.Source = "v2"
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, tool, entity.ToolEventSourceEntity{}))
+		tool.UiWidgets = "[]"
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, tool, entity.ToolEventSourceEntity{}))
+		assert.Equal(t, []int{3, 2, 1}, versionNumbers(tool.UniqueID))
+
+		version, exists, err := versionRdsImpl.GetVersion(ctx, userID, tool.UniqueID, 2)
+		assert.Nil(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "v2", version.Source)
+		assert.Equal(t, entity.ToolSourceHash("v2"), version.SourceHash)
+		assert.Equal(t, `[{"type": "text"}]`, version.UiWidgets)
+		_, exists, err = versionRdsImpl.GetVersion(ctx, userID, tool.UniqueID, 9)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+		_, exists, err = versionRdsImpl.GetVersion(ctx, "tool-version-user-2", tool.UniqueID, 1)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+
+		// past the limit the oldest versions are dropped and their sources released
+		tool.Source = "v4"
+		assert.Nil(t, toolRdsImpl.UpdateTool(userID, tool, entity.ToolEventSourceEntity{}))
+		assert.Equal(t, []int{4, 3, 2}, versionNumbers(tool.UniqueID))
+		var v1Refs int
+		assert.Nil(t, rdsClient.DB().Get(&v1Refs, "SELECT COUNT(*) FROM tool_sources WHERE hash = ?", entity.ToolSourceHash("v1")))
+		assert.Zero(t, v1Refs)
+
+		// deleting the tool deletes its versions
+		assert.Nil(t, toolRdsImpl.DeleteTool(userID, tool.UniqueID, entity.ToolEventSourceEntity{}))
+		assert.Empty(t, versionNumbers(tool.UniqueID))
+	})
+}
@@ -0,0 +1,115 @@
+package repository_impl
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/config"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+type UsageRdsModel struct {
+	UserID    string    `db:"user_id"`
+	Metric    string    `db:"metric"`
+	Period    string    `db:"period"`
+	Amount    int64     `db:"amount"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func NewUsageRepositoryRdsImpl(config config.Config, client repository.IRdsClient, clock domain_client.IClock) *UsageRepositoryRdsImpl {
+	return &UsageRepositoryRdsImpl{config: config, client: client, clock: clock}
+}
+
+type UsageRepositoryRdsImpl struct {
+	config config.Config
+	client repository.IRdsClient
+	clock  domain_client.IClock
+}
+
+func (r *UsageRepositoryRdsImpl) AddUsage(ctx context.Context, userID entity.UserIDEntity, metric entity.UsageMetric, period string, amount int64) (int64, error) {
+	tx, err := r.client.DB().BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var query string
+	switch r.config.DBType {
+	case "mysql":
+		query = `INSERT INTO user_usage (user_id, metric, period, amount, updated_at)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON DUPLICATE KEY UPDATE amount = amount + VALUES(amount), updated_at = VALUES(updated_at)`
+	default:
+		// sqlite and postgres, postgres needs the table name to tell the stored amount from the inserted one
+		query = `INSERT INTO user_usage (user_id, metric, period, amount, updated_at)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(user_id, metric, period) DO UPDATE SET amount = user_usage.amount + excluded.amount, updated_at = excluded.updated_at`
+	}
+	if _, err := tx.ExecContext(ctx, query, string(userID), string(metric), period, amount, r.clock.Now()); err != nil {
+		return 0, errors.Wrap(err, "failed to add usage in rds")
+	}
+
+	var usage int64
+	if err := tx.GetContext(ctx, &usage, "SELECT amount FROM user_usage WHERE user_id = ? AND metric = ? AND period = ?", string(userID), string(metric), period); err != nil {
+		return 0, errors.Wrap(err, "failed to get usage from rds")
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "failed to commit transaction")
+	}
+	return usage, nil
+}
+
+func (r *UsageRepositoryRdsImpl) SetUsage(ctx context.Context, userID entity.UserIDEntity, metric entity.UsageMetric, period string, amount int64) error {
+	var query string
+	switch r.config.DBType {
+	case "mysql":
+		query = `INSERT INTO user_usage (user_id, metric, period, amount, updated_at)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON DUPLICATE KEY UPDATE amount = VALUES(amount), updated_at = VALUES(updated_at)`
+	default:
+		query = `INSERT INTO user_usage (user_id, metric, period, amount, updated_at)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(user_id, metric, period) DO UPDATE SET amount = excluded.amount, updated_at = excluded.updated_at`
+	}
+	if _, err := r.client.DB().ExecContext(ctx, query, string(userID), string(metric), period, amount, r.clock.Now()); err != nil {
+		return errors.Wrap(err, "failed to set usage in rds")
+	}
+	return nil
+}
+
+func (r *UsageRepositoryRdsImpl) UserUsage(ctx context.Context, userID entity.UserIDEntity, period string) ([]entity.UsageEntity, error) {
+	var models []UsageRdsModel
+	err := r.client.DB().SelectContext(ctx, &models,
+		"SELECT * FROM user_usage WHERE user_id = ? AND period = ? ORDER BY metric", string(userID), period)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select user usage from rds")
+	}
+	return toUsageEntities(models), nil
+}
+
+func (r *UsageRepositoryRdsImpl) PeriodUsage(ctx context.Context, period string) ([]entity.UsageEntity, error) {
+	var models []UsageRdsModel
+	err := r.client.DB().SelectContext(ctx, &models,
+		"SELECT * FROM user_usage WHERE period = ? ORDER BY user_id, metric", period)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select period usage from rds")
+	}
+	return toUsageEntities(models), nil
+}
+
+func toUsageEntities(models []UsageRdsModel) []entity.UsageEntity {
+	usages := make([]entity.UsageEntity, len(models))
+	for i, model := range models {
+		usages[i] = entity.UsageEntity{
+			UserID:    entity.UserIDEntity(model.UserID),
+			Metric:    entity.UsageMetric(model.Metric),
+			Period:    model.Period,
+			Amount:    model.Amount,
+			UpdatedAt: model.UpdatedAt,
+		}
+	}
+	return usages
+}
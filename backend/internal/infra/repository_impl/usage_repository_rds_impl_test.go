@@ -0,0 +1,59 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewUsageRepositoryRdsImpl(config.Config{DBType: "sqlite"}, rdsClient, fixtures.NewFakeClock(time.Unix(100, 0)))
+
+		// the sqlite file is shared between tests, start from an empty table
+		_, err := rdsClient.DB().Exec("DELETE FROM user_usage")
+		assert.Nil(t, err)
+
+		usages, err := repo.UserUsage(ctx, "user-1", "2026-01")
+		assert.Nil(t, err)
+		assert.Empty(t, usages)
+
+		total, err := repo.AddUsage(ctx, "user-1", entity.UsageMetricAPICalls, "2026-01", 1)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), total)
+		total, err = repo.AddUsage(ctx, "user-1", entity.UsageMetricAPICalls, "2026-01", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), total)
+		// another period starts from zero
+		total, err = repo.AddUsage(ctx, "user-1", entity.UsageMetricAPICalls, "2026-02", 1)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), total)
+
+		assert.Nil(t, repo.SetUsage(ctx, "user-1", entity.UsageMetricStorageBytes, "2026-01", 4096))
+		assert.Nil(t, repo.SetUsage(ctx, "user-1", entity.UsageMetricStorageBytes, "2026-01", 1024))
+		assert.Nil(t, repo.SetUsage(ctx, "user-2", entity.UsageMetricStorageBytes, "2026-01", 10))
+
+		usages, err = repo.UserUsage(ctx, "user-1", "2026-01")
+		assert.Nil(t, err)
+		assert.Len(t, usages, 2)
+		assert.Equal(t, entity.UsageMetricAPICalls, usages[0].Metric)
+		assert.Equal(t, int64(3), usages[0].Amount)
+		assert.Equal(t, entity.UsageMetricStorageBytes, usages[1].Metric)
+		assert.Equal(t, int64(1024), usages[1].Amount)
+
+		usages, err = repo.PeriodUsage(ctx, "2026-01")
+		assert.Nil(t, err)
+		assert.Len(t, usages, 3)
+		assert.Equal(t, entity.UserIDEntity("user-2"), usages[2].UserID)
+		assert.Equal(t, int64(10), usages[2].Amount)
+	})
+}
@@ -0,0 +1,234 @@
+package repository_impl
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+// userDataExportColumns are the columns of an export without its archive
+const userDataExportColumns = "id, user_id, status, progress, total, error, created_at, updated_at, finished_at"
+
+type UserDataExportRdsModel struct {
+	ID         string       `db:"id"`
+	UserID     string       `db:"user_id"`
+	Status     string       `db:"status"`
+	Progress   int          `db:"progress"`
+	Total      int          `db:"total"`
+	Error      string       `db:"error"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+	FinishedAt sql.NullTime `db:"finished_at"`
+}
+
+type userDataArchiveRdsModel struct {
+	Archive string `db:"archive"`
+	// ArchiveKey is the data key of the archive sealed with OwnerEncryptKey, empty for a plaintext archive
+	ArchiveKey      string `db:"archive_key"`
+	OwnerEncryptKey string `db:"owner_encrypt_key"`
+}
+
+func NewUserDataExportRepositoryRdsImpl(config config.Config, client repository.IRdsClient) *UserDataExportRepositoryRdsImpl {
+	return &UserDataExportRepositoryRdsImpl{config: config, client: client}
+}
+
+type UserDataExportRepositoryRdsImpl struct {
+	config config.Config
+	client repository.IRdsClient
+}
+
+func (r *UserDataExportRepositoryRdsImpl) CreateExport(ctx context.Context, export entity.UserDataExportEntity) error {
+	_, err := r.client.DB().ExecContext(ctx,
+		`INSERT INTO user_data_exports (id, user_id, status, progress, total, error, archive, archive_key, created_at, updated_at, finished_at)
+		 VALUES (?, ?, ?, ?, ?, ?, '', '', ?, ?, ?)`,
+		export.ID,
+		string(export.UserID),
+		string(export.Status),
+		export.Progress,
+		export.Total,
+		export.Error,
+		export.CreatedAt,
+		export.UpdatedAt,
+		toNullTime(export.FinishedAt),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert user data export into rds")
+	}
+	return nil
+}
+
+func (r *UserDataExportRepositoryRdsImpl) GetExport(ctx context.Context, userID entity.UserIDEntity, id string) (entity.UserDataExportEntity, bool, error) {
+	var model UserDataExportRdsModel
+	err := r.client.DB().GetContext(ctx, &model,
+		"SELECT "+userDataExportColumns+" FROM user_data_exports WHERE user_id = ? AND id = ?",
+		string(userID), id,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.UserDataExportEntity{}, false, nil
+		}
+		return entity.UserDataExportEntity{}, false, errors.Wrap(err, "failed to get user data export from rds")
+	}
+	return toUserDataExportEntity(model), true, nil
+}
+
+func (r *UserDataExportRepositoryRdsImpl) ActiveExport(ctx context.Context, userID entity.UserIDEntity) (entity.UserDataExportEntity, bool, error) {
+	var models []UserDataExportRdsModel
+	err := r.client.DB().SelectContext(ctx, &models,
+		"SELECT "+userDataExportColumns+" FROM user_data_exports WHERE user_id = ? AND status IN (?, ?) ORDER BY created_at LIMIT 1",
+		string(userID), string(entity.UserDataExportStatusPending), string(entity.UserDataExportStatusRunning),
+	)
+	if err != nil {
+		return entity.UserDataExportEntity{}, false, errors.Wrap(err, "failed to get active user data export from rds")
+	}
+	if len(models) == 0 {
+		return entity.UserDataExportEntity{}, false, nil
+	}
+	return toUserDataExportEntity(models[0]), true, nil
+}
+
+func (r *UserDataExportRepositoryRdsImpl) PendingExports(ctx context.Context, staleBefore time.Time, limit int) ([]entity.UserDataExportEntity, error) {
+	var models []UserDataExportRdsModel
+	err := r.client.DB().SelectContext(ctx, &models,
+		"SELECT "+userDataExportColumns+` FROM user_data_exports
+		 WHERE status = ? OR (status = ? AND updated_at < ?) ORDER BY created_at, id LIMIT ?`,
+		string(entity.UserDataExportStatusPending), string(entity.UserDataExportStatusRunning), staleBefore, limit,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select pending user data exports from rds")
+	}
+	exports := make([]entity.UserDataExportEntity, 0, len(models))
+	for _, model := range models {
+		exports = append(exports, toUserDataExportEntity(model))
+	}
+	return exports, nil
+}
+
+func (r *UserDataExportRepositoryRdsImpl) ClaimExport(ctx context.Context, id string, staleBefore time.Time, now time.Time) (bool, error) {
+	result, err := r.client.DB().ExecContext(ctx,
+		`UPDATE user_data_exports SET status = ?, progress = 0, updated_at = ?
+		 WHERE id = ? AND (status = ? OR (status = ? AND updated_at < ?))`,
+		string(entity.UserDataExportStatusRunning), now,
+		id, string(entity.UserDataExportStatusPending), string(entity.UserDataExportStatusRunning), staleBefore,
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to claim user data export in rds")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get affected user data export count")
+	}
+	return affected > 0, nil
+}
+
+func (r *UserDataExportRepositoryRdsImpl) UpdateProgress(ctx context.Context, id string, progress int, now time.Time) error {
+	if _, err := r.client.DB().ExecContext(ctx,
+		"UPDATE user_data_exports SET progress = ?, updated_at = ? WHERE id = ?",
+		progress, now, id,
+	); err != nil {
+		return errors.Wrap(err, "failed to update user data export progress in rds")
+	}
+	return nil
+}
+
+func (r *UserDataExportRepositoryRdsImpl) FinishExport(ctx context.Context, id string, archive entity.UserDataArchiveEntity, now time.Time) error {
+	db := r.client.DB()
+	encoded, err := json.Marshal(archive)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode user data archive")
+	}
+
+	stored, archiveKey := string(encoded), ""
+	if r.config.EncryptSourcesAtRest {
+		// the archive holds the sources, it is sealed like them
+		if stored, archiveKey, err = sealOwnedText(db, r.config.SourceEncryptionSecret, archive.Profile.ID, stored); err != nil {
+			return errors.Wrap(err, "failed to seal user data archive")
+		}
+	}
+
+	if _, err := db.ExecContext(ctx,
+		`UPDATE user_data_exports SET status = ?, progress = total, archive = ?, archive_key = ?, updated_at = ?, finished_at = ?
+		 WHERE id = ?`,
+		string(entity.UserDataExportStatusDone), stored, archiveKey, now, now, id,
+	); err != nil {
+		return errors.Wrap(err, "failed to store user data archive in rds")
+	}
+	return nil
+}
+
+func (r *UserDataExportRepositoryRdsImpl) FailExport(ctx context.Context, id string, reason string, now time.Time) error {
+	if _, err := r.client.DB().ExecContext(ctx,
+		"UPDATE user_data_exports SET status = ?, error = ?, updated_at = ?, finished_at = ? WHERE id = ?",
+		string(entity.UserDataExportStatusFailed), reason, now, now, id,
+	); err != nil {
+		return errors.Wrap(err, "failed to mark user data export failed in rds")
+	}
+	return nil
+}
+
+func (r *UserDataExportRepositoryRdsImpl) GetArchive(ctx context.Context, userID entity.UserIDEntity, id string) (entity.UserDataArchiveEntity, bool, error) {
+	var model userDataArchiveRdsModel
+	err := r.client.DB().GetContext(ctx, &model,
+		`SELECT e.archive, e.archive_key, COALESCE(k.encrypt_key, '') AS owner_encrypt_key
+		 FROM user_data_exports e LEFT JOIN users k ON k.id = e.user_id
+		 WHERE e.user_id = ? AND e.id = ? AND e.status = ?`,
+		string(userID), id, string(entity.UserDataExportStatusDone),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.UserDataArchiveEntity{}, false, nil
+		}
+		return entity.UserDataArchiveEntity{}, false, errors.Wrap(err, "failed to get user data archive from rds")
+	}
+
+	encoded := model.Archive
+	if model.ArchiveKey != "" {
+		if encoded, err = openSealedText(r.config.SourceEncryptionSecret, model.OwnerEncryptKey, model.ArchiveKey, model.Archive); err != nil {
+			return entity.UserDataArchiveEntity{}, false, errors.Wrap(err, "failed to open user data archive")
+		}
+	}
+	var archive entity.UserDataArchiveEntity
+	if err := json.Unmarshal([]byte(encoded), &archive); err != nil {
+		return entity.UserDataArchiveEntity{}, false, errors.Wrap(err, "failed to decode user data archive")
+	}
+	return archive, true, nil
+}
+
+func (r *UserDataExportRepositoryRdsImpl) DeleteFinishedBefore(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.client.DB().ExecContext(ctx,
+		"DELETE FROM user_data_exports WHERE status IN (?, ?) AND finished_at < ?",
+		string(entity.UserDataExportStatusDone), string(entity.UserDataExportStatusFailed), before,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete user data exports from rds")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count deleted user data exports")
+	}
+	return int(deleted), nil
+}
+
+func toUserDataExportEntity(model UserDataExportRdsModel) entity.UserDataExportEntity {
+	export := entity.UserDataExportEntity{
+		ID:        model.ID,
+		UserID:    entity.UserIDEntity(model.UserID),
+		Status:    entity.UserDataExportStatus(model.Status),
+		Progress:  model.Progress,
+		Total:     model.Total,
+		Error:     model.Error,
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+	if model.FinishedAt.Valid {
+		finishedAt := model.FinishedAt.Time
+		export.FinishedAt = &finishedAt
+	}
+	return export
+}
@@ -0,0 +1,154 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserDataExportRepositoryRdsImpl_Lifecycle(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		repo := NewUserDataExportRepositoryRdsImpl(rdsConfig, rdsClient)
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		export := entity.NewUserDataExportEntityWithoutID(user.ID, 6)
+		export.CreatedAt = now
+		export.UpdatedAt = now
+		assert.Nil(t, repo.CreateExport(ctx, export))
+
+		active, found, err := repo.ActiveExport(ctx, user.ID)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, export.ID, active.ID)
+		_, found, err = repo.GetExport(ctx, "other-user", export.ID)
+		assert.Nil(t, err)
+		assert.False(t, found)
+
+		// only one instance claims a waiting export
+		staleBefore := now.Add(-10 * time.Minute)
+		pending, err := repo.PendingExports(ctx, staleBefore, 10)
+		assert.Nil(t, err)
+		assert.Len(t, pending, 1)
+		claimed, err := repo.ClaimExport(ctx, export.ID, staleBefore, now.Add(time.Second))
+		assert.Nil(t, err)
+		assert.True(t, claimed)
+		claimed, err = repo.ClaimExport(ctx, export.ID, staleBefore, now.Add(time.Second))
+		assert.Nil(t, err)
+		assert.False(t, claimed)
+		pending, err = repo.PendingExports(ctx, staleBefore, 10)
+		assert.Nil(t, err)
+		assert.Empty(t, pending)
+
+		// a running export without progress for long is taken over
+		pending, err = repo.PendingExports(ctx, now.Add(time.Hour), 10)
+		assert.Nil(t, err)
+		assert.Len(t, pending, 1)
+
+		assert.Nil(t, repo.UpdateProgress(ctx, export.ID, 3, now.Add(2*time.Second)))
+		got, found, err := repo.GetExport(ctx, user.ID, export.ID)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, entity.UserDataExportStatusRunning, got.Status)
+		assert.Equal(t, 3, got.Progress)
+		assert.Nil(t, got.FinishedAt)
+
+		// the archive is only there once the export is done
+		_, found, err = repo.GetArchive(ctx, user.ID, export.ID)
+		assert.Nil(t, err)
+		assert.False(t, found)
+
+		script := "globalThis.exported = true"
+		archive := entity.UserDataArchiveEntity{
+			ExportedAt:   now,
+			Profile:      entity.UserDataProfileEntity{ID: user.ID, Name: user.Name, Roles: []string{"user"}},
+			Tools:        []entity.ToolEntity{fixtures.NewTestTool().WithSource("function handler() {}").Build()},
+			GlobalScript: &script,
+		}
+		assert.Nil(t, repo.FinishExport(ctx, export.ID, archive, now.Add(time.Minute)))
+		got, _, err = repo.GetExport(ctx, user.ID, export.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, entity.UserDataExportStatusDone, got.Status)
+		assert.Equal(t, 6, got.Progress)
+		assert.True(t, got.FinishedAt.Equal(now.Add(time.Minute)))
+		_, found, err = repo.ActiveExport(ctx, user.ID)
+		assert.Nil(t, err)
+		assert.False(t, found)
+
+		stored, found, err := repo.GetArchive(ctx, user.ID, export.ID)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, user.Name, stored.Profile.Name)
+		assert.Equal(t, "function handler() {}", stored.Tools[0].Source)
+		assert.Equal(t, script, *stored.GlobalScript)
+
+		// a failed export keeps its reason
+		failed := entity.NewUserDataExportEntityWithoutID(user.ID, 6)
+		failed.CreatedAt = now
+		failed.UpdatedAt = now
+		assert.Nil(t, repo.CreateExport(ctx, failed))
+		assert.Nil(t, repo.FailExport(ctx, failed.ID, "tools unavailable", now.Add(2*time.Minute)))
+		got, _, err = repo.GetExport(ctx, user.ID, failed.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, entity.UserDataExportStatusFailed, got.Status)
+		assert.Equal(t, "tools unavailable", got.Error)
+
+		deleted, err := repo.DeleteFinishedBefore(ctx, now.Add(90*time.Second))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, deleted)
+		_, found, err = repo.GetExport(ctx, user.ID, export.ID)
+		assert.Nil(t, err)
+		assert.False(t, found)
+		_, found, err = repo.GetExport(ctx, user.ID, failed.ID)
+		assert.Nil(t, err)
+		assert.True(t, found)
+	})
+}
+
+func TestUserDataExportRepositoryRdsImpl_EncryptedArchive(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		cfg := rdsConfig
+		cfg.EncryptSourcesAtRest = true
+		cfg.SourceEncryptionSecret = "a source encryption secret of the tests"
+		userRdsImpl := NewUserRepositoryRdsImpl(cfg, rdsClient)
+		repo := NewUserDataExportRepositoryRdsImpl(cfg, rdsClient)
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+
+		now := time.Now().UTC()
+		export := entity.NewUserDataExportEntityWithoutID(user.ID, 6)
+		export.CreatedAt = now
+		export.UpdatedAt = now
+		assert.Nil(t, repo.CreateExport(ctx, export))
+		archive := entity.UserDataArchiveEntity{
+			Profile: entity.UserDataProfileEntity{ID: user.ID, Name: user.Name},
+			Tools:   []entity.ToolEntity{fixtures.NewTestTool().WithSource("function handler() { return 'archived plaintext' }").Build()},
+		}
+		assert.Nil(t, repo.FinishExport(ctx, export.ID, archive, now))
+
+		// the archive holds the sources, it is sealed like them
+		var storedArchive string
+		assert.Nil(t, rdsClient.DB().Get(&storedArchive, "SELECT archive FROM user_data_exports WHERE id = ?", export.ID))
+		assert.NotContains(t, storedArchive, "archived plaintext")
+
+		stored, found, err := repo.GetArchive(ctx, user.ID, export.ID)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, archive.Tools[0].Source, stored.Tools[0].Source)
+	})
+}
@@ -0,0 +1,124 @@
+package repository_impl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	domain_client "ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type UserDeviceRdsModel struct {
+	ID         string    `db:"id"`
+	UserID     string    `db:"user_id"`
+	Name       string    `db:"name"`
+	SyncCursor int64     `db:"sync_cursor"`
+	CreatedAt  time.Time `db:"created_at"`
+	LastSeenAt time.Time `db:"last_seen_at"`
+}
+
+func NewUserDeviceRepositoryRdsImpl(client repository.IRdsClient, clock domain_client.IClock) *UserDeviceRepositoryRdsImpl {
+	return &UserDeviceRepositoryRdsImpl{client: client, clock: clock}
+}
+
+type UserDeviceRepositoryRdsImpl struct {
+	client repository.IRdsClient
+	clock  domain_client.IClock
+}
+
+func (r *UserDeviceRepositoryRdsImpl) Create(ctx context.Context, userID entity.UserIDEntity, name string) (entity.UserDeviceEntity, error) {
+	db := r.client.DB()
+	now := r.clock.Now()
+
+	device := entity.UserDeviceEntity{
+		ID:         fmt.Sprintf("d-%s", uuid.New().String()),
+		UserID:     userID,
+		Name:       name,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO user_devices (id, user_id, name, sync_cursor, created_at, last_seen_at) VALUES (?, ?, ?, ?, ?, ?)",
+		device.ID, string(userID), name, 0, now, now,
+	)
+	if err != nil {
+		return entity.UserDeviceEntity{}, errors.Wrap(err, "failed to insert user device into rds")
+	}
+	return device, nil
+}
+
+func (r *UserDeviceRepositoryRdsImpl) Get(ctx context.Context, userID entity.UserIDEntity, deviceID string) (entity.UserDeviceEntity, bool, error) {
+	db := r.client.DB()
+
+	var model UserDeviceRdsModel
+	err := db.GetContext(ctx, &model, "SELECT * FROM user_devices WHERE user_id = ? AND id = ?", string(userID), deviceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.UserDeviceEntity{}, false, nil
+		}
+		return entity.UserDeviceEntity{}, false, errors.Wrap(err, "failed to get user device from rds")
+	}
+	return toUserDeviceEntity(model), true, nil
+}
+
+func (r *UserDeviceRepositoryRdsImpl) AllDevices(ctx context.Context, userID entity.UserIDEntity) ([]entity.UserDeviceEntity, error) {
+	db := r.client.DB()
+
+	var models []UserDeviceRdsModel
+	if err := db.SelectContext(ctx, &models, "SELECT * FROM user_devices WHERE user_id = ? ORDER BY last_seen_at DESC, id", string(userID)); err != nil {
+		return nil, errors.Wrap(err, "failed to select user devices from rds")
+	}
+
+	devices := make([]entity.UserDeviceEntity, len(models))
+	for i, model := range models {
+		devices[i] = toUserDeviceEntity(model)
+	}
+	return devices, nil
+}
+
+func (r *UserDeviceRepositoryRdsImpl) Touch(ctx context.Context, userID entity.UserIDEntity, deviceID string) error {
+	db := r.client.DB()
+
+	if _, err := db.ExecContext(ctx, "UPDATE user_devices SET last_seen_at = ? WHERE user_id = ? AND id = ?", r.clock.Now(), string(userID), deviceID); err != nil {
+		return errors.Wrap(err, "failed to update user device last seen time in rds")
+	}
+	return nil
+}
+
+func (r *UserDeviceRepositoryRdsImpl) UpdateSyncCursor(ctx context.Context, userID entity.UserIDEntity, deviceID string, cursor int64) error {
+	db := r.client.DB()
+
+	_, err := db.ExecContext(ctx,
+		"UPDATE user_devices SET sync_cursor = ?, last_seen_at = ? WHERE user_id = ? AND id = ?",
+		cursor, r.clock.Now(), string(userID), deviceID,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update user device sync cursor in rds")
+	}
+	return nil
+}
+
+func (r *UserDeviceRepositoryRdsImpl) Delete(ctx context.Context, userID entity.UserIDEntity, deviceID string) error {
+	db := r.client.DB()
+
+	if _, err := db.ExecContext(ctx, "DELETE FROM user_devices WHERE user_id = ? AND id = ?", string(userID), deviceID); err != nil {
+		return errors.Wrap(err, "failed to delete user device from rds")
+	}
+	return nil
+}
+
+func toUserDeviceEntity(model UserDeviceRdsModel) entity.UserDeviceEntity {
+	return entity.UserDeviceEntity{
+		ID:         model.ID,
+		UserID:     entity.UserIDEntity(model.UserID),
+		Name:       model.Name,
+		SyncCursor: model.SyncCursor,
+		CreatedAt:  model.CreatedAt,
+		LastSeenAt: model.LastSeenAt,
+	}
+}
@@ -0,0 +1,63 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserDeviceRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		clock := fixtures.NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		repo := NewUserDeviceRepositoryRdsImpl(rdsClient, clock)
+		userID := entity.UserIDEntity("u-device-test")
+
+		laptop, err := repo.Create(ctx, userID, "laptop")
+		assert.Nil(t, err)
+		assert.NotEmpty(t, laptop.ID)
+		assert.Equal(t, int64(0), laptop.SyncCursor)
+
+		clock.Advance(time.Minute)
+		phone, err := repo.Create(ctx, userID, "phone")
+		assert.Nil(t, err)
+
+		// another user can not see or change the device
+		_, exists, err := repo.Get(ctx, entity.UserIDEntity("u-other"), laptop.ID)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+
+		clock.Advance(time.Minute)
+		assert.Nil(t, repo.UpdateSyncCursor(ctx, userID, laptop.ID, 7))
+		device, exists, err := repo.Get(ctx, userID, laptop.ID)
+		assert.Nil(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, int64(7), device.SyncCursor)
+		assert.True(t, device.LastSeenAt.Equal(clock.Now()))
+
+		// last seen first
+		devices, err := repo.AllDevices(ctx, userID)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{laptop.ID, phone.ID}, []string{devices[0].ID, devices[1].ID})
+
+		clock.Advance(time.Minute)
+		assert.Nil(t, repo.Touch(ctx, userID, phone.ID))
+		devices, err = repo.AllDevices(ctx, userID)
+		assert.Nil(t, err)
+		assert.Equal(t, phone.ID, devices[0].ID)
+
+		assert.Nil(t, repo.Delete(ctx, userID, laptop.ID))
+		_, exists, err = repo.Get(ctx, userID, laptop.ID)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+		assert.Nil(t, repo.Delete(ctx, userID, laptop.ID))
+	})
+}
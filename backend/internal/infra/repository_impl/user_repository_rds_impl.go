@@ -5,12 +5,14 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/domain/entity"
 	"ya-tool-craft/internal/domain/repository"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
 	"golang.org/x/crypto/bcrypt"
@@ -25,8 +27,15 @@ type UserRdsModel struct {
 	Roles        string         `db:"roles"`       // stored as JSON string
 	EncryptKey   string         `db:"encrypt_key"` // encryption key for user data
 	RecoveryCode sql.NullString `db:"recovery_code"`
-	CreatedAt    time.Time      `db:"created_at"`
-	UpdatedAt    time.Time      `db:"updated_at"`
+	LastLoginAt  sql.NullTime   `db:"last_login_at"`
+	LoginCount   int            `db:"login_count"`
+	// Preferred2FAMethod is read and written by the 2FA repository
+	Preferred2FAMethod     sql.NullString `db:"preferred_2fa_method"`
+	PasswordChangeRequired bool           `db:"password_change_required"`
+	Disabled               bool           `db:"disabled"`
+	DeletionScheduledAt    sql.NullTime   `db:"deletion_scheduled_at"`
+	CreatedAt              time.Time      `db:"created_at"`
+	UpdatedAt              time.Time      `db:"updated_at"`
 }
 
 // UserSSORdsModel represents the user_sso table structure in RDS
@@ -36,8 +45,10 @@ type UserSSORdsModel struct {
 	ProviderUserID   string         `db:"provider_user_id"`
 	ProviderUsername sql.NullString `db:"provider_username"`
 	ProviderEmail    sql.NullString `db:"provider_email"`
-	CreatedAt        time.Time      `db:"created_at"`
-	UpdatedAt        time.Time      `db:"updated_at"`
+	// ProviderAccessToken is only read by GetUserSSOAccessToken, it never leaves the repository with the binding
+	ProviderAccessToken sql.NullString `db:"provider_access_token"`
+	CreatedAt           time.Time      `db:"created_at"`
+	UpdatedAt           time.Time      `db:"updated_at"`
 }
 
 func NewUserRepositoryRdsImpl(config config.Config, client repository.IRdsClient) *UserRepositoryRdsImpl {
@@ -102,6 +113,38 @@ func (r *UserRepositoryRdsImpl) GetByID(ctx context.Context, id entity.UserIDEnt
 	return user, true, nil
 }
 
+// AllUserIDs lists the ids of every user ordered by creation
+func (r *UserRepositoryRdsImpl) AllUserIDs(ctx context.Context) ([]entity.UserIDEntity, error) {
+	db := r.client.DB()
+
+	var ids []string
+	if err := db.Select(&ids, "SELECT id FROM users ORDER BY created_at, id"); err != nil {
+		return nil, errors.Wrap(err, "fail to select user ids from rds")
+	}
+
+	userIDs := make([]entity.UserIDEntity, 0, len(ids))
+	for _, id := range ids {
+		userIDs = append(userIDs, entity.UserIDEntity(id))
+	}
+	return userIDs, nil
+}
+
+// HasUserWithRole reports whether at least one user has the role, roles are stored as a json array of names
+func (r *UserRepositoryRdsImpl) HasUserWithRole(ctx context.Context, role entity.UserRoleEntity) (bool, error) {
+	db := r.client.DB()
+
+	roleJSON, err := json.Marshal(role.RoleName)
+	if err != nil {
+		return false, errors.Wrap(err, "fail to convert user role to json string")
+	}
+
+	var count int
+	if err := db.Get(&count, "SELECT COUNT(*) FROM users WHERE roles LIKE ?", "%"+string(roleJSON)+"%"); err != nil {
+		return false, errors.Wrap(err, "fail to count users with role from rds")
+	}
+	return count > 0, nil
+}
+
 // GetByEmail retrieves a user by email
 func (r *UserRepositoryRdsImpl) GetByEmail(ctx context.Context, email string) (entity.UserEntity, bool, error) {
 	db := r.client.DB()
@@ -193,7 +236,7 @@ func (r *UserRepositoryRdsImpl) Delete(ctx context.Context, id entity.UserIDEnti
 	return nil
 }
 
-// UpdatePassword updates user's password
+// UpdatePassword updates user's password, a new password satisfies a required password change
 func (r *UserRepositoryRdsImpl) UpdatePassword(ctx context.Context, id entity.UserIDEntity, newPassword string) error {
 	db := r.client.DB()
 	now := time.Now()
@@ -205,8 +248,8 @@ func (r *UserRepositoryRdsImpl) UpdatePassword(ctx context.Context, id entity.Us
 	}
 
 	_, err = db.Exec(
-		"UPDATE users SET password_hash = ?, updated_at = ? WHERE id = ?",
-		string(hashedPassword), now, string(id),
+		"UPDATE users SET password_hash = ?, password_change_required = ?, updated_at = ? WHERE id = ?",
+		string(hashedPassword), false, now, string(id),
 	)
 	if err != nil {
 		return errors.Wrap(err, "fail to update password in rds")
@@ -373,14 +416,24 @@ func (r *UserRepositoryRdsImpl) toEntity(model *UserRdsModel) (entity.UserEntity
 		passwordHash = &model.PasswordHash.String
 	}
 
-	return entity.NewUserEntity(
+	user := entity.NewUserEntity(
 		entity.UserIDEntity(model.ID),
 		model.Username,
 		email,
 		passwordHash,
 		roles,
 		model.EncryptKey,
-	), nil
+	)
+	if model.LastLoginAt.Valid {
+		user.LastLoginAt = &model.LastLoginAt.Time
+	}
+	user.LoginCount = model.LoginCount
+	user.PasswordChangeRequired = model.PasswordChangeRequired
+	user.Disabled = model.Disabled
+	if model.DeletionScheduledAt.Valid {
+		user.DeletionScheduledAt = &model.DeletionScheduledAt.Time
+	}
+	return user, nil
 }
 
 func (r *UserRepositoryRdsImpl) toUserSSOEntity(model *UserSSORdsModel) (entity.UserSSOEntity, error) {
@@ -433,6 +486,47 @@ func (r *UserRepositoryRdsImpl) AddUserSSOBinding(ctx context.Context, userID en
 	return nil
 }
 
+// SetUserSSOAccessToken keeps the provider api token on the user sso binding
+func (r *UserRepositoryRdsImpl) SetUserSSOAccessToken(ctx context.Context, userID entity.UserIDEntity, provider string, accessToken string) error {
+	db := r.client.DB()
+
+	result, err := db.Exec(
+		"UPDATE user_sso SET provider_access_token = ?, updated_at = ? WHERE user_id = ? AND provider = ?",
+		accessToken, time.Now(), string(userID), provider,
+	)
+	if err != nil {
+		return errors.Wrap(err, "fail to set user sso access token in rds")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "fail to get affected rows of user sso access token update")
+	}
+	if affected == 0 {
+		return repository.ErrUserSSOBindingNotFound
+	}
+
+	return nil
+}
+
+// GetUserSSOAccessToken returns the provider api token of the user sso binding
+func (r *UserRepositoryRdsImpl) GetUserSSOAccessToken(ctx context.Context, userID entity.UserIDEntity, provider string) (string, bool, error) {
+	db := r.client.DB()
+	var token sql.NullString
+
+	err := db.Get(&token, "SELECT provider_access_token FROM user_sso WHERE user_id = ? AND provider = ?", string(userID), provider)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, nil
+		}
+		return "", false, errors.Wrap(err, "fail to get user sso access token from rds")
+	}
+	if !token.Valid || token.String == "" {
+		return "", false, nil
+	}
+
+	return token.String, true, nil
+}
+
 // DeleteUserSSOBinding deletes a user sso binding by provider
 func (r *UserRepositoryRdsImpl) DeleteUserSSOBinding(ctx context.Context, userID entity.UserIDEntity, provider string) error {
 	db := r.client.DB()
@@ -454,40 +548,529 @@ func (r *UserRepositoryRdsImpl) DeleteUserWithAllData(ctx context.Context, id en
 	}
 
 	userIDStr := string(id)
+	if err := deleteUserData(tx, userIDStr); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Delete user record
+	if _, err := tx.Exec("DELETE FROM users WHERE id = ?", userIDStr); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "fail to delete user")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "fail to commit delete user transaction")
+	}
+
+	return nil
+}
+
+// DeleteUserIfDeletionDue deletes a user and all related data like DeleteUserWithAllData, but only when the deletion
+// of the account is scheduled at or before the time. The schedule is checked in the same transaction, a deletion
+// cancelled meanwhile keeps the user. Returns false when the user was kept.
+func (r *UserRepositoryRdsImpl) DeleteUserIfDeletionDue(ctx context.Context, id entity.UserIDEntity, before time.Time) (bool, error) {
+	db := r.client.DB()
+	tx, err := db.Beginx()
+	if err != nil {
+		return false, errors.Wrap(err, "fail to begin delete user transaction")
+	}
+
+	userIDStr := string(id)
+	// the user record goes first, it locks the row against a concurrent cancellation
+	result, err := tx.Exec("DELETE FROM users WHERE id = ? AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", userIDStr, before)
+	if err != nil {
+		tx.Rollback()
+		return false, errors.Wrap(err, "fail to delete user")
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return false, errors.Wrap(err, "fail to get deleted user count")
+	}
+	if deleted == 0 {
+		tx.Rollback()
+		return false, nil
+	}
 
+	if err := deleteUserData(tx, userIDStr); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, errors.Wrap(err, "fail to commit delete user transaction")
+	}
+
+	return true, nil
+}
+
+// deleteUserData deletes everything belonging to the user but the user record itself
+func deleteUserData(tx *sqlx.Tx, userIDStr string) error {
 	// Delete user SSO bindings
 	if _, err := tx.Exec("DELETE FROM user_sso WHERE user_id = ?", userIDStr); err != nil {
-		tx.Rollback()
 		return errors.Wrap(err, "fail to delete user sso bindings")
 	}
 
-	// Delete user tools
+	// Delete user passkeys and 2fa methods
+	if _, err := tx.Exec("DELETE FROM user_passkeys WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user passkeys")
+	}
+	if _, err := tx.Exec("DELETE FROM user_2fa WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user 2fa")
+	}
+
+	// Delete user tools and their versions, the sources only they use go with them
+	if err := releaseToolSourcesOfUser(tx, userIDStr); err != nil {
+		return err
+	}
+	if err := releaseToolVersionSourcesOfUser(tx, userIDStr); err != nil {
+		return err
+	}
 	if _, err := tx.Exec("DELETE FROM tools WHERE user_id = ?", userIDStr); err != nil {
-		tx.Rollback()
 		return errors.Wrap(err, "fail to delete user tools")
 	}
+	if _, err := tx.Exec("DELETE FROM tool_tags WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user tool tags")
+	}
+	if _, err := tx.Exec("DELETE FROM tool_search WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user tool search documents")
+	}
+	if _, err := tx.Exec("DELETE FROM tool_extra_info WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user tool extra info")
+	}
+
+	// Delete user tool change log and devices, they only describe the deleted tools
+	if _, err := tx.Exec("DELETE FROM tool_changes WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user tool changes")
+	}
+	if _, err := tx.Exec("DELETE FROM user_devices WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user devices")
+	}
+	if _, err := tx.Exec("DELETE FROM tool_events WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user tool events")
+	}
 
 	// Delete user tools last update timestamp
 	if _, err := tx.Exec("DELETE FROM tools_last_update_at WHERE user_id = ?", userIDStr); err != nil {
-		tx.Rollback()
 		return errors.Wrap(err, "fail to delete user tools last update timestamp")
 	}
 
+	// Delete user tool secrets
+	if _, err := tx.Exec("DELETE FROM tool_secrets WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user tool secrets")
+	}
+
+	// Delete user tool schedules and their runs
+	if _, err := tx.Exec("DELETE FROM tool_schedules WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user tool schedules")
+	}
+	if _, err := tx.Exec("DELETE FROM tool_runs WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user tool runs")
+	}
+
+	// Delete user data exports with their archives
+	if _, err := tx.Exec("DELETE FROM user_data_exports WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user data exports")
+	}
+
+	// Delete user account recovery requests
+	if _, err := tx.Exec("DELETE FROM account_recovery_requests WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user account recovery requests")
+	}
+
+	// Delete user single-use recovery codes
+	if _, err := tx.Exec("DELETE FROM user_recovery_codes WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user recovery codes")
+	}
+
+	// Delete the shares of the user's tools and the shares granted to the user
+	if _, err := tx.Exec("DELETE FROM shared_tools WHERE owner_id = ? OR grantee_id = ?", userIDStr, userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user tool shares")
+	}
+
+	// Delete the user's tools from the gallery
+	if _, err := tx.Exec("DELETE FROM gallery_tools WHERE owner_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user gallery tools")
+	}
+
+	// Delete user namespace settings
+	if _, err := tx.Exec("DELETE FROM namespaces WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user namespaces")
+	}
+
 	// Delete user global scripts
 	if _, err := tx.Exec("DELETE FROM global_scripts WHERE user_id = ?", userIDStr); err != nil {
-		tx.Rollback()
 		return errors.Wrap(err, "fail to delete user global scripts")
 	}
 
-	// Delete user record
-	if _, err := tx.Exec("DELETE FROM users WHERE id = ?", userIDStr); err != nil {
-		tx.Rollback()
-		return errors.Wrap(err, "fail to delete user")
+	// Delete user metered usage
+	if _, err := tx.Exec("DELETE FROM user_usage WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user usage")
+	}
+
+	// Delete user preferences
+	if _, err := tx.Exec("DELETE FROM user_settings WHERE user_id = ?", userIDStr); err != nil {
+		return errors.Wrap(err, "fail to delete user settings")
+	}
+
+	return nil
+}
+
+// MergeUsers moves all data of the duplicate user to the survivor and deletes the duplicate in a single transaction
+func (r *UserRepositoryRdsImpl) MergeUsers(ctx context.Context, survivorID entity.UserIDEntity, duplicateID entity.UserIDEntity) (entity.UserMergeResultEntity, error) {
+	result := entity.UserMergeResultEntity{
+		SurvivorID:   survivorID,
+		DuplicateID:  duplicateID,
+		RenamedTools: map[string]string{},
+	}
+	survivor, duplicate := string(survivorID), string(duplicateID)
+	now := time.Now()
+
+	tx, err := r.client.DB().Beginx()
+	if err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to begin merge user transaction")
+	}
+	defer tx.Rollback()
+
+	// Move tools, ids are unique per user so the ones the survivor already uses are renamed
+	var survivorToolIDs, duplicateToolIDs []string
+	if err := tx.Select(&survivorToolIDs, "SELECT id FROM tools WHERE user_id = ?", survivor); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to select survivor tool ids")
+	}
+	if err := tx.Select(&duplicateToolIDs, "SELECT id FROM tools WHERE user_id = ?", duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to select duplicate tool ids")
+	}
+
+	// the moved tools are new to the survivor's devices, the duplicate's devices go away with it
+	if _, err := tx.Exec("DELETE FROM tool_changes WHERE user_id = ?", duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete duplicate tool changes")
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO tool_changes (user_id, tool_unique_id, changed_at) SELECT ?, unique_id, ? FROM tools WHERE user_id = ?",
+		survivor, now, duplicate,
+	); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to record moved tool changes")
+	}
+	if _, err := tx.Exec("DELETE FROM user_devices WHERE user_id = ?", duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete duplicate devices")
+	}
+
+	// the moved sources and the global script stay sealed with their data keys, only the keys change owner
+	if err := resealDataKeysOfUser(tx, r.config.SourceEncryptionSecret, duplicateID, survivorID); err != nil {
+		return entity.UserMergeResultEntity{}, err
+	}
+
+	usedToolIDs := lo.SliceToMap(append(survivorToolIDs, duplicateToolIDs...), func(id string) (string, bool) { return id, true })
+	for _, toolID := range duplicateToolIDs {
+		if !lo.Contains(survivorToolIDs, toolID) {
+			continue
+		}
+		newToolID := toolID + "-merged"
+		for i := 2; usedToolIDs[newToolID]; i++ {
+			newToolID = fmt.Sprintf("%s-merged-%d", toolID, i)
+		}
+		usedToolIDs[newToolID] = true
+		if _, err := tx.Exec("UPDATE tools SET user_id = ?, id = ?, updated_at = ? WHERE user_id = ? AND id = ?", survivor, newToolID, now, duplicate, toolID); err != nil {
+			return entity.UserMergeResultEntity{}, errors.Wrapf(err, "fail to move renamed tool %s", toolID)
+		}
+		result.RenamedTools[toolID] = newToolID
+	}
+	if _, err := tx.Exec("UPDATE tools SET user_id = ? WHERE user_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move tools")
+	}
+	result.MovedTools = len(duplicateToolIDs)
+	if _, err := tx.Exec("UPDATE tool_extra_info SET user_id = ? WHERE user_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move tool extra info")
+	}
+	if _, err := tx.Exec("UPDATE tool_tags SET user_id = ? WHERE user_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move tool tags")
+	}
+	if _, err := tx.Exec("UPDATE tool_search SET user_id = ? WHERE user_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move tool search documents")
+	}
+	if _, err := tx.Exec("UPDATE tool_versions SET user_id = ? WHERE user_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move tool versions")
+	}
+	// the events keep their actor, the history of the moved tools stays readable
+	if _, err := tx.Exec("UPDATE tool_events SET user_id = ? WHERE user_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move tool events")
+	}
+	// the schedules keep running the moved tools, unique ids stay the same on a merge
+	if _, err := tx.Exec("UPDATE tool_schedules SET user_id = ? WHERE user_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move tool schedules")
+	}
+	if _, err := tx.Exec("UPDATE tool_runs SET user_id = ? WHERE user_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move tool runs")
+	}
+	// the archives of the duplicate are out of date once its data moved, it can export again as the survivor
+	if _, err := tx.Exec("DELETE FROM user_data_exports WHERE user_id = ?", duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete duplicate data exports")
+	}
+	// the survivor's tool list changed, clients have to sync it again
+	if _, err := tx.Exec("DELETE FROM tools_last_update_at WHERE user_id IN (?, ?)", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete tools last update timestamps")
+	}
+	if _, err := tx.Exec("INSERT INTO tools_last_update_at (user_id, last_updated_at) VALUES (?, ?)", survivor, now); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to update survivor tools last update timestamp")
+	}
+
+	// Move the global script unless the survivor has its own
+	var survivorScripts int
+	if err := tx.Get(&survivorScripts, "SELECT COUNT(*) FROM global_scripts WHERE user_id = ?", survivor); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to check survivor global script")
+	}
+	if survivorScripts == 0 {
+		moved, err := tx.Exec("UPDATE global_scripts SET user_id = ? WHERE user_id = ?", survivor, duplicate)
+		if err != nil {
+			return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move global script")
+		}
+		affected, _ := moved.RowsAffected()
+		result.MovedGlobalScript = affected > 0
+	} else if _, err := tx.Exec("DELETE FROM global_scripts WHERE user_id = ?", duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete duplicate global script")
+	}
+
+	// Move the tool shares, shares between the two accounts and grants the survivor already has are dropped
+	if _, err := tx.Exec(
+		"DELETE FROM shared_tools WHERE (owner_id = ? AND grantee_id = ?) OR (owner_id = ? AND grantee_id = ?)",
+		survivor, duplicate, duplicate, survivor,
+	); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete tool shares between the merged users")
+	}
+	var survivorGrants []ToolShareRdsModel
+	if err := tx.Select(&survivorGrants, "SELECT * FROM shared_tools WHERE grantee_id = ?", survivor); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to list tool shares granted to survivor")
+	}
+	for _, grant := range survivorGrants {
+		if _, err := tx.Exec(
+			"DELETE FROM shared_tools WHERE owner_id = ? AND tool_unique_id = ? AND grantee_id = ?",
+			grant.OwnerID, grant.ToolUniqueID, duplicate,
+		); err != nil {
+			return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete duplicate tool share")
+		}
+	}
+	if _, err := tx.Exec("UPDATE shared_tools SET grantee_id = ? WHERE grantee_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move tool shares granted to duplicate")
+	}
+	if _, err := tx.Exec("UPDATE shared_tools SET owner_id = ? WHERE owner_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move tool shares of duplicate")
+	}
+
+	// The published tools move with the tools
+	if _, err := tx.Exec("UPDATE gallery_tools SET owner_id = ? WHERE owner_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move gallery tools of duplicate")
+	}
+
+	// Move the namespace settings, the survivor keeps its own settings of a namespace both have
+	var survivorNamespaces []string
+	if err := tx.Select(&survivorNamespaces, "SELECT name FROM namespaces WHERE user_id = ?", survivor); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to list survivor namespaces")
+	}
+	for _, name := range survivorNamespaces {
+		if _, err := tx.Exec("DELETE FROM namespaces WHERE user_id = ? AND name = ?", duplicate, name); err != nil {
+			return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete duplicate namespace")
+		}
+	}
+	if _, err := tx.Exec("UPDATE namespaces SET user_id = ? WHERE user_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move namespaces")
+	}
+
+	// Move the preferences, the survivor keeps the ones it has set itself
+	var survivorSettingKeys []string
+	if err := tx.Select(&survivorSettingKeys, "SELECT setting_key FROM user_settings WHERE user_id = ?", survivor); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to list survivor settings")
+	}
+	for _, key := range survivorSettingKeys {
+		if _, err := tx.Exec("DELETE FROM user_settings WHERE user_id = ? AND setting_key = ?", duplicate, key); err != nil {
+			return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete duplicate setting")
+		}
+	}
+	if _, err := tx.Exec("UPDATE user_settings SET user_id = ? WHERE user_id = ?", survivor, duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move settings")
+	}
+
+	// Move passkeys and sso bindings, both are the ways to log in to the duplicate
+	moved, err := tx.Exec("UPDATE user_passkeys SET user_id = ? WHERE user_id = ?", survivor, duplicate)
+	if err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move passkeys")
+	}
+	affected, _ := moved.RowsAffected()
+	result.MovedPasskeys = int(affected)
+
+	moved, err = tx.Exec("UPDATE user_sso SET user_id = ?, updated_at = ? WHERE user_id = ?", survivor, now, duplicate)
+	if err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to move sso bindings")
+	}
+	affected, _ = moved.RowsAffected()
+	result.MovedSSOBindings = int(affected)
+
+	// the duplicate's tool secrets are sealed with the duplicate's encrypt key, the survivor can not open them
+	deleted, err := tx.Exec("DELETE FROM tool_secrets WHERE user_id = ?", duplicate)
+	if err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete duplicate tool secrets")
+	}
+	affected, _ = deleted.RowsAffected()
+	result.DeletedSecrets = int(affected)
+
+	// the duplicate's 2fa only protected the duplicate's password login
+	if _, err := tx.Exec("DELETE FROM user_2fa WHERE user_id = ?", duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete duplicate 2fa")
+	}
+	if _, err := tx.Exec("DELETE FROM user_recovery_codes WHERE user_id = ?", duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete duplicate recovery codes")
+	}
+	// a recovery of the duplicate would recover an account that no longer exists
+	if _, err := tx.Exec("DELETE FROM account_recovery_requests WHERE user_id = ?", duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete duplicate account recovery requests")
+	}
+
+	// Delete the duplicate, the survivor takes its password and email when it has none.
+	// The duplicate row goes first, email is unique.
+	var survivorModel, duplicateModel UserRdsModel
+	if err := tx.Get(&survivorModel, "SELECT * FROM users WHERE id = ?", survivor); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to get survivor user")
+	}
+	if err := tx.Get(&duplicateModel, "SELECT * FROM users WHERE id = ?", duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to get duplicate user")
+	}
+	if _, err := tx.Exec("DELETE FROM users WHERE id = ?", duplicate); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to delete duplicate user")
+	}
+
+	passwordHash, email := survivorModel.PasswordHash, survivorModel.Email
+	if !passwordHash.Valid && duplicateModel.PasswordHash.Valid {
+		passwordHash = duplicateModel.PasswordHash
+		result.MovedPassword = true
+	}
+	if !email.Valid && duplicateModel.Email.Valid {
+		email = duplicateModel.Email
+		result.MovedEmail = true
+	}
+	if _, err := tx.Exec("UPDATE users SET password_hash = ?, email = ?, updated_at = ? WHERE id = ?", passwordHash, email, now, survivor); err != nil {
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to update survivor user")
 	}
 
 	if err := tx.Commit(); err != nil {
-		return errors.Wrap(err, "fail to commit delete user transaction")
+		return entity.UserMergeResultEntity{}, errors.Wrap(err, "fail to commit merge user transaction")
 	}
 
+	return result, nil
+}
+
+// RecordLogin stores the time of a successful login and increases the login count of the user
+func (r *UserRepositoryRdsImpl) RecordLogin(ctx context.Context, userID entity.UserIDEntity) error {
+	db := r.client.DB()
+
+	_, err := db.Exec("UPDATE users SET last_login_at = ?, login_count = login_count + 1 WHERE id = ?", time.Now(), string(userID))
+	if err != nil {
+		return errors.Wrap(err, "fail to record user login in rds")
+	}
+	return nil
+}
+
+// SetPasswordChangeRequired flags the user to change the password before doing anything else
+func (r *UserRepositoryRdsImpl) SetPasswordChangeRequired(ctx context.Context, userID entity.UserIDEntity, required bool) error {
+	db := r.client.DB()
+
+	_, err := db.Exec("UPDATE users SET password_change_required = ?, updated_at = ? WHERE id = ?", required, time.Now(), string(userID))
+	if err != nil {
+		return errors.Wrap(err, "fail to set user password change required in rds")
+	}
+	return nil
+}
+
+// ListUsers returns a page of users in creation order with the number of tools each owns, and the total count of
+// matching users. The query matches a part of the username or the email, case-insensitively.
+func (r *UserRepositoryRdsImpl) ListUsers(ctx context.Context, filter entity.UserFilter) ([]entity.UserSummaryEntity, int, error) {
+	db := r.client.DB()
+
+	where := ""
+	var args []any
+	if filter.Query != "" {
+		where = " WHERE LOWER(username) LIKE ? ESCAPE '!' OR LOWER(email) LIKE ? ESCAPE '!'"
+		pattern := "%" + escapeLikePattern(strings.ToLower(filter.Query)) + "%"
+		args = append(args, pattern, pattern)
+	}
+
+	var total int
+	if err := db.GetContext(ctx, &total, "SELECT COUNT(*) FROM users"+where, args...); err != nil {
+		return nil, 0, errors.Wrap(err, "fail to count users in rds")
+	}
+
+	query := "SELECT users.*, (SELECT COUNT(*) FROM tools WHERE tools.user_id = users.id) AS tool_count FROM users" +
+		where + " ORDER BY created_at, id"
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+	var models []struct {
+		UserRdsModel
+		ToolCount int `db:"tool_count"`
+	}
+	if err := db.SelectContext(ctx, &models, query, args...); err != nil {
+		return nil, 0, errors.Wrap(err, "fail to select users from rds")
+	}
+
+	users := make([]entity.UserSummaryEntity, 0, len(models))
+	for _, model := range models {
+		user, err := r.toEntity(&model.UserRdsModel)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "fail to convert rds model to entity")
+		}
+		users = append(users, entity.UserSummaryEntity{User: user, ToolCount: model.ToolCount, CreatedAt: model.CreatedAt})
+	}
+	return users, total, nil
+}
+
+// SetDisabled disables or enables the account of the user
+func (r *UserRepositoryRdsImpl) SetDisabled(ctx context.Context, userID entity.UserIDEntity, disabled bool) error {
+	db := r.client.DB()
+
+	_, err := db.Exec("UPDATE users SET disabled = ?, updated_at = ? WHERE id = ?", disabled, time.Now(), string(userID))
+	if err != nil {
+		return errors.Wrap(err, "fail to set user disabled in rds")
+	}
+	return nil
+}
+
+// SetDeletionScheduledAt schedules the purge of the account of the user, nil cancels it
+func (r *UserRepositoryRdsImpl) SetDeletionScheduledAt(ctx context.Context, userID entity.UserIDEntity, at *time.Time) error {
+	db := r.client.DB()
+
+	_, err := db.ExecContext(ctx, "UPDATE users SET deletion_scheduled_at = ?, updated_at = ? WHERE id = ?", at, time.Now(), string(userID))
+	if err != nil {
+		return errors.Wrap(err, "fail to set user deletion scheduled at in rds")
+	}
+	return nil
+}
+
+// UsersDueForDeletion returns the users whose account deletion is scheduled at or before the time
+func (r *UserRepositoryRdsImpl) UsersDueForDeletion(ctx context.Context, before time.Time) ([]entity.UserIDEntity, error) {
+	db := r.client.DB()
+
+	var ids []string
+	if err := db.SelectContext(ctx, &ids,
+		"SELECT id FROM users WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ? ORDER BY deletion_scheduled_at, id", before,
+	); err != nil {
+		return nil, errors.Wrap(err, "fail to select users due for deletion from rds")
+	}
+	return lo.Map(ids, func(id string, _ int) entity.UserIDEntity { return entity.UserIDEntity(id) }), nil
+}
+
+// SetRoles replaces the roles of the user
+func (r *UserRepositoryRdsImpl) SetRoles(ctx context.Context, userID entity.UserIDEntity, roles []entity.UserRoleEntity) error {
+	db := r.client.DB()
+
+	roleNames := lo.Map(roles, func(item entity.UserRoleEntity, idx int) string { return item.RoleName })
+	rolesJSON, err := json.Marshal(roleNames)
+	if err != nil {
+		return errors.Wrap(err, "fail to convert user roles to json string")
+	}
+
+	_, err = db.Exec("UPDATE users SET roles = ?, updated_at = ? WHERE id = ?", string(rolesJSON), time.Now(), string(userID))
+	if err != nil {
+		return errors.Wrap(err, "fail to set user roles in rds")
+	}
 	return nil
 }
@@ -2,19 +2,28 @@ package repository_impl
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/infra/repository_impl/client"
 	"ya-tool-craft/internal/unittest"
+	"ya-tool-craft/internal/unittest/fixtures"
 
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestUserRepositoryImpl_Create(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		// Test basic creation
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -37,8 +46,8 @@ func TestUserRepositoryImpl_Create(t *testing.T) {
 func TestUserRepositoryImpl_GetByID(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -61,11 +70,56 @@ func TestUserRepositoryImpl_GetByID(t *testing.T) {
 	})
 }
 
+func TestUserRepositoryImpl_AllUserIDs(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+
+		// No users yet
+		ids, err := userRdsImpl.AllUserIDs(ctx)
+		assert.Nil(t, err)
+		assert.Empty(t, ids)
+
+		roles := []entity.UserRoleEntity{entity.UserRoleUser}
+		first, err := userRdsImpl.Create(ctx, "first", roles)
+		assert.Nil(t, err)
+		second, err := userRdsImpl.Create(ctx, "second", roles)
+		assert.Nil(t, err)
+
+		ids, err = userRdsImpl.AllUserIDs(ctx)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []entity.UserIDEntity{first.ID, second.ID}, ids)
+	})
+}
+
+func TestUserRepositoryImpl_HasUserWithRole(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+
+		_, err := userRdsImpl.Create(ctx, "plain", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+
+		hasAdmin, err := userRdsImpl.HasUserWithRole(ctx, entity.UserRoleAdmin)
+		assert.Nil(t, err)
+		assert.False(t, hasAdmin)
+
+		_, err = userRdsImpl.Create(ctx, "boss", []entity.UserRoleEntity{entity.UserRoleUser, entity.UserRoleAdmin})
+		assert.Nil(t, err)
+
+		hasAdmin, err = userRdsImpl.HasUserWithRole(ctx, entity.UserRoleAdmin)
+		assert.Nil(t, err)
+		assert.True(t, hasAdmin)
+	})
+}
+
 func TestUserRepositoryImpl_GetByUsername(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser, entity.UserRoleAdmin}
@@ -91,8 +145,8 @@ func TestUserRepositoryImpl_GetByUsername(t *testing.T) {
 func TestUserRepositoryImpl_GetByEmail(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -125,8 +179,8 @@ func TestUserRepositoryImpl_GetByEmail(t *testing.T) {
 func TestUserRepositoryImpl_Update(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -158,8 +212,8 @@ func TestUserRepositoryImpl_Update(t *testing.T) {
 func TestUserRepositoryImpl_Delete(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -185,8 +239,8 @@ func TestUserRepositoryImpl_Delete(t *testing.T) {
 func TestUserRepositoryImpl_UpdatePassword(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -221,8 +275,8 @@ func TestUserRepositoryImpl_UpdatePassword(t *testing.T) {
 func TestUserRepositoryImpl_ValidateCredentialsByUsername(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -263,8 +317,8 @@ func TestUserRepositoryImpl_ValidateCredentialsByUsername(t *testing.T) {
 func TestUserRepositoryImpl_ValidateCredentialsByEmail(t *testing.T) {
 	uintTestCtx := unittest.GetUnitTestCtx()
 
-	uintTestCtx.WithClearSqlite(func(ctx context.Context, sqliteClient *client.SqliteClient) {
-		userRdsImpl := NewUserRepositoryRdsImpl(uintTestCtx.Config, sqliteClient)
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
 
 		// Create a test user
 		roles := []entity.UserRoleEntity{entity.UserRoleUser}
@@ -313,3 +367,560 @@ func TestUserRepositoryImpl_ValidateCredentialsByEmail(t *testing.T) {
 		assert.False(t, valid)
 	})
 }
+
+func TestUserRepositoryImpl_SetDisabledAndRoles(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		assert.False(t, user.Disabled)
+
+		assert.Nil(t, userRdsImpl.SetDisabled(ctx, user.ID, true))
+		roles := []entity.UserRoleEntity{entity.UserRoleUser, {RoleName: string(entity.UserPermissionListUsers)}}
+		assert.Nil(t, userRdsImpl.SetRoles(ctx, user.ID, roles))
+		retrievedUser, _, err := userRdsImpl.GetByID(ctx, user.ID)
+		assert.Nil(t, err)
+		assert.True(t, retrievedUser.Disabled)
+		assert.Equal(t, roles, retrievedUser.Roles)
+		assert.True(t, retrievedUser.HasPermission(entity.UserPermissionListUsers))
+
+		assert.Nil(t, userRdsImpl.SetDisabled(ctx, user.ID, false))
+		retrievedUser, _, err = userRdsImpl.GetByID(ctx, user.ID)
+		assert.Nil(t, err)
+		assert.False(t, retrievedUser.Disabled)
+	})
+}
+
+func TestUserRepositoryImpl_ScheduledDeletion(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+
+		due, err := userRdsImpl.Create(ctx, "due", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		assert.Nil(t, due.DeletionScheduledAt)
+		later, err := userRdsImpl.Create(ctx, "later", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		_, err = userRdsImpl.Create(ctx, "kept", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+
+		now := time.Now().UTC().Truncate(time.Second)
+		dueAt := now.Add(-time.Minute)
+		laterAt := now.Add(time.Hour)
+		assert.Nil(t, userRdsImpl.SetDeletionScheduledAt(ctx, due.ID, &dueAt))
+		assert.Nil(t, userRdsImpl.SetDeletionScheduledAt(ctx, later.ID, &laterAt))
+
+		retrievedUser, _, err := userRdsImpl.GetByID(ctx, due.ID)
+		assert.Nil(t, err)
+		if assert.NotNil(t, retrievedUser.DeletionScheduledAt) {
+			assert.True(t, dueAt.Equal(*retrievedUser.DeletionScheduledAt))
+		}
+
+		userIDs, err := userRdsImpl.UsersDueForDeletion(ctx, now)
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.UserIDEntity{due.ID}, userIDs)
+
+		// a cancelled deletion is not due anymore
+		assert.Nil(t, userRdsImpl.SetDeletionScheduledAt(ctx, due.ID, nil))
+		userIDs, err = userRdsImpl.UsersDueForDeletion(ctx, now.Add(2*time.Hour))
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.UserIDEntity{later.ID}, userIDs)
+	})
+}
+
+func TestUserRepositoryImpl_ListUsers(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		roles := []entity.UserRoleEntity{entity.UserRoleUser}
+		alice, err := userRdsImpl.Create(ctx, "alice", roles)
+		assert.Nil(t, err)
+		bob, err := userRdsImpl.Create(ctx, "bob", roles)
+		assert.Nil(t, err)
+		email := "Carol_100%@example.com"
+		carol, err := userRdsImpl.Create(ctx, "carol", roles)
+		assert.Nil(t, err)
+		carol.Mail = &email
+		assert.Nil(t, userRdsImpl.Update(ctx, carol))
+
+		assert.Nil(t, toolRdsImpl.CreateTool(alice.ID, fixtures.NewTestTool().WithUniqueID("uid-a-1").WithID("a-1").Build(), entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(alice.ID, fixtures.NewTestTool().WithUniqueID("uid-a-2").WithID("a-2").Build(), entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(carol.ID, fixtures.NewTestTool().WithUniqueID("uid-c-1").WithID("c-1").Build(), entity.ToolEventSourceEntity{}))
+
+		users, total, err := userRdsImpl.ListUsers(ctx, entity.UserFilter{})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, total)
+		assert.Len(t, users, 3)
+		counts := map[entity.UserIDEntity]int{}
+		for _, summary := range users {
+			counts[summary.User.ID] = summary.ToolCount
+			assert.False(t, summary.CreatedAt.IsZero())
+		}
+		assert.Equal(t, map[entity.UserIDEntity]int{alice.ID: 2, bob.ID: 0, carol.ID: 1}, counts)
+
+		// a page keeps the total of every matching user
+		page, total, err := userRdsImpl.ListUsers(ctx, entity.UserFilter{Limit: 2, Offset: 2})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, total)
+		assert.Len(t, page, 1)
+
+		// the query matches the email too, its wildcards are literal
+		found, total, err := userRdsImpl.ListUsers(ctx, entity.UserFilter{Query: "_100%"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, carol.ID, found[0].User.ID)
+
+		found, total, err = userRdsImpl.ListUsers(ctx, entity.UserFilter{Query: "BO"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, bob.ID, found[0].User.ID)
+	})
+}
+
+func TestUserRepositoryImpl_DeleteUserWithAllData(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+		usageRdsImpl := NewUsageRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+
+		roles := []entity.UserRoleEntity{entity.UserRoleUser}
+		user, err := userRdsImpl.Create(ctx, "deleted", roles)
+		assert.Nil(t, err)
+		other, err := userRdsImpl.Create(ctx, "kept", roles)
+		assert.Nil(t, err)
+
+		extraInfo := map[string]string{"team": "infra"}
+		assert.Nil(t, toolRdsImpl.CreateTool(user.ID, fixtures.NewTestTool().WithUniqueID("uid-deleted").WithExtraInfo(extraInfo).Build(), entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(other.ID, fixtures.NewTestTool().WithUniqueID("uid-kept").WithExtraInfo(extraInfo).Build(), entity.ToolEventSourceEntity{}))
+		for _, userID := range []entity.UserIDEntity{user.ID, other.ID} {
+			_, err = usageRdsImpl.AddUsage(ctx, userID, entity.UsageMetricAPICalls, "2026-10", 3)
+			assert.Nil(t, err)
+		}
+
+		countRows := func(table string, userID entity.UserIDEntity) int {
+			var count int
+			assert.Nil(t, rdsClient.DB().Get(&count, "SELECT COUNT(*) FROM "+table+" WHERE user_id = ?", string(userID)))
+			return count
+		}
+
+		assert.Nil(t, userRdsImpl.DeleteUserWithAllData(ctx, user.ID))
+
+		_, exists, err := userRdsImpl.GetByID(ctx, user.ID)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+		assert.Equal(t, 0, countRows("tools", user.ID))
+		assert.Equal(t, 0, countRows("tool_extra_info", user.ID))
+		assert.Equal(t, 0, countRows("user_usage", user.ID))
+
+		// the rows of other users are kept
+		assert.Equal(t, 1, countRows("tools", other.ID))
+		assert.Equal(t, 1, countRows("tool_extra_info", other.ID))
+		assert.Equal(t, 1, countRows("user_usage", other.ID))
+	})
+}
+
+// userRowColumns are the columns naming the user a row belongs to, a table with one of them holds data of users
+var userRowColumns = []string{"user_id", "owner_id", "grantee_id"}
+
+type userTableColumn struct {
+	name    string
+	colType string
+}
+
+// userTables returns the tables holding data of users with the columns a seeded row sets, autoincrement and
+// generated columns are left to the database
+func userTables(t *testing.T, rdsConfig config.Config, rdsClient repository.IRdsClient) map[string][]userTableColumn {
+	type columnInfo struct {
+		Table     string         `db:"table_name"`
+		Name      string         `db:"column_name"`
+		Type      string         `db:"data_type"`
+		Default   sql.NullString `db:"column_default"`
+		Generated string         `db:"is_generated"`
+	}
+	type sqliteColumnInfo struct {
+		CID     int            `db:"cid"`
+		Name    string         `db:"name"`
+		Type    string         `db:"type"`
+		NotNull bool           `db:"notnull"`
+		Default sql.NullString `db:"dflt_value"`
+		PK      int            `db:"pk"`
+	}
+	var columns []columnInfo
+	if rdsConfig.DBType == "postgres" {
+		assert.Nil(t, rdsClient.DB().Select(&columns,
+			"SELECT table_name, column_name, data_type, column_default, is_generated FROM information_schema.columns WHERE table_schema = current_schema()"))
+	} else {
+		var tables []string
+		assert.Nil(t, rdsClient.DB().Select(&tables, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'"))
+		for _, table := range tables {
+			var infos []sqliteColumnInfo
+			assert.Nil(t, rdsClient.DB().Select(&infos, "SELECT * FROM pragma_table_info(?)", table))
+			keyColumns := lo.CountBy(infos, func(info sqliteColumnInfo) bool {
+				return info.PK > 0
+			})
+			for _, info := range infos {
+				// a single INTEGER PRIMARY KEY is the rowid, sqlite numbers it
+				if info.PK > 0 && keyColumns == 1 && strings.EqualFold(info.Type, "INTEGER") {
+					continue
+				}
+				columns = append(columns, columnInfo{Table: table, Name: info.Name, Type: info.Type, Generated: "NEVER"})
+			}
+		}
+	}
+
+	tables := map[string][]userTableColumn{}
+	for _, column := range columns {
+		if column.Generated == "ALWAYS" || strings.HasPrefix(column.Default.String, "nextval(") {
+			continue
+		}
+		tables[column.Table] = append(tables[column.Table], userTableColumn{name: column.Name, colType: strings.ToLower(column.Type)})
+	}
+	for table, tableColumns := range tables {
+		if !lo.ContainsBy(tableColumns, func(column userTableColumn) bool { return lo.Contains(userRowColumns, column.name) }) {
+			delete(tables, table)
+		}
+	}
+	return tables
+}
+
+// seedUserRow inserts a row of the user into the table, the values of the other columns only fit their type
+func seedUserRow(t *testing.T, rdsClient repository.IRdsClient, table string, columns []userTableColumn, userID entity.UserIDEntity, seq *int) {
+	names := make([]string, 0, len(columns))
+	values := make([]any, 0, len(columns))
+	for _, column := range columns {
+		*seq++
+		names = append(names, column.name)
+		switch {
+		case lo.Contains(userRowColumns, column.name):
+			values = append(values, string(userID))
+		case strings.Contains(column.colType, "int"):
+			values = append(values, *seq)
+		case strings.Contains(column.colType, "bool"):
+			values = append(values, false)
+		case strings.Contains(column.colType, "timestamp"), strings.Contains(column.colType, "date"):
+			values = append(values, time.Now())
+		case strings.Contains(column.colType, "blob"), strings.Contains(column.colType, "bytea"):
+			values = append(values, []byte{byte(*seq)})
+		default:
+			// short enough for the narrowest column, unique for the single column keys
+			values = append(values, strconv.Itoa(*seq))
+		}
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(names, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", "))
+	_, err := rdsClient.DB().Exec(query, values...)
+	assert.Nil(t, err, "seed %s", table)
+}
+
+// countUserRows counts the rows of the table belonging to the user through any of its user columns
+func countUserRows(t *testing.T, rdsClient repository.IRdsClient, table string, columns []userTableColumn, userID entity.UserIDEntity) int {
+	conditions := []string{}
+	args := []any{}
+	for _, column := range columns {
+		if lo.Contains(userRowColumns, column.name) {
+			conditions = append(conditions, column.name+" = ?")
+			args = append(args, string(userID))
+		}
+	}
+	var count int
+	assert.Nil(t, rdsClient.DB().Get(&count, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", table, strings.Join(conditions, " OR ")), args...))
+	return count
+}
+
+func TestUserRepositoryImpl_DeleteUserWithAllData_AllUserTables(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+
+		roles := []entity.UserRoleEntity{entity.UserRoleUser}
+		user, err := userRdsImpl.Create(ctx, "deleted", roles)
+		assert.Nil(t, err)
+		other, err := userRdsImpl.Create(ctx, "kept", roles)
+		assert.Nil(t, err)
+
+		// every table with a user column gets a row of both users, a new table is covered without touching the test
+		tables := userTables(t, rdsConfig, rdsClient)
+		assert.Subset(t, lo.Keys(tables), []string{"tools", "user_passkeys", "user_2fa", "user_usage", "tool_extra_info", "shared_tools"})
+		seq := 0
+		for table, columns := range tables {
+			seedUserRow(t, rdsClient, table, columns, user.ID, &seq)
+			seedUserRow(t, rdsClient, table, columns, other.ID, &seq)
+		}
+
+		assert.Nil(t, userRdsImpl.DeleteUserWithAllData(ctx, user.ID))
+
+		for table, columns := range tables {
+			assert.Equal(t, 0, countUserRows(t, rdsClient, table, columns, user.ID), "rows of the deleted user left in %s", table)
+			assert.Equal(t, 1, countUserRows(t, rdsClient, table, columns, other.ID), "rows of another user deleted from %s", table)
+		}
+	})
+}
+
+func TestUserRepositoryImpl_DeleteUserIfDeletionDue(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		settingRdsImpl := NewUserSettingRepositoryRdsImpl(rdsClient)
+
+		now := time.Now().UTC()
+		roles := []entity.UserRoleEntity{entity.UserRoleUser}
+		due, err := userRdsImpl.Create(ctx, "due", roles)
+		assert.Nil(t, err)
+		later, err := userRdsImpl.Create(ctx, "later", roles)
+		assert.Nil(t, err)
+		notScheduled, err := userRdsImpl.Create(ctx, "not-scheduled", roles)
+		assert.Nil(t, err)
+		dueAt := now.Add(-time.Minute)
+		laterAt := now.Add(time.Hour)
+		assert.Nil(t, userRdsImpl.SetDeletionScheduledAt(ctx, due.ID, &dueAt))
+		assert.Nil(t, userRdsImpl.SetDeletionScheduledAt(ctx, later.ID, &laterAt))
+		for _, userID := range []entity.UserIDEntity{due.ID, later.ID, notScheduled.ID} {
+			assert.Nil(t, settingRdsImpl.SaveSettings(ctx, userID, []entity.UserSettingEntity{{Key: "theme", Value: "dark", UpdatedAt: now}}))
+		}
+
+		// only the user whose deletion is due is deleted, with its data
+		for _, tc := range []struct {
+			userID      entity.UserIDEntity
+			wantDeleted bool
+		}{{due.ID, true}, {later.ID, false}, {notScheduled.ID, false}} {
+			deleted, err := userRdsImpl.DeleteUserIfDeletionDue(ctx, tc.userID, now)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.wantDeleted, deleted)
+
+			_, exists, err := userRdsImpl.GetByID(ctx, tc.userID)
+			assert.Nil(t, err)
+			assert.Equal(t, !tc.wantDeleted, exists)
+			settings, err := settingRdsImpl.AllSettings(ctx, tc.userID)
+			assert.Nil(t, err)
+			assert.Equal(t, !tc.wantDeleted, len(settings) == 1)
+		}
+
+		// a cancelled deletion keeps the user
+		assert.Nil(t, userRdsImpl.SetDeletionScheduledAt(ctx, later.ID, nil))
+		deleted, err := userRdsImpl.DeleteUserIfDeletionDue(ctx, later.ID, laterAt.Add(time.Minute))
+		assert.Nil(t, err)
+		assert.False(t, deleted)
+	})
+}
+
+func TestUserRepositoryImpl_MergeUsers(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+		globalScriptRdsImpl := NewGlobalScriptRepositoryRdsImpl(rdsConfig, rdsClient)
+		secretRdsImpl := NewToolSecretRepositoryRdsImpl(rdsConfig, rdsClient, client.NewSystemClock())
+		settingRdsImpl := NewUserSettingRepositoryRdsImpl(rdsClient)
+
+		roles := []entity.UserRoleEntity{entity.UserRoleUser}
+		survivor, err := userRdsImpl.Create(ctx, "survivor", roles)
+		assert.Nil(t, err)
+		githubEmail := "dup@example.com"
+		duplicate, err := userRdsImpl.CreateUserBySSO(ctx, "github", "gh-1", nil, &githubEmail, roles)
+		assert.Nil(t, err)
+		duplicate.Mail = &githubEmail
+		assert.Nil(t, userRdsImpl.Update(ctx, duplicate))
+		assert.Nil(t, userRdsImpl.UpdatePassword(ctx, duplicate.ID, "password123"))
+
+		assert.Nil(t, toolRdsImpl.CreateTool(survivor.ID, fixtures.NewTestTool().WithUniqueID("uid-s-1").WithID("shared").Build(), entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(duplicate.ID, fixtures.NewTestTool().WithUniqueID("uid-d-1").WithID("shared").Build(), entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(duplicate.ID, fixtures.NewTestTool().WithUniqueID("uid-d-2").WithID("shared-merged").Build(), entity.ToolEventSourceEntity{}))
+		assert.Nil(t, toolRdsImpl.CreateTool(duplicate.ID, fixtures.NewTestTool().WithUniqueID("uid-d-3").WithID("only-duplicate").Build(), entity.ToolEventSourceEntity{}))
+		assert.Nil(t, globalScriptRdsImpl.UpdateGlobalScript(duplicate.ID, "duplicate script"))
+		_, err = secretRdsImpl.PutSecret(ctx, duplicate.ID, "", "API_KEY", "sealed-by-duplicate")
+		assert.Nil(t, err)
+		_, err = secretRdsImpl.PutSecret(ctx, duplicate.ID, "uid-d-3", "API_KEY", "sealed-by-duplicate")
+		assert.Nil(t, err)
+		now := time.Now()
+		assert.Nil(t, settingRdsImpl.SaveSettings(ctx, survivor.ID, []entity.UserSettingEntity{{Key: "theme", Value: "light", UpdatedAt: now}}))
+		assert.Nil(t, settingRdsImpl.SaveSettings(ctx, duplicate.ID, []entity.UserSettingEntity{
+			{Key: "theme", Value: "dark", UpdatedAt: now},
+			{Key: "editor.font_size", Value: "18", UpdatedAt: now},
+		}))
+
+		result, err := userRdsImpl.MergeUsers(ctx, survivor.ID, duplicate.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, result.MovedTools)
+		assert.Equal(t, map[string]string{"shared": "shared-merged-2"}, result.RenamedTools)
+		assert.Equal(t, 1, result.MovedSSOBindings)
+		assert.True(t, result.MovedGlobalScript)
+		assert.True(t, result.MovedPassword)
+		assert.True(t, result.MovedEmail)
+		assert.Equal(t, 2, result.DeletedSecrets)
+
+		// duplicate is gone
+		_, exists, err := userRdsImpl.GetByID(ctx, duplicate.ID)
+		assert.Nil(t, err)
+		assert.False(t, exists)
+
+		// survivor owns everything
+		tools, err := toolRdsImpl.AllTools(survivor.ID)
+		assert.Nil(t, err)
+		toolIDs := []string{}
+		for _, tool := range tools.Tools {
+			toolIDs = append(toolIDs, tool.ID)
+		}
+		assert.ElementsMatch(t, []string{"shared", "shared-merged", "shared-merged-2", "only-duplicate"}, toolIDs)
+
+		script, err := globalScriptRdsImpl.GetGlobalScript(survivor.ID)
+		assert.Nil(t, err)
+		assert.NotNil(t, script)
+		assert.Equal(t, "duplicate script", script.Script)
+
+		bindings, err := userRdsImpl.GetUserSSOBindings(ctx, survivor.ID)
+		assert.Nil(t, err)
+		assert.Len(t, bindings, 1)
+		assert.Equal(t, "github", bindings[0].Provider)
+
+		// the survivor keeps its own preferences and gains the others
+		settings, err := settingRdsImpl.AllSettings(ctx, survivor.ID)
+		assert.Nil(t, err)
+		assert.Len(t, settings, 2)
+		assert.Equal(t, "18", settings[0].Value)
+		assert.Equal(t, "light", settings[1].Value)
+		settings, err = settingRdsImpl.AllSettings(ctx, duplicate.ID)
+		assert.Nil(t, err)
+		assert.Empty(t, settings)
+
+		// the duplicate's secrets can not be opened with the survivor's key
+		secrets, err := secretRdsImpl.ListSecrets(ctx, survivor.ID, "uid-d-3")
+		assert.Nil(t, err)
+		assert.Empty(t, secrets)
+
+		_, valid, err := userRdsImpl.ValidateCredentialsByEmail(ctx, githubEmail, "password123")
+		assert.Nil(t, err)
+		assert.True(t, valid)
+	})
+}
+
+func TestUserRepositoryImpl_MergeUsers_EncryptedSources(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		cfg := rdsConfig
+		cfg.EncryptSourcesAtRest = true
+		cfg.SourceEncryptionSecret = "a source encryption secret of the tests"
+		userRdsImpl := NewUserRepositoryRdsImpl(cfg, rdsClient)
+		toolRdsImpl := NewToolRepositoryRdsImpl(cfg, rdsClient, client.NewSystemClock())
+		globalScriptRdsImpl := NewGlobalScriptRepositoryRdsImpl(cfg, rdsClient)
+		versionRdsImpl := NewToolVersionRepositoryRdsImpl(cfg, rdsClient)
+
+		roles := []entity.UserRoleEntity{entity.UserRoleUser}
+		survivor, err := userRdsImpl.Create(ctx, "survivor", roles)
+		assert.Nil(t, err)
+		duplicate, err := userRdsImpl.Create(ctx, "duplicate", roles)
+		assert.Nil(t, err)
+
+		tool := fixtures.NewTestTool().WithUniqueID("uid-d-1").WithSource("function handler() { return 'sealed' }").Build()
+		assert.Nil(t, toolRdsImpl.CreateTool(duplicate.ID, tool, entity.ToolEventSourceEntity{}))
+		assert.Nil(t, globalScriptRdsImpl.UpdateGlobalScript(duplicate.ID, "globalThis.duplicate = true"))
+
+		_, err = userRdsImpl.MergeUsers(ctx, survivor.ID, duplicate.ID)
+		assert.Nil(t, err)
+
+		// the moved data keys are sealed with the survivor's encrypt key now
+		tools, err := toolRdsImpl.AllTools(survivor.ID)
+		assert.Nil(t, err)
+		assert.Len(t, tools.Tools, 1)
+		assert.Equal(t, tool.Source, tools.Tools[0].Source)
+		version, found, err := versionRdsImpl.GetVersion(ctx, survivor.ID, tool.UniqueID, 1)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, tool.Source, version.Source)
+		script, err := globalScriptRdsImpl.GetGlobalScript(survivor.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "globalThis.duplicate = true", script.Script)
+	})
+}
+
+func TestUserRepositoryImpl_RecordLogin(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+
+		// never logged in
+		retrievedUser, _, err := userRdsImpl.GetByID(ctx, user.ID)
+		assert.Nil(t, err)
+		assert.Nil(t, retrievedUser.LastLoginAt)
+		assert.Equal(t, 0, retrievedUser.LoginCount)
+
+		before := time.Now().Add(-time.Second)
+		assert.Nil(t, userRdsImpl.RecordLogin(ctx, user.ID))
+		assert.Nil(t, userRdsImpl.RecordLogin(ctx, user.ID))
+
+		retrievedUser, _, err = userRdsImpl.GetByID(ctx, user.ID)
+		assert.Nil(t, err)
+		assert.NotNil(t, retrievedUser.LastLoginAt)
+		assert.True(t, retrievedUser.LastLoginAt.After(before))
+		assert.Equal(t, 2, retrievedUser.LoginCount)
+	})
+}
+
+func TestUserRepositoryImpl_SetPasswordChangeRequired(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+
+		user, err := userRdsImpl.Create(ctx, "testuser", []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+		assert.False(t, user.PasswordChangeRequired)
+
+		assert.Nil(t, userRdsImpl.SetPasswordChangeRequired(ctx, user.ID, true))
+		retrievedUser, _, err := userRdsImpl.GetByID(ctx, user.ID)
+		assert.Nil(t, err)
+		assert.True(t, retrievedUser.PasswordChangeRequired)
+
+		// a new password satisfies the required change
+		assert.Nil(t, userRdsImpl.UpdatePassword(ctx, user.ID, "new-password"))
+		retrievedUser, _, err = userRdsImpl.GetByID(ctx, user.ID)
+		assert.Nil(t, err)
+		assert.False(t, retrievedUser.PasswordChangeRequired)
+	})
+}
+
+func TestUserRepositoryImpl_UserSSOAccessToken(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		userRdsImpl := NewUserRepositoryRdsImpl(rdsConfig, rdsClient)
+
+		user, err := userRdsImpl.CreateUserBySSO(ctx, "github", "gh-1", nil, nil, []entity.UserRoleEntity{entity.UserRoleUser})
+		assert.Nil(t, err)
+
+		// bindings made before the token was kept have none
+		_, found, err := userRdsImpl.GetUserSSOAccessToken(ctx, user.ID, "github")
+		assert.Nil(t, err)
+		assert.False(t, found)
+
+		assert.Nil(t, userRdsImpl.SetUserSSOAccessToken(ctx, user.ID, "github", "gho_token"))
+		token, found, err := userRdsImpl.GetUserSSOAccessToken(ctx, user.ID, "github")
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "gho_token", token)
+
+		err = userRdsImpl.SetUserSSOAccessToken(ctx, user.ID, "google", "token")
+		assert.ErrorIs(t, err, repository.ErrUserSSOBindingNotFound)
+
+		// the token goes with the binding
+		assert.Nil(t, userRdsImpl.DeleteUserSSOBinding(ctx, user.ID, "github"))
+		_, found, err = userRdsImpl.GetUserSSOAccessToken(ctx, user.ID, "github")
+		assert.Nil(t, err)
+		assert.False(t, found)
+	})
+}
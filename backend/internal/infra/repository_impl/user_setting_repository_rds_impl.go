@@ -0,0 +1,106 @@
+package repository_impl
+
+import (
+	"context"
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+
+	"github.com/pkg/errors"
+)
+
+type UserSettingRdsModel struct {
+	UserID       string    `db:"user_id"`
+	SettingKey   string    `db:"setting_key"`
+	SettingValue string    `db:"setting_value"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+func NewUserSettingRepositoryRdsImpl(client repository.IRdsClient) *UserSettingRepositoryRdsImpl {
+	return &UserSettingRepositoryRdsImpl{client: client}
+}
+
+type UserSettingRepositoryRdsImpl struct {
+	client repository.IRdsClient
+}
+
+func (r *UserSettingRepositoryRdsImpl) AllSettings(ctx context.Context, userID entity.UserIDEntity) ([]entity.UserSettingEntity, error) {
+	db := r.client.DB()
+
+	var models []UserSettingRdsModel
+	if err := db.SelectContext(ctx, &models,
+		"SELECT user_id, setting_key, setting_value, updated_at FROM user_settings WHERE user_id = ? ORDER BY setting_key",
+		string(userID),
+	); err != nil {
+		return nil, errors.Wrapf(err, "fail to select settings of user %s", userID)
+	}
+
+	settings := make([]entity.UserSettingEntity, 0, len(models))
+	for _, model := range models {
+		settings = append(settings, entity.UserSettingEntity{
+			Key:       model.SettingKey,
+			Value:     model.SettingValue,
+			UpdatedAt: model.UpdatedAt,
+		})
+	}
+	return settings, nil
+}
+
+func (r *UserSettingRepositoryRdsImpl) SaveSettings(ctx context.Context, userID entity.UserIDEntity, settings []entity.UserSettingEntity) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	db := r.client.DB()
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "fail to begin save user settings transaction")
+	}
+
+	for _, setting := range settings {
+		// delete + insert instead of a dialect specific upsert
+		if _, err = tx.ExecContext(ctx, "DELETE FROM user_settings WHERE user_id = ? AND setting_key = ?", string(userID), setting.Key); err != nil {
+			tx.Rollback()
+			return errors.Wrapf(err, "fail to delete user setting %s", setting.Key)
+		}
+		if _, err = tx.ExecContext(ctx,
+			"INSERT INTO user_settings (user_id, setting_key, setting_value, updated_at) VALUES (?, ?, ?, ?)",
+			string(userID),
+			setting.Key,
+			setting.Value,
+			setting.UpdatedAt,
+		); err != nil {
+			tx.Rollback()
+			return errors.Wrapf(err, "fail to insert user setting %s", setting.Key)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "fail to commit save user settings transaction")
+	}
+	return nil
+}
+
+func (r *UserSettingRepositoryRdsImpl) DeleteSettings(ctx context.Context, userID entity.UserIDEntity, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	db := r.client.DB()
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "fail to begin delete user settings transaction")
+	}
+
+	for _, key := range keys {
+		if _, err = tx.ExecContext(ctx, "DELETE FROM user_settings WHERE user_id = ? AND setting_key = ?", string(userID), key); err != nil {
+			tx.Rollback()
+			return errors.Wrapf(err, "fail to delete user setting %s", key)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "fail to commit delete user settings transaction")
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/repository"
+	"ya-tool-craft/internal/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserSettingRepositoryRdsImpl(t *testing.T) {
+	uintTestCtx := unittest.GetUnitTestCtx()
+
+	uintTestCtx.WithClearRds(t, func(t *testing.T, ctx context.Context, rdsConfig config.Config, rdsClient repository.IRdsClient) {
+		repo := NewUserSettingRepositoryRdsImpl(rdsClient)
+		userID := entity.UserIDEntity("user-settings-1")
+		otherUserID := entity.UserIDEntity("user-settings-2")
+
+		// the sqlite file is shared between tests, start from an empty table
+		_, err := rdsClient.DB().Exec("DELETE FROM user_settings")
+		assert.Nil(t, err)
+
+		settings, err := repo.AllSettings(ctx, userID)
+		assert.Nil(t, err)
+		assert.Empty(t, settings)
+
+		updatedAt := time.Now().UTC().Truncate(time.Second)
+		assert.Nil(t, repo.SaveSettings(ctx, userID, []entity.UserSettingEntity{
+			{Key: "theme", Value: "dark", UpdatedAt: updatedAt},
+			{Key: "editor.font_size", Value: "16", UpdatedAt: updatedAt},
+		}))
+		assert.Nil(t, repo.SaveSettings(ctx, otherUserID, []entity.UserSettingEntity{{Key: "theme", Value: "light", UpdatedAt: updatedAt}}))
+
+		// saving again replaces the value
+		assert.Nil(t, repo.SaveSettings(ctx, userID, []entity.UserSettingEntity{{Key: "theme", Value: "light", UpdatedAt: updatedAt}}))
+		assert.Nil(t, repo.SaveSettings(ctx, userID, nil))
+
+		settings, err = repo.AllSettings(ctx, userID)
+		assert.Nil(t, err)
+		assert.Len(t, settings, 2)
+		assert.Equal(t, "editor.font_size", settings[0].Key)
+		assert.Equal(t, "theme", settings[1].Key)
+		assert.Equal(t, "light", settings[1].Value)
+		assert.True(t, updatedAt.Equal(settings[1].UpdatedAt))
+
+		assert.Nil(t, repo.DeleteSettings(ctx, userID, []string{"theme", "missing.key"}))
+
+		settings, err = repo.AllSettings(ctx, userID)
+		assert.Nil(t, err)
+		assert.Len(t, settings, 1)
+		assert.Equal(t, "editor.font_size", settings[0].Key)
+
+		// the settings of other users are untouched
+		settings, err = repo.AllSettings(ctx, otherUserID)
+		assert.Nil(t, err)
+		assert.Len(t, settings, 1)
+		assert.Equal(t, "theme", settings[0].Key)
+	})
+}
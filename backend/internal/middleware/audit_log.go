@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/core/requestid"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const auditActorKey = "audit-actor-id"
+
+// SetAuditActor remembers the authenticated caller of the request, the audit middleware records it as the actor.
+func SetAuditActor(c *gin.Context, userID entity.UserIDEntity) {
+	c.Set(auditActorKey, userID)
+}
+
+// AuditLogMiddlewareFactory records every state changing request of the routes it is attached to,
+// with actor, route, a redacted payload summary and the outcome, after the handler has run.
+// Reads are not recorded.
+func AuditLogMiddlewareFactory(auditLogService *service.AuditLogService) gin.HandlerFunc {
+	return auditRequestMiddleware(func(c *gin.Context, auditLog entity.AuditLogEntity) {
+		// the response is already written, a failed record must not turn the action into an error
+		if err := auditLogService.Record(c, auditLog); err != nil {
+			logger.Errorf(c, "failed to record audit log for %s %s: %v", c.Request.Method, c.FullPath(), err)
+		}
+	})
+}
+
+// AuthEventMiddlewareFactory hands every state changing request of the auth routes it is attached to,
+// such as logins and logouts, to the siem export the same way the audit middleware records admin actions.
+func AuthEventMiddlewareFactory(auditLogService *service.AuditLogService) gin.HandlerFunc {
+	return auditRequestMiddleware(func(c *gin.Context, auditLog entity.AuditLogEntity) {
+		auditLogService.ExportAuthEvent(c, auditLog)
+	})
+}
+
+// auditRequestMiddleware captures the request body, runs the handler and passes the finished request to record
+func auditRequestMiddleware(record func(c *gin.Context, auditLog entity.AuditLogEntity)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		var payload []byte
+		if c.Request.Body != nil {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				logger.Errorf(c, "failed to read request body for audit log: %v", errors.WithStack(err))
+			}
+			payload = body
+			// hand the body back to the handler
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		c.Next()
+
+		actorID, _ := c.Get(auditActorKey)
+		actor, _ := actorID.(entity.UserIDEntity)
+		record(c, entity.NewAuditLogEntityWithoutID(
+			actor,
+			c.Request.Method,
+			c.FullPath(),
+			c.Request.URL.Path,
+			string(payload),
+			c.Writer.Status(),
+			requestid.GetRequestID(c),
+			c.ClientIP(),
+		))
+	}
+}
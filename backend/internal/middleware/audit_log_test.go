@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+	mockgen "ya-tool-craft/internal/infra/repository_impl/mock_gen"
+	"ya-tool-craft/internal/unittest/fixtures"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		actor      entity.UserIDEntity
+		status     int
+		wantRecord bool
+		want       entity.AuditLogEntity
+	}{
+		{
+			name:   "successful write is recorded with actor and redacted payload",
+			method: http.MethodPut,
+			body:   `{"value":"false","password":"hunter2"}`,
+			actor:  "admin-1",
+			status: http.StatusOK,
+			want: entity.AuditLogEntity{
+				ActorID: "admin-1",
+				Method:  http.MethodPut,
+				Route:   "/api/v1/admin/settings/:key",
+				Path:    "/api/v1/admin/settings/registration.enabled",
+				Payload: `{"password":"[REDACTED]","value":"false"}`,
+				Status:  http.StatusOK,
+				Outcome: entity.AuditOutcomeSuccess,
+			},
+			wantRecord: true,
+		},
+		{
+			name:   "rejected write is recorded without actor",
+			method: http.MethodDelete,
+			status: http.StatusUnauthorized,
+			want: entity.AuditLogEntity{
+				Method:  http.MethodDelete,
+				Route:   "/api/v1/admin/settings/:key",
+				Path:    "/api/v1/admin/settings/registration.enabled",
+				Status:  http.StatusUnauthorized,
+				Outcome: entity.AuditOutcomeFailure,
+			},
+			wantRecord: true,
+		},
+		{
+			name:   "reads are not recorded",
+			method: http.MethodGet,
+			actor:  "admin-1",
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+			if tt.wantRecord {
+				auditLogRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, auditLog entity.AuditLogEntity) error {
+					require.NotEmpty(t, auditLog.ID)
+					require.False(t, auditLog.CreatedAt.IsZero())
+					auditLog.ID = ""
+					auditLog.CreatedAt = time.Time{}
+					auditLog.RequestID = ""
+					auditLog.ClientIP = ""
+					require.Equal(t, tt.want, auditLog)
+					return nil
+				})
+			}
+
+			router := gin.New()
+			router.Handle(tt.method, "/api/v1/admin/settings/:key",
+				AuditLogMiddlewareFactory(service.NewAuditLogService(auditLogRepo, service.NewSIEMExportService(nil, nil, config.Config{}), fixtures.NewFakeClock(time.Now()))),
+				func(c *gin.Context) {
+					// the handler still sees the full body
+					body, err := io.ReadAll(c.Request.Body)
+					require.NoError(t, err)
+					require.Equal(t, tt.body, string(body))
+					if tt.actor != "" {
+						SetAuditActor(c, tt.actor)
+					}
+					c.Status(tt.status)
+				},
+			)
+
+			req := httptest.NewRequest(tt.method, "/api/v1/admin/settings/registration.enabled", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+// recordingSIEMSink keeps the batches written to it.
+type recordingSIEMSink struct {
+	mu      sync.Mutex
+	batches []string
+}
+
+func (s *recordingSIEMSink) Write(_ context.Context, batch []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, string(batch))
+	return nil
+}
+
+func TestAuthEventMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	// auth events are exported only, the audit log repository is never written
+	auditLogRepo := mockgen.NewMockIAuditLogRepository(ctrl)
+	sink := &recordingSIEMSink{}
+	clock := fixtures.NewFakeClock(time.Unix(100, 0))
+	siem := service.NewSIEMExportService(sink, clock, config.Config{SIEMExport: "file", SIEMFormat: "jsonl", SIEMBufferSize: 10, SIEMBatchSize: 10, SIEMFlushInterval: 60})
+	auditLogService := service.NewAuditLogService(auditLogRepo, siem, clock)
+	require.True(t, auditLogService.AuthEventsExported())
+	siem.Start()
+
+	router := gin.New()
+	router.POST("/api/v1/auth/login", AuthEventMiddlewareFactory(auditLogService), func(c *gin.Context) {
+		SetAuditActor(c, "user-1")
+		c.Status(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"username":"alice","password":"hunter2"}`))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// stopping writes the queued events
+	siem.Stop()
+	require.Len(t, sink.batches, 1)
+	require.Contains(t, sink.batches[0], `"category":"auth"`)
+	require.Contains(t, sink.batches[0], `"actor_id":"user-1"`)
+	require.Contains(t, sink.batches[0], `"route":"/api/v1/auth/login"`)
+	require.Contains(t, sink.batches[0], `[REDACTED]`)
+	require.NotContains(t, sink.batches[0], "hunter2")
+}
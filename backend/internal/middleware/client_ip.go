@@ -0,0 +1,13 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIPMiddlewareFactory adds the client ip to the context, so services can tell where a request came from
+func ClientIPMiddlewareFactory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("client-ip", c.ClientIP())
+		c.Next()
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"fmt"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientVersionHeader carries the version of the client making the request, e.g. "1.4.0"
+const ClientVersionHeader = "X-Client-Version"
+
+// ClientVersionMiddlewareFactory marks the responses to clients older than MIN_CLIENT_VERSION with a Deprecation
+// header and a Warning telling the user to upgrade. The request is still served, old clients keep working
+// until the server actually drops what they rely on.
+func ClientVersionMiddlewareFactory(compatibilityService *service.ClientCompatibilityService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientVersion := c.GetHeader(ClientVersionHeader)
+		if compatibilityService.IsClientDeprecated(clientVersion) {
+			logger.Debugf(c, "Client version %q is older than the minimum supported version %s", clientVersion, compatibilityService.MinClientVersion())
+			// the client version is not echoed, it is whatever the client sent
+			c.Header("Deprecation", "true")
+			c.Header("Warning", fmt.Sprintf(`299 - "this client version is no longer supported, upgrade to %s or later"`, compatibilityService.MinClientVersion()))
+		}
+		c.Next()
+	}
+}
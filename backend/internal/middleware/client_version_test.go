@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientVersionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger.InitLogger(config.Config{})
+
+	compatibilityService := service.NewClientCompatibilityService(config.Config{MinClientVersion: "1.4.0"})
+	router := gin.New()
+	router.Use(ClientVersionMiddlewareFactory(compatibilityService))
+	router.GET("/api/v1/tools", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		clientVersion  string
+		wantDeprecated bool
+	}{
+		{name: "old client", clientVersion: "1.3.0", wantDeprecated: true},
+		{name: "supported client", clientVersion: "1.4.0", wantDeprecated: false},
+		{name: "no client version", clientVersion: "", wantDeprecated: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tools", nil)
+			if tt.clientVersion != "" {
+				req.Header.Set(ClientVersionHeader, tt.clientVersion)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// the request is served either way
+			require.Equal(t, http.StatusOK, w.Code)
+			if tt.wantDeprecated {
+				require.Equal(t, "true", w.Header().Get("Deprecation"))
+				require.Contains(t, w.Header().Get("Warning"), "upgrade to 1.4.0 or later")
+				return
+			}
+			require.Empty(t, w.Header().Get("Deprecation"))
+			require.Empty(t, w.Header().Get("Warning"))
+		})
+	}
+}
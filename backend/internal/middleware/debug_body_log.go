@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	// debugBodyLogMaxCapture bounds the response bytes kept for logging, a cut json body is only logged by its size
+	debugBodyLogMaxCapture = 1 << 20
+	// debugBodyLogMaxLength bounds a logged body after redaction
+	debugBodyLogMaxLength = 4096
+)
+
+// debugBodyLogf is replaced in tests to capture the logged bodies
+var debugBodyLogf = logger.Debugf
+
+// MatchDebugBodyLogRoute reports whether a route is selected by one of the DEBUG_BODY_LOG_ROUTES patterns.
+// A pattern is "[METHOD ]PATH", without a method every method matches and a PATH ending with * matches by prefix.
+func MatchDebugBodyLogRoute(patterns []string, method string, path string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		patternPath := pattern
+		if patternMethod, rest, ok := strings.Cut(pattern, " "); ok {
+			if !strings.EqualFold(patternMethod, method) {
+				continue
+			}
+			patternPath = strings.TrimSpace(rest)
+		}
+		if prefix, ok := strings.CutSuffix(patternPath, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+			continue
+		}
+		if patternPath == path {
+			return true
+		}
+	}
+	return false
+}
+
+// DebugBodyLogMiddleware logs the request and response bodies of the routes it is attached to at debug level.
+// Bodies go through service.RedactPayload, so passwords, tokens and TOTP codes are never logged
+// and a non json body is only logged by its size.
+func DebugBodyLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var requestBody []byte
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				logger.Errorf(c, "failed to read request body for debug log: %v", errors.WithStack(err))
+			}
+			requestBody = body
+			// hand the body back to the handler
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		debugBodyLogf(c, "request body of %s %s: %s", c.Request.Method, c.FullPath(), service.RedactPayload(string(requestBody), debugBodyLogMaxLength))
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		responseBody := service.RedactPayload(writer.body.String(), debugBodyLogMaxLength)
+		if writer.truncated {
+			responseBody = fmt.Sprintf("<body larger than %d bytes>", debugBodyLogMaxCapture)
+		}
+		debugBodyLogf(c, "response body of %s %s, status %d: %s", c.Request.Method, c.FullPath(), c.Writer.Status(), responseBody)
+	}
+}
+
+// bodyCaptureWriter keeps a copy of the first debugBodyLogMaxCapture bytes written to the response.
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyCaptureWriter) capture(b []byte) {
+	if w.truncated {
+		return
+	}
+	if w.body.Len()+len(b) > debugBodyLogMaxCapture {
+		w.truncated = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(b)
+}
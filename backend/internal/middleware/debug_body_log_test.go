@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchDebugBodyLogRoute(t *testing.T) {
+	patterns := []string{"POST /api/v1/auth/login", " /api/v1/tools/* ", "", "delete /api/v1/user/devices/:device_id"}
+
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{http.MethodPost, "/api/v1/auth/login", true},
+		{http.MethodGet, "/api/v1/auth/login", false},
+		{http.MethodPut, "/api/v1/tools/:tool_uid", true},
+		{http.MethodGet, "/api/v1/tools", false},
+		{http.MethodDelete, "/api/v1/user/devices/:device_id", true},
+		{http.MethodGet, "/api/v1/user/devices", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			require.Equal(t, tt.want, MatchDebugBodyLogRoute(patterns, tt.method, tt.path))
+		})
+	}
+}
+
+func TestDebugBodyLogMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logged []string
+	original := debugBodyLogf
+	t.Cleanup(func() { debugBodyLogf = original })
+	debugBodyLogf = func(_ context.Context, format string, args ...any) {
+		logged = append(logged, fmt.Sprintf(format, args...))
+	}
+
+	requestBody := `{"username":"alice","password":"hunter2","totp":{"code":"123456"}}`
+	router := gin.New()
+	router.POST("/api/v1/auth/login", DebugBodyLogMiddleware(), func(c *gin.Context) {
+		// the handler still sees the full body
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		require.Equal(t, requestBody, string(body))
+		c.JSON(http.StatusOK, gin.H{"access_token": "eyJ.secret", "user": gin.H{"name": "alice"}})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(requestBody))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"access_token":"eyJ.secret","user":{"name":"alice"}}`, w.Body.String())
+	require.Equal(t, []string{
+		`request body of POST /api/v1/auth/login: {"password":"[REDACTED]","totp":"[REDACTED]","username":"alice"}`,
+		`response body of POST /api/v1/auth/login, status 200: {"access_token":"[REDACTED]","user":{"name":"alice"}}`,
+	}, logged)
+}
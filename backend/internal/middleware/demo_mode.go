@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DemoModeMiddleware rejects the request with DemoModeReadonly before it reaches the handler, it is put in front of
+// every route without router.RouterInfo.DemoModeAllowed. respond writes the error so the response has the same shape
+// as the ones of the controllers.
+func DemoModeMiddleware(respond func(ctx *gin.Context, err error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger.Infof(c, "Rejected %s %s, the instance runs in demo mode", c.Request.Method, c.FullPath())
+		respond(c, error_code.NewErrorWithErrorCodef(error_code.DemoModeReadonly, "%s %s is disabled in demo mode", c.Request.Method, c.FullPath()))
+		c.Abort()
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/error_code"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDemoModeMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger.InitLogger(config.Config{})
+
+	var respondedErr error
+	respond := func(c *gin.Context, err error) {
+		respondedErr = err
+		c.JSON(http.StatusForbidden, gin.H{"status": "error"})
+	}
+
+	router := gin.New()
+	router.PUT("/api/v1/tools/:tool_uid", DemoModeMiddleware(respond), func(c *gin.Context) {
+		t.Fatal("handler called in demo mode")
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/tools/abc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusForbidden, w.Code)
+	var codeErr error_code.ErrorWithErrorCode
+	require.True(t, errors.As(respondedErr, &codeErr))
+	require.Equal(t, error_code.DemoModeReadonly.Code, codeErr.ErrorCode.Code)
+}
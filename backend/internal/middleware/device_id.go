@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DeviceIDHeader = "X-Device-ID"
+	// deviceIDMaxLength is the size of user_devices.id, a longer header is not a device id
+	deviceIDMaxLength = 64
+)
+
+// DeviceIDMiddlewareFactory adds the device id a client got at login to the context, so tool changes record the device
+// they came from. The header is informational and not checked against the devices of the user.
+func DeviceIDMiddlewareFactory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if deviceID := c.GetHeader(DeviceIDHeader); deviceID != "" && len(deviceID) <= deviceIDMaxLength {
+			c.Set("device-id", deviceID)
+		}
+		c.Next()
+	}
+}
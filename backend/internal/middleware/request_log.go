@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/entity"
+	"ya-tool-craft/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+const (
+	// requestLogMaxPayloadCapture bounds the request bytes read for the log, a larger body is only logged by its size
+	requestLogMaxPayloadCapture = 64 << 10
+	// requestLogMaxPayloadLength bounds a logged payload after redaction
+	requestLogMaxPayloadLength = 1024
+)
+
+// requestLog is replaced in tests to capture the logged requests
+var requestLog = func(ctx context.Context, status int, fields map[string]any) {
+	if status >= http.StatusInternalServerError {
+		logger.ErrorFields(ctx, fields, "request")
+		return
+	}
+	logger.InfoFields(ctx, fields, "request")
+}
+
+// RequestLogMiddlewareFactory logs every request once it is handled, with its method, path, route, status, latency,
+// the authenticated user and the request id as fields, so they can be queried in the json log format. Sensitive query
+// parameters are redacted. With REQUEST_LOG_PAYLOADS the body of a state changing request is logged through
+// service.RedactPayload, so passwords, tokens and TOTP codes are never logged.
+func RequestLogMiddlewareFactory(config config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		payload := ""
+		if config.RequestLogPayloads {
+			payload = captureRequestLogPayload(c)
+		}
+
+		c.Next()
+
+		fields := map[string]any{
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"route":      c.FullPath(),
+			"status":     c.Writer.Status(),
+			"size":       c.Writer.Size(),
+			"latency_ms": time.Since(start).Milliseconds(),
+			"client_ip":  c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+		}
+		if query := service.RedactQuery(c.Request.URL.RawQuery); query != "" {
+			fields["query"] = query
+		}
+		if actorID, ok := c.Get(auditActorKey); ok {
+			if userID, ok := actorID.(entity.UserIDEntity); ok && userID != "" {
+				fields["user_id"] = string(userID)
+			}
+		}
+		if payload != "" {
+			fields["payload"] = payload
+		}
+		requestLog(c, c.Writer.Status(), fields)
+	}
+}
+
+// captureRequestLogPayload returns the redacted request body and hands the body back to the handler, reads have no payload
+func captureRequestLogPayload(c *gin.Context) string {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ""
+	}
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, requestLogMaxPayloadCapture+1))
+	if err != nil {
+		logger.Errorf(c, "failed to read request body for request log: %v", errors.WithStack(err))
+	}
+	// the rest of a large body is still read by the handler
+	c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), c.Request.Body), Closer: c.Request.Body}
+	if len(body) > requestLogMaxPayloadCapture {
+		return fmt.Sprintf("<body larger than %d bytes>", requestLogMaxPayloadCapture)
+	}
+	return service.RedactPayload(string(body), requestLogMaxPayloadLength)
+}
+
+// readCloser reads from Reader and closes the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func captureRequestLog(t *testing.T) *[]map[string]any {
+	var logged []map[string]any
+	original := requestLog
+	t.Cleanup(func() { requestLog = original })
+	requestLog = func(_ context.Context, _ int, fields map[string]any) {
+		logged = append(logged, fields)
+	}
+	return &logged
+}
+
+func TestRequestLogMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("logs the request as fields with the sensitive values redacted", func(t *testing.T) {
+		logged := captureRequestLog(t)
+
+		requestBody := `{"username":"alice","password":"hunter2","totp":{"code":"123456"}}`
+		router := gin.New()
+		router.Use(RequestLogMiddlewareFactory(config.Config{RequestLogPayloads: true}))
+		router.POST("/api/v1/auth/2fa/login/:method", func(c *gin.Context) {
+			// the handler still sees the full body
+			body, err := io.ReadAll(c.Request.Body)
+			require.NoError(t, err)
+			require.Equal(t, requestBody, string(body))
+			SetAuditActor(c, entity.UserIDEntity("user-1"))
+			c.JSON(http.StatusCreated, gin.H{"access_token": "eyJ.secret"})
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/2fa/login/totp?state=abc&code=s3cret&access_token=eyJ", strings.NewReader(requestBody))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Len(t, *logged, 1)
+		fields := (*logged)[0]
+		require.Equal(t, http.MethodPost, fields["method"])
+		require.Equal(t, "/api/v1/auth/2fa/login/totp", fields["path"])
+		require.Equal(t, "/api/v1/auth/2fa/login/:method", fields["route"])
+		require.Equal(t, http.StatusCreated, fields["status"])
+		require.Equal(t, "user-1", fields["user_id"])
+		require.Contains(t, fields, "latency_ms")
+		require.Equal(t, "access_token=[REDACTED]&code=[REDACTED]&state=abc", fields["query"])
+		require.Equal(t, `{"password":"[REDACTED]","totp":"[REDACTED]","username":"alice"}`, fields["payload"])
+	})
+
+	t.Run("leaves the payload out unless enabled and for reads", func(t *testing.T) {
+		for _, tt := range []struct {
+			name   string
+			cfg    config.Config
+			method string
+		}{
+			{"disabled", config.Config{}, http.MethodPost},
+			{"read", config.Config{RequestLogPayloads: true}, http.MethodGet},
+		} {
+			logged := captureRequestLog(t)
+			router := gin.New()
+			router.Use(RequestLogMiddlewareFactory(tt.cfg))
+			router.Handle(tt.method, "/api/v1/tools", func(c *gin.Context) {
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(tt.method, "/api/v1/tools", strings.NewReader(`{"password":"hunter2"}`))
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.Len(t, *logged, 1, tt.name)
+			require.NotContains(t, (*logged)[0], "payload", tt.name)
+			require.NotContains(t, (*logged)[0], "user_id", tt.name)
+		}
+	})
+
+	t.Run("logs a large body by its size and hands all of it to the handler", func(t *testing.T) {
+		logged := captureRequestLog(t)
+
+		requestBody := `{"source":"` + strings.Repeat("x", requestLogMaxPayloadCapture) + `"}`
+		router := gin.New()
+		router.Use(RequestLogMiddlewareFactory(config.Config{RequestLogPayloads: true}))
+		router.POST("/api/v1/tools/create", func(c *gin.Context) {
+			body, err := io.ReadAll(c.Request.Body)
+			require.NoError(t, err)
+			require.Equal(t, requestBody, string(body))
+			c.Status(http.StatusOK)
+		})
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/tools/create", strings.NewReader(requestBody)))
+
+		require.Len(t, *logged, 1)
+		require.Equal(t, "<body larger than 65536 bytes>", (*logged)[0]["payload"])
+	})
+}
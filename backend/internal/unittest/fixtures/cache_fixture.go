@@ -0,0 +1,118 @@
+package fixtures
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+	"ya-tool-craft/internal/domain/client"
+	"ya-tool-craft/internal/domain/repository"
+)
+
+var _ repository.ICache = (*FakeCache)(nil)
+
+// FakeCache is a thread-safe in-memory ICache for service tests.
+// TTLs behave like the real backends (seconds, 0 means no expiry) and are measured on the
+// given clock, so expiry can be tested by advancing a FakeClock instead of sleeping.
+type FakeCache struct {
+	mu      sync.Mutex
+	clock   client.IClock
+	entries map[string]fakeCacheEntry
+}
+
+type fakeCacheEntry struct {
+	value    string
+	expireAt time.Time // zero means no expiry
+}
+
+func NewFakeCache(clock client.IClock) *FakeCache {
+	return &FakeCache{clock: clock, entries: map[string]fakeCacheEntry{}}
+}
+
+func (c *FakeCache) Set(ctx context.Context, key string, value string) error {
+	return c.SetWithTTL(ctx, key, value, 0)
+}
+
+func (c *FakeCache) SetWithTTL(ctx context.Context, key string, value string, ttl uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := fakeCacheEntry{value: value}
+	if ttl > 0 {
+		entry.expireAt = c.clock.Now().Add(time.Duration(ttl) * time.Second)
+	}
+	c.entries[key] = entry
+	return nil
+}
+
+func (c *FakeCache) Get(ctx context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.live(key)
+	return entry.value, ok, nil
+}
+
+func (c *FakeCache) Increment(ctx context.Context, key string, ttl uint64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.live(key)
+	if !ok {
+		entry = fakeCacheEntry{value: "0"}
+		if ttl > 0 {
+			entry.expireAt = c.clock.Now().Add(time.Duration(ttl) * time.Second)
+		}
+	}
+	count, err := strconv.ParseInt(entry.value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	count++
+	entry.value = strconv.FormatInt(count, 10)
+	c.entries[key] = entry
+	return count, nil
+}
+
+func (c *FakeCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *FakeCache) Has(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.live(key)
+	return ok, nil
+}
+
+// Keys returns the keys that have not expired, for asserting what a service left behind.
+func (c *FakeCache) Keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		if _, ok := c.live(key); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// live returns the entry of key and drops it when it has expired, the caller holds mu.
+func (c *FakeCache) live(key string) (fakeCacheEntry, bool) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return fakeCacheEntry{}, false
+	}
+	if !entry.expireAt.IsZero() && !c.clock.Now().Before(entry.expireAt) {
+		delete(c.entries, key)
+		return fakeCacheEntry{}, false
+	}
+	return entry, true
+}
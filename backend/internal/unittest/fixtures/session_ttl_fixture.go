@@ -0,0 +1,34 @@
+package fixtures
+
+import (
+	"context"
+	"ya-tool-craft/internal/config"
+	"ya-tool-craft/internal/domain/repository"
+)
+
+var _ repository.ISessionTTLProvider = ConfigSessionTTLs{}
+
+// ConfigSessionTTLs is an ISessionTTLProvider returning the lifetimes of the env config, as if no setting overrides them.
+type ConfigSessionTTLs struct {
+	Config config.Config
+}
+
+func NewConfigSessionTTLs(cfg config.Config) ConfigSessionTTLs {
+	return ConfigSessionTTLs{Config: cfg}
+}
+
+func (p ConfigSessionTTLs) AccessTokenTTL(ctx context.Context) uint64 {
+	return p.Config.AccessTokenTTL
+}
+
+func (p ConfigSessionTTLs) MaxAccessTokenTTL() uint64 {
+	return p.Config.AccessTokenTTL
+}
+
+func (p ConfigSessionTTLs) RefreshTokenTTL(ctx context.Context) uint64 {
+	return p.Config.RefreshTokenTTL
+}
+
+func (p ConfigSessionTTLs) WebAuthnChallengeTTL(ctx context.Context) int {
+	return p.Config.WebAuthnChallengeTTL
+}
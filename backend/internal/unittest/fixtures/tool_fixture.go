@@ -0,0 +1,123 @@
+package fixtures
+
+import (
+	"time"
+	"ya-tool-craft/internal/domain/entity"
+)
+
+// TestToolBuilder builds a ToolEntity for tests, only the fields a test cares about need to be set.
+//
+//	tool := fixtures.NewTestTool().WithID("tool-2").WithSource("console.log(1)").Build()
+type TestToolBuilder struct {
+	uniqueID string
+	tool     entity.ToolEntity
+}
+
+// NewTestTool starts from an active, non realtime tool created now.
+// Build generates a new unique id unless WithUniqueID is used.
+func NewTestTool() TestToolBuilder {
+	now := time.Now()
+	return TestToolBuilder{tool: entity.ToolEntity{
+		ID:         "tool-1",
+		Name:       "Test Tool",
+		Namespace:  "test-namespace",
+		IsActivate: true,
+		UiWidgets:  `[{"type": "text"}]`,
+		Source:     "source code here",
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}}
+}
+
+func (b TestToolBuilder) WithUniqueID(uniqueID string) TestToolBuilder {
+	b.uniqueID = uniqueID
+	return b
+}
+
+func (b TestToolBuilder) WithID(id string) TestToolBuilder {
+	b.tool.ID = id
+	return b
+}
+
+func (b TestToolBuilder) WithName(name string) TestToolBuilder {
+	b.tool.Name = name
+	return b
+}
+
+func (b TestToolBuilder) WithNamespace(namespace string) TestToolBuilder {
+	b.tool.Namespace = namespace
+	return b
+}
+
+func (b TestToolBuilder) WithCategory(category string) TestToolBuilder {
+	b.tool.Category = category
+	return b
+}
+
+func (b TestToolBuilder) WithActivate(isActivate bool) TestToolBuilder {
+	b.tool.IsActivate = isActivate
+	return b
+}
+
+func (b TestToolBuilder) WithRealtimeExecution(realtimeExecution bool) TestToolBuilder {
+	b.tool.RealtimeExecution = realtimeExecution
+	return b
+}
+
+func (b TestToolBuilder) WithArchived(isArchived bool) TestToolBuilder {
+	b.tool.IsArchived = isArchived
+	return b
+}
+
+func (b TestToolBuilder) WithUiWidgets(uiWidgets string) TestToolBuilder {
+	b.tool.UiWidgets = uiWidgets
+	return b
+}
+
+func (b TestToolBuilder) WithSource(source string) TestToolBuilder {
+	b.tool.Source = source
+	return b
+}
+
+func (b TestToolBuilder) WithDescription(description string) TestToolBuilder {
+	b.tool.Description = description
+	return b
+}
+
+func (b TestToolBuilder) WithExtraInfo(extraInfo map[string]string) TestToolBuilder {
+	b.tool.ExtraInfo = extraInfo
+	return b
+}
+
+func (b TestToolBuilder) WithTimes(createdAt, updatedAt time.Time) TestToolBuilder {
+	b.tool.CreatedAt = createdAt
+	b.tool.UpdatedAt = updatedAt
+	return b
+}
+
+func (b TestToolBuilder) Build() entity.ToolEntity {
+	t := b.tool
+	var tool entity.ToolEntity
+	if b.uniqueID == "" {
+		tool = entity.NewToolEntityWithoutUID(
+			t.ID, t.Name, t.Namespace, t.Category,
+			t.IsActivate, t.RealtimeExecution,
+			t.UiWidgets, t.Source,
+			t.Description,
+			t.ExtraInfo,
+			t.CreatedAt, t.UpdatedAt,
+		)
+	} else {
+		tool = entity.NewToolEntityWithUID(
+			b.uniqueID, t.ID, t.Name, t.Namespace,
+			t.Category,
+			t.IsActivate, t.RealtimeExecution,
+			t.UiWidgets, t.Source,
+			t.Description,
+			t.ExtraInfo,
+			t.CreatedAt, t.UpdatedAt,
+		)
+	}
+	tool.IsArchived = t.IsArchived
+	return tool
+}
@@ -0,0 +1,66 @@
+package fixtures
+
+import (
+	"fmt"
+	"ya-tool-craft/internal/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// TestUserBuilder builds a UserEntity for tests, only the fields a test cares about need to be set.
+//
+//	user := fixtures.NewTestUser().WithName("alice").WithRole(entity.UserRoleAdmin).Build()
+type TestUserBuilder struct {
+	user entity.UserEntity
+}
+
+// NewTestUser starts from a plain user with a random id and the user role.
+func NewTestUser() TestUserBuilder {
+	return TestUserBuilder{user: entity.UserEntity{
+		ID:    entity.UserIDEntity(fmt.Sprintf("user-%s", uuid.New().String())),
+		Name:  "test-user",
+		Roles: []entity.UserRoleEntity{entity.UserRoleUser},
+	}}
+}
+
+func (b TestUserBuilder) WithID(id entity.UserIDEntity) TestUserBuilder {
+	b.user.ID = id
+	return b
+}
+
+func (b TestUserBuilder) WithName(name string) TestUserBuilder {
+	b.user.Name = name
+	return b
+}
+
+func (b TestUserBuilder) WithMail(mail string) TestUserBuilder {
+	b.user.Mail = &mail
+	return b
+}
+
+func (b TestUserBuilder) WithPasswordHash(passwordHash string) TestUserBuilder {
+	b.user.PasswordHash = &passwordHash
+	return b
+}
+
+// WithRole replaces the roles of the user.
+func (b TestUserBuilder) WithRole(roles ...entity.UserRoleEntity) TestUserBuilder {
+	b.user.Roles = append([]entity.UserRoleEntity(nil), roles...)
+	return b
+}
+
+func (b TestUserBuilder) WithEncryptKey(encryptKey string) TestUserBuilder {
+	b.user.EncrypKey = encryptKey
+	return b
+}
+
+func (b TestUserBuilder) WithSSOBindings(bindings ...entity.UserSSOEntity) TestUserBuilder {
+	b.user.SSOBindings = append([]entity.UserSSOEntity(nil), bindings...)
+	return b
+}
+
+func (b TestUserBuilder) Build() entity.UserEntity {
+	user := b.user
+	user.Roles = append([]entity.UserRoleEntity(nil), b.user.Roles...)
+	return user
+}
@@ -6,12 +6,16 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"testing"
 	"time"
 	"ya-tool-craft/internal/config"
 	"ya-tool-craft/internal/core/logger"
+	"ya-tool-craft/internal/domain/repository"
 	"ya-tool-craft/internal/infra/repository_impl/client"
 	"ya-tool-craft/internal/infra/repository_impl/migration"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/pkg/errors"
 )
 
@@ -90,6 +94,57 @@ func (c *UnitTestContext) WithClearMysql(callback func(ctx context.Context, clie
 	defer client.Close()
 }
 
+// WithClearPostgres runs the callback against the postgres of POSTGRES_HOST after dropping everything in its public
+// schema and migrating it again. Without POSTGRES_HOST the test is skipped, there is no in-process postgres.
+func (c *UnitTestContext) WithClearPostgres(t testing.TB, callback func(ctx context.Context, client *client.PostgresClient)) {
+	if c.Config.PostgresHost == "" {
+		t.Skip("POSTGRES_HOST is not set, skip the postgres test")
+	}
+	cfg := c.PostgresConfig()
+
+	client, err := client.NewPostgresClient(cfg)
+	if err != nil {
+		panic(errors.Errorf("init unit test failed, create postgres client fail: %+v", err))
+	}
+	defer client.Close()
+
+	if _, err := client.DB().Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public"); err != nil {
+		panic(errors.Errorf("init unit test failed, clear postgres db fail: %+v", err))
+	}
+
+	// run migrate for postgres
+	migration := migration.NewRdsMigrationImpl(client, cfg)
+	err = migration.RunMigrate(c.Context)
+	if err != nil {
+		panic(errors.Errorf("init unit test failed, migrate postgres db fail: %+v", err))
+	}
+	fmt.Println("run postgres migration ok")
+
+	callback(c.Context, client)
+}
+
+// PostgresConfig is the config with postgres as the database, the repositories pick their sql dialect by DB_TYPE
+func (c *UnitTestContext) PostgresConfig() config.Config {
+	cfg := c.Config
+	cfg.DBType = "postgres"
+	return cfg
+}
+
+// WithClearRds runs the callback as a subtest against every relational database the repositories support, sqlite
+// always and postgres when POSTGRES_HOST is set. The repositories have to be built with the config it passes.
+func (c *UnitTestContext) WithClearRds(t *testing.T, callback func(t *testing.T, ctx context.Context, cfg config.Config, client repository.IRdsClient)) {
+	t.Run("sqlite", func(t *testing.T) {
+		c.WithClearSqlite(func(ctx context.Context, client *client.SqliteClient) {
+			callback(t, ctx, c.Config, client)
+		})
+	})
+	t.Run("postgres", func(t *testing.T) {
+		c.WithClearPostgres(t, func(ctx context.Context, client *client.PostgresClient) {
+			callback(t, ctx, c.PostgresConfig(), client)
+		})
+	})
+}
+
 func (c *UnitTestContext) WithClearBadger(callback func(ctx context.Context, client *client.BadgerClient)) {
 	client, err := client.NewBadgerClient(c.Config)
 	if err != nil {
@@ -110,6 +165,37 @@ func (c *UnitTestContext) WithClearNutsDB(callback func(ctx context.Context, cli
 	defer client.Close()
 }
 
+// WithClearRedis runs the callback against the redis of REDIS_HOST after flushing its database,
+// without REDIS_HOST an in-process redis server is started for the callback.
+func (c *UnitTestContext) WithClearRedis(callback func(ctx context.Context, client *client.RedisClient)) {
+	cfg := c.Config
+	if cfg.RedisHost == "" {
+		server, err := miniredis.Run()
+		if err != nil {
+			panic(errors.Errorf("init unit test failed, start in-process redis fail: %+v", err))
+		}
+		defer server.Close()
+
+		port, _ := strconv.Atoi(server.Port())
+		cfg.RedisHost = server.Host()
+		cfg.RedisPort = port
+		cfg.RedisPassword = ""
+		cfg.RedisTLS = false
+	}
+
+	client, err := client.NewRedisClient(cfg)
+	if err != nil {
+		panic(errors.Errorf("init unit test failed, create redis client fail: %+v", err))
+	}
+	defer client.Close()
+
+	if err := client.Client.FlushDB(c.Context).Err(); err != nil {
+		panic(errors.Errorf("init unit test failed, flush redis db fail: %+v", err))
+	}
+
+	callback(c.Context, client)
+}
+
 func UnitTestGetProjectPath() string {
 	_, currentFilePath, _, ok := runtime.Caller(0)
 	if !ok {
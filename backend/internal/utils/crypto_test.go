@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptString_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		plaintext string
+	}{
+		{name: "empty", plaintext: ""},
+		{name: "ascii", plaintext: "sk-test-1234567890"},
+		{name: "unicode", plaintext: "パスワード🔑"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ciphertext, err := EncryptString("encry-key-1", tt.plaintext)
+			require.NoError(t, err)
+			if tt.plaintext != "" {
+				assert.NotContains(t, ciphertext, tt.plaintext)
+			}
+
+			plaintext, err := DecryptString("encry-key-1", ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, tt.plaintext, plaintext)
+		})
+	}
+}
+
+func TestEncryptString_UsesRandomNonce(t *testing.T) {
+	t.Parallel()
+
+	first, err := EncryptString("encry-key-1", "value")
+	require.NoError(t, err)
+	second, err := EncryptString("encry-key-1", "value")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+}
+
+func TestDecryptString_Errors(t *testing.T) {
+	t.Parallel()
+
+	ciphertext, err := EncryptString("encry-key-1", "value")
+	require.NoError(t, err)
+	tampered := []byte(ciphertext)
+	tampered[len(tampered)-3] ^= 1
+
+	tests := []struct {
+		name       string
+		key        string
+		ciphertext string
+	}{
+		{name: "wrong key", key: "encry-key-2", ciphertext: ciphertext},
+		{name: "empty key", key: "", ciphertext: ciphertext},
+		{name: "tampered", key: "encry-key-1", ciphertext: string(tampered)},
+		{name: "not base64", key: "encry-key-1", ciphertext: "%%%"},
+		{name: "too short", key: "encry-key-1", ciphertext: "AAAA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := DecryptString(tt.key, tt.ciphertext)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -21,5 +21,10 @@ func TTLInSecondToTimeDuration(ttl uint64) time.Duration {
 
 // NowToSecond returns the current time truncated to seconds
 func NowToSecond() time.Time {
-	return time.Now().Truncate(time.Second).UTC()
+	return ToSecond(time.Now())
+}
+
+// ToSecond truncates the given time to seconds in UTC
+func ToSecond(t time.Time) time.Time {
+	return t.Truncate(time.Second).UTC()
 }
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CompareVersions compares two dotted versions such as "v1.2.0" and "1.10", returning -1, 0 or 1.
+// A leading v and a -prerelease or +build suffix are ignored, missing parts count as 0.
+func CompareVersions(a string, b string) (int, error) {
+	partsA, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	partsB, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < max(len(partsA), len(partsB)); i++ {
+		var partA, partB int
+		if i < len(partsA) {
+			partA = partsA[i]
+		}
+		if i < len(partsB) {
+			partB = partsB[i]
+		}
+		if partA != partB {
+			if partA < partB {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseVersion(version string) ([]int, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(trimmed, "-+"); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+	if trimmed == "" {
+		return nil, errors.Errorf("invalid version %q", version)
+	}
+	fields := strings.Split(trimmed, ".")
+	parts := make([]int, len(fields))
+	for i, field := range fields {
+		part, err := strconv.Atoi(field)
+		if err != nil || part < 0 {
+			return nil, errors.Errorf("invalid version %q", version)
+		}
+		parts[i] = part
+	}
+	return parts, nil
+}
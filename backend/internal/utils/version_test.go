@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "1.2.0", 0},
+		{"v1.2.0", "1.2", 0},
+		{"1.2.1", "1.2.0", 1},
+		{"1.9.0", "1.10.0", -1},
+		{"2", "1.99.99", 1},
+		{"1.2.0-beta.1", "1.2.0", 0},
+		{"1.2.0+build.5", "1.3", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			got, err := CompareVersions(tt.a, tt.b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompareVersions_Invalid(t *testing.T) {
+	for _, version := range []string{"", "v", "1..2", "1.x", "latest", "-1.0"} {
+		t.Run(version, func(t *testing.T) {
+			_, err := CompareVersions(version, "1.0.0")
+			assert.Error(t, err)
+		})
+	}
+}
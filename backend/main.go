@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"ya-tool-craft/internal/core/engine"
+	"ya-tool-craft/internal/di"
+	"ya-tool-craft/internal/infra/repository_impl/client"
 )
 
 //	@title			My-Golang-Framework
@@ -21,19 +23,46 @@ import (
 // @externalDocs.description	OpenAPI
 // @externalDocs.url			https://swagger.io/resources/open-api/
 func main() {
-	engine := engine.NewEngine()
+	// the server runs every tool in a child process of its own executable, see GojaToolRunner
+	if client.IsToolRunProcess() {
+		os.Exit(client.ServeToolRunProcess())
+	}
 
-	// if there is env "SERVERLESS" set to true, it runs in serverless mode
+	// close the storage on the way out, also while a panic unwinds main, so the data files are left consistent
+	defer closeStorage()
 
+	// if there is env "SERVERLESS" set to true, it runs in serverless mode
 	serverlessMode := os.Getenv("SERVERLESS") == "true"
 	fmt.Println("[ENGINE] SERVERLESS MODE:", serverlessMode)
-	if !serverlessMode {
-		fmt.Println("[ENGINE] Not serverless mode, start to run migration and HTTP server")
-		engine.RunDBMigration()
-		fmt.Println("[ENGINE] Run Migration over")
-		engine.Run()
+	if serverlessMode {
+		// the engine is built by the first invocation
+		engine.RunServerless()
+		return
+	}
+
+	engine, err := engine.NewEngine()
+	if err != nil {
+		fmt.Println("[ENGINE] Refusing to start,", err)
+		// os.Exit skips the deferred close
+		closeStorage()
+		os.Exit(1)
+	}
+	fmt.Println("[ENGINE] Not serverless mode, start to run migration and HTTP server")
+	engine.RunDBMigration()
+	fmt.Println("[ENGINE] Run Migration over")
+	if err := engine.BootstrapAdmin(); err != nil {
+		fmt.Println("[ENGINE] Failed to bootstrap admin:", err)
+	}
+	engine.LogStartupSummary()
+	if err := engine.Run(); err != nil {
+		fmt.Println("[ENGINE] Server stopped:", err)
+	}
+}
+
+func closeStorage() {
+	if err := di.Close(); err != nil {
+		fmt.Println("[ENGINE] Failed to close storage:", err)
 		return
-	} else {
-		// todo: serverless mode support
 	}
+	fmt.Println("[ENGINE] Storage closed")
 }
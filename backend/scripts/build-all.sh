@@ -16,7 +16,8 @@ VERSION="$1"
 BUILD_TIME="$(date -u '+%Y-%m-%dT%H:%M:%SZ')"
 COMMIT="$(git -C "$PROJECT_ROOT" rev-parse --short HEAD 2>/dev/null || echo "unknown")"
 
-LDFLAGS="-s -w -X main.Version=${VERSION} -X main.BuildTime=${BUILD_TIME} -X main.Commit=${COMMIT}"
+BUILDINFO_PKG="ya-tool-craft/internal/core/buildinfo"
+LDFLAGS="-s -w -X ${BUILDINFO_PKG}.Version=${VERSION} -X ${BUILDINFO_PKG}.BuildTime=${BUILD_TIME} -X ${BUILDINFO_PKG}.Commit=${COMMIT}"
 
 FRONTEND_SRC="${PROJECT_ROOT}/../app/build/client"
 EMBED_DIR="${PROJECT_ROOT}/internal/embed/frontend"